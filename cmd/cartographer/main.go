@@ -15,36 +15,119 @@
 package main
 
 import (
-	"context"
 	"flag"
+	"os"
+	"time"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/registrar"
 	"github.com/vmware-tanzu/cartographer/pkg/root"
 )
 
 var devMode bool
 var port int
 var certDir string
+var otlpEndpoint string
+var otlpInsecure bool
+var metricsBindAddress string
+var gracefulShutdownTimeout time.Duration
+var workloadDefaults string
+var policyFile string
+var triggerToken string
+var realizationLeaseDuration time.Duration
+var artifactStoreURL string
+var artifactStoreToken string
+var clusterName string
+var clusterDomain string
+var clusterData string
+var rateLimits registrar.RateLimits
+var shardCount int
+var shardIndex int
+var cacheSelectors registrar.CacheSelectors
+var resyncInterval time.Duration
+var realizerPlugins plugin.Endpoints
+var realizerPluginsInsecure bool
+var preflightPermissions bool
+var repoCacheConfigMaps string
 
 func init() {
 	flag.IntVar(&port, "Port", 9443, "Webhook server Port")
 	flag.StringVar(&certDir, "cert-dir", "", "Webhook server tls dir")
 	flag.BoolVar(&devMode, "dev", false, "Human readable logs")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "", "OpenTelemetry collector host:port to export traces to (tracing is disabled when empty)")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Connect to the OpenTelemetry collector without TLS")
+	flag.StringVar(&metricsBindAddress, "metrics-bind-address", "0", "Address Prometheus metrics are served on, such as :8080 (metrics are not served when 0)")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 30*time.Second, "Longest time given to in-flight reconciles to finish on shutdown")
+	flag.StringVar(&workloadDefaults, "workload-defaults", "cartographer-system/workload-defaults", "ConfigMap (namespace/name) of defaults filled in on new workloads (defaulting is disabled when empty)")
+	flag.StringVar(&policyFile, "policy-file", "", "YAML file of CEL rules every stamped object must satisfy before it is submitted")
+	flag.StringVar(&triggerToken, "trigger-token", os.Getenv("CARTOGRAPHER_TRIGGER_TOKEN"), "Token callers of the trigger endpoint must present (defaults to $CARTOGRAPHER_TRIGGER_TOKEN; the endpoint is not served when empty)")
+	flag.DurationVar(&realizationLeaseDuration, "realization-lease-duration", 0, "How long a replica holds the lease on an object it realizes, keeping replicas from realizing it concurrently (leasing is disabled when 0)")
+	flag.StringVar(&artifactStoreURL, "artifact-store-url", "", "HTTP endpoint the artifacts realized for workloads are posted to (recording is disabled when empty)")
+	flag.StringVar(&artifactStoreToken, "artifact-store-token", os.Getenv("CARTOGRAPHER_ARTIFACT_STORE_TOKEN"), "Bearer token presented to the artifact store (defaults to $CARTOGRAPHER_ARTIFACT_STORE_TOKEN)")
+	flag.StringVar(&clusterName, "cluster-name", "", "Name of this cluster in the records of the artifact store, and to templates as $(cluster.name)$")
+	flag.StringVar(&clusterDomain, "cluster-domain", "cluster.local", "DNS domain of the cluster's services, read by templates as $(cluster.domain)$")
+	flag.StringVar(&clusterData, "cluster-data", "cartographer-system/cluster-data", "ConfigMap (namespace/name) whose data templates read as $(cluster.data)$")
+	flag.Var(&rateLimits.Workload, "workload-rate-limit", "Rate limiter of the workload controller's queue, as base-delay=5ms,max-delay=1000s,qps=10,burst=100 (settings left out keep these defaults)")
+	flag.Var(&rateLimits.Deliverable, "deliverable-rate-limit", "Rate limiter of the deliverable controller's queue, set as --workload-rate-limit")
+	flag.Var(&rateLimits.Pipeline, "pipeline-rate-limit", "Rate limiter of the pipeline controller's queue, set as --workload-rate-limit")
+	flag.Var(&rateLimits.Blueprint, "blueprint-rate-limit", "Rate limiter of the queues of the supply chain, delivery, blueprint and blueprint source controllers, set as --workload-rate-limit")
+	flag.IntVar(&shardCount, "shard-count", 1, "Number of shards workloads and deliverables are partitioned into between replicas (sharding is disabled when 1)")
+	flag.IntVar(&shardIndex, "shard-index", -1, "Shard this replica realizes the workloads and deliverables of (defaults to the ordinal ending the hostname, as in a StatefulSet)")
+	flag.Var(&cacheSelectors, "cache-label-selector", "Cache only the objects of a kind matching a label selector, as Kind.version.group=selector such as Deployment.v1.apps=carto.run/resource-name (may be repeated)")
+	flag.DurationVar(&resyncInterval, "resync-interval", 5*time.Second, "How often ready workloads and deliverables are reconciled again (overridden per object by the carto.run/resync-interval annotation)")
+	flag.Var(&realizerPlugins, "realizer-plugin", "gRPC plugin realizing the resources of templates that name it, as name=host:port such as terraform=terraform-runner.infra:9000 (may be repeated)")
+	flag.BoolVar(&realizerPluginsInsecure, "realizer-plugin-insecure", false, "Connect to the realizer plugins without TLS")
+	flag.BoolVar(&preflightPermissions, "preflight-permissions", false, "Review the permissions needed on the objects a blueprint stamps before realizing it, reporting those missing as PermissionsNotMet")
+	flag.StringVar(&repoCacheConfigMaps, "repo-cache-configmaps", "", "ConfigMaps (namespace/prefix) the caches of submitted objects are saved to and restored from on start, as <prefix>-workload, <prefix>-deliverable and <prefix>-pipeline (the caches are not persisted when empty)")
 	flag.Parse()
 }
 
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
-
-	defer cancel()
+	ctx := signals.SetupSignalHandler()
 
 	cmd := root.Command{
 		Port:    port,
 		CertDir: certDir,
 		Context: ctx,
 		Logger:  zap.New(zap.UseDevMode(devMode)),
+
+		OTLPEndpoint: otlpEndpoint,
+		OTLPInsecure: otlpInsecure,
+
+		MetricsBindAddress: metricsBindAddress,
+
+		GracefulShutdownTimeout: gracefulShutdownTimeout,
+		WorkloadDefaults:        workloadDefaults,
+		PolicyFile:              policyFile,
+		TriggerToken:            triggerToken,
+
+		RealizationLeaseDuration: realizationLeaseDuration,
+
+		ArtifactStoreURL:   artifactStoreURL,
+		ArtifactStoreToken: artifactStoreToken,
+		ClusterName:        clusterName,
+
+		ClusterDomain: clusterDomain,
+		ClusterData:   clusterData,
+
+		RateLimits: rateLimits,
+
+		ShardCount: shardCount,
+		ShardIndex: shardIndex,
+
+		CacheSelectors: cacheSelectors,
+		ResyncInterval: resyncInterval,
+
+		RealizerPlugins:         realizerPlugins,
+		RealizerPluginsInsecure: realizerPluginsInsecure,
+
+		PreflightPermissions: preflightPermissions,
+
+		RepoCacheConfigMaps: repoCacheConfigMaps,
 	}
 
 	if err := cmd.Execute(); err != nil {
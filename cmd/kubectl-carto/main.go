@@ -0,0 +1,411 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/describe"
+	"github.com/vmware-tanzu/cartographer/pkg/graph"
+	"github.com/vmware-tanzu/cartographer/pkg/lint"
+	"github.com/vmware-tanzu/cartographer/pkg/rbac"
+	"github.com/vmware-tanzu/cartographer/pkg/render"
+)
+
+const usage = `Usage:
+  kubectl carto graph supplychain <name> [--workload <name>] [-n <namespace>] [-o text|dot]
+  kubectl carto graph delivery <name> [--deliverable <name>] [-n <namespace>] [-o text|dot]
+  kubectl carto stamp -f <file> [-f <file>...]
+  kubectl carto simulate --baseline <file> --candidate <file> -f <file> [-f <file>...]
+  kubectl carto lint -f <file> [-f <file>...]
+  kubectl carto rbac -f <file> [-f <file>...] [--name <name>]
+  kubectl carto describe workload <name> [-n <namespace>]
+
+graph draws a blueprint as a graph of its resources and the outputs they
+pass to each other. Given a workload or deliverable, it also shows the
+object stamped for each resource, its readiness and its outputs.
+
+stamp prints the objects stamped for the workloads and deliverables in the
+given files, by the blueprints and templates in them, without a cluster.
+
+simulate stamps the workloads and deliverables in the -f files, such as
+those exported with kubectl get -o yaml, with the baseline blueprints and
+templates and with the candidate ones, then reports render errors and the
+stamped objects that differ. --baseline and --candidate are repeatable.
+
+lint checks the blueprints and templates in the given files: resource names,
+template references, the kinds of inputs, params and unused outputs.
+
+rbac prints a ClusterRole granting the controller access to the kinds
+stamped by the templates the blueprints and Pipelines in the given files
+reference, and to the kinds Pipelines select. It is aggregated into the
+cartographer-controller ClusterRole when applied.
+Templates it cannot inspect are listed in comments above it.
+
+describe workload reports on a workload in one place: its spec, the supply
+chain it matched, the object stamped for each resource with its readiness
+and retries, the transitions of its conditions, the recent events of the
+workload and its objects, and its health.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) > 0 && args[0] == "stamp" {
+		return runStamp(args[1:], out)
+	}
+	if len(args) > 0 && args[0] == "simulate" {
+		return runSimulate(args[1:], out)
+	}
+	if len(args) > 0 && args[0] == "lint" {
+		return runLint(args[1:], out)
+	}
+	if len(args) > 0 && args[0] == "rbac" {
+		return runRBAC(args[1:], out)
+	}
+	if len(args) >= 3 && args[0] == "graph" {
+		return runGraph(args[1:], out)
+	}
+	if len(args) >= 3 && args[0] == "describe" {
+		return runDescribe(args[1:], out)
+	}
+	fmt.Fprint(os.Stderr, usage)
+	os.Exit(2)
+	return nil
+}
+
+func runStamp(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("stamp", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+	var files fileList
+	flags.Var(&files, "f", "File of blueprints, templates, workloads and deliverables (repeatable, - for stdin)")
+	_ = flags.Parse(args)
+	if len(files) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	manifests := &render.Manifests{}
+	for _, file := range files {
+		if err := loadFile(manifests, file); err != nil {
+			return err
+		}
+	}
+
+	stamped, err := render.Render(context.Background(), manifests)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range stamped {
+		doc, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("marshal %s '%s': %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if _, err := fmt.Fprintf(out, "---\n%s", doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runSimulate(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+	var baselineFiles, candidateFiles, ownerFiles fileList
+	flags.Var(&baselineFiles, "baseline", "File of the current blueprints and templates (repeatable)")
+	flags.Var(&candidateFiles, "candidate", "File of the changed blueprints and templates (repeatable)")
+	flags.Var(&ownerFiles, "f", "File of workloads and deliverables to render (repeatable, - for stdin)")
+	_ = flags.Parse(args)
+	if len(baselineFiles) == 0 || len(candidateFiles) == 0 || len(ownerFiles) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	baseline, candidate, owners := &render.Manifests{}, &render.Manifests{}, &render.Manifests{}
+	for _, load := range []struct {
+		manifests *render.Manifests
+		files     fileList
+	}{{baseline, baselineFiles}, {candidate, candidateFiles}, {owners, ownerFiles}} {
+		for _, file := range load.files {
+			if err := loadFile(load.manifests, file); err != nil {
+				return err
+			}
+		}
+	}
+
+	simulations, err := render.Simulate(context.Background(), baseline, candidate, owners)
+	if err != nil {
+		return err
+	}
+	if err := render.WriteReport(out, simulations); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, s := range simulations {
+		if s.Status() == render.Failed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d failed to render with the candidate", failed, len(simulations))
+	}
+	return nil
+}
+
+func runLint(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+	var files fileList
+	flags.Var(&files, "f", "File of blueprints and templates (repeatable, - for stdin)")
+	_ = flags.Parse(args)
+	if len(files) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	manifests := &render.Manifests{}
+	for _, file := range files {
+		if err := loadFile(manifests, file); err != nil {
+			return err
+		}
+	}
+
+	errors := 0
+	for _, finding := range lint.Lint(manifests) {
+		if finding.Severity == lint.Error {
+			errors++
+		}
+		if _, err := fmt.Fprintln(out, finding); err != nil {
+			return err
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf("%d errors found", errors)
+	}
+	return nil
+}
+
+func runRBAC(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("rbac", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+	var files fileList
+	flags.Var(&files, "f", "File of blueprints and templates (repeatable, - for stdin)")
+	name := flags.String("name", "cartographer-controller-stamped", "Name of the ClusterRole")
+	_ = flags.Parse(args)
+	if len(files) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	manifests := &render.Manifests{}
+	for _, file := range files {
+		if err := loadFile(manifests, file); err != nil {
+			return err
+		}
+	}
+
+	role, uncovered := rbac.Generate(manifests, *name)
+	doc, err := yaml.Marshal(role)
+	if err != nil {
+		return fmt.Errorf("marshal ClusterRole '%s': %w", role.Name, err)
+	}
+
+	b := &strings.Builder{}
+	for _, u := range uncovered {
+		fmt.Fprintf(b, "# not covered: %s\n", u)
+	}
+	fmt.Fprintf(b, "---\n%s", doc)
+	_, err = io.WriteString(out, b.String())
+	return err
+}
+
+func loadFile(manifests *render.Manifests, file string) error {
+	if file == "-" {
+		return manifests.Load(os.Stdin)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	if err := manifests.Load(f); err != nil {
+		return fmt.Errorf("load %s: %w", file, err)
+	}
+	return nil
+}
+
+type fileList []string
+
+func (l *fileList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *fileList) Set(file string) error {
+	*l = append(*l, file)
+	return nil
+}
+
+func runGraph(args []string, out io.Writer) error {
+	kind, name := args[0], args[1]
+
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+	var workload, deliverable, namespace, output string
+	flags.StringVar(&workload, "workload", "", "Workload to realize a supply chain graph for")
+	flags.StringVar(&deliverable, "deliverable", "", "Deliverable to realize a delivery graph for")
+	flags.StringVar(&namespace, "namespace", "", "Namespace of the workload or deliverable (defaults to the kubeconfig context's)")
+	flags.StringVar(&namespace, "n", "", "Shorthand for --namespace")
+	flags.StringVar(&output, "output", "text", "Output format: text or dot")
+	flags.StringVar(&output, "o", "text", "Shorthand for --output")
+	_ = flags.Parse(args[2:])
+
+	write := graph.WriteText
+	switch output {
+	case "text":
+	case "dot":
+		write = graph.WriteDot
+	default:
+		return fmt.Errorf("unknown output format '%s', expected text or dot", output)
+	}
+
+	var owner string
+	switch kind {
+	case "supplychain":
+		if deliverable != "" {
+			return fmt.Errorf("--deliverable applies to delivery graphs, use --workload")
+		}
+		owner = workload
+	case "delivery":
+		if workload != "" {
+			return fmt.Errorf("--workload applies to supply chain graphs, use --deliverable")
+		}
+		owner = deliverable
+	default:
+		return fmt.Errorf("unknown blueprint kind '%s', expected supplychain or delivery", kind)
+	}
+
+	var ownerName *types.NamespacedName
+	if owner != "" {
+		if namespace == "" {
+			var err error
+			namespace, _, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+				clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{},
+			).Namespace()
+			if err != nil {
+				return fmt.Errorf("get namespace: %w", err)
+			}
+		}
+		ownerName = &types.NamespacedName{Namespace: namespace, Name: owner}
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	var g *graph.Graph
+	if kind == "supplychain" {
+		g, err = graph.SupplyChain(ctx, c, name, ownerName)
+	} else {
+		g, err = graph.Delivery(ctx, c, name, ownerName)
+	}
+	if err != nil {
+		return err
+	}
+
+	return write(out, g)
+}
+
+func runDescribe(args []string, out io.Writer) error {
+	kind, name := args[0], args[1]
+
+	flags := flag.NewFlagSet("describe", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+	var namespace string
+	flags.StringVar(&namespace, "namespace", "", "Namespace of the workload (defaults to the kubeconfig context's)")
+	flags.StringVar(&namespace, "n", "", "Shorthand for --namespace")
+	_ = flags.Parse(args[2:])
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if kind != "workload" {
+		return fmt.Errorf("unknown kind '%s', expected workload", kind)
+	}
+
+	if namespace == "" {
+		var err error
+		namespace, _, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{},
+		).Namespace()
+		if err != nil {
+			return fmt.Errorf("get namespace: %w", err)
+		}
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	return describe.Workload(context.Background(), c, types.NamespacedName{Namespace: namespace, Name: name}, out)
+}
+
+func newClient() (client.Client, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("get config: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("add to scheme: %w", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("add to scheme: %w", err)
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("new client: %w", err)
+	}
+	return c, nil
+}
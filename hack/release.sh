@@ -28,6 +28,12 @@ readonly RELEASE_DATE=${RELEASE_DATE:-$(TZ=UTC date +"%Y-%m-%dT%H:%M:%SZ")}
 readonly YTT_VERSION=0.36.0
 readonly YTT_CHECKSUM=d81ecf6c47209f6ac527e503a6fd85e999c3c2f8369e972794047bddc7e5fbe2
 
+readonly SOPS_VERSION=3.8.1
+# sha256 of sops-v${SOPS_VERSION}.linux.amd64, as published with the release.
+# It must be filled in, from a copy of the binary verified out of band, before
+# a release is cut.
+readonly SOPS_CHECKSUM=
+
 main() {
         readonly RELEASE_VERSION=${RELEASE_VERSION:-$(git_current_version)}
         readonly PREVIOUS_VERSION=${PREVIOUS_VERSION:-$(git_previous_version $RELEASE_VERSION)}
@@ -36,6 +42,7 @@ main() {
         cd $ROOT
 
         download_ytt_to_kodata
+        download_sops_to_kodata
         create_imgpkg_bundle
         create_carvel_packaging_objects
 
@@ -54,6 +61,7 @@ show_vars() {
 	ROOT:	       		  $ROOT
 	SCRATCH:       		$SCRATCH
 	YTT_VERSION		    $YTT_VERSION
+	SOPS_VERSION		  $SOPS_VERSION
 	"
 }
 
@@ -76,6 +84,30 @@ download_ytt_to_kodata() {
         popd
 }
 
+download_sops_to_kodata() {
+        local url=https://github.com/getsops/sops/releases/download/v${SOPS_VERSION}/sops-v${SOPS_VERSION}.linux.amd64
+        local fname=sops-v${SOPS_VERSION}.linux.amd64
+
+        test -n "${SOPS_CHECKSUM}" || {
+                echo "SOPS_CHECKSUM is not set: fill in the sha256 of $fname in $0." >&2
+                exit 1
+        }
+
+        local dest
+        dest=$(realpath ./cmd/cartographer/kodata/sops-linux-amd64)
+
+        test -x $dest && echo "${SOPS_CHECKSUM} $dest" | sha256sum -c && {
+                echo "sops already found in kodata."
+                return
+        }
+
+        pushd "$(mktemp -d)"
+        curl -sSOL $url
+        echo "${SOPS_CHECKSUM} $fname" | sha256sum -c
+        install -m 0755 $fname $dest
+        popd
+}
+
 # creates, in a scratch location, an imgpkg bundle following the convention that
 # is expected of bundles for Packages (see ref):
 #
@@ -0,0 +1,250 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	cradmission "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// TemplateValidator validates supply chain templates on admission. Beyond
+// the checks each template type performs on itself, a template is rejected
+// when it lists template libraries that do not exist or includes partials
+// or helpers they do not define, and a template that carries a sample is
+// stamped with it and rejected if it does not render.
+type TemplateValidator struct {
+	reader      client.Reader
+	clusterData *clusterdata.Reader
+}
+
+var _ cradmission.CustomValidator = &TemplateValidator{}
+
+// NewTemplateValidator returns a TemplateValidator that resolves sample
+// workload references through reader.
+func NewTemplateValidator(reader client.Reader) *TemplateValidator {
+	return &TemplateValidator{reader: reader}
+}
+
+// AddClusterData renders samples with the facts about the cluster the
+// reader returns, as templates are realized with them.
+func (v *TemplateValidator) AddClusterData(reader *clusterdata.Reader) {
+	v.clusterData = reader
+}
+
+func (v *TemplateValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	validator, ok := obj.(webhook.Validator)
+	if !ok {
+		return fmt.Errorf("unexpected object of type %T", obj)
+	}
+	if err := validator.ValidateCreate(); err != nil {
+		return err
+	}
+	if err := v.checkLibraries(ctx, obj); err != nil {
+		return err
+	}
+	return v.renderSample(ctx, obj)
+}
+
+func (v *TemplateValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	validator, ok := newObj.(webhook.Validator)
+	if !ok {
+		return fmt.Errorf("unexpected object of type %T", newObj)
+	}
+	if err := validator.ValidateUpdate(oldObj); err != nil {
+		return err
+	}
+	if err := v.checkLibraries(ctx, newObj); err != nil {
+		return err
+	}
+	return v.renderSample(ctx, newObj)
+}
+
+func (v *TemplateValidator) ValidateDelete(_ context.Context, obj runtime.Object) error {
+	validator, ok := obj.(webhook.Validator)
+	if !ok {
+		return fmt.Errorf("unexpected object of type %T", obj)
+	}
+	return validator.ValidateDelete()
+}
+
+// checkLibraries checks that the template libraries a template lists exist
+// and define the partials and helpers it includes.
+func (v *TemplateValidator) checkLibraries(ctx context.Context, obj runtime.Object) error {
+	apiTemplate, ok := obj.(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected object of type %T", obj)
+	}
+	template, err := templates.NewModelFromAPI(apiTemplate)
+	if err != nil {
+		return err
+	}
+
+	spec := template.GetResourceTemplate()
+	var raw []byte
+	if patcher, ok := template.(templates.ConfigPatcher); ok && patcher.GetPatches() != nil {
+		raw, _ = json.Marshal(patcher.GetPatches())
+	} else if spec.Template != nil {
+		raw = spec.Template.Raw
+	}
+
+	libraries, err := templates.GetLibraries(ctx, v.getLibrary, spec.Libraries)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	if err := templates.CheckLibraryReferences(raw, libraries); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	return nil
+}
+
+func (v *TemplateValidator) renderSample(ctx context.Context, obj runtime.Object) error {
+	apiTemplate, ok := obj.(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected object of type %T", obj)
+	}
+	template, err := templates.NewModelFromAPI(apiTemplate)
+	if err != nil {
+		return err
+	}
+
+	spec := template.GetResourceTemplate()
+	if spec.Sample == nil {
+		return nil
+	}
+
+	workload, err := v.sampleWorkload(ctx, spec.Sample)
+	if err != nil {
+		return fmt.Errorf("invalid sample: %w", err)
+	}
+
+	inputs := sampleInputs(spec.Sample)
+	templatingContext := map[string]interface{}{
+		"workload": workload,
+		"params":   templates.ParamsBuilder(template.GetDefaultParams(), nil),
+		"sources":  inputs.Sources,
+		"images":   inputs.Images,
+		"configs":  inputs.Configs,
+	}
+	if inputs.OnlyConfig() != nil {
+		templatingContext["config"] = inputs.OnlyConfig()
+	}
+	if inputs.OnlyImage() != nil {
+		templatingContext["image"] = inputs.OnlyImage()
+	}
+	if inputs.OnlySource() != nil {
+		templatingContext["source"] = inputs.OnlySource()
+	}
+
+	if v.clusterData != nil {
+		cluster, err := v.clusterData.Read(ctx)
+		if err != nil {
+			return fmt.Errorf("read cluster data: %w", err)
+		}
+		templatingContext["cluster"] = cluster
+	}
+
+	libraries, err := templates.GetLibraries(ctx, v.getLibrary, spec.Libraries)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	if err := templates.IncludeLibraries(libraries, templatingContext); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	stamper := templates.StamperBuilder(workload, templatingContext, templates.Labels{})
+	stampedObjects, err := stamper.StampAll(ctx, spec)
+	if err != nil {
+		return fmt.Errorf("invalid template: failed to render sample: %w", err)
+	}
+	for _, stampedObject := range stampedObjects {
+		if stampedObject.GetAPIVersion() == "" || stampedObject.GetKind() == "" {
+			return fmt.Errorf("invalid template: rendered sample must set apiVersion and kind")
+		}
+	}
+
+	return nil
+}
+
+// getLibrary gets the named template library, or nil when there is none.
+func (v *TemplateValidator) getLibrary(ctx context.Context, name string) (*v1alpha1.ClusterTemplateLibrary, error) {
+	library := &v1alpha1.ClusterTemplateLibrary{}
+	if err := v.reader.Get(ctx, types.NamespacedName{Name: name}, library); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return library, nil
+}
+
+func (v *TemplateValidator) sampleWorkload(ctx context.Context, sample *v1alpha1.TemplateSample) (*v1alpha1.Workload, error) {
+	workload := &v1alpha1.Workload{}
+
+	if sample.WorkloadRef != nil {
+		key := types.NamespacedName{Name: sample.WorkloadRef.Name, Namespace: sample.WorkloadRef.Namespace}
+		if err := v.reader.Get(ctx, key, workload); err != nil {
+			return nil, fmt.Errorf("get workload '%s': %w", key, err)
+		}
+		return workload, nil
+	}
+
+	if err := json.Unmarshal(sample.Workload.Raw, workload); err != nil {
+		return nil, fmt.Errorf("unmarshal workload: %w", err)
+	}
+	workload.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("Workload"))
+	return workload, nil
+}
+
+func sampleInputs(sample *v1alpha1.TemplateSample) *templates.Inputs {
+	inputs := &templates.Inputs{
+		Sources: map[string]templates.SourceInput{},
+		Images:  map[string]templates.ImageInput{},
+		Configs: map[string]templates.ConfigInput{},
+	}
+
+	for _, source := range sample.Sources {
+		inputs.Sources[source.Name] = templates.SourceInput{
+			URL:      source.URL,
+			Revision: source.Revision,
+			Name:     source.Name,
+		}
+	}
+	for _, image := range sample.Images {
+		inputs.Images[image.Name] = templates.ImageInput{
+			Image: image.Image,
+			Name:  image.Name,
+		}
+	}
+	for _, config := range sample.Configs {
+		inputs.Configs[config.Name] = templates.ConfigInput{
+			Config: config.Config,
+			Name:   config.Name,
+		}
+	}
+
+	return inputs
+}
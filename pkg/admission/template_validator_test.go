@@ -0,0 +1,327 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/admission"
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
+)
+
+var _ = Describe("TemplateValidator", func() {
+	var (
+		ctx       context.Context
+		validator *admission.TemplateValidator
+		template  *v1alpha1.ClusterSourceTemplate
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		existingWorkload := &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "existing-workload",
+				Namespace: "some-namespace",
+			},
+			Spec: v1alpha1.WorkloadSpec{
+				Image: pointer.StringPtr("some-image"),
+			},
+		}
+		library := &v1alpha1.ClusterTemplateLibrary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "standard",
+			},
+			Spec: v1alpha1.TemplateLibrarySpec{
+				Partials: []v1alpha1.TemplatePartial{
+					{Name: "labels", Template: runtime.RawExtension{Raw: []byte(`{"app": "$(workload.metadata.name)$"}`)}},
+				},
+				Helpers: []v1alpha1.TemplateHelper{
+					{Name: "configName", Expression: `value.workload.metadata.name + "-config"`},
+				},
+			},
+		}
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existingWorkload, library).Build()
+		validator = admission.NewTemplateValidator(reader)
+
+		template = &v1alpha1.ClusterSourceTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "some-template",
+			},
+			Spec: v1alpha1.SourceTemplateSpec{
+				TemplateSpec: v1alpha1.TemplateSpec{
+					Template: &runtime.RawExtension{Raw: []byte(`{
+						"apiVersion": "v1",
+						"kind": "ConfigMap",
+						"metadata": {"name": "$(workload.metadata.name)$"},
+						"data": {"url": "$(sources.code.url)$", "image": "$(workload.spec.image)$"}
+					}`)},
+				},
+				URLPath:      ".data.url",
+				RevisionPath: ".data.revision",
+			},
+		}
+	})
+
+	Context("template has no sample", func() {
+		It("succeeds without rendering", func() {
+			Expect(validator.ValidateCreate(ctx, template)).To(Succeed())
+		})
+	})
+
+	Context("template includes partials and helpers", func() {
+		BeforeEach(func() {
+			template.Spec.Libraries = []string{"standard"}
+			template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{
+				"apiVersion": "v1",
+				"kind": "ConfigMap",
+				"metadata": {"name": "$(helpers.configName)$"},
+				"data": "$(partials.labels)$"
+			}`)}
+		})
+
+		It("succeeds on create and update when its libraries define them", func() {
+			Expect(validator.ValidateCreate(ctx, template)).To(Succeed())
+			Expect(validator.ValidateUpdate(ctx, template.DeepCopy(), template)).To(Succeed())
+		})
+
+		It("rejects the template when a library does not exist", func() {
+			template.Spec.Libraries = []string{"standard", "missing"}
+
+			err := validator.ValidateCreate(ctx, template)
+			Expect(err).To(MatchError("invalid template: template library 'missing' not found"))
+		})
+
+		It("rejects the template when its libraries do not define what it includes", func() {
+			template.Spec.Libraries = nil
+
+			err := validator.ValidateUpdate(ctx, template.DeepCopy(), template)
+			Expect(err).To(MatchError("invalid template: helper 'configName' is not defined by the template's libraries"))
+		})
+	})
+
+	Context("template fails its own validation", func() {
+		BeforeEach(func() {
+			template.Spec.Template = nil
+		})
+
+		It("returns the validation error", func() {
+			err := validator.ValidateCreate(ctx, template)
+			Expect(err).To(MatchError(ContainSubstring("must specify one of template or ytt")))
+		})
+	})
+
+	Context("template embeds a sample workload", func() {
+		BeforeEach(func() {
+			template.Spec.Sample = &v1alpha1.TemplateSample{
+				Workload: &runtime.RawExtension{Raw: []byte(`{
+					"metadata": {"name": "sample-workload"},
+					"spec": {"image": "some-image"}
+				}`)},
+				Sources: []v1alpha1.SampleSource{
+					{Name: "code", URL: "https://example.com/code.tar.gz"},
+				},
+			}
+		})
+
+		It("succeeds on create and update when the sample renders", func() {
+			Expect(validator.ValidateCreate(ctx, template)).To(Succeed())
+			Expect(validator.ValidateUpdate(ctx, template.DeepCopy(), template)).To(Succeed())
+		})
+
+		Context("the template refers to an input the sample does not provide", func() {
+			BeforeEach(func() {
+				template.Spec.Sample.Sources = nil
+			})
+
+			It("rejects the template", func() {
+				err := validator.ValidateCreate(ctx, template)
+				Expect(err).To(MatchError(ContainSubstring("invalid template: failed to render sample")))
+			})
+		})
+
+		Context("the rendered object has no kind", func() {
+			BeforeEach(func() {
+				template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{
+					"apiVersion": "v1",
+					"metadata": {"name": "$(workload.metadata.name)$"}
+				}`)}
+			})
+
+			It("rejects the template", func() {
+				err := validator.ValidateCreate(ctx, template)
+				Expect(err).To(MatchError("invalid template: rendered sample must set apiVersion and kind"))
+			})
+		})
+
+		Context("the template includes partials", func() {
+			BeforeEach(func() {
+				template.Spec.Libraries = []string{"standard"}
+				template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{
+					"apiVersion": "v1",
+					"kind": "ConfigMap",
+					"metadata": {"name": "$(workload.metadata.name)$"},
+					"data": "$(partials.labels)$"
+				}`)}
+			})
+
+			It("renders the sample with the partials of the template's libraries", func() {
+				Expect(validator.ValidateCreate(ctx, template)).To(Succeed())
+			})
+
+			It("rejects the template when a library does not exist", func() {
+				template.Spec.Libraries = []string{"missing"}
+
+				err := validator.ValidateCreate(ctx, template)
+				Expect(err).To(MatchError("invalid template: template library 'missing' not found"))
+			})
+		})
+
+		Context("the template reads the cluster data", func() {
+			BeforeEach(func() {
+				template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{
+					"apiVersion": "v1",
+					"kind": "ConfigMap",
+					"metadata": {"name": "$(workload.metadata.name)$"},
+					"data": {"host": "$(workload.metadata.name)$.$(cluster.data.ingressDomain)$"}
+				}`)}
+			})
+
+			It("renders the sample with the cluster data", func() {
+				scheme := runtime.NewScheme()
+				Expect(corev1.AddToScheme(scheme)).To(Succeed())
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster-data", Namespace: "cartographer-system"},
+					Data:       map[string]string{"ingressDomain": "apps.example.com"},
+				}
+				reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+				validator.AddClusterData(clusterdata.NewReader(reader, "prod", "cluster.local", types.NamespacedName{Namespace: "cartographer-system", Name: "cluster-data"}, 0))
+
+				Expect(validator.ValidateCreate(ctx, template)).To(Succeed())
+			})
+
+			It("rejects the template when no cluster data was added", func() {
+				err := validator.ValidateCreate(ctx, template)
+				Expect(err).To(MatchError(ContainSubstring("invalid template: failed to render sample")))
+			})
+		})
+
+		Context("the sample workload is malformed", func() {
+			BeforeEach(func() {
+				template.Spec.Sample.Workload = &runtime.RawExtension{Raw: []byte(`{"spec": "not-an-object"}`)}
+			})
+
+			It("rejects the template", func() {
+				err := validator.ValidateCreate(ctx, template)
+				Expect(err).To(MatchError(ContainSubstring("invalid sample: unmarshal workload")))
+			})
+		})
+	})
+
+	Context("deployment template embeds a sample workload", func() {
+		var deploymentTemplate *v1alpha1.ClusterDeploymentTemplate
+
+		BeforeEach(func() {
+			deploymentTemplate = &v1alpha1.ClusterDeploymentTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-deployment-template"},
+				Spec: v1alpha1.TemplateSpec{
+					Template: &runtime.RawExtension{Raw: []byte(`{
+						"apiVersion": "v1",
+						"kind": "ConfigMap",
+						"metadata": {"name": "$(workload.metadata.name)$"},
+						"data": {"url": "$(sources.code.url)$"}
+					}`)},
+					Sample: &v1alpha1.TemplateSample{
+						Workload: &runtime.RawExtension{Raw: []byte(`{"metadata": {"name": "sample-workload"}}`)},
+						Sources:  []v1alpha1.SampleSource{{Name: "code", URL: "https://example.com/code.tar.gz"}},
+					},
+				},
+			}
+		})
+
+		It("succeeds when the sample renders", func() {
+			Expect(validator.ValidateCreate(ctx, deploymentTemplate)).To(Succeed())
+		})
+
+		It("rejects the template when the sample does not render", func() {
+			deploymentTemplate.Spec.Sample.Sources = nil
+
+			err := validator.ValidateUpdate(ctx, deploymentTemplate.DeepCopy(), deploymentTemplate)
+			Expect(err).To(MatchError(ContainSubstring("invalid template: failed to render sample")))
+		})
+	})
+
+	Context("template references a sample workload", func() {
+		BeforeEach(func() {
+			template.Spec.Sample = &v1alpha1.TemplateSample{
+				WorkloadRef: &v1alpha1.SampleWorkloadReference{
+					Name:      "existing-workload",
+					Namespace: "some-namespace",
+				},
+				Sources: []v1alpha1.SampleSource{
+					{Name: "code", URL: "https://example.com/code.tar.gz"},
+				},
+			}
+		})
+
+		It("renders the template with the referenced workload", func() {
+			Expect(validator.ValidateCreate(ctx, template)).To(Succeed())
+		})
+
+		Context("the referenced workload does not exist", func() {
+			BeforeEach(func() {
+				template.Spec.Sample.WorkloadRef.Name = "missing-workload"
+			})
+
+			It("rejects the template", func() {
+				err := validator.ValidateCreate(ctx, template)
+				Expect(err).To(MatchError(ContainSubstring("invalid sample: get workload 'some-namespace/missing-workload'")))
+			})
+		})
+	})
+
+	Context("sample sets both an embedded and a referenced workload", func() {
+		BeforeEach(func() {
+			template.Spec.Sample = &v1alpha1.TemplateSample{
+				Workload:    &runtime.RawExtension{Raw: []byte(`{}`)},
+				WorkloadRef: &v1alpha1.SampleWorkloadReference{Name: "a", Namespace: "b"},
+			}
+		})
+
+		It("rejects the template", func() {
+			err := validator.ValidateCreate(ctx, template)
+			Expect(err).To(MatchError("invalid sample: must specify one of workload or workloadRef, found both"))
+		})
+	})
+
+	Describe("ValidateDelete", func() {
+		It("succeeds", func() {
+			Expect(validator.ValidateDelete(ctx, template)).To(Succeed())
+		})
+	})
+})
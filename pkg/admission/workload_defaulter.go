@@ -0,0 +1,128 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	cradmission "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// ConfigMap keys read by the WorkloadDefaulter.
+const (
+	ServiceAccountNameDefaultsKey = "serviceAccountName"
+	LabelsDefaultsKey             = "labels"
+	ParamsDefaultsKey             = "params"
+)
+
+// WorkloadDefaults are filled in on workloads that do not set them.
+type WorkloadDefaults struct {
+	ServiceAccountName string
+	Labels             map[string]string
+	Params             []v1alpha1.Param
+}
+
+// Apply fills in each default the workload does not already set. Labels and
+// params the workload sets are left as they are.
+func (d WorkloadDefaults) Apply(workload *v1alpha1.Workload) {
+	if workload.Spec.ServiceAccountName == "" {
+		workload.Spec.ServiceAccountName = d.ServiceAccountName
+	}
+
+	for key, value := range d.Labels {
+		if _, ok := workload.Labels[key]; ok {
+			continue
+		}
+		if workload.Labels == nil {
+			workload.Labels = map[string]string{}
+		}
+		workload.Labels[key] = value
+	}
+
+	set := map[string]bool{}
+	for _, param := range workload.Spec.Params {
+		set[param.Name] = true
+	}
+	for _, param := range d.Params {
+		if !set[param.Name] {
+			workload.Spec.Params = append(workload.Spec.Params, param)
+		}
+	}
+}
+
+// WorkloadDefaulter fills in workload defaults from a cluster-level
+// ConfigMap as workloads are created. Workloads are admitted unchanged while
+// the ConfigMap is absent.
+type WorkloadDefaulter struct {
+	reader    client.Reader
+	configMap types.NamespacedName
+}
+
+var _ cradmission.CustomDefaulter = &WorkloadDefaulter{}
+
+func NewWorkloadDefaulter(reader client.Reader, configMap types.NamespacedName) *WorkloadDefaulter {
+	return &WorkloadDefaulter{reader: reader, configMap: configMap}
+}
+
+func (d *WorkloadDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	workload, ok := obj.(*v1alpha1.Workload)
+	if !ok {
+		return fmt.Errorf("unexpected object of type %T", obj)
+	}
+
+	defaults, err := d.defaults(ctx)
+	if err != nil {
+		return err
+	}
+	if defaults != nil {
+		defaults.Apply(workload)
+	}
+	return nil
+}
+
+func (d *WorkloadDefaulter) defaults(ctx context.Context) (*WorkloadDefaults, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := d.reader.Get(ctx, d.configMap, configMap); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get workload defaults '%s': %w", d.configMap, err)
+	}
+
+	defaults := &WorkloadDefaults{
+		ServiceAccountName: configMap.Data[ServiceAccountNameDefaultsKey],
+	}
+	if labels, ok := configMap.Data[LabelsDefaultsKey]; ok {
+		if err := yaml.Unmarshal([]byte(labels), &defaults.Labels); err != nil {
+			return nil, fmt.Errorf("invalid workload defaults '%s': unmarshal %s: %w", d.configMap, LabelsDefaultsKey, err)
+		}
+	}
+	if params, ok := configMap.Data[ParamsDefaultsKey]; ok {
+		if err := yaml.Unmarshal([]byte(params), &defaults.Params); err != nil {
+			return nil, fmt.Errorf("invalid workload defaults '%s': unmarshal %s: %w", d.configMap, ParamsDefaultsKey, err)
+		}
+	}
+
+	return defaults, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/admission"
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("WorkloadDefaulter", func() {
+	var (
+		ctx         context.Context
+		scheme      *runtime.Scheme
+		objects     []client.Object
+		configMapNN types.NamespacedName
+		workload    *v1alpha1.Workload
+		defaulter   *admission.WorkloadDefaulter
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		configMapNN = types.NamespacedName{Namespace: "cartographer-system", Name: "workload-defaults"}
+		objects = nil
+
+		workload = &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-workload",
+				Namespace: "some-namespace",
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		defaulter = admission.NewWorkloadDefaulter(reader, configMapNN)
+	})
+
+	Context("the defaults ConfigMap does not exist", func() {
+		It("leaves the workload unchanged", func() {
+			original := workload.DeepCopy()
+			Expect(defaulter.Default(ctx, workload)).To(Succeed())
+			Expect(workload).To(Equal(original))
+		})
+	})
+
+	Context("the defaults ConfigMap exists", func() {
+		BeforeEach(func() {
+			objects = append(objects, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      configMapNN.Name,
+					Namespace: configMapNN.Namespace,
+				},
+				Data: map[string]string{
+					"serviceAccountName": "default-service-account",
+					"labels":             "app.tanzu.vmware.com/workload-type: web\nteam: platform\n",
+					"params":             "- name: java-version\n  value: 11\n- name: debug\n  value: false\n",
+				},
+			})
+		})
+
+		It("fills in the defaults on a minimal workload", func() {
+			Expect(defaulter.Default(ctx, workload)).To(Succeed())
+
+			Expect(workload.Spec.ServiceAccountName).To(Equal("default-service-account"))
+			Expect(workload.Labels).To(Equal(map[string]string{
+				"app.tanzu.vmware.com/workload-type": "web",
+				"team":                               "platform",
+			}))
+			Expect(workload.Spec.Params).To(ConsistOf(
+				v1alpha1.Param{Name: "java-version", Value: apiextensionsv1.JSON{Raw: []byte(`11`)}},
+				v1alpha1.Param{Name: "debug", Value: apiextensionsv1.JSON{Raw: []byte(`false`)}},
+			))
+		})
+
+		It("keeps values the workload already sets", func() {
+			workload.Spec.ServiceAccountName = "my-service-account"
+			workload.Labels = map[string]string{"team": "payments"}
+			workload.Spec.Params = []v1alpha1.Param{
+				{Name: "debug", Value: apiextensionsv1.JSON{Raw: []byte(`true`)}},
+			}
+
+			Expect(defaulter.Default(ctx, workload)).To(Succeed())
+
+			Expect(workload.Spec.ServiceAccountName).To(Equal("my-service-account"))
+			Expect(workload.Labels).To(Equal(map[string]string{
+				"app.tanzu.vmware.com/workload-type": "web",
+				"team":                               "payments",
+			}))
+			Expect(workload.Spec.Params).To(ConsistOf(
+				v1alpha1.Param{Name: "debug", Value: apiextensionsv1.JSON{Raw: []byte(`true`)}},
+				v1alpha1.Param{Name: "java-version", Value: apiextensionsv1.JSON{Raw: []byte(`11`)}},
+			))
+		})
+	})
+
+	Context("the defaults ConfigMap is malformed", func() {
+		BeforeEach(func() {
+			objects = append(objects, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      configMapNN.Name,
+					Namespace: configMapNN.Namespace,
+				},
+				Data: map[string]string{
+					"labels": "- not-a-map",
+				},
+			})
+		})
+
+		It("returns an error", func() {
+			err := defaulter.Default(ctx, workload)
+			Expect(err).To(MatchError(ContainSubstring("invalid workload defaults 'cartographer-system/workload-defaults': unmarshal labels")))
+		})
+	})
+
+	Context("the object is not a workload", func() {
+		It("returns an error", func() {
+			err := defaulter.Default(ctx, &v1alpha1.Deliverable{})
+			Expect(err).To(MatchError("unexpected object of type *v1alpha1.Deliverable"))
+		})
+	})
+})
@@ -0,0 +1,121 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	cradmission "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// WorkloadValidator rejects workloads that do not meet the requirements of
+// the supply chain that realizes them. Workloads matching no supply chain,
+// or several, are admitted and left for the controller to report.
+type WorkloadValidator struct {
+	reader client.Reader
+}
+
+var _ cradmission.CustomValidator = &WorkloadValidator{}
+
+// NewWorkloadValidator returns a WorkloadValidator that reads supply chains
+// through reader.
+func NewWorkloadValidator(reader client.Reader) *WorkloadValidator {
+	return &WorkloadValidator{reader: reader}
+}
+
+func (v *WorkloadValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return v.validate(ctx, obj)
+}
+
+func (v *WorkloadValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) error {
+	return v.validate(ctx, newObj)
+}
+
+func (v *WorkloadValidator) ValidateDelete(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+func (v *WorkloadValidator) validate(ctx context.Context, obj runtime.Object) error {
+	workload, ok := obj.(*v1alpha1.Workload)
+	if !ok {
+		return fmt.Errorf("unexpected object of type %T", obj)
+	}
+	if len(workload.Labels) == 0 {
+		return nil
+	}
+
+	supplyChains, err := v.matchingSupplyChains(ctx, workload)
+	if err != nil {
+		return err
+	}
+	if len(supplyChains) != 1 {
+		return nil
+	}
+
+	unmet, err := supplyChains[0].GetSpec().Requires.Unmet(workload)
+	if err != nil {
+		return err
+	}
+	if len(unmet) > 0 {
+		return fmt.Errorf("workload does not meet the requirements of supply chain '%s': %s", supplyChains[0].GetName(), strings.Join(unmet, "; "))
+	}
+	return nil
+}
+
+// matchingSupplyChains returns the supply chains whose selector is satisfied
+// by the workload, as the controller chooses them: the SupplyChains in its
+// namespace when there are any, otherwise the ClusterSupplyChains.
+func (v *WorkloadValidator) matchingSupplyChains(ctx context.Context, workload *v1alpha1.Workload) ([]v1alpha1.SupplyChainObject, error) {
+	var supplyChains []v1alpha1.SupplyChainObject
+
+	namespaced := &v1alpha1.SupplyChainList{}
+	if err := v.reader.List(ctx, namespaced, client.InNamespace(workload.Namespace)); err != nil {
+		return nil, fmt.Errorf("list namespaced supply chains: %w", err)
+	}
+	for i := range namespaced.Items {
+		if selectorMatchesLabels(namespaced.Items[i].Spec.Selector, workload.Labels) {
+			supplyChains = append(supplyChains, &namespaced.Items[i])
+		}
+	}
+	if len(supplyChains) > 0 {
+		return supplyChains, nil
+	}
+
+	clusterSupplyChains := &v1alpha1.ClusterSupplyChainList{}
+	if err := v.reader.List(ctx, clusterSupplyChains); err != nil {
+		return nil, fmt.Errorf("list supply chains: %w", err)
+	}
+	for i := range clusterSupplyChains.Items {
+		if selectorMatchesLabels(clusterSupplyChains.Items[i].Spec.Selector, workload.Labels) {
+			supplyChains = append(supplyChains, &clusterSupplyChains.Items[i])
+		}
+	}
+	return supplyChains, nil
+}
+
+func selectorMatchesLabels(selector map[string]string, labels map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/admission"
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("WorkloadValidator", func() {
+	var (
+		ctx       context.Context
+		scheme    *runtime.Scheme
+		objects   []client.Object
+		workload  *v1alpha1.Workload
+		validator *admission.WorkloadValidator
+		contract  *v1alpha1.WorkloadContract
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme = runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		contract = &v1alpha1.WorkloadContract{
+			Fields: []v1alpha1.FieldSelectorRequirement{{Key: "spec.source.git", Operator: v1alpha1.FieldSelectorOpExists}},
+		}
+		objects = []client.Object{
+			&v1alpha1.ClusterSupplyChain{
+				ObjectMeta: metav1.ObjectMeta{Name: "source-to-url"},
+				Spec: v1alpha1.SupplyChainSpec{
+					Selector: map[string]string{"apps.example.com/type": "web"},
+					Requires: contract,
+				},
+			},
+		}
+
+		workload = &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-workload",
+				Namespace: "some-namespace",
+				Labels:    map[string]string{"apps.example.com/type": "web"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		validator = admission.NewWorkloadValidator(reader)
+	})
+
+	It("rejects a workload that does not meet the requirements of its supply chain", func() {
+		Expect(validator.ValidateCreate(ctx, workload)).
+			To(MatchError("workload does not meet the requirements of supply chain 'source-to-url': field spec.source.git is required"))
+		Expect(validator.ValidateUpdate(ctx, workload, workload)).To(HaveOccurred())
+	})
+
+	It("admits a workload that meets them", func() {
+		url := "https://github.com/example/app"
+		workload.Spec.Source = &v1alpha1.Source{Git: &v1alpha1.GitSource{URL: &url}}
+
+		Expect(validator.ValidateCreate(ctx, workload)).To(Succeed())
+	})
+
+	It("admits a workload no supply chain selects", func() {
+		workload.Labels = map[string]string{"apps.example.com/type": "worker"}
+
+		Expect(validator.ValidateCreate(ctx, workload)).To(Succeed())
+	})
+
+	Context("a SupplyChain in the workload's namespace selects it too", func() {
+		BeforeEach(func() {
+			objects = append(objects, &v1alpha1.SupplyChain{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-web", Namespace: "some-namespace"},
+				Spec: v1alpha1.SupplyChainSpec{
+					Selector: map[string]string{"apps.example.com/type": "web"},
+				},
+			})
+		})
+
+		It("checks the workload against the SupplyChain, which realizes it", func() {
+			Expect(validator.ValidateCreate(ctx, workload)).To(Succeed())
+		})
+	})
+
+	Context("several supply chains select the workload", func() {
+		BeforeEach(func() {
+			objects = append(objects, &v1alpha1.ClusterSupplyChain{
+				ObjectMeta: metav1.ObjectMeta{Name: "another"},
+				Spec: v1alpha1.SupplyChainSpec{
+					Selector: map[string]string{"apps.example.com/type": "web"},
+					Requires: contract,
+				},
+			})
+		})
+
+		It("admits the workload, leaving the controller to report the ambiguity", func() {
+			Expect(validator.ValidateCreate(ctx, workload)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,182 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha1
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	BlueprintReady            = "Ready"
+	BlueprintObjectsApplied   = "ObjectsApplied"
+	BlueprintSupplyChainReady = "SupplyChainReady"
+)
+
+// The ObjectsApplied condition otherwise shares the reasons of the
+// ClusterBlueprintSource's.
+const (
+	InvalidTemplateObjectsAppliedReason = "InvalidTemplate"
+	ReadySupplyChainReadyReason         = "Ready"
+	NotReadySupplyChainReadyReason      = "NotReady"
+	PendingSupplyChainReadyReason       = "Pending"
+)
+
+// BlueprintLabel is set, on the supply chain and templates applied for a
+// ClusterBlueprint, to the blueprint's name.
+const BlueprintLabel = "carto.run/blueprint"
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=".spec.version"
+// +kubebuilder:printcolumn:name="Applied",type=string,JSONPath=".status.version"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// ClusterBlueprint bundles a supply chain with the templates it references
+// as one versioned unit. Changing the version rolls workloads onto the new
+// set of templates, or back onto an earlier one, in a single step.
+type ClusterBlueprint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ClusterBlueprintSpec   `json:"spec"`
+	Status            ClusterBlueprintStatus `json:"status,omitempty"`
+}
+
+var _ webhook.Validator = &ClusterBlueprint{}
+
+type ClusterBlueprintSpec struct {
+	// Version of the bundle, such as v3. The templates are applied under
+	// this version, and the supply chain is only switched to them once
+	// they all are.
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+
+	// SupplyChain is applied as a ClusterSupplyChain named after the
+	// blueprint. Its templateRefs refer to the bundled templates by name,
+	// or to templates in git.
+	SupplyChain SupplyChainSpec `json:"supplyChain"`
+
+	// Templates bundled with the supply chain.
+	// +optional
+	Templates []BlueprintTemplate `json:"templates,omitempty"`
+}
+
+type BlueprintTemplate struct {
+	// +kubebuilder:validation:Enum=ClusterSourceTemplate;ClusterImageTemplate;ClusterTemplate;ClusterConfigTemplate
+	Kind string `json:"kind"`
+	// Name the supply chain's templateRefs refer to the template by.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Spec of the template, as in a template of its kind.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Spec runtime.RawExtension `json:"spec"`
+}
+
+type ClusterBlueprintStatus struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+
+	// Version the supply chain was last switched to.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Objects applied for the version. Objects of earlier versions are
+	// deleted once the supply chain no longer refers to them.
+	// +optional
+	Objects []ObjectReference `json:"objects,omitempty"`
+}
+
+// TemplateName is the name the blueprint's templates of that name are
+// labelled with, and the supply chain refers to them by.
+func (b *ClusterBlueprint) TemplateName(name string) string {
+	return b.Name + "-" + name
+}
+
+func (b *ClusterBlueprint) ValidateCreate() error {
+	return b.validateNewState()
+}
+
+func (b *ClusterBlueprint) ValidateUpdate(_ runtime.Object) error {
+	return b.validateNewState()
+}
+
+func (b *ClusterBlueprint) ValidateDelete() error {
+	return nil
+}
+
+func (b *ClusterBlueprint) validateNewState() error {
+	if errs := validation.IsDNS1123Label(b.Spec.Version); len(errs) > 0 {
+		return fmt.Errorf("invalid version '%s': %s", b.Spec.Version, strings.Join(errs, ", "))
+	}
+
+	bundled := map[string]bool{}
+	for _, template := range b.Spec.Templates {
+		key := template.Kind + "/" + template.Name
+		if bundled[key] {
+			return fmt.Errorf("duplicate template %s '%s' found in clusterblueprint '%s'", template.Kind, template.Name, b.Name)
+		}
+		bundled[key] = true
+	}
+
+	if err := b.Spec.SupplyChain.validate("clusterblueprint", b.Name); err != nil {
+		return err
+	}
+	if err := b.Spec.SupplyChain.validateSecretRefs(""); err != nil {
+		return err
+	}
+
+	for _, resource := range b.Spec.SupplyChain.Resources {
+		ref := resource.TemplateRef
+		if ref.Version != "" {
+			return fmt.Errorf("invalid templateRef for resource '%s': version cannot be set, the blueprint's version is used", resource.Name)
+		}
+		if ref.Git != nil {
+			continue
+		}
+		for _, choice := range ref.Choices() {
+			if !bundled[choice.Kind+"/"+choice.Name] {
+				return fmt.Errorf("invalid templateRef for resource '%s': %s '%s' is not bundled in the blueprint", resource.Name, choice.Kind, choice.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// +kubebuilder:object:root=true
+
+type ClusterBlueprintList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterBlueprint `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ClusterBlueprint{},
+		&ClusterBlueprintList{},
+	)
+}
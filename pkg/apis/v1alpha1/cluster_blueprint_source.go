@@ -0,0 +1,130 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha1
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	BlueprintSourceReady          = "Ready"
+	BlueprintSourceArtifactPulled = "ArtifactPulled"
+	BlueprintSourceObjectsApplied = "ObjectsApplied"
+)
+
+const (
+	PulledArtifactPulledReason      = "Pulled"
+	PullFailedArtifactPulledReason  = "PullFailed"
+	AppliedObjectsAppliedReason     = "Applied"
+	InvalidObjectsAppliedReason     = "InvalidArtifact"
+	ApplyFailedObjectsAppliedReason = "ApplyFailed"
+)
+
+const defaultBlueprintSourceInterval = 5 * time.Minute
+
+// BlueprintSourceLabel is set, on the objects unpacked from a
+// ClusterBlueprintSource, to the source's name.
+const BlueprintSourceLabel = "carto.run/blueprint-source"
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Image",type=string,JSONPath=".spec.image"
+// +kubebuilder:printcolumn:name="Digest",type=string,JSONPath=".status.digest"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// ClusterBlueprintSource unpacks the supply chains, deliveries and templates
+// packaged in an OCI artifact into the cluster, and keeps them in step with
+// the artifact.
+type ClusterBlueprintSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ClusterBlueprintSourceSpec   `json:"spec"`
+	Status            ClusterBlueprintSourceStatus `json:"status,omitempty"`
+}
+
+type ClusterBlueprintSourceSpec struct {
+	// Image is the reference of the OCI artifact, such as
+	// registry.example.com/blueprints/web:1.2.0 or, to pin a version,
+	// registry.example.com/blueprints/web@sha256:<digest>.
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// Interval between polls of the registry for a new artifact behind a
+	// tag. Defaults to 5m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// SecretRef is a kubernetes.io/dockerconfigjson Secret holding the
+	// credentials to pull the artifact with. Pulls are anonymous without it.
+	// +optional
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+
+	// Insecure pulls the artifact over plain HTTP.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// SecretReference refers to a Secret in a namespace.
+type SecretReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+type ClusterBlueprintSourceStatus struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+
+	// Digest of the artifact last unpacked.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// Objects unpacked from the artifact. Objects no longer in the artifact
+	// are deleted.
+	// +optional
+	Objects []ObjectReference `json:"objects,omitempty"`
+}
+
+// GetInterval returns the interval between polls, defaulted.
+func (s *ClusterBlueprintSourceSpec) GetInterval() metav1.Duration {
+	if s.Interval == nil {
+		return metav1.Duration{Duration: defaultBlueprintSourceInterval}
+	}
+	return *s.Interval
+}
+
+// +kubebuilder:object:root=true
+
+type ClusterBlueprintSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterBlueprintSource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ClusterBlueprintSource{},
+		&ClusterBlueprintSourceList{},
+	)
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("ClusterBlueprint", func() {
+	var blueprint *v1alpha1.ClusterBlueprint
+
+	BeforeEach(func() {
+		blueprint = &v1alpha1.ClusterBlueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: "web"},
+			Spec: v1alpha1.ClusterBlueprintSpec{
+				Version: "v2",
+				SupplyChain: v1alpha1.SupplyChainSpec{
+					Selector: map[string]string{"workload-type": "web"},
+					Resources: []v1alpha1.SupplyChainResource{
+						{
+							Name:        "source",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterSourceTemplate", Name: "git"},
+						},
+						{
+							Name: "deploy",
+							TemplateRef: v1alpha1.ClusterTemplateReference{
+								Kind: "ClusterTemplate",
+								Git:  &v1alpha1.GitTemplateSource{URL: "https://example.com/templates.git", Ref: "main", Path: "deploy.yaml"},
+							},
+						},
+					},
+				},
+				Templates: []v1alpha1.BlueprintTemplate{
+					{Kind: "ClusterSourceTemplate", Name: "git", Spec: runtime.RawExtension{Raw: []byte(`{"urlPath":".url"}`)}},
+				},
+			},
+		}
+	})
+
+	It("is valid when its supply chain refers to bundled templates and templates in git", func() {
+		Expect(blueprint.ValidateCreate()).To(Succeed())
+		Expect(blueprint.ValidateUpdate(nil)).To(Succeed())
+	})
+
+	It("rejects a version that is not a DNS label", func() {
+		blueprint.Spec.Version = "V2.0"
+		Expect(blueprint.ValidateCreate()).To(MatchError(ContainSubstring("invalid version 'V2.0'")))
+	})
+
+	It("rejects templates bundled twice", func() {
+		blueprint.Spec.Templates = append(blueprint.Spec.Templates, blueprint.Spec.Templates[0])
+		Expect(blueprint.ValidateCreate()).To(MatchError("duplicate template ClusterSourceTemplate 'git' found in clusterblueprint 'web'"))
+	})
+
+	It("validates the supply chain", func() {
+		blueprint.Spec.SupplyChain.Resources[1].Name = "source"
+		Expect(blueprint.ValidateCreate()).To(MatchError("duplicate resource name 'source' found in clusterblueprint 'web'"))
+	})
+
+	It("rejects references to templates it does not bundle", func() {
+		blueprint.Spec.SupplyChain.Resources[0].TemplateRef.Kind = "ClusterImageTemplate"
+		Expect(blueprint.ValidateCreate()).To(MatchError("invalid templateRef for resource 'source': ClusterImageTemplate 'git' is not bundled in the blueprint"))
+	})
+
+	It("rejects options choosing templates it does not bundle", func() {
+		blueprint.Spec.SupplyChain.Resources[0].TemplateRef = v1alpha1.ClusterTemplateReference{
+			Kind: "ClusterSourceTemplate",
+			Options: []v1alpha1.TemplateOption{
+				{Name: "git", Selector: v1alpha1.OptionSelector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"source": "git"}}}},
+				{Name: "image", Selector: v1alpha1.OptionSelector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"source": "image"}}}},
+			},
+		}
+		Expect(blueprint.ValidateCreate()).To(MatchError("invalid templateRef for resource 'source': ClusterSourceTemplate 'image' is not bundled in the blueprint"))
+	})
+
+	It("rejects references pinning a version", func() {
+		blueprint.Spec.SupplyChain.Resources[0].TemplateRef.Version = "v1"
+		Expect(blueprint.ValidateCreate()).To(MatchError("invalid templateRef for resource 'source': version cannot be set, the blueprint's version is used"))
+	})
+})
@@ -19,6 +19,10 @@
 package v1alpha1
 
 import (
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -27,6 +31,7 @@ import (
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
 
 type ClusterConfigTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -37,7 +42,79 @@ type ClusterConfigTemplate struct {
 
 type ConfigTemplateSpec struct {
 	TemplateSpec `json:",inline"`
-	ConfigPath   string `json:"configPath"`
+
+	// ConfigPath is the path of the config. Either it or ConfigExpression
+	// must be set.
+	// +optional
+	ConfigPath string `json:"configPath,omitempty"`
+
+	// ConfigExpression is a CEL expression over the stamped object,
+	// available to it as `value`, that produces the config.
+	// +optional
+	ConfigExpression string `json:"configExpression,omitempty"`
+
+	// ConfigDefault is the config output while the stamped object has none
+	// to read yet.
+	// +optional
+	ConfigDefault *apiextensionsv1.JSON `json:"configDefault,omitempty"`
+
+	// OptionalOutputs are the outputs, of config, that resources consuming
+	// them are stamped without, as null, while the stamped object has not
+	// populated them. Other outputs hold those resources back until they
+	// are populated.
+	// +optional
+	OptionalOutputs []string `json:"optionalOutputs,omitempty"`
+
+	// SensitiveOutputs are the outputs, of config, whose values are
+	// redacted in the status, Events and logs of workloads and
+	// deliverables. Resources consuming them are stamped with the values.
+	// +optional
+	SensitiveOutputs []string `json:"sensitiveOutputs,omitempty"`
+
+	// Patches, when set, output a config input of the resource patched
+	// the way kustomize patches objects, in place of stamping an object.
+	// The template then has no template, ytt or output paths.
+	// +optional
+	Patches *ConfigPatches `json:"patches,omitempty"`
+}
+
+// ConfigPatches overlay the config of an upstream resource. Patches are
+// interpolated like templates, so they can carry params and workload
+// fields.
+type ConfigPatches struct {
+	// Config is the name of the config input that is patched. Defaults to
+	// the resource's only config input.
+	// +optional
+	Config string `json:"config,omitempty"`
+
+	// StrategicMerge patches are merged into the config, in order. Configs
+	// of Kubernetes' built-in kinds are merged the way kubectl merges
+	// them, others as JSON merge patches.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	StrategicMerge []runtime.RawExtension `json:"strategicMerge,omitempty"`
+
+	// JSON6902 operations are applied to the config, in order, after the
+	// strategic merge patches.
+	// +optional
+	JSON6902 []JSON6902Operation `json:"json6902,omitempty"`
+}
+
+// JSON6902Operation is an RFC 6902 JSON patch operation.
+type JSON6902Operation struct {
+	// +kubebuilder:validation:Enum=add;remove;replace;move;copy;test
+	Op string `json:"op"`
+
+	// Path is the JSON pointer the operation applies to.
+	Path string `json:"path"`
+
+	// From is the JSON pointer moved or copied from.
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// Value is added, replaced or tested at the path.
+	// +optional
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
 }
 
 type ConfigTemplateStatus struct {
@@ -46,17 +123,63 @@ type ConfigTemplateStatus struct {
 var _ webhook.Validator = &ClusterConfigTemplate{}
 
 func (c *ClusterConfigTemplate) ValidateCreate() error {
-	return c.Spec.TemplateSpec.validate()
+	return c.validate()
 }
 
 func (c *ClusterConfigTemplate) ValidateUpdate(_ runtime.Object) error {
-	return c.Spec.TemplateSpec.validate()
+	return c.validate()
 }
 
 func (c *ClusterConfigTemplate) ValidateDelete() error {
 	return nil
 }
 
+func (c *ClusterConfigTemplate) validate() error {
+	if c.Spec.Patches != nil {
+		return c.Spec.validatePatches()
+	}
+	if err := c.Spec.TemplateSpec.validate(); err != nil {
+		return err
+	}
+	if c.Spec.ConfigPath == "" && c.Spec.ConfigExpression == "" {
+		return fmt.Errorf("one of spec.configPath and spec.configExpression must be set")
+	}
+	if err := validateOutputPath("spec.configPath", c.Spec.ConfigPath); err != nil {
+		return err
+	}
+	if err := validateOutputExpression("spec.configExpression", c.Spec.ConfigExpression, "spec.configPath", c.Spec.ConfigPath); err != nil {
+		return err
+	}
+	if err := validateOutputNames("spec.optionalOutputs", c.Spec.OptionalOutputs, "config"); err != nil {
+		return err
+	}
+	return validateOutputNames("spec.sensitiveOutputs", c.Spec.SensitiveOutputs, "config")
+}
+
+func (s *ConfigTemplateSpec) validatePatches() error {
+	if s.Template != nil || s.Ytt != "" {
+		return fmt.Errorf("invalid spec.patches: a template with patches does not stamp an object, found template or ytt")
+	}
+	if s.ConfigPath != "" || s.ConfigExpression != "" || s.ConfigDefault != nil || len(s.OptionalOutputs) > 0 {
+		return fmt.Errorf("invalid spec.patches: a template with patches outputs the patched config, found config outputs")
+	}
+	if s.IsJob() || s.Preset != "" || s.Plugin != "" || s.Naming != nil || s.Sample != nil {
+		return fmt.Errorf("invalid spec.patches: a template with patches cannot have a lifecycle, preset, plugin, naming or sample")
+	}
+	if len(s.Patches.StrategicMerge) == 0 && len(s.Patches.JSON6902) == 0 {
+		return fmt.Errorf("invalid spec.patches: must specify at least one of strategicMerge or json6902")
+	}
+	for i, operation := range s.Patches.JSON6902 {
+		if !strings.HasPrefix(operation.Path, "/") {
+			return fmt.Errorf("invalid spec.patches.json6902[%d]: path '%s' is not a JSON pointer", i, operation.Path)
+		}
+		if (operation.Op == "move" || operation.Op == "copy") && operation.From == "" {
+			return fmt.Errorf("invalid spec.patches.json6902[%d]: %s must set from", i, operation.Op)
+		}
+	}
+	return nil
+}
+
 // +kubebuilder:object:root=true
 
 type ClusterConfigTemplateList struct {
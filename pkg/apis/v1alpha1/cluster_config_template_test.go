@@ -37,6 +37,9 @@ var _ = Describe("ClusterConfigTemplate", func() {
 					Name:      "some-template",
 					Namespace: "default",
 				},
+				Spec: v1alpha1.ConfigTemplateSpec{
+					ConfigPath: "data.config",
+				},
 			}
 		})
 
@@ -88,6 +91,143 @@ var _ = Describe("ClusterConfigTemplate", func() {
 						To(MatchError("invalid template: template should not set metadata.namespace on the child object"))
 				})
 			})
+
+			Context("output paths are valid jsonpath expressions", func() {
+				BeforeEach(func() {
+					raw, err := json.Marshal(&ArbitraryObject{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "some-kind",
+							APIVersion: "v1",
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "some-name",
+						},
+						Spec: ArbitrarySpec{
+							SomeKey: "some-val",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					template.Spec.Template = &runtime.RawExtension{Raw: raw}
+					template.Spec.ConfigPath = "data.config"
+				})
+
+				It("succeeds", func() {
+					Expect(template.ValidateCreate()).To(Succeed())
+				})
+			})
+
+			Context("an output path is not a valid jsonpath expression", func() {
+				BeforeEach(func() {
+					raw, err := json.Marshal(&ArbitraryObject{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "some-kind",
+							APIVersion: "v1",
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "some-name",
+						},
+						Spec: ArbitrarySpec{
+							SomeKey: "some-val",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					template.Spec.Template = &runtime.RawExtension{Raw: raw}
+					template.Spec.ConfigPath = "data["
+				})
+
+				It("returns an error", func() {
+					Expect(template.ValidateCreate()).
+						To(MatchError(ContainSubstring("invalid spec.configPath: jsonpath parse path '{.data[}'")))
+				})
+			})
+
+			Context("the config is read with an expression", func() {
+				BeforeEach(func() {
+					template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "some-kind", "metadata": {"name": "some-name"}}`)}
+					template.Spec.ConfigPath = ""
+					template.Spec.ConfigExpression = `value.data.host + ":" + value.data.port`
+				})
+
+				It("succeeds", func() {
+					Expect(template.ValidateCreate()).To(Succeed())
+				})
+
+				It("returns an error when the path is set too", func() {
+					template.Spec.ConfigPath = "data.config"
+					Expect(template.ValidateCreate()).
+						To(MatchError("only one of spec.configPath and spec.configExpression may be set"))
+				})
+
+				It("returns an error when the expression does not compile", func() {
+					template.Spec.ConfigExpression = "value.data.("
+					Expect(template.ValidateCreate()).
+						To(MatchError(ContainSubstring("invalid spec.configExpression: compile")))
+				})
+			})
+
+			Context("neither a path nor an expression reads the config", func() {
+				BeforeEach(func() {
+					template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "some-kind", "metadata": {"name": "some-name"}}`)}
+					template.Spec.ConfigPath = ""
+				})
+
+				It("returns an error", func() {
+					Expect(template.ValidateCreate()).
+						To(MatchError("one of spec.configPath and spec.configExpression must be set"))
+				})
+			})
+
+			Context("the template patches a config", func() {
+				BeforeEach(func() {
+					template.Spec.ConfigPath = ""
+					template.Spec.Patches = &v1alpha1.ConfigPatches{
+						StrategicMerge: []runtime.RawExtension{{Raw: []byte(`{"spec": {"replicas": 3}}`)}},
+						JSON6902: []v1alpha1.JSON6902Operation{
+							{Op: "remove", Path: "/metadata/annotations"},
+						},
+					}
+				})
+
+				It("succeeds", func() {
+					Expect(template.ValidateCreate()).To(Succeed())
+				})
+
+				It("returns an error when it also stamps an object", func() {
+					template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "some-kind", "metadata": {"name": "some-name"}}`)}
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid spec.patches: a template with patches does not stamp an object, found template or ytt"))
+				})
+
+				It("returns an error when it reads outputs", func() {
+					template.Spec.ConfigPath = "data.config"
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid spec.patches: a template with patches outputs the patched config, found config outputs"))
+				})
+
+				It("returns an error when it has a lifecycle", func() {
+					template.Spec.Lifecycle = v1alpha1.JobTemplateLifecycle
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid spec.patches: a template with patches cannot have a lifecycle, preset, plugin, naming or sample"))
+				})
+
+				It("returns an error when there are no patches", func() {
+					template.Spec.Patches = &v1alpha1.ConfigPatches{Config: "app"}
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid spec.patches: must specify at least one of strategicMerge or json6902"))
+				})
+
+				It("returns an error when an operation's path is not a JSON pointer", func() {
+					template.Spec.Patches.JSON6902[0].Path = "metadata.annotations"
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid spec.patches.json6902[0]: path 'metadata.annotations' is not a JSON pointer"))
+				})
+
+				It("returns an error when a move does not say where from", func() {
+					template.Spec.Patches.JSON6902[0] = v1alpha1.JSON6902Operation{Op: "move", Path: "/metadata/labels"}
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid spec.patches.json6902[0]: move must set from"))
+				})
+			})
 		})
 
 		Describe("#Update", func() {
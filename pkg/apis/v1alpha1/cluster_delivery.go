@@ -38,6 +38,9 @@ const (
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=".spec.description"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
 
 type ClusterDelivery struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -46,14 +49,171 @@ type ClusterDelivery struct {
 	Status            ClusterDeliveryStatus `json:"status,omitempty"`
 }
 
+func (c *ClusterDelivery) GetSpec() *ClusterDeliverySpec {
+	return &c.Spec
+}
+
+func (c *ClusterDelivery) GetStatus() *ClusterDeliveryStatus {
+	return &c.Status
+}
+
 type ClusterDeliverySpec struct {
+	// Description tells developers what the delivery does and which
+	// deliverables it is meant for.
+	// +optional
+	Description string `json:"description,omitempty"`
+
 	Resources []ClusterDeliveryResource `json:"resources"`
 	Selector  map[string]string         `json:"selector"`
+
+	// Scheduling hints applied to the objects stamped for every resource.
+	// +optional
+	Scheduling *SchedulingHints `json:"scheduling,omitempty"`
+
+	// Propagation of the deliverable's labels and annotations to the objects
+	// stamped for every resource. Defaults to propagating none.
+	// +optional
+	Propagation *MetadataPropagation `json:"propagation,omitempty"`
+
+	// Transforms are named expressions that resources' sources and configs
+	// can apply to the outputs they consume.
+	// +optional
+	Transforms []OutputTransform `json:"transforms,omitempty"`
+
+	// Teardown, when set, holds back the deletion of the blueprint until
+	// the objects stamped on its behalf, for every owner, are torn down:
+	// "Delete" deletes them, "Orphan" detaches them from their owners so
+	// they outlive them. Without it, the objects are left to their owners.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +optional
+	Teardown string `json:"teardown,omitempty"`
+
+	// Target, when set, is the cluster, or the clusters, the objects stamped
+	// for deliverables are applied to, in place of the deliverables' own.
+	// +optional
+	Target *DeliveryTarget `json:"target,omitempty"`
+
+	// PullRequest, when set, is the GitOps repository the objects stamped
+	// for resources with submission: PullRequest are proposed to, in a pull
+	// request per deliverable, rather than applied.
+	// +optional
+	PullRequest *PullRequestTarget `json:"pullRequest,omitempty"`
+
+	// PreDelete are hooks run, in order, when a deliverable realized with
+	// the delivery is deleted. The deliverable is only deleted once each
+	// has completed. Their Jobs are stamped in the deliverable's namespace,
+	// whatever the target.
+	// +optional
+	PreDelete []PreDeleteHook `json:"preDelete,omitempty"`
+}
+
+// DeliveryTarget is the remote clusters a delivery applies objects to.
+// Exactly one of kubeconfigSecretRef and clusters must be set.
+type DeliveryTarget struct {
+	// KubeconfigSecretRef is the Secret holding a kubeconfig for the
+	// cluster, such as the one Cluster API writes for the clusters it
+	// provisions.
+	// +optional
+	KubeconfigSecretRef *KubeconfigSecretReference `json:"kubeconfigSecretRef,omitempty"`
+
+	// Clusters selects Cluster API Clusters by label: every deliverable is
+	// delivered to each of the matching clusters, through the kubeconfig
+	// Cluster API writes for it.
+	// +optional
+	Clusters *ClusterSelector `json:"clusters,omitempty"`
+}
+
+// ClusterSelector selects Cluster API Clusters.
+type ClusterSelector struct {
+	// Namespace of the Clusters. Required by a ClusterDelivery; a Delivery
+	// always selects Clusters in its own namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Selector matches the labels of the Clusters.
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// KubeconfigSecretReference locates a kubeconfig in a Secret.
+type KubeconfigSecretReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Secret. Required by a ClusterDelivery; a Delivery
+	// always reads the Secret from its own namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key of the kubeconfig in the Secret's data. Defaults to "value".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+const (
+	GitHubPullRequestProvider = "GitHub"
+	GitLabPullRequestProvider = "GitLab"
+)
+
+// PullRequestTarget is the GitOps repository a delivery proposes objects
+// to, in pull requests, or merge requests on GitLab.
+type PullRequestTarget struct {
+	// Provider hosts the repository.
+	// +kubebuilder:validation:Enum=GitHub;GitLab
+	Provider string `json:"provider"`
+
+	// APIURL is the provider's API, for self-hosted installations. Defaults
+	// to https://api.github.com or https://gitlab.com/api/v4.
+	// +optional
+	APIURL string `json:"apiURL,omitempty"`
+
+	// Repository is the repository, as owner/name on GitHub or as the
+	// project's path on GitLab.
+	// +kubebuilder:validation:MinLength=1
+	Repository string `json:"repository"`
+
+	// Branch the pull requests merge into. Defaults to "main".
+	// +optional
+	Branch string `json:"branch,omitempty"`
+
+	// Path is the directory the manifests of each deliverable are written
+	// to, as <namespace>/<name>.yaml. Defaults to the repository's root.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// TokenSecretRef is the Secret holding the token the provider's API is
+	// called with.
+	TokenSecretRef TokenSecretReference `json:"tokenSecretRef"`
+}
+
+// TokenSecretReference locates an API token in a Secret.
+type TokenSecretReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Secret. Required by a ClusterDelivery; a Delivery
+	// always reads the Secret from its own namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key of the token in the Secret's data. Defaults to "token".
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 type ClusterDeliveryStatus struct {
 	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
 	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+
+	// Resources tell, resource by resource, which templates were not found.
+	// +optional
+	// +listType=map
+	// +listMapKey=resource
+	Resources []ResourceTemplatesStatus `json:"resources,omitempty"`
+
+	// Rollout counts the deliverables realized with the delivery by how
+	// far they have converged on its current generation.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
 }
 
 type ClusterDeliveryResource struct {
@@ -62,13 +222,117 @@ type ClusterDeliveryResource struct {
 	Params      []Param                          `json:"params,omitempty"`
 	Sources     []ResourceReference              `json:"sources,omitempty"`
 	Configs     []ResourceReference              `json:"configs,omitempty"`
+
+	// Description tells developers what the resource contributes to the
+	// delivery.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// ServiceAccountName is the service account, in the owner's namespace,
+	// that Cartographer impersonates when creating and updating the object
+	// stamped for this resource. Defaults to Cartographer's own identity.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Scheduling hints for this resource's object, merged over the
+	// blueprint's.
+	// +optional
+	Scheduling *SchedulingHints `json:"scheduling,omitempty"`
+
+	// Propagation for this resource's object: the labels and annotations
+	// policies it sets replace the blueprint's.
+	// +optional
+	Propagation *MetadataPropagation `json:"propagation,omitempty"`
+
+	// Publish copies values from the object stamped for this resource, or
+	// from the results of its job, into the deliverable's status.outputs,
+	// where other systems can read them.
+	// +optional
+	Publish []PublishedOutput `json:"publish,omitempty"`
+
+	// Drift is what Cartographer does when this resource's object is
+	// changed by something else: "remediate", the default, stamps it
+	// again; "detect" leaves it, reporting it in the Drifted condition.
+	// +kubebuilder:validation:Enum=remediate;detect
+	// +optional
+	Drift string `json:"drift,omitempty"`
+
+	// DryRun, when true, submits this resource's objects to the apiserver
+	// as a server-side dry run before creating or updating them. Objects
+	// the apiserver, or its admission webhooks, reject are then never
+	// written, and are reported with the StampedObjectRejected reason.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// DeletionPolicy is what happens to this resource's object when the
+	// deliverable is deleted: "Delete" deletes it along with the deliverable; "Orphan"
+	// leaves it behind, stamped without an owner reference, for instance to
+	// be inspected after the fact. Defaults to the deliverable's deletionPolicy.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// Ownership is how this resource's object is tied to the deliverable:
+	// "OwnerReference", the default, by an owner reference, which leaves
+	// its deletion to the garbage collector; "Tracked" by its labels alone,
+	// Cartographer deleting it once the deliverable is gone. Objects that cannot
+	// be owned, such as cluster-scoped ones, need the latter.
+	// +kubebuilder:validation:Enum=OwnerReference;Tracked
+	// +optional
+	Ownership string `json:"ownership,omitempty"`
+
+	// Submission is how this resource's object reaches a cluster: "Apply",
+	// the default, applies it; "PullRequest" proposes it to the delivery's
+	// pullRequest repository instead, for a GitOps tool to apply once the
+	// pull request is merged. Outputs are then read from the object as
+	// stamped.
+	// +kubebuilder:validation:Enum=Apply;PullRequest
+	// +optional
+	Submission string `json:"submission,omitempty"`
+}
+
+// PublishedOutput is a value a delivery resource publishes to the
+// deliverable's status.
+type PublishedOutput struct {
+	// Name the value is published under. Unique across the delivery.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Path is a jsonpath expression to the value in the stamped object.
+	// Nothing is published until a value is found there.
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+	// Sensitive values are published redacted.
+	// +optional
+	Sensitive bool `json:"sensitive,omitempty"`
 }
 
 type DeliveryClusterTemplateReference struct {
 	// +kubebuilder:validation:Enum=ClusterSourceTemplate;ClusterDeploymentTemplate;ClusterTemplate
 	Kind string `json:"kind"`
-	// +kubebuilder:validation:MinLength=1
-	Name string `json:"name"`
+	// Name of the template on the cluster. Exactly one of name and git must
+	// be set.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Version pins the template to one of its versions: the template of
+	// the same kind labelled carto.run/template-name with name and
+	// carto.run/template-version with version. Only valid with name.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Git fetches the template from a git repository instead.
+	// +optional
+	Git *GitTemplateSource `json:"git,omitempty"`
+}
+
+// DisplayName names the template in messages: its name and the version it
+// is pinned to, or where it is fetched from.
+func (r DeliveryClusterTemplateReference) DisplayName() string {
+	if r.Git != nil {
+		return r.Git.String()
+	}
+	if r.Version != "" {
+		return r.Name + "@" + r.Version
+	}
+	return r.Name
 }
 
 // +kubebuilder:object:root=true
@@ -80,25 +344,100 @@ type ClusterDeliveryList struct {
 }
 
 func (c *ClusterDelivery) ValidateCreate() error {
-	return validateNewState(c)
+	return c.Spec.validateIn("")
 }
 
 func (c *ClusterDelivery) ValidateUpdate(_ runtime.Object) error {
-	return validateNewState(c)
+	return c.Spec.validateIn("")
 }
 
 func (c *ClusterDelivery) ValidateDelete() error {
 	return nil
 }
 
-func validateNewState(c *ClusterDelivery) error {
+// validateIn validates the spec of a delivery in namespace, empty when it is
+// cluster-scoped.
+func (s *ClusterDeliverySpec) validateIn(namespace string) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	for idx, resource := range s.Resources {
+		if err := resource.TemplateRef.Git.validateSecretRef(namespace); err != nil {
+			return fmt.Errorf("spec.resources[%d].templateRef is invalid: %w", idx, err)
+		}
+	}
+	return nil
+}
+
+func (s *ClusterDeliverySpec) validate() error {
 	names := map[string]bool{}
+	published := map[string]bool{}
 
-	for idx, resource := range c.Spec.Resources {
+	for idx, resource := range s.Resources {
 		if names[resource.Name] {
 			return fmt.Errorf("spec.resources[%d].name \"%s\" cannot appear twice", idx, resource.Name)
 		}
 		names[resource.Name] = true
+
+		if err := validateResourceParams(resource.Params); err != nil {
+			return fmt.Errorf("spec.resources[%d] \"%s\" has invalid params: %w", idx, resource.Name, err)
+		}
+
+		if err := validateTransforms(s.Transforms, resource.Sources, resource.Configs); err != nil {
+			return fmt.Errorf("spec.resources[%d] \"%s\" has invalid transforms: %w", idx, resource.Name, err)
+		}
+
+		if err := resource.Propagation.validate(); err != nil {
+			return fmt.Errorf("spec.resources[%d].propagation is invalid: %w", idx, err)
+		}
+
+		for publishIdx, output := range resource.Publish {
+			if published[output.Name] {
+				return fmt.Errorf("spec.resources[%d].publish[%d].name \"%s\" cannot be published twice", idx, publishIdx, output.Name)
+			}
+			published[output.Name] = true
+
+			if err := validateOutputPath(fmt.Sprintf("spec.resources[%d].publish[%d].path", idx, publishIdx), output.Path); err != nil {
+				return err
+			}
+		}
+	}
+
+	for idx, resource := range s.Resources {
+		if err := validateTemplateRef(resource.TemplateRef.Name, resource.TemplateRef.Version, resource.TemplateRef.Git); err != nil {
+			return fmt.Errorf("spec.resources[%d].templateRef is invalid: %w", idx, err)
+		}
+	}
+
+	if err := s.Propagation.validate(); err != nil {
+		return fmt.Errorf("spec.propagation is invalid: %w", err)
+	}
+
+	if err := validatePreDeleteHooks(s.PreDelete); err != nil {
+		return fmt.Errorf("spec.preDelete is invalid: %w", err)
+	}
+
+	for idx, resource := range s.Resources {
+		if resource.Submission == PullRequestSubmission && s.PullRequest == nil {
+			return fmt.Errorf("spec.resources[%d] \"%s\" is submitted as a pull request, but spec.pullRequest is not set", idx, resource.Name)
+		}
+	}
+
+	if s.Target != nil {
+		return s.Target.validate()
+	}
+	return nil
+}
+
+func (t *DeliveryTarget) validate() error {
+	if (t.KubeconfigSecretRef == nil) == (t.Clusters == nil) {
+		return fmt.Errorf("spec.target must set exactly one of kubeconfigSecretRef and clusters")
+	}
+	if t.Clusters != nil {
+		if _, err := metav1.LabelSelectorAsSelector(&t.Clusters.Selector); err != nil {
+			return fmt.Errorf("spec.target.clusters.selector is invalid: %w", err)
+		}
 	}
 	return nil
 }
@@ -58,6 +58,57 @@ var _ = Describe("Delivery Validation", func() {
 				Expect(delivery.ValidateCreate()).NotTo(HaveOccurred())
 			})
 
+			Context("when the delivery has a target", func() {
+				BeforeEach(func() {
+					delivery.Spec.Target = &v1alpha1.DeliveryTarget{
+						Clusters: &v1alpha1.ClusterSelector{
+							Namespace: "fleet",
+							Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+						},
+					}
+				})
+
+				It("does not return an error", func() {
+					Expect(delivery.ValidateCreate()).NotTo(HaveOccurred())
+				})
+
+				It("returns an error when both a kubeconfig secret and clusters are set", func() {
+					delivery.Spec.Target.KubeconfigSecretRef = &v1alpha1.KubeconfigSecretReference{Name: "prod-kubeconfig"}
+					Expect(delivery.ValidateCreate()).To(MatchError("spec.target must set exactly one of kubeconfigSecretRef and clusters"))
+				})
+
+				It("returns an error when neither a kubeconfig secret nor clusters are set", func() {
+					delivery.Spec.Target.Clusters = nil
+					Expect(delivery.ValidateCreate()).To(MatchError("spec.target must set exactly one of kubeconfigSecretRef and clusters"))
+				})
+
+				It("returns an error when the cluster selector is invalid", func() {
+					delivery.Spec.Target.Clusters.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{
+						{Key: "env", Operator: "Near"},
+					}
+					Expect(delivery.ValidateCreate()).To(MatchError(ContainSubstring("spec.target.clusters.selector is invalid")))
+				})
+			})
+
+			Context("when a resource is submitted as a pull request", func() {
+				BeforeEach(func() {
+					delivery.Spec.Resources[1].Submission = v1alpha1.PullRequestSubmission
+				})
+
+				It("returns an error without a pull request target", func() {
+					Expect(delivery.ValidateCreate()).To(MatchError("spec.resources[1] \"other-source-provider\" is submitted as a pull request, but spec.pullRequest is not set"))
+				})
+
+				It("does not return an error with a pull request target", func() {
+					delivery.Spec.PullRequest = &v1alpha1.PullRequestTarget{
+						Provider:       v1alpha1.GitHubPullRequestProvider,
+						Repository:     "example/gitops",
+						TokenSecretRef: v1alpha1.TokenSecretReference{Name: "github-token", Namespace: "delivery"},
+					}
+					Expect(delivery.ValidateCreate()).NotTo(HaveOccurred())
+				})
+			})
+
 		})
 
 		Context("Duplicate resource names", func() {
@@ -97,6 +148,94 @@ var _ = Describe("Delivery Validation", func() {
 			})
 
 		})
+
+		Context("Transforms", func() {
+			var delivery *v1alpha1.ClusterDelivery
+
+			BeforeEach(func() {
+				delivery = &v1alpha1.ClusterDelivery{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "delivery-resource",
+					},
+					Spec: v1alpha1.ClusterDeliverySpec{
+						Transforms: []v1alpha1.OutputTransform{
+							{Name: "wrap", Expression: `{"manifest": value}`},
+						},
+						Resources: []v1alpha1.ClusterDeliveryResource{
+							{
+								Name: "deployer",
+								TemplateRef: v1alpha1.DeliveryClusterTemplateReference{
+									Kind: "ClusterDeploymentTemplate",
+									Name: "deploy-template",
+								},
+								Configs: []v1alpha1.ResourceReference{
+									{Name: "config", Resource: "config-provider", Transform: "wrap"},
+								},
+							},
+						},
+					},
+				}
+			})
+
+			It("does not return an error when the transforms compile", func() {
+				Expect(delivery.ValidateCreate()).NotTo(HaveOccurred())
+			})
+
+			It("returns an error when a transform does not compile", func() {
+				delivery.Spec.Resources[0].Configs[0].Transform = "{"
+				Expect(delivery.ValidateCreate()).To(MatchError(ContainSubstring(`spec.resources[0] "deployer" has invalid transforms: invalid transform for 'config'`)))
+			})
+		})
+
+		Context("Published outputs", func() {
+			var delivery *v1alpha1.ClusterDelivery
+
+			BeforeEach(func() {
+				delivery = &v1alpha1.ClusterDelivery{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "delivery-resource",
+					},
+					Spec: v1alpha1.ClusterDeliverySpec{
+						Resources: []v1alpha1.ClusterDeliveryResource{
+							{
+								Name: "source-provider",
+								TemplateRef: v1alpha1.DeliveryClusterTemplateReference{
+									Kind: "ClusterSourceTemplate",
+									Name: "source-template",
+								},
+								Publish: []v1alpha1.PublishedOutput{
+									{Name: "revision", Path: ".status.artifact.revision"},
+								},
+							},
+							{
+								Name: "deployer",
+								TemplateRef: v1alpha1.DeliveryClusterTemplateReference{
+									Kind: "ClusterTemplate",
+									Name: "deploy-template",
+								},
+								Publish: []v1alpha1.PublishedOutput{
+									{Name: "url", Path: ".status.url"},
+								},
+							},
+						},
+					},
+				}
+			})
+
+			It("does not return an error", func() {
+				Expect(delivery.ValidateCreate()).NotTo(HaveOccurred())
+			})
+
+			It("returns an error when a name is published twice", func() {
+				delivery.Spec.Resources[1].Publish[0].Name = "revision"
+				Expect(delivery.ValidateCreate()).To(MatchError(`spec.resources[1].publish[0].name "revision" cannot be published twice`))
+			})
+
+			It("returns an error when a path is not a valid jsonpath", func() {
+				delivery.Spec.Resources[1].Publish[0].Path = ".status[.url"
+				Expect(delivery.ValidateCreate()).To(MatchError(ContainSubstring("invalid spec.resources[1].publish[0].path")))
+			})
+		})
 	})
 
 	Describe("#Update", func() {
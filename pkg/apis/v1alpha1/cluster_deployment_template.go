@@ -20,6 +20,8 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 // +kubebuilder:object:root=true
@@ -33,6 +35,20 @@ type ClusterDeploymentTemplate struct {
 	Status            TemplateStatus `json:"status,omitempty"`
 }
 
+var _ webhook.Validator = &ClusterDeploymentTemplate{}
+
+func (c *ClusterDeploymentTemplate) ValidateCreate() error {
+	return c.Spec.validate()
+}
+
+func (c *ClusterDeploymentTemplate) ValidateUpdate(_ runtime.Object) error {
+	return c.Spec.validate()
+}
+
+func (c *ClusterDeploymentTemplate) ValidateDelete() error {
+	return nil
+}
+
 // +kubebuilder:object:root=true
 
 type ClusterDeploymentTemplateList struct {
@@ -19,6 +19,7 @@
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -27,6 +28,7 @@ import (
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
 
 type ClusterImageTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -36,7 +38,35 @@ type ClusterImageTemplate struct {
 }
 type ImageTemplateSpec struct {
 	TemplateSpec `json:",inline"`
-	ImagePath    string `json:"imagePath"`
+
+	// ImagePath is the path of the image. It may be left out with the
+	// Kpack preset.
+	// +optional
+	ImagePath string `json:"imagePath,omitempty"`
+
+	// ImageExpression is a CEL expression over the stamped object,
+	// available to it as `value`, that produces the image. It is an
+	// alternative to ImagePath.
+	// +optional
+	ImageExpression string `json:"imageExpression,omitempty"`
+
+	// ImageDefault is the image output while the stamped object has none
+	// to read yet.
+	// +optional
+	ImageDefault *apiextensionsv1.JSON `json:"imageDefault,omitempty"`
+
+	// OptionalOutputs are the outputs, of image, that resources consuming
+	// them are stamped without, as null, while the stamped object has not
+	// populated them. Other outputs hold those resources back until they
+	// are populated.
+	// +optional
+	OptionalOutputs []string `json:"optionalOutputs,omitempty"`
+
+	// SensitiveOutputs are the outputs, of image, whose values are
+	// redacted in the status, Events and logs of workloads and
+	// deliverables. Resources consuming them are stamped with the values.
+	// +optional
+	SensitiveOutputs []string `json:"sensitiveOutputs,omitempty"`
 }
 
 type ImageTemplateStatus struct {
@@ -45,17 +75,33 @@ type ImageTemplateStatus struct {
 var _ webhook.Validator = &ClusterImageTemplate{}
 
 func (c *ClusterImageTemplate) ValidateCreate() error {
-	return c.Spec.TemplateSpec.validate()
+	return c.validate()
 }
 
 func (c *ClusterImageTemplate) ValidateUpdate(_ runtime.Object) error {
-	return c.Spec.TemplateSpec.validate()
+	return c.validate()
 }
 
 func (c *ClusterImageTemplate) ValidateDelete() error {
 	return nil
 }
 
+func (c *ClusterImageTemplate) validate() error {
+	if err := c.Spec.TemplateSpec.validate(); err != nil {
+		return err
+	}
+	if err := validateOutputPath("spec.imagePath", c.Spec.ImagePath); err != nil {
+		return err
+	}
+	if err := validateOutputExpression("spec.imageExpression", c.Spec.ImageExpression, "spec.imagePath", c.Spec.ImagePath); err != nil {
+		return err
+	}
+	if err := validateOutputNames("spec.optionalOutputs", c.Spec.OptionalOutputs, "image"); err != nil {
+		return err
+	}
+	return validateOutputNames("spec.sensitiveOutputs", c.Spec.SensitiveOutputs, "image")
+}
+
 // +kubebuilder:object:root=true
 
 type ClusterImageTemplateList struct {
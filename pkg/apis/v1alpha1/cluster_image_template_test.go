@@ -88,6 +88,55 @@ var _ = Describe("ClusterImageTemplate", func() {
 						To(MatchError("invalid template: template should not set metadata.namespace on the child object"))
 				})
 			})
+
+			Context("output paths are valid jsonpath expressions", func() {
+				BeforeEach(func() {
+					raw, err := json.Marshal(&ArbitraryObject{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "some-kind",
+							APIVersion: "v1",
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "some-name",
+						},
+						Spec: ArbitrarySpec{
+							SomeKey: "some-val",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					template.Spec.Template = &runtime.RawExtension{Raw: raw}
+					template.Spec.ImagePath = "status.latestImage"
+				})
+
+				It("succeeds", func() {
+					Expect(template.ValidateCreate()).To(Succeed())
+				})
+			})
+
+			Context("an output path is not a valid jsonpath expression", func() {
+				BeforeEach(func() {
+					raw, err := json.Marshal(&ArbitraryObject{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "some-kind",
+							APIVersion: "v1",
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "some-name",
+						},
+						Spec: ArbitrarySpec{
+							SomeKey: "some-val",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					template.Spec.Template = &runtime.RawExtension{Raw: raw}
+					template.Spec.ImagePath = "data["
+				})
+
+				It("returns an error", func() {
+					Expect(template.ValidateCreate()).
+						To(MatchError(ContainSubstring("invalid spec.imagePath: jsonpath parse path '{.data[}'")))
+				})
+			})
 		})
 
 		Describe("#Update", func() {
@@ -0,0 +1,150 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha1
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// ClusterNotificationSink is told about the workloads and deliverables that
+// stop being ready: it is notified whenever their Ready condition becomes
+// False, or stays False for another reason.
+type ClusterNotificationSink struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ClusterNotificationSinkSpec `json:"spec"`
+}
+
+// ClusterNotificationSinkSpec is where notifications are sent and what they
+// say. Exactly one of webhook and slack must be set.
+type ClusterNotificationSinkSpec struct {
+	// Selector matches the labels of the workloads and deliverables the
+	// sink is notified about. It is notified about every one when empty.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Message is a Go text/template rendering the notification's message
+	// from .Kind, .Namespace, .Name, .Blueprint, .Reason and .Message.
+	// Defaults to naming the object and why it is not ready.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Webhook posts each notification to a URL as a JSON object.
+	// +optional
+	Webhook *NotificationEndpoint `json:"webhook,omitempty"`
+
+	// Slack posts each notification's message to a Slack incoming webhook.
+	// +optional
+	Slack *NotificationEndpoint `json:"slack,omitempty"`
+}
+
+// NotificationEndpoint is the URL notifications are posted to. Exactly one
+// of url and urlFrom must be set.
+type NotificationEndpoint struct {
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// URLFrom reads the URL from a Secret, for URLs that carry credentials
+	// such as Slack incoming webhooks.
+	// +optional
+	URLFrom *SecretKeyReference `json:"urlFrom,omitempty"`
+}
+
+// SecretKeyReference locates a value in a Secret.
+type SecretKeyReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+var _ webhook.Validator = &ClusterNotificationSink{}
+
+func (c *ClusterNotificationSink) ValidateCreate() error {
+	return c.Spec.validate()
+}
+
+func (c *ClusterNotificationSink) ValidateUpdate(_ runtime.Object) error {
+	return c.Spec.validate()
+}
+
+func (c *ClusterNotificationSink) ValidateDelete() error {
+	return nil
+}
+
+func (s *ClusterNotificationSinkSpec) validate() error {
+	if (s.Webhook == nil) == (s.Slack == nil) {
+		return errors.New("spec must set exactly one of webhook and slack")
+	}
+	if s.Webhook != nil {
+		if err := s.Webhook.validate(); err != nil {
+			return fmt.Errorf("spec.webhook: %w", err)
+		}
+	}
+	if s.Slack != nil {
+		if err := s.Slack.validate(); err != nil {
+			return fmt.Errorf("spec.slack: %w", err)
+		}
+	}
+	if s.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(s.Selector); err != nil {
+			return fmt.Errorf("spec.selector is invalid: %w", err)
+		}
+	}
+	if s.Message != "" {
+		if _, err := template.New("message").Parse(s.Message); err != nil {
+			return fmt.Errorf("spec.message is invalid: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *NotificationEndpoint) validate() error {
+	if (e.URL == "") == (e.URLFrom == nil) {
+		return errors.New("must set exactly one of url and urlFrom")
+	}
+	return nil
+}
+
+// +kubebuilder:object:root=true
+
+type ClusterNotificationSinkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNotificationSink `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ClusterNotificationSink{},
+		&ClusterNotificationSinkList{},
+	)
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("ClusterNotificationSink", func() {
+	var sink *v1alpha1.ClusterNotificationSink
+
+	BeforeEach(func() {
+		sink = &v1alpha1.ClusterNotificationSink{
+			Spec: v1alpha1.ClusterNotificationSinkSpec{
+				Webhook: &v1alpha1.NotificationEndpoint{URL: "https://example.com/hook"},
+			},
+		}
+	})
+
+	It("accepts a well formed sink", func() {
+		Expect(sink.ValidateCreate()).To(Succeed())
+		Expect(sink.ValidateUpdate(nil)).To(Succeed())
+	})
+
+	It("rejects a sink with both a webhook and slack", func() {
+		sink.Spec.Slack = &v1alpha1.NotificationEndpoint{URL: "https://hooks.slack.com/x"}
+		Expect(sink.ValidateCreate()).To(MatchError("spec must set exactly one of webhook and slack"))
+	})
+
+	It("rejects a sink with neither a webhook nor slack", func() {
+		sink.Spec.Webhook = nil
+		Expect(sink.ValidateCreate()).To(MatchError("spec must set exactly one of webhook and slack"))
+	})
+
+	It("rejects an endpoint with both url and urlFrom", func() {
+		sink.Spec.Webhook.URLFrom = &v1alpha1.SecretKeyReference{Name: "hook", Namespace: "ns", Key: "url"}
+		Expect(sink.ValidateCreate()).To(MatchError("spec.webhook: must set exactly one of url and urlFrom"))
+	})
+
+	It("rejects an invalid selector", func() {
+		sink.Spec.Selector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: "Sometimes"}},
+		}
+		Expect(sink.ValidateCreate()).To(MatchError(ContainSubstring("spec.selector is invalid")))
+	})
+
+	It("rejects a message that does not parse", func() {
+		sink.Spec.Message = "{{.Name"
+		Expect(sink.ValidateCreate()).To(MatchError(ContainSubstring("spec.message is invalid")))
+	})
+})
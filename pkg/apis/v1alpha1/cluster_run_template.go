@@ -36,6 +36,14 @@ type ClusterRunTemplateSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Template runtime.RawExtension `json:"template"`
 	Outputs  map[string]string    `json:"outputs,omitempty"`
+
+	// Results are outputs read from the results of a stamped Tekton
+	// PipelineRun or TaskRun, keyed by output name, valued by result name.
+	// They are read once the run's Succeeded condition is True, from
+	// status.results, or status.pipelineResults and status.taskResults
+	// with tekton.dev/v1beta1.
+	// +optional
+	Results map[string]string `json:"results,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -19,6 +19,7 @@
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -27,6 +28,7 @@ import (
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
 
 type ClusterSourceTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -37,8 +39,51 @@ type ClusterSourceTemplate struct {
 
 type SourceTemplateSpec struct {
 	TemplateSpec `json:",inline"`
-	URLPath      string `json:"urlPath"`
-	RevisionPath string `json:"revisionPath"`
+
+	// URLPath is the path of the source url. It may be left out with the
+	// Flux preset.
+	// +optional
+	URLPath string `json:"urlPath,omitempty"`
+
+	// RevisionPath is the path of the source revision. It may be left out
+	// with the Flux preset.
+	// +optional
+	RevisionPath string `json:"revisionPath,omitempty"`
+
+	// URLExpression is a CEL expression over the stamped object, available
+	// to it as `value`, that produces the source url. It is an alternative
+	// to URLPath.
+	// +optional
+	URLExpression string `json:"urlExpression,omitempty"`
+
+	// RevisionExpression is a CEL expression over the stamped object,
+	// available to it as `value`, that produces the source revision. It is
+	// an alternative to RevisionPath.
+	// +optional
+	RevisionExpression string `json:"revisionExpression,omitempty"`
+
+	// URLDefault is the source url output while the stamped object has
+	// none to read yet.
+	// +optional
+	URLDefault *apiextensionsv1.JSON `json:"urlDefault,omitempty"`
+
+	// RevisionDefault is the source revision output while the stamped
+	// object has none to read yet.
+	// +optional
+	RevisionDefault *apiextensionsv1.JSON `json:"revisionDefault,omitempty"`
+
+	// OptionalOutputs are the outputs, of url and revision, that resources consuming
+	// them are stamped without, as null, while the stamped object has not
+	// populated them. Other outputs hold those resources back until they
+	// are populated.
+	// +optional
+	OptionalOutputs []string `json:"optionalOutputs,omitempty"`
+
+	// SensitiveOutputs are the outputs, of url and revision, whose values are
+	// redacted in the status, Events and logs of workloads and
+	// deliverables. Resources consuming them are stamped with the values.
+	// +optional
+	SensitiveOutputs []string `json:"sensitiveOutputs,omitempty"`
 }
 
 type SourceTemplateStatus struct {
@@ -47,17 +92,39 @@ type SourceTemplateStatus struct {
 var _ webhook.Validator = &ClusterSourceTemplate{}
 
 func (c *ClusterSourceTemplate) ValidateCreate() error {
-	return c.Spec.TemplateSpec.validate()
+	return c.validate()
 }
 
 func (c *ClusterSourceTemplate) ValidateUpdate(_ runtime.Object) error {
-	return c.Spec.TemplateSpec.validate()
+	return c.validate()
 }
 
 func (c *ClusterSourceTemplate) ValidateDelete() error {
 	return nil
 }
 
+func (c *ClusterSourceTemplate) validate() error {
+	if err := c.Spec.TemplateSpec.validate(); err != nil {
+		return err
+	}
+	if err := validateOutputPath("spec.urlPath", c.Spec.URLPath); err != nil {
+		return err
+	}
+	if err := validateOutputPath("spec.revisionPath", c.Spec.RevisionPath); err != nil {
+		return err
+	}
+	if err := validateOutputExpression("spec.urlExpression", c.Spec.URLExpression, "spec.urlPath", c.Spec.URLPath); err != nil {
+		return err
+	}
+	if err := validateOutputExpression("spec.revisionExpression", c.Spec.RevisionExpression, "spec.revisionPath", c.Spec.RevisionPath); err != nil {
+		return err
+	}
+	if err := validateOutputNames("spec.optionalOutputs", c.Spec.OptionalOutputs, "url", "revision"); err != nil {
+		return err
+	}
+	return validateOutputNames("spec.sensitiveOutputs", c.Spec.SensitiveOutputs, "url", "revision")
+}
+
 // +kubebuilder:object:root=true
 
 type ClusterSourceTemplateList struct {
@@ -88,6 +88,112 @@ var _ = Describe("ClusterSourceTemplate", func() {
 						To(MatchError("invalid template: template should not set metadata.namespace on the child object"))
 				})
 			})
+
+			Context("output paths are valid jsonpath expressions", func() {
+				BeforeEach(func() {
+					raw, err := json.Marshal(&ArbitraryObject{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "some-kind",
+							APIVersion: "v1",
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "some-name",
+						},
+						Spec: ArbitrarySpec{
+							SomeKey: "some-val",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					template.Spec.Template = &runtime.RawExtension{Raw: raw}
+					template.Spec.URLPath = "spec.url"
+					template.Spec.RevisionPath = "spec.revision"
+				})
+
+				It("succeeds", func() {
+					Expect(template.ValidateCreate()).To(Succeed())
+				})
+			})
+
+			Context("an output path is not a valid jsonpath expression", func() {
+				BeforeEach(func() {
+					raw, err := json.Marshal(&ArbitraryObject{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "some-kind",
+							APIVersion: "v1",
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "some-name",
+						},
+						Spec: ArbitrarySpec{
+							SomeKey: "some-val",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					template.Spec.Template = &runtime.RawExtension{Raw: raw}
+					template.Spec.URLPath = "spec.url"
+					template.Spec.RevisionPath = "data["
+				})
+
+				It("returns an error", func() {
+					Expect(template.ValidateCreate()).
+						To(MatchError(ContainSubstring("invalid spec.revisionPath: jsonpath parse path '{.data[}'")))
+				})
+			})
+
+			Context("outputs are read with expressions", func() {
+				BeforeEach(func() {
+					template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "some-kind", "metadata": {"name": "some-name"}}`)}
+					template.Spec.URLExpression = `value.status.url.replace("https://", "")`
+					template.Spec.RevisionPath = "status.revision"
+				})
+
+				It("succeeds", func() {
+					Expect(template.ValidateCreate()).To(Succeed())
+				})
+
+				It("returns an error when the path of the same output is set too", func() {
+					template.Spec.URLPath = "status.url"
+					Expect(template.ValidateCreate()).
+						To(MatchError("only one of spec.urlPath and spec.urlExpression may be set"))
+				})
+
+				It("accepts its outputs as optional outputs", func() {
+					template.Spec.OptionalOutputs = []string{"url", "revision"}
+					Expect(template.ValidateCreate()).To(Succeed())
+				})
+
+				It("returns an error when an optional output is not one of its outputs", func() {
+					template.Spec.OptionalOutputs = []string{"image"}
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid spec.optionalOutputs: 'image' is not one of url, revision"))
+				})
+
+				It("returns an error when a sensitive output is not one of its outputs", func() {
+					template.Spec.SensitiveOutputs = []string{"url", "config"}
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid spec.sensitiveOutputs: 'config' is not one of url, revision"))
+				})
+
+				It("returns an error when an expression does not compile", func() {
+					template.Spec.RevisionPath = ""
+					template.Spec.RevisionExpression = "value.status.revision +"
+					Expect(template.ValidateCreate()).
+						To(MatchError(ContainSubstring("invalid spec.revisionExpression: compile")))
+				})
+			})
+
+			Context("a job lifecycle template has a preset", func() {
+				BeforeEach(func() {
+					template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "some-name"}}`)}
+					template.Spec.Lifecycle = v1alpha1.JobTemplateLifecycle
+					template.Spec.Preset = v1alpha1.FluxTemplatePreset
+				})
+
+				It("returns an error", func() {
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid preset: job lifecycle templates cannot have a preset"))
+				})
+			})
 		})
 
 		Describe("#Update", func() {
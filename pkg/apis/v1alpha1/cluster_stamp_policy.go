@@ -0,0 +1,100 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha1
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// ClusterStampPolicy lists the kinds of object that templates may stamp into
+// a set of namespaces. Once any ClusterStampPolicy applies to a namespace,
+// only the kinds allowed by one of the policies that apply to it may be
+// stamped there.
+type ClusterStampPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ClusterStampPolicySpec `json:"spec"`
+}
+
+type ClusterStampPolicySpec struct {
+	// Namespaces the policy applies to. It applies to every namespace when
+	// empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// AllowedKinds are the kinds of object that may be stamped into the
+	// namespaces.
+	// +kubebuilder:validation:MinItems=1
+	AllowedKinds []StampableKind `json:"allowedKinds"`
+}
+
+// StampableKind identifies a kind of object by API group, regardless of
+// version.
+type StampableKind struct {
+	// Group is the API group of the kind, empty for the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Kind is the kind's name, or `*` for every kind in the group.
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+}
+
+// AppliesTo reports whether the policy constrains objects stamped into
+// namespace.
+func (s *ClusterStampPolicySpec) AppliesTo(namespace string) bool {
+	if len(s.Namespaces) == 0 {
+		return true
+	}
+	for _, n := range s.Namespaces {
+		if n == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether objects of the given group and kind may be stamped.
+func (s *ClusterStampPolicySpec) Allows(group, kind string) bool {
+	for _, allowed := range s.AllowedKinds {
+		if allowed.Group == group && (allowed.Kind == "*" || allowed.Kind == kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:object:root=true
+
+type ClusterStampPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterStampPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ClusterStampPolicy{},
+		&ClusterStampPolicyList{},
+	)
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("ClusterStampPolicy", func() {
+	var spec v1alpha1.ClusterStampPolicySpec
+
+	BeforeEach(func() {
+		spec = v1alpha1.ClusterStampPolicySpec{
+			AllowedKinds: []v1alpha1.StampableKind{
+				{Kind: "ConfigMap"},
+				{Group: "serving.knative.dev", Kind: "*"},
+			},
+		}
+	})
+
+	Describe("AppliesTo", func() {
+		It("applies to every namespace when none are listed", func() {
+			Expect(spec.AppliesTo("anything")).To(BeTrue())
+		})
+
+		It("applies only to the listed namespaces", func() {
+			spec.Namespaces = []string{"team-a", "team-b"}
+			Expect(spec.AppliesTo("team-b")).To(BeTrue())
+			Expect(spec.AppliesTo("team-c")).To(BeFalse())
+		})
+	})
+
+	DescribeTable("Allows",
+		func(group, kind string, allowed bool) {
+			Expect(spec.Allows(group, kind)).To(Equal(allowed))
+		},
+		Entry("a listed core kind", "", "ConfigMap", true),
+		Entry("an unlisted core kind", "", "Secret", false),
+		Entry("a listed kind in another group", "example.com", "ConfigMap", false),
+		Entry("any kind in a wildcard group", "serving.knative.dev", "Service", true),
+	)
+})
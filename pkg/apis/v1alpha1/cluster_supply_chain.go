@@ -20,9 +20,11 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -39,6 +41,9 @@ const (
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=".spec.description"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
 
 type ClusterSupplyChain struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -47,22 +52,54 @@ type ClusterSupplyChain struct {
 	Status            SupplyChainStatus `json:"status,omitempty"`
 }
 
+func (c *ClusterSupplyChain) GetSpec() *SupplyChainSpec {
+	return &c.Spec
+}
+
+func (c *ClusterSupplyChain) GetStatus() *SupplyChainStatus {
+	return &c.Status
+}
+
 func (c *ClusterSupplyChain) validateNewState() error {
+	if err := c.Spec.validate("clustersupplychain", c.Name); err != nil {
+		return err
+	}
+	return c.Spec.validateSecretRefs("")
+}
+
+func (s *SupplyChainSpec) validate(kind, name string) error {
 	names := make(map[string]bool)
 
-	for _, resource := range c.Spec.Resources {
+	for _, resource := range s.Resources {
 		if _, ok := names[resource.Name]; ok {
 			return fmt.Errorf(
-				"duplicate resource name '%s' found in clustersupplychain '%s'",
+				"duplicate resource name '%s' found in %s '%s'",
 				resource.Name,
-				c.Name,
+				kind,
+				name,
 			)
 		}
 		names[resource.Name] = true
 	}
 
-	for _, resource := range c.Spec.Resources {
-		if err := c.validateResourceRefs(resource.Sources, "ClusterSourceTemplate"); err != nil {
+	for _, resource := range s.Resources {
+		if err := resource.TemplateRef.validate(); err != nil {
+			return fmt.Errorf(
+				"invalid templateRef for resource '%s': %w",
+				resource.Name,
+				err,
+			)
+		}
+
+		if err := validateResourceParams(resource.Params); err != nil {
+			return fmt.Errorf(
+				"invalid params for resource '%s': %w",
+				resource.Name,
+				err,
+			)
+		}
+
+		if err := s.validateResourceRefs(resource.Sources, "ClusterSourceTemplate"); err != nil {
 			return fmt.Errorf(
 				"invalid sources for resource '%s': %w",
 				resource.Name,
@@ -70,7 +107,7 @@ func (c *ClusterSupplyChain) validateNewState() error {
 			)
 		}
 
-		if err := c.validateResourceRefs(resource.Images, "ClusterImageTemplate"); err != nil {
+		if err := s.validateResourceRefs(resource.Images, "ClusterImageTemplate"); err != nil {
 			return fmt.Errorf(
 				"invalid images for resource '%s': %w",
 				resource.Name,
@@ -78,21 +115,45 @@ func (c *ClusterSupplyChain) validateNewState() error {
 			)
 		}
 
-		if err := c.validateResourceRefs(resource.Configs, "ClusterConfigTemplate"); err != nil {
+		if err := s.validateResourceRefs(resource.Configs, "ClusterConfigTemplate"); err != nil {
 			return fmt.Errorf(
 				"invalid configs for resource '%s': %w",
 				resource.Name,
 				err,
 			)
 		}
+
+		if err := validateTransforms(s.Transforms, resource.Sources, resource.Images, resource.Configs); err != nil {
+			return fmt.Errorf(
+				"invalid transforms for resource '%s': %w",
+				resource.Name,
+				err,
+			)
+		}
+
+		if err := resource.Propagation.validate(); err != nil {
+			return fmt.Errorf(
+				"invalid propagation for resource '%s': %w",
+				resource.Name,
+				err,
+			)
+		}
 	}
 
-	return nil
+	if err := s.Propagation.validate(); err != nil {
+		return fmt.Errorf("invalid propagation: %w", err)
+	}
+
+	if err := s.Requires.validate(); err != nil {
+		return fmt.Errorf("invalid requires: %w", err)
+	}
+
+	return validatePreDeleteHooks(s.PreDelete)
 }
 
-func (c *ClusterSupplyChain) validateResourceRefs(references []ResourceReference, targetKind string) error {
+func (s *SupplyChainSpec) validateResourceRefs(references []ResourceReference, targetKind string) error {
 	for _, ref := range references {
-		referencedResource := c.getResourceByName(ref.Resource)
+		referencedResource := s.getResourceByName(ref.Resource)
 		if referencedResource == nil {
 			return fmt.Errorf(
 				"'%s' is provided by unknown resource '%s'",
@@ -112,8 +173,8 @@ func (c *ClusterSupplyChain) validateResourceRefs(references []ResourceReference
 	return nil
 }
 
-func (c *ClusterSupplyChain) getResourceByName(name string) *SupplyChainResource {
-	for _, resource := range c.Spec.Resources {
+func (s *SupplyChainSpec) getResourceByName(name string) *SupplyChainResource {
+	for _, resource := range s.Resources {
 		if resource.Name == name {
 			return &resource
 		}
@@ -149,8 +210,68 @@ func GetSelectorsFromObject(o client.Object) []string {
 }
 
 type SupplyChainSpec struct {
+	// Description tells developers what the supply chain does and which
+	// workloads it is meant for.
+	// +optional
+	Description string `json:"description,omitempty"`
+
 	Resources []SupplyChainResource `json:"resources"`
 	Selector  map[string]string     `json:"selector"`
+
+	// Platforms the supply chain can build for and deploy to. A workload
+	// asking for a platform not in this list is not realized. When empty,
+	// every platform is supported.
+	// +optional
+	Platforms []Platform `json:"platforms,omitempty"`
+
+	// Requires is what workloads must set to be realized with the supply
+	// chain. Workloads that do not are reported, requirement by
+	// requirement, and not realized.
+	// +optional
+	Requires *WorkloadContract `json:"requires,omitempty"`
+
+	// Scheduling hints applied to the objects stamped for every resource.
+	// +optional
+	Scheduling *SchedulingHints `json:"scheduling,omitempty"`
+
+	// Propagation of the workload's labels and annotations to the objects
+	// stamped for every resource. Defaults to propagating none.
+	// +optional
+	Propagation *MetadataPropagation `json:"propagation,omitempty"`
+
+	// Transforms are named expressions that resources' sources, images and
+	// configs can apply to the outputs they consume.
+	// +optional
+	Transforms []OutputTransform `json:"transforms,omitempty"`
+
+	// Teardown, when set, holds back the deletion of the blueprint until
+	// the objects stamped on its behalf, for every owner, are torn down:
+	// "Delete" deletes them, "Orphan" detaches them from their owners so
+	// they outlive them. Without it, the objects are left to their owners.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +optional
+	Teardown string `json:"teardown,omitempty"`
+
+	// PreDelete are hooks run, in order, when a workload realized with the
+	// supply chain is deleted. The workload is only deleted once each has
+	// completed.
+	// +optional
+	PreDelete []PreDeleteHook `json:"preDelete,omitempty"`
+}
+
+// SupportsPlatform reports whether a workload asking for platform can be
+// realized by the supply chain. Workloads that do not ask for a platform
+// are always supported.
+func (s *SupplyChainSpec) SupportsPlatform(platform *Platform) bool {
+	if platform == nil || len(s.Platforms) == 0 {
+		return true
+	}
+	for _, supported := range s.Platforms {
+		if supported.Matches(*platform) {
+			return true
+		}
+	}
+	return false
 }
 
 type SupplyChainResource struct {
@@ -160,18 +281,173 @@ type SupplyChainResource struct {
 	Sources     []ResourceReference      `json:"sources,omitempty"`
 	Images      []ResourceReference      `json:"images,omitempty"`
 	Configs     []ResourceReference      `json:"configs,omitempty"`
+
+	// Description tells developers what the resource contributes to the
+	// supply chain.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// ServiceAccountName is the service account, in the owner's namespace,
+	// that Cartographer impersonates when creating and updating the object
+	// stamped for this resource. Defaults to Cartographer's own identity.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Scheduling hints for this resource's object, merged over the
+	// blueprint's.
+	// +optional
+	Scheduling *SchedulingHints `json:"scheduling,omitempty"`
+
+	// Propagation for this resource's object: the labels and annotations
+	// policies it sets replace the blueprint's.
+	// +optional
+	Propagation *MetadataPropagation `json:"propagation,omitempty"`
+
+	// TargetNamespace is the namespace this resource's object is stamped
+	// into, when it is not the workload's. Such objects cannot be owned by
+	// the workload, so Cartographer deletes them itself when the workload is
+	// deleted or no longer stamps them.
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// HashName, when true, suffixes the name of this resource's object with
+	// a short hash of the workload's namespace and name. Workloads of
+	// different namespaces stamping into a shared target namespace then do
+	// not claim the same object, without every template having to name
+	// objects uniquely.
+	// +optional
+	HashName bool `json:"hashName,omitempty"`
+
+	// Drift is what Cartographer does when this resource's object is
+	// changed by something else: "remediate", the default, stamps it
+	// again; "detect" leaves it, reporting it in the Drifted condition.
+	// +kubebuilder:validation:Enum=remediate;detect
+	// +optional
+	Drift string `json:"drift,omitempty"`
+
+	// DryRun, when true, submits this resource's objects to the apiserver
+	// as a server-side dry run before creating or updating them. Objects
+	// the apiserver, or its admission webhooks, reject are then never
+	// written, and are reported with the StampedObjectRejected reason.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// DeletionPolicy is what happens to this resource's object when the
+	// workload is deleted: "Delete" deletes it along with the workload; "Orphan"
+	// leaves it behind, stamped without an owner reference, for instance to
+	// be inspected after the fact. Defaults to the workload's deletionPolicy.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// Ownership is how this resource's object is tied to the workload:
+	// "OwnerReference", the default, by an owner reference, which leaves
+	// its deletion to the garbage collector; "Tracked" by its labels alone,
+	// Cartographer deleting it once the workload is gone. Objects that cannot
+	// be owned, such as cluster-scoped ones, need the latter.
+	// +kubebuilder:validation:Enum=OwnerReference;Tracked
+	// +optional
+	Ownership string `json:"ownership,omitempty"`
+}
+
+// validateSecretRefs checks the Secrets that templates are fetched from git
+// with against namespace, the blueprint's, empty when it is cluster-scoped.
+func (s *SupplyChainSpec) validateSecretRefs(namespace string) error {
+	for _, resource := range s.Resources {
+		if err := resource.TemplateRef.Git.validateSecretRef(namespace); err != nil {
+			return fmt.Errorf(
+				"invalid templateRef for resource '%s': %w",
+				resource.Name,
+				err,
+			)
+		}
+	}
+	return nil
+}
+
+// StampsAcrossNamespaces reports whether any resource is stamped into a
+// namespace other than namespace.
+func (s *SupplyChainSpec) StampsAcrossNamespaces(namespace string) bool {
+	for _, resource := range s.Resources {
+		if resource.TargetNamespace != "" && resource.TargetNamespace != namespace {
+			return true
+		}
+	}
+	return false
 }
 
 type ClusterTemplateReference struct {
 	// +kubebuilder:validation:Enum=ClusterSourceTemplate;ClusterImageTemplate;ClusterTemplate;ClusterConfigTemplate
 	Kind string `json:"kind"`
-	// +kubebuilder:validation:MinLength=1
-	Name string `json:"name"`
+	// Name of the template on the cluster. Exactly one of name, git and
+	// options must be set.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Version pins the template to one of its versions: the template of
+	// the same kind labelled carto.run/template-name with name and
+	// carto.run/template-version with version. Only valid with name or
+	// options, whose chosen template it pins.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Git fetches the template from a git repository instead.
+	// +optional
+	Git *GitTemplateSource `json:"git,omitempty"`
+	// Options choose the template by the workload: each names a template of
+	// kind and selects the workloads it is used for. Exactly one option
+	// must match a workload.
+	// +optional
+	Options []TemplateOption `json:"options,omitempty"`
+}
+
+// DisplayName names the template in messages: its name and the version it
+// is pinned to, where it is fetched from, or the names of its options.
+func (r ClusterTemplateReference) DisplayName() string {
+	if r.Git != nil {
+		return r.Git.String()
+	}
+	name := r.Name
+	if len(r.Options) > 0 {
+		var names []string
+		for _, option := range r.Options {
+			names = append(names, option.Name)
+		}
+		name = strings.Join(names, "|")
+	}
+	if r.Version != "" {
+		return name + "@" + r.Version
+	}
+	return name
+}
+
+func (r ClusterTemplateReference) validate() error {
+	if len(r.Options) == 0 {
+		return validateTemplateRef(r.Name, r.Version, r.Git)
+	}
+	if r.Name != "" || r.Git != nil {
+		return fmt.Errorf("options cannot be set with name or git")
+	}
+	if r.Version != "" {
+		if errs := validation.IsValidLabelValue(r.Version); len(errs) > 0 {
+			return fmt.Errorf("version '%s' is not a valid label value: %s", r.Version, strings.Join(errs, ", "))
+		}
+	}
+	return validateTemplateOptions(r.Options)
 }
 
 type SupplyChainStatus struct {
 	Conditions         []metav1.Condition `json:"conditions,omitempty"`
 	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+
+	// Resources tell, resource by resource, which templates were not found.
+	// +optional
+	// +listType=map
+	// +listMapKey=resource
+	Resources []ResourceTemplatesStatus `json:"resources,omitempty"`
+
+	// Rollout counts the workloads realized with the supply chain by how
+	// far they have converged on its current generation.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
 }
 
 // +kubebuilder:object:root=true
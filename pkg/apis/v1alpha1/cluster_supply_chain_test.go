@@ -53,6 +53,40 @@ var _ = Describe("ClusterSupplyChain", func() {
 			Expect(jsonValue).To(ContainSubstring("selector"))
 			Expect(jsonValue).NotTo(ContainSubstring("omitempty"))
 		})
+
+		DescribeTable("SupportsPlatform",
+			func(platforms []v1alpha1.Platform, platform *v1alpha1.Platform, supported bool) {
+				spec := v1alpha1.SupplyChainSpec{Platforms: platforms}
+				Expect(spec.SupportsPlatform(platform)).To(Equal(supported))
+			},
+			Entry("workload asks for no platform",
+				[]v1alpha1.Platform{{OS: "windows"}}, nil, true),
+			Entry("supply chain declares no platforms",
+				nil, &v1alpha1.Platform{OS: "windows", Arch: "amd64"}, true),
+			Entry("operating system is declared",
+				[]v1alpha1.Platform{{OS: "linux"}, {OS: "windows"}}, &v1alpha1.Platform{OS: "windows", Arch: "amd64"}, true),
+			Entry("operating system and architecture are declared",
+				[]v1alpha1.Platform{{OS: "linux", Arch: "arm64"}}, &v1alpha1.Platform{OS: "linux", Arch: "arm64"}, true),
+			Entry("workload accepts any architecture",
+				[]v1alpha1.Platform{{OS: "linux", Arch: "arm64"}}, &v1alpha1.Platform{OS: "linux"}, true),
+			Entry("operating system is not declared",
+				[]v1alpha1.Platform{{OS: "linux"}}, &v1alpha1.Platform{OS: "windows"}, false),
+			Entry("architecture is not declared",
+				[]v1alpha1.Platform{{OS: "linux", Arch: "amd64"}}, &v1alpha1.Platform{OS: "linux", Arch: "arm64"}, false),
+		)
+
+		DescribeTable("StampsAcrossNamespaces",
+			func(targetNamespaces []string, stamps bool) {
+				spec := v1alpha1.SupplyChainSpec{}
+				for _, namespace := range targetNamespaces {
+					spec.Resources = append(spec.Resources, v1alpha1.SupplyChainResource{TargetNamespace: namespace})
+				}
+				Expect(spec.StampsAcrossNamespaces("team-a")).To(Equal(stamps))
+			},
+			Entry("no resource targets a namespace", []string{"", ""}, false),
+			Entry("a resource targets the workload's namespace", []string{"team-a"}, false),
+			Entry("a resource targets another namespace", []string{"", "shared-builds"}, true),
+		)
 	})
 
 	Describe("SupplyChainResource", func() {
@@ -230,6 +264,324 @@ var _ = Describe("ClusterSupplyChain", func() {
 				})
 			})
 
+			Context("Supply chain with a template in git", func() {
+				var supplyChain *v1alpha1.ClusterSupplyChain
+
+				BeforeEach(func() {
+					supplyChain = &v1alpha1.ClusterSupplyChain{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "responsible-ops---git",
+						},
+						Spec: v1alpha1.SupplyChainSpec{
+							Resources: []v1alpha1.SupplyChainResource{
+								{
+									Name: "source-provider",
+									TemplateRef: v1alpha1.ClusterTemplateReference{
+										Kind: "ClusterSourceTemplate",
+										Git: &v1alpha1.GitTemplateSource{
+											URL:  "https://git.example.com/templates.git",
+											Path: "source.yaml",
+										},
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("accepts a templateRef with only git", func() {
+					Expect(supplyChain.ValidateCreate()).To(Succeed())
+				})
+
+				It("rejects a templateRef with both a name and git", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Name = "git-template"
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("invalid templateRef for resource 'source-provider': exactly one of name and git must be set")))
+				})
+
+				It("rejects a templateRef with neither a name nor git", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Git = nil
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("exactly one of name and git must be set")))
+				})
+
+				It("rejects a templateRef pinning a version of a template in git", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Version = "v2"
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("version cannot be set with git, pin git.ref instead")))
+				})
+
+				It("accepts a secretRef in the controller's namespace", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Git.SecretRef = &v1alpha1.SecretReference{Namespace: "cartographer-system", Name: "git-credentials"}
+					Expect(supplyChain.ValidateCreate()).To(Succeed())
+				})
+
+				It("rejects a secretRef in any other namespace", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Git.SecretRef = &v1alpha1.SecretReference{Namespace: "team-a", Name: "git-credentials"}
+					Expect(supplyChain.ValidateCreate()).To(MatchError(
+						"invalid templateRef for resource 'source-provider': git.secretRef must be in namespace 'cartographer-system', not 'team-a'",
+					))
+				})
+			})
+
+			Context("Supply chain pinning a template version", func() {
+				var supplyChain *v1alpha1.ClusterSupplyChain
+
+				BeforeEach(func() {
+					supplyChain = &v1alpha1.ClusterSupplyChain{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "responsible-ops---version",
+						},
+						Spec: v1alpha1.SupplyChainSpec{
+							Resources: []v1alpha1.SupplyChainResource{
+								{
+									Name: "source-provider",
+									TemplateRef: v1alpha1.ClusterTemplateReference{
+										Kind:    "ClusterSourceTemplate",
+										Name:    "git-template",
+										Version: "v2",
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("accepts a version that is a valid label value", func() {
+					Expect(supplyChain.ValidateCreate()).To(Succeed())
+				})
+
+				It("rejects a version that is not a valid label value", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Version = "v2 beta"
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("invalid templateRef for resource 'source-provider': version 'v2 beta' is not a valid label value")))
+				})
+			})
+
+			Context("Supply chain choosing a template by option", func() {
+				var supplyChain *v1alpha1.ClusterSupplyChain
+
+				BeforeEach(func() {
+					supplyChain = &v1alpha1.ClusterSupplyChain{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "responsible-ops---options",
+						},
+						Spec: v1alpha1.SupplyChainSpec{
+							Resources: []v1alpha1.SupplyChainResource{
+								{
+									Name: "image-builder",
+									TemplateRef: v1alpha1.ClusterTemplateReference{
+										Kind: "ClusterImageTemplate",
+										Options: []v1alpha1.TemplateOption{
+											{
+												Name: "java-build",
+												Selector: v1alpha1.OptionSelector{
+													LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"language": "java"}},
+												},
+											},
+											{
+												Name: "go-build",
+												Selector: v1alpha1.OptionSelector{
+													MatchFields: []v1alpha1.FieldSelectorRequirement{
+														{Key: "spec.source.git.url", Operator: v1alpha1.FieldSelectorOpIn, Values: []string{"https://example.com/go"}},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("accepts options", func() {
+					Expect(supplyChain.ValidateCreate()).To(Succeed())
+				})
+
+				It("accepts options pinned to a version", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Version = "v2"
+					Expect(supplyChain.ValidateCreate()).To(Succeed())
+				})
+
+				It("rejects options with a name", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Name = "kpack"
+					Expect(supplyChain.ValidateCreate()).To(MatchError("invalid templateRef for resource 'image-builder': options cannot be set with name or git"))
+				})
+
+				It("rejects two options for the same template", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Options[1].Name = "java-build"
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("template 'java-build' appears in more than one option")))
+				})
+
+				It("rejects an option without a selector", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Options[0].Selector = v1alpha1.OptionSelector{}
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("option 'java-build': selector must set at least one of matchLabels, matchExpressions and matchFields")))
+				})
+
+				It("rejects an invalid label selector", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Options[0].Selector.MatchExpressions = []metav1.LabelSelectorRequirement{
+						{Key: "language", Operator: "Within"},
+					}
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("option 'java-build': invalid selector")))
+				})
+
+				It("rejects a field requirement with an invalid key", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Options[1].Selector.MatchFields[0].Key = "spec.source[0"
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("option 'go-build': invalid key 'spec.source[0' in matchFields")))
+				})
+
+				It("rejects a field requirement missing values", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Options[1].Selector.MatchFields[0].Values = nil
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("matchFields key 'spec.source.git.url' with operator In needs values")))
+				})
+
+				It("rejects a field requirement with values it cannot have", func() {
+					supplyChain.Spec.Resources[0].TemplateRef.Options[1].Selector.MatchFields[0].Operator = v1alpha1.FieldSelectorOpExists
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("matchFields key 'spec.source.git.url' with operator Exists cannot have values")))
+				})
+			})
+
+			Context("Supply chain with transforms", func() {
+				var supplyChain *v1alpha1.ClusterSupplyChain
+
+				BeforeEach(func() {
+					supplyChain = &v1alpha1.ClusterSupplyChain{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "responsible-ops---transforms",
+						},
+						Spec: v1alpha1.SupplyChainSpec{
+							Transforms: []v1alpha1.OutputTransform{
+								{Name: "subpath", Expression: `{"url": value.url + "/app", "revision": value.revision}`},
+							},
+							Resources: []v1alpha1.SupplyChainResource{
+								{
+									Name: "source-provider",
+									TemplateRef: v1alpha1.ClusterTemplateReference{
+										Kind: "ClusterSourceTemplate",
+										Name: "git-template",
+									},
+								},
+								{
+									Name: "builder",
+									TemplateRef: v1alpha1.ClusterTemplateReference{
+										Kind: "ClusterImageTemplate",
+										Name: "kpack-template",
+									},
+									Sources: []v1alpha1.ResourceReference{
+										{Name: "source", Resource: "source-provider", Transform: "subpath"},
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("accepts named and inline transforms that compile", func() {
+					Expect(supplyChain.ValidateCreate()).To(Succeed())
+
+					supplyChain.Spec.Resources[1].Sources[0].Transform = `{"url": value.url, "revision": "main"}`
+					Expect(supplyChain.ValidateCreate()).To(Succeed())
+				})
+
+				It("rejects a reference whose transform does not compile", func() {
+					supplyChain.Spec.Resources[1].Sources[0].Transform = "value.url +"
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("invalid transforms for resource 'builder': invalid transform for 'source'")))
+				})
+
+				It("rejects a named transform that does not compile", func() {
+					supplyChain.Spec.Transforms[0].Expression = "value.url +"
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("invalid transform 'subpath'")))
+				})
+
+				It("rejects duplicate transform names", func() {
+					supplyChain.Spec.Transforms = append(supplyChain.Spec.Transforms, supplyChain.Spec.Transforms[0])
+					Expect(supplyChain.ValidateCreate()).To(MatchError(ContainSubstring("duplicate transform name 'subpath'")))
+				})
+			})
+
+			Context("propagation", func() {
+				var supplyChain *v1alpha1.ClusterSupplyChain
+
+				BeforeEach(func() {
+					supplyChain = &v1alpha1.ClusterSupplyChain{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "responsible-ops---propagation",
+						},
+						Spec: v1alpha1.SupplyChainSpec{
+							Propagation: &v1alpha1.MetadataPropagation{
+								Labels: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"cost-center", "example.com/*"}},
+							},
+							Resources: []v1alpha1.SupplyChainResource{
+								{
+									Name: "source-provider",
+									TemplateRef: v1alpha1.ClusterTemplateReference{
+										Kind: "ClusterSourceTemplate",
+										Name: "git-template",
+									},
+									Propagation: &v1alpha1.MetadataPropagation{
+										Annotations: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("accepts allowlists and policies without keys", func() {
+					Expect(supplyChain.ValidateCreate()).To(Succeed())
+				})
+
+				It("rejects keys with a policy other than Allowlist", func() {
+					supplyChain.Spec.Propagation.Labels.Policy = v1alpha1.PropagateAll
+					Expect(supplyChain.ValidateCreate()).To(MatchError("invalid propagation: labels: keys are only valid with the Allowlist policy"))
+				})
+
+				It("rejects an empty key", func() {
+					supplyChain.Spec.Resources[0].Propagation.Annotations = &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{""}}
+					Expect(supplyChain.ValidateCreate()).To(MatchError("invalid propagation for resource 'source-provider': annotations: key '' is invalid"))
+				})
+			})
+
+			Context("requires", func() {
+				var supplyChain *v1alpha1.ClusterSupplyChain
+
+				BeforeEach(func() {
+					supplyChain = &v1alpha1.ClusterSupplyChain{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "responsible-ops---requires",
+						},
+						Spec: v1alpha1.SupplyChainSpec{
+							Requires: &v1alpha1.WorkloadContract{
+								Fields: []v1alpha1.FieldSelectorRequirement{
+									{Key: "spec.source.git", Operator: v1alpha1.FieldSelectorOpExists},
+								},
+								Params: []v1alpha1.RequiredParam{{Name: "language", Values: []string{"java"}}},
+							},
+							Resources: []v1alpha1.SupplyChainResource{
+								{
+									Name: "source-provider",
+									TemplateRef: v1alpha1.ClusterTemplateReference{
+										Kind: "ClusterSourceTemplate",
+										Name: "git-template",
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("accepts field requirements and required params", func() {
+					Expect(supplyChain.ValidateCreate()).To(Succeed())
+				})
+
+				It("rejects a field requirement with values it cannot take", func() {
+					supplyChain.Spec.Requires.Fields[0].Values = []string{"https://github.com/example/app"}
+					Expect(supplyChain.ValidateCreate()).To(MatchError("invalid requires: fields key 'spec.source.git' with operator Exists cannot have values"))
+				})
+
+				It("rejects a param required more than once", func() {
+					supplyChain.Spec.Requires.Params = append(supplyChain.Spec.Requires.Params, v1alpha1.RequiredParam{Name: "language"})
+					Expect(supplyChain.ValidateCreate()).To(MatchError("invalid requires: param 'language' is required more than once"))
+				})
+			})
+
 			Describe("Template inputs must reference a resource with a matching type", func() {
 				var supplyChain *v1alpha1.ClusterSupplyChain
 				var consumerToProviderMapping = map[string]string{
@@ -44,6 +44,201 @@ type TemplateSpec struct {
 	Template *runtime.RawExtension `json:"template,omitempty"`
 	Ytt      string                `json:"ytt,omitempty"`
 	Params   DefaultParams         `json:"params,omitempty"`
+
+	// Sample, when set, is stamped through the template by the admission
+	// webhook so that templates which do not render are rejected on apply.
+	// The sample is stamped as a workload, whichever blueprints use the
+	// template.
+	// +optional
+	Sample *TemplateSample `json:"sample,omitempty"`
+
+	// Lifecycle is how the objects stamped from the template are managed.
+	// "mutable", the default, keeps a single object up to date. "job" runs
+	// the stamped Job once for every change to it, waits for it to complete
+	// and reads the template's outputs from the job's results.
+	// +kubebuilder:validation:Enum=mutable;job
+	// +optional
+	Lifecycle string `json:"lifecycle,omitempty"`
+
+	// Results says where the outputs of a job lifecycle template are read
+	// from. Output paths are evaluated against the results, not the Job.
+	// +optional
+	Results *JobResults `json:"results,omitempty"`
+
+	// Plugin names the realizer plugin, registered with the controller's
+	// --realizer-plugin flag, that realizes the objects stamped from the
+	// template in place of submitting them to the cluster, such as a
+	// Terraform runner. Output paths are evaluated against the outputs the
+	// plugin returns, not the stamped object.
+	// +optional
+	Plugin string `json:"plugin,omitempty"`
+
+	// Preset, when set, reads the stamped object the way a known family of
+	// objects reports its state. "Flux" holds back the template's outputs
+	// until the object's Ready condition is True for its current
+	// generation, and lets a source template leave out its paths to read
+	// the artifact Flux sources publish: .status.artifact.url and
+	// .status.artifact.revision. "ArgoCD" holds them back until the stamped
+	// Application is synced and healthy. "Knative" holds them back until
+	// the stamped Service is Ready for its current generation and its
+	// latest revision is ready, and lets a source template leave out its
+	// paths to read the Service's url and latest ready revision. "Kpack"
+	// holds them back until the stamped kpack Image is Ready for its
+	// current generation, and lets an image template leave out its path to
+	// read .status.latestImage.
+	// +kubebuilder:validation:Enum=Flux;ArgoCD;Knative;Kpack
+	// +optional
+	Preset string `json:"preset,omitempty"`
+
+	// Naming, when set, names the stamped object in place of, or around,
+	// the name the template gives it, so that owners sharing a namespace do
+	// not stamp over each other's objects.
+	// +optional
+	Naming *NamingStrategy `json:"naming,omitempty"`
+
+	// Libraries are the names of the ClusterTemplateLibraries whose
+	// partials the template includes, as "$(partials.<name>)$".
+	// +optional
+	Libraries []string `json:"libraries,omitempty"`
+
+	// TemplateOptions tune how the template's tags are interpolated.
+	// +optional
+	TemplateOptions *TemplateOptions `json:"templateOptions,omitempty"`
+}
+
+// TemplateOptions tune how the tags of a template are interpolated.
+type TemplateOptions struct {
+	// MissingKey is what a tag reading a value that is not there does.
+	// "error" fails stamping, for keys that do not exist as well as for
+	// fields the workload, deliverable or inputs leave unset or null.
+	// "zero" interpolates the empty string within a string, and null for a
+	// tag that is the whole value. Unset, keys that do not exist fail
+	// stamping while unset fields are interpolated as their empty value.
+	// +kubebuilder:validation:Enum=error;zero
+	// +optional
+	MissingKey string `json:"missingKey,omitempty"`
+}
+
+// NamingStrategy names the objects stamped from a template. The prefix and
+// suffix are added to the name, or to the generateName of a template that
+// only sets one.
+type NamingStrategy struct {
+	// Name replaces the name the template gives the object. It is
+	// interpolated like the template, for instance
+	// "$(workload.metadata.name)$-build".
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Prefix, when "Owner", prepends the name of the workload or
+	// deliverable the object is stamped for.
+	// +kubebuilder:validation:Enum=Owner
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Suffix, when "Hash", appends a hash of the namespace and name of the
+	// workload or deliverable the object is stamped for, as hashName does.
+	// +kubebuilder:validation:Enum=Hash
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+}
+
+const (
+	OwnerNamePrefix = "Owner"
+	HashNameSuffix  = "Hash"
+)
+
+const (
+	ErrorMissingKey = "error"
+	ZeroMissingKey  = "zero"
+)
+
+const (
+	MutableTemplateLifecycle = "mutable"
+	JobTemplateLifecycle     = "job"
+)
+
+// Presets reading stamped objects the way the tool that reconciles them
+// reports their state.
+const (
+	FluxTemplatePreset    = "Flux"
+	ArgoCDTemplatePreset  = "ArgoCD"
+	KnativeTemplatePreset = "Knative"
+	KpackTemplatePreset   = "Kpack"
+)
+
+const (
+	ConfigMapJobResults          = "ConfigMap"
+	TerminationMessageJobResults = "TerminationMessage"
+)
+
+// JobResults locates the results of a completed job.
+type JobResults struct {
+	// From is "TerminationMessage", the default, to read the termination
+	// message of the job's succeeded pod as a JSON object, or "ConfigMap" to
+	// read the data of the ConfigMap the job writes, named after the job, in
+	// its namespace.
+	// +kubebuilder:validation:Enum=TerminationMessage;ConfigMap
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// Container whose termination message holds the results. Defaults to
+	// the pod's first container.
+	// +optional
+	Container string `json:"container,omitempty"`
+}
+
+// IsJob reports whether objects stamped from the template are run as jobs.
+func (t TemplateSpec) IsJob() bool {
+	return t.Lifecycle == JobTemplateLifecycle
+}
+
+// MissingKey is what a tag reading a value that is not there does, or ""
+// when the template does not say.
+func (t TemplateSpec) MissingKey() string {
+	if t.TemplateOptions == nil {
+		return ""
+	}
+	return t.TemplateOptions.MissingKey
+}
+
+// TemplateSample describes the workload and inputs a template is stamped
+// with at admission time.
+type TemplateSample struct {
+	// Workload is an embedded workload to stamp the template with.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Workload *runtime.RawExtension `json:"workload,omitempty"`
+
+	// WorkloadRef refers to an existing workload to stamp the template with.
+	// +optional
+	WorkloadRef *SampleWorkloadReference `json:"workloadRef,omitempty"`
+
+	// Sources are provided to the template as if output by earlier
+	// resources in a supply chain.
+	Sources []SampleSource `json:"sources,omitempty"`
+	Images  []SampleImage  `json:"images,omitempty"`
+	Configs []SampleConfig `json:"configs,omitempty"`
+}
+
+type SampleWorkloadReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type SampleSource struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Revision string `json:"revision,omitempty"`
+}
+
+type SampleImage struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type SampleConfig struct {
+	Name   string `json:"name"`
+	Config string `json:"config"`
 }
 
 type TemplateStatus struct {
@@ -78,6 +273,47 @@ func (t *TemplateSpec) validate() error {
 		if obj.Namespace != metav1.NamespaceNone {
 			return errors.New("invalid template: template should not set metadata.namespace on the child object")
 		}
+		if obj.APIVersion == "v1" && obj.Kind == "List" {
+			list := struct {
+				Items []metav1.PartialObjectMetadata `json:"items"`
+			}{}
+			if err := json.Unmarshal(t.Template.Raw, &list); err != nil {
+				return fmt.Errorf("invalid template: failed to parse list items: %w", err)
+			}
+			if len(list.Items) == 0 {
+				return errors.New("invalid template: a List template must have items")
+			}
+			for i, item := range list.Items {
+				if item.Namespace != metav1.NamespaceNone {
+					return fmt.Errorf("invalid template: template should not set metadata.namespace on the child object at items[%d]", i)
+				}
+			}
+		}
+		if t.IsJob() && (obj.APIVersion != "batch/v1" || obj.Kind != "Job") {
+			return fmt.Errorf("invalid template: a job lifecycle template must stamp a batch/v1 Job, found %s %s", obj.APIVersion, obj.Kind)
+		}
+	}
+	if t.Results != nil && !t.IsJob() {
+		return errors.New("invalid results: only job lifecycle templates have results")
+	}
+	if t.Preset != "" && t.IsJob() {
+		return errors.New("invalid preset: job lifecycle templates cannot have a preset")
+	}
+	if t.Plugin != "" && (t.IsJob() || t.Preset != "") {
+		return errors.New("invalid plugin: job lifecycle templates and templates with a preset cannot be realized by a plugin")
+	}
+	if t.Sample != nil {
+		return t.Sample.validate()
+	}
+	return nil
+}
+
+func (s *TemplateSample) validate() error {
+	if s.Workload == nil && s.WorkloadRef == nil {
+		return fmt.Errorf("invalid sample: must specify one of workload or workloadRef, found neither")
+	}
+	if s.Workload != nil && s.WorkloadRef != nil {
+		return fmt.Errorf("invalid sample: must specify one of workload or workloadRef, found both")
 	}
 	return nil
 }
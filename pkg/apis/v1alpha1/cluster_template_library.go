@@ -0,0 +1,140 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha1
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/vmware-tanzu/cartographer/pkg/transform"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// ClusterTemplateLibrary holds template partials, blocks like standard
+// labels, probes or a securityContext, and helpers computing values, that
+// templates listing the library include rather than repeat. Objects stamped
+// from those templates are stamped again when the library changes.
+type ClusterTemplateLibrary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              TemplateLibrarySpec `json:"spec"`
+}
+
+type TemplateLibrarySpec struct {
+	// Partials are the named blocks the library offers. A template
+	// includes one as "$(partials.<name>)$", in place of a value.
+	// +optional
+	Partials []TemplatePartial `json:"partials,omitempty"`
+
+	// Helpers are the named values the library computes. A template
+	// includes one as "$(helpers.<name>)$", in place of a value.
+	// +optional
+	Helpers []TemplateHelper `json:"helpers,omitempty"`
+}
+
+type TemplatePartial struct {
+	// Name is how templates refer to the partial.
+	Name string `json:"name"`
+
+	// Template is the block included, interpolated like the template that
+	// includes it.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Template runtime.RawExtension `json:"template"`
+}
+
+type TemplateHelper struct {
+	// Name is how templates refer to the helper.
+	Name string `json:"name"`
+
+	// Expression is a CEL expression computing the helper's value from
+	// the values templates are stamped with, available as `value`, such
+	// as `value.workload.metadata.name + "-" + value.params.suffix`.
+	Expression string `json:"expression"`
+}
+
+var _ webhook.Validator = &ClusterTemplateLibrary{}
+
+func (c *ClusterTemplateLibrary) ValidateCreate() error {
+	return c.Spec.validate()
+}
+
+func (c *ClusterTemplateLibrary) ValidateUpdate(_ runtime.Object) error {
+	return c.Spec.validate()
+}
+
+func (c *ClusterTemplateLibrary) ValidateDelete() error {
+	return nil
+}
+
+func (s *TemplateLibrarySpec) validate() error {
+	if len(s.Partials) == 0 && len(s.Helpers) == 0 {
+		return errors.New("spec must set partials or helpers")
+	}
+
+	partials := map[string]bool{}
+	for i, partial := range s.Partials {
+		if partial.Name == "" {
+			return fmt.Errorf("spec.partials[%d] must have a name", i)
+		}
+		if partials[partial.Name] {
+			return fmt.Errorf("spec.partials[%d]: duplicate partial name '%s'", i, partial.Name)
+		}
+		partials[partial.Name] = true
+		if !json.Valid(partial.Template.Raw) {
+			return fmt.Errorf("spec.partials[%d]: template must be a value", i)
+		}
+	}
+
+	helpers := map[string]bool{}
+	for i, helper := range s.Helpers {
+		if helper.Name == "" {
+			return fmt.Errorf("spec.helpers[%d] must have a name", i)
+		}
+		if helpers[helper.Name] {
+			return fmt.Errorf("spec.helpers[%d]: duplicate helper name '%s'", i, helper.Name)
+		}
+		helpers[helper.Name] = true
+		if err := transform.Compile(helper.Expression); err != nil {
+			return fmt.Errorf("spec.helpers[%d]: invalid expression: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// +kubebuilder:object:root=true
+
+type ClusterTemplateLibraryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterTemplateLibrary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ClusterTemplateLibrary{},
+		&ClusterTemplateLibraryList{},
+	)
+}
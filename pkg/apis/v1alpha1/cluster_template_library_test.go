@@ -0,0 +1,75 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("ClusterTemplateLibrary", func() {
+	var library *v1alpha1.ClusterTemplateLibrary
+
+	BeforeEach(func() {
+		library = &v1alpha1.ClusterTemplateLibrary{
+			Spec: v1alpha1.TemplateLibrarySpec{
+				Partials: []v1alpha1.TemplatePartial{
+					{Name: "labels", Template: runtime.RawExtension{Raw: []byte(`{"app": "$(workload.metadata.name)$"}`)}},
+				},
+				Helpers: []v1alpha1.TemplateHelper{
+					{Name: "fullName", Expression: `value.workload.metadata.name + "-app"`},
+				},
+			},
+		}
+	})
+
+	It("accepts a well formed library", func() {
+		Expect(library.ValidateCreate()).To(Succeed())
+		Expect(library.ValidateUpdate(nil)).To(Succeed())
+	})
+
+	It("rejects a library with neither partials nor helpers", func() {
+		library.Spec = v1alpha1.TemplateLibrarySpec{}
+		Expect(library.ValidateCreate()).To(MatchError("spec must set partials or helpers"))
+	})
+
+	It("rejects partials without a name", func() {
+		library.Spec.Partials[0].Name = ""
+		Expect(library.ValidateCreate()).To(MatchError("spec.partials[0] must have a name"))
+	})
+
+	It("rejects partials sharing a name", func() {
+		library.Spec.Partials = append(library.Spec.Partials, library.Spec.Partials[0])
+		Expect(library.ValidateCreate()).To(MatchError("spec.partials[1]: duplicate partial name 'labels'"))
+	})
+
+	It("rejects partials without a template", func() {
+		library.Spec.Partials[0].Template = runtime.RawExtension{}
+		Expect(library.ValidateCreate()).To(MatchError("spec.partials[0]: template must be a value"))
+	})
+
+	It("rejects helpers sharing a name", func() {
+		library.Spec.Helpers = append(library.Spec.Helpers, library.Spec.Helpers[0])
+		Expect(library.ValidateCreate()).To(MatchError("spec.helpers[1]: duplicate helper name 'fullName'"))
+	})
+
+	It("rejects helpers whose expression does not compile", func() {
+		library.Spec.Helpers[0].Expression = `value.workload +`
+		Expect(library.ValidateCreate()).To(MatchError(ContainSubstring("spec.helpers[0]: invalid expression")))
+	})
+})
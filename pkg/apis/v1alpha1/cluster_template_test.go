@@ -89,6 +89,35 @@ var _ = Describe("ClusterTemplate", func() {
 				})
 			})
 
+			Context("template is a List", func() {
+				BeforeEach(func() {
+					template.Spec.Template = &runtime.RawExtension{Raw: []byte(`{
+						"apiVersion": "v1",
+						"kind": "List",
+						"items": [
+							{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "some-name"}},
+							{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "some-name"}}
+						]
+					}`)}
+				})
+
+				It("succeeds", func() {
+					Expect(template.ValidateCreate()).To(Succeed())
+				})
+
+				It("returns an error when an item sets its namespace", func() {
+					template.Spec.Template.Raw = []byte(`{"apiVersion": "v1", "kind": "List", "items": [{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "some-name", "namespace": "some-namespace"}}]}`)
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid template: template should not set metadata.namespace on the child object at items[0]"))
+				})
+
+				It("returns an error when it has no items", func() {
+					template.Spec.Template.Raw = []byte(`{"apiVersion": "v1", "kind": "List", "items": []}`)
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid template: a List template must have items"))
+				})
+			})
+
 			Context("template missing", func() {
 				It("succeeds", func() {
 					Expect(template.ValidateCreate()).
@@ -120,6 +149,65 @@ var _ = Describe("ClusterTemplate", func() {
 						To(MatchError("invalid template: must specify one of template or ytt, found both"))
 				})
 			})
+
+			Context("sample without a workload", func() {
+				BeforeEach(func() {
+					template.Spec.Ytt = `hello: #@ data.values.hello`
+					template.Spec.Sample = &v1alpha1.TemplateSample{}
+				})
+
+				It("returns an error", func() {
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid sample: must specify one of workload or workloadRef, found neither"))
+				})
+			})
+
+			Context("job lifecycle", func() {
+				stamp := func(apiVersion, kind string) {
+					raw, err := json.Marshal(&ArbitraryObject{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       kind,
+							APIVersion: apiVersion,
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "some-name",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					template.Spec.Template = &runtime.RawExtension{Raw: raw}
+				}
+
+				BeforeEach(func() {
+					template.Spec.Lifecycle = v1alpha1.JobTemplateLifecycle
+				})
+
+				It("succeeds for a template stamping a Job", func() {
+					stamp("batch/v1", "Job")
+					template.Spec.Results = &v1alpha1.JobResults{From: v1alpha1.ConfigMapJobResults}
+					Expect(template.ValidateCreate()).To(Succeed())
+				})
+
+				It("returns an error for a template stamping anything else", func() {
+					stamp("apps/v1", "Deployment")
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid template: a job lifecycle template must stamp a batch/v1 Job, found apps/v1 Deployment"))
+				})
+
+				It("returns an error for results on a mutable template", func() {
+					stamp("batch/v1", "Job")
+					template.Spec.Lifecycle = ""
+					template.Spec.Results = &v1alpha1.JobResults{}
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid results: only job lifecycle templates have results"))
+				})
+
+				It("returns an error for a plugin realizing the job", func() {
+					stamp("batch/v1", "Job")
+					template.Spec.Plugin = "terraform"
+					Expect(template.ValidateCreate()).
+						To(MatchError("invalid plugin: job lifecycle templates and templates with a preset cannot be realized by a plugin"))
+				})
+			})
 		})
 
 		Describe("#Update", func() {
@@ -16,9 +16,17 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/eval"
+	"github.com/vmware-tanzu/cartographer/pkg/transform"
 )
 
 type DefaultParams []DefaultParam
@@ -26,16 +34,559 @@ type DefaultParams []DefaultParam
 type DefaultParam struct {
 	Name         string               `json:"name"`
 	DefaultValue apiextensionsv1.JSON `json:"default"`
+	// Description tells developers what the param controls.
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Sensitive params have their value redacted in the status, Events and
+	// logs of workloads and deliverables. Templates are stamped with it.
+	// +optional
+	Sensitive bool `json:"sensitive,omitempty"`
 }
 
 type Param struct {
-	Name  string               `json:"name"`
-	Value apiextensionsv1.JSON `json:"value"`
+	Name string `json:"name"`
+	// +optional
+	Value apiextensionsv1.JSON `json:"value,omitempty"`
+	// ValueFrom reads the value of the param from a key of a ConfigMap or
+	// Secret in the namespace of the workload or deliverable, or decrypts it
+	// from a sealed payload, in place of value. Changes to that key are
+	// realized as they happen. Only the params of workloads and deliverables
+	// can set it.
+	// +optional
+	ValueFrom *ParamValueSource `json:"valueFrom,omitempty"`
+	// Sensitive params have their value redacted in the status, Events and
+	// logs of workloads and deliverables. Templates are stamped with it.
+	// Params read from a Secret or decrypted from a sealed payload are
+	// always sensitive.
+	// +optional
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// ParamValueSource is a source of the value of a param. Exactly one of its
+// fields must be set.
+type ParamValueSource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap. The value is read as
+	// the string at that key.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// SecretKeyRef selects a key of a Secret. The value is read as the
+	// decoded string at that key.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+	// Sealed decrypts the value from an encrypted payload with a private key
+	// read from a Secret, so that neither the workload nor the blueprint
+	// holds it in plain text.
+	// +optional
+	Sealed *SealedValueSource `json:"sealed,omitempty"`
+}
+
+// SealedValueSource is an encrypted value and the key that decrypts it.
+type SealedValueSource struct {
+	// Format of the encrypted value. "sealedSecrets" is a value sealed with
+	// `kubeseal --raw`, decrypted with the private key of the sealing
+	// certificate; the value of the param is the decrypted string. "sops" is
+	// a YAML or JSON document encrypted with sops for an age recipient,
+	// holding the namespace of the workload or deliverable under `namespace`
+	// and the value of the param under `value`.
+	// +kubebuilder:validation:Enum=sealedSecrets;sops
+	Format string `json:"format"`
+	// EncryptedValue is the encrypted value: the base64 output of kubeseal,
+	// or the sops document.
+	EncryptedValue string `json:"encryptedValue"`
+	// Scope of a sealedSecrets value, as given to kubeseal.
+	// "namespace-wide", the default, only decrypts values sealed for the
+	// namespace of the workload or deliverable. "cluster-wide" decrypts
+	// values sealed for any namespace.
+	// +kubebuilder:validation:Enum=namespace-wide;cluster-wide
+	// +optional
+	Scope string `json:"scope,omitempty"`
+	// KeyRef selects the key of a Secret in cartographer-system that holds
+	// the private key: the PEM encoded RSA key of a sealed-secrets key pair,
+	// or an age identity for sops. The Secret must be labelled
+	// carto.run/sealing-key: "true". Keys are never read from the namespace
+	// of the workload or deliverable.
+	KeyRef corev1.SecretKeySelector `json:"keyRef"`
+}
+
+const (
+	SealedSecretsFormat = "sealedSecrets"
+	SOPSFormat          = "sops"
+)
+
+const (
+	NamespaceWideSealScope = "namespace-wide"
+	ClusterWideSealScope   = "cluster-wide"
+)
+
+// SealingKeyLabel marks, with the value "true", the Secrets in the
+// controller's namespace that sealed params may be decrypted with. Other
+// Secrets there are never read for them.
+const SealingKeyLabel = "carto.run/sealing-key"
+
+// IsSensitive reports whether the value of the param is redacted: it is
+// marked sensitive, or read from a Secret or a sealed payload.
+func (p Param) IsSensitive() bool {
+	return p.Sensitive || (p.ValueFrom != nil && (p.ValueFrom.SecretKeyRef != nil || p.ValueFrom.Sealed != nil))
+}
+
+// validateResourceParams checks that the params of a blueprint resource do
+// not read their value from the cluster, which only the params of workloads
+// and deliverables can.
+func validateResourceParams(params []Param) error {
+	for _, param := range params {
+		if param.ValueFrom != nil {
+			return fmt.Errorf("param '%s' cannot set valueFrom", param.Name)
+		}
+	}
+	return nil
 }
 
 type ResourceReference struct {
 	Name     string `json:"name"`
 	Resource string `json:"resource"`
+
+	// Transform reshapes the consumed output before the template sees it. It
+	// is either the name of one of the blueprint's transforms or a CEL
+	// expression, which reads the output as `value`: a source as a map with
+	// `url` and `revision`, an image or a config as is. A source transform
+	// must return a map of the same shape.
+	// +optional
+	Transform string `json:"transform,omitempty"`
+
+	// WhileWaiting is what the consuming resource does while the providing
+	// resource's object has not produced outputs yet. "block", the default,
+	// leaves the consuming resource's object as it is until it has.
+	// "useLastOutputs" stamps it with the outputs last read from the
+	// providing resource, which templates see as stale.
+	// +kubebuilder:validation:Enum=block;useLastOutputs
+	// +optional
+	WhileWaiting string `json:"whileWaiting,omitempty"`
+}
+
+const (
+	BlockWhileWaiting          = "block"
+	UseLastOutputsWhileWaiting = "useLastOutputs"
+)
+
+const (
+	RemediateDrift = "remediate"
+	DetectDrift    = "detect"
+)
+
+const (
+	DeleteDeletionPolicy = "Delete"
+	OrphanDeletionPolicy = "Orphan"
+)
+
+// OrphansOnDeletion reports whether the object stamped for a resource is
+// left behind when its owner is deleted: by the resource's deletion policy
+// or, when it has none, by the owner's. Objects are deleted by default.
+func OrphansOnDeletion(resourcePolicy, ownerPolicy string) bool {
+	if resourcePolicy != "" {
+		return resourcePolicy == OrphanDeletionPolicy
+	}
+	return ownerPolicy == OrphanDeletionPolicy
+}
+
+const (
+	OwnerReferenceOwnership = "OwnerReference"
+	TrackedOwnership        = "Tracked"
+)
+
+const (
+	ApplySubmission       = "Apply"
+	PullRequestSubmission = "PullRequest"
+)
+
+// TrackedOwnerAnnotation holds, on an object stamped for a resource with
+// Tracked ownership, the UID of the owner it was stamped for. Such objects
+// have no owner reference: Cartographer deletes them itself once that owner
+// is gone.
+const TrackedOwnerAnnotation = "carto.run/tracked-owner-uid"
+
+// DriftedObject is an object stamped for a resource with drift: detect
+// that was changed by something other than Cartographer.
+type DriftedObject struct {
+	Resource string          `json:"resource"`
+	Object   ObjectReference `json:"object"`
+	// Fields are the paths of the fields the template sets that differ.
+	Fields []string `json:"fields"`
+}
+
+// MissingOutput is an optional output of a resource that its stamped object
+// has not populated, and which was output as null.
+type MissingOutput struct {
+	Resource string `json:"resource"`
+	// Output is the name of the output: url, revision, image or config.
+	Output string `json:"output"`
+	// Expression is the path or CEL expression that found nothing to read.
+	Expression string `json:"expression"`
+}
+
+// FieldConflict is an object stamped for a resource whose fields, last set
+// by another field manager, Cartographer set back to what its template
+// stamps.
+type FieldConflict struct {
+	Resource string          `json:"resource"`
+	Object   ObjectReference `json:"object"`
+	// Manager is the field manager that set the fields.
+	Manager string   `json:"manager"`
+	Fields  []string `json:"fields"`
+}
+
+// UsesLastOutputs reports whether the consuming resource is stamped with
+// the last outputs of the providing resource while it waits for new ones.
+func (r ResourceReference) UsesLastOutputs() bool {
+	return r.WhileWaiting == UseLastOutputsWhileWaiting
+}
+
+// LastOutput is the output last read from the object stamped for a
+// resource, kept for consumers that use it while the resource waits for
+// new outputs.
+type LastOutput struct {
+	Resource string `json:"resource"`
+	// Output is the source, image or config the resource output.
+	Output apiextensionsv1.JSON `json:"output"`
+}
+
+// ResourceTemplatesStatus tells whether the templates a resource of a supply
+// chain or delivery refers to were found.
+type ResourceTemplatesStatus struct {
+	Resource string `json:"resource"`
+	// ResolvedTemplateRefs are the template objects the templateRef of the
+	// resource was resolved to: one for each of its options, or the
+	// template of the version it is pinned to.
+	// +optional
+	ResolvedTemplateRefs []ResolvedTemplateRef `json:"resolvedTemplateRefs,omitempty"`
+	// UnresolvedTemplateRefs are the templates of the resource that were not
+	// found, as kind/name.
+	// +optional
+	UnresolvedTemplateRefs []string `json:"unresolvedTemplateRefs,omitempty"`
+	// Conditions hold the TemplatesReady condition of the resource.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ResolvedTemplateRef is a template object a templateRef was resolved to.
+type ResolvedTemplateRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Generation is the generation of the template when it was resolved.
+	Generation int64 `json:"generation"`
+}
+
+// ResolvedResourceTemplate is the template object a resource was resolved to
+// for an owner, which may depend on the owner through the options of the
+// resource's templateRef.
+type ResolvedResourceTemplate struct {
+	Resource string              `json:"resource"`
+	Template ResolvedTemplateRef `json:"template"`
+}
+
+// RolloutStatus counts the owners realized with a blueprint. Each owner is
+// counted once: as failing when it is not Ready, as ready when it is Ready
+// and was realized with the current generations of its spec and of the
+// blueprint, and as stale otherwise.
+type RolloutStatus struct {
+	// Total is the number of owners realized with the blueprint.
+	Total int `json:"total"`
+	Ready int `json:"ready"`
+	// Failing owners have a Ready condition that is False.
+	Failing int `json:"failing"`
+	// Stale owners have yet to be realized with the current generation of
+	// their spec or of the blueprint.
+	Stale int `json:"stale"`
+}
+
+// ResourceRetries counts the attempts to realize a resource that failed or
+// had to wait for outputs in the current realization. A realization starts
+// when the owner's spec changes, or when a resource fails after the owner
+// was ready.
+type ResourceRetries struct {
+	Resource string `json:"resource"`
+	Retries  int64  `json:"retries"`
+}
+
+// TotalRetries sums the retries of every resource.
+func TotalRetries(retries []ResourceRetries) int64 {
+	var total int64
+	for _, r := range retries {
+		total += r.Retries
+	}
+	return total
+}
+
+// RealizationRecord is a realization that submitted every resource of an
+// owner, recorded when it realized other inputs, or stamped other objects,
+// than the realization recorded before it.
+type RealizationRecord struct {
+	// Time is when the realization completed.
+	Time metav1.Time `json:"time"`
+
+	// Generation is the owner's generation realized.
+	Generation int64 `json:"generation"`
+
+	// Manager is the field manager that last changed the owner's spec.
+	// +optional
+	Manager string `json:"manager,omitempty"`
+
+	// ParamsDigest is a digest of the owner's params.
+	// +optional
+	ParamsDigest string `json:"paramsDigest,omitempty"`
+
+	// Blueprint is the name of the supply chain or delivery realized.
+	Blueprint string `json:"blueprint"`
+
+	// BlueprintGeneration is the generation of the blueprint realized.
+	BlueprintGeneration int64 `json:"blueprintGeneration"`
+
+	// Resources are the resources submitted, in name order.
+	// +optional
+	// +listType=map
+	// +listMapKey=resource
+	Resources []RealizedResource `json:"resources,omitempty"`
+
+	// Changes describe what differs from the realization recorded before.
+	// +optional
+	Changes []string `json:"changes,omitempty"`
+}
+
+// RealizedResource is a resource as submitted in a realization.
+type RealizedResource struct {
+	Resource string `json:"resource"`
+
+	// Template is the template the resource was stamped from.
+	Template ObjectReference `json:"template"`
+
+	// TemplateGeneration is the generation of the template.
+	TemplateGeneration int64 `json:"templateGeneration"`
+
+	// SourceRevision is the revision of the source the resource output,
+	// if any.
+	// +optional
+	SourceRevision string `json:"sourceRevision,omitempty"`
+
+	// Stamped is the object stamped for the resource.
+	Stamped ObjectReference `json:"stamped"`
+}
+
+// OutputTransform is a named CEL expression that a blueprint's resource
+// references can apply to the outputs they consume.
+type OutputTransform struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Expression is the CEL expression, reading the output as `value`.
+	// +kubebuilder:validation:MinLength=1
+	Expression string `json:"expression"`
+}
+
+// ResolveTransforms returns a copy of references in which every transform
+// naming one of transforms is replaced by that transform's expression.
+func ResolveTransforms(references []ResourceReference, transforms []OutputTransform) []ResourceReference {
+	if references == nil {
+		return nil
+	}
+
+	resolved := make([]ResourceReference, len(references))
+	for i, reference := range references {
+		for _, transform := range transforms {
+			if reference.Transform == transform.Name {
+				reference.Transform = transform.Expression
+				break
+			}
+		}
+		resolved[i] = reference
+	}
+	return resolved
+}
+
+// validateTransforms checks that transforms have unique names and that
+// they, and the transforms of references, compile.
+func validateTransforms(transforms []OutputTransform, references ...[]ResourceReference) error {
+	names := map[string]bool{}
+	for _, t := range transforms {
+		if names[t.Name] {
+			return fmt.Errorf("duplicate transform name '%s'", t.Name)
+		}
+		names[t.Name] = true
+		if err := transform.Compile(t.Expression); err != nil {
+			return fmt.Errorf("invalid transform '%s': %w", t.Name, err)
+		}
+	}
+
+	for _, refs := range references {
+		for _, ref := range ResolveTransforms(refs, transforms) {
+			if ref.Transform == "" {
+				continue
+			}
+			if err := transform.Compile(ref.Transform); err != nil {
+				return fmt.Errorf("invalid transform for '%s': %w", ref.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SchedulingHints are applied to every pod-bearing object stamped for a
+// blueprint's resources, so that platform-wide scheduling and cost settings
+// live in one place instead of in each template.
+type SchedulingHints struct {
+	// PriorityClassName is set on pod specs that do not already name one.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Tolerations are added to pod specs, alongside any the template
+	// declares.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Annotations, such as cost centres or resource tiers, are added to the
+	// stamped object and to its pod template where not already set.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Merge returns h overlaid with override: the priority class and annotations
+// of override win, and the tolerations of both apply.
+func (h *SchedulingHints) Merge(override *SchedulingHints) *SchedulingHints {
+	if h == nil {
+		return override
+	}
+	if override == nil {
+		return h
+	}
+
+	merged := h.DeepCopy()
+	if override.PriorityClassName != "" {
+		merged.PriorityClassName = override.PriorityClassName
+	}
+	merged.Tolerations = append(merged.Tolerations, override.Tolerations...)
+	for key, value := range override.Annotations {
+		if merged.Annotations == nil {
+			merged.Annotations = map[string]string{}
+		}
+		merged.Annotations[key] = value
+	}
+	return merged
+}
+
+// MetadataPropagation says which of the owner's labels and annotations are
+// copied onto the objects stamped for it, so that organisation-wide metadata,
+// such as a cost centre, flows downstream. The carto.run labels Cartographer
+// identifies stamped objects by are always set, and the template's own labels
+// and annotations are never overwritten.
+type MetadataPropagation struct {
+	// Labels policy. Defaults to propagating none.
+	// +optional
+	Labels *PropagationPolicy `json:"labels,omitempty"`
+
+	// Annotations policy. Defaults to propagating none.
+	// +optional
+	Annotations *PropagationPolicy `json:"annotations,omitempty"`
+}
+
+const (
+	PropagateAll       = "All"
+	PropagateAllowlist = "Allowlist"
+	PropagateNone      = "None"
+)
+
+// PropagationPolicy selects the keys of labels or annotations to propagate.
+type PropagationPolicy struct {
+	// Policy is "All", which propagates every key but those in the
+	// carto.run, kubernetes.io and k8s.io domains, "Allowlist", which
+	// propagates the keys listed, or "None".
+	// +kubebuilder:validation:Enum=All;Allowlist;None
+	Policy string `json:"policy"`
+
+	// Keys propagated by the "Allowlist" policy. A key ending in "/*", such
+	// as example.com/*, allows every key of that prefix.
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+}
+
+// Merge returns p overlaid with override: the labels and annotations
+// policies override sets replace p's.
+func (p *MetadataPropagation) Merge(override *MetadataPropagation) *MetadataPropagation {
+	if p == nil {
+		return override
+	}
+	if override == nil {
+		return p
+	}
+
+	merged := p.DeepCopy()
+	if override.Labels != nil {
+		merged.Labels = override.Labels
+	}
+	if override.Annotations != nil {
+		merged.Annotations = override.Annotations
+	}
+	return merged
+}
+
+// Propagates reports whether the policy propagates key.
+func (p *PropagationPolicy) Propagates(key string) bool {
+	if p == nil {
+		return false
+	}
+
+	switch p.Policy {
+	case PropagateAll:
+		return !reservedMetadataKey(key)
+	case PropagateAllowlist:
+		for _, allowed := range p.Keys {
+			if allowed == key || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(key, strings.TrimSuffix(allowed, "*"))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *PropagationPolicy) validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.Policy != PropagateAllowlist && len(p.Keys) > 0 {
+		return fmt.Errorf("keys are only valid with the %s policy", PropagateAllowlist)
+	}
+	for _, key := range p.Keys {
+		if key == "" || key == "/*" {
+			return fmt.Errorf("key '%s' is invalid", key)
+		}
+	}
+	return nil
+}
+
+func (p *MetadataPropagation) validate() error {
+	if p == nil {
+		return nil
+	}
+	if err := p.Labels.validate(); err != nil {
+		return fmt.Errorf("labels: %w", err)
+	}
+	if err := p.Annotations.validate(); err != nil {
+		return fmt.Errorf("annotations: %w", err)
+	}
+	return nil
+}
+
+// reservedMetadataKey reports whether key belongs to Cartographer or
+// Kubernetes, whose labels and annotations describe the owner itself.
+func reservedMetadataKey(key string) bool {
+	slash := strings.Index(key, "/")
+	if slash < 0 {
+		return false
+	}
+	prefix := key[:slash]
+	for _, domain := range []string{"carto.run", "kubernetes.io", "k8s.io"} {
+		if prefix == domain || strings.HasSuffix(prefix, "."+domain) {
+			return true
+		}
+	}
+	return false
 }
 
 type Source struct {
@@ -43,6 +594,44 @@ type Source struct {
 	// Image is an OCI image is a registry that contains source code
 	Image   *string `json:"image,omitempty"`
 	Subpath *string `json:"subPath,omitempty"`
+
+	// OCI is an artifact in an OCI registry, such as an imgpkg bundle or an
+	// ORAS artifact. Its reference is resolved to a digest, published in
+	// status.source, and resolved again every minute so that artifacts
+	// pushed again are picked up. Cannot be set with git or image.
+	// +optional
+	OCI *OCISource `json:"oci,omitempty"`
+}
+
+// OCISource locates an artifact in an OCI registry.
+type OCISource struct {
+	// Image is the artifact's reference, by tag or digest, e.g.
+	// registry.example.com/team/app-bundle:main.
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// SecretRef is a kubernetes.io/dockerconfigjson Secret, in the owner's
+	// namespace, holding credentials for the registry. The artifact is
+	// resolved anonymously when unset.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Insecure talks to the registry over plain HTTP.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// ResolvedSource is an OCI source as last resolved.
+type ResolvedSource struct {
+	// Image is the reference that was resolved, as in spec.source.oci.image.
+	Image string `json:"image"`
+
+	// Digest is the digest of the artifact's manifest.
+	Digest string `json:"digest"`
+
+	// URL is the reference pinned to the digest, image@digest, for
+	// templates to use: $(workload.status.source.url)$.
+	URL string `json:"url"`
 }
 
 type GitSource struct {
@@ -82,3 +671,228 @@ func GetAPITemplate(templateKind string) (client.Object, error) {
 	}
 	return template, nil
 }
+
+// validateOutputPath rejects an output path that would fail to parse at
+// stamp time. Presence is left to the CRD schema.
+func validateOutputPath(field, path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := eval.ValidateJsonPath(path); err != nil {
+		return fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return nil
+}
+
+// validateOutputNames rejects names, listed at field, that are not among
+// the outputs of the template.
+func validateOutputNames(field string, names []string, outputs ...string) error {
+	for _, name := range names {
+		known := false
+		for _, output := range outputs {
+			known = known || name == output
+		}
+		if !known {
+			return fmt.Errorf("invalid %s: '%s' is not one of %s", field, name, strings.Join(outputs, ", "))
+		}
+	}
+	return nil
+}
+
+// validateOutputExpression rejects an output expression that does not
+// compile, or that is set alongside the path of the same output.
+func validateOutputExpression(field, expression, pathField, path string) error {
+	if expression == "" {
+		return nil
+	}
+	if path != "" {
+		return fmt.Errorf("only one of %s and %s may be set", pathField, field)
+	}
+	if err := transform.Compile(expression); err != nil {
+		return fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return nil
+}
+
+// TemplateNameLabel and TemplateVersionLabel mark a template as a version of
+// a named template, which templateRefs pinning a version select.
+const (
+	TemplateNameLabel    = "carto.run/template-name"
+	TemplateVersionLabel = "carto.run/template-version"
+)
+
+// ResyncIntervalAnnotation sets, on a workload or deliverable, how often it
+// is reconciled again once ready, as a duration such as 30s or 10m.
+const ResyncIntervalAnnotation = "carto.run/resync-interval"
+
+// GetResyncInterval returns the interval set by obj's
+// ResyncIntervalAnnotation, or zero when it has none.
+func GetResyncInterval(obj metav1.Object) (time.Duration, error) {
+	value, ok := obj.GetAnnotations()[ResyncIntervalAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation: %w", ResyncIntervalAnnotation, err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("invalid %s annotation: '%s' is not positive", ResyncIntervalAnnotation, value)
+	}
+	return interval, nil
+}
+
+// ControllerNamespace is the namespace Cartographer runs in.
+const ControllerNamespace = "cartographer-system"
+
+// SecretNamespace is the only namespace a blueprint in namespace may read
+// Secrets from: its own, or ControllerNamespace for cluster-scoped
+// blueprints, so that blueprint authors cannot have the controller read
+// Secrets of namespaces they do not own.
+func SecretNamespace(namespace string) string {
+	if namespace == "" {
+		return ControllerNamespace
+	}
+	return namespace
+}
+
+// TeardownFinalizer keeps a blueprint with a teardown policy around until
+// the objects stamped on its behalf have been deleted or orphaned.
+const TeardownFinalizer = "carto.run/teardown"
+
+const (
+	DeleteTeardownPolicy = "Delete"
+	OrphanTeardownPolicy = "Orphan"
+)
+
+// PreDeleteFinalizer keeps a workload or deliverable around until the
+// pre-delete hooks of its blueprint have completed.
+const PreDeleteFinalizer = "carto.run/pre-delete"
+
+const (
+	FailPreDeleteHookFailurePolicy   = "Fail"
+	IgnorePreDeleteHookFailurePolicy = "Ignore"
+)
+
+// The PreDeleteHooksCompleted condition is only reported on a workload or
+// deliverable being deleted, while its blueprint's pre-delete hooks hold it
+// back.
+const (
+	PreDeleteHooksCompleted              = "PreDeleteHooksCompleted"
+	RunningPreDeleteHooksCompletedReason = "HookRunning"
+	FailedPreDeleteHooksCompletedReason  = "HookFailed"
+)
+
+// PreDeleteHook is a Job stamped when a workload or deliverable is deleted,
+// and waited for before it goes, to clean up what its objects created
+// outside the cluster, such as DNS records or image repositories.
+type PreDeleteHook struct {
+	// Name of the hook, unique within the blueprint.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// TemplateRef names the template the hook's Job is stamped from. The
+	// Job is stamped once, as for a template with a job lifecycle, and the
+	// hook completes when it does.
+	TemplateRef PreDeleteHookTemplateReference `json:"templateRef"`
+
+	// Params of the template, as for a resource.
+	// +optional
+	Params []Param `json:"params,omitempty"`
+
+	// FailurePolicy is what happens when the hook's Job fails: "Fail", the
+	// default, keeps the owner from being deleted until the Job is deleted
+	// and runs again successfully; "Ignore" moves on to the next hook.
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	// +optional
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+type PreDeleteHookTemplateReference struct {
+	// +kubebuilder:validation:Enum=ClusterTemplate
+	Kind string `json:"kind"`
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+func validatePreDeleteHooks(hooks []PreDeleteHook) error {
+	names := map[string]bool{}
+	for _, hook := range hooks {
+		if names[hook.Name] {
+			return fmt.Errorf("duplicate pre-delete hook name '%s'", hook.Name)
+		}
+		names[hook.Name] = true
+
+		if err := validateResourceParams(hook.Params); err != nil {
+			return fmt.Errorf("invalid params for pre-delete hook '%s': %w", hook.Name, err)
+		}
+	}
+	return nil
+}
+
+// The TornDown condition is only reported on a blueprint being deleted,
+// and keeps it from being ready so that owners stop stamping for it.
+const (
+	BlueprintTornDown            = "TornDown"
+	TearingDownTornDownReason    = "TearingDown"
+	TeardownFailedTornDownReason = "TeardownFailed"
+)
+
+// GitTemplateSource locates a template stored in a git repository.
+type GitTemplateSource struct {
+	// URL of the repository, served over HTTP(S).
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Ref is the branch, tag or commit the template is read from. Defaults
+	// to the repository's HEAD. Branches and tags are resolved again every
+	// minute, so that changes pushed to them are picked up.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// Path of the YAML file holding the template in the repository.
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// SecretRef is a kubernetes.io/basic-auth Secret holding the username
+	// and password the repository is fetched with. It must be in the
+	// blueprint's namespace, or in cartographer-system for cluster-scoped
+	// blueprints. Fetches are anonymous without it.
+	// +optional
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+}
+
+func (s GitTemplateSource) String() string {
+	ref := s.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return fmt.Sprintf("%s//%s@%s", s.URL, strings.TrimPrefix(s.Path, "/"), ref)
+}
+
+// validateSecretRef checks that the Secret the repository is fetched with
+// is one the blueprint in namespace may read.
+func (s *GitTemplateSource) validateSecretRef(namespace string) error {
+	if s == nil || s.SecretRef == nil {
+		return nil
+	}
+	if allowed := SecretNamespace(namespace); s.SecretRef.Namespace != allowed {
+		return fmt.Errorf("git.secretRef must be in namespace '%s', not '%s'", allowed, s.SecretRef.Namespace)
+	}
+	return nil
+}
+
+func validateTemplateRef(name, version string, git *GitTemplateSource) error {
+	if (name == "") == (git == nil) {
+		return fmt.Errorf("exactly one of name and git must be set")
+	}
+	if version != "" && git != nil {
+		return fmt.Errorf("version cannot be set with git, pin git.ref instead")
+	}
+	if version != "" {
+		if errs := validation.IsValidLabelValue(version); len(errs) > 0 {
+			return fmt.Errorf("version '%s' is not a valid label value: %s", version, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
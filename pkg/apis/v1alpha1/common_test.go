@@ -16,10 +16,12 @@ package v1alpha1_test
 
 import (
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
 )
@@ -49,4 +51,152 @@ var _ = Describe("common", func() {
 		})
 
 	})
+
+	Describe("SchedulingHints.Merge", func() {
+		var chain *v1alpha1.SchedulingHints
+
+		BeforeEach(func() {
+			chain = &v1alpha1.SchedulingHints{
+				PriorityClassName: "chain-priority",
+				Tolerations:       []corev1.Toleration{{Key: "chain", Operator: corev1.TolerationOpExists}},
+				Annotations:       map[string]string{"cost-center": "platform", "tier": "standard"},
+			}
+		})
+
+		It("returns the other hints when either is nil", func() {
+			var none *v1alpha1.SchedulingHints
+			Expect(none.Merge(chain)).To(Equal(chain))
+			Expect(chain.Merge(nil)).To(Equal(chain))
+		})
+
+		It("lets the override's priority class and annotations win and keeps both sets of tolerations", func() {
+			merged := chain.Merge(&v1alpha1.SchedulingHints{
+				PriorityClassName: "resource-priority",
+				Tolerations:       []corev1.Toleration{{Key: "resource", Operator: corev1.TolerationOpExists}},
+				Annotations:       map[string]string{"tier": "gpu"},
+			})
+
+			Expect(merged.PriorityClassName).To(Equal("resource-priority"))
+			Expect(merged.Tolerations).To(Equal([]corev1.Toleration{
+				{Key: "chain", Operator: corev1.TolerationOpExists},
+				{Key: "resource", Operator: corev1.TolerationOpExists},
+			}))
+			Expect(merged.Annotations).To(Equal(map[string]string{"cost-center": "platform", "tier": "gpu"}))
+		})
+
+		It("keeps the chain's priority class when the override names none", func() {
+			merged := chain.Merge(&v1alpha1.SchedulingHints{Annotations: map[string]string{"team": "a"}})
+			Expect(merged.PriorityClassName).To(Equal("chain-priority"))
+		})
+
+		It("does not modify either set of hints", func() {
+			override := &v1alpha1.SchedulingHints{Annotations: map[string]string{"tier": "gpu"}}
+			chain.Merge(override)
+			Expect(chain.Annotations).To(Equal(map[string]string{"cost-center": "platform", "tier": "standard"}))
+			Expect(override.Annotations).To(Equal(map[string]string{"tier": "gpu"}))
+		})
+	})
+
+	Describe("MetadataPropagation.Merge", func() {
+		var chain *v1alpha1.MetadataPropagation
+
+		BeforeEach(func() {
+			chain = &v1alpha1.MetadataPropagation{
+				Labels:      &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+				Annotations: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"team"}},
+			}
+		})
+
+		It("returns the other propagation when either is nil", func() {
+			var none *v1alpha1.MetadataPropagation
+			Expect(none.Merge(chain)).To(Equal(chain))
+			Expect(chain.Merge(nil)).To(Equal(chain))
+		})
+
+		It("replaces only the policies the override sets", func() {
+			merged := chain.Merge(&v1alpha1.MetadataPropagation{
+				Labels: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateNone},
+			})
+
+			Expect(merged.Labels).To(Equal(&v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateNone}))
+			Expect(merged.Annotations).To(Equal(chain.Annotations))
+			Expect(chain.Labels.Policy).To(Equal(v1alpha1.PropagateAll))
+		})
+	})
+
+	DescribeTable("PropagationPolicy.Propagates",
+		func(policy *v1alpha1.PropagationPolicy, key string, expected bool) {
+			Expect(policy.Propagates(key)).To(Equal(expected))
+		},
+		Entry("propagates nothing without a policy", nil, "cost-center", false),
+		Entry("propagates nothing with None", &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateNone}, "cost-center", false),
+		Entry("propagates any key with All", &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll}, "example.com/cost-center", true),
+		Entry("keeps Cartographer's keys with All", &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll}, "carto.run/workload-name", false),
+		Entry("keeps Kubernetes' keys with All", &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll}, "kubectl.kubernetes.io/last-applied-configuration", false),
+		Entry("propagates listed keys with Allowlist", &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"cost-center"}}, "cost-center", true),
+		Entry("keeps unlisted keys with Allowlist", &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"cost-center"}}, "team", false),
+		Entry("propagates keys by prefix with Allowlist", &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"example.com/*"}}, "example.com/team", true),
+		Entry("matches prefixes by whole domain", &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"example.com/*"}}, "example.company/team", false),
+	)
+
+	Describe("ResolveTransforms", func() {
+		transforms := []v1alpha1.OutputTransform{
+			{Name: "wrap", Expression: `{"manifest": value}`},
+		}
+
+		It("replaces transform names with their expressions", func() {
+			references := []v1alpha1.ResourceReference{
+				{Name: "named", Resource: "a", Transform: "wrap"},
+				{Name: "inline", Resource: "b", Transform: `value + "!"`},
+				{Name: "plain", Resource: "c"},
+			}
+
+			Expect(v1alpha1.ResolveTransforms(references, transforms)).To(Equal([]v1alpha1.ResourceReference{
+				{Name: "named", Resource: "a", Transform: `{"manifest": value}`},
+				{Name: "inline", Resource: "b", Transform: `value + "!"`},
+				{Name: "plain", Resource: "c"},
+			}))
+			Expect(references[0].Transform).To(Equal("wrap"))
+		})
+
+		It("returns nil for nil references", func() {
+			Expect(v1alpha1.ResolveTransforms(nil, transforms)).To(BeNil())
+		})
+	})
+
+	Describe("GetResyncInterval", func() {
+		It("parses the annotation", func() {
+			workload := &v1alpha1.Workload{}
+			workload.Annotations = map[string]string{v1alpha1.ResyncIntervalAnnotation: "90s"}
+			Expect(v1alpha1.GetResyncInterval(workload)).To(Equal(90 * time.Second))
+		})
+
+		It("is zero without the annotation", func() {
+			Expect(v1alpha1.GetResyncInterval(&v1alpha1.Workload{})).To(BeZero())
+		})
+
+		It("rejects malformed durations", func() {
+			workload := &v1alpha1.Workload{}
+			workload.Annotations = map[string]string{v1alpha1.ResyncIntervalAnnotation: "often"}
+			_, err := v1alpha1.GetResyncInterval(workload)
+			Expect(err).To(MatchError(ContainSubstring("invalid carto.run/resync-interval annotation")))
+		})
+
+		It("rejects intervals that are not positive", func() {
+			workload := &v1alpha1.Workload{}
+			workload.Annotations = map[string]string{v1alpha1.ResyncIntervalAnnotation: "0s"}
+			_, err := v1alpha1.GetResyncInterval(workload)
+			Expect(err).To(MatchError("invalid carto.run/resync-interval annotation: '0s' is not positive"))
+		})
+	})
+
+	DescribeTable("OrphansOnDeletion",
+		func(resourcePolicy, ownerPolicy string, expected bool) {
+			Expect(v1alpha1.OrphansOnDeletion(resourcePolicy, ownerPolicy)).To(Equal(expected))
+		},
+		Entry("deletes by default", "", "", false),
+		Entry("follows the owner's policy", "", v1alpha1.OrphanDeletionPolicy, true),
+		Entry("lets the resource's policy win over the owner's", v1alpha1.DeleteDeletionPolicy, v1alpha1.OrphanDeletionPolicy, false),
+		Entry("orphans when the resource says so", v1alpha1.OrphanDeletionPolicy, "", true),
+	)
 })
@@ -0,0 +1,32 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha1
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha1
+
+// v1alpha1 is the storage version, and the hub other versions convert
+// through.
+
+func (*ClusterSupplyChain) Hub() {}
+
+func (*ClusterDelivery) Hub() {}
+
+func (*ClusterSourceTemplate) Hub() {}
+
+func (*ClusterImageTemplate) Hub() {}
+
+func (*ClusterConfigTemplate) Hub() {}
@@ -15,6 +15,7 @@
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -22,6 +23,8 @@ const (
 	DeliverableReady              = "Ready"
 	DeliverableDeliveryReady      = "DeliveryReady"
 	DeliverableResourcesSubmitted = "ResourcesSubmitted"
+	DeliverableDrifted            = "Drifted"
+	DeliverableFieldConflict      = "FieldConflict"
 )
 
 const (
@@ -35,12 +38,36 @@ const (
 const (
 	CompleteResourcesSubmittedReason                       = "ResourceSubmissionComplete"
 	TemplateObjectRetrievalFailureResourcesSubmittedReason = "TemplateObjectRetrievalFailure"
+	TemplateNotFoundResourcesSubmittedReason               = "TemplateNotFound"
+	TemplateOptionsMatchErrorResourcesSubmittedReason      = "TemplateOptionsMatchError"
+	SourceResolutionFailedResourcesSubmittedReason         = "SourceResolutionFailed"
+	ParamResolutionFailedResourcesSubmittedReason          = "ParamResolutionFailed"
+	ClusterDataUnavailableResourcesSubmittedReason         = "ClusterDataUnavailable"
+	PermissionsNotMetResourcesSubmittedReason              = "PermissionsNotMet"
+	TargetUnavailableResourcesSubmittedReason              = "TargetUnavailable"
 	MissingValueAtPathResourcesSubmittedReason             = "MissingValueAtPath"
+	InvalidOutputPathResourcesSubmittedReason              = "InvalidOutputPath"
 	TemplateStampFailureResourcesSubmittedReason           = "TemplateStampFailure"
 	TemplateRejectedByAPIServerResourcesSubmittedReason    = "TemplateRejectedByAPIServer"
+	TemplateApplyConflictResourcesSubmittedReason          = "TemplateApplyConflict"
+	PolicyViolationResourcesSubmittedReason                = "PolicyViolation"
+	StampedObjectRejectedResourcesSubmittedReason          = "StampedObjectRejected"
+	KindNotRegisteredResourcesSubmittedReason              = "KindNotRegistered"
+	JobRunningResourcesSubmittedReason                     = "JobRunning"
+	JobFailedResourcesSubmittedReason                      = "JobFailed"
+	PluginRunningResourcesSubmittedReason                  = "PluginRunning"
+	PluginFailedResourcesSubmittedReason                   = "PluginFailed"
+	PullRequestOpenResourcesSubmittedReason                = "PullRequestOpen"
+	PullRequestClosedResourcesSubmittedReason              = "PullRequestClosed"
+	PullRequestFailedResourcesSubmittedReason              = "PullRequestFailed"
 	UnknownErrorResourcesSubmittedReason                   = "UnknownError"
 )
 
+const (
+	StampedObjectsDriftedReason = "StampedObjectsDrifted"
+	FieldManagerConflictReason  = "FieldManagerConflict"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -54,12 +81,164 @@ type Deliverable struct {
 type DeliverableSpec struct {
 	Params []Param `json:"params,omitempty"`
 	Source *Source `json:"source,omitempty"`
+
+	// Sources are further sources of the deliverable, such as a config
+	// repository alongside an image, that templates reference by name:
+	// $(deliverableSources.<name>.git.url)$.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Sources []DeliverableSource `json:"sources,omitempty"`
+
+	// NamePrefix, when set, is presented to templates as the deliverable name
+	// ($(deliverable.metadata.name)$) in place of metadata.name, so objects stamped
+	// from it keep names chosen before the deliverable was adopted.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	NamePrefix string `json:"namePrefix,omitempty"`
+
+	// DeletionPolicy is what happens to the objects stamped for the deliverable
+	// when it is deleted, for resources that do not set their own:
+	// "Delete", the default, deletes them along with it; "Orphan" leaves
+	// them behind.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
 }
 
 type DeliverableStatus struct {
 	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
 	Conditions         []metav1.Condition `json:"conditions,omitempty"`
 	DeliveryRef        ObjectReference    `json:"deliveryRef,omitempty"`
+
+	// Source is spec.source.oci as last resolved.
+	// +optional
+	Source *ResolvedSource `json:"source,omitempty"`
+
+	// Sources are the OCI sources in spec.sources as last resolved.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Sources []ResolvedDeliverableSource `json:"sources,omitempty"`
+
+	// Outputs are the values the delivery's resources publish, read from
+	// the objects stamped for them.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Outputs []DeliverableOutput `json:"outputs,omitempty"`
+
+	// LastOutputs are the outputs of resources consumed with
+	// whileWaiting: useLastOutputs, as last read.
+	// +optional
+	// +listType=map
+	// +listMapKey=resource
+	LastOutputs []LastOutput `json:"lastOutputs,omitempty"`
+
+	// Retries are the retries each resource consumed in the current
+	// realization. Resources realized at the first attempt are not listed.
+	// +optional
+	// +listType=map
+	// +listMapKey=resource
+	Retries []ResourceRetries `json:"retries,omitempty"`
+
+	// Targets are how the realization went on each of the clusters the
+	// delivery's target selects.
+	// +optional
+	// +listType=map
+	// +listMapKey=cluster
+	Targets []DeliverableTargetStatus `json:"targets,omitempty"`
+
+	// PullRequest is the pull request proposing the objects of the
+	// delivery's resources with submission: PullRequest.
+	// +optional
+	PullRequest *DeliverablePullRequest `json:"pullRequest,omitempty"`
+
+	// History are the latest realizations that submitted every resource,
+	// newest first.
+	// +optional
+	History []RealizationRecord `json:"history,omitempty"`
+
+	// Drifted are the objects of resources with drift: detect found
+	// changed by something other than Cartographer, and left so.
+	// +optional
+	Drifted []DriftedObject `json:"drifted,omitempty"`
+
+	// FieldConflicts are the fields of stamped objects that other field
+	// managers set and that the last realization set back.
+	// +optional
+	FieldConflicts []FieldConflict `json:"fieldConflicts,omitempty"`
+
+	// MissingOptionalOutputs are the optional outputs that the stamped
+	// objects of the last realization had not populated yet. They do not
+	// hold back the resources that consume them.
+	// +optional
+	MissingOptionalOutputs []MissingOutput `json:"missingOptionalOutputs,omitempty"`
+}
+
+// DeliverableTargetStatus is how the realization of a deliverable went on
+// one of the clusters its delivery targets.
+type DeliverableTargetStatus struct {
+	// Cluster is the Cluster API Cluster, as namespace/name.
+	Cluster string `json:"cluster"`
+	// Status is True when the resources were submitted to the cluster.
+	Status metav1.ConditionStatus `json:"status"`
+	// Reason the resources were, or were not, submitted to the cluster.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	OpenPullRequestState     = "Open"
+	MergedPullRequestState   = "Merged"
+	ClosedPullRequestState   = "Closed"
+	UpToDatePullRequestState = "UpToDate"
+)
+
+// DeliverablePullRequest is the pull request proposing the manifests of a
+// deliverable to its delivery's GitOps repository.
+type DeliverablePullRequest struct {
+	// URL of the pull request. Empty when the repository already held the
+	// manifests without one.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// Number of the pull request.
+	// +optional
+	Number int `json:"number,omitempty"`
+	// State is Open, Merged or Closed, or UpToDate when the repository
+	// already held the manifests without a pull request.
+	State string `json:"state"`
+	// Branch the manifests are pushed to.
+	Branch string `json:"branch"`
+	// Digest identifies the manifests proposed.
+	Digest string `json:"digest"`
+}
+
+// DeliverableOutput is a value published by a resource of the delivery.
+type DeliverableOutput struct {
+	// Name the value is published under.
+	Name string `json:"name"`
+	// Resource is the delivery resource that published the value.
+	Resource string `json:"resource"`
+	// Value is the value found at the published path.
+	Value apiextensionsv1.JSON `json:"value"`
+}
+
+// DeliverableSource is a source of a deliverable, named for templates to
+// reference it.
+type DeliverableSource struct {
+	// +kubebuilder:validation:MinLength=1
+	Name   string `json:"name"`
+	Source `json:",inline"`
+}
+
+// ResolvedDeliverableSource is a named OCI source as last resolved.
+type ResolvedDeliverableSource struct {
+	Name           string `json:"name"`
+	ResolvedSource `json:",inline"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,86 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha1
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// DeliveryObject is either a ClusterDelivery or a Delivery.
+// +kubebuilder:object:generate=false
+type DeliveryObject interface {
+	client.Object
+	GetSpec() *ClusterDeliverySpec
+	GetStatus() *ClusterDeliveryStatus
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=".spec.description"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// Delivery is a ClusterDelivery owned by a namespace. It only selects
+// deliverables in its own namespace, and those deliverables are matched
+// against it before any ClusterDelivery.
+type Delivery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ClusterDeliverySpec   `json:"spec"`
+	Status            ClusterDeliveryStatus `json:"status,omitempty"`
+}
+
+var _ webhook.Validator = &Delivery{}
+
+func (c *Delivery) GetSpec() *ClusterDeliverySpec {
+	return &c.Spec
+}
+
+func (c *Delivery) GetStatus() *ClusterDeliveryStatus {
+	return &c.Status
+}
+
+func (c *Delivery) ValidateCreate() error {
+	return c.Spec.validateIn(c.Namespace)
+}
+
+func (c *Delivery) ValidateUpdate(_ runtime.Object) error {
+	return c.Spec.validateIn(c.Namespace)
+}
+
+func (c *Delivery) ValidateDelete() error {
+	return nil
+}
+
+// +kubebuilder:object:root=true
+
+type DeliveryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Delivery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&Delivery{},
+		&DeliveryList{},
+	)
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("Delivery", func() {
+	It("rejects duplicate resource names", func() {
+		delivery := &v1alpha1.Delivery{
+			Spec: v1alpha1.ClusterDeliverySpec{
+				Resources: []v1alpha1.ClusterDeliveryResource{
+					{Name: "deployer"},
+					{Name: "deployer"},
+				},
+			},
+		}
+
+		Expect(delivery.ValidateCreate()).To(MatchError(`spec.resources[1].name "deployer" cannot appear twice`))
+	})
+	It("rejects params that read their value from the cluster", func() {
+		delivery := &v1alpha1.Delivery{
+			Spec: v1alpha1.ClusterDeliverySpec{
+				Resources: []v1alpha1.ClusterDeliveryResource{
+					{
+						Name: "deployer",
+						Params: []v1alpha1.Param{
+							{
+								Name:      "token",
+								ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "token"}},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		Expect(delivery.ValidateCreate()).To(MatchError(`spec.resources[0] "deployer" has invalid params: param 'token' cannot set valueFrom`))
+	})
+	It("rejects templates fetched with a Secret of another namespace", func() {
+		delivery := &v1alpha1.Delivery{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+			Spec: v1alpha1.ClusterDeliverySpec{
+				Resources: []v1alpha1.ClusterDeliveryResource{
+					{
+						Name: "deployer",
+						TemplateRef: v1alpha1.DeliveryClusterTemplateReference{
+							Kind: "ClusterDeploymentTemplate",
+							Git: &v1alpha1.GitTemplateSource{
+								URL:       "https://git.example.com/templates.git",
+								Path:      "deploy.yaml",
+								SecretRef: &v1alpha1.SecretReference{Namespace: "team-b", Name: "git-credentials"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		Expect(delivery.ValidateCreate()).To(MatchError("spec.resources[0].templateRef is invalid: git.secretRef must be in namespace 'team-a', not 'team-b'"))
+
+		delivery.Spec.Resources[0].TemplateRef.Git.SecretRef.Namespace = "team-a"
+		Expect(delivery.ValidateCreate()).To(Succeed())
+	})
+})
@@ -35,6 +35,7 @@ const (
 	OutputPathNotSatisfiedRunTemplateReason           = "OutputPathNotSatisfied"
 	TemplateStampFailureRunTemplateReason             = "TemplateStampFailure"
 	FailedToListCreatedObjectsReason                  = "FailedToListCreatedObjects"
+	PolicyViolationRunTemplateReason                  = "PolicyViolation"
 )
 
 // +kubebuilder:object:root=true
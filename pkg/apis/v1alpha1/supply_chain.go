@@ -0,0 +1,108 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha1
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SupplyChainObject is either a ClusterSupplyChain or a SupplyChain.
+// +kubebuilder:object:generate=false
+type SupplyChainObject interface {
+	client.Object
+	GetSpec() *SupplyChainSpec
+	GetStatus() *SupplyChainStatus
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=".spec.description"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// SupplyChain is a ClusterSupplyChain owned by a namespace. It only selects
+// workloads in its own namespace, and those workloads are matched against
+// it before any ClusterSupplyChain.
+type SupplyChain struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              SupplyChainSpec   `json:"spec"`
+	Status            SupplyChainStatus `json:"status,omitempty"`
+}
+
+var _ webhook.Validator = &SupplyChain{}
+
+func (c *SupplyChain) GetSpec() *SupplyChainSpec {
+	return &c.Spec
+}
+
+func (c *SupplyChain) GetStatus() *SupplyChainStatus {
+	return &c.Status
+}
+
+func (c *SupplyChain) ValidateCreate() error {
+	return c.validate()
+}
+
+func (c *SupplyChain) ValidateUpdate(_ runtime.Object) error {
+	return c.validate()
+}
+
+// validate also keeps the supply chain in its own namespace: a team owning
+// a namespace must not stamp into, or read Secrets of, namespaces it does
+// not own.
+func (c *SupplyChain) validate() error {
+	if err := c.Spec.validate("supplychain", c.Name); err != nil {
+		return err
+	}
+	if err := c.Spec.validateSecretRefs(c.Namespace); err != nil {
+		return err
+	}
+
+	for _, resource := range c.Spec.Resources {
+		if resource.TargetNamespace != "" && resource.TargetNamespace != c.Namespace {
+			return fmt.Errorf("resource '%s' cannot set targetNamespace '%s': supplychain '%s' only stamps into its own namespace '%s'",
+				resource.Name, resource.TargetNamespace, c.Name, c.Namespace)
+		}
+	}
+	return nil
+}
+
+func (c *SupplyChain) ValidateDelete() error {
+	return nil
+}
+
+// +kubebuilder:object:root=true
+
+type SupplyChainList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SupplyChain `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&SupplyChain{},
+		&SupplyChainList{},
+	)
+}
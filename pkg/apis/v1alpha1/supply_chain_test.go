@@ -0,0 +1,134 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("SupplyChain", func() {
+	var supplyChain *v1alpha1.SupplyChain
+
+	BeforeEach(func() {
+		supplyChain = &v1alpha1.SupplyChain{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "team-supply-chain",
+				Namespace: "team-a",
+			},
+			Spec: v1alpha1.SupplyChainSpec{
+				Resources: []v1alpha1.SupplyChainResource{
+					{
+						Name: "source-provider",
+						TemplateRef: v1alpha1.ClusterTemplateReference{
+							Kind: "ClusterSourceTemplate",
+							Name: "git-template",
+						},
+					},
+					{
+						Name: "image-provider",
+						TemplateRef: v1alpha1.ClusterTemplateReference{
+							Kind: "ClusterImageTemplate",
+							Name: "kpack-template",
+						},
+						Sources: []v1alpha1.ResourceReference{
+							{
+								Name:     "source",
+								Resource: "source-provider",
+							},
+						},
+					},
+				},
+				Selector: map[string]string{"app": "web"},
+			},
+		}
+	})
+
+	It("accepts a well formed supply chain", func() {
+		Expect(supplyChain.ValidateCreate()).To(Succeed())
+		Expect(supplyChain.ValidateUpdate(nil)).To(Succeed())
+	})
+
+	It("rejects duplicate resource names", func() {
+		supplyChain.Spec.Resources[1].Name = "source-provider"
+
+		Expect(supplyChain.ValidateCreate()).To(MatchError(
+			"duplicate resource name 'source-provider' found in supplychain 'team-supply-chain'",
+		))
+	})
+
+	It("rejects inputs provided by resources of the wrong kind", func() {
+		supplyChain.Spec.Resources[1].Images = []v1alpha1.ResourceReference{
+			{
+				Name:     "image",
+				Resource: "source-provider",
+			},
+		}
+
+		Expect(supplyChain.ValidateCreate()).To(MatchError(
+			"invalid images for resource 'image-provider': resource 'source-provider' providing 'image' must reference a ClusterImageTemplate",
+		))
+	})
+	It("rejects params that read their value from the cluster", func() {
+		supplyChain.Spec.Resources[1].Params = []v1alpha1.Param{
+			{
+				Name:      "token",
+				ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "token"}},
+			},
+		}
+
+		Expect(supplyChain.ValidateCreate()).To(MatchError(
+			"invalid params for resource 'image-provider': param 'token' cannot set valueFrom",
+		))
+	})
+
+	It("rejects resources stamping into another namespace", func() {
+		supplyChain.Spec.Resources[1].TargetNamespace = "team-b"
+
+		Expect(supplyChain.ValidateCreate()).To(MatchError(
+			"resource 'image-provider' cannot set targetNamespace 'team-b': supplychain 'team-supply-chain' only stamps into its own namespace 'team-a'",
+		))
+		Expect(supplyChain.ValidateUpdate(nil)).NotTo(Succeed())
+	})
+
+	It("accepts resources naming its own namespace as their target", func() {
+		supplyChain.Spec.Resources[1].TargetNamespace = "team-a"
+
+		Expect(supplyChain.ValidateCreate()).To(Succeed())
+		Expect(supplyChain.ValidateUpdate(nil)).To(Succeed())
+	})
+
+	It("rejects templates fetched with a Secret of another namespace", func() {
+		supplyChain.Spec.Resources[0].TemplateRef = v1alpha1.ClusterTemplateReference{
+			Kind: "ClusterSourceTemplate",
+			Git: &v1alpha1.GitTemplateSource{
+				URL:       "https://git.example.com/templates.git",
+				Path:      "source.yaml",
+				SecretRef: &v1alpha1.SecretReference{Namespace: "cartographer-system", Name: "git-credentials"},
+			},
+		}
+
+		Expect(supplyChain.ValidateCreate()).To(MatchError(
+			"invalid templateRef for resource 'source-provider': git.secretRef must be in namespace 'team-a', not 'cartographer-system'",
+		))
+
+		supplyChain.Spec.Resources[0].TemplateRef.Git.SecretRef.Namespace = "team-a"
+		Expect(supplyChain.ValidateCreate()).To(Succeed())
+	})
+})
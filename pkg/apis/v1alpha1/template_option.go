@@ -0,0 +1,221 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/eval"
+)
+
+// TemplateOption is a template a resource uses for the workloads its
+// selector matches.
+type TemplateOption struct {
+	// Name of the template on the cluster.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Selector over the workload's labels and fields.
+	Selector OptionSelector `json:"selector"`
+}
+
+// OptionSelector selects workloads by their labels, as a label selector
+// does, and by their fields. A workload must meet every requirement.
+type OptionSelector struct {
+	metav1.LabelSelector `json:",inline"`
+	// MatchFields are requirements on the workload's fields.
+	// +optional
+	MatchFields []FieldSelectorRequirement `json:"matchFields,omitempty"`
+}
+
+type FieldSelectorOperator string
+
+const (
+	FieldSelectorOpIn           FieldSelectorOperator = "In"
+	FieldSelectorOpNotIn        FieldSelectorOperator = "NotIn"
+	FieldSelectorOpExists       FieldSelectorOperator = "Exists"
+	FieldSelectorOpDoesNotExist FieldSelectorOperator = "DoesNotExist"
+)
+
+type FieldSelectorRequirement struct {
+	// Key is the JSONPath of the field in the workload, such as
+	// spec.source.git.url.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+	// Operator compares the field with values. In and NotIn need values,
+	// Exists and DoesNotExist take none.
+	// +kubebuilder:validation:Enum=In;NotIn;Exists;DoesNotExist
+	Operator FieldSelectorOperator `json:"operator"`
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
+// ForWorkload returns the reference to the template the resource uses for
+// workload: the reference itself, or the reference to the template of the
+// one option that matches workload, pinned to the reference's version.
+func (r ClusterTemplateReference) ForWorkload(workload *Workload) (ClusterTemplateReference, error) {
+	if len(r.Options) == 0 {
+		return r, nil
+	}
+
+	fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(workload)
+	if err != nil {
+		return r, fmt.Errorf("read workload fields: %w", err)
+	}
+
+	var matched []string
+	for _, option := range r.Options {
+		matches, err := option.Selector.matches(workload.Labels, fields)
+		if err != nil {
+			return r, fmt.Errorf("option '%s': %w", option.Name, err)
+		}
+		if matches {
+			matched = append(matched, option.Name)
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return r, fmt.Errorf("no option of %s matches the workload", r.DisplayName())
+	case 1:
+		return ClusterTemplateReference{Kind: r.Kind, Name: matched[0], Version: r.Version}, nil
+	}
+	return r, fmt.Errorf("options %s all match the workload, only one may", quoteAll(matched))
+}
+
+// Choices returns the references to the templates the resource may use:
+// one for each option, or the reference itself.
+func (r ClusterTemplateReference) Choices() []ClusterTemplateReference {
+	if len(r.Options) == 0 {
+		return []ClusterTemplateReference{r}
+	}
+	var choices []ClusterTemplateReference
+	for _, option := range r.Options {
+		choices = append(choices, ClusterTemplateReference{Kind: r.Kind, Name: option.Name, Version: r.Version})
+	}
+	return choices
+}
+
+func (s OptionSelector) matches(workloadLabels map[string]string, fields map[string]interface{}) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&s.LabelSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid selector: %w", err)
+	}
+	if !selector.Matches(labels.Set(workloadLabels)) {
+		return false, nil
+	}
+
+	for _, requirement := range s.MatchFields {
+		if !requirement.matches(fields) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matches reports whether fields meet the requirement. A field the key does
+// not find does not exist, so it is not in any values.
+func (r FieldSelectorRequirement) matches(fields map[string]interface{}) bool {
+	value, exists := r.lookup(fields)
+
+	switch r.Operator {
+	case FieldSelectorOpExists:
+		return exists
+	case FieldSelectorOpDoesNotExist:
+		return !exists
+	case FieldSelectorOpIn:
+		return exists && containsString(r.Values, fmt.Sprint(value))
+	case FieldSelectorOpNotIn:
+		return !exists || !containsString(r.Values, fmt.Sprint(value))
+	}
+	return false
+}
+
+// lookup returns the value of the field the key finds in fields, and
+// whether it finds one.
+func (r FieldSelectorRequirement) lookup(fields map[string]interface{}) (interface{}, bool) {
+	value, err := eval.EvaluatorBuilder().EvaluateJsonPath(r.Key, fields)
+	return value, err == nil
+}
+
+func (s OptionSelector) validate() error {
+	if len(s.MatchLabels) == 0 && len(s.MatchExpressions) == 0 && len(s.MatchFields) == 0 {
+		return fmt.Errorf("selector must set at least one of matchLabels, matchExpressions and matchFields")
+	}
+	if _, err := metav1.LabelSelectorAsSelector(&s.LabelSelector); err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+	return validateFieldRequirements("matchFields", s.MatchFields)
+}
+
+// validateFieldRequirements checks the requirements listed at field.
+func validateFieldRequirements(field string, requirements []FieldSelectorRequirement) error {
+	for _, requirement := range requirements {
+		if err := eval.ValidateJsonPath(requirement.Key); err != nil {
+			return fmt.Errorf("invalid key '%s' in %s: %w", requirement.Key, field, err)
+		}
+		switch requirement.Operator {
+		case FieldSelectorOpIn, FieldSelectorOpNotIn:
+			if len(requirement.Values) == 0 {
+				return fmt.Errorf("%s key '%s' with operator %s needs values", field, requirement.Key, requirement.Operator)
+			}
+		case FieldSelectorOpExists, FieldSelectorOpDoesNotExist:
+			if len(requirement.Values) > 0 {
+				return fmt.Errorf("%s key '%s' with operator %s cannot have values", field, requirement.Key, requirement.Operator)
+			}
+		default:
+			return fmt.Errorf("%s key '%s' has unknown operator '%s'", field, requirement.Key, requirement.Operator)
+		}
+	}
+	return nil
+}
+
+func validateTemplateOptions(options []TemplateOption) error {
+	names := map[string]bool{}
+	for _, option := range options {
+		if option.Name == "" {
+			return fmt.Errorf("every option must name a template")
+		}
+		if names[option.Name] {
+			return fmt.Errorf("template '%s' appears in more than one option", option.Name)
+		}
+		names[option.Name] = true
+		if err := option.Selector.validate(); err != nil {
+			return fmt.Errorf("option '%s': %w", option.Name, err)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + name + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
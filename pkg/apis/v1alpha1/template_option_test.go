@@ -0,0 +1,153 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("ClusterTemplateReference", func() {
+	var (
+		ref      v1alpha1.ClusterTemplateReference
+		workload *v1alpha1.Workload
+	)
+
+	BeforeEach(func() {
+		ref = v1alpha1.ClusterTemplateReference{
+			Kind: "ClusterImageTemplate",
+			Options: []v1alpha1.TemplateOption{
+				{
+					Name: "java-build",
+					Selector: v1alpha1.OptionSelector{
+						LabelSelector: metav1.LabelSelector{
+							MatchLabels: map[string]string{"apps.tanzu.vmware.com/language": "java"},
+						},
+					},
+				},
+				{
+					Name: "go-build",
+					Selector: v1alpha1.OptionSelector{
+						MatchFields: []v1alpha1.FieldSelectorRequirement{
+							{Key: `spec.params[?(@.name=="language")].value`, Operator: v1alpha1.FieldSelectorOpIn, Values: []string{"go", "golang"}},
+						},
+					},
+				},
+			},
+		}
+
+		workload = &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+		}
+	})
+
+	Describe("ForWorkload", func() {
+		It("returns a reference without options as it is", func() {
+			plain := v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "kpack", Version: "v2"}
+			Expect(plain.ForWorkload(workload)).To(Equal(plain))
+		})
+
+		It("chooses the option matching the workload's labels", func() {
+			workload.Labels = map[string]string{"apps.tanzu.vmware.com/language": "java"}
+
+			Expect(ref.ForWorkload(workload)).To(Equal(v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "java-build"}))
+		})
+
+		It("chooses the option matching the workload's fields", func() {
+			workload.Spec.Params = []v1alpha1.Param{{Name: "language", Value: apiextensionsv1.JSON{Raw: []byte(`"golang"`)}}}
+
+			Expect(ref.ForWorkload(workload)).To(Equal(v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "go-build"}))
+		})
+
+		It("pins the chosen template to the reference's version", func() {
+			ref.Version = "v3"
+			workload.Labels = map[string]string{"apps.tanzu.vmware.com/language": "java"}
+
+			Expect(ref.ForWorkload(workload)).To(Equal(v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "java-build", Version: "v3"}))
+		})
+
+		It("returns an error when no option matches", func() {
+			_, err := ref.ForWorkload(workload)
+			Expect(err).To(MatchError("no option of java-build|go-build matches the workload"))
+		})
+
+		It("returns an error when several options match", func() {
+			workload.Labels = map[string]string{"apps.tanzu.vmware.com/language": "java"}
+			workload.Spec.Params = []v1alpha1.Param{{Name: "language", Value: apiextensionsv1.JSON{Raw: []byte(`"go"`)}}}
+
+			_, err := ref.ForWorkload(workload)
+			Expect(err).To(MatchError("options 'java-build', 'go-build' all match the workload, only one may"))
+		})
+
+		DescribeTable("field requirements",
+			func(operator v1alpha1.FieldSelectorOperator, values []string, url string, matches bool) {
+				ref.Options = []v1alpha1.TemplateOption{{
+					Name: "only",
+					Selector: v1alpha1.OptionSelector{
+						MatchFields: []v1alpha1.FieldSelectorRequirement{
+							{Key: "spec.source.git.url", Operator: operator, Values: values},
+						},
+					},
+				}}
+				if url != "" {
+					workload.Spec.Source = &v1alpha1.Source{Git: &v1alpha1.GitSource{URL: &url}}
+				}
+
+				_, err := ref.ForWorkload(workload)
+				if matches {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(HaveOccurred())
+				}
+			},
+			Entry("In a value", v1alpha1.FieldSelectorOpIn, []string{"https://a"}, "https://a", true),
+			Entry("In another value", v1alpha1.FieldSelectorOpIn, []string{"https://a"}, "https://b", false),
+			Entry("In a missing field", v1alpha1.FieldSelectorOpIn, []string{"https://a"}, "", false),
+			Entry("NotIn a value", v1alpha1.FieldSelectorOpNotIn, []string{"https://a"}, "https://a", false),
+			Entry("NotIn another value", v1alpha1.FieldSelectorOpNotIn, []string{"https://a"}, "https://b", true),
+			Entry("NotIn a missing field", v1alpha1.FieldSelectorOpNotIn, []string{"https://a"}, "", true),
+			Entry("Exists", v1alpha1.FieldSelectorOpExists, nil, "https://a", true),
+			Entry("Exists missing", v1alpha1.FieldSelectorOpExists, nil, "", false),
+			Entry("DoesNotExist", v1alpha1.FieldSelectorOpDoesNotExist, nil, "https://a", false),
+			Entry("DoesNotExist missing", v1alpha1.FieldSelectorOpDoesNotExist, nil, "", true),
+		)
+	})
+
+	Describe("Choices", func() {
+		It("returns a reference without options as it is", func() {
+			plain := v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "kpack"}
+			Expect(plain.Choices()).To(Equal([]v1alpha1.ClusterTemplateReference{plain}))
+		})
+
+		It("returns a reference to each option's template, pinned to the reference's version", func() {
+			ref.Version = "v3"
+			Expect(ref.Choices()).To(Equal([]v1alpha1.ClusterTemplateReference{
+				{Kind: "ClusterImageTemplate", Name: "java-build", Version: "v3"},
+				{Kind: "ClusterImageTemplate", Name: "go-build", Version: "v3"},
+			}))
+		})
+	})
+
+	Describe("DisplayName", func() {
+		It("names the options' templates", func() {
+			Expect(ref.DisplayName()).To(Equal("java-build|go-build"))
+		})
+	})
+})
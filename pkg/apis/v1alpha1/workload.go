@@ -20,6 +20,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,6 +28,8 @@ const (
 	WorkloadReady             = "Ready"
 	WorkloadSupplyChainReady  = "SupplyChainReady"
 	WorkloadResourceSubmitted = "ResourcesSubmitted"
+	WorkloadDrifted           = "Drifted"
+	WorkloadFieldConflict     = "FieldConflict"
 )
 
 const (
@@ -35,8 +38,30 @@ const (
 	NotFoundSupplyChainReadyReason         = "SupplyChainNotFound"
 	MultipleMatchesSupplyChainReadyReason  = "MultipleSupplyChainMatches"
 	NotReadySupplyChainReason              = "SupplyChainNotReady"
+	UnsupportedPlatformSupplyChainReason   = "UnsupportedPlatform"
+	ContractNotMetSupplyChainReason        = "WorkloadContractNotMet"
 )
 
+// CrossNamespaceCleanupFinalizer keeps a workload around until the objects
+// stamped for it in other namespaces have been deleted.
+const CrossNamespaceCleanupFinalizer = "carto.run/cross-namespace-cleanup"
+
+// WorkloadUIDLabel holds, on an object stamped for a workload outside its
+// namespace, the UID of that workload. Without an owner reference, it tells
+// the object apart from one of the same name left by an earlier workload or
+// created by someone else.
+const WorkloadUIDLabel = "carto.run/workload-uid"
+
+// StampedFor reports whether obj carries the labels of an object stamped
+// for workload outside its namespace: the workload's name, namespace and
+// UID.
+func StampedFor(obj metav1.Object, workload *Workload) bool {
+	labels := obj.GetLabels()
+	return labels["carto.run/workload-name"] == workload.Name &&
+		labels["carto.run/workload-namespace"] == workload.Namespace &&
+		labels[WorkloadUIDLabel] == string(workload.UID)
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -47,6 +72,35 @@ type Workload struct {
 	Status            WorkloadStatus `json:"status,omitempty"`
 }
 
+// Platform names an operating system and CPU architecture, using the values
+// of the kubernetes.io/os and kubernetes.io/arch node labels.
+type Platform struct {
+	// OS is the operating system, e.g. linux or windows.
+	// +kubebuilder:validation:MinLength=1
+	OS string `json:"os"`
+
+	// Arch is the CPU architecture, e.g. amd64 or arm64. When empty, any
+	// architecture is acceptable.
+	// +optional
+	Arch string `json:"arch,omitempty"`
+}
+
+// Matches reports whether p is satisfied by other: the operating systems
+// are the same and, when both name one, so are the architectures.
+func (p Platform) Matches(other Platform) bool {
+	if p.OS != other.OS {
+		return false
+	}
+	return p.Arch == "" || other.Arch == "" || p.Arch == other.Arch
+}
+
+func (p Platform) String() string {
+	if p.Arch == "" {
+		return p.OS
+	}
+	return p.OS + "/" + p.Arch
+}
+
 type WorkloadServiceClaim struct {
 	Name string                         `json:"name"`
 	Ref  *WorkloadServiceClaimReference `json:"ref,omitempty"`
@@ -59,6 +113,18 @@ type WorkloadServiceClaimReference struct {
 }
 
 type WorkloadSpec struct {
+	// ServiceAccountName is the service account the workload's objects
+	// should run as. Templates may refer to it as
+	// $(workload.spec.serviceAccountName)$.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Platform is the operating system and architecture the workload's
+	// objects should be built for and run on. Templates may refer to it as
+	// $(workload.spec.platform.os)$ and $(workload.spec.platform.arch)$.
+	// +optional
+	Platform *Platform `json:"platform,omitempty"`
+
 	Params []Param `json:"params,omitempty"`
 	Source *Source `json:"source,omitempty"`
 	// Image is a pre-built image in a registry. It is an alternative to defining source
@@ -67,12 +133,100 @@ type WorkloadSpec struct {
 	ServiceClaims []WorkloadServiceClaim       `json:"serviceClaims,omitempty"`
 	Env           []corev1.EnvVar              `json:"env,omitempty"`
 	Resources     *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NamePrefix, when set, is presented to templates as the workload name
+	// ($(workload.metadata.name)$) in place of metadata.name, so objects stamped
+	// from it keep names chosen before the workload was adopted.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	NamePrefix string `json:"namePrefix,omitempty"`
+
+	// DeletionPolicy is what happens to the objects stamped for the workload
+	// when it is deleted, for resources that do not set their own:
+	// "Delete", the default, deletes them along with it; "Orphan" leaves
+	// them behind.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
 }
 
 type WorkloadStatus struct {
 	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
 	Conditions         []metav1.Condition `json:"conditions,omitempty"`
 	SupplyChainRef     ObjectReference    `json:"supplyChainRef,omitempty"`
+
+	// Source is spec.source.oci as last resolved.
+	// +optional
+	Source *ResolvedSource `json:"source,omitempty"`
+
+	// Outputs are the url, revision, image and config the supply chain
+	// produced, each read from the last resource in the supply chain to
+	// output it, as of the latest realization that submitted every
+	// resource.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Outputs []WorkloadOutput `json:"outputs,omitempty"`
+
+	// ResolvedTemplates are the template objects the resources of the
+	// supply chain were resolved to in the latest realization, for those
+	// it reached.
+	// +optional
+	// +listType=map
+	// +listMapKey=resource
+	ResolvedTemplates []ResolvedResourceTemplate `json:"resolvedTemplates,omitempty"`
+
+	// CrossNamespaceObjects are the objects stamped for the workload outside
+	// its namespace, in other namespaces or of cluster-scoped kinds, which
+	// Cartographer deletes along with it.
+	// +optional
+	CrossNamespaceObjects []ObjectReference `json:"crossNamespaceObjects,omitempty"`
+
+	// LastOutputs are the outputs of resources consumed with
+	// whileWaiting: useLastOutputs, as last read.
+	// +optional
+	// +listType=map
+	// +listMapKey=resource
+	LastOutputs []LastOutput `json:"lastOutputs,omitempty"`
+
+	// Retries are the retries each resource consumed in the current
+	// realization. Resources realized at the first attempt are not listed.
+	// +optional
+	// +listType=map
+	// +listMapKey=resource
+	Retries []ResourceRetries `json:"retries,omitempty"`
+
+	// History are the latest realizations that submitted every resource,
+	// newest first.
+	// +optional
+	History []RealizationRecord `json:"history,omitempty"`
+
+	// Drifted are the objects of resources with drift: detect found
+	// changed by something other than Cartographer, and left so.
+	// +optional
+	Drifted []DriftedObject `json:"drifted,omitempty"`
+
+	// FieldConflicts are the fields of stamped objects that other field
+	// managers set and that the last realization set back.
+	// +optional
+	FieldConflicts []FieldConflict `json:"fieldConflicts,omitempty"`
+
+	// MissingOptionalOutputs are the optional outputs that the stamped
+	// objects of the last realization had not populated yet. They do not
+	// hold back the resources that consume them.
+	// +optional
+	MissingOptionalOutputs []MissingOutput `json:"missingOptionalOutputs,omitempty"`
+}
+
+// WorkloadOutput is a value the supply chain of a workload produced.
+type WorkloadOutput struct {
+	// Name of the value: url, revision, image or config.
+	Name string `json:"name"`
+	// Resource is the supply chain resource that output the value.
+	Resource string `json:"resource"`
+	// Value is the value the resource output.
+	Value apiextensionsv1.JSON `json:"value"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,142 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// WorkloadContract is what a supply chain requires of the workloads it
+// realizes. Workloads that do not meet it are not realized.
+type WorkloadContract struct {
+	// Fields are requirements on the workload's fields, such as
+	// spec.source.git with the operator Exists.
+	// +optional
+	Fields []FieldSelectorRequirement `json:"fields,omitempty"`
+	// Params the workload must set.
+	// +optional
+	Params []RequiredParam `json:"params,omitempty"`
+}
+
+// RequiredParam is a param a workload must set.
+type RequiredParam struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Values, when set, are the values the param may take. Only checked
+	// against the value the workload sets, not one read with valueFrom.
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
+// Unmet returns, for each requirement of the contract workload does not
+// meet, a message telling why. Every workload meets a nil contract.
+func (c *WorkloadContract) Unmet(workload *Workload) ([]string, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	var unmet []string
+
+	if len(c.Fields) > 0 {
+		fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(workload)
+		if err != nil {
+			return nil, fmt.Errorf("read workload fields: %w", err)
+		}
+		for _, requirement := range c.Fields {
+			if !requirement.matches(fields) {
+				unmet = append(unmet, requirement.unmetMessage(fields))
+			}
+		}
+	}
+
+	for _, required := range c.Params {
+		param := findParam(workload.Spec.Params, required.Name)
+		if param == nil {
+			unmet = append(unmet, fmt.Sprintf("param '%s' is required", required.Name))
+			continue
+		}
+		if len(required.Values) == 0 || param.ValueFrom != nil {
+			continue
+		}
+		if value := paramString(param.Value); !containsString(required.Values, value) {
+			unmet = append(unmet, fmt.Sprintf("param '%s' is '%s', must be one of %s", required.Name, value, strings.Join(required.Values, ", ")))
+		}
+	}
+
+	return unmet, nil
+}
+
+func (c *WorkloadContract) validate() error {
+	if c == nil {
+		return nil
+	}
+	if err := validateFieldRequirements("fields", c.Fields); err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for _, param := range c.Params {
+		if param.Name == "" {
+			return fmt.Errorf("every required param must have a name")
+		}
+		if names[param.Name] {
+			return fmt.Errorf("param '%s' is required more than once", param.Name)
+		}
+		names[param.Name] = true
+	}
+	return nil
+}
+
+// unmetMessage tells why fields do not meet the requirement.
+func (r FieldSelectorRequirement) unmetMessage(fields map[string]interface{}) string {
+	value, exists := r.lookup(fields)
+	switch r.Operator {
+	case FieldSelectorOpExists:
+		return fmt.Sprintf("field %s is required", r.Key)
+	case FieldSelectorOpDoesNotExist:
+		return fmt.Sprintf("field %s must not be set", r.Key)
+	case FieldSelectorOpIn:
+		if !exists {
+			return fmt.Sprintf("field %s is required, one of %s", r.Key, strings.Join(r.Values, ", "))
+		}
+		return fmt.Sprintf("field %s is '%v', must be one of %s", r.Key, value, strings.Join(r.Values, ", "))
+	case FieldSelectorOpNotIn:
+		return fmt.Sprintf("field %s is '%v', must not be one of %s", r.Key, value, strings.Join(r.Values, ", "))
+	}
+	return fmt.Sprintf("field %s has unknown operator '%s'", r.Key, r.Operator)
+}
+
+func findParam(params []Param, name string) *Param {
+	for i := range params {
+		if params[i].Name == name {
+			return &params[i]
+		}
+	}
+	return nil
+}
+
+// paramString is the value of a param as a string: a string value as it
+// is, any other value as JSON.
+func paramString(value apiextensionsv1.JSON) string {
+	var s string
+	if err := json.Unmarshal(value.Raw, &s); err == nil {
+		return s
+	}
+	return string(value.Raw)
+}
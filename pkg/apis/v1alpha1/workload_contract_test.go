@@ -0,0 +1,111 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("WorkloadContract", func() {
+	var (
+		contract *v1alpha1.WorkloadContract
+		workload *v1alpha1.Workload
+	)
+
+	BeforeEach(func() {
+		contract = &v1alpha1.WorkloadContract{
+			Fields: []v1alpha1.FieldSelectorRequirement{
+				{Key: "spec.source.git", Operator: v1alpha1.FieldSelectorOpExists},
+				{Key: "spec.image", Operator: v1alpha1.FieldSelectorOpDoesNotExist},
+			},
+			Params: []v1alpha1.RequiredParam{
+				{Name: "language", Values: []string{"java", "go"}},
+				{Name: "port"},
+			},
+		}
+
+		url := "https://github.com/example/app"
+		workload = &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec: v1alpha1.WorkloadSpec{
+				Source: &v1alpha1.Source{Git: &v1alpha1.GitSource{URL: &url}},
+				Params: []v1alpha1.Param{
+					{Name: "language", Value: apiextensionsv1.JSON{Raw: []byte(`"go"`)}},
+					{Name: "port", Value: apiextensionsv1.JSON{Raw: []byte(`8080`)}},
+				},
+			},
+		}
+	})
+
+	Describe("Unmet", func() {
+		It("returns nothing when the workload meets every requirement", func() {
+			Expect(contract.Unmet(workload)).To(BeEmpty())
+		})
+
+		It("returns nothing for a nil contract", func() {
+			var none *v1alpha1.WorkloadContract
+			Expect(none.Unmet(workload)).To(BeEmpty())
+		})
+
+		It("tells which fields the workload is missing or must not set", func() {
+			image := "registry.example.com/app"
+			workload.Spec.Source = nil
+			workload.Spec.Image = &image
+
+			Expect(contract.Unmet(workload)).To(Equal([]string{
+				"field spec.source.git is required",
+				"field spec.image must not be set",
+			}))
+		})
+
+		It("tells which fields hold values they may not", func() {
+			contract.Fields = []v1alpha1.FieldSelectorRequirement{
+				{Key: "spec.serviceAccountName", Operator: v1alpha1.FieldSelectorOpIn, Values: []string{"builder"}},
+				{Key: "spec.source.git.url", Operator: v1alpha1.FieldSelectorOpNotIn, Values: []string{"https://github.com/example/app"}},
+			}
+			workload.Spec.ServiceAccountName = "default"
+
+			Expect(contract.Unmet(workload)).To(Equal([]string{
+				"field spec.serviceAccountName is 'default', must be one of builder",
+				"field spec.source.git.url is 'https://github.com/example/app', must not be one of https://github.com/example/app",
+			}))
+		})
+
+		It("tells which params the workload is missing or sets to a value they may not take", func() {
+			workload.Spec.Params = []v1alpha1.Param{
+				{Name: "language", Value: apiextensionsv1.JSON{Raw: []byte(`"rust"`)}},
+			}
+
+			Expect(contract.Unmet(workload)).To(Equal([]string{
+				"param 'language' is 'rust', must be one of java, go",
+				"param 'port' is required",
+			}))
+		})
+
+		It("accepts params read with valueFrom whatever their value", func() {
+			workload.Spec.Params[0] = v1alpha1.Param{Name: "language", ValueFrom: &v1alpha1.ParamValueSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}, Key: "language"},
+			}}
+
+			Expect(contract.Unmet(workload)).To(BeEmpty())
+		})
+	})
+})
@@ -190,12 +190,20 @@ var _ = Describe("Workload", func() {
 			Expect(jsonValue).NotTo(ContainSubstring("omitempty"))
 		})
 
-		It("requires value", func() {
+		It("does not require value, which valueFrom can take the place of", func() {
 			valueField, found := workloadParamType.FieldByName("Value")
 			Expect(found).To(BeTrue())
 			jsonValue := valueField.Tag.Get("json")
 			Expect(jsonValue).To(ContainSubstring("value"))
-			Expect(jsonValue).NotTo(ContainSubstring("omitempty"))
+			Expect(jsonValue).To(ContainSubstring("omitempty"))
+		})
+
+		It("does not require valueFrom", func() {
+			valueFromField, found := workloadParamType.FieldByName("ValueFrom")
+			Expect(found).To(BeTrue())
+			jsonValue := valueFromField.Tag.Get("json")
+			Expect(jsonValue).To(ContainSubstring("valueFrom"))
+			Expect(jsonValue).To(ContainSubstring("omitempty"))
 		})
 	})
 })
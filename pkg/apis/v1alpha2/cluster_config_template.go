@@ -0,0 +1,96 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha2
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha2
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+type ClusterConfigTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ConfigTemplateSpec            `json:"spec"`
+	Status            v1alpha1.ConfigTemplateStatus `json:"status,omitempty"`
+}
+
+type ConfigTemplateSpec struct {
+	v1alpha1.TemplateSpec `json:",inline"`
+
+	// Outputs are the paths, in the stamped object, of what the template
+	// provides to the resources that consume it. Templates with patches
+	// have none.
+	// +optional
+	Outputs ConfigOutputs `json:"outputs"`
+
+	// Patches, when set, output a config input of the resource patched
+	// the way kustomize patches objects, in place of stamping an object.
+	// +optional
+	Patches *v1alpha1.ConfigPatches `json:"patches,omitempty"`
+}
+
+type ConfigOutputs struct {
+	// Config is the path of the config, read as `config` by consumers.
+	// Either it or ConfigExpression must be set.
+	// +optional
+	Config string `json:"config,omitempty"`
+
+	// ConfigExpression is a CEL expression over the stamped object,
+	// available to it as `value`, that produces the config.
+	// +optional
+	ConfigExpression string `json:"configExpression,omitempty"`
+
+	// ConfigDefault is the config output while the stamped object has none
+	// to read yet.
+	// +optional
+	ConfigDefault *apiextensionsv1.JSON `json:"configDefault,omitempty"`
+
+	// Optional are the outputs, of config, that resources consuming them
+	// are stamped without, as null, while the stamped object has not
+	// populated them.
+	// +optional
+	Optional []string `json:"optional,omitempty"`
+
+	// Sensitive are the outputs, of config, whose values are redacted in
+	// the status, Events and logs of workloads and deliverables. Resources
+	// consuming them are stamped with the values.
+	// +optional
+	Sensitive []string `json:"sensitive,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+type ClusterConfigTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterConfigTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ClusterConfigTemplate{},
+		&ClusterConfigTemplateList{},
+	)
+}
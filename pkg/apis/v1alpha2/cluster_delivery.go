@@ -0,0 +1,98 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha2
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=".spec.description"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+type ClusterDelivery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ClusterDeliverySpec            `json:"spec"`
+	Status            v1alpha1.ClusterDeliveryStatus `json:"status,omitempty"`
+}
+
+type ClusterDeliverySpec struct {
+	// Description tells developers what the delivery does and which
+	// deliverables it is meant for.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	Resources []v1alpha1.ClusterDeliveryResource `json:"resources"`
+	Selector  Selector                           `json:"selector"`
+
+	// Scheduling hints applied to the objects stamped for every resource.
+	// +optional
+	Scheduling *v1alpha1.SchedulingHints `json:"scheduling,omitempty"`
+
+	// Propagation of the deliverable's labels and annotations to the objects
+	// stamped for every resource. Defaults to propagating none.
+	// +optional
+	Propagation *v1alpha1.MetadataPropagation `json:"propagation,omitempty"`
+
+	// Transforms are named expressions that resources' sources and configs
+	// can apply to the outputs they consume.
+	// +optional
+	Transforms []v1alpha1.OutputTransform `json:"transforms,omitempty"`
+
+	// Teardown, when set, holds back the deletion of the blueprint until
+	// the objects stamped on its behalf are deleted or orphaned.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +optional
+	Teardown string `json:"teardown,omitempty"`
+
+	// Target, when set, is the cluster the objects stamped for deliverables
+	// are applied to, in place of the deliverables' own.
+	// +optional
+	Target *v1alpha1.DeliveryTarget `json:"target,omitempty"`
+
+	// PullRequest, when set, is the GitOps repository the objects stamped
+	// for resources with submission: PullRequest are proposed to.
+	// +optional
+	PullRequest *v1alpha1.PullRequestTarget `json:"pullRequest,omitempty"`
+
+	// PreDelete are hooks run, in order, when a deliverable realized with
+	// the delivery is deleted.
+	// +optional
+	PreDelete []v1alpha1.PreDeleteHook `json:"preDelete,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+type ClusterDeliveryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterDelivery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ClusterDelivery{},
+		&ClusterDeliveryList{},
+	)
+}
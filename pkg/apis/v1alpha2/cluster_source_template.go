@@ -0,0 +1,107 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha2
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha2
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+type ClusterSourceTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              SourceTemplateSpec            `json:"spec"`
+	Status            v1alpha1.SourceTemplateStatus `json:"status,omitempty"`
+}
+
+type SourceTemplateSpec struct {
+	v1alpha1.TemplateSpec `json:",inline"`
+
+	// Outputs are the paths, in the stamped object, of what the template
+	// provides to the resources that consume it. They may be left out with
+	// the Flux preset.
+	// +optional
+	Outputs SourceOutputs `json:"outputs,omitempty"`
+}
+
+type SourceOutputs struct {
+	// URL is the path of the source url, read as `url` by consumers.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Revision is the path of the source revision, read as `revision` by
+	// consumers.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// URLExpression is a CEL expression over the stamped object, available
+	// to it as `value`, that produces the source url. It is an alternative
+	// to URL.
+	// +optional
+	URLExpression string `json:"urlExpression,omitempty"`
+
+	// RevisionExpression is a CEL expression over the stamped object,
+	// available to it as `value`, that produces the source revision. It is
+	// an alternative to Revision.
+	// +optional
+	RevisionExpression string `json:"revisionExpression,omitempty"`
+
+	// URLDefault is the source url output while the stamped object has
+	// none to read yet.
+	// +optional
+	URLDefault *apiextensionsv1.JSON `json:"urlDefault,omitempty"`
+
+	// RevisionDefault is the source revision output while the stamped
+	// object has none to read yet.
+	// +optional
+	RevisionDefault *apiextensionsv1.JSON `json:"revisionDefault,omitempty"`
+
+	// Optional are the outputs, of url and revision, that resources consuming them
+	// are stamped without, as null, while the stamped object has not
+	// populated them.
+	// +optional
+	Optional []string `json:"optional,omitempty"`
+
+	// Sensitive are the outputs, of url and revision, whose values are redacted in
+	// the status, Events and logs of workloads and deliverables. Resources
+	// consuming them are stamped with the values.
+	// +optional
+	Sensitive []string `json:"sensitive,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+type ClusterSourceTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterSourceTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ClusterSourceTemplate{},
+		&ClusterSourceTemplateList{},
+	)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +versionName=v1alpha2
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=".spec.description"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+type ClusterSupplyChain struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              SupplyChainSpec            `json:"spec"`
+	Status            v1alpha1.SupplyChainStatus `json:"status,omitempty"`
+}
+
+type SupplyChainSpec struct {
+	// Description tells developers what the supply chain does and which
+	// workloads it is meant for.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	Resources []v1alpha1.SupplyChainResource `json:"resources"`
+	Selector  Selector                       `json:"selector"`
+
+	// Platforms the supply chain can build for and deploy to. When empty,
+	// every platform is supported.
+	// +optional
+	Platforms []v1alpha1.Platform `json:"platforms,omitempty"`
+
+	// Requires is what workloads must set to be realized with the supply
+	// chain.
+	// +optional
+	Requires *v1alpha1.WorkloadContract `json:"requires,omitempty"`
+
+	// Scheduling hints applied to the objects stamped for every resource.
+	// +optional
+	Scheduling *v1alpha1.SchedulingHints `json:"scheduling,omitempty"`
+
+	// Propagation of the workload's labels and annotations to the objects
+	// stamped for every resource. Defaults to propagating none.
+	// +optional
+	Propagation *v1alpha1.MetadataPropagation `json:"propagation,omitempty"`
+
+	// Transforms are named expressions that resources' sources, images and
+	// configs can apply to the outputs they consume.
+	// +optional
+	Transforms []v1alpha1.OutputTransform `json:"transforms,omitempty"`
+
+	// Teardown, when set, holds back the deletion of the blueprint until
+	// the objects stamped on its behalf are deleted or orphaned.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +optional
+	Teardown string `json:"teardown,omitempty"`
+
+	// PreDelete are hooks run, in order, when a workload realized with the
+	// supply chain is deleted.
+	// +optional
+	PreDelete []v1alpha1.PreDeleteHook `json:"preDelete,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+type ClusterSupplyChainList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterSupplyChain `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&ClusterSupplyChain{},
+		&ClusterSupplyChainList{},
+	)
+}
@@ -0,0 +1,255 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var (
+	_ conversion.Convertible = &ClusterSupplyChain{}
+	_ conversion.Convertible = &ClusterDelivery{}
+	_ conversion.Convertible = &ClusterSourceTemplate{}
+	_ conversion.Convertible = &ClusterImageTemplate{}
+	_ conversion.Convertible = &ClusterConfigTemplate{}
+)
+
+func (c *ClusterSupplyChain) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha1.ClusterSupplyChain)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	dst.ObjectMeta = c.ObjectMeta
+	dst.Spec = v1alpha1.SupplyChainSpec{
+		Description: c.Spec.Description,
+		Resources:   c.Spec.Resources,
+		Selector:    c.Spec.Selector.MatchLabels,
+		Platforms:   c.Spec.Platforms,
+		Requires:    c.Spec.Requires,
+		Scheduling:  c.Spec.Scheduling,
+		Propagation: c.Spec.Propagation,
+		Transforms:  c.Spec.Transforms,
+		Teardown:    c.Spec.Teardown,
+		PreDelete:   c.Spec.PreDelete,
+	}
+	dst.Status = c.Status
+	return nil
+}
+
+func (c *ClusterSupplyChain) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha1.ClusterSupplyChain)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	c.ObjectMeta = src.ObjectMeta
+	c.Spec = SupplyChainSpec{
+		Description: src.Spec.Description,
+		Resources:   src.Spec.Resources,
+		Selector:    Selector{MatchLabels: src.Spec.Selector},
+		Platforms:   src.Spec.Platforms,
+		Requires:    src.Spec.Requires,
+		Scheduling:  src.Spec.Scheduling,
+		Propagation: src.Spec.Propagation,
+		Transforms:  src.Spec.Transforms,
+		Teardown:    src.Spec.Teardown,
+		PreDelete:   src.Spec.PreDelete,
+	}
+	c.Status = src.Status
+	return nil
+}
+
+func (c *ClusterDelivery) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha1.ClusterDelivery)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	dst.ObjectMeta = c.ObjectMeta
+	dst.Spec = v1alpha1.ClusterDeliverySpec{
+		Description: c.Spec.Description,
+		Resources:   c.Spec.Resources,
+		Selector:    c.Spec.Selector.MatchLabels,
+		Scheduling:  c.Spec.Scheduling,
+		Propagation: c.Spec.Propagation,
+		Transforms:  c.Spec.Transforms,
+		Teardown:    c.Spec.Teardown,
+		Target:      c.Spec.Target,
+		PullRequest: c.Spec.PullRequest,
+		PreDelete:   c.Spec.PreDelete,
+	}
+	dst.Status = c.Status
+	return nil
+}
+
+func (c *ClusterDelivery) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha1.ClusterDelivery)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	c.ObjectMeta = src.ObjectMeta
+	c.Spec = ClusterDeliverySpec{
+		Description: src.Spec.Description,
+		Resources:   src.Spec.Resources,
+		Selector:    Selector{MatchLabels: src.Spec.Selector},
+		Scheduling:  src.Spec.Scheduling,
+		Propagation: src.Spec.Propagation,
+		Transforms:  src.Spec.Transforms,
+		Teardown:    src.Spec.Teardown,
+		Target:      src.Spec.Target,
+		PullRequest: src.Spec.PullRequest,
+		PreDelete:   src.Spec.PreDelete,
+	}
+	c.Status = src.Status
+	return nil
+}
+
+func (c *ClusterSourceTemplate) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha1.ClusterSourceTemplate)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	dst.ObjectMeta = c.ObjectMeta
+	dst.Spec = v1alpha1.SourceTemplateSpec{
+		TemplateSpec:       c.Spec.TemplateSpec,
+		URLPath:            c.Spec.Outputs.URL,
+		RevisionPath:       c.Spec.Outputs.Revision,
+		URLExpression:      c.Spec.Outputs.URLExpression,
+		RevisionExpression: c.Spec.Outputs.RevisionExpression,
+		URLDefault:         c.Spec.Outputs.URLDefault,
+		RevisionDefault:    c.Spec.Outputs.RevisionDefault,
+		OptionalOutputs:    c.Spec.Outputs.Optional,
+		SensitiveOutputs:   c.Spec.Outputs.Sensitive,
+	}
+	dst.Status = c.Status
+	return nil
+}
+
+func (c *ClusterSourceTemplate) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha1.ClusterSourceTemplate)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	c.ObjectMeta = src.ObjectMeta
+	c.Spec = SourceTemplateSpec{
+		TemplateSpec: src.Spec.TemplateSpec,
+		Outputs: SourceOutputs{
+			URL:                src.Spec.URLPath,
+			Revision:           src.Spec.RevisionPath,
+			URLExpression:      src.Spec.URLExpression,
+			RevisionExpression: src.Spec.RevisionExpression,
+			URLDefault:         src.Spec.URLDefault,
+			RevisionDefault:    src.Spec.RevisionDefault,
+			Optional:           src.Spec.OptionalOutputs,
+			Sensitive:          src.Spec.SensitiveOutputs,
+		},
+	}
+	c.Status = src.Status
+	return nil
+}
+
+func (c *ClusterImageTemplate) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha1.ClusterImageTemplate)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	dst.ObjectMeta = c.ObjectMeta
+	dst.Spec = v1alpha1.ImageTemplateSpec{
+		TemplateSpec:     c.Spec.TemplateSpec,
+		ImagePath:        c.Spec.Outputs.Image,
+		ImageExpression:  c.Spec.Outputs.ImageExpression,
+		ImageDefault:     c.Spec.Outputs.ImageDefault,
+		OptionalOutputs:  c.Spec.Outputs.Optional,
+		SensitiveOutputs: c.Spec.Outputs.Sensitive,
+	}
+	dst.Status = c.Status
+	return nil
+}
+
+func (c *ClusterImageTemplate) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha1.ClusterImageTemplate)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	c.ObjectMeta = src.ObjectMeta
+	c.Spec = ImageTemplateSpec{
+		TemplateSpec: src.Spec.TemplateSpec,
+		Outputs: ImageOutputs{
+			Image:           src.Spec.ImagePath,
+			ImageExpression: src.Spec.ImageExpression,
+			ImageDefault:    src.Spec.ImageDefault,
+			Optional:        src.Spec.OptionalOutputs,
+			Sensitive:       src.Spec.SensitiveOutputs,
+		},
+	}
+	c.Status = src.Status
+	return nil
+}
+
+func (c *ClusterConfigTemplate) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha1.ClusterConfigTemplate)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	dst.ObjectMeta = c.ObjectMeta
+	dst.Spec = v1alpha1.ConfigTemplateSpec{
+		TemplateSpec:     c.Spec.TemplateSpec,
+		ConfigPath:       c.Spec.Outputs.Config,
+		ConfigExpression: c.Spec.Outputs.ConfigExpression,
+		ConfigDefault:    c.Spec.Outputs.ConfigDefault,
+		OptionalOutputs:  c.Spec.Outputs.Optional,
+		SensitiveOutputs: c.Spec.Outputs.Sensitive,
+		Patches:          c.Spec.Patches,
+	}
+	dst.Status = c.Status
+	return nil
+}
+
+func (c *ClusterConfigTemplate) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha1.ClusterConfigTemplate)
+	if !ok {
+		return unexpectedHub(hub)
+	}
+
+	c.ObjectMeta = src.ObjectMeta
+	c.Spec = ConfigTemplateSpec{
+		TemplateSpec: src.Spec.TemplateSpec,
+		Outputs: ConfigOutputs{
+			Config:           src.Spec.ConfigPath,
+			ConfigExpression: src.Spec.ConfigExpression,
+			ConfigDefault:    src.Spec.ConfigDefault,
+			Optional:         src.Spec.OptionalOutputs,
+			Sensitive:        src.Spec.SensitiveOutputs,
+		},
+		Patches: src.Spec.Patches,
+	}
+	c.Status = src.Status
+	return nil
+}
+
+func unexpectedHub(hub conversion.Hub) error {
+	return fmt.Errorf("unexpected conversion hub %T", hub)
+}
@@ -0,0 +1,422 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apix "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+	webhookconversion "sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha2"
+)
+
+var _ = Describe("Conversion", func() {
+	var (
+		meta         metav1.ObjectMeta
+		templateSpec v1alpha1.TemplateSpec
+		status       v1alpha1.SupplyChainStatus
+	)
+
+	BeforeEach(func() {
+		meta = metav1.ObjectMeta{
+			Name:        "my-blueprint",
+			Labels:      map[string]string{"team": "platform"},
+			Annotations: map[string]string{"note": "kept"},
+			Generation:  3,
+		}
+		templateSpec = v1alpha1.TemplateSpec{
+			Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap"}`)},
+			Params: v1alpha1.DefaultParams{
+				{Name: "flavour", DefaultValue: apix.JSON{Raw: []byte(`"vanilla"`)}, Description: "what to build"},
+			},
+		}
+		status = v1alpha1.SupplyChainStatus{
+			ObservedGeneration: 2,
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+			},
+		}
+	})
+
+	// roundTrip converts hub to spoke and back into a fresh hub, as the
+	// apiserver does when a v1alpha1 object is read and written as v1alpha2.
+	roundTrip := func(hub conversion.Hub, spoke conversion.Convertible, back conversion.Hub) {
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		Expect(spoke.ConvertTo(back)).To(Succeed())
+		Expect(back).To(Equal(hub))
+	}
+
+	Describe("ClusterSupplyChain", func() {
+		var hub *v1alpha1.ClusterSupplyChain
+
+		BeforeEach(func() {
+			hub = &v1alpha1.ClusterSupplyChain{
+				ObjectMeta: meta,
+				Spec: v1alpha1.SupplyChainSpec{
+					Description: "builds web apps",
+					Resources: []v1alpha1.SupplyChainResource{
+						{
+							Name:        "source-provider",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterSourceTemplate", Name: "git"},
+						},
+						{
+							Name:            "builder",
+							TemplateRef:     v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "kpack"},
+							Sources:         []v1alpha1.ResourceReference{{Name: "source", Resource: "source-provider", Transform: "subpath"}},
+							TargetNamespace: "builds",
+						},
+					},
+					Selector:  map[string]string{"apps.example.com/type": "web"},
+					Platforms: []v1alpha1.Platform{{OS: "linux", Arch: "amd64"}},
+					Requires: &v1alpha1.WorkloadContract{
+						Fields: []v1alpha1.FieldSelectorRequirement{{Key: "spec.source.git", Operator: v1alpha1.FieldSelectorOpExists}},
+						Params: []v1alpha1.RequiredParam{{Name: "language", Values: []string{"java", "go"}}},
+					},
+					Scheduling: &v1alpha1.SchedulingHints{PriorityClassName: "builds", Tolerations: []corev1.Toleration{{Key: "dedicated"}}},
+					Propagation: &v1alpha1.MetadataPropagation{
+						Labels:      &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+						Annotations: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"example.com/*"}},
+					},
+					Transforms: []v1alpha1.OutputTransform{{Name: "subpath", Expression: `{"url": value.url, "revision": value.revision}`}},
+					Teardown:   v1alpha1.OrphanTeardownPolicy,
+					PreDelete: []v1alpha1.PreDeleteHook{
+						{Name: "release-dns", TemplateRef: v1alpha1.PreDeleteHookTemplateReference{Kind: "ClusterTemplate", Name: "dns-cleanup"}},
+					},
+				},
+				Status: status,
+			}
+		})
+
+		It("moves the selector labels under matchLabels", func() {
+			spoke := &v1alpha2.ClusterSupplyChain{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Selector).To(Equal(v1alpha2.Selector{
+				MatchLabels: map[string]string{"apps.example.com/type": "web"},
+			}))
+			Expect(spoke.Spec.Resources).To(Equal(hub.Spec.Resources))
+			Expect(spoke.Spec.Propagation).To(Equal(hub.Spec.Propagation))
+			Expect(spoke.ObjectMeta).To(Equal(hub.ObjectMeta))
+			Expect(spoke.Status).To(Equal(hub.Status))
+		})
+
+		It("round-trips through v1alpha2 without loss", func() {
+			roundTrip(hub, &v1alpha2.ClusterSupplyChain{}, &v1alpha1.ClusterSupplyChain{})
+		})
+
+		It("round-trips v1alpha2 objects through the hub without loss", func() {
+			spoke := &v1alpha2.ClusterSupplyChain{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+
+			stored := &v1alpha1.ClusterSupplyChain{}
+			Expect(spoke.ConvertTo(stored)).To(Succeed())
+			again := &v1alpha2.ClusterSupplyChain{}
+			Expect(again.ConvertFrom(stored)).To(Succeed())
+			Expect(again).To(Equal(spoke))
+		})
+	})
+
+	Describe("ClusterDelivery", func() {
+		It("round-trips through v1alpha2 without loss", func() {
+			hub := &v1alpha1.ClusterDelivery{
+				ObjectMeta: meta,
+				Spec: v1alpha1.ClusterDeliverySpec{
+					Description: "deploys web apps",
+					Resources: []v1alpha1.ClusterDeliveryResource{
+						{
+							Name:        "deployer",
+							TemplateRef: v1alpha1.DeliveryClusterTemplateReference{Kind: "ClusterDeploymentTemplate", Name: "app-deploy"},
+						},
+					},
+					Selector: map[string]string{"apps.example.com/type": "web"},
+					Propagation: &v1alpha1.MetadataPropagation{
+						Labels: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"app.kubernetes.io/part-of"}},
+					},
+					Transforms: []v1alpha1.OutputTransform{{Name: "wrap", Expression: `{"manifest": value}`}},
+					Teardown:   v1alpha1.DeleteTeardownPolicy,
+					Target: &v1alpha1.DeliveryTarget{
+						KubeconfigSecretRef: &v1alpha1.KubeconfigSecretReference{Name: "prod-kubeconfig", Namespace: "clusters"},
+					},
+					PullRequest: &v1alpha1.PullRequestTarget{
+						Provider:       v1alpha1.GitHubPullRequestProvider,
+						Repository:     "example/gitops",
+						TokenSecretRef: v1alpha1.TokenSecretReference{Name: "github-token", Namespace: "clusters"},
+					},
+					PreDelete: []v1alpha1.PreDeleteHook{
+						{Name: "drain", TemplateRef: v1alpha1.PreDeleteHookTemplateReference{Kind: "ClusterTemplate", Name: "drain-traffic"}, FailurePolicy: v1alpha1.IgnorePreDeleteHookFailurePolicy},
+					},
+				},
+				Status: v1alpha1.ClusterDeliveryStatus{ObservedGeneration: 2},
+			}
+
+			spoke := &v1alpha2.ClusterDelivery{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Selector.MatchLabels).To(Equal(hub.Spec.Selector))
+			Expect(spoke.Spec.Propagation).To(Equal(hub.Spec.Propagation))
+
+			roundTrip(hub, &v1alpha2.ClusterDelivery{}, &v1alpha1.ClusterDelivery{})
+		})
+	})
+
+	Describe("ClusterSourceTemplate", func() {
+		It("groups the url and revision paths under outputs", func() {
+			hub := &v1alpha1.ClusterSourceTemplate{
+				ObjectMeta: meta,
+				Spec: v1alpha1.SourceTemplateSpec{
+					TemplateSpec: templateSpec,
+					URLPath:      ".status.artifact.url",
+					RevisionPath: ".status.artifact.revision",
+				},
+			}
+
+			spoke := &v1alpha2.ClusterSourceTemplate{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Outputs).To(Equal(v1alpha2.SourceOutputs{
+				URL:      ".status.artifact.url",
+				Revision: ".status.artifact.revision",
+			}))
+			Expect(spoke.Spec.TemplateSpec).To(Equal(templateSpec))
+
+			roundTrip(hub, &v1alpha2.ClusterSourceTemplate{}, &v1alpha1.ClusterSourceTemplate{})
+		})
+
+		It("carries output expressions and defaults under outputs", func() {
+			hub := &v1alpha1.ClusterSourceTemplate{
+				ObjectMeta: meta,
+				Spec: v1alpha1.SourceTemplateSpec{
+					TemplateSpec:       templateSpec,
+					URLExpression:      `value.status.url.replace("https://", "")`,
+					RevisionExpression: `value.status.revision`,
+					URLDefault:         &apix.JSON{Raw: []byte(`"https://example.com/placeholder.tar.gz"`)},
+				},
+			}
+
+			spoke := &v1alpha2.ClusterSourceTemplate{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Outputs).To(Equal(v1alpha2.SourceOutputs{
+				URLExpression:      `value.status.url.replace("https://", "")`,
+				RevisionExpression: `value.status.revision`,
+				URLDefault:         &apix.JSON{Raw: []byte(`"https://example.com/placeholder.tar.gz"`)},
+			}))
+
+			roundTrip(hub, &v1alpha2.ClusterSourceTemplate{}, &v1alpha1.ClusterSourceTemplate{})
+		})
+
+		It("carries optional and sensitive outputs under outputs", func() {
+			hub := &v1alpha1.ClusterSourceTemplate{
+				ObjectMeta: meta,
+				Spec: v1alpha1.SourceTemplateSpec{
+					TemplateSpec:     templateSpec,
+					URLPath:          ".status.artifact.url",
+					RevisionPath:     ".status.artifact.revision",
+					OptionalOutputs:  []string{"revision"},
+					SensitiveOutputs: []string{"url"},
+				},
+			}
+
+			spoke := &v1alpha2.ClusterSourceTemplate{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Outputs.Optional).To(Equal([]string{"revision"}))
+			Expect(spoke.Spec.Outputs.Sensitive).To(Equal([]string{"url"}))
+
+			roundTrip(hub, &v1alpha2.ClusterSourceTemplate{}, &v1alpha1.ClusterSourceTemplate{})
+		})
+	})
+
+	Describe("ClusterImageTemplate", func() {
+		It("moves the image path under outputs", func() {
+			hub := &v1alpha1.ClusterImageTemplate{
+				ObjectMeta: meta,
+				Spec: v1alpha1.ImageTemplateSpec{
+					TemplateSpec: templateSpec,
+					ImagePath:    ".status.latestImage",
+				},
+			}
+
+			spoke := &v1alpha2.ClusterImageTemplate{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Outputs.Image).To(Equal(".status.latestImage"))
+
+			roundTrip(hub, &v1alpha2.ClusterImageTemplate{}, &v1alpha1.ClusterImageTemplate{})
+		})
+
+		It("carries sensitive outputs under outputs", func() {
+			hub := &v1alpha1.ClusterImageTemplate{
+				ObjectMeta: meta,
+				Spec: v1alpha1.ImageTemplateSpec{
+					TemplateSpec:     templateSpec,
+					ImagePath:        ".status.latestImage",
+					SensitiveOutputs: []string{"image"},
+				},
+			}
+
+			spoke := &v1alpha2.ClusterImageTemplate{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Outputs.Sensitive).To(Equal([]string{"image"}))
+
+			roundTrip(hub, &v1alpha2.ClusterImageTemplate{}, &v1alpha1.ClusterImageTemplate{})
+		})
+	})
+
+	Describe("ClusterConfigTemplate", func() {
+		It("moves the config path under outputs", func() {
+			hub := &v1alpha1.ClusterConfigTemplate{
+				ObjectMeta: meta,
+				Spec: v1alpha1.ConfigTemplateSpec{
+					TemplateSpec: templateSpec,
+					ConfigPath:   ".data",
+				},
+			}
+
+			spoke := &v1alpha2.ClusterConfigTemplate{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Outputs.Config).To(Equal(".data"))
+
+			roundTrip(hub, &v1alpha2.ClusterConfigTemplate{}, &v1alpha1.ClusterConfigTemplate{})
+		})
+
+		It("carries sensitive outputs under outputs", func() {
+			hub := &v1alpha1.ClusterConfigTemplate{
+				ObjectMeta: meta,
+				Spec: v1alpha1.ConfigTemplateSpec{
+					TemplateSpec:     templateSpec,
+					ConfigPath:       ".data",
+					SensitiveOutputs: []string{"config"},
+				},
+			}
+
+			spoke := &v1alpha2.ClusterConfigTemplate{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Outputs.Sensitive).To(Equal([]string{"config"}))
+
+			roundTrip(hub, &v1alpha2.ClusterConfigTemplate{}, &v1alpha1.ClusterConfigTemplate{})
+		})
+
+		It("keeps the patches", func() {
+			hub := &v1alpha1.ClusterConfigTemplate{
+				ObjectMeta: meta,
+				Spec: v1alpha1.ConfigTemplateSpec{
+					Patches: &v1alpha1.ConfigPatches{
+						Config: "app",
+						JSON6902: []v1alpha1.JSON6902Operation{
+							{Op: "replace", Path: "/spec/replicas", Value: &apix.JSON{Raw: []byte(`3`)}},
+						},
+					},
+				},
+			}
+
+			spoke := &v1alpha2.ClusterConfigTemplate{}
+			Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			Expect(spoke.Spec.Patches).To(Equal(hub.Spec.Patches))
+
+			roundTrip(hub, &v1alpha2.ClusterConfigTemplate{}, &v1alpha1.ClusterConfigTemplate{})
+		})
+	})
+
+	It("rejects hubs of another kind", func() {
+		Expect((&v1alpha2.ClusterSupplyChain{}).ConvertTo(&v1alpha1.ClusterDelivery{})).
+			To(MatchError(ContainSubstring("unexpected conversion hub *v1alpha1.ClusterDelivery")))
+	})
+
+	Describe("the conversion webhook", func() {
+		var webhook *webhookconversion.Webhook
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+			Expect(v1alpha2.AddToScheme(scheme)).To(Succeed())
+
+			webhook = &webhookconversion.Webhook{}
+			Expect(webhook.InjectScheme(scheme)).To(Succeed())
+		})
+
+		convert := func(desiredAPIVersion string, object string) map[string]interface{} {
+			review := apix.ConversionReview{
+				TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+				Request: &apix.ConversionRequest{
+					UID:               types.UID("some-uid"),
+					DesiredAPIVersion: desiredAPIVersion,
+					Objects:           []runtime.RawExtension{{Raw: []byte(object)}},
+				},
+			}
+			body, err := json.Marshal(review)
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			webhook.ServeHTTP(recorder, httptest.NewRequest("POST", "/convert", bytes.NewReader(body)))
+
+			response := apix.ConversionReview{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Response.Result.Status).To(Equal(metav1.StatusSuccess), response.Response.Result.Message)
+			Expect(response.Response.ConvertedObjects).To(HaveLen(1))
+
+			converted := map[string]interface{}{}
+			Expect(json.Unmarshal(response.Response.ConvertedObjects[0].Raw, &converted)).To(Succeed())
+			return converted
+		}
+
+		It("serves stored v1alpha1 supply chains as v1alpha2 and back", func() {
+			stored := `{
+				"apiVersion": "carto.run/v1alpha1",
+				"kind": "ClusterSupplyChain",
+				"metadata": {"name": "web"},
+				"spec": {
+					"selector": {"apps.example.com/type": "web"},
+					"resources": [{"name": "source-provider", "templateRef": {"kind": "ClusterSourceTemplate", "name": "git"}}]
+				}
+			}`
+
+			served := convert("carto.run/v1alpha2", stored)
+			Expect(served["apiVersion"]).To(Equal("carto.run/v1alpha2"))
+			Expect(served["spec"]).To(HaveKeyWithValue("selector", map[string]interface{}{
+				"matchLabels": map[string]interface{}{"apps.example.com/type": "web"},
+			}))
+
+			served["apiVersion"] = "carto.run/v1alpha2"
+			body, err := json.Marshal(served)
+			Expect(err).NotTo(HaveOccurred())
+
+			restored := convert("carto.run/v1alpha1", string(body))
+			Expect(restored["apiVersion"]).To(Equal("carto.run/v1alpha1"))
+			Expect(restored["spec"]).To(HaveKeyWithValue("selector", map[string]interface{}{"apps.example.com/type": "web"}))
+		})
+
+		It("serves stored v1alpha1 source templates as v1alpha2", func() {
+			stored := `{
+				"apiVersion": "carto.run/v1alpha1",
+				"kind": "ClusterSourceTemplate",
+				"metadata": {"name": "git"},
+				"spec": {"urlPath": ".status.url", "revisionPath": ".status.revision"}
+			}`
+
+			served := convert("carto.run/v1alpha2", stored)
+			Expect(served["spec"]).To(HaveKeyWithValue("outputs", map[string]interface{}{
+				"url":      ".status.url",
+				"revision": ".status.revision",
+			}))
+			Expect(served["spec"]).NotTo(HaveKey("urlPath"))
+		})
+	})
+})
@@ -0,0 +1,42 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1alpha2 is the second version of the carto.run API. It replaces
+// the label map selecting owners with a Selector struct and groups each
+// template's output paths under outputs, named after the inputs templates
+// consume. Objects are still stored as v1alpha1, the conversion hub, and
+// types v1alpha2 does not change are shared with it.
+// +versionName=v1alpha2
+// +groupName=carto.run
+// +kubebuilder:object:generate=true
+
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	SchemeGroupVersion = schema.GroupVersion{
+		Group:   "carto.run",
+		Version: "v1alpha2",
+	}
+
+	SchemeBuilder = &scheme.Builder{
+		GroupVersion: SchemeGroupVersion,
+	}
+
+	AddToScheme = SchemeBuilder.AddToScheme
+)
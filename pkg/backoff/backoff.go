@@ -0,0 +1,87 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backoff
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The defaults of a Backoff for templates a blueprint names that do not
+// exist yet.
+const (
+	DefaultBaseDelay = time.Second
+	DefaultMaxDelay  = 5 * time.Minute
+)
+
+// Backoff tracks, per object, how many times in a row reconciling it has
+// failed, and how long to wait before reconciling it again. The delay
+// doubles with each failure from the base delay, up to the max delay, and
+// is jittered so that objects that failed together do not retry together.
+// It is safe for concurrent use.
+type Backoff struct {
+	baseDelay time.Duration
+	maxDelay  time.Duration
+
+	mu       sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+// New returns a Backoff whose delays start at baseDelay and are capped at
+// maxDelay.
+func New(baseDelay, maxDelay time.Duration) *Backoff {
+	return &Backoff{
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+		failures:  map[types.NamespacedName]int{},
+	}
+}
+
+// Next records another failure of the object named key and returns how
+// long to wait before reconciling it again: between half and all of the
+// exponential delay.
+func (b *Backoff) Next(key types.NamespacedName) time.Duration {
+	b.mu.Lock()
+	failures := b.failures[key]
+	b.failures[key] = failures + 1
+	b.mu.Unlock()
+
+	delay := b.maxDelay
+	if failures < 32 {
+		if exponential := b.baseDelay << failures; exponential > 0 && exponential < b.maxDelay {
+			delay = exponential
+		}
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Reset forgets the failures of the object named key, so that its next
+// failure waits the base delay again.
+func (b *Backoff) Reset(key types.NamespacedName) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, key)
+}
+
+// Failures returns how many times in a row the object named key failed.
+func (b *Backoff) Failures(key types.NamespacedName) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures[key]
+}
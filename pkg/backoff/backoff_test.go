@@ -0,0 +1,85 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backoff_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/backoff"
+)
+
+var _ = Describe("Backoff", func() {
+	var (
+		b   *backoff.Backoff
+		key types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		b = backoff.New(time.Second, 10*time.Second)
+		key = types.NamespacedName{Namespace: "my-ns", Name: "my-supply-chain"}
+	})
+
+	It("waits between half and all of the base delay after the first failure", func() {
+		delay := b.Next(key)
+		Expect(delay).To(BeNumerically(">=", 500*time.Millisecond))
+		Expect(delay).To(BeNumerically("<=", time.Second))
+	})
+
+	It("doubles the delay with each failure in a row", func() {
+		b.Next(key)
+		b.Next(key)
+		delay := b.Next(key)
+		Expect(delay).To(BeNumerically(">=", 2*time.Second))
+		Expect(delay).To(BeNumerically("<=", 4*time.Second))
+	})
+
+	It("caps the delay at the max delay", func() {
+		for i := 0; i < 100; i++ {
+			b.Next(key)
+		}
+		delay := b.Next(key)
+		Expect(delay).To(BeNumerically(">=", 5*time.Second))
+		Expect(delay).To(BeNumerically("<=", 10*time.Second))
+	})
+
+	It("jitters the delays", func() {
+		delays := map[time.Duration]bool{}
+		for i := 0; i < 10; i++ {
+			other := types.NamespacedName{Namespace: "my-ns", Name: string(rune('a' + i))}
+			delays[b.Next(other)] = true
+		}
+		Expect(len(delays)).To(BeNumerically(">", 1))
+	})
+
+	It("counts the failures of each object apart", func() {
+		b.Next(key)
+		b.Next(key)
+		Expect(b.Failures(key)).To(Equal(2))
+		Expect(b.Failures(types.NamespacedName{Namespace: "my-ns", Name: "other"})).To(Equal(0))
+	})
+
+	It("starts over from the base delay once reset", func() {
+		for i := 0; i < 5; i++ {
+			b.Next(key)
+		}
+		b.Reset(key)
+		Expect(b.Failures(key)).To(Equal(0))
+		Expect(b.Next(key)).To(BeNumerically("<=", time.Second))
+	})
+})
@@ -0,0 +1,114 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clusterdata exposes a curated set of facts about the cluster to
+// templates, as $(cluster)$: its name and domain, the number of its nodes
+// and the data of a ConfigMap the operator names. Templates read these
+// facts from one sanctioned place, rather than from params every workload
+// and deliverable must set.
+package clusterdata
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultTTL is how long facts are kept before they are read again.
+const DefaultTTL = 30 * time.Second
+
+// Reader reads the facts about the cluster, keeping them for a TTL so that
+// the owners reconciled in the meantime share a read.
+type Reader struct {
+	client    client.Reader
+	name      string
+	domain    string
+	configMap types.NamespacedName
+	ttl       time.Duration
+
+	mu     sync.Mutex
+	data   map[string]interface{}
+	readAt time.Time
+}
+
+// NewReader returns a Reader of the facts about the cluster, reading the
+// nodes and the data of configMap, when its name is set, with c.
+func NewReader(c client.Reader, name, domain string, configMap types.NamespacedName, ttl time.Duration) *Reader {
+	return &Reader{
+		client:    c,
+		name:      name,
+		domain:    domain,
+		configMap: configMap,
+		ttl:       ttl,
+	}
+}
+
+// Read returns the facts templates read as $(cluster)$: the name of the
+// cluster, the DNS domain of its services, such as cluster.local, the
+// number of its nodes, and the data of the named ConfigMap, which is empty
+// when the ConfigMap does not exist. The map returned must not be modified.
+func (r *Reader) Read(ctx context.Context) (map[string]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.data != nil && time.Since(r.readAt) < r.ttl {
+		return r.data, nil
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+
+	data := map[string]interface{}{}
+	if r.configMap.Name != "" {
+		configMap := &corev1.ConfigMap{}
+		err := r.client.Get(ctx, r.configMap, configMap)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("get configmap '%s': %w", r.configMap, err)
+		}
+		for key, value := range configMap.Data {
+			data[key] = value
+		}
+	}
+
+	r.data = map[string]interface{}{
+		"name":   r.name,
+		"domain": r.domain,
+		"nodes":  int64(len(nodes.Items)),
+		"data":   data,
+	}
+	r.readAt = time.Now()
+	return r.data, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the facts templates read as
+// $(cluster)$.
+func NewContext(ctx context.Context, data map[string]interface{}) context.Context {
+	return context.WithValue(ctx, contextKey{}, data)
+}
+
+// FromContext returns the facts carried by ctx, or nil.
+func FromContext(ctx context.Context) map[string]interface{} {
+	data, _ := ctx.Value(contextKey{}).(map[string]interface{})
+	return data
+}
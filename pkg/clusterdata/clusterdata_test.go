@@ -0,0 +1,126 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterdata_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
+)
+
+var _ = Describe("Reader", func() {
+	var (
+		ctx       context.Context
+		c         client.Client
+		configMap types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-data", Namespace: "cartographer-system"},
+				Data:       map[string]string{"ingressDomain": "apps.example.com"},
+			},
+		).Build()
+		configMap = types.NamespacedName{Namespace: "cartographer-system", Name: "cluster-data"}
+	})
+
+	It("reads the name, domain, node count and data of the cluster", func() {
+		reader := clusterdata.NewReader(c, "prod", "cluster.local", configMap, 0)
+
+		data, err := reader.Read(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(map[string]interface{}{
+			"name":   "prod",
+			"domain": "cluster.local",
+			"nodes":  int64(2),
+			"data":   map[string]interface{}{"ingressDomain": "apps.example.com"},
+		}))
+	})
+
+	It("reads empty data when the ConfigMap does not exist", func() {
+		reader := clusterdata.NewReader(c, "prod", "cluster.local", types.NamespacedName{Namespace: "cartographer-system", Name: "missing"}, 0)
+
+		data, err := reader.Read(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data["data"]).To(BeEmpty())
+	})
+
+	It("reads empty data when no ConfigMap is named", func() {
+		reader := clusterdata.NewReader(c, "prod", "cluster.local", types.NamespacedName{}, 0)
+
+		data, err := reader.Read(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data["data"]).To(BeEmpty())
+	})
+
+	It("keeps the facts it read for the TTL", func() {
+		reader := clusterdata.NewReader(c, "prod", "cluster.local", configMap, time.Hour)
+		_, err := reader.Read(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}})).To(Succeed())
+
+		data, err := reader.Read(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data["nodes"]).To(Equal(int64(2)))
+	})
+
+	It("reads the facts again once the TTL has passed", func() {
+		reader := clusterdata.NewReader(c, "prod", "cluster.local", configMap, 0)
+		_, err := reader.Read(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}})).To(Succeed())
+
+		data, err := reader.Read(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data["nodes"]).To(Equal(int64(3)))
+	})
+
+	It("errors when the nodes cannot be listed", func() {
+		reader := clusterdata.NewReader(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build(), "prod", "cluster.local", configMap, 0)
+
+		_, err := reader.Read(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("list nodes: "))
+	})
+})
+
+var _ = Describe("Context", func() {
+	It("carries the facts about the cluster", func() {
+		data := map[string]interface{}{"name": "prod"}
+		Expect(clusterdata.FromContext(clusterdata.NewContext(context.Background(), data))).To(Equal(data))
+	})
+
+	It("carries none by default", func() {
+		Expect(clusterdata.FromContext(context.Background())).To(BeNil())
+	})
+})
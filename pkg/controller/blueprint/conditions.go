@@ -0,0 +1,77 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+func ObjectsAppliedCondition(version string) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintObjectsApplied,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.AppliedObjectsAppliedReason,
+		Message: fmt.Sprintf("applied version %s", version),
+	}
+}
+
+func InvalidTemplateCondition(template v1alpha1.BlueprintTemplate, err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintObjectsApplied,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.InvalidTemplateObjectsAppliedReason,
+		Message: fmt.Sprintf("%s '%s': %s", template.Kind, template.Name, err.Error()),
+	}
+}
+
+func ApplyFailedCondition(obj *unstructured.Unstructured, err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintObjectsApplied,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.ApplyFailedObjectsAppliedReason,
+		Message: fmt.Sprintf("apply %s '%s': %s", obj.GetKind(), obj.GetName(), err.Error()),
+	}
+}
+
+func SupplyChainReadyCondition() metav1.Condition {
+	return metav1.Condition{
+		Type:   v1alpha1.BlueprintSupplyChainReady,
+		Status: metav1.ConditionTrue,
+		Reason: v1alpha1.ReadySupplyChainReadyReason,
+	}
+}
+
+func SupplyChainNotReadyCondition(ready metav1.Condition) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintSupplyChainReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.NotReadySupplyChainReadyReason,
+		Message: fmt.Sprintf("%s: %s", ready.Reason, ready.Message),
+	}
+}
+
+func SupplyChainPendingCondition() metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintSupplyChainReady,
+		Status:  metav1.ConditionUnknown,
+		Reason:  v1alpha1.PendingSupplyChainReadyReason,
+		Message: "waiting for the supply chain to be reconciled",
+	}
+}
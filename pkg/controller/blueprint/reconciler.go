@@ -0,0 +1,258 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+type Reconciler struct {
+	repo                    repository.Repository
+	conditionManager        conditions.ConditionManager
+	conditionManagerBuilder conditions.ConditionManagerBuilder
+}
+
+func NewReconciler(repo repository.Repository, conditionManagerBuilder conditions.ConditionManagerBuilder) *Reconciler {
+	return &Reconciler{
+		repo:                    repo,
+		conditionManagerBuilder: conditionManagerBuilder,
+	}
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logr.FromContext(ctx).WithValues("name", req.Name)
+	logger.Info("started")
+
+	reconcileCtx := logr.NewContext(ctx, logger)
+
+	blueprint, err := r.repo.GetBlueprint(ctx, req.Name)
+	if err != nil {
+		logger.Info("finished")
+		return ctrl.Result{}, fmt.Errorf("get blueprint: %w", err)
+	}
+	if blueprint == nil {
+		logger.Info("finished")
+		return ctrl.Result{}, nil
+	}
+	blueprint = blueprint.DeepCopy()
+	previous := blueprint.Status.DeepCopy()
+
+	r.conditionManager = r.conditionManagerBuilder(v1alpha1.BlueprintReady, blueprint.Status.Conditions)
+
+	err = r.reconcileBlueprint(reconcileCtx, blueprint)
+
+	return r.completeReconciliation(reconcileCtx, blueprint, previous, err)
+}
+
+func (r *Reconciler) completeReconciliation(ctx context.Context, blueprint *v1alpha1.ClusterBlueprint, previous *v1alpha1.ClusterBlueprintStatus, err error) (ctrl.Result, error) {
+	logger := logr.FromContext(ctx)
+
+	var changed bool
+	blueprint.Status.Conditions, changed = r.conditionManager.Finalize()
+
+	applied := blueprint.Status.Version != previous.Version || !equality.Semantic.DeepEqual(blueprint.Status.Objects, previous.Objects)
+	if changed || applied || (blueprint.Status.ObservedGeneration != blueprint.Generation) {
+		blueprint.Status.ObservedGeneration = blueprint.Generation
+		if updateErr := r.repo.StatusUpdate(ctx, blueprint); updateErr != nil {
+			logger.Error(updateErr, "update error")
+			if err == nil {
+				logger.Info("finished")
+				return ctrl.Result{}, fmt.Errorf("update blueprint status: %w", updateErr)
+			}
+		}
+	}
+
+	logger.Info("finished")
+	return ctrl.Result{}, err
+}
+
+// reconcileBlueprint applies the blueprint's templates under its version,
+// then switches its supply chain to them, and only then deletes the objects
+// applied for earlier versions. Workloads therefore move from one version's
+// templates to the next in the single update of the supply chain. Errors
+// that a retry cannot fix, such as a template spec that is not an object,
+// are only reported in the blueprint's conditions.
+func (r *Reconciler) reconcileBlueprint(ctx context.Context, blueprint *v1alpha1.ClusterBlueprint) error {
+	var objects []*unstructured.Unstructured
+	for _, template := range blueprint.Spec.Templates {
+		obj, err := templateObject(blueprint, template)
+		if err != nil {
+			r.conditionManager.AddPositive(InvalidTemplateCondition(template, err))
+			return nil
+		}
+		objects = append(objects, obj)
+	}
+
+	supplyChain, err := supplyChainObject(blueprint)
+	if err != nil {
+		return fmt.Errorf("supply chain object: %w", err)
+	}
+	// The supply chain goes last, so that every template it is switched to
+	// already exists.
+	objects = append(objects, supplyChain)
+
+	var applied []v1alpha1.ObjectReference
+	for _, obj := range objects {
+		if err := r.repo.EnsureObjectExistsOnCluster(ctx, obj, true); err != nil {
+			r.conditionManager.AddPositive(ApplyFailedCondition(obj, err))
+			return err
+		}
+		applied = append(applied, v1alpha1.ObjectReference{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+		})
+	}
+
+	for _, ref := range blueprint.Status.Objects {
+		if containsKindAndName(applied, ref) {
+			continue
+		}
+		stale := &unstructured.Unstructured{}
+		stale.SetAPIVersion(ref.APIVersion)
+		stale.SetKind(ref.Kind)
+		stale.SetName(ref.Name)
+		if err := r.repo.DeleteUnstructured(ctx, stale); err != nil {
+			r.conditionManager.AddPositive(ApplyFailedCondition(stale, err))
+			return err
+		}
+	}
+
+	blueprint.Status.Version = blueprint.Spec.Version
+	blueprint.Status.Objects = applied
+	r.conditionManager.AddPositive(ObjectsAppliedCondition(blueprint.Spec.Version))
+
+	return r.checkSupplyChain(ctx, blueprint, supplyChain.GetGeneration())
+}
+
+// checkSupplyChain reports whether the supply chain is ready at generation,
+// the one the blueprint last applied.
+func (r *Reconciler) checkSupplyChain(ctx context.Context, blueprint *v1alpha1.ClusterBlueprint, generation int64) error {
+	supplyChain, err := r.repo.GetSupplyChain(ctx, blueprint.Name)
+	if err != nil {
+		return fmt.Errorf("get supply chain: %w", err)
+	}
+
+	if supplyChain == nil || supplyChain.Status.ObservedGeneration < generation {
+		r.conditionManager.AddPositive(SupplyChainPendingCondition())
+		return nil
+	}
+
+	ready := meta.FindStatusCondition(supplyChain.Status.Conditions, v1alpha1.SupplyChainReady)
+	switch {
+	case ready == nil || ready.Status == metav1.ConditionUnknown:
+		r.conditionManager.AddPositive(SupplyChainPendingCondition())
+	case ready.Status == metav1.ConditionTrue:
+		r.conditionManager.AddPositive(SupplyChainReadyCondition())
+	default:
+		r.conditionManager.AddPositive(SupplyChainNotReadyCondition(*ready))
+	}
+	return nil
+}
+
+// templateObject is the template of the blueprint's version, named after
+// the blueprint, the template and the version, and labelled as that
+// version of the template.
+func templateObject(blueprint *v1alpha1.ClusterBlueprint, template v1alpha1.BlueprintTemplate) (*unstructured.Unstructured, error) {
+	if len(template.Spec.Raw) == 0 {
+		return nil, errors.New("spec is empty")
+	}
+	spec := map[string]interface{}{}
+	if err := json.Unmarshal(template.Spec.Raw, &spec); err != nil {
+		return nil, fmt.Errorf("spec is not an object: %w", err)
+	}
+
+	name := blueprint.TemplateName(template.Name)
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetAPIVersion(v1alpha1.SchemeGroupVersion.String())
+	obj.SetKind(template.Kind)
+	obj.SetName(name + "-" + blueprint.Spec.Version)
+	obj.SetLabels(map[string]string{
+		v1alpha1.BlueprintLabel:       blueprint.Name,
+		v1alpha1.TemplateNameLabel:    name,
+		v1alpha1.TemplateVersionLabel: blueprint.Spec.Version,
+	})
+	obj.SetOwnerReferences([]metav1.OwnerReference{ownerReference(blueprint)})
+	return obj, nil
+}
+
+// supplyChainObject is the blueprint's ClusterSupplyChain, its references
+// to bundled templates pinned to the blueprint's version.
+func supplyChainObject(blueprint *v1alpha1.ClusterBlueprint) (*unstructured.Unstructured, error) {
+	spec := blueprint.Spec.SupplyChain.DeepCopy()
+	for i := range spec.Resources {
+		ref := &spec.Resources[i].TemplateRef
+		if ref.Git != nil {
+			continue
+		}
+		if ref.Name != "" {
+			ref.Name = blueprint.TemplateName(ref.Name)
+		}
+		for j := range ref.Options {
+			ref.Options[j].Name = blueprint.TemplateName(ref.Options[j].Name)
+		}
+		ref.Version = blueprint.Spec.Version
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+	if err != nil {
+		return nil, fmt.Errorf("to unstructured: %w", err)
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": content}}
+	obj.SetAPIVersion(v1alpha1.SchemeGroupVersion.String())
+	obj.SetKind("ClusterSupplyChain")
+	obj.SetName(blueprint.Name)
+	obj.SetLabels(map[string]string{v1alpha1.BlueprintLabel: blueprint.Name})
+	obj.SetOwnerReferences([]metav1.OwnerReference{ownerReference(blueprint)})
+	return obj, nil
+}
+
+func ownerReference(blueprint *v1alpha1.ClusterBlueprint) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "ClusterBlueprint",
+		Name:       blueprint.Name,
+		UID:        blueprint.UID,
+		Controller: &controller,
+	}
+}
+
+// containsKindAndName reports whether refs hold the object ref refers to,
+// in any version.
+func containsKindAndName(refs []v1alpha1.ObjectReference, ref v1alpha1.ObjectReference) bool {
+	for _, r := range refs {
+		if r.Kind == ref.Kind && r.Name == ref.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+	"github.com/vmware-tanzu/cartographer/pkg/conditions/conditionsfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/controller/blueprint"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+var _ = Describe("Reconciler", func() {
+	var (
+		reconciler       *blueprint.Reconciler
+		out              *Buffer
+		ctx              context.Context
+		req              ctrl.Request
+		conditionManager *conditionsfakes.FakeConditionManager
+		repo             *repositoryfakes.FakeRepository
+		bp               *v1alpha1.ClusterBlueprint
+		supplyChain      *v1alpha1.ClusterSupplyChain
+	)
+
+	BeforeEach(func() {
+		out = NewBuffer()
+		logger := zap.New(zap.WriteTo(out))
+		ctx = logr.NewContext(context.Background(), logger)
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "web"}}
+
+		conditionManager = &conditionsfakes.FakeConditionManager{}
+		conditionManagerBuilder := func(string, []metav1.Condition) conditions.ConditionManager {
+			return conditionManager
+		}
+
+		bp = &v1alpha1.ClusterBlueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", UID: "some-uid", Generation: 1},
+			Spec: v1alpha1.ClusterBlueprintSpec{
+				Version: "v2",
+				SupplyChain: v1alpha1.SupplyChainSpec{
+					Selector: map[string]string{"workload-type": "web"},
+					Resources: []v1alpha1.SupplyChainResource{
+						{
+							Name:        "deploy",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "deploy"},
+						},
+						{
+							Name: "notify",
+							TemplateRef: v1alpha1.ClusterTemplateReference{
+								Kind: "ClusterTemplate",
+								Git:  &v1alpha1.GitTemplateSource{URL: "https://example.com/templates.git", Path: "notify.yaml"},
+							},
+						},
+					},
+				},
+				Templates: []v1alpha1.BlueprintTemplate{
+					{Kind: "ClusterTemplate", Name: "deploy", Spec: runtime.RawExtension{Raw: []byte(`{"template":{}}`)}},
+				},
+			},
+		}
+		supplyChain = &v1alpha1.ClusterSupplyChain{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: 3},
+			Status: v1alpha1.SupplyChainStatus{
+				ObservedGeneration: 3,
+				Conditions:         []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"}},
+			},
+		}
+
+		repo = &repositoryfakes.FakeRepository{}
+		repo.GetBlueprintReturns(bp, nil)
+		repo.GetSupplyChainReturns(supplyChain, nil)
+		repo.EnsureObjectExistsOnClusterStub = func(_ context.Context, obj *unstructured.Unstructured, _ bool) error {
+			if obj.GetKind() == "ClusterSupplyChain" {
+				obj.SetGeneration(3)
+			}
+			return nil
+		}
+
+		reconciler = blueprint.NewReconciler(repo, conditionManagerBuilder)
+	})
+
+	appliedObjects := func() []*unstructured.Unstructured {
+		var objects []*unstructured.Unstructured
+		for i := 0; i < repo.EnsureObjectExistsOnClusterCallCount(); i++ {
+			_, obj, allowUpdate := repo.EnsureObjectExistsOnClusterArgsForCall(i)
+			Expect(allowUpdate).To(BeTrue())
+			objects = append(objects, obj)
+		}
+		return objects
+	}
+
+	It("applies the templates under the version, then the supply chain pinned to it", func() {
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(out).To(Say(`"msg":"started"`))
+		Expect(out).To(Say(`"name":"web"`))
+
+		objects := appliedObjects()
+		Expect(objects).To(HaveLen(2))
+
+		template := objects[0]
+		Expect(template.GetKind()).To(Equal("ClusterTemplate"))
+		Expect(template.GetName()).To(Equal("web-deploy-v2"))
+		Expect(template.GetLabels()).To(Equal(map[string]string{
+			"carto.run/blueprint":        "web",
+			"carto.run/template-name":    "web-deploy",
+			"carto.run/template-version": "v2",
+		}))
+		Expect(template.Object["spec"]).To(Equal(map[string]interface{}{"template": map[string]interface{}{}}))
+		Expect(template.GetOwnerReferences()).To(HaveLen(1))
+		Expect(template.GetOwnerReferences()[0].Kind).To(Equal("ClusterBlueprint"))
+		Expect(template.GetOwnerReferences()[0].UID).To(Equal(types.UID("some-uid")))
+
+		chain := objects[1]
+		Expect(chain.GetKind()).To(Equal("ClusterSupplyChain"))
+		Expect(chain.GetName()).To(Equal("web"))
+		Expect(chain.GetLabels()).To(Equal(map[string]string{"carto.run/blueprint": "web"}))
+		refs, _, _ := unstructured.NestedSlice(chain.Object, "spec", "resources")
+		Expect(refs[0].(map[string]interface{})["templateRef"]).To(Equal(map[string]interface{}{
+			"kind":    "ClusterTemplate",
+			"name":    "web-deploy",
+			"version": "v2",
+		}))
+		Expect(refs[1].(map[string]interface{})["templateRef"]).To(HaveKeyWithValue("git", HaveKeyWithValue("path", "notify.yaml")))
+
+		Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(blueprint.ObjectsAppliedCondition("v2")))
+		Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(blueprint.SupplyChainReadyCondition()))
+
+		Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+		_, updated := repo.StatusUpdateArgsForCall(0)
+		status := updated.(*v1alpha1.ClusterBlueprint).Status
+		Expect(status.Version).To(Equal("v2"))
+		Expect(status.ObservedGeneration).To(Equal(int64(1)))
+		Expect(status.Objects).To(Equal([]v1alpha1.ObjectReference{
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterTemplate", Name: "web-deploy-v2"},
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterSupplyChain", Name: "web"},
+		}))
+	})
+
+	It("pins the templates of options to the version", func() {
+		bp.Spec.SupplyChain.Resources[0].TemplateRef = v1alpha1.ClusterTemplateReference{
+			Kind: "ClusterTemplate",
+			Options: []v1alpha1.TemplateOption{
+				{
+					Name: "deploy",
+					Selector: v1alpha1.OptionSelector{
+						LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+					},
+				},
+			},
+		}
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		chain := appliedObjects()[1]
+		refs, _, _ := unstructured.NestedSlice(chain.Object, "spec", "resources")
+		templateRef := refs[0].(map[string]interface{})["templateRef"].(map[string]interface{})
+		Expect(templateRef).To(HaveKeyWithValue("version", "v2"))
+		Expect(templateRef).NotTo(HaveKey("name"))
+		Expect(templateRef["options"]).To(ConsistOf(HaveKeyWithValue("name", "web-deploy")))
+	})
+
+	It("deletes the templates of the earlier version once the supply chain is switched", func() {
+		bp.Status.Version = "v1"
+		bp.Status.Objects = []v1alpha1.ObjectReference{
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterTemplate", Name: "web-deploy-v1"},
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterSupplyChain", Name: "web"},
+		}
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(repo.DeleteUnstructuredCallCount()).To(Equal(1))
+		_, deleted := repo.DeleteUnstructuredArgsForCall(0)
+		Expect(deleted.GetKind()).To(Equal("ClusterTemplate"))
+		Expect(deleted.GetName()).To(Equal("web-deploy-v1"))
+	})
+
+	It("does not update the status when nothing changed", func() {
+		bp.Status.ObservedGeneration = 1
+		bp.Status.Version = "v2"
+		bp.Status.Objects = []v1alpha1.ObjectReference{
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterTemplate", Name: "web-deploy-v2"},
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterSupplyChain", Name: "web"},
+		}
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+	})
+
+	It("leaves the supply chain on the earlier version when a template fails to apply", func() {
+		bp.Status.Version = "v1"
+		repo.EnsureObjectExistsOnClusterReturns(errors.New("forbidden"))
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).To(MatchError("forbidden"))
+		Expect(repo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+		Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+		Expect(conditionManager.AddPositiveArgsForCall(0).Message).To(Equal("apply ClusterTemplate 'web-deploy-v2': forbidden"))
+
+		_, updated := repo.StatusUpdateArgsForCall(0)
+		Expect(updated.(*v1alpha1.ClusterBlueprint).Status.Version).To(Equal("v1"))
+	})
+
+	It("reports templates whose spec is not an object without retrying", func() {
+		bp.Spec.Templates[0].Spec = runtime.RawExtension{Raw: []byte(`"deploy"`)}
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conditionManager.AddPositiveArgsForCall(0).Reason).To(Equal(v1alpha1.InvalidTemplateObjectsAppliedReason))
+		Expect(repo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+	})
+
+	Context("when the supply chain has not been reconciled at the applied generation", func() {
+		BeforeEach(func() {
+			supplyChain.Status.ObservedGeneration = 2
+		})
+
+		It("waits for it", func() {
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(blueprint.SupplyChainPendingCondition()))
+		})
+	})
+
+	Context("when the supply chain is not ready", func() {
+		BeforeEach(func() {
+			supplyChain.Status.Conditions = []metav1.Condition{{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "TemplatesNotFound",
+				Message: "cannot find ClusterTemplate 'web-deploy@v2'",
+			}}
+		})
+
+		It("is not ready either", func() {
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			condition := conditionManager.AddPositiveArgsForCall(1)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Message).To(Equal("TemplatesNotFound: cannot find ClusterTemplate 'web-deploy@v2'"))
+		})
+	})
+
+	It("does nothing when the blueprint no longer exists", func() {
+		repo.GetBlueprintReturns(nil, nil)
+
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(repo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+	})
+})
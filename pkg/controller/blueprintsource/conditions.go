@@ -0,0 +1,68 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprintsource
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+func ArtifactPulledCondition(digest string) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintSourceArtifactPulled,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.PulledArtifactPulledReason,
+		Message: fmt.Sprintf("pulled %s", digest),
+	}
+}
+
+func PullFailedCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintSourceArtifactPulled,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.PullFailedArtifactPulledReason,
+		Message: err.Error(),
+	}
+}
+
+func ObjectsAppliedCondition() metav1.Condition {
+	return metav1.Condition{
+		Type:   v1alpha1.BlueprintSourceObjectsApplied,
+		Status: metav1.ConditionTrue,
+		Reason: v1alpha1.AppliedObjectsAppliedReason,
+	}
+}
+
+func InvalidArtifactCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintSourceObjectsApplied,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.InvalidObjectsAppliedReason,
+		Message: err.Error(),
+	}
+}
+
+func ApplyFailedCondition(obj *unstructured.Unstructured, err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintSourceObjectsApplied,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.ApplyFailedObjectsAppliedReason,
+		Message: fmt.Sprintf("apply %s '%s': %s", obj.GetKind(), obj.GetName(), err.Error()),
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprintsource
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+	"github.com/vmware-tanzu/cartographer/pkg/oci"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+// dockerConfigKey is the key of the credentials in a
+// kubernetes.io/dockerconfigjson Secret.
+const dockerConfigKey = ".dockerconfigjson"
+
+//counterfeiter:generate . Puller
+type Puller interface {
+	Pull(ctx context.Context, ref oci.Reference, options oci.PullOptions) (*oci.Artifact, error)
+}
+
+type Reconciler struct {
+	repo                    repository.Repository
+	puller                  Puller
+	conditionManager        conditions.ConditionManager
+	conditionManagerBuilder conditions.ConditionManagerBuilder
+}
+
+func NewReconciler(repo repository.Repository, puller Puller, conditionManagerBuilder conditions.ConditionManagerBuilder) *Reconciler {
+	return &Reconciler{
+		repo:                    repo,
+		puller:                  puller,
+		conditionManagerBuilder: conditionManagerBuilder,
+	}
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logr.FromContext(ctx).WithValues("name", req.Name)
+	logger.Info("started")
+
+	reconcileCtx := logr.NewContext(ctx, logger)
+
+	source, err := r.repo.GetBlueprintSource(ctx, req.Name)
+	if err != nil {
+		logger.Info("finished")
+		return ctrl.Result{}, fmt.Errorf("get blueprint source: %w", err)
+	}
+	if source == nil {
+		logger.Info("finished")
+		return ctrl.Result{}, nil
+	}
+	source = source.DeepCopy()
+	previous := source.Status.DeepCopy()
+
+	r.conditionManager = r.conditionManagerBuilder(v1alpha1.BlueprintSourceReady, source.Status.Conditions)
+
+	err = r.reconcileSource(reconcileCtx, source)
+
+	return r.completeReconciliation(reconcileCtx, source, previous, err)
+}
+
+func (r *Reconciler) completeReconciliation(ctx context.Context, source *v1alpha1.ClusterBlueprintSource, previous *v1alpha1.ClusterBlueprintSourceStatus, err error) (ctrl.Result, error) {
+	logger := logr.FromContext(ctx)
+
+	var changed bool
+	source.Status.Conditions, changed = r.conditionManager.Finalize()
+
+	unpacked := source.Status.Digest != previous.Digest || !equality.Semantic.DeepEqual(source.Status.Objects, previous.Objects)
+	if changed || unpacked || (source.Status.ObservedGeneration != source.Generation) {
+		source.Status.ObservedGeneration = source.Generation
+		if updateErr := r.repo.StatusUpdate(ctx, source); updateErr != nil {
+			logger.Error(updateErr, "update error")
+			if err == nil {
+				logger.Info("finished")
+				return ctrl.Result{}, fmt.Errorf("update blueprint source status: %w", updateErr)
+			}
+		}
+	}
+
+	logger.Info("finished")
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: source.Spec.GetInterval().Duration}, nil
+}
+
+// reconcileSource pulls the source's artifact, applies the objects in it and
+// deletes the objects unpacked from earlier artifacts that it no longer
+// holds. Errors that a retry cannot fix, such as an invalid reference or
+// artifact, are only reported in the source's conditions.
+func (r *Reconciler) reconcileSource(ctx context.Context, source *v1alpha1.ClusterBlueprintSource) error {
+	ref, err := oci.ParseReference(source.Spec.Image)
+	if err != nil {
+		r.conditionManager.AddPositive(PullFailedCondition(err))
+		return nil
+	}
+
+	options, err := r.pullOptions(ctx, source, ref)
+	if err != nil {
+		r.conditionManager.AddPositive(PullFailedCondition(err))
+		return err
+	}
+
+	artifact, err := r.puller.Pull(ctx, ref, options)
+	if err != nil {
+		r.conditionManager.AddPositive(PullFailedCondition(err))
+		return err
+	}
+	r.conditionManager.AddPositive(ArtifactPulledCondition(artifact.Digest))
+
+	objects, err := unpack(artifact.Documents)
+	if err != nil {
+		r.conditionManager.AddPositive(InvalidArtifactCondition(err))
+		return nil
+	}
+
+	var unpacked []v1alpha1.ObjectReference
+	for _, obj := range objects {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[v1alpha1.BlueprintSourceLabel] = source.Name
+		obj.SetLabels(labels)
+		obj.SetOwnerReferences([]metav1.OwnerReference{ownerReference(source)})
+
+		if err := r.repo.EnsureObjectExistsOnCluster(ctx, obj, true); err != nil {
+			r.conditionManager.AddPositive(ApplyFailedCondition(obj, err))
+			return err
+		}
+		unpacked = append(unpacked, v1alpha1.ObjectReference{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+		})
+	}
+
+	for _, ref := range source.Status.Objects {
+		if containsKindAndName(unpacked, ref) {
+			continue
+		}
+		stale := &unstructured.Unstructured{}
+		stale.SetAPIVersion(ref.APIVersion)
+		stale.SetKind(ref.Kind)
+		stale.SetName(ref.Name)
+		if err := r.repo.DeleteUnstructured(ctx, stale); err != nil {
+			r.conditionManager.AddPositive(ApplyFailedCondition(stale, err))
+			return err
+		}
+	}
+
+	source.Status.Digest = artifact.Digest
+	source.Status.Objects = unpacked
+	r.conditionManager.AddPositive(ObjectsAppliedCondition())
+	return nil
+}
+
+func (r *Reconciler) pullOptions(ctx context.Context, source *v1alpha1.ClusterBlueprintSource, ref oci.Reference) (oci.PullOptions, error) {
+	options := oci.PullOptions{Insecure: source.Spec.Insecure}
+	if source.Spec.SecretRef == nil {
+		return options, nil
+	}
+
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace(source.Spec.SecretRef.Namespace)
+	secret.SetName(source.Spec.SecretRef.Name)
+	if err := r.repo.GetUnstructured(ctx, secret); err != nil {
+		return options, fmt.Errorf("get secret '%s/%s': %w", secret.GetNamespace(), secret.GetName(), err)
+	}
+
+	encoded, _, _ := unstructured.NestedString(secret.Object, "data", dockerConfigKey)
+	config, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(config) == 0 {
+		return options, fmt.Errorf("secret '%s/%s' has no %s", secret.GetNamespace(), secret.GetName(), dockerConfigKey)
+	}
+
+	options.Credentials, err = oci.CredentialsFromDockerConfig(config, ref.Registry)
+	if err != nil {
+		return options, fmt.Errorf("secret '%s/%s': %w", secret.GetNamespace(), secret.GetName(), err)
+	}
+	return options, nil
+}
+
+func ownerReference(source *v1alpha1.ClusterBlueprintSource) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "ClusterBlueprintSource",
+		Name:       source.Name,
+		UID:        source.UID,
+		Controller: &controller,
+	}
+}
+
+// containsKindAndName reports whether refs hold the object ref refers to,
+// in any version.
+func containsKindAndName(refs []v1alpha1.ObjectReference, ref v1alpha1.ObjectReference) bool {
+	for _, r := range refs {
+		if r.Kind == ref.Kind && r.Name == ref.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprintsource_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+	"github.com/vmware-tanzu/cartographer/pkg/conditions/conditionsfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/controller/blueprintsource"
+	"github.com/vmware-tanzu/cartographer/pkg/controller/blueprintsource/blueprintsourcefakes"
+	"github.com/vmware-tanzu/cartographer/pkg/oci"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+const blueprintsYAML = `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: web
+spec:
+  selector:
+    workload-type: web
+  resources: []
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: deploy
+  labels:
+    team: platform
+spec:
+  template: {}
+`
+
+var _ = Describe("Reconciler", func() {
+	var (
+		reconciler       *blueprintsource.Reconciler
+		out              *Buffer
+		ctx              context.Context
+		req              ctrl.Request
+		conditionManager *conditionsfakes.FakeConditionManager
+		repo             *repositoryfakes.FakeRepository
+		puller           *blueprintsourcefakes.FakePuller
+		source           *v1alpha1.ClusterBlueprintSource
+	)
+
+	BeforeEach(func() {
+		out = NewBuffer()
+		logger := zap.New(zap.WriteTo(out))
+		ctx = logr.NewContext(context.Background(), logger)
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "blueprints"}}
+
+		conditionManager = &conditionsfakes.FakeConditionManager{}
+		conditionManagerBuilder := func(string, []metav1.Condition) conditions.ConditionManager {
+			return conditionManager
+		}
+
+		source = &v1alpha1.ClusterBlueprintSource{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints", UID: "some-uid", Generation: 1},
+			Spec:       v1alpha1.ClusterBlueprintSourceSpec{Image: "registry.example.com/blueprints:1.0.0"},
+		}
+		repo = &repositoryfakes.FakeRepository{}
+		repo.GetBlueprintSourceReturns(source, nil)
+
+		puller = &blueprintsourcefakes.FakePuller{}
+		puller.PullReturns(&oci.Artifact{Digest: "sha256:abc", Documents: [][]byte{[]byte(blueprintsYAML)}}, nil)
+
+		reconciler = blueprintsource.NewReconciler(repo, puller, conditionManagerBuilder)
+	})
+
+	appliedObjects := func() []*unstructured.Unstructured {
+		var objects []*unstructured.Unstructured
+		for i := 0; i < repo.EnsureObjectExistsOnClusterCallCount(); i++ {
+			_, obj, allowUpdate := repo.EnsureObjectExistsOnClusterArgsForCall(i)
+			Expect(allowUpdate).To(BeTrue())
+			objects = append(objects, obj)
+		}
+		return objects
+	}
+
+	It("pulls the artifact and applies its objects, owned by the source", func() {
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Minute}))
+		Expect(out).To(Say(`"msg":"started"`))
+		Expect(out).To(Say(`"name":"blueprints"`))
+
+		_, ref, options := puller.PullArgsForCall(0)
+		Expect(ref).To(Equal(oci.Reference{Registry: "registry.example.com", Repository: "blueprints", Tag: "1.0.0"}))
+		Expect(options).To(Equal(oci.PullOptions{}))
+
+		objects := appliedObjects()
+		Expect(objects).To(HaveLen(2))
+		Expect(objects[0].GetKind()).To(Equal("ClusterSupplyChain"))
+		Expect(objects[1].GetName()).To(Equal("deploy"))
+		Expect(objects[1].GetLabels()).To(Equal(map[string]string{
+			"team":                       "platform",
+			"carto.run/blueprint-source": "blueprints",
+		}))
+		Expect(objects[1].GetOwnerReferences()).To(HaveLen(1))
+		Expect(objects[1].GetOwnerReferences()[0].Kind).To(Equal("ClusterBlueprintSource"))
+		Expect(objects[1].GetOwnerReferences()[0].UID).To(Equal(types.UID("some-uid")))
+
+		Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(blueprintsource.ArtifactPulledCondition("sha256:abc")))
+		Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(blueprintsource.ObjectsAppliedCondition()))
+
+		Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+		_, updated := repo.StatusUpdateArgsForCall(0)
+		status := updated.(*v1alpha1.ClusterBlueprintSource).Status
+		Expect(status.Digest).To(Equal("sha256:abc"))
+		Expect(status.Objects).To(Equal([]v1alpha1.ObjectReference{
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterSupplyChain", Name: "web"},
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterTemplate", Name: "deploy"},
+		}))
+	})
+
+	It("polls at the source's interval", func() {
+		source.Spec.Interval = &metav1.Duration{Duration: time.Minute}
+
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+	})
+
+	It("deletes the objects unpacked earlier that the artifact no longer holds", func() {
+		source.Status.Objects = []v1alpha1.ObjectReference{
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterTemplate", Name: "deploy"},
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterTemplate", Name: "removed"},
+		}
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(repo.DeleteUnstructuredCallCount()).To(Equal(1))
+		_, deleted := repo.DeleteUnstructuredArgsForCall(0)
+		Expect(deleted.GetKind()).To(Equal("ClusterTemplate"))
+		Expect(deleted.GetName()).To(Equal("removed"))
+	})
+
+	It("does not update the status when nothing changed", func() {
+		source.Status.ObservedGeneration = 1
+		source.Status.Digest = "sha256:abc"
+		source.Status.Objects = []v1alpha1.ObjectReference{
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterSupplyChain", Name: "web"},
+			{APIVersion: "carto.run/v1alpha1", Kind: "ClusterTemplate", Name: "deploy"},
+		}
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+	})
+
+	It("pulls with the credentials of the source's secret", func() {
+		source.Spec.SecretRef = &v1alpha1.SecretReference{Namespace: "cartographer-system", Name: "registry-credentials"}
+		source.Spec.Insecure = true
+		repo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+			Expect(obj.GetKind()).To(Equal("Secret"))
+			Expect(obj.GetNamespace()).To(Equal("cartographer-system"))
+			Expect(obj.GetName()).To(Equal("registry-credentials"))
+			config := `{"auths": {"registry.example.com": {"username": "user", "password": "pass"}}}`
+			return unstructured.SetNestedField(obj.Object, base64.StdEncoding.EncodeToString([]byte(config)), "data", ".dockerconfigjson")
+		}
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, options := puller.PullArgsForCall(0)
+		Expect(options).To(Equal(oci.PullOptions{
+			Credentials: &oci.Credentials{Username: "user", Password: "pass"},
+			Insecure:    true,
+		}))
+	})
+
+	It("reports pull failures and retries", func() {
+		puller.PullReturns(nil, errors.New("registry unavailable"))
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).To(MatchError("registry unavailable"))
+		Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(blueprintsource.PullFailedCondition(errors.New("registry unavailable"))))
+		Expect(repo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+	})
+
+	It("reports invalid references without retrying", func() {
+		source.Spec.Image = "registry.example.com/Blueprints"
+
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(5 * time.Minute))
+		Expect(conditionManager.AddPositiveArgsForCall(0).Reason).To(Equal(v1alpha1.PullFailedArtifactPulledReason))
+		Expect(puller.PullCallCount()).To(Equal(0))
+	})
+
+	It("rejects artifacts holding objects other than cluster blueprints and templates", func() {
+		puller.PullReturns(&oci.Artifact{Digest: "sha256:abc", Documents: [][]byte{[]byte(blueprintsYAML + `
+---
+apiVersion: carto.run/v1alpha1
+kind: Workload
+metadata:
+  name: app
+  namespace: default
+`)}}, nil)
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(blueprintsource.InvalidArtifactCondition(
+			errors.New("Workload 'app' cannot be unpacked: only cluster blueprints and templates can"),
+		)))
+		Expect(repo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+	})
+
+	It("reports objects it fails to apply", func() {
+		repo.EnsureObjectExistsOnClusterReturns(errors.New("forbidden"))
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).To(MatchError("forbidden"))
+		Expect(conditionManager.AddPositiveArgsForCall(1).Message).To(Equal("apply ClusterSupplyChain 'web': forbidden"))
+	})
+
+	It("does nothing when the source no longer exists", func() {
+		repo.GetBlueprintSourceReturns(nil, nil)
+
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(puller.PullCallCount()).To(Equal(0))
+	})
+})
@@ -0,0 +1,94 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprintsource
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// unpackableKinds are the kinds of object an artifact may hold: cluster
+// scoped blueprints and templates.
+var unpackableKinds = map[string]bool{
+	"ClusterSupplyChain":        true,
+	"ClusterDelivery":           true,
+	"ClusterSourceTemplate":     true,
+	"ClusterImageTemplate":      true,
+	"ClusterConfigTemplate":     true,
+	"ClusterDeploymentTemplate": true,
+	"ClusterTemplate":           true,
+	"ClusterRunTemplate":        true,
+}
+
+// unpack decodes the objects in the YAML documents of an artifact. Every
+// object must be one of the unpackableKinds, without a namespace, and
+// appear once.
+func unpack(documents [][]byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	seen := map[string]bool{}
+
+	for _, document := range documents {
+		reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(document)))
+		for {
+			doc, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("read document: %w", err)
+			}
+			doc, err = yaml.ToJSON(doc)
+			if err != nil {
+				return nil, fmt.Errorf("parse document: %w", err)
+			}
+			// Documents holding only comments.
+			if bytes.Equal(bytes.TrimSpace(doc), []byte("null")) {
+				continue
+			}
+
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON(doc); err != nil {
+				return nil, fmt.Errorf("decode document: %w", err)
+			}
+
+			gvk := obj.GroupVersionKind()
+			if gvk.Group != v1alpha1.SchemeGroupVersion.Group || !unpackableKinds[gvk.Kind] {
+				return nil, fmt.Errorf("%s '%s' cannot be unpacked: only cluster blueprints and templates can", gvk.Kind, obj.GetName())
+			}
+			if obj.GetName() == "" {
+				return nil, fmt.Errorf("%s has no name", gvk.Kind)
+			}
+			if obj.GetNamespace() != "" {
+				return nil, fmt.Errorf("%s '%s' cannot have a namespace", gvk.Kind, obj.GetName())
+			}
+
+			key := gvk.Kind + "/" + obj.GetName()
+			if seen[key] {
+				return nil, fmt.Errorf("%s '%s' appears twice", gvk.Kind, obj.GetName())
+			}
+			seen[key] = true
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/redact"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+// StampedObjectChangedReason is the reason of the Events recorded on a
+// deliverable when an object stamped for it is updated.
+const StampedObjectChangedReason = "StampedObjectChanged"
+
+// changesInMessage is the number of changes an Event's message lists. Every
+// change is in its carto.run/changes annotation.
+const changesInMessage = 5
+
+//counterfeiter:generate . EventRecorder
+type EventRecorder interface {
+	AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{})
+}
+
+// AddEventRecording lets the reconciler record an Event on the deliverable
+// each time it updates a stamped object, describing what changed.
+func (r *Reconciler) AddEventRecording(recorder EventRecorder) {
+	r.eventRecorder = recorder
+}
+
+// changeReporter returns the reporter recording the changes made to the
+// objects stamped for deliverable, with sensitive values redacted, or nil
+// when Events are not recorded.
+func (r *Reconciler) changeReporter(deliverable *v1alpha1.Deliverable) repository.ChangeReporter {
+	if r.eventRecorder == nil {
+		return nil
+	}
+	return func(obj *unstructured.Unstructured, changes []repository.Change) {
+		changes = redactChanges(r.redactor, changes)
+		resourceName := obj.GetLabels()["carto.run/resource-name"]
+		annotations := map[string]string{"carto.run/resource-name": resourceName}
+		if encoded, err := json.Marshal(changes); err == nil {
+			annotations["carto.run/changes"] = string(encoded)
+		}
+		r.eventRecorder.AnnotatedEventf(deliverable, annotations, corev1.EventTypeNormal, StampedObjectChangedReason,
+			"resource '%s' %s", resourceName, repository.Summary(obj, changes, changesInMessage))
+	}
+}
+
+// redactChanges returns changes with the sensitive values in them redacted.
+func redactChanges(redactor *redact.Redactor, changes []repository.Change) []repository.Change {
+	redacted := make([]repository.Change, len(changes))
+	for i, change := range changes {
+		redacted[i] = repository.Change{
+			Path: change.Path,
+			From: redactor.Value(change.From),
+			To:   redactor.Value(change.To),
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"context"
+)
+
+//counterfeiter:generate . ClusterDataReader
+type ClusterDataReader interface {
+	Read(ctx context.Context) (map[string]interface{}, error)
+}
+
+// AddClusterData lets templates read the facts about the cluster the
+// reader returns, as $(cluster)$.
+func (r *Reconciler) AddClusterData(reader ClusterDataReader) {
+	r.clusterDataReader = reader
+}
+
+// readClusterData returns the facts about the cluster, or nil when no
+// reader was added.
+func (r *Reconciler) readClusterData(ctx context.Context) (map[string]interface{}, error) {
+	if r.clusterDataReader == nil {
+		return nil, nil
+	}
+	return r.clusterDataReader.Read(ctx)
+}
@@ -16,10 +16,12 @@ package deliverable
 
 import (
 	"fmt"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/rbac"
 )
 
 // -- Delivery conditions
@@ -70,11 +72,12 @@ func MissingReadyInDeliveryCondition(deliveryReadyCondition metav1.Condition) me
 
 // -- Resource conditions
 
-func ResourcesSubmittedCondition() metav1.Condition {
+func ResourcesSubmittedCondition(retries int64) metav1.Condition {
 	return metav1.Condition{
-		Type:   v1alpha1.DeliverableResourcesSubmitted,
-		Status: metav1.ConditionTrue,
-		Reason: v1alpha1.CompleteResourcesSubmittedReason,
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.CompleteResourcesSubmittedReason,
+		Message: retriesMessage(retries),
 	}
 }
 
@@ -87,6 +90,15 @@ func TemplateObjectRetrievalFailureCondition(err error) metav1.Condition {
 	}
 }
 
+func TemplateNotFoundCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.TemplateNotFoundResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
 func MissingValueAtPathCondition(resourceName, expression string) metav1.Condition {
 	return metav1.Condition{
 		Type:    v1alpha1.DeliverableResourcesSubmitted,
@@ -96,6 +108,15 @@ func MissingValueAtPathCondition(resourceName, expression string) metav1.Conditi
 	}
 }
 
+func InvalidOutputPathCondition(resourceName, expression string) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.InvalidOutputPathResourcesSubmittedReason,
+		Message: fmt.Sprintf("Resource '%s' has an invalid output path '%s'", resourceName, expression),
+	}
+}
+
 func TemplateStampFailureCondition(err error) metav1.Condition {
 	return metav1.Condition{
 		Type:    v1alpha1.DeliverableResourcesSubmitted,
@@ -114,6 +135,100 @@ func TemplateRejectedByAPIServerCondition(err error) metav1.Condition {
 	}
 }
 
+func TemplateApplyConflictCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.TemplateApplyConflictResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func PolicyViolationCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.PolicyViolationResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func StampedObjectRejectedCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.StampedObjectRejectedResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func KindNotRegisteredCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.KindNotRegisteredResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func JobRunningCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionUnknown,
+		Reason:  v1alpha1.JobRunningResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func JobFailedCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.JobFailedResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func ClusterDataUnavailableCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.ClusterDataUnavailableResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func PermissionsNotMetCondition(missing []rbac.MissingPermission) metav1.Condition {
+	var messages []string
+	for _, permission := range missing {
+		messages = append(messages, permission.String())
+	}
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.PermissionsNotMetResourcesSubmittedReason,
+		Message: strings.Join(messages, "; "),
+	}
+}
+
+func PluginRunningCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionUnknown,
+		Reason:  v1alpha1.PluginRunningResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func PluginFailedCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.PluginFailedResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
 func UnknownResourceErrorCondition(err error) metav1.Condition {
 	return metav1.Condition{
 		Type:    v1alpha1.DeliverableResourcesSubmitted,
@@ -122,3 +237,113 @@ func UnknownResourceErrorCondition(err error) metav1.Condition {
 		Message: err.Error(),
 	}
 }
+
+// retriesMessage tells how many retries the resources took to submit, when
+// they took any.
+func retriesMessage(retries int64) string {
+	switch retries {
+	case 0:
+		return ""
+	case 1:
+		return "submitted after 1 retry"
+	default:
+		return fmt.Sprintf("submitted after %d retries", retries)
+	}
+}
+
+func SourceResolutionFailedCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.SourceResolutionFailedResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func ParamResolutionFailedCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.ParamResolutionFailedResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func TargetUnavailableCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.TargetUnavailableResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+func PullRequestOpenCondition(pullRequest v1alpha1.DeliverablePullRequest) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionUnknown,
+		Reason:  v1alpha1.PullRequestOpenResourcesSubmittedReason,
+		Message: fmt.Sprintf("waiting for pull request %s to be merged", pullRequest.URL),
+	}
+}
+
+func PullRequestClosedCondition(pullRequest v1alpha1.DeliverablePullRequest) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.PullRequestClosedResourcesSubmittedReason,
+		Message: fmt.Sprintf("pull request %s was closed without being merged", pullRequest.URL),
+	}
+}
+
+func PullRequestFailedCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableResourcesSubmitted,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.PullRequestFailedResourcesSubmittedReason,
+		Message: err.Error(),
+	}
+}
+
+// -- Drift conditions
+
+func DriftedCondition(drifted []v1alpha1.DriftedObject) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableDrifted,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.StampedObjectsDriftedReason,
+		Message: driftedMessage(drifted),
+	}
+}
+
+// driftedMessage tells which fields of which objects drifted.
+func driftedMessage(drifted []v1alpha1.DriftedObject) string {
+	var messages []string
+	for _, d := range drifted {
+		messages = append(messages, fmt.Sprintf("resource '%s' %s %s/%s drifted at %s",
+			d.Resource, d.Object.Kind, d.Object.Namespace, d.Object.Name, strings.Join(d.Fields, ", ")))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// -- Field conflict conditions
+
+func FieldConflictCondition(conflicts []v1alpha1.FieldConflict) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliverableFieldConflict,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.FieldManagerConflictReason,
+		Message: fieldConflictsMessage(conflicts),
+	}
+}
+
+// fieldConflictsMessage tells which fields of which objects other field
+// managers set.
+func fieldConflictsMessage(conflicts []v1alpha1.FieldConflict) string {
+	var messages []string
+	for _, c := range conflicts {
+		messages = append(messages, fmt.Sprintf("resource '%s' %s %s/%s: %s set by field manager '%s'",
+			c.Resource, c.Object.Kind, c.Object.Namespace, c.Object.Name, strings.Join(c.Fields, ", "), c.Manager))
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+// conflictReporter returns the reporter listing, in the deliverable's status,
+// the fields of its stamped objects that other field managers had set.
+func conflictReporter(deliverable *v1alpha1.Deliverable) repository.ConflictReporter {
+	return func(obj *unstructured.Unstructured, conflicts []repository.FieldConflict) {
+		ref := v1alpha1.ObjectReference{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		}
+		for _, conflict := range conflicts {
+			deliverable.Status.FieldConflicts = append(deliverable.Status.FieldConflicts, v1alpha1.FieldConflict{
+				Resource: obj.GetLabels()["carto.run/resource-name"],
+				Object:   ref,
+				Manager:  conflict.Manager,
+				Fields:   conflict.Fields,
+			})
+		}
+	}
+}
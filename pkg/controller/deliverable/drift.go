@@ -0,0 +1,72 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//counterfeiter:generate . DynamicTracker
+type DynamicTracker interface {
+	Watch(log logr.Logger, obj runtime.Object, handler handler.EventHandler) error
+}
+
+// AddTracking lets the reconciler watch the objects it stamps, so that
+// changes made to them are remediated or detected as they happen.
+func (r *Reconciler) AddTracking(dynamicTracker DynamicTracker) {
+	r.dynamicTracker = dynamicTracker
+}
+
+// trackStampedObjects watches the kinds of the objects stamped for the
+// deliverable on this cluster, whether or not they have output yet, so that
+// their status changing, or something else changing them, reconciles the
+// deliverable right away. Objects on target clusters are not watched.
+func (r *Reconciler) trackStampedObjects(targets []targetRealizer) {
+	if r.dynamicTracker == nil {
+		return
+	}
+
+	for _, target := range targets {
+		if target.cluster != "" || target.realizer == nil {
+			continue
+		}
+		for _, ref := range target.realizer.StampedObjects() {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion(ref.APIVersion)
+			obj.SetKind(ref.Kind)
+			err := r.dynamicTracker.Watch(r.logger, obj, handler.EnqueueRequestsFromMapFunc(deliverableRequestsForStampedObject))
+			if err != nil {
+				r.logger.Error(err, "dynamic tracker watch")
+			}
+		}
+	}
+}
+
+// deliverableRequestsForStampedObject maps an object to the deliverable it
+// was stamped for, by the labels every stamped object carries.
+func deliverableRequestsForStampedObject(obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	name, namespace := labels["carto.run/deliverable-name"], labels["carto.run/deliverable-namespace"]
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/history"
+)
+
+// recordHistory adds the realization of the deliverable with delivery, which
+// submitted resources, to the deliverable's history when it realized something
+// new.
+func (r *Reconciler) recordHistory(deliverable *v1alpha1.Deliverable, delivery v1alpha1.DeliveryObject, resources []v1alpha1.RealizedResource) {
+	deliverable.Status.History, r.historyChanged = history.Record(deliverable.Status.History, v1alpha1.RealizationRecord{
+		Time:                metav1.Now(),
+		Generation:          deliverable.Generation,
+		Manager:             history.Manager(deliverable),
+		ParamsDigest:        history.ParamsDigest(deliverable.Spec.Params),
+		Blueprint:           delivery.GetName(),
+		BlueprintGeneration: delivery.GetGeneration(),
+		Resources:           resources,
+	})
+}
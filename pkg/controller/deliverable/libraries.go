@@ -0,0 +1,59 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+type LibraryTracker interface {
+	Track(owner types.NamespacedName, libraries []string)
+}
+
+// AddLibraryTracking lets the reconciler remember the ClusterTemplateLibraries
+// included by the templates stamped for each deliverable, so that changes to
+// them reconcile the deliverable.
+func (r *Reconciler) AddLibraryTracking(tracker LibraryTracker) {
+	r.libraryTracker = tracker
+}
+
+// trackLibraries remembers the libraries included on every target, local or
+// remote, as templates are always read from this cluster.
+func (r *Reconciler) trackLibraries(deliverable *v1alpha1.Deliverable, targets []targetRealizer) {
+	if r.libraryTracker == nil {
+		return
+	}
+
+	included := map[string]bool{}
+	for _, target := range targets {
+		if target.realizer == nil {
+			continue
+		}
+		for _, library := range target.realizer.Libraries() {
+			included[library] = true
+		}
+	}
+	var libraries []string
+	for library := range included {
+		libraries = append(libraries, library)
+	}
+	sort.Strings(libraries)
+
+	r.libraryTracker.Track(types.NamespacedName{Namespace: deliverable.Namespace, Name: deliverable.Name}, libraries)
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/notification"
+)
+
+//counterfeiter:generate . Notifier
+type Notifier interface {
+	Notify(ctx context.Context, event notification.Event)
+}
+
+// AddNotifications lets the reconciler tell the notification sinks about
+// the deliverables that stop being ready.
+func (r *Reconciler) AddNotifications(notifier Notifier) {
+	r.notifier = notifier
+}
+
+// readyCondition returns a copy of the Ready condition in conditions.
+func readyCondition(conditions []metav1.Condition) *metav1.Condition {
+	ready := meta.FindStatusCondition(conditions, v1alpha1.DeliverableReady)
+	if ready == nil {
+		return nil
+	}
+	copied := *ready
+	return &copied
+}
+
+// notify tells the notifier about the deliverable when its Ready condition,
+// previously previous, became False or changed reason while False.
+func (r *Reconciler) notify(ctx context.Context, deliverable *v1alpha1.Deliverable, previous *metav1.Condition) {
+	if r.notifier == nil {
+		return
+	}
+	current := readyCondition(deliverable.Status.Conditions)
+	if !notification.BecameUnready(previous, current) {
+		return
+	}
+	r.notifier.Notify(ctx, notification.Event{
+		Kind:      "Deliverable",
+		Namespace: deliverable.Namespace,
+		Name:      deliverable.Name,
+		Labels:    deliverable.Labels,
+		Blueprint: deliverable.Status.DeliveryRef.Name,
+		Reason:    current.Reason,
+		Message:   current.Message,
+	})
+}
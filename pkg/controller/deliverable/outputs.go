@@ -0,0 +1,59 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// mergeOutputs keeps, after a failed realization, the previously published
+// outputs that this reconcile did not publish again, as the resources that
+// published them may not have been reached. After a successful realization
+// only the outputs published by this reconcile remain. Sensitive values are
+// redacted.
+func (r *Reconciler) mergeOutputs(deliverable *v1alpha1.Deliverable, previous []v1alpha1.DeliverableOutput, realizeErr error) {
+	for i, output := range deliverable.Status.Outputs {
+		var value interface{}
+		if err := json.Unmarshal(output.Value.Raw, &value); err != nil {
+			continue
+		}
+		if raw, err := json.Marshal(r.redactor.Value(value)); err == nil {
+			deliverable.Status.Outputs[i].Value.Raw = raw
+		}
+	}
+
+	if realizeErr != nil {
+		for _, output := range previous {
+			if !hasOutput(deliverable.Status.Outputs, output.Name) {
+				deliverable.Status.Outputs = append(deliverable.Status.Outputs, output)
+			}
+		}
+	}
+
+	r.outputsChanged = !equality.Semantic.DeepEqual(previous, deliverable.Status.Outputs)
+}
+
+func hasOutput(outputs []v1alpha1.DeliverableOutput, name string) bool {
+	for _, output := range outputs {
+		if output.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+)
+
+// AddRealizerPlugins lets the reconciler realize the resources whose
+// templates name a plugin through realizer, rather than leaving them waiting
+// for a plugin that is not registered.
+func (r *Reconciler) AddRealizerPlugins(realizer plugin.Realizer) {
+	r.plugins = realizer
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate"
+	"github.com/vmware-tanzu/cartographer/pkg/predelete"
+)
+
+// ensurePreDeleteFinalizer adds the pre-delete finalizer to a deliverable
+// whose delivery has pre-delete hooks, so that they get to run when it is
+// deleted.
+func (r *Reconciler) ensurePreDeleteFinalizer(ctx context.Context, deliverable *v1alpha1.Deliverable, delivery v1alpha1.DeliveryObject) error {
+	if len(delivery.GetSpec().PreDelete) == 0 ||
+		controllerutil.ContainsFinalizer(deliverable, v1alpha1.PreDeleteFinalizer) {
+		return nil
+	}
+
+	controllerutil.AddFinalizer(deliverable, v1alpha1.PreDeleteFinalizer)
+	if err := r.repo.Update(ctx, deliverable); err != nil {
+		return fmt.Errorf("add finalizer: %w", err)
+	}
+	return nil
+}
+
+// finalize runs the pre-delete hooks of a deleted deliverable, then lets its
+// deletion proceed.
+func (r *Reconciler) finalize(ctx context.Context, deliverable *v1alpha1.Deliverable) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(deliverable, v1alpha1.PreDeleteFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	complete, err := r.runPreDeleteHooks(ctx, deliverable)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !complete {
+		return ctrl.Result{RequeueAfter: r.resyncIntervalOf(deliverable)}, nil
+	}
+
+	controllerutil.RemoveFinalizer(deliverable, v1alpha1.PreDeleteFinalizer)
+	if err := r.repo.Update(ctx, deliverable); err != nil {
+		return ctrl.Result{}, fmt.Errorf("remove finalizer: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// runPreDeleteHooks runs the pre-delete hooks of the delivery the deleted
+// deliverable was last realized with. It returns whether they are complete,
+// reporting their progress in the PreDeleteHooksCompleted condition until
+// they are. A failed hook holds the deletion back until its Job is deleted,
+// which runs it again.
+func (r *Reconciler) runPreDeleteHooks(ctx context.Context, deliverable *v1alpha1.Deliverable) (bool, error) {
+	delivery, err := r.lastDeliveryOf(ctx, deliverable)
+	if err != nil {
+		return false, err
+	}
+	if delivery == nil {
+		return true, nil
+	}
+	ctx = gittemplate.WithBlueprint(ctx, delivery)
+
+	progress, err := predelete.Run(ctx, r.repo, predelete.Owner{
+		Object:         deliverable,
+		Kind:           "Deliverable",
+		Templating:     deliverable,
+		BlueprintLabel: "carto.run/cluster-delivery-name",
+		Blueprint:      delivery.GetName(),
+	}, delivery.GetSpec().PreDelete)
+	r.trackPreDeleteJobs(progress.Stamped)
+
+	var failedErr predelete.FailedError
+	switch {
+	case errors.As(err, &failedErr):
+		return false, r.setPreDeleteCondition(ctx, deliverable, predelete.FailedCondition(failedErr))
+	case err != nil:
+		return false, err
+	case !progress.Complete():
+		return false, r.setPreDeleteCondition(ctx, deliverable, predelete.RunningCondition(progress))
+	}
+	return true, nil
+}
+
+// lastDeliveryOf returns the delivery recorded in the deliverable's status,
+// or nil when there is none, or it no longer exists.
+func (r *Reconciler) lastDeliveryOf(ctx context.Context, deliverable *v1alpha1.Deliverable) (v1alpha1.DeliveryObject, error) {
+	ref := deliverable.Status.DeliveryRef
+	if ref.Name == "" {
+		return nil, nil
+	}
+
+	if ref.Kind == "Delivery" {
+		delivery, err := r.repo.GetNamespacedDelivery(ctx, ref.Name, deliverable.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("get delivery: %w", err)
+		}
+		if delivery == nil {
+			return nil, nil
+		}
+		return delivery, nil
+	}
+
+	delivery, err := r.repo.GetDelivery(ctx, ref.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get delivery: %w", err)
+	}
+	if delivery == nil {
+		return nil, nil
+	}
+	return delivery, nil
+}
+
+// trackPreDeleteJobs watches the Jobs of the pre-delete hooks, so that their
+// completing reconciles the deliverable right away.
+func (r *Reconciler) trackPreDeleteJobs(stamped []v1alpha1.ObjectReference) {
+	if r.dynamicTracker == nil {
+		return
+	}
+
+	for _, ref := range stamped {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(ref.APIVersion)
+		obj.SetKind(ref.Kind)
+		err := r.dynamicTracker.Watch(r.logger, obj, handler.EnqueueRequestsFromMapFunc(deliverableRequestsForStampedObject))
+		if err != nil {
+			r.logger.Error(err, "dynamic tracker watch")
+		}
+	}
+}
+
+// setPreDeleteCondition records condition in the deliverable's status,
+// unless it is already there.
+func (r *Reconciler) setPreDeleteCondition(ctx context.Context, deliverable *v1alpha1.Deliverable, condition metav1.Condition) error {
+	previous := meta.FindStatusCondition(deliverable.Status.Conditions, condition.Type)
+	if previous != nil && previous.Status == condition.Status && previous.Reason == condition.Reason && previous.Message == condition.Message {
+		return nil
+	}
+
+	meta.SetStatusCondition(&deliverable.Status.Conditions, condition)
+	if err := r.repo.StatusUpdate(ctx, deliverable); err != nil {
+		return fmt.Errorf("update deliverable status: %w", err)
+	}
+	return nil
+}
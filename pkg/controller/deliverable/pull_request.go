@@ -0,0 +1,160 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/gitops"
+)
+
+//counterfeiter:generate . Promoter
+type Promoter interface {
+	Promote(ctx context.Context, req gitops.Request) (v1alpha1.DeliverablePullRequest, error)
+}
+
+// defaultTokenKey is the key of the token in the Secret a delivery's pull
+// request refers to, when it names none.
+const defaultTokenKey = "token"
+
+// AddPullRequests lets the reconciler propose the objects stamped for
+// resources submitted as a pull request to their delivery's GitOps
+// repository, with promoter.
+func (r *Reconciler) AddPullRequests(promoter Promoter) {
+	r.promoter = promoter
+}
+
+// pullRequestManifests returns the manifests to collect the objects stamped
+// for resources submitted as a pull request in, or nil when the delivery
+// proposes none.
+func (r *Reconciler) pullRequestManifests(delivery v1alpha1.DeliveryObject) *gitops.Manifests {
+	if delivery.GetSpec().PullRequest == nil {
+		return nil
+	}
+	return gitops.NewManifests(r.repo)
+}
+
+// proposeManifests opens, or updates, the pull request proposing manifests
+// to the delivery's repository, recording it in the deliverable's status.
+// It returns the ResourcesSubmitted condition the pull request's state
+// leads to: submitted only once merged.
+func (r *Reconciler) proposeManifests(ctx context.Context, deliverable *v1alpha1.Deliverable, delivery v1alpha1.DeliveryObject, manifests *gitops.Manifests) (metav1.Condition, error) {
+	previous := deliverable.Status.PullRequest
+	defer func() {
+		r.pullRequestChanged = !equality.Semantic.DeepEqual(previous, deliverable.Status.PullRequest)
+	}()
+
+	if manifests.Len() == 0 {
+		deliverable.Status.PullRequest = nil
+		return ResourcesSubmittedCondition(v1alpha1.TotalRetries(deliverable.Status.Retries)), nil
+	}
+	if r.promoter == nil {
+		err := fmt.Errorf("pull requests are not enabled")
+		return PullRequestFailedCondition(err), err
+	}
+
+	req, err := r.pullRequest(ctx, deliverable, delivery, manifests)
+	if err != nil {
+		return PullRequestFailedCondition(err), err
+	}
+	pullRequest, err := r.promoter.Promote(ctx, req)
+	if err != nil {
+		err = fmt.Errorf("pull request: %w", err)
+		return PullRequestFailedCondition(err), err
+	}
+	deliverable.Status.PullRequest = &pullRequest
+
+	switch pullRequest.State {
+	case v1alpha1.OpenPullRequestState:
+		return PullRequestOpenCondition(pullRequest), nil
+	case v1alpha1.ClosedPullRequestState:
+		return PullRequestClosedCondition(pullRequest), nil
+	default:
+		return ResourcesSubmittedCondition(v1alpha1.TotalRetries(deliverable.Status.Retries)), nil
+	}
+}
+
+// pullRequest returns the request proposing manifests to the delivery's
+// repository: in a file and on a branch of the deliverable's own.
+func (r *Reconciler) pullRequest(ctx context.Context, deliverable *v1alpha1.Deliverable, delivery v1alpha1.DeliveryObject, manifests *gitops.Manifests) (gitops.Request, error) {
+	spec := delivery.GetSpec().PullRequest
+
+	token, err := r.readToken(ctx, delivery, spec.TokenSecretRef)
+	if err != nil {
+		return gitops.Request{}, err
+	}
+	content, err := manifests.YAML()
+	if err != nil {
+		return gitops.Request{}, err
+	}
+
+	base := spec.Branch
+	if base == "" {
+		base = "main"
+	}
+	name := deliverable.Namespace + "/" + deliverable.Name
+	return gitops.Request{
+		Provider:   spec.Provider,
+		APIURL:     spec.APIURL,
+		Repository: spec.Repository,
+		Base:       base,
+		Branch:     "cartographer/" + name,
+		Path:       path.Join(spec.Path, deliverable.Namespace, deliverable.Name+".yaml"),
+		Content:    content,
+		Title:      fmt.Sprintf("Deliver %s", name),
+		Body:       fmt.Sprintf("The objects delivery '%s' stamped for deliverable '%s'.", delivery.GetName(), name),
+		Token:      token,
+	}, nil
+}
+
+// readToken returns the token held by the Secret ref refers to.
+func (r *Reconciler) readToken(ctx context.Context, delivery v1alpha1.DeliveryObject, ref v1alpha1.TokenSecretReference) (string, error) {
+	namespace := ref.Namespace
+	// a Delivery cannot reach secrets outside its namespace.
+	if delivery.GetNamespace() != "" {
+		namespace = delivery.GetNamespace()
+	}
+	if namespace == "" {
+		return "", fmt.Errorf("pull request token secret '%s' has no namespace", ref.Name)
+	}
+	key := ref.Key
+	if key == "" {
+		key = defaultTokenKey
+	}
+
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace(namespace)
+	secret.SetName(ref.Name)
+	if err := r.repo.GetUnstructured(ctx, secret); err != nil {
+		return "", fmt.Errorf("get secret '%s/%s': %w", namespace, ref.Name, err)
+	}
+
+	encoded, _, _ := unstructured.NestedString(secret.Object, "data", key)
+	token, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(token) == 0 {
+		return "", fmt.Errorf("secret '%s/%s' has no %s", namespace, ref.Name, key)
+	}
+	return string(token), nil
+}
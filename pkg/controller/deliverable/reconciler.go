@@ -20,104 +20,327 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/backoff"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+	"github.com/vmware-tanzu/cartographer/pkg/gitops"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate"
+	"github.com/vmware-tanzu/cartographer/pkg/metrics"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
 	realizer "github.com/vmware-tanzu/cartographer/pkg/realizer/deliverable"
+	"github.com/vmware-tanzu/cartographer/pkg/redact"
 	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/scope"
+	"github.com/vmware-tanzu/cartographer/pkg/shutdown"
+	"github.com/vmware-tanzu/cartographer/pkg/tracing"
 	"github.com/vmware-tanzu/cartographer/pkg/utils"
 )
 
-const reconcileInterval = 5 * time.Second
+// DefaultResyncInterval is how often a ready deliverable is reconciled again,
+// unless SetResyncInterval or its carto.run/resync-interval annotation say
+// otherwise.
+const DefaultResyncInterval = 5 * time.Second
 
 type Reconciler struct {
 	repo                    repository.Repository
+	serviceAccountRepo      repository.ServiceAccountRepository
 	conditionManager        conditions.ConditionManager
 	conditionManagerBuilder conditions.ConditionManagerBuilder
+	redactor                *redact.Redactor
 	realizer                realizer.Realizer
+	sourceResolver          SourceResolver
+	paramResolver           ParamResolver
+	libraryTracker          LibraryTracker
+	notifier                Notifier
+	eventRecorder           EventRecorder
+	plugins                 plugin.Realizer
+	clusterDataReader       ClusterDataReader
+	restMapper              meta.RESTMapper
+	permissionReviewer      PermissionReviewer
+	cacheEvicter            CacheEvicter
+	templateBackoff         *backoff.Backoff
+	resyncInterval          time.Duration
+	dynamicTracker          DynamicTracker
+	targetRepository        repository.TargetRepository
+	promoter                Promoter
 	logger                  logr.Logger
+	outputsChanged          bool
+	lastOutputsChanged      bool
+	retriesChanged          bool
+	historyChanged          bool
+	driftedChanged          bool
+	fieldConflictsChanged   bool
+	missingOutputsChanged   bool
+	sourceChanged           bool
+	templatesMissing        bool
+	targetsChanged          bool
+	pullRequestChanged      bool
+	settled                 bool
 }
 
-func NewReconciler(repo repository.Repository, conditionManagerBuilder conditions.ConditionManagerBuilder, realizer realizer.Realizer) *Reconciler {
+func NewReconciler(repo repository.Repository, serviceAccountRepo repository.ServiceAccountRepository, conditionManagerBuilder conditions.ConditionManagerBuilder, realizer realizer.Realizer) *Reconciler {
 	return &Reconciler{
 		repo:                    repo,
+		serviceAccountRepo:      serviceAccountRepo,
 		conditionManagerBuilder: conditionManagerBuilder,
 		realizer:                realizer,
+		templateBackoff:         backoff.New(backoff.DefaultBaseDelay, backoff.DefaultMaxDelay),
+		resyncInterval:          DefaultResyncInterval,
 	}
 }
 
-func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+// SetResyncInterval sets how often deliverables that are ready are reconciled
+// again, for those without a carto.run/resync-interval annotation.
+func (r *Reconciler) SetResyncInterval(interval time.Duration) {
+	r.resyncInterval = interval
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "deliverable.reconcile",
+		attribute.String("deliverable.name", req.Name),
+		attribute.String("deliverable.namespace", req.Namespace),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	r.logger = logr.FromContext(ctx).
 		WithValues("name", req.Name, "namespace", req.Namespace)
 	r.logger.Info("started")
 	defer r.logger.Info("finished")
 
-	deliverable, err := r.repo.GetDeliverable(req.Name, req.Namespace)
+	deliverable, err := r.repo.GetDeliverable(ctx, req.Name, req.Namespace)
 	if err != nil || deliverable == nil {
 		if kerrors.IsNotFound(err) {
+			r.evictStamped(req.Name, req.Namespace)
+			metrics.ForgetDeliverable(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 
 		return ctrl.Result{}, fmt.Errorf("get deliverable: %w", err)
 	}
 
-	r.conditionManager = r.conditionManagerBuilder(v1alpha1.DeliverableReady, deliverable.Status.Conditions)
+	if !deliverable.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, deliverable)
+	}
+
+	r.redactor = redact.New()
+	r.conditionManager = r.redactor.ConditionManager(r.conditionManagerBuilder(v1alpha1.DeliverableReady, deliverable.Status.Conditions))
+	r.outputsChanged = false
+	r.lastOutputsChanged = false
+	r.retriesChanged = false
+	r.historyChanged = false
+	r.driftedChanged = false
+	r.fieldConflictsChanged = false
+	r.missingOutputsChanged = false
+	r.sourceChanged = false
+	r.templatesMissing = false
+	r.targetsChanged = false
+	r.pullRequestChanged = false
+	r.settled = deliverable.Status.ObservedGeneration == deliverable.Generation &&
+		meta.IsStatusConditionTrue(deliverable.Status.Conditions, v1alpha1.DeliverableReady)
 
-	delivery, err := r.getDeliveriesForDeliverable(deliverable)
+	delivery, err := r.getDeliveriesForDeliverable(ctx, deliverable)
 	if err != nil {
-		return r.completeReconciliation(deliverable, err)
+		return r.completeReconciliation(ctx, deliverable, err)
+	}
+	ctx = gittemplate.WithBlueprint(ctx, delivery)
+	ctx = policy.WithBlueprint(ctx, deliverable, delivery)
+
+	if err := r.ensurePreDeleteFinalizer(ctx, deliverable, delivery); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	deliveryGVK, err := utils.GetObjectGVK(delivery, r.repo.GetScheme())
 	if err != nil {
-		return r.completeReconciliation(deliverable, fmt.Errorf("get object gvk: %w", err))
+		return r.completeReconciliation(ctx, deliverable, fmt.Errorf("get object gvk: %w", err))
 	}
 
 	deliverable.Status.DeliveryRef.Kind = deliveryGVK.Kind
-	deliverable.Status.DeliveryRef.Name = delivery.Name
+	deliverable.Status.DeliveryRef.Name = delivery.GetName()
 
 	err = r.checkDeliveryReadiness(delivery)
 	if err != nil {
 		r.conditionManager.AddPositive(MissingReadyInDeliveryCondition(getDeliveryReadyCondition(delivery)))
-		return r.completeReconciliation(deliverable, err)
+		return r.completeReconciliation(ctx, deliverable, err)
 	}
 	r.conditionManager.AddPositive(DeliveryReadyCondition())
 
-	err = r.realizer.Realize(ctx, realizer.NewResourceRealizer(deliverable, r.repo), delivery)
+	if err := r.resolveSource(ctx, deliverable); err != nil {
+		r.conditionManager.AddPositive(SourceResolutionFailedCondition(err))
+		return r.completeReconciliation(ctx, deliverable, err)
+	}
+
+	if err := r.resolveParams(ctx, deliverable); err != nil {
+		r.conditionManager.AddPositive(ParamResolutionFailedCondition(err))
+		return r.completeReconciliation(ctx, deliverable, err)
+	}
+
+	clusterData, err := r.readClusterData(ctx)
+	if err != nil {
+		r.conditionManager.AddPositive(ClusterDataUnavailableCondition(err))
+		return r.completeReconciliation(ctx, deliverable, err)
+	}
+
+	missingPermissions, err := r.reviewPermissions(ctx, deliverable, delivery)
+	if err != nil {
+		r.conditionManager.AddPositive(UnknownResourceErrorCondition(err))
+		return r.completeReconciliation(ctx, deliverable, fmt.Errorf("review permissions: %w", err))
+	}
+	if len(missingPermissions) > 0 {
+		r.conditionManager.AddPositive(PermissionsNotMetCondition(missingPermissions))
+		return r.completeReconciliation(ctx, deliverable, fmt.Errorf("permissions missing to realize delivery '%s'", delivery.GetName()))
+	}
+
+	targets, err := r.resourceRealizers(ctx, deliverable, delivery)
+	if err != nil {
+		r.targetsChanged = len(deliverable.Status.Targets) > 0
+		deliverable.Status.Targets = nil
+		r.conditionManager.AddPositive(TargetUnavailableCondition(err))
+		return r.completeReconciliation(ctx, deliverable, err)
+	}
+
+	previousOutputs := deliverable.Status.Outputs
+	previousLastOutputs := deliverable.Status.LastOutputs
+	previousDrifted := deliverable.Status.Drifted
+	previousFieldConflicts := deliverable.Status.FieldConflicts
+	previousMissingOutputs := deliverable.Status.MissingOptionalOutputs
+	deliverable.Status.Outputs = nil
+	deliverable.Status.Drifted = nil
+	deliverable.Status.FieldConflicts = nil
+	deliverable.Status.MissingOptionalOutputs = nil
+	previousRetries := deliverable.Status.Retries
+	if r.settled || deliverable.Status.ObservedGeneration != deliverable.Generation {
+		deliverable.Status.Retries = nil
+	}
+	realizationRetries := deliverable.Status.Retries
+	realizeCtx := repository.WithChangeReporter(ctx, r.changeReporter(deliverable))
+	realizeCtx = repository.WithConflictReporter(realizeCtx, conflictReporter(deliverable))
+	realizeCtx = redact.NewContext(realizeCtx, r.redactor)
+	if r.plugins != nil {
+		realizeCtx = plugin.NewContext(realizeCtx, r.plugins)
+	}
+	if clusterData != nil {
+		realizeCtx = clusterdata.NewContext(realizeCtx, clusterData)
+	}
+	if r.restMapper != nil {
+		realizeCtx = scope.NewContext(realizeCtx, r.restMapper)
+	}
+	manifests := r.pullRequestManifests(delivery)
+	if manifests != nil {
+		realizeCtx = gitops.NewContext(realizeCtx, manifests)
+	}
+	failedCluster, err := r.realizeTargets(realizeCtx, deliverable, delivery, targets)
+	r.mergeOutputs(deliverable, previousOutputs, err)
+	r.lastOutputsChanged = !equality.Semantic.DeepEqual(previousLastOutputs, deliverable.Status.LastOutputs)
+	metrics.RecordRetries("Deliverable", delivery.GetName(), realizationRetries, deliverable.Status.Retries)
+	if r.settled && len(deliverable.Status.Retries) == 0 {
+		// nothing failed, so the deliverable is still settled: keep the
+		// retries of the realization that made it ready.
+		deliverable.Status.Retries = previousRetries
+	}
+	r.retriesChanged = !equality.Semantic.DeepEqual(previousRetries, deliverable.Status.Retries)
+	r.trackStampedObjects(targets)
+	r.trackLibraries(deliverable, targets)
+	r.driftedChanged = !equality.Semantic.DeepEqual(previousDrifted, deliverable.Status.Drifted)
+	if len(deliverable.Status.Drifted) > 0 {
+		r.conditionManager.AddNegative(DriftedCondition(deliverable.Status.Drifted))
+	}
+	r.fieldConflictsChanged = !equality.Semantic.DeepEqual(previousFieldConflicts, deliverable.Status.FieldConflicts)
+	if len(deliverable.Status.FieldConflicts) > 0 {
+		r.conditionManager.AddNegative(FieldConflictCondition(deliverable.Status.FieldConflicts))
+	}
+	r.missingOutputsChanged = !equality.Semantic.DeepEqual(previousMissingOutputs, deliverable.Status.MissingOptionalOutputs)
 	if err != nil {
-		switch typedErr := err.(type) {
-		case realizer.GetDeliveryClusterTemplateError:
-			r.conditionManager.AddPositive(TemplateObjectRetrievalFailureCondition(typedErr))
-		case realizer.StampError:
-			r.conditionManager.AddPositive(TemplateStampFailureCondition(typedErr))
-		case realizer.ApplyStampedObjectError:
-			r.conditionManager.AddPositive(TemplateRejectedByAPIServerCondition(typedErr))
-		case realizer.RetrieveOutputError:
-			r.conditionManager.AddPositive(MissingValueAtPathCondition(typedErr.ResourceName(), typedErr.JsonPathExpression()))
-			err = nil
-		default:
-			r.conditionManager.AddPositive(UnknownResourceErrorCondition(typedErr))
+		condition, retryErr := resourcesSubmittedCondition(err)
+		if failedCluster != "" {
+			condition.Message = fmt.Sprintf("cluster '%s': %s", failedCluster, condition.Message)
+			if retryErr != nil {
+				retryErr = fmt.Errorf("cluster '%s': %w", failedCluster, retryErr)
+			}
 		}
+		r.conditionManager.AddPositive(condition)
+		_, r.templatesMissing = err.(realizer.TemplateNotFoundError)
+		return r.completeReconciliation(ctx, deliverable, retryErr)
+	}
 
-		return r.completeReconciliation(deliverable, err)
+	submitted := ResourcesSubmittedCondition(v1alpha1.TotalRetries(deliverable.Status.Retries))
+	var proposeErr error
+	if manifests != nil {
+		submitted, proposeErr = r.proposeManifests(ctx, deliverable, delivery, manifests)
+	}
+	r.conditionManager.AddPositive(submitted)
+	if proposeErr != nil {
+		return r.completeReconciliation(ctx, deliverable, proposeErr)
 	}
+	// every target stamps the same objects, so the first tells them all.
+	r.recordHistory(deliverable, delivery, targets[0].realizer.RealizedResources())
 
-	r.conditionManager.AddPositive(ResourcesSubmittedCondition())
+	return r.completeReconciliation(ctx, deliverable, nil)
+}
 
-	return r.completeReconciliation(deliverable, nil)
+// resourcesSubmittedCondition is the ResourcesSubmitted condition a failed
+// realization leads to, along with the error to requeue with: nil when the
+// deliverable waits on an event to be reconciled again.
+func resourcesSubmittedCondition(err error) (metav1.Condition, error) {
+	switch typedErr := err.(type) {
+	case realizer.GetDeliveryClusterTemplateError:
+		return TemplateObjectRetrievalFailureCondition(typedErr), err
+	case realizer.TemplateNotFoundError:
+		return TemplateNotFoundCondition(typedErr), err
+	case realizer.StampError:
+		return TemplateStampFailureCondition(typedErr), err
+	case realizer.ApplyStampedObjectError:
+		return TemplateRejectedByAPIServerCondition(typedErr), err
+	case realizer.ApplyConflictError:
+		return TemplateApplyConflictCondition(typedErr), err
+	case realizer.PolicyViolationError:
+		return PolicyViolationCondition(typedErr), nil
+	case realizer.StampedObjectRejectedError:
+		return StampedObjectRejectedCondition(typedErr), nil
+	case realizer.KindNotRegisteredError:
+		return KindNotRegisteredCondition(typedErr), nil
+	case realizer.JobRunningError:
+		return JobRunningCondition(typedErr), nil
+	case realizer.JobFailedError:
+		return JobFailedCondition(typedErr), nil
+	case realizer.PluginRunningError:
+		return PluginRunningCondition(typedErr), nil
+	case realizer.PluginFailedError:
+		return PluginFailedCondition(typedErr), nil
+	case realizer.RetrieveOutputError:
+		return MissingValueAtPathCondition(typedErr.ResourceName(), typedErr.JsonPathExpression()), nil
+	case realizer.OutputPathError:
+		return InvalidOutputPathCondition(typedErr.ResourceName(), typedErr.JsonPathExpression()), err
+	case targetUnavailableError:
+		return TargetUnavailableCondition(typedErr), err
+	default:
+		return UnknownResourceErrorCondition(typedErr), err
+	}
 }
 
-func (r *Reconciler) completeReconciliation(deliverable *v1alpha1.Deliverable, err error) (ctrl.Result, error) {
+func (r *Reconciler) completeReconciliation(ctx context.Context, deliverable *v1alpha1.Deliverable, err error) (ctrl.Result, error) {
+	previousReady := readyCondition(deliverable.Status.Conditions)
 	var changed bool
 	deliverable.Status.Conditions, changed = r.conditionManager.Finalize()
+	metrics.RecordDeliverable(client.ObjectKeyFromObject(deliverable), deliverable.Status.DeliveryRef.Name, readyCondition(deliverable.Status.Conditions))
 
 	var updateErr error
-	if changed || (deliverable.Status.ObservedGeneration != deliverable.Generation) {
+	if changed || r.outputsChanged || r.lastOutputsChanged || r.retriesChanged || r.historyChanged || r.driftedChanged || r.fieldConflictsChanged || r.missingOutputsChanged || r.sourceChanged || r.targetsChanged || r.pullRequestChanged || (deliverable.Status.ObservedGeneration != deliverable.Generation) {
 		deliverable.Status.ObservedGeneration = deliverable.Generation
-		updateErr = r.repo.StatusUpdate(deliverable)
+		flushCtx, cancel := shutdown.FlushContext(ctx)
+		defer cancel()
+		updateCtx, span := tracing.Start(flushCtx, "deliverable.status-update")
+		updateErr = r.repo.StatusUpdate(updateCtx, deliverable)
+		tracing.End(span, updateErr)
 		if updateErr != nil {
 			r.logger.Error(updateErr, "update error")
 			if err == nil {
@@ -125,6 +348,19 @@ func (r *Reconciler) completeReconciliation(deliverable *v1alpha1.Deliverable, e
 			}
 		}
 	}
+	if updateErr == nil {
+		r.notify(ctx, deliverable, previousReady)
+	}
+
+	key := client.ObjectKeyFromObject(deliverable)
+	if r.templatesMissing && updateErr == nil {
+		// the template may yet be created: wait for it with a backoff
+		// rather than retrying right away.
+		delay := r.templateBackoff.Next(key)
+		r.logger.Info("template not found", "error", err.Error(), "retry-after", delay.String())
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	r.templateBackoff.Reset(key)
 
 	if err != nil {
 		return ctrl.Result{}, err
@@ -134,10 +370,27 @@ func (r *Reconciler) completeReconciliation(deliverable *v1alpha1.Deliverable, e
 		return ctrl.Result{}, fmt.Errorf("deliverable not ready")
 	}
 
-	return ctrl.Result{RequeueAfter: reconcileInterval}, nil
+	if !r.settled {
+		metrics.ObserveRealization("Deliverable", deliverable.Status.DeliveryRef.Name, deliverable.Status.Retries)
+	}
+
+	return ctrl.Result{RequeueAfter: r.resyncIntervalOf(deliverable)}, nil
 }
 
-func (r *Reconciler) checkDeliveryReadiness(delivery *v1alpha1.ClusterDelivery) error {
+// resyncIntervalOf is the interval set by the deliverable's
+// carto.run/resync-interval annotation, or else the reconciler's.
+func (r *Reconciler) resyncIntervalOf(deliverable *v1alpha1.Deliverable) time.Duration {
+	interval, err := v1alpha1.GetResyncInterval(deliverable)
+	if err != nil {
+		r.logger.Error(err, "ignoring resync interval")
+	}
+	if interval == 0 {
+		return r.resyncInterval
+	}
+	return interval
+}
+
+func (r *Reconciler) checkDeliveryReadiness(delivery v1alpha1.DeliveryObject) error {
 	readyCondition := getDeliveryReadyCondition(delivery)
 	if readyCondition.Status == "True" {
 		return nil
@@ -145,8 +398,8 @@ func (r *Reconciler) checkDeliveryReadiness(delivery *v1alpha1.ClusterDelivery)
 	return fmt.Errorf("delivery is not in ready condition")
 }
 
-func getDeliveryReadyCondition(delivery *v1alpha1.ClusterDelivery) metav1.Condition {
-	for _, condition := range delivery.Status.Conditions {
+func getDeliveryReadyCondition(delivery v1alpha1.DeliveryObject) metav1.Condition {
+	for _, condition := range delivery.GetStatus().Conditions {
 		if condition.Type == "Ready" {
 			return condition
 		}
@@ -154,13 +407,16 @@ func getDeliveryReadyCondition(delivery *v1alpha1.ClusterDelivery) metav1.Condit
 	return metav1.Condition{}
 }
 
-func (r *Reconciler) getDeliveriesForDeliverable(deliverable *v1alpha1.Deliverable) (*v1alpha1.ClusterDelivery, error) {
+func (r *Reconciler) getDeliveriesForDeliverable(ctx context.Context, deliverable *v1alpha1.Deliverable) (delivery v1alpha1.DeliveryObject, err error) {
+	ctx, span := tracing.Start(ctx, "deliverable.select-delivery")
+	defer func() { tracing.End(span, err) }()
+
 	if len(deliverable.Labels) == 0 {
 		r.conditionManager.AddPositive(DeliverableMissingLabelsCondition())
 		return nil, fmt.Errorf("deliverable is missing required labels")
 	}
 
-	deliveries, err := r.repo.GetDeliveriesForDeliverable(deliverable)
+	deliveries, err := r.matchingDeliveries(ctx, deliverable)
 	if err != nil {
 		r.conditionManager.AddPositive(DeliveryNotFoundCondition(deliverable.Labels))
 		return nil, fmt.Errorf("get delivery by label: %w", err)
@@ -176,5 +432,34 @@ func (r *Reconciler) getDeliveriesForDeliverable(deliverable *v1alpha1.Deliverab
 		return nil, fmt.Errorf("too many deliveries match the deliverable selector label")
 	}
 
-	return &deliveries[0], nil
+	span.SetAttributes(attribute.String("delivery.name", deliveries[0].GetName()))
+
+	return deliveries[0], nil
+}
+
+// matchingDeliveries returns the deliveries whose selector is satisfied by
+// the deliverable: the Deliveries in its namespace when there are any,
+// otherwise the ClusterDeliveries.
+func (r *Reconciler) matchingDeliveries(ctx context.Context, deliverable *v1alpha1.Deliverable) ([]v1alpha1.DeliveryObject, error) {
+	var deliveries []v1alpha1.DeliveryObject
+
+	namespaced, err := r.repo.GetNamespacedDeliveriesForDeliverable(ctx, deliverable)
+	if err != nil {
+		return nil, err
+	}
+	for i := range namespaced {
+		deliveries = append(deliveries, &namespaced[i])
+	}
+	if len(deliveries) > 0 {
+		return deliveries, nil
+	}
+
+	clusterDeliveries, err := r.repo.GetDeliveriesForDeliverable(ctx, deliverable)
+	if err != nil {
+		return nil, err
+	}
+	for i := range clusterDeliveries {
+		deliveries = append(deliveries, &clusterDeliveries[i])
+	}
+	return deliveries, nil
 }
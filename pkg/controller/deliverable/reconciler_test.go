@@ -17,6 +17,7 @@ package deliverable_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -24,23 +25,41 @@ import (
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
 	. "github.com/onsi/gomega/gstruct"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/backoff"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions/conditionsfakes"
 	"github.com/vmware-tanzu/cartographer/pkg/controller/deliverable"
+	controllerfakes "github.com/vmware-tanzu/cartographer/pkg/controller/deliverable/deliverablefakes"
+	"github.com/vmware-tanzu/cartographer/pkg/eval"
+	"github.com/vmware-tanzu/cartographer/pkg/gitops"
+	"github.com/vmware-tanzu/cartographer/pkg/jobs"
+	"github.com/vmware-tanzu/cartographer/pkg/notification"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin/pluginfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
+	"github.com/vmware-tanzu/cartographer/pkg/rbac"
 	realizer "github.com/vmware-tanzu/cartographer/pkg/realizer/deliverable"
 	"github.com/vmware-tanzu/cartographer/pkg/realizer/deliverable/deliverablefakes"
 	"github.com/vmware-tanzu/cartographer/pkg/registrar"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
 	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/scope"
+	"github.com/vmware-tanzu/cartographer/pkg/templatelibrary"
 	"github.com/vmware-tanzu/cartographer/pkg/templates"
 )
 
@@ -80,7 +99,7 @@ var _ = Describe("Reconciler", func() {
 			Expect(err).NotTo(HaveOccurred())
 			repo.GetSchemeReturns(scheme)
 
-			reconciler = deliverable.NewReconciler(repo, fakeConditionManagerBuilder, rlzr)
+			reconciler = deliverable.NewReconciler(repo, nil, fakeConditionManagerBuilder, rlzr)
 
 			req = ctrl.Request{
 				NamespacedName: types.NamespacedName{Name: "my-deliverable-name", Namespace: "my-namespace"},
@@ -119,10 +138,35 @@ var _ = Describe("Reconciler", func() {
 			Expect(repo.StatusUpdateCallCount()).To(Equal(1))
 		})
 
+		Context("when the reconcile is cancelled during realization", func() {
+			var cancel context.CancelFunc
+
+			BeforeEach(func() {
+				ctx, cancel = context.WithCancel(ctx)
+				rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.DeliveryObject) error {
+					cancel()
+					return ctx.Err()
+				}
+			})
+
+			It("still writes the status with a live context", func() {
+				var statusCtxErr error
+				repo.StatusUpdateStub = func(statusCtx context.Context, _ client.Object) error {
+					statusCtxErr = statusCtx.Err()
+					return nil
+				}
+
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				Expect(statusCtxErr).NotTo(HaveOccurred())
+			})
+		})
+
 		It("updates the status.observedGeneration to equal metadata.generation", func() {
 			_, _ = reconciler.Reconcile(ctx, req)
 
-			updatedDeliverable := repo.StatusUpdateArgsForCall(0)
+			_, updatedDeliverable := repo.StatusUpdateArgsForCall(0)
 
 			Expect(*updatedDeliverable.(*v1alpha1.Deliverable)).To(MatchFields(IgnoreExtras, Fields{
 				"Status": MatchFields(IgnoreExtras, Fields{
@@ -153,7 +197,7 @@ var _ = Describe("Reconciler", func() {
 
 			_, _ = reconciler.Reconcile(ctx, req)
 
-			updatedDeliverable := repo.StatusUpdateArgsForCall(0)
+			_, updatedDeliverable := repo.StatusUpdateArgsForCall(0)
 
 			Expect(*updatedDeliverable.(*v1alpha1.Deliverable)).To(MatchFields(IgnoreExtras, Fields{
 				"Status": MatchFields(IgnoreExtras, Fields{
@@ -165,7 +209,8 @@ var _ = Describe("Reconciler", func() {
 		It("requests deliveries from the repo", func() {
 			_, _ = reconciler.Reconcile(ctx, req)
 
-			Expect(repo.GetDeliveriesForDeliverableArgsForCall(0)).To(Equal(dl))
+			_, requested := repo.GetDeliveriesForDeliverableArgsForCall(0)
+			Expect(requested).To(Equal(dl))
 		})
 
 		Context("and the repo returns a single matching delivery for the deliverable", func() {
@@ -194,28 +239,1075 @@ var _ = Describe("Reconciler", func() {
 				repo.GetDeliveriesForDeliverableReturns([]v1alpha1.ClusterDelivery{delivery}, nil)
 			})
 
-			It("reschedules for 5 seconds", func() {
-				result, err := reconciler.Reconcile(ctx, req)
+			It("reschedules for 5 seconds", func() {
+				result, err := reconciler.Reconcile(ctx, req)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
+			})
+
+			It("adds the pre-delete finalizer when the delivery has pre-delete hooks", func() {
+				delivery.Spec.PreDelete = []v1alpha1.PreDeleteHook{
+					{Name: "unregister", TemplateRef: v1alpha1.PreDeleteHookTemplateReference{Kind: "ClusterTemplate", Name: "unregister-app"}},
+				}
+				repo.GetDeliveriesForDeliverableReturns([]v1alpha1.ClusterDelivery{delivery}, nil)
+				repo.UpdateStub = func(context.Context, client.Object) error {
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+					return nil
+				}
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.UpdateCallCount()).To(Equal(1))
+				_, updated := repo.UpdateArgsForCall(0)
+				Expect(updated.GetFinalizers()).To(ConsistOf(v1alpha1.PreDeleteFinalizer))
+			})
+
+			It("reschedules at the resync interval it is set", func() {
+				reconciler.SetResyncInterval(time.Minute)
+				result, err := reconciler.Reconcile(ctx, req)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+			})
+
+			Context("when the deliverable is annotated with a resync interval", func() {
+				BeforeEach(func() {
+					reconciler.SetResyncInterval(time.Minute)
+					dl.Annotations = map[string]string{v1alpha1.ResyncIntervalAnnotation: "30m"}
+				})
+
+				It("reschedules at the interval of the annotation", func() {
+					result, err := reconciler.Reconcile(ctx, req)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(ctrl.Result{RequeueAfter: 30 * time.Minute}))
+				})
+
+				Context("that is malformed", func() {
+					BeforeEach(func() {
+						dl.Annotations = map[string]string{v1alpha1.ResyncIntervalAnnotation: "hourly"}
+					})
+
+					It("logs the error and reschedules at the reconciler's interval", func() {
+						result, err := reconciler.Reconcile(ctx, req)
+
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+						Expect(out).To(Say(`"msg":"ignoring resync interval"`))
+					})
+				})
+			})
+
+			It("sets the DeliveryRef", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(dl.Status.DeliveryRef.Kind).To(Equal("ClusterDelivery"))
+				Expect(dl.Status.DeliveryRef.Name).To(Equal(deliveryName))
+			})
+
+			It("calls the condition manager to specify the delivery is ready", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+				Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(deliverable.DeliveryReadyCondition()))
+			})
+
+			It("calls the condition manager to report the resources have been submitted", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+				Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.ResourcesSubmittedCondition(0)))
+			})
+
+			Context("when resources publish outputs", func() {
+				var (
+					url      v1alpha1.DeliverableOutput
+					revision v1alpha1.DeliverableOutput
+				)
+
+				BeforeEach(func() {
+					url = v1alpha1.DeliverableOutput{Name: "url", Resource: "deployer", Value: apiextensionsv1.JSON{Raw: []byte(`"https://app.example.com"`)}}
+					revision = v1alpha1.DeliverableOutput{Name: "revision", Resource: "source-provider", Value: apiextensionsv1.JSON{Raw: []byte(`"abc123"`)}}
+
+					dl.Status.ObservedGeneration = dl.Generation
+					dl.Status.Outputs = []v1alpha1.DeliverableOutput{revision, url}
+					dl.Status.History = []v1alpha1.RealizationRecord{{Generation: 1, Blueprint: "some-delivery"}}
+				})
+
+				It("does not update the status when the outputs are unchanged", func() {
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.DeliveryObject) error {
+						dl.Status.Outputs = []v1alpha1.DeliverableOutput{revision, url}
+						return nil
+					}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+				})
+
+				It("replaces the outputs with those published by a successful realization", func() {
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.DeliveryObject) error {
+						Expect(dl.Status.Outputs).To(BeEmpty())
+						dl.Status.Outputs = []v1alpha1.DeliverableOutput{revision}
+						return nil
+					}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+					Expect(dl.Status.Outputs).To(Equal([]v1alpha1.DeliverableOutput{revision}))
+				})
+
+				It("keeps the outputs not published again by a failed realization", func() {
+					newRevision := revision
+					newRevision.Value = apiextensionsv1.JSON{Raw: []byte(`"def456"`)}
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.DeliveryObject) error {
+						dl.Status.Outputs = []v1alpha1.DeliverableOutput{newRevision}
+						return realizer.StampError{Err: errors.New("bad template"), Resource: &v1alpha1.ClusterDeliveryResource{Name: "deployer"}}
+					}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+					Expect(dl.Status.Outputs).To(Equal([]v1alpha1.DeliverableOutput{newRevision, url}))
+				})
+			})
+
+			Context("when resources record last outputs", func() {
+				var last v1alpha1.LastOutput
+
+				BeforeEach(func() {
+					last = v1alpha1.LastOutput{Resource: "source-provider", Output: apiextensionsv1.JSON{Raw: []byte(`{"source":{"url":"some-url","revision":"abc123"}}`)}}
+
+					dl.Status.ObservedGeneration = dl.Generation
+					dl.Status.LastOutputs = []v1alpha1.LastOutput{last}
+					dl.Status.History = []v1alpha1.RealizationRecord{{Generation: 1, Blueprint: "some-delivery"}}
+				})
+
+				It("does not update the status when the last outputs are unchanged", func() {
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.DeliveryObject) error {
+						dl.Status.LastOutputs = []v1alpha1.LastOutput{last}
+						return nil
+					}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+				})
+
+				It("updates the status when the last outputs change", func() {
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.DeliveryObject) error {
+						dl.Status.LastOutputs = nil
+						return nil
+					}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				})
+			})
+
+			It("records the realization in the deliverable's history", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				Expect(dl.Status.History).To(HaveLen(1))
+				Expect(dl.Status.History[0].Generation).To(Equal(int64(1)))
+				Expect(dl.Status.History[0].Blueprint).To(Equal("some-delivery"))
+				Expect(dl.Status.History[0].Time.IsZero()).To(BeFalse())
+			})
+
+			It("does not record failed realizations in the deliverable's history", func() {
+				rlzr.RealizeReturns(errors.New("realize failed"))
+
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(dl.Status.History).To(BeEmpty())
+			})
+
+			Context("when events are recorded", func() {
+				var eventRecorder *controllerfakes.FakeEventRecorder
+
+				BeforeEach(func() {
+					eventRecorder = &controllerfakes.FakeEventRecorder{}
+					reconciler.AddEventRecording(eventRecorder)
+				})
+
+				It("records an event for each stamped object updated", func() {
+					rlzr.RealizeStub = func(ctx context.Context, _ realizer.ResourceRealizer, _ v1alpha1.DeliveryObject) error {
+						stamped := &unstructured.Unstructured{}
+						stamped.SetKind("Deployment")
+						stamped.SetNamespace("my-namespace")
+						stamped.SetName("app")
+						stamped.SetLabels(map[string]string{"carto.run/resource-name": "deployer"})
+						repository.ChangeReporterFrom(ctx)(stamped, []repository.Change{
+							{Path: "spec.replicas", From: int64(1), To: int64(3)},
+						})
+						return nil
+					}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(eventRecorder.AnnotatedEventfCallCount()).To(Equal(1))
+					object, annotations, eventType, reason, messageFmt, args := eventRecorder.AnnotatedEventfArgsForCall(0)
+					Expect(object).To(Equal(dl))
+					Expect(annotations).To(Equal(map[string]string{
+						"carto.run/resource-name": "deployer",
+						"carto.run/changes":       `[{"path":"spec.replicas","from":1,"to":3}]`,
+					}))
+					Expect(eventType).To(Equal("Normal"))
+					Expect(reason).To(Equal(deliverable.StampedObjectChangedReason))
+					Expect(fmt.Sprintf(messageFmt, args...)).To(Equal("resource 'deployer' updated Deployment my-namespace/app: spec.replicas: 1 -> 3"))
+				})
+			})
+
+			Context("when stamped objects drift", func() {
+				var drifted []v1alpha1.DriftedObject
+
+				BeforeEach(func() {
+					drifted = []v1alpha1.DriftedObject{{
+						Resource: "deployer",
+						Object:   v1alpha1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "my-namespace", Name: "app"},
+						Fields:   []string{"spec.replicas", "spec.paused"},
+					}}
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.DeliveryObject) error {
+						dl.Status.Drifted = append(dl.Status.Drifted, drifted...)
+						return nil
+					}
+				})
+
+				It("adds a negative Drifted condition listing the drifted fields", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(conditionManager.AddNegativeCallCount()).To(Equal(1))
+					condition := conditionManager.AddNegativeArgsForCall(0)
+					Expect(condition).To(Equal(deliverable.DriftedCondition(drifted)))
+					Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+					Expect(condition.Message).To(Equal("resource 'deployer' Deployment my-namespace/app drifted at spec.replicas, spec.paused"))
+				})
+
+				It("updates the status with the drifted objects", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(dl.Status.Drifted).To(Equal(drifted))
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				})
+
+				It("forgets objects that no longer drift", func() {
+					dl.Status.Drifted = drifted
+					rlzr.RealizeReturns(nil)
+					rlzr.RealizeStub = nil
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(dl.Status.Drifted).To(BeEmpty())
+					Expect(conditionManager.AddNegativeCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when other field managers set fields of stamped objects", func() {
+				BeforeEach(func() {
+					rlzr.RealizeStub = func(ctx context.Context, _ realizer.ResourceRealizer, _ v1alpha1.DeliveryObject) error {
+						stamped := &unstructured.Unstructured{}
+						stamped.SetAPIVersion("apps/v1")
+						stamped.SetKind("Deployment")
+						stamped.SetNamespace("my-namespace")
+						stamped.SetName("app")
+						stamped.SetLabels(map[string]string{"carto.run/resource-name": "deployer"})
+						repository.ConflictReporterFrom(ctx)(stamped, []repository.FieldConflict{
+							{Manager: "autoscaler", Fields: []string{"spec.replicas"}},
+						})
+						return nil
+					}
+				})
+
+				It("lists the conflicts in the status", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(dl.Status.FieldConflicts).To(Equal([]v1alpha1.FieldConflict{{
+						Resource: "deployer",
+						Object:   v1alpha1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "my-namespace", Name: "app"},
+						Manager:  "autoscaler",
+						Fields:   []string{"spec.replicas"},
+					}}))
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				})
+
+				It("adds a negative FieldConflict condition naming the field manager", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(conditionManager.AddNegativeCallCount()).To(Equal(1))
+					condition := conditionManager.AddNegativeArgsForCall(0)
+					Expect(condition.Type).To(Equal("FieldConflict"))
+					Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+					Expect(condition.Reason).To(Equal("FieldManagerConflict"))
+					Expect(condition.Message).To(Equal("resource 'deployer' Deployment my-namespace/app: spec.replicas set by field manager 'autoscaler'"))
+				})
+
+				It("forgets conflicts the realization no longer meets", func() {
+					dl.Status.FieldConflicts = []v1alpha1.FieldConflict{{Resource: "deployer", Manager: "autoscaler"}}
+					rlzr.RealizeStub = nil
+					rlzr.RealizeReturns(nil)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(dl.Status.FieldConflicts).To(BeEmpty())
+					Expect(conditionManager.AddNegativeCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when stamped objects are tracked", func() {
+				var tracker *controllerfakes.FakeDynamicTracker
+
+				BeforeEach(func() {
+					tracker = &controllerfakes.FakeDynamicTracker{}
+					reconciler.AddTracking(tracker)
+
+					repo.GetDeliveryClusterTemplateReturns(templates.NewClusterTemplateModel(&v1alpha1.ClusterTemplate{
+						ObjectMeta: metav1.ObjectMeta{Name: "config-template"},
+						Spec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app-config"}}`)},
+						},
+					}), nil)
+					rlzr.RealizeStub = func(ctx context.Context, resourceRealizer realizer.ResourceRealizer, _ v1alpha1.DeliveryObject) error {
+						_, err := resourceRealizer.Do(ctx, &v1alpha1.ClusterDeliveryResource{
+							Name:        "config",
+							TemplateRef: v1alpha1.DeliveryClusterTemplateReference{Kind: "ClusterTemplate", Name: "config-template"},
+						}, "some-delivery", realizer.NewOutputs())
+						return err
+					}
+				})
+
+				It("watches the kinds of the objects stamped, so that changes to them are acted on as they happen", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(tracker.WatchCallCount()).To(Equal(1))
+					_, watched, _ := tracker.WatchArgsForCall(0)
+					Expect(watched.GetObjectKind().GroupVersionKind()).To(Equal(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}))
+				})
+
+				Context("when the object stamped has not output yet", func() {
+					BeforeEach(func() {
+						repo.GetDeliveryClusterTemplateReturns(templates.NewClusterConfigTemplateModel(&v1alpha1.ClusterConfigTemplate{
+							ObjectMeta: metav1.ObjectMeta{Name: "config-template"},
+							Spec: v1alpha1.ConfigTemplateSpec{
+								TemplateSpec: v1alpha1.TemplateSpec{
+									Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app-config"}}`)},
+								},
+								ConfigPath: ".data.config",
+							},
+						}, eval.EvaluatorBuilder()), nil)
+					})
+
+					It("still watches its kind, so that its status changing reconciles the deliverable right away", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+
+						Expect(tracker.WatchCallCount()).To(Equal(1))
+						_, watched, _ := tracker.WatchArgsForCall(0)
+						Expect(watched.GetObjectKind().GroupVersionKind()).To(Equal(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}))
+					})
+				})
+			})
+
+			Context("when templates include template libraries", func() {
+				var tracker *templatelibrary.Tracker
+
+				BeforeEach(func() {
+					dl.Namespace = req.Namespace
+					dl.Name = req.Name
+					tracker = templatelibrary.NewTracker()
+					reconciler.AddLibraryTracking(tracker)
+
+					repo.GetDeliveryClusterTemplateReturns(templates.NewClusterTemplateModel(&v1alpha1.ClusterTemplate{
+						ObjectMeta: metav1.ObjectMeta{Name: "config-template"},
+						Spec: v1alpha1.TemplateSpec{
+							Template:  &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app-config"}}`)},
+							Libraries: []string{"standard"},
+						},
+					}), nil)
+					repo.GetTemplateLibraryReturns(&v1alpha1.ClusterTemplateLibrary{ObjectMeta: metav1.ObjectMeta{Name: "standard"}}, nil)
+					rlzr.RealizeStub = func(ctx context.Context, resourceRealizer realizer.ResourceRealizer, _ v1alpha1.DeliveryObject) error {
+						_, err := resourceRealizer.Do(ctx, &v1alpha1.ClusterDeliveryResource{
+							Name:        "config",
+							TemplateRef: v1alpha1.DeliveryClusterTemplateReference{Kind: "ClusterTemplate", Name: "config-template"},
+						}, "some-delivery", realizer.NewOutputs())
+						return err
+					}
+				})
+
+				It("tracks the libraries, so that changes to them reconcile the deliverable", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(tracker.Requests(&v1alpha1.ClusterTemplateLibrary{ObjectMeta: metav1.ObjectMeta{Name: "standard"}})).
+						To(Equal([]ctrl.Request{req}))
+				})
+			})
+
+			Context("when cluster data is added", func() {
+				var clusterDataReader *controllerfakes.FakeClusterDataReader
+
+				BeforeEach(func() {
+					clusterDataReader = &controllerfakes.FakeClusterDataReader{}
+					reconciler.AddClusterData(clusterDataReader)
+				})
+
+				It("passes the cluster data to the realizer", func() {
+					clusterDataReader.ReadReturns(map[string]interface{}{"domain": "cluster.local"}, nil)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+					realizeCtx, _, _ := rlzr.RealizeArgsForCall(0)
+					Expect(clusterdata.FromContext(realizeCtx)).To(Equal(map[string]interface{}{"domain": "cluster.local"}))
+				})
+
+				It("reports the failure to read it and does not realize the delivery", func() {
+					clusterDataReader.ReadReturns(nil, errors.New("list nodes: forbidden"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("list nodes: forbidden"))
+
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.ClusterDataUnavailableCondition(err)))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when scope detection is added", func() {
+				It("passes the mapper to the realizer", func() {
+					mapper := meta.NewDefaultRESTMapper(nil)
+					reconciler.AddScopeDetection(mapper)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+					realizeCtx, _, _ := rlzr.RealizeArgsForCall(0)
+					Expect(scope.FromContext(realizeCtx)).To(BeIdenticalTo(mapper))
+				})
+			})
+
+			Context("when a permission reviewer is added", func() {
+				var (
+					reviewer *controllerfakes.FakePermissionReviewer
+					template templates.Template
+				)
+
+				BeforeEach(func() {
+					dl.Namespace = "my-namespace"
+					reviewer = &controllerfakes.FakePermissionReviewer{}
+					reconciler.AddPermissionReviewer(reviewer)
+
+					template = templates.NewClusterTemplateModel(&v1alpha1.ClusterTemplate{
+						ObjectMeta: metav1.ObjectMeta{Name: "deploy"},
+						Spec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"apps/v1","kind":"Deployment"}`)},
+						},
+					})
+					repo.GetDeliveryClusterTemplateReturns(template, nil)
+
+					delivery.Spec.Resources = []v1alpha1.ClusterDeliveryResource{{
+						Name:               "deployer",
+						TemplateRef:        v1alpha1.DeliveryClusterTemplateReference{Kind: "ClusterTemplate", Name: "deploy"},
+						ServiceAccountName: "deployer",
+					}}
+					repo.GetDeliveriesForDeliverableReturns([]v1alpha1.ClusterDelivery{delivery}, nil)
+				})
+
+				It("reviews the permissions needed on the objects each resource stamps", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(reviewer.ReviewCallCount()).To(Equal(1))
+					_, stampings := reviewer.ReviewArgsForCall(0)
+					Expect(stampings).To(Equal([]rbac.Stamping{
+						{Resource: "deployer", Template: template, Namespace: "my-namespace", ServiceAccountNamespace: "my-namespace", ServiceAccountName: "deployer"},
+					}))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
+
+				It("reports the permissions missing and does not realize the delivery", func() {
+					missing := []rbac.MissingPermission{{
+						Resource:      "deployer",
+						User:          "system:serviceaccount:my-namespace:deployer",
+						Verb:          "patch",
+						GroupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+						Namespace:     "my-namespace",
+					}}
+					reviewer.ReviewReturns(missing, nil)
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("permissions missing to realize delivery 'some-delivery'"))
+
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.PermissionsNotMetCondition(missing)))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+
+				It("does not review the permissions of deliveries to remote targets", func() {
+					delivery.Spec.Target = &v1alpha1.DeliveryTarget{
+						KubeconfigSecretRef: &v1alpha1.KubeconfigSecretReference{Name: "prod-kubeconfig", Namespace: "clusters"},
+					}
+					repo.GetDeliveriesForDeliverableReturns([]v1alpha1.ClusterDelivery{delivery}, nil)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(reviewer.ReviewCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when realizer plugins are added", func() {
+				It("passes them to the realizer", func() {
+					plugins := &pluginfakes.FakeRealizer{}
+					reconciler.AddRealizerPlugins(plugins)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+					realizeCtx, _, _ := rlzr.RealizeArgsForCall(0)
+					Expect(plugin.FromContext(realizeCtx)).To(BeIdenticalTo(plugins))
+				})
+			})
+
+			Context("when notifications are sent", func() {
+				var notifier *controllerfakes.FakeNotifier
+
+				BeforeEach(func() {
+					notifier = &controllerfakes.FakeNotifier{}
+					reconciler.AddNotifications(notifier)
+
+					dl.Name = "my-deliverable-name"
+					dl.Namespace = "my-namespace"
+					dl.Status.Conditions = []metav1.Condition{
+						{Type: "Ready", Status: "True", Reason: "Ready"},
+					}
+				})
+
+				It("notifies when the deliverable stops being ready", func() {
+					conditionManager.FinalizeReturns([]metav1.Condition{
+						{Type: "Ready", Status: "False", Reason: "TemplateRejectedByAPIServer", Message: "denied"},
+					}, true)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(notifier.NotifyCallCount()).To(Equal(1))
+					_, event := notifier.NotifyArgsForCall(0)
+					Expect(event).To(Equal(notification.Event{
+						Kind:      "Deliverable",
+						Namespace: "my-namespace",
+						Name:      "my-deliverable-name",
+						Labels:    deliverableLabels,
+						Blueprint: "some-delivery",
+						Reason:    "TemplateRejectedByAPIServer",
+						Message:   "denied",
+					}))
+				})
+
+				It("does not notify while the deliverable stays ready", func() {
+					conditionManager.FinalizeReturns([]metav1.Condition{
+						{Type: "Ready", Status: "True", Reason: "Ready"},
+					}, false)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(notifier.NotifyCallCount()).To(Equal(0))
+				})
+
+				It("does not notify when the status cannot be updated", func() {
+					conditionManager.FinalizeReturns([]metav1.Condition{
+						{Type: "Ready", Status: "False", Reason: "TemplateRejectedByAPIServer"},
+					}, true)
+					repo.StatusUpdateReturns(errors.New("some error"))
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(notifier.NotifyCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the deliverable's source is an OCI artifact", func() {
+				var (
+					sourceResolver *controllerfakes.FakeSourceResolver
+					resolved       *v1alpha1.ResolvedSource
+				)
+
+				BeforeEach(func() {
+					dl.Namespace = "my-namespace"
+					dl.Spec.Source = &v1alpha1.Source{
+						OCI: &v1alpha1.OCISource{Image: "registry.example.com/team/app-config:main"},
+					}
+					resolved = &v1alpha1.ResolvedSource{
+						Image:  "registry.example.com/team/app-config:main",
+						Digest: "sha256:abc",
+						URL:    "registry.example.com/team/app-config:main@sha256:abc",
+					}
+
+					sourceResolver = &controllerfakes.FakeSourceResolver{}
+					sourceResolver.ResolveReturns(resolved, nil)
+					reconciler.AddSourceResolution(sourceResolver)
+				})
+
+				It("resolves the source in the deliverable's namespace before realizing it", func() {
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.DeliveryObject) error {
+						Expect(dl.Status.Source).To(Equal(resolved))
+						return nil
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(sourceResolver.ResolveCallCount()).To(Equal(1))
+					_, namespace, source := sourceResolver.ResolveArgsForCall(0)
+					Expect(namespace).To(Equal("my-namespace"))
+					Expect(source).To(Equal(*dl.Spec.Source.OCI))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
+
+				It("reports sources that cannot be resolved", func() {
+					sourceResolver.ResolveReturns(nil, errors.New("registry down"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("registry down"))
+
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.SourceResolutionFailedCondition(err)))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+
+				It("resolves the OCI sources among the named sources", func() {
+					configURL := "https://example.com/config.git"
+					dl.Spec.Sources = []v1alpha1.DeliverableSource{
+						{Name: "config", Source: v1alpha1.Source{Git: &v1alpha1.GitSource{URL: &configURL}}},
+						{Name: "bundle", Source: v1alpha1.Source{OCI: &v1alpha1.OCISource{Image: "registry.example.com/team/app-bundle:main"}}},
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(sourceResolver.ResolveCallCount()).To(Equal(2))
+					_, _, source := sourceResolver.ResolveArgsForCall(1)
+					Expect(source.Image).To(Equal("registry.example.com/team/app-bundle:main"))
+					Expect(dl.Status.Sources).To(Equal([]v1alpha1.ResolvedDeliverableSource{
+						{Name: "bundle", ResolvedSource: *resolved},
+					}))
+				})
+
+				It("reports named sources that cannot be resolved", func() {
+					dl.Spec.Sources = []v1alpha1.DeliverableSource{
+						{Name: "bundle", Source: v1alpha1.Source{OCI: &v1alpha1.OCISource{Image: "registry.example.com/team/app-bundle:main"}}},
+					}
+					sourceResolver.ResolveReturnsOnCall(1, nil, errors.New("registry down"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("source 'bundle': registry down"))
+					Expect(dl.Status.Source).To(BeNil())
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+
+				It("rejects named sources that also set git or image", func() {
+					image := "some-image"
+					dl.Spec.Sources = []v1alpha1.DeliverableSource{
+						{Name: "bundle", Source: v1alpha1.Source{Image: &image, OCI: &v1alpha1.OCISource{Image: "registry.example.com/team/app-bundle:main"}}},
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("source 'bundle': sources[bundle].oci cannot be set with sources[bundle].git or sources[bundle].image"))
+				})
+
+				It("keeps the digest the image was last resolved to when resolving fails", func() {
+					sourceResolver.ResolveReturns(nil, errors.New("registry down"))
+					last := &v1alpha1.ResolvedSource{
+						Image:  "registry.example.com/team/app-config:main",
+						Digest: "sha256:old",
+						URL:    "registry.example.com/team/app-config:main@sha256:old",
+					}
+					dl.Status.Source = last
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(dl.Status.Source).To(Equal(last))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the deliverable's params read their value from the cluster", func() {
+				var (
+					paramResolver *controllerfakes.FakeParamResolver
+					inputs        []v1alpha1.ObjectReference
+				)
+
+				BeforeEach(func() {
+					dl.Namespace = "my-namespace"
+					dl.Name = "my-deliverable-name"
+					dl.Spec.Params = []v1alpha1.Param{{Name: "token", ValueFrom: &v1alpha1.ParamValueSource{
+						SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}, Key: "token"},
+					}}}
+					inputs = []v1alpha1.ObjectReference{{APIVersion: "v1", Kind: "Secret", Namespace: "my-namespace", Name: "credentials"}}
+
+					paramResolver = &controllerfakes.FakeParamResolver{}
+					paramResolver.ResolveReturns([]v1alpha1.Param{{Name: "token", Value: apiextensionsv1.JSON{Raw: []byte(`"s3cr3t"`)}}}, inputs, nil)
+					reconciler.AddParamResolution(paramResolver)
+				})
+
+				It("realizes the delivery with the values read", func() {
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.DeliveryObject) error {
+						Expect(dl.Spec.Params).To(Equal([]v1alpha1.Param{{Name: "token", Value: apiextensionsv1.JSON{Raw: []byte(`"s3cr3t"`)}}}))
+						return nil
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(paramResolver.ResolveCallCount()).To(Equal(1))
+					_, namespace, _ := paramResolver.ResolveArgsForCall(0)
+					Expect(namespace).To(Equal("my-namespace"))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
+
+				It("tracks the objects read, so that changes to them reconcile the deliverable", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(paramResolver.TrackCallCount()).To(Equal(1))
+					owner, tracked := paramResolver.TrackArgsForCall(0)
+					Expect(owner).To(Equal(types.NamespacedName{Namespace: "my-namespace", Name: "my-deliverable-name"}))
+					Expect(tracked).To(Equal(inputs))
+				})
+
+				It("reports params that cannot be resolved", func() {
+					paramResolver.ResolveReturns(nil, inputs, errors.New("param 'token': key 'token' of Secret 'my-namespace/credentials' not found"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("param 'token': key 'token' of Secret 'my-namespace/credentials' not found"))
+
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.ParamResolutionFailedCondition(err)))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+					Expect(paramResolver.TrackCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the delivery targets a remote cluster", func() {
+				var (
+					targetRepo *repositoryfakes.FakeRepository
+					requested  []v1alpha1.KubeconfigSecretReference
+					targetErr  error
+				)
+
+				BeforeEach(func() {
+					delivery.Spec.Target = &v1alpha1.DeliveryTarget{
+						KubeconfigSecretRef: &v1alpha1.KubeconfigSecretReference{Name: "prod-kubeconfig", Namespace: "clusters"},
+					}
+					repo.GetDeliveriesForDeliverableReturns([]v1alpha1.ClusterDelivery{delivery}, nil)
+
+					targetRepo = &repositoryfakes.FakeRepository{}
+					requested = nil
+					targetErr = nil
+					reconciler.AddRemoteTargets(func(_ context.Context, secret v1alpha1.KubeconfigSecretReference) (repository.Repository, error) {
+						requested = append(requested, secret)
+						return targetRepo, targetErr
+					})
+				})
+
+				It("realizes the delivery against the target cluster", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(requested).To(Equal([]v1alpha1.KubeconfigSecretReference{{Name: "prod-kubeconfig", Namespace: "clusters"}}))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
+
+				It("reports a target that cannot be reached", func() {
+					targetErr = errors.New("secret not found")
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("target cluster 'clusters/prod-kubeconfig': secret not found"))
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.TargetUnavailableCondition(err)))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+
+				It("requires the secret of a ClusterDelivery to name its namespace", func() {
+					delivery.Spec.Target.KubeconfigSecretRef.Namespace = ""
+					repo.GetDeliveriesForDeliverableReturns([]v1alpha1.ClusterDelivery{delivery}, nil)
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("target kubeconfig secret 'prod-kubeconfig' has no namespace"))
+					Expect(requested).To(BeEmpty())
+				})
+			})
+
+			Context("when the delivery targets clusters by label", func() {
+				var (
+					requested   []v1alpha1.KubeconfigSecretReference
+					unreachable map[string]bool
+				)
+
+				cluster := func(name string, labels map[string]string) *unstructured.Unstructured {
+					obj := &unstructured.Unstructured{}
+					obj.SetAPIVersion("cluster.x-k8s.io/v1beta1")
+					obj.SetKind("Cluster")
+					obj.SetNamespace("fleet")
+					obj.SetName(name)
+					obj.SetLabels(labels)
+					return obj
+				}
+
+				BeforeEach(func() {
+					delivery.Spec.Target = &v1alpha1.DeliveryTarget{
+						Clusters: &v1alpha1.ClusterSelector{
+							Namespace: "fleet",
+							Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+						},
+					}
+					repo.GetDeliveriesForDeliverableReturns([]v1alpha1.ClusterDelivery{delivery}, nil)
+					repo.ListUnstructuredReturns([]*unstructured.Unstructured{
+						cluster("prod-west", map[string]string{"env": "prod"}),
+						cluster("staging", map[string]string{"env": "staging"}),
+						cluster("prod-east", map[string]string{"env": "prod"}),
+					}, nil)
+
+					requested = nil
+					unreachable = map[string]bool{}
+					reconciler.AddRemoteTargets(func(_ context.Context, secret v1alpha1.KubeconfigSecretReference) (repository.Repository, error) {
+						requested = append(requested, secret)
+						if unreachable[secret.Name] {
+							return nil, errors.New("secret not found")
+						}
+						return &repositoryfakes.FakeRepository{}, nil
+					})
+				})
+
+				It("realizes the delivery on every matching cluster", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, query := repo.ListUnstructuredArgsForCall(0)
+					Expect(query.GetKind()).To(Equal("Cluster"))
+					Expect(query.GetNamespace()).To(Equal("fleet"))
+
+					Expect(requested).To(Equal([]v1alpha1.KubeconfigSecretReference{
+						{Name: "prod-east-kubeconfig", Namespace: "fleet"},
+						{Name: "prod-west-kubeconfig", Namespace: "fleet"},
+					}))
+					Expect(rlzr.RealizeCallCount()).To(Equal(2))
+					Expect(dl.Status.Targets).To(ConsistOf(
+						MatchFields(IgnoreExtras, Fields{"Cluster": Equal("fleet/prod-east"), "Status": Equal(metav1.ConditionTrue)}),
+						MatchFields(IgnoreExtras, Fields{"Cluster": Equal("fleet/prod-west"), "Status": Equal(metav1.ConditionTrue)}),
+					))
+				})
+
+				It("records the clusters the realization failed on", func() {
+					rlzr.RealizeStub = func(_ context.Context, _ realizer.ResourceRealizer, _ v1alpha1.DeliveryObject) error {
+						if rlzr.RealizeCallCount() == 2 {
+							return realizer.StampError{Err: errors.New("bad template"), Resource: &v1alpha1.ClusterDeliveryResource{Name: "deployer"}}
+						}
+						return nil
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError(ContainSubstring("cluster 'fleet/prod-west': ")))
+
+					Expect(rlzr.RealizeCallCount()).To(Equal(2))
+					Expect(dl.Status.Targets).To(ConsistOf(
+						MatchFields(IgnoreExtras, Fields{"Cluster": Equal("fleet/prod-east"), "Status": Equal(metav1.ConditionTrue)}),
+						MatchFields(IgnoreExtras, Fields{
+							"Cluster": Equal("fleet/prod-west"),
+							"Status":  Equal(metav1.ConditionFalse),
+							"Reason":  Equal(v1alpha1.TemplateStampFailureResourcesSubmittedReason),
+						}),
+					))
+					condition := conditionManager.AddPositiveArgsForCall(1)
+					Expect(condition.Reason).To(Equal(v1alpha1.TemplateStampFailureResourcesSubmittedReason))
+					Expect(condition.Message).To(HavePrefix("cluster 'fleet/prod-west': "))
+				})
+
+				It("keeps delivering to the other clusters when one cannot be reached", func() {
+					unreachable["prod-east-kubeconfig"] = true
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("cluster 'fleet/prod-east': secret not found"))
+
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+					Expect(dl.Status.Targets).To(ConsistOf(
+						MatchFields(IgnoreExtras, Fields{
+							"Cluster": Equal("fleet/prod-east"),
+							"Status":  Equal(metav1.ConditionFalse),
+							"Reason":  Equal(v1alpha1.TargetUnavailableResourcesSubmittedReason),
+							"Message": Equal("secret not found"),
+						}),
+						MatchFields(IgnoreExtras, Fields{"Cluster": Equal("fleet/prod-west"), "Status": Equal(metav1.ConditionTrue)}),
+					))
+				})
+
+				It("reports a target no cluster matches", func() {
+					repo.ListUnstructuredReturns([]*unstructured.Unstructured{
+						cluster("staging", map[string]string{"env": "staging"}),
+					}, nil)
+					dl.Status.Targets = []v1alpha1.DeliverableTargetStatus{{Cluster: "fleet/prod-east", Status: metav1.ConditionTrue}}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("no cluster in namespace 'fleet' matches the target selector 'env=prod'"))
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.TargetUnavailableCondition(err)))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+					Expect(dl.Status.Targets).To(BeEmpty())
+				})
+			})
+
+			Context("when the delivery proposes resources in a pull request", func() {
+				var (
+					promoter    *controllerfakes.FakePromoter
+					pullRequest v1alpha1.DeliverablePullRequest
+				)
+
+				BeforeEach(func() {
+					dl.Name = "my-deliverable-name"
+					dl.Namespace = "my-namespace"
+					delivery.Spec.PullRequest = &v1alpha1.PullRequestTarget{
+						Provider:       v1alpha1.GitHubPullRequestProvider,
+						Repository:     "org/gitops",
+						Branch:         "main",
+						Path:           "deliveries",
+						TokenSecretRef: v1alpha1.TokenSecretReference{Name: "gitops-token", Namespace: "gitops"},
+					}
+					repo.GetDeliveriesForDeliverableReturns([]v1alpha1.ClusterDelivery{delivery}, nil)
+					repo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+						obj.Object["data"] = map[string]interface{}{"token": "c29tZS10b2tlbg=="}
+						return nil
+					}
+
+					rlzr.RealizeStub = func(ctx context.Context, _ realizer.ResourceRealizer, _ v1alpha1.DeliveryObject) error {
+						configMap := &unstructured.Unstructured{}
+						configMap.SetAPIVersion("v1")
+						configMap.SetKind("ConfigMap")
+						configMap.SetNamespace("my-namespace")
+						configMap.SetName("app-config")
+						return gitops.FromContext(ctx).EnsureObjectExistsOnCluster(ctx, configMap, true)
+					}
+
+					pullRequest = v1alpha1.DeliverablePullRequest{
+						URL:    "https://github.com/org/gitops/pull/1",
+						Number: 1,
+						State:  v1alpha1.OpenPullRequestState,
+						Branch: "cartographer/my-namespace/my-deliverable-name",
+						Digest: "abc",
+					}
+					promoter = &controllerfakes.FakePromoter{}
+					promoter.PromoteReturns(pullRequest, nil)
+					reconciler.AddPullRequests(promoter)
+				})
+
+				It("proposes the objects collected in a pull request, and records it in the deliverable's status", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(promoter.PromoteCallCount()).To(Equal(1))
+					_, request := promoter.PromoteArgsForCall(0)
+					Expect(request.Provider).To(Equal(v1alpha1.GitHubPullRequestProvider))
+					Expect(request.Repository).To(Equal("org/gitops"))
+					Expect(request.Base).To(Equal("main"))
+					Expect(request.Branch).To(Equal("cartographer/my-namespace/my-deliverable-name"))
+					Expect(request.Path).To(Equal("deliveries/my-namespace/my-deliverable-name.yaml"))
+					Expect(request.Token).To(Equal("some-token"))
+					Expect(string(request.Content)).To(ContainSubstring("name: app-config"))
+
+					_, secret := repo.GetUnstructuredArgsForCall(0)
+					Expect(secret.GetNamespace()).To(Equal("gitops"))
+					Expect(secret.GetName()).To(Equal("gitops-token"))
+
+					_, updatedDeliverable := repo.StatusUpdateArgsForCall(0)
+					Expect(updatedDeliverable.(*v1alpha1.Deliverable).Status.PullRequest).To(Equal(&pullRequest))
+				})
+
+				It("waits for the pull request to be merged", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.PullRequestOpenCondition(pullRequest)))
+				})
+
+				It("reports the resources submitted once the pull request is merged", func() {
+					pullRequest.State = v1alpha1.MergedPullRequestState
+					promoter.PromoteReturns(pullRequest, nil)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.ResourcesSubmittedCondition(0)))
+				})
+
+				It("reports a pull request closed without being merged", func() {
+					pullRequest.State = v1alpha1.ClosedPullRequestState
+					promoter.PromoteReturns(pullRequest, nil)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.PullRequestClosedCondition(pullRequest)))
+				})
+
+				It("reports a pull request that cannot be opened", func() {
+					promoter.PromoteReturns(v1alpha1.DeliverablePullRequest{}, errors.New("rate limited"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("pull request: rate limited"))
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.PullRequestFailedCondition(err)))
+				})
+
+				It("reports a token secret without the token", func() {
+					repo.GetUnstructuredStub = nil
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("secret 'gitops/gitops-token' has no token"))
+					Expect(promoter.PromoteCallCount()).To(Equal(0))
+				})
+
+				It("opens no pull request when no resource is submitted as one", func() {
+					rlzr.RealizeStub = nil
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(promoter.PromoteCallCount()).To(Equal(0))
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.ResourcesSubmittedCondition(0)))
+				})
+
+				It("opens no pull request when the realization fails", func() {
+					rlzr.RealizeStub = nil
+					rlzr.RealizeReturns(errors.New("stamp failed"))
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(promoter.PromoteCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when resources are retried", func() {
+				var retried []string
+
+				BeforeEach(func() {
+					retried = []string{"source-provider"}
+					dl.Status.ObservedGeneration = dl.Generation
+					dl.Status.Retries = []v1alpha1.ResourceRetries{{Resource: "source-provider", Retries: 1}}
+					rlzr.RealizeStub = func(_ context.Context, resourceRealizer realizer.ResourceRealizer, _ v1alpha1.DeliveryObject) error {
+						for _, name := range retried {
+							resourceRealizer.RecordRetry(name)
+						}
+						return nil
+					}
+				})
+
+				It("counts the retries of the current realization and reports them", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
 
-				Expect(err).NotTo(HaveOccurred())
-				Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
-			})
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+					Expect(dl.Status.Retries).To(Equal([]v1alpha1.ResourceRetries{{Resource: "source-provider", Retries: 2}}))
+					Expect(conditionManager.AddPositiveArgsForCall(1).Message).To(Equal("submitted after 2 retries"))
+				})
 
-			It("sets the DeliveryRef", func() {
-				_, _ = reconciler.Reconcile(ctx, req)
+				Context("and the deliverable was ready", func() {
+					BeforeEach(func() {
+						dl.Status.Conditions = []metav1.Condition{{Type: v1alpha1.DeliverableReady, Status: metav1.ConditionTrue}}
+					})
 
-				Expect(dl.Status.DeliveryRef.Kind).To(Equal("ClusterDelivery"))
-				Expect(dl.Status.DeliveryRef.Name).To(Equal(deliveryName))
-			})
+					It("starts counting again when a resource fails", func() {
+						retried = []string{"deployer"}
 
-			It("calls the condition manager to specify the delivery is ready", func() {
-				_, _ = reconciler.Reconcile(ctx, req)
-				Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(deliverable.DeliveryReadyCondition()))
-			})
+						_, _ = reconciler.Reconcile(ctx, req)
 
-			It("calls the condition manager to report the resources have been submitted", func() {
-				_, _ = reconciler.Reconcile(ctx, req)
-				Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.ResourcesSubmittedCondition()))
+						Expect(dl.Status.Retries).To(Equal([]v1alpha1.ResourceRetries{{Resource: "deployer", Retries: 1}}))
+					})
+
+					It("keeps the retries that made it ready while nothing fails", func() {
+						retried = nil
+						dl.Status.History = []v1alpha1.RealizationRecord{{Generation: 1, Blueprint: "some-delivery"}}
+
+						_, _ = reconciler.Reconcile(ctx, req)
+
+						Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+						Expect(dl.Status.Retries).To(Equal([]v1alpha1.ResourceRetries{{Resource: "source-provider", Retries: 1}}))
+					})
+				})
 			})
 
 			Context("but getting the object GVK fails", func() {
@@ -282,6 +1374,52 @@ var _ = Describe("Reconciler", func() {
 					})
 				})
 
+				Context("of type TemplateNotFoundError", func() {
+					var templateError error
+					BeforeEach(func() {
+						templateError = realizer.TemplateNotFoundError{
+							Err: errors.New("some error"),
+						}
+						rlzr.RealizeReturns(templateError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.TemplateNotFoundCondition(templateError)))
+					})
+
+					It("requeues within the base delay of the backoff without an error", func() {
+						result, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+						Expect(result.RequeueAfter).To(BeNumerically("<=", backoff.DefaultBaseDelay))
+						Expect(out).To(Say(`"msg":"template not found"`))
+					})
+
+					It("backs off further while the template is missing", func() {
+						for i := 0; i < 3; i++ {
+							_, _ = reconciler.Reconcile(ctx, req)
+						}
+						result, _ := reconciler.Reconcile(ctx, req)
+						Expect(result.RequeueAfter).To(BeNumerically(">=", 4*backoff.DefaultBaseDelay))
+						Expect(result.RequeueAfter).To(BeNumerically("<=", 8*backoff.DefaultBaseDelay))
+					})
+
+					It("starts the backoff over once the template is found", func() {
+						for i := 0; i < 3; i++ {
+							_, _ = reconciler.Reconcile(ctx, req)
+						}
+						rlzr.RealizeReturns(nil)
+						result, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
+
+						rlzr.RealizeReturns(templateError)
+						result, _ = reconciler.Reconcile(ctx, req)
+						Expect(result.RequeueAfter).To(BeNumerically("<=", backoff.DefaultBaseDelay))
+					})
+				})
+
 				Context("of type StampError", func() {
 					var stampError realizer.StampError
 					BeforeEach(func() {
@@ -324,6 +1462,174 @@ var _ = Describe("Reconciler", func() {
 					})
 				})
 
+				Context("of type ApplyConflictError", func() {
+					var conflictError realizer.ApplyConflictError
+					BeforeEach(func() {
+						conflictError = realizer.ApplyConflictError{
+							Err:           errors.New("some error"),
+							StampedObject: &unstructured.Unstructured{},
+						}
+						rlzr.RealizeReturns(conflictError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.TemplateApplyConflictCondition(conflictError)))
+					})
+
+					It("returns the error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err.Error()).To(ContainSubstring(conflictError.Error()))
+					})
+				})
+
+				Context("of type PolicyViolationError", func() {
+					var violationError realizer.PolicyViolationError
+					BeforeEach(func() {
+						violationError = realizer.PolicyViolationError{
+							Err:           policy.ViolationError{Rule: "no-pods", Message: "pods are not allowed"},
+							StampedObject: &unstructured.Unstructured{},
+						}
+						rlzr.RealizeReturns(violationError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.PolicyViolationCondition(violationError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type StampedObjectRejectedError", func() {
+					var rejectedError realizer.StampedObjectRejectedError
+					BeforeEach(func() {
+						rejectedError = realizer.StampedObjectRejectedError{
+							Err:           repository.RejectedError{Err: errors.New("admission webhook denied the request")},
+							StampedObject: &unstructured.Unstructured{},
+						}
+						rlzr.RealizeReturns(rejectedError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.StampedObjectRejectedCondition(rejectedError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type KindNotRegisteredError", func() {
+					var notRegisteredError realizer.KindNotRegisteredError
+					BeforeEach(func() {
+						notRegisteredError = realizer.KindNotRegisteredError{
+							Err:           errors.New(`no matches for kind "Image" in version "kpack.io/v1alpha2"`),
+							StampedObject: &unstructured.Unstructured{},
+						}
+						rlzr.RealizeReturns(notRegisteredError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.KindNotRegisteredCondition(notRegisteredError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type JobRunningError", func() {
+					var runningError realizer.JobRunningError
+					BeforeEach(func() {
+						runningError = realizer.JobRunningError{
+							Err:      jobs.RunningError{Job: &unstructured.Unstructured{}},
+							Resource: &v1alpha1.ClusterDeliveryResource{Name: "some-resource"},
+						}
+						rlzr.RealizeReturns(runningError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.JobRunningCondition(runningError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type JobFailedError", func() {
+					var failedError realizer.JobFailedError
+					BeforeEach(func() {
+						failedError = realizer.JobFailedError{
+							Err:      jobs.FailedError{Job: &unstructured.Unstructured{}, Message: "BackoffLimitExceeded"},
+							Resource: &v1alpha1.ClusterDeliveryResource{Name: "some-resource"},
+						}
+						rlzr.RealizeReturns(failedError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.JobFailedCondition(failedError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type PluginRunningError", func() {
+					var runningError realizer.PluginRunningError
+					BeforeEach(func() {
+						runningError = realizer.PluginRunningError{
+							Err:      plugin.RunningError{Plugin: "terraform", Message: "applying"},
+							Resource: &v1alpha1.ClusterDeliveryResource{Name: "some-resource"},
+						}
+						rlzr.RealizeReturns(runningError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.PluginRunningCondition(runningError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type PluginFailedError", func() {
+					var failedError realizer.PluginFailedError
+					BeforeEach(func() {
+						failedError = realizer.PluginFailedError{
+							Err:      plugin.FailedError{Plugin: "terraform", Message: "quota exceeded"},
+							Resource: &v1alpha1.ClusterDeliveryResource{Name: "some-resource"},
+						}
+						rlzr.RealizeReturns(failedError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.PluginFailedCondition(failedError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
 				Context("of type RetrieveOutputError", func() {
 					var retrieveError realizer.RetrieveOutputError
 					BeforeEach(func() {
@@ -346,6 +1652,27 @@ var _ = Describe("Reconciler", func() {
 					})
 				})
 
+				Context("of type OutputPathError", func() {
+					var outputPathError realizer.OutputPathError
+					BeforeEach(func() {
+						jsonPathError := templates.NewJsonPathError("data[", errors.New("some error"))
+						outputPathError = realizer.NewOutputPathError(
+							&v1alpha1.ClusterDeliveryResource{Name: "some-resource"},
+							&jsonPathError)
+						rlzr.RealizeReturns(outputPathError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(deliverable.InvalidOutputPathCondition("some-resource", "data[")))
+					})
+
+					It("returns the error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err.Error()).To(ContainSubstring(outputPathError.Error()))
+					})
+				})
+
 				Context("of unknown type", func() {
 					var realizerError error
 					BeforeEach(func() {
@@ -467,6 +1794,157 @@ var _ = Describe("Reconciler", func() {
 			})
 		})
 
+		Context("and the repo returns a matching Delivery in the deliverable's namespace", func() {
+			var namespacedDelivery v1alpha1.Delivery
+
+			BeforeEach(func() {
+				namespacedDelivery = v1alpha1.Delivery{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "team-delivery",
+						Namespace: "my-namespace",
+					},
+					Status: v1alpha1.ClusterDeliveryStatus{
+						Conditions: []metav1.Condition{
+							{
+								Type:   "Ready",
+								Status: "True",
+								Reason: "Ready",
+							},
+						},
+					},
+				}
+				repo.GetNamespacedDeliveriesForDeliverableReturns([]v1alpha1.Delivery{namespacedDelivery}, nil)
+				repo.GetDeliveriesForDeliverableReturns([]v1alpha1.ClusterDelivery{{}}, nil)
+			})
+
+			It("realizes the Delivery without considering cluster deliveries", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.GetDeliveriesForDeliverableCallCount()).To(Equal(0))
+				_, _, realized := rlzr.RealizeArgsForCall(0)
+				Expect(realized).To(Equal(&namespacedDelivery))
+			})
+
+			It("reads the kubeconfig of a target from the Delivery's namespace", func() {
+				namespacedDelivery.Spec.Target = &v1alpha1.DeliveryTarget{
+					KubeconfigSecretRef: &v1alpha1.KubeconfigSecretReference{Name: "prod-kubeconfig", Namespace: "other-namespace"},
+				}
+				repo.GetNamespacedDeliveriesForDeliverableReturns([]v1alpha1.Delivery{namespacedDelivery}, nil)
+				var requested v1alpha1.KubeconfigSecretReference
+				reconciler.AddRemoteTargets(func(_ context.Context, secret v1alpha1.KubeconfigSecretReference) (repository.Repository, error) {
+					requested = secret
+					return &repositoryfakes.FakeRepository{}, nil
+				})
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requested.Namespace).To(Equal("my-namespace"))
+			})
+
+			It("sets the DeliveryRef to the Delivery", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(dl.Status.DeliveryRef.Kind).To(Equal("Delivery"))
+				Expect(dl.Status.DeliveryRef.Name).To(Equal("team-delivery"))
+			})
+		})
+
+		Context("and repo returns an error when requesting namespaced deliveries", func() {
+			BeforeEach(func() {
+				repo.GetNamespacedDeliveriesForDeliverableReturns(nil, errors.New("some error"))
+			})
+
+			It("returns a helpful error", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err.Error()).To(ContainSubstring("get delivery by label: some error"))
+				Expect(repo.GetDeliveriesForDeliverableCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("the deliverable is being deleted", func() {
+			var jobCondition string
+
+			BeforeEach(func() {
+				now := metav1.Now()
+				dl.DeletionTimestamp = &now
+				dl.Finalizers = []string{v1alpha1.PreDeleteFinalizer}
+				dl.Status.DeliveryRef = v1alpha1.ObjectReference{Kind: "ClusterDelivery", Name: "some-delivery"}
+
+				repo.GetDeliveryReturns(&v1alpha1.ClusterDelivery{
+					ObjectMeta: metav1.ObjectMeta{Name: "some-delivery"},
+					Spec: v1alpha1.ClusterDeliverySpec{
+						PreDelete: []v1alpha1.PreDeleteHook{
+							{Name: "unregister", TemplateRef: v1alpha1.PreDeleteHookTemplateReference{Kind: "ClusterTemplate", Name: "unregister-app"}},
+						},
+					},
+				}, nil)
+
+				apiTemplate := &v1alpha1.ClusterTemplate{}
+				apiTemplate.Name = "unregister-app"
+				apiTemplate.Spec.Template = &runtime.RawExtension{Raw: []byte(`{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "unregister"}, "spec": {}}`)}
+				template, err := templates.NewModelFromAPI(apiTemplate)
+				Expect(err).NotTo(HaveOccurred())
+				repo.GetClusterTemplateReturns(template, nil)
+
+				jobCondition = ""
+				repo.EnsureImmutableObjectExistsOnClusterStub = func(_ context.Context, job *unstructured.Unstructured) error {
+					if jobCondition != "" {
+						Expect(unstructured.SetNestedSlice(job.Object, []interface{}{
+							map[string]interface{}{"type": jobCondition, "status": "True"},
+						}, "status", "conditions")).To(Succeed())
+					}
+					return nil
+				}
+			})
+
+			It("runs the hooks of the delivery and waits for them", func() {
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(deliverable.DefaultResyncInterval))
+
+				Expect(repo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, job := repo.EnsureImmutableObjectExistsOnClusterArgsForCall(0)
+				Expect(job.GetLabels()).To(HaveKeyWithValue("carto.run/cluster-delivery-name", "some-delivery"))
+				Expect(repo.UpdateCallCount()).To(Equal(0))
+				Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				Expect(dl.Status.Conditions).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(v1alpha1.PreDeleteHooksCompleted),
+					"Reason": Equal(v1alpha1.RunningPreDeleteHooksCompletedReason),
+				})))
+			})
+
+			It("removes the finalizer once the hooks complete", func() {
+				jobCondition = "Complete"
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+
+				Expect(repo.UpdateCallCount()).To(Equal(1))
+				_, updated := repo.UpdateArgsForCall(0)
+				Expect(updated.GetFinalizers()).To(BeEmpty())
+			})
+
+			It("reads a namespaced delivery from the deliverable's namespace", func() {
+				dl.Status.DeliveryRef.Kind = "Delivery"
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repo.GetNamespacedDeliveryCallCount()).To(Equal(1))
+				Expect(repo.UpdateCallCount()).To(Equal(1))
+			})
+
+			It("does nothing without the finalizer", func() {
+				dl.Finalizers = nil
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repo.GetDeliveryCallCount()).To(Equal(0))
+				Expect(repo.UpdateCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("but status update fails", func() {
 			BeforeEach(func() {
 				repo.StatusUpdateReturns(errors.New("some error"))
@@ -518,6 +1996,19 @@ var _ = Describe("Reconciler", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result).To(Equal(ctrl.Result{Requeue: false}))
 			})
+
+			It("forgets the objects stamped for the deliverable", func() {
+				evicter := &controllerfakes.FakeCacheEvicter{}
+				reconciler.AddCacheEviction(evicter)
+
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(evicter.EvictOwnedCallCount()).To(Equal(1))
+				Expect(evicter.EvictOwnedArgsForCall(0)).To(Equal(map[string]string{
+					"carto.run/deliverable-name":      "my-deliverable-name",
+					"carto.run/deliverable-namespace": "my-namespace",
+				}))
+			})
 		})
 
 	})
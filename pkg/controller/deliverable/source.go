@@ -0,0 +1,102 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+//counterfeiter:generate . SourceResolver
+type SourceResolver interface {
+	Resolve(ctx context.Context, namespace string, source v1alpha1.OCISource) (*v1alpha1.ResolvedSource, error)
+}
+
+// AddSourceResolution lets the reconciler resolve deliverables' OCI sources to
+// the digests they point at, so that artifacts pushed again are realized.
+func (r *Reconciler) AddSourceResolution(resolver SourceResolver) {
+	r.sourceResolver = resolver
+}
+
+// resolveSource publishes the deliverable's OCI sources, as resolved, in
+// its status. When a source cannot be resolved, the deliverable keeps the
+// digest it last resolved the same image to.
+func (r *Reconciler) resolveSource(ctx context.Context, deliverable *v1alpha1.Deliverable) error {
+	previous := deliverable.Status.Source
+	previousSources := deliverable.Status.Sources
+
+	resolved, err := r.resolve(ctx, deliverable.Namespace, "source", deliverable.Spec.Source, previous)
+	if err != nil {
+		return err
+	}
+
+	var resolvedSources []v1alpha1.ResolvedDeliverableSource
+	for i := range deliverable.Spec.Sources {
+		named := &deliverable.Spec.Sources[i]
+		field := fmt.Sprintf("sources[%s]", named.Name)
+		last := lastResolvedSource(previousSources, named.Name)
+		resolvedSource, err := r.resolve(ctx, deliverable.Namespace, field, &named.Source, last)
+		if err != nil {
+			return fmt.Errorf("source '%s': %w", named.Name, err)
+		}
+		if resolvedSource != nil {
+			resolvedSources = append(resolvedSources, v1alpha1.ResolvedDeliverableSource{Name: named.Name, ResolvedSource: *resolvedSource})
+		}
+	}
+
+	deliverable.Status.Source = resolved
+	deliverable.Status.Sources = resolvedSources
+	r.sourceChanged = !equality.Semantic.DeepEqual(previous, resolved) || !equality.Semantic.DeepEqual(previousSources, resolvedSources)
+	return nil
+}
+
+// resolve returns source as resolved, or nil when it is not an OCI source.
+// previous is how the source was last resolved, if it was.
+func (r *Reconciler) resolve(ctx context.Context, namespace, field string, source *v1alpha1.Source, previous *v1alpha1.ResolvedSource) (*v1alpha1.ResolvedSource, error) {
+	if source == nil || source.OCI == nil {
+		return nil, nil
+	}
+	if source.Git != nil || source.Image != nil {
+		return nil, fmt.Errorf("%[1]s.oci cannot be set with %[1]s.git or %[1]s.image", field)
+	}
+	if r.sourceResolver == nil {
+		return previous, nil
+	}
+
+	resolved, err := r.sourceResolver.Resolve(ctx, namespace, *source.OCI)
+	if err != nil {
+		if previous == nil || previous.Image != source.OCI.Image {
+			return nil, err
+		}
+		r.logger.Error(err, "resolve source, keeping last digest", "source", field, "digest", previous.Digest)
+		return previous, nil
+	}
+	return resolved, nil
+}
+
+func lastResolvedSource(sources []v1alpha1.ResolvedDeliverableSource, name string) *v1alpha1.ResolvedSource {
+	for i := range sources {
+		if sources[i].Name == name {
+			return &sources[i].ResolvedSource
+		}
+	}
+	return nil
+}
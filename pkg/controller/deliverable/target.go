@@ -0,0 +1,194 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deliverable
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	realizer "github.com/vmware-tanzu/cartographer/pkg/realizer/deliverable"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+// AddRemoteTargets lets the reconciler apply the objects stamped for
+// deliverables to the remote clusters their deliveries target.
+func (r *Reconciler) AddRemoteTargets(targets repository.TargetRepository) {
+	r.targetRepository = targets
+}
+
+// clusterGVK is the Cluster API Cluster a delivery's target selects.
+var clusterGVK = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster"}
+
+// targetRealizer realizes the deliverable on one of the clusters its
+// delivery targets.
+type targetRealizer struct {
+	// cluster is the selected Cluster, as namespace/name. It is empty when
+	// the delivery does not select clusters.
+	cluster  string
+	realizer realizer.ResourceRealizer
+	// err is why the cluster cannot be reached.
+	err error
+}
+
+// targetUnavailableError is a selected cluster that cannot be reached.
+type targetUnavailableError struct {
+	err error
+}
+
+func (e targetUnavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e targetUnavailableError) Unwrap() error {
+	return e.err
+}
+
+// resourceRealizers returns the realizers of the deliverable's resources:
+// one per cluster the delivery targets, each applying the objects it stamps
+// to its cluster, or a single one applying them to this cluster.
+func (r *Reconciler) resourceRealizers(ctx context.Context, deliverable *v1alpha1.Deliverable, delivery v1alpha1.DeliveryObject) ([]targetRealizer, error) {
+	target := delivery.GetSpec().Target
+	if target == nil {
+		return []targetRealizer{{realizer: realizer.NewResourceRealizer(deliverable, r.repo, r.serviceAccountRepo)}}, nil
+	}
+	if r.targetRepository == nil {
+		return nil, fmt.Errorf("remote delivery targets are not enabled")
+	}
+	if target.Clusters != nil {
+		return r.clusterRealizers(ctx, deliverable, delivery, target.Clusters)
+	}
+	if target.KubeconfigSecretRef == nil {
+		return nil, fmt.Errorf("target has neither kubeconfigSecretRef nor clusters")
+	}
+
+	secret := *target.KubeconfigSecretRef
+	// a Delivery cannot reach secrets outside its namespace.
+	if delivery.GetNamespace() != "" {
+		secret.Namespace = delivery.GetNamespace()
+	}
+	if secret.Namespace == "" {
+		return nil, fmt.Errorf("target kubeconfig secret '%s' has no namespace", secret.Name)
+	}
+	name := secret.Namespace + "/" + secret.Name
+
+	targetRepo, err := r.targetRepository(ctx, secret)
+	if err != nil {
+		return nil, fmt.Errorf("target cluster '%s': %w", name, err)
+	}
+	return []targetRealizer{{realizer: realizer.NewRemoteResourceRealizer(deliverable, r.repo, targetRepo, name)}}, nil
+}
+
+// clusterRealizers returns a realizer for each Cluster the selector
+// matches, in the order of their names. A cluster whose kubeconfig cannot
+// be read gets a realizer-less entry holding the error.
+func (r *Reconciler) clusterRealizers(ctx context.Context, deliverable *v1alpha1.Deliverable, delivery v1alpha1.DeliveryObject, clusters *v1alpha1.ClusterSelector) ([]targetRealizer, error) {
+	namespace := clusters.Namespace
+	// a Delivery cannot reach clusters outside its namespace.
+	if delivery.GetNamespace() != "" {
+		namespace = delivery.GetNamespace()
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("target clusters have no namespace")
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&clusters.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("target clusters selector: %w", err)
+	}
+
+	query := &unstructured.Unstructured{}
+	query.SetGroupVersionKind(clusterGVK)
+	query.SetNamespace(namespace)
+	candidates, err := r.repo.ListUnstructured(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list target clusters in namespace '%s': %w", namespace, err)
+	}
+
+	var names []string
+	for _, cluster := range candidates {
+		if cluster.GetDeletionTimestamp() != nil || !selector.Matches(labels.Set(cluster.GetLabels())) {
+			continue
+		}
+		names = append(names, cluster.GetName())
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no cluster in namespace '%s' matches the target selector '%s'", namespace, selector)
+	}
+	sort.Strings(names)
+
+	var targets []targetRealizer
+	for _, name := range names {
+		cluster := namespace + "/" + name
+		// Cluster API writes the kubeconfig of each cluster it provisions
+		// to a Secret named after it.
+		targetRepo, err := r.targetRepository(ctx, v1alpha1.KubeconfigSecretReference{Name: name + "-kubeconfig", Namespace: namespace})
+		if err != nil {
+			targets = append(targets, targetRealizer{cluster: cluster, err: targetUnavailableError{err: err}})
+			continue
+		}
+		targets = append(targets, targetRealizer{
+			cluster:  cluster,
+			realizer: realizer.NewRemoteResourceRealizer(deliverable, r.repo, targetRepo, cluster),
+		})
+	}
+	return targets, nil
+}
+
+// realizeTargets realizes the delivery on every target, recording how it
+// went on each selected cluster in the deliverable's status. It returns
+// the first error met, along with the cluster it was met on.
+func (r *Reconciler) realizeTargets(ctx context.Context, deliverable *v1alpha1.Deliverable, delivery v1alpha1.DeliveryObject, targets []targetRealizer) (string, error) {
+	previous := deliverable.Status.Targets
+	deliverable.Status.Targets = nil
+
+	var (
+		failedCluster string
+		firstErr      error
+	)
+	for _, target := range targets {
+		err := target.err
+		if err == nil {
+			err = r.realizer.Realize(ctx, target.realizer, delivery)
+		}
+		if err != nil && firstErr == nil {
+			failedCluster, firstErr = target.cluster, err
+		}
+		if target.cluster == "" {
+			continue
+		}
+
+		condition := ResourcesSubmittedCondition(0)
+		if err != nil {
+			condition, _ = resourcesSubmittedCondition(err)
+		}
+		deliverable.Status.Targets = append(deliverable.Status.Targets, v1alpha1.DeliverableTargetStatus{
+			Cluster: target.cluster,
+			Status:  condition.Status,
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		})
+	}
+
+	r.targetsChanged = !equality.Semantic.DeepEqual(previous, deliverable.Status.Targets)
+	return failedCluster, firstErr
+}
@@ -44,3 +44,22 @@ func TemplatesFoundCondition() metav1.Condition {
 		Reason: v1alpha1.ReadyDeliveryTemplatesReadyReason,
 	}
 }
+
+// -- Resource conditions
+
+func ResourceTemplatesNotFoundCondition(templateRefs []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.DeliveryTemplatesReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.NotFoundDeliveryTemplatesReadyReason,
+		Message: fmt.Sprintf("Did not find the template(s) '%s'", strings.Join(templateRefs, "', '")),
+	}
+}
+
+func ResourceTemplatesFoundCondition() metav1.Condition {
+	return metav1.Condition{
+		Type:   v1alpha1.DeliveryTemplatesReady,
+		Status: metav1.ConditionTrue,
+		Reason: v1alpha1.ReadyDeliveryTemplatesReadyReason,
+	}
+}
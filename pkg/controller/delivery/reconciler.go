@@ -21,11 +21,15 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/backoff"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate"
 	"github.com/vmware-tanzu/cartographer/pkg/repository"
 )
 
@@ -35,11 +39,41 @@ type Reconciler struct {
 	repo             repository.Repository
 	conditionManager conditions.ConditionManager
 	logger           logr.Logger
+	kind             string
+	getDelivery      func(ctx context.Context, req reconcile.Request) (v1alpha1.DeliveryObject, error)
+	templateBackoff  *backoff.Backoff
+	templatesMissing bool
 }
 
 func NewReconciler(repo repository.Repository) *Reconciler {
 	return &Reconciler{
-		repo: repo,
+		repo:            repo,
+		kind:            "ClusterDelivery",
+		templateBackoff: backoff.New(backoff.DefaultBaseDelay, backoff.DefaultMaxDelay),
+		getDelivery: func(ctx context.Context, req reconcile.Request) (v1alpha1.DeliveryObject, error) {
+			delivery, err := repo.GetDelivery(ctx, req.Name)
+			if err != nil || delivery == nil {
+				return nil, err
+			}
+			return delivery, nil
+		},
+	}
+}
+
+// NewNamespacedReconciler returns a Reconciler for Deliveries rather than
+// ClusterDeliveries.
+func NewNamespacedReconciler(repo repository.Repository) *Reconciler {
+	return &Reconciler{
+		repo:            repo,
+		kind:            "Delivery",
+		templateBackoff: backoff.New(backoff.DefaultBaseDelay, backoff.DefaultMaxDelay),
+		getDelivery: func(ctx context.Context, req reconcile.Request) (v1alpha1.DeliveryObject, error) {
+			delivery, err := repo.GetNamespacedDelivery(ctx, req.Name, req.Namespace)
+			if err != nil || delivery == nil {
+				return nil, err
+			}
+			return delivery, nil
+		},
 	}
 }
 
@@ -49,7 +83,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl
 	r.logger.Info("started")
 	defer r.logger.Info("finished")
 
-	delivery, err := r.repo.GetDelivery(req.Name)
+	delivery, err := r.getDelivery(ctx, req)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("get delivery: %w", err)
 	}
@@ -58,42 +92,102 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl
 		r.logger.Info("delivery no longer exists")
 		return ctrl.Result{}, nil
 	}
+	ctx = gittemplate.WithBlueprint(ctx, delivery)
 
-	r.conditionManager = conditions.NewConditionManager(v1alpha1.DeliveryReady, delivery.Status.Conditions)
+	r.conditionManager = conditions.NewConditionManager(v1alpha1.DeliveryReady, delivery.GetStatus().Conditions)
+
+	if !delivery.GetDeletionTimestamp().IsZero() {
+		return r.tearDown(ctx, delivery)
+	}
+
+	if err := r.ensureTeardownFinalizer(ctx, delivery); err != nil {
+		return ctrl.Result{}, err
+	}
 
-	err = r.reconcileDelivery(delivery)
+	r.templatesMissing = false
+	err = r.reconcileDelivery(ctx, delivery)
+	r.countRollout(ctx, delivery)
+	r.collectTrackedObjects(ctx, delivery)
 
-	return r.completeReconciliation(delivery, err)
+	return r.completeReconciliation(ctx, delivery, err)
 }
 
-func (r *Reconciler) reconcileDelivery(delivery *v1alpha1.ClusterDelivery) error {
-	var missing []string
-	for _, resource := range delivery.Spec.Resources {
-		_, err := r.repo.GetDeliveryClusterTemplate(resource.TemplateRef)
+func (r *Reconciler) reconcileDelivery(ctx context.Context, delivery v1alpha1.DeliveryObject) error {
+	var (
+		missing          []string
+		resourceStatuses []v1alpha1.ResourceTemplatesStatus
+	)
+
+	status := delivery.GetStatus()
+	for _, resource := range delivery.GetSpec().Resources {
+		var (
+			resolved   []v1alpha1.ResolvedTemplateRef
+			unresolved []string
+		)
+		template, err := r.repo.GetDeliveryClusterTemplate(ctx, resource.TemplateRef)
 		if err != nil {
 			missing = append(missing, resource.Name)
+			unresolved = append(unresolved, resource.TemplateRef.Kind+"/"+resource.TemplateRef.DisplayName())
 			r.logger.Error(err, "retrieving cluster template")
+		} else if template != nil {
+			resolved = append(resolved, v1alpha1.ResolvedTemplateRef{
+				Kind:       resource.TemplateRef.Kind,
+				Name:       template.GetName(),
+				Generation: template.GetGeneration(),
+			})
 		}
+		resourceStatuses = append(resourceStatuses, resourceTemplatesStatus(status.Resources, resource.Name, resolved, unresolved))
 	}
+	status.Resources = resourceStatuses
 
 	if len(missing) == 0 {
 		r.conditionManager.AddPositive(TemplatesFoundCondition())
 		return nil
 	} else {
+		r.templatesMissing = true
 		r.conditionManager.AddPositive(TemplatesNotFoundCondition(missing))
 		return fmt.Errorf("encountered errors fetching resources: %s", strings.Join(missing, ", "))
 	}
 }
 
-func (r *Reconciler) completeReconciliation(delivery *v1alpha1.ClusterDelivery, reconcileError error) (ctrl.Result, error) {
-	delivery.Status.Conditions, _ = r.conditionManager.Finalize()
+// resourceTemplatesStatus returns the status of the resource named name,
+// keeping the time its TemplatesReady condition last changed in previous.
+func resourceTemplatesStatus(previous []v1alpha1.ResourceTemplatesStatus, name string, resolved []v1alpha1.ResolvedTemplateRef, unresolved []string) v1alpha1.ResourceTemplatesStatus {
+	status := v1alpha1.ResourceTemplatesStatus{Resource: name, ResolvedTemplateRefs: resolved, UnresolvedTemplateRefs: unresolved}
+	for _, p := range previous {
+		if p.Resource == name {
+			status.Conditions = append(status.Conditions, p.Conditions...)
+		}
+	}
+
+	condition := ResourceTemplatesFoundCondition()
+	if len(unresolved) > 0 {
+		condition = ResourceTemplatesNotFoundCondition(unresolved)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+	return status
+}
+
+func (r *Reconciler) completeReconciliation(ctx context.Context, delivery v1alpha1.DeliveryObject, reconcileError error) (ctrl.Result, error) {
+	status := delivery.GetStatus()
+	status.Conditions, _ = r.conditionManager.Finalize()
 
-	delivery.Status.ObservedGeneration = delivery.Generation
-	err := r.repo.StatusUpdate(delivery)
+	status.ObservedGeneration = delivery.GetGeneration()
+	err := r.repo.StatusUpdate(ctx, delivery)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("status update: %w", err)
 	}
 
+	key := client.ObjectKeyFromObject(delivery)
+	if r.templatesMissing {
+		// templates are often created after the deliveries naming them:
+		// wait for them with a backoff rather than retrying right away.
+		delay := r.templateBackoff.Next(key)
+		r.logger.Info("templates not found", "error", reconcileError.Error(), "retry-after", delay.String())
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	r.templateBackoff.Reset(key)
+
 	if reconcileError != nil {
 		return ctrl.Result{}, reconcileError
 	}
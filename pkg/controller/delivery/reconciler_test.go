@@ -25,14 +25,19 @@ import (
 	. "github.com/onsi/gomega/gbytes"
 	. "github.com/onsi/gomega/gstruct"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/backoff"
 	"github.com/vmware-tanzu/cartographer/pkg/controller/delivery"
 	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/teardown"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
 )
 
 var _ = Describe("delivery reconciler", func() {
@@ -103,11 +108,12 @@ var _ = Describe("delivery reconciler", func() {
 
 				Expect(repo.GetDeliveryCallCount()).To(Equal(1))
 
-				name := repo.GetDeliveryArgsForCall(0)
+				_, name := repo.GetDeliveryArgsForCall(0)
 				Expect(name).To(Equal("my-new-delivery"))
 
 				Expect(repo.StatusUpdateCallCount()).To(Equal(1))
-				deliveryObject, ok := repo.StatusUpdateArgsForCall(0).(*v1alpha1.ClusterDelivery)
+				_, statusObject := repo.StatusUpdateArgsForCall(0)
+				deliveryObject, ok := statusObject.(*v1alpha1.ClusterDelivery)
 				Expect(ok).To(BeTrue())
 
 				Expect(deliveryObject).To(Equal(apiDelivery))
@@ -134,7 +140,7 @@ var _ = Describe("delivery reconciler", func() {
 			It("updates the status.observedGeneration to equal metadata.generation", func() {
 				_, _ = reconciler.Reconcile(ctx, req)
 
-				updatedDelivery := repo.StatusUpdateArgsForCall(0)
+				_, updatedDelivery := repo.StatusUpdateArgsForCall(0)
 
 				Expect(*updatedDelivery.(*v1alpha1.ClusterDelivery)).To(MatchFields(IgnoreExtras, Fields{
 					"Status": MatchFields(IgnoreExtras, Fields{
@@ -143,6 +149,49 @@ var _ = Describe("delivery reconciler", func() {
 				}))
 			})
 
+			It("counts the deliverables realized with it by how far they have converged", func() {
+				deliverable := func(kind string, delivery string, ready metav1.ConditionStatus) v1alpha1.Deliverable {
+					return v1alpha1.Deliverable{
+						ObjectMeta: metav1.ObjectMeta{Generation: 1},
+						Status: v1alpha1.DeliverableStatus{
+							ObservedGeneration: 1,
+							Conditions:         []metav1.Condition{{Type: "Ready", Status: ready}},
+							DeliveryRef:        v1alpha1.ObjectReference{Kind: kind, Name: delivery},
+							History: []v1alpha1.RealizationRecord{
+								{Generation: 1, Blueprint: delivery, BlueprintGeneration: 99},
+							},
+						},
+					}
+				}
+				repo.ListDeliverablesReturns([]v1alpha1.Deliverable{
+					deliverable("ClusterDelivery", "my-new-delivery", metav1.ConditionTrue),
+					deliverable("ClusterDelivery", "my-new-delivery", metav1.ConditionFalse),
+					deliverable("ClusterDelivery", "other-delivery", metav1.ConditionTrue),
+					deliverable("Delivery", "my-new-delivery", metav1.ConditionTrue),
+				}, nil)
+
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(apiDelivery.Status.Rollout).To(Equal(&v1alpha1.RolloutStatus{Total: 2, Ready: 1, Failing: 1}))
+			})
+
+			It("reports the template each resource was resolved to, with its generation", func() {
+				template, err := templates.NewModelFromAPI(&v1alpha1.ClusterTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-final-template", Generation: 7},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				repo.GetDeliveryClusterTemplateReturnsOnCall(1, template, nil)
+
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				resources := apiDelivery.Status.Resources
+				Expect(resources).To(HaveLen(2))
+				Expect(resources[0].ResolvedTemplateRefs).To(BeEmpty())
+				Expect(resources[1].ResolvedTemplateRefs).To(Equal([]v1alpha1.ResolvedTemplateRef{
+					{Kind: "ClusterTemplate", Name: "my-final-template", Generation: 7},
+				}))
+			})
+
 			It("reschedules for 5 seconds", func() {
 				result, err := reconciler.Reconcile(ctx, req)
 				Expect(err).NotTo(HaveOccurred())
@@ -157,19 +206,44 @@ var _ = Describe("delivery reconciler", func() {
 				repo.GetDeliveryClusterTemplateReturnsOnCall(1, nil, errors.New("second-resource not found"))
 			})
 
-			It("returns an error without a requeue value", func() {
-				_, err := reconciler.Reconcile(ctx, req)
-				Expect(err).To(HaveOccurred())
+			It("requeues within the base delay of the backoff without an error", func() {
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", backoff.DefaultBaseDelay))
+				Expect(out).To(Say(`"msg":"templates not found"`))
+				Expect(out).To(Say(`"error":"encountered errors fetching resources: second-resource"`))
+			})
+
+			It("backs off further while the template is missing", func() {
+				repo.GetDeliveryClusterTemplateReturns(nil, errors.New("second-resource not found"))
+				for i := 0; i < 3; i++ {
+					_, _ = reconciler.Reconcile(ctx, req)
+				}
+				result, _ := reconciler.Reconcile(ctx, req)
+
+				Expect(result.RequeueAfter).To(BeNumerically(">=", 4*backoff.DefaultBaseDelay))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", 8*backoff.DefaultBaseDelay))
+			})
+
+			It("reschedules for 5 seconds once the template is found", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+				repo.GetDeliveryClusterTemplateReturnsOnCall(2, nil, nil)
+				repo.GetDeliveryClusterTemplateReturnsOnCall(3, nil, nil)
 
-				Expect(err).To(MatchError(ContainSubstring("encountered errors fetching resources: second-resource")))
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
 			})
 
 			It("Sets the status for TemplateNotFound", func() {
 				_, err := reconciler.Reconcile(ctx, req)
-				Expect(err).To(HaveOccurred())
+				Expect(err).NotTo(HaveOccurred())
 
 				Expect(repo.StatusUpdateCallCount()).To(Equal(1))
-				deliveryObject, ok := repo.StatusUpdateArgsForCall(0).(*v1alpha1.ClusterDelivery)
+				_, statusObject := repo.StatusUpdateArgsForCall(0)
+				deliveryObject, ok := statusObject.(*v1alpha1.ClusterDelivery)
 				Expect(ok).To(BeTrue())
 
 				Expect(deliveryObject.Status.Conditions).To(ContainElements(
@@ -192,15 +266,154 @@ var _ = Describe("delivery reconciler", func() {
 				))
 			})
 
+			It("reports, resource by resource, which templates were not found", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				_, statusObject := repo.StatusUpdateArgsForCall(0)
+				resources := statusObject.(*v1alpha1.ClusterDelivery).Status.Resources
+				Expect(resources).To(HaveLen(2))
+				Expect(resources[0].Resource).To(Equal("first-resource"))
+				Expect(resources[0].UnresolvedTemplateRefs).To(BeEmpty())
+				Expect(resources[0].Conditions).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal("TemplatesReady"),
+					"Status": Equal(metav1.ConditionTrue),
+					"Reason": Equal("Ready"),
+				})))
+				Expect(resources[1].Resource).To(Equal("second-resource"))
+				Expect(resources[1].UnresolvedTemplateRefs).To(Equal([]string{"ClusterTemplate/my-final-template"}))
+				Expect(resources[1].Conditions).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Type":    Equal("TemplatesReady"),
+					"Status":  Equal(metav1.ConditionFalse),
+					"Reason":  Equal("TemplatesNotFound"),
+					"Message": Equal("Did not find the template(s) 'ClusterTemplate/my-final-template'"),
+				})))
+			})
+
+			It("keeps the time the condition of each resource last changed", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+				previous := apiDelivery.Status.DeepCopy()
+				previous.Resources[1].Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+				apiDelivery.Status = *previous.DeepCopy()
+				repo.GetDeliveryClusterTemplateReturnsOnCall(2, nil, nil)
+				repo.GetDeliveryClusterTemplateReturnsOnCall(3, nil, errors.New("second-resource not found"))
+
+				_, _ = reconciler.Reconcile(ctx, req)
+				Expect(apiDelivery.Status.Resources).To(Equal(previous.Resources))
+			})
+
 			It("logs all GetTemplate errors encountered", func() {
 				_, err := reconciler.Reconcile(ctx, req)
-				Expect(err).To(HaveOccurred())
+				Expect(err).NotTo(HaveOccurred())
 
 				Expect(out).To(Say(`"msg":"retrieving cluster template"`))
 				Expect(out).To(Say(`"error":"second-resource not found"`))
 			})
 		})
 
+		Context("with a teardown policy", func() {
+			BeforeEach(func() {
+				apiDelivery.Spec.Teardown = v1alpha1.OrphanTeardownPolicy
+			})
+
+			It("adds the teardown finalizer", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.UpdateCallCount()).To(Equal(1))
+				_, updated := repo.UpdateArgsForCall(0)
+				Expect(updated.GetFinalizers()).To(ConsistOf("carto.run/teardown"))
+			})
+		})
+
+		Context("when the delivery is being deleted", func() {
+			var stamped *unstructured.Unstructured
+
+			BeforeEach(func() {
+				now := metav1.Now()
+				apiDelivery.DeletionTimestamp = &now
+				apiDelivery.Finalizers = []string{"carto.run/teardown"}
+				apiDelivery.Spec.Teardown = v1alpha1.OrphanTeardownPolicy
+
+				template, err := templates.NewModelFromAPI(&v1alpha1.ClusterTemplate{
+					Spec: v1alpha1.TemplateSpec{
+						Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap"}`)},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				repo.GetDeliveryClusterTemplateReturns(template, nil)
+
+				deliverable := &unstructured.Unstructured{}
+				deliverable.SetName("my-deliverable")
+				deliverable.SetNamespace("my-ns")
+				Expect(unstructured.SetNestedField(deliverable.Object, "ClusterDelivery", "status", "deliveryRef", "kind")).To(Succeed())
+				Expect(unstructured.SetNestedField(deliverable.Object, "my-new-delivery", "status", "deliveryRef", "name")).To(Succeed())
+				stamped = &unstructured.Unstructured{}
+				stamped.SetName("my-config")
+				stamped.SetLabels(map[string]string{
+					"carto.run/cluster-delivery-name": "my-new-delivery",
+					"carto.run/deliverable-name":      "my-deliverable",
+					"carto.run/deliverable-namespace": "my-ns",
+				})
+				repo.ListUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+					if obj.GetKind() == "Deliverable" {
+						return []*unstructured.Unstructured{deliverable}, nil
+					}
+					return []*unstructured.Unstructured{stamped}, nil
+				}
+			})
+
+			It("first announces the teardown, making the delivery not ready", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.UpdateCallCount()).To(Equal(0))
+				_, statusObject := repo.StatusUpdateArgsForCall(0)
+				conditions := statusObject.(*v1alpha1.ClusterDelivery).Status.Conditions
+				Expect(conditions).To(ContainElements(
+					MatchFields(IgnoreExtras, Fields{"Type": Equal("TornDown"), "Status": Equal(metav1.ConditionFalse), "Reason": Equal("TearingDown")}),
+					MatchFields(IgnoreExtras, Fields{"Type": Equal("Ready"), "Status": Equal(metav1.ConditionFalse)}),
+				))
+			})
+
+			Context("once the teardown has been announced", func() {
+				BeforeEach(func() {
+					apiDelivery.Status.Conditions = []metav1.Condition{teardown.StartingCondition("Deliverable")}
+				})
+
+				It("orphans the objects stamped for its deliverables", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+					Expect(repo.UpdateCallCount()).To(Equal(1))
+					_, updated := repo.UpdateArgsForCall(0)
+					Expect(updated.GetName()).To(Equal("my-config"))
+					Expect(updated.GetLabels()).NotTo(HaveKey("carto.run/cluster-delivery-name"))
+
+					_, statusObject := repo.StatusUpdateArgsForCall(0)
+					Expect(statusObject.(*v1alpha1.ClusterDelivery).Status.Conditions).To(ContainElement(
+						MatchFields(IgnoreExtras, Fields{"Type": Equal("TornDown"), "Message": Equal("1 objects stamped for 1 deliverables remaining")}),
+					))
+				})
+
+				Context("when nothing is left", func() {
+					BeforeEach(func() {
+						repo.ListUnstructuredStub = nil
+					})
+
+					It("removes the finalizer", func() {
+						result, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(Equal(ctrl.Result{}))
+
+						Expect(repo.UpdateCallCount()).To(Equal(1))
+						_, updated := repo.UpdateArgsForCall(0)
+						Expect(updated.GetFinalizers()).To(BeEmpty())
+					})
+				})
+			})
+		})
+
 		It("Starts and Finishes cleanly", func() {
 			_, err := reconciler.Reconcile(ctx, req)
 			Expect(err).NotTo(HaveOccurred())
@@ -254,4 +467,64 @@ var _ = Describe("delivery reconciler", func() {
 			Expect(result).To(Equal(ctrl.Result{Requeue: false}))
 		})
 	})
+
+	Context("with a namespaced Delivery", func() {
+		var apiDelivery *v1alpha1.Delivery
+
+		BeforeEach(func() {
+			apiDelivery = &v1alpha1.Delivery{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "my-new-delivery",
+					Namespace:  "my-namespace",
+					Generation: 3,
+				},
+				Spec: v1alpha1.ClusterDeliverySpec{
+					Resources: []v1alpha1.ClusterDeliveryResource{
+						{
+							Name: "first-resource",
+							TemplateRef: v1alpha1.DeliveryClusterTemplateReference{
+								Kind: "ClusterSourceTemplate",
+								Name: "my-source-template",
+							},
+						},
+					},
+				},
+			}
+			repo.GetNamespacedDeliveryReturns(apiDelivery, nil)
+
+			reconciler = delivery.NewNamespacedReconciler(repo)
+			req.Namespace = "my-namespace"
+		})
+
+		It("gets the Delivery from the request's namespace and updates its status", func() {
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(repo.GetDeliveryCallCount()).To(Equal(0))
+			_, name, namespace := repo.GetNamespacedDeliveryArgsForCall(0)
+			Expect(name).To(Equal("my-new-delivery"))
+			Expect(namespace).To(Equal("my-namespace"))
+
+			_, statusObject := repo.StatusUpdateArgsForCall(0)
+			deliveryObject, ok := statusObject.(*v1alpha1.Delivery)
+			Expect(ok).To(BeTrue())
+			Expect(deliveryObject.Status.ObservedGeneration).To(BeEquivalentTo(3))
+			Expect(deliveryObject.Status.Conditions).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal("Ready"),
+				"Status": Equal(metav1.ConditionTrue),
+			})))
+		})
+
+		Context("when the Delivery no longer exists", func() {
+			BeforeEach(func() {
+				repo.GetNamespacedDeliveryReturns(nil, nil)
+			})
+
+			It("does not return an error", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+			})
+		})
+	})
 })
@@ -0,0 +1,50 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package delivery
+
+import (
+	"context"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/rollout"
+)
+
+// countRollout counts, in the status of delivery, the deliverables realized
+// with it by how far they have converged on its current generation. When
+// the deliverables cannot be listed, the counts are left as they were.
+func (r *Reconciler) countRollout(ctx context.Context, delivery v1alpha1.DeliveryObject) {
+	deliverables, err := r.repo.ListDeliverables(ctx, delivery.GetNamespace())
+	if err != nil {
+		r.logger.Error(err, "count rollout")
+		return
+	}
+
+	var owners []rollout.Owner
+	for _, deliverable := range deliverables {
+		ref := deliverable.Status.DeliveryRef
+		if ref.Kind != r.kind || ref.Name != delivery.GetName() {
+			continue
+		}
+		owners = append(owners, rollout.Owner{
+			Generation:         deliverable.Generation,
+			ObservedGeneration: deliverable.Status.ObservedGeneration,
+			Conditions:         deliverable.Status.Conditions,
+			History:            deliverable.Status.History,
+		})
+	}
+
+	counts := rollout.Count(owners, delivery.GetName(), delivery.GetGeneration())
+	delivery.GetStatus().Rollout = &counts
+}
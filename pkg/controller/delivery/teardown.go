@@ -0,0 +1,147 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package delivery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/teardown"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// ensureTeardownFinalizer adds the teardown finalizer to a delivery
+// with a teardown policy, and removes it from one without.
+func (r *Reconciler) ensureTeardownFinalizer(ctx context.Context, delivery v1alpha1.DeliveryObject) error {
+	wanted := delivery.GetSpec().Teardown != ""
+	if wanted == controllerutil.ContainsFinalizer(delivery, v1alpha1.TeardownFinalizer) {
+		return nil
+	}
+
+	if wanted {
+		controllerutil.AddFinalizer(delivery, v1alpha1.TeardownFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(delivery, v1alpha1.TeardownFinalizer)
+	}
+	if err := r.repo.Update(ctx, delivery); err != nil {
+		return fmt.Errorf("update finalizer: %w", err)
+	}
+	return nil
+}
+
+// tearDown deletes or orphans the objects stamped for the deliverables of a
+// deleted delivery, then lets its deletion proceed. Deliverables only stop
+// stamping once they have read that the delivery is not ready, so
+// nothing is torn down before that has been recorded in its status.
+func (r *Reconciler) tearDown(ctx context.Context, delivery v1alpha1.DeliveryObject) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(delivery, v1alpha1.TeardownFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if meta.FindStatusCondition(delivery.GetStatus().Conditions, v1alpha1.BlueprintTornDown) == nil {
+		r.conditionManager.AddPositive(teardown.StartingCondition("Deliverable"))
+		return r.completeReconciliation(ctx, delivery, nil)
+	}
+
+	progress, err := r.runTeardown(ctx, delivery)
+	if err != nil {
+		r.conditionManager.AddPositive(teardown.FailedCondition(err))
+		return r.completeReconciliation(ctx, delivery, err)
+	}
+	if !progress.Complete() {
+		r.conditionManager.AddPositive(teardown.TearingDownCondition(progress, "Deliverable"))
+		return r.completeReconciliation(ctx, delivery, nil)
+	}
+
+	controllerutil.RemoveFinalizer(delivery, v1alpha1.TeardownFinalizer)
+	if err := r.repo.Update(ctx, delivery); err != nil {
+		return ctrl.Result{}, fmt.Errorf("remove finalizer: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *Reconciler) runTeardown(ctx context.Context, delivery v1alpha1.DeliveryObject) (teardown.Progress, error) {
+	stamping, err := r.templatesOf(ctx, delivery.GetSpec().Resources)
+	if err != nil {
+		return teardown.Progress{}, err
+	}
+
+	return teardown.Run(ctx, r.repo, r.teardownBlueprint(delivery, stamping))
+}
+
+// collectTrackedObjects deletes the objects stamped for resources with
+// Tracked ownership whose deliverables are gone. As the delivery is
+// reconciled periodically, so are they collected.
+func (r *Reconciler) collectTrackedObjects(ctx context.Context, delivery v1alpha1.DeliveryObject) {
+	var tracked []v1alpha1.ClusterDeliveryResource
+	for _, resource := range delivery.GetSpec().Resources {
+		if resource.Ownership == v1alpha1.TrackedOwnership {
+			tracked = append(tracked, resource)
+		}
+	}
+	if len(tracked) == 0 {
+		return
+	}
+
+	logger := logr.FromContextOrDiscard(ctx)
+	stamping, err := r.templatesOf(ctx, tracked)
+	if err == nil {
+		var collected int
+		collected, err = teardown.Collect(ctx, r.repo, r.teardownBlueprint(delivery, stamping))
+		if collected > 0 {
+			logger.Info("collected tracked objects", "count", collected)
+		}
+	}
+	if err != nil {
+		logger.Error(err, "collect tracked objects")
+	}
+}
+
+// templatesOf returns the templates of resources, leaving out those that
+// are not found.
+func (r *Reconciler) templatesOf(ctx context.Context, resources []v1alpha1.ClusterDeliveryResource) ([]templates.Template, error) {
+	var stamping []templates.Template
+	for _, resource := range resources {
+		template, err := r.repo.GetDeliveryClusterTemplate(ctx, resource.TemplateRef)
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get template of resource '%s': %w", resource.Name, err)
+		}
+		stamping = append(stamping, template)
+	}
+	return stamping, nil
+}
+
+func (r *Reconciler) teardownBlueprint(delivery v1alpha1.DeliveryObject, stamping []templates.Template) teardown.Blueprint {
+	return teardown.Blueprint{
+		Kind:          r.kind,
+		Namespace:     delivery.GetNamespace(),
+		Name:          delivery.GetName(),
+		Policy:        delivery.GetSpec().Teardown,
+		Label:         "carto.run/cluster-delivery-name",
+		OwnerKind:     "Deliverable",
+		OwnerRefField: "deliveryRef",
+		Templates:     stamping,
+	}
+}
@@ -30,11 +30,13 @@ import (
 	"github.com/vmware-tanzu/cartographer/pkg/conditions"
 	realizer "github.com/vmware-tanzu/cartographer/pkg/realizer/pipeline"
 	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/shutdown"
 )
 
 type Reconciler interface {
 	Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error)
 	AddTracking(dynamicTracker DynamicTracker)
+	AddCacheEviction(evicter CacheEvicter)
 }
 
 func NewReconciler(repository repository.Repository, realizer realizer.Realizer) Reconciler {
@@ -48,6 +50,7 @@ type reconciler struct {
 	repository     repository.Repository
 	realizer       realizer.Realizer
 	dynamicTracker DynamicTracker
+	cacheEvicter   CacheEvicter
 }
 
 //counterfeiter:generate . DynamicTracker
@@ -59,16 +62,30 @@ func (r *reconciler) AddTracking(dynamicTracker DynamicTracker) {
 	r.dynamicTracker = dynamicTracker
 }
 
+//counterfeiter:generate . CacheEvicter
+type CacheEvicter interface {
+	EvictOwned(ownerLabels map[string]string)
+}
+
+// AddCacheEviction has the objects stamped for a pipeline forgotten by the
+// evicter once the pipeline no longer exists.
+func (r *reconciler) AddCacheEviction(evicter CacheEvicter) {
+	r.cacheEvicter = evicter
+}
+
 func (r *reconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 	logger := logr.FromContext(ctx).
 		WithValues("name", request.Name, "namespace", request.Namespace)
 	logger.Info("started")
 	defer logger.Info("finished")
 
-	pipeline, err := r.repository.GetPipeline(request.Name, request.Namespace)
+	pipeline, err := r.repository.GetPipeline(ctx, request.Name, request.Namespace)
 
 	if kerrors.IsNotFound(err) {
 		logger.Info("pipeline no longer exists")
+		if r.cacheEvicter != nil {
+			r.cacheEvicter.EvictOwned(map[string]string{"carto.run/pipeline-name": request.Name})
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -90,7 +107,9 @@ func (r *reconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.
 	pipeline.Status.Conditions, _ = conditionManager.Finalize()
 	pipeline.Status.Outputs = outputs
 
-	statusUpdateError := r.repository.StatusUpdate(pipeline)
+	flushCtx, cancel := shutdown.FlushContext(ctx)
+	defer cancel()
+	statusUpdateError := r.repository.StatusUpdate(flushCtx, pipeline)
 	if statusUpdateError != nil {
 		return ctrl.Result{}, fmt.Errorf("update pipeline status: %w", statusUpdateError)
 	}
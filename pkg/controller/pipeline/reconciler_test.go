@@ -134,7 +134,7 @@ var _ = Describe("Reconcile", func() {
 				_, _ = reconciler.Reconcile(ctx, request)
 
 				Expect(repository.GetPipelineCallCount()).To(Equal(1))
-				actualName, actualNamespace := repository.GetPipelineArgsForCall(0)
+				_, actualName, actualNamespace := repository.GetPipelineArgsForCall(0)
 				Expect(actualName).To(Equal("my-pipeline"))
 				Expect(actualNamespace).To(Equal("my-namespace"))
 			})
@@ -152,7 +152,8 @@ var _ = Describe("Reconcile", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(repository.StatusUpdateCallCount()).To(Equal(1))
-				statusObject, ok := repository.StatusUpdateArgsForCall(0).(*v1alpha1.Pipeline)
+				_, updatedObject := repository.StatusUpdateArgsForCall(0)
+				statusObject, ok := updatedObject.(*v1alpha1.Pipeline)
 
 				Expect(ok).To(BeTrue())
 
@@ -191,7 +192,8 @@ var _ = Describe("Reconcile", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(repository.StatusUpdateCallCount()).To(Equal(1))
-				statusObject, ok := repository.StatusUpdateArgsForCall(0).(*v1alpha1.Pipeline)
+				_, updatedObject := repository.StatusUpdateArgsForCall(0)
+				statusObject, ok := updatedObject.(*v1alpha1.Pipeline)
 				Expect(ok).To(BeTrue())
 
 				Expect(statusObject.Status.Outputs).To(HaveLen(1))
@@ -259,6 +261,16 @@ var _ = Describe("Reconcile", func() {
 			Expect(result).To(Equal(controllerruntime.Result{}))
 		})
 
+		It("forgets the objects stamped for the pipeline", func() {
+			evicter := &pipelinefakes2.FakeCacheEvicter{}
+			reconciler.AddCacheEviction(evicter)
+
+			_, _ = reconciler.Reconcile(ctx, request)
+
+			Expect(evicter.EvictOwnedCallCount()).To(Equal(1))
+			Expect(evicter.EvictOwnedArgsForCall(0)).To(Equal(map[string]string{"carto.run/pipeline-name": "my-pipeline"}))
+		})
+
 		It("logs that we saw the pipeline go away", func() {
 			_, err := reconciler.Reconcile(ctx, request)
 			Expect(err).NotTo(HaveOccurred())
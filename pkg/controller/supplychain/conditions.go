@@ -44,3 +44,22 @@ func TemplatesFoundCondition() metav1.Condition {
 		Reason: v1alpha1.ReadyTemplatesReadyReason,
 	}
 }
+
+// -- Resource conditions
+
+func ResourceTemplatesNotFoundCondition(templateRefs []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.SupplyChainTemplatesReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.NotFoundTemplatesReadyReason,
+		Message: fmt.Sprintf("Did not find the template(s) '%s'", strings.Join(templateRefs, "', '")),
+	}
+}
+
+func ResourceTemplatesFoundCondition() metav1.Condition {
+	return metav1.Condition{
+		Type:   v1alpha1.SupplyChainTemplatesReady,
+		Status: metav1.ConditionTrue,
+		Reason: v1alpha1.ReadyTemplatesReadyReason,
+	}
+}
@@ -20,12 +20,17 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/backoff"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate"
 	"github.com/vmware-tanzu/cartographer/pkg/repository"
 )
 
@@ -39,12 +44,45 @@ type Reconciler struct {
 	repo                    repository.Repository
 	conditionManager        conditions.ConditionManager
 	conditionManagerBuilder conditions.ConditionManagerBuilder
+	kind                    string
+	getSupplyChain          func(ctx context.Context, req ctrl.Request) (v1alpha1.SupplyChainObject, error)
+	templateBackoff         *backoff.Backoff
+	templatesMissing        bool
+	resourcesChanged        bool
+	rolloutChanged          bool
 }
 
 func NewReconciler(repo repository.Repository, conditionManagerBuilder conditions.ConditionManagerBuilder) *Reconciler {
 	return &Reconciler{
 		repo:                    repo,
 		conditionManagerBuilder: conditionManagerBuilder,
+		kind:                    "ClusterSupplyChain",
+		templateBackoff:         backoff.New(backoff.DefaultBaseDelay, backoff.DefaultMaxDelay),
+		getSupplyChain: func(ctx context.Context, req ctrl.Request) (v1alpha1.SupplyChainObject, error) {
+			supplyChain, err := repo.GetSupplyChain(ctx, req.Name)
+			if err != nil || supplyChain == nil {
+				return nil, err
+			}
+			return supplyChain, nil
+		},
+	}
+}
+
+// NewNamespacedReconciler returns a Reconciler for SupplyChains rather than
+// ClusterSupplyChains.
+func NewNamespacedReconciler(repo repository.Repository, conditionManagerBuilder conditions.ConditionManagerBuilder) *Reconciler {
+	return &Reconciler{
+		repo:                    repo,
+		conditionManagerBuilder: conditionManagerBuilder,
+		kind:                    "SupplyChain",
+		templateBackoff:         backoff.New(backoff.DefaultBaseDelay, backoff.DefaultMaxDelay),
+		getSupplyChain: func(ctx context.Context, req ctrl.Request) (v1alpha1.SupplyChainObject, error) {
+			supplyChain, err := repo.GetNamespacedSupplyChain(ctx, req.Name, req.Namespace)
+			if err != nil || supplyChain == nil {
+				return nil, err
+			}
+			return supplyChain, nil
+		},
 	}
 }
 
@@ -55,7 +93,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	reconcileCtx := logr.NewContext(ctx, logger)
 
-	sc, err := r.repo.GetSupplyChain(req.Name)
+	sc, err := r.getSupplyChain(ctx, req)
 	if err != nil || sc == nil {
 		if kerrors.IsNotFound(err) {
 			return ctrl.Result{}, nil
@@ -65,25 +103,42 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// fixme: discuss DeepCopy() as a prophylactic
-	supplyChain := sc.DeepCopy()
+	supplyChain := sc.DeepCopyObject().(v1alpha1.SupplyChainObject)
+	ctx = gittemplate.WithBlueprint(ctx, supplyChain)
+	reconcileCtx = gittemplate.WithBlueprint(reconcileCtx, supplyChain)
+
+	r.conditionManager = r.conditionManagerBuilder(v1alpha1.SupplyChainReady, supplyChain.GetStatus().Conditions)
+
+	if !supplyChain.GetDeletionTimestamp().IsZero() {
+		return r.tearDown(reconcileCtx, supplyChain)
+	}
 
-	r.conditionManager = r.conditionManagerBuilder(v1alpha1.SupplyChainReady, supplyChain.Status.Conditions)
+	if err := r.ensureTeardownFinalizer(ctx, supplyChain); err != nil {
+		logger.Info("finished")
+		return ctrl.Result{}, err
+	}
 
-	err = r.reconcileSupplyChain(supplyChain)
+	r.templatesMissing = false
+	r.resourcesChanged = false
+	r.rolloutChanged = false
+	err = r.reconcileSupplyChain(ctx, supplyChain)
+	r.countRollout(reconcileCtx, supplyChain)
+	r.collectTrackedObjects(reconcileCtx, supplyChain)
 
 	return r.completeReconciliation(reconcileCtx, supplyChain, err)
 }
 
-func (r *Reconciler) completeReconciliation(ctx context.Context, supplyChain *v1alpha1.ClusterSupplyChain, err error) (ctrl.Result, error) {
+func (r *Reconciler) completeReconciliation(ctx context.Context, supplyChain v1alpha1.SupplyChainObject, err error) (ctrl.Result, error) {
 	logger := logr.FromContext(ctx)
+	status := supplyChain.GetStatus()
 
 	var changed bool
-	supplyChain.Status.Conditions, changed = r.conditionManager.Finalize()
+	status.Conditions, changed = r.conditionManager.Finalize()
 
 	var updateErr error
-	if changed || (supplyChain.Status.ObservedGeneration != supplyChain.Generation) {
-		supplyChain.Status.ObservedGeneration = supplyChain.Generation
-		updateErr = r.repo.StatusUpdate(supplyChain)
+	if changed || r.resourcesChanged || r.rolloutChanged || (status.ObservedGeneration != supplyChain.GetGeneration()) {
+		status.ObservedGeneration = supplyChain.GetGeneration()
+		updateErr = r.repo.StatusUpdate(ctx, supplyChain)
 		if updateErr != nil {
 			logger.Error(updateErr, "update error")
 			if err == nil {
@@ -94,6 +149,16 @@ func (r *Reconciler) completeReconciliation(ctx context.Context, supplyChain *v1
 	}
 
 	logger.Info("finished")
+	key := client.ObjectKeyFromObject(supplyChain)
+	if r.templatesMissing && updateErr == nil {
+		// templates are often created after the supply chains naming them:
+		// wait for them with a backoff rather than retrying right away.
+		delay := r.templateBackoff.Next(key)
+		logger.Info("templates not found", "error", err.Error(), "retry-after", delay.String())
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	r.templateBackoff.Reset(key)
+
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -101,23 +166,47 @@ func (r *Reconciler) completeReconciliation(ctx context.Context, supplyChain *v1
 	return ctrl.Result{RequeueAfter: reconcileInterval}, nil
 }
 
-func (r *Reconciler) reconcileSupplyChain(chain *v1alpha1.ClusterSupplyChain) error {
+func (r *Reconciler) reconcileSupplyChain(ctx context.Context, chain v1alpha1.SupplyChainObject) error {
 	var (
-		resourceHandlingError, err error
-		resourcesNotFound          []string
+		resourceHandlingError error
+		resourcesNotFound     []string
+		resourceStatuses      []v1alpha1.ResourceTemplatesStatus
 	)
 
-	for _, resource := range chain.Spec.Resources {
-		_, err = r.repo.GetClusterTemplate(resource.TemplateRef)
-		if err != nil {
-			resourcesNotFound = append(resourcesNotFound, resource.Name)
-			if resourceHandlingError == nil {
-				resourceHandlingError = fmt.Errorf("handle resource: %w", err)
+	status := chain.GetStatus()
+	for _, resource := range chain.GetSpec().Resources {
+		var (
+			resolved   []v1alpha1.ResolvedTemplateRef
+			unresolved []string
+		)
+		for _, templateRef := range resource.TemplateRef.Choices() {
+			template, err := r.repo.GetClusterTemplate(ctx, templateRef)
+			if err != nil {
+				unresolved = append(unresolved, templateRef.Kind+"/"+templateRef.DisplayName())
+				if resourceHandlingError == nil {
+					resourceHandlingError = fmt.Errorf("handle resource: %w", err)
+				}
+				continue
+			}
+			if template != nil {
+				resolved = append(resolved, v1alpha1.ResolvedTemplateRef{
+					Kind:       templateRef.Kind,
+					Name:       template.GetName(),
+					Generation: template.GetGeneration(),
+				})
 			}
 		}
+		if len(unresolved) > 0 {
+			resourcesNotFound = append(resourcesNotFound, resource.Name)
+		}
+		resourceStatuses = append(resourceStatuses, resourceTemplatesStatus(status.Resources, resource.Name, resolved, unresolved))
 	}
 
+	r.resourcesChanged = !equality.Semantic.DeepEqual(status.Resources, resourceStatuses)
+	status.Resources = resourceStatuses
+
 	if resourceHandlingError != nil {
+		r.templatesMissing = true
 		r.conditionManager.AddPositive(TemplatesNotFoundCondition(resourcesNotFound))
 	} else {
 		r.conditionManager.AddPositive(TemplatesFoundCondition())
@@ -125,3 +214,21 @@ func (r *Reconciler) reconcileSupplyChain(chain *v1alpha1.ClusterSupplyChain) er
 
 	return resourceHandlingError
 }
+
+// resourceTemplatesStatus returns the status of the resource named name,
+// keeping the time its TemplatesReady condition last changed in previous.
+func resourceTemplatesStatus(previous []v1alpha1.ResourceTemplatesStatus, name string, resolved []v1alpha1.ResolvedTemplateRef, unresolved []string) v1alpha1.ResourceTemplatesStatus {
+	status := v1alpha1.ResourceTemplatesStatus{Resource: name, ResolvedTemplateRefs: resolved, UnresolvedTemplateRefs: unresolved}
+	for _, p := range previous {
+		if p.Resource == name {
+			status.Conditions = append(status.Conditions, p.Conditions...)
+		}
+	}
+
+	condition := ResourceTemplatesFoundCondition()
+	if len(unresolved) > 0 {
+		condition = ResourceTemplatesNotFoundCondition(unresolved)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+	return status
+}
@@ -26,6 +26,7 @@ import (
 	. "github.com/onsi/gomega/gstruct"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -33,11 +34,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/backoff"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions/conditionsfakes"
 	"github.com/vmware-tanzu/cartographer/pkg/controller/supplychain"
 	"github.com/vmware-tanzu/cartographer/pkg/registrar"
 	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/teardown"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
 )
 
 var _ = Describe("Reconciler", func() {
@@ -142,7 +146,7 @@ var _ = Describe("Reconciler", func() {
 		It("updates the status.observedGeneration to equal metadata.generation", func() {
 			_, _ = reconciler.Reconcile(ctx, req)
 
-			updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+			_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
 
 			Expect(*updatedSupplyChain.(*v1alpha1.ClusterSupplyChain)).To(MatchFields(IgnoreExtras, Fields{
 				"Status": MatchFields(IgnoreExtras, Fields{
@@ -154,7 +158,7 @@ var _ = Describe("Reconciler", func() {
 		It("updates the conditions based on the output of the conditionManager", func() {
 			_, _ = reconciler.Reconcile(ctx, req)
 
-			updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+			_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
 
 			Expect(*updatedSupplyChain.(*v1alpha1.ClusterSupplyChain)).To(MatchFields(IgnoreExtras, Fields{
 				"Status": MatchFields(IgnoreExtras, Fields{
@@ -163,6 +167,138 @@ var _ = Describe("Reconciler", func() {
 			}))
 		})
 
+		It("reports the template each resource was resolved to, with its generation", func() {
+			template, err := templates.NewModelFromAPI(&v1alpha1.ClusterConfigTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "another-name", Generation: 2},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			repo.GetClusterTemplateReturnsOnCall(1, template, nil)
+
+			_, _ = reconciler.Reconcile(ctx, req)
+
+			_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+			resources := updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.Resources
+			Expect(resources).To(HaveLen(2))
+			Expect(resources[0].ResolvedTemplateRefs).To(BeEmpty())
+			Expect(resources[1].ResolvedTemplateRefs).To(Equal([]v1alpha1.ResolvedTemplateRef{
+				{Kind: "another-kind", Name: "another-name", Generation: 2},
+			}))
+		})
+
+		Context("when workloads are realized with the supply chain", func() {
+			workload := func(name string, kind string, supplyChain string, ready metav1.ConditionStatus, blueprintGeneration int64) v1alpha1.Workload {
+				return v1alpha1.Workload{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Generation: 1},
+					Status: v1alpha1.WorkloadStatus{
+						ObservedGeneration: 1,
+						Conditions:         []metav1.Condition{{Type: "Ready", Status: ready}},
+						SupplyChainRef:     v1alpha1.ObjectReference{Kind: kind, Name: supplyChain},
+						History: []v1alpha1.RealizationRecord{
+							{Generation: 1, Blueprint: supplyChain, BlueprintGeneration: blueprintGeneration},
+						},
+					},
+				}
+			}
+
+			BeforeEach(func() {
+				sc.Name = "my-supply-chain"
+				repo.ListWorkloadsReturns([]v1alpha1.Workload{
+					workload("ready", "ClusterSupplyChain", "my-supply-chain", metav1.ConditionTrue, 1),
+					workload("failing", "ClusterSupplyChain", "my-supply-chain", metav1.ConditionFalse, 1),
+					workload("stale", "ClusterSupplyChain", "my-supply-chain", metav1.ConditionTrue, 0),
+					workload("other-chain", "ClusterSupplyChain", "other-supply-chain", metav1.ConditionTrue, 1),
+					workload("namespaced-chain", "SupplyChain", "my-supply-chain", metav1.ConditionTrue, 1),
+				}, nil)
+			})
+
+			It("counts the workloads realized with it by how far they have converged", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				_, namespace := repo.ListWorkloadsArgsForCall(0)
+				Expect(namespace).To(BeEmpty())
+
+				_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+				Expect(updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.Rollout).To(Equal(&v1alpha1.RolloutStatus{
+					Total:   3,
+					Ready:   1,
+					Failing: 1,
+					Stale:   1,
+				}))
+			})
+
+			It("updates the status when only the counts changed", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+				_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+				sc.Status = *updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.DeepCopy()
+				sc.Status.ObservedGeneration = 1
+				conditionManager.FinalizeReturns(expectedConditions, false)
+
+				_, _ = reconciler.Reconcile(ctx, req)
+				Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+
+				repo.ListWorkloadsReturns(nil, nil)
+				_, _ = reconciler.Reconcile(ctx, req)
+				Expect(repo.StatusUpdateCallCount()).To(Equal(2))
+			})
+
+			Context("when the workloads cannot be listed", func() {
+				It("keeps the counts as they were", func() {
+					sc.Status.Rollout = &v1alpha1.RolloutStatus{Total: 4, Ready: 4}
+					repo.ListWorkloadsReturns(nil, errors.New("list failed"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+					Expect(updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.Rollout).To(Equal(&v1alpha1.RolloutStatus{Total: 4, Ready: 4}))
+				})
+			})
+		})
+
+		Context("when a resource has options", func() {
+			BeforeEach(func() {
+				sc.Spec.Resources[1].TemplateRef = v1alpha1.ClusterTemplateReference{
+					Kind: "ClusterConfigTemplate",
+					Options: []v1alpha1.TemplateOption{
+						{Name: "java-config"},
+						{Name: "go-config"},
+					},
+				}
+				for i, name := range []string{"java-config", "go-config"} {
+					template, err := templates.NewModelFromAPI(&v1alpha1.ClusterConfigTemplate{
+						ObjectMeta: metav1.ObjectMeta{Name: name, Generation: int64(i + 1)},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					repo.GetClusterTemplateReturnsOnCall(i+1, template, nil)
+				}
+			})
+
+			It("reports the template of each option", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+				resources := updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.Resources
+				Expect(resources[1].ResolvedTemplateRefs).To(Equal([]v1alpha1.ResolvedTemplateRef{
+					{Kind: "ClusterConfigTemplate", Name: "java-config", Generation: 1},
+					{Kind: "ClusterConfigTemplate", Name: "go-config", Generation: 2},
+				}))
+			})
+		})
+
+		It("updates the status when only the status of its resources changed", func() {
+			conditionManager.FinalizeReturns(expectedConditions, false)
+			sc.Status.ObservedGeneration = 1
+
+			_, _ = reconciler.Reconcile(ctx, req)
+			Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+
+			_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+			sc.Status = *updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.DeepCopy()
+
+			_, _ = reconciler.Reconcile(ctx, req)
+			Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+		})
+
 		It("adds a positive templates found condition", func() {
 			_, _ = reconciler.Reconcile(ctx, req)
 
@@ -192,16 +328,80 @@ var _ = Describe("Reconciler", func() {
 				Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(supplychain.TemplatesNotFoundCondition([]string{"second name"})))
 			})
 
-			It("returns an error", func() {
+			It("does not return an error", func() {
 				_, err := reconciler.Reconcile(ctx, req)
-				Expect(err).To(MatchError(ContainSubstring("handle resource")))
-				Expect(err).To(MatchError(ContainSubstring("getting templates is hard")))
+				Expect(err).NotTo(HaveOccurred())
 			})
 
-			It("does not requeue", func() {
+			It("logs the error", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+				Expect(out).To(Say(`"msg":"templates not found"`))
+				Expect(out).To(Say(`"error":"handle resource: getting templates is hard"`))
+			})
+
+			It("reports, resource by resource, which templates were not found", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+				resources := updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.Resources
+				Expect(resources).To(HaveLen(2))
+				Expect(resources[0].Resource).To(Equal("first name"))
+				Expect(resources[0].UnresolvedTemplateRefs).To(BeEmpty())
+				Expect(resources[0].Conditions).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(v1alpha1.SupplyChainTemplatesReady),
+					"Status": Equal(metav1.ConditionTrue),
+					"Reason": Equal(v1alpha1.ReadyTemplatesReadyReason),
+				})))
+				Expect(resources[1].Resource).To(Equal("second name"))
+				Expect(resources[1].UnresolvedTemplateRefs).To(Equal([]string{"another-kind/another-name"}))
+				Expect(resources[1].Conditions).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Type":    Equal(v1alpha1.SupplyChainTemplatesReady),
+					"Status":  Equal(metav1.ConditionFalse),
+					"Reason":  Equal(v1alpha1.NotFoundTemplatesReadyReason),
+					"Message": Equal("Did not find the template(s) 'another-kind/another-name'"),
+				})))
+			})
+
+			It("keeps the time the condition of each resource last changed", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+				_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+				sc.Status = *updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.DeepCopy()
+				sc.Status.Resources[1].Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+				repo.GetClusterTemplateReturnsOnCall(2, nil, nil)
+				repo.GetClusterTemplateReturnsOnCall(3, nil, errors.New("getting templates is hard"))
+
+				_, _ = reconciler.Reconcile(ctx, req)
+				_, updatedSupplyChain = repo.StatusUpdateArgsForCall(1)
+				Expect(updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.Resources).To(Equal(sc.Status.Resources))
+			})
+
+			It("requeues within the base delay of the backoff", func() {
 				result, _ := reconciler.Reconcile(ctx, req)
 
-				Expect(result).To(Equal(ctrl.Result{Requeue: false}))
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", backoff.DefaultBaseDelay))
+			})
+
+			It("backs off further while the templates are missing", func() {
+				for i := 0; i < 3; i++ {
+					_, _ = reconciler.Reconcile(ctx, req)
+					repo.GetClusterTemplateReturnsOnCall(2*(i+1), nil, nil)
+					repo.GetClusterTemplateReturnsOnCall(2*(i+1)+1, nil, errors.New("getting templates is hard"))
+				}
+				result, _ := reconciler.Reconcile(ctx, req)
+
+				Expect(result.RequeueAfter).To(BeNumerically(">=", 4*backoff.DefaultBaseDelay))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", 8*backoff.DefaultBaseDelay))
+			})
+
+			It("reschedules for 5 seconds once the templates are found", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+				repo.GetClusterTemplateReturnsOnCall(2, nil, nil)
+				repo.GetClusterTemplateReturnsOnCall(3, nil, nil)
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
 			})
 
 			Context("when retrieving multiple resource templates fails", func() {
@@ -214,17 +414,50 @@ var _ = Describe("Reconciler", func() {
 					Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(supplychain.TemplatesNotFoundCondition([]string{"first name", "second name"})))
 				})
 
-				It("returns an error", func() {
-					_, err := reconciler.Reconcile(ctx, req)
-					Expect(err).To(MatchError(ContainSubstring("handle resource")))
-					Expect(err).To(MatchError(ContainSubstring("first error is all that matters")))
-					Expect(err).NotTo(MatchError(ContainSubstring("getting templates is hard")))
+				It("logs the first error", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(out).To(Say(`"error":"handle resource: first error is all that matters"`))
 				})
+			})
+		})
 
-				It("does not requeue", func() {
-					result, _ := reconciler.Reconcile(ctx, req)
+		Context("when a resource chooses its template by option", func() {
+			BeforeEach(func() {
+				sc.Spec.Resources[1].TemplateRef = v1alpha1.ClusterTemplateReference{
+					Kind: "another-kind",
+					Options: []v1alpha1.TemplateOption{
+						{Name: "java-name"},
+						{Name: "go-name"},
+					},
+				}
+			})
 
-					Expect(result).To(Equal(ctrl.Result{Requeue: false}))
+			It("looks for the template of every option", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(repo.GetClusterTemplateCallCount()).To(Equal(3))
+				_, templateRef := repo.GetClusterTemplateArgsForCall(1)
+				Expect(templateRef).To(Equal(v1alpha1.ClusterTemplateReference{Kind: "another-kind", Name: "java-name"}))
+				_, templateRef = repo.GetClusterTemplateArgsForCall(2)
+				Expect(templateRef).To(Equal(v1alpha1.ClusterTemplateReference{Kind: "another-kind", Name: "go-name"}))
+			})
+
+			Context("when an option's template is not found", func() {
+				BeforeEach(func() {
+					repo.GetClusterTemplateReturnsOnCall(2, nil, errors.New("getting templates is hard"))
+				})
+
+				It("adds a positive templates NOT found condition listing the resource", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(supplychain.TemplatesNotFoundCondition([]string{"second name"})))
+				})
+
+				It("lists the option's template as unresolved", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+					resources := updatedSupplyChain.(*v1alpha1.ClusterSupplyChain).Status.Resources
+					Expect(resources[1].UnresolvedTemplateRefs).To(Equal([]string{"another-kind/go-name"}))
 				})
 			})
 		})
@@ -272,6 +505,228 @@ var _ = Describe("Reconciler", func() {
 			})
 		})
 
+		It("does not add the teardown finalizer", func() {
+			_, _ = reconciler.Reconcile(ctx, req)
+
+			Expect(repo.UpdateCallCount()).To(Equal(0))
+		})
+
+		Context("when the supply chain has a teardown policy", func() {
+			BeforeEach(func() {
+				sc.Spec.Teardown = v1alpha1.DeleteTeardownPolicy
+			})
+
+			It("adds the teardown finalizer", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.UpdateCallCount()).To(Equal(1))
+				_, updated := repo.UpdateArgsForCall(0)
+				Expect(updated.GetFinalizers()).To(ConsistOf("carto.run/teardown"))
+			})
+
+			Context("when adding the finalizer fails", func() {
+				BeforeEach(func() {
+					repo.UpdateReturns(errors.New("updating is hard"))
+				})
+
+				It("returns a helpful error without reconciling", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("update finalizer: updating is hard"))
+					Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the teardown policy has been removed", func() {
+			BeforeEach(func() {
+				sc.Finalizers = []string{"carto.run/teardown", "other"}
+			})
+
+			It("removes the teardown finalizer", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(repo.UpdateCallCount()).To(Equal(1))
+				_, updated := repo.UpdateArgsForCall(0)
+				Expect(updated.GetFinalizers()).To(ConsistOf("other"))
+			})
+		})
+
+		It("collects nothing when no resource is tracked", func() {
+			_, _ = reconciler.Reconcile(ctx, req)
+
+			Expect(repo.ListUnstructuredCallCount()).To(Equal(0))
+			Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+		})
+
+		Context("when a resource has tracked ownership", func() {
+			BeforeEach(func() {
+				sc.Name = "my-supply-chain"
+				sc.Spec.Resources[1].Ownership = v1alpha1.TrackedOwnership
+
+				template, err := templates.NewModelFromAPI(&v1alpha1.ClusterTemplate{
+					Spec: v1alpha1.TemplateSpec{
+						Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "Namespace"}`)},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				repo.GetClusterTemplateReturns(template, nil)
+
+				stamped := &unstructured.Unstructured{}
+				stamped.SetName("team-namespace")
+				stamped.SetLabels(map[string]string{
+					"carto.run/workload-name":      "deleted-workload",
+					"carto.run/workload-namespace": "my-ns",
+				})
+				stamped.SetAnnotations(map[string]string{v1alpha1.TrackedOwnerAnnotation: "deleted-uid"})
+				repo.ListUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+					if obj.GetKind() == "Workload" {
+						return nil, nil
+					}
+					return []*unstructured.Unstructured{stamped}, nil
+				}
+			})
+
+			It("deletes the tracked objects of workloads that are gone", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.DeleteUnstructuredCallCount()).To(Equal(1))
+				_, deleted := repo.DeleteUnstructuredArgsForCall(0)
+				Expect(deleted.GetName()).To(Equal("team-namespace"))
+				Expect(out).To(Say(`"msg":"collected tracked objects","name":"my-supply-chain","namespace":"my-namespace","count":1`))
+			})
+
+			Context("when collecting fails", func() {
+				BeforeEach(func() {
+					repo.DeleteUnstructuredReturns(errors.New("deleting is hard"))
+				})
+
+				It("logs the error without failing the reconcile", func() {
+					result, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
+					Expect(out).To(Say(`"msg":"collect tracked objects".*deleting is hard`))
+				})
+			})
+		})
+
+		Context("when the supply chain is being deleted", func() {
+			BeforeEach(func() {
+				now := metav1.Now()
+				sc.Name = "my-supply-chain"
+				sc.DeletionTimestamp = &now
+				sc.Finalizers = []string{"carto.run/teardown"}
+				sc.Spec.Teardown = v1alpha1.DeleteTeardownPolicy
+
+				template, err := templates.NewModelFromAPI(&v1alpha1.ClusterConfigTemplate{
+					Spec: v1alpha1.ConfigTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap"}`)},
+						},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				repo.GetClusterTemplateReturns(template, nil)
+
+				workload := &unstructured.Unstructured{}
+				workload.SetName("my-workload")
+				workload.SetNamespace("my-ns")
+				Expect(unstructured.SetNestedField(workload.Object, "ClusterSupplyChain", "status", "supplyChainRef", "kind")).To(Succeed())
+				Expect(unstructured.SetNestedField(workload.Object, "my-supply-chain", "status", "supplyChainRef", "name")).To(Succeed())
+				stamped := &unstructured.Unstructured{}
+				stamped.SetName("my-config")
+				stamped.SetLabels(map[string]string{
+					"carto.run/workload-name":      "my-workload",
+					"carto.run/workload-namespace": "my-ns",
+				})
+				repo.ListUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+					if obj.GetKind() == "Workload" {
+						return []*unstructured.Unstructured{workload}, nil
+					}
+					return []*unstructured.Unstructured{stamped}, nil
+				}
+			})
+
+			It("first announces the teardown, without tearing anything down", func() {
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
+
+				Expect(conditionManager.AddPositiveCallCount()).To(Equal(1))
+				Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(teardown.StartingCondition("Workload")))
+				Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				Expect(repo.ListUnstructuredCallCount()).To(Equal(0))
+				Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+			})
+
+			Context("once the teardown has been announced", func() {
+				BeforeEach(func() {
+					sc.Status.Conditions = []metav1.Condition{teardown.StartingCondition("Workload")}
+				})
+
+				It("tears down the objects stamped for its workloads and reports progress", func() {
+					result, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
+
+					Expect(repo.DeleteUnstructuredCallCount()).To(Equal(1))
+					_, deleted := repo.DeleteUnstructuredArgsForCall(0)
+					Expect(deleted.GetName()).To(Equal("my-config"))
+
+					Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(teardown.TearingDownCondition(teardown.Progress{Owners: 1, Remaining: 1}, "Workload")))
+					Expect(conditionManager.AddPositiveArgsForCall(0).Message).To(Equal("1 objects stamped for 1 workloads remaining"))
+					Expect(repo.UpdateCallCount()).To(Equal(0))
+				})
+
+				Context("when tearing down fails", func() {
+					BeforeEach(func() {
+						repo.DeleteUnstructuredReturns(errors.New("deleting is hard"))
+					})
+
+					It("reports and returns the error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).To(MatchError(ContainSubstring("deleting is hard")))
+
+						Expect(conditionManager.AddPositiveArgsForCall(0).Reason).To(Equal("TeardownFailed"))
+						Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+					})
+				})
+
+				Context("when nothing is left", func() {
+					BeforeEach(func() {
+						repo.ListUnstructuredStub = nil
+					})
+
+					It("removes the finalizer", func() {
+						result, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(Equal(ctrl.Result{}))
+
+						Expect(repo.UpdateCallCount()).To(Equal(1))
+						_, updated := repo.UpdateArgsForCall(0)
+						Expect(updated.GetFinalizers()).To(BeEmpty())
+						Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+					})
+				})
+			})
+
+			Context("without the teardown finalizer", func() {
+				BeforeEach(func() {
+					sc.Finalizers = nil
+				})
+
+				It("does nothing", func() {
+					result, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(ctrl.Result{}))
+
+					Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+					Expect(repo.UpdateCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		Context("when the supply chain has been deleted from the apiServer", func() {
 			BeforeEach(func() {
 				repo.GetSupplyChainReturns(nil, kerrors.NewNotFound(schema.GroupResource{
@@ -312,5 +767,61 @@ var _ = Describe("Reconciler", func() {
 				Expect(result).To(Equal(ctrl.Result{Requeue: false}))
 			})
 		})
+
+		Context("when reconciling namespaced SupplyChains", func() {
+			var namespaced *v1alpha1.SupplyChain
+
+			BeforeEach(func() {
+				namespaced = &v1alpha1.SupplyChain{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "my-supply-chain",
+						Namespace:  "my-namespace",
+						Generation: 2,
+					},
+					Spec: sc.Spec,
+				}
+				repo.GetNamespacedSupplyChainReturns(namespaced, nil)
+
+				reconciler = supplychain.NewNamespacedReconciler(repo, func(string, []metav1.Condition) conditions.ConditionManager {
+					return conditionManager
+				})
+			})
+
+			It("gets the SupplyChain from the request's namespace", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.GetSupplyChainCallCount()).To(Equal(0))
+				_, name, namespace := repo.GetNamespacedSupplyChainArgsForCall(0)
+				Expect(name).To(Equal("my-supply-chain"))
+				Expect(namespace).To(Equal("my-namespace"))
+			})
+
+			It("updates the status of the SupplyChain", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				_, updatedSupplyChain := repo.StatusUpdateArgsForCall(0)
+				Expect(updatedSupplyChain.(*v1alpha1.SupplyChain).Status).To(MatchFields(IgnoreExtras, Fields{
+					"Conditions":         Equal(expectedConditions),
+					"ObservedGeneration": BeEquivalentTo(2),
+					"Resources":          HaveLen(2),
+				}))
+			})
+
+			Context("when the SupplyChain no longer exists", func() {
+				BeforeEach(func() {
+					repo.GetNamespacedSupplyChainReturns(nil, kerrors.NewNotFound(schema.GroupResource{
+						Group:    "carto.run",
+						Resource: "SupplyChain",
+					}, "my-supply-chain"))
+				})
+
+				It("does not return an error", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+				})
+			})
+		})
 	})
 })
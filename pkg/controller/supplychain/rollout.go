@@ -0,0 +1,54 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supplychain
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/rollout"
+)
+
+// countRollout counts, in the status of supplyChain, the workloads realized
+// with it by how far they have converged on its current generation. When
+// the workloads cannot be listed, the counts are left as they were.
+func (r *Reconciler) countRollout(ctx context.Context, supplyChain v1alpha1.SupplyChainObject) {
+	workloads, err := r.repo.ListWorkloads(ctx, supplyChain.GetNamespace())
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "count rollout")
+		return
+	}
+
+	var owners []rollout.Owner
+	for _, workload := range workloads {
+		ref := workload.Status.SupplyChainRef
+		if ref.Kind != r.kind || ref.Name != supplyChain.GetName() {
+			continue
+		}
+		owners = append(owners, rollout.Owner{
+			Generation:         workload.Generation,
+			ObservedGeneration: workload.Status.ObservedGeneration,
+			Conditions:         workload.Status.Conditions,
+			History:            workload.Status.History,
+		})
+	}
+
+	counts := rollout.Count(owners, supplyChain.GetName(), supplyChain.GetGeneration())
+	status := supplyChain.GetStatus()
+	r.rolloutChanged = status.Rollout == nil || *status.Rollout != counts
+	status.Rollout = &counts
+}
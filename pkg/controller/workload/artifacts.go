@@ -0,0 +1,48 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+//counterfeiter:generate . ArtifactRecorder
+type ArtifactRecorder interface {
+	Record(ctx context.Context, workload *v1alpha1.Workload, supplyChainName string, outputs map[string]*templates.Output) error
+}
+
+// AddArtifactRecording lets the reconciler record the artifacts realized
+// for workloads, such as image digests and source revisions, in an
+// external metadata store.
+func (r *Reconciler) AddArtifactRecording(recorder ArtifactRecorder) {
+	r.artifactRecorder = recorder
+}
+
+// recordArtifacts records the outputs of a realization that completed. A
+// store that cannot be reached does not hold the workload back: the failure
+// is logged, and the artifacts are recorded on a later reconcile.
+func (r *Reconciler) recordArtifacts(ctx context.Context, workload *v1alpha1.Workload, supplyChainName string, outputs map[string]*templates.Output) {
+	if r.artifactRecorder == nil {
+		return
+	}
+	if err := r.artifactRecorder.Record(ctx, workload, supplyChainName, outputs); err != nil {
+		logr.FromContext(ctx).Error(err, "record artifacts")
+	}
+}
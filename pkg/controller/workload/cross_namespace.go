@@ -0,0 +1,217 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+//counterfeiter:generate . DynamicTracker
+type DynamicTracker interface {
+	Watch(log logr.Logger, obj runtime.Object, handler handler.EventHandler) error
+}
+
+// AddTracking lets the reconciler watch the objects it stamps, including
+// those in other namespaces, which cannot be watched through an owner
+// reference, so that changes made to them are remediated or detected as
+// they happen.
+func (r *Reconciler) AddTracking(dynamicTracker DynamicTracker) {
+	r.dynamicTracker = dynamicTracker
+}
+
+// ensureCleanupFinalizer adds the cross-namespace cleanup finalizer to a
+// workload whose supply chain stamps into other namespaces, before anything
+// is stamped there.
+func (r *Reconciler) ensureCleanupFinalizer(ctx context.Context, workload *v1alpha1.Workload, supplyChain v1alpha1.SupplyChainObject) error {
+	if !supplyChain.GetSpec().StampsAcrossNamespaces(workload.Namespace) ||
+		controllerutil.ContainsFinalizer(workload, v1alpha1.CrossNamespaceCleanupFinalizer) {
+		return nil
+	}
+
+	controllerutil.AddFinalizer(workload, v1alpha1.CrossNamespaceCleanupFinalizer)
+	if err := r.repo.Update(ctx, workload); err != nil {
+		return fmt.Errorf("add finalizer: %w", err)
+	}
+	return nil
+}
+
+// finalize runs the pre-delete hooks of a deleted workload, then deletes the
+// objects stamped for it in other namespaces, then lets its deletion
+// proceed.
+func (r *Reconciler) finalize(ctx context.Context, workload *v1alpha1.Workload) (ctrl.Result, error) {
+	if controllerutil.ContainsFinalizer(workload, v1alpha1.PreDeleteFinalizer) {
+		complete, err := r.runPreDeleteHooks(ctx, workload)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !complete {
+			return ctrl.Result{RequeueAfter: r.resyncIntervalOf(ctx, workload)}, nil
+		}
+	}
+
+	if !controllerutil.ContainsFinalizer(workload, v1alpha1.CrossNamespaceCleanupFinalizer) &&
+		!controllerutil.ContainsFinalizer(workload, v1alpha1.PreDeleteFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if controllerutil.ContainsFinalizer(workload, v1alpha1.CrossNamespaceCleanupFinalizer) {
+		for _, ref := range workload.Status.CrossNamespaceObjects {
+			if err := r.deleteStampedObject(ctx, workload, ref); err != nil {
+				return ctrl.Result{}, fmt.Errorf("delete cross-namespace object %s '%s/%s': %w", ref.Kind, ref.Namespace, ref.Name, err)
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(workload, v1alpha1.PreDeleteFinalizer)
+	controllerutil.RemoveFinalizer(workload, v1alpha1.CrossNamespaceCleanupFinalizer)
+	if err := r.repo.Update(ctx, workload); err != nil {
+		return ctrl.Result{}, fmt.Errorf("remove finalizer: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// pruneCrossNamespaceObjects deletes the objects recorded as stamped into
+// other namespaces by a previous reconcile that this one, having realized
+// every resource, no longer stamped. After a failed realization, every
+// previously recorded object is kept, as the resources that stamped them may
+// not have been reached. Objects this reconcile stamped without recording
+// them, as their resources now orphan them, are left alone.
+func (r *Reconciler) pruneCrossNamespaceObjects(ctx context.Context, workload *v1alpha1.Workload, previous, stamped []v1alpha1.ObjectReference, realizeErr error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	for _, ref := range previous {
+		if containsRef(workload.Status.CrossNamespaceObjects, ref) || containsRef(stamped, ref) {
+			continue
+		}
+		if realizeErr == nil {
+			err := r.deleteStampedObject(ctx, workload, ref)
+			if err == nil {
+				continue
+			}
+			logger.Error(err, "delete stale cross-namespace object", "kind", ref.Kind, "namespace", ref.Namespace, "name", ref.Name)
+		}
+		workload.Status.CrossNamespaceObjects = append(workload.Status.CrossNamespaceObjects, ref)
+	}
+
+	r.crossNamespaceObjectsChanged = !sameRefs(previous, workload.Status.CrossNamespaceObjects)
+}
+
+// deleteStampedObject deletes the object ref records as stamped for the
+// workload outside its namespace, unless it is gone or no longer carries the
+// workload's labels: an object of the same name that someone else created,
+// or that an earlier workload of the same name stamped, is left alone.
+func (r *Reconciler) deleteStampedObject(ctx context.Context, workload *v1alpha1.Workload, ref v1alpha1.ObjectReference) error {
+	obj := unstructuredFor(ref)
+	err := r.repo.GetUnstructured(ctx, obj)
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !v1alpha1.StampedFor(obj, workload) {
+		logr.FromContextOrDiscard(ctx).Info("leaving cross-namespace object not stamped for the workload", "kind", ref.Kind, "namespace", ref.Namespace, "name", ref.Name)
+		return nil
+	}
+	return r.repo.DeleteUnstructured(ctx, obj)
+}
+
+// trackCrossNamespaceObjects watches the objects stamped for the workload in
+// other namespaces, so that changes to them reconcile the workload.
+func (r *Reconciler) trackCrossNamespaceObjects(logger logr.Logger, workload *v1alpha1.Workload) {
+	if r.dynamicTracker == nil {
+		return
+	}
+
+	for _, ref := range workload.Status.CrossNamespaceObjects {
+		err := r.dynamicTracker.Watch(logger, unstructuredFor(ref), handler.EnqueueRequestsFromMapFunc(workloadRequestsForStampedObject))
+		if err != nil {
+			logger.Error(err, "dynamic tracker watch")
+		}
+	}
+}
+
+// trackStampedObjects watches the kinds of the stamped objects, whether or
+// not they have output yet, so that their status changing, or something
+// else changing them, reconciles the workload right away.
+func (r *Reconciler) trackStampedObjects(logger logr.Logger, stamped []v1alpha1.ObjectReference) {
+	if r.dynamicTracker == nil {
+		return
+	}
+
+	for _, ref := range stamped {
+		err := r.dynamicTracker.Watch(logger, unstructuredFor(ref), handler.EnqueueRequestsFromMapFunc(workloadRequestsForStampedObject))
+		if err != nil {
+			logger.Error(err, "dynamic tracker watch")
+		}
+	}
+}
+
+// workloadRequestsForStampedObject maps an object to the workload it was
+// stamped for, by the labels every stamped object carries.
+func workloadRequestsForStampedObject(obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	name, namespace := labels["carto.run/workload-name"], labels["carto.run/workload-namespace"]
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
+func unstructuredFor(ref v1alpha1.ObjectReference) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(ref.APIVersion)
+	obj.SetKind(ref.Kind)
+	obj.SetNamespace(ref.Namespace)
+	obj.SetName(ref.Name)
+	return obj
+}
+
+func containsRef(refs []v1alpha1.ObjectReference, ref v1alpha1.ObjectReference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+func sameRefs(a, b []v1alpha1.ObjectReference) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
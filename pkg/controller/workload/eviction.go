@@ -0,0 +1,38 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+//counterfeiter:generate . CacheEvicter
+type CacheEvicter interface {
+	EvictOwned(ownerLabels map[string]string)
+}
+
+// AddCacheEviction has the objects stamped for a workload forgotten by the
+// evicter once the workload no longer exists.
+func (r *Reconciler) AddCacheEviction(evicter CacheEvicter) {
+	r.cacheEvicter = evicter
+}
+
+// evictStamped forgets the objects stamped for the workload named name in
+// namespace, or does nothing when no evicter was added.
+func (r *Reconciler) evictStamped(name, namespace string) {
+	if r.cacheEvicter == nil {
+		return
+	}
+	r.cacheEvicter.EvictOwned(map[string]string{
+		"carto.run/workload-name":      name,
+		"carto.run/workload-namespace": namespace,
+	})
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/history"
+)
+
+// recordHistory adds the realization of the workload with supplyChain, which
+// submitted resources, to the workload's history when it realized something
+// new.
+func (r *Reconciler) recordHistory(workload *v1alpha1.Workload, supplyChain v1alpha1.SupplyChainObject, resources []v1alpha1.RealizedResource) {
+	workload.Status.History, r.historyChanged = history.Record(workload.Status.History, v1alpha1.RealizationRecord{
+		Time:                metav1.Now(),
+		Generation:          workload.Generation,
+		Manager:             history.Manager(workload),
+		ParamsDigest:        history.ParamsDigest(workload.Spec.Params),
+		Blueprint:           supplyChain.GetName(),
+		BlueprintGeneration: supplyChain.GetGeneration(),
+		Resources:           resources,
+	})
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+type LibraryTracker interface {
+	Track(owner types.NamespacedName, libraries []string)
+}
+
+// AddLibraryTracking lets the reconciler remember the ClusterTemplateLibraries
+// included by the templates stamped for each workload, so that changes to
+// them reconcile the workload.
+func (r *Reconciler) AddLibraryTracking(tracker LibraryTracker) {
+	r.libraryTracker = tracker
+}
+
+func (r *Reconciler) trackLibraries(workload *v1alpha1.Workload, libraries []string) {
+	if r.libraryTracker == nil {
+		return
+	}
+	r.libraryTracker.Track(types.NamespacedName{Namespace: workload.Namespace, Name: workload.Name}, libraries)
+}
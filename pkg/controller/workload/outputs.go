@@ -0,0 +1,77 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// recordOutputs replaces the workload's status.outputs with the url,
+// revision, image and config of a realization that submitted every
+// resource, each read from the last resource in the supply chain to output
+// it. Values that cannot be encoded are left out, and sensitive values are
+// redacted.
+func (r *Reconciler) recordOutputs(logger logr.Logger, workload *v1alpha1.Workload, resources []v1alpha1.SupplyChainResource, outputs map[string]*templates.Output) {
+	var source, image, config string
+	for _, resource := range resources {
+		output := outputs[resource.Name]
+		if output == nil {
+			continue
+		}
+		if output.Source != nil {
+			source = resource.Name
+		}
+		if output.Image != nil {
+			image = resource.Name
+		}
+		if output.Config != nil {
+			config = resource.Name
+		}
+	}
+
+	var recorded []v1alpha1.WorkloadOutput
+	record := func(name, resource string, value interface{}) {
+		raw, err := json.Marshal(r.redactor.Value(value))
+		if err != nil {
+			logger.Error(err, "record output", "output", name, "resource", resource)
+			return
+		}
+		recorded = append(recorded, v1alpha1.WorkloadOutput{
+			Name:     name,
+			Resource: resource,
+			Value:    apiextensionsv1.JSON{Raw: raw},
+		})
+	}
+	if source != "" {
+		record("url", source, outputs[source].Source.URL)
+		record("revision", source, outputs[source].Source.Revision)
+	}
+	if image != "" {
+		record("image", image, outputs[image].Image)
+	}
+	if config != "" {
+		record("config", config, outputs[config].Config)
+	}
+
+	r.outputsChanged = !equality.Semantic.DeepEqual(workload.Status.Outputs, recorded)
+	workload.Status.Outputs = recorded
+}
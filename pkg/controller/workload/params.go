@@ -0,0 +1,62 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+//counterfeiter:generate . ParamResolver
+type ParamResolver interface {
+	Resolve(ctx context.Context, namespace string, params []v1alpha1.Param) ([]v1alpha1.Param, []v1alpha1.ObjectReference, error)
+	Track(owner types.NamespacedName, inputs []v1alpha1.ObjectReference)
+}
+
+// AddParamResolution lets the reconciler read the params of workloads that
+// set valueFrom from their ConfigMaps and Secrets, which the resolver
+// remembers so that changes to them reconcile the workload.
+func (r *Reconciler) AddParamResolution(resolver ParamResolver) {
+	r.paramResolver = resolver
+}
+
+// resolveParams replaces the params of the workload, in memory only, with
+// their values as read from the objects they refer to, so that templates
+// see them as any other value. The values of sensitive params are redacted.
+func (r *Reconciler) resolveParams(ctx context.Context, workload *v1alpha1.Workload) error {
+	sensitive := map[string]bool{}
+	for _, param := range workload.Spec.Params {
+		sensitive[param.Name] = param.IsSensitive()
+	}
+
+	if r.paramResolver != nil {
+		resolved, inputs, err := r.paramResolver.Resolve(ctx, workload.Namespace, workload.Spec.Params)
+		r.paramResolver.Track(types.NamespacedName{Namespace: workload.Namespace, Name: workload.Name}, inputs)
+		if err != nil {
+			return err
+		}
+		workload.Spec.Params = resolved
+	}
+
+	for _, param := range workload.Spec.Params {
+		if sensitive[param.Name] {
+			r.redactor.Add(param.Value)
+		}
+	}
+	return nil
+}
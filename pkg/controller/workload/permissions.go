@@ -0,0 +1,70 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/rbac"
+)
+
+//counterfeiter:generate . PermissionReviewer
+type PermissionReviewer interface {
+	Review(ctx context.Context, stampings []rbac.Stamping) ([]rbac.MissingPermission, error)
+}
+
+// AddPermissionReviewer has the permissions needed to submit and track the
+// objects a supply chain stamps reviewed before it is realized.
+func (r *Reconciler) AddPermissionReviewer(reviewer PermissionReviewer) {
+	r.permissionReviewer = reviewer
+}
+
+// reviewPermissions returns the permissions missing for the objects the
+// supply chain stamps for the workload, or none when no reviewer was added.
+// Resources whose templates cannot be resolved are left for realization to
+// report.
+func (r *Reconciler) reviewPermissions(ctx context.Context, workload *v1alpha1.Workload, supplyChain v1alpha1.SupplyChainObject) ([]rbac.MissingPermission, error) {
+	if r.permissionReviewer == nil {
+		return nil, nil
+	}
+
+	var stampings []rbac.Stamping
+	for _, resource := range supplyChain.GetSpec().Resources {
+		templateRef, err := resource.TemplateRef.ForWorkload(workload)
+		if err != nil {
+			continue
+		}
+		template, err := r.repo.GetClusterTemplate(ctx, templateRef)
+		if err != nil {
+			continue
+		}
+
+		stamping := rbac.Stamping{
+			Resource:  resource.Name,
+			Template:  template,
+			Namespace: workload.Namespace,
+		}
+		if resource.TargetNamespace != "" {
+			stamping.Namespace = resource.TargetNamespace
+		}
+		if resource.ServiceAccountName != "" {
+			stamping.ServiceAccountNamespace = workload.Namespace
+			stamping.ServiceAccountName = resource.ServiceAccountName
+		}
+		stampings = append(stampings, stamping)
+	}
+	return r.permissionReviewer.Review(ctx, stampings)
+}
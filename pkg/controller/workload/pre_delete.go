@@ -0,0 +1,128 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate"
+	"github.com/vmware-tanzu/cartographer/pkg/predelete"
+)
+
+// ensurePreDeleteFinalizer adds the pre-delete finalizer to a workload whose
+// supply chain has pre-delete hooks, so that they get to run when it is
+// deleted.
+func (r *Reconciler) ensurePreDeleteFinalizer(ctx context.Context, workload *v1alpha1.Workload, supplyChain v1alpha1.SupplyChainObject) error {
+	if len(supplyChain.GetSpec().PreDelete) == 0 ||
+		controllerutil.ContainsFinalizer(workload, v1alpha1.PreDeleteFinalizer) {
+		return nil
+	}
+
+	controllerutil.AddFinalizer(workload, v1alpha1.PreDeleteFinalizer)
+	if err := r.repo.Update(ctx, workload); err != nil {
+		return fmt.Errorf("add finalizer: %w", err)
+	}
+	return nil
+}
+
+// runPreDeleteHooks runs the pre-delete hooks of the supply chain the deleted
+// workload was last realized with. It returns whether they are complete,
+// reporting their progress in the PreDeleteHooksCompleted condition until
+// they are. A failed hook holds the deletion back until its Job is deleted,
+// which runs it again.
+func (r *Reconciler) runPreDeleteHooks(ctx context.Context, workload *v1alpha1.Workload) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	supplyChain, err := r.lastSupplyChainOf(ctx, workload)
+	if err != nil {
+		return false, err
+	}
+	if supplyChain == nil {
+		return true, nil
+	}
+	ctx = gittemplate.WithBlueprint(ctx, supplyChain)
+
+	progress, err := predelete.Run(ctx, r.repo, predelete.Owner{
+		Object:         workload,
+		Kind:           "Workload",
+		Templating:     workload,
+		BlueprintLabel: "carto.run/cluster-supply-chain-name",
+		Blueprint:      supplyChain.GetName(),
+	}, supplyChain.GetSpec().PreDelete)
+	r.trackStampedObjects(logger, progress.Stamped)
+
+	var failedErr predelete.FailedError
+	switch {
+	case errors.As(err, &failedErr):
+		return false, r.setPreDeleteCondition(ctx, workload, predelete.FailedCondition(failedErr))
+	case err != nil:
+		return false, err
+	case !progress.Complete():
+		return false, r.setPreDeleteCondition(ctx, workload, predelete.RunningCondition(progress))
+	}
+	return true, nil
+}
+
+// lastSupplyChainOf returns the supply chain recorded in the workload's
+// status, or nil when there is none, or it no longer exists.
+func (r *Reconciler) lastSupplyChainOf(ctx context.Context, workload *v1alpha1.Workload) (v1alpha1.SupplyChainObject, error) {
+	ref := workload.Status.SupplyChainRef
+	if ref.Name == "" {
+		return nil, nil
+	}
+
+	if ref.Kind == "SupplyChain" {
+		supplyChain, err := r.repo.GetNamespacedSupplyChain(ctx, ref.Name, workload.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("get supply chain: %w", err)
+		}
+		if supplyChain == nil {
+			return nil, nil
+		}
+		return supplyChain, nil
+	}
+
+	supplyChain, err := r.repo.GetSupplyChain(ctx, ref.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get supply chain: %w", err)
+	}
+	if supplyChain == nil {
+		return nil, nil
+	}
+	return supplyChain, nil
+}
+
+// setPreDeleteCondition records condition in the workload's status, unless
+// it is already there.
+func (r *Reconciler) setPreDeleteCondition(ctx context.Context, workload *v1alpha1.Workload, condition metav1.Condition) error {
+	previous := meta.FindStatusCondition(workload.Status.Conditions, condition.Type)
+	if previous != nil && previous.Status == condition.Status && previous.Reason == condition.Reason && previous.Message == condition.Message {
+		return nil
+	}
+
+	meta.SetStatusCondition(&workload.Status.Conditions, condition)
+	if err := r.repo.StatusUpdate(ctx, workload); err != nil {
+		return fmt.Errorf("update workload status: %w", err)
+	}
+	return nil
+}
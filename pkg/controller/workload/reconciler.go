@@ -20,35 +20,96 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/backoff"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate"
+	"github.com/vmware-tanzu/cartographer/pkg/metrics"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
 	realizer "github.com/vmware-tanzu/cartographer/pkg/realizer/workload"
+	"github.com/vmware-tanzu/cartographer/pkg/redact"
 	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/scope"
+	"github.com/vmware-tanzu/cartographer/pkg/shutdown"
+	"github.com/vmware-tanzu/cartographer/pkg/tracing"
 	"github.com/vmware-tanzu/cartographer/pkg/utils"
 )
 
-const reconcileInterval = 5 * time.Second
+// DefaultResyncInterval is how often a ready workload is reconciled again,
+// unless SetResyncInterval or its carto.run/resync-interval annotation say
+// otherwise.
+const DefaultResyncInterval = 5 * time.Second
 
 type Reconciler struct {
 	repo                    repository.Repository
+	serviceAccountRepo      repository.ServiceAccountRepository
 	conditionManager        conditions.ConditionManager
 	conditionManagerBuilder conditions.ConditionManagerBuilder
+	redactor                *redact.Redactor
 	realizer                realizer.Realizer
+	dynamicTracker          DynamicTracker
+	artifactRecorder        ArtifactRecorder
+	sourceResolver          SourceResolver
+	paramResolver           ParamResolver
+	libraryTracker          LibraryTracker
+	notifier                Notifier
+	eventRecorder           EventRecorder
+	plugins                 plugin.Realizer
+	clusterDataReader       ClusterDataReader
+	restMapper              meta.RESTMapper
+	permissionReviewer      PermissionReviewer
+	cacheEvicter            CacheEvicter
+	templateBackoff         *backoff.Backoff
+	resyncInterval          time.Duration
+
+	crossNamespaceObjectsChanged bool
+	lastOutputsChanged           bool
+	retriesChanged               bool
+	historyChanged               bool
+	driftedChanged               bool
+	fieldConflictsChanged        bool
+	missingOutputsChanged        bool
+	sourceChanged                bool
+	resolvedTemplatesChanged     bool
+	outputsChanged               bool
+	templatesMissing             bool
+	settled                      bool
 }
 
-func NewReconciler(repo repository.Repository, conditionManagerBuilder conditions.ConditionManagerBuilder, realizer realizer.Realizer) *Reconciler {
+func NewReconciler(repo repository.Repository, serviceAccountRepo repository.ServiceAccountRepository, conditionManagerBuilder conditions.ConditionManagerBuilder, realizer realizer.Realizer) *Reconciler {
 	return &Reconciler{
 		repo:                    repo,
+		serviceAccountRepo:      serviceAccountRepo,
 		conditionManagerBuilder: conditionManagerBuilder,
 		realizer:                realizer,
+		templateBackoff:         backoff.New(backoff.DefaultBaseDelay, backoff.DefaultMaxDelay),
+		resyncInterval:          DefaultResyncInterval,
 	}
 }
 
-func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+// SetResyncInterval sets how often workloads that are ready are reconciled
+// again, for those without a carto.run/resync-interval annotation.
+func (r *Reconciler) SetResyncInterval(interval time.Duration) {
+	r.resyncInterval = interval
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "workload.reconcile",
+		attribute.String("workload.name", req.Name),
+		attribute.String("workload.namespace", req.Namespace),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	logger := logr.FromContext(ctx).
 		WithValues("name", req.Name, "namespace", req.Namespace)
 	ctx = logr.NewContext(ctx, logger)
@@ -56,21 +117,51 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	reconcileCtx := logr.NewContext(ctx, logger)
 
-	workload, err := r.repo.GetWorkload(req.Name, req.Namespace)
+	workload, err := r.repo.GetWorkload(ctx, req.Name, req.Namespace)
 	if err != nil || workload == nil {
 		if kerrors.IsNotFound(err) {
+			r.evictStamped(req.Name, req.Namespace)
+			metrics.ForgetWorkload(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 
 		return ctrl.Result{}, fmt.Errorf("get workload: %w", err)
 	}
 
-	r.conditionManager = r.conditionManagerBuilder(v1alpha1.WorkloadReady, workload.Status.Conditions)
+	if !workload.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, workload)
+	}
 
-	supplyChain, err := r.getSupplyChainsForWorkload(workload)
+	r.crossNamespaceObjectsChanged = false
+	r.lastOutputsChanged = false
+	r.retriesChanged = false
+	r.historyChanged = false
+	r.driftedChanged = false
+	r.fieldConflictsChanged = false
+	r.missingOutputsChanged = false
+	r.sourceChanged = false
+	r.resolvedTemplatesChanged = false
+	r.outputsChanged = false
+	r.templatesMissing = false
+	r.settled = workload.Status.ObservedGeneration == workload.Generation &&
+		meta.IsStatusConditionTrue(workload.Status.Conditions, v1alpha1.WorkloadReady)
+	r.redactor = redact.New()
+	r.conditionManager = r.redactor.ConditionManager(r.conditionManagerBuilder(v1alpha1.WorkloadReady, workload.Status.Conditions))
+
+	supplyChain, err := r.getSupplyChainsForWorkload(ctx, workload)
 	if err != nil {
 		return r.completeReconciliation(reconcileCtx, workload, err)
 	}
+	ctx = gittemplate.WithBlueprint(ctx, supplyChain)
+	ctx = policy.WithBlueprint(ctx, workload, supplyChain)
+
+	if err := r.ensureCleanupFinalizer(ctx, workload, supplyChain); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.ensurePreDeleteFinalizer(ctx, workload, supplyChain); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	supplyChainGVK, err := utils.GetObjectGVK(supplyChain, r.repo.GetScheme())
 	if err != nil {
@@ -78,27 +169,149 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	workload.Status.SupplyChainRef.Kind = supplyChainGVK.Kind
-	workload.Status.SupplyChainRef.Name = supplyChain.Name
+	workload.Status.SupplyChainRef.Name = supplyChain.GetName()
 
 	err = r.checkSupplyChainReadiness(supplyChain)
 	if err != nil {
 		r.conditionManager.AddPositive(MissingReadyInSupplyChainCondition(getSupplyChainReadyCondition(supplyChain)))
 		return r.completeReconciliation(reconcileCtx, workload, err)
 	}
+
+	if !supplyChain.GetSpec().SupportsPlatform(workload.Spec.Platform) {
+		r.conditionManager.AddPositive(UnsupportedPlatformCondition(supplyChain, workload.Spec.Platform))
+		return r.completeReconciliation(reconcileCtx, workload, fmt.Errorf("supply-chain does not support platform '%s'", workload.Spec.Platform))
+	}
+
+	unmet, err := supplyChain.GetSpec().Requires.Unmet(workload)
+	if err != nil {
+		return r.completeReconciliation(reconcileCtx, workload, fmt.Errorf("check supply chain requirements: %w", err))
+	}
+	if len(unmet) > 0 {
+		r.conditionManager.AddPositive(ContractNotMetCondition(supplyChain, unmet))
+		return r.completeReconciliation(reconcileCtx, workload, fmt.Errorf("workload does not meet the requirements of supply chain '%s'", supplyChain.GetName()))
+	}
 	r.conditionManager.AddPositive(SupplyChainReadyCondition())
 
-	err = r.realizer.Realize(ctx, realizer.NewResourceRealizer(workload, r.repo), supplyChain)
+	if err := r.resolveSource(ctx, workload); err != nil {
+		r.conditionManager.AddPositive(SourceResolutionFailedCondition(err))
+		return r.completeReconciliation(reconcileCtx, workload, err)
+	}
+
+	if err := r.resolveParams(ctx, workload); err != nil {
+		r.conditionManager.AddPositive(ParamResolutionFailedCondition(err))
+		return r.completeReconciliation(reconcileCtx, workload, err)
+	}
+
+	clusterData, err := r.readClusterData(ctx)
+	if err != nil {
+		r.conditionManager.AddPositive(ClusterDataUnavailableCondition(err))
+		return r.completeReconciliation(reconcileCtx, workload, err)
+	}
+
+	missingPermissions, err := r.reviewPermissions(ctx, workload, supplyChain)
+	if err != nil {
+		r.conditionManager.AddPositive(UnknownResourceErrorCondition(err))
+		return r.completeReconciliation(reconcileCtx, workload, fmt.Errorf("review permissions: %w", err))
+	}
+	if len(missingPermissions) > 0 {
+		r.conditionManager.AddPositive(PermissionsNotMetCondition(missingPermissions))
+		return r.completeReconciliation(reconcileCtx, workload, fmt.Errorf("permissions missing to realize supply chain '%s'", supplyChain.GetName()))
+	}
+
+	previousCrossNamespaceObjects := workload.Status.CrossNamespaceObjects
+	previousLastOutputs := workload.Status.LastOutputs
+	previousDrifted := workload.Status.Drifted
+	previousFieldConflicts := workload.Status.FieldConflicts
+	previousMissingOutputs := workload.Status.MissingOptionalOutputs
+	workload.Status.CrossNamespaceObjects = nil
+	workload.Status.Drifted = nil
+	workload.Status.FieldConflicts = nil
+	workload.Status.MissingOptionalOutputs = nil
+	previousRetries := workload.Status.Retries
+	if r.settled || workload.Status.ObservedGeneration != workload.Generation {
+		workload.Status.Retries = nil
+	}
+	realizationRetries := workload.Status.Retries
+	resourceRealizer := realizer.NewResourceRealizer(workload, r.repo, r.serviceAccountRepo)
+	realizeCtx := repository.WithChangeReporter(ctx, r.changeReporter(workload))
+	realizeCtx = repository.WithConflictReporter(realizeCtx, conflictReporter(workload))
+	realizeCtx = redact.NewContext(realizeCtx, r.redactor)
+	if r.plugins != nil {
+		realizeCtx = plugin.NewContext(realizeCtx, r.plugins)
+	}
+	if clusterData != nil {
+		realizeCtx = clusterdata.NewContext(realizeCtx, clusterData)
+	}
+	if r.restMapper != nil {
+		realizeCtx = scope.NewContext(realizeCtx, r.restMapper)
+	}
+	err = r.realizer.Realize(realizeCtx, resourceRealizer, supplyChain)
+	r.lastOutputsChanged = !equality.Semantic.DeepEqual(previousLastOutputs, workload.Status.LastOutputs)
+	resolvedTemplates := resourceRealizer.ResolvedTemplates()
+	r.resolvedTemplatesChanged = !equality.Semantic.DeepEqual(workload.Status.ResolvedTemplates, resolvedTemplates)
+	workload.Status.ResolvedTemplates = resolvedTemplates
+	metrics.RecordRetries("Workload", supplyChain.GetName(), realizationRetries, workload.Status.Retries)
+	if r.settled && len(workload.Status.Retries) == 0 {
+		// nothing failed, so the workload is still settled: keep the
+		// retries of the realization that made it ready.
+		workload.Status.Retries = previousRetries
+	}
+	r.retriesChanged = !equality.Semantic.DeepEqual(previousRetries, workload.Status.Retries)
+	r.pruneCrossNamespaceObjects(ctx, workload, previousCrossNamespaceObjects, resourceRealizer.StampedObjects(), err)
+	r.trackCrossNamespaceObjects(logger, workload)
+	r.trackStampedObjects(logger, resourceRealizer.StampedObjects())
+	r.trackLibraries(workload, resourceRealizer.Libraries())
+	r.driftedChanged = !equality.Semantic.DeepEqual(previousDrifted, workload.Status.Drifted)
+	if len(workload.Status.Drifted) > 0 {
+		r.conditionManager.AddNegative(DriftedCondition(workload.Status.Drifted))
+	}
+	r.fieldConflictsChanged = !equality.Semantic.DeepEqual(previousFieldConflicts, workload.Status.FieldConflicts)
+	if len(workload.Status.FieldConflicts) > 0 {
+		r.conditionManager.AddNegative(FieldConflictCondition(workload.Status.FieldConflicts))
+	}
+	r.missingOutputsChanged = !equality.Semantic.DeepEqual(previousMissingOutputs, workload.Status.MissingOptionalOutputs)
 	if err != nil {
 		switch typedErr := err.(type) {
 		case realizer.GetClusterTemplateError:
 			r.conditionManager.AddPositive(TemplateObjectRetrievalFailureCondition(typedErr))
+		case realizer.TemplateNotFoundError:
+			r.conditionManager.AddPositive(TemplateNotFoundCondition(typedErr))
+			r.templatesMissing = true
+		case realizer.TemplateOptionsError:
+			r.conditionManager.AddPositive(TemplateOptionsMatchErrorCondition(typedErr))
+			err = nil
 		case realizer.StampError:
 			r.conditionManager.AddPositive(TemplateStampFailureCondition(typedErr))
 		case realizer.ApplyStampedObjectError:
 			r.conditionManager.AddPositive(TemplateRejectedByAPIServerCondition(typedErr))
+		case realizer.ApplyConflictError:
+			r.conditionManager.AddPositive(TemplateApplyConflictCondition(typedErr))
+		case realizer.PolicyViolationError:
+			r.conditionManager.AddPositive(PolicyViolationCondition(typedErr))
+			err = nil
+		case realizer.StampedObjectRejectedError:
+			r.conditionManager.AddPositive(StampedObjectRejectedCondition(typedErr))
+			err = nil
+		case realizer.KindNotRegisteredError:
+			r.conditionManager.AddPositive(KindNotRegisteredCondition(typedErr))
+			err = nil
+		case realizer.JobRunningError:
+			r.conditionManager.AddPositive(JobRunningCondition(typedErr))
+			err = nil
+		case realizer.JobFailedError:
+			r.conditionManager.AddPositive(JobFailedCondition(typedErr))
+			err = nil
+		case realizer.PluginRunningError:
+			r.conditionManager.AddPositive(PluginRunningCondition(typedErr))
+			err = nil
+		case realizer.PluginFailedError:
+			r.conditionManager.AddPositive(PluginFailedCondition(typedErr))
+			err = nil
 		case realizer.RetrieveOutputError:
 			r.conditionManager.AddPositive(MissingValueAtPathCondition(typedErr.ResourceName(), typedErr.JsonPathExpression()))
 			err = nil
+		case realizer.OutputPathError:
+			r.conditionManager.AddPositive(InvalidOutputPathCondition(typedErr.ResourceName(), typedErr.JsonPathExpression()))
 		default:
 			r.conditionManager.AddPositive(UnknownResourceErrorCondition(typedErr))
 		}
@@ -106,7 +319,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return r.completeReconciliation(reconcileCtx, workload, err)
 	}
 
-	r.conditionManager.AddPositive(ResourcesSubmittedCondition())
+	r.conditionManager.AddPositive(ResourcesSubmittedCondition(v1alpha1.TotalRetries(workload.Status.Retries)))
+	r.recordOutputs(logger, workload, supplyChain.GetSpec().Resources, resourceRealizer.RealizedOutputs())
+	r.recordArtifacts(ctx, workload, supplyChain.GetName(), resourceRealizer.RealizedOutputs())
+	r.recordHistory(workload, supplyChain, resourceRealizer.RealizedResources())
 
 	return r.completeReconciliation(reconcileCtx, workload, nil)
 }
@@ -114,13 +330,19 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 func (r *Reconciler) completeReconciliation(ctx context.Context, workload *v1alpha1.Workload, err error) (ctrl.Result, error) {
 	logger := logr.FromContext(ctx)
 
+	previousReady := readyCondition(workload.Status.Conditions)
 	var changed bool
 	workload.Status.Conditions, changed = r.conditionManager.Finalize()
+	metrics.RecordWorkload(client.ObjectKeyFromObject(workload), workload.Status.SupplyChainRef.Name, readyCondition(workload.Status.Conditions))
 
 	var updateErr error
-	if changed || (workload.Status.ObservedGeneration != workload.Generation) {
+	if changed || r.crossNamespaceObjectsChanged || r.lastOutputsChanged || r.retriesChanged || r.historyChanged || r.driftedChanged || r.fieldConflictsChanged || r.missingOutputsChanged || r.sourceChanged || r.resolvedTemplatesChanged || r.outputsChanged || (workload.Status.ObservedGeneration != workload.Generation) {
 		workload.Status.ObservedGeneration = workload.Generation
-		updateErr = r.repo.StatusUpdate(workload)
+		flushCtx, cancel := shutdown.FlushContext(ctx)
+		defer cancel()
+		updateCtx, span := tracing.Start(flushCtx, "workload.status-update")
+		updateErr = r.repo.StatusUpdate(updateCtx, workload)
+		tracing.End(span, updateErr)
 		if updateErr != nil {
 			logger.Error(updateErr, "update error")
 			if err == nil {
@@ -129,9 +351,22 @@ func (r *Reconciler) completeReconciliation(ctx context.Context, workload *v1alp
 			}
 		}
 	}
+	if updateErr == nil {
+		r.notify(ctx, workload, previousReady)
+	}
 
 	logger.Info("finished")
 
+	key := client.ObjectKeyFromObject(workload)
+	if r.templatesMissing && updateErr == nil {
+		// the template may yet be created: wait for it with a backoff
+		// rather than retrying right away.
+		delay := r.templateBackoff.Next(key)
+		logger.Info("template not found", "error", err.Error(), "retry-after", delay.String())
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	r.templateBackoff.Reset(key)
+
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -140,10 +375,27 @@ func (r *Reconciler) completeReconciliation(ctx context.Context, workload *v1alp
 		return ctrl.Result{}, fmt.Errorf("workload not ready")
 	}
 
-	return ctrl.Result{RequeueAfter: reconcileInterval}, nil
+	if !r.settled {
+		metrics.ObserveRealization("Workload", workload.Status.SupplyChainRef.Name, workload.Status.Retries)
+	}
+
+	return ctrl.Result{RequeueAfter: r.resyncIntervalOf(ctx, workload)}, nil
+}
+
+// resyncIntervalOf is the interval set by the workload's
+// carto.run/resync-interval annotation, or else the reconciler's.
+func (r *Reconciler) resyncIntervalOf(ctx context.Context, workload *v1alpha1.Workload) time.Duration {
+	interval, err := v1alpha1.GetResyncInterval(workload)
+	if err != nil {
+		logr.FromContext(ctx).Error(err, "ignoring resync interval")
+	}
+	if interval == 0 {
+		return r.resyncInterval
+	}
+	return interval
 }
 
-func (r *Reconciler) checkSupplyChainReadiness(supplyChain *v1alpha1.ClusterSupplyChain) error {
+func (r *Reconciler) checkSupplyChainReadiness(supplyChain v1alpha1.SupplyChainObject) error {
 	supplyChainReadyCondition := getSupplyChainReadyCondition(supplyChain)
 	if supplyChainReadyCondition.Status == "True" {
 		return nil
@@ -151,8 +403,8 @@ func (r *Reconciler) checkSupplyChainReadiness(supplyChain *v1alpha1.ClusterSupp
 	return fmt.Errorf("supply-chain is not in ready condition")
 }
 
-func getSupplyChainReadyCondition(supplyChain *v1alpha1.ClusterSupplyChain) metav1.Condition {
-	for _, condition := range supplyChain.Status.Conditions {
+func getSupplyChainReadyCondition(supplyChain v1alpha1.SupplyChainObject) metav1.Condition {
+	for _, condition := range supplyChain.GetStatus().Conditions {
 		if condition.Type == "Ready" {
 			return condition
 		}
@@ -160,13 +412,16 @@ func getSupplyChainReadyCondition(supplyChain *v1alpha1.ClusterSupplyChain) meta
 	return metav1.Condition{}
 }
 
-func (r *Reconciler) getSupplyChainsForWorkload(workload *v1alpha1.Workload) (*v1alpha1.ClusterSupplyChain, error) {
+func (r *Reconciler) getSupplyChainsForWorkload(ctx context.Context, workload *v1alpha1.Workload) (supplyChain v1alpha1.SupplyChainObject, err error) {
+	ctx, span := tracing.Start(ctx, "workload.select-supply-chain")
+	defer func() { tracing.End(span, err) }()
+
 	if len(workload.Labels) == 0 {
 		r.conditionManager.AddPositive(WorkloadMissingLabelsCondition())
 		return nil, fmt.Errorf("workload is missing required labels")
 	}
 
-	supplyChains, err := r.repo.GetSupplyChainsForWorkload(workload)
+	supplyChains, err := r.matchingSupplyChains(ctx, workload)
 	if err != nil || len(supplyChains) == 0 {
 		r.conditionManager.AddPositive(SupplyChainNotFoundCondition(workload.Labels))
 
@@ -180,5 +435,34 @@ func (r *Reconciler) getSupplyChainsForWorkload(workload *v1alpha1.Workload) (*v
 		return nil, fmt.Errorf("too many supply chains match the workload selector")
 	}
 
-	return supplyChains[0].DeepCopy(), nil
+	span.SetAttributes(attribute.String("supply-chain.name", supplyChains[0].GetName()))
+
+	return supplyChains[0], nil
+}
+
+// matchingSupplyChains returns the supply chains whose selector is satisfied
+// by the workload: the SupplyChains in its namespace when there are any,
+// otherwise the ClusterSupplyChains.
+func (r *Reconciler) matchingSupplyChains(ctx context.Context, workload *v1alpha1.Workload) ([]v1alpha1.SupplyChainObject, error) {
+	var supplyChains []v1alpha1.SupplyChainObject
+
+	namespaced, err := r.repo.GetNamespacedSupplyChainsForWorkload(ctx, workload)
+	if err != nil {
+		return nil, err
+	}
+	for i := range namespaced {
+		supplyChains = append(supplyChains, namespaced[i].DeepCopy())
+	}
+	if len(supplyChains) > 0 {
+		return supplyChains, nil
+	}
+
+	clusterSupplyChains, err := r.repo.GetSupplyChainsForWorkload(ctx, workload)
+	if err != nil {
+		return nil, err
+	}
+	for i := range clusterSupplyChains {
+		supplyChains = append(supplyChains, clusterSupplyChains[i].DeepCopy())
+	}
+	return supplyChains, nil
 }
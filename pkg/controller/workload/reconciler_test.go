@@ -17,6 +17,7 @@ package workload_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -24,23 +25,40 @@ import (
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
 	. "github.com/onsi/gomega/gstruct"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/backoff"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions/conditionsfakes"
 	"github.com/vmware-tanzu/cartographer/pkg/controller/workload"
+	controllerfakes "github.com/vmware-tanzu/cartographer/pkg/controller/workload/workloadfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/eval"
+	"github.com/vmware-tanzu/cartographer/pkg/jobs"
+	"github.com/vmware-tanzu/cartographer/pkg/notification"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin/pluginfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
+	"github.com/vmware-tanzu/cartographer/pkg/rbac"
 	realizer "github.com/vmware-tanzu/cartographer/pkg/realizer/workload"
 	"github.com/vmware-tanzu/cartographer/pkg/realizer/workload/workloadfakes"
 	"github.com/vmware-tanzu/cartographer/pkg/registrar"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
 	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/scope"
+	"github.com/vmware-tanzu/cartographer/pkg/templatelibrary"
 	"github.com/vmware-tanzu/cartographer/pkg/templates"
 )
 
@@ -80,7 +98,7 @@ var _ = Describe("Reconciler", func() {
 			Expect(err).NotTo(HaveOccurred())
 			repo.GetSchemeReturns(scheme)
 
-			reconciler = workload.NewReconciler(repo, fakeConditionManagerBuilder, rlzr)
+			reconciler = workload.NewReconciler(repo, nil, fakeConditionManagerBuilder, rlzr)
 
 			req = ctrl.Request{
 				NamespacedName: types.NamespacedName{Name: "my-workload-name", Namespace: "my-namespace"},
@@ -119,10 +137,35 @@ var _ = Describe("Reconciler", func() {
 			Expect(repo.StatusUpdateCallCount()).To(Equal(1))
 		})
 
+		Context("when the reconcile is cancelled during realization", func() {
+			var cancel context.CancelFunc
+
+			BeforeEach(func() {
+				ctx, cancel = context.WithCancel(ctx)
+				rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.SupplyChainObject) error {
+					cancel()
+					return ctx.Err()
+				}
+			})
+
+			It("still writes the status with a live context", func() {
+				var statusCtxErr error
+				repo.StatusUpdateStub = func(statusCtx context.Context, _ client.Object) error {
+					statusCtxErr = statusCtx.Err()
+					return nil
+				}
+
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				Expect(statusCtxErr).NotTo(HaveOccurred())
+			})
+		})
+
 		It("updates the status.observedGeneration to equal metadata.generation", func() {
 			_, _ = reconciler.Reconcile(ctx, req)
 
-			updatedWorkload := repo.StatusUpdateArgsForCall(0)
+			_, updatedWorkload := repo.StatusUpdateArgsForCall(0)
 
 			Expect(*updatedWorkload.(*v1alpha1.Workload)).To(MatchFields(IgnoreExtras, Fields{
 				"Status": MatchFields(IgnoreExtras, Fields{
@@ -153,7 +196,7 @@ var _ = Describe("Reconciler", func() {
 
 			_, _ = reconciler.Reconcile(ctx, req)
 
-			updatedWorkload := repo.StatusUpdateArgsForCall(0)
+			_, updatedWorkload := repo.StatusUpdateArgsForCall(0)
 
 			Expect(*updatedWorkload.(*v1alpha1.Workload)).To(MatchFields(IgnoreExtras, Fields{
 				"Status": MatchFields(IgnoreExtras, Fields{
@@ -165,7 +208,8 @@ var _ = Describe("Reconciler", func() {
 		It("requests supply chains from the repo", func() {
 			_, _ = reconciler.Reconcile(ctx, req)
 
-			Expect(repo.GetSupplyChainsForWorkloadArgsForCall(0)).To(Equal(wl))
+			_, requested := repo.GetSupplyChainsForWorkloadArgsForCall(0)
+			Expect(requested).To(Equal(wl))
 		})
 
 		Context("and the repo returns a single matching supply-chain for the workload", func() {
@@ -201,6 +245,42 @@ var _ = Describe("Reconciler", func() {
 				Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
 			})
 
+			It("reschedules at the resync interval it is set", func() {
+				reconciler.SetResyncInterval(time.Minute)
+				result, err := reconciler.Reconcile(ctx, req)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+			})
+
+			Context("when the workload is annotated with a resync interval", func() {
+				BeforeEach(func() {
+					reconciler.SetResyncInterval(time.Minute)
+					wl.Annotations = map[string]string{v1alpha1.ResyncIntervalAnnotation: "30m"}
+				})
+
+				It("reschedules at the interval of the annotation", func() {
+					result, err := reconciler.Reconcile(ctx, req)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(ctrl.Result{RequeueAfter: 30 * time.Minute}))
+				})
+
+				Context("that is malformed", func() {
+					BeforeEach(func() {
+						wl.Annotations = map[string]string{v1alpha1.ResyncIntervalAnnotation: "hourly"}
+					})
+
+					It("logs the error and reschedules at the reconciler's interval", func() {
+						result, err := reconciler.Reconcile(ctx, req)
+
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+						Expect(out).To(Say(`"msg":"ignoring resync interval"`))
+					})
+				})
+			})
+
 			It("sets the SupplyChainRef", func() {
 				_, _ = reconciler.Reconcile(ctx, req)
 
@@ -215,112 +295,1264 @@ var _ = Describe("Reconciler", func() {
 
 			It("calls the condition manager to report the resources have been submitted", func() {
 				_, _ = reconciler.Reconcile(ctx, req)
-				Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.ResourcesSubmittedCondition()))
+				Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.ResourcesSubmittedCondition(0)))
 			})
 
-			Context("but getting the object GVK fails", func() {
+			Context("when artifacts are recorded", func() {
+				var recorder *controllerfakes.FakeArtifactRecorder
+
 				BeforeEach(func() {
-					repo.GetSchemeReturns(runtime.NewScheme())
+					recorder = &controllerfakes.FakeArtifactRecorder{}
+					reconciler.AddArtifactRecording(recorder)
 				})
-				It("returns a helpful error", func() {
+
+				It("records the outputs of the realization", func() {
 					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
 
-					Expect(err.Error()).To(ContainSubstring("get object gvk: "))
+					Expect(recorder.RecordCallCount()).To(Equal(1))
+					_, recorded, supplyChainName, outputs := recorder.RecordArgsForCall(0)
+					Expect(recorded).To(Equal(wl))
+					Expect(supplyChainName).To(Equal("some-supply-chain"))
+					Expect(outputs).To(BeEmpty())
+				})
+
+				It("logs failures to record without failing the reconcile", func() {
+					recorder.RecordReturns(errors.New("store unavailable"))
+
+					result, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
+					Expect(out).To(Say(`"msg":"record artifacts".*"error":"store unavailable"`))
+				})
+
+				It("records nothing when the realization fails", func() {
+					rlzr.RealizeReturns(errors.New("realize failed"))
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(recorder.RecordCallCount()).To(Equal(0))
 				})
 			})
 
-			Context("but the supply chain is not in a ready state", func() {
+			It("records the realization in the workload's history", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				Expect(wl.Status.History).To(HaveLen(1))
+				Expect(wl.Status.History[0].Generation).To(Equal(int64(1)))
+				Expect(wl.Status.History[0].Blueprint).To(Equal("some-supply-chain"))
+				Expect(wl.Status.History[0].Time.IsZero()).To(BeFalse())
+			})
+
+			It("does not record failed realizations in the workload's history", func() {
+				rlzr.RealizeReturns(errors.New("realize failed"))
+
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(wl.Status.History).To(BeEmpty())
+			})
+
+			Context("when events are recorded", func() {
+				var eventRecorder *controllerfakes.FakeEventRecorder
+
 				BeforeEach(func() {
-					supplyChain.Status.Conditions = []metav1.Condition{
+					eventRecorder = &controllerfakes.FakeEventRecorder{}
+					reconciler.AddEventRecording(eventRecorder)
+				})
+
+				It("records an event for each stamped object updated", func() {
+					rlzr.RealizeStub = func(ctx context.Context, _ realizer.ResourceRealizer, _ v1alpha1.SupplyChainObject) error {
+						stamped := &unstructured.Unstructured{}
+						stamped.SetKind("Deployment")
+						stamped.SetNamespace("my-namespace")
+						stamped.SetName("app")
+						stamped.SetLabels(map[string]string{"carto.run/resource-name": "deployer"})
+						repository.ChangeReporterFrom(ctx)(stamped, []repository.Change{
+							{Path: "spec.replicas", From: int64(1), To: int64(3)},
+						})
+						return nil
+					}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(eventRecorder.AnnotatedEventfCallCount()).To(Equal(1))
+					object, annotations, eventType, reason, messageFmt, args := eventRecorder.AnnotatedEventfArgsForCall(0)
+					Expect(object).To(Equal(wl))
+					Expect(annotations).To(Equal(map[string]string{
+						"carto.run/resource-name": "deployer",
+						"carto.run/changes":       `[{"path":"spec.replicas","from":1,"to":3}]`,
+					}))
+					Expect(eventType).To(Equal("Normal"))
+					Expect(reason).To(Equal(workload.StampedObjectChangedReason))
+					Expect(fmt.Sprintf(messageFmt, args...)).To(Equal("resource 'deployer' updated Deployment my-namespace/app: spec.replicas: 1 -> 3"))
+				})
+
+				It("redacts the values of sensitive params from the event", func() {
+					wl.Spec.Params = []v1alpha1.Param{{Name: "token", Value: apiextensionsv1.JSON{Raw: []byte(`"s3cr3t"`)}, Sensitive: true}}
+					rlzr.RealizeStub = func(ctx context.Context, _ realizer.ResourceRealizer, _ v1alpha1.SupplyChainObject) error {
+						stamped := &unstructured.Unstructured{}
+						stamped.SetKind("Secret")
+						stamped.SetNamespace("my-namespace")
+						stamped.SetName("app")
+						stamped.SetLabels(map[string]string{"carto.run/resource-name": "deployer"})
+						repository.ChangeReporterFrom(ctx)(stamped, []repository.Change{
+							{Path: "stringData.token", From: "old", To: "s3cr3t"},
+						})
+						return nil
+					}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(eventRecorder.AnnotatedEventfCallCount()).To(Equal(1))
+					_, annotations, _, _, messageFmt, args := eventRecorder.AnnotatedEventfArgsForCall(0)
+					Expect(annotations["carto.run/changes"]).To(Equal(`[{"path":"stringData.token","from":"old","to":"[redacted]"}]`))
+					Expect(fmt.Sprintf(messageFmt, args...)).To(Equal("resource 'deployer' updated Secret my-namespace/app: stringData.token: \"old\" -> \"[redacted]\""))
+				})
+			})
+
+			Context("when stamped objects drift", func() {
+				var drifted []v1alpha1.DriftedObject
+
+				BeforeEach(func() {
+					drifted = []v1alpha1.DriftedObject{{
+						Resource: "deployer",
+						Object:   v1alpha1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "my-namespace", Name: "app"},
+						Fields:   []string{"spec.replicas", "spec.paused"},
+					}}
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.SupplyChainObject) error {
+						wl.Status.Drifted = append(wl.Status.Drifted, drifted...)
+						return nil
+					}
+				})
+
+				It("adds a negative Drifted condition listing the drifted fields", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(conditionManager.AddNegativeCallCount()).To(Equal(1))
+					condition := conditionManager.AddNegativeArgsForCall(0)
+					Expect(condition).To(Equal(workload.DriftedCondition(drifted)))
+					Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+					Expect(condition.Message).To(Equal("resource 'deployer' Deployment my-namespace/app drifted at spec.replicas, spec.paused"))
+				})
+
+				It("updates the status with the drifted objects", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(wl.Status.Drifted).To(Equal(drifted))
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				})
+
+				It("forgets objects that no longer drift", func() {
+					wl.Status.Drifted = drifted
+					rlzr.RealizeReturns(nil)
+					rlzr.RealizeStub = nil
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(wl.Status.Drifted).To(BeEmpty())
+					Expect(conditionManager.AddNegativeCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when other field managers set fields of stamped objects", func() {
+				BeforeEach(func() {
+					rlzr.RealizeStub = func(ctx context.Context, _ realizer.ResourceRealizer, _ v1alpha1.SupplyChainObject) error {
+						stamped := &unstructured.Unstructured{}
+						stamped.SetAPIVersion("apps/v1")
+						stamped.SetKind("Deployment")
+						stamped.SetNamespace("my-namespace")
+						stamped.SetName("app")
+						stamped.SetLabels(map[string]string{"carto.run/resource-name": "deployer"})
+						repository.ConflictReporterFrom(ctx)(stamped, []repository.FieldConflict{
+							{Manager: "autoscaler", Fields: []string{"spec.replicas"}},
+						})
+						return nil
+					}
+				})
+
+				It("lists the conflicts in the status", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(wl.Status.FieldConflicts).To(Equal([]v1alpha1.FieldConflict{{
+						Resource: "deployer",
+						Object:   v1alpha1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "my-namespace", Name: "app"},
+						Manager:  "autoscaler",
+						Fields:   []string{"spec.replicas"},
+					}}))
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				})
+
+				It("adds a negative FieldConflict condition naming the field manager", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(conditionManager.AddNegativeCallCount()).To(Equal(1))
+					condition := conditionManager.AddNegativeArgsForCall(0)
+					Expect(condition.Type).To(Equal("FieldConflict"))
+					Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+					Expect(condition.Reason).To(Equal("FieldManagerConflict"))
+					Expect(condition.Message).To(Equal("resource 'deployer' Deployment my-namespace/app: spec.replicas set by field manager 'autoscaler'"))
+				})
+
+				It("forgets conflicts the realization no longer meets", func() {
+					wl.Status.FieldConflicts = []v1alpha1.FieldConflict{{Resource: "deployer", Manager: "autoscaler"}}
+					rlzr.RealizeStub = nil
+					rlzr.RealizeReturns(nil)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(wl.Status.FieldConflicts).To(BeEmpty())
+					Expect(conditionManager.AddNegativeCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when stamped objects are tracked", func() {
+				var tracker *controllerfakes.FakeDynamicTracker
+
+				BeforeEach(func() {
+					tracker = &controllerfakes.FakeDynamicTracker{}
+					reconciler.AddTracking(tracker)
+
+					repo.GetClusterTemplateReturns(templates.NewClusterTemplateModel(&v1alpha1.ClusterTemplate{
+						ObjectMeta: metav1.ObjectMeta{Name: "config-template"},
+						Spec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app-config"}}`)},
+						},
+					}), nil)
+					rlzr.RealizeStub = func(ctx context.Context, resourceRealizer realizer.ResourceRealizer, _ v1alpha1.SupplyChainObject) error {
+						_, err := resourceRealizer.Do(ctx, &v1alpha1.SupplyChainResource{
+							Name:        "config",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "config-template"},
+						}, "some-supply-chain", realizer.NewOutputs())
+						return err
+					}
+				})
+
+				It("watches the kinds of the objects stamped, so that changes to them are acted on as they happen", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(tracker.WatchCallCount()).To(Equal(1))
+					_, watched, _ := tracker.WatchArgsForCall(0)
+					Expect(watched.GetObjectKind().GroupVersionKind()).To(Equal(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}))
+				})
+
+				Context("when the object stamped has not output yet", func() {
+					BeforeEach(func() {
+						repo.GetClusterTemplateReturns(templates.NewClusterConfigTemplateModel(&v1alpha1.ClusterConfigTemplate{
+							ObjectMeta: metav1.ObjectMeta{Name: "config-template"},
+							Spec: v1alpha1.ConfigTemplateSpec{
+								TemplateSpec: v1alpha1.TemplateSpec{
+									Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app-config"}}`)},
+								},
+								ConfigPath: ".data.config",
+							},
+						}, eval.EvaluatorBuilder()), nil)
+					})
+
+					It("still watches its kind, so that its status changing reconciles the workload right away", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+
+						Expect(tracker.WatchCallCount()).To(Equal(1))
+						_, watched, _ := tracker.WatchArgsForCall(0)
+						Expect(watched.GetObjectKind().GroupVersionKind()).To(Equal(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}))
+					})
+				})
+			})
+
+			Context("when templates include template libraries", func() {
+				var tracker *templatelibrary.Tracker
+
+				BeforeEach(func() {
+					wl.Namespace = "my-namespace"
+					wl.Name = "my-workload-name"
+					tracker = templatelibrary.NewTracker()
+					reconciler.AddLibraryTracking(tracker)
+
+					repo.GetClusterTemplateReturns(templates.NewClusterTemplateModel(&v1alpha1.ClusterTemplate{
+						ObjectMeta: metav1.ObjectMeta{Name: "config-template"},
+						Spec: v1alpha1.TemplateSpec{
+							Template:  &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app-config"}}`)},
+							Libraries: []string{"standard"},
+						},
+					}), nil)
+					rlzr.RealizeStub = func(ctx context.Context, resourceRealizer realizer.ResourceRealizer, _ v1alpha1.SupplyChainObject) error {
+						_, err := resourceRealizer.Do(ctx, &v1alpha1.SupplyChainResource{
+							Name:        "config",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "config-template"},
+						}, "some-supply-chain", realizer.NewOutputs())
+						return err
+					}
+				})
+
+				It("tracks the libraries, so that changes to them reconcile the workload", func() {
+					repo.GetTemplateLibraryReturns(&v1alpha1.ClusterTemplateLibrary{ObjectMeta: metav1.ObjectMeta{Name: "standard"}}, nil)
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(tracker.Requests(&v1alpha1.ClusterTemplateLibrary{ObjectMeta: metav1.ObjectMeta{Name: "standard"}})).
+						To(Equal([]ctrl.Request{req}))
+				})
+
+				It("still tracks a library that does not exist, so that creating it reconciles the workload", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(tracker.Requests(&v1alpha1.ClusterTemplateLibrary{ObjectMeta: metav1.ObjectMeta{Name: "standard"}})).
+						To(Equal([]ctrl.Request{req}))
+				})
+			})
+
+			Context("when cluster data is added", func() {
+				var clusterDataReader *controllerfakes.FakeClusterDataReader
+
+				BeforeEach(func() {
+					clusterDataReader = &controllerfakes.FakeClusterDataReader{}
+					reconciler.AddClusterData(clusterDataReader)
+				})
+
+				It("passes the cluster data to the realizer", func() {
+					clusterDataReader.ReadReturns(map[string]interface{}{"domain": "cluster.local"}, nil)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+					realizeCtx, _, _ := rlzr.RealizeArgsForCall(0)
+					Expect(clusterdata.FromContext(realizeCtx)).To(Equal(map[string]interface{}{"domain": "cluster.local"}))
+				})
+
+				It("reports the failure to read it and does not realize the supply chain", func() {
+					clusterDataReader.ReadReturns(nil, errors.New("list nodes: forbidden"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("list nodes: forbidden"))
+
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.ClusterDataUnavailableCondition(err)))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when scope detection is added", func() {
+				It("passes the mapper to the realizer", func() {
+					mapper := meta.NewDefaultRESTMapper(nil)
+					reconciler.AddScopeDetection(mapper)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+					realizeCtx, _, _ := rlzr.RealizeArgsForCall(0)
+					Expect(scope.FromContext(realizeCtx)).To(BeIdenticalTo(mapper))
+				})
+			})
+
+			Context("when a permission reviewer is added", func() {
+				var (
+					reviewer *controllerfakes.FakePermissionReviewer
+					template templates.Template
+				)
+
+				BeforeEach(func() {
+					wl.Namespace = "my-namespace"
+					reviewer = &controllerfakes.FakePermissionReviewer{}
+					reconciler.AddPermissionReviewer(reviewer)
+
+					template = templates.NewClusterTemplateModel(&v1alpha1.ClusterTemplate{
+						ObjectMeta: metav1.ObjectMeta{Name: "deploy"},
+						Spec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"apps/v1","kind":"Deployment"}`)},
+						},
+					})
+					repo.GetClusterTemplateReturns(template, nil)
+
+					supplyChain.Spec.Resources = []v1alpha1.SupplyChainResource{
 						{
-							Type:    "Ready",
-							Status:  "False",
-							Reason:  "SomeReason",
-							Message: "some informative message",
+							Name:        "deployer",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "deploy"},
+						},
+						{
+							Name:               "prod-deployer",
+							TemplateRef:        v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "deploy"},
+							TargetNamespace:    "prod",
+							ServiceAccountName: "prod-deployer",
 						},
 					}
 					repo.GetSupplyChainsForWorkloadReturns([]v1alpha1.ClusterSupplyChain{supplyChain}, nil)
 				})
-				It("returns a helpful error", func() {
+
+				It("reviews the permissions needed on the objects each resource stamps", func() {
 					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(reviewer.ReviewCallCount()).To(Equal(1))
+					_, stampings := reviewer.ReviewArgsForCall(0)
+					Expect(stampings).To(Equal([]rbac.Stamping{
+						{Resource: "deployer", Template: template, Namespace: "my-namespace"},
+						{Resource: "prod-deployer", Template: template, Namespace: "prod", ServiceAccountNamespace: "my-namespace", ServiceAccountName: "prod-deployer"},
+					}))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
 
-					Expect(err.Error()).To(ContainSubstring("supply-chain is not in ready condition"))
+				It("reports the permissions missing and does not realize the supply chain", func() {
+					missing := []rbac.MissingPermission{{
+						Resource:      "deployer",
+						Verb:          "create",
+						GroupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+						Namespace:     "my-namespace",
+					}}
+					reviewer.ReviewReturns(missing, nil)
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("permissions missing to realize supply chain 'some-supply-chain'"))
+
+					Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.PermissionsNotMetCondition(missing)))
+					Expect(conditionManager.AddPositiveArgsForCall(1).Message).To(Equal("resource 'deployer': cartographer cannot create deployments.apps in namespace 'my-namespace'"))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+
+				It("reports the failure to review them", func() {
+					reviewer.ReviewReturns(nil, errors.New("review access to create deployments.apps: forbidden"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("review permissions: review access to create deployments.apps: forbidden"))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when realizer plugins are added", func() {
+				It("passes them to the realizer", func() {
+					plugins := &pluginfakes.FakeRealizer{}
+					reconciler.AddRealizerPlugins(plugins)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+					realizeCtx, _, _ := rlzr.RealizeArgsForCall(0)
+					Expect(plugin.FromContext(realizeCtx)).To(BeIdenticalTo(plugins))
+				})
+			})
+
+			Context("when the templates of resources are resolved", func() {
+				BeforeEach(func() {
+					repo.GetClusterTemplateReturns(templates.NewClusterTemplateModel(&v1alpha1.ClusterTemplate{
+						ObjectMeta: metav1.ObjectMeta{Name: "config-template", Generation: 4},
+						Spec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app-config"}}`)},
+						},
+					}), nil)
+					rlzr.RealizeStub = func(ctx context.Context, resourceRealizer realizer.ResourceRealizer, _ v1alpha1.SupplyChainObject) error {
+						_, err := resourceRealizer.Do(ctx, &v1alpha1.SupplyChainResource{
+							Name:        "config",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "config-template"},
+						}, "some-supply-chain", realizer.NewOutputs())
+						return err
+					}
+				})
+
+				It("records the template each resource was resolved to in the workload's status", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+					Expect(wl.Status.ResolvedTemplates).To(Equal([]v1alpha1.ResolvedResourceTemplate{{
+						Resource: "config",
+						Template: v1alpha1.ResolvedTemplateRef{Kind: "ClusterTemplate", Name: "config-template", Generation: 4},
+					}}))
+				})
+
+				It("replaces the templates recorded by earlier realizations", func() {
+					wl.Status.ResolvedTemplates = []v1alpha1.ResolvedResourceTemplate{{
+						Resource: "removed",
+						Template: v1alpha1.ResolvedTemplateRef{Kind: "ClusterTemplate", Name: "removed-template", Generation: 1},
+					}}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(wl.Status.ResolvedTemplates).To(HaveLen(1))
+					Expect(wl.Status.ResolvedTemplates[0].Resource).To(Equal("config"))
+				})
+			})
+
+			Context("when the supply chain outputs", func() {
+				BeforeEach(func() {
+					configMap := func(data string) v1alpha1.TemplateSpec {
+						return v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"out"},"data":` + data + `}`)},
+						}
+					}
+					repo.GetClusterTemplateStub = func(_ context.Context, ref v1alpha1.ClusterTemplateReference) (templates.Template, error) {
+						switch ref.Name {
+						case "source-template":
+							return templates.NewClusterSourceTemplateModel(&v1alpha1.ClusterSourceTemplate{
+								Spec: v1alpha1.SourceTemplateSpec{
+									TemplateSpec: configMap(`{"url":"https://example.com/app.tgz","revision":"abc123"}`),
+									URLPath:      ".data.url",
+									RevisionPath: ".data.revision",
+								},
+							}, eval.EvaluatorBuilder()), nil
+						case "image-template":
+							return templates.NewClusterImageTemplateModel(&v1alpha1.ClusterImageTemplate{
+								Spec: v1alpha1.ImageTemplateSpec{
+									TemplateSpec: configMap(`{"image":"registry.example.com/app@sha256:1c4d"}`),
+									ImagePath:    ".data.image",
+								},
+							}, eval.EvaluatorBuilder()), nil
+						default:
+							return templates.NewClusterConfigTemplateModel(&v1alpha1.ClusterConfigTemplate{
+								Spec: v1alpha1.ConfigTemplateSpec{
+									TemplateSpec: configMap(`{"config":"replicas: 2"}`),
+									ConfigPath:   ".data.config",
+								},
+							}, eval.EvaluatorBuilder()), nil
+						}
+					}
+
+					supplyChain.Spec.Resources = []v1alpha1.SupplyChainResource{
+						{Name: "source-provider", TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterSourceTemplate", Name: "source-template"}},
+						{Name: "image-builder", TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "image-template"}},
+						{Name: "config-provider", TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Name: "config-template"}},
+					}
+					repo.GetSupplyChainsForWorkloadReturns([]v1alpha1.ClusterSupplyChain{supplyChain}, nil)
+
+					rlzr.RealizeStub = func(ctx context.Context, resourceRealizer realizer.ResourceRealizer, supplyChain v1alpha1.SupplyChainObject) error {
+						for i := range supplyChain.GetSpec().Resources {
+							if _, err := resourceRealizer.Do(ctx, &supplyChain.GetSpec().Resources[i], supplyChain.GetName(), realizer.NewOutputs()); err != nil {
+								return err
+							}
+						}
+						return nil
+					}
+				})
+
+				It("records the url, revision, image and config the supply chain produced in the workload's status", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+					Expect(wl.Status.Outputs).To(Equal([]v1alpha1.WorkloadOutput{
+						{Name: "url", Resource: "source-provider", Value: apiextensionsv1.JSON{Raw: []byte(`"https://example.com/app.tgz"`)}},
+						{Name: "revision", Resource: "source-provider", Value: apiextensionsv1.JSON{Raw: []byte(`"abc123"`)}},
+						{Name: "image", Resource: "image-builder", Value: apiextensionsv1.JSON{Raw: []byte(`"registry.example.com/app@sha256:1c4d"`)}},
+						{Name: "config", Resource: "config-provider", Value: apiextensionsv1.JSON{Raw: []byte(`"replicas: 2"`)}},
+					}))
+				})
+
+				It("takes each output from the last resource in the supply chain to output it", func() {
+					supplyChain.Spec.Resources = append(supplyChain.Spec.Resources, v1alpha1.SupplyChainResource{
+						Name: "config-writer", TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Name: "writer-template"},
+					})
+					repo.GetSupplyChainsForWorkloadReturns([]v1alpha1.ClusterSupplyChain{supplyChain}, nil)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(wl.Status.Outputs).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+						"Name":     Equal("config"),
+						"Resource": Equal("config-writer"),
+					})))
+				})
+
+				It("keeps the outputs recorded before when the realization fails", func() {
+					previous := []v1alpha1.WorkloadOutput{
+						{Name: "image", Resource: "image-builder", Value: apiextensionsv1.JSON{Raw: []byte(`"registry.example.com/app@sha256:9f2b"`)}},
+					}
+					wl.Status.Outputs = previous
+					rlzr.RealizeStub = nil
+					rlzr.RealizeReturns(errors.New("realize failed"))
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(wl.Status.Outputs).To(Equal(previous))
+				})
+			})
+
+			Context("when notifications are sent", func() {
+				var notifier *controllerfakes.FakeNotifier
+
+				BeforeEach(func() {
+					notifier = &controllerfakes.FakeNotifier{}
+					reconciler.AddNotifications(notifier)
+
+					wl.Name = "my-workload-name"
+					wl.Namespace = "my-namespace"
+					wl.Status.Conditions = []metav1.Condition{
+						{Type: "Ready", Status: "True", Reason: "Ready"},
+					}
 				})
 
-				It("calls the condition manager to report supply chain not ready", func() {
-					_, _ = reconciler.Reconcile(ctx, req)
-					expectedCondition := metav1.Condition{
-						Type:               v1alpha1.WorkloadSupplyChainReady,
-						Status:             metav1.ConditionFalse,
-						ObservedGeneration: 0,
-						LastTransitionTime: metav1.Time{},
-						Reason:             "SomeReason",
-						Message:            "some informative message",
-					}
-					Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(workload.MissingReadyInSupplyChainCondition(expectedCondition)))
+				It("notifies when the workload stops being ready", func() {
+					conditionManager.FinalizeReturns([]metav1.Condition{
+						{Type: "Ready", Status: "False", Reason: "TemplateRejectedByAPIServer", Message: "denied"},
+					}, true)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(notifier.NotifyCallCount()).To(Equal(1))
+					_, event := notifier.NotifyArgsForCall(0)
+					Expect(event).To(Equal(notification.Event{
+						Kind:      "Workload",
+						Namespace: "my-namespace",
+						Name:      "my-workload-name",
+						Labels:    workloadLabels,
+						Blueprint: "some-supply-chain",
+						Reason:    "TemplateRejectedByAPIServer",
+						Message:   "denied",
+					}))
+				})
+
+				It("does not notify while the workload stays ready", func() {
+					conditionManager.FinalizeReturns([]metav1.Condition{
+						{Type: "Ready", Status: "True", Reason: "Ready"},
+					}, false)
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(notifier.NotifyCallCount()).To(Equal(0))
+				})
+
+				It("does not notify when the status cannot be updated", func() {
+					conditionManager.FinalizeReturns([]metav1.Condition{
+						{Type: "Ready", Status: "False", Reason: "TemplateRejectedByAPIServer"},
+					}, true)
+					repo.StatusUpdateReturns(errors.New("some error"))
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(notifier.NotifyCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the workload's source is an OCI artifact", func() {
+				var (
+					sourceResolver *controllerfakes.FakeSourceResolver
+					resolved       *v1alpha1.ResolvedSource
+				)
+
+				BeforeEach(func() {
+					wl.Namespace = "my-namespace"
+					wl.Spec.Source = &v1alpha1.Source{
+						OCI: &v1alpha1.OCISource{Image: "registry.example.com/team/app-bundle:main"},
+					}
+					resolved = &v1alpha1.ResolvedSource{
+						Image:  "registry.example.com/team/app-bundle:main",
+						Digest: "sha256:abc",
+						URL:    "registry.example.com/team/app-bundle:main@sha256:abc",
+					}
+
+					sourceResolver = &controllerfakes.FakeSourceResolver{}
+					sourceResolver.ResolveReturns(resolved, nil)
+					reconciler.AddSourceResolution(sourceResolver)
+				})
+
+				It("resolves the source in the workload's namespace before realizing it", func() {
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.SupplyChainObject) error {
+						Expect(wl.Status.Source).To(Equal(resolved))
+						return nil
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(sourceResolver.ResolveCallCount()).To(Equal(1))
+					_, namespace, source := sourceResolver.ResolveArgsForCall(0)
+					Expect(namespace).To(Equal("my-namespace"))
+					Expect(source).To(Equal(*wl.Spec.Source.OCI))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
+
+				It("updates the status when the digest changes", func() {
+					wl.Status.ObservedGeneration = 1
+					wl.Status.Source = &v1alpha1.ResolvedSource{
+						Image:  "registry.example.com/team/app-bundle:main",
+						Digest: "sha256:old",
+						URL:    "registry.example.com/team/app-bundle:main@sha256:old",
+					}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+					_, updated := repo.StatusUpdateArgsForCall(0)
+					Expect(updated.(*v1alpha1.Workload).Status.Source).To(Equal(resolved))
+				})
+
+				Context("but the source cannot be resolved", func() {
+					BeforeEach(func() {
+						sourceResolver.ResolveReturns(nil, errors.New("registry down"))
+					})
+
+					It("reports the failure and does not realize the supply chain", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).To(MatchError("registry down"))
+
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.SourceResolutionFailedCondition(err)))
+						Expect(rlzr.RealizeCallCount()).To(Equal(0))
+					})
+
+					It("keeps the digest the image was last resolved to", func() {
+						last := &v1alpha1.ResolvedSource{
+							Image:  "registry.example.com/team/app-bundle:main",
+							Digest: "sha256:old",
+							URL:    "registry.example.com/team/app-bundle:main@sha256:old",
+						}
+						wl.Status.Source = last
+
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(wl.Status.Source).To(Equal(last))
+						Expect(rlzr.RealizeCallCount()).To(Equal(1))
+						Expect(out).To(Say(`"msg":"resolve source, keeping last digest".*"error":"registry down"`))
+					})
+				})
+
+				It("rejects sources that also set git or image", func() {
+					image := "some-image"
+					wl.Spec.Source.Image = &image
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("source.oci cannot be set with source.git or source.image"))
+					Expect(sourceResolver.ResolveCallCount()).To(Equal(0))
+				})
+
+				It("clears the resolved source once the source is no longer an OCI artifact", func() {
+					wl.Spec.Source = nil
+					wl.Status.Source = resolved
+
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(wl.Status.Source).To(BeNil())
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the workload's params read their value from the cluster", func() {
+				var (
+					paramResolver *controllerfakes.FakeParamResolver
+					inputs        []v1alpha1.ObjectReference
+				)
+
+				BeforeEach(func() {
+					wl.Namespace = "my-namespace"
+					wl.Name = "my-workload-name"
+					wl.Spec.Params = []v1alpha1.Param{{Name: "log-level", ValueFrom: &v1alpha1.ParamValueSource{
+						ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}, Key: "log-level"},
+					}}}
+					inputs = []v1alpha1.ObjectReference{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "my-namespace", Name: "settings"}}
+
+					paramResolver = &controllerfakes.FakeParamResolver{}
+					paramResolver.ResolveReturns([]v1alpha1.Param{{Name: "log-level", Value: apiextensionsv1.JSON{Raw: []byte(`"debug"`)}}}, inputs, nil)
+					reconciler.AddParamResolution(paramResolver)
+				})
+
+				It("realizes the supply chain with the values read", func() {
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.SupplyChainObject) error {
+						Expect(wl.Spec.Params).To(Equal([]v1alpha1.Param{{Name: "log-level", Value: apiextensionsv1.JSON{Raw: []byte(`"debug"`)}}}))
+						return nil
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(paramResolver.ResolveCallCount()).To(Equal(1))
+					_, namespace, params := paramResolver.ResolveArgsForCall(0)
+					Expect(namespace).To(Equal("my-namespace"))
+					Expect(params[0].ValueFrom.ConfigMapKeyRef.Name).To(Equal("settings"))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
+
+				It("tracks the objects read, so that changes to them reconcile the workload", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(paramResolver.TrackCallCount()).To(Equal(1))
+					owner, tracked := paramResolver.TrackArgsForCall(0)
+					Expect(owner).To(Equal(types.NamespacedName{Namespace: "my-namespace", Name: "my-workload-name"}))
+					Expect(tracked).To(Equal(inputs))
+				})
+
+				Context("but a param cannot be resolved", func() {
+					BeforeEach(func() {
+						paramResolver.ResolveReturns(nil, inputs, errors.New("param 'log-level': key 'log-level' of ConfigMap 'my-namespace/settings' not found"))
+					})
+
+					It("reports the failure and does not realize the supply chain", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).To(MatchError("param 'log-level': key 'log-level' of ConfigMap 'my-namespace/settings' not found"))
+
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.ParamResolutionFailedCondition(err)))
+						Expect(rlzr.RealizeCallCount()).To(Equal(0))
+					})
+
+					It("still tracks the objects, so that creating them reconciles the workload", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+
+						Expect(paramResolver.TrackCallCount()).To(Equal(1))
+						_, tracked := paramResolver.TrackArgsForCall(0)
+						Expect(tracked).To(Equal(inputs))
+					})
+				})
+			})
+
+			Context("when resources are retried", func() {
+				var retried []string
+
+				BeforeEach(func() {
+					retried = []string{"resource-1"}
+					wl.Status.ObservedGeneration = 1
+					rlzr.RealizeStub = func(_ context.Context, resourceRealizer realizer.ResourceRealizer, _ v1alpha1.SupplyChainObject) error {
+						for _, name := range retried {
+							resourceRealizer.RecordRetry(name)
+						}
+						return nil
+					}
+				})
+
+				It("counts the retries of the current realization in the status", func() {
+					wl.Status.Retries = []v1alpha1.ResourceRetries{{Resource: "resource-1", Retries: 2}}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+					Expect(wl.Status.Retries).To(Equal([]v1alpha1.ResourceRetries{{Resource: "resource-1", Retries: 3}}))
+				})
+
+				It("reports the retries on the resources submitted condition", func() {
+					wl.Status.Retries = []v1alpha1.ResourceRetries{{Resource: "resource-1", Retries: 2}}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					condition := conditionManager.AddPositiveArgsForCall(1)
+					Expect(condition).To(Equal(workload.ResourcesSubmittedCondition(3)))
+					Expect(condition.Message).To(Equal("submitted after 3 retries"))
+				})
+
+				It("starts counting again when the workload's spec changed", func() {
+					wl.Status.ObservedGeneration = 0
+					wl.Status.Retries = []v1alpha1.ResourceRetries{{Resource: "resource-2", Retries: 2}}
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(wl.Status.Retries).To(Equal([]v1alpha1.ResourceRetries{{Resource: "resource-1", Retries: 1}}))
+				})
+
+				Context("and the workload was ready", func() {
+					BeforeEach(func() {
+						wl.Status.Conditions = []metav1.Condition{{Type: v1alpha1.WorkloadReady, Status: metav1.ConditionTrue}}
+						wl.Status.Retries = []v1alpha1.ResourceRetries{{Resource: "resource-2", Retries: 4}}
+					})
+
+					It("starts counting again when a resource fails", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+
+						Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+						Expect(wl.Status.Retries).To(Equal([]v1alpha1.ResourceRetries{{Resource: "resource-1", Retries: 1}}))
+					})
+
+					It("keeps the retries that made it ready while nothing fails", func() {
+						retried = nil
+						wl.Status.History = []v1alpha1.RealizationRecord{{Generation: 1, Blueprint: "some-supply-chain"}}
+
+						_, _ = reconciler.Reconcile(ctx, req)
+
+						Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+						Expect(wl.Status.Retries).To(Equal([]v1alpha1.ResourceRetries{{Resource: "resource-2", Retries: 4}}))
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.ResourcesSubmittedCondition(4)))
+					})
+				})
+			})
+
+			Context("but getting the object GVK fails", func() {
+				BeforeEach(func() {
+					repo.GetSchemeReturns(runtime.NewScheme())
+				})
+				It("returns a helpful error", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+
+					Expect(err.Error()).To(ContainSubstring("get object gvk: "))
+				})
+			})
+
+			Context("but the supply chain is not in a ready state", func() {
+				BeforeEach(func() {
+					supplyChain.Status.Conditions = []metav1.Condition{
+						{
+							Type:    "Ready",
+							Status:  "False",
+							Reason:  "SomeReason",
+							Message: "some informative message",
+						},
+					}
+					repo.GetSupplyChainsForWorkloadReturns([]v1alpha1.ClusterSupplyChain{supplyChain}, nil)
+				})
+				It("returns a helpful error", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+
+					Expect(err.Error()).To(ContainSubstring("supply-chain is not in ready condition"))
+				})
+
+				It("calls the condition manager to report supply chain not ready", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+					expectedCondition := metav1.Condition{
+						Type:               v1alpha1.WorkloadSupplyChainReady,
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 0,
+						LastTransitionTime: metav1.Time{},
+						Reason:             "SomeReason",
+						Message:            "some informative message",
+					}
+					Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(workload.MissingReadyInSupplyChainCondition(expectedCondition)))
+				})
+			})
+
+			Context("but the supply chain does not support the workload's platform", func() {
+				BeforeEach(func() {
+					wl.Spec.Platform = &v1alpha1.Platform{OS: "windows", Arch: "amd64"}
+					supplyChain.Spec.Platforms = []v1alpha1.Platform{{OS: "linux", Arch: "amd64"}, {OS: "linux", Arch: "arm64"}}
+					repo.GetSupplyChainsForWorkloadReturns([]v1alpha1.ClusterSupplyChain{supplyChain}, nil)
+				})
+
+				It("returns a helpful error", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+
+					Expect(err).To(MatchError("supply-chain does not support platform 'windows/amd64'"))
+				})
+
+				It("calls the condition manager to report the unsupported platform", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(metav1.Condition{
+						Type:    v1alpha1.WorkloadSupplyChainReady,
+						Status:  metav1.ConditionFalse,
+						Reason:  v1alpha1.UnsupportedPlatformSupplyChainReason,
+						Message: "supply chain 'some-supply-chain' does not support platform 'windows/amd64', supported platforms: linux/amd64, linux/arm64",
+					}))
+				})
+
+				It("does not realize the supply chain", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("but the workload does not meet the supply chain's requirements", func() {
+				BeforeEach(func() {
+					supplyChain.Spec.Requires = &v1alpha1.WorkloadContract{
+						Fields: []v1alpha1.FieldSelectorRequirement{{Key: "spec.source.git", Operator: v1alpha1.FieldSelectorOpExists}},
+						Params: []v1alpha1.RequiredParam{{Name: "language"}},
+					}
+					repo.GetSupplyChainsForWorkloadReturns([]v1alpha1.ClusterSupplyChain{supplyChain}, nil)
+				})
+
+				It("returns a helpful error", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+
+					Expect(err).To(MatchError("workload does not meet the requirements of supply chain 'some-supply-chain'"))
+				})
+
+				It("calls the condition manager to report each requirement not met", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(conditionManager.AddPositiveArgsForCall(0)).To(Equal(metav1.Condition{
+						Type:    v1alpha1.WorkloadSupplyChainReady,
+						Status:  metav1.ConditionFalse,
+						Reason:  v1alpha1.ContractNotMetSupplyChainReason,
+						Message: "workload does not meet the requirements of supply chain 'some-supply-chain': field spec.source.git is required; param 'language' is required",
+					}))
+				})
+
+				It("does not realize the supply chain", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("but the realizer returns an error", func() {
+				Context("of type GetClusterTemplateError", func() {
+					var templateError error
+					BeforeEach(func() {
+						templateError = realizer.GetClusterTemplateError{
+							Err: errors.New("some error"),
+						}
+						rlzr.RealizeReturns(templateError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.TemplateObjectRetrievalFailureCondition(templateError)))
+					})
+
+					It("returns the error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err.Error()).To(ContainSubstring(templateError.Error()))
+					})
+				})
+
+				Context("of type TemplateNotFoundError", func() {
+					var templateError error
+					BeforeEach(func() {
+						templateError = realizer.TemplateNotFoundError{
+							Err: errors.New("some error"),
+						}
+						rlzr.RealizeReturns(templateError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.TemplateNotFoundCondition(templateError)))
+					})
+
+					It("requeues within the base delay of the backoff without an error", func() {
+						result, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+						Expect(result.RequeueAfter).To(BeNumerically("<=", backoff.DefaultBaseDelay))
+						Expect(out).To(Say(`"msg":"template not found"`))
+					})
+
+					It("backs off further while the template is missing", func() {
+						for i := 0; i < 3; i++ {
+							_, _ = reconciler.Reconcile(ctx, req)
+						}
+						result, _ := reconciler.Reconcile(ctx, req)
+						Expect(result.RequeueAfter).To(BeNumerically(">=", 4*backoff.DefaultBaseDelay))
+						Expect(result.RequeueAfter).To(BeNumerically("<=", 8*backoff.DefaultBaseDelay))
+					})
+
+					It("starts the backoff over once the template is found", func() {
+						for i := 0; i < 3; i++ {
+							_, _ = reconciler.Reconcile(ctx, req)
+						}
+						rlzr.RealizeReturns(nil)
+						result, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(Equal(ctrl.Result{RequeueAfter: 5 * time.Second}))
+
+						rlzr.RealizeReturns(templateError)
+						result, _ = reconciler.Reconcile(ctx, req)
+						Expect(result.RequeueAfter).To(BeNumerically("<=", backoff.DefaultBaseDelay))
+					})
+				})
+
+				Context("of type TemplateOptionsError", func() {
+					var optionsError realizer.TemplateOptionsError
+					BeforeEach(func() {
+						optionsError = realizer.TemplateOptionsError{
+							Err:          errors.New("no option of a|b matches the workload"),
+							ResourceName: "some-name",
+						}
+						rlzr.RealizeReturns(optionsError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.TemplateOptionsMatchErrorCondition(optionsError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type StampError", func() {
+					var stampError realizer.StampError
+					BeforeEach(func() {
+						stampError = realizer.StampError{
+							Err:      errors.New("some error"),
+							Resource: &v1alpha1.SupplyChainResource{Name: "some-name"},
+						}
+						rlzr.RealizeReturns(stampError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.TemplateStampFailureCondition(stampError)))
+					})
+
+					It("returns the error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err.Error()).To(ContainSubstring(stampError.Error()))
+					})
+				})
+
+				Context("of type ApplyStampedObjectError", func() {
+					var stampedObjectError realizer.ApplyStampedObjectError
+					BeforeEach(func() {
+						stampedObjectError = realizer.ApplyStampedObjectError{
+							Err:           errors.New("some error"),
+							StampedObject: &unstructured.Unstructured{},
+						}
+						rlzr.RealizeReturns(stampedObjectError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.TemplateRejectedByAPIServerCondition(stampedObjectError)))
+					})
+
+					It("returns the error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err.Error()).To(ContainSubstring(stampedObjectError.Error()))
+					})
+				})
+
+				Context("of type ApplyConflictError", func() {
+					var conflictError realizer.ApplyConflictError
+					BeforeEach(func() {
+						conflictError = realizer.ApplyConflictError{
+							Err:           errors.New("some error"),
+							StampedObject: &unstructured.Unstructured{},
+						}
+						rlzr.RealizeReturns(conflictError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.TemplateApplyConflictCondition(conflictError)))
+					})
+
+					It("returns the error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err.Error()).To(ContainSubstring(conflictError.Error()))
+					})
+				})
+
+				Context("of type PolicyViolationError", func() {
+					var violationError realizer.PolicyViolationError
+					BeforeEach(func() {
+						violationError = realizer.PolicyViolationError{
+							Err:           policy.ViolationError{Rule: "no-pods", Message: "pods are not allowed"},
+							StampedObject: &unstructured.Unstructured{},
+						}
+						rlzr.RealizeReturns(violationError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.PolicyViolationCondition(violationError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type StampedObjectRejectedError", func() {
+					var rejectedError realizer.StampedObjectRejectedError
+					BeforeEach(func() {
+						rejectedError = realizer.StampedObjectRejectedError{
+							Err:           repository.RejectedError{Err: errors.New("admission webhook denied the request")},
+							StampedObject: &unstructured.Unstructured{},
+						}
+						rlzr.RealizeReturns(rejectedError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.StampedObjectRejectedCondition(rejectedError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type KindNotRegisteredError", func() {
+					var notRegisteredError realizer.KindNotRegisteredError
+					BeforeEach(func() {
+						notRegisteredError = realizer.KindNotRegisteredError{
+							Err:           errors.New(`no matches for kind "Image" in version "kpack.io/v1alpha2"`),
+							StampedObject: &unstructured.Unstructured{},
+						}
+						rlzr.RealizeReturns(notRegisteredError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.KindNotRegisteredCondition(notRegisteredError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+
+				Context("of type JobRunningError", func() {
+					var runningError realizer.JobRunningError
+					BeforeEach(func() {
+						runningError = realizer.JobRunningError{
+							Err:      jobs.RunningError{Job: &unstructured.Unstructured{}},
+							Resource: &v1alpha1.SupplyChainResource{Name: "some-resource"},
+						}
+						rlzr.RealizeReturns(runningError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.JobRunningCondition(runningError)))
+					})
+
+					It("does not return an error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err).NotTo(HaveOccurred())
+					})
 				})
-			})
 
-			Context("but the realizer returns an error", func() {
-				Context("of type GetClusterTemplateError", func() {
-					var templateError error
+				Context("of type JobFailedError", func() {
+					var failedError realizer.JobFailedError
 					BeforeEach(func() {
-						templateError = realizer.GetClusterTemplateError{
-							Err: errors.New("some error"),
+						failedError = realizer.JobFailedError{
+							Err:      jobs.FailedError{Job: &unstructured.Unstructured{}, Message: "BackoffLimitExceeded"},
+							Resource: &v1alpha1.SupplyChainResource{Name: "some-resource"},
 						}
-						rlzr.RealizeReturns(templateError)
+						rlzr.RealizeReturns(failedError)
 					})
 
 					It("calls the condition manager to report", func() {
 						_, _ = reconciler.Reconcile(ctx, req)
-						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.TemplateObjectRetrievalFailureCondition(templateError)))
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.JobFailedCondition(failedError)))
 					})
 
-					It("returns the error", func() {
+					It("does not return an error", func() {
 						_, err := reconciler.Reconcile(ctx, req)
-						Expect(err.Error()).To(ContainSubstring(templateError.Error()))
+						Expect(err).NotTo(HaveOccurred())
 					})
 				})
 
-				Context("of type StampError", func() {
-					var stampError realizer.StampError
+				Context("of type PluginRunningError", func() {
+					var runningError realizer.PluginRunningError
 					BeforeEach(func() {
-						stampError = realizer.StampError{
-							Err:      errors.New("some error"),
-							Resource: &v1alpha1.SupplyChainResource{Name: "some-name"},
+						runningError = realizer.PluginRunningError{
+							Err:      plugin.RunningError{Plugin: "terraform", Message: "applying"},
+							Resource: &v1alpha1.SupplyChainResource{Name: "some-resource"},
 						}
-						rlzr.RealizeReturns(stampError)
+						rlzr.RealizeReturns(runningError)
 					})
 
 					It("calls the condition manager to report", func() {
 						_, _ = reconciler.Reconcile(ctx, req)
-						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.TemplateStampFailureCondition(stampError)))
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.PluginRunningCondition(runningError)))
 					})
 
-					It("returns the error", func() {
+					It("does not return an error", func() {
 						_, err := reconciler.Reconcile(ctx, req)
-						Expect(err.Error()).To(ContainSubstring(stampError.Error()))
+						Expect(err).NotTo(HaveOccurred())
 					})
 				})
 
-				Context("of type ApplyStampedObjectError", func() {
-					var stampedObjectError realizer.ApplyStampedObjectError
+				Context("of type PluginFailedError", func() {
+					var failedError realizer.PluginFailedError
 					BeforeEach(func() {
-						stampedObjectError = realizer.ApplyStampedObjectError{
-							Err:           errors.New("some error"),
-							StampedObject: &unstructured.Unstructured{},
+						failedError = realizer.PluginFailedError{
+							Err:      plugin.FailedError{Plugin: "terraform", Message: "quota exceeded"},
+							Resource: &v1alpha1.SupplyChainResource{Name: "some-resource"},
 						}
-						rlzr.RealizeReturns(stampedObjectError)
+						rlzr.RealizeReturns(failedError)
 					})
 
 					It("calls the condition manager to report", func() {
 						_, _ = reconciler.Reconcile(ctx, req)
-						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.TemplateRejectedByAPIServerCondition(stampedObjectError)))
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.PluginFailedCondition(failedError)))
 					})
 
-					It("returns the error", func() {
+					It("does not return an error", func() {
 						_, err := reconciler.Reconcile(ctx, req)
-						Expect(err.Error()).To(ContainSubstring(stampedObjectError.Error()))
+						Expect(err).NotTo(HaveOccurred())
 					})
 				})
 
@@ -346,6 +1578,27 @@ var _ = Describe("Reconciler", func() {
 					})
 				})
 
+				Context("of type OutputPathError", func() {
+					var outputPathError realizer.OutputPathError
+					BeforeEach(func() {
+						jsonPathError := templates.NewJsonPathError("data[", errors.New("some error"))
+						outputPathError = realizer.NewOutputPathError(
+							&v1alpha1.SupplyChainResource{Name: "some-resource"},
+							&jsonPathError)
+						rlzr.RealizeReturns(outputPathError)
+					})
+
+					It("calls the condition manager to report", func() {
+						_, _ = reconciler.Reconcile(ctx, req)
+						Expect(conditionManager.AddPositiveArgsForCall(1)).To(Equal(workload.InvalidOutputPathCondition("some-resource", "data[")))
+					})
+
+					It("returns the error", func() {
+						_, err := reconciler.Reconcile(ctx, req)
+						Expect(err.Error()).To(ContainSubstring(outputPathError.Error()))
+					})
+				})
+
 				Context("of unknown type", func() {
 					var realizerError error
 					BeforeEach(func() {
@@ -365,6 +1618,196 @@ var _ = Describe("Reconciler", func() {
 				})
 			})
 
+			Context("and the supply chain stamps into another namespace", func() {
+				var (
+					stamped v1alpha1.ObjectReference
+					stale   v1alpha1.ObjectReference
+					tracker *controllerfakes.FakeDynamicTracker
+				)
+
+				BeforeEach(func() {
+					wl.Namespace = "my-namespace"
+					wl.Status.ObservedGeneration = 1
+					conditionManager.FinalizeReturns(nil, false)
+
+					supplyChain.Spec.Resources = []v1alpha1.SupplyChainResource{
+						{Name: "build", TargetNamespace: "shared-builds"},
+					}
+					repo.GetSupplyChainsForWorkloadReturns([]v1alpha1.ClusterSupplyChain{supplyChain}, nil)
+
+					stamped = v1alpha1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "shared-builds", Name: "new-config"}
+					stale = v1alpha1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "shared-builds", Name: "old-config"}
+					rlzr.RealizeStub = func(context.Context, realizer.ResourceRealizer, v1alpha1.SupplyChainObject) error {
+						wl.Status.CrossNamespaceObjects = append(wl.Status.CrossNamespaceObjects, stamped)
+						return nil
+					}
+
+					tracker = &controllerfakes.FakeDynamicTracker{}
+					reconciler.AddTracking(tracker)
+
+					wl.UID = "some-uid"
+					repo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+						obj.SetLabels(map[string]string{
+							"carto.run/workload-name":      wl.Name,
+							"carto.run/workload-namespace": wl.Namespace,
+							v1alpha1.WorkloadUIDLabel:      string(wl.UID),
+						})
+						return nil
+					}
+				})
+
+				It("adds the cleanup finalizer before realizing", func() {
+					repo.UpdateStub = func(context.Context, client.Object) error {
+						Expect(rlzr.RealizeCallCount()).To(Equal(0))
+						return nil
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(repo.UpdateCallCount()).To(Equal(1))
+					_, updated := repo.UpdateArgsForCall(0)
+					Expect(updated.GetFinalizers()).To(ConsistOf(v1alpha1.CrossNamespaceCleanupFinalizer))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
+
+				It("does not add the finalizer twice", func() {
+					wl.Finalizers = []string{v1alpha1.CrossNamespaceCleanupFinalizer}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(repo.UpdateCallCount()).To(Equal(0))
+				})
+
+				It("does not realize the supply chain when the finalizer cannot be added", func() {
+					repo.UpdateReturns(errors.New("conflict"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("add finalizer: conflict"))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+
+				It("records the stamped objects in the workload's status", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(wl.Status.CrossNamespaceObjects).To(Equal([]v1alpha1.ObjectReference{stamped}))
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				})
+
+				It("watches the stamped objects", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(tracker.WatchCallCount()).To(Equal(1))
+					_, watched, _ := tracker.WatchArgsForCall(0)
+					Expect(watched.(*unstructured.Unstructured).GetName()).To(Equal("new-config"))
+				})
+
+				It("deletes objects that are no longer stamped", func() {
+					wl.Status.CrossNamespaceObjects = []v1alpha1.ObjectReference{stale}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(repo.DeleteUnstructuredCallCount()).To(Equal(1))
+					_, deleted := repo.DeleteUnstructuredArgsForCall(0)
+					Expect(deleted.GetName()).To(Equal("old-config"))
+					Expect(deleted.GetNamespace()).To(Equal("shared-builds"))
+					Expect(wl.Status.CrossNamespaceObjects).To(Equal([]v1alpha1.ObjectReference{stamped}))
+				})
+
+				It("leaves objects no longer stamped for the workload", func() {
+					wl.Status.CrossNamespaceObjects = []v1alpha1.ObjectReference{stale}
+					repo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+						obj.SetLabels(map[string]string{"carto.run/workload-name": wl.Name})
+						return nil
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, read := repo.GetUnstructuredArgsForCall(0)
+					Expect(read.GetName()).To(Equal("old-config"))
+					Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+					Expect(wl.Status.CrossNamespaceObjects).To(Equal([]v1alpha1.ObjectReference{stamped}))
+				})
+
+				It("does not delete objects that are already gone", func() {
+					wl.Status.CrossNamespaceObjects = []v1alpha1.ObjectReference{stale}
+					repo.GetUnstructuredStub = nil
+					repo.GetUnstructuredReturns(kerrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "old-config"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+					Expect(wl.Status.CrossNamespaceObjects).To(Equal([]v1alpha1.ObjectReference{stamped}))
+				})
+
+				It("keeps objects it could not delete", func() {
+					wl.Status.CrossNamespaceObjects = []v1alpha1.ObjectReference{stale}
+					repo.DeleteUnstructuredReturns(errors.New("forbidden"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(wl.Status.CrossNamespaceObjects).To(ConsistOf(stamped, stale))
+				})
+
+				It("keeps every recorded object when realization fails", func() {
+					wl.Status.CrossNamespaceObjects = []v1alpha1.ObjectReference{stale}
+					rlzr.RealizeStub = nil
+					rlzr.RealizeReturns(errors.New("realizing is hard"))
+
+					_, _ = reconciler.Reconcile(ctx, req)
+
+					Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+					Expect(wl.Status.CrossNamespaceObjects).To(Equal([]v1alpha1.ObjectReference{stale}))
+				})
+
+				It("does not update the status when the recorded objects are unchanged", func() {
+					wl.Status.CrossNamespaceObjects = []v1alpha1.ObjectReference{stamped}
+					wl.Status.History = []v1alpha1.RealizationRecord{{Generation: 1, Blueprint: "some-supply-chain"}}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(repo.StatusUpdateCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("and the supply chain has pre-delete hooks", func() {
+				BeforeEach(func() {
+					supplyChain.Spec.PreDelete = []v1alpha1.PreDeleteHook{
+						{Name: "release-dns", TemplateRef: v1alpha1.PreDeleteHookTemplateReference{Kind: "ClusterTemplate", Name: "dns-cleanup"}},
+					}
+					repo.GetSupplyChainsForWorkloadReturns([]v1alpha1.ClusterSupplyChain{supplyChain}, nil)
+				})
+
+				It("adds the pre-delete finalizer before realizing", func() {
+					repo.UpdateStub = func(context.Context, client.Object) error {
+						Expect(rlzr.RealizeCallCount()).To(Equal(0))
+						return nil
+					}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(repo.UpdateCallCount()).To(Equal(1))
+					_, updated := repo.UpdateArgsForCall(0)
+					Expect(updated.GetFinalizers()).To(ConsistOf(v1alpha1.PreDeleteFinalizer))
+					Expect(rlzr.RealizeCallCount()).To(Equal(1))
+				})
+
+				It("does not add the finalizer twice", func() {
+					wl.Finalizers = []string{v1alpha1.PreDeleteFinalizer}
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(repo.UpdateCallCount()).To(Equal(0))
+				})
+			})
+
 			Context("but the condition manager reflects that the workload is not ready", func() {
 				BeforeEach(func() {
 					conditionManager.IsSuccessfulReturns(false)
@@ -467,6 +1910,71 @@ var _ = Describe("Reconciler", func() {
 			})
 		})
 
+		Context("and the repo returns a matching SupplyChain in the workload's namespace", func() {
+			var namespacedSupplyChain v1alpha1.SupplyChain
+
+			BeforeEach(func() {
+				namespacedSupplyChain = v1alpha1.SupplyChain{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "team-supply-chain",
+						Namespace: "my-namespace",
+					},
+					Status: v1alpha1.SupplyChainStatus{
+						Conditions: []metav1.Condition{
+							{
+								Type:   "Ready",
+								Status: "True",
+								Reason: "Ready",
+							},
+						},
+					},
+				}
+				repo.GetNamespacedSupplyChainsForWorkloadReturns([]v1alpha1.SupplyChain{namespacedSupplyChain}, nil)
+				repo.GetSupplyChainsForWorkloadReturns([]v1alpha1.ClusterSupplyChain{{}}, nil)
+			})
+
+			It("realizes the SupplyChain without considering cluster supply chains", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.GetSupplyChainsForWorkloadCallCount()).To(Equal(0))
+				_, _, realized := rlzr.RealizeArgsForCall(0)
+				Expect(realized).To(Equal(&namespacedSupplyChain))
+			})
+
+			It("sets the SupplyChainRef to the SupplyChain", func() {
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(wl.Status.SupplyChainRef.Kind).To(Equal("SupplyChain"))
+				Expect(wl.Status.SupplyChainRef.Name).To(Equal("team-supply-chain"))
+			})
+
+			Context("but the SupplyChain is not ready", func() {
+				BeforeEach(func() {
+					namespacedSupplyChain.Status.Conditions[0].Status = "False"
+					repo.GetNamespacedSupplyChainsForWorkloadReturns([]v1alpha1.SupplyChain{namespacedSupplyChain}, nil)
+				})
+
+				It("does not realize it", func() {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("supply-chain is not in ready condition"))
+					Expect(rlzr.RealizeCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("and repo returns an error when requesting namespaced supply chains", func() {
+			BeforeEach(func() {
+				repo.GetNamespacedSupplyChainsForWorkloadReturns(nil, errors.New("some error"))
+			})
+
+			It("returns a helpful error", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err.Error()).To(ContainSubstring("get supply chain by label: some error"))
+				Expect(repo.GetSupplyChainsForWorkloadCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("but status update fails", func() {
 			BeforeEach(func() {
 				repo.StatusUpdateReturns(errors.New("some error"))
@@ -505,6 +2013,212 @@ var _ = Describe("Reconciler", func() {
 			})
 		})
 
+		Context("the workload is being deleted", func() {
+			var stamped v1alpha1.ObjectReference
+
+			BeforeEach(func() {
+				now := metav1.Now()
+				stamped = v1alpha1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "shared-builds", Name: "some-config"}
+				wl.DeletionTimestamp = &now
+				wl.Finalizers = []string{v1alpha1.CrossNamespaceCleanupFinalizer}
+				wl.Status.CrossNamespaceObjects = []v1alpha1.ObjectReference{stamped}
+				wl.UID = "some-uid"
+				repo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					obj.SetLabels(map[string]string{
+						"carto.run/workload-name":      wl.Name,
+						"carto.run/workload-namespace": wl.Namespace,
+						v1alpha1.WorkloadUIDLabel:      string(wl.UID),
+					})
+					return nil
+				}
+			})
+
+			It("deletes the objects stamped into other namespaces and removes the finalizer", func() {
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+
+				Expect(repo.DeleteUnstructuredCallCount()).To(Equal(1))
+				_, deleted := repo.DeleteUnstructuredArgsForCall(0)
+				Expect(deleted.GetName()).To(Equal("some-config"))
+				Expect(deleted.GetKind()).To(Equal("ConfigMap"))
+
+				Expect(repo.UpdateCallCount()).To(Equal(1))
+				_, updated := repo.UpdateArgsForCall(0)
+				Expect(updated.GetFinalizers()).To(BeEmpty())
+				Expect(rlzr.RealizeCallCount()).To(Equal(0))
+			})
+
+			It("leaves objects not stamped for the workload, and removes the finalizer", func() {
+				repo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					obj.SetLabels(map[string]string{
+						"carto.run/workload-name":      wl.Name,
+						"carto.run/workload-namespace": wl.Namespace,
+						v1alpha1.WorkloadUIDLabel:      "earlier-uid",
+					})
+					return nil
+				}
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+				Expect(repo.UpdateCallCount()).To(Equal(1))
+				_, updated := repo.UpdateArgsForCall(0)
+				Expect(updated.GetFinalizers()).To(BeEmpty())
+			})
+
+			It("keeps the finalizer when an object cannot be read", func() {
+				repo.GetUnstructuredStub = nil
+				repo.GetUnstructuredReturns(errors.New("forbidden"))
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).To(MatchError(ContainSubstring("delete cross-namespace object ConfigMap 'shared-builds/some-config': forbidden")))
+				Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+				Expect(repo.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("keeps the finalizer when an object cannot be deleted", func() {
+				repo.DeleteUnstructuredReturns(errors.New("forbidden"))
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).To(MatchError(ContainSubstring("delete cross-namespace object ConfigMap 'shared-builds/some-config': forbidden")))
+				Expect(repo.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("does nothing without the finalizer", func() {
+				wl.Finalizers = nil
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+				Expect(repo.UpdateCallCount()).To(Equal(0))
+			})
+
+			Context("with the pre-delete finalizer", func() {
+				var (
+					jobCondition string
+					tracker      *controllerfakes.FakeDynamicTracker
+				)
+
+				BeforeEach(func() {
+					wl.Finalizers = []string{v1alpha1.PreDeleteFinalizer, v1alpha1.CrossNamespaceCleanupFinalizer}
+					wl.Status.SupplyChainRef = v1alpha1.ObjectReference{Kind: "ClusterSupplyChain", Name: "some-supply-chain"}
+
+					repo.GetSupplyChainReturns(&v1alpha1.ClusterSupplyChain{
+						ObjectMeta: metav1.ObjectMeta{Name: "some-supply-chain"},
+						Spec: v1alpha1.SupplyChainSpec{
+							PreDelete: []v1alpha1.PreDeleteHook{
+								{Name: "release-dns", TemplateRef: v1alpha1.PreDeleteHookTemplateReference{Kind: "ClusterTemplate", Name: "dns-cleanup"}},
+							},
+						},
+					}, nil)
+
+					apiTemplate := &v1alpha1.ClusterTemplate{}
+					apiTemplate.Name = "dns-cleanup"
+					apiTemplate.Spec.Template = &runtime.RawExtension{Raw: []byte(`{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "dns-cleanup"}, "spec": {}}`)}
+					template, err := templates.NewModelFromAPI(apiTemplate)
+					Expect(err).NotTo(HaveOccurred())
+					repo.GetClusterTemplateReturns(template, nil)
+
+					jobCondition = ""
+					repo.EnsureImmutableObjectExistsOnClusterStub = func(_ context.Context, job *unstructured.Unstructured) error {
+						if jobCondition != "" {
+							Expect(unstructured.SetNestedSlice(job.Object, []interface{}{
+								map[string]interface{}{"type": jobCondition, "status": "True", "message": "exit 1"},
+							}, "status", "conditions")).To(Succeed())
+						}
+						return nil
+					}
+
+					tracker = &controllerfakes.FakeDynamicTracker{}
+					reconciler.AddTracking(tracker)
+				})
+
+				It("runs the hooks of the supply chain, and waits for them, before cleaning up", func() {
+					result, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.RequeueAfter).To(Equal(workload.DefaultResyncInterval))
+
+					Expect(repo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(1))
+					_, job := repo.EnsureImmutableObjectExistsOnClusterArgsForCall(0)
+					Expect(job.GetLabels()).To(HaveKeyWithValue("carto.run/pre-delete-hook", "release-dns"))
+					Expect(tracker.WatchCallCount()).To(Equal(1))
+
+					Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+					Expect(repo.UpdateCallCount()).To(Equal(0))
+
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+					Expect(wl.Status.Conditions).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+						"Type":   Equal(v1alpha1.PreDeleteHooksCompleted),
+						"Status": Equal(metav1.ConditionFalse),
+						"Reason": Equal(v1alpha1.RunningPreDeleteHooksCompletedReason),
+					})))
+				})
+
+				It("does not update the status again while the same hook runs", func() {
+					_, _ = reconciler.Reconcile(ctx, req)
+					_, _ = reconciler.Reconcile(ctx, req)
+					Expect(repo.StatusUpdateCallCount()).To(Equal(1))
+				})
+
+				It("cleans up and removes both finalizers once the hooks complete", func() {
+					jobCondition = "Complete"
+
+					result, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(ctrl.Result{}))
+
+					Expect(repo.DeleteUnstructuredCallCount()).To(Equal(1))
+					Expect(repo.UpdateCallCount()).To(Equal(1))
+					_, updated := repo.UpdateArgsForCall(0)
+					Expect(updated.GetFinalizers()).To(BeEmpty())
+				})
+
+				It("holds the deletion back when a hook fails", func() {
+					jobCondition = "Failed"
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(repo.UpdateCallCount()).To(Equal(0))
+					Expect(wl.Status.Conditions).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+						"Type":    Equal(v1alpha1.PreDeleteHooksCompleted),
+						"Reason":  Equal(v1alpha1.FailedPreDeleteHooksCompletedReason),
+						"Message": ContainSubstring("pre-delete hook 'release-dns': job '/dns-cleanup-"),
+					})))
+				})
+
+				It("removes the finalizers when the supply chain no longer exists", func() {
+					repo.GetSupplyChainReturns(nil, nil)
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(repo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(0))
+					Expect(repo.UpdateCallCount()).To(Equal(1))
+				})
+
+				It("reads a namespaced supply chain from the workload's namespace", func() {
+					wl.Status.SupplyChainRef.Kind = "SupplyChain"
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(repo.GetNamespacedSupplyChainCallCount()).To(Equal(1))
+					_, name, namespace := repo.GetNamespacedSupplyChainArgsForCall(0)
+					Expect(name).To(Equal("some-supply-chain"))
+					Expect(namespace).To(Equal(wl.Namespace))
+				})
+
+				It("returns an error when the supply chain cannot be read", func() {
+					repo.GetSupplyChainReturns(nil, errors.New("unavailable"))
+
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).To(MatchError("get supply chain: unavailable"))
+					Expect(repo.UpdateCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		Context("workload is deleted", func() { // Todo: can we move error handling out of repo to make this more obvious?
 			BeforeEach(func() {
 				repo.GetWorkloadReturns(nil, kerrors.NewNotFound(schema.GroupResource{
@@ -518,6 +2232,19 @@ var _ = Describe("Reconciler", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result).To(Equal(ctrl.Result{Requeue: false}))
 			})
+
+			It("forgets the objects stamped for the workload", func() {
+				evicter := &controllerfakes.FakeCacheEvicter{}
+				reconciler.AddCacheEviction(evicter)
+
+				_, _ = reconciler.Reconcile(ctx, req)
+
+				Expect(evicter.EvictOwnedCallCount()).To(Equal(1))
+				Expect(evicter.EvictOwnedArgsForCall(0)).To(Equal(map[string]string{
+					"carto.run/workload-name":      "my-workload-name",
+					"carto.run/workload-namespace": "my-namespace",
+				}))
+			})
 		})
 
 	})
@@ -0,0 +1,27 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// AddScopeDetection lets the realizer tell the objects of cluster-scoped
+// kinds from those of namespaced kinds by the mapper, to stamp them without
+// a namespace or an owner reference and delete them along with the
+// workload, as it does objects stamped into other namespaces.
+func (r *Reconciler) AddScopeDetection(mapper meta.RESTMapper) {
+	r.restMapper = mapper
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+//counterfeiter:generate . SourceResolver
+type SourceResolver interface {
+	Resolve(ctx context.Context, namespace string, source v1alpha1.OCISource) (*v1alpha1.ResolvedSource, error)
+}
+
+// AddSourceResolution lets the reconciler resolve workloads' OCI sources to
+// the digests they point at, so that artifacts pushed again are realized.
+func (r *Reconciler) AddSourceResolution(resolver SourceResolver) {
+	r.sourceResolver = resolver
+}
+
+// resolveSource publishes the workload's OCI source, as resolved, in its
+// status. When the source cannot be resolved, the workload keeps the digest
+// it last resolved the same image to.
+func (r *Reconciler) resolveSource(ctx context.Context, workload *v1alpha1.Workload) error {
+	previous := workload.Status.Source
+	source := workload.Spec.Source
+	if source == nil || source.OCI == nil {
+		workload.Status.Source = nil
+		r.sourceChanged = previous != nil
+		return nil
+	}
+	if source.Git != nil || source.Image != nil {
+		return fmt.Errorf("source.oci cannot be set with source.git or source.image")
+	}
+	if r.sourceResolver == nil {
+		return nil
+	}
+
+	resolved, err := r.sourceResolver.Resolve(ctx, workload.Namespace, *source.OCI)
+	if err != nil {
+		if previous == nil || previous.Image != source.OCI.Image {
+			return err
+		}
+		logr.FromContext(ctx).Error(err, "resolve source, keeping last digest", "digest", previous.Digest)
+		return nil
+	}
+
+	workload.Status.Source = resolved
+	r.sourceChanged = !equality.Semantic.DeepEqual(previous, resolved)
+	return nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package describe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+type resource struct {
+	name         string
+	description  string
+	templateKind string
+	templateName string
+	// templateVersion is the version the template is pinned to, if any.
+	templateVersion string
+	// templateDisplayName names the template in the description.
+	templateDisplayName string
+	// fromGit is set for templates fetched from git, which are not read.
+	fromGit bool
+	// options choose the template by the workload. Their templates are
+	// not read.
+	options []v1alpha1.TemplateOption
+}
+
+// SupplyChain writes a description of the named ClusterSupplyChain to out:
+// its description and selector, then each resource along with the params
+// its template accepts.
+func SupplyChain(ctx context.Context, reader client.Reader, name string, out io.Writer) error {
+	supplyChain := &v1alpha1.ClusterSupplyChain{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: name}, supplyChain); err != nil {
+		return fmt.Errorf("get supply chain '%s': %w", name, err)
+	}
+
+	var resources []resource
+	for _, r := range supplyChain.Spec.Resources {
+		resources = append(resources, resource{
+			name:                r.Name,
+			description:         r.Description,
+			templateKind:        r.TemplateRef.Kind,
+			templateName:        r.TemplateRef.Name,
+			templateVersion:     r.TemplateRef.Version,
+			templateDisplayName: r.TemplateRef.DisplayName(),
+			fromGit:             r.TemplateRef.Git != nil,
+			options:             r.TemplateRef.Options,
+		})
+	}
+
+	return describe(ctx, reader, out, supplyChain.Name, supplyChain.Spec.Description, supplyChain.Spec.Selector, resources)
+}
+
+// Delivery writes a description of the named ClusterDelivery to out, in the
+// same form as SupplyChain.
+func Delivery(ctx context.Context, reader client.Reader, name string, out io.Writer) error {
+	delivery := &v1alpha1.ClusterDelivery{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: name}, delivery); err != nil {
+		return fmt.Errorf("get delivery '%s': %w", name, err)
+	}
+
+	var resources []resource
+	for _, r := range delivery.Spec.Resources {
+		resources = append(resources, resource{
+			name:                r.Name,
+			description:         r.Description,
+			templateKind:        r.TemplateRef.Kind,
+			templateName:        r.TemplateRef.Name,
+			templateVersion:     r.TemplateRef.Version,
+			templateDisplayName: r.TemplateRef.DisplayName(),
+			fromGit:             r.TemplateRef.Git != nil,
+		})
+	}
+
+	return describe(ctx, reader, out, delivery.Name, delivery.Spec.Description, delivery.Spec.Selector, resources)
+}
+
+func describe(ctx context.Context, reader client.Reader, out io.Writer, name, description string, selector map[string]string, resources []resource) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Name:\t%s\n", name)
+	fmt.Fprintf(w, "Description:\t%s\n", description)
+	fmt.Fprintf(w, "Selector:\t%s\n", formatSelector(selector))
+	fmt.Fprintf(w, "\nResources:\n")
+
+	for _, r := range resources {
+		fmt.Fprintf(w, "  %s (%s/%s)\n", r.name, r.templateKind, r.templateDisplayName)
+		if r.description != "" {
+			fmt.Fprintf(w, "    %s\n", r.description)
+		}
+
+		if r.fromGit {
+			fmt.Fprintf(w, "    Params:\t<fetched from git>\n")
+			continue
+		}
+
+		if len(r.options) > 0 {
+			fmt.Fprintf(w, "    Options:\n")
+			fmt.Fprintf(w, "      NAME\tSELECTOR\n")
+			for _, option := range r.options {
+				fmt.Fprintf(w, "      %s\t%s\n", option.Name, formatOptionSelector(option.Selector))
+			}
+			continue
+		}
+
+		params, err := templateParams(ctx, reader, r.templateKind, r.templateName, r.templateVersion)
+		if err != nil {
+			if !kerrors.IsNotFound(err) {
+				return err
+			}
+			fmt.Fprintf(w, "    Params:\t<template not found>\n")
+			continue
+		}
+		if len(params) == 0 {
+			fmt.Fprintf(w, "    Params:\t<none>\n")
+			continue
+		}
+
+		fmt.Fprintf(w, "    Params:\n")
+		fmt.Fprintf(w, "      NAME\tDEFAULT\tDESCRIPTION\n")
+		for _, param := range params {
+			fmt.Fprintf(w, "      %s\t%s\t%s\n", param.Name, string(param.DefaultValue.Raw), param.Description)
+		}
+	}
+
+	return w.Flush()
+}
+
+func templateParams(ctx context.Context, reader client.Reader, kind, name, version string) (v1alpha1.DefaultParams, error) {
+	var apiTemplate client.Object
+	switch kind {
+	case "ClusterSourceTemplate":
+		apiTemplate = &v1alpha1.ClusterSourceTemplate{}
+	case "ClusterImageTemplate":
+		apiTemplate = &v1alpha1.ClusterImageTemplate{}
+	case "ClusterConfigTemplate":
+		apiTemplate = &v1alpha1.ClusterConfigTemplate{}
+	case "ClusterDeploymentTemplate":
+		apiTemplate = &v1alpha1.ClusterDeploymentTemplate{}
+	case "ClusterTemplate":
+		apiTemplate = &v1alpha1.ClusterTemplate{}
+	default:
+		return nil, fmt.Errorf("unknown template kind '%s'", kind)
+	}
+
+	if version == "" {
+		if err := reader.Get(ctx, types.NamespacedName{Name: name}, apiTemplate); err != nil {
+			return nil, err
+		}
+	} else if err := getTemplateVersion(ctx, reader, kind, name, version, apiTemplate); err != nil {
+		return nil, err
+	}
+
+	template, err := templates.NewModelFromAPI(apiTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return template.GetDefaultParams(), nil
+}
+
+// getTemplateVersion reads into apiTemplate the template of kind labelled
+// as the given version of the named template.
+func getTemplateVersion(ctx context.Context, reader client.Reader, kind, name, version string, apiTemplate client.Object) error {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(kind + "List"))
+	if err := reader.List(ctx, list, client.MatchingLabels{
+		v1alpha1.TemplateNameLabel:    name,
+		v1alpha1.TemplateVersionLabel: version,
+	}); err != nil {
+		return err
+	}
+	if len(list.Items) != 1 {
+		return kerrors.NewNotFound(v1alpha1.SchemeGroupVersion.WithResource(kind).GroupResource(), name+"@"+version)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[0].Object, apiTemplate)
+}
+
+func formatOptionSelector(selector v1alpha1.OptionSelector) string {
+	var requirements []string
+	if len(selector.MatchLabels) > 0 || len(selector.MatchExpressions) > 0 {
+		requirements = append(requirements, metav1.FormatLabelSelector(&selector.LabelSelector))
+	}
+	// fields are written the way label selectors write their expressions.
+	for _, field := range selector.MatchFields {
+		switch field.Operator {
+		case v1alpha1.FieldSelectorOpExists:
+			requirements = append(requirements, field.Key)
+		case v1alpha1.FieldSelectorOpDoesNotExist:
+			requirements = append(requirements, "!"+field.Key)
+		default:
+			requirements = append(requirements, fmt.Sprintf("%s %s (%s)", field.Key, strings.ToLower(string(field.Operator)), strings.Join(field.Values, ",")))
+		}
+	}
+	return strings.Join(requirements, ",")
+}
+
+func formatSelector(selector map[string]string) string {
+	var pairs []string
+	for key, value := range selector {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
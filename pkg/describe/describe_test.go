@@ -0,0 +1,457 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package describe_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/describe"
+)
+
+var _ = Describe("Describe", func() {
+	var (
+		ctx     context.Context
+		objects []client.Object
+		reader  client.Reader
+		out     *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		out = &bytes.Buffer{}
+
+		objects = []client.Object{
+			&v1alpha1.ClusterSourceTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "git-source"},
+				Spec: v1alpha1.SourceTemplateSpec{
+					TemplateSpec: v1alpha1.TemplateSpec{
+						Params: v1alpha1.DefaultParams{
+							{
+								Name:         "git-implementation",
+								DefaultValue: apiextensionsv1.JSON{Raw: []byte(`"libgit2"`)},
+								Description:  "Git library used to fetch source",
+							},
+						},
+					},
+				},
+			},
+			&v1alpha1.ClusterTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-deploy"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		reader = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	})
+
+	Describe("SupplyChain", func() {
+		BeforeEach(func() {
+			objects = append(objects, &v1alpha1.ClusterSupplyChain{
+				ObjectMeta: metav1.ObjectMeta{Name: "source-to-url"},
+				Spec: v1alpha1.SupplyChainSpec{
+					Description: "Builds and runs web workloads",
+					Selector:    map[string]string{"workload-type": "web", "app": "web"},
+					Resources: []v1alpha1.SupplyChainResource{
+						{
+							Name:        "source-provider",
+							Description: "Fetches source from git",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterSourceTemplate", Name: "git-source"},
+						},
+						{
+							Name:        "deployer",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "app-deploy"},
+						},
+						{
+							Name:        "missing",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "not-there"},
+						},
+						{
+							Name:        "pinned",
+							TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "app-deploy", Version: "v2"},
+						},
+					},
+				},
+			}, &v1alpha1.ClusterTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-deploy-v2",
+					Labels: map[string]string{
+						v1alpha1.TemplateNameLabel:    "app-deploy",
+						v1alpha1.TemplateVersionLabel: "v2",
+					},
+				},
+				Spec: v1alpha1.TemplateSpec{
+					Params: v1alpha1.DefaultParams{
+						{Name: "replicas", DefaultValue: apiextensionsv1.JSON{Raw: []byte(`2`)}},
+					},
+				},
+			})
+		})
+
+		It("writes the blueprint, its resources and their template params", func() {
+			Expect(describe.SupplyChain(ctx, reader, "source-to-url", out)).To(Succeed())
+			Expect(out.String()).To(Equal(`Name:         source-to-url
+Description:  Builds and runs web workloads
+Selector:     app=web,workload-type=web
+
+Resources:
+  source-provider (ClusterSourceTemplate/git-source)
+    Fetches source from git
+    Params:
+      NAME                DEFAULT    DESCRIPTION
+      git-implementation  "libgit2"  Git library used to fetch source
+  deployer (ClusterTemplate/app-deploy)
+    Params:  <none>
+  missing (ClusterImageTemplate/not-there)
+    Params:  <template not found>
+  pinned (ClusterTemplate/app-deploy@v2)
+    Params:
+      NAME      DEFAULT  DESCRIPTION
+      replicas  2        
+`))
+		})
+
+		It("returns an error when the supply chain does not exist", func() {
+			err := describe.SupplyChain(ctx, reader, "nope", out)
+			Expect(err).To(MatchError(ContainSubstring("get supply chain 'nope'")))
+		})
+	})
+
+	Describe("SupplyChain choosing templates by option", func() {
+		BeforeEach(func() {
+			objects = append(objects, &v1alpha1.ClusterSupplyChain{
+				ObjectMeta: metav1.ObjectMeta{Name: "polyglot"},
+				Spec: v1alpha1.SupplyChainSpec{
+					Resources: []v1alpha1.SupplyChainResource{
+						{
+							Name: "image-builder",
+							TemplateRef: v1alpha1.ClusterTemplateReference{
+								Kind: "ClusterImageTemplate",
+								Options: []v1alpha1.TemplateOption{
+									{
+										Name: "java-build",
+										Selector: v1alpha1.OptionSelector{
+											LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"language": "java"}},
+										},
+									},
+									{
+										Name: "go-build",
+										Selector: v1alpha1.OptionSelector{
+											MatchFields: []v1alpha1.FieldSelectorRequirement{
+												{Key: "spec.source.git.url", Operator: v1alpha1.FieldSelectorOpIn, Values: []string{"https://a", "https://b"}},
+												{Key: "spec.image", Operator: v1alpha1.FieldSelectorOpDoesNotExist},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			})
+		})
+
+		It("writes the options and their selectors", func() {
+			Expect(describe.SupplyChain(ctx, reader, "polyglot", out)).To(Succeed())
+			Expect(out.String()).To(HaveSuffix(`Resources:
+  image-builder (ClusterImageTemplate/java-build|go-build)
+    Options:
+      NAME        SELECTOR
+      java-build  language=java
+      go-build    spec.source.git.url in (https://a,https://b),!spec.image
+`))
+		})
+	})
+
+	Describe("Delivery", func() {
+		BeforeEach(func() {
+			objects = append(objects, &v1alpha1.ClusterDelivery{
+				ObjectMeta: metav1.ObjectMeta{Name: "delivery"},
+				Spec: v1alpha1.ClusterDeliverySpec{
+					Description: "Deploys to production",
+					Resources: []v1alpha1.ClusterDeliveryResource{
+						{
+							Name:        "source-provider",
+							Description: "Fetches configuration from git",
+							TemplateRef: v1alpha1.DeliveryClusterTemplateReference{Kind: "ClusterSourceTemplate", Name: "git-source"},
+						},
+					},
+				},
+			})
+		})
+
+		It("writes the blueprint, its resources and their template params", func() {
+			Expect(describe.Delivery(ctx, reader, "delivery", out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("Description:  Deploys to production\n"))
+			Expect(out.String()).To(ContainSubstring("    Fetches configuration from git\n"))
+			Expect(out.String()).To(ContainSubstring(`git-implementation  "libgit2"  Git library used to fetch source`))
+		})
+	})
+
+	Describe("Workload", func() {
+		var (
+			created  time.Time
+			workload *v1alpha1.Workload
+		)
+
+		BeforeEach(func() {
+			created = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+			url, branch := "https://github.com/example/app", "main"
+			workload = &v1alpha1.Workload{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "app",
+					Namespace:         "dev",
+					Generation:        2,
+					CreationTimestamp: metav1.NewTime(created),
+				},
+				Spec: v1alpha1.WorkloadSpec{
+					ServiceAccountName: "builder",
+					Source: &v1alpha1.Source{
+						Git: &v1alpha1.GitSource{URL: &url, Ref: &v1alpha1.GitRef{Branch: &branch}},
+					},
+					Params: []v1alpha1.Param{
+						{Name: "port", Value: apiextensionsv1.JSON{Raw: []byte(`8080`)}},
+					},
+				},
+				Status: v1alpha1.WorkloadStatus{
+					ObservedGeneration: 2,
+					SupplyChainRef:     v1alpha1.ObjectReference{Kind: "ClusterSupplyChain", Name: "source-to-url"},
+					Conditions: []metav1.Condition{
+						{
+							Type:               "SupplyChainReady",
+							Status:             metav1.ConditionTrue,
+							Reason:             "Ready",
+							LastTransitionTime: metav1.NewTime(created.Add(time.Minute)),
+						},
+						{
+							Type:               "ResourcesSubmitted",
+							Status:             metav1.ConditionFalse,
+							Reason:             "TemplateRejectedByAPIServer",
+							Message:            "configmaps is forbidden",
+							LastTransitionTime: metav1.NewTime(created.Add(3 * time.Minute)),
+						},
+						{
+							Type:               "Ready",
+							Status:             metav1.ConditionFalse,
+							Reason:             "TemplateRejectedByAPIServer",
+							Message:            "configmaps is forbidden",
+							LastTransitionTime: metav1.NewTime(created.Add(3 * time.Minute)),
+						},
+					},
+					Retries: []v1alpha1.ResourceRetries{{Resource: "config-writer", Retries: 4}},
+				},
+			}
+
+			objects = append(objects,
+				workload,
+				&v1alpha1.ClusterSupplyChain{
+					ObjectMeta: metav1.ObjectMeta{Name: "source-to-url"},
+					Spec: v1alpha1.SupplyChainSpec{
+						Selector: map[string]string{"workload-type": "web"},
+						Resources: []v1alpha1.SupplyChainResource{
+							{
+								Name:        "source-provider",
+								TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "source-config"},
+							},
+							{
+								Name:        "config-writer",
+								TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "app-deploy"},
+							},
+						},
+					},
+				},
+				&v1alpha1.ClusterTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "source-config"},
+					Spec: v1alpha1.TemplateSpec{
+						Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "$(workload.metadata.name)$"}}`)},
+					},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "app",
+						Namespace: "dev",
+						Labels: map[string]string{
+							"carto.run/workload-name":             "app",
+							"carto.run/workload-namespace":        "dev",
+							"carto.run/cluster-supply-chain-name": "source-to-url",
+							"carto.run/resource-name":             "source-provider",
+						},
+					},
+				},
+				&corev1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "app.1", Namespace: "dev"},
+					InvolvedObject: corev1.ObjectReference{Kind: "ConfigMap", Namespace: "dev", Name: "app"},
+					Type:           "Normal",
+					Reason:         "Updated",
+					Message:        "config map updated",
+					LastTimestamp:  metav1.NewTime(created.Add(2 * time.Minute)),
+				},
+				&corev1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "app.2", Namespace: "dev"},
+					InvolvedObject: corev1.ObjectReference{Kind: "Workload", Namespace: "dev", Name: "app"},
+					Type:           "Warning",
+					Reason:         "Forbidden",
+					Message:        "configmaps is forbidden",
+					LastTimestamp:  metav1.NewTime(created.Add(3 * time.Minute)),
+				},
+				&corev1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "other.1", Namespace: "dev"},
+					InvolvedObject: corev1.ObjectReference{Kind: "Workload", Namespace: "dev", Name: "other"},
+					Type:           "Normal",
+					Reason:         "Unrelated",
+					LastTimestamp:  metav1.NewTime(created),
+				},
+			)
+		})
+
+		It("writes the workload's spec, resources, history, events and health", func() {
+			Expect(describe.Workload(ctx, reader, types.NamespacedName{Namespace: "dev", Name: "app"}, out)).To(Succeed())
+			Expect(out.String()).To(Equal(`Name:          app
+Namespace:     dev
+Created:       2026-10-01T12:00:00Z
+Health:        Not ready (TemplateRejectedByAPIServer): configmaps is forbidden
+Supply chain:  ClusterSupplyChain/source-to-url
+
+Spec:
+  Source:           git https://github.com/example/app branch main
+  Service account:  builder
+  Params:
+    port  8080
+
+Resources:
+  NAME             TEMPLATE                       OBJECT         READY    RETRIES
+  source-provider  ClusterTemplate/source-config  ConfigMap/app  Unknown  0
+  config-writer    ClusterTemplate/app-deploy     <none>                  4
+
+History:
+  SINCE                 CONDITION           STATUS  REASON                       MESSAGE
+  2026-10-01T12:03:00Z  ResourcesSubmitted  False   TemplateRejectedByAPIServer  configmaps is forbidden
+  2026-10-01T12:03:00Z  Ready               False   TemplateRejectedByAPIServer  configmaps is forbidden
+  2026-10-01T12:01:00Z  SupplyChainReady    True    Ready                        
+
+Events:
+  LAST SEEN             TYPE     REASON     OBJECT         MESSAGE
+  2026-10-01T12:02:00Z  Normal   Updated    ConfigMap/app  config map updated
+  2026-10-01T12:03:00Z  Warning  Forbidden  Workload/app   configmaps is forbidden
+`))
+		})
+
+		Context("when its generation is not realized yet", func() {
+			BeforeEach(func() {
+				workload.Generation = 3
+			})
+
+			It("says so in its health", func() {
+				Expect(describe.Workload(ctx, reader, types.NamespacedName{Namespace: "dev", Name: "app"}, out)).To(Succeed())
+				Expect(out.String()).To(ContainSubstring("Health:        Not ready (TemplateRejectedByAPIServer): configmaps is forbidden, generation 3 not realized yet\n"))
+			})
+		})
+
+		Context("when it matched no supply chain", func() {
+			BeforeEach(func() {
+				workload.Status = v1alpha1.WorkloadStatus{}
+			})
+
+			It("writes no resources", func() {
+				Expect(describe.Workload(ctx, reader, types.NamespacedName{Namespace: "dev", Name: "app"}, out)).To(Succeed())
+				Expect(out.String()).To(ContainSubstring("Health:        Unknown: not reconciled yet\n"))
+				Expect(out.String()).To(ContainSubstring("Supply chain:  <none>\n"))
+				Expect(out.String()).To(ContainSubstring("Resources:\n  <none>\n"))
+				Expect(out.String()).To(ContainSubstring("History:\n  <none>\n"))
+			})
+		})
+
+		Context("when its supply chain is gone", func() {
+			BeforeEach(func() {
+				workload.Status.SupplyChainRef.Name = "deleted"
+			})
+
+			It("says so in place of its resources", func() {
+				Expect(describe.Workload(ctx, reader, types.NamespacedName{Namespace: "dev", Name: "app"}, out)).To(Succeed())
+				Expect(out.String()).To(ContainSubstring("Supply chain:  ClusterSupplyChain/deleted\n"))
+				Expect(out.String()).To(ContainSubstring("Resources:\n  <supply chain not found>\n"))
+			})
+		})
+
+		Context("when it matched a supply chain in its namespace", func() {
+			BeforeEach(func() {
+				workload.Status.SupplyChainRef = v1alpha1.ObjectReference{Kind: "SupplyChain", Namespace: "dev", Name: "team-source-to-url"}
+				objects = append(objects, &v1alpha1.SupplyChain{
+					ObjectMeta: metav1.ObjectMeta{Name: "team-source-to-url", Namespace: "dev"},
+					Spec: v1alpha1.SupplyChainSpec{
+						Selector: map[string]string{"workload-type": "web"},
+						Resources: []v1alpha1.SupplyChainResource{
+							{
+								Name:        "team-config",
+								TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "app-deploy"},
+							},
+						},
+					},
+				})
+			})
+
+			It("writes the resources of that supply chain", func() {
+				Expect(describe.Workload(ctx, reader, types.NamespacedName{Namespace: "dev", Name: "app"}, out)).To(Succeed())
+				Expect(out.String()).To(ContainSubstring("Supply chain:  SupplyChain/team-source-to-url\n"))
+				Expect(out.String()).To(ContainSubstring("  team-config  ClusterTemplate/app-deploy  <none>"))
+				Expect(out.String()).NotTo(ContainSubstring("source-provider"))
+			})
+		})
+
+		Context("when there are many events", func() {
+			BeforeEach(func() {
+				for i := 0; i < 12; i++ {
+					objects = append(objects, &corev1.Event{
+						ObjectMeta:     metav1.ObjectMeta{Name: fmt.Sprintf("app.%d", i+10), Namespace: "dev"},
+						InvolvedObject: corev1.ObjectReference{Kind: "Workload", Namespace: "dev", Name: "app"},
+						Type:           "Normal",
+						Reason:         fmt.Sprintf("Reason%d", i),
+						LastTimestamp:  metav1.NewTime(created.Add(time.Hour + time.Duration(i)*time.Minute)),
+					})
+				}
+			})
+
+			It("writes only the latest ten", func() {
+				Expect(describe.Workload(ctx, reader, types.NamespacedName{Namespace: "dev", Name: "app"}, out)).To(Succeed())
+				Expect(out.String()).NotTo(ContainSubstring("Forbidden"))
+				Expect(out.String()).NotTo(ContainSubstring("Reason1 "))
+				Expect(out.String()).To(ContainSubstring("Reason2 "))
+				Expect(out.String()).To(HaveSuffix("Reason11  Workload/app  \n"))
+			})
+		})
+
+		It("returns an error when the workload does not exist", func() {
+			err := describe.Workload(ctx, reader, types.NamespacedName{Namespace: "dev", Name: "nope"}, out)
+			Expect(err).To(MatchError(ContainSubstring("get workload 'dev/nope'")))
+		})
+	})
+})
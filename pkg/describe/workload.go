@@ -0,0 +1,287 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package describe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/graph"
+)
+
+// maxEvents is the number of recent events a description lists.
+const maxEvents = 10
+
+// Workload writes a description of the named workload to out: its spec, the
+// supply chain it matched, the object stamped for each resource, the
+// transitions of its conditions, recent events of the workload and its
+// objects, and its health.
+func Workload(ctx context.Context, reader client.Reader, name types.NamespacedName, out io.Writer) error {
+	workload := &v1alpha1.Workload{}
+	if err := reader.Get(ctx, name, workload); err != nil {
+		return fmt.Errorf("get workload '%s': %w", name, err)
+	}
+
+	g, err := workloadGraph(ctx, reader, workload)
+	if err != nil {
+		return err
+	}
+
+	involved := []corev1.ObjectReference{{Kind: "Workload", Namespace: workload.Namespace, Name: workload.Name}}
+	if g != nil {
+		for _, node := range g.Nodes {
+			if node.Object != nil {
+				involved = append(involved, corev1.ObjectReference{Kind: node.Object.Kind, Namespace: node.Object.Namespace, Name: node.Object.Name})
+			}
+		}
+	}
+	events, err := recentEvents(ctx, reader, involved)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Name:\t%s\n", workload.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", workload.Namespace)
+	fmt.Fprintf(w, "Created:\t%s\n", formatTime(workload.CreationTimestamp.Time))
+	fmt.Fprintf(w, "Health:\t%s\n", health(workload))
+	if ref := workload.Status.SupplyChainRef; ref.Name != "" {
+		fmt.Fprintf(w, "Supply chain:\t%s/%s\n", ref.Kind, ref.Name)
+	} else {
+		fmt.Fprintf(w, "Supply chain:\t<none>\n")
+	}
+
+	fmt.Fprintf(w, "\nSpec:\n")
+	writeWorkloadSpec(w, workload.Spec)
+
+	fmt.Fprintf(w, "\nResources:\n")
+	switch {
+	case workload.Status.SupplyChainRef.Name == "":
+		fmt.Fprintf(w, "  <none>\n")
+	case g == nil:
+		fmt.Fprintf(w, "  <supply chain not found>\n")
+	default:
+		retries := map[string]int64{}
+		for _, r := range workload.Status.Retries {
+			retries[r.Resource] = r.Retries
+		}
+		fmt.Fprintf(w, "  NAME\tTEMPLATE\tOBJECT\tREADY\tRETRIES\n")
+		for _, node := range g.Nodes {
+			object, ready := "<none>", ""
+			if node.Object != nil {
+				object = fmt.Sprintf("%s/%s", node.Object.Kind, node.Object.Name)
+				ready = formatReadiness(node.Object.Ready)
+			}
+			fmt.Fprintf(w, "  %s\t%s/%s\t%s\t%s\t%d\n", node.Name, node.TemplateKind, node.TemplateName, object, ready, retries[node.Name])
+		}
+	}
+
+	fmt.Fprintf(w, "\nHistory:\n")
+	conditions := append([]metav1.Condition{}, workload.Status.Conditions...)
+	sort.SliceStable(conditions, func(i, j int) bool {
+		return conditions[i].LastTransitionTime.After(conditions[j].LastTransitionTime.Time)
+	})
+	if len(conditions) == 0 {
+		fmt.Fprintf(w, "  <none>\n")
+	} else {
+		fmt.Fprintf(w, "  SINCE\tCONDITION\tSTATUS\tREASON\tMESSAGE\n")
+		for _, c := range conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", formatTime(c.LastTransitionTime.Time), c.Type, c.Status, c.Reason, c.Message)
+		}
+	}
+
+	fmt.Fprintf(w, "\nEvents:\n")
+	if len(events) == 0 {
+		fmt.Fprintf(w, "  <none>\n")
+	} else {
+		fmt.Fprintf(w, "  LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\n")
+		for _, e := range events {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s/%s\t%s\n", formatTime(eventTime(e)), e.Type, e.Reason, e.InvolvedObject.Kind, e.InvolvedObject.Name, strings.TrimSpace(e.Message))
+		}
+	}
+
+	return w.Flush()
+}
+
+// workloadGraph returns the graph of the supply chain the workload matched,
+// realized for it, or nil when it matched none or the supply chain is gone.
+func workloadGraph(ctx context.Context, reader client.Reader, workload *v1alpha1.Workload) (*graph.Graph, error) {
+	ref := workload.Status.SupplyChainRef
+	if ref.Name == "" {
+		return nil, nil
+	}
+
+	name := types.NamespacedName{Namespace: workload.Namespace, Name: workload.Name}
+	var g *graph.Graph
+	var err error
+	if ref.Kind == "SupplyChain" {
+		g, err = graph.NamespacedSupplyChain(ctx, reader, types.NamespacedName{Namespace: workload.Namespace, Name: ref.Name}, &name)
+	} else {
+		g, err = graph.SupplyChain(ctx, reader, ref.Name, &name)
+	}
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return g, nil
+}
+
+func health(workload *v1alpha1.Workload) string {
+	ready := meta.FindStatusCondition(workload.Status.Conditions, v1alpha1.WorkloadReady)
+	var h string
+	switch {
+	case ready == nil:
+		return "Unknown: not reconciled yet"
+	case ready.Status == metav1.ConditionTrue:
+		h = "Ready"
+	default:
+		h = fmt.Sprintf("Not ready (%s)", ready.Reason)
+		if ready.Message != "" {
+			h += ": " + ready.Message
+		}
+	}
+	if workload.Status.ObservedGeneration != workload.Generation {
+		h += fmt.Sprintf(", generation %d not realized yet", workload.Generation)
+	}
+	return h
+}
+
+func writeWorkloadSpec(w io.Writer, spec v1alpha1.WorkloadSpec) {
+	if source := spec.Source; source != nil {
+		if source.Git != nil {
+			fmt.Fprintf(w, "  Source:\tgit %s%s\n", stringValue(source.Git.URL), formatGitRef(source.Git.Ref))
+		}
+		if source.Image != nil {
+			fmt.Fprintf(w, "  Source:\timage %s\n", *source.Image)
+		}
+		if source.Subpath != nil {
+			fmt.Fprintf(w, "  Subpath:\t%s\n", *source.Subpath)
+		}
+	}
+	if spec.Image != nil {
+		fmt.Fprintf(w, "  Image:\t%s\n", *spec.Image)
+	}
+	if spec.ServiceAccountName != "" {
+		fmt.Fprintf(w, "  Service account:\t%s\n", spec.ServiceAccountName)
+	}
+	if spec.Platform != nil {
+		fmt.Fprintf(w, "  Platform:\t%s\n", spec.Platform.String())
+	}
+	if len(spec.Params) == 0 {
+		fmt.Fprintf(w, "  Params:\t<none>\n")
+		return
+	}
+	fmt.Fprintf(w, "  Params:\n")
+	for _, param := range spec.Params {
+		fmt.Fprintf(w, "    %s\t%s\n", param.Name, string(param.Value.Raw))
+	}
+}
+
+func formatGitRef(ref *v1alpha1.GitRef) string {
+	switch {
+	case ref == nil:
+		return ""
+	case ref.Commit != nil:
+		return " commit " + *ref.Commit
+	case ref.Tag != nil:
+		return " tag " + *ref.Tag
+	case ref.Branch != nil:
+		return " branch " + *ref.Branch
+	}
+	return ""
+}
+
+func formatReadiness(r graph.Readiness) string {
+	switch {
+	case r.Status == "":
+		return "Unknown"
+	case r.Reason == "":
+		return r.Status
+	}
+	return fmt.Sprintf("%s (%s)", r.Status, r.Reason)
+}
+
+// recentEvents returns the latest events involving any of the objects,
+// oldest first.
+func recentEvents(ctx context.Context, reader client.Reader, objects []corev1.ObjectReference) ([]corev1.Event, error) {
+	byNamespace := map[string][]corev1.ObjectReference{}
+	for _, o := range objects {
+		byNamespace[o.Namespace] = append(byNamespace[o.Namespace], o)
+	}
+
+	var events []corev1.Event
+	for namespace, involved := range byNamespace {
+		list := &corev1.EventList{}
+		if err := reader.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("list events in '%s': %w", namespace, err)
+		}
+		for _, e := range list.Items {
+			for _, o := range involved {
+				if e.InvolvedObject.Kind == o.Kind && e.InvolvedObject.Name == o.Name {
+					events = append(events, e)
+					break
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+	return events, nil
+}
+
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
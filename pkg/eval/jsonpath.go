@@ -17,9 +17,12 @@ package eval
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
+	"k8s.io/client-go/util/jsonpath"
+
 	"github.com/vmware-tanzu/cartographer/pkg/utils"
 )
 
@@ -36,9 +39,21 @@ func EvaluatorBuilder() Evaluator {
 	}
 }
 
+// LenientEvaluatorBuilder builds an evaluator finding no results, rather
+// than failing, where an expression reads a key that does not exist.
+func LenientEvaluatorBuilder() Evaluator {
+	return Evaluator{
+		Evaluate: utils.SinglePathEvaluateAllowingMissingKeys,
+	}
+}
+
+// ErrNoResults is returned for an expression selecting a single value that
+// selects none.
+var ErrNoResults = errors.New("no results")
+
 func (e Evaluator) EvaluateJsonPath(path string, obj interface{}) (interface{}, error) {
 	if path == "" {
-		return nil, fmt.Errorf("empty jsonpath not allowed")
+		return nil, utils.JsonPathParseError{Err: fmt.Errorf("empty jsonpath not allowed"), Expression: path}
 	}
 
 	jsonpathExpression := ensureValidWrapping(path)
@@ -48,6 +63,17 @@ func (e Evaluator) EvaluateJsonPath(path string, obj interface{}) (interface{},
 		return nil, fmt.Errorf("evaluate: %w", err)
 	}
 
+	if selectsMany(jsonpathExpression) {
+		if interfaceList == nil {
+			interfaceList = []interface{}{}
+		}
+		return interfaceList, nil
+	}
+
+	if len(interfaceList) == 0 {
+		return nil, fmt.Errorf("%w for the query: %s", ErrNoResults, path)
+	}
+
 	if len(interfaceList) > 1 {
 		return "", fmt.Errorf("too many results for the query: %s", path)
 	}
@@ -55,6 +81,51 @@ func (e Evaluator) EvaluateJsonPath(path string, obj interface{}) (interface{},
 	return interfaceList[0], nil
 }
 
+// selectsMany reports whether the expression selects a list of values, by
+// a wildcard, a union, a slice or a recursive descent, as opposed to a
+// single value, which a filter selects too. Filters matching more than one
+// value are an error.
+func selectsMany(jsonpathExpression string) bool {
+	parser, err := jsonpath.Parse("", jsonpathExpression)
+	if err != nil {
+		return false
+	}
+	return anySelectsMany(parser.Root)
+}
+
+func anySelectsMany(node jsonpath.Node) bool {
+	switch n := node.(type) {
+	case *jsonpath.ListNode:
+		for _, child := range n.Nodes {
+			if anySelectsMany(child) {
+				return true
+			}
+		}
+	case *jsonpath.WildcardNode, *jsonpath.UnionNode, *jsonpath.RecursiveNode:
+		return true
+	case *jsonpath.ArrayNode:
+		// a single index is parsed as the slice of one, its end derived
+		return !n.Params[1].Derived
+	}
+	return false
+}
+
+// ValidateJsonPath reports whether path would parse when passed to
+// EvaluateJsonPath, without evaluating it against an object.
+func ValidateJsonPath(path string) error {
+	if path == "" {
+		return utils.JsonPathParseError{Err: fmt.Errorf("empty jsonpath not allowed"), Expression: path}
+	}
+
+	jsonpathExpression := ensureValidWrapping(path)
+
+	if err := jsonpath.New("").Parse(jsonpathExpression); err != nil {
+		return utils.JsonPathParseError{Err: err, Expression: jsonpathExpression}
+	}
+
+	return nil
+}
+
 func ensureValidWrapping(jsonpathExpression string) string {
 	if !strings.HasPrefix(jsonpathExpression, "{.") {
 		if !strings.HasPrefix(jsonpathExpression, ".") {
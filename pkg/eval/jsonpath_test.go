@@ -15,6 +15,7 @@
 package eval_test
 
 import (
+	"errors"
 	"fmt"
 
 	. "github.com/onsi/ginkgo"
@@ -23,6 +24,7 @@ import (
 
 	"github.com/vmware-tanzu/cartographer/pkg/eval"
 	"github.com/vmware-tanzu/cartographer/pkg/eval/evalfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/utils"
 )
 
 var _ = Describe("JsonPath", func() {
@@ -124,6 +126,35 @@ var _ = Describe("JsonPath", func() {
 			})
 		})
 
+		Context("when evaluate returns an empty list", func() {
+			BeforeEach(func() {
+				evaluate.Returns([]interface{}{}, nil)
+				result, err = evaluator.EvaluateJsonPath(path, obj)
+			})
+
+			ItReturnsAHelpfulError("no results for the query: some.path")
+		})
+
+		Context("when the path selects many values", func() {
+			BeforeEach(func() {
+				path = "items[*].name"
+			})
+
+			It("returns every value as a list", func() {
+				evaluate.Returns([]interface{}{"first", "second"}, nil)
+				result, err = evaluator.EvaluateJsonPath(path, obj)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal([]interface{}{"first", "second"}))
+			})
+
+			It("returns an empty list when none is found", func() {
+				evaluate.Returns(nil, nil)
+				result, err = evaluator.EvaluateJsonPath(path, obj)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal([]interface{}{}))
+			})
+		})
+
 		Context("when path is empty", func() {
 			BeforeEach(func() {
 				path = ""
@@ -131,6 +162,78 @@ var _ = Describe("JsonPath", func() {
 			})
 
 			ItReturnsAHelpfulError("empty jsonpath not allowed")
+
+			It("returns a parse error", func() {
+				Expect(errors.As(err, &utils.JsonPathParseError{})).To(BeTrue())
+			})
 		})
 	})
 })
+
+var _ = Describe("EvaluateJsonPath against objects", func() {
+	obj := map[string]interface{}{
+		"status": map[string]interface{}{
+			"url":      "https://example.com/app.tar.gz",
+			"revision": "main/abc123",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+				map[string]interface{}{"type": "Succeeded", "status": "False"},
+			},
+		},
+	}
+
+	DescribeTable("evaluates",
+		func(path string, expected interface{}) {
+			result, err := eval.EvaluatorBuilder().EvaluateJsonPath(path, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+		Entry("a filter to the single value it matches", `status.conditions[?(@.type=="Ready")].status`, "True"),
+		Entry("an index to a single value", "status.conditions[1].type", "Succeeded"),
+		Entry("a wildcard to a list", "status.conditions[*].type", []interface{}{"Ready", "Succeeded"}),
+		Entry("a slice to a list", "status.conditions[0:1].type", []interface{}{"Ready"}),
+		Entry("a union to a list", "status['url','revision']", []interface{}{"https://example.com/app.tar.gz", "main/abc123"}),
+		Entry("a recursive descent to a list", "..type", []interface{}{"Ready", "Succeeded"}),
+	)
+
+	It("reports a filter that matches nothing", func() {
+		_, err := eval.EvaluatorBuilder().EvaluateJsonPath(`status.conditions[?(@.type=="Healthy")].status`, obj)
+		Expect(err).To(MatchError(`no results for the query: status.conditions[?(@.type=="Healthy")].status`))
+		Expect(errors.Is(err, eval.ErrNoResults)).To(BeTrue())
+	})
+
+	It("reports a missing key as no results when lenient", func() {
+		_, err := eval.EvaluatorBuilder().EvaluateJsonPath("status.missing", obj)
+		Expect(err).To(MatchError(ContainSubstring("missing is not found")))
+
+		_, err = eval.LenientEvaluatorBuilder().EvaluateJsonPath("status.missing", obj)
+		Expect(errors.Is(err, eval.ErrNoResults)).To(BeTrue())
+
+		result, err := eval.LenientEvaluatorBuilder().EvaluateJsonPath("status.url", obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("https://example.com/app.tar.gz"))
+	})
+
+	It("reports a filter that matches more than one value", func() {
+		_, err := eval.EvaluatorBuilder().EvaluateJsonPath(`status.conditions[?(@.status)].type`, obj)
+		Expect(err).To(MatchError(ContainSubstring("too many results for the query")))
+	})
+})
+
+var _ = Describe("ValidateJsonPath", func() {
+	It("accepts expressions that EvaluateJsonPath can parse", func() {
+		Expect(eval.ValidateJsonPath("status.artifact.url")).To(Succeed())
+		Expect(eval.ValidateJsonPath(".status.artifact.url")).To(Succeed())
+		Expect(eval.ValidateJsonPath("{.status.conditions[?(@.type==\"Ready\")].status}")).To(Succeed())
+	})
+
+	It("rejects an empty expression", func() {
+		Expect(eval.ValidateJsonPath("")).To(MatchError(ContainSubstring("empty jsonpath not allowed")))
+	})
+
+	It("rejects an expression that does not parse", func() {
+		err := eval.ValidateJsonPath("status.artifact[")
+		Expect(err).To(MatchError(ContainSubstring("jsonpath parse path '{.status.artifact[}'")))
+		Expect(errors.As(err, &utils.JsonPathParseError{})).To(BeTrue())
+	})
+})
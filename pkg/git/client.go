@@ -0,0 +1,249 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package git reads files from git repositories served over HTTP(S),
+// fetching only the commit they are read from.
+package git
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// maxResponseSize bounds the refs and packs read from a server.
+const maxResponseSize = 64 << 20
+
+// ErrNotFound is returned when a repository, a ref or a file does not
+// exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrUnauthorized is returned when the server refuses the credentials a
+// repository is read with, or requires some.
+var ErrUnauthorized = errors.New("unauthorized")
+
+type Credentials struct {
+	Username string
+	Password string
+}
+
+type Client struct {
+	HTTP *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{HTTP: &http.Client{Timeout: time.Minute}}
+}
+
+// ResolveRef returns the commit ref names in the repository at url. ref is
+// a branch, a tag, a full ref name or a commit. The repository's HEAD is
+// resolved when ref is empty.
+func (c *Client) ResolveRef(ctx context.Context, url, ref string, credentials *Credentials) (string, error) {
+	if isObjectName(ref) {
+		return ref, nil
+	}
+
+	session, err := c.session(url, credentials)
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	refs, err := session.AdvertisedReferencesContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list refs of %s: %w", url, translate(err))
+	}
+
+	if ref == "" {
+		if refs.Head == nil {
+			return "", fmt.Errorf("HEAD of %s: %w", url, ErrNotFound)
+		}
+		return refs.Head.String(), nil
+	}
+	for _, candidate := range []string{ref, "refs/heads/" + ref, "refs/tags/" + ref} {
+		// the commit an annotated tag points at.
+		if id, ok := refs.Peeled[candidate]; ok {
+			return id.String(), nil
+		}
+		if id, ok := refs.References[candidate]; ok {
+			return id.String(), nil
+		}
+	}
+	return "", fmt.Errorf("ref '%s' of %s: %w", ref, url, ErrNotFound)
+}
+
+// ReadFile returns the content of the file at path in the commit of the
+// repository at url. Only that commit is fetched, not its history.
+func (c *Client) ReadFile(ctx context.Context, url, commit, path string, credentials *Credentials) ([]byte, error) {
+	if !isObjectName(commit) {
+		return nil, fmt.Errorf("invalid commit '%s'", commit)
+	}
+
+	storage, err := c.fetch(ctx, url, commit, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	fetched, err := object.GetCommit(storage, plumbing.NewHash(commit))
+	if err != nil {
+		return nil, fmt.Errorf("commit %s of %s: %w", commit, url, err)
+	}
+	tree, err := fetched.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("tree of commit %s of %s: %w", commit, url, err)
+	}
+
+	entry, err := tree.FindEntry(strings.Trim(path, "/"))
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !entry.Mode.IsFile() || entry.Mode == filemode.Submodule {
+		return nil, fmt.Errorf("%s is not a file", path)
+	}
+
+	file, err := tree.TreeEntryFile(entry)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// fetch fetches the commit, without its parents, into memory.
+func (c *Client) fetch(ctx context.Context, url, commit string, credentials *Credentials) (*memory.Storage, error) {
+	session, err := c.session(url, credentials)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	request := packp.NewUploadPackRequest()
+	request.Wants = []plumbing.Hash{plumbing.NewHash(commit)}
+	request.Depth = packp.DepthCommits(1)
+	for _, name := range []capability.Capability{capability.OFSDelta, capability.Shallow, capability.NoProgress} {
+		if err := request.Capabilities.Set(name); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := session.UploadPack(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s from %s: %w", commit, url, translate(err))
+	}
+	defer response.Close()
+
+	storage := memory.NewStorage()
+	if err := packfile.UpdateObjectStorage(storage, response); err != nil {
+		return nil, fmt.Errorf("read pack of %s: %w", url, err)
+	}
+	return storage, nil
+}
+
+// session opens an upload-pack session with the repository at url, over
+// HTTP(S) only.
+func (c *Client) session(url string, credentials *Credentials) (transport.UploadPackSession, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository url '%s': %w", url, err)
+	}
+	if endpoint.Protocol != "http" && endpoint.Protocol != "https" {
+		return nil, fmt.Errorf("invalid repository url '%s': only http and https are supported", url)
+	}
+
+	var auth transport.AuthMethod
+	if credentials != nil {
+		auth = &githttp.BasicAuth{Username: credentials.Username, Password: credentials.Password}
+	}
+
+	client := *c.HTTP
+	client.Transport = limitedTransport{RoundTripper: client.Transport}
+	return githttp.NewClient(&client).NewUploadPackSession(endpoint, auth)
+}
+
+func translate(err error) error {
+	if errors.Is(err, transport.ErrRepositoryNotFound) {
+		return fmt.Errorf("repository %w", ErrNotFound)
+	}
+	if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+		return fmt.Errorf("%s: %w", err, ErrUnauthorized)
+	}
+	return err
+}
+
+// limitedTransport fails reading responses larger than maxResponseSize.
+type limitedTransport struct {
+	http.RoundTripper
+}
+
+func (t limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	roundTripper := t.RoundTripper
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+	resp, err := roundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: maxResponseSize}
+	return resp, nil
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, fmt.Errorf("response exceeds %d bytes", maxResponseSize)
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// isObjectName reports whether s is the SHA-1 name of an object.
+func isObjectName(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
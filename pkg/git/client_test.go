@@ -0,0 +1,190 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/git"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		ctx       context.Context
+		root      string
+		server    *httptest.Server
+		url       string
+		client    *git.Client
+		firstRev  string
+		secondRev string
+	)
+
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = filepath.Join(root, "templates.git")
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	write := func(path, content string) {
+		path = filepath.Join(root, "templates.git", path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		gitPath, err := exec.LookPath("git")
+		if err != nil {
+			Skip("git is not installed")
+		}
+
+		ctx = context.Background()
+		root, err = ioutil.TempDir("", "git-client")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Mkdir(filepath.Join(root, "templates.git"), 0755)).To(Succeed())
+
+		run("init", "--quiet", "--initial-branch=main")
+		write("templates/source.yaml", "kind: ClusterSourceTemplate\n")
+		// similar files, so that the pack holds deltas.
+		write("templates/big-1.yaml", strings.Repeat("line of a large template\n", 500)+"one\n")
+		write("templates/big-2.yaml", strings.Repeat("line of a large template\n", 500)+"two\n")
+		run("add", ".")
+		run("commit", "--quiet", "-m", "first")
+		run("tag", "-a", "v1", "-m", "first release")
+		firstRev = run("rev-parse", "HEAD")
+
+		write("templates/source.yaml", "kind: ClusterSourceTemplate\nmetadata: {name: source}\n")
+		run("commit", "--quiet", "-am", "second")
+		secondRev = run("rev-parse", "HEAD")
+
+		backend := &cgi.Handler{
+			Path: gitPath,
+			Args: []string{"http-backend"},
+			Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+		}
+		server = httptest.NewServer(backend)
+		url = server.URL + "/templates.git"
+		client = git.NewClient()
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			server.Close()
+		}
+		_ = os.RemoveAll(root)
+	})
+
+	Describe("ResolveRef", func() {
+		It("resolves branches", func() {
+			Expect(client.ResolveRef(ctx, url, "main", nil)).To(Equal(secondRev))
+			Expect(client.ResolveRef(ctx, url, "refs/heads/main", nil)).To(Equal(secondRev))
+		})
+
+		It("resolves annotated tags to the commit they point at", func() {
+			Expect(client.ResolveRef(ctx, url, "v1", nil)).To(Equal(firstRev))
+		})
+
+		It("resolves HEAD when no ref is given", func() {
+			Expect(client.ResolveRef(ctx, url, "", nil)).To(Equal(secondRev))
+		})
+
+		It("returns commits as they are", func() {
+			server.Close()
+			Expect(client.ResolveRef(ctx, url, firstRev, nil)).To(Equal(firstRev))
+		})
+
+		It("reports refs that do not exist", func() {
+			_, err := client.ResolveRef(ctx, url, "missing", nil)
+			Expect(err).To(MatchError(git.ErrNotFound))
+		})
+
+		It("reports repositories that do not exist", func() {
+			_, err := client.ResolveRef(ctx, server.URL+"/missing.git", "main", nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ReadFile", func() {
+		It("reads files from the commit", func() {
+			Expect(client.ReadFile(ctx, url, firstRev, "templates/source.yaml", nil)).
+				To(Equal([]byte("kind: ClusterSourceTemplate\n")))
+			Expect(client.ReadFile(ctx, url, secondRev, "/templates/source.yaml", nil)).
+				To(Equal([]byte("kind: ClusterSourceTemplate\nmetadata: {name: source}\n")))
+		})
+
+		It("reads files stored as deltas", func() {
+			content, err := client.ReadFile(ctx, url, secondRev, "templates/big-2.yaml", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(HaveSuffix("line of a large template\ntwo\n"))
+		})
+
+		It("reports files that do not exist", func() {
+			_, err := client.ReadFile(ctx, url, secondRev, "templates/missing.yaml", nil)
+			Expect(err).To(MatchError(git.ErrNotFound))
+		})
+
+		It("rejects directories", func() {
+			_, err := client.ReadFile(ctx, url, secondRev, "templates", nil)
+			Expect(err).To(MatchError("templates is not a file"))
+		})
+	})
+
+	Context("when the repository requires credentials", func() {
+		BeforeEach(func() {
+			backend := server.Config.Handler
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				backend.ServeHTTP(w, r)
+			})
+		})
+
+		It("fetches with them", func() {
+			credentials := &git.Credentials{Username: "user", Password: "pass"}
+			Expect(client.ResolveRef(ctx, url, "main", credentials)).To(Equal(secondRev))
+			Expect(client.ReadFile(ctx, url, secondRev, "templates/source.yaml", credentials)).NotTo(BeEmpty())
+		})
+
+		It("fails without them", func() {
+			_, err := client.ResolveRef(ctx, url, "main", nil)
+			Expect(err).To(MatchError(ContainSubstring("authentication required")))
+			Expect(errors.Is(err, git.ErrUnauthorized)).To(BeTrue())
+		})
+
+		It("fails with the wrong ones", func() {
+			_, err := client.ResolveRef(ctx, url, "main", &git.Credentials{Username: "user", Password: "wrong"})
+			Expect(errors.Is(err, git.ErrUnauthorized)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,155 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+const defaultGitHubAPIURL = "https://api.github.com"
+
+// gitHub proposes changes to a GitHub repository with its REST API.
+type gitHub struct {
+	api        api
+	repository string
+}
+
+func newGitHub(client *http.Client, req Request) *gitHub {
+	baseURL := req.APIURL
+	if baseURL == "" {
+		baseURL = defaultGitHubAPIURL
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+req.Token)
+	return &gitHub{
+		api:        api{client: client, baseURL: baseURL, header: header},
+		repository: req.Repository,
+	}
+}
+
+type gitHubPullRequest struct {
+	Number   int     `json:"number"`
+	HTMLURL  string  `json:"html_url"`
+	State    string  `json:"state"`
+	MergedAt *string `json:"merged_at"`
+}
+
+func (p gitHubPullRequest) status() v1alpha1.DeliverablePullRequest {
+	state := v1alpha1.OpenPullRequestState
+	if p.MergedAt != nil {
+		state = v1alpha1.MergedPullRequestState
+	} else if p.State == "closed" {
+		state = v1alpha1.ClosedPullRequestState
+	}
+	return v1alpha1.DeliverablePullRequest{URL: p.HTMLURL, Number: p.Number, State: state}
+}
+
+func (g *gitHub) file(ctx context.Context, branch, path string) (*file, error) {
+	var content struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+		SHA      string `json:"sha"`
+	}
+	err := g.api.call(ctx, http.MethodGet, "/repos/"+g.repository+"/contents/"+escapePath(path), url.Values{"ref": {branch}}, nil, &content)
+	if hasStatus(err, http.StatusNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the content is wrapped over lines.
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+	if err != nil {
+		return nil, err
+	}
+	return &file{content: decoded, sha: content.SHA}, nil
+}
+
+func (g *gitHub) latest(ctx context.Context, head, base string) (*v1alpha1.DeliverablePullRequest, error) {
+	owner := strings.SplitN(g.repository, "/", 2)[0]
+	var pullRequests []gitHubPullRequest
+	err := g.api.call(ctx, http.MethodGet, "/repos/"+g.repository+"/pulls", url.Values{
+		"head":      {owner + ":" + head},
+		"base":      {base},
+		"state":     {"all"},
+		"sort":      {"created"},
+		"direction": {"desc"},
+		"per_page":  {"1"},
+	}, nil, &pullRequests)
+	if err != nil {
+		return nil, err
+	}
+	if len(pullRequests) == 0 {
+		return nil, nil
+	}
+	latest := pullRequests[0].status()
+	return &latest, nil
+}
+
+func (g *gitHub) push(ctx context.Context, req Request, existing *file) error {
+	var base struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if err := g.api.call(ctx, http.MethodGet, "/repos/"+g.repository+"/git/ref/heads/"+escapePath(req.Base), nil, nil, &base); err != nil {
+		return err
+	}
+
+	err := g.api.call(ctx, http.MethodPatch, "/repos/"+g.repository+"/git/refs/heads/"+escapePath(req.Branch), nil, map[string]interface{}{
+		"sha":   base.Object.SHA,
+		"force": true,
+	}, nil)
+	if hasStatus(err, http.StatusNotFound, http.StatusUnprocessableEntity) {
+		err = g.api.call(ctx, http.MethodPost, "/repos/"+g.repository+"/git/refs", nil, map[string]interface{}{
+			"ref": "refs/heads/" + req.Branch,
+			"sha": base.Object.SHA,
+		}, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	contents := map[string]interface{}{
+		"message": req.Title,
+		"content": base64.StdEncoding.EncodeToString(req.Content),
+		"branch":  req.Branch,
+	}
+	if existing != nil {
+		contents["sha"] = existing.sha
+	}
+	return g.api.call(ctx, http.MethodPut, "/repos/"+g.repository+"/contents/"+escapePath(req.Path), nil, contents, nil)
+}
+
+func (g *gitHub) open(ctx context.Context, req Request) (v1alpha1.DeliverablePullRequest, error) {
+	var pullRequest gitHubPullRequest
+	err := g.api.call(ctx, http.MethodPost, "/repos/"+g.repository+"/pulls", nil, map[string]interface{}{
+		"title": req.Title,
+		"body":  req.Body,
+		"head":  req.Branch,
+		"base":  req.Base,
+	}, &pullRequest)
+	if err != nil {
+		return v1alpha1.DeliverablePullRequest{}, err
+	}
+	return pullRequest.status(), nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+const defaultGitLabAPIURL = "https://gitlab.com/api/v4"
+
+// gitLab proposes changes to a GitLab project with its REST API, in merge
+// requests.
+type gitLab struct {
+	api     api
+	project string
+}
+
+func newGitLab(client *http.Client, req Request) *gitLab {
+	baseURL := req.APIURL
+	if baseURL == "" {
+		baseURL = defaultGitLabAPIURL
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+req.Token)
+	return &gitLab{
+		api:     api{client: client, baseURL: baseURL, header: header},
+		project: "/projects/" + url.PathEscape(req.Repository),
+	}
+}
+
+type gitLabMergeRequest struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+	State  string `json:"state"`
+}
+
+func (m gitLabMergeRequest) status() v1alpha1.DeliverablePullRequest {
+	state := v1alpha1.OpenPullRequestState
+	switch m.State {
+	case "merged":
+		state = v1alpha1.MergedPullRequestState
+	case "closed":
+		state = v1alpha1.ClosedPullRequestState
+	}
+	return v1alpha1.DeliverablePullRequest{URL: m.WebURL, Number: m.IID, State: state}
+}
+
+func (g *gitLab) file(ctx context.Context, branch, path string) (*file, error) {
+	var content struct {
+		Content  string `json:"content"`
+		BlobID   string `json:"blob_id"`
+		Encoding string `json:"encoding"`
+	}
+	err := g.api.call(ctx, http.MethodGet, g.project+"/repository/files/"+url.PathEscape(path), url.Values{"ref": {branch}}, nil, &content)
+	if hasStatus(err, http.StatusNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(content.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &file{content: decoded, sha: content.BlobID}, nil
+}
+
+func (g *gitLab) latest(ctx context.Context, head, base string) (*v1alpha1.DeliverablePullRequest, error) {
+	var mergeRequests []gitLabMergeRequest
+	err := g.api.call(ctx, http.MethodGet, g.project+"/merge_requests", url.Values{
+		"source_branch": {head},
+		"target_branch": {base},
+		"state":         {"all"},
+		"order_by":      {"created_at"},
+		"sort":          {"desc"},
+		"per_page":      {"1"},
+	}, nil, &mergeRequests)
+	if err != nil {
+		return nil, err
+	}
+	if len(mergeRequests) == 0 {
+		return nil, nil
+	}
+	latest := mergeRequests[0].status()
+	return &latest, nil
+}
+
+func (g *gitLab) push(ctx context.Context, req Request, existing *file) error {
+	action := "create"
+	if existing != nil {
+		action = "update"
+	}
+	// force has the branch start over from start_branch.
+	return g.api.call(ctx, http.MethodPost, g.project+"/repository/commits", nil, map[string]interface{}{
+		"branch":         req.Branch,
+		"start_branch":   req.Base,
+		"force":          true,
+		"commit_message": req.Title,
+		"actions": []map[string]interface{}{{
+			"action":    action,
+			"file_path": req.Path,
+			"content":   base64.StdEncoding.EncodeToString(req.Content),
+			"encoding":  "base64",
+		}},
+	}, nil)
+}
+
+func (g *gitLab) open(ctx context.Context, req Request) (v1alpha1.DeliverablePullRequest, error) {
+	var mergeRequest gitLabMergeRequest
+	err := g.api.call(ctx, http.MethodPost, g.project+"/merge_requests", nil, map[string]interface{}{
+		"title":         req.Title,
+		"description":   req.Body,
+		"source_branch": req.Branch,
+		"target_branch": req.Base,
+	}, &mergeRequest)
+	if err != nil {
+		return v1alpha1.DeliverablePullRequest{}, err
+	}
+	return mergeRequest.status(), nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gitops proposes the objects stamped for deliverables to GitOps
+// repositories in pull requests, for them to be applied once merged, rather
+// than applying them to the cluster.
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+// Manifests stands in for the repository that would apply the objects
+// stamped for resources submitted as a pull request: it collects them
+// instead. Everything else is read and written with the repository it
+// wraps.
+type Manifests struct {
+	repository.Repository
+
+	mu      sync.Mutex
+	objects []*unstructured.Unstructured
+}
+
+// NewManifests returns a Manifests collecting the objects stamped for
+// resources submitted as a pull request, and reading with repo.
+func NewManifests(repo repository.Repository) *Manifests {
+	return &Manifests{Repository: repo}
+}
+
+// EnsureObjectExistsOnCluster collects a copy of obj, in place of any
+// object collected before with the same kind, namespace and name. obj is
+// left as stamped, for outputs to be read from it.
+func (m *Manifests) EnsureObjectExistsOnCluster(_ context.Context, obj *unstructured.Unstructured, _ bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	collected := obj.DeepCopy()
+	for i, existing := range m.objects {
+		if sameObject(existing, collected) {
+			m.objects[i] = collected
+			return nil
+		}
+	}
+	m.objects = append(m.objects, collected)
+	return nil
+}
+
+// EnsureImmutableObjectExistsOnCluster refuses obj: the object of a job
+// template must run for its results to be read, which a pull request
+// cannot wait for.
+func (m *Manifests) EnsureImmutableObjectExistsOnCluster(_ context.Context, obj *unstructured.Unstructured) error {
+	return fmt.Errorf("%s '%s' of a job template cannot be submitted as a pull request", obj.GetKind(), obj.GetName())
+}
+
+// Len returns the number of objects collected.
+func (m *Manifests) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.objects)
+}
+
+// YAML returns the objects collected, in the order they were first
+// collected, as a stream of YAML documents. What the apiserver would set
+// on them, their status among it, is left out.
+func (m *Manifests) YAML() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out bytes.Buffer
+	for i, obj := range m.objects {
+		manifest := obj.DeepCopy()
+		unstructured.RemoveNestedField(manifest.Object, "status")
+		for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "ownerReferences"} {
+			unstructured.RemoveNestedField(manifest.Object, "metadata", field)
+		}
+
+		document, err := yaml.Marshal(manifest.Object)
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s '%s': %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(document)
+	}
+	return out.Bytes(), nil
+}
+
+// Digest identifies content, for a pull request to be told apart from the
+// next.
+func Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func sameObject(a, b *unstructured.Unstructured) bool {
+	return a.GroupVersionKind().GroupKind() == b.GroupVersionKind().GroupKind() &&
+		a.GetNamespace() == b.GetNamespace() &&
+		a.GetName() == b.GetName()
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the manifests collecting the
+// objects stamped for resources submitted as a pull request.
+func NewContext(ctx context.Context, manifests *Manifests) context.Context {
+	return context.WithValue(ctx, contextKey{}, manifests)
+}
+
+// FromContext returns the manifests carried by ctx, or nil.
+func FromContext(ctx context.Context) *Manifests {
+	manifests, _ := ctx.Value(contextKey{}).(*Manifests)
+	return manifests
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/gitops"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+var _ = Describe("Manifests", func() {
+	var (
+		ctx       context.Context
+		repo      *repositoryfakes.FakeRepository
+		manifests *gitops.Manifests
+	)
+
+	configMap := func(name, value string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":            name,
+				"namespace":       "prod",
+				"uid":             "some-uid",
+				"resourceVersion": "7",
+				"labels":          map[string]interface{}{"carto.run/resource-name": "config"},
+			},
+			"data":   map[string]interface{}{"value": value},
+			"status": map[string]interface{}{"ready": true},
+		}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &repositoryfakes.FakeRepository{}
+		manifests = gitops.NewManifests(repo)
+	})
+
+	It("collects objects rather than applying them", func() {
+		obj := configMap("app", "one")
+		Expect(manifests.EnsureObjectExistsOnCluster(ctx, obj, true)).To(Succeed())
+
+		Expect(manifests.Len()).To(Equal(1))
+		Expect(repo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+		Expect(obj.Object).To(HaveKey("status"))
+	})
+
+	It("renders the objects as YAML documents, without what the apiserver sets", func() {
+		Expect(manifests.EnsureObjectExistsOnCluster(ctx, configMap("app", "one"), true)).To(Succeed())
+		Expect(manifests.EnsureObjectExistsOnCluster(ctx, configMap("other", "two"), true)).To(Succeed())
+
+		content, err := manifests.YAML()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal(`apiVersion: v1
+data:
+  value: one
+kind: ConfigMap
+metadata:
+  labels:
+    carto.run/resource-name: config
+  name: app
+  namespace: prod
+---
+apiVersion: v1
+data:
+  value: two
+kind: ConfigMap
+metadata:
+  labels:
+    carto.run/resource-name: config
+  name: other
+  namespace: prod
+`))
+	})
+
+	It("keeps the last object stamped with a kind, namespace and name, in its first place", func() {
+		Expect(manifests.EnsureObjectExistsOnCluster(ctx, configMap("app", "one"), true)).To(Succeed())
+		Expect(manifests.EnsureObjectExistsOnCluster(ctx, configMap("other", "two"), true)).To(Succeed())
+		Expect(manifests.EnsureObjectExistsOnCluster(ctx, configMap("app", "three"), true)).To(Succeed())
+
+		Expect(manifests.Len()).To(Equal(2))
+		content, err := manifests.YAML()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(MatchRegexp(`(?s)value: three.*name: app.*value: two.*name: other`))
+	})
+
+	It("refuses the objects of job templates", func() {
+		err := manifests.EnsureImmutableObjectExistsOnCluster(ctx, configMap("app", "one"))
+		Expect(err).To(MatchError("ConfigMap 'app' of a job template cannot be submitted as a pull request"))
+		Expect(manifests.Len()).To(Equal(0))
+	})
+
+	It("reads with the repository it wraps", func() {
+		obj := configMap("app", "one")
+		Expect(manifests.GetUnstructured(ctx, obj)).To(Succeed())
+		Expect(repo.GetUnstructuredCallCount()).To(Equal(1))
+	})
+
+	It("is carried by contexts", func() {
+		Expect(gitops.FromContext(ctx)).To(BeNil())
+		Expect(gitops.FromContext(gitops.NewContext(ctx, manifests))).To(BeIdenticalTo(manifests))
+	})
+
+	It("digests content", func() {
+		Expect(gitops.Digest([]byte("kind: ConfigMap\n"))).To(HaveLen(12))
+		Expect(gitops.Digest([]byte("one"))).NotTo(Equal(gitops.Digest([]byte("two"))))
+	})
+})
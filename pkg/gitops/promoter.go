@@ -0,0 +1,183 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// DefaultPollInterval is how long the state of a pull request is taken as
+// known before the provider is asked again.
+const DefaultPollInterval = time.Minute
+
+// Request is a change to propose: Content written to the file at Path, on
+// Branch, against the Base branch of the repository.
+type Request struct {
+	// Provider is v1alpha1.GitHubPullRequestProvider or
+	// v1alpha1.GitLabPullRequestProvider.
+	Provider string
+	// APIURL is the provider's API, when it is not the public one.
+	APIURL string
+	// Repository is owner/name on GitHub, the project's full path on
+	// GitLab.
+	Repository string
+	Base       string
+	Branch     string
+	Path       string
+	Content    []byte
+	Title      string
+	Body       string
+	Token      string
+}
+
+// Promoter proposes changes to GitOps repositories in pull requests, one
+// pull request per branch, and follows them until they are merged or
+// closed.
+type Promoter struct {
+	client   *http.Client
+	interval time.Duration
+
+	mu       sync.Mutex
+	promoted map[string]promotion
+}
+
+type promotion struct {
+	pullRequest v1alpha1.DeliverablePullRequest
+	at          time.Time
+}
+
+// NewPromoter returns a Promoter making requests with client, and asking
+// providers for the state of a pull request no more often than interval.
+func NewPromoter(client *http.Client, interval time.Duration) *Promoter {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Promoter{
+		client:   client,
+		interval: interval,
+		promoted: map[string]promotion{},
+	}
+}
+
+// Promote proposes req's content, returning the pull request that does. A
+// pull request already open on req.Branch is updated with the content,
+// rather than a second one opened. Once the base branch holds the content,
+// the pull request is reported merged, or the base up to date when another
+// change brought it there. A pull request closed without being merged is
+// reported closed until the content changes.
+func (p *Promoter) Promote(ctx context.Context, req Request) (v1alpha1.DeliverablePullRequest, error) {
+	digest := Digest(req.Content)
+	key := strings.Join([]string{req.Provider, req.APIURL, req.Repository, req.Branch}, "|")
+
+	p.mu.Lock()
+	last, ok := p.promoted[key]
+	p.mu.Unlock()
+	if ok && last.pullRequest.Digest == digest && time.Since(last.at) < p.interval {
+		return last.pullRequest, nil
+	}
+
+	provider, err := p.provider(req)
+	if err != nil {
+		return v1alpha1.DeliverablePullRequest{}, err
+	}
+	pullRequest, err := promote(ctx, provider, req)
+	if err != nil {
+		return v1alpha1.DeliverablePullRequest{}, fmt.Errorf("%s repository '%s': %w", req.Provider, req.Repository, err)
+	}
+	pullRequest.Branch = req.Branch
+	pullRequest.Digest = digest
+
+	p.mu.Lock()
+	p.promoted[key] = promotion{pullRequest: pullRequest, at: time.Now()}
+	p.mu.Unlock()
+	return pullRequest, nil
+}
+
+func (p *Promoter) provider(req Request) (provider, error) {
+	switch req.Provider {
+	case v1alpha1.GitHubPullRequestProvider:
+		return newGitHub(p.client, req), nil
+	case v1alpha1.GitLabPullRequestProvider:
+		return newGitLab(p.client, req), nil
+	default:
+		return nil, fmt.Errorf("unknown pull request provider '%s'", req.Provider)
+	}
+}
+
+// provider is the API of a git hosting service, as far as proposing a file
+// in a pull request goes.
+type provider interface {
+	// file returns the file at path on the named branch, or nil if there
+	// is none.
+	file(ctx context.Context, branch, path string) (*file, error)
+	// latest returns the pull request last opened from branch head into
+	// branch base, or nil if there is none.
+	latest(ctx context.Context, head, base string) (*v1alpha1.DeliverablePullRequest, error)
+	// push commits req's content to req.Branch, reset to req.Base first.
+	// existing is the file at req.Path on req.Base.
+	push(ctx context.Context, req Request, existing *file) error
+	// open opens a pull request from req.Branch into req.Base.
+	open(ctx context.Context, req Request) (v1alpha1.DeliverablePullRequest, error)
+}
+
+type file struct {
+	content []byte
+	// sha is the file's blob, as GitHub requires to replace it.
+	sha string
+}
+
+func promote(ctx context.Context, provider provider, req Request) (v1alpha1.DeliverablePullRequest, error) {
+	base, err := provider.file(ctx, req.Base, req.Path)
+	if err != nil {
+		return v1alpha1.DeliverablePullRequest{}, err
+	}
+	latest, err := provider.latest(ctx, req.Branch, req.Base)
+	if err != nil {
+		return v1alpha1.DeliverablePullRequest{}, err
+	}
+
+	if base != nil && bytes.Equal(base.content, req.Content) {
+		if latest != nil && latest.State == v1alpha1.MergedPullRequestState {
+			return *latest, nil
+		}
+		return v1alpha1.DeliverablePullRequest{State: v1alpha1.UpToDatePullRequestState}, nil
+	}
+
+	if latest != nil && latest.State != v1alpha1.MergedPullRequestState {
+		head, err := provider.file(ctx, req.Branch, req.Path)
+		if err != nil {
+			return v1alpha1.DeliverablePullRequest{}, err
+		}
+		if head != nil && bytes.Equal(head.content, req.Content) {
+			return *latest, nil
+		}
+	}
+
+	if err := provider.push(ctx, req, base); err != nil {
+		return v1alpha1.DeliverablePullRequest{}, err
+	}
+	if latest != nil && latest.State == v1alpha1.OpenPullRequestState {
+		return *latest, nil
+	}
+	return provider.open(ctx, req)
+}
@@ -0,0 +1,399 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/gitops"
+)
+
+// fakeRepository is the state of a repository on a fake provider.
+type fakeRepository struct {
+	mu sync.Mutex
+	// branches holds the files of each branch, by path.
+	branches map[string]map[string]string
+	pulls    []fakePull
+	requests []string
+	pushes   int
+}
+
+type fakePull struct {
+	head, base, state string
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{branches: map[string]map[string]string{"main": {}}}
+}
+
+func (f *fakeRepository) reset(branch, base string) {
+	files := map[string]string{}
+	for path, content := range f.branches[base] {
+		files[path] = content
+	}
+	f.branches[branch] = files
+}
+
+// gitHub serves the parts of GitHub's API the promoter uses, for the
+// org/gitops repository.
+func (f *fakeRepository) gitHub() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+		if r.Header.Get("Authorization") != "Bearer some-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message": "Bad credentials"}`))
+			return
+		}
+
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		path := strings.TrimPrefix(r.URL.Path, "/repos/org/gitops")
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "/contents/"):
+			content, ok := f.branches[r.URL.Query().Get("ref")][strings.TrimPrefix(path, "/contents/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			encoded := base64.StdEncoding.EncodeToString([]byte(content))
+			_ = json.NewEncoder(w).Encode(map[string]string{"content": encoded[:4] + "\n" + encoded[4:], "sha": "blob-" + content})
+		case r.Method == http.MethodPut && strings.HasPrefix(path, "/contents/"):
+			content, _ := base64.StdEncoding.DecodeString(body["content"].(string))
+			filePath := strings.TrimPrefix(path, "/contents/")
+			if existing, ok := f.branches[body["branch"].(string)][filePath]; ok && body["sha"] != "blob-"+existing {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			f.branches[body["branch"].(string)][filePath] = string(content)
+			f.pushes++
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "/git/ref/heads/"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"object": map[string]string{"sha": "sha-" + strings.TrimPrefix(path, "/git/ref/heads/")}})
+		case r.Method == http.MethodPatch && strings.HasPrefix(path, "/git/refs/heads/"):
+			branch := strings.TrimPrefix(path, "/git/refs/heads/")
+			if _, ok := f.branches[branch]; !ok {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"message": "Reference does not exist"}`))
+				return
+			}
+			f.reset(branch, strings.TrimPrefix(body["sha"].(string), "sha-"))
+		case r.Method == http.MethodPost && path == "/git/refs":
+			f.reset(strings.TrimPrefix(body["ref"].(string), "refs/heads/"), strings.TrimPrefix(body["sha"].(string), "sha-"))
+		case r.Method == http.MethodGet && path == "/pulls":
+			query := r.URL.Query()
+			pulls := []map[string]interface{}{}
+			for i := len(f.pulls) - 1; i >= 0; i-- {
+				pull := f.pulls[i]
+				if "org:"+pull.head == query.Get("head") && pull.base == query.Get("base") {
+					pulls = append(pulls, gitHubPull(i+1, pull))
+					break
+				}
+			}
+			_ = json.NewEncoder(w).Encode(pulls)
+		case r.Method == http.MethodPost && path == "/pulls":
+			pull := fakePull{head: body["head"].(string), base: body["base"].(string), state: "open"}
+			f.pulls = append(f.pulls, pull)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(gitHubPull(len(f.pulls), pull))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func gitHubPull(number int, pull fakePull) map[string]interface{} {
+	state, mergedAt := pull.state, interface{}(nil)
+	if pull.state == "merged" {
+		state, mergedAt = "closed", "2022-01-01T00:00:00Z"
+	}
+	return map[string]interface{}{
+		"number":    number,
+		"html_url":  fmt.Sprintf("https://github.com/org/gitops/pull/%d", number),
+		"state":     state,
+		"merged_at": mergedAt,
+	}
+}
+
+// gitLab serves the parts of GitLab's API the promoter uses, for the
+// group/gitops project.
+func (f *fakeRepository) gitLab() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.EscapedPath())
+
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		path := strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4/projects/group%2Fgitops")
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "/repository/files/"):
+			filePath := strings.ReplaceAll(strings.TrimPrefix(path, "/repository/files/"), "%2F", "/")
+			content, ok := f.branches[r.URL.Query().Get("ref")][filePath]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"content": base64.StdEncoding.EncodeToString([]byte(content)), "encoding": "base64"})
+		case r.Method == http.MethodPost && path == "/repository/commits":
+			Expect(body["force"]).To(BeTrue())
+			branch := body["branch"].(string)
+			f.reset(branch, body["start_branch"].(string))
+			for _, action := range body["actions"].([]interface{}) {
+				action := action.(map[string]interface{})
+				_, exists := f.branches[branch][action["file_path"].(string)]
+				if exists != (action["action"] == "update") {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				content, _ := base64.StdEncoding.DecodeString(action["content"].(string))
+				f.branches[branch][action["file_path"].(string)] = string(content)
+			}
+			f.pushes++
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && path == "/merge_requests":
+			query := r.URL.Query()
+			mergeRequests := []map[string]interface{}{}
+			for i := len(f.pulls) - 1; i >= 0; i-- {
+				pull := f.pulls[i]
+				if pull.head == query.Get("source_branch") && pull.base == query.Get("target_branch") {
+					mergeRequests = append(mergeRequests, gitLabMergeRequest(i+1, pull))
+					break
+				}
+			}
+			_ = json.NewEncoder(w).Encode(mergeRequests)
+		case r.Method == http.MethodPost && path == "/merge_requests":
+			pull := fakePull{head: body["source_branch"].(string), base: body["target_branch"].(string), state: "opened"}
+			f.pulls = append(f.pulls, pull)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(gitLabMergeRequest(len(f.pulls), pull))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func gitLabMergeRequest(iid int, pull fakePull) map[string]interface{} {
+	return map[string]interface{}{
+		"iid":     iid,
+		"web_url": fmt.Sprintf("https://gitlab.com/group/gitops/-/merge_requests/%d", iid),
+		"state":   pull.state,
+	}
+}
+
+var _ = Describe("Promoter", func() {
+	var (
+		ctx      context.Context
+		repo     *fakeRepository
+		server   *httptest.Server
+		promoter *gitops.Promoter
+		request  gitops.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = newFakeRepository()
+		promoter = gitops.NewPromoter(nil, 0)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("on GitHub", func() {
+		BeforeEach(func() {
+			server = httptest.NewServer(repo.gitHub())
+			request = gitops.Request{
+				Provider:   v1alpha1.GitHubPullRequestProvider,
+				APIURL:     server.URL,
+				Repository: "org/gitops",
+				Base:       "main",
+				Branch:     "cartographer/prod/app",
+				Path:       "deliveries/prod/app.yaml",
+				Content:    []byte("kind: ConfigMap\n"),
+				Title:      "Deliver prod/app",
+				Token:      "some-token",
+			}
+		})
+
+		It("opens a pull request from a branch holding the content", func() {
+			pullRequest, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullRequest).To(Equal(v1alpha1.DeliverablePullRequest{
+				URL:    "https://github.com/org/gitops/pull/1",
+				Number: 1,
+				State:  v1alpha1.OpenPullRequestState,
+				Branch: "cartographer/prod/app",
+				Digest: gitops.Digest(request.Content),
+			}))
+
+			Expect(repo.branches["cartographer/prod/app"]).To(Equal(map[string]string{"deliveries/prod/app.yaml": "kind: ConfigMap\n"}))
+			Expect(repo.branches["main"]).To(BeEmpty())
+			Expect(repo.pulls).To(Equal([]fakePull{{head: "cartographer/prod/app", base: "main", state: "open"}}))
+		})
+
+		It("reports the open pull request without pushing the same content again", func() {
+			_, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			pullRequest, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullRequest.State).To(Equal(v1alpha1.OpenPullRequestState))
+			Expect(pullRequest.Number).To(Equal(1))
+			Expect(repo.pushes).To(Equal(1))
+			Expect(repo.pulls).To(HaveLen(1))
+		})
+
+		It("updates the open pull request when the content changes", func() {
+			repo.branches["main"]["deliveries/prod/app.yaml"] = "kind: Secret\n"
+			_, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			request.Content = []byte("kind: ConfigMap\ndata: {}\n")
+			pullRequest, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullRequest.Number).To(Equal(1))
+			Expect(pullRequest.Digest).To(Equal(gitops.Digest(request.Content)))
+			Expect(repo.branches["cartographer/prod/app"]["deliveries/prod/app.yaml"]).To(Equal("kind: ConfigMap\ndata: {}\n"))
+			Expect(repo.pushes).To(Equal(2))
+			Expect(repo.pulls).To(HaveLen(1))
+		})
+
+		It("reports the pull request merged once the base holds the content", func() {
+			_, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			repo.pulls[0].state = "merged"
+			repo.branches["main"]["deliveries/prod/app.yaml"] = "kind: ConfigMap\n"
+
+			pullRequest, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullRequest.State).To(Equal(v1alpha1.MergedPullRequestState))
+			Expect(pullRequest.URL).To(Equal("https://github.com/org/gitops/pull/1"))
+		})
+
+		It("reports the base up to date when it holds the content without a pull request", func() {
+			repo.branches["main"]["deliveries/prod/app.yaml"] = "kind: ConfigMap\n"
+
+			pullRequest, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullRequest.State).To(Equal(v1alpha1.UpToDatePullRequestState))
+			Expect(pullRequest.URL).To(BeEmpty())
+			Expect(repo.pushes).To(Equal(0))
+			Expect(repo.pulls).To(BeEmpty())
+		})
+
+		It("reports a closed pull request until the content changes", func() {
+			_, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			repo.pulls[0].state = "closed"
+
+			pullRequest, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullRequest.State).To(Equal(v1alpha1.ClosedPullRequestState))
+			Expect(repo.pulls).To(HaveLen(1))
+
+			request.Content = []byte("kind: ConfigMap\ndata: {}\n")
+			pullRequest, err = promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullRequest.State).To(Equal(v1alpha1.OpenPullRequestState))
+			Expect(pullRequest.Number).To(Equal(2))
+		})
+
+		It("does not ask again within the poll interval, unless the content changes", func() {
+			promoter = gitops.NewPromoter(nil, time.Hour)
+			_, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			requests := len(repo.requests)
+
+			_, err = promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo.requests).To(HaveLen(requests))
+
+			request.Content = []byte("kind: ConfigMap\ndata: {}\n")
+			_, err = promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(repo.requests)).To(BeNumerically(">", requests))
+		})
+
+		It("returns the provider's errors", func() {
+			request.Token = "wrong"
+			_, err := promoter.Promote(ctx, request)
+			Expect(err).To(MatchError(ContainSubstring("GitHub repository 'org/gitops': GET /repos/org/gitops/contents/deliveries/prod/app.yaml: unexpected status 401: Bad credentials")))
+		})
+	})
+
+	Context("on GitLab", func() {
+		BeforeEach(func() {
+			server = httptest.NewServer(repo.gitLab())
+			request = gitops.Request{
+				Provider:   v1alpha1.GitLabPullRequestProvider,
+				APIURL:     server.URL + "/api/v4",
+				Repository: "group/gitops",
+				Base:       "main",
+				Branch:     "cartographer/prod/app",
+				Path:       "deliveries/prod/app.yaml",
+				Content:    []byte("kind: ConfigMap\n"),
+				Title:      "Deliver prod/app",
+				Token:      "some-token",
+			}
+		})
+
+		It("opens a merge request from a branch holding the content", func() {
+			pullRequest, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullRequest).To(Equal(v1alpha1.DeliverablePullRequest{
+				URL:    "https://gitlab.com/group/gitops/-/merge_requests/1",
+				Number: 1,
+				State:  v1alpha1.OpenPullRequestState,
+				Branch: "cartographer/prod/app",
+				Digest: gitops.Digest(request.Content),
+			}))
+			Expect(repo.branches["cartographer/prod/app"]).To(Equal(map[string]string{"deliveries/prod/app.yaml": "kind: ConfigMap\n"}))
+			Expect(repo.requests).To(ContainElement("GET /api/v4/projects/group%2Fgitops/repository/files/deliveries%2Fprod%2Fapp.yaml"))
+		})
+
+		It("updates the file on the base and reports the merge request merged", func() {
+			repo.branches["main"]["deliveries/prod/app.yaml"] = "kind: Secret\n"
+			_, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo.pushes).To(Equal(1))
+
+			repo.pulls[0].state = "merged"
+			repo.branches["main"]["deliveries/prod/app.yaml"] = "kind: ConfigMap\n"
+			pullRequest, err := promoter.Promote(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pullRequest.State).To(Equal(v1alpha1.MergedPullRequestState))
+		})
+	})
+
+	It("refuses unknown providers", func() {
+		server = httptest.NewServer(http.NotFoundHandler())
+		_, err := promoter.Promote(ctx, gitops.Request{Provider: "Gitea"})
+		Expect(err).To(MatchError("unknown pull request provider 'Gitea'"))
+	})
+})
@@ -0,0 +1,142 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittemplate
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/git"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+type gitRepository struct {
+	repository.Repository
+	resolver *Resolver
+}
+
+// WithGitTemplates returns a Repository that reads the templates that
+// references locate in git through resolver, and every other template
+// through repo.
+func WithGitTemplates(repo repository.Repository, resolver *Resolver) repository.Repository {
+	return &gitRepository{Repository: repo, resolver: resolver}
+}
+
+func (r *gitRepository) GetClusterTemplate(ctx context.Context, ref v1alpha1.ClusterTemplateReference) (templates.Template, error) {
+	if ref.Git == nil {
+		return r.Repository.GetClusterTemplate(ctx, ref)
+	}
+	return r.getTemplate(ctx, ref.Kind, *ref.Git)
+}
+
+func (r *gitRepository) GetDeliveryClusterTemplate(ctx context.Context, ref v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error) {
+	if ref.Git == nil {
+		return r.Repository.GetDeliveryClusterTemplate(ctx, ref)
+	}
+	return r.getTemplate(ctx, ref.Kind, *ref.Git)
+}
+
+func (r *gitRepository) getTemplate(ctx context.Context, kind string, source v1alpha1.GitTemplateSource) (templates.Template, error) {
+	apiTemplate, err := v1alpha1.GetAPITemplate(kind)
+	if err != nil {
+		return nil, fmt.Errorf("get api template: %w", err)
+	}
+
+	credentials, err := r.credentials(ctx, source.SecretRef)
+	if err != nil {
+		return nil, err
+	}
+
+	content, commit, err := r.resolver.Read(ctx, source, credentials)
+	if errors.Is(err, git.ErrNotFound) {
+		return nil, kerrors.NewNotFound(schema.GroupResource{Group: v1alpha1.SchemeGroupVersion.Group, Resource: kind}, source.String())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", source.String(), err)
+	}
+
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(content, &typeMeta); err != nil {
+		return nil, fmt.Errorf("decode %s at %s: %w", source.String(), commit, err)
+	}
+	if typeMeta.Kind != kind {
+		return nil, fmt.Errorf("%s at %s holds a %s, not a %s", source.String(), commit, typeMeta.Kind, kind)
+	}
+	if err := yaml.UnmarshalStrict(content, apiTemplate); err != nil {
+		return nil, fmt.Errorf("decode %s at %s: %w", source.String(), commit, err)
+	}
+
+	template, err := templates.NewModelFromAPI(apiTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("new model from api: %w", err)
+	}
+	return template, nil
+}
+
+type blueprintNamespaceKey struct{}
+
+// WithBlueprint returns a copy of ctx in which templates are read for
+// blueprint: they may only be fetched with Secrets of its namespace, or of
+// the controller's when the blueprint is cluster-scoped.
+func WithBlueprint(ctx context.Context, blueprint client.Object) context.Context {
+	return context.WithValue(ctx, blueprintNamespaceKey{}, v1alpha1.SecretNamespace(blueprint.GetNamespace()))
+}
+
+// credentials reads the username and password in the basic-auth Secret ref
+// refers to, refusing Secrets the blueprint in ctx may not read.
+func (r *gitRepository) credentials(ctx context.Context, ref *v1alpha1.SecretReference) (*git.Credentials, error) {
+	if ref == nil {
+		return nil, nil
+	}
+
+	namespace, ok := ctx.Value(blueprintNamespaceKey{}).(string)
+	if !ok {
+		return nil, fmt.Errorf("secret '%s/%s' cannot be read outside of a blueprint", ref.Namespace, ref.Name)
+	}
+	if ref.Namespace != namespace {
+		return nil, fmt.Errorf("secret '%s/%s' cannot be read: the blueprint may only read secrets in namespace '%s'", ref.Namespace, ref.Name, namespace)
+	}
+
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace(ref.Namespace)
+	secret.SetName(ref.Name)
+	if err := r.Repository.GetUnstructured(ctx, secret); err != nil {
+		return nil, fmt.Errorf("get secret '%s/%s': %w", ref.Namespace, ref.Name, err)
+	}
+
+	credentials := &git.Credentials{}
+	for key, value := range map[string]*string{"username": &credentials.Username, "password": &credentials.Password} {
+		encoded, _, _ := unstructured.NestedString(secret.Object, "data", key)
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(decoded) == 0 {
+			return nil, fmt.Errorf("secret '%s/%s' has no %s", ref.Namespace, ref.Name, key)
+		}
+		*value = string(decoded)
+	}
+	return credentials, nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittemplate_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/git"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate/gittemplatefakes"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+const configTemplateYAML = `
+apiVersion: carto.run/v1alpha1
+kind: ClusterConfigTemplate
+metadata:
+  name: app-config
+spec:
+  configPath: .data
+  template:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: $(workload.metadata.name)$
+`
+
+var _ = Describe("WithGitTemplates", func() {
+	var (
+		ctx     context.Context
+		fetcher *gittemplatefakes.FakeFetcher
+		inner   *repositoryfakes.FakeRepository
+		repo    repository.Repository
+		source  *v1alpha1.GitTemplateSource
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fetcher = &gittemplatefakes.FakeFetcher{}
+		fetcher.ResolveRefReturns("commit-1", nil)
+		fetcher.ReadFileReturns([]byte(configTemplateYAML), nil)
+		inner = &repositoryfakes.FakeRepository{}
+		repo = gittemplate.WithGitTemplates(inner, gittemplate.NewResolver(fetcher, time.Minute))
+		source = &v1alpha1.GitTemplateSource{
+			URL:  "https://example.com/templates.git",
+			Path: "config.yaml",
+		}
+	})
+
+	Describe("GetClusterTemplate", func() {
+		It("reads templates referenced by name through the repository", func() {
+			ref := v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Name: "app-config"}
+
+			_, err := repo.GetClusterTemplate(ctx, ref)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(inner.GetClusterTemplateCallCount()).To(Equal(1))
+			_, passedRef := inner.GetClusterTemplateArgsForCall(0)
+			Expect(passedRef).To(Equal(ref))
+			Expect(fetcher.ResolveRefCallCount()).To(Equal(0))
+		})
+
+		It("reads templates referenced in git from git", func() {
+			template, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(template.GetKind()).To(Equal("ClusterConfigTemplate"))
+			Expect(template.GetName()).To(Equal("app-config"))
+			Expect(template.GetResourceTemplate().Template).NotTo(BeNil())
+			Expect(inner.GetClusterTemplateCallCount()).To(Equal(0))
+		})
+
+		It("rejects a template of another kind", func() {
+			_, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Git: source})
+			Expect(err).To(MatchError("https://example.com/templates.git//config.yaml@HEAD at commit-1 holds a ClusterConfigTemplate, not a ClusterImageTemplate"))
+		})
+
+		It("rejects a file that is not a template", func() {
+			fetcher.ReadFileReturns([]byte("- not\n- a template\n"), nil)
+
+			_, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+			Expect(err).To(MatchError(ContainSubstring("decode https://example.com/templates.git//config.yaml@HEAD at commit-1")))
+		})
+
+		It("reports missing files as not found", func() {
+			fetcher.ReadFileReturns(nil, git.ErrNotFound)
+
+			_, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("wraps other fetch errors", func() {
+			fetcher.ResolveRefReturns("", errors.New("connection refused"))
+
+			_, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+			Expect(err).To(MatchError("read https://example.com/templates.git//config.yaml@HEAD: connection refused"))
+		})
+
+		Context("with a secret", func() {
+			BeforeEach(func() {
+				ctx = gittemplate.WithBlueprint(ctx, &v1alpha1.ClusterSupplyChain{})
+				source.SecretRef = &v1alpha1.SecretReference{Namespace: "cartographer-system", Name: "git-credentials"}
+			})
+
+			It("fetches with the credentials in the secret", func() {
+				inner.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					Expect(obj.GetKind()).To(Equal("Secret"))
+					Expect(obj.GetNamespace()).To(Equal("cartographer-system"))
+					Expect(obj.GetName()).To(Equal("git-credentials"))
+					obj.Object["data"] = map[string]interface{}{
+						"username": base64.StdEncoding.EncodeToString([]byte("user")),
+						"password": base64.StdEncoding.EncodeToString([]byte("secret")),
+					}
+					return nil
+				}
+
+				_, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, _, credentials := fetcher.ResolveRefArgsForCall(0)
+				Expect(credentials).To(Equal(&git.Credentials{Username: "user", Password: "secret"}))
+			})
+
+			It("rejects a secret without a password", func() {
+				inner.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					obj.Object["data"] = map[string]interface{}{
+						"username": base64.StdEncoding.EncodeToString([]byte("user")),
+					}
+					return nil
+				}
+
+				_, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+				Expect(err).To(MatchError("secret 'cartographer-system/git-credentials' has no password"))
+				Expect(fetcher.ResolveRefCallCount()).To(Equal(0))
+			})
+
+			It("returns errors getting the secret", func() {
+				inner.GetUnstructuredReturns(errors.New("forbidden"))
+
+				_, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+				Expect(err).To(MatchError("get secret 'cartographer-system/git-credentials': forbidden"))
+			})
+
+			It("refuses a secret of another namespace than the blueprint's", func() {
+				ctx = gittemplate.WithBlueprint(context.Background(), &v1alpha1.SupplyChain{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}})
+
+				_, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+				Expect(err).To(MatchError("secret 'cartographer-system/git-credentials' cannot be read: the blueprint may only read secrets in namespace 'team-a'"))
+				Expect(inner.GetUnstructuredCallCount()).To(Equal(0))
+				Expect(fetcher.ResolveRefCallCount()).To(Equal(0))
+			})
+
+			It("refuses a secret when no blueprint is known", func() {
+				ctx = context.Background()
+
+				_, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+				Expect(err).To(MatchError("secret 'cartographer-system/git-credentials' cannot be read outside of a blueprint"))
+				Expect(inner.GetUnstructuredCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("GetDeliveryClusterTemplate", func() {
+		It("reads templates referenced by name through the repository", func() {
+			ref := v1alpha1.DeliveryClusterTemplateReference{Kind: "ClusterTemplate", Name: "app-deploy"}
+
+			_, err := repo.GetDeliveryClusterTemplate(ctx, ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inner.GetDeliveryClusterTemplateCallCount()).To(Equal(1))
+		})
+
+		It("reads templates referenced in git from git", func() {
+			template, err := repo.GetDeliveryClusterTemplate(ctx, v1alpha1.DeliveryClusterTemplateReference{Kind: "ClusterConfigTemplate", Git: source})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template.GetName()).To(Equal("app-config"))
+			Expect(inner.GetDeliveryClusterTemplateCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,142 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittemplate
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/git"
+)
+
+const (
+	// DefaultRefInterval is how often branches and tags are resolved again.
+	DefaultRefInterval = time.Minute
+
+	// maxCachedFiles bounds the templates kept in memory.
+	maxCachedFiles = 256
+)
+
+//counterfeiter:generate . Fetcher
+type Fetcher interface {
+	ResolveRef(ctx context.Context, url, ref string, credentials *git.Credentials) (string, error)
+	ReadFile(ctx context.Context, url, commit, path string, credentials *git.Credentials) ([]byte, error)
+}
+
+type resolvedRef struct {
+	commit     string
+	resolvedAt time.Time
+}
+
+// Resolver reads templates from git through a Fetcher, caching what it
+// reads. A ref is resolved again once refInterval has passed since it was
+// last resolved; a file is read once per commit. What is read with one
+// Secret is only cached for sources read with that same Secret.
+type Resolver struct {
+	fetcher     Fetcher
+	refInterval time.Duration
+
+	mu    sync.Mutex
+	refs  map[string]resolvedRef
+	files map[string][]byte
+	// order of the cached files, oldest first.
+	order []string
+}
+
+func NewResolver(fetcher Fetcher, refInterval time.Duration) *Resolver {
+	return &Resolver{
+		fetcher:     fetcher,
+		refInterval: refInterval,
+		refs:        map[string]resolvedRef{},
+		files:       map[string][]byte{},
+	}
+}
+
+// Read returns the content of the file source points at, and the commit it
+// was read from. When resolving the ref again fails, the commit it was last
+// resolved to is read, unless the server refused the credentials.
+func (r *Resolver) Read(ctx context.Context, source v1alpha1.GitTemplateSource, credentials *git.Credentials) ([]byte, string, error) {
+	commit, err := r.resolve(ctx, source, credentials)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fileKey := identity(source) + ":" + source.URL + "@" + commit + ":" + source.Path
+	r.mu.Lock()
+	content, ok := r.files[fileKey]
+	r.mu.Unlock()
+	if ok {
+		return content, commit, nil
+	}
+
+	content, err = r.fetcher.ReadFile(ctx, source.URL, commit, source.Path, credentials)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.files[fileKey]; !ok {
+		r.files[fileKey] = content
+		r.order = append(r.order, fileKey)
+		if len(r.order) > maxCachedFiles {
+			delete(r.files, r.order[0])
+			r.order = r.order[1:]
+		}
+	}
+	return content, commit, nil
+}
+
+func (r *Resolver) resolve(ctx context.Context, source v1alpha1.GitTemplateSource, credentials *git.Credentials) (string, error) {
+	refKey := identity(source) + ":" + source.URL + "@" + source.Ref
+	r.mu.Lock()
+	last, ok := r.refs[refKey]
+	r.mu.Unlock()
+	if ok && time.Since(last.resolvedAt) < r.refInterval {
+		return last.commit, nil
+	}
+
+	commit, err := r.fetcher.ResolveRef(ctx, source.URL, source.Ref, credentials)
+	if errors.Is(err, git.ErrUnauthorized) {
+		r.mu.Lock()
+		delete(r.refs, refKey)
+		r.mu.Unlock()
+		return "", err
+	}
+	if err != nil {
+		if ok {
+			return last.commit, nil
+		}
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.refs[refKey] = resolvedRef{commit: commit, resolvedAt: time.Now()}
+	r.mu.Unlock()
+	return commit, nil
+}
+
+// identity names the Secret source is read with, or anonymous.
+func identity(source v1alpha1.GitTemplateSource) string {
+	if source.SecretRef == nil {
+		return "anonymous"
+	}
+	return source.SecretRef.Namespace + "/" + source.SecretRef.Name
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittemplate_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/git"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate/gittemplatefakes"
+)
+
+var _ = Describe("Resolver", func() {
+	var (
+		ctx      context.Context
+		fetcher  *gittemplatefakes.FakeFetcher
+		resolver *gittemplate.Resolver
+		source   v1alpha1.GitTemplateSource
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fetcher = &gittemplatefakes.FakeFetcher{}
+		fetcher.ResolveRefReturns("commit-1", nil)
+		fetcher.ReadFileReturns([]byte("content-1"), nil)
+		source = v1alpha1.GitTemplateSource{
+			URL:  "https://example.com/templates.git",
+			Ref:  "main",
+			Path: "app-deploy.yaml",
+		}
+	})
+
+	Context("within the ref interval", func() {
+		BeforeEach(func() {
+			resolver = gittemplate.NewResolver(fetcher, time.Hour)
+		})
+
+		It("resolves the ref and reads the file once", func() {
+			credentials := &git.Credentials{Username: "user", Password: "secret"}
+			for i := 0; i < 3; i++ {
+				content, commit, err := resolver.Read(ctx, source, credentials)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("content-1"))
+				Expect(commit).To(Equal("commit-1"))
+			}
+
+			Expect(fetcher.ResolveRefCallCount()).To(Equal(1))
+			_, url, ref, passedCredentials := fetcher.ResolveRefArgsForCall(0)
+			Expect(url).To(Equal("https://example.com/templates.git"))
+			Expect(ref).To(Equal("main"))
+			Expect(passedCredentials).To(Equal(credentials))
+
+			Expect(fetcher.ReadFileCallCount()).To(Equal(1))
+			_, url, commit, path, passedCredentials := fetcher.ReadFileArgsForCall(0)
+			Expect(url).To(Equal("https://example.com/templates.git"))
+			Expect(commit).To(Equal("commit-1"))
+			Expect(path).To(Equal("app-deploy.yaml"))
+			Expect(passedCredentials).To(Equal(credentials))
+		})
+
+		It("reads each path on its own", func() {
+			_, _, err := resolver.Read(ctx, source, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			source.Path = "other.yaml"
+			_, _, err = resolver.Read(ctx, source, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fetcher.ResolveRefCallCount()).To(Equal(1))
+			Expect(fetcher.ReadFileCallCount()).To(Equal(2))
+		})
+
+		It("returns errors resolving the ref", func() {
+			fetcher.ResolveRefReturns("", errors.New("connection refused"))
+
+			_, _, err := resolver.Read(ctx, source, nil)
+			Expect(err).To(MatchError("connection refused"))
+		})
+
+		It("does not share what it read with sources read with another secret", func() {
+			source.SecretRef = &v1alpha1.SecretReference{Namespace: "cartographer-system", Name: "private-templates"}
+			_, _, err := resolver.Read(ctx, source, &git.Credentials{Username: "user", Password: "secret"})
+			Expect(err).NotTo(HaveOccurred())
+
+			source.SecretRef = nil
+			fetcher.ResolveRefReturns("", git.ErrUnauthorized)
+			_, _, err = resolver.Read(ctx, source, nil)
+			Expect(err).To(MatchError(git.ErrUnauthorized))
+
+			source.SecretRef = &v1alpha1.SecretReference{Namespace: "team-a", Name: "private-templates"}
+			fetcher.ResolveRefReturns("commit-1", nil)
+			_, _, err = resolver.Read(ctx, source, &git.Credentials{Username: "other", Password: "other"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fetcher.ResolveRefCallCount()).To(Equal(3))
+			Expect(fetcher.ReadFileCallCount()).To(Equal(2))
+		})
+
+		It("does not cache errors reading the file", func() {
+			fetcher.ReadFileReturnsOnCall(0, nil, git.ErrNotFound)
+
+			_, _, err := resolver.Read(ctx, source, nil)
+			Expect(err).To(MatchError(git.ErrNotFound))
+
+			content, _, err := resolver.Read(ctx, source, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("content-1"))
+		})
+	})
+
+	Context("once the ref interval has passed", func() {
+		BeforeEach(func() {
+			resolver = gittemplate.NewResolver(fetcher, 0)
+		})
+
+		It("reads the commit the ref moved to", func() {
+			_, _, err := resolver.Read(ctx, source, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			fetcher.ResolveRefReturns("commit-2", nil)
+			fetcher.ReadFileReturns([]byte("content-2"), nil)
+
+			content, commit, err := resolver.Read(ctx, source, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("content-2"))
+			Expect(commit).To(Equal("commit-2"))
+			Expect(fetcher.ResolveRefCallCount()).To(Equal(2))
+		})
+
+		It("keeps reading the commit the ref was last resolved to when resolving fails", func() {
+			_, _, err := resolver.Read(ctx, source, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			fetcher.ResolveRefReturns("", errors.New("connection refused"))
+
+			content, commit, err := resolver.Read(ctx, source, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("content-1"))
+			Expect(commit).To(Equal("commit-1"))
+			Expect(fetcher.ReadFileCallCount()).To(Equal(1))
+		})
+
+		It("stops reading the commit the ref was last resolved to once the credentials are refused", func() {
+			_, _, err := resolver.Read(ctx, source, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			fetcher.ResolveRefReturns("", git.ErrUnauthorized)
+
+			_, _, err = resolver.Read(ctx, source, nil)
+			Expect(err).To(MatchError(git.ErrUnauthorized))
+
+			fetcher.ResolveRefReturns("", errors.New("connection refused"))
+
+			_, _, err = resolver.Read(ctx, source, nil)
+			Expect(err).To(MatchError("connection refused"))
+			Expect(fetcher.ReadFileCallCount()).To(Equal(1))
+		})
+	})
+})
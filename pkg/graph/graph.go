@@ -0,0 +1,451 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graph draws blueprints as graphs of their resources and the
+// outputs those resources pass to each other, along with, for a workload or
+// deliverable, the state of the objects stamped for it.
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// Graph is a blueprint's resources, in the order the blueprint lists them.
+type Graph struct {
+	Kind  string
+	Name  string
+	Owner *Owner
+	Nodes []Node
+}
+
+// Owner is the workload or deliverable a graph is realized for.
+type Owner struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Ready     Readiness
+}
+
+// Readiness is a Ready condition's status and reason. An empty status means
+// no readiness was reported.
+type Readiness struct {
+	Status string
+	Reason string
+}
+
+// Node is a resource of a blueprint.
+type Node struct {
+	Name         string
+	TemplateKind string
+	TemplateName string
+	Inputs       []Input
+
+	// Object is the object stamped for the resource. It is nil when the
+	// graph is not realized, or no object was found.
+	Object *Object
+}
+
+// Input is an output of another resource that a resource consumes.
+type Input struct {
+	// Resource is the resource producing the output.
+	Resource string
+	// Type is source, image or config.
+	Type string
+	// Name is the name the output is consumed under.
+	Name string
+}
+
+// Object is an object stamped for a resource.
+type Object struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Ready     Readiness
+	// Outputs are the template's outputs, as name=value, when they can be
+	// read from the object.
+	Outputs []string
+}
+
+type resource struct {
+	node            Node
+	targetNamespace string
+	// templateName and templateVersion find the template on the cluster.
+	templateName    string
+	templateVersion string
+}
+
+// SupplyChain returns the graph of the named ClusterSupplyChain. When
+// workload is set, the graph is realized for that workload.
+func SupplyChain(ctx context.Context, reader client.Reader, name string, workload *types.NamespacedName) (*Graph, error) {
+	supplyChain := &v1alpha1.ClusterSupplyChain{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: name}, supplyChain); err != nil {
+		return nil, fmt.Errorf("get supply chain '%s': %w", name, err)
+	}
+
+	return supplyChainGraph(ctx, reader, "ClusterSupplyChain", supplyChain.Name, supplyChain.Spec, workload)
+}
+
+// NamespacedSupplyChain returns the graph of the named SupplyChain, realized
+// for workload when it is set.
+func NamespacedSupplyChain(ctx context.Context, reader client.Reader, name types.NamespacedName, workload *types.NamespacedName) (*Graph, error) {
+	supplyChain := &v1alpha1.SupplyChain{}
+	if err := reader.Get(ctx, name, supplyChain); err != nil {
+		return nil, fmt.Errorf("get supply chain '%s': %w", name, err)
+	}
+
+	return supplyChainGraph(ctx, reader, "SupplyChain", supplyChain.Name, supplyChain.Spec, workload)
+}
+
+func supplyChainGraph(ctx context.Context, reader client.Reader, kind, name string, spec v1alpha1.SupplyChainSpec, workload *types.NamespacedName) (*Graph, error) {
+	var resources []resource
+	for _, r := range spec.Resources {
+		var inputs []Input
+		inputs = appendInputs(inputs, "source", r.Sources)
+		inputs = appendInputs(inputs, "image", r.Images)
+		inputs = appendInputs(inputs, "config", r.Configs)
+		resources = append(resources, resource{
+			node: Node{
+				Name:         r.Name,
+				TemplateKind: r.TemplateRef.Kind,
+				TemplateName: r.TemplateRef.DisplayName(),
+				Inputs:       inputs,
+			},
+			targetNamespace: r.TargetNamespace,
+			templateName:    r.TemplateRef.Name,
+			templateVersion: r.TemplateRef.Version,
+		})
+	}
+
+	g := &Graph{Kind: kind, Name: name}
+	if workload == nil {
+		g.Nodes = nodes(resources)
+		return g, nil
+	}
+
+	w := &v1alpha1.Workload{}
+	if err := reader.Get(ctx, *workload, w); err != nil {
+		return nil, fmt.Errorf("get workload '%s': %w", workload, err)
+	}
+
+	// A resource with options is realized with the template of the option
+	// the workload matches, and not at all when it matches none.
+	for i, r := range spec.Resources {
+		if len(r.TemplateRef.Options) == 0 {
+			continue
+		}
+		templateRef, err := r.TemplateRef.ForWorkload(w)
+		if err != nil {
+			continue
+		}
+		resources[i].node.TemplateName = templateRef.DisplayName()
+		resources[i].templateName = templateRef.Name
+	}
+	g.Owner = &Owner{
+		Kind:      "Workload",
+		Namespace: w.Namespace,
+		Name:      w.Name,
+		Ready:     conditionReadiness(w.Status.Conditions, v1alpha1.WorkloadReady),
+	}
+
+	labels := map[string]string{
+		"carto.run/workload-name":             w.Name,
+		"carto.run/workload-namespace":        w.Namespace,
+		"carto.run/cluster-supply-chain-name": name,
+	}
+	if err := realize(ctx, reader, resources, w.Namespace, labels); err != nil {
+		return nil, err
+	}
+	g.Nodes = nodes(resources)
+	return g, nil
+}
+
+// Delivery returns the graph of the named ClusterDelivery. When deliverable
+// is set, the graph is realized for that deliverable.
+func Delivery(ctx context.Context, reader client.Reader, name string, deliverable *types.NamespacedName) (*Graph, error) {
+	delivery := &v1alpha1.ClusterDelivery{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: name}, delivery); err != nil {
+		return nil, fmt.Errorf("get delivery '%s': %w", name, err)
+	}
+
+	var resources []resource
+	for _, r := range delivery.Spec.Resources {
+		var inputs []Input
+		inputs = appendInputs(inputs, "source", r.Sources)
+		inputs = appendInputs(inputs, "config", r.Configs)
+		resources = append(resources, resource{
+			node: Node{
+				Name:         r.Name,
+				TemplateKind: r.TemplateRef.Kind,
+				TemplateName: r.TemplateRef.DisplayName(),
+				Inputs:       inputs,
+			},
+			templateName:    r.TemplateRef.Name,
+			templateVersion: r.TemplateRef.Version,
+		})
+	}
+
+	g := &Graph{Kind: "ClusterDelivery", Name: delivery.Name}
+	if deliverable == nil {
+		g.Nodes = nodes(resources)
+		return g, nil
+	}
+
+	d := &v1alpha1.Deliverable{}
+	if err := reader.Get(ctx, *deliverable, d); err != nil {
+		return nil, fmt.Errorf("get deliverable '%s': %w", deliverable, err)
+	}
+	g.Owner = &Owner{
+		Kind:      "Deliverable",
+		Namespace: d.Namespace,
+		Name:      d.Name,
+		Ready:     conditionReadiness(d.Status.Conditions, v1alpha1.DeliverableReady),
+	}
+
+	labels := map[string]string{
+		"carto.run/deliverable-name":      d.Name,
+		"carto.run/deliverable-namespace": d.Namespace,
+		"carto.run/cluster-delivery-name": delivery.Name,
+	}
+	if err := realize(ctx, reader, resources, d.Namespace, labels); err != nil {
+		return nil, err
+	}
+	g.Nodes = nodes(resources)
+	return g, nil
+}
+
+func appendInputs(inputs []Input, inputType string, references []v1alpha1.ResourceReference) []Input {
+	for _, reference := range references {
+		inputs = append(inputs, Input{Resource: reference.Resource, Type: inputType, Name: reference.Name})
+	}
+	return inputs
+}
+
+func nodes(resources []resource) []Node {
+	var nodes []Node
+	for _, r := range resources {
+		nodes = append(nodes, r.node)
+	}
+	return nodes
+}
+
+// realize finds the object stamped for each resource, by the labels the
+// realizer stamps it with, in the owner's namespace or the resource's
+// target namespace.
+func realize(ctx context.Context, reader client.Reader, resources []resource, namespace string, labels map[string]string) error {
+	for i := range resources {
+		r := &resources[i]
+		// templates fetched from git, or chosen by an option the owner
+		// does not match, are not on the cluster.
+		if r.templateName == "" {
+			continue
+		}
+
+		template, err := getTemplate(ctx, reader, r.node.TemplateKind, r.templateName, r.templateVersion)
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		objectNamespace := namespace
+		if r.targetNamespace != "" {
+			objectNamespace = r.targetNamespace
+		}
+		objectLabels := map[string]string{"carto.run/resource-name": r.node.Name}
+		for key, value := range labels {
+			objectLabels[key] = value
+		}
+
+		stamped, err := stampedObject(ctx, reader, template, objectNamespace, objectLabels)
+		if err != nil {
+			return fmt.Errorf("find object stamped for resource '%s': %w", r.node.Name, err)
+		}
+		if stamped == nil {
+			continue
+		}
+
+		object := &Object{
+			Kind:      stamped.GetKind(),
+			Namespace: stamped.GetNamespace(),
+			Name:      stamped.GetName(),
+			Ready:     objectReadiness(stamped),
+		}
+		// A job's outputs are read from its results, not from the job.
+		if !template.GetResourceTemplate().IsJob() {
+			if output, err := template.GetOutput(stamped); err == nil {
+				object.Outputs = formatOutput(output)
+			}
+		}
+		r.node.Object = object
+	}
+	return nil
+}
+
+func getTemplate(ctx context.Context, reader client.Reader, kind, name, version string) (templates.Template, error) {
+	var apiTemplate client.Object
+	switch kind {
+	case "ClusterSourceTemplate":
+		apiTemplate = &v1alpha1.ClusterSourceTemplate{}
+	case "ClusterImageTemplate":
+		apiTemplate = &v1alpha1.ClusterImageTemplate{}
+	case "ClusterConfigTemplate":
+		apiTemplate = &v1alpha1.ClusterConfigTemplate{}
+	case "ClusterDeploymentTemplate":
+		apiTemplate = &v1alpha1.ClusterDeploymentTemplate{}
+	case "ClusterTemplate":
+		apiTemplate = &v1alpha1.ClusterTemplate{}
+	default:
+		return nil, fmt.Errorf("unknown template kind '%s'", kind)
+	}
+
+	if version == "" {
+		if err := reader.Get(ctx, types.NamespacedName{Name: name}, apiTemplate); err != nil {
+			return nil, err
+		}
+		return templates.NewModelFromAPI(apiTemplate)
+	}
+
+	// A template pinned to a version is the one labelled as that version.
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(kind + "List"))
+	if err := reader.List(ctx, list, client.MatchingLabels{
+		v1alpha1.TemplateNameLabel:    name,
+		v1alpha1.TemplateVersionLabel: version,
+	}); err != nil {
+		return nil, err
+	}
+	if len(list.Items) != 1 {
+		return nil, kerrors.NewNotFound(v1alpha1.SchemeGroupVersion.WithResource(kind).GroupResource(), name+"@"+version)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[0].Object, apiTemplate); err != nil {
+		return nil, err
+	}
+	return templates.NewModelFromAPI(apiTemplate)
+}
+
+// stampedObject returns the newest object of the kind template stamps that
+// carries labels, or nil when there is none. Objects stamped by ytt
+// templates, whose kind is only known once stamped, are not found.
+func stampedObject(ctx context.Context, reader client.Reader, template templates.Template, namespace string, labels map[string]string) (*unstructured.Unstructured, error) {
+	raw := template.GetResourceTemplate().Template
+	if raw == nil {
+		return nil, nil
+	}
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw.Raw, &typeMeta); err != nil || typeMeta.Kind == "" {
+		return nil, nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion(typeMeta.APIVersion)
+	list.SetKind(typeMeta.Kind + "List")
+	if err := reader.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels(labels)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+
+	items := list.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].GetCreationTimestamp().Time.After(items[j].GetCreationTimestamp().Time)
+	})
+	return &items[0], nil
+}
+
+func conditionReadiness(conditions []metav1.Condition, conditionType string) Readiness {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	if condition == nil {
+		return Readiness{}
+	}
+	return Readiness{Status: string(condition.Status), Reason: condition.Reason}
+}
+
+// objectReadiness reads the Ready, or Succeeded, condition of obj, or the
+// Complete and Failed conditions of a job.
+func objectReadiness(obj *unstructured.Unstructured) Readiness {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	byType := map[string]map[string]interface{}{}
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]interface{}); ok {
+			if conditionType, ok := condition["type"].(string); ok {
+				byType[conditionType] = condition
+			}
+		}
+	}
+
+	readiness := func(condition map[string]interface{}) Readiness {
+		status, _ := condition["status"].(string)
+		reason, _ := condition["reason"].(string)
+		return Readiness{Status: status, Reason: reason}
+	}
+
+	for _, conditionType := range []string{"Ready", "Succeeded"} {
+		if condition, ok := byType[conditionType]; ok {
+			return readiness(condition)
+		}
+	}
+	if condition, ok := byType["Failed"]; ok && condition["status"] == "True" {
+		failed := readiness(condition)
+		failed.Status = "False"
+		return failed
+	}
+	if condition, ok := byType["Complete"]; ok {
+		return readiness(condition)
+	}
+	return Readiness{}
+}
+
+func formatOutput(output *templates.Output) []string {
+	var outputs []string
+	if output.Source != nil {
+		outputs = append(outputs, "url="+formatValue(output.Source.URL), "revision="+formatValue(output.Source.Revision))
+	}
+	if output.Image != nil {
+		outputs = append(outputs, "image="+formatValue(output.Image))
+	}
+	if output.Config != nil {
+		outputs = append(outputs, "config="+formatValue(output.Config))
+	}
+	return outputs
+}
+
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
@@ -0,0 +1,270 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/graph"
+)
+
+var _ = Describe("Graph", func() {
+	var (
+		ctx         context.Context
+		supplyChain *v1alpha1.ClusterSupplyChain
+		objects     []client.Object
+		reader      client.Reader
+		out         *bytes.Buffer
+	)
+
+	configMapTemplate := func(name string) v1alpha1.TemplateSpec {
+		return v1alpha1.TemplateSpec{
+			Template: &runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "` + name + `"}}`)},
+		}
+	}
+
+	stamped := func(name, resource string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				Labels: map[string]string{
+					"carto.run/workload-name":             "app",
+					"carto.run/workload-namespace":        "default",
+					"carto.run/cluster-supply-chain-name": "source-to-url",
+					"carto.run/resource-name":             resource,
+				},
+			},
+			Data: data,
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		out = &bytes.Buffer{}
+
+		supplyChain = &v1alpha1.ClusterSupplyChain{
+			ObjectMeta: metav1.ObjectMeta{Name: "source-to-url"},
+			Spec: v1alpha1.SupplyChainSpec{
+				Resources: []v1alpha1.SupplyChainResource{
+					{
+						Name:        "source-provider",
+						TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterSourceTemplate", Name: "git-source"},
+					},
+					{
+						Name:        "image-builder",
+						TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "builder"},
+						Sources:     []v1alpha1.ResourceReference{{Name: "source", Resource: "source-provider"}},
+					},
+					{
+						Name:        "deployer",
+						TemplateRef: v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "app-deploy"},
+						Sources:     []v1alpha1.ResourceReference{{Name: "source", Resource: "source-provider"}},
+						Images:      []v1alpha1.ResourceReference{{Name: "image", Resource: "image-builder"}},
+					},
+				},
+			},
+		}
+
+		objects = []client.Object{
+			supplyChain,
+			&v1alpha1.ClusterSourceTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "git-source"},
+				Spec: v1alpha1.SourceTemplateSpec{
+					TemplateSpec: configMapTemplate("app-source"),
+					URLPath:      ".data.url",
+					RevisionPath: ".data.revision",
+				},
+			},
+			&v1alpha1.ClusterImageTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "builder"},
+				Spec: v1alpha1.ImageTemplateSpec{
+					TemplateSpec: configMapTemplate("app-image"),
+					ImagePath:    ".data.image",
+				},
+			},
+			&v1alpha1.ClusterTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-deploy"},
+				Spec:       configMapTemplate("app-deploy"),
+			},
+			&v1alpha1.Workload{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+				Status: v1alpha1.WorkloadStatus{
+					Conditions: []metav1.Condition{
+						{Type: "Ready", Status: metav1.ConditionFalse, Reason: "MissingValueAtPath"},
+					},
+				},
+			},
+			stamped("app-source", "source-provider", map[string]string{"url": "https://example.com/app.tar.gz", "revision": "abc123"}),
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		reader = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	})
+
+	Describe("SupplyChain", func() {
+		It("links each resource to the resources consuming its outputs", func() {
+			g, err := graph.SupplyChain(ctx, reader, "source-to-url", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(g.Owner).To(BeNil())
+			Expect(g.Nodes).To(HaveLen(3))
+			Expect(g.Nodes[2].Inputs).To(Equal([]graph.Input{
+				{Resource: "source-provider", Type: "source", Name: "source"},
+				{Resource: "image-builder", Type: "image", Name: "image"},
+			}))
+
+			Expect(graph.WriteText(out, g)).To(Succeed())
+			Expect(out.String()).To(Equal("ClusterSupplyChain/source-to-url\n" +
+				"\n" +
+				"source-provider [ClusterSourceTemplate/git-source]\n" +
+				"  |--> image-builder (source: source)\n" +
+				"  `--> deployer (source: source)\n" +
+				"\n" +
+				"image-builder [ClusterImageTemplate/builder]\n" +
+				"  `--> deployer (image: image)\n" +
+				"\n" +
+				"deployer [ClusterTemplate/app-deploy]\n"))
+		})
+
+		It("shows the objects stamped for a workload, their readiness and outputs", func() {
+			g, err := graph.SupplyChain(ctx, reader, "source-to-url", &types.NamespacedName{Namespace: "default", Name: "app"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(graph.WriteText(out, g)).To(Succeed())
+			Expect(out.String()).To(Equal("ClusterSupplyChain/source-to-url\n" +
+				"Workload default/app: Ready=False (MissingValueAtPath)\n" +
+				"\n" +
+				"source-provider [ClusterSourceTemplate/git-source]\n" +
+				"  object:  ConfigMap default/app-source\n" +
+				"  ready:   -\n" +
+				"  outputs: url=https://example.com/app.tar.gz, revision=abc123\n" +
+				"  |--> image-builder (source: source)\n" +
+				"  `--> deployer (source: source)\n" +
+				"\n" +
+				"image-builder [ClusterImageTemplate/builder]\n" +
+				"  object:  <not found>\n" +
+				"  `--> deployer (image: image)\n" +
+				"\n" +
+				"deployer [ClusterTemplate/app-deploy]\n" +
+				"  object:  <not found>\n"))
+		})
+
+		It("writes the graph in the dot language", func() {
+			g, err := graph.SupplyChain(ctx, reader, "source-to-url", &types.NamespacedName{Namespace: "default", Name: "app"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(graph.WriteDot(out, g)).To(Succeed())
+			Expect(out.String()).To(HavePrefix(`digraph "ClusterSupplyChain/source-to-url" {`))
+			Expect(out.String()).To(ContainSubstring(`"source-provider" [label="source-provider\nClusterSourceTemplate/git-source\nConfigMap app-source\n-\nurl=https://example.com/app.tar.gz\nrevision=abc123", color=black];`))
+			Expect(out.String()).To(ContainSubstring(`"image-builder" [label="image-builder\nClusterImageTemplate/builder\n<not found>", color=gray];`))
+			Expect(out.String()).To(ContainSubstring(`"image-builder" -> "deployer" [label="image: image"];`))
+			Expect(out.String()).To(HaveSuffix("}\n"))
+		})
+
+		When("a resource stamps a job", func() {
+			BeforeEach(func() {
+				objects = append(objects,
+					&v1alpha1.ClusterTemplate{
+						ObjectMeta: metav1.ObjectMeta{Name: "scan"},
+						Spec: v1alpha1.TemplateSpec{
+							Template:  &runtime.RawExtension{Raw: []byte(`{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "scan"}}`)},
+							Lifecycle: v1alpha1.JobTemplateLifecycle,
+						},
+					},
+					&batchv1.Job{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "default",
+							Name:      "scan-0123456789",
+							Labels:    stamped("", "deployer", nil).Labels,
+						},
+						Status: batchv1.JobStatus{
+							Conditions: []batchv1.JobCondition{
+								{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+							},
+						},
+					},
+				)
+				supplyChain.Spec.Resources[2].TemplateRef = v1alpha1.ClusterTemplateReference{Kind: "ClusterTemplate", Name: "scan"}
+			})
+
+			It("reads its readiness from the job's conditions", func() {
+				g, err := graph.SupplyChain(ctx, reader, "source-to-url", &types.NamespacedName{Namespace: "default", Name: "app"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(g.Nodes[2].Object).To(Equal(&graph.Object{
+					Kind:      "Job",
+					Namespace: "default",
+					Name:      "scan-0123456789",
+					Ready:     graph.Readiness{Status: "False", Reason: "BackoffLimitExceeded"},
+				}))
+			})
+		})
+
+		It("returns an error when the workload does not exist", func() {
+			_, err := graph.SupplyChain(ctx, reader, "source-to-url", &types.NamespacedName{Namespace: "default", Name: "nope"})
+			Expect(err).To(MatchError(ContainSubstring("get workload 'default/nope'")))
+		})
+
+		It("returns an error when the supply chain does not exist", func() {
+			_, err := graph.SupplyChain(ctx, reader, "nope", nil)
+			Expect(err).To(MatchError(ContainSubstring("get supply chain 'nope'")))
+		})
+	})
+
+	Describe("Delivery", func() {
+		BeforeEach(func() {
+			objects = append(objects, &v1alpha1.ClusterDelivery{
+				ObjectMeta: metav1.ObjectMeta{Name: "delivery"},
+				Spec: v1alpha1.ClusterDeliverySpec{
+					Resources: []v1alpha1.ClusterDeliveryResource{
+						{
+							Name:        "source-provider",
+							TemplateRef: v1alpha1.DeliveryClusterTemplateReference{Kind: "ClusterSourceTemplate", Name: "git-source"},
+						},
+						{
+							Name:        "deployer",
+							TemplateRef: v1alpha1.DeliveryClusterTemplateReference{Kind: "ClusterTemplate", Name: "app-deploy"},
+							Sources:     []v1alpha1.ResourceReference{{Name: "source", Resource: "source-provider"}},
+						},
+					},
+				},
+			})
+		})
+
+		It("links each resource to the resources consuming its outputs", func() {
+			g, err := graph.Delivery(ctx, reader, "delivery", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(graph.WriteText(out, g)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("source-provider [ClusterSourceTemplate/git-source]\n  `--> deployer (source: source)\n"))
+		})
+	})
+})
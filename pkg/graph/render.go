@@ -0,0 +1,143 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type edge struct {
+	to    string
+	input Input
+}
+
+// WriteText writes g to out as text: each resource, the object stamped for
+// it when g is realized, and arrows to the resources consuming its outputs.
+func WriteText(out io.Writer, g *Graph) error {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "%s/%s\n", g.Kind, g.Name)
+	if g.Owner != nil {
+		fmt.Fprintf(b, "%s %s/%s: %s\n", g.Owner.Kind, g.Owner.Namespace, g.Owner.Name, formatReadiness(g.Owner.Ready))
+	}
+
+	consumers := edgesFrom(g)
+	for _, node := range g.Nodes {
+		fmt.Fprintf(b, "\n%s [%s/%s]\n", node.Name, node.TemplateKind, node.TemplateName)
+		if g.Owner != nil {
+			if node.Object == nil {
+				fmt.Fprintf(b, "  object:  <not found>\n")
+			} else {
+				fmt.Fprintf(b, "  object:  %s %s/%s\n", node.Object.Kind, node.Object.Namespace, node.Object.Name)
+				fmt.Fprintf(b, "  ready:   %s\n", formatReadiness(node.Object.Ready))
+				if len(node.Object.Outputs) > 0 {
+					fmt.Fprintf(b, "  outputs: %s\n", strings.Join(node.Object.Outputs, ", "))
+				}
+			}
+		}
+
+		edges := consumers[node.Name]
+		for i, e := range edges {
+			branch := "|--> "
+			if i == len(edges)-1 {
+				branch = "`--> "
+			}
+			fmt.Fprintf(b, "  %s%s (%s)\n", branch, e.to, formatInput(e.input))
+		}
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// WriteDot writes g to out in the Graphviz dot language, coloring the
+// resources of a realized graph by their readiness.
+func WriteDot(out io.Writer, g *Graph) error {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "digraph %s {\n", strconv.Quote(g.Kind+"/"+g.Name))
+	fmt.Fprintf(b, "  rankdir=LR;\n")
+	fmt.Fprintf(b, "  node [shape=box];\n")
+	if g.Owner != nil {
+		fmt.Fprintf(b, "  label=%s;\n", strconv.Quote(fmt.Sprintf("%s %s/%s: %s", g.Owner.Kind, g.Owner.Namespace, g.Owner.Name, formatReadiness(g.Owner.Ready))))
+	}
+
+	for _, node := range g.Nodes {
+		lines := []string{node.Name, node.TemplateKind + "/" + node.TemplateName}
+		color := "black"
+		if g.Owner != nil {
+			if node.Object == nil {
+				lines = append(lines, "<not found>")
+				color = "gray"
+			} else {
+				lines = append(lines, node.Object.Kind+" "+node.Object.Name, formatReadiness(node.Object.Ready))
+				lines = append(lines, node.Object.Outputs...)
+				color = readinessColor(node.Object.Ready)
+			}
+		}
+		fmt.Fprintf(b, "  %s [label=%s, color=%s];\n", strconv.Quote(node.Name), strconv.Quote(strings.Join(lines, "\n")), color)
+	}
+
+	for _, node := range g.Nodes {
+		for _, input := range node.Inputs {
+			fmt.Fprintf(b, "  %s -> %s [label=%s];\n", strconv.Quote(input.Resource), strconv.Quote(node.Name), strconv.Quote(formatInput(input)))
+		}
+	}
+
+	fmt.Fprintf(b, "}\n")
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func edgesFrom(g *Graph) map[string][]edge {
+	edges := map[string][]edge{}
+	for _, node := range g.Nodes {
+		for _, input := range node.Inputs {
+			edges[input.Resource] = append(edges[input.Resource], edge{to: node.Name, input: input})
+		}
+	}
+	return edges
+}
+
+func formatInput(input Input) string {
+	return input.Type + ": " + input.Name
+}
+
+func formatReadiness(readiness Readiness) string {
+	if readiness.Status == "" {
+		return "-"
+	}
+	if readiness.Reason == "" {
+		return "Ready=" + readiness.Status
+	}
+	return fmt.Sprintf("Ready=%s (%s)", readiness.Status, readiness.Reason)
+}
+
+func readinessColor(readiness Readiness) string {
+	switch readiness.Status {
+	case "True":
+		return "green"
+	case "False":
+		return "red"
+	case "Unknown":
+		return "orange"
+	default:
+		return "black"
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package history keeps the realization history of workloads and
+// deliverables: the latest realizations that submitted every resource, and
+// what changed between them.
+package history
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// MaxRecords is the number of realizations kept in an owner's history.
+const MaxRecords = 10
+
+// Record adds record to the front of records, with the changes since the
+// latest record, keeping the MaxRecords newest. When record realized the
+// same as the latest record, records are returned as they are, and false.
+func Record(records []v1alpha1.RealizationRecord, record v1alpha1.RealizationRecord) ([]v1alpha1.RealizationRecord, bool) {
+	record.Changes = nil
+	if len(records) > 0 {
+		if same(records[0], record) {
+			return records, false
+		}
+		record.Changes = changes(records[0], record)
+	}
+
+	updated := append([]v1alpha1.RealizationRecord{record}, records...)
+	if len(updated) > MaxRecords {
+		updated = updated[:MaxRecords]
+	}
+	return updated, true
+}
+
+func same(a, b v1alpha1.RealizationRecord) bool {
+	return a.Generation == b.Generation &&
+		a.ParamsDigest == b.ParamsDigest &&
+		a.Blueprint == b.Blueprint &&
+		a.BlueprintGeneration == b.BlueprintGeneration &&
+		equality.Semantic.DeepEqual(a.Resources, b.Resources)
+}
+
+func changes(previous, current v1alpha1.RealizationRecord) []string {
+	var changed []string
+	if previous.Generation != current.Generation {
+		changed = append(changed, fmt.Sprintf("generation %d -> %d", previous.Generation, current.Generation))
+	}
+	if previous.ParamsDigest != current.ParamsDigest {
+		changed = append(changed, "params changed")
+	}
+	if previous.Blueprint != current.Blueprint {
+		changed = append(changed, fmt.Sprintf("blueprint '%s' -> '%s'", previous.Blueprint, current.Blueprint))
+	} else if previous.BlueprintGeneration != current.BlueprintGeneration {
+		changed = append(changed, fmt.Sprintf("blueprint '%s' generation %d -> %d", current.Blueprint, previous.BlueprintGeneration, current.BlueprintGeneration))
+	}
+
+	previousResources := map[string]v1alpha1.RealizedResource{}
+	for _, resource := range previous.Resources {
+		previousResources[resource.Resource] = resource
+	}
+	currentResources := map[string]bool{}
+	for _, resource := range current.Resources {
+		currentResources[resource.Resource] = true
+		was, ok := previousResources[resource.Resource]
+		if !ok {
+			changed = append(changed, fmt.Sprintf("resource '%s' added", resource.Resource))
+			continue
+		}
+		changed = append(changed, resourceChanges(was, resource)...)
+	}
+	for _, resource := range previous.Resources {
+		if !currentResources[resource.Resource] {
+			changed = append(changed, fmt.Sprintf("resource '%s' removed", resource.Resource))
+		}
+	}
+	return changed
+}
+
+func resourceChanges(previous, current v1alpha1.RealizedResource) []string {
+	var changed []string
+	if previous.Template != current.Template {
+		changed = append(changed, fmt.Sprintf("resource '%s' template '%s' -> '%s'", current.Resource, display(previous.Template), display(current.Template)))
+	} else if previous.TemplateGeneration != current.TemplateGeneration {
+		changed = append(changed, fmt.Sprintf("resource '%s' template '%s' generation %d -> %d", current.Resource, display(current.Template), previous.TemplateGeneration, current.TemplateGeneration))
+	}
+	if previous.SourceRevision != current.SourceRevision {
+		changed = append(changed, fmt.Sprintf("resource '%s' source revision '%s' -> '%s'", current.Resource, previous.SourceRevision, current.SourceRevision))
+	}
+	if previous.Stamped != current.Stamped {
+		changed = append(changed, fmt.Sprintf("resource '%s' stamped '%s' -> '%s'", current.Resource, display(previous.Stamped), display(current.Stamped)))
+	}
+	return changed
+}
+
+func display(ref v1alpha1.ObjectReference) string {
+	if ref.Namespace == "" {
+		return fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", ref.Kind, ref.Namespace, ref.Name)
+}
+
+// Resource describes the resource named resourceName, stamped from template
+// as stamped, which output output.
+func Resource(resourceName string, template templates.Template, stamped *unstructured.Unstructured, output *templates.Output) v1alpha1.RealizedResource {
+	resource := v1alpha1.RealizedResource{
+		Resource: resourceName,
+		Template: v1alpha1.ObjectReference{
+			Kind: template.GetKind(),
+			Name: template.GetName(),
+		},
+		TemplateGeneration: template.GetGeneration(),
+		Stamped: v1alpha1.ObjectReference{
+			APIVersion: stamped.GetAPIVersion(),
+			Kind:       stamped.GetKind(),
+			Namespace:  stamped.GetNamespace(),
+			Name:       stamped.GetName(),
+		},
+	}
+	if output != nil && output.Source != nil {
+		resource.SourceRevision = revision(output.Source.Revision)
+	}
+	return resource
+}
+
+func revision(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
+
+// Sort orders resources by name.
+func Sort(resources []v1alpha1.RealizedResource) {
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Resource < resources[j].Resource
+	})
+}
+
+// ParamsDigest returns a digest of params, or "" when there are none.
+func ParamsDigest(params []v1alpha1.Param) string {
+	if len(params) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(encoded))
+}
+
+// Manager returns the field manager that last changed obj's spec, or ""
+// when obj's managed fields do not tell.
+func Manager(obj metav1.Object) string {
+	var (
+		manager string
+		latest  metav1.Time
+	)
+	for _, entry := range obj.GetManagedFields() {
+		if entry.FieldsV1 == nil || !bytes.Contains(entry.FieldsV1.Raw, []byte(`"f:spec"`)) {
+			continue
+		}
+		if entry.Time == nil || !entry.Time.Before(&latest) {
+			manager = entry.Manager
+			if entry.Time != nil {
+				latest = *entry.Time
+			}
+		}
+	}
+	return manager
+}
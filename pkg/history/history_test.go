@@ -0,0 +1,190 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/eval"
+	"github.com/vmware-tanzu/cartographer/pkg/history"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+var _ = Describe("Record", func() {
+	var record v1alpha1.RealizationRecord
+
+	BeforeEach(func() {
+		record = v1alpha1.RealizationRecord{
+			Generation:          2,
+			Blueprint:           "web",
+			BlueprintGeneration: 3,
+			Resources: []v1alpha1.RealizedResource{
+				{
+					Resource:           "source-provider",
+					Template:           v1alpha1.ObjectReference{Kind: "ClusterSourceTemplate", Name: "git"},
+					TemplateGeneration: 1,
+					SourceRevision:     "main/abc",
+					Stamped:            v1alpha1.ObjectReference{Kind: "GitRepository", Namespace: "ns", Name: "app"},
+				},
+				{
+					Resource:           "deployer",
+					Template:           v1alpha1.ObjectReference{Kind: "ClusterTemplate", Name: "app-deploy"},
+					TemplateGeneration: 4,
+					Stamped:            v1alpha1.ObjectReference{Kind: "App", Namespace: "ns", Name: "app"},
+				},
+			},
+		}
+	})
+
+	It("records the first realization without changes", func() {
+		records, changed := history.Record(nil, record)
+		Expect(changed).To(BeTrue())
+		Expect(records).To(Equal([]v1alpha1.RealizationRecord{record}))
+	})
+
+	It("does not record a realization that realized the same as the latest", func() {
+		previous := record
+		previous.Time = metav1.Unix(100, 0)
+		record.Time = metav1.Unix(200, 0)
+		record.Manager = "kubectl"
+
+		records, changed := history.Record([]v1alpha1.RealizationRecord{previous}, record)
+		Expect(changed).To(BeFalse())
+		Expect(records).To(Equal([]v1alpha1.RealizationRecord{previous}))
+	})
+
+	It("records what changed since the latest realization, newest first", func() {
+		previous := record
+		previous.Resources = append([]v1alpha1.RealizedResource{}, record.Resources...)
+		record.Generation = 3
+		record.ParamsDigest = "sha256:new"
+		record.BlueprintGeneration = 4
+		record.Resources[0].SourceRevision = "main/def"
+		record.Resources[1].TemplateGeneration = 5
+		record.Resources[1].Stamped.Name = "app-1234"
+		record.Resources = append(record.Resources[:1], record.Resources[1], v1alpha1.RealizedResource{Resource: "tester"})
+
+		records, changed := history.Record([]v1alpha1.RealizationRecord{previous}, record)
+		Expect(changed).To(BeTrue())
+		Expect(records).To(HaveLen(2))
+		Expect(records[1]).To(Equal(previous))
+		Expect(records[0].Generation).To(Equal(int64(3)))
+		Expect(records[0].Changes).To(Equal([]string{
+			"generation 2 -> 3",
+			"params changed",
+			"blueprint 'web' generation 3 -> 4",
+			"resource 'source-provider' source revision 'main/abc' -> 'main/def'",
+			"resource 'deployer' template 'ClusterTemplate/app-deploy' generation 4 -> 5",
+			"resource 'deployer' stamped 'App/ns/app' -> 'App/ns/app-1234'",
+			"resource 'tester' added",
+		}))
+	})
+
+	It("records resources removed and templates replaced", func() {
+		previous := record
+		record.Blueprint = "api"
+		record.Resources = []v1alpha1.RealizedResource{record.Resources[0]}
+		record.Resources[0].Template.Name = "oci"
+
+		records, _ := history.Record([]v1alpha1.RealizationRecord{previous}, record)
+		Expect(records[0].Changes).To(Equal([]string{
+			"blueprint 'web' -> 'api'",
+			"resource 'source-provider' template 'ClusterSourceTemplate/git' -> 'ClusterSourceTemplate/oci'",
+			"resource 'deployer' removed",
+		}))
+	})
+
+	It("keeps the latest MaxRecords realizations", func() {
+		var records []v1alpha1.RealizationRecord
+		for generation := int64(1); generation <= history.MaxRecords+2; generation++ {
+			record.Generation = generation
+			records, _ = history.Record(records, record)
+		}
+
+		Expect(records).To(HaveLen(history.MaxRecords))
+		Expect(records[0].Generation).To(Equal(int64(history.MaxRecords + 2)))
+		Expect(records[history.MaxRecords-1].Generation).To(Equal(int64(3)))
+	})
+})
+
+var _ = Describe("Resource", func() {
+	It("describes the template, the stamped object and the source revision", func() {
+		template := templates.NewClusterSourceTemplateModel(&v1alpha1.ClusterSourceTemplate{
+			TypeMeta:   metav1.TypeMeta{Kind: "ClusterSourceTemplate"},
+			ObjectMeta: metav1.ObjectMeta{Name: "git", Generation: 7},
+		}, eval.EvaluatorBuilder())
+		stamped := &unstructured.Unstructured{}
+		stamped.SetAPIVersion("source.toolkit.fluxcd.io/v1beta1")
+		stamped.SetKind("GitRepository")
+		stamped.SetNamespace("ns")
+		stamped.SetName("app")
+		output := &templates.Output{Source: &templates.Source{URL: "https://example.com", Revision: map[string]interface{}{"branch": "main"}}}
+
+		Expect(history.Resource("source-provider", template, stamped, output)).To(Equal(v1alpha1.RealizedResource{
+			Resource:           "source-provider",
+			Template:           v1alpha1.ObjectReference{Kind: "ClusterSourceTemplate", Name: "git"},
+			TemplateGeneration: 7,
+			SourceRevision:     `{"branch":"main"}`,
+			Stamped:            v1alpha1.ObjectReference{APIVersion: "source.toolkit.fluxcd.io/v1beta1", Kind: "GitRepository", Namespace: "ns", Name: "app"},
+		}))
+	})
+})
+
+var _ = Describe("ParamsDigest", func() {
+	It("is empty without params", func() {
+		Expect(history.ParamsDigest(nil)).To(BeEmpty())
+	})
+
+	It("changes with the params", func() {
+		params := func(value string) []v1alpha1.Param {
+			return []v1alpha1.Param{{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(value)}}}
+		}
+		Expect(history.ParamsDigest(params("1"))).To(HavePrefix("sha256:"))
+		Expect(history.ParamsDigest(params("1"))).To(Equal(history.ParamsDigest(params("1"))))
+		Expect(history.ParamsDigest(params("1"))).NotTo(Equal(history.ParamsDigest(params("2"))))
+	})
+})
+
+var _ = Describe("Manager", func() {
+	entry := func(manager string, seconds int64, fields string) metav1.ManagedFieldsEntry {
+		time := metav1.Unix(seconds, 0)
+		return metav1.ManagedFieldsEntry{
+			Manager:  manager,
+			Time:     &time,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+
+	It("is the manager that last changed the spec", func() {
+		obj := &v1alpha1.Workload{}
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+			entry("kubectl", 100, `{"f:spec":{"f:params":{}}}`),
+			entry("argocd", 200, `{"f:spec":{"f:source":{}}}`),
+			entry("cartographer", 300, `{"f:status":{}}`),
+			entry("kubectl-label", 400, `{"f:metadata":{"f:labels":{}}}`),
+		})
+
+		Expect(history.Manager(obj)).To(Equal("argocd"))
+	})
+
+	It("is empty when no manager changed the spec", func() {
+		Expect(history.Manager(&v1alpha1.Workload{})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,223 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobs runs the Jobs stamped from job lifecycle templates: once for
+// every change to them, reading their results when they complete.
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+// jobNameLabel is set by the job controller on a Job's pods.
+const jobNameLabel = "job-name"
+
+const hashLength = 10
+
+// RunningError is returned for a Job that has not completed yet.
+type RunningError struct {
+	Job *unstructured.Unstructured
+}
+
+func (e RunningError) Error() string {
+	return fmt.Sprintf("job '%s/%s' has not completed", e.Job.GetNamespace(), e.Job.GetName())
+}
+
+// FailedError is returned for a Job that has failed.
+type FailedError struct {
+	Job     *unstructured.Unstructured
+	Message string
+}
+
+func (e FailedError) Error() string {
+	return fmt.Sprintf("job '%s/%s' failed: %s", e.Job.GetNamespace(), e.Job.GetName(), e.Message)
+}
+
+// Identify names job after the hash of its spec, so that a Job is run again
+// only when its spec, and so the inputs it was stamped with, changes. The
+// name the template gives the Job, or its generateName, is kept as a
+// prefix.
+func Identify(job *unstructured.Unstructured) error {
+	spec, err := json.Marshal(job.Object["spec"])
+	if err != nil {
+		return fmt.Errorf("marshal job spec: %w", err)
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(spec))[:hashLength]
+
+	prefix := job.GetName()
+	if prefix == "" {
+		prefix = strings.TrimSuffix(job.GetGenerateName(), "-")
+	}
+	if prefix == "" {
+		prefix = "job"
+	}
+	// Job names are copied into their pods' labels, so must be valid label
+	// values.
+	if maxPrefix := validation.LabelValueMaxLength - hashLength - 1; len(prefix) > maxPrefix {
+		prefix = strings.TrimSuffix(prefix[:maxPrefix], "-")
+	}
+
+	job.SetGenerateName("")
+	job.SetName(prefix + "-" + hash)
+	return nil
+}
+
+// Results returns the results of job, read as results says, once the job has
+// completed. It returns a RunningError while the job runs and a FailedError
+// when it has failed.
+func Results(ctx context.Context, repo repository.Repository, job *unstructured.Unstructured, results *v1alpha1.JobResults) (map[string]interface{}, error) {
+	if err := Finished(job); err != nil {
+		return nil, err
+	}
+
+	if results == nil {
+		results = &v1alpha1.JobResults{}
+	}
+	switch results.From {
+	case v1alpha1.ConfigMapJobResults:
+		return configMapResults(ctx, repo, job)
+	default:
+		return terminationMessageResults(ctx, repo, job, results.Container)
+	}
+}
+
+// Finished returns nil once job has completed, a RunningError while it runs
+// and a FailedError when it has failed.
+func Finished(job *unstructured.Unstructured) error {
+	complete, failed, message := status(job)
+	if failed {
+		return FailedError{Job: job, Message: message}
+	}
+	if !complete {
+		return RunningError{Job: job}
+	}
+	return nil
+}
+
+// Prune deletes the Jobs run for earlier versions of job: those stamped for
+// the same resource, carrying the same labels, under another name.
+func Prune(ctx context.Context, repo repository.Repository, job *unstructured.Unstructured) error {
+	runs, err := repo.ListUnstructured(ctx, job)
+	if err != nil {
+		return fmt.Errorf("list earlier runs: %w", err)
+	}
+	for _, run := range runs {
+		if run.GetName() == job.GetName() {
+			continue
+		}
+		if err := repo.DeleteUnstructured(ctx, run); err != nil {
+			return fmt.Errorf("delete earlier run '%s': %w", run.GetName(), err)
+		}
+	}
+	return nil
+}
+
+func status(job *unstructured.Unstructured) (complete, failed bool, message string) {
+	conditions, _, _ := unstructured.NestedSlice(job.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["status"] != "True" {
+			continue
+		}
+		switch condition["type"] {
+		case "Complete":
+			complete = true
+		case "Failed":
+			failed = true
+			message, _ = condition["message"].(string)
+		}
+	}
+	return complete, failed, message
+}
+
+func configMapResults(ctx context.Context, repo repository.Repository, job *unstructured.Unstructured) (map[string]interface{}, error) {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace(job.GetNamespace())
+	configMap.SetName(job.GetName())
+
+	if err := repo.GetUnstructured(ctx, configMap); err != nil {
+		return nil, fmt.Errorf("get results config map: %w", err)
+	}
+
+	data, _, _ := unstructured.NestedMap(configMap.Object, "data")
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return data, nil
+}
+
+func terminationMessageResults(ctx context.Context, repo repository.Repository, job *unstructured.Unstructured, container string) (map[string]interface{}, error) {
+	query := &unstructured.Unstructured{}
+	query.SetAPIVersion("v1")
+	query.SetKind("Pod")
+	query.SetNamespace(job.GetNamespace())
+	query.SetLabels(map[string]string{jobNameLabel: job.GetName()})
+
+	pods, err := repo.ListUnstructured(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list job pods: %w", err)
+	}
+
+	for _, pod := range pods {
+		if phase, _, _ := unstructured.NestedString(pod.Object, "status", "phase"); phase != "Succeeded" {
+			continue
+		}
+
+		message, err := terminationMessage(pod, container)
+		if err != nil {
+			return nil, err
+		}
+		results := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(message), &results); err != nil {
+			return nil, fmt.Errorf("termination message of pod '%s' is not a JSON object: %w", pod.GetName(), err)
+		}
+		return results, nil
+	}
+
+	return nil, fmt.Errorf("no succeeded pod found for job '%s/%s'", job.GetNamespace(), job.GetName())
+}
+
+func terminationMessage(pod *unstructured.Unstructured, container string) (string, error) {
+	if container == "" {
+		containers, _, _ := unstructured.NestedSlice(pod.Object, "spec", "containers")
+		if len(containers) > 0 {
+			if first, ok := containers[0].(map[string]interface{}); ok {
+				container, _ = first["name"].(string)
+			}
+		}
+	}
+
+	statuses, _, _ := unstructured.NestedSlice(pod.Object, "status", "containerStatuses")
+	for _, s := range statuses {
+		containerStatus, ok := s.(map[string]interface{})
+		if !ok || containerStatus["name"] != container {
+			continue
+		}
+		message, _, _ := unstructured.NestedString(containerStatus, "state", "terminated", "message")
+		return message, nil
+	}
+	return "", fmt.Errorf("container '%s' not found in pod '%s'", container, pod.GetName())
+}
@@ -0,0 +1,223 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/jobs"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+var _ = Describe("Jobs", func() {
+	var job *unstructured.Unstructured
+
+	BeforeEach(func() {
+		job = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"namespace": "ns",
+				"name":      "scan",
+				"labels": map[string]interface{}{
+					"carto.run/resource-name": "scanner",
+				},
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "scan", "image": "scanner:v1"},
+						},
+					},
+				},
+			},
+		}}
+	})
+
+	Describe("Identify", func() {
+		It("suffixes the name with a hash of the spec", func() {
+			Expect(jobs.Identify(job)).To(Succeed())
+			Expect(job.GetName()).To(MatchRegexp(`^scan-[0-9a-f]{10}$`))
+		})
+
+		It("names jobs with the same spec alike", func() {
+			other := job.DeepCopy()
+			Expect(jobs.Identify(job)).To(Succeed())
+			Expect(jobs.Identify(other)).To(Succeed())
+			Expect(other.GetName()).To(Equal(job.GetName()))
+		})
+
+		It("names jobs with another spec differently", func() {
+			other := job.DeepCopy()
+			Expect(unstructured.SetNestedSlice(other.Object, []interface{}{
+				map[string]interface{}{"name": "scan", "image": "scanner:v2"},
+			}, "spec", "template", "spec", "containers")).To(Succeed())
+			Expect(jobs.Identify(job)).To(Succeed())
+			Expect(jobs.Identify(other)).To(Succeed())
+			Expect(other.GetName()).NotTo(Equal(job.GetName()))
+		})
+
+		It("uses the generateName as the prefix when there is no name", func() {
+			job.SetName("")
+			job.SetGenerateName("scan-run-")
+			Expect(jobs.Identify(job)).To(Succeed())
+			Expect(job.GetName()).To(MatchRegexp(`^scan-run-[0-9a-f]{10}$`))
+			Expect(job.GetGenerateName()).To(BeEmpty())
+		})
+
+		It("falls back to a default prefix", func() {
+			job.SetName("")
+			Expect(jobs.Identify(job)).To(Succeed())
+			Expect(job.GetName()).To(MatchRegexp(`^job-[0-9a-f]{10}$`))
+		})
+
+		It("keeps long names within the label value limit", func() {
+			job.SetName(strings.Repeat("a", 80))
+			Expect(jobs.Identify(job)).To(Succeed())
+			Expect(len(job.GetName())).To(Equal(63))
+		})
+	})
+
+	Describe("Results", func() {
+		var repo *repositoryfakes.FakeRepository
+
+		BeforeEach(func() {
+			repo = &repositoryfakes.FakeRepository{}
+		})
+
+		setCondition := func(conditionType, message string) {
+			Expect(unstructured.SetNestedSlice(job.Object, []interface{}{
+				map[string]interface{}{"type": conditionType, "status": "True", "message": message},
+			}, "status", "conditions")).To(Succeed())
+		}
+
+		succeededPod := func(message string) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]interface{}{"name": "scan-pod"},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "scan"},
+						map[string]interface{}{"name": "report"},
+					},
+				},
+				"status": map[string]interface{}{
+					"phase": "Succeeded",
+					"containerStatuses": []interface{}{
+						map[string]interface{}{
+							"name":  "report",
+							"state": map[string]interface{}{"terminated": map[string]interface{}{"message": `{"report": "r"}`}},
+						},
+						map[string]interface{}{
+							"name":  "scan",
+							"state": map[string]interface{}{"terminated": map[string]interface{}{"message": message}},
+						},
+					},
+				},
+			}}
+		}
+
+		It("returns a RunningError while the job has not completed", func() {
+			_, err := jobs.Results(context.Background(), repo, job, nil)
+			Expect(errors.As(err, &jobs.RunningError{})).To(BeTrue())
+		})
+
+		It("returns a FailedError with the failure message", func() {
+			setCondition("Failed", "BackoffLimitExceeded")
+			_, err := jobs.Results(context.Background(), repo, job, nil)
+			Expect(errors.As(err, &jobs.FailedError{})).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("BackoffLimitExceeded")))
+		})
+
+		Context("when the job has completed", func() {
+			BeforeEach(func() {
+				setCondition("Complete", "")
+			})
+
+			It("reads the termination message of the first container by default", func() {
+				repo.ListUnstructuredReturns([]*unstructured.Unstructured{succeededPod(`{"digest": "sha256:abc"}`)}, nil)
+
+				results, err := jobs.Results(context.Background(), repo, job, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(Equal(map[string]interface{}{"digest": "sha256:abc"}))
+
+				_, query := repo.ListUnstructuredArgsForCall(0)
+				Expect(query.GetKind()).To(Equal("Pod"))
+				Expect(query.GetNamespace()).To(Equal("ns"))
+				Expect(query.GetLabels()).To(Equal(map[string]string{"job-name": "scan"}))
+			})
+
+			It("reads the termination message of the named container", func() {
+				repo.ListUnstructuredReturns([]*unstructured.Unstructured{succeededPod(`{}`)}, nil)
+
+				results, err := jobs.Results(context.Background(), repo, job, &v1alpha1.JobResults{Container: "report"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(Equal(map[string]interface{}{"report": "r"}))
+			})
+
+			It("rejects termination messages that are not JSON objects", func() {
+				repo.ListUnstructuredReturns([]*unstructured.Unstructured{succeededPod(`done`)}, nil)
+
+				_, err := jobs.Results(context.Background(), repo, job, nil)
+				Expect(err).To(MatchError(ContainSubstring("is not a JSON object")))
+			})
+
+			It("errors when no pod succeeded", func() {
+				_, err := jobs.Results(context.Background(), repo, job, nil)
+				Expect(err).To(MatchError(ContainSubstring("no succeeded pod found")))
+			})
+
+			It("reads the data of the config map named after the job", func() {
+				repo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					obj.Object["data"] = map[string]interface{}{"url": "https://example.com"}
+					return nil
+				}
+
+				results, err := jobs.Results(context.Background(), repo, job, &v1alpha1.JobResults{From: v1alpha1.ConfigMapJobResults})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(Equal(map[string]interface{}{"url": "https://example.com"}))
+
+				_, configMap := repo.GetUnstructuredArgsForCall(0)
+				Expect(configMap.GetKind()).To(Equal("ConfigMap"))
+				Expect(configMap.GetNamespace()).To(Equal("ns"))
+				Expect(configMap.GetName()).To(Equal("scan"))
+			})
+		})
+	})
+
+	Describe("Prune", func() {
+		It("deletes the runs with other names", func() {
+			repo := &repositoryfakes.FakeRepository{}
+			earlier := job.DeepCopy()
+			earlier.SetName("scan-earlier")
+			repo.ListUnstructuredReturns([]*unstructured.Unstructured{job.DeepCopy(), earlier}, nil)
+
+			Expect(jobs.Prune(context.Background(), repo, job)).To(Succeed())
+
+			Expect(repo.DeleteUnstructuredCallCount()).To(Equal(1))
+			_, deleted := repo.DeleteUnstructuredArgsForCall(0)
+			Expect(deleted.GetName()).To(Equal("scan-earlier"))
+		})
+	})
+})
@@ -0,0 +1,170 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lease
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Locker hands out per-object leases, stored as coordination.k8s.io Leases
+// next to the objects they guard, so that when several controller replicas
+// run, at most one of them realizes a given object at a time. A lease that
+// is not renewed expires, letting another replica take over after a
+// failover.
+type Locker struct {
+	client   client.Client
+	reader   client.Reader
+	identity string
+	duration time.Duration
+}
+
+// NewLocker returns a Locker that holds leases as identity for duration.
+// Leases are read through reader, so that they need not be cached, and
+// written through c.
+func NewLocker(c client.Client, reader client.Reader, identity string, duration time.Duration) *Locker {
+	return &Locker{
+		client:   c,
+		reader:   reader,
+		identity: identity,
+		duration: duration,
+	}
+}
+
+// Name returns the name of the lease guarding the object called name, of
+// the kind described by prefix.
+func Name(prefix, name string) string {
+	leaseName := fmt.Sprintf("%s-%s", prefix, name)
+	if len(leaseName) <= validation.DNS1123SubdomainMaxLength {
+		return leaseName
+	}
+	return fmt.Sprintf("%s-%x", prefix, sha256.Sum256([]byte(name)))
+}
+
+// TryAcquire takes, or renews, the lease on obj for this replica. When
+// another replica holds an unexpired lease it returns false along with how
+// long to wait before trying again. The lease is owned by obj, so it is
+// garbage collected with it.
+func (l *Locker) TryAcquire(ctx context.Context, prefix string, obj client.Object) (bool, time.Duration, error) {
+	now := metav1.NewMicroTime(time.Now())
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: Name(prefix, obj.GetName())}
+
+	lease := &coordinationv1.Lease{}
+	err := l.reader.Get(ctx, key, lease)
+	if kerrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+			},
+		}
+		if err := controllerutil.SetOwnerReference(obj, lease, l.client.Scheme()); err != nil {
+			return false, 0, fmt.Errorf("set lease owner: %w", err)
+		}
+		l.hold(lease, now)
+		if err := l.client.Create(ctx, lease); err != nil {
+			if kerrors.IsAlreadyExists(err) {
+				return false, l.duration, nil
+			}
+			return false, 0, fmt.Errorf("create lease: %w", err)
+		}
+		return true, 0, nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("get lease: %w", err)
+	}
+
+	if holder := lease.Spec.HolderIdentity; holder != nil && *holder != l.identity {
+		if remaining := expiresIn(lease, now.Time); remaining > 0 {
+			return false, remaining, nil
+		}
+	}
+
+	l.hold(lease, now)
+	if err := l.client.Update(ctx, lease); err != nil {
+		if kerrors.IsConflict(err) {
+			return false, l.duration, nil
+		}
+		return false, 0, fmt.Errorf("update lease: %w", err)
+	}
+	return true, 0, nil
+}
+
+// Wrap decorates a reconciler so that it only runs for objects whose lease
+// this replica holds. Requests for objects leased by another replica are
+// requeued for when that lease expires. newObject returns an empty object of
+// the kind r reconciles. A nil Locker returns r unchanged.
+func (l *Locker) Wrap(prefix string, newObject func() client.Object, r reconcile.Reconciler) reconcile.Reconciler {
+	if l == nil {
+		return r
+	}
+
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		obj := newObject()
+		if err := l.client.Get(ctx, req.NamespacedName, obj); err != nil {
+			if kerrors.IsNotFound(err) {
+				return r.Reconcile(ctx, req)
+			}
+			return reconcile.Result{}, fmt.Errorf("get object to lease: %w", err)
+		}
+
+		held, retryAfter, err := l.TryAcquire(ctx, prefix, obj)
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("acquire lease: %w", err)
+		}
+		if !held {
+			logr.FromContextOrDiscard(ctx).Info("leased by another replica, not reconciling", "request", req.NamespacedName, "retryAfter", retryAfter)
+			return reconcile.Result{RequeueAfter: retryAfter}, nil
+		}
+
+		return r.Reconcile(ctx, req)
+	})
+}
+
+func (l *Locker) hold(lease *coordinationv1.Lease, now metav1.MicroTime) {
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.identity {
+		identity := l.identity
+		transitions := int32(0)
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions + 1
+		}
+		lease.Spec.HolderIdentity = &identity
+		lease.Spec.AcquireTime = &now
+		lease.Spec.LeaseTransitions = &transitions
+	}
+	seconds := int32(l.duration.Seconds())
+	lease.Spec.LeaseDurationSeconds = &seconds
+	lease.Spec.RenewTime = &now
+}
+
+func expiresIn(lease *coordinationv1.Lease, now time.Time) time.Duration {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return 0
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return expiry.Sub(now)
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lease_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/lease"
+)
+
+var _ = Describe("Locker", func() {
+	var (
+		ctx      context.Context
+		c        client.Client
+		workload *v1alpha1.Workload
+		leaseKey types.NamespacedName
+		replicaA *lease.Locker
+		replicaB *lease.Locker
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(coordinationv1.AddToScheme(scheme)).To(Succeed())
+
+		workload = &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-workload",
+				Namespace: "my-namespace",
+				UID:       "workload-uid",
+			},
+		}
+		leaseKey = types.NamespacedName{Namespace: "my-namespace", Name: "cartographer-workload-my-workload"}
+
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(workload).Build()
+		replicaA = lease.NewLocker(c, c, "replica-a", time.Minute)
+		replicaB = lease.NewLocker(c, c, "replica-b", time.Minute)
+	})
+
+	Describe("TryAcquire", func() {
+		It("creates a lease owned by the object", func() {
+			held, _, err := replicaA.TryAcquire(ctx, "cartographer-workload", workload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(held).To(BeTrue())
+
+			l := &coordinationv1.Lease{}
+			Expect(c.Get(ctx, leaseKey, l)).To(Succeed())
+			Expect(*l.Spec.HolderIdentity).To(Equal("replica-a"))
+			Expect(*l.Spec.LeaseDurationSeconds).To(Equal(int32(60)))
+			Expect(l.OwnerReferences).To(HaveLen(1))
+			Expect(l.OwnerReferences[0].UID).To(Equal(types.UID("workload-uid")))
+		})
+
+		It("renews a lease the replica already holds", func() {
+			held, _, err := replicaA.TryAcquire(ctx, "cartographer-workload", workload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(held).To(BeTrue())
+
+			held, _, err = replicaA.TryAcquire(ctx, "cartographer-workload", workload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(held).To(BeTrue())
+
+			l := &coordinationv1.Lease{}
+			Expect(c.Get(ctx, leaseKey, l)).To(Succeed())
+			Expect(*l.Spec.LeaseTransitions).To(Equal(int32(0)))
+		})
+
+		It("refuses a lease another replica holds, until it expires", func() {
+			held, _, err := replicaA.TryAcquire(ctx, "cartographer-workload", workload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(held).To(BeTrue())
+
+			held, retryAfter, err := replicaB.TryAcquire(ctx, "cartographer-workload", workload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(held).To(BeFalse())
+			Expect(retryAfter).To(BeNumerically("~", time.Minute, 5*time.Second))
+		})
+
+		It("takes over a lease that has expired", func() {
+			held, _, err := replicaA.TryAcquire(ctx, "cartographer-workload", workload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(held).To(BeTrue())
+
+			l := &coordinationv1.Lease{}
+			Expect(c.Get(ctx, leaseKey, l)).To(Succeed())
+			expired := metav1.NewMicroTime(time.Now().Add(-2 * time.Minute))
+			l.Spec.RenewTime = &expired
+			Expect(c.Update(ctx, l)).To(Succeed())
+
+			held, _, err = replicaB.TryAcquire(ctx, "cartographer-workload", workload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(held).To(BeTrue())
+
+			Expect(c.Get(ctx, leaseKey, l)).To(Succeed())
+			Expect(*l.Spec.HolderIdentity).To(Equal("replica-b"))
+			Expect(*l.Spec.LeaseTransitions).To(Equal(int32(1)))
+		})
+	})
+
+	Describe("Name", func() {
+		It("prefixes the object's name", func() {
+			Expect(lease.Name("cartographer-workload", "app")).To(Equal("cartographer-workload-app"))
+		})
+
+		It("hashes names that would be too long", func() {
+			name := lease.Name("cartographer-workload", strings.Repeat("a", 253))
+			Expect(len(name)).To(BeNumerically("<=", 253))
+			Expect(name).To(HavePrefix("cartographer-workload-"))
+		})
+	})
+
+	Describe("Wrap", func() {
+		var (
+			calls      int
+			reconciler reconcile.Reconciler
+			request    reconcile.Request
+		)
+
+		BeforeEach(func() {
+			calls = 0
+			reconciler = reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				calls++
+				return reconcile.Result{}, nil
+			})
+			request = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "my-namespace", Name: "my-workload"}}
+		})
+
+		newWorkload := func() client.Object { return &v1alpha1.Workload{} }
+
+		It("reconciles objects whose lease the replica acquires", func() {
+			_, err := replicaA.Wrap("cartographer-workload", newWorkload, reconciler).Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("requeues objects leased by another replica without reconciling them", func() {
+			_, err := replicaA.Wrap("cartographer-workload", newWorkload, reconciler).Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := replicaB.Wrap("cartographer-workload", newWorkload, reconciler).Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("reconciles objects that no longer exist", func() {
+			request.Name = "gone"
+			_, err := replicaA.Wrap("cartographer-workload", newWorkload, reconciler).Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("returns the reconciler unchanged for a nil locker", func() {
+			var locker *lease.Locker
+			_, err := locker.Wrap("cartographer-workload", newWorkload, reconciler).Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,294 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lint checks blueprints and templates offline, before they are
+// applied, for mistakes the admission webhooks cannot see on their own:
+// references between blueprints and templates, and how resources feed
+// each other.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/render"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// Severity of a finding. Errors stop blueprints from working; warnings point
+// at parts of them that have no effect.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Finding is a problem with a blueprint, one of its resources, or a
+// template.
+type Finding struct {
+	Severity Severity
+	// Object is the blueprint or template, as Kind/name.
+	Object string
+	// Resource of the blueprint, when the finding is about one.
+	Resource string
+	Message  string
+}
+
+func (f Finding) String() string {
+	if f.Resource == "" {
+		return fmt.Sprintf("%s: %s: %s", f.Severity, f.Object, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: resource '%s': %s", f.Severity, f.Object, f.Resource, f.Message)
+}
+
+// outputKinds are the template kinds whose outputs resources consume, by
+// the kind of input.
+var outputKinds = map[string]string{
+	"sources": "ClusterSourceTemplate",
+	"images":  "ClusterImageTemplate",
+	"configs": "ClusterConfigTemplate",
+}
+
+// sinkKinds are the template kinds that stamp the objects a blueprint is
+// for, rather than outputs for other resources.
+var sinkKinds = map[string]bool{
+	"ClusterTemplate":           true,
+	"ClusterDeploymentTemplate": true,
+}
+
+type input struct {
+	kind      string
+	reference v1alpha1.ResourceReference
+}
+
+type resource struct {
+	name         string
+	templateKind string
+	// templateNames are the templates the resource may use: one, or one
+	// for each of its templateRef's options.
+	templateNames []string
+	// fromGit is set for templates fetched from git, which are not checked.
+	fromGit bool
+	params  []v1alpha1.Param
+	inputs  []input
+}
+
+// Lint checks the blueprints and templates in m. Each blueprint's
+// resources must have unique names, reference templates in m, and consume
+// outputs of the right kind from resources realized before them. Params
+// must be declared by the templates they are passed to or read by.
+// Resources whose outputs never reach a ClusterTemplate or
+// ClusterDeploymentTemplate are reported as warnings.
+func Lint(m *render.Manifests) []Finding {
+	models := map[string]templates.Template{}
+	var findings []Finding
+	for _, apiTemplate := range m.Templates {
+		template, err := templates.NewModelFromAPI(apiTemplate)
+		if err != nil {
+			object := apiTemplate.GetObjectKind().GroupVersionKind().Kind + "/" + apiTemplate.GetName()
+			findings = append(findings, Finding{Severity: Error, Object: object, Message: err.Error()})
+			continue
+		}
+		for _, key := range render.TemplateKeys(template.GetKind(), apiTemplate) {
+			if other, ok := models[key]; ok && other.GetName() != template.GetName() {
+				object := template.GetKind() + "/" + template.GetName()
+				findings = append(findings, Finding{Severity: Error, Object: object, Message: fmt.Sprintf("labelled as the same version as '%s'", other.GetName())})
+			}
+			models[key] = template
+		}
+	}
+
+	for _, supplyChain := range m.SupplyChains {
+		var resources []resource
+		for _, r := range supplyChain.GetSpec().Resources {
+			var templateNames []string
+			for _, templateRef := range r.TemplateRef.Choices() {
+				templateNames = append(templateNames, templateRef.DisplayName())
+			}
+			resources = append(resources, resource{
+				name:          r.Name,
+				templateKind:  r.TemplateRef.Kind,
+				templateNames: templateNames,
+				fromGit:       r.TemplateRef.Git != nil,
+				params:        r.Params,
+				inputs:        inputs(map[string][]v1alpha1.ResourceReference{"sources": r.Sources, "images": r.Images, "configs": r.Configs}),
+			})
+		}
+		object := supplyChain.GetObjectKind().GroupVersionKind().Kind + "/" + supplyChain.GetName()
+		findings = append(findings, lintBlueprint(object, resources, models)...)
+	}
+
+	for _, delivery := range m.Deliveries {
+		var resources []resource
+		for _, r := range delivery.GetSpec().Resources {
+			resources = append(resources, resource{
+				name:          r.Name,
+				templateKind:  r.TemplateRef.Kind,
+				templateNames: []string{r.TemplateRef.DisplayName()},
+				fromGit:       r.TemplateRef.Git != nil,
+				params:        r.Params,
+				inputs:        inputs(map[string][]v1alpha1.ResourceReference{"sources": r.Sources, "configs": r.Configs}),
+			})
+		}
+		object := delivery.GetObjectKind().GroupVersionKind().Kind + "/" + delivery.GetName()
+		findings = append(findings, lintBlueprint(object, resources, models)...)
+	}
+
+	keys := make([]string, 0, len(models))
+	for key := range models {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		findings = append(findings, lintTemplate(key, models[key])...)
+	}
+
+	return findings
+}
+
+func inputs(references map[string][]v1alpha1.ResourceReference) []input {
+	var all []input
+	for _, kind := range []string{"sources", "images", "configs"} {
+		for _, reference := range references[kind] {
+			all = append(all, input{kind: kind, reference: reference})
+		}
+	}
+	return all
+}
+
+func lintBlueprint(object string, resources []resource, models map[string]templates.Template) []Finding {
+	var findings []Finding
+	report := func(severity Severity, resource, format string, args ...interface{}) {
+		findings = append(findings, Finding{Severity: severity, Object: object, Resource: resource, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// position of each resource, in the order resources are realized.
+	position := map[string]int{}
+	for i, r := range resources {
+		if _, ok := position[r.name]; ok {
+			report(Error, r.name, "resource name is not unique")
+			continue
+		}
+		position[r.name] = i
+	}
+
+	// consumers of each resource's outputs.
+	consumers := map[string][]string{}
+	for i, r := range resources {
+		for _, templateName := range r.templateNames {
+			template, ok := models[r.templateKind+"/"+templateName]
+			switch {
+			case r.fromGit:
+				// templates fetched from git are not in m, so their params
+				// are not checked.
+			case !ok:
+				report(Error, r.name, "template %s '%s' not found", r.templateKind, templateName)
+			default:
+				declared := declaredParams(template)
+				for _, param := range r.params {
+					if !declared[param.Name] {
+						report(Warning, r.name, "param '%s' is not declared by %s '%s', so it is ignored", param.Name, r.templateKind, templateName)
+					}
+				}
+			}
+		}
+
+		for _, in := range r.inputs {
+			producer, ok := position[in.reference.Resource]
+			if !ok {
+				report(Error, r.name, "%s '%s' is provided by unknown resource '%s'", in.kind, in.reference.Name, in.reference.Resource)
+				continue
+			}
+			consumers[in.reference.Resource] = append(consumers[in.reference.Resource], r.name)
+			if producer >= i {
+				report(Error, r.name, "%s '%s' is provided by resource '%s', which is not realized before it", in.kind, in.reference.Name, in.reference.Resource)
+			}
+			if kind := resources[producer].templateKind; kind != outputKinds[in.kind] {
+				report(Error, r.name, "%s '%s' must be provided by a %s, resource '%s' references a %s", in.kind, in.reference.Name, outputKinds[in.kind], in.reference.Resource, kind)
+			}
+		}
+	}
+
+	// A resource is reachable when it stamps what the blueprint is for, or
+	// feeds a reachable resource.
+	reachable := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range resources {
+			if reachable[r.name] {
+				continue
+			}
+			if sinkKinds[r.templateKind] || anyReachable(consumers[r.name], reachable) {
+				reachable[r.name] = true
+				changed = true
+			}
+		}
+	}
+	for _, r := range resources {
+		if !reachable[r.name] {
+			report(Warning, r.name, "outputs do not reach a ClusterTemplate or ClusterDeploymentTemplate")
+		}
+	}
+
+	return findings
+}
+
+func anyReachable(names []string, reachable map[string]bool) bool {
+	for _, name := range names {
+		if reachable[name] {
+			return true
+		}
+	}
+	return false
+}
+
+var paramReference = regexp.MustCompile(`\$\(\s*params\.([A-Za-z0-9_-]+)`)
+
+// lintTemplate reports params a template, or its patches, reads without
+// declaring them. ytt templates are not checked.
+func lintTemplate(object string, template templates.Template) []Finding {
+	var raw []byte
+	if patcher, ok := template.(templates.ConfigPatcher); ok && patcher.GetPatches() != nil {
+		raw, _ = json.Marshal(patcher.GetPatches())
+	} else if spec := template.GetResourceTemplate(); spec.Template != nil {
+		raw = spec.Template.Raw
+	} else {
+		return nil
+	}
+
+	declared := declaredParams(template)
+	reported := map[string]bool{}
+	var findings []Finding
+	for _, match := range paramReference.FindAllSubmatch(raw, -1) {
+		name := string(match[1])
+		if declared[name] || reported[name] {
+			continue
+		}
+		reported[name] = true
+		findings = append(findings, Finding{Severity: Error, Object: object, Message: fmt.Sprintf("param '%s' is read but not declared", name)})
+	}
+	return findings
+}
+
+func declaredParams(template templates.Template) map[string]bool {
+	declared := map[string]bool{}
+	for _, param := range template.GetDefaultParams() {
+		declared[param.Name] = true
+	}
+	return declared
+}
@@ -0,0 +1,317 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/lint"
+	"github.com/vmware-tanzu/cartographer/pkg/render"
+)
+
+const templatesYAML = `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSourceTemplate
+metadata:
+  name: git-source
+spec:
+  urlPath: .status.artifact.url
+  revisionPath: .status.artifact.revision
+  params:
+    - name: interval
+      default: 1m
+  template:
+    apiVersion: source.toolkit.fluxcd.io/v1beta1
+    kind: GitRepository
+    metadata:
+      name: $(workload.metadata.name)$
+    spec:
+      interval: $(params.interval)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterImageTemplate
+metadata:
+  name: kpack
+spec:
+  imagePath: .status.latestImage
+  template:
+    apiVersion: kpack.io/v1alpha2
+    kind: Image
+    metadata:
+      name: $(workload.metadata.name)$
+    spec:
+      source: $(source.url)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: deploy
+spec:
+  template:
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: $(workload.metadata.name)$
+    spec:
+      image: $(images.image.image)$
+`
+
+var _ = Describe("Lint", func() {
+	lintYAML := func(docs ...string) []string {
+		manifests := &render.Manifests{}
+		for _, doc := range docs {
+			Expect(manifests.Load(strings.NewReader(doc))).To(Succeed())
+		}
+
+		var findings []string
+		for _, finding := range lint.Lint(manifests) {
+			findings = append(findings, finding.String())
+		}
+		return findings
+	}
+
+	It("finds nothing wrong with a well formed supply chain", func() {
+		Expect(lintYAML(templatesYAML, `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: supply-chain
+spec:
+  selector:
+    app: web
+  resources:
+    - name: source-provider
+      templateRef:
+        kind: ClusterSourceTemplate
+        name: git-source
+      params:
+        - name: interval
+          value: 5m
+    - name: image-builder
+      templateRef:
+        kind: ClusterImageTemplate
+        name: kpack
+      sources:
+        - name: source
+          resource: source-provider
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: deploy
+      images:
+        - name: image
+          resource: image-builder
+`)).To(BeEmpty())
+	})
+
+	It("reports duplicate names, missing templates and undeclared params", func() {
+		Expect(lintYAML(templatesYAML, `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: supply-chain
+spec:
+  selector:
+    app: web
+  resources:
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: deploy
+      params:
+        - name: replicas
+          value: 2
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: missing
+`)).To(ConsistOf(
+			"error: ClusterSupplyChain/supply-chain: resource 'deployer': resource name is not unique",
+			"warning: ClusterSupplyChain/supply-chain: resource 'deployer': param 'replicas' is not declared by ClusterTemplate 'deploy', so it is ignored",
+			"error: ClusterSupplyChain/supply-chain: resource 'deployer': template ClusterTemplate 'missing' not found",
+		))
+	})
+
+	It("checks the template of every option", func() {
+		Expect(lintYAML(templatesYAML, `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: supply-chain
+spec:
+  selector:
+    app: web
+  resources:
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        options:
+          - name: deploy
+            selector:
+              matchLabels:
+                tier: web
+          - name: missing
+            selector:
+              matchLabels:
+                tier: worker
+      params:
+        - name: replicas
+          value: 2
+`)).To(ConsistOf(
+			"warning: ClusterSupplyChain/supply-chain: resource 'deployer': param 'replicas' is not declared by ClusterTemplate 'deploy', so it is ignored",
+			"error: ClusterSupplyChain/supply-chain: resource 'deployer': template ClusterTemplate 'missing' not found",
+		))
+	})
+
+	It("reports inputs of the wrong kind, from unknown or later resources", func() {
+		Expect(lintYAML(templatesYAML, `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: supply-chain
+spec:
+  selector:
+    app: web
+  resources:
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: deploy
+      images:
+        - name: image
+          resource: image-builder
+        - name: other
+          resource: missing
+    - name: image-builder
+      templateRef:
+        kind: ClusterImageTemplate
+        name: kpack
+      sources:
+        - name: source
+          resource: deployer
+`)).To(ConsistOf(
+			"error: ClusterSupplyChain/supply-chain: resource 'deployer': images 'image' is provided by resource 'image-builder', which is not realized before it",
+			"error: ClusterSupplyChain/supply-chain: resource 'deployer': images 'other' is provided by unknown resource 'missing'",
+			"error: ClusterSupplyChain/supply-chain: resource 'image-builder': sources 'source' must be provided by a ClusterSourceTemplate, resource 'deployer' references a ClusterTemplate",
+		))
+	})
+
+	It("warns about resources whose outputs are not used", func() {
+		Expect(lintYAML(templatesYAML, `
+apiVersion: carto.run/v1alpha1
+kind: ClusterDelivery
+metadata:
+  name: delivery
+spec:
+  selector:
+    app: web
+  resources:
+    - name: source-provider
+      templateRef:
+        kind: ClusterSourceTemplate
+        name: git-source
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: deploy
+`)).To(ConsistOf(
+			"warning: ClusterDelivery/delivery: resource 'source-provider': outputs do not reach a ClusterTemplate or ClusterDeploymentTemplate",
+		))
+	})
+
+	It("reports params templates read without declaring them", func() {
+		Expect(lintYAML(`
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: deploy
+spec:
+  params:
+    - name: replicas
+      default: 1
+  template:
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: $(params.name)$
+    spec:
+      replicas: $(params.replicas)$
+      serviceAccountName: $(params.name)$
+`)).To(ConsistOf(
+			"error: ClusterTemplate/deploy: param 'name' is read but not declared",
+		))
+	})
+
+	It("finds pinned versions of templates by their labels", func() {
+		Expect(lintYAML(`
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: deploy-v2
+  labels:
+    carto.run/template-name: deploy
+    carto.run/template-version: v2
+spec:
+  template:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: $(workload.metadata.name)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: deploy-v2-copy
+  labels:
+    carto.run/template-name: deploy
+    carto.run/template-version: v2
+spec:
+  template:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: $(workload.metadata.name)$
+`, `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: supply-chain
+spec:
+  selector:
+    app: web
+  resources:
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: deploy
+        version: v2
+      params:
+        - name: replicas
+          value: 2
+    - name: next-deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: deploy
+        version: v3
+`)).To(ConsistOf(
+			"error: ClusterTemplate/deploy-v2-copy: labelled as the same version as 'deploy-v2'",
+			"warning: ClusterSupplyChain/supply-chain: resource 'deployer': param 'replicas' is not declared by ClusterTemplate 'deploy@v2', so it is ignored",
+			"error: ClusterSupplyChain/supply-chain: resource 'next-deployer': template ClusterTemplate 'deploy@v3' not found",
+		))
+	})
+})
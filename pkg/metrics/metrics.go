@@ -0,0 +1,66 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics holds the Prometheus metrics Cartographer exposes, on the
+// controller manager's metrics endpoint, about the realization and readiness
+// of workloads and deliverables.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+var (
+	// ResourceRetries counts the attempts to realize a resource that failed
+	// or had to wait for outputs.
+	ResourceRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cartographer_resource_retries_total",
+		Help: "Attempts to realize a resource of a supply chain or delivery that failed or had to wait for outputs.",
+	}, []string{"kind", "blueprint", "resource"})
+
+	// RealizationRetries observes the retries a workload or deliverable
+	// consumed, over all its resources, before becoming ready.
+	RealizationRetries = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cartographer_realization_retries",
+		Help:    "Retries a workload or deliverable consumed before becoming ready.",
+		Buckets: []float64{0, 1, 2, 5, 10, 20, 50, 100},
+	}, []string{"kind", "blueprint"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(ResourceRetries, RealizationRetries, Workloads, Deliverables)
+}
+
+// RecordRetries adds the retries counted during a reconcile, the difference
+// between the retries before and after it, to ResourceRetries.
+func RecordRetries(kind, blueprint string, before, after []v1alpha1.ResourceRetries) {
+	counted := map[string]int64{}
+	for _, entry := range before {
+		counted[entry.Resource] = entry.Retries
+	}
+	for _, entry := range after {
+		if retries := entry.Retries - counted[entry.Resource]; retries > 0 {
+			ResourceRetries.WithLabelValues(kind, blueprint, entry.Resource).Add(float64(retries))
+		}
+	}
+}
+
+// ObserveRealization records, in RealizationRetries, the retries of a
+// realization that made its owner ready.
+func ObserveRealization(kind, blueprint string, retries []v1alpha1.ResourceRetries) {
+	RealizationRetries.WithLabelValues(kind, blueprint).Observe(float64(v1alpha1.TotalRetries(retries)))
+}
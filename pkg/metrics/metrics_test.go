@@ -0,0 +1,85 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/metrics"
+)
+
+var _ = Describe("Metrics", func() {
+	BeforeEach(func() {
+		metrics.ResourceRetries.Reset()
+		metrics.RealizationRetries.Reset()
+	})
+
+	Describe("RecordRetries", func() {
+		It("counts the retries added during a reconcile", func() {
+			metrics.RecordRetries("Workload", "web", []v1alpha1.ResourceRetries{
+				{Resource: "source-provider", Retries: 2},
+				{Resource: "image-builder", Retries: 1},
+			}, []v1alpha1.ResourceRetries{
+				{Resource: "source-provider", Retries: 2},
+				{Resource: "image-builder", Retries: 2},
+				{Resource: "deployer", Retries: 1},
+			})
+
+			Expect(testutil.ToFloat64(metrics.ResourceRetries.WithLabelValues("Workload", "web", "source-provider"))).To(BeZero())
+			Expect(testutil.ToFloat64(metrics.ResourceRetries.WithLabelValues("Workload", "web", "image-builder"))).To(Equal(1.0))
+			Expect(testutil.ToFloat64(metrics.ResourceRetries.WithLabelValues("Workload", "web", "deployer"))).To(Equal(1.0))
+		})
+
+		It("counts every retry of a new realization", func() {
+			metrics.RecordRetries("Deliverable", "delivery", nil, []v1alpha1.ResourceRetries{
+				{Resource: "deployer", Retries: 1},
+			})
+
+			Expect(testutil.ToFloat64(metrics.ResourceRetries.WithLabelValues("Deliverable", "delivery", "deployer"))).To(Equal(1.0))
+		})
+	})
+
+	Describe("ObserveRealization", func() {
+		It("observes the retries of every resource", func() {
+			metrics.ObserveRealization("Workload", "web", []v1alpha1.ResourceRetries{
+				{Resource: "source-provider", Retries: 3},
+				{Resource: "image-builder", Retries: 11},
+			})
+			metrics.ObserveRealization("Workload", "web", nil)
+
+			Expect(testutil.CollectAndCount(metrics.RealizationRetries)).To(Equal(1))
+			Expect(testutil.CollectAndCompare(metrics.RealizationRetries, strings.NewReader(`
+# HELP cartographer_realization_retries Retries a workload or deliverable consumed before becoming ready.
+# TYPE cartographer_realization_retries histogram
+cartographer_realization_retries_bucket{blueprint="web",kind="Workload",le="0"} 1
+cartographer_realization_retries_bucket{blueprint="web",kind="Workload",le="1"} 1
+cartographer_realization_retries_bucket{blueprint="web",kind="Workload",le="2"} 1
+cartographer_realization_retries_bucket{blueprint="web",kind="Workload",le="5"} 1
+cartographer_realization_retries_bucket{blueprint="web",kind="Workload",le="10"} 1
+cartographer_realization_retries_bucket{blueprint="web",kind="Workload",le="20"} 2
+cartographer_realization_retries_bucket{blueprint="web",kind="Workload",le="50"} 2
+cartographer_realization_retries_bucket{blueprint="web",kind="Workload",le="100"} 2
+cartographer_realization_retries_bucket{blueprint="web",kind="Workload",le="+Inf"} 2
+cartographer_realization_retries_sum{blueprint="web",kind="Workload"} 14
+cartographer_realization_retries_count{blueprint="web",kind="Workload"} 2
+`))).To(Succeed())
+		})
+	})
+})
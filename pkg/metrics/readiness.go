@@ -0,0 +1,117 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	// Workloads counts the workloads by the supply chain realizing them and
+	// the status of their Ready condition.
+	Workloads = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cartographer_workloads",
+		Help: "Workloads by the supply chain realizing them and the status of their Ready condition.",
+	}, []string{"supply_chain", "status"})
+
+	// Deliverables counts the deliverables by the delivery realizing them
+	// and the status of their Ready condition.
+	Deliverables = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cartographer_deliverables",
+		Help: "Deliverables by the delivery realizing them and the status of their Ready condition.",
+	}, []string{"delivery", "status"})
+
+	workloads    = newReadiness(Workloads)
+	deliverables = newReadiness(Deliverables)
+)
+
+// RecordWorkload counts the workload in Workloads under its supply chain and
+// the status of ready, Unknown when nil, in place of where it was counted
+// before.
+func RecordWorkload(key types.NamespacedName, supplyChain string, ready *metav1.Condition) {
+	workloads.record(key, supplyChain, readyStatus(ready))
+}
+
+// ForgetWorkload stops counting the workload, once it no longer exists.
+func ForgetWorkload(key types.NamespacedName) {
+	workloads.forget(key)
+}
+
+// RecordDeliverable counts the deliverable in Deliverables, as
+// RecordWorkload.
+func RecordDeliverable(key types.NamespacedName, delivery string, ready *metav1.Condition) {
+	deliverables.record(key, delivery, readyStatus(ready))
+}
+
+// ForgetDeliverable stops counting the deliverable, once it no longer
+// exists.
+func ForgetDeliverable(key types.NamespacedName) {
+	deliverables.forget(key)
+}
+
+func readyStatus(ready *metav1.Condition) string {
+	if ready == nil || ready.Status == "" {
+		return string(metav1.ConditionUnknown)
+	}
+	return string(ready.Status)
+}
+
+// readiness remembers where each owner is counted in a gauge, so that it is
+// counted once as it moves between blueprints and statuses.
+type readiness struct {
+	gauge *prometheus.GaugeVec
+
+	mu      sync.Mutex
+	counted map[types.NamespacedName][2]string
+}
+
+func newReadiness(gauge *prometheus.GaugeVec) *readiness {
+	return &readiness{
+		gauge:   gauge,
+		counted: map[types.NamespacedName][2]string{},
+	}
+}
+
+func (r *readiness) record(key types.NamespacedName, blueprint, status string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	labels := [2]string{blueprint, status}
+	previous, ok := r.counted[key]
+	if ok && previous == labels {
+		return
+	}
+	if ok {
+		r.gauge.WithLabelValues(previous[0], previous[1]).Dec()
+	}
+	r.gauge.WithLabelValues(labels[0], labels[1]).Inc()
+	r.counted[key] = labels
+}
+
+func (r *readiness) forget(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, ok := r.counted[key]
+	if !ok {
+		return
+	}
+	r.gauge.WithLabelValues(previous[0], previous[1]).Dec()
+	delete(r.counted, key)
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/metrics"
+)
+
+var _ = Describe("Readiness", func() {
+	ready := &metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue}
+	notReady := &metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse}
+
+	Describe("RecordWorkload", func() {
+		It("counts each workload once, where it was last recorded", func() {
+			app := types.NamespacedName{Namespace: "dev", Name: "readiness-app"}
+			api := types.NamespacedName{Namespace: "dev", Name: "readiness-api"}
+
+			metrics.RecordWorkload(app, "readiness-web", notReady)
+			metrics.RecordWorkload(api, "readiness-web", notReady)
+			Expect(testutil.ToFloat64(metrics.Workloads.WithLabelValues("readiness-web", "False"))).To(Equal(2.0))
+
+			metrics.RecordWorkload(app, "readiness-web", ready)
+			metrics.RecordWorkload(app, "readiness-web", ready)
+			Expect(testutil.ToFloat64(metrics.Workloads.WithLabelValues("readiness-web", "False"))).To(Equal(1.0))
+			Expect(testutil.ToFloat64(metrics.Workloads.WithLabelValues("readiness-web", "True"))).To(Equal(1.0))
+
+			metrics.RecordWorkload(app, "readiness-batch", nil)
+			Expect(testutil.ToFloat64(metrics.Workloads.WithLabelValues("readiness-web", "True"))).To(BeZero())
+			Expect(testutil.ToFloat64(metrics.Workloads.WithLabelValues("readiness-batch", "Unknown"))).To(Equal(1.0))
+		})
+	})
+
+	Describe("ForgetWorkload", func() {
+		It("stops counting the workload", func() {
+			app := types.NamespacedName{Namespace: "dev", Name: "forgotten-app"}
+
+			metrics.RecordWorkload(app, "forgotten-web", ready)
+			metrics.ForgetWorkload(app)
+			metrics.ForgetWorkload(app)
+
+			Expect(testutil.ToFloat64(metrics.Workloads.WithLabelValues("forgotten-web", "True"))).To(BeZero())
+		})
+	})
+
+	Describe("RecordDeliverable", func() {
+		It("counts the deliverables by delivery and status", func() {
+			app := types.NamespacedName{Namespace: "prod", Name: "readiness-app"}
+
+			metrics.RecordDeliverable(app, "readiness-delivery", ready)
+			Expect(testutil.ToFloat64(metrics.Deliverables.WithLabelValues("readiness-delivery", "True"))).To(Equal(1.0))
+
+			metrics.ForgetDeliverable(app)
+			Expect(testutil.ToFloat64(metrics.Deliverables.WithLabelValues("readiness-delivery", "True"))).To(BeZero())
+		})
+	})
+})
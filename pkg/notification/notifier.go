@@ -0,0 +1,204 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notification tells ClusterNotificationSinks about the workloads
+// and deliverables that stop being ready.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+const (
+	// DefaultMessage is the message of sinks that do not template their own.
+	DefaultMessage = `{{.Kind}} {{.Namespace}}/{{.Name}} is not ready: {{.Reason}}{{with .Message}}: {{.}}{{end}}`
+
+	// DefaultTimeout bounds each post to a sink.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Event is a workload or deliverable that stopped being ready.
+type Event struct {
+	Kind      string            `json:"kind"`
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Blueprint is the supply chain or delivery the object is matched to.
+	Blueprint string `json:"blueprint,omitempty"`
+	// Reason and Message are those of the object's Ready condition.
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Text is the message rendered for the sink.
+	Text string `json:"text"`
+}
+
+// BecameUnready reports whether an object whose Ready condition was
+// previous is to be notified about now that it is current: when it became
+// False, or stayed False for another reason.
+func BecameUnready(previous, current *metav1.Condition) bool {
+	if current == nil || current.Status != metav1.ConditionFalse {
+		return false
+	}
+	return previous == nil || previous.Status != metav1.ConditionFalse || previous.Reason != current.Reason
+}
+
+// Notifier posts events to the ClusterNotificationSinks that select them.
+type Notifier struct {
+	reader client.Reader
+	client *http.Client
+	logger logr.Logger
+}
+
+// NewNotifier returns a Notifier reading sinks, and the Secrets holding
+// their URLs, through reader.
+func NewNotifier(reader client.Reader, logger logr.Logger) *Notifier {
+	return &Notifier{
+		reader: reader,
+		client: &http.Client{Timeout: DefaultTimeout},
+		logger: logger,
+	}
+}
+
+// Notify posts event, in the background, to every sink whose selector
+// matches it. Sinks that cannot be reached are logged, not retried.
+func (n *Notifier) Notify(ctx context.Context, event Event) {
+	sinks := &v1alpha1.ClusterNotificationSinkList{}
+	if err := n.reader.List(ctx, sinks); err != nil {
+		n.logger.Error(err, "list cluster notification sinks")
+		return
+	}
+
+	for _, sink := range sinks.Items {
+		selected, err := selects(sink.Spec.Selector, event.Labels)
+		if err != nil {
+			n.logger.Error(err, "notification sink selector", "sink", sink.Name)
+			continue
+		}
+		if !selected {
+			continue
+		}
+
+		sink := sink
+		go func() {
+			if err := n.send(context.Background(), sink, event); err != nil {
+				n.logger.Error(err, "notify sink", "sink", sink.Name, "kind", event.Kind, "namespace", event.Namespace, "name", event.Name)
+			}
+		}()
+	}
+}
+
+func selects(selector *metav1.LabelSelector, objectLabels map[string]string) (bool, error) {
+	if selector == nil {
+		return true, nil
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return s.Matches(labels.Set(objectLabels)), nil
+}
+
+func (n *Notifier) send(ctx context.Context, sink v1alpha1.ClusterNotificationSink, event Event) error {
+	text, err := Render(sink.Spec.Message, event)
+	if err != nil {
+		return err
+	}
+
+	var (
+		endpoint *v1alpha1.NotificationEndpoint
+		body     interface{}
+	)
+	if sink.Spec.Slack != nil {
+		endpoint, body = sink.Spec.Slack, map[string]string{"text": text}
+	} else if sink.Spec.Webhook != nil {
+		event.Text = text
+		endpoint, body = sink.Spec.Webhook, event
+	} else {
+		return fmt.Errorf("sink has neither webhook nor slack")
+	}
+
+	url, err := n.url(ctx, *endpoint)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("post notification: %s", resp.Status)
+	}
+	return nil
+}
+
+func (n *Notifier) url(ctx context.Context, endpoint v1alpha1.NotificationEndpoint) (string, error) {
+	if endpoint.URLFrom == nil {
+		return endpoint.URL, nil
+	}
+
+	ref := endpoint.URLFrom
+	secret := &corev1.Secret{}
+	if err := n.reader.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		return "", fmt.Errorf("get secret '%s/%s': %w", ref.Namespace, ref.Name, err)
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret '%s/%s' has no %s", ref.Namespace, ref.Name, ref.Key)
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// Render renders message, or DefaultMessage when it is empty, for event.
+func Render(message string, event Event) (string, error) {
+	if message == "" {
+		message = DefaultMessage
+	}
+	tmpl, err := template.New("message").Parse(message)
+	if err != nil {
+		return "", fmt.Errorf("parse message: %w", err)
+	}
+	var text strings.Builder
+	if err := tmpl.Execute(&text, event); err != nil {
+		return "", fmt.Errorf("render message: %w", err)
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/notification"
+)
+
+var _ = Describe("BecameUnready", func() {
+	ready := func(status metav1.ConditionStatus, reason string) *metav1.Condition {
+		return &metav1.Condition{Type: "Ready", Status: status, Reason: reason}
+	}
+
+	It("is true when the Ready condition becomes False", func() {
+		Expect(notification.BecameUnready(ready(metav1.ConditionTrue, "Ready"), ready(metav1.ConditionFalse, "TemplateRejectedByAPIServer"))).To(BeTrue())
+		Expect(notification.BecameUnready(ready(metav1.ConditionUnknown, "Unknown"), ready(metav1.ConditionFalse, "TemplateRejectedByAPIServer"))).To(BeTrue())
+		Expect(notification.BecameUnready(nil, ready(metav1.ConditionFalse, "TemplateRejectedByAPIServer"))).To(BeTrue())
+	})
+
+	It("is true when a False Ready condition changes reason", func() {
+		Expect(notification.BecameUnready(ready(metav1.ConditionFalse, "TemplateStampFailure"), ready(metav1.ConditionFalse, "TemplateRejectedByAPIServer"))).To(BeTrue())
+	})
+
+	It("is false when the Ready condition stays False for the same reason", func() {
+		Expect(notification.BecameUnready(ready(metav1.ConditionFalse, "TemplateStampFailure"), ready(metav1.ConditionFalse, "TemplateStampFailure"))).To(BeFalse())
+	})
+
+	It("is false when the Ready condition is not False", func() {
+		Expect(notification.BecameUnready(ready(metav1.ConditionFalse, "TemplateStampFailure"), ready(metav1.ConditionTrue, "Ready"))).To(BeFalse())
+		Expect(notification.BecameUnready(nil, nil)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Notifier", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		mu       sync.Mutex
+		received []map[string]interface{}
+		objects  []client.Object
+		event    notification.Event
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			var payload map[string]interface{}
+			Expect(json.Unmarshal(body, &payload)).To(Succeed())
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, payload)
+		}))
+		objects = nil
+		event = notification.Event{
+			Kind:      "Workload",
+			Namespace: "team-a",
+			Name:      "app",
+			Labels:    map[string]string{"env": "prod"},
+			Blueprint: "web",
+			Reason:    "TemplateRejectedByAPIServer",
+			Message:   "admission webhook denied the request",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	notify := func() {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		notification.NewNotifier(reader, logr.Discard()).Notify(ctx, event)
+	}
+
+	notifications := func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]map[string]interface{}{}, received...)
+	}
+
+	sink := func(name string, spec v1alpha1.ClusterNotificationSinkSpec) *v1alpha1.ClusterNotificationSink {
+		return &v1alpha1.ClusterNotificationSink{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+
+	It("posts the event to webhook sinks", func() {
+		objects = append(objects, sink("ops", v1alpha1.ClusterNotificationSinkSpec{
+			Webhook: &v1alpha1.NotificationEndpoint{URL: server.URL},
+		}))
+
+		notify()
+
+		Eventually(notifications).Should(HaveLen(1))
+		Expect(notifications()[0]).To(Equal(map[string]interface{}{
+			"kind":      "Workload",
+			"namespace": "team-a",
+			"name":      "app",
+			"labels":    map[string]interface{}{"env": "prod"},
+			"blueprint": "web",
+			"reason":    "TemplateRejectedByAPIServer",
+			"message":   "admission webhook denied the request",
+			"text":      "Workload team-a/app is not ready: TemplateRejectedByAPIServer: admission webhook denied the request",
+		}))
+	})
+
+	It("posts the templated message to slack sinks, reading their URL from a secret", func() {
+		objects = append(objects,
+			sink("slack", v1alpha1.ClusterNotificationSinkSpec{
+				Message: `:red_circle: {{.Name}} on {{.Blueprint}}: {{.Reason}}`,
+				Slack: &v1alpha1.NotificationEndpoint{
+					URLFrom: &v1alpha1.SecretKeyReference{Name: "slack", Namespace: "cartographer-system", Key: "url"},
+				},
+			}),
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "slack", Namespace: "cartographer-system"},
+				Data:       map[string][]byte{"url": []byte(server.URL + "\n")},
+			},
+		)
+
+		notify()
+
+		Eventually(notifications).Should(HaveLen(1))
+		Expect(notifications()[0]).To(Equal(map[string]interface{}{
+			"text": ":red_circle: app on web: TemplateRejectedByAPIServer",
+		}))
+	})
+
+	It("only notifies the sinks selecting the object", func() {
+		objects = append(objects,
+			sink("prod", v1alpha1.ClusterNotificationSinkSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+				Webhook:  &v1alpha1.NotificationEndpoint{URL: server.URL + "/prod"},
+			}),
+			sink("staging", v1alpha1.ClusterNotificationSinkSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "staging"}},
+				Webhook:  &v1alpha1.NotificationEndpoint{URL: server.URL + "/staging"},
+			}),
+		)
+
+		notify()
+
+		Eventually(notifications).Should(HaveLen(1))
+		Consistently(notifications, "100ms").Should(HaveLen(1))
+	})
+})
+
+var _ = Describe("Render", func() {
+	It("renders the default message", func() {
+		text, err := notification.Render("", notification.Event{Kind: "Deliverable", Namespace: "ns", Name: "app", Reason: "JobFailed"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal("Deliverable ns/app is not ready: JobFailed"))
+	})
+
+	It("returns an error for a message that does not parse", func() {
+		_, err := notification.Render("{{.Name", notification.Event{})
+		Expect(err).To(MatchError(ContainSubstring("parse message")))
+	})
+})
@@ -0,0 +1,313 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oci pulls artifacts of YAML documents, such as packaged
+// blueprints, from OCI registries, and resolves references to the digests
+// of the artifacts they point at.
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	ociIndexMediaType       = "application/vnd.oci.image.index.v1+json"
+	dockerListMediaType     = "application/vnd.docker.distribution.manifest.list.v2+json"
+	orasArtifactMediaType   = "application/vnd.cncf.oras.artifact.manifest.v1+json"
+
+	// maxBlobSize bounds the manifests and layers read from a registry.
+	maxBlobSize = 32 << 20
+)
+
+// Artifact is the content of a pulled artifact.
+type Artifact struct {
+	// Digest of the artifact's manifest.
+	Digest string
+	// Documents are the YAML files of the artifact's layers, in order.
+	Documents [][]byte
+}
+
+// Client pulls artifacts over the registry HTTP API.
+type Client struct {
+	HTTP *http.Client
+}
+
+// NewClient returns a Client using http.DefaultClient.
+func NewClient() *Client {
+	return &Client{HTTP: http.DefaultClient}
+}
+
+// PullOptions configure a pull.
+type PullOptions struct {
+	// Credentials to pull with, anonymously when nil.
+	Credentials *Credentials
+	// Insecure talks to the registry over plain HTTP.
+	Insecure bool
+}
+
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Layers    []descriptor `json:"layers"`
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Pull fetches the manifest of ref and the YAML documents in its layers.
+// Layers are either tar archives, optionally gzipped, whose .yaml and .yml
+// files are read in name order, or YAML files themselves.
+func (c *Client) Pull(ctx context.Context, ref Reference, options PullOptions) (*Artifact, error) {
+	s := &session{client: c.HTTP, ref: ref, options: options, scheme: "https"}
+	if options.Insecure {
+		s.scheme = "http"
+	}
+
+	raw, digest, err := s.fetch(ctx, "manifests", ref.version(), ociManifestMediaType+", "+dockerManifestMediaType)
+	if err != nil {
+		return nil, fmt.Errorf("pull manifest of '%s': %w", ref, err)
+	}
+	if ref.Digest != "" && digest != ref.Digest {
+		return nil, fmt.Errorf("pull manifest of '%s': digest is %s", ref, digest)
+	}
+
+	m := manifest{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("unmarshal manifest of '%s': %w", ref, err)
+	}
+	if m.MediaType != "" && m.MediaType != ociManifestMediaType && m.MediaType != dockerManifestMediaType {
+		return nil, fmt.Errorf("unsupported manifest of '%s': media type %s", ref, m.MediaType)
+	}
+
+	artifact := &Artifact{Digest: digest}
+	for _, layer := range m.Layers {
+		blob, blobDigest, err := s.fetch(ctx, "blobs", layer.Digest, "*/*")
+		if err != nil {
+			return nil, fmt.Errorf("pull layer %s of '%s': %w", layer.Digest, ref, err)
+		}
+		if blobDigest != layer.Digest {
+			return nil, fmt.Errorf("pull layer %s of '%s': digest is %s", layer.Digest, ref, blobDigest)
+		}
+
+		documents, err := layerDocuments(layer.MediaType, blob)
+		if err != nil {
+			return nil, fmt.Errorf("read layer %s of '%s': %w", layer.Digest, ref, err)
+		}
+		artifact.Documents = append(artifact.Documents, documents...)
+	}
+	return artifact, nil
+}
+
+// Resolve returns the digest of the manifest ref points at. Any kind of
+// manifest is accepted, so that image indexes, imgpkg bundles and ORAS
+// artifacts can be resolved as well as images.
+func (c *Client) Resolve(ctx context.Context, ref Reference, options PullOptions) (string, error) {
+	s := &session{client: c.HTTP, ref: ref, options: options, scheme: "https"}
+	if options.Insecure {
+		s.scheme = "http"
+	}
+
+	accept := strings.Join([]string{ociManifestMediaType, dockerManifestMediaType, ociIndexMediaType, dockerListMediaType, orasArtifactMediaType}, ", ")
+	_, digest, err := s.fetch(ctx, "manifests", ref.version(), accept)
+	if err != nil {
+		return "", fmt.Errorf("resolve '%s': %w", ref, err)
+	}
+	if ref.Digest != "" && digest != ref.Digest {
+		return "", fmt.Errorf("resolve '%s': digest is %s", ref, digest)
+	}
+	return digest, nil
+}
+
+type session struct {
+	client  *http.Client
+	ref     Reference
+	options PullOptions
+	scheme  string
+	// authorization is the Authorization header of requests, once the
+	// registry has challenged one.
+	authorization string
+}
+
+// fetch gets a manifest or blob, authenticating when challenged, and
+// returns it with its digest.
+func (s *session) fetch(ctx context.Context, kind, version, accept string) ([]byte, string, error) {
+	endpoint := fmt.Sprintf("%s://%s/v2/%s/%s/%s", s.scheme, s.ref.Registry, s.ref.Repository, kind, version)
+
+	resp, err := s.get(ctx, endpoint, accept)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && s.authorization == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := s.authorize(ctx, challenge); err != nil {
+			return nil, "", err
+		}
+		if resp, err = s.get(ctx, endpoint, accept); err != nil {
+			return nil, "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("GET %s: %s", endpoint, resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBlobSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("GET %s: %w", endpoint, err)
+	}
+	if len(body) > maxBlobSize {
+		return nil, "", fmt.Errorf("GET %s: larger than %d bytes", endpoint, maxBlobSize)
+	}
+
+	sum := sha256.Sum256(body)
+	return body, "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+func (s *session) get(ctx context.Context, endpoint, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if s.authorization != "" {
+		req.Header.Set("Authorization", s.authorization)
+	}
+	return s.client.Do(req)
+}
+
+// authorize answers a WWW-Authenticate challenge: Basic challenges with the
+// credentials, Bearer challenges with a token fetched from the realm.
+func (s *session) authorize(ctx context.Context, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if s.options.Credentials == nil {
+			return fmt.Errorf("registry requires credentials")
+		}
+		userinfo := s.options.Credentials.Username + ":" + s.options.Credentials.Password
+		s.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(userinfo))
+		return nil
+	case "bearer":
+		token, err := s.token(ctx, params)
+		if err != nil {
+			return err
+		}
+		s.authorization = "Bearer " + token
+		return nil
+	}
+	return fmt.Errorf("unsupported authentication challenge '%s'", challenge)
+}
+
+func (s *session) token(ctx context.Context, params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid token realm '%s'", params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", s.ref.Repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if s.options.Credentials != nil {
+		req.SetBasicAuth(s.options.Credentials.Username, s.options.Credentials.Password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("get token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("get token: %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxBlobSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("get token: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("get token: no token in response")
+}
+
+// parseChallenge splits a WWW-Authenticate header, such as
+// Bearer realm="https://auth.example.com/token",service="registry", into
+// its scheme and parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+
+	rest := parts[1]
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.Index(rest, ",")
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		params[key] = value
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return parts[0], params
+}
@@ -0,0 +1,233 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/oci"
+)
+
+type registry struct {
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	// token, when set, must be presented as a bearer token obtained from
+	// the /token endpoint with the credentials user:pass.
+	token string
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		user, pass, ok := req.BasicAuth()
+		if !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": r.token})
+		return
+	}
+
+	if r.token != "" && req.Header.Get("Authorization") != "Bearer "+r.token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test"`, req.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(req.URL.Path, "/")
+	version := parts[len(parts)-1]
+	switch parts[len(parts)-2] {
+	case "manifests":
+		if m, ok := r.manifests[version]; ok {
+			_, _ = w.Write(m)
+			return
+		}
+	case "blobs":
+		if b, ok := r.blobs[version]; ok {
+			_, _ = w.Write(b)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func digestOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func tarball(files map[string]string, gzipped bool) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, name := range []string{"b/templates.yaml", "a/supply-chain.yml", "README.md"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tw.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	if !gzipped {
+		return buf.Bytes()
+	}
+
+	gzBuf := &bytes.Buffer{}
+	gz := gzip.NewWriter(gzBuf)
+	_, err := gz.Write(buf.Bytes())
+	Expect(err).NotTo(HaveOccurred())
+	Expect(gz.Close()).To(Succeed())
+	return gzBuf.Bytes()
+}
+
+var _ = Describe("Client", func() {
+	var (
+		reg            *registry
+		server         *httptest.Server
+		manifestDigest string
+		client         *oci.Client
+	)
+
+	BeforeEach(func() {
+		layer1 := tarball(map[string]string{
+			"b/templates.yaml":   "kind: ClusterTemplate",
+			"a/supply-chain.yml": "kind: ClusterSupplyChain",
+			"README.md":          "# blueprints",
+		}, true)
+		layer2 := []byte("kind: ClusterDelivery")
+
+		manifest, err := json.Marshal(map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+			"layers": []map[string]interface{}{
+				{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": digestOf(layer1), "size": len(layer1)},
+				{"mediaType": "application/yaml", "digest": digestOf(layer2), "size": len(layer2)},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		manifestDigest = digestOf(manifest)
+
+		reg = &registry{
+			blobs:     map[string][]byte{digestOf(layer1): layer1, digestOf(layer2): layer2},
+			manifests: map[string][]byte{"1.0.0": manifest, manifestDigest: manifest},
+		}
+		server = httptest.NewServer(reg)
+		client = &oci.Client{HTTP: server.Client()}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	reference := func(version string) oci.Reference {
+		ref, err := oci.ParseReference(strings.TrimPrefix(server.URL, "http://") + "/team/blueprints" + version)
+		Expect(err).NotTo(HaveOccurred())
+		return ref
+	}
+
+	It("pulls the YAML files of the artifact's layers", func() {
+		artifact, err := client.Pull(context.Background(), reference(":1.0.0"), oci.PullOptions{Insecure: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(artifact.Digest).To(Equal(manifestDigest))
+		Expect(artifact.Documents).To(Equal([][]byte{
+			[]byte("kind: ClusterSupplyChain"),
+			[]byte("kind: ClusterTemplate"),
+			[]byte("kind: ClusterDelivery"),
+		}))
+	})
+
+	It("pulls artifacts by digest", func() {
+		artifact, err := client.Pull(context.Background(), reference("@"+manifestDigest), oci.PullOptions{Insecure: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(artifact.Documents).To(HaveLen(3))
+	})
+
+	It("rejects content that does not match its digest", func() {
+		for digest := range reg.blobs {
+			reg.blobs[digest] = []byte("tampered")
+		}
+
+		_, err := client.Pull(context.Background(), reference(":1.0.0"), oci.PullOptions{Insecure: true})
+		Expect(err).To(MatchError(ContainSubstring("digest is")))
+	})
+
+	It("returns an error for missing artifacts", func() {
+		_, err := client.Pull(context.Background(), reference(":2.0.0"), oci.PullOptions{Insecure: true})
+		Expect(err).To(MatchError(ContainSubstring("404 Not Found")))
+	})
+
+	Describe("Resolve", func() {
+		It("resolves a tag to the digest of its manifest", func() {
+			digest, err := client.Resolve(context.Background(), reference(":1.0.0"), oci.PullOptions{Insecure: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(Equal(manifestDigest))
+		})
+
+		It("resolves manifests of any media type", func() {
+			index := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[]}`)
+			reg.manifests["index"] = index
+
+			digest, err := client.Resolve(context.Background(), reference(":index"), oci.PullOptions{Insecure: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digest).To(Equal(digestOf(index)))
+		})
+
+		It("rejects a manifest that does not match the digest it is pulled by", func() {
+			reg.manifests[manifestDigest] = []byte("tampered")
+
+			_, err := client.Resolve(context.Background(), reference("@"+manifestDigest), oci.PullOptions{Insecure: true})
+			Expect(err).To(MatchError(ContainSubstring("digest is")))
+		})
+
+		It("returns an error for missing artifacts", func() {
+			_, err := client.Resolve(context.Background(), reference(":2.0.0"), oci.PullOptions{Insecure: true})
+			Expect(err).To(MatchError(ContainSubstring("404 Not Found")))
+		})
+	})
+
+	Context("when the registry requires a token", func() {
+		BeforeEach(func() {
+			reg.token = "some-token"
+		})
+
+		It("pulls with a token obtained with the credentials", func() {
+			artifact, err := client.Pull(context.Background(), reference(":1.0.0"), oci.PullOptions{
+				Insecure:    true,
+				Credentials: &oci.Credentials{Username: "user", Password: "pass"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(artifact.Documents).To(HaveLen(3))
+		})
+
+		It("fails without credentials", func() {
+			_, err := client.Pull(context.Background(), reference(":1.0.0"), oci.PullOptions{Insecure: true})
+			Expect(err).To(MatchError(ContainSubstring("get token: 401")))
+		})
+	})
+})
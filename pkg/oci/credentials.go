@@ -0,0 +1,78 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Credentials authenticate pulls from a registry.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+type dockerConfig struct {
+	Auths map[string]struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	} `json:"auths"`
+}
+
+// CredentialsFromDockerConfig returns the credentials for registry in a
+// Docker config.json, as held by kubernetes.io/dockerconfigjson Secrets, or
+// nil if it has none.
+func CredentialsFromDockerConfig(data []byte, registry string) (*Credentials, error) {
+	config := dockerConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("unmarshal docker config: %w", err)
+	}
+
+	for host, auth := range config.Auths {
+		if normalizeRegistry(host) != normalizeRegistry(registry) {
+			continue
+		}
+		if auth.Auth == "" {
+			return &Credentials{Username: auth.Username, Password: auth.Password}, nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("decode auth of registry '%s': %w", host, err)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("decode auth of registry '%s': expected username:password", host)
+		}
+		return &Credentials{Username: parts[0], Password: parts[1]}, nil
+	}
+	return nil, nil
+}
+
+// normalizeRegistry reduces the keys of a docker config, which may be URLs
+// or refer to Docker Hub by one of its names, to registry hosts.
+func normalizeRegistry(host string) string {
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	host = strings.SplitN(host, "/", 2)[0]
+	switch host {
+	case dockerHub, "index.docker.io":
+		return dockerHubRegistry
+	}
+	return host
+}
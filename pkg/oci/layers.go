@@ -0,0 +1,84 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+)
+
+// layerDocuments returns the YAML files in a layer.
+func layerDocuments(mediaType string, blob []byte) ([][]byte, error) {
+	if !strings.Contains(mediaType, "tar") {
+		if strings.Contains(mediaType, "yaml") {
+			return [][]byte{blob}, nil
+		}
+		return nil, fmt.Errorf("unsupported media type %s", mediaType)
+	}
+
+	var r io.Reader = bytes.NewReader(blob)
+	if strings.Contains(mediaType, "gzip") || bytes.HasPrefix(blob, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("gunzip: %w", err)
+		}
+		defer gz.Close()
+		r = io.LimitReader(gz, maxBlobSize)
+	}
+
+	files := map[string][]byte{}
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("untar: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if ext := path.Ext(header.Name); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("untar %s: %w", header.Name, err)
+		}
+		files[header.Name] = content
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	documents := make([][]byte, 0, len(names))
+	for _, name := range names {
+		documents = append(documents, files[name])
+	}
+	return documents, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dockerHub         = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// Reference locates an artifact in a registry, by tag or digest.
+type Reference struct {
+	// Registry host, with its port if any.
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an image reference such as
+// registry.example.com/team/blueprints:1.0.0. References without a registry
+// are looked up on Docker Hub, and references without a tag or digest use
+// the latest tag.
+func ParseReference(ref string) (Reference, error) {
+	if ref == "" || strings.ContainsAny(ref, " \t\n") {
+		return Reference{}, fmt.Errorf("invalid reference '%s'", ref)
+	}
+
+	parsed := Reference{}
+	rest := ref
+	if i := strings.Index(rest, "@"); i >= 0 {
+		parsed.Digest = rest[i+1:]
+		rest = rest[:i]
+		if !strings.HasPrefix(parsed.Digest, "sha256:") || len(parsed.Digest) != len("sha256:")+64 {
+			return Reference{}, fmt.Errorf("invalid reference '%s': unsupported digest '%s'", ref, parsed.Digest)
+		}
+	}
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.Contains(rest[i+1:], "/") {
+		parsed.Tag = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		parsed.Registry, parsed.Repository = parts[0], parts[1]
+	} else {
+		parsed.Registry, parsed.Repository = dockerHub, rest
+	}
+	if parsed.Registry == dockerHub {
+		parsed.Registry = dockerHubRegistry
+		if !strings.Contains(parsed.Repository, "/") {
+			parsed.Repository = "library/" + parsed.Repository
+		}
+	}
+
+	if parsed.Repository == "" || strings.ToLower(parsed.Repository) != parsed.Repository {
+		return Reference{}, fmt.Errorf("invalid reference '%s': invalid repository '%s'", ref, parsed.Repository)
+	}
+	if parsed.Tag == "" && parsed.Digest == "" {
+		parsed.Tag = "latest"
+	}
+	return parsed, nil
+}
+
+// version is the tag or digest of the manifest to pull, preferring the
+// digest.
+func (r Reference) version() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/oci"
+)
+
+var _ = Describe("ParseReference", func() {
+	digest := "sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	DescribeTable("valid references",
+		func(ref string, expected oci.Reference) {
+			Expect(oci.ParseReference(ref)).To(Equal(expected))
+		},
+		Entry("with registry and tag", "registry.example.com/team/blueprints:1.0.0",
+			oci.Reference{Registry: "registry.example.com", Repository: "team/blueprints", Tag: "1.0.0"}),
+		Entry("with a registry port", "localhost:5000/blueprints",
+			oci.Reference{Registry: "localhost:5000", Repository: "blueprints", Tag: "latest"}),
+		Entry("with a digest", "registry.example.com/blueprints@"+digest,
+			oci.Reference{Registry: "registry.example.com", Repository: "blueprints", Digest: digest}),
+		Entry("on Docker Hub", "team/blueprints:1.0.0",
+			oci.Reference{Registry: "registry-1.docker.io", Repository: "team/blueprints", Tag: "1.0.0"}),
+		Entry("of an official Docker Hub image", "blueprints",
+			oci.Reference{Registry: "registry-1.docker.io", Repository: "library/blueprints", Tag: "latest"}),
+	)
+
+	DescribeTable("invalid references",
+		func(ref string) {
+			_, err := oci.ParseReference(ref)
+			Expect(err).To(MatchError(ContainSubstring("invalid reference")))
+		},
+		Entry("empty", ""),
+		Entry("with spaces", "registry.example.com/blue prints"),
+		Entry("with an unsupported digest", "registry.example.com/blueprints@md5:abc"),
+		Entry("with uppercase", "registry.example.com/Blueprints"),
+	)
+})
+
+var _ = Describe("CredentialsFromDockerConfig", func() {
+	It("returns the credentials of the registry", func() {
+		config := `{"auths": {
+			"https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="},
+			"registry.example.com": {"username": "user", "password": "pass"}
+		}}`
+
+		Expect(oci.CredentialsFromDockerConfig([]byte(config), "registry.example.com")).To(Equal(&oci.Credentials{Username: "user", Password: "pass"}))
+		Expect(oci.CredentialsFromDockerConfig([]byte(config), "registry-1.docker.io")).To(Equal(&oci.Credentials{Username: "hub", Password: "secret"}))
+		Expect(oci.CredentialsFromDockerConfig([]byte(config), "other.example.com")).To(BeNil())
+	})
+
+	It("returns an error for invalid configs", func() {
+		_, err := oci.CredentialsFromDockerConfig([]byte(`{"auths": {"registry.example.com": {"auth": "bm9jb2xvbg=="}}}`), "registry.example.com")
+		Expect(err).To(MatchError(ContainSubstring("expected username:password")))
+	})
+})
@@ -0,0 +1,161 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ocisource resolves the OCI artifacts workloads and deliverables
+// use as their source to the digests they point at.
+package ocisource
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/oci"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+const (
+	// DefaultInterval is how often references are resolved again.
+	DefaultInterval = time.Minute
+
+	// dockerConfigKey is the key of the credentials in a
+	// kubernetes.io/dockerconfigjson Secret.
+	dockerConfigKey = ".dockerconfigjson"
+)
+
+//counterfeiter:generate . Registry
+type Registry interface {
+	Resolve(ctx context.Context, ref oci.Reference, options oci.PullOptions) (string, error)
+}
+
+type resolvedDigest struct {
+	digest     string
+	resolvedAt time.Time
+}
+
+// Resolver resolves OCI sources through a Registry. A reference is resolved
+// again once interval has passed since it was last resolved, so that
+// artifacts pushed again under the same tag are picked up.
+type Resolver struct {
+	registry Registry
+	repo     repository.Repository
+	interval time.Duration
+
+	mu      sync.Mutex
+	digests map[string]resolvedDigest
+}
+
+func NewResolver(registry Registry, repo repository.Repository, interval time.Duration) *Resolver {
+	return &Resolver{
+		registry: registry,
+		repo:     repo,
+		interval: interval,
+		digests:  map[string]resolvedDigest{},
+	}
+}
+
+// Resolve returns source as resolved for an owner in namespace, reading the
+// registry credentials from source's secret in that namespace. When
+// resolving the reference again fails, the digest it was last resolved to
+// is returned.
+func (r *Resolver) Resolve(ctx context.Context, namespace string, source v1alpha1.OCISource) (*v1alpha1.ResolvedSource, error) {
+	ref, err := oci.ParseReference(source.Image)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Digest != "" {
+		return resolved(source, ref, ref.Digest), nil
+	}
+
+	// Sources are cached per namespace and secret, so that an owner never
+	// gets a digest resolved with credentials it cannot read.
+	key := namespace + "/" + secretName(source) + "/" + source.Image
+	r.mu.Lock()
+	last, ok := r.digests[key]
+	r.mu.Unlock()
+	if ok && time.Since(last.resolvedAt) < r.interval {
+		return resolved(source, ref, last.digest), nil
+	}
+
+	digest, err := r.resolve(ctx, namespace, source, ref)
+	if err != nil {
+		if ok {
+			return resolved(source, ref, last.digest), nil
+		}
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.digests[key] = resolvedDigest{digest: digest, resolvedAt: time.Now()}
+	r.mu.Unlock()
+	return resolved(source, ref, digest), nil
+}
+
+func (r *Resolver) resolve(ctx context.Context, namespace string, source v1alpha1.OCISource, ref oci.Reference) (string, error) {
+	options := oci.PullOptions{Insecure: source.Insecure}
+	if source.SecretRef != nil {
+		credentials, err := r.credentials(ctx, namespace, source.SecretRef.Name, ref)
+		if err != nil {
+			return "", err
+		}
+		options.Credentials = credentials
+	}
+	return r.registry.Resolve(ctx, ref, options)
+}
+
+func (r *Resolver) credentials(ctx context.Context, namespace, name string, ref oci.Reference) (*oci.Credentials, error) {
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace(namespace)
+	secret.SetName(name)
+	if err := r.repo.GetUnstructured(ctx, secret); err != nil {
+		return nil, fmt.Errorf("get secret '%s/%s': %w", namespace, name, err)
+	}
+
+	encoded, _, _ := unstructured.NestedString(secret.Object, "data", dockerConfigKey)
+	config, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(config) == 0 {
+		return nil, fmt.Errorf("secret '%s/%s' has no %s", namespace, name, dockerConfigKey)
+	}
+
+	credentials, err := oci.CredentialsFromDockerConfig(config, ref.Registry)
+	if err != nil {
+		return nil, fmt.Errorf("secret '%s/%s': %w", namespace, name, err)
+	}
+	return credentials, nil
+}
+
+func secretName(source v1alpha1.OCISource) string {
+	if source.SecretRef == nil {
+		return ""
+	}
+	return source.SecretRef.Name
+}
+
+func resolved(source v1alpha1.OCISource, ref oci.Reference, digest string) *v1alpha1.ResolvedSource {
+	ref.Digest = digest
+	return &v1alpha1.ResolvedSource{
+		Image:  source.Image,
+		Digest: digest,
+		URL:    ref.String(),
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ocisource_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/oci"
+	"github.com/vmware-tanzu/cartographer/pkg/ocisource"
+	"github.com/vmware-tanzu/cartographer/pkg/ocisource/ocisourcefakes"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+const (
+	digest1 = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	digest2 = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+var _ = Describe("Resolver", func() {
+	var (
+		ctx      context.Context
+		registry *ocisourcefakes.FakeRegistry
+		repo     *repositoryfakes.FakeRepository
+		resolver *ocisource.Resolver
+		source   v1alpha1.OCISource
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		registry = &ocisourcefakes.FakeRegistry{}
+		registry.ResolveReturns(digest1, nil)
+		repo = &repositoryfakes.FakeRepository{}
+		source = v1alpha1.OCISource{Image: "registry.example.com/team/app-bundle:main"}
+	})
+
+	Context("within the interval", func() {
+		BeforeEach(func() {
+			resolver = ocisource.NewResolver(registry, repo, time.Hour)
+		})
+
+		It("resolves the reference once", func() {
+			for i := 0; i < 3; i++ {
+				resolved, err := resolver.Resolve(ctx, "some-ns", source)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resolved).To(Equal(&v1alpha1.ResolvedSource{
+					Image:  "registry.example.com/team/app-bundle:main",
+					Digest: digest1,
+					URL:    "registry.example.com/team/app-bundle:main@" + digest1,
+				}))
+			}
+
+			Expect(registry.ResolveCallCount()).To(Equal(1))
+			_, ref, options := registry.ResolveArgsForCall(0)
+			Expect(ref.String()).To(Equal("registry.example.com/team/app-bundle:main"))
+			Expect(options).To(Equal(oci.PullOptions{}))
+		})
+
+		It("resolves the reference for each namespace on its own", func() {
+			_, err := resolver.Resolve(ctx, "some-ns", source)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = resolver.Resolve(ctx, "other-ns", source)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registry.ResolveCallCount()).To(Equal(2))
+		})
+
+		It("does not resolve references pinned to a digest", func() {
+			source.Image = "registry.example.com/team/app-bundle@" + digest2
+
+			resolved, err := resolver.Resolve(ctx, "some-ns", source)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolved.Digest).To(Equal(digest2))
+			Expect(resolved.URL).To(Equal("registry.example.com/team/app-bundle@" + digest2))
+			Expect(registry.ResolveCallCount()).To(Equal(0))
+		})
+
+		It("returns an error for invalid references", func() {
+			source.Image = "Registry.example.com/Team"
+
+			_, err := resolver.Resolve(ctx, "some-ns", source)
+			Expect(err).To(MatchError(ContainSubstring("invalid reference")))
+		})
+
+		It("returns the error of a reference never resolved", func() {
+			registry.ResolveReturns("", errors.New("registry down"))
+
+			_, err := resolver.Resolve(ctx, "some-ns", source)
+			Expect(err).To(MatchError("registry down"))
+		})
+
+		Context("with a secret", func() {
+			BeforeEach(func() {
+				source.SecretRef = &corev1.LocalObjectReference{Name: "registry-credentials"}
+				source.Insecure = true
+			})
+
+			It("resolves with the credentials of the secret in the owner's namespace", func() {
+				config := `{"auths":{"registry.example.com":{"username":"user","password":"pass"}}}`
+				repo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					return unstructured.SetNestedField(obj.Object, base64.StdEncoding.EncodeToString([]byte(config)), "data", ".dockerconfigjson")
+				}
+
+				_, err := resolver.Resolve(ctx, "some-ns", source)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(repo.GetUnstructuredCallCount()).To(Equal(1))
+				_, secret := repo.GetUnstructuredArgsForCall(0)
+				Expect(secret.GetKind()).To(Equal("Secret"))
+				Expect(secret.GetNamespace()).To(Equal("some-ns"))
+				Expect(secret.GetName()).To(Equal("registry-credentials"))
+
+				_, _, options := registry.ResolveArgsForCall(0)
+				Expect(options).To(Equal(oci.PullOptions{
+					Credentials: &oci.Credentials{Username: "user", Password: "pass"},
+					Insecure:    true,
+				}))
+			})
+
+			It("returns an error when the secret holds no docker config", func() {
+				_, err := resolver.Resolve(ctx, "some-ns", source)
+				Expect(err).To(MatchError("secret 'some-ns/registry-credentials' has no .dockerconfigjson"))
+				Expect(registry.ResolveCallCount()).To(Equal(0))
+			})
+
+			It("returns an error when the secret cannot be read", func() {
+				repo.GetUnstructuredReturns(errors.New("forbidden"))
+
+				_, err := resolver.Resolve(ctx, "some-ns", source)
+				Expect(err).To(MatchError(ContainSubstring("get secret 'some-ns/registry-credentials': forbidden")))
+			})
+		})
+	})
+
+	Context("once the interval has passed", func() {
+		BeforeEach(func() {
+			resolver = ocisource.NewResolver(registry, repo, 0)
+		})
+
+		It("resolves the reference again", func() {
+			_, err := resolver.Resolve(ctx, "some-ns", source)
+			Expect(err).NotTo(HaveOccurred())
+
+			registry.ResolveReturns(digest2, nil)
+			resolved, err := resolver.Resolve(ctx, "some-ns", source)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolved.Digest).To(Equal(digest2))
+			Expect(resolved.URL).To(HaveSuffix("@" + digest2))
+		})
+
+		It("falls back to the last digest when resolving fails", func() {
+			_, err := resolver.Resolve(ctx, "some-ns", source)
+			Expect(err).NotTo(HaveOccurred())
+
+			registry.ResolveReturns("", errors.New("registry down"))
+			resolved, err := resolver.Resolve(ctx, "some-ns", source)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolved.Digest).To(Equal(digest1))
+		})
+	})
+})
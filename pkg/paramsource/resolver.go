@@ -0,0 +1,220 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package paramsource resolves the params of workloads and deliverables that
+// read their value from a ConfigMap or Secret, or decrypt it from a sealed
+// payload, and remembers which objects each owner read, so that changes to
+// those objects requeue their owners.
+package paramsource
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+// Kinds are the kinds of the objects params can read their value from.
+var Kinds = []string{"ConfigMap", "Secret"}
+
+// Resolver reads the values of params from the objects they refer to.
+type Resolver struct {
+	repo repository.Repository
+
+	mu     sync.Mutex
+	inputs map[types.NamespacedName][]v1alpha1.ObjectReference
+}
+
+func NewResolver(repo repository.Repository) *Resolver {
+	return &Resolver{
+		repo:   repo,
+		inputs: map[types.NamespacedName][]v1alpha1.ObjectReference{},
+	}
+}
+
+// Resolve returns params with the value of each one that sets valueFrom read
+// from its object in namespace, and the objects it read, including those
+// not found. A param whose optional key is not found is left out, so that
+// the default of the param applies.
+func (r *Resolver) Resolve(ctx context.Context, namespace string, params []v1alpha1.Param) ([]v1alpha1.Param, []v1alpha1.ObjectReference, error) {
+	var (
+		resolved []v1alpha1.Param
+		inputs   []v1alpha1.ObjectReference
+	)
+
+	for _, param := range params {
+		if param.ValueFrom == nil {
+			resolved = append(resolved, param)
+			continue
+		}
+
+		ref, key, optional, err := selected(namespace, param)
+		if err != nil {
+			return nil, inputs, err
+		}
+		inputs = appendRef(inputs, ref)
+
+		value, found, err := r.read(ctx, ref, key, param.ValueFrom.Sealed != nil)
+		if err != nil {
+			return nil, inputs, fmt.Errorf("param '%s': %w", param.Name, err)
+		}
+		if !found {
+			if optional {
+				continue
+			}
+			return nil, inputs, fmt.Errorf("param '%s': key '%s' of %s '%s/%s' not found", param.Name, key, ref.Kind, ref.Namespace, ref.Name)
+		}
+
+		var raw []byte
+		if param.ValueFrom.Sealed != nil {
+			raw, err = unseal(ctx, namespace, param.ValueFrom.Sealed, value)
+		} else {
+			raw, err = json.Marshal(value)
+		}
+		if err != nil {
+			return nil, inputs, fmt.Errorf("param '%s': %w", param.Name, err)
+		}
+		param.Value.Raw = raw
+		param.ValueFrom = nil
+		resolved = append(resolved, param)
+	}
+
+	return resolved, inputs, nil
+}
+
+// Track records the objects the params of owner were read from, in place of
+// those recorded before.
+func (r *Resolver) Track(owner types.NamespacedName, inputs []v1alpha1.ObjectReference) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(inputs) == 0 {
+		delete(r.inputs, owner)
+		return
+	}
+	r.inputs[owner] = inputs
+}
+
+// Requests maps a ConfigMap or Secret to the owners whose params were last
+// read from it.
+func (r *Resolver) Requests(obj client.Object) []reconcile.Request {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var requests []reconcile.Request
+	for owner, inputs := range r.inputs {
+		for _, ref := range inputs {
+			if ref.Kind == kind && ref.Namespace == obj.GetNamespace() && ref.Name == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: owner})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// read returns the value at key of the object ref refers to, and whether
+// both the object and the key were found. A sealing key is only read from a
+// Secret labelled as one.
+func (r *Resolver) read(ctx context.Context, ref v1alpha1.ObjectReference, key string, sealingKey bool) (string, bool, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(ref.APIVersion)
+	obj.SetKind(ref.Kind)
+	obj.SetNamespace(ref.Namespace)
+	obj.SetName(ref.Name)
+	if err := r.repo.GetUnstructured(ctx, obj); err != nil {
+		if kerrors.IsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("get %s '%s/%s': %w", ref.Kind, ref.Namespace, ref.Name, err)
+	}
+
+	if sealingKey && obj.GetLabels()[v1alpha1.SealingKeyLabel] != "true" {
+		return "", false, fmt.Errorf("%s '%s/%s' is not labelled %s: \"true\", and may not be used to unseal params", ref.Kind, ref.Namespace, ref.Name, v1alpha1.SealingKeyLabel)
+	}
+
+	value, found, _ := unstructured.NestedString(obj.Object, "data", key)
+	if !found || ref.Kind == "ConfigMap" {
+		return value, found, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", false, fmt.Errorf("%s '%s/%s' key '%s': %w", ref.Kind, ref.Namespace, ref.Name, key, err)
+	}
+	return string(decoded), true, nil
+}
+
+// selected returns the object and key the valueFrom of param selects in
+// namespace, and whether the key is optional. For a sealed value, that is
+// the key that decrypts it, which only the controller's namespace holds.
+func selected(namespace string, param v1alpha1.Param) (v1alpha1.ObjectReference, string, bool, error) {
+	source := param.ValueFrom
+	ref := v1alpha1.ObjectReference{APIVersion: "v1", Namespace: namespace}
+
+	set := 0
+	if source.ConfigMapKeyRef != nil {
+		set++
+	}
+	if source.SecretKeyRef != nil {
+		set++
+	}
+	if source.Sealed != nil {
+		set++
+	}
+
+	switch {
+	case set > 1:
+		return ref, "", false, fmt.Errorf("param '%s' must set only one of valueFrom.configMapKeyRef, valueFrom.secretKeyRef and valueFrom.sealed", param.Name)
+	case len(param.Value.Raw) > 0:
+		return ref, "", false, fmt.Errorf("param '%s' cannot set both value and valueFrom", param.Name)
+	case source.ConfigMapKeyRef != nil:
+		ref.Kind, ref.Name = "ConfigMap", source.ConfigMapKeyRef.Name
+		return ref, source.ConfigMapKeyRef.Key, isTrue(source.ConfigMapKeyRef.Optional), nil
+	case source.SecretKeyRef != nil:
+		ref.Kind, ref.Name = "Secret", source.SecretKeyRef.Name
+		return ref, source.SecretKeyRef.Key, isTrue(source.SecretKeyRef.Optional), nil
+	case source.Sealed != nil:
+		ref.Namespace = v1alpha1.ControllerNamespace
+		ref.Kind, ref.Name = "Secret", source.Sealed.KeyRef.Name
+		return ref, source.Sealed.KeyRef.Key, isTrue(source.Sealed.KeyRef.Optional), nil
+	default:
+		return ref, "", false, fmt.Errorf("param '%s' must set valueFrom.configMapKeyRef, valueFrom.secretKeyRef or valueFrom.sealed", param.Name)
+	}
+}
+
+func appendRef(refs []v1alpha1.ObjectReference, ref v1alpha1.ObjectReference) []v1alpha1.ObjectReference {
+	for _, r := range refs {
+		if r == ref {
+			return refs
+		}
+	}
+	return append(refs, ref)
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
@@ -0,0 +1,359 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paramsource_test
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/paramsource"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+var _ = Describe("Resolver", func() {
+	var (
+		ctx      context.Context
+		repo     *repositoryfakes.FakeRepository
+		resolver *paramsource.Resolver
+		objects  map[string]map[string]interface{}
+		labels   map[string]map[string]string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		objects = map[string]map[string]interface{}{
+			"ConfigMap/some-ns/settings": {"log-level": "debug"},
+			"Secret/some-ns/credentials": {"token": base64.StdEncoding.EncodeToString([]byte("s3cr3t"))},
+		}
+		labels = map[string]map[string]string{}
+		repo = &repositoryfakes.FakeRepository{}
+		repo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+			path := obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+			data, ok := objects[path]
+			if !ok {
+				return kerrors.NewNotFound(schema.GroupResource{Resource: obj.GetKind()}, obj.GetName())
+			}
+			obj.Object["data"] = data
+			obj.SetLabels(labels[path])
+			return nil
+		}
+		resolver = paramsource.NewResolver(repo)
+	})
+
+	configMapParam := func(name, configMap, key string) v1alpha1.Param {
+		return v1alpha1.Param{Name: name, ValueFrom: &v1alpha1.ParamValueSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: configMap}, Key: key},
+		}}
+	}
+
+	secretParam := func(name, secret, key string) v1alpha1.Param {
+		return v1alpha1.Param{Name: name, ValueFrom: &v1alpha1.ParamValueSource{
+			SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secret}, Key: key},
+		}}
+	}
+
+	Describe("Resolve", func() {
+		It("reads the values of params from ConfigMaps and Secrets", func() {
+			params := []v1alpha1.Param{
+				{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`3`)}},
+				configMapParam("log-level", "settings", "log-level"),
+				secretParam("token", "credentials", "token"),
+			}
+
+			resolved, inputs, err := resolver.Resolve(ctx, "some-ns", params)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolved).To(Equal([]v1alpha1.Param{
+				{Name: "replicas", Value: apiextensionsv1.JSON{Raw: []byte(`3`)}},
+				{Name: "log-level", Value: apiextensionsv1.JSON{Raw: []byte(`"debug"`)}},
+				{Name: "token", Value: apiextensionsv1.JSON{Raw: []byte(`"s3cr3t"`)}},
+			}))
+			Expect(inputs).To(Equal([]v1alpha1.ObjectReference{
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "some-ns", Name: "settings"},
+				{APIVersion: "v1", Kind: "Secret", Namespace: "some-ns", Name: "credentials"},
+			}))
+		})
+
+		It("does not change the params it is given", func() {
+			params := []v1alpha1.Param{configMapParam("log-level", "settings", "log-level")}
+
+			_, _, err := resolver.Resolve(ctx, "some-ns", params)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(params[0].ValueFrom).NotTo(BeNil())
+			Expect(params[0].Value.Raw).To(BeNil())
+		})
+
+		It("reports each object once", func() {
+			params := []v1alpha1.Param{
+				configMapParam("log-level", "settings", "log-level"),
+				configMapParam("other", "settings", "log-level"),
+			}
+
+			_, inputs, err := resolver.Resolve(ctx, "some-ns", params)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inputs).To(HaveLen(1))
+		})
+
+		Context("when the object is not found", func() {
+			It("fails, reporting the object so that its creation is noticed", func() {
+				params := []v1alpha1.Param{configMapParam("log-level", "missing", "log-level")}
+
+				_, inputs, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).To(MatchError("param 'log-level': key 'log-level' of ConfigMap 'some-ns/missing' not found"))
+				Expect(inputs).To(Equal([]v1alpha1.ObjectReference{
+					{APIVersion: "v1", Kind: "ConfigMap", Namespace: "some-ns", Name: "missing"},
+				}))
+			})
+		})
+
+		Context("when the key is not found", func() {
+			It("fails", func() {
+				params := []v1alpha1.Param{secretParam("token", "credentials", "password")}
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).To(MatchError("param 'token': key 'password' of Secret 'some-ns/credentials' not found"))
+			})
+
+			Context("and the key is optional", func() {
+				It("leaves the param out", func() {
+					param := secretParam("token", "credentials", "password")
+					optional := true
+					param.ValueFrom.SecretKeyRef.Optional = &optional
+
+					resolved, inputs, err := resolver.Resolve(ctx, "some-ns", []v1alpha1.Param{param})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resolved).To(BeEmpty())
+					Expect(inputs).To(HaveLen(1))
+				})
+			})
+		})
+
+		Context("when the object cannot be read", func() {
+			It("fails", func() {
+				repo.GetUnstructuredStub = nil
+				repo.GetUnstructuredReturns(errors.New("forbidden"))
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", []v1alpha1.Param{configMapParam("log-level", "settings", "log-level")})
+				Expect(err).To(MatchError("param 'log-level': get ConfigMap 'some-ns/settings': forbidden"))
+			})
+		})
+
+		Context("when a param sets both value and valueFrom", func() {
+			It("fails", func() {
+				param := configMapParam("log-level", "settings", "log-level")
+				param.Value = apiextensionsv1.JSON{Raw: []byte(`"info"`)}
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", []v1alpha1.Param{param})
+				Expect(err).To(MatchError("param 'log-level' cannot set both value and valueFrom"))
+			})
+		})
+
+		Context("when valueFrom selects nothing", func() {
+			It("fails", func() {
+				param := v1alpha1.Param{Name: "log-level", ValueFrom: &v1alpha1.ParamValueSource{}}
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", []v1alpha1.Param{param})
+				Expect(err).To(MatchError("param 'log-level' must set valueFrom.configMapKeyRef, valueFrom.secretKeyRef or valueFrom.sealed"))
+			})
+		})
+
+		Context("when valueFrom selects more than one source", func() {
+			It("fails", func() {
+				param := configMapParam("log-level", "settings", "log-level")
+				param.ValueFrom.SecretKeyRef = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}, Key: "token"}
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", []v1alpha1.Param{param})
+				Expect(err).To(MatchError("param 'log-level' must set only one of valueFrom.configMapKeyRef, valueFrom.secretKeyRef and valueFrom.sealed"))
+			})
+		})
+
+		Context("when the value is sealed", func() {
+			var key *rsa.PrivateKey
+
+			// seal encrypts value the way kubeseal --raw does for label
+			seal := func(value, label string) string {
+				sessionKey := make([]byte, 32)
+				_, err := rand.Read(sessionKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &key.PublicKey, sessionKey, []byte(label))
+				Expect(err).NotTo(HaveOccurred())
+
+				block, err := aes.NewCipher(sessionKey)
+				Expect(err).NotTo(HaveOccurred())
+				aead, err := cipher.NewGCM(block)
+				Expect(err).NotTo(HaveOccurred())
+
+				ciphertext := make([]byte, 2)
+				binary.BigEndian.PutUint16(ciphertext, uint16(len(encryptedKey)))
+				ciphertext = append(ciphertext, encryptedKey...)
+				ciphertext = aead.Seal(ciphertext, make([]byte, aead.NonceSize()), []byte(value), nil)
+				return base64.StdEncoding.EncodeToString(ciphertext)
+			}
+
+			sealedParam := func(name, format, value string) v1alpha1.Param {
+				return v1alpha1.Param{Name: name, ValueFrom: &v1alpha1.ParamValueSource{
+					Sealed: &v1alpha1.SealedValueSource{
+						Format:         format,
+						EncryptedValue: value,
+						KeyRef:         corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "sealing-key"}, Key: "tls.key"},
+					},
+				}}
+			}
+
+			BeforeEach(func() {
+				var err error
+				key, err = rsa.GenerateKey(rand.Reader, 2048)
+				Expect(err).NotTo(HaveOccurred())
+
+				keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+				objects["Secret/cartographer-system/sealing-key"] = map[string]interface{}{"tls.key": base64.StdEncoding.EncodeToString(keyPEM)}
+				labels["Secret/cartographer-system/sealing-key"] = map[string]string{v1alpha1.SealingKeyLabel: "true"}
+			})
+
+			It("decrypts it with the key, reporting the Secret of the key", func() {
+				params := []v1alpha1.Param{sealedParam("token", v1alpha1.SealedSecretsFormat, seal("s3cr3t", "some-ns"))}
+
+				resolved, inputs, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resolved).To(Equal([]v1alpha1.Param{
+					{Name: "token", Value: apiextensionsv1.JSON{Raw: []byte(`"s3cr3t"`)}},
+				}))
+				Expect(inputs).To(Equal([]v1alpha1.ObjectReference{
+					{APIVersion: "v1", Kind: "Secret", Namespace: "cartographer-system", Name: "sealing-key"},
+				}))
+			})
+
+			It("never reads the key from the namespace of the params", func() {
+				objects["Secret/some-ns/sealing-key"] = objects["Secret/cartographer-system/sealing-key"]
+				delete(objects, "Secret/cartographer-system/sealing-key")
+				params := []v1alpha1.Param{sealedParam("token", v1alpha1.SealedSecretsFormat, seal("s3cr3t", "some-ns"))}
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).To(MatchError("param 'token': key 'tls.key' of Secret 'cartographer-system/sealing-key' not found"))
+			})
+
+			It("never reads the key from a Secret not labelled as a sealing key", func() {
+				delete(labels, "Secret/cartographer-system/sealing-key")
+				params := []v1alpha1.Param{sealedParam("token", v1alpha1.SealedSecretsFormat, seal("s3cr3t", "some-ns"))}
+
+				_, inputs, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).To(MatchError(`param 'token': Secret 'cartographer-system/sealing-key' is not labelled carto.run/sealing-key: "true", and may not be used to unseal params`))
+				Expect(inputs).To(HaveLen(1))
+			})
+
+			It("decrypts cluster-wide values", func() {
+				param := sealedParam("token", v1alpha1.SealedSecretsFormat, seal("s3cr3t", ""))
+				param.ValueFrom.Sealed.Scope = v1alpha1.ClusterWideSealScope
+
+				resolved, _, err := resolver.Resolve(ctx, "some-ns", []v1alpha1.Param{param})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resolved[0].Value.Raw).To(Equal([]byte(`"s3cr3t"`)))
+			})
+
+			It("fails for values sealed for another namespace", func() {
+				params := []v1alpha1.Param{sealedParam("token", v1alpha1.SealedSecretsFormat, seal("s3cr3t", "other-ns"))}
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).To(MatchError(ContainSubstring("param 'token': unseal sealedSecrets value: decrypt session key")))
+			})
+
+			It("fails when the key is not a private key", func() {
+				objects["Secret/cartographer-system/sealing-key"] = map[string]interface{}{"tls.key": base64.StdEncoding.EncodeToString([]byte("nope"))}
+				params := []v1alpha1.Param{sealedParam("token", v1alpha1.SealedSecretsFormat, seal("s3cr3t", "some-ns"))}
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).To(MatchError("param 'token': unseal sealedSecrets value: private key is not PEM encoded"))
+			})
+
+			It("fails when the key is not found", func() {
+				delete(objects, "Secret/cartographer-system/sealing-key")
+				params := []v1alpha1.Param{sealedParam("token", v1alpha1.SealedSecretsFormat, seal("s3cr3t", "some-ns"))}
+
+				_, inputs, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).To(MatchError("param 'token': key 'tls.key' of Secret 'cartographer-system/sealing-key' not found"))
+				Expect(inputs).To(HaveLen(1))
+			})
+
+			It("fails for sops documents that do not decrypt", func() {
+				params := []v1alpha1.Param{sealedParam("token", v1alpha1.SOPSFormat, "not: encrypted")}
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).To(MatchError(ContainSubstring("param 'token': decrypt sops value")))
+			})
+
+			It("fails for unknown formats", func() {
+				params := []v1alpha1.Param{sealedParam("token", "vault", "whatever")}
+
+				_, _, err := resolver.Resolve(ctx, "some-ns", params)
+				Expect(err).To(MatchError("param 'token': unknown sealed format 'vault', expected sealedSecrets or sops"))
+			})
+		})
+	})
+
+	Describe("Requests", func() {
+		var owner types.NamespacedName
+
+		BeforeEach(func() {
+			owner = types.NamespacedName{Namespace: "some-ns", Name: "my-workload"}
+			resolver.Track(owner, []v1alpha1.ObjectReference{
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "some-ns", Name: "settings"},
+			})
+		})
+
+		object := func(kind, namespace, name string) *unstructured.Unstructured {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion("v1")
+			obj.SetKind(kind)
+			obj.SetNamespace(namespace)
+			obj.SetName(name)
+			return obj
+		}
+
+		It("maps an object to the owners that read it", func() {
+			Expect(resolver.Requests(object("ConfigMap", "some-ns", "settings"))).To(Equal([]reconcile.Request{{NamespacedName: owner}}))
+		})
+
+		It("does not map other objects", func() {
+			Expect(resolver.Requests(object("Secret", "some-ns", "settings"))).To(BeEmpty())
+			Expect(resolver.Requests(object("ConfigMap", "other-ns", "settings"))).To(BeEmpty())
+			Expect(resolver.Requests(object("ConfigMap", "some-ns", "other"))).To(BeEmpty())
+		})
+
+		It("forgets the objects an owner no longer reads", func() {
+			resolver.Track(owner, nil)
+			Expect(resolver.Requests(object("ConfigMap", "some-ns", "settings"))).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,197 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paramsource
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/utils"
+)
+
+// unseal returns the JSON value of the param sealed decrypts to with key,
+// the private key read for it from namespace.
+func unseal(ctx context.Context, namespace string, sealed *v1alpha1.SealedValueSource, key string) ([]byte, error) {
+	switch sealed.Format {
+	case v1alpha1.SealedSecretsFormat:
+		label, err := sealLabel(namespace, sealed.Scope)
+		if err != nil {
+			return nil, err
+		}
+		value, err := openSealedSecret(sealed.EncryptedValue, key, label)
+		if err != nil {
+			return nil, fmt.Errorf("unseal sealedSecrets value: %w", err)
+		}
+		return json.Marshal(string(value))
+	case v1alpha1.SOPSFormat:
+		document, err := decryptSOPS(ctx, sealed.EncryptedValue, key)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt sops value: %w", err)
+		}
+		return boundValue(document, namespace)
+	default:
+		return nil, fmt.Errorf("unknown sealed format '%s', expected %s or %s", sealed.Format, v1alpha1.SealedSecretsFormat, v1alpha1.SOPSFormat)
+	}
+}
+
+// sealLabel returns the label kubeseal binds a value sealed for scope in
+// namespace to.
+func sealLabel(namespace, scope string) ([]byte, error) {
+	switch scope {
+	case "", v1alpha1.NamespaceWideSealScope:
+		return []byte(namespace), nil
+	case v1alpha1.ClusterWideSealScope:
+		return []byte{}, nil
+	default:
+		return nil, fmt.Errorf("unknown sealed scope '%s', expected %s or %s", scope, v1alpha1.NamespaceWideSealScope, v1alpha1.ClusterWideSealScope)
+	}
+}
+
+// boundValue returns the value of a decrypted sops document, refusing a
+// document encrypted for another namespace. sops authenticates the whole
+// document, so the namespace it holds cannot be changed without the key.
+func boundValue(document []byte, namespace string) ([]byte, error) {
+	var bound struct {
+		Namespace string          `json:"namespace"`
+		Value     json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(document, &bound); err != nil {
+		return nil, fmt.Errorf("sops value must be a document with a namespace and a value: %w", err)
+	}
+	if bound.Namespace != namespace {
+		return nil, fmt.Errorf("sops value was encrypted for namespace '%s', not '%s'", bound.Namespace, namespace)
+	}
+	if bound.Value == nil {
+		return nil, fmt.Errorf("sops value has no value")
+	}
+	return bound.Value, nil
+}
+
+// openSealedSecret decrypts the base64 encoded value kubeseal sealed with
+// the public key of privateKey: a two byte length, the session key
+// encrypted with RSA-OAEP, then the value encrypted with AES-GCM under the
+// session key.
+func openSealedSecret(value, privateKey string, label []byte) ([]byte, error) {
+	key, err := parseRSAKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("decode encrypted value: %w", err)
+	}
+	if len(ciphertext) < 2 {
+		return nil, fmt.Errorf("encrypted value is too short")
+	}
+	sessionKeyLen := int(binary.BigEndian.Uint16(ciphertext))
+	if len(ciphertext) < sessionKeyLen+2 {
+		return nil, fmt.Errorf("encrypted value is too short")
+	}
+
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:sessionKeyLen+2], label)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt session key: %w", err)
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// the session key is used once, so kubeseal leaves the nonce zero
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, ciphertext[sessionKeyLen+2:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+func parseRSAKey(privateKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// decryptSOPS decrypts a sops document with the age identity, returning
+// it as JSON.
+func decryptSOPS(ctx context.Context, document, identity string) ([]byte, error) {
+	// limit execution duration so that a hung decryption does not hold up the reconcile
+	parentCtx := ctx
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sops := utils.KoDataBinary("sops")
+
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+
+	cmd := exec.CommandContext(ctx, sops, "--decrypt", "--input-type", "yaml", "--output-type", "json", "/dev/stdin")
+	cmd.Env = append(os.Environ(), "SOPS_AGE_KEY="+identity)
+	cmd.Stdin = strings.NewReader(document)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if parentCtx.Err() != nil {
+			return nil, fmt.Errorf("cancelled: %w", parentCtx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &value); err != nil {
+		return nil, fmt.Errorf("sops returned invalid json: %w", err)
+	}
+	return json.Marshal(value)
+}
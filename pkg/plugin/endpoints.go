@@ -0,0 +1,58 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Endpoints are the addresses, as host:port, of the realizer plugins by
+// name. It is a flag.Value that can be set repeatedly, each time as
+// name=host:port, such as terraform=terraform-runner.infra:9000.
+type Endpoints map[string]string
+
+func (e *Endpoints) String() string {
+	if e == nil {
+		return ""
+	}
+	var settings []string
+	for name, address := range *e {
+		settings = append(settings, name+"="+address)
+	}
+	sort.Strings(settings)
+	return strings.Join(settings, " ")
+}
+
+// Set parses a name=host:port setting into e, replacing the address of the
+// plugin if it was already set.
+func (e *Endpoints) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("'%s' is not a name=host:port setting", value)
+	}
+	if errs := validation.IsDNS1123Label(parts[0]); len(errs) > 0 {
+		return fmt.Errorf("plugin name '%s' is invalid: %s", parts[0], strings.Join(errs, ", "))
+	}
+
+	if *e == nil {
+		*e = Endpoints{}
+	}
+	(*e)[parts[0]] = parts[1]
+	return nil
+}
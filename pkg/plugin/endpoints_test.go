@@ -0,0 +1,59 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+)
+
+var _ = Describe("Endpoints", func() {
+	var endpoints plugin.Endpoints
+
+	BeforeEach(func() {
+		endpoints = nil
+	})
+
+	It("sets the address of each named plugin", func() {
+		Expect(endpoints.Set("terraform=terraform-runner.infra:9000")).To(Succeed())
+		Expect(endpoints.Set("pulumi=pulumi:9000")).To(Succeed())
+
+		Expect(endpoints).To(Equal(plugin.Endpoints{
+			"terraform": "terraform-runner.infra:9000",
+			"pulumi":    "pulumi:9000",
+		}))
+		Expect(endpoints.String()).To(Equal("pulumi=pulumi:9000 terraform=terraform-runner.infra:9000"))
+	})
+
+	It("replaces the address of a plugin set again", func() {
+		Expect(endpoints.Set("terraform=one:9000")).To(Succeed())
+		Expect(endpoints.Set("terraform=two:9000")).To(Succeed())
+
+		Expect(endpoints).To(Equal(plugin.Endpoints{"terraform": "two:9000"}))
+	})
+
+	It("rejects settings without an address", func() {
+		Expect(endpoints.Set("terraform")).To(MatchError("'terraform' is not a name=host:port setting"))
+		Expect(endpoints.Set("terraform=")).To(MatchError("'terraform=' is not a name=host:port setting"))
+	})
+
+	It("rejects invalid names", func() {
+		err := endpoints.Set("Terraform_Runner=host:9000")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("plugin name 'Terraform_Runner' is invalid: "))
+	})
+})
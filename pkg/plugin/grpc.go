@@ -0,0 +1,192 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName is the gRPC service plugins serve, as described by
+// realizer.proto. Requests and responses are google.protobuf.Struct
+// messages holding a Request and a Response.
+const ServiceName = "carto.run.realizer.v1.Realizer"
+
+const realizeMethod = "/" + ServiceName + "/Realize"
+
+// CallTimeout bounds each call to a plugin. Plugins doing long work should
+// answer Running and carry on in the background.
+const CallTimeout = 30 * time.Second
+
+// Plugins is a Realizer calling the plugins registered with the controller
+// over gRPC.
+type Plugins struct {
+	conns map[string]grpc.ClientConnInterface
+}
+
+var _ Realizer = &Plugins{}
+
+// New returns Plugins calling each plugin through its connection, by name.
+func New(conns map[string]grpc.ClientConnInterface) *Plugins {
+	return &Plugins{conns: conns}
+}
+
+// Dial connects to the plugins at endpoints, over TLS unless insecure is
+// set. Connections are established lazily, so plugins that are not up yet
+// are reported when they are first called.
+func Dial(endpoints Endpoints, insecureTransport bool) (*Plugins, error) {
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if insecureTransport {
+		creds = insecure.NewCredentials()
+	}
+
+	conns := map[string]grpc.ClientConnInterface{}
+	for name, address := range endpoints {
+		conn, err := grpc.Dial(address, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("dial realizer plugin '%s' at '%s': %w", name, address, err)
+		}
+		conns[name] = conn
+	}
+	return New(conns), nil
+}
+
+// Close closes the connections to the plugins.
+func (p *Plugins) Close() error {
+	var names []string
+	for name := range p.conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if conn, ok := p.conns[name].(*grpc.ClientConn); ok {
+			if err := conn.Close(); err != nil {
+				return fmt.Errorf("close realizer plugin '%s': %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Plugins) Realize(ctx context.Context, request Request) (map[string]interface{}, error) {
+	conn, ok := p.conns[request.Plugin]
+	if !ok {
+		return nil, fmt.Errorf("realizer plugin '%s' is not registered", request.Plugin)
+	}
+
+	in, err := toStruct(request)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, CallTimeout)
+	defer cancel()
+
+	out := &structpb.Struct{}
+	if err := conn.Invoke(ctx, realizeMethod, in, out); err != nil {
+		return nil, fmt.Errorf("call realizer plugin '%s': %w", request.Plugin, err)
+	}
+
+	response := Response{}
+	if err := fromStruct(out, &response); err != nil {
+		return nil, fmt.Errorf("decode response of realizer plugin '%s': %w", request.Plugin, err)
+	}
+
+	switch response.Phase {
+	case ReadyPhase:
+		return response.Outputs, nil
+	case RunningPhase:
+		return nil, RunningError{Plugin: request.Plugin, Message: response.Message}
+	case FailedPhase:
+		return nil, FailedError{Plugin: request.Plugin, Message: response.Message}
+	}
+	return nil, fmt.Errorf("realizer plugin '%s' answered unknown phase '%s', expected Ready, Running or Failed", request.Plugin, response.Phase)
+}
+
+// RealizerServer is implemented by plugins written in Go, and served with
+// RegisterRealizerServer.
+type RealizerServer interface {
+	Realize(ctx context.Context, request *Request) (*Response, error)
+}
+
+// RegisterRealizerServer serves impl as the Realizer service of s.
+func RegisterRealizerServer(s *grpc.Server, impl RealizerServer) {
+	s.RegisterService(&realizerServiceDesc, impl)
+}
+
+var realizerServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*RealizerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Realize", Handler: realizeHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "realizer.proto",
+}
+
+func realizeHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &structpb.Struct{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handle := func(ctx context.Context, req interface{}) (interface{}, error) {
+		request := &Request{}
+		if err := fromStruct(req.(*structpb.Struct), request); err != nil {
+			return nil, fmt.Errorf("decode request: %w", err)
+		}
+		response, err := srv.(RealizerServer).Realize(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		return toStruct(response)
+	}
+
+	if interceptor == nil {
+		return handle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: realizeMethod}
+	return interceptor(ctx, in, info, handle)
+}
+
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
+
+func fromStruct(s *structpb.Struct, v interface{}) error {
+	raw, err := json.Marshal(s.AsMap())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
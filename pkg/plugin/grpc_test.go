@@ -0,0 +1,140 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+)
+
+type realizerServer struct {
+	requests []*plugin.Request
+	response *plugin.Response
+	err      error
+}
+
+func (s *realizerServer) Realize(_ context.Context, request *plugin.Request) (*plugin.Response, error) {
+	s.requests = append(s.requests, request)
+	return s.response, s.err
+}
+
+var _ = Describe("Plugins", func() {
+	var (
+		ctx     context.Context
+		server  *realizerServer
+		plugins *plugin.Plugins
+		request plugin.Request
+		stop    func()
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = &realizerServer{response: &plugin.Response{Phase: plugin.ReadyPhase}}
+
+		listener := bufconn.Listen(1024 * 1024)
+		grpcServer := grpc.NewServer()
+		plugin.RegisterRealizerServer(grpcServer, server)
+		go func() {
+			_ = grpcServer.Serve(listener)
+		}()
+
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		plugins = plugin.New(map[string]grpc.ClientConnInterface{"terraform": conn})
+		stop = func() {
+			Expect(plugins.Close()).To(Succeed())
+			grpcServer.Stop()
+		}
+
+		request = plugin.Request{
+			Plugin:   "terraform",
+			Resource: "database",
+			Objects:  []map[string]interface{}{{"kind": "Database"}},
+			Digest:   "abc",
+		}
+	})
+
+	AfterEach(func() {
+		stop()
+	})
+
+	It("sends the request to the plugin", func() {
+		_, err := plugins.Realize(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(server.requests).To(HaveLen(1))
+		Expect(*server.requests[0]).To(Equal(request))
+	})
+
+	It("returns the outputs of a ready plugin", func() {
+		server.response.Outputs = map[string]interface{}{"url": "postgres://db", "port": 5432.0}
+
+		outputs, err := plugins.Realize(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outputs).To(Equal(map[string]interface{}{"url": "postgres://db", "port": 5432.0}))
+	})
+
+	It("returns a RunningError while the plugin is running", func() {
+		server.response = &plugin.Response{Phase: plugin.RunningPhase, Message: "applying"}
+
+		_, err := plugins.Realize(ctx, request)
+		Expect(errors.As(err, &plugin.RunningError{})).To(BeTrue())
+		Expect(err).To(MatchError("plugin 'terraform' is realizing the objects: applying"))
+	})
+
+	It("returns a FailedError when the plugin failed", func() {
+		server.response = &plugin.Response{Phase: plugin.FailedPhase, Message: "quota exceeded"}
+
+		_, err := plugins.Realize(ctx, request)
+		Expect(errors.As(err, &plugin.FailedError{})).To(BeTrue())
+		Expect(err).To(MatchError("plugin 'terraform' failed: quota exceeded"))
+	})
+
+	It("errors when the plugin answers an unknown phase", func() {
+		server.response = &plugin.Response{Phase: "Pending"}
+
+		_, err := plugins.Realize(ctx, request)
+		Expect(err).To(MatchError("realizer plugin 'terraform' answered unknown phase 'Pending', expected Ready, Running or Failed"))
+	})
+
+	It("errors when the call fails", func() {
+		server.err = errors.New("boom")
+
+		_, err := plugins.Realize(ctx, request)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("call realizer plugin 'terraform': "))
+		Expect(err.Error()).To(ContainSubstring("boom"))
+	})
+
+	It("errors when the plugin is not registered", func() {
+		request.Plugin = "pulumi"
+
+		_, err := plugins.Realize(ctx, request)
+		Expect(err).To(MatchError("realizer plugin 'pulumi' is not registered"))
+	})
+})
@@ -0,0 +1,152 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugin realizes supply chain and delivery resources out of tree:
+// the objects stamped from a template naming a plugin are sent, over gRPC,
+// to an external process, such as a Terraform runner, instead of being
+// submitted to the cluster, and the outputs it returns flow on through the
+// blueprint.
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+// Phase is how far a plugin has got realizing the objects of a request.
+type Phase string
+
+const (
+	// ReadyPhase means the objects are realized and the outputs current.
+	ReadyPhase Phase = "Ready"
+	// RunningPhase means the plugin is still realizing the objects. The
+	// request is sent again on the next reconcile.
+	RunningPhase Phase = "Running"
+	// FailedPhase means the objects cannot be realized as they are.
+	FailedPhase Phase = "Failed"
+)
+
+// Request asks a plugin to realize the objects stamped for a resource. The
+// same request is sent on every reconcile of the owner, so plugins should
+// be idempotent, and can tell from the digest whether the objects changed
+// since they last realized them.
+type Request struct {
+	// Plugin is the name the plugin is registered under.
+	Plugin string `json:"plugin"`
+	// Owner is the workload or deliverable the objects are stamped for.
+	Owner v1alpha1.ObjectReference `json:"owner"`
+	// Resource is the name of the resource in the blueprint.
+	Resource string `json:"resource"`
+	// Objects are the objects stamped from the template.
+	Objects []map[string]interface{} `json:"objects"`
+	// Digest is the hex encoded sha256 of the objects.
+	Digest string `json:"digest"`
+}
+
+// Response is the answer of a plugin to a Request.
+type Response struct {
+	Phase Phase `json:"phase"`
+	// Message tells why the plugin is running or failed.
+	Message string `json:"message,omitempty"`
+	// Outputs are what the template's output paths are evaluated against
+	// once the plugin is ready.
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+}
+
+//counterfeiter:generate . Realizer
+
+// Realizer has plugins realize the objects of requests.
+type Realizer interface {
+	// Realize returns the outputs of the plugin named by the request once
+	// it is ready, a RunningError while it is still realizing the objects
+	// and a FailedError when it cannot.
+	Realize(ctx context.Context, request Request) (map[string]interface{}, error)
+}
+
+// RunningError is returned while a plugin is realizing the objects.
+type RunningError struct {
+	Plugin  string
+	Message string
+}
+
+func (e RunningError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("plugin '%s' is realizing the objects", e.Plugin)
+	}
+	return fmt.Sprintf("plugin '%s' is realizing the objects: %s", e.Plugin, e.Message)
+}
+
+// FailedError is returned when a plugin cannot realize the objects.
+type FailedError struct {
+	Plugin  string
+	Message string
+}
+
+func (e FailedError) Error() string {
+	return fmt.Sprintf("plugin '%s' failed: %s", e.Plugin, e.Message)
+}
+
+// Results has the named plugin realize the objects stamped for resource on
+// behalf of owner, using the Realizer in ctx, and returns its outputs as the
+// object the template's outputs are read from.
+func Results(ctx context.Context, name string, owner v1alpha1.ObjectReference, resource string, objects []*unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	realizer := FromContext(ctx)
+	if realizer == nil {
+		return nil, fmt.Errorf("realizer plugin '%s' is not registered", name)
+	}
+
+	request := Request{
+		Plugin:   name,
+		Owner:    owner,
+		Resource: resource,
+	}
+	for _, object := range objects {
+		request.Objects = append(request.Objects, object.Object)
+	}
+	raw, err := json.Marshal(request.Objects)
+	if err != nil {
+		return nil, fmt.Errorf("marshal objects: %w", err)
+	}
+	request.Digest = fmt.Sprintf("%x", sha256.Sum256(raw))
+
+	outputs, err := realizer.Realize(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if outputs == nil {
+		outputs = map[string]interface{}{}
+	}
+	return &unstructured.Unstructured{Object: outputs}, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying realizer.
+func NewContext(ctx context.Context, realizer Realizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, realizer)
+}
+
+// FromContext returns the Realizer carried by ctx, or nil.
+func FromContext(ctx context.Context) Realizer {
+	realizer, _ := ctx.Value(contextKey{}).(Realizer)
+	return realizer
+}
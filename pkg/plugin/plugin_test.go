@@ -0,0 +1,99 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin/pluginfakes"
+)
+
+var _ = Describe("Results", func() {
+	var (
+		ctx      context.Context
+		realizer *pluginfakes.FakeRealizer
+		owner    v1alpha1.ObjectReference
+		objects  []*unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		realizer = &pluginfakes.FakeRealizer{}
+		ctx = plugin.NewContext(context.Background(), realizer)
+		owner = v1alpha1.ObjectReference{APIVersion: "carto.run/v1alpha1", Kind: "Workload", Namespace: "dev", Name: "app"}
+		objects = []*unstructured.Unstructured{
+			{Object: map[string]interface{}{"kind": "Database", "spec": map[string]interface{}{"size": "small"}}},
+		}
+	})
+
+	It("sends the stamped objects to the plugin, with a digest of them", func() {
+		_, err := plugin.Results(ctx, "terraform", owner, "database", objects)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(realizer.RealizeCallCount()).To(Equal(1))
+		_, request := realizer.RealizeArgsForCall(0)
+		Expect(request.Plugin).To(Equal("terraform"))
+		Expect(request.Owner).To(Equal(owner))
+		Expect(request.Resource).To(Equal("database"))
+		Expect(request.Objects).To(Equal([]map[string]interface{}{objects[0].Object}))
+		Expect(request.Digest).To(HaveLen(64))
+	})
+
+	It("changes the digest with the objects", func() {
+		_, _ = plugin.Results(ctx, "terraform", owner, "database", objects)
+		_, _ = plugin.Results(ctx, "terraform", owner, "database", objects)
+		objects[0].Object["spec"] = map[string]interface{}{"size": "large"}
+		_, _ = plugin.Results(ctx, "terraform", owner, "database", objects)
+
+		_, first := realizer.RealizeArgsForCall(0)
+		_, second := realizer.RealizeArgsForCall(1)
+		_, third := realizer.RealizeArgsForCall(2)
+		Expect(second.Digest).To(Equal(first.Digest))
+		Expect(third.Digest).NotTo(Equal(first.Digest))
+	})
+
+	It("returns the outputs of the plugin as an object", func() {
+		realizer.RealizeReturns(map[string]interface{}{"url": "postgres://db"}, nil)
+
+		results, err := plugin.Results(ctx, "terraform", owner, "database", objects)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results.Object).To(Equal(map[string]interface{}{"url": "postgres://db"}))
+	})
+
+	It("returns an empty object when the plugin has no outputs", func() {
+		results, err := plugin.Results(ctx, "terraform", owner, "database", objects)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results.Object).To(BeEmpty())
+	})
+
+	It("returns the errors of the plugin", func() {
+		realizer.RealizeReturns(nil, plugin.RunningError{Plugin: "terraform", Message: "applying"})
+
+		_, err := plugin.Results(ctx, "terraform", owner, "database", objects)
+		Expect(errors.As(err, &plugin.RunningError{})).To(BeTrue())
+		Expect(err).To(MatchError("plugin 'terraform' is realizing the objects: applying"))
+	})
+
+	It("errors when the context carries no plugins", func() {
+		_, err := plugin.Results(context.Background(), "terraform", owner, "database", objects)
+		Expect(err).To(MatchError("realizer plugin 'terraform' is not registered"))
+	})
+})
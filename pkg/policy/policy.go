@@ -0,0 +1,121 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// Rule is a CEL expression that every stamped object must satisfy. The
+// object is available to the expression as `object`.
+type Rule struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	// Message is reported when the rule is violated. It defaults to the
+	// expression.
+	Message string `json:"message,omitempty"`
+}
+
+// Policy is a compiled set of rules.
+type Policy struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	program cel.Program
+}
+
+// NewPolicy compiles rules into a Policy, failing on the first rule that is
+// not a valid boolean CEL expression.
+func NewPolicy(rules []Rule) (*Policy, error) {
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		return nil, fmt.Errorf("new cel env: %w", err)
+	}
+
+	policy := &Policy{}
+	for _, rule := range rules {
+		ast, issues := env.Compile(rule.Expression)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("compile rule '%s': %w", rule.Name, issues.Err())
+		}
+		if !ast.OutputType().IsAssignableType(cel.BoolType) {
+			return nil, fmt.Errorf("compile rule '%s': expression must evaluate to a bool, got %s", rule.Name, ast.OutputType())
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("compile rule '%s': %w", rule.Name, err)
+		}
+		policy.rules = append(policy.rules, compiledRule{Rule: rule, program: program})
+	}
+
+	return policy, nil
+}
+
+// LoadFile reads a YAML list of rules from path and compiles them.
+func LoadFile(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy file: %w", err)
+	}
+
+	var rules []Rule
+	if err := yaml.Unmarshal(content, &rules); err != nil {
+		return nil, fmt.Errorf("unmarshal policy file: %w", err)
+	}
+
+	return NewPolicy(rules)
+}
+
+// Evaluate returns a ViolationError for the first rule the object does not
+// satisfy. A rule that cannot be evaluated against the object, for instance
+// because it reads a field the object does not have, counts as violated.
+func (p *Policy) Evaluate(obj *unstructured.Unstructured) error {
+	if p == nil {
+		return nil
+	}
+
+	for _, rule := range p.rules {
+		result, _, err := rule.program.Eval(map[string]interface{}{"object": obj.Object})
+		if err != nil {
+			return ViolationError{Rule: rule.Name, Message: fmt.Sprintf("evaluation failed: %s", err)}
+		}
+		if result != types.True {
+			message := rule.Message
+			if message == "" {
+				message = rule.Expression
+			}
+			return ViolationError{Rule: rule.Name, Message: message}
+		}
+	}
+
+	return nil
+}
+
+type ViolationError struct {
+	Rule    string
+	Message string
+}
+
+func (e ViolationError) Error() string {
+	return fmt.Sprintf("violates policy rule '%s': %s", e.Rule, e.Message)
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
+)
+
+var _ = Describe("Policy", func() {
+	var obj *unstructured.Unstructured
+
+	BeforeEach(func() {
+		obj = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "some-name",
+				"namespace": "some-namespace",
+				"labels": map[string]interface{}{
+					"team": "platform",
+				},
+			},
+		}}
+	})
+
+	Describe("NewPolicy", func() {
+		It("rejects expressions that do not compile", func() {
+			_, err := policy.NewPolicy([]policy.Rule{{Name: "broken", Expression: "object.kind =="}})
+			Expect(err).To(MatchError(ContainSubstring("compile rule 'broken'")))
+		})
+
+		It("rejects expressions that do not evaluate to a bool", func() {
+			_, err := policy.NewPolicy([]policy.Rule{{Name: "not-bool", Expression: "'a string'"}})
+			Expect(err).To(MatchError("compile rule 'not-bool': expression must evaluate to a bool, got string"))
+		})
+	})
+
+	Describe("Evaluate", func() {
+		var rules []policy.Rule
+
+		evaluate := func() error {
+			p, err := policy.NewPolicy(rules)
+			Expect(err).NotTo(HaveOccurred())
+			return p.Evaluate(obj)
+		}
+
+		It("allows objects that satisfy every rule", func() {
+			rules = []policy.Rule{
+				{Name: "no-pods", Expression: "object.kind != 'Pod'"},
+				{Name: "team-label", Expression: "'team' in object.metadata.labels"},
+			}
+			Expect(evaluate()).To(Succeed())
+		})
+
+		It("reports the first rule the object violates with its message", func() {
+			rules = []policy.Rule{
+				{Name: "no-pods", Expression: "object.kind != 'Pod'"},
+				{Name: "no-configmaps", Expression: "object.kind != 'ConfigMap'", Message: "config maps are not allowed"},
+			}
+			err := evaluate()
+			Expect(err).To(Equal(policy.ViolationError{Rule: "no-configmaps", Message: "config maps are not allowed"}))
+			Expect(err).To(MatchError("violates policy rule 'no-configmaps': config maps are not allowed"))
+		})
+
+		It("defaults the message to the expression", func() {
+			rules = []policy.Rule{{Name: "no-configmaps", Expression: "object.kind != 'ConfigMap'"}}
+			Expect(evaluate()).To(MatchError("violates policy rule 'no-configmaps': object.kind != 'ConfigMap'"))
+		})
+
+		It("treats rules that cannot be evaluated as violated", func() {
+			rules = []policy.Rule{{Name: "replicas", Expression: "object.spec.replicas < 3"}}
+			err := evaluate()
+			Expect(err).To(BeAssignableToTypeOf(policy.ViolationError{}))
+			Expect(err).To(MatchError(ContainSubstring("violates policy rule 'replicas': evaluation failed")))
+		})
+
+		It("allows everything when there is no policy", func() {
+			var p *policy.Policy
+			Expect(p.Evaluate(obj)).To(Succeed())
+		})
+	})
+
+	Describe("LoadFile", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = filepath.Join(GinkgoT().TempDir(), "policy.yaml")
+		})
+
+		It("compiles the rules in the file", func() {
+			Expect(os.WriteFile(path, []byte(`
+- name: no-configmaps
+  expression: object.kind != 'ConfigMap'
+`), 0600)).To(Succeed())
+
+			p, err := policy.LoadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Evaluate(obj)).To(MatchError(ContainSubstring("no-configmaps")))
+		})
+
+		It("returns an error for a missing file", func() {
+			_, err := policy.LoadFile(filepath.Join(path, "missing"))
+			Expect(err).To(MatchError(ContainSubstring("read policy file")))
+		})
+	})
+})
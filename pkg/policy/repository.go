@@ -0,0 +1,48 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+type guardedRepository struct {
+	repository.Repository
+	policy *Policy
+}
+
+// Guard returns a Repository that evaluates policy against each object
+// before submitting it through repo.
+func Guard(repo repository.Repository, policy *Policy) repository.Repository {
+	return &guardedRepository{Repository: repo, policy: policy}
+}
+
+func (r *guardedRepository) EnsureObjectExistsOnCluster(ctx context.Context, obj *unstructured.Unstructured, allowUpdate bool) error {
+	if err := r.policy.Evaluate(obj); err != nil {
+		return err
+	}
+	return r.Repository.EnsureObjectExistsOnCluster(ctx, obj, allowUpdate)
+}
+
+func (r *guardedRepository) EnsureImmutableObjectExistsOnCluster(ctx context.Context, obj *unstructured.Unstructured) error {
+	if err := r.policy.Evaluate(obj); err != nil {
+		return err
+	}
+	return r.Repository.EnsureImmutableObjectExistsOnCluster(ctx, obj)
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+var _ = Describe("Guard", func() {
+	var (
+		ctx      context.Context
+		fakeRepo *repositoryfakes.FakeRepository
+		obj      *unstructured.Unstructured
+		p        *policy.Policy
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fakeRepo = &repositoryfakes.FakeRepository{}
+		obj = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+		}}
+
+		var err error
+		p, err = policy.NewPolicy([]policy.Rule{{Name: "no-pods", Expression: "object.kind != 'Pod'"}})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("submits objects that satisfy the policy", func() {
+		fakeRepo.EnsureObjectExistsOnClusterReturns(errors.New("apply failed"))
+
+		err := policy.Guard(fakeRepo, p).EnsureObjectExistsOnCluster(ctx, obj, true)
+		Expect(err).To(MatchError("apply failed"))
+
+		Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+		_, submitted, allowUpdate := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+		Expect(submitted).To(Equal(obj))
+		Expect(allowUpdate).To(BeTrue())
+	})
+
+	It("does not submit objects that violate the policy", func() {
+		obj.SetKind("Pod")
+
+		err := policy.Guard(fakeRepo, p).EnsureObjectExistsOnCluster(ctx, obj, true)
+		Expect(err).To(BeAssignableToTypeOf(policy.ViolationError{}))
+		Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+	})
+
+	It("guards immutable objects too", func() {
+		Expect(policy.Guard(fakeRepo, p).EnsureImmutableObjectExistsOnCluster(ctx, obj)).To(Succeed())
+		Expect(fakeRepo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(1))
+
+		obj.SetKind("Pod")
+		err := policy.Guard(fakeRepo, p).EnsureImmutableObjectExistsOnCluster(ctx, obj)
+		Expect(err).To(BeAssignableToTypeOf(policy.ViolationError{}))
+		Expect(fakeRepo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(1))
+	})
+
+	It("passes other calls through to the repository", func() {
+		_, _ = policy.Guard(fakeRepo, p).ListUnstructured(ctx, obj)
+		Expect(fakeRepo.ListUnstructuredCallCount()).To(Equal(1))
+	})
+})
@@ -0,0 +1,149 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+// StampPolicyRule is the rule reported by violations of ClusterStampPolicies.
+const StampPolicyRule = "ClusterStampPolicy"
+
+type kindGuardedRepository struct {
+	repository.Repository
+	reader client.Reader
+}
+
+// GuardKinds returns a Repository that refuses to submit objects of a kind
+// that the ClusterStampPolicies applying to their namespace, read through
+// reader, do not allow.
+func GuardKinds(repo repository.Repository, reader client.Reader) repository.Repository {
+	return &kindGuardedRepository{Repository: repo, reader: reader}
+}
+
+func (r *kindGuardedRepository) EnsureObjectExistsOnCluster(ctx context.Context, obj *unstructured.Unstructured, allowUpdate bool) error {
+	if err := CheckKind(ctx, r.reader, obj); err != nil {
+		return err
+	}
+	return r.Repository.EnsureObjectExistsOnCluster(ctx, obj, allowUpdate)
+}
+
+func (r *kindGuardedRepository) EnsureImmutableObjectExistsOnCluster(ctx context.Context, obj *unstructured.Unstructured) error {
+	if err := CheckKind(ctx, r.reader, obj); err != nil {
+		return err
+	}
+	return r.Repository.EnsureImmutableObjectExistsOnCluster(ctx, obj)
+}
+
+type stampingKey struct{}
+
+type stamping struct {
+	ownerNamespace      string
+	namespacedBlueprint bool
+}
+
+// WithBlueprint returns a copy of ctx in which objects are stamped for owner
+// by blueprint. Cluster-scoped objects stamped in ctx are held to the
+// policies of the owner's namespace. A namespaced blueprint may only stamp
+// into the owner's namespace.
+func WithBlueprint(ctx context.Context, owner, blueprint client.Object) context.Context {
+	return context.WithValue(ctx, stampingKey{}, stamping{
+		ownerNamespace:      owner.GetNamespace(),
+		namespacedBlueprint: blueprint.GetNamespace() != "",
+	})
+}
+
+// CheckScope returns a ViolationError when obj is stamped by a namespaced
+// blueprint outside the namespace of the owner it is stamped for, either into
+// another namespace or at cluster scope.
+func CheckScope(ctx context.Context, obj *unstructured.Unstructured) error {
+	s, _ := ctx.Value(stampingKey{}).(stamping)
+	if !s.namespacedBlueprint || obj.GetNamespace() == s.ownerNamespace {
+		return nil
+	}
+
+	gvk := obj.GroupVersionKind()
+	if obj.GetNamespace() == "" {
+		return ViolationError{
+			Rule:    StampPolicyRule,
+			Message: fmt.Sprintf("cluster-scoped kind '%s' may not be stamped by a namespaced blueprint", gvk.GroupKind()),
+		}
+	}
+	return ViolationError{
+		Rule:    StampPolicyRule,
+		Message: fmt.Sprintf("kind '%s' may not be stamped into namespace '%s' by a namespaced blueprint, only into '%s'", gvk.GroupKind(), obj.GetNamespace(), s.ownerNamespace),
+	}
+}
+
+// CheckKind returns a ViolationError when ClusterStampPolicies apply to the
+// namespace of obj and none of them allows its kind. Objects are allowed
+// into namespaces that no policy applies to. Cluster-scoped objects are
+// checked against the namespace of the owner they are stamped for, or
+// against every policy when ctx does not carry one.
+func CheckKind(ctx context.Context, reader client.Reader, obj *unstructured.Unstructured) error {
+	if err := CheckScope(ctx, obj); err != nil {
+		return err
+	}
+
+	gvk := obj.GroupVersionKind()
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		s, _ := ctx.Value(stampingKey{}).(stamping)
+		namespace = s.ownerNamespace
+	}
+
+	policies := &v1alpha1.ClusterStampPolicyList{}
+	if err := reader.List(ctx, policies); err != nil {
+		return fmt.Errorf("list cluster stamp policies: %w", err)
+	}
+
+	var applying []string
+	for _, p := range policies.Items {
+		if namespace != "" && !p.Spec.AppliesTo(namespace) {
+			continue
+		}
+		if p.Spec.Allows(gvk.Group, gvk.Kind) {
+			return nil
+		}
+		applying = append(applying, p.Name)
+	}
+
+	if len(applying) == 0 {
+		return nil
+	}
+
+	sort.Strings(applying)
+	into := fmt.Sprintf("into namespace '%s'", obj.GetNamespace())
+	if obj.GetNamespace() == "" {
+		into = fmt.Sprintf("for namespace '%s'", namespace)
+		if namespace == "" {
+			into = "at cluster scope"
+		}
+	}
+	return ViolationError{
+		Rule: StampPolicyRule,
+		Message: fmt.Sprintf("kind '%s' may not be stamped %s, see cluster stamp policies: %s",
+			gvk.GroupKind(), into, strings.Join(applying, ", ")),
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+var _ = Describe("GuardKinds", func() {
+	var (
+		ctx      context.Context
+		fakeRepo *repositoryfakes.FakeRepository
+		policies []client.Object
+		obj      *unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fakeRepo = &repositoryfakes.FakeRepository{}
+		policies = nil
+		obj = &unstructured.Unstructured{}
+		obj.SetAPIVersion("apps/v1")
+		obj.SetKind("Deployment")
+		obj.SetNamespace("team-a")
+	})
+
+	submit := func() error {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policies...).Build()
+		return policy.GuardKinds(fakeRepo, reader).EnsureObjectExistsOnCluster(ctx, obj, true)
+	}
+
+	stampPolicy := func(name string, namespaces []string, kinds ...v1alpha1.StampableKind) *v1alpha1.ClusterStampPolicy {
+		return &v1alpha1.ClusterStampPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.ClusterStampPolicySpec{
+				Namespaces:   namespaces,
+				AllowedKinds: kinds,
+			},
+		}
+	}
+
+	It("submits any object when there are no policies", func() {
+		Expect(submit()).To(Succeed())
+		Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+	})
+
+	It("submits objects of a kind a policy for the namespace allows", func() {
+		policies = []client.Object{
+			stampPolicy("config-only", nil, v1alpha1.StampableKind{Kind: "ConfigMap"}),
+			stampPolicy("team-a-apps", []string{"team-a"}, v1alpha1.StampableKind{Group: "apps", Kind: "*"}),
+		}
+
+		Expect(submit()).To(Succeed())
+		Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+	})
+
+	It("submits objects into namespaces no policy applies to", func() {
+		policies = []client.Object{
+			stampPolicy("team-b", []string{"team-b"}, v1alpha1.StampableKind{Kind: "ConfigMap"}),
+		}
+
+		Expect(submit()).To(Succeed())
+		Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+	})
+
+	It("refuses objects of a kind no policy for the namespace allows", func() {
+		policies = []client.Object{
+			stampPolicy("config-only", nil, v1alpha1.StampableKind{Kind: "ConfigMap"}),
+			stampPolicy("team-a-jobs", []string{"team-a"}, v1alpha1.StampableKind{Group: "batch", Kind: "Job"}),
+		}
+
+		err := submit()
+		Expect(err).To(BeAssignableToTypeOf(policy.ViolationError{}))
+		Expect(err).To(MatchError("violates policy rule 'ClusterStampPolicy': kind 'Deployment.apps' may not be stamped into namespace 'team-a', see cluster stamp policies: config-only, team-a-jobs"))
+		Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+	})
+
+	It("refuses immutable objects of a kind no policy for the namespace allows", func() {
+		policies = []client.Object{
+			stampPolicy("team-a-jobs", []string{"team-a"}, v1alpha1.StampableKind{Group: "batch", Kind: "Job"}),
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policies...).Build()
+		guarded := policy.GuardKinds(fakeRepo, reader)
+
+		Expect(guarded.EnsureImmutableObjectExistsOnCluster(ctx, obj)).To(BeAssignableToTypeOf(policy.ViolationError{}))
+		Expect(fakeRepo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(0))
+
+		obj.SetAPIVersion("batch/v1")
+		obj.SetKind("Job")
+		Expect(guarded.EnsureImmutableObjectExistsOnCluster(ctx, obj)).To(Succeed())
+		Expect(fakeRepo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(1))
+	})
+	Context("when the object is cluster-scoped", func() {
+		var owner, blueprint *unstructured.Unstructured
+
+		BeforeEach(func() {
+			obj.SetAPIVersion("rbac.authorization.k8s.io/v1")
+			obj.SetKind("ClusterRole")
+			obj.SetNamespace("")
+
+			owner = &unstructured.Unstructured{}
+			owner.SetNamespace("team-a")
+			blueprint = &unstructured.Unstructured{}
+			policies = []client.Object{
+				stampPolicy("team-a", []string{"team-a"}, v1alpha1.StampableKind{Kind: "ConfigMap"}),
+			}
+		})
+
+		It("holds it to the policies of the owner's namespace", func() {
+			ctx = policy.WithBlueprint(ctx, owner, blueprint)
+
+			err := submit()
+			Expect(err).To(BeAssignableToTypeOf(policy.ViolationError{}))
+			Expect(err).To(MatchError("violates policy rule 'ClusterStampPolicy': kind 'ClusterRole.rbac.authorization.k8s.io' may not be stamped for namespace 'team-a', see cluster stamp policies: team-a"))
+			Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+		})
+
+		It("submits it when the owner's namespace allows its kind", func() {
+			policies = append(policies, stampPolicy("team-a-rbac", []string{"team-a"}, v1alpha1.StampableKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}))
+			ctx = policy.WithBlueprint(ctx, owner, blueprint)
+
+			Expect(submit()).To(Succeed())
+			Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+		})
+
+		It("submits it when no policy applies to the owner's namespace", func() {
+			owner.SetNamespace("team-b")
+			ctx = policy.WithBlueprint(ctx, owner, blueprint)
+
+			Expect(submit()).To(Succeed())
+			Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+		})
+
+		It("holds it to every policy when the owner is not known", func() {
+			err := submit()
+			Expect(err).To(MatchError("violates policy rule 'ClusterStampPolicy': kind 'ClusterRole.rbac.authorization.k8s.io' may not be stamped at cluster scope, see cluster stamp policies: team-a"))
+			Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+		})
+
+		It("refuses it when the blueprint is namespaced", func() {
+			policies = nil
+			blueprint.SetNamespace("team-a")
+			ctx = policy.WithBlueprint(ctx, owner, blueprint)
+
+			err := submit()
+			Expect(err).To(BeAssignableToTypeOf(policy.ViolationError{}))
+			Expect(err).To(MatchError("violates policy rule 'ClusterStampPolicy': cluster-scoped kind 'ClusterRole.rbac.authorization.k8s.io' may not be stamped by a namespaced blueprint"))
+			Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+		})
+	})
+	It("refuses objects a namespaced blueprint stamps into another namespace", func() {
+		owner := &unstructured.Unstructured{}
+		owner.SetNamespace("team-b")
+		blueprint := &unstructured.Unstructured{}
+		blueprint.SetNamespace("team-b")
+		ctx = policy.WithBlueprint(ctx, owner, blueprint)
+
+		err := submit()
+		Expect(err).To(BeAssignableToTypeOf(policy.ViolationError{}))
+		Expect(err).To(MatchError("violates policy rule 'ClusterStampPolicy': kind 'Deployment.apps' may not be stamped into namespace 'team-a' by a namespaced blueprint, only into 'team-b'"))
+		Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+
+		owner.SetNamespace("team-a")
+		blueprint.SetNamespace("team-a")
+		ctx = policy.WithBlueprint(context.Background(), owner, blueprint)
+		Expect(submit()).To(Succeed())
+	})
+})
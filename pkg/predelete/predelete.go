@@ -0,0 +1,148 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package predelete runs the pre-delete hooks of a blueprint for one of its
+// workloads or deliverables being deleted.
+package predelete
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/jobs"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// HookLabel holds the name of the hook on the Jobs stamped for it.
+const HookLabel = "carto.run/pre-delete-hook"
+
+// Owner is the workload or deliverable being deleted.
+type Owner struct {
+	// Object owns the hooks' Jobs, so that they go along with it.
+	Object client.Object
+	// Kind is Workload or Deliverable.
+	Kind string
+	// Templating is the owner as templates see it, under the name of its
+	// kind: $(workload)$ or $(deliverable)$.
+	Templating interface{}
+
+	// BlueprintLabel is the label holding the blueprint's name on the
+	// objects stamped for it, such as carto.run/cluster-supply-chain-name.
+	BlueprintLabel string
+	Blueprint      string
+}
+
+// FailedError is returned when the Job of a hook failed and the hook's
+// failure policy does not ignore it.
+type FailedError struct {
+	Hook string
+	Err  error
+}
+
+func (e FailedError) Error() string {
+	return fmt.Sprintf("pre-delete hook '%s': %s", e.Hook, e.Err.Error())
+}
+
+func (e FailedError) Unwrap() error {
+	return e.Err
+}
+
+// Progress of the hooks. They are complete once none is running.
+type Progress struct {
+	// Running is the hook whose Job has not completed yet, if any.
+	Running string
+	// Stamped are the Jobs stamped so far, whose changes are worth
+	// watching.
+	Stamped []v1alpha1.ObjectReference
+}
+
+func (p Progress) Complete() bool {
+	return p.Running == ""
+}
+
+// Run stamps the Job of each hook in turn, moving on to the next once it has
+// completed, or failed when its failure policy ignores failures. The Jobs are
+// named after the hash of their spec, as those of job lifecycle templates, so
+// a hook whose Job is deleted runs again.
+func Run(ctx context.Context, repo repository.Repository, owner Owner, hooks []v1alpha1.PreDeleteHook) (Progress, error) {
+	progress := Progress{}
+	for _, hook := range hooks {
+		job, err := stamp(ctx, repo, owner, hook)
+		if err != nil {
+			return progress, fmt.Errorf("pre-delete hook '%s': %w", hook.Name, err)
+		}
+		progress.Stamped = append(progress.Stamped, v1alpha1.ObjectReference{
+			APIVersion: job.GetAPIVersion(),
+			Kind:       job.GetKind(),
+			Namespace:  job.GetNamespace(),
+			Name:       job.GetName(),
+		})
+
+		err = jobs.Finished(job)
+		if errors.As(err, &jobs.RunningError{}) {
+			progress.Running = hook.Name
+			return progress, nil
+		}
+		if err != nil && hook.FailurePolicy != v1alpha1.IgnorePreDeleteHookFailurePolicy {
+			return progress, FailedError{Hook: hook.Name, Err: err}
+		}
+	}
+	return progress, nil
+}
+
+// stamp stamps the Job of hook, unless it already exists, and returns it as
+// it is on the cluster.
+func stamp(ctx context.Context, repo repository.Repository, owner Owner, hook v1alpha1.PreDeleteHook) (*unstructured.Unstructured, error) {
+	template, err := repo.GetClusterTemplate(ctx, v1alpha1.ClusterTemplateReference{
+		Kind: hook.TemplateRef.Kind,
+		Name: hook.TemplateRef.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get template '%s': %w", hook.TemplateRef.Name, err)
+	}
+
+	prefix := "carto.run/" + strings.ToLower(owner.Kind)
+	labels := templates.Labels{
+		prefix + "-name":                  owner.Object.GetName(),
+		prefix + "-namespace":             owner.Object.GetNamespace(),
+		owner.BlueprintLabel:              owner.Blueprint,
+		HookLabel:                         hook.Name,
+		"carto.run/template-kind":         template.GetKind(),
+		"carto.run/cluster-template-name": template.GetName(),
+	}
+	templatingContext := map[string]interface{}{
+		strings.ToLower(owner.Kind): owner.Templating,
+		"params":                    templates.ParamsBuilder(template.GetDefaultParams(), hook.Params),
+	}
+
+	stamper := templates.StamperBuilder(owner.Object, templatingContext, labels)
+	job, err := stamper.Stamp(ctx, template.GetResourceTemplate())
+	if err != nil {
+		return nil, fmt.Errorf("stamp: %w", err)
+	}
+	if err := jobs.Identify(job); err != nil {
+		return nil, err
+	}
+	if err := repo.EnsureImmutableObjectExistsOnCluster(ctx, job); err != nil {
+		return nil, fmt.Errorf("create job: %w", err)
+	}
+	return job, nil
+}
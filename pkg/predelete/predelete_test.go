@@ -0,0 +1,183 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predelete_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/predelete"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+var _ = Describe("Run", func() {
+	var (
+		ctx      context.Context
+		repo     *repositoryfakes.FakeRepository
+		owner    predelete.Owner
+		hooks    []v1alpha1.PreDeleteHook
+		statuses map[string]string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &repositoryfakes.FakeRepository{}
+
+		workload := &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "my-ns"},
+		}
+		owner = predelete.Owner{
+			Object:         workload,
+			Kind:           "Workload",
+			Templating:     workload,
+			BlueprintLabel: "carto.run/cluster-supply-chain-name",
+			Blueprint:      "my-supply-chain",
+		}
+		hooks = []v1alpha1.PreDeleteHook{
+			{Name: "release-dns", TemplateRef: v1alpha1.PreDeleteHookTemplateReference{Kind: "ClusterTemplate", Name: "dns-cleanup"}},
+			{Name: "drop-repo", TemplateRef: v1alpha1.PreDeleteHookTemplateReference{Kind: "ClusterTemplate", Name: "repo-cleanup"},
+				Params: []v1alpha1.Param{{Name: "registry", Value: apiextensionsv1.JSON{Raw: []byte(`"registry.example.com"`)}}}},
+		}
+
+		repo.GetClusterTemplateStub = func(_ context.Context, ref v1alpha1.ClusterTemplateReference) (templates.Template, error) {
+			args := `"$(workload.metadata.name)$"`
+			if ref.Name == "repo-cleanup" {
+				args += `, "$(params.registry)$"`
+			}
+			apiTemplate := &v1alpha1.ClusterTemplate{}
+			apiTemplate.Kind = "ClusterTemplate"
+			apiTemplate.Name = ref.Name
+			apiTemplate.Spec.Params = v1alpha1.DefaultParams{
+				{Name: "registry", DefaultValue: apiextensionsv1.JSON{Raw: []byte(`"docker.io"`)}},
+			}
+			apiTemplate.Spec.Template = &runtime.RawExtension{Raw: []byte(`{
+				"apiVersion": "batch/v1",
+				"kind": "Job",
+				"metadata": {"generateName": "` + ref.Name + `-"},
+				"spec": {"template": {"spec": {"containers": [{"name": "clean", "image": "cleaner", "args": [` + args + `]}]}}}
+			}`)}
+			return templates.NewModelFromAPI(apiTemplate)
+		}
+
+		// statuses holds the condition the Job of each template has.
+		statuses = map[string]string{}
+		repo.EnsureImmutableObjectExistsOnClusterStub = func(_ context.Context, job *unstructured.Unstructured) error {
+			if condition, ok := statuses[job.GetLabels()["carto.run/cluster-template-name"]]; ok {
+				Expect(unstructured.SetNestedSlice(job.Object, []interface{}{
+					map[string]interface{}{"type": condition, "status": "True", "message": "it broke"},
+				}, "status", "conditions")).To(Succeed())
+			}
+			return nil
+		}
+	})
+
+	It("stamps the Job of the first hook, owned and labelled by the owner, and waits for it", func() {
+		progress, err := predelete.Run(ctx, repo, owner, hooks)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(progress.Complete()).To(BeFalse())
+		Expect(progress.Running).To(Equal("release-dns"))
+		Expect(repo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(1))
+
+		_, job := repo.EnsureImmutableObjectExistsOnClusterArgsForCall(0)
+		Expect(job.GetName()).To(MatchRegexp(`^dns-cleanup-[0-9a-f]{10}$`))
+		Expect(job.GetOwnerReferences()).To(HaveLen(1))
+		Expect(job.GetOwnerReferences()[0].Name).To(Equal("my-app"))
+		Expect(job.GetLabels()).To(Equal(map[string]string{
+			"carto.run/workload-name":             "my-app",
+			"carto.run/workload-namespace":        "my-ns",
+			"carto.run/cluster-supply-chain-name": "my-supply-chain",
+			"carto.run/pre-delete-hook":           "release-dns",
+			"carto.run/template-kind":             "ClusterTemplate",
+			"carto.run/cluster-template-name":     "dns-cleanup",
+		}))
+		Expect(progress.Stamped).To(Equal([]v1alpha1.ObjectReference{
+			{APIVersion: "batch/v1", Kind: "Job", Namespace: "my-ns", Name: job.GetName()},
+		}))
+	})
+
+	It("moves on to the next hook once a Job completes, stamping it with the hook's params", func() {
+		statuses["dns-cleanup"] = "Complete"
+
+		progress, err := predelete.Run(ctx, repo, owner, hooks)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Running).To(Equal("drop-repo"))
+		Expect(progress.Stamped).To(HaveLen(2))
+
+		_, job := repo.EnsureImmutableObjectExistsOnClusterArgsForCall(1)
+		args, _, _ := unstructured.NestedSlice(job.Object, "spec", "template", "spec", "containers")
+		Expect(args[0].(map[string]interface{})["args"]).To(Equal([]interface{}{"my-app", "registry.example.com"}))
+	})
+
+	It("is complete once every Job has completed", func() {
+		statuses["dns-cleanup"] = "Complete"
+		statuses["repo-cleanup"] = "Complete"
+
+		progress, err := predelete.Run(ctx, repo, owner, hooks)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Complete()).To(BeTrue())
+	})
+
+	It("is complete without hooks", func() {
+		progress, err := predelete.Run(ctx, repo, owner, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Complete()).To(BeTrue())
+		Expect(repo.GetClusterTemplateCallCount()).To(Equal(0))
+	})
+
+	It("stops at a hook whose Job failed", func() {
+		statuses["dns-cleanup"] = "Failed"
+
+		_, err := predelete.Run(ctx, repo, owner, hooks)
+		Expect(err).To(BeAssignableToTypeOf(predelete.FailedError{}))
+		Expect(err).To(MatchError(ContainSubstring("pre-delete hook 'release-dns': job 'my-ns/dns-cleanup-")))
+		Expect(err).To(MatchError(ContainSubstring("failed: it broke")))
+		Expect(repo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(1))
+	})
+
+	It("moves past a failed Job when the hook ignores failures", func() {
+		statuses["dns-cleanup"] = "Failed"
+		hooks[0].FailurePolicy = v1alpha1.IgnorePreDeleteHookFailurePolicy
+
+		progress, err := predelete.Run(ctx, repo, owner, hooks)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Running).To(Equal("drop-repo"))
+	})
+
+	It("returns an error when the template cannot be read", func() {
+		repo.GetClusterTemplateStub = nil
+		repo.GetClusterTemplateReturns(nil, errors.New("not found"))
+
+		_, err := predelete.Run(ctx, repo, owner, hooks)
+		Expect(err).To(MatchError("pre-delete hook 'release-dns': get template 'dns-cleanup': not found"))
+	})
+
+	It("returns an error when the Job cannot be created", func() {
+		repo.EnsureImmutableObjectExistsOnClusterStub = nil
+		repo.EnsureImmutableObjectExistsOnClusterReturns(errors.New("forbidden"))
+
+		_, err := predelete.Run(ctx, repo, owner, hooks)
+		Expect(err).To(MatchError("pre-delete hook 'release-dns': create job: forbidden"))
+	})
+})
@@ -0,0 +1,62 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagation
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// Apply copies the owner's labels and annotations that propagation selects
+// onto obj, leaving those obj already sets, such as the template's own and
+// Cartographer's carto.run labels, as they are.
+func Apply(obj *unstructured.Unstructured, ownerLabels, ownerAnnotations map[string]string, propagation *v1alpha1.MetadataPropagation) {
+	if obj == nil || propagation == nil {
+		return
+	}
+
+	if labels := selected(ownerLabels, propagation.Labels); len(labels) > 0 {
+		obj.SetLabels(withDefaults(obj.GetLabels(), labels))
+	}
+	if annotations := selected(ownerAnnotations, propagation.Annotations); len(annotations) > 0 {
+		obj.SetAnnotations(withDefaults(obj.GetAnnotations(), annotations))
+	}
+}
+
+func selected(metadata map[string]string, policy *v1alpha1.PropagationPolicy) map[string]string {
+	var propagated map[string]string
+	for key, value := range metadata {
+		if !policy.Propagates(key) {
+			continue
+		}
+		if propagated == nil {
+			propagated = map[string]string{}
+		}
+		propagated[key] = value
+	}
+	return propagated
+}
+
+func withDefaults(existing, defaults map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(defaults))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range existing {
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package propagation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/propagation"
+)
+
+var _ = Describe("Apply", func() {
+	var (
+		obj              *unstructured.Unstructured
+		ownerLabels      map[string]string
+		ownerAnnotations map[string]string
+	)
+
+	BeforeEach(func() {
+		obj = &unstructured.Unstructured{}
+		obj.SetLabels(map[string]string{
+			"carto.run/workload-name": "my-workload",
+			"team":                    "from-template",
+		})
+
+		ownerLabels = map[string]string{
+			"cost-center":               "cc-42",
+			"team":                      "from-workload",
+			"carto.run/workload-name":   "spoofed",
+			"app.kubernetes.io/part-of": "shop",
+		}
+		ownerAnnotations = map[string]string{
+			"example.com/owner": "payments",
+			"kubectl.kubernetes.io/last-applied-configuration": "{}",
+		}
+	})
+
+	It("propagates nothing without a policy", func() {
+		propagation.Apply(obj, ownerLabels, ownerAnnotations, nil)
+
+		Expect(obj.GetLabels()).To(Equal(map[string]string{
+			"carto.run/workload-name": "my-workload",
+			"team":                    "from-template",
+		}))
+		Expect(obj.GetAnnotations()).To(BeNil())
+	})
+
+	It("propagates every key but Cartographer's and Kubernetes' with All", func() {
+		propagation.Apply(obj, ownerLabels, ownerAnnotations, &v1alpha1.MetadataPropagation{
+			Labels:      &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+			Annotations: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+		})
+
+		Expect(obj.GetLabels()).To(Equal(map[string]string{
+			"carto.run/workload-name": "my-workload",
+			"team":                    "from-template",
+			"cost-center":             "cc-42",
+		}))
+		Expect(obj.GetAnnotations()).To(Equal(map[string]string{"example.com/owner": "payments"}))
+	})
+
+	It("propagates only the allowed keys with Allowlist", func() {
+		propagation.Apply(obj, ownerLabels, ownerAnnotations, &v1alpha1.MetadataPropagation{
+			Labels:      &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"app.kubernetes.io/part-of"}},
+			Annotations: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"example.com/*"}},
+		})
+
+		Expect(obj.GetLabels()).To(Equal(map[string]string{
+			"carto.run/workload-name":   "my-workload",
+			"team":                      "from-template",
+			"app.kubernetes.io/part-of": "shop",
+		}))
+		Expect(obj.GetAnnotations()).To(Equal(map[string]string{"example.com/owner": "payments"}))
+	})
+
+	It("leaves the object's metadata alone with None", func() {
+		propagation.Apply(obj, ownerLabels, ownerAnnotations, &v1alpha1.MetadataPropagation{
+			Labels: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateNone},
+		})
+
+		Expect(obj.GetLabels()).To(HaveLen(2))
+		Expect(obj.GetAnnotations()).To(BeNil())
+	})
+})
@@ -0,0 +1,152 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/redact"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// maxRecordedWorkloads bounds the workloads whose last record is kept.
+// Past it the recorder forgets them all, and sends each one's artifacts
+// again the next time it is realized.
+const maxRecordedWorkloads = 10000
+
+// Recorder sends the artifacts realized for a workload to a Store when
+// they differ from those last sent for it, so that workloads reconciled
+// again without change do not flood the store.
+type Recorder struct {
+	store   Store
+	cluster string
+	now     func() time.Time
+
+	mu   sync.Mutex
+	last map[types.UID]string
+}
+
+func NewRecorder(store Store, cluster string) *Recorder {
+	return &Recorder{
+		store:   store,
+		cluster: cluster,
+		now:     time.Now,
+		last:    map[types.UID]string{},
+	}
+}
+
+// Record sends the outputs of the workload's resources to the store,
+// unless the same outputs were last sent for the workload's generation. A
+// nil Recorder records nothing.
+func (r *Recorder) Record(ctx context.Context, workload *v1alpha1.Workload, supplyChainName string, outputs map[string]*templates.Output) error {
+	if r == nil {
+		return nil
+	}
+
+	record := Record{
+		Cluster: r.cluster,
+		Workload: WorkloadRef{
+			Namespace:  workload.Namespace,
+			Name:       workload.Name,
+			UID:        workload.UID,
+			Generation: workload.Generation,
+		},
+		SupplyChain: supplyChainName,
+		Artifacts:   artifacts(outputs),
+	}
+
+	fingerprint, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+
+	r.mu.Lock()
+	unchanged := r.last[workload.UID] == string(fingerprint)
+	r.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	record.RecordedAt = r.now().UTC()
+	if err := r.store.Record(ctx, record); err != nil {
+		return fmt.Errorf("record artifacts of workload '%s/%s': %w", workload.Namespace, workload.Name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.last) >= maxRecordedWorkloads {
+		r.last = map[types.UID]string{}
+	}
+	r.last[workload.UID] = string(fingerprint)
+	return nil
+}
+
+func artifacts(outputs map[string]*templates.Output) []Artifact {
+	result := []Artifact{}
+	for resource, output := range outputs {
+		if output == nil || (output.Source == nil && output.Image == nil && output.Config == nil) {
+			continue
+		}
+		result = append(result, artifact(resource, output))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Resource < result[j].Resource
+	})
+	return result
+}
+
+// artifact returns the artifact of a resource's output, with the values of
+// the outputs its template marks sensitive redacted: stores are outside the
+// cluster, which sensitive values must not leave.
+func artifact(resource string, output *templates.Output) Artifact {
+	result := Artifact{
+		Resource: resource,
+		Source:   output.Source,
+		Image:    output.Image,
+		Config:   output.Config,
+	}
+	for _, name := range output.Sensitive {
+		switch name {
+		case "url", "revision":
+			if result.Source == nil {
+				continue
+			}
+			source := *result.Source
+			if name == "url" {
+				source.URL = redact.Placeholder
+			} else {
+				source.Revision = redact.Placeholder
+			}
+			result.Source = &source
+		case "image":
+			if result.Image != nil {
+				result.Image = redact.Placeholder
+			}
+		case "config":
+			if result.Config != nil {
+				result.Config = redact.Placeholder
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provenance_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/provenance"
+	"github.com/vmware-tanzu/cartographer/pkg/provenance/provenancefakes"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		store    *provenancefakes.FakeStore
+		recorder *provenance.Recorder
+		workload *v1alpha1.Workload
+		outputs  map[string]*templates.Output
+	)
+
+	BeforeEach(func() {
+		store = &provenancefakes.FakeStore{}
+		recorder = provenance.NewRecorder(store, "prod-eu")
+		workload = &v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team-a", Name: "web", UID: "some-uid", Generation: 2,
+		}}
+		outputs = map[string]*templates.Output{
+			"source-provider": {Source: &templates.Source{URL: "https://example.com/web.tar.gz", Revision: "main/1234"}},
+			"image-builder":   {Image: "registry.example.com/web@sha256:abc"},
+			"deployer":        {},
+		}
+	})
+
+	It("records the artifacts of the workload in resource order", func() {
+		Expect(recorder.Record(context.Background(), workload, "web", outputs)).To(Succeed())
+
+		Expect(store.RecordCallCount()).To(Equal(1))
+		_, record := store.RecordArgsForCall(0)
+		Expect(record.Cluster).To(Equal("prod-eu"))
+		Expect(record.Workload).To(Equal(provenance.WorkloadRef{Namespace: "team-a", Name: "web", UID: "some-uid", Generation: 2}))
+		Expect(record.SupplyChain).To(Equal("web"))
+		Expect(record.Artifacts).To(Equal([]provenance.Artifact{
+			{Resource: "image-builder", Image: "registry.example.com/web@sha256:abc"},
+			{Resource: "source-provider", Source: &templates.Source{URL: "https://example.com/web.tar.gz", Revision: "main/1234"}},
+		}))
+		Expect(record.RecordedAt).NotTo(BeZero())
+	})
+
+	It("redacts the outputs templates mark sensitive", func() {
+		outputs["source-provider"].Sensitive = []string{"url"}
+		outputs["config-provider"] = &templates.Output{Config: map[string]interface{}{"token": "s3cr3t"}, Sensitive: []string{"config"}}
+
+		Expect(recorder.Record(context.Background(), workload, "web", outputs)).To(Succeed())
+
+		_, record := store.RecordArgsForCall(0)
+		Expect(record.Artifacts).To(Equal([]provenance.Artifact{
+			{Resource: "config-provider", Config: "[redacted]"},
+			{Resource: "image-builder", Image: "registry.example.com/web@sha256:abc"},
+			{Resource: "source-provider", Source: &templates.Source{URL: "[redacted]", Revision: "main/1234"}},
+		}))
+		Expect(outputs["source-provider"].Source.URL).To(Equal("https://example.com/web.tar.gz"))
+	})
+
+	It("does not record the same artifacts twice", func() {
+		Expect(recorder.Record(context.Background(), workload, "web", outputs)).To(Succeed())
+		Expect(recorder.Record(context.Background(), workload, "web", outputs)).To(Succeed())
+		Expect(store.RecordCallCount()).To(Equal(1))
+	})
+
+	It("records artifacts that changed", func() {
+		Expect(recorder.Record(context.Background(), workload, "web", outputs)).To(Succeed())
+		outputs["image-builder"] = &templates.Output{Image: "registry.example.com/web@sha256:def"}
+		Expect(recorder.Record(context.Background(), workload, "web", outputs)).To(Succeed())
+		Expect(store.RecordCallCount()).To(Equal(2))
+	})
+
+	It("records the artifacts again after a failure", func() {
+		store.RecordReturnsOnCall(0, errors.New("store unavailable"))
+
+		err := recorder.Record(context.Background(), workload, "web", outputs)
+		Expect(err).To(MatchError("record artifacts of workload 'team-a/web': store unavailable"))
+		Expect(recorder.Record(context.Background(), workload, "web", outputs)).To(Succeed())
+		Expect(store.RecordCallCount()).To(Equal(2))
+	})
+
+	It("records nothing when nil", func() {
+		var nilRecorder *provenance.Recorder
+		Expect(nilRecorder.Record(context.Background(), workload, "web", outputs)).To(Succeed())
+	})
+})
@@ -0,0 +1,116 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package provenance records the artifacts realized for workloads in an
+// external metadata store, so that provenance can be traced across the
+// clusters delivering them.
+package provenance
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// maxErrorBodySize bounds how much of a failed response is reported.
+const maxErrorBodySize = 512
+
+// Record is what is sent to the store for a workload each time the
+// artifacts realized for it change.
+type Record struct {
+	// Cluster names the cluster the workload is realized in.
+	Cluster     string      `json:"cluster,omitempty"`
+	Workload    WorkloadRef `json:"workload"`
+	SupplyChain string      `json:"supplyChain"`
+	// Artifacts in resource name order.
+	Artifacts  []Artifact `json:"artifacts"`
+	RecordedAt time.Time  `json:"recordedAt"`
+}
+
+type WorkloadRef struct {
+	Namespace  string    `json:"namespace"`
+	Name       string    `json:"name"`
+	UID        types.UID `json:"uid"`
+	Generation int64     `json:"generation"`
+}
+
+// Artifact is the output of a resource: the source, image or config the
+// object stamped for it produced.
+type Artifact struct {
+	Resource string            `json:"resource"`
+	Source   *templates.Source `json:"source,omitempty"`
+	Image    templates.Image   `json:"image,omitempty"`
+	Config   templates.Config  `json:"config,omitempty"`
+}
+
+//counterfeiter:generate . Store
+type Store interface {
+	Record(ctx context.Context, record Record) error
+}
+
+// HTTPStore posts each record as JSON to an HTTP endpoint. The endpoint
+// should treat records as idempotent: a record may be sent again, such as
+// after the controller restarts.
+type HTTPStore struct {
+	HTTP  *http.Client
+	URL   string
+	Token string
+}
+
+func NewHTTPStore(url, token string) *HTTPStore {
+	return &HTTPStore{
+		HTTP:  &http.Client{Timeout: 10 * time.Second},
+		URL:   url,
+		Token: token,
+	}
+}
+
+func (s *HTTPStore) Record(ctx context.Context, record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("post %s: %w", s.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("post %s: %s: %s", s.URL, resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provenance_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/provenance"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+var _ = Describe("HTTPStore", func() {
+	var (
+		server   *httptest.Server
+		received []*http.Request
+		bodies   [][]byte
+		status   int
+		record   provenance.Record
+	)
+
+	BeforeEach(func() {
+		received = nil
+		bodies = nil
+		status = http.StatusAccepted
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			received = append(received, r)
+			bodies = append(bodies, body)
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte("unavailable\n"))
+		}))
+
+		record = provenance.Record{
+			Cluster:     "prod-eu",
+			Workload:    provenance.WorkloadRef{Namespace: "team-a", Name: "web", UID: "some-uid", Generation: 2},
+			SupplyChain: "web",
+			Artifacts: []provenance.Artifact{
+				{Resource: "image-builder", Image: "registry.example.com/web@sha256:abc"},
+				{Resource: "source-provider", Source: &templates.Source{URL: "https://example.com/web.tar.gz", Revision: "main/1234"}},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("posts the record as JSON", func() {
+		store := provenance.NewHTTPStore(server.URL+"/records", "")
+		Expect(store.Record(context.Background(), record)).To(Succeed())
+
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].Method).To(Equal(http.MethodPost))
+		Expect(received[0].URL.Path).To(Equal("/records"))
+		Expect(received[0].Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(received[0].Header.Get("Authorization")).To(BeEmpty())
+
+		var posted map[string]interface{}
+		Expect(json.Unmarshal(bodies[0], &posted)).To(Succeed())
+		Expect(posted["cluster"]).To(Equal("prod-eu"))
+		Expect(posted["workload"]).To(Equal(map[string]interface{}{
+			"namespace": "team-a", "name": "web", "uid": "some-uid", "generation": float64(2),
+		}))
+		Expect(posted["artifacts"]).To(Equal([]interface{}{
+			map[string]interface{}{"resource": "image-builder", "image": "registry.example.com/web@sha256:abc"},
+			map[string]interface{}{"resource": "source-provider", "source": map[string]interface{}{
+				"url": "https://example.com/web.tar.gz", "revision": "main/1234",
+			}},
+		}))
+	})
+
+	It("presents the token as a bearer token", func() {
+		store := provenance.NewHTTPStore(server.URL, "s3cr3t")
+		Expect(store.Record(context.Background(), record)).To(Succeed())
+		Expect(received[0].Header.Get("Authorization")).To(Equal("Bearer s3cr3t"))
+	})
+
+	It("fails on responses other than 2xx", func() {
+		status = http.StatusServiceUnavailable
+		store := provenance.NewHTTPStore(server.URL, "")
+		Expect(store.Record(context.Background(), record)).To(MatchError(ContainSubstring("503 Service Unavailable: unavailable")))
+	})
+})
@@ -0,0 +1,235 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// DefaultReviewTTL is how long the outcome of an access review is kept
+// before it is asked again.
+const DefaultReviewTTL = time.Minute
+
+var (
+	// stampingVerbs are what the identity submitting stamped objects does
+	// with them: list the objects stamped before, create or patch them.
+	stampingVerbs = []string{"create", "patch", "list"}
+
+	// trackingVerbs are what Cartographer does with stamped objects,
+	// whoever submits them: watch them for changes.
+	trackingVerbs = []string{"watch"}
+)
+
+// Stamping is the objects a resource of a blueprint stamps for an owner.
+type Stamping struct {
+	// Resource is the name of the blueprint's resource.
+	Resource string
+	Template templates.Template
+
+	// Namespace is the namespace the objects are stamped into.
+	Namespace string
+
+	// ServiceAccountNamespace and ServiceAccountName are the service
+	// account the objects are submitted as. They are empty when the
+	// objects are submitted as Cartographer.
+	ServiceAccountNamespace string
+	ServiceAccountName      string
+}
+
+// MissingPermission is a verb an identity needs on the objects a resource
+// stamps but is not allowed.
+type MissingPermission struct {
+	Resource string
+	// User is the service account, as system:serviceaccount:namespace:name,
+	// or empty for Cartographer.
+	User          string
+	Verb          string
+	GroupResource schema.GroupResource
+	// Namespace is empty for cluster-scoped objects.
+	Namespace string
+}
+
+func (m MissingPermission) String() string {
+	user := m.User
+	if user == "" {
+		user = "cartographer"
+	}
+	message := fmt.Sprintf("resource '%s': %s cannot %s %s", m.Resource, user, m.Verb, m.GroupResource)
+	if m.Namespace != "" {
+		message += fmt.Sprintf(" in namespace '%s'", m.Namespace)
+	}
+	return message
+}
+
+// Reviewer asks the apiserver, with SelfSubjectAccessReviews, whether the
+// identities that submit and track stamped objects may do so, keeping the
+// answers for a TTL so that owners reconciled in the meantime share them.
+type Reviewer struct {
+	client  client.Client
+	clients func(namespace, serviceAccountName string) (client.Client, error)
+	mapper  meta.RESTMapper
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	reviews map[review]reviewed
+}
+
+type review struct {
+	user      string
+	verb      string
+	resource  schema.GroupResource
+	namespace string
+}
+
+type reviewed struct {
+	allowed bool
+	at      time.Time
+}
+
+// NewReviewer returns a Reviewer that reviews Cartographer's access with c
+// and a service account's with the client clients returns for it, mapping
+// kinds to resources with mapper.
+func NewReviewer(c client.Client, clients func(namespace, serviceAccountName string) (client.Client, error), mapper meta.RESTMapper, ttl time.Duration) *Reviewer {
+	return &Reviewer{
+		client:  c,
+		clients: clients,
+		mapper:  mapper,
+		ttl:     ttl,
+		reviews: map[review]reviewed{},
+	}
+}
+
+// Review returns the permissions missing for the objects of stampings to be
+// submitted and tracked: their submitter must be allowed to create, patch
+// and list them, and Cartographer to watch them. Templates whose kinds are
+// not known before stamping, such as those written in ytt, and kinds the
+// apiserver does not serve are not reviewed; realization reports those.
+func (r *Reviewer) Review(ctx context.Context, stampings []Stamping) ([]MissingPermission, error) {
+	var missing []MissingPermission
+	for _, stamping := range stampings {
+		var user string
+		if stamping.ServiceAccountName != "" {
+			user = serviceaccount.MakeUsername(stamping.ServiceAccountNamespace, stamping.ServiceAccountName)
+		}
+
+		for _, gvk := range reviewedKinds(stamping.Template) {
+			mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				if meta.IsNoMatchError(err) {
+					continue
+				}
+				return nil, fmt.Errorf("map kind '%s': %w", gvk, err)
+			}
+
+			namespace := stamping.Namespace
+			if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+				namespace = ""
+			}
+
+			checks := []struct {
+				user  string
+				verbs []string
+			}{{user, stampingVerbs}, {"", trackingVerbs}}
+			for _, check := range checks {
+				for _, verb := range check.verbs {
+					key := review{user: check.user, verb: verb, resource: mapping.Resource.GroupResource(), namespace: namespace}
+					allowed, err := r.allowed(ctx, key, stamping)
+					if err != nil {
+						return nil, err
+					}
+					if !allowed {
+						missing = append(missing, MissingPermission{
+							Resource:      stamping.Resource,
+							User:          key.user,
+							Verb:          key.verb,
+							GroupResource: key.resource,
+							Namespace:     key.namespace,
+						})
+					}
+				}
+			}
+		}
+	}
+	return missing, nil
+}
+
+// allowed returns whether the user of key may do what key describes, asking
+// the apiserver as that user unless the answer is cached.
+func (r *Reviewer) allowed(ctx context.Context, key review, stamping Stamping) (bool, error) {
+	r.mu.Lock()
+	cached, ok := r.reviews[key]
+	r.mu.Unlock()
+	if ok && time.Since(cached.at) < r.ttl {
+		return cached.allowed, nil
+	}
+
+	cl := r.client
+	if key.user != "" {
+		var err error
+		cl, err = r.clients(stamping.ServiceAccountNamespace, stamping.ServiceAccountName)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	accessReview := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: key.namespace,
+				Verb:      key.verb,
+				Group:     key.resource.Group,
+				Resource:  key.resource.Resource,
+			},
+		},
+	}
+	if err := cl.Create(ctx, accessReview); err != nil {
+		return false, fmt.Errorf("review access to %s %s: %w", key.verb, key.resource, err)
+	}
+
+	r.mu.Lock()
+	r.reviews[key] = reviewed{allowed: accessReview.Status.Allowed, at: time.Now()}
+	r.mu.Unlock()
+	return accessReview.Status.Allowed, nil
+}
+
+// reviewedKinds returns the kinds of the objects template stamps, or none
+// when they are not known before stamping or are not submitted by
+// Cartographer: for templates that patch a config, are written in ytt,
+// template their apiVersion or kind, or are realized by a plugin.
+func reviewedKinds(template templates.Template) []schema.GroupVersionKind {
+	if patcher, ok := template.(templates.ConfigPatcher); ok && patcher.GetPatches() != nil {
+		return nil
+	}
+	spec := template.GetResourceTemplate()
+	if spec.Template == nil || spec.Plugin != "" {
+		return nil
+	}
+	gvks, err := stampedKinds(spec.Template.Raw)
+	if err != nil {
+		return nil
+	}
+	return gvks
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/rbac"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// reviewingClient answers the SelfSubjectAccessReviews created with it as
+// user, allowing what allowed holds.
+type reviewingClient struct {
+	client.Client
+	user    string
+	allowed map[string]bool
+	reviews *[]string
+	err     error
+}
+
+func (c reviewingClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	if c.err != nil {
+		return c.err
+	}
+	attributes := obj.(*authorizationv1.SelfSubjectAccessReview).Spec.ResourceAttributes
+	review := c.user + " " + attributes.Verb + " " + schema.GroupResource{Group: attributes.Group, Resource: attributes.Resource}.String() + " " + attributes.Namespace
+	*c.reviews = append(*c.reviews, review)
+	obj.(*authorizationv1.SelfSubjectAccessReview).Status.Allowed = c.allowed[review]
+	return nil
+}
+
+var _ = Describe("Reviewer", func() {
+	var (
+		ctx      context.Context
+		allowed  map[string]bool
+		reviews  []string
+		mapper   *meta.DefaultRESTMapper
+		reviewer *rbac.Reviewer
+	)
+
+	template := func(raw string) templates.Template {
+		return templates.NewClusterTemplateModel(&v1alpha1.ClusterTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "deploy"},
+			Spec: v1alpha1.TemplateSpec{
+				Template: &runtime.RawExtension{Raw: []byte(raw)},
+			},
+		})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		allowed = map[string]bool{}
+		reviews = nil
+		mapper = meta.NewDefaultRESTMapper(nil)
+		mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+		mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+
+		reviewer = rbac.NewReviewer(
+			reviewingClient{user: "cartographer", allowed: allowed, reviews: &reviews},
+			func(namespace, serviceAccountName string) (client.Client, error) {
+				return reviewingClient{user: namespace + "/" + serviceAccountName, allowed: allowed, reviews: &reviews}, nil
+			},
+			mapper,
+			time.Minute,
+		)
+	})
+
+	It("reviews the verbs the submitter and Cartographer need on the stamped kinds", func() {
+		allowed["dev/deployer create deployments.apps dev"] = true
+		allowed["dev/deployer patch deployments.apps dev"] = true
+		allowed["cartographer watch deployments.apps dev"] = true
+
+		missing, err := reviewer.Review(ctx, []rbac.Stamping{{
+			Resource:                "deployer",
+			Template:                template(`{"apiVersion": "apps/v1", "kind": "Deployment"}`),
+			Namespace:               "dev",
+			ServiceAccountNamespace: "dev",
+			ServiceAccountName:      "deployer",
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reviews).To(ConsistOf(
+			"dev/deployer create deployments.apps dev",
+			"dev/deployer patch deployments.apps dev",
+			"dev/deployer list deployments.apps dev",
+			"cartographer watch deployments.apps dev",
+		))
+		Expect(missing).To(Equal([]rbac.MissingPermission{{
+			Resource:      "deployer",
+			User:          "system:serviceaccount:dev:deployer",
+			Verb:          "list",
+			GroupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+			Namespace:     "dev",
+		}}))
+		Expect(missing[0].String()).To(Equal("resource 'deployer': system:serviceaccount:dev:deployer cannot list deployments.apps in namespace 'dev'"))
+	})
+
+	It("reviews Cartographer's access when no service account is named, and cluster-scoped kinds outside namespaces", func() {
+		missing, err := reviewer.Review(ctx, []rbac.Stamping{{
+			Resource:  "roles",
+			Template:  template(`{"apiVersion": "v1", "kind": "List", "items": [{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRole"}]}`),
+			Namespace: "dev",
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(HaveLen(4))
+		Expect(missing[0].String()).To(Equal("resource 'roles': cartographer cannot create clusterroles.rbac.authorization.k8s.io"))
+	})
+
+	It("does not review templates whose kinds are not known before stamping, nor unknown kinds", func() {
+		missing, err := reviewer.Review(ctx, []rbac.Stamping{
+			{Resource: "templated", Template: template(`{"apiVersion": "$(params.apiVersion)$", "kind": "Deployment"}`), Namespace: "dev"},
+			{Resource: "unknown", Template: template(`{"apiVersion": "kpack.io/v1alpha2", "kind": "Image"}`), Namespace: "dev"},
+			{Resource: "ytt", Template: templates.NewClusterTemplateModel(&v1alpha1.ClusterTemplate{Spec: v1alpha1.TemplateSpec{Ytt: "#@ load(\"@ytt:data\", \"data\")"}}), Namespace: "dev"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(BeEmpty())
+		Expect(reviews).To(BeEmpty())
+	})
+
+	It("keeps the answers for the TTL", func() {
+		stampings := []rbac.Stamping{{
+			Resource:  "deployer",
+			Template:  template(`{"apiVersion": "apps/v1", "kind": "Deployment"}`),
+			Namespace: "dev",
+		}}
+		_, err := reviewer.Review(ctx, stampings)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reviews).To(HaveLen(4))
+
+		missing, err := reviewer.Review(ctx, stampings)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reviews).To(HaveLen(4))
+		Expect(missing).To(HaveLen(4))
+	})
+
+	It("returns an error when the review cannot be created", func() {
+		reviewer = rbac.NewReviewer(reviewingClient{err: errors.New("forbidden")}, nil, mapper, time.Minute)
+		_, err := reviewer.Review(ctx, []rbac.Stamping{{
+			Resource:  "deployer",
+			Template:  template(`{"apiVersion": "apps/v1", "kind": "Deployment"}`),
+			Namespace: "dev",
+		}})
+		Expect(err).To(MatchError("review access to create deployments.apps: forbidden"))
+	})
+})
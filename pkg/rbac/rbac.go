@@ -0,0 +1,274 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rbac derives the permissions Cartographer's controller needs on
+// the objects blueprints and Pipelines stamp, so that installations can
+// grant it those rather than cluster-admin.
+package rbac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/render"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// AggregateToControllerLabel marks ClusterRoles whose rules are aggregated
+// into the cartographer-controller ClusterRole.
+const AggregateToControllerLabel = "carto.run/aggregate-to-controller"
+
+// runTemplateKind is the kind of the templates Pipelines reference.
+const runTemplateKind = "ClusterRunTemplate"
+
+var (
+	manageVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+	readVerbs   = []string{"get", "list", "watch"}
+)
+
+// Uncovered is a template whose stamped objects, or a Pipeline whose
+// selected objects, a generated ClusterRole does not grant access to.
+type Uncovered struct {
+	// Template, or Pipeline, as Kind/name.
+	Template string
+	Reason   string
+}
+
+func (u Uncovered) String() string {
+	return fmt.Sprintf("%s: %s", u.Template, u.Reason)
+}
+
+// Generate returns a ClusterRole, aggregated into the controller's, that
+// grants access to the kinds stamped by the templates the blueprints and
+// Pipelines in m reference, to the pods or ConfigMaps their jobs' results
+// are read from, and to the kinds Pipelines select. Templates are only
+// inspected, not rendered: those written in ytt, or whose apiVersion or
+// kind is templated, are returned as uncovered.
+func Generate(m *render.Manifests, name string) (*rbacv1.ClusterRole, []Uncovered) {
+	// specs are the resource templates by Kind/name, nil for those that
+	// stamp no objects.
+	specs := map[string]*v1alpha1.TemplateSpec{}
+	for _, apiTemplate := range m.Templates {
+		template, err := templates.NewModelFromAPI(apiTemplate)
+		if err != nil {
+			continue
+		}
+		var spec *v1alpha1.TemplateSpec
+		if patcher, ok := template.(templates.ConfigPatcher); !ok || patcher.GetPatches() == nil {
+			resourceTemplate := template.GetResourceTemplate()
+			spec = &resourceTemplate
+		}
+		for _, key := range render.TemplateKeys(template.GetKind(), apiTemplate) {
+			specs[key] = spec
+		}
+	}
+	for _, runTemplate := range m.RunTemplates {
+		resourceTemplate := templates.NewRunTemplateModel(runTemplate).GetResourceTemplate()
+		specs[runTemplateKind+"/"+runTemplate.Name] = &resourceTemplate
+	}
+
+	access := map[schema.GroupResource][]string{}
+	grant := func(resource schema.GroupResource, verbs []string) {
+		if len(verbs) > len(access[resource]) {
+			access[resource] = verbs
+		}
+	}
+
+	var uncovered []Uncovered
+	keys, fromGit := referencedTemplates(m)
+	for _, key := range keys {
+		if fromGit[key] {
+			uncovered = append(uncovered, Uncovered{Template: key, Reason: "templates fetched from git are not inspected"})
+			continue
+		}
+		spec, ok := specs[key]
+		if !ok {
+			uncovered = append(uncovered, Uncovered{Template: key, Reason: "template not found"})
+			continue
+		}
+		if spec == nil {
+			// templates with patches stamp no objects
+			continue
+		}
+		if spec.Template == nil {
+			uncovered = append(uncovered, Uncovered{Template: key, Reason: "ytt templates are not inspected"})
+			continue
+		}
+		gvks, err := stampedKinds(spec.Template.Raw)
+		if err != nil {
+			uncovered = append(uncovered, Uncovered{Template: key, Reason: err.Error()})
+			continue
+		}
+
+		for _, gvk := range gvks {
+			plural, _ := meta.UnsafeGuessKindToResource(gvk)
+			grant(plural.GroupResource(), manageVerbs)
+		}
+		if spec.IsJob() {
+			if spec.Results != nil && spec.Results.From == v1alpha1.ConfigMapJobResults {
+				grant(schema.GroupResource{Resource: "configmaps"}, readVerbs)
+			} else {
+				grant(schema.GroupResource{Resource: "pods"}, readVerbs)
+			}
+		}
+	}
+
+	for _, pipeline := range m.Pipelines {
+		if pipeline.Spec.Selector == nil {
+			continue
+		}
+		gvk, err := selectedKind(pipeline.Spec.Selector.Resource)
+		if err != nil {
+			uncovered = append(uncovered, Uncovered{Template: "Pipeline/" + pipeline.Name, Reason: err.Error()})
+			continue
+		}
+		plural, _ := meta.UnsafeGuessKindToResource(gvk)
+		grant(plural.GroupResource(), readVerbs)
+	}
+
+	return &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{AggregateToControllerLabel: "true"},
+		},
+		Rules: rules(access),
+	}, uncovered
+}
+
+// referencedTemplates returns the templates referenced by the blueprints in
+// m, including those of their pre-delete hooks, and by its Pipelines, as
+// Kind/name, in the order they are first referenced, along with those
+// fetched from git.
+func referencedTemplates(m *render.Manifests) ([]string, map[string]bool) {
+	seen := map[string]bool{}
+	fromGit := map[string]bool{}
+	var keys []string
+	add := func(kind, name string, git bool) {
+		key := kind + "/" + name
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+		if git {
+			fromGit[key] = true
+		}
+	}
+
+	for _, supplyChain := range m.SupplyChains {
+		for _, resource := range supplyChain.GetSpec().Resources {
+			for _, templateRef := range resource.TemplateRef.Choices() {
+				add(templateRef.Kind, templateRef.DisplayName(), templateRef.Git != nil)
+			}
+		}
+		for _, hook := range supplyChain.GetSpec().PreDelete {
+			add(hook.TemplateRef.Kind, hook.TemplateRef.Name, false)
+		}
+	}
+	for _, delivery := range m.Deliveries {
+		for _, resource := range delivery.GetSpec().Resources {
+			add(resource.TemplateRef.Kind, resource.TemplateRef.DisplayName(), resource.TemplateRef.Git != nil)
+		}
+		for _, hook := range delivery.GetSpec().PreDelete {
+			add(hook.TemplateRef.Kind, hook.TemplateRef.Name, false)
+		}
+	}
+	for _, pipeline := range m.Pipelines {
+		add(runTemplateKind, pipeline.Spec.RunTemplateRef.Name, false)
+	}
+	return keys, fromGit
+}
+
+// selectedKind returns the kind of the objects a Pipeline selects.
+func selectedKind(resource v1alpha1.ResourceType) (schema.GroupVersionKind, error) {
+	if resource.APIVersion == "" || resource.Kind == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("selector has no apiVersion or kind")
+	}
+	gv, err := schema.ParseGroupVersion(resource.APIVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("parse selector apiVersion: %w", err)
+	}
+	return gv.WithKind(resource.Kind), nil
+}
+
+// stampedKinds returns the kinds of the objects a template stamps: every
+// item's, for a List template.
+func stampedKinds(raw []byte) ([]schema.GroupVersionKind, error) {
+	types, err := templates.TemplatedTypes(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal template: %w", err)
+	}
+
+	var gvks []schema.GroupVersionKind
+	for _, typeMeta := range types {
+		if typeMeta.APIVersion == "" || typeMeta.Kind == "" {
+			return nil, fmt.Errorf("template has no apiVersion or kind")
+		}
+		if strings.Contains(typeMeta.APIVersion, "$(") || strings.Contains(typeMeta.Kind, "$(") {
+			return nil, fmt.Errorf("apiVersion or kind is templated")
+		}
+
+		gv, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+		if err != nil {
+			return nil, fmt.Errorf("parse apiVersion: %w", err)
+		}
+		gvks = append(gvks, gv.WithKind(typeMeta.Kind))
+	}
+	return gvks, nil
+}
+
+// rules groups access into one rule per API group and set of verbs,
+// ordered by group.
+func rules(access map[schema.GroupResource][]string) []rbacv1.PolicyRule {
+	type ruleKey struct {
+		group string
+		verbs string
+	}
+	resources := map[ruleKey][]string{}
+	verbs := map[ruleKey][]string{}
+	for resource, resourceVerbs := range access {
+		key := ruleKey{group: resource.Group, verbs: strings.Join(resourceVerbs, ",")}
+		resources[key] = append(resources[key], resource.Resource)
+		verbs[key] = resourceVerbs
+	}
+
+	keys := make([]ruleKey, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		return keys[i].verbs < keys[j].verbs
+	})
+
+	var policyRules []rbacv1.PolicyRule
+	for _, key := range keys {
+		sort.Strings(resources[key])
+		policyRules = append(policyRules, rbacv1.PolicyRule{
+			APIGroups: []string{key.group},
+			Resources: resources[key],
+			Verbs:     verbs[key],
+		})
+	}
+	return policyRules
+}
@@ -0,0 +1,375 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/rbac"
+	"github.com/vmware-tanzu/cartographer/pkg/render"
+)
+
+const templatesYAML = `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSourceTemplate
+metadata:
+  name: git-source
+spec:
+  urlPath: .status.artifact.url
+  revisionPath: .status.artifact.revision
+  template:
+    apiVersion: source.toolkit.fluxcd.io/v1beta1
+    kind: GitRepository
+    metadata:
+      name: $(workload.metadata.name)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterImageTemplate
+metadata:
+  name: kpack
+spec:
+  imagePath: .status.latestImage
+  template:
+    apiVersion: kpack.io/v1alpha2
+    kind: Image
+    metadata:
+      name: $(workload.metadata.name)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: deploy
+spec:
+  template:
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: $(workload.metadata.name)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: unused
+spec:
+  template:
+    apiVersion: v1
+    kind: Secret
+    metadata:
+      name: $(workload.metadata.name)$
+`
+
+const supplyChainYAML = `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: source-to-deploy
+spec:
+  selector:
+    workload-type: web
+  resources:
+    - name: source-provider
+      templateRef:
+        kind: ClusterSourceTemplate
+        name: git-source
+    - name: image-builder
+      templateRef:
+        kind: ClusterImageTemplate
+        name: kpack
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: deploy
+`
+
+var _ = Describe("Generate", func() {
+	generate := func(docs ...string) (*rbacv1.ClusterRole, []string) {
+		manifests := &render.Manifests{}
+		for _, doc := range docs {
+			Expect(manifests.Load(strings.NewReader(doc))).To(Succeed())
+		}
+		role, uncovered := rbac.Generate(manifests, "cartographer-stamped")
+
+		var reasons []string
+		for _, u := range uncovered {
+			reasons = append(reasons, u.String())
+		}
+		return role, reasons
+	}
+
+	It("grants the controller access to the kinds stamped by the blueprints' templates", func() {
+		role, uncovered := generate(templatesYAML, supplyChainYAML)
+		Expect(uncovered).To(BeEmpty())
+
+		Expect(role.Name).To(Equal("cartographer-stamped"))
+		Expect(role.Kind).To(Equal("ClusterRole"))
+		Expect(role.Labels).To(Equal(map[string]string{rbac.AggregateToControllerLabel: "true"}))
+
+		manage := []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+		Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: manage},
+			{APIGroups: []string{"kpack.io"}, Resources: []string{"images"}, Verbs: manage},
+			{APIGroups: []string{"source.toolkit.fluxcd.io"}, Resources: []string{"gitrepositories"}, Verbs: manage},
+		}))
+	})
+
+	It("grants read access to where the results of jobs are read from", func() {
+		role, _ := generate(`
+apiVersion: carto.run/v1alpha1
+kind: ClusterConfigTemplate
+metadata:
+  name: tests
+spec:
+  configPath: .tests
+  lifecycle: job
+  template:
+    apiVersion: batch/v1
+    kind: Job
+    metadata:
+      generateName: $(workload.metadata.name)$-tests-
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterConfigTemplate
+metadata:
+  name: scan
+spec:
+  configPath: .report
+  lifecycle: job
+  results:
+    from: ConfigMap
+  template:
+    apiVersion: batch/v1
+    kind: Job
+    metadata:
+      generateName: $(workload.metadata.name)$-scan-
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: test-and-scan
+spec:
+  selector:
+    workload-type: web
+  resources:
+    - name: tests
+      templateRef:
+        kind: ClusterConfigTemplate
+        name: tests
+    - name: scan
+      templateRef:
+        kind: ClusterConfigTemplate
+        name: scan
+`)
+
+		Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps", "pods"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+		}))
+	})
+
+	It("grants access to the Jobs of pre-delete hooks", func() {
+		role, uncovered := generate(`
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: dns-cleanup
+spec:
+  template:
+    apiVersion: batch/v1
+    kind: Job
+    metadata:
+      generateName: $(workload.metadata.name)$-dns-cleanup-
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: web
+spec:
+  selector:
+    workload-type: web
+  resources: []
+  preDelete:
+    - name: release-dns
+      templateRef:
+        kind: ClusterTemplate
+        name: dns-cleanup
+`)
+		Expect(uncovered).To(BeEmpty())
+		Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{
+			{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+		}))
+	})
+
+	It("grants access to the kinds of every item of List templates", func() {
+		role, uncovered := generate(`
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: app
+spec:
+  template:
+    apiVersion: v1
+    kind: List
+    items:
+      - apiVersion: apps/v1
+        kind: Deployment
+        metadata:
+          name: $(workload.metadata.name)$
+      - apiVersion: v1
+        kind: Service
+        metadata:
+          name: $(workload.metadata.name)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: web
+spec:
+  selector:
+    workload-type: web
+  resources:
+    - name: app
+      templateRef:
+        kind: ClusterTemplate
+        name: app
+`)
+		Expect(uncovered).To(BeEmpty())
+		manage := []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+		Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: manage},
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: manage},
+		}))
+	})
+
+	It("grants access to the runs Pipelines stamp and the objects they select", func() {
+		role, uncovered := generate(`
+apiVersion: carto.run/v1alpha1
+kind: ClusterRunTemplate
+metadata:
+  name: tekton-tests
+spec:
+  template:
+    apiVersion: tekton.dev/v1beta1
+    kind: PipelineRun
+    metadata:
+      generateName: $(runnable.metadata.name)$-
+---
+apiVersion: carto.run/v1alpha1
+kind: Pipeline
+metadata:
+  name: tests
+  namespace: dev
+spec:
+  runTemplateRef:
+    name: tekton-tests
+  selector:
+    resource:
+      apiVersion: source.toolkit.fluxcd.io/v1beta1
+      kind: GitRepository
+    matchingLabels:
+      app: web
+---
+apiVersion: carto.run/v1alpha1
+kind: Pipeline
+metadata:
+  name: unselected
+  namespace: dev
+spec:
+  runTemplateRef:
+    name: tekton-tests
+`)
+		Expect(uncovered).To(BeEmpty())
+		Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{
+			{APIGroups: []string{"source.toolkit.fluxcd.io"}, Resources: []string{"gitrepositories"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{"tekton.dev"}, Resources: []string{"pipelineruns"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+		}))
+	})
+
+	It("reports the run templates and selectors of Pipelines it cannot inspect", func() {
+		role, uncovered := generate(`
+apiVersion: carto.run/v1alpha1
+kind: Pipeline
+metadata:
+  name: tests
+  namespace: dev
+spec:
+  runTemplateRef:
+    name: missing
+  selector:
+    resource:
+      kind: GitRepository
+    matchingLabels:
+      app: web
+`)
+		Expect(role.Rules).To(BeEmpty())
+		Expect(uncovered).To(Equal([]string{
+			"ClusterRunTemplate/missing: template not found",
+			"Pipeline/tests: selector has no apiVersion or kind",
+		}))
+	})
+
+	It("reports the templates it cannot inspect", func() {
+		role, uncovered := generate(`
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: ytt-deploy
+spec:
+  ytt: |
+    apiVersion: apps/v1
+    kind: Deployment
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: any-kind
+spec:
+  template:
+    apiVersion: $(params.apiVersion)$
+    kind: $(params.kind)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterDelivery
+metadata:
+  name: delivery
+spec:
+  selector:
+    app: web
+  resources:
+    - name: ytt
+      templateRef:
+        kind: ClusterTemplate
+        name: ytt-deploy
+    - name: templated
+      templateRef:
+        kind: ClusterTemplate
+        name: any-kind
+    - name: missing
+      templateRef:
+        kind: ClusterTemplate
+        name: missing
+`)
+
+		Expect(role.Rules).To(BeEmpty())
+		Expect(uncovered).To(Equal([]string{
+			"ClusterTemplate/ytt-deploy: ytt templates are not inspected",
+			"ClusterTemplate/any-kind: apiVersion or kind is templated",
+			"ClusterTemplate/missing: template not found",
+		}))
+	})
+})
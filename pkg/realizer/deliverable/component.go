@@ -16,10 +16,33 @@ package deliverable
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
+	"github.com/vmware-tanzu/cartographer/pkg/eval"
+	"github.com/vmware-tanzu/cartographer/pkg/gitops"
+	"github.com/vmware-tanzu/cartographer/pkg/history"
+	"github.com/vmware-tanzu/cartographer/pkg/jobs"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
+	"github.com/vmware-tanzu/cartographer/pkg/propagation"
+	"github.com/vmware-tanzu/cartographer/pkg/redact"
 	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/scheduling"
+	"github.com/vmware-tanzu/cartographer/pkg/scope"
 	"github.com/vmware-tanzu/cartographer/pkg/templates"
+	"github.com/vmware-tanzu/cartographer/pkg/tracing"
+	"github.com/vmware-tanzu/cartographer/pkg/utils"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -27,23 +50,75 @@ import (
 //counterfeiter:generate . ResourceRealizer
 type ResourceRealizer interface {
 	Do(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, deliveryName string, outputs Outputs) (*templates.Output, error)
+	// LastOutput returns the output last recorded for the named resource, or
+	// nil if there is none.
+	LastOutput(resourceName string) (*templates.Output, error)
+	// RecordLastOutputs replaces the outputs recorded in the deliverable's status
+	// with outputs.
+	RecordLastOutputs(outputs Outputs) error
+	// RecordRetry counts an attempt to realize the named resource that
+	// failed or had to wait in the deliverable's status.
+	RecordRetry(resourceName string)
+	// RealizedResources returns the resources that output, in this
+	// realization, in name order.
+	RealizedResources() []v1alpha1.RealizedResource
+	// Libraries returns the names of the template libraries included by
+	// the templates of the resources reached, in this realization, in name
+	// order.
+	Libraries() []string
+	// StampedObjects returns the objects stamped, in this realization, in
+	// the order they were stamped, including those that have not output yet.
+	StampedObjects() []v1alpha1.ObjectReference
 }
 
 type resourceRealizer struct {
-	deliverable *v1alpha1.Deliverable
-	repo        repository.Repository
+	deliverable        *v1alpha1.Deliverable
+	repo               repository.Repository
+	serviceAccountRepo repository.ServiceAccountRepository
+	targetRepo         repository.Repository
+	target             string
+	resources          []v1alpha1.RealizedResource
+	stamped            []v1alpha1.ObjectReference
+	libraries          map[string]bool
+}
+
+func NewResourceRealizer(deliverable *v1alpha1.Deliverable, repo repository.Repository, serviceAccountRepo repository.ServiceAccountRepository) ResourceRealizer {
+	return &resourceRealizer{
+		deliverable:        deliverable,
+		repo:               repo,
+		serviceAccountRepo: serviceAccountRepo,
+	}
 }
 
-func NewResourceRealizer(deliverable *v1alpha1.Deliverable, repo repository.Repository) ResourceRealizer {
+// NewRemoteResourceRealizer returns a ResourceRealizer that applies the
+// objects it stamps to the remote cluster targetRepo makes requests to.
+// Templates are still read with repo. target names the cluster in errors.
+func NewRemoteResourceRealizer(deliverable *v1alpha1.Deliverable, repo repository.Repository, targetRepo repository.Repository, target string) ResourceRealizer {
 	return &resourceRealizer{
 		deliverable: deliverable,
 		repo:        repo,
+		targetRepo:  targetRepo,
+		target:      target,
 	}
 }
 
-func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, deliveryName string, outputs Outputs) (*templates.Output, error) {
-	template, err := r.repo.GetDeliveryClusterTemplate(resource.TemplateRef)
+func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, deliveryName string, outputs Outputs) (output *templates.Output, err error) {
+	ctx, span := tracing.Start(ctx, "resource.realize", attribute.String("resource.name", resource.Name))
+	defer func() { tracing.End(span, err) }()
+
+	resolveCtx, resolveSpan := tracing.Start(ctx, "template.resolve",
+		attribute.String("template.kind", resource.TemplateRef.Kind),
+		attribute.String("template.name", resource.TemplateRef.DisplayName()),
+	)
+	template, err := r.repo.GetDeliveryClusterTemplate(resolveCtx, resource.TemplateRef)
+	tracing.End(resolveSpan, err)
 	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, TemplateNotFoundError{
+				Err:         err,
+				TemplateRef: resource.TemplateRef,
+			}
+		}
 		return nil, GetDeliveryClusterTemplateError{
 			Err:         err,
 			TemplateRef: resource.TemplateRef,
@@ -59,12 +134,25 @@ func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.ClusterDel
 		"carto.run/cluster-template-name": template.GetName(),
 	}
 
-	inputs := outputs.GenerateInputs(resource)
+	inputs, err := outputs.GenerateInputs(resource)
+	if err != nil {
+		return nil, StampError{
+			Err:      err,
+			Resource: resource,
+		}
+	}
+	params := templates.ParamsBuilder(template.GetDefaultParams(), resource.Params)
+	redactor := redact.FromContext(ctx)
+	for _, value := range templates.SensitiveParams(template.GetDefaultParams(), resource.Params, params) {
+		redactor.Add(value)
+	}
+
 	templatingContext := map[string]interface{}{
-		"deliverable": r.deliverable,
-		"params":      templates.ParamsBuilder(template.GetDefaultParams(), resource.Params),
-		"sources":     inputs.Sources,
-		"configs":     inputs.Configs,
+		"deliverable":        r.templatingDeliverable(),
+		"deliverableSources": r.templatingSources(),
+		"params":             params,
+		"sources":            inputs.Sources,
+		"configs":            inputs.Configs,
 	}
 
 	// Todo: this belongs in Stamp.
@@ -75,8 +163,35 @@ func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.ClusterDel
 		templatingContext["source"] = inputs.OnlySource()
 	}
 
+	if cluster := clusterdata.FromContext(ctx); cluster != nil {
+		templatingContext["cluster"] = cluster
+	}
+
+	if err := r.includeLibraries(ctx, template.GetResourceTemplate().Libraries, templatingContext); err != nil {
+		return nil, StampError{
+			Err:      err,
+			Resource: resource,
+		}
+	}
+
 	stampContext := templates.StamperBuilder(r.deliverable, templatingContext, labels)
-	stampedObject, err := stampContext.Stamp(ctx, template.GetResourceTemplate())
+	if patcher, ok := template.(templates.ConfigPatcher); ok && patcher.GetPatches() != nil {
+		return r.patch(ctx, resource, template, stampContext, patcher, *inputs)
+	}
+
+	stampCtx, stampSpan := tracing.Start(ctx, "template.stamp")
+	stampedObjects, err := stampContext.StampAll(stampCtx, template.GetResourceTemplate())
+	isJob := template.GetResourceTemplate().IsJob()
+	orphan := v1alpha1.OrphansOnDeletion(resource.DeletionPolicy, r.deliverable.Spec.DeletionPolicy)
+	for _, stampedObject := range stampedObjects {
+		if err = r.prepare(ctx, resource, stampedObject, orphan); err != nil {
+			break
+		}
+	}
+	if err == nil && isJob {
+		err = jobs.Identify(stampedObjects[0])
+	}
+	tracing.End(stampSpan, err)
 	if err != nil {
 		return nil, StampError{
 			Err:      err,
@@ -84,21 +199,471 @@ func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.ClusterDel
 		}
 	}
 
-	err = r.repo.EnsureObjectExistsOnCluster(stampedObject, true)
+	if name := template.GetResourceTemplate().Plugin; name != "" {
+		outputSource, err := r.pluginResults(ctx, resource, name, stampedObjects)
+		if err != nil {
+			return nil, err
+		}
+		return r.output(ctx, resource, template, stampedObjects[0], outputSource)
+	}
+
+	stampingRepo, err := r.stampingRepo(ctx, resource)
 	if err != nil {
 		return nil, ApplyStampedObjectError{
 			Err:           err,
-			StampedObject: stampedObject,
+			StampedObject: stampedObjects[0],
+		}
+	}
+	for _, stampedObject := range stampedObjects {
+		if err := r.apply(ctx, stampingRepo, resource, stampedObject, isJob); err != nil {
+			return nil, err
+		}
+		if resource.Submission == v1alpha1.PullRequestSubmission {
+			// the object is proposed, not applied: nothing of it is on the
+			// cluster to be cleaned up.
+			continue
+		}
+
+		r.stamped = append(r.stamped, v1alpha1.ObjectReference{
+			APIVersion: stampedObject.GetAPIVersion(),
+			Kind:       stampedObject.GetKind(),
+			Namespace:  stampedObject.GetNamespace(),
+			Name:       stampedObject.GetName(),
+		})
+	}
+
+	// a template stamping several objects is read through its first
+	stampedObject := stampedObjects[0]
+	outputSource := stampedObject
+	if isJob {
+		outputSource, err = r.jobResults(ctx, stampingRepo, resource, template, stampedObject)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	output, err := template.GetOutput(stampedObject)
+	return r.output(ctx, resource, template, stampedObject, outputSource)
+}
+
+// output publishes and extracts the outputs of resource from outputSource,
+// recording them as realized from stampedObject.
+func (r *resourceRealizer) output(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, template templates.Template, stampedObject, outputSource *unstructured.Unstructured) (*templates.Output, error) {
+	r.publish(ctx, resource, outputSource)
+
+	_, outputSpan := tracing.Start(ctx, "output.extract")
+	output, err := template.GetOutput(outputSource)
+	tracing.End(outputSpan, err)
 	if err != nil {
+		if errors.As(err, &utils.JsonPathParseError{}) {
+			return nil, OutputPathError{
+				Err:      err,
+				resource: resource,
+			}
+		}
 		return nil, RetrieveOutputError{
 			Err:      err,
 			resource: resource,
 		}
 	}
 
+	r.recordMissingOutputs(resource.Name, output)
+	r.recordRealized(ctx, resource.Name, template, stampedObject, output)
 	return output, nil
 }
+
+// includeLibraries adds the partials and helpers of the named libraries to
+// the templating context, remembering the names whether or not they exist so
+// that the owner is realized again when they change.
+func (r *resourceRealizer) includeLibraries(ctx context.Context, names []string, templatingContext map[string]interface{}) error {
+	for _, name := range names {
+		if r.libraries == nil {
+			r.libraries = map[string]bool{}
+		}
+		r.libraries[name] = true
+	}
+
+	libraries, err := templates.GetLibraries(ctx, r.repo.GetTemplateLibrary, names)
+	if err != nil {
+		return err
+	}
+	return templates.IncludeLibraries(libraries, templatingContext)
+}
+
+// patch outputs the config input of resource that patches name, patched,
+// for templates that patch a config rather than stamp an object.
+func (r *resourceRealizer) patch(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, template templates.Template, stampContext templates.Stamper, patcher templates.ConfigPatcher, inputs templates.Inputs) (*templates.Output, error) {
+	_, patchSpan := tracing.Start(ctx, "config.patch")
+	stampContext.MissingKey = template.GetResourceTemplate().MissingKey()
+	output, err := templates.PatchConfig(stampContext, *patcher.GetPatches(), inputs)
+	tracing.End(patchSpan, err)
+	if err != nil {
+		return nil, StampError{
+			Err:      err,
+			Resource: resource,
+		}
+	}
+
+	output.Sensitive = patcher.GetSensitiveOutputs()
+	r.recordRealized(ctx, resource.Name, template, &unstructured.Unstructured{}, output)
+	return output, nil
+}
+
+// recordRealized records the output of the named resource as realized, its
+// sensitive values as to be redacted.
+func (r *resourceRealizer) recordRealized(ctx context.Context, resourceName string, template templates.Template, stampedObject *unstructured.Unstructured, output *templates.Output) {
+	redactor := redact.FromContext(ctx)
+	for _, value := range output.SensitiveValues() {
+		redactor.Add(value)
+	}
+
+	realized := history.Resource(resourceName, template, stampedObject, output)
+	realized.SourceRevision = redactor.String(realized.SourceRevision)
+	r.resources = append(r.resources, realized)
+}
+
+// prepare readies an object stamped for resource to be applied: with the
+// deliverable's metadata and the resource's scheduling, owned as the
+// resource's deletion policy and ownership say. An object of a
+// cluster-scoped kind is stamped outside any namespace, where the
+// deliverable cannot own it.
+func (r *resourceRealizer) prepare(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, stampedObject *unstructured.Unstructured, orphan bool) error {
+	clusterScoped, err := scope.ClusterScoped(scope.FromContext(ctx), stampedObject)
+	if err != nil {
+		return err
+	}
+	if clusterScoped {
+		stampedObject.SetNamespace("")
+		stampedObject.SetOwnerReferences(nil)
+	}
+	propagation.Apply(stampedObject, r.deliverable.Labels, r.deliverable.Annotations, resource.Propagation)
+	if err := scheduling.Inject(stampedObject, resource.Scheduling); err != nil {
+		return err
+	}
+	if r.targetRepo != nil || resource.Submission == v1alpha1.PullRequestSubmission {
+		// the deliverable does not exist on the remote cluster, nor where
+		// the object is applied once proposed, to have it garbage
+		// collected.
+		stampedObject.SetOwnerReferences(nil)
+	}
+	if orphan {
+		// without an owner reference, the object is not garbage collected
+		// along with the deliverable.
+		stampedObject.SetOwnerReferences(nil)
+	} else if resource.Ownership == v1alpha1.TrackedOwnership {
+		r.track(stampedObject)
+	}
+	return nil
+}
+
+// apply ensures an object stamped for resource exists on the cluster, the
+// deliverable's target cluster when it has one, as it was stamped.
+func (r *resourceRealizer) apply(ctx context.Context, stampingRepo repository.Repository, resource *v1alpha1.ClusterDeliveryResource, stampedObject *unstructured.Unstructured, isJob bool) error {
+	applyCtx, applySpan := tracing.Start(ctx, "object.apply",
+		attribute.String("object.kind", stampedObject.GetKind()),
+		attribute.String("object.namespace", stampedObject.GetNamespace()),
+		attribute.String("object.name", stampedObject.GetName()),
+	)
+	if resource.Drift == v1alpha1.DetectDrift {
+		applyCtx = repository.WithDriftDetection(applyCtx, r.recordDrift(resource.Name))
+	}
+	if resource.DryRun {
+		applyCtx = repository.WithDryRun(applyCtx)
+	}
+	var err error
+	if isJob {
+		err = stampingRepo.EnsureImmutableObjectExistsOnCluster(applyCtx, stampedObject)
+	} else {
+		err = stampingRepo.EnsureObjectExistsOnCluster(applyCtx, stampedObject, true)
+	}
+	if err != nil && r.targetRepo != nil && resource.Submission != v1alpha1.PullRequestSubmission {
+		err = fmt.Errorf("target cluster '%s': %w", r.target, err)
+	}
+	tracing.End(applySpan, err)
+	if err != nil {
+		if errors.As(err, &policy.ViolationError{}) {
+			return PolicyViolationError{
+				Err:           err,
+				StampedObject: stampedObject,
+			}
+		}
+		if errors.As(err, &repository.RejectedError{}) {
+			return StampedObjectRejectedError{
+				Err:           err,
+				StampedObject: stampedObject,
+			}
+		}
+		if repository.IsKindNotRegistered(err) {
+			return KindNotRegisteredError{
+				Err:           err,
+				StampedObject: stampedObject,
+			}
+		}
+		if kerrors.IsConflict(err) || kerrors.IsAlreadyExists(err) {
+			return ApplyConflictError{
+				Err:           err,
+				StampedObject: stampedObject,
+			}
+		}
+		return ApplyStampedObjectError{
+			Err:           err,
+			StampedObject: stampedObject,
+		}
+	}
+	return nil
+}
+
+// jobResults returns the results of the job stamped for resource, once it
+// has completed, as the object the template's outputs are read from. Jobs
+// run for earlier inputs are then deleted. repo is the repository the job
+// was applied with.
+func (r *resourceRealizer) jobResults(ctx context.Context, repo repository.Repository, resource *v1alpha1.ClusterDeliveryResource, template templates.Template, job *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	results, err := jobs.Results(ctx, repo, job, template.GetResourceTemplate().Results)
+	if err != nil {
+		if errors.As(err, &jobs.RunningError{}) {
+			return nil, JobRunningError{Err: err, Resource: resource}
+		}
+		if errors.As(err, &jobs.FailedError{}) {
+			return nil, JobFailedError{Err: err, Resource: resource}
+		}
+		return nil, RetrieveOutputError{Err: err, resource: resource}
+	}
+
+	if err := jobs.Prune(ctx, repo, job); err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "prune earlier job runs", "resource", resource.Name)
+	}
+
+	return &unstructured.Unstructured{Object: results}, nil
+}
+
+// pluginResults has the named plugin realize the objects stamped for
+// resource, rather than submitting them to the cluster, and returns its
+// outputs, once it is ready, as the object the template's outputs are read
+// from.
+func (r *resourceRealizer) pluginResults(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, name string, stampedObjects []*unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	pluginCtx, pluginSpan := tracing.Start(ctx, "plugin.realize", attribute.String("plugin.name", name))
+	owner := v1alpha1.ObjectReference{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "Deliverable",
+		Namespace:  r.deliverable.Namespace,
+		Name:       r.deliverable.Name,
+	}
+	results, err := plugin.Results(pluginCtx, name, owner, resource.Name, stampedObjects)
+	tracing.End(pluginSpan, err)
+	if err != nil {
+		if errors.As(err, &plugin.RunningError{}) {
+			return nil, PluginRunningError{Err: err, Resource: resource}
+		}
+		if errors.As(err, &plugin.FailedError{}) {
+			return nil, PluginFailedError{Err: err, Resource: resource}
+		}
+		return nil, RetrieveOutputError{Err: err, resource: resource}
+	}
+	return results, nil
+}
+
+// publish records the values resource publishes, read from obj, in the
+// deliverable's status. Values not found in obj are left out, and sensitive
+// ones are marked to be redacted.
+func (r *resourceRealizer) publish(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, obj *unstructured.Unstructured) {
+	evaluator := eval.EvaluatorBuilder()
+	for _, output := range resource.Publish {
+		value, err := evaluator.EvaluateJsonPath(output.Path, obj.UnstructuredContent())
+		if err != nil {
+			continue
+		}
+		if output.Sensitive {
+			redact.FromContext(ctx).Add(value)
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		r.recordOutput(v1alpha1.DeliverableOutput{
+			Name:     output.Name,
+			Resource: resource.Name,
+			Value:    apiextensionsv1.JSON{Raw: raw},
+		})
+	}
+}
+
+func (r *resourceRealizer) recordOutput(output v1alpha1.DeliverableOutput) {
+	for i, recorded := range r.deliverable.Status.Outputs {
+		if recorded.Name == output.Name {
+			r.deliverable.Status.Outputs[i] = output
+			return
+		}
+	}
+	r.deliverable.Status.Outputs = append(r.deliverable.Status.Outputs, output)
+}
+
+// templatingDeliverable returns the deliverable as templates see it: under
+// its name prefix, when one is set.
+// track ties the stamped object to the deliverable by its labels and the
+// deliverable's UID rather than an owner reference, for the delivery
+// reconciler to delete it once the deliverable is gone.
+func (r *resourceRealizer) track(stampedObject *unstructured.Unstructured) {
+	stampedObject.SetOwnerReferences(nil)
+	annotations := stampedObject.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[v1alpha1.TrackedOwnerAnnotation] = string(r.deliverable.UID)
+	stampedObject.SetAnnotations(annotations)
+}
+
+func (r *resourceRealizer) templatingDeliverable() *v1alpha1.Deliverable {
+	if r.deliverable.Spec.NamePrefix == "" {
+		return r.deliverable
+	}
+	deliverable := r.deliverable.DeepCopy()
+	deliverable.Name = deliverable.Spec.NamePrefix
+	return deliverable
+}
+
+// templatingSource is a named source of the deliverable as templates see
+// it: as declared, with how it was last resolved when it is an OCI source.
+type templatingSource struct {
+	v1alpha1.Source `json:",inline"`
+	Resolved        *v1alpha1.ResolvedSource `json:"resolved,omitempty"`
+}
+
+// templatingSources returns the deliverable's named sources, by name.
+func (r *resourceRealizer) templatingSources() map[string]templatingSource {
+	sources := map[string]templatingSource{}
+	for _, named := range r.deliverable.Spec.Sources {
+		source := templatingSource{Source: named.Source}
+		for i := range r.deliverable.Status.Sources {
+			if r.deliverable.Status.Sources[i].Name == named.Name {
+				source.Resolved = &r.deliverable.Status.Sources[i].ResolvedSource
+				break
+			}
+		}
+		sources[named.Name] = source
+	}
+	return sources
+}
+
+// stampingRepo returns the repository that writes the object stamped for
+// resource: the manifests proposed in the delivery's pull request, when the
+// resource is submitted as one, the remote cluster's, when the delivery
+// targets one, or one acting as the resource's service account, when it
+// names one.
+func (r *resourceRealizer) stampingRepo(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource) (repository.Repository, error) {
+	if resource.Submission == v1alpha1.PullRequestSubmission {
+		manifests := gitops.FromContext(ctx)
+		if manifests == nil {
+			return nil, fmt.Errorf("resource is submitted as a pull request, but its delivery has no pullRequest")
+		}
+		return manifests, nil
+	}
+	if r.targetRepo != nil {
+		if resource.ServiceAccountName != "" {
+			return nil, fmt.Errorf("serviceAccountName cannot be used when the delivery targets a remote cluster")
+		}
+		return r.targetRepo, nil
+	}
+	if resource.ServiceAccountName == "" {
+		return r.repo, nil
+	}
+	return r.serviceAccountRepo(r.deliverable.Namespace, resource.ServiceAccountName)
+}
+
+// recordMissingOutputs lists, in the deliverable's status, the optional
+// outputs the named resource's object had not populated.
+func (r *resourceRealizer) recordMissingOutputs(resourceName string, output *templates.Output) {
+	for _, missing := range output.Missing {
+		r.deliverable.Status.MissingOptionalOutputs = append(r.deliverable.Status.MissingOptionalOutputs, v1alpha1.MissingOutput{
+			Resource:   resourceName,
+			Output:     missing.Name,
+			Expression: missing.Expression,
+		})
+	}
+}
+
+// recordDrift returns a reporter listing the objects stamped for the named
+// resource that drifted in the deliverable's status.
+func (r *resourceRealizer) recordDrift(resourceName string) repository.ChangeReporter {
+	return func(obj *unstructured.Unstructured, changes []repository.Change) {
+		drifted := v1alpha1.DriftedObject{
+			Resource: resourceName,
+			Object: v1alpha1.ObjectReference{
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       obj.GetKind(),
+				Namespace:  obj.GetNamespace(),
+				Name:       obj.GetName(),
+			},
+		}
+		for _, change := range changes {
+			drifted.Fields = append(drifted.Fields, change.Path)
+		}
+		r.deliverable.Status.Drifted = append(r.deliverable.Status.Drifted, drifted)
+	}
+}
+
+func (r *resourceRealizer) LastOutput(resourceName string) (*templates.Output, error) {
+	for _, last := range r.deliverable.Status.LastOutputs {
+		if last.Resource == resourceName {
+			return templates.StaleOutput(last)
+		}
+	}
+	return nil, nil
+}
+
+// RecordLastOutputs leaves out the outputs with sensitive values, which
+// status cannot hold, so that resources consuming them wait for new ones.
+func (r *resourceRealizer) RecordLastOutputs(outputs Outputs) error {
+	names := make([]string, 0, len(outputs))
+	for name, output := range outputs {
+		if len(output.Sensitive) > 0 {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lastOutputs []v1alpha1.LastOutput
+	for _, name := range names {
+		last, err := templates.NewLastOutput(name, outputs[name])
+		if err != nil {
+			return err
+		}
+		lastOutputs = append(lastOutputs, last)
+	}
+	r.deliverable.Status.LastOutputs = lastOutputs
+	return nil
+}
+
+func (r *resourceRealizer) RealizedResources() []v1alpha1.RealizedResource {
+	resources := append([]v1alpha1.RealizedResource{}, r.resources...)
+	history.Sort(resources)
+	return resources
+}
+
+func (r *resourceRealizer) Libraries() []string {
+	var libraries []string
+	for library := range r.libraries {
+		libraries = append(libraries, library)
+	}
+	sort.Strings(libraries)
+	return libraries
+}
+
+func (r *resourceRealizer) StampedObjects() []v1alpha1.ObjectReference {
+	return r.stamped
+}
+
+func (r *resourceRealizer) RecordRetry(resourceName string) {
+	var retries []v1alpha1.ResourceRetries
+	counted := false
+	for _, entry := range r.deliverable.Status.Retries {
+		if entry.Resource == resourceName {
+			entry.Retries++
+			counted = true
+		}
+		retries = append(retries, entry)
+	}
+	if !counted {
+		retries = append(retries, v1alpha1.ResourceRetries{Resource: resourceName, Retries: 1})
+	}
+	r.deliverable.Status.Retries = retries
+}
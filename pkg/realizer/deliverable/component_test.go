@@ -18,18 +18,33 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
 	"github.com/vmware-tanzu/cartographer/pkg/eval"
+	"github.com/vmware-tanzu/cartographer/pkg/gitops"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin/pluginfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
 	realizer "github.com/vmware-tanzu/cartographer/pkg/realizer/deliverable"
+	"github.com/vmware-tanzu/cartographer/pkg/redact"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
 	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/scope"
 	"github.com/vmware-tanzu/cartographer/pkg/templates"
 )
 
@@ -41,6 +56,9 @@ var _ = Describe("Resource", func() {
 		outputs      realizer.Outputs
 		deliveryName string
 		fakeRepo     repositoryfakes.FakeRepository
+		saRepo       repositoryfakes.FakeRepository
+		saRepoErr    error
+		impersonated []string
 		r            realizer.ResourceRealizer
 	)
 
@@ -59,7 +77,14 @@ var _ = Describe("Resource", func() {
 
 		fakeRepo = repositoryfakes.FakeRepository{}
 		deliverable = v1alpha1.Deliverable{}
-		r = realizer.NewResourceRealizer(&deliverable, &fakeRepo)
+		saRepo = repositoryfakes.FakeRepository{}
+		saRepoErr = nil
+		impersonated = nil
+		serviceAccountRepo := func(namespace, serviceAccountName string) (repository.Repository, error) {
+			impersonated = append(impersonated, namespace+"/"+serviceAccountName)
+			return &saRepo, saRepoErr
+		}
+		r = realizer.NewResourceRealizer(&deliverable, &fakeRepo, serviceAccountRepo)
 	})
 
 	Describe("Do", func() {
@@ -122,7 +147,7 @@ var _ = Describe("Resource", func() {
 				out, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
 				Expect(err).ToNot(HaveOccurred())
 
-				stampedObject, allowUpdate := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				_, stampedObject, allowUpdate := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
 				Expect(allowUpdate).To(BeTrue())
 				metadata := stampedObject.Object["metadata"]
 				metadataValues, ok := metadata.(map[string]interface{})
@@ -131,8 +156,8 @@ var _ = Describe("Resource", func() {
 				Expect(metadataValues["namespace"]).To(Equal("some-namespace"))
 				Expect(metadataValues["ownerReferences"]).To(Equal([]interface{}{
 					map[string]interface{}{
-						"apiVersion":         "",
-						"kind":               "",
+						"apiVersion":         "carto.run/v1alpha1",
+						"kind":               "Deliverable",
 						"name":               "",
 						"uid":                "",
 						"controller":         true,
@@ -152,6 +177,777 @@ var _ = Describe("Resource", func() {
 				Expect(out.Source.Revision).To(Equal("some-revision"))
 				Expect(out.Source.URL).To(Equal("some-url"))
 			})
+
+			It("keeps the resources as realized in this realization", func() {
+				Expect(r.RealizedResources()).To(BeEmpty())
+
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(r.RealizedResources()).To(Equal([]v1alpha1.RealizedResource{{
+					Resource:       "resource-1",
+					Template:       v1alpha1.ObjectReference{Kind: "ClusterSourceTemplate", Name: "source-template-1"},
+					SourceRevision: "some-revision",
+					Stamped:        v1alpha1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "some-namespace", Name: "example-config-map"},
+				}}))
+			})
+
+			It("remediates drift of the stamped object by default", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				ctx, _, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(repository.DriftReporterFrom(ctx)).To(BeNil())
+			})
+
+			Context("and the resource detects drift", func() {
+				BeforeEach(func() {
+					resource.Drift = v1alpha1.DetectDrift
+					fakeRepo.EnsureObjectExistsOnClusterStub = func(ctx context.Context, obj *unstructured.Unstructured, allowUpdate bool) error {
+						repository.DriftReporterFrom(ctx)(obj, []repository.Change{{Path: "data.some_other_info"}})
+						return nil
+					}
+				})
+
+				It("records the drifted objects the repository reports in the status", func() {
+					_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(deliverable.Status.Drifted).To(Equal([]v1alpha1.DriftedObject{{
+						Resource: "resource-1",
+						Object:   v1alpha1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "some-namespace", Name: "example-config-map"},
+						Fields:   []string{"data.some_other_info"},
+					}}))
+				})
+			})
+
+			It("dry runs the stamped object when the resource asks for it", func() {
+				resource.DryRun = true
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				ctx, _, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(repository.DryRunFrom(ctx)).To(BeTrue())
+			})
+
+			Context("and the resource orphans its object", func() {
+				BeforeEach(func() {
+					resource.DeletionPolicy = v1alpha1.OrphanDeletionPolicy
+				})
+
+				It("stamps the object without an owner reference", func() {
+					_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+					Expect(stampedObject.GetOwnerReferences()).To(BeEmpty())
+				})
+			})
+
+			Context("and the deliverable orphans its objects", func() {
+				BeforeEach(func() {
+					deliverable.Spec.DeletionPolicy = v1alpha1.OrphanDeletionPolicy
+				})
+
+				It("stamps the object without an owner reference", func() {
+					_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+					Expect(stampedObject.GetOwnerReferences()).To(BeEmpty())
+				})
+
+				It("lets the resource's own policy take precedence", func() {
+					resource.DeletionPolicy = v1alpha1.DeleteDeletionPolicy
+
+					_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+					Expect(stampedObject.GetOwnerReferences()).To(HaveLen(1))
+				})
+			})
+
+			It("publishes the values found at the resource's paths to the deliverable's status", func() {
+				deliverable.Status.Outputs = []v1alpha1.DeliverableOutput{
+					{Name: "revision", Resource: "resource-1", Value: apiextensionsv1.JSON{Raw: []byte(`"old-revision"`)}},
+				}
+				resource.Publish = []v1alpha1.PublishedOutput{
+					{Name: "revision", Path: ".data.some_other_info"},
+					{Name: "data", Path: ".data"},
+					{Name: "url", Path: ".status.url"},
+				}
+
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(deliverable.Status.Outputs).To(Equal([]v1alpha1.DeliverableOutput{
+					{Name: "revision", Resource: "resource-1", Value: apiextensionsv1.JSON{Raw: []byte(`"some-revision"`)}},
+					{Name: "data", Resource: "resource-1", Value: apiextensionsv1.JSON{Raw: []byte(`{"player_current_lives":"some-url","some_other_info":"some-revision"}`)}},
+				}))
+			})
+
+			It("marks the values of sensitive published outputs to be redacted", func() {
+				resource.Publish = []v1alpha1.PublishedOutput{
+					{Name: "revision", Path: ".data.some_other_info", Sensitive: true},
+				}
+				redactor := redact.New()
+
+				_, err := r.Do(redact.NewContext(context.TODO(), redactor), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(redactor.String("some-revision")).To(Equal("[redacted]"))
+			})
+		})
+
+		When("the deliverable has a name prefix", func() {
+			BeforeEach(func() {
+				deliverable.Name = "some-deliverable"
+				deliverable.Spec.NamePrefix = "legacy-app"
+
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: `$(deliverable.metadata.name)$-config`,
+					},
+					Data: map[string]string{
+						"value": "some-value",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterSourceTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.SourceTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						URLPath:      "data.value",
+						RevisionPath: "data.value",
+					},
+				}
+
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterSourceTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("stamps the object under the prefix while labelling it with the deliverable name", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetName()).To(Equal("legacy-app-config"))
+				Expect(stampedObject.GetLabels()).To(HaveKeyWithValue("carto.run/deliverable-name", "some-deliverable"))
+				Expect(stampedObject.GetOwnerReferences()[0].Name).To(Equal("some-deliverable"))
+				Expect(deliverable.Name).To(Equal("some-deliverable"))
+			})
+		})
+
+		When("the deliverable has named sources", func() {
+			BeforeEach(func() {
+				configURL := "https://example.com/config.git"
+				deliverable.Spec.Sources = []v1alpha1.DeliverableSource{
+					{Name: "config", Source: v1alpha1.Source{Git: &v1alpha1.GitSource{URL: &configURL}}},
+					{Name: "bundle", Source: v1alpha1.Source{OCI: &v1alpha1.OCISource{Image: "registry.example.com/app-bundle:main"}}},
+				}
+				deliverable.Status.Sources = []v1alpha1.ResolvedDeliverableSource{
+					{Name: "bundle", ResolvedSource: v1alpha1.ResolvedSource{
+						Image:  "registry.example.com/app-bundle:main",
+						Digest: "sha256:abc",
+						URL:    "registry.example.com/app-bundle:main@sha256:abc",
+					}},
+				}
+
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-config",
+					},
+					Data: map[string]string{
+						"config": `$(deliverableSources.config.git.url)$`,
+						"bundle": `$(deliverableSources.bundle.resolved.url)$`,
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterSourceTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.SourceTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						URLPath:      "data.config",
+						RevisionPath: "data.bundle",
+					},
+				}
+
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterSourceTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("lets templates reference each source by name", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.Object["data"]).To(Equal(map[string]interface{}{
+					"config": "https://example.com/config.git",
+					"bundle": "registry.example.com/app-bundle:main@sha256:abc",
+				}))
+			})
+		})
+
+		When("the resource names a service account", func() {
+			BeforeEach(func() {
+				deliverable.Namespace = "some-namespace"
+				resource.ServiceAccountName = "some-service-account"
+
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-config",
+					},
+					Data: map[string]string{
+						"value": "some-value",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterSourceTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.SourceTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						URLPath:      "data.value",
+						RevisionPath: "data.value",
+					},
+				}
+
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterSourceTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("submits the stamped object as the service account", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(impersonated).To(Equal([]string{"some-namespace/some-service-account"}))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(saRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, stampedObject, _ := saRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetName()).To(Equal("some-config"))
+			})
+
+			When("the service account's repository cannot be built", func() {
+				BeforeEach(func() {
+					saRepoErr = errors.New("bad config")
+				})
+
+				It("returns ApplyStampedObjectError", func() {
+					_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("bad config"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.ApplyStampedObjectError"))
+					Expect(saRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		When("the delivery targets a remote cluster", func() {
+			var targetRepo repositoryfakes.FakeRepository
+
+			BeforeEach(func() {
+				deliverable.Name = "some-deliverable"
+				targetRepo = repositoryfakes.FakeRepository{}
+				r = realizer.NewRemoteResourceRealizer(&deliverable, &fakeRepo, &targetRepo, "clusters/prod-kubeconfig")
+
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-config",
+					},
+					Data: map[string]string{
+						"value": "some-value",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterSourceTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.SourceTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						URLPath:      "data.value",
+						RevisionPath: "data.value",
+					},
+				}
+
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterSourceTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("reads the template locally and applies the stamped object to the remote cluster, without owner", func() {
+				out, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out.Source.URL).To(Equal("some-value"))
+
+				Expect(fakeRepo.GetDeliveryClusterTemplateCallCount()).To(Equal(1))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(targetRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, stampedObject, _ := targetRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetName()).To(Equal("some-config"))
+				Expect(stampedObject.GetOwnerReferences()).To(BeEmpty())
+				Expect(stampedObject.GetLabels()).To(HaveKeyWithValue("carto.run/deliverable-name", "some-deliverable"))
+			})
+
+			It("names the cluster in errors applying the object", func() {
+				targetRepo.EnsureObjectExistsOnClusterReturns(errors.New("connection refused"))
+
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).To(MatchError(ContainSubstring("target cluster 'clusters/prod-kubeconfig': connection refused")))
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.ApplyStampedObjectError"))
+			})
+
+			It("keeps telling conflicts apart", func() {
+				targetRepo.EnsureObjectExistsOnClusterReturns(kerrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "some-config", errors.New("modified")))
+
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.ApplyConflictError"))
+			})
+
+			It("rejects resources that name a service account", func() {
+				resource.ServiceAccountName = "some-service-account"
+
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).To(MatchError(ContainSubstring("serviceAccountName cannot be used when the delivery targets a remote cluster")))
+				Expect(targetRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the resource is submitted as a pull request", func() {
+			var manifests *gitops.Manifests
+
+			BeforeEach(func() {
+				deliverable.Name = "some-deliverable"
+				resource.Submission = v1alpha1.PullRequestSubmission
+				manifests = gitops.NewManifests(&fakeRepo)
+
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-config",
+					},
+					Data: map[string]string{
+						"value": "some-value",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterSourceTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.SourceTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						URLPath:      "data.value",
+						RevisionPath: "data.value",
+					},
+				}
+
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterSourceTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("collects the stamped object for the pull request, without owner, rather than applying it", func() {
+				out, err := r.Do(gitops.NewContext(context.TODO(), manifests), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out.Source.URL).To(Equal("some-value"))
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(manifests.Len()).To(Equal(1))
+				content, err := manifests.YAML()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: some-config"))
+				Expect(string(content)).NotTo(ContainSubstring("ownerReferences"))
+				Expect(r.StampedObjects()).To(BeEmpty())
+			})
+
+			It("returns an error when the delivery has no pull request", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).To(MatchError(ContainSubstring("resource is submitted as a pull request, but its delivery has no pullRequest")))
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.ApplyStampedObjectError"))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+			})
+
+			It("proposes the object rather than applying it to the target cluster", func() {
+				targetRepo := repositoryfakes.FakeRepository{}
+				r = realizer.NewRemoteResourceRealizer(&deliverable, &fakeRepo, &targetRepo, "clusters/prod-kubeconfig")
+
+				_, err := r.Do(gitops.NewContext(context.TODO(), manifests), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(targetRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(manifests.Len()).To(Equal(1))
+			})
+		})
+
+		When("the resource carries scheduling hints", func() {
+			BeforeEach(func() {
+				resource.Scheduling = &v1alpha1.SchedulingHints{
+					PriorityClassName: "platform-default",
+					Annotations:       map[string]string{"cost-center": "platform"},
+				}
+
+				pod := &corev1.Pod{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Pod",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-pod",
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "app", Image: "some-image"}},
+					},
+				}
+
+				dbytes, err := json.Marshal(pod)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.TemplateSpec{
+						Template: &runtime.RawExtension{Raw: dbytes},
+					},
+				}
+
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterTemplateModel(templateAPI), nil)
+			})
+
+			It("injects the hints into the stamped object", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetAnnotations()).To(HaveKeyWithValue("cost-center", "platform"))
+				Expect(stampedObject.Object["spec"]).To(HaveKeyWithValue("priorityClassName", "platform-default"))
+			})
+		})
+
+		When("the template has a job lifecycle", func() {
+			var jobStatus map[string]interface{}
+
+			BeforeEach(func() {
+				job := &batchv1.Job{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Job",
+						APIVersion: "batch/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						GenerateName: "fetch-",
+					},
+					Spec: batchv1.JobSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers:    []corev1.Container{{Name: "fetch", Image: "fetcher"}},
+								RestartPolicy: corev1.RestartPolicyNever,
+							},
+						},
+					},
+				}
+
+				dbytes, err := json.Marshal(job)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterSourceTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "fetch-template",
+					},
+					Spec: v1alpha1.SourceTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template:  &runtime.RawExtension{Raw: dbytes},
+							Lifecycle: v1alpha1.JobTemplateLifecycle,
+						},
+						URLPath:      "url",
+						RevisionPath: "revision",
+					},
+				}
+
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterSourceTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+
+				jobStatus = map[string]interface{}{}
+				fakeRepo.EnsureImmutableObjectExistsOnClusterStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					obj.Object["status"] = jobStatus
+					return nil
+				}
+			})
+
+			It("creates the job under a name derived from its spec", func() {
+				_, _ = r.Do(context.TODO(), &resource, deliveryName, outputs)
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(fakeRepo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, stampedObject := fakeRepo.EnsureImmutableObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetName()).To(MatchRegexp(`^fetch-[0-9a-f]{10}$`))
+				Expect(stampedObject.GetGenerateName()).To(BeEmpty())
+			})
+
+			It("returns JobRunningError while the job runs", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).To(HaveOccurred())
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.JobRunningError"))
+			})
+
+			It("returns JobFailedError when the job fails", func() {
+				jobStatus["conditions"] = []interface{}{
+					map[string]interface{}{"type": "Failed", "status": "True", "message": "BackoffLimitExceeded"},
+				}
+
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("BackoffLimitExceeded"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.JobFailedError"))
+			})
+
+			It("returns the outputs read from the termination message once the job completes", func() {
+				jobStatus["conditions"] = []interface{}{
+					map[string]interface{}{"type": "Complete", "status": "True"},
+				}
+				fakeRepo.ListUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+					if obj.GetKind() != "Pod" {
+						return nil, nil
+					}
+					return []*unstructured.Unstructured{{Object: map[string]interface{}{
+						"metadata": map[string]interface{}{"name": "fetch-pod"},
+						"spec": map[string]interface{}{
+							"containers": []interface{}{map[string]interface{}{"name": "fetch"}},
+						},
+						"status": map[string]interface{}{
+							"phase": "Succeeded",
+							"containerStatuses": []interface{}{map[string]interface{}{
+								"name":  "fetch",
+								"state": map[string]interface{}{"terminated": map[string]interface{}{"message": `{"url": "some-url", "revision": "some-revision"}`}},
+							}},
+						},
+					}}}, nil
+				}
+
+				out, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out.Source.URL).To(Equal("some-url"))
+				Expect(out.Source.Revision).To(Equal("some-revision"))
+			})
+		})
+
+		When("the template reads the cluster data", func() {
+			BeforeEach(func() {
+				deliverable.Name = "app"
+				deliverable.Namespace = "dev"
+
+				configMap := map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata":   map[string]interface{}{"name": "app-host"},
+					"data": map[string]interface{}{
+						"host":  "$(deliverable.metadata.name)$.$(cluster.data.ingressDomain)$",
+						"nodes": "$(cluster.nodes)$",
+					},
+				}
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "host-template"},
+					Spec: v1alpha1.TemplateSpec{
+						Template: &runtime.RawExtension{Raw: dbytes},
+					},
+				}
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterTemplateModel(templateAPI), nil)
+			})
+
+			It("stamps the template with the cluster data carried by the context", func() {
+				ctx := clusterdata.NewContext(context.TODO(), map[string]interface{}{
+					"name":   "prod",
+					"domain": "cluster.local",
+					"nodes":  int64(3),
+					"data":   map[string]interface{}{"ingressDomain": "apps.example.com"},
+				})
+				_, err := r.Do(ctx, &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.Object["data"]).To(Equal(map[string]interface{}{
+					"host":  "app.apps.example.com",
+					"nodes": float64(3),
+				}))
+			})
+
+			It("returns StampError when the context carries no cluster data", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).To(HaveOccurred())
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.StampError"))
+			})
+		})
+
+		When("the object is of a cluster-scoped kind", func() {
+			BeforeEach(func() {
+				deliverable.Name = "app"
+				deliverable.Namespace = "dev"
+
+				templateAPI := &v1alpha1.ClusterTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "namespace-template"},
+					Spec: v1alpha1.TemplateSpec{
+						Template: &runtime.RawExtension{Raw: []byte(`{
+							"apiVersion": "v1",
+							"kind": "Namespace",
+							"metadata": {"name": "$(deliverable.metadata.name)$-runtime"}
+						}`)},
+					},
+				}
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterTemplateModel(templateAPI), nil)
+			})
+
+			It("stamps the object without a namespace or an owner reference", func() {
+				mapper := meta.NewDefaultRESTMapper(nil)
+				mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+
+				_, err := r.Do(scope.NewContext(context.TODO(), mapper), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetName()).To(Equal("app-runtime"))
+				Expect(stampedObject.GetNamespace()).To(BeEmpty())
+				Expect(stampedObject.GetOwnerReferences()).To(BeEmpty())
+			})
+
+			It("stamps the object as namespaced without scope detection", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetNamespace()).To(Equal("dev"))
+				Expect(stampedObject.GetOwnerReferences()).To(HaveLen(1))
+			})
+		})
+
+		When("the template names a realizer plugin", func() {
+			var plugins *pluginfakes.FakeRealizer
+
+			BeforeEach(func() {
+				deliverable.Name = "app"
+				deliverable.Namespace = "prod"
+
+				database := map[string]interface{}{
+					"apiVersion": "example.com/v1",
+					"kind":       "Database",
+					"metadata":   map[string]interface{}{"name": "db"},
+					"spec":       map[string]interface{}{"owner": "$(deliverable.metadata.name)$"},
+				}
+				dbytes, err := json.Marshal(database)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterSourceTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "database-template",
+					},
+					Spec: v1alpha1.SourceTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+							Plugin:   "terraform",
+						},
+						URLPath:      "url",
+						RevisionPath: "revision",
+					},
+				}
+				fakeRepo.GetDeliveryClusterTemplateReturns(templates.NewClusterSourceTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+
+				plugins = &pluginfakes.FakeRealizer{}
+				plugins.RealizeReturns(map[string]interface{}{"url": "postgres://db", "revision": "v2"}, nil)
+			})
+
+			It("has the plugin realize the stamped objects instead of submitting them", func() {
+				ctx := plugin.NewContext(context.TODO(), plugins)
+				_, err := r.Do(ctx, &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(r.StampedObjects()).To(BeEmpty())
+
+				Expect(plugins.RealizeCallCount()).To(Equal(1))
+				_, request := plugins.RealizeArgsForCall(0)
+				Expect(request.Plugin).To(Equal("terraform"))
+				Expect(request.Owner).To(Equal(v1alpha1.ObjectReference{APIVersion: "carto.run/v1alpha1", Kind: "Deliverable", Namespace: "prod", Name: "app"}))
+				Expect(request.Objects).To(HaveLen(1))
+				Expect(request.Objects[0]["spec"]).To(Equal(map[string]interface{}{"owner": "app"}))
+			})
+
+			It("returns and publishes the outputs read from what the plugin returned", func() {
+				resource.Publish = []v1alpha1.PublishedOutput{
+					{Name: "url", Path: ".url"},
+				}
+
+				ctx := plugin.NewContext(context.TODO(), plugins)
+				out, err := r.Do(ctx, &resource, deliveryName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out.Source.URL).To(Equal("postgres://db"))
+				Expect(out.Source.Revision).To(Equal("v2"))
+
+				Expect(deliverable.Status.Outputs).To(Equal([]v1alpha1.DeliverableOutput{
+					{Name: "url", Resource: "resource-1", Value: apiextensionsv1.JSON{Raw: []byte(`"postgres://db"`)}},
+				}))
+			})
+
+			It("returns PluginRunningError while the plugin runs", func() {
+				plugins.RealizeReturns(nil, plugin.RunningError{Plugin: "terraform"})
+
+				ctx := plugin.NewContext(context.TODO(), plugins)
+				_, err := r.Do(ctx, &resource, deliveryName, outputs)
+				Expect(err).To(MatchError("waiting for plugin of resource 'resource-1': plugin 'terraform' is realizing the objects"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.PluginRunningError"))
+			})
+
+			It("returns PluginFailedError when the plugin fails", func() {
+				plugins.RealizeReturns(nil, plugin.FailedError{Plugin: "terraform", Message: "quota exceeded"})
+
+				ctx := plugin.NewContext(context.TODO(), plugins)
+				_, err := r.Do(ctx, &resource, deliveryName, outputs)
+				Expect(err).To(MatchError("plugin of resource 'resource-1' failed: plugin 'terraform' failed: quota exceeded"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.PluginFailedError"))
+			})
 		})
 
 		When("unable to get the template ref from repo", func() {
@@ -169,6 +965,20 @@ var _ = Describe("Resource", func() {
 			})
 		})
 
+		When("the template ref does not exist", func() {
+			BeforeEach(func() {
+				fakeRepo.GetDeliveryClusterTemplateReturns(nil, fmt.Errorf("get: %w", kerrors.NewNotFound(schema.GroupResource{Group: "carto.run", Resource: "clustersourcetemplates"}, "source-template-1")))
+			})
+
+			It("returns TemplateNotFoundError", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("template 'source-template-1' of kind 'ClusterSourceTemplate' not found"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.TemplateNotFoundError"))
+			})
+		})
+
 		When("unable to Stamp a new template", func() {
 			BeforeEach(func() {
 				templateAPI := &v1alpha1.ClusterSourceTemplate{
@@ -247,6 +1057,63 @@ var _ = Describe("Resource", func() {
 				Expect(err.Error()).To(ContainSubstring("find results: does-not-exist is not found"))
 				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.RetrieveOutputError"))
 			})
+
+			It("keeps the object as stamped, though it has not output", func() {
+				_, _ = r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(r.RealizedResources()).To(BeEmpty())
+				Expect(r.StampedObjects()).To(Equal([]v1alpha1.ObjectReference{
+					{APIVersion: "v1", Kind: "ConfigMap", Namespace: "some-namespace", Name: "example-config-map"},
+				}))
+			})
+		})
+
+		When("the output path is not a valid jsonpath expression", func() {
+			BeforeEach(func() {
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "example-config-map",
+						Namespace: "some-namespace",
+					},
+					Data: map[string]string{
+						"some_other_info": "10",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterSourceTemplate{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ClusterSourceTemplate",
+						APIVersion: "carto.run/v1alpha1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "source-template-1",
+						Namespace: "some-namespace",
+					},
+					Spec: v1alpha1.SourceTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						URLPath:      "data[",
+						RevisionPath: "data.some_other_info",
+					},
+				}
+
+				template := templates.NewClusterSourceTemplateModel(templateAPI, eval.EvaluatorBuilder())
+				fakeRepo.GetDeliveryClusterTemplateReturns(template, nil)
+			})
+
+			It("returns OutputPathError", func() {
+				_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid output path for resource 'resource-1'"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.OutputPathError"))
+			})
 		})
 
 		When("unable to EnsureObjectExistsOnCluster the stamped object", func() {
@@ -309,6 +1176,63 @@ var _ = Describe("Resource", func() {
 				Expect(err.Error()).To(ContainSubstring("bad object"))
 				Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.ApplyStampedObjectError"))
 			})
+
+			When("the object violates the stamp policy", func() {
+				BeforeEach(func() {
+					fakeRepo.EnsureObjectExistsOnClusterReturns(policy.ViolationError{Rule: "no-config-maps", Message: "config maps are not allowed"})
+				})
+
+				It("returns PolicyViolationError", func() {
+					_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+					Expect(err).To(HaveOccurred())
+
+					Expect(err.Error()).To(ContainSubstring("object 'some-namespace/example-config-map' of kind 'ConfigMap' violates policy rule 'no-config-maps': config maps are not allowed"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.PolicyViolationError"))
+				})
+			})
+
+			When("the dry run of the object is rejected", func() {
+				BeforeEach(func() {
+					fakeRepo.EnsureObjectExistsOnClusterReturns(repository.RejectedError{Err: kerrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "example-config-map", errors.New("denied by webhook"))})
+				})
+
+				It("returns StampedObjectRejectedError", func() {
+					_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+					Expect(err).To(HaveOccurred())
+
+					Expect(err.Error()).To(ContainSubstring("object 'some-namespace/example-config-map' of kind 'ConfigMap' rejected on dry run"))
+					Expect(err.Error()).To(ContainSubstring("denied by webhook"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.StampedObjectRejectedError"))
+				})
+			})
+
+			When("the kind of the object is not registered", func() {
+				BeforeEach(func() {
+					fakeRepo.EnsureObjectExistsOnClusterReturns(fmt.Errorf("list: %w", &meta.NoKindMatchError{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, SearchedVersions: []string{"v1"}}))
+				})
+
+				It("returns KindNotRegisteredError", func() {
+					_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+					Expect(err).To(HaveOccurred())
+
+					Expect(err.Error()).To(ContainSubstring("kind 'ConfigMap' of 'v1' is not registered with the apiserver"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.KindNotRegisteredError"))
+				})
+			})
+
+			When("the apiServer reports a conflict", func() {
+				BeforeEach(func() {
+					fakeRepo.EnsureObjectExistsOnClusterReturns(fmt.Errorf("patch: %w", kerrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "example-config-map", errors.New("object has been modified"))))
+				})
+
+				It("returns ApplyConflictError", func() {
+					_, err := r.Do(context.TODO(), &resource, deliveryName, outputs)
+					Expect(err).To(HaveOccurred())
+
+					Expect(err.Error()).To(ContainSubstring("conflict applying object 'some-namespace/example-config-map'"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("deliverable.ApplyConflictError"))
+				})
+			})
 		})
 	})
 })
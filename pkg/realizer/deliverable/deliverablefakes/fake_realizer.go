@@ -10,12 +10,12 @@ import (
 )
 
 type FakeRealizer struct {
-	RealizeStub        func(context.Context, deliverable.ResourceRealizer, *v1alpha1.ClusterDelivery) error
+	RealizeStub        func(context.Context, deliverable.ResourceRealizer, v1alpha1.DeliveryObject) error
 	realizeMutex       sync.RWMutex
 	realizeArgsForCall []struct {
 		arg1 context.Context
 		arg2 deliverable.ResourceRealizer
-		arg3 *v1alpha1.ClusterDelivery
+		arg3 v1alpha1.DeliveryObject
 	}
 	realizeReturns struct {
 		result1 error
@@ -27,13 +27,13 @@ type FakeRealizer struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeRealizer) Realize(arg1 context.Context, arg2 deliverable.ResourceRealizer, arg3 *v1alpha1.ClusterDelivery) error {
+func (fake *FakeRealizer) Realize(arg1 context.Context, arg2 deliverable.ResourceRealizer, arg3 v1alpha1.DeliveryObject) error {
 	fake.realizeMutex.Lock()
 	ret, specificReturn := fake.realizeReturnsOnCall[len(fake.realizeArgsForCall)]
 	fake.realizeArgsForCall = append(fake.realizeArgsForCall, struct {
 		arg1 context.Context
 		arg2 deliverable.ResourceRealizer
-		arg3 *v1alpha1.ClusterDelivery
+		arg3 v1alpha1.DeliveryObject
 	}{arg1, arg2, arg3})
 	stub := fake.RealizeStub
 	fakeReturns := fake.realizeReturns
@@ -54,13 +54,13 @@ func (fake *FakeRealizer) RealizeCallCount() int {
 	return len(fake.realizeArgsForCall)
 }
 
-func (fake *FakeRealizer) RealizeCalls(stub func(context.Context, deliverable.ResourceRealizer, *v1alpha1.ClusterDelivery) error) {
+func (fake *FakeRealizer) RealizeCalls(stub func(context.Context, deliverable.ResourceRealizer, v1alpha1.DeliveryObject) error) {
 	fake.realizeMutex.Lock()
 	defer fake.realizeMutex.Unlock()
 	fake.RealizeStub = stub
 }
 
-func (fake *FakeRealizer) RealizeArgsForCall(i int) (context.Context, deliverable.ResourceRealizer, *v1alpha1.ClusterDelivery) {
+func (fake *FakeRealizer) RealizeArgsForCall(i int) (context.Context, deliverable.ResourceRealizer, v1alpha1.DeliveryObject) {
 	fake.realizeMutex.RLock()
 	defer fake.realizeMutex.RUnlock()
 	argsForCall := fake.realizeArgsForCall[i]
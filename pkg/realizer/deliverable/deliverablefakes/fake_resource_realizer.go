@@ -27,6 +27,65 @@ type FakeResourceRealizer struct {
 		result1 *templates.Output
 		result2 error
 	}
+	LastOutputStub        func(string) (*templates.Output, error)
+	lastOutputMutex       sync.RWMutex
+	lastOutputArgsForCall []struct {
+		arg1 string
+	}
+	lastOutputReturns struct {
+		result1 *templates.Output
+		result2 error
+	}
+	lastOutputReturnsOnCall map[int]struct {
+		result1 *templates.Output
+		result2 error
+	}
+	LibrariesStub        func() []string
+	librariesMutex       sync.RWMutex
+	librariesArgsForCall []struct {
+	}
+	librariesReturns struct {
+		result1 []string
+	}
+	librariesReturnsOnCall map[int]struct {
+		result1 []string
+	}
+	RealizedResourcesStub        func() []v1alpha1.RealizedResource
+	realizedResourcesMutex       sync.RWMutex
+	realizedResourcesArgsForCall []struct {
+	}
+	realizedResourcesReturns struct {
+		result1 []v1alpha1.RealizedResource
+	}
+	realizedResourcesReturnsOnCall map[int]struct {
+		result1 []v1alpha1.RealizedResource
+	}
+	RecordLastOutputsStub        func(deliverable.Outputs) error
+	recordLastOutputsMutex       sync.RWMutex
+	recordLastOutputsArgsForCall []struct {
+		arg1 deliverable.Outputs
+	}
+	recordLastOutputsReturns struct {
+		result1 error
+	}
+	recordLastOutputsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RecordRetryStub        func(string)
+	recordRetryMutex       sync.RWMutex
+	recordRetryArgsForCall []struct {
+		arg1 string
+	}
+	StampedObjectsStub        func() []v1alpha1.ObjectReference
+	stampedObjectsMutex       sync.RWMutex
+	stampedObjectsArgsForCall []struct {
+	}
+	stampedObjectsReturns struct {
+		result1 []v1alpha1.ObjectReference
+	}
+	stampedObjectsReturnsOnCall map[int]struct {
+		result1 []v1alpha1.ObjectReference
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -98,11 +157,339 @@ func (fake *FakeResourceRealizer) DoReturnsOnCall(i int, result1 *templates.Outp
 	}{result1, result2}
 }
 
+func (fake *FakeResourceRealizer) LastOutput(arg1 string) (*templates.Output, error) {
+	fake.lastOutputMutex.Lock()
+	ret, specificReturn := fake.lastOutputReturnsOnCall[len(fake.lastOutputArgsForCall)]
+	fake.lastOutputArgsForCall = append(fake.lastOutputArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LastOutputStub
+	fakeReturns := fake.lastOutputReturns
+	fake.recordInvocation("LastOutput", []interface{}{arg1})
+	fake.lastOutputMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeResourceRealizer) LastOutputCallCount() int {
+	fake.lastOutputMutex.RLock()
+	defer fake.lastOutputMutex.RUnlock()
+	return len(fake.lastOutputArgsForCall)
+}
+
+func (fake *FakeResourceRealizer) LastOutputCalls(stub func(string) (*templates.Output, error)) {
+	fake.lastOutputMutex.Lock()
+	defer fake.lastOutputMutex.Unlock()
+	fake.LastOutputStub = stub
+}
+
+func (fake *FakeResourceRealizer) LastOutputArgsForCall(i int) string {
+	fake.lastOutputMutex.RLock()
+	defer fake.lastOutputMutex.RUnlock()
+	argsForCall := fake.lastOutputArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeResourceRealizer) LastOutputReturns(result1 *templates.Output, result2 error) {
+	fake.lastOutputMutex.Lock()
+	defer fake.lastOutputMutex.Unlock()
+	fake.LastOutputStub = nil
+	fake.lastOutputReturns = struct {
+		result1 *templates.Output
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeResourceRealizer) LastOutputReturnsOnCall(i int, result1 *templates.Output, result2 error) {
+	fake.lastOutputMutex.Lock()
+	defer fake.lastOutputMutex.Unlock()
+	fake.LastOutputStub = nil
+	if fake.lastOutputReturnsOnCall == nil {
+		fake.lastOutputReturnsOnCall = make(map[int]struct {
+			result1 *templates.Output
+			result2 error
+		})
+	}
+	fake.lastOutputReturnsOnCall[i] = struct {
+		result1 *templates.Output
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeResourceRealizer) Libraries() []string {
+	fake.librariesMutex.Lock()
+	ret, specificReturn := fake.librariesReturnsOnCall[len(fake.librariesArgsForCall)]
+	fake.librariesArgsForCall = append(fake.librariesArgsForCall, struct {
+	}{})
+	stub := fake.LibrariesStub
+	fakeReturns := fake.librariesReturns
+	fake.recordInvocation("Libraries", []interface{}{})
+	fake.librariesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeResourceRealizer) LibrariesCallCount() int {
+	fake.librariesMutex.RLock()
+	defer fake.librariesMutex.RUnlock()
+	return len(fake.librariesArgsForCall)
+}
+
+func (fake *FakeResourceRealizer) LibrariesCalls(stub func() []string) {
+	fake.librariesMutex.Lock()
+	defer fake.librariesMutex.Unlock()
+	fake.LibrariesStub = stub
+}
+
+func (fake *FakeResourceRealizer) LibrariesReturns(result1 []string) {
+	fake.librariesMutex.Lock()
+	defer fake.librariesMutex.Unlock()
+	fake.LibrariesStub = nil
+	fake.librariesReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeResourceRealizer) LibrariesReturnsOnCall(i int, result1 []string) {
+	fake.librariesMutex.Lock()
+	defer fake.librariesMutex.Unlock()
+	fake.LibrariesStub = nil
+	if fake.librariesReturnsOnCall == nil {
+		fake.librariesReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.librariesReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeResourceRealizer) RealizedResources() []v1alpha1.RealizedResource {
+	fake.realizedResourcesMutex.Lock()
+	ret, specificReturn := fake.realizedResourcesReturnsOnCall[len(fake.realizedResourcesArgsForCall)]
+	fake.realizedResourcesArgsForCall = append(fake.realizedResourcesArgsForCall, struct {
+	}{})
+	stub := fake.RealizedResourcesStub
+	fakeReturns := fake.realizedResourcesReturns
+	fake.recordInvocation("RealizedResources", []interface{}{})
+	fake.realizedResourcesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeResourceRealizer) RealizedResourcesCallCount() int {
+	fake.realizedResourcesMutex.RLock()
+	defer fake.realizedResourcesMutex.RUnlock()
+	return len(fake.realizedResourcesArgsForCall)
+}
+
+func (fake *FakeResourceRealizer) RealizedResourcesCalls(stub func() []v1alpha1.RealizedResource) {
+	fake.realizedResourcesMutex.Lock()
+	defer fake.realizedResourcesMutex.Unlock()
+	fake.RealizedResourcesStub = stub
+}
+
+func (fake *FakeResourceRealizer) RealizedResourcesReturns(result1 []v1alpha1.RealizedResource) {
+	fake.realizedResourcesMutex.Lock()
+	defer fake.realizedResourcesMutex.Unlock()
+	fake.RealizedResourcesStub = nil
+	fake.realizedResourcesReturns = struct {
+		result1 []v1alpha1.RealizedResource
+	}{result1}
+}
+
+func (fake *FakeResourceRealizer) RealizedResourcesReturnsOnCall(i int, result1 []v1alpha1.RealizedResource) {
+	fake.realizedResourcesMutex.Lock()
+	defer fake.realizedResourcesMutex.Unlock()
+	fake.RealizedResourcesStub = nil
+	if fake.realizedResourcesReturnsOnCall == nil {
+		fake.realizedResourcesReturnsOnCall = make(map[int]struct {
+			result1 []v1alpha1.RealizedResource
+		})
+	}
+	fake.realizedResourcesReturnsOnCall[i] = struct {
+		result1 []v1alpha1.RealizedResource
+	}{result1}
+}
+
+func (fake *FakeResourceRealizer) RecordLastOutputs(arg1 deliverable.Outputs) error {
+	fake.recordLastOutputsMutex.Lock()
+	ret, specificReturn := fake.recordLastOutputsReturnsOnCall[len(fake.recordLastOutputsArgsForCall)]
+	fake.recordLastOutputsArgsForCall = append(fake.recordLastOutputsArgsForCall, struct {
+		arg1 deliverable.Outputs
+	}{arg1})
+	stub := fake.RecordLastOutputsStub
+	fakeReturns := fake.recordLastOutputsReturns
+	fake.recordInvocation("RecordLastOutputs", []interface{}{arg1})
+	fake.recordLastOutputsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeResourceRealizer) RecordLastOutputsCallCount() int {
+	fake.recordLastOutputsMutex.RLock()
+	defer fake.recordLastOutputsMutex.RUnlock()
+	return len(fake.recordLastOutputsArgsForCall)
+}
+
+func (fake *FakeResourceRealizer) RecordLastOutputsCalls(stub func(deliverable.Outputs) error) {
+	fake.recordLastOutputsMutex.Lock()
+	defer fake.recordLastOutputsMutex.Unlock()
+	fake.RecordLastOutputsStub = stub
+}
+
+func (fake *FakeResourceRealizer) RecordLastOutputsArgsForCall(i int) deliverable.Outputs {
+	fake.recordLastOutputsMutex.RLock()
+	defer fake.recordLastOutputsMutex.RUnlock()
+	argsForCall := fake.recordLastOutputsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeResourceRealizer) RecordLastOutputsReturns(result1 error) {
+	fake.recordLastOutputsMutex.Lock()
+	defer fake.recordLastOutputsMutex.Unlock()
+	fake.RecordLastOutputsStub = nil
+	fake.recordLastOutputsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeResourceRealizer) RecordLastOutputsReturnsOnCall(i int, result1 error) {
+	fake.recordLastOutputsMutex.Lock()
+	defer fake.recordLastOutputsMutex.Unlock()
+	fake.RecordLastOutputsStub = nil
+	if fake.recordLastOutputsReturnsOnCall == nil {
+		fake.recordLastOutputsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.recordLastOutputsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeResourceRealizer) RecordRetry(arg1 string) {
+	fake.recordRetryMutex.Lock()
+	fake.recordRetryArgsForCall = append(fake.recordRetryArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RecordRetryStub
+	fake.recordInvocation("RecordRetry", []interface{}{arg1})
+	fake.recordRetryMutex.Unlock()
+	if stub != nil {
+		fake.RecordRetryStub(arg1)
+	}
+}
+
+func (fake *FakeResourceRealizer) RecordRetryCallCount() int {
+	fake.recordRetryMutex.RLock()
+	defer fake.recordRetryMutex.RUnlock()
+	return len(fake.recordRetryArgsForCall)
+}
+
+func (fake *FakeResourceRealizer) RecordRetryCalls(stub func(string)) {
+	fake.recordRetryMutex.Lock()
+	defer fake.recordRetryMutex.Unlock()
+	fake.RecordRetryStub = stub
+}
+
+func (fake *FakeResourceRealizer) RecordRetryArgsForCall(i int) string {
+	fake.recordRetryMutex.RLock()
+	defer fake.recordRetryMutex.RUnlock()
+	argsForCall := fake.recordRetryArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeResourceRealizer) StampedObjects() []v1alpha1.ObjectReference {
+	fake.stampedObjectsMutex.Lock()
+	ret, specificReturn := fake.stampedObjectsReturnsOnCall[len(fake.stampedObjectsArgsForCall)]
+	fake.stampedObjectsArgsForCall = append(fake.stampedObjectsArgsForCall, struct {
+	}{})
+	stub := fake.StampedObjectsStub
+	fakeReturns := fake.stampedObjectsReturns
+	fake.recordInvocation("StampedObjects", []interface{}{})
+	fake.stampedObjectsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeResourceRealizer) StampedObjectsCallCount() int {
+	fake.stampedObjectsMutex.RLock()
+	defer fake.stampedObjectsMutex.RUnlock()
+	return len(fake.stampedObjectsArgsForCall)
+}
+
+func (fake *FakeResourceRealizer) StampedObjectsCalls(stub func() []v1alpha1.ObjectReference) {
+	fake.stampedObjectsMutex.Lock()
+	defer fake.stampedObjectsMutex.Unlock()
+	fake.StampedObjectsStub = stub
+}
+
+func (fake *FakeResourceRealizer) StampedObjectsReturns(result1 []v1alpha1.ObjectReference) {
+	fake.stampedObjectsMutex.Lock()
+	defer fake.stampedObjectsMutex.Unlock()
+	fake.StampedObjectsStub = nil
+	fake.stampedObjectsReturns = struct {
+		result1 []v1alpha1.ObjectReference
+	}{result1}
+}
+
+func (fake *FakeResourceRealizer) StampedObjectsReturnsOnCall(i int, result1 []v1alpha1.ObjectReference) {
+	fake.stampedObjectsMutex.Lock()
+	defer fake.stampedObjectsMutex.Unlock()
+	fake.StampedObjectsStub = nil
+	if fake.stampedObjectsReturnsOnCall == nil {
+		fake.stampedObjectsReturnsOnCall = make(map[int]struct {
+			result1 []v1alpha1.ObjectReference
+		})
+	}
+	fake.stampedObjectsReturnsOnCall[i] = struct {
+		result1 []v1alpha1.ObjectReference
+	}{result1}
+}
+
 func (fake *FakeResourceRealizer) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.doMutex.RLock()
 	defer fake.doMutex.RUnlock()
+	fake.lastOutputMutex.RLock()
+	defer fake.lastOutputMutex.RUnlock()
+	fake.librariesMutex.RLock()
+	defer fake.librariesMutex.RUnlock()
+	fake.realizedResourcesMutex.RLock()
+	defer fake.realizedResourcesMutex.RUnlock()
+	fake.recordLastOutputsMutex.RLock()
+	defer fake.recordLastOutputsMutex.RUnlock()
+	fake.recordRetryMutex.RLock()
+	defer fake.recordRetryMutex.RUnlock()
+	fake.stampedObjectsMutex.RLock()
+	defer fake.stampedObjectsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
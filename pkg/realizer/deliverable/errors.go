@@ -28,7 +28,24 @@ type GetDeliveryClusterTemplateError struct {
 }
 
 func (e GetDeliveryClusterTemplateError) Error() string {
-	return fmt.Errorf("unable to get template '%s': %w", e.TemplateRef.Name, e.Err).Error()
+	return fmt.Errorf("unable to get template '%s': %w", e.TemplateRef.DisplayName(), e.Err).Error()
+}
+
+func (e GetDeliveryClusterTemplateError) Unwrap() error {
+	return e.Err
+}
+
+type TemplateNotFoundError struct {
+	Err         error
+	TemplateRef v1alpha1.DeliveryClusterTemplateReference
+}
+
+func (e TemplateNotFoundError) Error() string {
+	return fmt.Errorf("template '%s' of kind '%s' not found: %w", e.TemplateRef.DisplayName(), e.TemplateRef.Kind, e.Err).Error()
+}
+
+func (e TemplateNotFoundError) Unwrap() error {
+	return e.Err
 }
 
 type ApplyStampedObjectError struct {
@@ -40,6 +57,62 @@ func (e ApplyStampedObjectError) Error() string {
 	return fmt.Errorf("unable to apply object '%s/%s': %w", e.StampedObject.GetNamespace(), e.StampedObject.GetName(), e.Err).Error()
 }
 
+func (e ApplyStampedObjectError) Unwrap() error {
+	return e.Err
+}
+
+type ApplyConflictError struct {
+	Err           error
+	StampedObject *unstructured.Unstructured
+}
+
+func (e ApplyConflictError) Error() string {
+	return fmt.Errorf("conflict applying object '%s/%s': %w", e.StampedObject.GetNamespace(), e.StampedObject.GetName(), e.Err).Error()
+}
+
+func (e ApplyConflictError) Unwrap() error {
+	return e.Err
+}
+
+type PolicyViolationError struct {
+	Err           error
+	StampedObject *unstructured.Unstructured
+}
+
+func (e PolicyViolationError) Error() string {
+	return fmt.Errorf("object '%s/%s' of kind '%s' %w", e.StampedObject.GetNamespace(), e.StampedObject.GetName(), e.StampedObject.GetKind(), e.Err).Error()
+}
+
+func (e PolicyViolationError) Unwrap() error {
+	return e.Err
+}
+
+type StampedObjectRejectedError struct {
+	Err           error
+	StampedObject *unstructured.Unstructured
+}
+
+func (e StampedObjectRejectedError) Error() string {
+	return fmt.Errorf("object '%s/%s' of kind '%s' %w", e.StampedObject.GetNamespace(), e.StampedObject.GetName(), e.StampedObject.GetKind(), e.Err).Error()
+}
+
+func (e StampedObjectRejectedError) Unwrap() error {
+	return e.Err
+}
+
+type KindNotRegisteredError struct {
+	Err           error
+	StampedObject *unstructured.Unstructured
+}
+
+func (e KindNotRegisteredError) Error() string {
+	return fmt.Errorf("unable to apply object '%s/%s': kind '%s' of '%s' is not registered with the apiserver: %w", e.StampedObject.GetNamespace(), e.StampedObject.GetName(), e.StampedObject.GetKind(), e.StampedObject.GetAPIVersion(), e.Err).Error()
+}
+
+func (e KindNotRegisteredError) Unwrap() error {
+	return e.Err
+}
+
 type StampError struct {
 	Err      error
 	Resource *v1alpha1.ClusterDeliveryResource
@@ -49,6 +122,36 @@ func (e StampError) Error() string {
 	return fmt.Errorf("unable to stamp object for resource '%s': %w", e.Resource.Name, e.Err).Error()
 }
 
+func (e StampError) Unwrap() error {
+	return e.Err
+}
+
+type PluginRunningError struct {
+	Err      error
+	Resource *v1alpha1.ClusterDeliveryResource
+}
+
+func (e PluginRunningError) Error() string {
+	return fmt.Errorf("waiting for plugin of resource '%s': %w", e.Resource.Name, e.Err).Error()
+}
+
+func (e PluginRunningError) Unwrap() error {
+	return e.Err
+}
+
+type PluginFailedError struct {
+	Err      error
+	Resource *v1alpha1.ClusterDeliveryResource
+}
+
+func (e PluginFailedError) Error() string {
+	return fmt.Errorf("plugin of resource '%s' failed: %w", e.Resource.Name, e.Err).Error()
+}
+
+func (e PluginFailedError) Unwrap() error {
+	return e.Err
+}
+
 func NewRetrieveOutputError(resource *v1alpha1.ClusterDeliveryResource, err error) RetrieveOutputError {
 	return RetrieveOutputError{
 		Err:      err,
@@ -56,6 +159,32 @@ func NewRetrieveOutputError(resource *v1alpha1.ClusterDeliveryResource, err erro
 	}
 }
 
+type JobRunningError struct {
+	Err      error
+	Resource *v1alpha1.ClusterDeliveryResource
+}
+
+func (e JobRunningError) Error() string {
+	return fmt.Errorf("waiting for job of resource '%s': %w", e.Resource.Name, e.Err).Error()
+}
+
+func (e JobRunningError) Unwrap() error {
+	return e.Err
+}
+
+type JobFailedError struct {
+	Err      error
+	Resource *v1alpha1.ClusterDeliveryResource
+}
+
+func (e JobFailedError) Error() string {
+	return fmt.Errorf("job of resource '%s' failed: %w", e.Resource.Name, e.Err).Error()
+}
+
+func (e JobFailedError) Unwrap() error {
+	return e.Err
+}
+
 type RetrieveOutputError struct {
 	Err      error
 	resource *v1alpha1.ClusterDeliveryResource
@@ -69,6 +198,10 @@ func (e RetrieveOutputError) Error() string {
 	return fmt.Errorf("unable to retrieve outputs from stamped object for resource '%s': %w", e.resource.Name, e.Err).Error()
 }
 
+func (e RetrieveOutputError) Unwrap() error {
+	return e.Err
+}
+
 func (e RetrieveOutputError) ResourceName() string {
 	return e.resource.Name
 }
@@ -80,3 +213,35 @@ func (e RetrieveOutputError) JsonPathExpression() string {
 	}
 	return "<no jsonpath context>"
 }
+
+func NewOutputPathError(resource *v1alpha1.ClusterDeliveryResource, err error) OutputPathError {
+	return OutputPathError{
+		Err:      err,
+		resource: resource,
+	}
+}
+
+type OutputPathError struct {
+	Err      error
+	resource *v1alpha1.ClusterDeliveryResource
+}
+
+func (e OutputPathError) Error() string {
+	return fmt.Errorf("invalid output path for resource '%s': %w", e.resource.Name, e.Err).Error()
+}
+
+func (e OutputPathError) Unwrap() error {
+	return e.Err
+}
+
+func (e OutputPathError) ResourceName() string {
+	return e.resource.Name
+}
+
+func (e OutputPathError) JsonPathExpression() string {
+	jsonPathErrorContext, ok := e.Err.(JsonPathErrorContext)
+	if ok {
+		return jsonPathErrorContext.JsonPathExpression()
+	}
+	return "<no jsonpath context>"
+}
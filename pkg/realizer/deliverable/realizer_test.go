@@ -81,8 +81,143 @@ var _ = Describe("Realize", func() {
 		Expect(executedResourceOrder).To(Equal([]string{"resource1", "resource2"}))
 	})
 
+	It("passes each resource the delivery's scheduling hints merged with its own", func() {
+		delivery.Spec.Scheduling = &v1alpha1.SchedulingHints{
+			PriorityClassName: "chain-priority",
+			Annotations:       map[string]string{"cost-center": "platform"},
+		}
+		delivery.Spec.Resources[1].Scheduling = &v1alpha1.SchedulingHints{
+			PriorityClassName: "resource-priority",
+		}
+
+		hints := map[string]*v1alpha1.SchedulingHints{}
+		resourceRealizer.DoCalls(func(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, deliveryName string, outputs realizer.Outputs) (*templates.Output, error) {
+			hints[resource.Name] = resource.Scheduling
+			return &templates.Output{}, nil
+		})
+
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, delivery)).To(Succeed())
+
+		Expect(hints["resource1"]).To(Equal(delivery.Spec.Scheduling))
+		Expect(hints["resource2"]).To(Equal(&v1alpha1.SchedulingHints{
+			PriorityClassName: "resource-priority",
+			Annotations:       map[string]string{"cost-center": "platform"},
+		}))
+		Expect(delivery.Spec.Resources[1].Scheduling.Annotations).To(BeNil())
+	})
+
+	It("passes each resource the delivery's propagation merged with its own", func() {
+		delivery.Spec.Propagation = &v1alpha1.MetadataPropagation{
+			Labels: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+		}
+		delivery.Spec.Resources[1].Propagation = &v1alpha1.MetadataPropagation{
+			Labels: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateNone},
+		}
+
+		propagations := map[string]*v1alpha1.MetadataPropagation{}
+		resourceRealizer.DoCalls(func(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, deliveryName string, outputs realizer.Outputs) (*templates.Output, error) {
+			propagations[resource.Name] = resource.Propagation
+			return &templates.Output{}, nil
+		})
+
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, delivery)).To(Succeed())
+
+		Expect(propagations["resource1"]).To(Equal(delivery.Spec.Propagation))
+		Expect(propagations["resource2"]).To(Equal(delivery.Spec.Resources[1].Propagation))
+	})
+
+	It("passes each resource its references with the delivery's named transforms resolved", func() {
+		delivery.Spec.Transforms = []v1alpha1.OutputTransform{
+			{Name: "wrap", Expression: `{"manifest": value}`},
+		}
+		delivery.Spec.Resources[1].Configs = []v1alpha1.ResourceReference{
+			{Name: "config", Resource: "resource1", Transform: "wrap"},
+		}
+
+		var configs []v1alpha1.ResourceReference
+		resourceRealizer.DoCalls(func(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, deliveryName string, outputs realizer.Outputs) (*templates.Output, error) {
+			if resource.Name == "resource2" {
+				configs = resource.Configs
+			}
+			return &templates.Output{}, nil
+		})
+
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, delivery)).To(Succeed())
+
+		Expect(configs).To(Equal([]v1alpha1.ResourceReference{
+			{Name: "config", Resource: "resource1", Transform: `{"manifest": value}`},
+		}))
+		Expect(delivery.Spec.Resources[1].Configs[0].Transform).To(Equal("wrap"))
+	})
+
 	It("returns any error encountered realizing a resource", func() {
 		resourceRealizer.DoReturns(nil, errors.New("realizing is hard"))
 		Expect(rlzr.Realize(context.TODO(), resourceRealizer, delivery)).To(MatchError("realizing is hard"))
 	})
+
+	It("records a retry of the resource that failed", func() {
+		resourceRealizer.DoReturnsOnCall(1, nil, errors.New("realizing is hard"))
+
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, delivery)).To(MatchError("realizing is hard"))
+		Expect(resourceRealizer.RecordRetryCallCount()).To(Equal(1))
+		Expect(resourceRealizer.RecordRetryArgsForCall(0)).To(Equal("resource2"))
+	})
+
+	It("records no retries when every resource is realized", func() {
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, delivery)).To(Succeed())
+		Expect(resourceRealizer.RecordRetryCallCount()).To(Equal(0))
+	})
+	Context("when a resource's job is still running", func() {
+		var (
+			waitErr    error
+			lastOutput *templates.Output
+			realized   []string
+		)
+
+		BeforeEach(func() {
+			delivery.Spec.Resources = append(delivery.Spec.Resources, v1alpha1.ClusterDeliveryResource{
+				Name:    "resource3",
+				Sources: []v1alpha1.ResourceReference{{Name: "source", Resource: "resource1"}},
+			})
+			delivery.Spec.Resources[1].Sources = []v1alpha1.ResourceReference{
+				{Name: "source", Resource: "resource1", WhileWaiting: v1alpha1.UseLastOutputsWhileWaiting},
+			}
+
+			waitErr = realizer.JobRunningError{Err: errors.New("job running"), Resource: &delivery.Spec.Resources[0]}
+			lastOutput = &templates.Output{Source: &templates.Source{URL: "last"}, Stale: true}
+			realized = nil
+
+			resourceRealizer.DoCalls(func(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, deliveryName string, outputs realizer.Outputs) (*templates.Output, error) {
+				realized = append(realized, resource.Name)
+				if resource.Name == "resource1" {
+					return nil, waitErr
+				}
+				Expect(outputs).To(HaveKeyWithValue("resource1", lastOutput))
+				return &templates.Output{}, nil
+			})
+			resourceRealizer.LastOutputReturns(lastOutput, nil)
+		})
+
+		It("stamps consumers using its last outputs with them, skips the others and returns the wait", func() {
+			Expect(rlzr.Realize(context.TODO(), resourceRealizer, delivery)).To(MatchError(waitErr))
+
+			Expect(realized).To(Equal([]string{"resource1", "resource2"}))
+			Expect(resourceRealizer.RecordLastOutputsArgsForCall(0)).To(Equal(realizer.Outputs{"resource1": lastOutput}))
+		})
+
+		It("records a retry of the resource that waits", func() {
+			Expect(rlzr.Realize(context.TODO(), resourceRealizer, delivery)).To(MatchError(waitErr))
+
+			Expect(resourceRealizer.RecordRetryCallCount()).To(Equal(1))
+			Expect(resourceRealizer.RecordRetryArgsForCall(0)).To(Equal("resource1"))
+		})
+
+		It("returns errors other than waits", func() {
+			waitErr = errors.New("realizing is hard")
+
+			Expect(rlzr.Realize(context.TODO(), resourceRealizer, delivery)).To(MatchError("realizing is hard"))
+			Expect(realized).To(Equal([]string{"resource1"}))
+			Expect(resourceRealizer.RecordLastOutputsCallCount()).To(Equal(0))
+		})
+	})
 })
@@ -48,6 +48,15 @@ func StampedObjectRejectedByAPIServerCondition(err error) *metav1.Condition {
 	}
 }
 
+func PolicyViolationCondition(err error) *metav1.Condition {
+	return &metav1.Condition{
+		Type:    v1alpha1.RunTemplateReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.PolicyViolationRunTemplateReason,
+		Message: err.Error(),
+	}
+}
+
 func OutputPathNotSatisfiedCondition(err error) *metav1.Condition {
 	return &metav1.Condition{
 		Type:    v1alpha1.RunTemplateReady,
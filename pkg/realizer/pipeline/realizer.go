@@ -18,6 +18,7 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/go-logr/logr"
@@ -26,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
 	"github.com/vmware-tanzu/cartographer/pkg/repository"
 	"github.com/vmware-tanzu/cartographer/pkg/templates"
 )
@@ -48,7 +50,7 @@ type TemplatingContext struct {
 
 func (p *pipelineRealizer) Realize(ctx context.Context, pipeline *v1alpha1.Pipeline, logger logr.Logger, repository repository.Repository) (*v1.Condition, templates.Outputs, *unstructured.Unstructured) {
 	pipeline.Spec.RunTemplateRef.Kind = "ClusterRunTemplate"
-	template, err := repository.GetRunTemplate(pipeline.Spec.RunTemplateRef)
+	template, err := repository.GetRunTemplate(ctx, pipeline.Spec.RunTemplateRef)
 
 	if err != nil {
 		errorMessage := fmt.Sprintf("could not get ClusterRunTemplate '%s'", pipeline.Spec.RunTemplateRef.Name)
@@ -62,7 +64,7 @@ func (p *pipelineRealizer) Realize(ctx context.Context, pipeline *v1alpha1.Pipel
 		"carto.run/run-template-name": template.GetName(),
 	}
 
-	selected, err := resolveSelector(pipeline.Spec.Selector, repository)
+	selected, err := resolveSelector(ctx, pipeline.Spec.Selector, repository)
 	if err != nil {
 		errorMessage := fmt.Sprintf("could not resolve selector (apiVersion:%s kind:%s labels:%v)",
 			pipeline.Spec.Selector.Resource.APIVersion,
@@ -88,7 +90,12 @@ func (p *pipelineRealizer) Realize(ctx context.Context, pipeline *v1alpha1.Pipel
 		return TemplateStampFailureCondition(fmt.Errorf("%s: %w", errorMessage, err)), nil, nil
 	}
 
-	err = repository.EnsureObjectExistsOnCluster(stampedObject.DeepCopy(), false)
+	err = repository.EnsureObjectExistsOnCluster(ctx, stampedObject.DeepCopy(), false)
+	if errors.As(err, &policy.ViolationError{}) {
+		errorMessage := fmt.Sprintf("object of kind '%s' not created", stampedObject.GetKind())
+		logger.Info(errorMessage, "reason", err.Error())
+		return PolicyViolationCondition(fmt.Errorf("%s: %w", errorMessage, err)), nil, nil
+	}
 	if err != nil {
 		errorMessage := "could not create object"
 		logger.Error(err, errorMessage)
@@ -98,7 +105,7 @@ func (p *pipelineRealizer) Realize(ctx context.Context, pipeline *v1alpha1.Pipel
 	objectForListCall := stampedObject.DeepCopy()
 	objectForListCall.SetLabels(labels)
 
-	allPipelineStampedObjects, err := repository.ListUnstructured(objectForListCall)
+	allPipelineStampedObjects, err := repository.ListUnstructured(ctx, objectForListCall)
 	if err != nil {
 		err := fmt.Errorf("could not list pipeline objects: %w", err)
 		logger.Info(err.Error())
@@ -118,7 +125,7 @@ func (p *pipelineRealizer) Realize(ctx context.Context, pipeline *v1alpha1.Pipel
 	return RunTemplateReadyCondition(), outputs, stampedObject
 }
 
-func resolveSelector(selector *v1alpha1.ResourceSelector, repository repository.Repository) (map[string]interface{}, error) {
+func resolveSelector(ctx context.Context, selector *v1alpha1.ResourceSelector, repository repository.Repository) (map[string]interface{}, error) {
 	if selector == nil {
 		return nil, nil
 	}
@@ -126,7 +133,7 @@ func resolveSelector(selector *v1alpha1.ResourceSelector, repository repository.
 	queryObj.SetGroupVersionKind(schema.FromAPIVersionAndKind(selector.Resource.APIVersion, selector.Resource.Kind))
 	queryObj.SetLabels(selector.MatchingLabels)
 
-	results, err := repository.ListUnstructured(queryObj)
+	results, err := repository.ListUnstructured(ctx, queryObj)
 	if err != nil {
 		return nil, fmt.Errorf("could not list objects matching selector: %w", err)
 	}
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
 	realizer "github.com/vmware-tanzu/cartographer/pkg/realizer/pipeline"
 	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
 	"github.com/vmware-tanzu/cartographer/pkg/templates"
@@ -107,7 +108,7 @@ var _ = Describe("Realizer", func() {
 
 			createdUnstructured = &unstructured.Unstructured{}
 
-			repository.EnsureObjectExistsOnClusterStub = func(obj *unstructured.Unstructured, allowUpdate bool) error {
+			repository.EnsureObjectExistsOnClusterStub = func(ctx context.Context, obj *unstructured.Unstructured, allowUpdate bool) error {
 				createdUnstructured.Object = obj.Object
 				return nil
 			}
@@ -119,7 +120,8 @@ var _ = Describe("Realizer", func() {
 			_, _, _ = rlzr.Realize(context.TODO(), pipeline, logger, repository)
 
 			Expect(repository.GetRunTemplateCallCount()).To(Equal(1))
-			Expect(repository.GetRunTemplateArgsForCall(0)).To(MatchFields(IgnoreExtras,
+			_, runTemplateRef := repository.GetRunTemplateArgsForCall(0)
+			Expect(runTemplateRef).To(MatchFields(IgnoreExtras,
 				Fields{
 					"Kind": Equal("ClusterRunTemplate"),
 					"Name": Equal("my-template"),
@@ -127,7 +129,7 @@ var _ = Describe("Realizer", func() {
 			))
 
 			Expect(repository.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
-			stamped, allowUpdate := repository.EnsureObjectExistsOnClusterArgsForCall(0)
+			_, stamped, allowUpdate := repository.EnsureObjectExistsOnClusterArgsForCall(0)
 			Expect(allowUpdate).To(BeFalse())
 			Expect(stamped.Object).To(
 				MatchKeys(IgnoreExtras, Keys{
@@ -195,6 +197,25 @@ var _ = Describe("Realizer", func() {
 			})
 		})
 
+		Context("the stamped object violates the stamp policy", func() {
+			BeforeEach(func() {
+				repository.EnsureObjectExistsOnClusterReturns(policy.ViolationError{Rule: "no-tests", Message: "tests are not allowed"})
+			})
+
+			It("returns a condition stating the policy violation", func() {
+				condition, _, _ := rlzr.Realize(context.TODO(), pipeline, logger, repository)
+
+				Expect(*condition).To(
+					MatchFields(IgnoreExtras, Fields{
+						"Type":    Equal("RunTemplateReady"),
+						"Status":  Equal(metav1.ConditionFalse),
+						"Reason":  Equal("PolicyViolation"),
+						"Message": Equal("object of kind 'Test' not created: violates policy rule 'no-tests': tests are not allowed"),
+					}),
+				)
+			})
+		})
+
 		Context("listing previously created objects fails", func() {
 			BeforeEach(func() {
 				repository.ListUnstructuredReturns(nil, errors.New("some list error"))
@@ -236,13 +257,13 @@ var _ = Describe("Realizer", func() {
 				_, _, _ = rlzr.Realize(context.TODO(), pipeline, logger, repository)
 
 				Expect(repository.ListUnstructuredCallCount()).To(Equal(2))
-				clientQueryObjectForSelector := repository.ListUnstructuredArgsForCall(0)
+				_, clientQueryObjectForSelector := repository.ListUnstructuredArgsForCall(0)
 				Expect(clientQueryObjectForSelector.GetAPIVersion()).To(Equal("apiversion-to-be-selected"))
 				Expect(clientQueryObjectForSelector.GetKind()).To(Equal("kind-to-be-selected"))
 				Expect(clientQueryObjectForSelector.GetLabels()).To(Equal(map[string]string{"expected-label": "expected-value"}))
 
 				Expect(repository.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
-				stamped, allowUpdate := repository.EnsureObjectExistsOnClusterArgsForCall(0)
+				_, stamped, allowUpdate := repository.EnsureObjectExistsOnClusterArgsForCall(0)
 				Expect(allowUpdate).To(BeFalse())
 				Expect(stamped.Object).To(
 					MatchKeys(IgnoreExtras, Keys{
@@ -384,7 +405,7 @@ var _ = Describe("Realizer", func() {
 
 			createdUnstructured = &unstructured.Unstructured{}
 
-			repository.EnsureObjectExistsOnClusterStub = func(obj *unstructured.Unstructured, allowUpdate bool) error {
+			repository.EnsureObjectExistsOnClusterStub = func(ctx context.Context, obj *unstructured.Unstructured, allowUpdate bool) error {
 				createdUnstructured.Object = obj.Object
 				return nil
 			}
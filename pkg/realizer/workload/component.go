@@ -16,10 +16,31 @@ package workload
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
+	"github.com/vmware-tanzu/cartographer/pkg/history"
+	"github.com/vmware-tanzu/cartographer/pkg/jobs"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
+	"github.com/vmware-tanzu/cartographer/pkg/propagation"
+	"github.com/vmware-tanzu/cartographer/pkg/redact"
 	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/scheduling"
+	"github.com/vmware-tanzu/cartographer/pkg/scope"
 	"github.com/vmware-tanzu/cartographer/pkg/templates"
+	"github.com/vmware-tanzu/cartographer/pkg/tracing"
+	"github.com/vmware-tanzu/cartographer/pkg/utils"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -27,29 +48,93 @@ import (
 //counterfeiter:generate . ResourceRealizer
 type ResourceRealizer interface {
 	Do(ctx context.Context, resource *v1alpha1.SupplyChainResource, supplyChainName string, outputs Outputs) (*templates.Output, error)
+	// LastOutput returns the output last recorded for the named resource, or
+	// nil if there is none.
+	LastOutput(resourceName string) (*templates.Output, error)
+	// RecordLastOutputs replaces the outputs recorded in the workload's status
+	// with outputs.
+	RecordLastOutputs(outputs Outputs) error
+	// RecordRetry counts an attempt to realize the named resource that
+	// failed or had to wait in the workload's status.
+	RecordRetry(resourceName string)
+	// RealizedOutputs returns the outputs read, in this realization, from
+	// the objects stamped for resources.
+	RealizedOutputs() Outputs
+	// RealizedResources returns the resources that output, in this
+	// realization, in name order.
+	RealizedResources() []v1alpha1.RealizedResource
+	// Libraries returns the names of the template libraries included by
+	// the templates of the resources reached, in this realization, in name
+	// order.
+	Libraries() []string
+	// StampedObjects returns the objects stamped, in this realization, in
+	// the order they were stamped, including those that have not output yet.
+	StampedObjects() []v1alpha1.ObjectReference
+	// ResolvedTemplates returns the templates the resources reached, in
+	// this realization, were resolved to, in resource name order.
+	ResolvedTemplates() []v1alpha1.ResolvedResourceTemplate
 }
 
 type resourceRealizer struct {
-	workload *v1alpha1.Workload
-	repo     repository.Repository
+	workload           *v1alpha1.Workload
+	repo               repository.Repository
+	serviceAccountRepo repository.ServiceAccountRepository
+	realized           Outputs
+	resources          []v1alpha1.RealizedResource
+	stamped            []v1alpha1.ObjectReference
+	libraries          map[string]bool
+	resolved           []v1alpha1.ResolvedResourceTemplate
 }
 
-func NewResourceRealizer(workload *v1alpha1.Workload, repo repository.Repository) ResourceRealizer {
+func NewResourceRealizer(workload *v1alpha1.Workload, repo repository.Repository, serviceAccountRepo repository.ServiceAccountRepository) ResourceRealizer {
 	return &resourceRealizer{
-		workload: workload,
-		repo:     repo,
+		workload:           workload,
+		repo:               repo,
+		serviceAccountRepo: serviceAccountRepo,
+		realized:           NewOutputs(),
 	}
 }
 
-func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.SupplyChainResource, supplyChainName string, outputs Outputs) (*templates.Output, error) {
-	template, err := r.repo.GetClusterTemplate(resource.TemplateRef)
+func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.SupplyChainResource, supplyChainName string, outputs Outputs) (output *templates.Output, err error) {
+	ctx, span := tracing.Start(ctx, "resource.realize", attribute.String("resource.name", resource.Name))
+	defer func() { tracing.End(span, err) }()
+
+	templateRef, err := resource.TemplateRef.ForWorkload(r.workload)
+	if err != nil {
+		return nil, TemplateOptionsError{
+			Err:          err,
+			ResourceName: resource.Name,
+		}
+	}
+
+	resolveCtx, resolveSpan := tracing.Start(ctx, "template.resolve",
+		attribute.String("template.kind", templateRef.Kind),
+		attribute.String("template.name", templateRef.DisplayName()),
+	)
+	template, err := r.repo.GetClusterTemplate(resolveCtx, templateRef)
+	tracing.End(resolveSpan, err)
 	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, TemplateNotFoundError{
+				Err:         err,
+				TemplateRef: templateRef,
+			}
+		}
 		return nil, GetClusterTemplateError{
 			Err:         err,
-			TemplateRef: resource.TemplateRef,
+			TemplateRef: templateRef,
 		}
 	}
 
+	r.resolved = append(r.resolved, v1alpha1.ResolvedResourceTemplate{
+		Resource: resource.Name,
+		Template: v1alpha1.ResolvedTemplateRef{
+			Kind:       templateRef.Kind,
+			Name:       template.GetName(),
+			Generation: template.GetGeneration(),
+		},
+	})
+
 	labels := map[string]string{
 		"carto.run/workload-name":             r.workload.Name,
 		"carto.run/workload-namespace":        r.workload.Namespace,
@@ -59,10 +144,22 @@ func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.SupplyChai
 		"carto.run/cluster-template-name":     template.GetName(),
 	}
 
-	inputs := outputs.GenerateInputs(resource)
+	inputs, err := outputs.GenerateInputs(resource)
+	if err != nil {
+		return nil, StampError{
+			Err:      err,
+			Resource: resource,
+		}
+	}
+	params := templates.ParamsBuilder(template.GetDefaultParams(), resource.Params)
+	redactor := redact.FromContext(ctx)
+	for _, value := range templates.SensitiveParams(template.GetDefaultParams(), resource.Params, params) {
+		redactor.Add(value)
+	}
+
 	workloadTemplatingContext := map[string]interface{}{
-		"workload": r.workload,
-		"params":   templates.ParamsBuilder(template.GetDefaultParams(), resource.Params),
+		"workload": r.templatingWorkload(),
+		"params":   params,
 		"sources":  inputs.Sources,
 		"images":   inputs.Images,
 		"configs":  inputs.Configs,
@@ -79,8 +176,44 @@ func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.SupplyChai
 		workloadTemplatingContext["source"] = inputs.OnlySource()
 	}
 
+	if cluster := clusterdata.FromContext(ctx); cluster != nil {
+		workloadTemplatingContext["cluster"] = cluster
+	}
+
+	if err := r.includeLibraries(ctx, template.GetResourceTemplate().Libraries, workloadTemplatingContext); err != nil {
+		return nil, StampError{
+			Err:      err,
+			Resource: resource,
+		}
+	}
+
 	stampContext := templates.StamperBuilder(r.workload, workloadTemplatingContext, labels)
-	stampedObject, err := stampContext.Stamp(ctx, template.GetResourceTemplate())
+	if patcher, ok := template.(templates.ConfigPatcher); ok && patcher.GetPatches() != nil {
+		return r.patch(ctx, resource, template, stampContext, patcher, *inputs)
+	}
+
+	stampCtx, stampSpan := tracing.Start(ctx, "template.stamp")
+	stampedObjects, err := stampContext.StampAll(stampCtx, template.GetResourceTemplate())
+	crossNamespace := resource.TargetNamespace != "" && resource.TargetNamespace != r.workload.Namespace
+	isJob := template.GetResourceTemplate().IsJob()
+	orphan := v1alpha1.OrphansOnDeletion(resource.DeletionPolicy, r.workload.Spec.DeletionPolicy)
+	outside := make([]bool, len(stampedObjects))
+	for i, stampedObject := range stampedObjects {
+		if outside[i], err = r.prepare(ctx, resource, stampedObject, crossNamespace, orphan); err != nil {
+			break
+		}
+		if err = policy.CheckScope(ctx, stampedObject); err != nil {
+			tracing.End(stampSpan, err)
+			return nil, PolicyViolationError{
+				Err:           err,
+				StampedObject: stampedObject,
+			}
+		}
+	}
+	if err == nil && isJob {
+		err = jobs.Identify(stampedObjects[0])
+	}
+	tracing.End(stampSpan, err)
 	if err != nil {
 		return nil, StampError{
 			Err:      err,
@@ -88,21 +221,503 @@ func (r *resourceRealizer) Do(ctx context.Context, resource *v1alpha1.SupplyChai
 		}
 	}
 
-	err = r.repo.EnsureObjectExistsOnCluster(stampedObject, true)
+	if name := template.GetResourceTemplate().Plugin; name != "" {
+		outputSource, err := r.pluginResults(ctx, resource, name, stampedObjects)
+		if err != nil {
+			return nil, err
+		}
+		return r.output(ctx, resource, template, stampedObjects[0], outputSource)
+	}
+
+	stampingRepo, err := r.stampingRepo(resource)
 	if err != nil {
 		return nil, ApplyStampedObjectError{
 			Err:           err,
-			StampedObject: stampedObject,
+			StampedObject: stampedObjects[0],
+		}
+	}
+	for i, stampedObject := range stampedObjects {
+		if outside[i] && !orphan {
+			if err := r.claim(ctx, stampingRepo, stampedObject); err != nil {
+				return nil, ApplyStampedObjectError{
+					Err:           err,
+					StampedObject: stampedObject,
+				}
+			}
+			if err := r.holdForCleanup(ctx); err != nil {
+				return nil, ApplyStampedObjectError{
+					Err:           err,
+					StampedObject: stampedObject,
+				}
+			}
+		}
+
+		if err := r.apply(ctx, stampingRepo, resource, stampedObject, isJob); err != nil {
+			return nil, err
+		}
+
+		if outside[i] && !orphan {
+			r.recordCrossNamespaceObject(stampedObject)
+		}
+
+		r.stamped = append(r.stamped, v1alpha1.ObjectReference{
+			APIVersion: stampedObject.GetAPIVersion(),
+			Kind:       stampedObject.GetKind(),
+			Namespace:  stampedObject.GetNamespace(),
+			Name:       stampedObject.GetName(),
+		})
+	}
+
+	// a template stamping several objects is read through its first
+	stampedObject := stampedObjects[0]
+	outputSource := stampedObject
+	if isJob {
+		outputSource, err = r.jobResults(ctx, resource, template, stampedObject)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	output, err := template.GetOutput(stampedObject)
+	return r.output(ctx, resource, template, stampedObject, outputSource)
+}
+
+// output extracts the outputs of resource from outputSource, recording them
+// as realized from stampedObject.
+func (r *resourceRealizer) output(ctx context.Context, resource *v1alpha1.SupplyChainResource, template templates.Template, stampedObject, outputSource *unstructured.Unstructured) (*templates.Output, error) {
+	_, outputSpan := tracing.Start(ctx, "output.extract")
+	output, err := template.GetOutput(outputSource)
+	tracing.End(outputSpan, err)
 	if err != nil {
+		if errors.As(err, &utils.JsonPathParseError{}) {
+			return nil, OutputPathError{
+				Err:      err,
+				resource: resource,
+			}
+		}
 		return nil, RetrieveOutputError{
 			Err:      err,
 			resource: resource,
 		}
 	}
 
+	r.recordMissingOutputs(resource.Name, output)
+	r.recordRealized(ctx, resource.Name, template, stampedObject, output)
+	return output, nil
+}
+
+// includeLibraries adds the partials and helpers of the named libraries to
+// the templating context, remembering the names whether or not they exist so
+// that the owner is realized again when they change.
+func (r *resourceRealizer) includeLibraries(ctx context.Context, names []string, templatingContext map[string]interface{}) error {
+	for _, name := range names {
+		if r.libraries == nil {
+			r.libraries = map[string]bool{}
+		}
+		r.libraries[name] = true
+	}
+
+	libraries, err := templates.GetLibraries(ctx, r.repo.GetTemplateLibrary, names)
+	if err != nil {
+		return err
+	}
+	return templates.IncludeLibraries(libraries, templatingContext)
+}
+
+// patch outputs the config input of resource that patches name, patched,
+// for templates that patch a config rather than stamp an object.
+func (r *resourceRealizer) patch(ctx context.Context, resource *v1alpha1.SupplyChainResource, template templates.Template, stampContext templates.Stamper, patcher templates.ConfigPatcher, inputs templates.Inputs) (*templates.Output, error) {
+	_, patchSpan := tracing.Start(ctx, "config.patch")
+	stampContext.MissingKey = template.GetResourceTemplate().MissingKey()
+	output, err := templates.PatchConfig(stampContext, *patcher.GetPatches(), inputs)
+	tracing.End(patchSpan, err)
+	if err != nil {
+		return nil, StampError{
+			Err:      err,
+			Resource: resource,
+		}
+	}
+
+	output.Sensitive = patcher.GetSensitiveOutputs()
+	r.recordRealized(ctx, resource.Name, template, &unstructured.Unstructured{}, output)
 	return output, nil
 }
+
+// recordRealized records the output of the named resource as realized, its
+// sensitive values as to be redacted.
+func (r *resourceRealizer) recordRealized(ctx context.Context, resourceName string, template templates.Template, stampedObject *unstructured.Unstructured, output *templates.Output) {
+	redactor := redact.FromContext(ctx)
+	for _, value := range output.SensitiveValues() {
+		redactor.Add(value)
+	}
+
+	realized := history.Resource(resourceName, template, stampedObject, output)
+	realized.SourceRevision = redactor.String(realized.SourceRevision)
+
+	r.realized.AddOutput(resourceName, output)
+	r.resources = append(r.resources, realized)
+}
+
+// prepare readies an object stamped for resource to be applied: in its
+// target namespace, or in none for a cluster-scoped kind, with the
+// workload's metadata and the resource's scheduling, owned as the
+// resource's deletion policy and ownership say. It reports whether the
+// object lands outside the workload's namespace.
+func (r *resourceRealizer) prepare(ctx context.Context, resource *v1alpha1.SupplyChainResource, stampedObject *unstructured.Unstructured, crossNamespace, orphan bool) (bool, error) {
+	clusterScoped, err := scope.ClusterScoped(scope.FromContext(ctx), stampedObject)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case clusterScoped:
+		r.retarget(stampedObject, "")
+	case crossNamespace:
+		r.retarget(stampedObject, resource.TargetNamespace)
+	}
+	if resource.HashName {
+		r.hashName(stampedObject)
+	}
+	propagation.Apply(stampedObject, r.workload.Labels, r.workload.Annotations, resource.Propagation)
+	if err := scheduling.Inject(stampedObject, resource.Scheduling); err != nil {
+		return false, err
+	}
+	if orphan {
+		// without an owner reference, the object is not garbage collected
+		// along with the workload.
+		stampedObject.SetOwnerReferences(nil)
+	} else if resource.Ownership == v1alpha1.TrackedOwnership {
+		r.track(stampedObject)
+	}
+	return clusterScoped || crossNamespace, nil
+}
+
+// apply ensures an object stamped for resource exists on the cluster as it
+// was stamped.
+func (r *resourceRealizer) apply(ctx context.Context, stampingRepo repository.Repository, resource *v1alpha1.SupplyChainResource, stampedObject *unstructured.Unstructured, isJob bool) error {
+	applyCtx, applySpan := tracing.Start(ctx, "object.apply",
+		attribute.String("object.kind", stampedObject.GetKind()),
+		attribute.String("object.namespace", stampedObject.GetNamespace()),
+		attribute.String("object.name", stampedObject.GetName()),
+	)
+	if resource.Drift == v1alpha1.DetectDrift {
+		applyCtx = repository.WithDriftDetection(applyCtx, r.recordDrift(resource.Name))
+	}
+	if resource.DryRun {
+		applyCtx = repository.WithDryRun(applyCtx)
+	}
+	var err error
+	if isJob {
+		err = stampingRepo.EnsureImmutableObjectExistsOnCluster(applyCtx, stampedObject)
+	} else {
+		err = stampingRepo.EnsureObjectExistsOnCluster(applyCtx, stampedObject, true)
+	}
+	tracing.End(applySpan, err)
+	if err != nil {
+		if errors.As(err, &policy.ViolationError{}) {
+			return PolicyViolationError{
+				Err:           err,
+				StampedObject: stampedObject,
+			}
+		}
+		if errors.As(err, &repository.RejectedError{}) {
+			return StampedObjectRejectedError{
+				Err:           err,
+				StampedObject: stampedObject,
+			}
+		}
+		if repository.IsKindNotRegistered(err) {
+			return KindNotRegisteredError{
+				Err:           err,
+				StampedObject: stampedObject,
+			}
+		}
+		if kerrors.IsConflict(err) || kerrors.IsAlreadyExists(err) {
+			return ApplyConflictError{
+				Err:           err,
+				StampedObject: stampedObject,
+			}
+		}
+		return ApplyStampedObjectError{
+			Err:           err,
+			StampedObject: stampedObject,
+		}
+	}
+	return nil
+}
+
+// jobResults returns the results of the job stamped for resource, once it
+// has completed, as the object the template's outputs are read from. Jobs
+// run for earlier inputs are then deleted.
+func (r *resourceRealizer) jobResults(ctx context.Context, resource *v1alpha1.SupplyChainResource, template templates.Template, job *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	results, err := jobs.Results(ctx, r.repo, job, template.GetResourceTemplate().Results)
+	if err != nil {
+		if errors.As(err, &jobs.RunningError{}) {
+			return nil, JobRunningError{Err: err, Resource: resource}
+		}
+		if errors.As(err, &jobs.FailedError{}) {
+			return nil, JobFailedError{Err: err, Resource: resource}
+		}
+		return nil, RetrieveOutputError{Err: err, resource: resource}
+	}
+
+	if err := jobs.Prune(ctx, r.repo, job); err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "prune earlier job runs", "resource", resource.Name)
+	}
+
+	return &unstructured.Unstructured{Object: results}, nil
+}
+
+// pluginResults has the named plugin realize the objects stamped for
+// resource, rather than submitting them to the cluster, and returns its
+// outputs, once it is ready, as the object the template's outputs are read
+// from.
+func (r *resourceRealizer) pluginResults(ctx context.Context, resource *v1alpha1.SupplyChainResource, name string, stampedObjects []*unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	pluginCtx, pluginSpan := tracing.Start(ctx, "plugin.realize", attribute.String("plugin.name", name))
+	owner := v1alpha1.ObjectReference{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "Workload",
+		Namespace:  r.workload.Namespace,
+		Name:       r.workload.Name,
+	}
+	results, err := plugin.Results(pluginCtx, name, owner, resource.Name, stampedObjects)
+	tracing.End(pluginSpan, err)
+	if err != nil {
+		if errors.As(err, &plugin.RunningError{}) {
+			return nil, PluginRunningError{Err: err, Resource: resource}
+		}
+		if errors.As(err, &plugin.FailedError{}) {
+			return nil, PluginFailedError{Err: err, Resource: resource}
+		}
+		return nil, RetrieveOutputError{Err: err, resource: resource}
+	}
+	return results, nil
+}
+
+// templatingWorkload returns the workload as templates see it: under its
+// name prefix, when one is set.
+func (r *resourceRealizer) templatingWorkload() *v1alpha1.Workload {
+	if r.workload.Spec.NamePrefix == "" {
+		return r.workload
+	}
+	workload := r.workload.DeepCopy()
+	workload.Name = workload.Spec.NamePrefix
+	return workload
+}
+
+// stampingRepo returns the repository that writes the object stamped for
+// resource: one acting as the resource's service account, when it names one.
+func (r *resourceRealizer) stampingRepo(resource *v1alpha1.SupplyChainResource) (repository.Repository, error) {
+	if resource.ServiceAccountName == "" {
+		return r.repo, nil
+	}
+	return r.serviceAccountRepo(r.workload.Namespace, resource.ServiceAccountName)
+}
+
+// retarget moves a stamped object into another namespace, or out of any
+// for a cluster-scoped kind. The workload cannot own objects outside its
+// namespace, so they are stamped without an owner reference.
+func (r *resourceRealizer) retarget(stampedObject *unstructured.Unstructured, namespace string) {
+	stampedObject.SetNamespace(namespace)
+	stampedObject.SetOwnerReferences(nil)
+	labels := stampedObject.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[v1alpha1.WorkloadUIDLabel] = string(r.workload.UID)
+	stampedObject.SetLabels(labels)
+}
+
+// claim refuses to take over an object of the stamped object's name outside
+// the workload's namespace that was not stamped for the workload: the
+// workload reconciler deletes the objects recorded there.
+func (r *resourceRealizer) claim(ctx context.Context, stampingRepo repository.Repository, stampedObject *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(stampedObject.GroupVersionKind())
+	existing.SetNamespace(stampedObject.GetNamespace())
+	existing.SetName(stampedObject.GetName())
+	err := stampingRepo.GetUnstructured(ctx, existing)
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !v1alpha1.StampedFor(existing, r.workload) {
+		return fmt.Errorf("%s '%s' already exists and was not stamped for this workload", existing.GetKind(), existing.GetName())
+	}
+	return nil
+}
+
+// track ties the stamped object to the workload by its labels and the
+// workload's UID rather than an owner reference, for the supply chain
+// reconciler to delete it once the workload is gone.
+func (r *resourceRealizer) track(stampedObject *unstructured.Unstructured) {
+	stampedObject.SetOwnerReferences(nil)
+	annotations := stampedObject.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[v1alpha1.TrackedOwnerAnnotation] = string(r.workload.UID)
+	stampedObject.SetAnnotations(annotations)
+}
+
+// hashName suffixes the stamped object's name, or its generateName, with a
+// hash of the workload's namespace and name.
+func (r *resourceRealizer) hashName(stampedObject *unstructured.Unstructured) {
+	hash := templates.NameHash(r.workload)
+	if name := stampedObject.GetName(); name != "" {
+		stampedObject.SetName(name + "-" + hash)
+		return
+	}
+	if generateName := stampedObject.GetGenerateName(); generateName != "" {
+		stampedObject.SetGenerateName(strings.TrimSuffix(generateName, "-") + "-" + hash + "-")
+	}
+}
+
+// holdForCleanup adds the cross-namespace cleanup finalizer to the workload
+// before an object outside its namespace is stamped, when its supply chain
+// did not already call for it, as for the objects of cluster-scoped kinds,
+// which are only known once stamped. The status realized so far is kept
+// over the one the update returns.
+func (r *resourceRealizer) holdForCleanup(ctx context.Context) error {
+	if controllerutil.ContainsFinalizer(r.workload, v1alpha1.CrossNamespaceCleanupFinalizer) {
+		return nil
+	}
+
+	status := r.workload.Status.DeepCopy()
+	controllerutil.AddFinalizer(r.workload, v1alpha1.CrossNamespaceCleanupFinalizer)
+	err := r.repo.Update(ctx, r.workload)
+	r.workload.Status = *status
+	if err != nil {
+		return fmt.Errorf("add finalizer: %w", err)
+	}
+	return nil
+}
+
+// recordCrossNamespaceObject lists obj in the workload's status, so that the
+// workload reconciler can clean it up.
+func (r *resourceRealizer) recordCrossNamespaceObject(obj *unstructured.Unstructured) {
+	ref := v1alpha1.ObjectReference{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+	for _, recorded := range r.workload.Status.CrossNamespaceObjects {
+		if recorded == ref {
+			return
+		}
+	}
+	r.workload.Status.CrossNamespaceObjects = append(r.workload.Status.CrossNamespaceObjects, ref)
+}
+
+// recordMissingOutputs lists, in the workload's status, the optional
+// outputs the named resource's object had not populated.
+func (r *resourceRealizer) recordMissingOutputs(resourceName string, output *templates.Output) {
+	for _, missing := range output.Missing {
+		r.workload.Status.MissingOptionalOutputs = append(r.workload.Status.MissingOptionalOutputs, v1alpha1.MissingOutput{
+			Resource:   resourceName,
+			Output:     missing.Name,
+			Expression: missing.Expression,
+		})
+	}
+}
+
+// recordDrift returns a reporter listing the objects stamped for the named
+// resource that drifted in the workload's status.
+func (r *resourceRealizer) recordDrift(resourceName string) repository.ChangeReporter {
+	return func(obj *unstructured.Unstructured, changes []repository.Change) {
+		drifted := v1alpha1.DriftedObject{
+			Resource: resourceName,
+			Object: v1alpha1.ObjectReference{
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       obj.GetKind(),
+				Namespace:  obj.GetNamespace(),
+				Name:       obj.GetName(),
+			},
+		}
+		for _, change := range changes {
+			drifted.Fields = append(drifted.Fields, change.Path)
+		}
+		r.workload.Status.Drifted = append(r.workload.Status.Drifted, drifted)
+	}
+}
+
+func (r *resourceRealizer) LastOutput(resourceName string) (*templates.Output, error) {
+	for _, last := range r.workload.Status.LastOutputs {
+		if last.Resource == resourceName {
+			return templates.StaleOutput(last)
+		}
+	}
+	return nil, nil
+}
+
+// RecordLastOutputs leaves out the outputs with sensitive values, which
+// status cannot hold, so that resources consuming them wait for new ones.
+func (r *resourceRealizer) RecordLastOutputs(outputs Outputs) error {
+	names := make([]string, 0, len(outputs))
+	for name, output := range outputs {
+		if len(output.Sensitive) > 0 {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lastOutputs []v1alpha1.LastOutput
+	for _, name := range names {
+		last, err := templates.NewLastOutput(name, outputs[name])
+		if err != nil {
+			return err
+		}
+		lastOutputs = append(lastOutputs, last)
+	}
+	r.workload.Status.LastOutputs = lastOutputs
+	return nil
+}
+
+func (r *resourceRealizer) RealizedOutputs() Outputs {
+	return r.realized
+}
+
+func (r *resourceRealizer) RealizedResources() []v1alpha1.RealizedResource {
+	resources := append([]v1alpha1.RealizedResource{}, r.resources...)
+	history.Sort(resources)
+	return resources
+}
+
+func (r *resourceRealizer) Libraries() []string {
+	var libraries []string
+	for library := range r.libraries {
+		libraries = append(libraries, library)
+	}
+	sort.Strings(libraries)
+	return libraries
+}
+
+func (r *resourceRealizer) StampedObjects() []v1alpha1.ObjectReference {
+	return r.stamped
+}
+
+func (r *resourceRealizer) ResolvedTemplates() []v1alpha1.ResolvedResourceTemplate {
+	resolved := append([]v1alpha1.ResolvedResourceTemplate{}, r.resolved...)
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].Resource < resolved[j].Resource
+	})
+	return resolved
+}
+
+func (r *resourceRealizer) RecordRetry(resourceName string) {
+	var retries []v1alpha1.ResourceRetries
+	counted := false
+	for _, entry := range r.workload.Status.Retries {
+		if entry.Resource == resourceName {
+			entry.Retries++
+			counted = true
+		}
+		retries = append(retries, entry)
+	}
+	if !counted {
+		retries = append(retries, v1alpha1.ResourceRetries{Resource: resourceName, Retries: 1})
+	}
+	r.workload.Status.Retries = retries
+}
@@ -18,18 +18,33 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
 	"github.com/vmware-tanzu/cartographer/pkg/eval"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin/pluginfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
 	realizer "github.com/vmware-tanzu/cartographer/pkg/realizer/workload"
+	"github.com/vmware-tanzu/cartographer/pkg/redact"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
 	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/scope"
 	"github.com/vmware-tanzu/cartographer/pkg/templates"
 )
 
@@ -41,6 +56,9 @@ var _ = Describe("Resource", func() {
 		outputs         realizer.Outputs
 		supplyChainName string
 		fakeRepo        repositoryfakes.FakeRepository
+		saRepo          repositoryfakes.FakeRepository
+		saRepoErr       error
+		impersonated    []string
 		r               realizer.ResourceRealizer
 	)
 
@@ -59,7 +77,14 @@ var _ = Describe("Resource", func() {
 
 		fakeRepo = repositoryfakes.FakeRepository{}
 		workload = v1alpha1.Workload{}
-		r = realizer.NewResourceRealizer(&workload, &fakeRepo)
+		saRepo = repositoryfakes.FakeRepository{}
+		saRepoErr = nil
+		impersonated = nil
+		serviceAccountRepo := func(namespace, serviceAccountName string) (repository.Repository, error) {
+			impersonated = append(impersonated, namespace+"/"+serviceAccountName)
+			return &saRepo, saRepoErr
+		}
+		r = realizer.NewResourceRealizer(&workload, &fakeRepo, serviceAccountRepo)
 	})
 
 	Describe("Do", func() {
@@ -72,83 +97,1108 @@ var _ = Describe("Resource", func() {
 					},
 				}
 
-				outputs.AddOutput("previous-resource", &templates.Output{Source: &templates.Source{
-					URL:      "some-url",
-					Revision: "some-revision",
-				}})
+				outputs.AddOutput("previous-resource", &templates.Output{Source: &templates.Source{
+					URL:      "some-url",
+					Revision: "some-revision",
+				}})
+
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "example-config-map",
+						Namespace: "some-namespace",
+					},
+					Data: map[string]string{
+						"player_current_lives": `$(source.url)$`,
+						"some_other_info":      `$(sources.source-provider.revision)$`,
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterImageTemplate{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ClusterImageTemplate",
+						APIVersion: "carto.run/v1alpha1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "image-template-1",
+						Namespace:  "some-namespace",
+						Generation: 3,
+					},
+					Spec: v1alpha1.ImageTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						ImagePath: "data.some_other_info",
+					},
+				}
+
+				template := templates.NewClusterImageTemplateModel(templateAPI, eval.EvaluatorBuilder())
+				fakeRepo.GetClusterTemplateReturns(template, nil)
+				fakeRepo.EnsureObjectExistsOnClusterReturns(nil)
+			})
+
+			It("creates a stamped object and returns the outputs", func() {
+				out, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, allowUpdate := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(allowUpdate).To(BeTrue())
+				metadata := stampedObject.Object["metadata"]
+				metadataValues, ok := metadata.(map[string]interface{})
+				Expect(ok).To(BeTrue())
+				Expect(metadataValues["name"]).To(Equal("example-config-map"))
+				Expect(metadataValues["namespace"]).To(Equal("some-namespace"))
+				Expect(metadataValues["ownerReferences"]).To(Equal([]interface{}{
+					map[string]interface{}{
+						"apiVersion":         "carto.run/v1alpha1",
+						"kind":               "Workload",
+						"name":               "",
+						"uid":                "",
+						"controller":         true,
+						"blockOwnerDeletion": true,
+					},
+				}))
+				Expect(metadataValues["labels"]).To(Equal(map[string]interface{}{
+					"carto.run/cluster-supply-chain-name": "supply-chain-name",
+					"carto.run/resource-name":             "resource-1",
+					"carto.run/cluster-template-name":     "image-template-1",
+					"carto.run/workload-name":             "",
+					"carto.run/workload-namespace":        "",
+					"carto.run/template-kind":             "ClusterImageTemplate",
+				}))
+				Expect(stampedObject.Object["data"]).To(Equal(map[string]interface{}{"player_current_lives": "some-url", "some_other_info": "some-revision"}))
+
+				Expect(out.Image).To(Equal("some-revision"))
+			})
+
+			It("propagates the workload's labels and annotations the resource's policy selects", func() {
+				workload.Labels = map[string]string{"cost-center": "cc-42", "team": "payments"}
+				workload.Annotations = map[string]string{"example.com/owner": "payments"}
+				resource.Propagation = &v1alpha1.MetadataPropagation{
+					Labels:      &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAllowlist, Keys: []string{"cost-center"}},
+					Annotations: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+				}
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetLabels()).To(HaveKeyWithValue("cost-center", "cc-42"))
+				Expect(stampedObject.GetLabels()).NotTo(HaveKey("team"))
+				Expect(stampedObject.GetLabels()).To(HaveKeyWithValue("carto.run/resource-name", "resource-1"))
+				Expect(stampedObject.GetAnnotations()).To(Equal(map[string]string{"example.com/owner": "payments"}))
+			})
+
+			It("stamps without an owner reference, recording the workload's UID, when the resource is tracked", func() {
+				workload.UID = "workload-uid"
+				resource.Ownership = v1alpha1.TrackedOwnership
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetOwnerReferences()).To(BeEmpty())
+				Expect(stampedObject.GetAnnotations()).To(HaveKeyWithValue(v1alpha1.TrackedOwnerAnnotation, "workload-uid"))
+			})
+
+			It("does not track an object the resource orphans", func() {
+				resource.Ownership = v1alpha1.TrackedOwnership
+				resource.DeletionPolicy = v1alpha1.OrphanDeletionPolicy
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetAnnotations()).NotTo(HaveKey(v1alpha1.TrackedOwnerAnnotation))
+			})
+
+			It("keeps the outputs as realized in this realization", func() {
+				Expect(r.RealizedOutputs()).To(BeEmpty())
+
+				out, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(r.RealizedOutputs()).To(Equal(realizer.Outputs{"resource-1": out}))
+			})
+
+			It("keeps the resources as realized in this realization", func() {
+				Expect(r.RealizedResources()).To(BeEmpty())
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(r.RealizedResources()).To(Equal([]v1alpha1.RealizedResource{{
+					Resource:           "resource-1",
+					Template:           v1alpha1.ObjectReference{Kind: "ClusterImageTemplate", Name: "image-template-1"},
+					TemplateGeneration: 3,
+					Stamped:            v1alpha1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "some-namespace", Name: "example-config-map"},
+				}}))
+			})
+
+			It("keeps the template the resource was resolved to in this realization", func() {
+				Expect(r.ResolvedTemplates()).To(BeEmpty())
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(r.ResolvedTemplates()).To(Equal([]v1alpha1.ResolvedResourceTemplate{{
+					Resource: "resource-1",
+					Template: v1alpha1.ResolvedTemplateRef{Kind: "ClusterImageTemplate", Name: "image-template-1", Generation: 3},
+				}}))
+			})
+
+			It("remediates drift of the stamped object by default", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				ctx, _, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(repository.DriftReporterFrom(ctx)).To(BeNil())
+			})
+
+			Context("and the resource detects drift", func() {
+				BeforeEach(func() {
+					resource.Drift = v1alpha1.DetectDrift
+					fakeRepo.EnsureObjectExistsOnClusterStub = func(ctx context.Context, obj *unstructured.Unstructured, allowUpdate bool) error {
+						repository.DriftReporterFrom(ctx)(obj, []repository.Change{{Path: "data.some_other_info"}})
+						return nil
+					}
+				})
+
+				It("records the drifted objects the repository reports in the status", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(workload.Status.Drifted).To(Equal([]v1alpha1.DriftedObject{{
+						Resource: "resource-1",
+						Object:   v1alpha1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "some-namespace", Name: "example-config-map"},
+						Fields:   []string{"data.some_other_info"},
+					}}))
+				})
+			})
+
+			It("dry runs the stamped object when the resource asks for it", func() {
+				resource.DryRun = true
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				ctx, _, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(repository.DryRunFrom(ctx)).To(BeTrue())
+			})
+
+			Context("and the resource orphans its object", func() {
+				BeforeEach(func() {
+					resource.DeletionPolicy = v1alpha1.OrphanDeletionPolicy
+				})
+
+				It("stamps the object without an owner reference", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+					Expect(stampedObject.GetOwnerReferences()).To(BeEmpty())
+				})
+			})
+
+			Context("and the workload orphans its objects", func() {
+				BeforeEach(func() {
+					workload.Spec.DeletionPolicy = v1alpha1.OrphanDeletionPolicy
+				})
+
+				It("stamps the object without an owner reference", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+					Expect(stampedObject.GetOwnerReferences()).To(BeEmpty())
+				})
+
+				It("lets the resource's own policy take precedence", func() {
+					resource.DeletionPolicy = v1alpha1.DeleteDeletionPolicy
+
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+					Expect(stampedObject.GetOwnerReferences()).To(HaveLen(1))
+				})
+			})
+		})
+
+		When("the template marks params and outputs sensitive", func() {
+			BeforeEach(func() {
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-config",
+					},
+					Data: map[string]string{
+						"token": "$(params.token)$",
+						"image": "registry.example.com/app@sha256:abc",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterImageTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.ImageTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+							Params: v1alpha1.DefaultParams{
+								{Name: "token", DefaultValue: apiextensionsv1.JSON{Raw: []byte(`"none"`)}, Sensitive: true},
+							},
+						},
+						ImagePath:        "data.image",
+						SensitiveOutputs: []string{"image"},
+					},
+				}
+				resource.Params = []v1alpha1.Param{{Name: "token", Value: apiextensionsv1.JSON{Raw: []byte(`"s3cr3t"`)}}}
+
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterImageTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("stamps with their values, marking them to be redacted", func() {
+				redactor := redact.New()
+				ctx := redact.NewContext(context.TODO(), redactor)
+
+				out, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.Object["data"]).To(HaveKeyWithValue("token", "s3cr3t"))
+				Expect(out.Image).To(Equal("registry.example.com/app@sha256:abc"))
+
+				Expect(redactor.String("s3cr3t registry.example.com/app@sha256:abc")).To(Equal("[redacted] [redacted]"))
+			})
+		})
+
+		When("the workload has a name prefix", func() {
+			BeforeEach(func() {
+				workload.Name = "some-workload"
+				workload.Spec.NamePrefix = "legacy-app"
+
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: `$(workload.metadata.name)$-config`,
+					},
+					Data: map[string]string{
+						"value": "some-value",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterImageTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.ImageTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						ImagePath: "data.value",
+					},
+				}
+
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterImageTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("stamps the object under the prefix while labelling it with the workload name", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetName()).To(Equal("legacy-app-config"))
+				Expect(stampedObject.GetLabels()).To(HaveKeyWithValue("carto.run/workload-name", "some-workload"))
+				Expect(stampedObject.GetOwnerReferences()[0].Name).To(Equal("some-workload"))
+				Expect(workload.Name).To(Equal("some-workload"))
+			})
+		})
+
+		When("the resource names a service account", func() {
+			BeforeEach(func() {
+				workload.Namespace = "some-namespace"
+				resource.ServiceAccountName = "some-service-account"
+
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-config",
+					},
+					Data: map[string]string{
+						"value": "some-value",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterImageTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.ImageTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						ImagePath: "data.value",
+					},
+				}
+
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterImageTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("submits the stamped object as the service account", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(impersonated).To(Equal([]string{"some-namespace/some-service-account"}))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(saRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, stampedObject, _ := saRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetName()).To(Equal("some-config"))
+			})
+
+			When("the service account's repository cannot be built", func() {
+				BeforeEach(func() {
+					saRepoErr = errors.New("bad config")
+				})
+
+				It("returns ApplyStampedObjectError", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("bad config"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("workload.ApplyStampedObjectError"))
+					Expect(saRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		When("the resource targets another namespace", func() {
+			BeforeEach(func() {
+				workload.Namespace = "some-namespace"
+				workload.Name = "some-workload"
+				workload.UID = "some-uid"
+				resource.TargetNamespace = "shared-builds"
+				fakeRepo.GetUnstructuredReturns(kerrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "some-config"))
+
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-config",
+					},
+					Data: map[string]string{
+						"value": "some-value",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterImageTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.ImageTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						ImagePath: "data.value",
+					},
+				}
+
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterImageTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("stamps the object into the target namespace without an owner reference", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetNamespace()).To(Equal("shared-builds"))
+				Expect(stampedObject.GetOwnerReferences()).To(BeEmpty())
+				Expect(stampedObject.GetLabels()).To(HaveKeyWithValue("carto.run/workload-namespace", "some-namespace"))
+				Expect(stampedObject.GetLabels()).To(HaveKeyWithValue(v1alpha1.WorkloadUIDLabel, "some-uid"))
+			})
+
+			It("updates an object of the same name stamped for the workload", func() {
+				fakeRepo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					obj.SetLabels(map[string]string{
+						"carto.run/workload-name":      "some-workload",
+						"carto.run/workload-namespace": "some-namespace",
+						v1alpha1.WorkloadUIDLabel:      "some-uid",
+					})
+					return nil
+				}
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, existing := fakeRepo.GetUnstructuredArgsForCall(0)
+				Expect(existing.GetNamespace()).To(Equal("shared-builds"))
+				Expect(existing.GetName()).To(Equal("some-config"))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+			})
+
+			It("refuses to take over an object of the same name not stamped for the workload", func() {
+				fakeRepo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					obj.SetLabels(map[string]string{
+						"carto.run/workload-name":      "some-workload",
+						"carto.run/workload-namespace": "some-namespace",
+						v1alpha1.WorkloadUIDLabel:      "earlier-uid",
+					})
+					return nil
+				}
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(MatchError(ContainSubstring("ConfigMap 'some-config' already exists and was not stamped for this workload")))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.ApplyStampedObjectError"))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(workload.Status.CrossNamespaceObjects).To(BeEmpty())
+			})
+
+			It("records the object in the workload's status", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(workload.Status.CrossNamespaceObjects).To(Equal([]v1alpha1.ObjectReference{
+					{APIVersion: "v1", Kind: "ConfigMap", Namespace: "shared-builds", Name: "some-config"},
+				}))
+			})
+
+			It("refuses to stamp into it for a namespaced supply chain", func() {
+				supplyChain := &v1alpha1.SupplyChain{ObjectMeta: metav1.ObjectMeta{Name: "team-chain", Namespace: "some-namespace"}}
+				ctx := policy.WithBlueprint(context.TODO(), &workload, supplyChain)
+
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).To(MatchError("object 'shared-builds/some-config' of kind 'ConfigMap' violates policy rule 'ClusterStampPolicy': kind 'ConfigMap' may not be stamped into namespace 'shared-builds' by a namespaced blueprint, only into 'some-namespace'"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.PolicyViolationError"))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(fakeRepo.UpdateCallCount()).To(Equal(0))
+				Expect(workload.Status.CrossNamespaceObjects).To(BeEmpty())
+			})
+
+			It("treats the workload's own namespace as no target", func() {
+				resource.TargetNamespace = "some-namespace"
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetOwnerReferences()).To(HaveLen(1))
+				Expect(workload.Status.CrossNamespaceObjects).To(BeEmpty())
+			})
+
+			It("does not record objects the resource orphans, which are left to outlive the workload", func() {
+				resource.DeletionPolicy = v1alpha1.OrphanDeletionPolicy
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(workload.Status.CrossNamespaceObjects).To(BeEmpty())
+				Expect(r.StampedObjects()).To(HaveLen(1))
+			})
+
+			When("the resource hashes names", func() {
+				BeforeEach(func() {
+					resource.HashName = true
+				})
+
+				It("suffixes the object's name with a hash of the workload's namespace and name", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+					Expect(stampedObject.GetName()).To(MatchRegexp(`^some-config-[0-9a-f]{8}$`))
+					Expect(workload.Status.CrossNamespaceObjects[0].Name).To(Equal(stampedObject.GetName()))
+				})
+
+				It("gives workloads of other namespaces other names", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					workload.Namespace = "other-namespace"
+					_, err = r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					_, first, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+					_, second, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(1)
+					Expect(second.GetName()).NotTo(Equal(first.GetName()))
+				})
+			})
+		})
+
+		When("the object is of a cluster-scoped kind", func() {
+			var ctx context.Context
+
+			BeforeEach(func() {
+				workload.Namespace = "some-namespace"
+				workload.Name = "some-workload"
+				fakeRepo.GetUnstructuredReturns(kerrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "some-workload-builds"))
+
+				mapper := meta.NewDefaultRESTMapper(nil)
+				mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+				ctx = scope.NewContext(context.TODO(), mapper)
+
+				templateAPI := &v1alpha1.ClusterImageTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.ImageTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{
+								"apiVersion": "v1",
+								"kind": "Namespace",
+								"metadata": {"name": "$(workload.metadata.name)$-builds"}
+							}`)},
+						},
+						ImagePath: "metadata.name",
+					},
+				}
+
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterImageTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("stamps the object without a namespace or an owner reference", func() {
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetNamespace()).To(BeEmpty())
+				Expect(stampedObject.GetOwnerReferences()).To(BeEmpty())
+				Expect(stampedObject.GetLabels()).To(HaveKeyWithValue("carto.run/workload-namespace", "some-namespace"))
+			})
+
+			It("adds the cleanup finalizer before stamping, keeping the status realized so far", func() {
+				workload.Status.LastOutputs = []v1alpha1.LastOutput{{Resource: "previous-resource"}}
+				fakeRepo.UpdateStub = func(_ context.Context, obj client.Object) error {
+					obj.(*v1alpha1.Workload).Status = v1alpha1.WorkloadStatus{}
+					return nil
+				}
+
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeRepo.UpdateCallCount()).To(Equal(1))
+				_, updated := fakeRepo.UpdateArgsForCall(0)
+				Expect(updated.GetFinalizers()).To(ContainElement(v1alpha1.CrossNamespaceCleanupFinalizer))
+				Expect(workload.Status.LastOutputs).To(HaveLen(1))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+			})
+
+			It("does not update the workload when it already holds the finalizer", func() {
+				workload.Finalizers = []string{v1alpha1.CrossNamespaceCleanupFinalizer}
+
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeRepo.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("does not stamp the object when the finalizer cannot be added", func() {
+				fakeRepo.UpdateReturns(errors.New("conflict"))
+
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).To(MatchError(ContainSubstring("add finalizer: conflict")))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.ApplyStampedObjectError"))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+			})
+
+			It("records the object in the workload's status for cleanup", func() {
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(workload.Status.CrossNamespaceObjects).To(Equal([]v1alpha1.ObjectReference{
+					{APIVersion: "v1", Kind: "Namespace", Name: "some-workload-builds"},
+				}))
+			})
+
+			It("neither holds nor records objects the resource orphans", func() {
+				resource.DeletionPolicy = v1alpha1.OrphanDeletionPolicy
+
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeRepo.UpdateCallCount()).To(Equal(0))
+				Expect(workload.Status.CrossNamespaceObjects).To(BeEmpty())
+			})
+
+			It("stamps the object as namespaced without scope detection", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetNamespace()).To(Equal("some-namespace"))
+				Expect(workload.Status.CrossNamespaceObjects).To(BeEmpty())
+			})
+		})
+
+		When("the resource carries scheduling hints", func() {
+			BeforeEach(func() {
+				resource.Scheduling = &v1alpha1.SchedulingHints{
+					PriorityClassName: "platform-default",
+					Annotations:       map[string]string{"cost-center": "platform"},
+				}
+
+				pod := &corev1.Pod{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Pod",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-pod",
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "app", Image: "some-image"}},
+					},
+				}
+
+				dbytes, err := json.Marshal(pod)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.TemplateSpec{
+						Template: &runtime.RawExtension{Raw: dbytes},
+					},
+				}
+
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterTemplateModel(templateAPI), nil)
+			})
+
+			It("injects the hints into the stamped object", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetAnnotations()).To(HaveKeyWithValue("cost-center", "platform"))
+				Expect(stampedObject.Object["spec"]).To(HaveKeyWithValue("priorityClassName", "platform-default"))
+			})
+		})
+
+		When("the template stamps several objects", func() {
+			BeforeEach(func() {
+				templateAPI := &v1alpha1.ClusterConfigTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.ConfigTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{
+								"apiVersion": "v1",
+								"kind": "List",
+								"items": [
+									{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "first"}, "data": {"config": "first-config"}},
+									{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "second"}, "data": {"config": "second-config"}}
+								]
+							}`)},
+						},
+						ConfigPath: "data.config",
+					},
+				}
+
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterConfigTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
+
+			It("applies each object and reads the outputs from the first", func() {
+				out, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out.Config).To(Equal("first-config"))
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(2))
+				_, first, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				_, second, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(1)
+				Expect(first.GetName()).To(Equal("first"))
+				Expect(second.GetName()).To(Equal("second"))
+				Expect(second.GetLabels()).To(HaveKeyWithValue("carto.run/resource-name", resource.Name))
+
+				Expect(r.StampedObjects()).To(Equal([]v1alpha1.ObjectReference{
+					{APIVersion: "v1", Kind: "ConfigMap", Name: "first"},
+					{APIVersion: "v1", Kind: "ConfigMap", Name: "second"},
+				}))
+			})
+
+			It("stops at the first object that fails to apply, returning it with the error", func() {
+				fakeRepo.EnsureObjectExistsOnClusterReturnsOnCall(0, nil)
+				fakeRepo.EnsureObjectExistsOnClusterReturnsOnCall(1, errors.New("bad object"))
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(BeAssignableToTypeOf(realizer.ApplyStampedObjectError{}))
+				Expect(err.(realizer.ApplyStampedObjectError).StampedObject.GetName()).To(Equal("second"))
+			})
+		})
+
+		When("the template includes partials and helpers", func() {
+			BeforeEach(func() {
+				templateAPI := &v1alpha1.ClusterConfigTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "some-template",
+					},
+					Spec: v1alpha1.ConfigTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: []byte(`{
+								"apiVersion": "v1",
+								"kind": "ConfigMap",
+								"metadata": {"name": "$(helpers.configName)$"},
+								"data": "$(partials.data)$"
+							}`)},
+							Libraries: []string{"standard"},
+						},
+						ConfigPath: "data.owner",
+					},
+				}
+
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterConfigTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+				workload.Name = "my-app"
+				fakeRepo.GetTemplateLibraryReturns(&v1alpha1.ClusterTemplateLibrary{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "standard",
+					},
+					Spec: v1alpha1.TemplateLibrarySpec{
+						Partials: []v1alpha1.TemplatePartial{
+							{Name: "data", Template: runtime.RawExtension{Raw: []byte(`{"owner": "$(workload.metadata.name)$"}`)}},
+						},
+						Helpers: []v1alpha1.TemplateHelper{
+							{Name: "configName", Expression: `value.workload.metadata.name + "-config"`},
+						},
+					},
+				}, nil)
+			})
+
+			It("stamps the partials and helpers into the object, interpolating the partials", func() {
+				out, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out.Config).To(Equal("my-app"))
+
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetName()).To(Equal("my-app-config"))
+
+				_, name := fakeRepo.GetTemplateLibraryArgsForCall(0)
+				Expect(name).To(Equal("standard"))
+				Expect(r.Libraries()).To(Equal([]string{"standard"}))
+			})
+
+			It("returns StampError when a library does not exist, still remembering it", func() {
+				fakeRepo.GetTemplateLibraryReturns(nil, nil)
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(BeAssignableToTypeOf(realizer.StampError{}))
+				Expect(err).To(MatchError(ContainSubstring("template library 'standard' not found")))
+				Expect(r.Libraries()).To(Equal([]string{"standard"}))
+			})
+		})
+
+		When("the template patches a config", func() {
+			BeforeEach(func() {
+				resource.Configs = []v1alpha1.ResourceReference{
+					{Name: "app", Resource: "previous-resource"},
+				}
+				outputs.AddOutput("previous-resource", &templates.Output{Config: map[string]interface{}{
+					"apiVersion": "example.com/v1",
+					"kind":       "App",
+					"spec":       map[string]interface{}{"env": "dev"},
+				}})
+
+				templateAPI := &v1alpha1.ClusterConfigTemplate{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ClusterConfigTemplate",
+						APIVersion: "carto.run/v1alpha1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "overlay",
+					},
+					Spec: v1alpha1.ConfigTemplateSpec{
+						Patches: &v1alpha1.ConfigPatches{
+							StrategicMerge: []runtime.RawExtension{{Raw: []byte(`{"spec": {"env": "$(workload.metadata.name)$"}}`)}},
+						},
+					},
+				}
+
+				workload.Name = "prod"
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterConfigTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+			})
 
-				configMap := &corev1.ConfigMap{
+			It("outputs the patched config without stamping an object", func() {
+				out, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out.Config).To(HaveKeyWithValue("spec", map[string]interface{}{"env": "prod"}))
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(r.StampedObjects()).To(BeEmpty())
+				Expect(r.RealizedOutputs()).To(HaveKeyWithValue(resource.Name, out))
+				Expect(r.RealizedResources()).To(HaveLen(1))
+				Expect(r.RealizedResources()[0].Template).To(Equal(v1alpha1.ObjectReference{Kind: "ClusterConfigTemplate", Name: "overlay"}))
+			})
+
+			It("returns StampError when the config cannot be patched", func() {
+				resource.Configs = nil
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(BeAssignableToTypeOf(realizer.StampError{}))
+				Expect(err).To(MatchError(ContainSubstring("found 0 config inputs")))
+			})
+		})
+
+		When("the template has a job lifecycle", func() {
+			var jobStatus map[string]interface{}
+
+			BeforeEach(func() {
+				job := &batchv1.Job{
 					TypeMeta: metav1.TypeMeta{
-						Kind:       "ConfigMap",
-						APIVersion: "v1",
+						Kind:       "Job",
+						APIVersion: "batch/v1",
 					},
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "example-config-map",
-						Namespace: "some-namespace",
+						Name: "scan",
 					},
-					Data: map[string]string{
-						"player_current_lives": `$(source.url)$`,
-						"some_other_info":      `$(sources.source-provider.revision)$`,
+					Spec: batchv1.JobSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers:    []corev1.Container{{Name: "scan", Image: "scanner"}},
+								RestartPolicy: corev1.RestartPolicyNever,
+							},
+						},
+					},
+				}
+
+				dbytes, err := json.Marshal(job)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterConfigTemplate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "scan-template",
+					},
+					Spec: v1alpha1.ConfigTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template:  &runtime.RawExtension{Raw: dbytes},
+							Lifecycle: v1alpha1.JobTemplateLifecycle,
+							Results:   &v1alpha1.JobResults{From: v1alpha1.ConfigMapJobResults},
+						},
+						ConfigPath: "report",
 					},
 				}
 
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterConfigTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+
+				jobStatus = map[string]interface{}{}
+				fakeRepo.EnsureImmutableObjectExistsOnClusterStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					obj.Object["status"] = jobStatus
+					return nil
+				}
+				fakeRepo.GetUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) error {
+					obj.Object["data"] = map[string]interface{}{"report": "clean"}
+					return nil
+				}
+			})
+
+			It("creates the job under a name derived from its spec", func() {
+				_, _ = r.Do(context.TODO(), &resource, supplyChainName, outputs)
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(fakeRepo.EnsureImmutableObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, stampedObject := fakeRepo.EnsureImmutableObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.GetName()).To(MatchRegexp(`^scan-[0-9a-f]{10}$`))
+			})
+
+			It("returns JobRunningError while the job runs", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(HaveOccurred())
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.JobRunningError"))
+			})
+
+			It("returns JobFailedError when the job fails", func() {
+				jobStatus["conditions"] = []interface{}{
+					map[string]interface{}{"type": "Failed", "status": "True", "message": "BackoffLimitExceeded"},
+				}
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("BackoffLimitExceeded"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.JobFailedError"))
+			})
+
+			When("the job has completed", func() {
+				BeforeEach(func() {
+					jobStatus["conditions"] = []interface{}{
+						map[string]interface{}{"type": "Complete", "status": "True"},
+					}
+				})
+
+				It("returns the outputs read from its results", func() {
+					out, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(out.Config).To(Equal("clean"))
+				})
+
+				It("deletes the jobs run for earlier inputs", func() {
+					earlier := &unstructured.Unstructured{}
+					earlier.SetName("scan-earlier")
+					fakeRepo.ListUnstructuredReturns([]*unstructured.Unstructured{earlier}, nil)
+
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(fakeRepo.DeleteUnstructuredCallCount()).To(Equal(1))
+					_, deleted := fakeRepo.DeleteUnstructuredArgsForCall(0)
+					Expect(deleted.GetName()).To(Equal("scan-earlier"))
+				})
+			})
+		})
+
+		When("the template reads the cluster data", func() {
+			BeforeEach(func() {
+				workload.Name = "app"
+				workload.Namespace = "dev"
+
+				configMap := map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata":   map[string]interface{}{"name": "app-host"},
+					"data": map[string]interface{}{
+						"host":  "$(workload.metadata.name)$.$(cluster.data.ingressDomain)$",
+						"nodes": "$(cluster.nodes)$",
+					},
+				}
 				dbytes, err := json.Marshal(configMap)
 				Expect(err).ToNot(HaveOccurred())
 
-				templateAPI := &v1alpha1.ClusterImageTemplate{
-					TypeMeta: metav1.TypeMeta{
-						Kind:       "ClusterImageTemplate",
-						APIVersion: "carto.run/v1alpha1",
+				templateAPI := &v1alpha1.ClusterTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "host-template"},
+					Spec: v1alpha1.TemplateSpec{
+						Template: &runtime.RawExtension{Raw: dbytes},
 					},
+				}
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterTemplateModel(templateAPI), nil)
+			})
+
+			It("stamps the template with the cluster data carried by the context", func() {
+				ctx := clusterdata.NewContext(context.TODO(), map[string]interface{}{
+					"name":   "prod",
+					"domain": "cluster.local",
+					"nodes":  int64(3),
+					"data":   map[string]interface{}{"ingressDomain": "apps.example.com"},
+				})
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(1))
+				_, stampedObject, _ := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
+				Expect(stampedObject.Object["data"]).To(Equal(map[string]interface{}{
+					"host":  "app.apps.example.com",
+					"nodes": float64(3),
+				}))
+			})
+
+			It("returns StampError when the context carries no cluster data", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(HaveOccurred())
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.StampError"))
+			})
+		})
+
+		When("the template names a realizer plugin", func() {
+			var plugins *pluginfakes.FakeRealizer
+
+			BeforeEach(func() {
+				workload.Name = "app"
+				workload.Namespace = "dev"
+
+				database := map[string]interface{}{
+					"apiVersion": "example.com/v1",
+					"kind":       "Database",
+					"metadata":   map[string]interface{}{"name": "db"},
+					"spec":       map[string]interface{}{"owner": "$(workload.metadata.name)$"},
+				}
+				dbytes, err := json.Marshal(database)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterConfigTemplate{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "image-template-1",
-						Namespace: "some-namespace",
+						Name: "database-template",
 					},
-					Spec: v1alpha1.ImageTemplateSpec{
+					Spec: v1alpha1.ConfigTemplateSpec{
 						TemplateSpec: v1alpha1.TemplateSpec{
 							Template: &runtime.RawExtension{Raw: dbytes},
+							Plugin:   "terraform",
 						},
-						ImagePath: "data.some_other_info",
+						ConfigPath: "url",
 					},
 				}
+				fakeRepo.GetClusterTemplateReturns(templates.NewClusterConfigTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
 
-				template := templates.NewClusterImageTemplateModel(templateAPI, eval.EvaluatorBuilder())
-				fakeRepo.GetClusterTemplateReturns(template, nil)
-				fakeRepo.EnsureObjectExistsOnClusterReturns(nil)
+				plugins = &pluginfakes.FakeRealizer{}
+				plugins.RealizeReturns(map[string]interface{}{"url": "postgres://db"}, nil)
 			})
 
-			It("creates a stamped object and returns the outputs", func() {
-				out, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+			It("has the plugin realize the stamped objects instead of submitting them", func() {
+				ctx := plugin.NewContext(context.TODO(), plugins)
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
 				Expect(err).ToNot(HaveOccurred())
 
-				stampedObject, allowUpdate := fakeRepo.EnsureObjectExistsOnClusterArgsForCall(0)
-				Expect(allowUpdate).To(BeTrue())
-				metadata := stampedObject.Object["metadata"]
-				metadataValues, ok := metadata.(map[string]interface{})
-				Expect(ok).To(BeTrue())
-				Expect(metadataValues["name"]).To(Equal("example-config-map"))
-				Expect(metadataValues["namespace"]).To(Equal("some-namespace"))
-				Expect(metadataValues["ownerReferences"]).To(Equal([]interface{}{
-					map[string]interface{}{
-						"apiVersion":         "",
-						"kind":               "",
-						"name":               "",
-						"uid":                "",
-						"controller":         true,
-						"blockOwnerDeletion": true,
-					},
-				}))
-				Expect(metadataValues["labels"]).To(Equal(map[string]interface{}{
-					"carto.run/cluster-supply-chain-name": "supply-chain-name",
-					"carto.run/resource-name":             "resource-1",
-					"carto.run/cluster-template-name":     "image-template-1",
-					"carto.run/workload-name":             "",
-					"carto.run/workload-namespace":        "",
-					"carto.run/template-kind":             "ClusterImageTemplate",
-				}))
-				Expect(stampedObject.Object["data"]).To(Equal(map[string]interface{}{"player_current_lives": "some-url", "some_other_info": "some-revision"}))
+				Expect(fakeRepo.EnsureObjectExistsOnClusterCallCount()).To(Equal(0))
+				Expect(r.StampedObjects()).To(BeEmpty())
 
-				Expect(out.Image).To(Equal("some-revision"))
+				Expect(plugins.RealizeCallCount()).To(Equal(1))
+				_, request := plugins.RealizeArgsForCall(0)
+				Expect(request.Plugin).To(Equal("terraform"))
+				Expect(request.Owner).To(Equal(v1alpha1.ObjectReference{APIVersion: "carto.run/v1alpha1", Kind: "Workload", Namespace: "dev", Name: "app"}))
+				Expect(request.Resource).To(Equal("resource-1"))
+				Expect(request.Objects).To(HaveLen(1))
+				Expect(request.Objects[0]["spec"]).To(Equal(map[string]interface{}{"owner": "app"}))
+			})
+
+			It("returns the outputs read from what the plugin returned", func() {
+				ctx := plugin.NewContext(context.TODO(), plugins)
+				out, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out.Config).To(Equal("postgres://db"))
+			})
+
+			It("returns PluginRunningError while the plugin runs", func() {
+				plugins.RealizeReturns(nil, plugin.RunningError{Plugin: "terraform", Message: "applying"})
+
+				ctx := plugin.NewContext(context.TODO(), plugins)
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).To(MatchError("waiting for plugin of resource 'resource-1': plugin 'terraform' is realizing the objects: applying"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.PluginRunningError"))
+			})
+
+			It("returns PluginFailedError when the plugin fails", func() {
+				plugins.RealizeReturns(nil, plugin.FailedError{Plugin: "terraform", Message: "quota exceeded"})
+
+				ctx := plugin.NewContext(context.TODO(), plugins)
+				_, err := r.Do(ctx, &resource, supplyChainName, outputs)
+				Expect(err).To(MatchError("plugin of resource 'resource-1' failed: plugin 'terraform' failed: quota exceeded"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.PluginFailedError"))
+			})
+
+			It("returns RetrieveOutputError when no plugins are registered", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("realizer plugin 'terraform' is not registered"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.RetrieveOutputError"))
 			})
 		})
 
@@ -167,6 +1217,66 @@ var _ = Describe("Resource", func() {
 			})
 		})
 
+		When("the template ref does not exist", func() {
+			BeforeEach(func() {
+				fakeRepo.GetClusterTemplateReturns(nil, fmt.Errorf("get: %w", kerrors.NewNotFound(schema.GroupResource{Group: "carto.run", Resource: "clusterimagetemplates"}, "image-template-1")))
+			})
+
+			It("returns TemplateNotFoundError", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("template 'image-template-1' of kind 'ClusterImageTemplate' not found"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.TemplateNotFoundError"))
+			})
+
+			It("does not keep a resolved template for the resource", func() {
+				_, _ = r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(r.ResolvedTemplates()).To(BeEmpty())
+			})
+		})
+
+		When("the template ref has options", func() {
+			BeforeEach(func() {
+				resource.TemplateRef = v1alpha1.ClusterTemplateReference{
+					Kind: "ClusterImageTemplate",
+					Options: []v1alpha1.TemplateOption{
+						{
+							Name: "java-build",
+							Selector: v1alpha1.OptionSelector{
+								LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"language": "java"}},
+							},
+						},
+						{
+							Name: "go-build",
+							Selector: v1alpha1.OptionSelector{
+								LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"language": "go"}},
+							},
+						},
+					},
+				}
+				fakeRepo.GetClusterTemplateReturns(nil, errors.New("stop here"))
+			})
+
+			It("gets the template of the option the workload matches", func() {
+				workload.Labels = map[string]string{"language": "go"}
+
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(MatchError(ContainSubstring("unable to get template 'go-build'")))
+
+				_, templateRef := fakeRepo.GetClusterTemplateArgsForCall(0)
+				Expect(templateRef).To(Equal(v1alpha1.ClusterTemplateReference{Kind: "ClusterImageTemplate", Name: "go-build"}))
+			})
+
+			It("returns TemplateOptionsError when no option matches", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(MatchError("unable to choose a template for resource 'resource-1': no option of java-build|go-build matches the workload"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.TemplateOptionsError"))
+
+				Expect(fakeRepo.GetClusterTemplateCallCount()).To(Equal(0))
+			})
+		})
+
 		When("unable to Stamp a new template", func() {
 			BeforeEach(func() {
 				templateAPI := &v1alpha1.ClusterImageTemplate{
@@ -198,6 +1308,8 @@ var _ = Describe("Resource", func() {
 		})
 
 		When("unable to retrieve the output from the stamped object", func() {
+			var templateAPI *v1alpha1.ClusterImageTemplate
+
 			BeforeEach(func() {
 				configMap := &corev1.ConfigMap{
 					TypeMeta: metav1.TypeMeta{
@@ -217,7 +1329,7 @@ var _ = Describe("Resource", func() {
 				dbytes, err := json.Marshal(configMap)
 				Expect(err).ToNot(HaveOccurred())
 
-				templateAPI := &v1alpha1.ClusterImageTemplate{
+				templateAPI = &v1alpha1.ClusterImageTemplate{
 					TypeMeta: metav1.TypeMeta{
 						Kind:       "ClusterImageTemplate",
 						APIVersion: "carto.run/v1alpha1",
@@ -245,6 +1357,80 @@ var _ = Describe("Resource", func() {
 				Expect(err.Error()).To(ContainSubstring("find results: does-not-exist is not found"))
 				Expect(reflect.TypeOf(err).String()).To(Equal("workload.RetrieveOutputError"))
 			})
+
+			It("keeps the object as stamped, though it has not output", func() {
+				_, _ = r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(r.RealizedResources()).To(BeEmpty())
+				Expect(r.StampedObjects()).To(Equal([]v1alpha1.ObjectReference{
+					{APIVersion: "v1", Kind: "ConfigMap", Namespace: "some-namespace", Name: "example-config-map"},
+				}))
+			})
+
+			When("the output is optional", func() {
+				BeforeEach(func() {
+					templateAPI.Spec.OptionalOutputs = []string{"image"}
+					fakeRepo.GetClusterTemplateReturns(templates.NewClusterImageTemplateModel(templateAPI, eval.EvaluatorBuilder()), nil)
+				})
+
+				It("outputs it as null and records it as missing in the status", func() {
+					out, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(out.Image).To(BeNil())
+					Expect(workload.Status.MissingOptionalOutputs).To(Equal([]v1alpha1.MissingOutput{{
+						Resource:   "resource-1",
+						Output:     "image",
+						Expression: "data.does-not-exist",
+					}}))
+				})
+			})
+		})
+
+		When("the output path is not a valid jsonpath expression", func() {
+			BeforeEach(func() {
+				configMap := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "example-config-map",
+						Namespace: "some-namespace",
+					},
+					Data: map[string]string{
+						"some_other_info": "10",
+					},
+				}
+
+				dbytes, err := json.Marshal(configMap)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateAPI := &v1alpha1.ClusterImageTemplate{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ClusterImageTemplate",
+						APIVersion: "carto.run/v1alpha1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "image-template-1",
+						Namespace: "some-namespace",
+					},
+					Spec: v1alpha1.ImageTemplateSpec{
+						TemplateSpec: v1alpha1.TemplateSpec{
+							Template: &runtime.RawExtension{Raw: dbytes},
+						},
+						ImagePath: "data[",
+					},
+				}
+
+				template := templates.NewClusterImageTemplateModel(templateAPI, eval.EvaluatorBuilder())
+				fakeRepo.GetClusterTemplateReturns(template, nil)
+			})
+
+			It("returns OutputPathError", func() {
+				_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid output path for resource 'resource-1'"))
+				Expect(reflect.TypeOf(err).String()).To(Equal("workload.OutputPathError"))
+			})
 		})
 
 		When("unable to EnsureObjectExistsOnCluster the stamped object", func() {
@@ -307,6 +1493,129 @@ var _ = Describe("Resource", func() {
 				Expect(err.Error()).To(ContainSubstring("bad object"))
 				Expect(reflect.TypeOf(err).String()).To(Equal("workload.ApplyStampedObjectError"))
 			})
+
+			When("the object violates the stamp policy", func() {
+				BeforeEach(func() {
+					fakeRepo.EnsureObjectExistsOnClusterReturns(policy.ViolationError{Rule: "no-config-maps", Message: "config maps are not allowed"})
+				})
+
+				It("returns PolicyViolationError", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).To(HaveOccurred())
+
+					Expect(err.Error()).To(ContainSubstring("object 'some-namespace/example-config-map' of kind 'ConfigMap' violates policy rule 'no-config-maps': config maps are not allowed"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("workload.PolicyViolationError"))
+				})
+			})
+
+			When("the dry run of the object is rejected", func() {
+				BeforeEach(func() {
+					fakeRepo.EnsureObjectExistsOnClusterReturns(repository.RejectedError{Err: kerrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "example-config-map", errors.New("denied by webhook"))})
+				})
+
+				It("returns StampedObjectRejectedError", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).To(HaveOccurred())
+
+					Expect(err.Error()).To(ContainSubstring("object 'some-namespace/example-config-map' of kind 'ConfigMap' rejected on dry run"))
+					Expect(err.Error()).To(ContainSubstring("denied by webhook"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("workload.StampedObjectRejectedError"))
+				})
+			})
+
+			When("the kind of the object is not registered", func() {
+				BeforeEach(func() {
+					fakeRepo.EnsureObjectExistsOnClusterReturns(fmt.Errorf("list: %w", &meta.NoKindMatchError{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, SearchedVersions: []string{"v1"}}))
+				})
+
+				It("returns KindNotRegisteredError", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).To(HaveOccurred())
+
+					Expect(err.Error()).To(ContainSubstring("kind 'ConfigMap' of 'v1' is not registered with the apiserver"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("workload.KindNotRegisteredError"))
+				})
+			})
+
+			When("the apiServer reports a conflict", func() {
+				BeforeEach(func() {
+					fakeRepo.EnsureObjectExistsOnClusterReturns(fmt.Errorf("patch: %w", kerrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "example-config-map", errors.New("object has been modified"))))
+				})
+
+				It("returns ApplyConflictError", func() {
+					_, err := r.Do(context.TODO(), &resource, supplyChainName, outputs)
+					Expect(err).To(HaveOccurred())
+
+					Expect(err.Error()).To(ContainSubstring("conflict applying object 'some-namespace/example-config-map'"))
+					Expect(reflect.TypeOf(err).String()).To(Equal("workload.ApplyConflictError"))
+				})
+			})
+		})
+	})
+	Describe("LastOutput", func() {
+		It("returns nothing for resources without recorded outputs", func() {
+			Expect(r.LastOutput("resource-1")).To(BeNil())
+		})
+
+		It("returns the outputs recorded by RecordLastOutputs, flagged as stale", func() {
+			recorded := realizer.NewOutputs()
+			recorded.AddOutput("resource-2", &templates.Output{Image: "some-image"})
+			recorded.AddOutput("resource-1", &templates.Output{Source: &templates.Source{URL: "some-url", Revision: "some-revision"}})
+			Expect(r.RecordLastOutputs(recorded)).To(Succeed())
+
+			Expect(workload.Status.LastOutputs).To(HaveLen(2))
+			Expect(workload.Status.LastOutputs[0].Resource).To(Equal("resource-1"))
+			Expect(string(workload.Status.LastOutputs[0].Output.Raw)).To(MatchJSON(`{"source": {"url": "some-url", "revision": "some-revision"}}`))
+
+			Expect(r.LastOutput("resource-1")).To(Equal(&templates.Output{
+				Source: &templates.Source{URL: "some-url", Revision: "some-revision"},
+				Stale:  true,
+			}))
+			Expect(r.LastOutput("resource-2")).To(Equal(&templates.Output{Image: "some-image", Stale: true}))
+		})
+
+		It("leaves out outputs with sensitive values", func() {
+			recorded := realizer.NewOutputs()
+			recorded.AddOutput("resource-1", &templates.Output{Image: "some-image", Sensitive: []string{"image"}})
+			recorded.AddOutput("resource-2", &templates.Output{Config: "some-config"})
+			Expect(r.RecordLastOutputs(recorded)).To(Succeed())
+
+			Expect(workload.Status.LastOutputs).To(HaveLen(1))
+			Expect(workload.Status.LastOutputs[0].Resource).To(Equal("resource-2"))
+			Expect(r.LastOutput("resource-1")).To(BeNil())
+		})
+
+		It("forgets outputs that are no longer recorded", func() {
+			recorded := realizer.NewOutputs()
+			recorded.AddOutput("resource-1", &templates.Output{Config: "some-config"})
+			Expect(r.RecordLastOutputs(recorded)).To(Succeed())
+			Expect(r.RecordLastOutputs(realizer.NewOutputs())).To(Succeed())
+
+			Expect(workload.Status.LastOutputs).To(BeEmpty())
+			Expect(r.LastOutput("resource-1")).To(BeNil())
+		})
+	})
+
+	Describe("RecordRetry", func() {
+		It("counts the retries of each resource in the workload's status", func() {
+			r.RecordRetry("resource-1")
+			r.RecordRetry("resource-2")
+			r.RecordRetry("resource-1")
+
+			Expect(workload.Status.Retries).To(Equal([]v1alpha1.ResourceRetries{
+				{Resource: "resource-1", Retries: 2},
+				{Resource: "resource-2", Retries: 1},
+			}))
+		})
+
+		It("does not modify the retries it replaces", func() {
+			previous := []v1alpha1.ResourceRetries{{Resource: "resource-1", Retries: 1}}
+			workload.Status.Retries = previous
+
+			r.RecordRetry("resource-1")
+
+			Expect(previous[0].Retries).To(Equal(int64(1)))
+			Expect(workload.Status.Retries[0].Retries).To(Equal(int64(2)))
 		})
 	})
 })
@@ -15,8 +15,11 @@
 package workload
 
 import (
+	"fmt"
+
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
 	"github.com/vmware-tanzu/cartographer/pkg/templates"
+	"github.com/vmware-tanzu/cartographer/pkg/transform"
 )
 
 type Outputs map[string]*templates.Output
@@ -29,6 +32,26 @@ func (o Outputs) AddOutput(name string, output *templates.Output) {
 	o[name] = output
 }
 
+func (o Outputs) isStale(resourceName string) bool {
+	output := o[resourceName]
+	return output != nil && output.Stale
+}
+
+// isMissing reports whether the named output of the resource is optional
+// and was missing from its object, so that it is passed on as null.
+func (o Outputs) isMissing(resourceName, outputName string) bool {
+	output := o[resourceName]
+	if output == nil {
+		return false
+	}
+	for _, missing := range output.Missing {
+		if missing.Name == outputName {
+			return true
+		}
+	}
+	return false
+}
+
 func (o Outputs) getResourceSource(resourceName string) *templates.Source {
 	output := o[resourceName]
 	if output == nil {
@@ -54,7 +77,7 @@ func (o Outputs) getResourceConfig(resourceName string) templates.Config {
 	return output.Config
 }
 
-func (o Outputs) GenerateInputs(resource *v1alpha1.SupplyChainResource) *templates.Inputs {
+func (o Outputs) GenerateInputs(resource *v1alpha1.SupplyChainResource) (*templates.Inputs, error) {
 	inputs := &templates.Inputs{
 		Sources: map[string]templates.SourceInput{},
 		Images:  map[string]templates.ImageInput{},
@@ -64,33 +87,58 @@ func (o Outputs) GenerateInputs(resource *v1alpha1.SupplyChainResource) *templat
 	for _, referenceSource := range resource.Sources {
 		source := o.getResourceSource(referenceSource.Resource)
 		if source != nil {
+			url, revision := source.URL, source.Revision
+			if referenceSource.Transform != "" {
+				var err error
+				url, revision, err = transform.ApplySource(referenceSource.Transform, url, revision)
+				if err != nil {
+					return nil, fmt.Errorf("transform source '%s': %w", referenceSource.Name, err)
+				}
+			}
 			inputs.Sources[referenceSource.Name] = templates.SourceInput{
-				URL:      source.URL,
-				Revision: source.Revision,
+				URL:      url,
+				Revision: revision,
 				Name:     referenceSource.Name,
+				Stale:    o.isStale(referenceSource.Resource),
 			}
 		}
 	}
 
 	for _, referenceImage := range resource.Images {
-		image := o.getResourceImage(referenceImage.Resource)
-		if image != nil {
+		var image interface{} = o.getResourceImage(referenceImage.Resource)
+		if image != nil || o.isMissing(referenceImage.Resource, "image") {
+			if image != nil && referenceImage.Transform != "" {
+				var err error
+				image, err = transform.Apply(referenceImage.Transform, image)
+				if err != nil {
+					return nil, fmt.Errorf("transform image '%s': %w", referenceImage.Name, err)
+				}
+			}
 			inputs.Images[referenceImage.Name] = templates.ImageInput{
 				Image: image,
 				Name:  referenceImage.Name,
+				Stale: o.isStale(referenceImage.Resource),
 			}
 		}
 	}
 
 	for _, referenceConfig := range resource.Configs {
-		config := o.getResourceConfig(referenceConfig.Resource)
-		if config != nil {
+		var config interface{} = o.getResourceConfig(referenceConfig.Resource)
+		if config != nil || o.isMissing(referenceConfig.Resource, "config") {
+			if config != nil && referenceConfig.Transform != "" {
+				var err error
+				config, err = transform.Apply(referenceConfig.Transform, config)
+				if err != nil {
+					return nil, fmt.Errorf("transform config '%s': %w", referenceConfig.Name, err)
+				}
+			}
 			inputs.Configs[referenceConfig.Name] = templates.ConfigInput{
 				Config: config,
 				Name:   referenceConfig.Name,
+				Stale:  o.isStale(referenceConfig.Resource),
 			}
 		}
 	}
 
-	return inputs
+	return inputs, nil
 }
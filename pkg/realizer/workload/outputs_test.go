@@ -48,7 +48,8 @@ var _ = Describe("Outputs", func() {
 							},
 						},
 					}
-					inputs := outs.GenerateInputs(resource)
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
 					Expect(inputs.Sources).To(HaveLen(1))
 					Expect(inputs.Sources["source-ref"].Name).To(Equal("source-ref"))
 					Expect(inputs.Sources["source-ref"].URL).To(Equal("source-url"))
@@ -56,6 +57,38 @@ var _ = Describe("Outputs", func() {
 				})
 			})
 
+			Context("And the source reference has a transform", func() {
+				It("Adds the transformed source to inputs", func() {
+					resource := &v1alpha1.SupplyChainResource{
+						Sources: []v1alpha1.ResourceReference{
+							{
+								Name:      "source-ref",
+								Resource:  "source-output",
+								Transform: `{"url": value.url + "/sub", "revision": "rev-" + value.revision}`,
+							},
+						},
+					}
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(inputs.Sources["source-ref"].URL).To(Equal("source-url/sub"))
+					Expect(inputs.Sources["source-ref"].Revision).To(Equal("rev-source-revision"))
+				})
+
+				It("Errors when the transform does not return a map", func() {
+					resource := &v1alpha1.SupplyChainResource{
+						Sources: []v1alpha1.ResourceReference{
+							{
+								Name:      "source-ref",
+								Resource:  "source-output",
+								Transform: `value.url`,
+							},
+						},
+					}
+					_, err := outs.GenerateInputs(resource)
+					Expect(err).To(MatchError(ContainSubstring("transform source 'source-ref'")))
+				})
+			})
+
 			Context("And the sources do not have a match with the outputs", func() {
 				It("Does not add sources to inputs", func() {
 					resource := &v1alpha1.SupplyChainResource{
@@ -66,7 +99,8 @@ var _ = Describe("Outputs", func() {
 							},
 						},
 					}
-					inputs := outs.GenerateInputs(resource)
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
 					Expect(len(inputs.Sources)).To(Equal(0))
 				})
 			})
@@ -92,13 +126,52 @@ var _ = Describe("Outputs", func() {
 							},
 						},
 					}
-					inputs := outs.GenerateInputs(resource)
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
 					Expect(inputs.Images).To(HaveLen(1))
 					Expect(inputs.Images["image-ref"].Name).To(Equal("image-ref"))
 					Expect(inputs.Images["image-ref"].Image).To(Equal("image12345"))
 				})
 			})
 
+			Context("And the image reference has a transform", func() {
+				It("Adds the transformed image to inputs", func() {
+					resource := &v1alpha1.SupplyChainResource{
+						Images: []v1alpha1.ResourceReference{
+							{
+								Name:      "image-ref",
+								Resource:  "image-output",
+								Transform: `"registry.example.com/" + value`,
+							},
+						},
+					}
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(inputs.Images["image-ref"].Image).To(Equal("registry.example.com/image12345"))
+				})
+			})
+
+			Context("And the image is an optional output missing from its object", func() {
+				It("Adds it to inputs as null, without transforming it", func() {
+					outs.AddOutput("image-output", &templates.Output{
+						Missing: []templates.MissingOutput{{Name: "image", Expression: ".status.latestImage"}},
+					})
+					resource := &v1alpha1.SupplyChainResource{
+						Images: []v1alpha1.ResourceReference{
+							{
+								Name:      "image-ref",
+								Resource:  "image-output",
+								Transform: `"registry.example.com/" + value`,
+							},
+						},
+					}
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(inputs.Images).To(HaveKey("image-ref"))
+					Expect(inputs.Images["image-ref"].Image).To(BeNil())
+				})
+			})
+
 			Context("And the images do not have a match with the outputs", func() {
 				It("Does not add images to inputs", func() {
 					resource := &v1alpha1.SupplyChainResource{
@@ -109,7 +182,8 @@ var _ = Describe("Outputs", func() {
 							},
 						},
 					}
-					inputs := outs.GenerateInputs(resource)
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
 					Expect(inputs.Sources).To(BeEmpty())
 				})
 			})
@@ -136,13 +210,45 @@ var _ = Describe("Outputs", func() {
 							},
 						},
 					}
-					inputs := outs.GenerateInputs(resource)
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
 					Expect(inputs.Configs).To(HaveLen(1))
 					Expect(inputs.Configs["config-ref"].Name).To(Equal("config-ref"))
 					Expect(inputs.Configs["config-ref"].Config).To(Equal("config12345"))
 				})
 			})
 
+			Context("And the config reference has a transform", func() {
+				It("Adds the transformed config to inputs", func() {
+					resource := &v1alpha1.SupplyChainResource{
+						Configs: []v1alpha1.ResourceReference{
+							{
+								Name:      "config-ref",
+								Resource:  "config-output",
+								Transform: `{"data": value}`,
+							},
+						},
+					}
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(inputs.Configs["config-ref"].Config).To(Equal(map[string]interface{}{"data": "config12345"}))
+				})
+
+				It("Errors when the transform fails to evaluate", func() {
+					resource := &v1alpha1.SupplyChainResource{
+						Configs: []v1alpha1.ResourceReference{
+							{
+								Name:      "config-ref",
+								Resource:  "config-output",
+								Transform: `value.missing`,
+							},
+						},
+					}
+					_, err := outs.GenerateInputs(resource)
+					Expect(err).To(MatchError(ContainSubstring("transform config 'config-ref'")))
+				})
+			})
+
 			Context("And the configs do not have a match with the outputs", func() {
 				It("Does not add configs to inputs", func() {
 					resource := &v1alpha1.SupplyChainResource{
@@ -153,7 +259,8 @@ var _ = Describe("Outputs", func() {
 							},
 						},
 					}
-					inputs := outs.GenerateInputs(resource)
+					inputs, err := outs.GenerateInputs(resource)
+					Expect(err).NotTo(HaveOccurred())
 					Expect(inputs.Configs).To(BeEmpty())
 				})
 			})
@@ -18,13 +18,14 @@ package workload
 
 import (
 	"context"
+	"errors"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
 )
 
 //counterfeiter:generate . Realizer
 type Realizer interface {
-	Realize(ctx context.Context, resourceRealizer ResourceRealizer, supplyChain *v1alpha1.ClusterSupplyChain) error
+	Realize(ctx context.Context, resourceRealizer ResourceRealizer, supplyChain v1alpha1.SupplyChainObject) error
 }
 
 type realizer struct{}
@@ -33,17 +34,111 @@ func NewRealizer() Realizer {
 	return &realizer{}
 }
 
-func (r *realizer) Realize(ctx context.Context, resourceRealizer ResourceRealizer, supplyChain *v1alpha1.ClusterSupplyChain) error {
+func (r *realizer) Realize(ctx context.Context, resourceRealizer ResourceRealizer, supplyChain v1alpha1.SupplyChainObject) error {
 	outs := NewOutputs()
+	lastOutputs := NewOutputs()
+	kept := lastOutputsKept(supplyChain.GetSpec().Resources)
+	skipped := map[string]bool{}
+	var waitErr error
 
-	for i := range supplyChain.Spec.Resources {
-		resource := supplyChain.Spec.Resources[i]
-		out, err := resourceRealizer.Do(ctx, &resource, supplyChain.Name, outs)
+	resources := supplyChain.GetSpec().Resources
+	for i := range resources {
+		resource := resources[i]
+		resource.Scheduling = supplyChain.GetSpec().Scheduling.Merge(resource.Scheduling)
+		resource.Propagation = supplyChain.GetSpec().Propagation.Merge(resource.Propagation)
+		resource.Sources = v1alpha1.ResolveTransforms(resource.Sources, supplyChain.GetSpec().Transforms)
+		resource.Images = v1alpha1.ResolveTransforms(resource.Images, supplyChain.GetSpec().Transforms)
+		resource.Configs = v1alpha1.ResolveTransforms(resource.Configs, supplyChain.GetSpec().Transforms)
+
+		if blocked(outs, skipped, resource.Sources, resource.Images, resource.Configs) {
+			skipped[resource.Name] = true
+			if err := keepLastOutput(resourceRealizer, lastOutputs, kept, resource.Name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		out, err := resourceRealizer.Do(ctx, &resource, supplyChain.GetName(), outs)
 		if err != nil {
-			return err
+			resourceRealizer.RecordRetry(resource.Name)
+			if !kept[resource.Name] || !isWaiting(err) {
+				return err
+			}
+			last, lastErr := resourceRealizer.LastOutput(resource.Name)
+			if lastErr != nil {
+				return lastErr
+			}
+			if last == nil {
+				return err
+			}
+			if waitErr == nil {
+				waitErr = err
+			}
+			out = last
 		}
 		outs.AddOutput(resource.Name, out)
+		if kept[resource.Name] {
+			lastOutputs.AddOutput(resource.Name, out)
+		}
+	}
+
+	if err := resourceRealizer.RecordLastOutputs(lastOutputs); err != nil {
+		return err
+	}
+	return waitErr
+}
+
+// lastOutputsKept returns the resources consumed with whileWaiting:
+// useLastOutputs, whose last outputs are recorded.
+func lastOutputsKept(resources []v1alpha1.SupplyChainResource) map[string]bool {
+	kept := map[string]bool{}
+	for _, resource := range resources {
+		for _, refs := range [][]v1alpha1.ResourceReference{resource.Sources, resource.Images, resource.Configs} {
+			for _, ref := range refs {
+				if ref.UsesLastOutputs() {
+					kept[ref.Resource] = true
+				}
+			}
+		}
 	}
+	return kept
+}
+
+// blocked reports whether a resource consuming refs has to wait: a resource
+// it consumes was skipped, or only has stale outputs and is not consumed
+// with whileWaiting: useLastOutputs.
+func blocked(outs Outputs, skipped map[string]bool, refs ...[]v1alpha1.ResourceReference) bool {
+	for _, refs := range refs {
+		for _, ref := range refs {
+			if skipped[ref.Resource] || (outs.isStale(ref.Resource) && !ref.UsesLastOutputs()) {
+				return true
+			}
+		}
+	}
+	return false
+}
 
+// keepLastOutput carries the last output of a skipped resource over to the
+// outputs recorded at the end of the realization.
+func keepLastOutput(resourceRealizer ResourceRealizer, lastOutputs Outputs, kept map[string]bool, resourceName string) error {
+	if !kept[resourceName] {
+		return nil
+	}
+	last, err := resourceRealizer.LastOutput(resourceName)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		lastOutputs.AddOutput(resourceName, last)
+	}
 	return nil
 }
+
+// isWaiting reports whether err means the resource's object, job or plugin
+// has not produced outputs yet.
+func isWaiting(err error) bool {
+	var retrieveOutputErr RetrieveOutputError
+	var jobRunningErr JobRunningError
+	var pluginRunningErr PluginRunningError
+	return errors.As(err, &retrieveOutputErr) || errors.As(err, &jobRunningErr) || errors.As(err, &pluginRunningErr)
+}
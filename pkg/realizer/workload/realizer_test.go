@@ -81,8 +81,177 @@ var _ = Describe("Realize", func() {
 		Expect(executedResourceOrder).To(Equal([]string{"resource1", "resource2"}))
 	})
 
+	It("passes each resource the supplyChain's scheduling hints merged with its own", func() {
+		supplyChain.Spec.Scheduling = &v1alpha1.SchedulingHints{
+			PriorityClassName: "chain-priority",
+			Annotations:       map[string]string{"cost-center": "platform"},
+		}
+		supplyChain.Spec.Resources[1].Scheduling = &v1alpha1.SchedulingHints{
+			PriorityClassName: "resource-priority",
+		}
+
+		hints := map[string]*v1alpha1.SchedulingHints{}
+		resourceRealizer.DoCalls(func(ctx context.Context, resource *v1alpha1.SupplyChainResource, supplyChainName string, outputs realizer.Outputs) (*templates.Output, error) {
+			hints[resource.Name] = resource.Scheduling
+			return &templates.Output{}, nil
+		})
+
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(Succeed())
+
+		Expect(hints["resource1"]).To(Equal(supplyChain.Spec.Scheduling))
+		Expect(hints["resource2"]).To(Equal(&v1alpha1.SchedulingHints{
+			PriorityClassName: "resource-priority",
+			Annotations:       map[string]string{"cost-center": "platform"},
+		}))
+		Expect(supplyChain.Spec.Resources[1].Scheduling.Annotations).To(BeNil())
+	})
+
+	It("passes each resource the supplyChain's propagation merged with its own", func() {
+		supplyChain.Spec.Propagation = &v1alpha1.MetadataPropagation{
+			Labels: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+		}
+		supplyChain.Spec.Resources[1].Propagation = &v1alpha1.MetadataPropagation{
+			Annotations: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+		}
+
+		propagations := map[string]*v1alpha1.MetadataPropagation{}
+		resourceRealizer.DoCalls(func(ctx context.Context, resource *v1alpha1.SupplyChainResource, supplyChainName string, outputs realizer.Outputs) (*templates.Output, error) {
+			propagations[resource.Name] = resource.Propagation
+			return &templates.Output{}, nil
+		})
+
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(Succeed())
+
+		Expect(propagations["resource1"]).To(Equal(supplyChain.Spec.Propagation))
+		Expect(propagations["resource2"]).To(Equal(&v1alpha1.MetadataPropagation{
+			Labels:      &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+			Annotations: &v1alpha1.PropagationPolicy{Policy: v1alpha1.PropagateAll},
+		}))
+	})
+
+	It("passes each resource its references with the supply chain's named transforms resolved", func() {
+		supplyChain.Spec.Transforms = []v1alpha1.OutputTransform{
+			{Name: "wrap", Expression: `{"manifest": value}`},
+		}
+		supplyChain.Spec.Resources[1].Configs = []v1alpha1.ResourceReference{
+			{Name: "config", Resource: "resource1", Transform: "wrap"},
+		}
+
+		var configs []v1alpha1.ResourceReference
+		resourceRealizer.DoCalls(func(ctx context.Context, resource *v1alpha1.SupplyChainResource, supplyChainName string, outputs realizer.Outputs) (*templates.Output, error) {
+			if resource.Name == "resource2" {
+				configs = resource.Configs
+			}
+			return &templates.Output{}, nil
+		})
+
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(Succeed())
+
+		Expect(configs).To(Equal([]v1alpha1.ResourceReference{
+			{Name: "config", Resource: "resource1", Transform: `{"manifest": value}`},
+		}))
+		Expect(supplyChain.Spec.Resources[1].Configs[0].Transform).To(Equal("wrap"))
+	})
+
 	It("returns any error encountered realizing a resource", func() {
 		resourceRealizer.DoReturns(nil, errors.New("realizing is hard"))
 		Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(MatchError("realizing is hard"))
 	})
+
+	It("records a retry of the resource that failed", func() {
+		resourceRealizer.DoReturnsOnCall(1, nil, errors.New("realizing is hard"))
+
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(MatchError("realizing is hard"))
+		Expect(resourceRealizer.RecordRetryCallCount()).To(Equal(1))
+		Expect(resourceRealizer.RecordRetryArgsForCall(0)).To(Equal("resource2"))
+	})
+
+	It("records no retries when every resource is realized", func() {
+		Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(Succeed())
+		Expect(resourceRealizer.RecordRetryCallCount()).To(Equal(0))
+	})
+	Context("when a resource's object has not produced outputs yet", func() {
+		var (
+			waitErr    error
+			lastOutput *templates.Output
+			realized   []string
+			inputs     map[string]realizer.Outputs
+		)
+
+		BeforeEach(func() {
+			supplyChain.Spec.Resources = append(supplyChain.Spec.Resources, v1alpha1.SupplyChainResource{
+				Name:    "resource3",
+				Configs: []v1alpha1.ResourceReference{{Name: "config", Resource: "resource1"}},
+			})
+			supplyChain.Spec.Resources[1].Configs = []v1alpha1.ResourceReference{
+				{Name: "config", Resource: "resource1", WhileWaiting: v1alpha1.UseLastOutputsWhileWaiting},
+			}
+
+			waitErr = realizer.NewRetrieveOutputError(&supplyChain.Spec.Resources[0], errors.New("no value at path"))
+			lastOutput = &templates.Output{Config: "last", Stale: true}
+			realized = nil
+			inputs = map[string]realizer.Outputs{}
+
+			resourceRealizer.DoCalls(func(ctx context.Context, resource *v1alpha1.SupplyChainResource, supplyChainName string, outputs realizer.Outputs) (*templates.Output, error) {
+				realized = append(realized, resource.Name)
+				inputs[resource.Name] = outputs
+				if resource.Name == "resource1" {
+					return nil, waitErr
+				}
+				return &templates.Output{}, nil
+			})
+			resourceRealizer.LastOutputReturns(lastOutput, nil)
+		})
+
+		It("stamps consumers using its last outputs with them, skips the others and returns the wait", func() {
+			Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(MatchError(waitErr))
+
+			Expect(realized).To(Equal([]string{"resource1", "resource2"}))
+			Expect(inputs["resource2"]).To(HaveKeyWithValue("resource1", lastOutput))
+
+			Expect(resourceRealizer.LastOutputCallCount()).To(Equal(1))
+			Expect(resourceRealizer.LastOutputArgsForCall(0)).To(Equal("resource1"))
+		})
+
+		It("records a retry of the resource that waits", func() {
+			Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(MatchError(waitErr))
+
+			Expect(resourceRealizer.RecordRetryCallCount()).To(Equal(1))
+			Expect(resourceRealizer.RecordRetryArgsForCall(0)).To(Equal("resource1"))
+		})
+
+		It("records the last outputs it passed on", func() {
+			Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(MatchError(waitErr))
+
+			Expect(resourceRealizer.RecordLastOutputsCallCount()).To(Equal(1))
+			Expect(resourceRealizer.RecordLastOutputsArgsForCall(0)).To(Equal(realizer.Outputs{"resource1": lastOutput}))
+		})
+
+		It("returns the wait when no outputs were recorded yet", func() {
+			resourceRealizer.LastOutputReturns(nil, nil)
+
+			Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(MatchError(waitErr))
+			Expect(realized).To(Equal([]string{"resource1"}))
+		})
+
+		It("records fresh outputs once the object produces them", func() {
+			fresh := &templates.Output{Config: "fresh"}
+			resourceRealizer.DoReturns(fresh, nil)
+			resourceRealizer.DoCalls(nil)
+
+			Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(Succeed())
+
+			Expect(resourceRealizer.LastOutputCallCount()).To(Equal(0))
+			Expect(resourceRealizer.RecordLastOutputsArgsForCall(0)).To(Equal(realizer.Outputs{"resource1": fresh}))
+		})
+
+		It("does not use last outputs of resources no consumer uses them for", func() {
+			supplyChain.Spec.Resources[1].Configs[0].WhileWaiting = v1alpha1.BlockWhileWaiting
+
+			Expect(rlzr.Realize(context.TODO(), resourceRealizer, supplyChain)).To(MatchError(waitErr))
+
+			Expect(realized).To(Equal([]string{"resource1"}))
+			Expect(resourceRealizer.LastOutputCallCount()).To(Equal(0))
+		})
+	})
 })
@@ -0,0 +1,189 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redact hides the values of sensitive params and outputs from the
+// status, Events, conditions and logs of workloads and deliverables, while
+// templates are still stamped with them.
+package redact
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+)
+
+// Placeholder is what sensitive values are replaced with.
+const Placeholder = "[redacted]"
+
+// Redactor replaces the sensitive values it was given wherever they appear.
+// A nil Redactor redacts nothing.
+type Redactor struct {
+	// values are the forms of the sensitive values, longest first so that a
+	// value is replaced before any value it contains.
+	values []string
+}
+
+func New() *Redactor {
+	return &Redactor{}
+}
+
+// Add marks value as sensitive: value itself when it is a string, and each
+// string in it when it is a map or a list. Each string is also recognised
+// JSON escaped and base64 encoded, the forms logs and Secrets carry it in.
+func (r *Redactor) Add(value interface{}) {
+	if r == nil {
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		r.add(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			r.Add(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			r.Add(item)
+		}
+	case apiextensionsv1.JSON:
+		var decoded interface{}
+		if err := json.Unmarshal(v.Raw, &decoded); err == nil {
+			r.Add(decoded)
+		}
+	}
+}
+
+func (r *Redactor) add(value string) {
+	if value == "" {
+		return
+	}
+
+	forms := []string{value, base64.StdEncoding.EncodeToString([]byte(value))}
+	if encoded, err := json.Marshal(value); err == nil {
+		forms = append(forms, strings.Trim(string(encoded), `"`))
+	}
+
+	for _, form := range forms {
+		known := false
+		for _, v := range r.values {
+			known = known || v == form
+		}
+		if !known {
+			r.values = append(r.values, form)
+		}
+	}
+	sort.SliceStable(r.values, func(i, j int) bool {
+		return len(r.values[i]) > len(r.values[j])
+	})
+}
+
+// String returns s with each sensitive value in it replaced.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, value := range r.values {
+		s = strings.ReplaceAll(s, value, Placeholder)
+	}
+	return s
+}
+
+// Value returns a copy of value with each sensitive value in its strings
+// replaced.
+func (r *Redactor) Value(value interface{}) interface{} {
+	if r == nil || len(r.values) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.String(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			redacted[key] = r.Value(item)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.Value(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// Strings returns a copy of values with each sensitive value in them
+// replaced.
+func (r *Redactor) Strings(values []string) []string {
+	if r == nil || len(r.values) == 0 {
+		return values
+	}
+
+	redacted := make([]string, len(values))
+	for i, value := range values {
+		redacted[i] = r.String(value)
+	}
+	return redacted
+}
+
+// ConditionManager returns manager with the message of each condition added
+// to it redacted.
+func (r *Redactor) ConditionManager(manager conditions.ConditionManager) conditions.ConditionManager {
+	return &conditionManager{ConditionManager: manager, redactor: r}
+}
+
+type conditionManager struct {
+	conditions.ConditionManager
+	redactor *Redactor
+}
+
+func (c *conditionManager) Add(condition metav1.Condition, polarity conditions.Polarity) {
+	condition.Message = c.redactor.String(condition.Message)
+	c.ConditionManager.Add(condition, polarity)
+}
+
+func (c *conditionManager) AddPositive(condition metav1.Condition) {
+	condition.Message = c.redactor.String(condition.Message)
+	c.ConditionManager.AddPositive(condition)
+}
+
+func (c *conditionManager) AddNegative(condition metav1.Condition) {
+	condition.Message = c.redactor.String(condition.Message)
+	c.ConditionManager.AddNegative(condition)
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying r, so that values marked sensitive while
+// realizing are redacted by the reconcile that started it.
+func NewContext(ctx context.Context, r *Redactor) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the Redactor ctx carries, or nil.
+func FromContext(ctx context.Context) *Redactor {
+	r, _ := ctx.Value(contextKey{}).(*Redactor)
+	return r
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registrar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// CacheSelectors restrict the manager's cache of a kind to the objects
+// matching a label selector, so that the memory of the controller does not
+// grow with every object of the kinds it stamps or watches. Objects of a
+// restricted kind that do not match are invisible to the controllers. It
+// is a flag.Value that can be set repeatedly, each time as
+// Kind.version.group=selector, such as
+// Deployment.v1.apps=carto.run/workload-name.
+type CacheSelectors map[schema.GroupVersionKind]labels.Selector
+
+// NewCache returns a constructor of caches that list and watch each kind
+// of s with its selector.
+func (s CacheSelectors) NewCache() cache.NewCacheFunc {
+	selectors := cache.SelectorsByObject{}
+	for gvk, selector := range s {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		// the type of the selectors is internal to controller-runtime, so
+		// they can only be built in a composite literal
+		for k, v := range (cache.SelectorsByObject{obj: {Label: selector}}) {
+			selectors[k] = v
+		}
+	}
+	return cache.BuilderWithOptions(cache.Options{SelectorsByObject: selectors})
+}
+
+func (s *CacheSelectors) String() string {
+	if s == nil {
+		return ""
+	}
+	var settings []string
+	for gvk, selector := range *s {
+		settings = append(settings, formatKind(gvk)+"="+selector.String())
+	}
+	sort.Strings(settings)
+	return strings.Join(settings, " ")
+}
+
+// Set parses a Kind.version.group=selector setting into s, replacing the
+// selector of the kind if it was already set.
+func (s *CacheSelectors) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("'%s' is not a Kind.version.group=selector setting", value)
+	}
+
+	gvk, err := parseKind(parts[0])
+	if err != nil {
+		return err
+	}
+
+	selector, err := labels.Parse(parts[1])
+	if err != nil {
+		return fmt.Errorf("selector of %s: %w", parts[0], err)
+	}
+	if selector.Empty() {
+		return fmt.Errorf("selector of %s must not be empty", parts[0])
+	}
+
+	if *s == nil {
+		*s = CacheSelectors{}
+	}
+	(*s)[gvk] = selector
+	return nil
+}
+
+// parseKind parses Kind.version.group, or Kind.version for the core group.
+func parseKind(s string) (schema.GroupVersionKind, error) {
+	parts := strings.SplitN(s, ".", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("'%s' is not a Kind.version.group", s)
+	}
+	gvk := schema.GroupVersionKind{Kind: parts[0], Version: parts[1]}
+	if len(parts) == 3 {
+		gvk.Group = parts[2]
+	}
+	return gvk, nil
+}
+
+func formatKind(gvk schema.GroupVersionKind) string {
+	if gvk.Group == "" {
+		return gvk.Kind + "." + gvk.Version
+	}
+	return gvk.Kind + "." + gvk.Version + "." + gvk.Group
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registrar_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/vmware-tanzu/cartographer/pkg/registrar"
+)
+
+var _ = Describe("CacheSelectors", func() {
+	var selectors registrar.CacheSelectors
+
+	BeforeEach(func() {
+		selectors = nil
+	})
+
+	Describe("Set", func() {
+		It("parses the kind and selector", func() {
+			Expect(selectors.Set("Deployment.v1.apps=carto.run/workload-name")).To(Succeed())
+
+			selector := selectors[schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}]
+			Expect(selector).NotTo(BeNil())
+			Expect(selector.Matches(labels.Set{"carto.run/workload-name": "my-workload"})).To(BeTrue())
+			Expect(selector.Matches(labels.Set{"app": "my-app"})).To(BeFalse())
+		})
+
+		It("parses kinds of the core group", func() {
+			Expect(selectors.Set("ConfigMap.v1=carto.run/resource-name")).To(Succeed())
+			Expect(selectors).To(HaveKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}))
+		})
+
+		It("accumulates settings of several kinds", func() {
+			Expect(selectors.Set("Service.v1=carto.run/resource-name")).To(Succeed())
+			Expect(selectors.Set("Deployment.v1.apps=carto.run/resource-name,tier!=test")).To(Succeed())
+			Expect(selectors.String()).To(Equal("Deployment.v1.apps=carto.run/resource-name,tier!=test Service.v1=carto.run/resource-name"))
+		})
+
+		It("rejects settings without a selector", func() {
+			Expect(selectors.Set("Deployment.v1.apps")).To(MatchError("'Deployment.v1.apps' is not a Kind.version.group=selector setting"))
+		})
+
+		It("rejects kinds without a version", func() {
+			Expect(selectors.Set("Deployment=app")).To(MatchError("'Deployment' is not a Kind.version.group"))
+		})
+
+		It("rejects malformed selectors", func() {
+			Expect(selectors.Set("Deployment.v1.apps=a in (")).To(MatchError(ContainSubstring("selector of Deployment.v1.apps")))
+		})
+
+		It("rejects empty selectors", func() {
+			Expect(selectors.Set("Deployment.v1.apps=")).To(MatchError("selector of Deployment.v1.apps must not be empty"))
+		})
+	})
+})
@@ -18,6 +18,8 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -36,21 +38,33 @@ type Mapper struct {
 }
 
 func (mapper *Mapper) ClusterSupplyChainToWorkloadRequests(object client.Object) []reconcile.Request {
-	var err error
-
 	supplyChain, ok := object.(*v1alpha1.ClusterSupplyChain)
 	if !ok {
 		mapper.Logger.Error(nil, "cluster supply chain to workload requests: cast to ClusterSupplyChain failed")
 		return nil
 	}
 
+	return mapper.workloadRequests(supplyChain, "cluster supply chain to workload requests")
+}
+
+func (mapper *Mapper) SupplyChainToWorkloadRequests(object client.Object) []reconcile.Request {
+	supplyChain, ok := object.(*v1alpha1.SupplyChain)
+	if !ok {
+		mapper.Logger.Error(nil, "supply chain to workload requests: cast to SupplyChain failed")
+		return nil
+	}
+
+	return mapper.workloadRequests(supplyChain, "supply chain to workload requests")
+}
+
+func (mapper *Mapper) workloadRequests(supplyChain v1alpha1.SupplyChainObject, operation string) []reconcile.Request {
 	list := &v1alpha1.WorkloadList{}
 
-	err = mapper.Client.List(context.TODO(), list,
-		client.InNamespace(supplyChain.Namespace),
-		client.MatchingLabels(supplyChain.Spec.Selector))
+	err := mapper.Client.List(context.TODO(), list,
+		client.InNamespace(supplyChain.GetNamespace()),
+		client.MatchingLabels(supplyChain.GetSpec().Selector))
 	if err != nil {
-		mapper.Logger.Error(fmt.Errorf("client list: %w", err), "cluster supply chain to workload requests: client list")
+		mapper.Logger.Error(fmt.Errorf("client list: %w", err), operation+": client list")
 		return nil
 	}
 
@@ -65,25 +79,36 @@ func (mapper *Mapper) ClusterSupplyChainToWorkloadRequests(object client.Object)
 	}
 
 	return requests
-
 }
 
 func (mapper *Mapper) ClusterDeliveryToDeliverableRequests(object client.Object) []reconcile.Request {
-	var err error
-
 	delivery, ok := object.(*v1alpha1.ClusterDelivery)
 	if !ok {
 		mapper.Logger.Error(nil, "cluster delivery to deliverable requests: cast to ClusterDelivery failed")
 		return nil
 	}
 
+	return mapper.deliverableRequests(delivery, "cluster delivery to deliverable requests")
+}
+
+func (mapper *Mapper) DeliveryToDeliverableRequests(object client.Object) []reconcile.Request {
+	delivery, ok := object.(*v1alpha1.Delivery)
+	if !ok {
+		mapper.Logger.Error(nil, "delivery to deliverable requests: cast to Delivery failed")
+		return nil
+	}
+
+	return mapper.deliverableRequests(delivery, "delivery to deliverable requests")
+}
+
+func (mapper *Mapper) deliverableRequests(delivery v1alpha1.DeliveryObject, operation string) []reconcile.Request {
 	list := &v1alpha1.DeliverableList{}
 
-	err = mapper.Client.List(context.TODO(), list,
-		client.InNamespace(delivery.Namespace),
-		client.MatchingLabels(delivery.Spec.Selector))
+	err := mapper.Client.List(context.TODO(), list,
+		client.InNamespace(delivery.GetNamespace()),
+		client.MatchingLabels(delivery.GetSpec().Selector))
 	if err != nil {
-		mapper.Logger.Error(fmt.Errorf("client list: %w", err), "cluster delivery to deliverable requests: client list")
+		mapper.Logger.Error(fmt.Errorf("client list: %w", err), operation+": client list")
 		return nil
 	}
 
@@ -100,6 +125,61 @@ func (mapper *Mapper) ClusterDeliveryToDeliverableRequests(object client.Object)
 	return requests
 }
 
+// CustomResourceDefinitionToWorkloadRequests maps a CRD, as it is installed
+// or becomes established, to the workloads whose resources could not be
+// submitted as the kind of an object they stamp was not registered.
+func (mapper *Mapper) CustomResourceDefinitionToWorkloadRequests(_ client.Object) []reconcile.Request {
+	list := &v1alpha1.WorkloadList{}
+	if err := mapper.Client.List(context.TODO(), list); err != nil {
+		mapper.Logger.Error(fmt.Errorf("client list: %w", err), "custom resource definition to workload requests: client list")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, workload := range list.Items {
+		if kindNotRegistered(workload.Status.Conditions, v1alpha1.WorkloadResourceSubmitted) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      workload.Name,
+					Namespace: workload.Namespace,
+				},
+			})
+		}
+	}
+
+	return requests
+}
+
+// CustomResourceDefinitionToDeliverableRequests maps a CRD to the
+// deliverables waiting on a kind to be registered, as
+// CustomResourceDefinitionToWorkloadRequests.
+func (mapper *Mapper) CustomResourceDefinitionToDeliverableRequests(_ client.Object) []reconcile.Request {
+	list := &v1alpha1.DeliverableList{}
+	if err := mapper.Client.List(context.TODO(), list); err != nil {
+		mapper.Logger.Error(fmt.Errorf("client list: %w", err), "custom resource definition to deliverable requests: client list")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, deliverable := range list.Items {
+		if kindNotRegistered(deliverable.Status.Conditions, v1alpha1.DeliverableResourcesSubmitted) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      deliverable.Name,
+					Namespace: deliverable.Namespace,
+				},
+			})
+		}
+	}
+
+	return requests
+}
+
+func kindNotRegistered(conditions []metav1.Condition, submitted string) bool {
+	condition := meta.FindStatusCondition(conditions, submitted)
+	return condition != nil && condition.Reason == v1alpha1.KindNotRegisteredResourcesSubmittedReason
+}
+
 func (mapper *Mapper) RunTemplateToPipelineRequests(object client.Object) []reconcile.Request {
 	var err error
 
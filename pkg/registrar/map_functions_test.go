@@ -17,6 +17,7 @@ package registrar_test
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -167,6 +168,78 @@ var _ = Describe("MapFunctions", func() {
 		})
 	})
 
+	Describe("SupplyChainToWorkloadRequests", func() {
+		var (
+			mapper      *registrar.Mapper
+			fakeLogger  *registrarfakes.FakeLogger
+			supplyChain client.Object
+			result      []reconcile.Request
+		)
+
+		BeforeEach(func() {
+			fakeLogger = &registrarfakes.FakeLogger{}
+
+			supplyChain = &v1alpha1.SupplyChain{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "some-supply-chain",
+					Namespace: "first-namespace",
+				},
+				Spec: v1alpha1.SupplyChainSpec{
+					Selector: map[string]string{
+						"myLabel": "myLabelsValue",
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			labels := map[string]string{"myLabel": "myLabelsValue"}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(
+					&v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Name: "first-workload", Namespace: "first-namespace", Labels: labels}},
+					&v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Name: "second-workload", Namespace: "second-namespace", Labels: labels}},
+				).
+				Build()
+
+			mapper = &registrar.Mapper{
+				Client: fakeClient,
+				Logger: fakeLogger,
+			}
+
+			result = mapper.SupplyChainToWorkloadRequests(supplyChain)
+		})
+
+		It("returns requests for the matching workloads in its own namespace only", func() {
+			Expect(result).To(Equal([]reconcile.Request{
+				{
+					types.NamespacedName{
+						Namespace: "first-namespace",
+						Name:      "first-workload",
+					},
+				},
+			}))
+		})
+
+		Context("when function is passed an object that is not a SupplyChain", func() {
+			BeforeEach(func() {
+				supplyChain = &v1alpha1.ClusterSupplyChain{}
+			})
+
+			It("logs a helpful error", func() {
+				Expect(result).To(BeEmpty())
+
+				Expect(fakeLogger.ErrorCallCount()).To(Equal(1))
+				firstArg, secondArg, _ := fakeLogger.ErrorArgsForCall(0)
+				Expect(firstArg).To(BeNil())
+				Expect(secondArg).To(Equal("supply chain to workload requests: cast to SupplyChain failed"))
+			})
+		})
+	})
+
 	Describe("ClusterDeliveryToDeliverableRequests", func() {
 		var (
 			clientObjects     []client.Object
@@ -304,6 +377,78 @@ var _ = Describe("MapFunctions", func() {
 		})
 	})
 
+	Describe("DeliveryToDeliverableRequests", func() {
+		var (
+			mapper     *registrar.Mapper
+			fakeLogger *registrarfakes.FakeLogger
+			delivery   client.Object
+			result     []reconcile.Request
+		)
+
+		BeforeEach(func() {
+			fakeLogger = &registrarfakes.FakeLogger{}
+
+			delivery = &v1alpha1.Delivery{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "some-delivery",
+					Namespace: "first-namespace",
+				},
+				Spec: v1alpha1.ClusterDeliverySpec{
+					Selector: map[string]string{
+						"myLabel": "myLabelsValue",
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			labels := map[string]string{"myLabel": "myLabelsValue"}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(
+					&v1alpha1.Deliverable{ObjectMeta: metav1.ObjectMeta{Name: "first-deliverable", Namespace: "first-namespace", Labels: labels}},
+					&v1alpha1.Deliverable{ObjectMeta: metav1.ObjectMeta{Name: "second-deliverable", Namespace: "second-namespace", Labels: labels}},
+				).
+				Build()
+
+			mapper = &registrar.Mapper{
+				Client: fakeClient,
+				Logger: fakeLogger,
+			}
+
+			result = mapper.DeliveryToDeliverableRequests(delivery)
+		})
+
+		It("returns requests for the matching deliverables in its own namespace only", func() {
+			Expect(result).To(Equal([]reconcile.Request{
+				{
+					types.NamespacedName{
+						Namespace: "first-namespace",
+						Name:      "first-deliverable",
+					},
+				},
+			}))
+		})
+
+		Context("when function is passed an object that is not a Delivery", func() {
+			BeforeEach(func() {
+				delivery = &v1alpha1.ClusterDelivery{}
+			})
+
+			It("logs a helpful error", func() {
+				Expect(result).To(BeEmpty())
+
+				Expect(fakeLogger.ErrorCallCount()).To(Equal(1))
+				firstArg, secondArg, _ := fakeLogger.ErrorArgsForCall(0)
+				Expect(firstArg).To(BeNil())
+				Expect(secondArg).To(Equal("delivery to deliverable requests: cast to Delivery failed"))
+			})
+		})
+	})
+
 	Describe("RunTemplateToPipelineRequests", func() {
 		var (
 			clientObjects     []client.Object
@@ -469,4 +614,83 @@ var _ = Describe("MapFunctions", func() {
 			})
 		})
 	})
+
+	Describe("CustomResourceDefinitionToWorkloadRequests", func() {
+		var (
+			scheme     *runtime.Scheme
+			fakeLogger *registrarfakes.FakeLogger
+			objects    []client.Object
+		)
+
+		crd := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "images.kpack.io"}}
+
+		submitted := func(reason string) []metav1.Condition {
+			return []metav1.Condition{{Type: v1alpha1.WorkloadResourceSubmitted, Status: metav1.ConditionFalse, Reason: reason}}
+		}
+
+		mapperOf := func() *registrar.Mapper {
+			return &registrar.Mapper{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+				Logger: fakeLogger,
+			}
+		}
+
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			fakeLogger = &registrarfakes.FakeLogger{}
+			objects = nil
+		})
+
+		It("logs an error when the client cannot list", func() {
+			Expect(mapperOf().CustomResourceDefinitionToWorkloadRequests(crd)).To(BeEmpty())
+
+			Expect(fakeLogger.ErrorCallCount()).To(Equal(1))
+			_, message, _ := fakeLogger.ErrorArgsForCall(0)
+			Expect(message).To(Equal("custom resource definition to workload requests: client list"))
+		})
+
+		It("returns the workloads waiting on a kind to be registered", func() {
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+			objects = []client.Object{
+				&v1alpha1.Workload{
+					ObjectMeta: metav1.ObjectMeta{Name: "waiting", Namespace: "dev"},
+					Status:     v1alpha1.WorkloadStatus{Conditions: submitted(v1alpha1.KindNotRegisteredResourcesSubmittedReason)},
+				},
+				&v1alpha1.Workload{
+					ObjectMeta: metav1.ObjectMeta{Name: "rejected", Namespace: "dev"},
+					Status:     v1alpha1.WorkloadStatus{Conditions: submitted(v1alpha1.TemplateRejectedByAPIServerResourcesSubmittedReason)},
+				},
+				&v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "dev"}},
+			}
+
+			Expect(mapperOf().CustomResourceDefinitionToWorkloadRequests(crd)).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "dev", Name: "waiting"}},
+			}))
+		})
+	})
+
+	Describe("CustomResourceDefinitionToDeliverableRequests", func() {
+		It("returns the deliverables waiting on a kind to be registered", func() {
+			scheme := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+			mapper := &registrar.Mapper{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+					&v1alpha1.Deliverable{
+						ObjectMeta: metav1.ObjectMeta{Name: "waiting", Namespace: "prod"},
+						Status: v1alpha1.DeliverableStatus{Conditions: []metav1.Condition{{
+							Type:   v1alpha1.DeliverableResourcesSubmitted,
+							Status: metav1.ConditionFalse,
+							Reason: v1alpha1.KindNotRegisteredResourcesSubmittedReason,
+						}}},
+					},
+					&v1alpha1.Deliverable{ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "prod"}},
+				).Build(),
+				Logger: &registrarfakes.FakeLogger{},
+			}
+
+			Expect(mapper.CustomResourceDefinitionToDeliverableRequests(&apiextensionsv1.CustomResourceDefinition{})).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "prod", Name: "waiting"}},
+			}))
+		})
+	})
 })
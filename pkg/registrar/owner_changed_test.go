@@ -0,0 +1,82 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registrar_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/registrar"
+)
+
+var _ = Describe("OwnerChanged", func() {
+	var old *v1alpha1.Workload
+
+	changed := func(updated *v1alpha1.Workload) bool {
+		return registrar.OwnerChanged().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})
+	}
+
+	BeforeEach(func() {
+		old = &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "app",
+				Namespace:       "dev",
+				Generation:      1,
+				ResourceVersion: "1",
+				Labels:          map[string]string{"apps.tanzu.vmware.com/workload-type": "web"},
+			},
+		}
+	})
+
+	It("does not enqueue the workload for the status written by a failing reconcile", func() {
+		updated := old.DeepCopy()
+		updated.ResourceVersion = "2"
+		updated.Status.ObservedGeneration = 1
+		updated.Status.Retries = []v1alpha1.ResourceRetries{{Resource: "image-builder", Retries: 1}}
+		Expect(changed(updated)).To(BeFalse())
+	})
+
+	It("enqueues the workload when its spec changes", func() {
+		updated := old.DeepCopy()
+		updated.Generation = 2
+		Expect(changed(updated)).To(BeTrue())
+	})
+
+	It("enqueues the workload when its labels change", func() {
+		updated := old.DeepCopy()
+		updated.Labels["apps.tanzu.vmware.com/workload-type"] = "worker"
+		Expect(changed(updated)).To(BeTrue())
+	})
+
+	It("enqueues the workload when its annotations change", func() {
+		updated := old.DeepCopy()
+		updated.Annotations = map[string]string{"example.com/note": "retry"}
+		Expect(changed(updated)).To(BeTrue())
+	})
+
+	It("enqueues the workload when it is deleted", func() {
+		updated := old.DeepCopy()
+		now := metav1.Now()
+		updated.DeletionTimestamp = &now
+		Expect(changed(updated)).To(BeTrue())
+	})
+
+	It("enqueues workloads as they are created", func() {
+		Expect(registrar.OwnerChanged().Create(event.CreateEvent{Object: old})).To(BeTrue())
+	})
+})
@@ -0,0 +1,153 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registrar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+)
+
+// The defaults of a RateLimit, those of client-go's default controller
+// rate limiter.
+const (
+	DefaultBaseDelay = 5 * time.Millisecond
+	DefaultMaxDelay  = 1000 * time.Second
+	DefaultQPS       = 10
+	DefaultBurst     = 100
+)
+
+// RateLimit tunes the rate limiter of a controller's work queue. Fields
+// left zero take their defaults. It is a flag.Value, written as
+// base-delay=5ms,max-delay=5m,qps=20,burst=200.
+type RateLimit struct {
+	// BaseDelay is how long an object that failed waits before it is
+	// reconciled again. The delay doubles with each failure in a row, up to
+	// MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// QPS is how many objects per second the queue hands out overall, with
+	// bursts of up to Burst.
+	QPS   float64
+	Burst int
+}
+
+// RateLimits are the rate limits of each group of controllers.
+type RateLimits struct {
+	Workload    RateLimit
+	Deliverable RateLimit
+	// Pipeline is that of the controller of pipelines, which run templates.
+	Pipeline RateLimit
+	// Blueprint is that of the controllers of supply chains, deliveries,
+	// blueprints and blueprint sources.
+	Blueprint RateLimit
+}
+
+// RateLimiter returns the rate limiter l describes.
+func (l RateLimit) RateLimiter() ratelimiter.RateLimiter {
+	qps, burst := l.QPS, l.Burst
+	if qps == 0 {
+		qps = DefaultQPS
+	}
+	if burst == 0 {
+		burst = DefaultBurst
+	}
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(l.baseDelay(), l.maxDelay()),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
+
+func (l RateLimit) baseDelay() time.Duration {
+	if l.BaseDelay == 0 {
+		return DefaultBaseDelay
+	}
+	return l.BaseDelay
+}
+
+func (l RateLimit) maxDelay() time.Duration {
+	if l.MaxDelay == 0 {
+		return DefaultMaxDelay
+	}
+	return l.MaxDelay
+}
+
+func (l *RateLimit) String() string {
+	if l == nil {
+		return ""
+	}
+	var fields []string
+	if l.BaseDelay != 0 {
+		fields = append(fields, "base-delay="+l.BaseDelay.String())
+	}
+	if l.MaxDelay != 0 {
+		fields = append(fields, "max-delay="+l.MaxDelay.String())
+	}
+	if l.QPS != 0 {
+		fields = append(fields, "qps="+strconv.FormatFloat(l.QPS, 'g', -1, 64))
+	}
+	if l.Burst != 0 {
+		fields = append(fields, "burst="+strconv.Itoa(l.Burst))
+	}
+	return strings.Join(fields, ",")
+}
+
+// Set parses a comma-separated list of base-delay, max-delay, qps and
+// burst settings into l.
+func (l *RateLimit) Set(value string) error {
+	parsed := RateLimit{}
+	for _, field := range strings.Split(value, ",") {
+		if field == "" {
+			continue
+		}
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("'%s' is not a name=value setting", field)
+		}
+		name, setting := parts[0], parts[1]
+
+		var err error
+		switch name {
+		case "base-delay":
+			parsed.BaseDelay, err = time.ParseDuration(setting)
+		case "max-delay":
+			parsed.MaxDelay, err = time.ParseDuration(setting)
+		case "qps":
+			parsed.QPS, err = strconv.ParseFloat(setting, 64)
+		case "burst":
+			parsed.Burst, err = strconv.Atoi(setting)
+		default:
+			return fmt.Errorf("unknown setting '%s': must be one of base-delay, max-delay, qps and burst", name)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	if parsed.BaseDelay < 0 || parsed.MaxDelay < 0 || parsed.QPS < 0 || parsed.Burst < 0 {
+		return fmt.Errorf("settings must not be negative")
+	}
+	if baseDelay, maxDelay := parsed.baseDelay(), parsed.maxDelay(); baseDelay > maxDelay {
+		return fmt.Errorf("base-delay %s is longer than max-delay %s", baseDelay, maxDelay)
+	}
+
+	*l = parsed
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registrar_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/registrar"
+)
+
+var _ = Describe("RateLimit", func() {
+	var rateLimit registrar.RateLimit
+
+	BeforeEach(func() {
+		rateLimit = registrar.RateLimit{}
+	})
+
+	Describe("Set", func() {
+		It("parses every setting", func() {
+			Expect(rateLimit.Set("base-delay=1s,max-delay=5m,qps=2.5,burst=20")).To(Succeed())
+			Expect(rateLimit).To(Equal(registrar.RateLimit{
+				BaseDelay: time.Second,
+				MaxDelay:  5 * time.Minute,
+				QPS:       2.5,
+				Burst:     20,
+			}))
+		})
+
+		It("leaves settings that are not given zero", func() {
+			Expect(rateLimit.Set("qps=50")).To(Succeed())
+			Expect(rateLimit).To(Equal(registrar.RateLimit{QPS: 50}))
+		})
+
+		It("writes the settings back as they are set", func() {
+			Expect(rateLimit.Set("base-delay=1s,qps=50")).To(Succeed())
+			Expect(rateLimit.String()).To(Equal("base-delay=1s,qps=50"))
+		})
+
+		It("rejects unknown settings", func() {
+			Expect(rateLimit.Set("delay=1s")).To(MatchError(ContainSubstring("unknown setting 'delay'")))
+		})
+
+		It("rejects settings without a value", func() {
+			Expect(rateLimit.Set("qps")).To(MatchError("'qps' is not a name=value setting"))
+		})
+
+		It("rejects values that do not parse", func() {
+			Expect(rateLimit.Set("max-delay=soon")).To(MatchError(ContainSubstring("max-delay:")))
+		})
+
+		It("rejects negative values", func() {
+			Expect(rateLimit.Set("burst=-1")).To(MatchError("settings must not be negative"))
+		})
+
+		It("rejects a base delay longer than the max delay, including the default one", func() {
+			Expect(rateLimit.Set("base-delay=1m,max-delay=1s")).To(MatchError("base-delay 1m0s is longer than max-delay 1s"))
+			Expect(rateLimit.Set("base-delay=2000s")).To(MatchError(ContainSubstring("is longer than max-delay 16m40s")))
+		})
+	})
+
+	Describe("RateLimiter", func() {
+		It("backs off objects that keep failing from the base delay up to the max delay", func() {
+			rateLimit = registrar.RateLimit{BaseDelay: time.Second, MaxDelay: 3 * time.Second}
+			limiter := rateLimit.RateLimiter()
+
+			Expect(limiter.When("some-object")).To(Equal(time.Second))
+			Expect(limiter.When("some-object")).To(Equal(2 * time.Second))
+			Expect(limiter.When("some-object")).To(Equal(3 * time.Second))
+			Expect(limiter.When("another-object")).To(Equal(time.Second))
+		})
+
+		It("uses the defaults for settings left zero", func() {
+			limiter := rateLimit.RateLimiter()
+
+			Expect(limiter.When("some-object")).To(Equal(registrar.DefaultBaseDelay))
+		})
+	})
+})
@@ -19,10 +19,15 @@ package registrar
 import (
 	"context"
 	"fmt"
+	"time"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"sigs.k8s.io/cluster-api/controllers/external"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	pkgcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -30,16 +35,36 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha2"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
 	"github.com/vmware-tanzu/cartographer/pkg/conditions"
+	"github.com/vmware-tanzu/cartographer/pkg/controller/blueprint"
+	"github.com/vmware-tanzu/cartographer/pkg/controller/blueprintsource"
 	"github.com/vmware-tanzu/cartographer/pkg/controller/deliverable"
 	"github.com/vmware-tanzu/cartographer/pkg/controller/delivery"
 	"github.com/vmware-tanzu/cartographer/pkg/controller/pipeline"
 	"github.com/vmware-tanzu/cartographer/pkg/controller/supplychain"
 	"github.com/vmware-tanzu/cartographer/pkg/controller/workload"
+	"github.com/vmware-tanzu/cartographer/pkg/git"
+	"github.com/vmware-tanzu/cartographer/pkg/gitops"
+	"github.com/vmware-tanzu/cartographer/pkg/gittemplate"
+	"github.com/vmware-tanzu/cartographer/pkg/lease"
+	"github.com/vmware-tanzu/cartographer/pkg/notification"
+	"github.com/vmware-tanzu/cartographer/pkg/oci"
+	"github.com/vmware-tanzu/cartographer/pkg/ocisource"
+	"github.com/vmware-tanzu/cartographer/pkg/paramsource"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
+	"github.com/vmware-tanzu/cartographer/pkg/provenance"
+	"github.com/vmware-tanzu/cartographer/pkg/rbac"
 	realizerdeliverable "github.com/vmware-tanzu/cartographer/pkg/realizer/deliverable"
 	realizerpipeline "github.com/vmware-tanzu/cartographer/pkg/realizer/pipeline"
 	realizerworkload "github.com/vmware-tanzu/cartographer/pkg/realizer/workload"
 	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/shard"
+	"github.com/vmware-tanzu/cartographer/pkg/shutdown"
+	"github.com/vmware-tanzu/cartographer/pkg/templatelibrary"
+	"github.com/vmware-tanzu/cartographer/pkg/trigger"
 )
 
 type Timer struct{}
@@ -53,54 +78,169 @@ func AddToScheme(scheme *runtime.Scheme) error {
 		return fmt.Errorf("cartographer v1alpha1 add to scheme: %w", err)
 	}
 
+	if err := v1alpha2.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("cartographer v1alpha2 add to scheme: %w", err)
+	}
+
+	if err := coordinationv1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("coordination v1 add to scheme: %w", err)
+	}
+
+	if err := authorizationv1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("authorization v1 add to scheme: %w", err)
+	}
+
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("core v1 add to scheme: %w", err)
+	}
+
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("apiextensions v1 add to scheme: %w", err)
+	}
+
 	return nil
 }
 
-func RegisterControllers(mgr manager.Manager) error {
-	if err := registerWorkloadController(mgr); err != nil {
+// RegisterControllers registers each controller with mgr. Objects stamped
+// by the workload, deliverable and pipeline controllers must satisfy
+// stampPolicy, which may be nil. When receiver is not nil, the workload and
+// deliverable controllers also reconcile on the triggers it receives. When
+// locker is not nil, the workload, deliverable and pipeline controllers only
+// realize objects whose lease this replica holds. Ready workloads and
+// deliverables are reconciled again every resyncInterval, unless they are
+// annotated otherwise. When plugins is not nil, the workload and deliverable
+// controllers realize the resources whose templates name a plugin through
+// it. When clusterData is not nil, their templates read the facts about the
+// cluster it returns. When permissions is not nil, they review the
+// permissions needed to submit and track the objects they stamp before
+// realizing their blueprints. When cacheStore is not nil, the caches of the
+// workload, deliverable and pipeline controllers are restored from it and
+// saved to it, so that they survive a restart.
+func RegisterControllers(mgr manager.Manager, drainer *shutdown.Drainer, stampPolicy *policy.Policy, receiver *trigger.Receiver, locker *lease.Locker, recorder *provenance.Recorder, rateLimits RateLimits, sharder *shard.Sharder, resyncInterval time.Duration, plugins *plugin.Plugins, clusterData *clusterdata.Reader, permissions *rbac.Reviewer, cacheStore repository.CacheStore) error {
+	// The controllers share a resolver, so that each template in git is
+	// fetched once.
+	resolver := gittemplate.NewResolver(git.NewClient(), gittemplate.DefaultRefInterval)
+
+	if err := registerWorkloadController(mgr, drainer, resolver, stampPolicy, receiver, locker, recorder, rateLimits.Workload, sharder, resyncInterval, plugins, clusterData, permissions, cacheStore); err != nil {
 		return fmt.Errorf("register workload controller: %w", err)
 	}
 
-	if err := registerSupplyChainController(mgr); err != nil {
+	if err := registerSupplyChainController(mgr, drainer, resolver, rateLimits.Blueprint); err != nil {
 		return fmt.Errorf("register supply-chain controller: %w", err)
 	}
 
-	if err := registerDeliveryController(mgr); err != nil {
+	if err := registerNamespacedSupplyChainController(mgr, drainer, resolver, rateLimits.Blueprint); err != nil {
+		return fmt.Errorf("register namespaced supply-chain controller: %w", err)
+	}
+
+	if err := registerDeliveryController(mgr, drainer, resolver, rateLimits.Blueprint); err != nil {
 		return fmt.Errorf("register delivery controller: %w", err)
 	}
 
-	if err := registerDeliverableController(mgr); err != nil {
+	if err := registerNamespacedDeliveryController(mgr, drainer, resolver, rateLimits.Blueprint); err != nil {
+		return fmt.Errorf("register namespaced delivery controller: %w", err)
+	}
+
+	if err := registerDeliverableController(mgr, drainer, resolver, stampPolicy, receiver, locker, rateLimits.Deliverable, sharder, resyncInterval, plugins, clusterData, permissions, cacheStore); err != nil {
 		return fmt.Errorf("register deliverable controller: %w", err)
 	}
 
-	if err := registerPipelineServiceController(mgr); err != nil {
+	if err := registerPipelineServiceController(mgr, drainer, stampPolicy, locker, rateLimits.Pipeline, cacheStore); err != nil {
 		return fmt.Errorf("register pipeline-service controller: %w", err)
 	}
 
+	if err := registerBlueprintSourceController(mgr, drainer, rateLimits.Blueprint); err != nil {
+		return fmt.Errorf("register blueprint-source controller: %w", err)
+	}
+
+	if err := registerBlueprintController(mgr, drainer, rateLimits.Blueprint); err != nil {
+		return fmt.Errorf("register blueprint controller: %w", err)
+	}
+
 	return nil
 }
 
-func registerWorkloadController(mgr manager.Manager) error {
-	repo := repository.NewRepository(
-		mgr.GetClient(),
-		repository.NewCache(mgr.GetLogger().WithName("workload-repo-cache")),
-		mgr.GetLogger().WithName("workload-repo"),
-	)
-
+func registerWorkloadController(mgr manager.Manager, drainer *shutdown.Drainer, resolver *gittemplate.Resolver, stampPolicy *policy.Policy, receiver *trigger.Receiver, locker *lease.Locker, recorder *provenance.Recorder, rateLimit RateLimit, sharder *shard.Sharder, resyncInterval time.Duration, plugins *plugin.Plugins, clusterData *clusterdata.Reader, permissions *rbac.Reviewer, cacheStore repository.CacheStore) error {
+	repoCache, err := newRepoCache(mgr, "workload", cacheStore)
+	if err != nil {
+		return fmt.Errorf("repo cache: %w", err)
+	}
+	repoLogger := mgr.GetLogger().WithName("workload-repo")
+	repo := gittemplate.WithGitTemplates(guard(mgr, repository.NewRepository(mgr.GetClient(), repoCache, repoLogger), stampPolicy), resolver)
+	serviceAccountRepo := newServiceAccountRepository(mgr, repoCache, repoLogger, stampPolicy)
+
+	reconciler := workload.NewReconciler(repo, serviceAccountRepo, conditions.NewConditionManager, realizerworkload.NewRealizer())
+	reconciler.AddSourceResolution(ocisource.NewResolver(oci.NewClient(), repo, ocisource.DefaultInterval))
+	paramResolver := paramsource.NewResolver(repo)
+	reconciler.AddParamResolution(paramResolver)
+	libraryTracker := templatelibrary.NewTracker()
+	reconciler.AddLibraryTracking(libraryTracker)
+	reconciler.AddNotifications(notification.NewNotifier(mgr.GetClient(), mgr.GetLogger().WithName("workload-notifications")))
+	reconciler.AddEventRecording(mgr.GetEventRecorderFor("workload-controller"))
+	reconciler.AddCacheEviction(repoCache)
+	reconciler.AddScopeDetection(mgr.GetRESTMapper())
+	if plugins != nil {
+		reconciler.AddRealizerPlugins(plugins)
+	}
+	if clusterData != nil {
+		reconciler.AddClusterData(clusterData)
+	}
+	if permissions != nil {
+		reconciler.AddPermissionReviewer(permissions)
+	}
+	if resyncInterval > 0 {
+		reconciler.SetResyncInterval(resyncInterval)
+	}
 	ctrl, err := pkgcontroller.New("workload", mgr, pkgcontroller.Options{
-		Reconciler: workload.NewReconciler(repo, conditions.NewConditionManager, realizerworkload.NewRealizer()),
+		Reconciler: drainer.Wrap(sharder.Wrap(
+			func() client.Object { return &v1alpha1.Workload{} },
+			locker.Wrap("cartographer-workload",
+				func() client.Object { return &v1alpha1.Workload{} },
+				reconciler,
+			),
+		)),
+		RateLimiter: rateLimit.RateLimiter(),
 	})
 	if err != nil {
 		return fmt.Errorf("controller new: %w", err)
 	}
 
+	reconciler.AddTracking(&StampedObjectTracker{
+		Controller: ctrl,
+	})
+	if recorder != nil {
+		reconciler.AddArtifactRecording(recorder)
+	}
+
 	if err := ctrl.Watch(
 		&source.Kind{Type: &v1alpha1.Workload{}},
 		&handler.EnqueueRequestForObject{},
+		sharder.Predicate(),
+		OwnerChanged(),
 	); err != nil {
 		return fmt.Errorf("watch: %w", err)
 	}
 
+	if err := watchParamSources(ctrl, paramResolver); err != nil {
+		return err
+	}
+
+	if err := ctrl.Watch(
+		&source.Kind{Type: &v1alpha1.ClusterTemplateLibrary{}},
+		handler.EnqueueRequestsFromMapFunc(libraryTracker.Requests),
+	); err != nil {
+		return fmt.Errorf("watch template libraries: %w", err)
+	}
+
+	if receiver != nil {
+		if err := ctrl.Watch(
+			&source.Channel{Source: receiver.WorkloadEvents()},
+			&handler.EnqueueRequestForObject{},
+		); err != nil {
+			return fmt.Errorf("watch triggers: %w", err)
+		}
+	}
+
 	mapper := Mapper{
 		Client: mgr.GetClient(),
 		Logger: mgr.GetLogger().WithName("workload"),
@@ -113,18 +253,91 @@ func registerWorkloadController(mgr manager.Manager) error {
 		return fmt.Errorf("watch: %w", err)
 	}
 
+	if err := ctrl.Watch(
+		&source.Kind{Type: &v1alpha1.SupplyChain{}},
+		handler.EnqueueRequestsFromMapFunc(mapper.SupplyChainToWorkloadRequests),
+	); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	if err := ctrl.Watch(
+		&source.Kind{Type: &apiextensionsv1.CustomResourceDefinition{}},
+		handler.EnqueueRequestsFromMapFunc(mapper.CustomResourceDefinitionToWorkloadRequests),
+	); err != nil {
+		return fmt.Errorf("watch custom resource definitions: %w", err)
+	}
+
+	return nil
+}
+
+func registerBlueprintSourceController(mgr manager.Manager, drainer *shutdown.Drainer, rateLimit RateLimit) error {
+	repo := repository.NewRepository(
+		mgr.GetClient(),
+		repository.NewCache(mgr.GetLogger().WithName("blueprint-source-repo-cache")),
+		mgr.GetLogger().WithName("blueprint-source-repo"),
+	)
+
+	ctrl, err := pkgcontroller.New("blueprint-source", mgr, pkgcontroller.Options{
+		Reconciler:  drainer.Wrap(blueprintsource.NewReconciler(repo, oci.NewClient(), conditions.NewConditionManager)),
+		RateLimiter: rateLimit.RateLimiter(),
+	})
+	if err != nil {
+		return fmt.Errorf("controller new: %w", err)
+	}
+
+	if err := ctrl.Watch(
+		&source.Kind{Type: &v1alpha1.ClusterBlueprintSource{}},
+		&handler.EnqueueRequestForObject{},
+	); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
 	return nil
 }
 
-func registerSupplyChainController(mgr manager.Manager) error {
+func registerBlueprintController(mgr manager.Manager, drainer *shutdown.Drainer, rateLimit RateLimit) error {
 	repo := repository.NewRepository(
+		mgr.GetClient(),
+		repository.NewCache(mgr.GetLogger().WithName("blueprint-repo-cache")),
+		mgr.GetLogger().WithName("blueprint-repo"),
+	)
+
+	ctrl, err := pkgcontroller.New("blueprint", mgr, pkgcontroller.Options{
+		Reconciler:  drainer.Wrap(blueprint.NewReconciler(repo, conditions.NewConditionManager)),
+		RateLimiter: rateLimit.RateLimiter(),
+	})
+	if err != nil {
+		return fmt.Errorf("controller new: %w", err)
+	}
+
+	if err := ctrl.Watch(
+		&source.Kind{Type: &v1alpha1.ClusterBlueprint{}},
+		&handler.EnqueueRequestForObject{},
+	); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	// A blueprint is ready once its supply chain is.
+	if err := ctrl.Watch(
+		&source.Kind{Type: &v1alpha1.ClusterSupplyChain{}},
+		&handler.EnqueueRequestForOwner{OwnerType: &v1alpha1.ClusterBlueprint{}, IsController: true},
+	); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	return nil
+}
+
+func registerSupplyChainController(mgr manager.Manager, drainer *shutdown.Drainer, resolver *gittemplate.Resolver, rateLimit RateLimit) error {
+	repo := gittemplate.WithGitTemplates(repository.NewRepository(
 		mgr.GetClient(),
 		repository.NewCache(mgr.GetLogger().WithName("supply-chain-repo-cache")),
 		mgr.GetLogger().WithName("supply-chain-repo"),
-	)
+	), resolver)
 
 	ctrl, err := pkgcontroller.New("supply-chain", mgr, pkgcontroller.Options{
-		Reconciler: supplychain.NewReconciler(repo, conditions.NewConditionManager),
+		Reconciler:  drainer.Wrap(supplychain.NewReconciler(repo, conditions.NewConditionManager)),
+		RateLimiter: rateLimit.RateLimiter(),
 	})
 	if err != nil {
 		return fmt.Errorf("controller new: %w", err)
@@ -140,15 +353,41 @@ func registerSupplyChainController(mgr manager.Manager) error {
 	return nil
 }
 
-func registerDeliveryController(mgr manager.Manager) error {
-	repo := repository.NewRepository(
+func registerNamespacedSupplyChainController(mgr manager.Manager, drainer *shutdown.Drainer, resolver *gittemplate.Resolver, rateLimit RateLimit) error {
+	repo := gittemplate.WithGitTemplates(repository.NewRepository(
+		mgr.GetClient(),
+		repository.NewCache(mgr.GetLogger().WithName("namespaced-supply-chain-repo-cache")),
+		mgr.GetLogger().WithName("namespaced-supply-chain-repo"),
+	), resolver)
+
+	ctrl, err := pkgcontroller.New("namespaced-supply-chain", mgr, pkgcontroller.Options{
+		Reconciler:  drainer.Wrap(supplychain.NewNamespacedReconciler(repo, conditions.NewConditionManager)),
+		RateLimiter: rateLimit.RateLimiter(),
+	})
+	if err != nil {
+		return fmt.Errorf("controller new: %w", err)
+	}
+
+	if err := ctrl.Watch(
+		&source.Kind{Type: &v1alpha1.SupplyChain{}},
+		&handler.EnqueueRequestForObject{},
+	); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	return nil
+}
+
+func registerDeliveryController(mgr manager.Manager, drainer *shutdown.Drainer, resolver *gittemplate.Resolver, rateLimit RateLimit) error {
+	repo := gittemplate.WithGitTemplates(repository.NewRepository(
 		mgr.GetClient(),
 		repository.NewCache(mgr.GetLogger().WithName("delivery-repo-cache")),
 		mgr.GetLogger().WithName("delivery-repo"),
-	)
+	), resolver)
 
 	ctrl, err := pkgcontroller.New("delivery", mgr, pkgcontroller.Options{
-		Reconciler: delivery.NewReconciler(repo),
+		Reconciler:  drainer.Wrap(delivery.NewReconciler(repo)),
+		RateLimiter: rateLimit.RateLimiter(),
 	})
 	if err != nil {
 		return fmt.Errorf("controller new: %w", err)
@@ -164,27 +403,110 @@ func registerDeliveryController(mgr manager.Manager) error {
 	return nil
 }
 
-func registerDeliverableController(mgr manager.Manager) error {
-	repo := repository.NewRepository(
+func registerNamespacedDeliveryController(mgr manager.Manager, drainer *shutdown.Drainer, resolver *gittemplate.Resolver, rateLimit RateLimit) error {
+	repo := gittemplate.WithGitTemplates(repository.NewRepository(
 		mgr.GetClient(),
-		repository.NewCache(mgr.GetLogger().WithName("deliverable-repo-cache")),
-		mgr.GetLogger().WithName("deliverable-repo"),
-	)
+		repository.NewCache(mgr.GetLogger().WithName("namespaced-delivery-repo-cache")),
+		mgr.GetLogger().WithName("namespaced-delivery-repo"),
+	), resolver)
+
+	ctrl, err := pkgcontroller.New("namespaced-delivery", mgr, pkgcontroller.Options{
+		Reconciler:  drainer.Wrap(delivery.NewNamespacedReconciler(repo)),
+		RateLimiter: rateLimit.RateLimiter(),
+	})
+	if err != nil {
+		return fmt.Errorf("controller new: %w", err)
+	}
+
+	if err := ctrl.Watch(
+		&source.Kind{Type: &v1alpha1.Delivery{}},
+		&handler.EnqueueRequestForObject{},
+	); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	return nil
+}
 
+func registerDeliverableController(mgr manager.Manager, drainer *shutdown.Drainer, resolver *gittemplate.Resolver, stampPolicy *policy.Policy, receiver *trigger.Receiver, locker *lease.Locker, rateLimit RateLimit, sharder *shard.Sharder, resyncInterval time.Duration, plugins *plugin.Plugins, clusterData *clusterdata.Reader, permissions *rbac.Reviewer, cacheStore repository.CacheStore) error {
+	repoCache, err := newRepoCache(mgr, "deliverable", cacheStore)
+	if err != nil {
+		return fmt.Errorf("repo cache: %w", err)
+	}
+	repoLogger := mgr.GetLogger().WithName("deliverable-repo")
+	repo := gittemplate.WithGitTemplates(guard(mgr, repository.NewRepository(mgr.GetClient(), repoCache, repoLogger), stampPolicy), resolver)
+	serviceAccountRepo := newServiceAccountRepository(mgr, repoCache, repoLogger, stampPolicy)
+
+	reconciler := deliverable.NewReconciler(repo, serviceAccountRepo, conditions.NewConditionManager, realizerdeliverable.NewRealizer())
+	reconciler.AddSourceResolution(ocisource.NewResolver(oci.NewClient(), repo, ocisource.DefaultInterval))
+	paramResolver := paramsource.NewResolver(repo)
+	reconciler.AddParamResolution(paramResolver)
+	libraryTracker := templatelibrary.NewTracker()
+	reconciler.AddLibraryTracking(libraryTracker)
+	reconciler.AddRemoteTargets(newTargetRepository(mgr, repo, repoLogger, stampPolicy))
+	reconciler.AddPullRequests(gitops.NewPromoter(nil, gitops.DefaultPollInterval))
+	reconciler.AddNotifications(notification.NewNotifier(mgr.GetClient(), mgr.GetLogger().WithName("deliverable-notifications")))
+	reconciler.AddEventRecording(mgr.GetEventRecorderFor("deliverable-controller"))
+	reconciler.AddCacheEviction(repoCache)
+	reconciler.AddScopeDetection(mgr.GetRESTMapper())
+	if plugins != nil {
+		reconciler.AddRealizerPlugins(plugins)
+	}
+	if clusterData != nil {
+		reconciler.AddClusterData(clusterData)
+	}
+	if permissions != nil {
+		reconciler.AddPermissionReviewer(permissions)
+	}
+	if resyncInterval > 0 {
+		reconciler.SetResyncInterval(resyncInterval)
+	}
 	ctrl, err := pkgcontroller.New("deliverable", mgr, pkgcontroller.Options{
-		Reconciler: deliverable.NewReconciler(repo, conditions.NewConditionManager, realizerdeliverable.NewRealizer()),
+		Reconciler: drainer.Wrap(sharder.Wrap(
+			func() client.Object { return &v1alpha1.Deliverable{} },
+			locker.Wrap("cartographer-deliverable",
+				func() client.Object { return &v1alpha1.Deliverable{} },
+				reconciler,
+			),
+		)),
+		RateLimiter: rateLimit.RateLimiter(),
 	})
 	if err != nil {
 		return fmt.Errorf("controller new: %w", err)
 	}
+	reconciler.AddTracking(&StampedObjectTracker{
+		Controller: ctrl,
+	})
 
 	if err := ctrl.Watch(
 		&source.Kind{Type: &v1alpha1.Deliverable{}},
 		&handler.EnqueueRequestForObject{},
+		sharder.Predicate(),
+		OwnerChanged(),
 	); err != nil {
 		return fmt.Errorf("watch: %w", err)
 	}
 
+	if err := watchParamSources(ctrl, paramResolver); err != nil {
+		return err
+	}
+
+	if err := ctrl.Watch(
+		&source.Kind{Type: &v1alpha1.ClusterTemplateLibrary{}},
+		handler.EnqueueRequestsFromMapFunc(libraryTracker.Requests),
+	); err != nil {
+		return fmt.Errorf("watch template libraries: %w", err)
+	}
+
+	if receiver != nil {
+		if err := ctrl.Watch(
+			&source.Channel{Source: receiver.DeliverableEvents()},
+			&handler.EnqueueRequestForObject{},
+		); err != nil {
+			return fmt.Errorf("watch triggers: %w", err)
+		}
+	}
+
 	mapper := Mapper{
 		Client: mgr.GetClient(),
 		Logger: mgr.GetLogger().WithName("deliverable"),
@@ -197,25 +519,48 @@ func registerDeliverableController(mgr manager.Manager) error {
 		return fmt.Errorf("watch: %w", err)
 	}
 
+	if err := ctrl.Watch(
+		&source.Kind{Type: &v1alpha1.Delivery{}},
+		handler.EnqueueRequestsFromMapFunc(mapper.DeliveryToDeliverableRequests),
+	); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	if err := ctrl.Watch(
+		&source.Kind{Type: &apiextensionsv1.CustomResourceDefinition{}},
+		handler.EnqueueRequestsFromMapFunc(mapper.CustomResourceDefinitionToDeliverableRequests),
+	); err != nil {
+		return fmt.Errorf("watch custom resource definitions: %w", err)
+	}
+
 	return nil
 }
 
-func registerPipelineServiceController(mgr manager.Manager) error {
-	repo := repository.NewRepository(
+func registerPipelineServiceController(mgr manager.Manager, drainer *shutdown.Drainer, stampPolicy *policy.Policy, locker *lease.Locker, rateLimit RateLimit, cacheStore repository.CacheStore) error {
+	repoCache, err := newRepoCache(mgr, "pipeline", cacheStore)
+	if err != nil {
+		return fmt.Errorf("repo cache: %w", err)
+	}
+	repo := guard(mgr, repository.NewRepository(
 		mgr.GetClient(),
-		repository.NewCache(mgr.GetLogger().WithName("pipeline-repo-cache")),
+		repoCache,
 		mgr.GetLogger().WithName("pipeline-repo"),
-	)
+	), stampPolicy)
 
 	reconciler := pipeline.NewReconciler(repo, realizerpipeline.NewRealizer())
+	reconciler.AddCacheEviction(repoCache)
 	ctrl, err := pkgcontroller.New("pipeline-service", mgr, pkgcontroller.Options{
-		Reconciler: reconciler,
+		Reconciler: drainer.Wrap(locker.Wrap("cartographer-pipeline",
+			func() client.Object { return &v1alpha1.Pipeline{} },
+			reconciler,
+		)),
+		RateLimiter: rateLimit.RateLimiter(),
 	})
 	if err != nil {
 		return fmt.Errorf("controller new pipeline-service: %w", err)
 	}
 
-	reconciler.AddTracking(&external.ObjectTracker{
+	reconciler.AddTracking(&StampedObjectTracker{
 		Controller: ctrl,
 	})
 
@@ -259,3 +604,78 @@ func indexSupplyChains(ctx context.Context, fieldIndexer client.FieldIndexer) er
 
 	return nil
 }
+
+// restoreTimeout bounds the restoring of each persistent cache on start.
+const restoreTimeout = 10 * time.Second
+
+// newRepoCache returns the cache of the controller named, restored from
+// cacheStore and saved to it while mgr runs when cacheStore is not nil. A
+// cache that cannot be restored starts empty.
+func newRepoCache(mgr manager.Manager, controller string, cacheStore repository.CacheStore) (repository.RepoCache, error) {
+	logger := mgr.GetLogger().WithName(controller + "-repo-cache")
+	if cacheStore == nil {
+		return repository.NewCache(logger), nil
+	}
+
+	repoCache := repository.NewPersistentCache(logger, repository.DefaultCacheSize, cacheStore, controller, repository.DefaultPersistInterval)
+	ctx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+	defer cancel()
+	if err := repoCache.Restore(ctx); err != nil {
+		logger.Error(err, "failed to restore cache, starting empty")
+	}
+	if err := mgr.Add(repoCache); err != nil {
+		return nil, fmt.Errorf("add persistent cache: %w", err)
+	}
+	return repoCache, nil
+}
+
+// newServiceAccountRepository returns repositories that act as service
+// accounts. They share the controller's repository cache and logger, and are
+// held to the same stamp policies.
+func newServiceAccountRepository(mgr manager.Manager, repoCache repository.RepoCache, repoLogger repository.Logger, stampPolicy *policy.Policy) repository.ServiceAccountRepository {
+	clients := repository.NewImpersonatingClients(mgr.GetConfig(), client.Options{
+		Scheme: mgr.GetScheme(),
+		Mapper: mgr.GetRESTMapper(),
+	})
+
+	return func(namespace, serviceAccountName string) (repository.Repository, error) {
+		cl, err := clients.For(namespace, serviceAccountName)
+		if err != nil {
+			return nil, err
+		}
+		return guard(mgr, repository.NewRepository(cl, repoCache, repoLogger), stampPolicy), nil
+	}
+}
+
+// newTargetRepository returns the repositories for the remote clusters
+// deliveries target, reading their kubeconfigs with repo.
+func newTargetRepository(mgr manager.Manager, repo repository.Repository, repoLogger repository.Logger, stampPolicy *policy.Policy) repository.TargetRepository {
+	clients := repository.NewRemoteClients(client.Options{Scheme: mgr.GetScheme()}, repoLogger)
+
+	return repository.NewTargetRepository(repo, clients, func(cl client.Client, cache repository.RepoCache) repository.Repository {
+		return guard(mgr, repository.NewRepository(cl, cache, repoLogger), stampPolicy)
+	})
+}
+
+// guard holds repo to stampPolicy and to the ClusterStampPolicies on the
+// cluster.
+func guard(mgr manager.Manager, repo repository.Repository, stampPolicy *policy.Policy) repository.Repository {
+	return policy.GuardKinds(policy.Guard(repo, stampPolicy), mgr.GetClient())
+}
+
+// watchParamSources reconciles the owners whose params were read from a
+// ConfigMap or Secret whenever it changes. Only their metadata is watched,
+// so the values of every Secret on the cluster are not cached.
+func watchParamSources(ctrl pkgcontroller.Controller, resolver *paramsource.Resolver) error {
+	for _, kind := range paramsource.Kinds {
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: kind})
+		if err := ctrl.Watch(
+			&source.Kind{Type: obj},
+			handler.EnqueueRequestsFromMapFunc(resolver.Requests),
+		); err != nil {
+			return fmt.Errorf("watch %s: %w", kind, err)
+		}
+	}
+	return nil
+}
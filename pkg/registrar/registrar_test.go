@@ -22,7 +22,9 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
 	"github.com/vmware-tanzu/cartographer/pkg/registrar"
 )
 
@@ -53,15 +55,53 @@ var _ = Describe("Registrar", func() {
 				Expect(scheme.IsGroupRegistered("carto.run")).To(BeTrue())
 			})
 
+			It("registers the coordination group for realization leases", func() {
+				Expect(scheme.IsGroupRegistered("coordination.k8s.io")).To(BeTrue())
+			})
+
 			It("creates a scheme with expected length", func() {
 				gv := schema.GroupVersion{
 					Group:   "carto.run",
 					Version: "v1alpha1",
 				}
-				Expect(len(scheme.KnownTypes(gv))).To(Equal(29))
+				Expect(len(scheme.KnownTypes(gv))).To(Equal(43))
 				// If this test fails, it may indicate that new types should be added to the test below
 			})
 
+			It("adds the cartographer v1alpha2 objects to the scheme", func() {
+				baseGVK := schema.GroupVersionKind{
+					Group:   "carto.run",
+					Version: "v1alpha2",
+				}
+
+				kinds := []string{
+					"ClusterConfigTemplate",
+					"ClusterDelivery",
+					"ClusterImageTemplate",
+					"ClusterSourceTemplate",
+					"ClusterSupplyChain",
+				}
+
+				for _, kind := range kinds {
+					baseGVK.Kind = kind
+					Expect(scheme.Recognizes(baseGVK)).To(BeTrue(), fmt.Sprintf("scheme should have kind: %s", kind))
+				}
+			})
+
+			It("makes the kinds served in several versions convertible", func() {
+				for _, obj := range []runtime.Object{
+					&v1alpha1.ClusterConfigTemplate{},
+					&v1alpha1.ClusterDelivery{},
+					&v1alpha1.ClusterImageTemplate{},
+					&v1alpha1.ClusterSourceTemplate{},
+					&v1alpha1.ClusterSupplyChain{},
+				} {
+					convertible, err := conversion.IsConvertible(scheme, obj)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(convertible).To(BeTrue(), fmt.Sprintf("%T should be convertible", obj))
+				}
+			})
+
 			It("adds the cartographer objects to the scheme", func() {
 				baseGVK := schema.GroupVersionKind{
 					Group:   "carto.run",
@@ -69,16 +109,23 @@ var _ = Describe("Registrar", func() {
 				}
 
 				kinds := []string{
+					"ClusterBlueprint",
+					"ClusterBlueprintSource",
 					"ClusterConfigTemplate",
 					"ClusterDelivery",
 					"ClusterDeploymentTemplate",
 					"ClusterImageTemplate",
+					"ClusterNotificationSink",
 					"ClusterRunTemplate",
 					"ClusterSourceTemplate",
+					"ClusterStampPolicy",
 					"ClusterSupplyChain",
 					"ClusterTemplate",
+					"ClusterTemplateLibrary",
 					"Deliverable",
+					"Delivery",
 					"Pipeline",
+					"SupplyChain",
 					"Workload",
 				}
 
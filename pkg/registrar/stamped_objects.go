@@ -0,0 +1,112 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registrar
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// StampedObjectTracker watches each kind of the objects stamped by a
+// controller once, as the first object of the kind is tracked. Updates that
+// change nothing the controller reads, as told by StampedObjectChanged, are
+// filtered out.
+type StampedObjectTracker struct {
+	Controller controller.Controller
+
+	watched sync.Map
+}
+
+func (t *StampedObjectTracker) Watch(log logr.Logger, obj runtime.Object, handler handler.EventHandler) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	key := gvk.GroupKind().String()
+	if _, loaded := t.watched.LoadOrStore(key, struct{}{}); loaded {
+		return nil
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+
+	log.Info("watching stamped objects", "kind", gvk.String())
+	err := t.Controller.Watch(
+		&source.Kind{Type: u},
+		handler,
+		predicates.ResourceNotPaused(log),
+		StampedObjectChanged(),
+	)
+	if err != nil {
+		t.watched.Delete(key)
+		return fmt.Errorf("watch %s: %w", gvk, err)
+	}
+	return nil
+}
+
+// StampedObjectChanged filters out the updates of stamped objects that
+// change nothing but their resourceVersion, managed fields or the times of
+// their status conditions, such as the heartbeats of a controller that
+// rewrites its status periodically. Their outputs and health are read from
+// the rest of the object, so reconciling their owner would change nothing.
+func StampedObjectChanged() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*unstructured.Unstructured)
+			if !ok {
+				return true
+			}
+			updated, ok := e.ObjectNew.(*unstructured.Unstructured)
+			if !ok {
+				return true
+			}
+			return !equality.Semantic.DeepEqual(relevantContent(old), relevantContent(updated))
+		},
+	}
+}
+
+// conditionTimes are the fields of status conditions that only record when
+// the condition was last written or probed.
+var conditionTimes = []string{"lastTransitionTime", "lastHeartbeatTime", "lastUpdateTime", "lastProbeTime"}
+
+// relevantContent returns the content of u without the fields that
+// StampedObjectChanged ignores.
+func relevantContent(u *unstructured.Unstructured) map[string]interface{} {
+	content := runtime.DeepCopyJSON(u.Object)
+	unstructured.RemoveNestedField(content, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(content, "metadata", "managedFields")
+
+	conditions, found, err := unstructured.NestedSlice(content, "status", "conditions")
+	if !found || err != nil {
+		return content
+	}
+	for _, condition := range conditions {
+		if fields, ok := condition.(map[string]interface{}); ok {
+			for _, field := range conditionTimes {
+				delete(fields, field)
+			}
+		}
+	}
+	_ = unstructured.SetNestedSlice(content, conditions, "status", "conditions")
+	return content
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registrar_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware-tanzu/cartographer/pkg/registrar"
+)
+
+var _ = Describe("StampedObjectChanged", func() {
+	var old *unstructured.Unstructured
+
+	changed := func(updated *unstructured.Unstructured) bool {
+		return registrar.StampedObjectChanged().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})
+	}
+
+	BeforeEach(func() {
+		old = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kpack.io/v1alpha2",
+			"kind":       "Image",
+			"metadata": map[string]interface{}{
+				"name":            "app",
+				"namespace":       "dev",
+				"resourceVersion": "1",
+				"managedFields":   []interface{}{map[string]interface{}{"manager": "cartographer"}},
+			},
+			"spec": map[string]interface{}{"tag": "registry/app"},
+			"status": map[string]interface{}{
+				"latestImage": "registry/app@sha256:1",
+				"conditions": []interface{}{map[string]interface{}{
+					"type":               "Ready",
+					"status":             "True",
+					"lastTransitionTime": "2022-03-01T10:00:00Z",
+				}},
+			},
+		}}
+	})
+
+	It("filters out updates of the resourceVersion and managed fields", func() {
+		updated := old.DeepCopy()
+		updated.SetResourceVersion("2")
+		updated.SetManagedFields(nil)
+		Expect(changed(updated)).To(BeFalse())
+	})
+
+	It("filters out updates of the times of status conditions", func() {
+		updated := old.DeepCopy()
+		updated.SetResourceVersion("2")
+		Expect(unstructured.SetNestedSlice(updated.Object, []interface{}{map[string]interface{}{
+			"type":               "Ready",
+			"status":             "True",
+			"lastTransitionTime": "2022-03-01T10:05:00Z",
+			"lastHeartbeatTime":  "2022-03-01T10:05:00Z",
+		}}, "status", "conditions")).To(Succeed())
+		Expect(changed(updated)).To(BeFalse())
+		Expect(old.Object["status"]).To(HaveKeyWithValue("conditions", ContainElement(HaveKeyWithValue("lastTransitionTime", "2022-03-01T10:00:00Z"))))
+	})
+
+	It("passes updates of the status", func() {
+		updated := old.DeepCopy()
+		Expect(unstructured.SetNestedField(updated.Object, "registry/app@sha256:2", "status", "latestImage")).To(Succeed())
+		Expect(changed(updated)).To(BeTrue())
+
+		updated = old.DeepCopy()
+		Expect(unstructured.SetNestedSlice(updated.Object, []interface{}{map[string]interface{}{
+			"type":   "Ready",
+			"status": "False",
+		}}, "status", "conditions")).To(Succeed())
+		Expect(changed(updated)).To(BeTrue())
+	})
+
+	It("passes updates of the spec and metadata", func() {
+		updated := old.DeepCopy()
+		Expect(unstructured.SetNestedField(updated.Object, "registry/other", "spec", "tag")).To(Succeed())
+		Expect(changed(updated)).To(BeTrue())
+
+		updated = old.DeepCopy()
+		updated.SetLabels(map[string]string{"team": "web"})
+		Expect(changed(updated)).To(BeTrue())
+	})
+
+	It("passes updates of objects that are not unstructured", func() {
+		Expect(registrar.StampedObjectChanged().Update(event.UpdateEvent{
+			ObjectOld: &corev1.ConfigMap{},
+			ObjectNew: &corev1.ConfigMap{},
+		})).To(BeTrue())
+	})
+
+	It("passes creations and deletions", func() {
+		Expect(registrar.StampedObjectChanged().Create(event.CreateEvent{Object: old})).To(BeTrue())
+		Expect(registrar.StampedObjectChanged().Delete(event.DeleteEvent{Object: old})).To(BeTrue())
+	})
+})
+
+var _ = Describe("StampedObjectTracker", func() {
+	var (
+		ctrl    *watchingController
+		tracker *registrar.StampedObjectTracker
+		image   *unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		ctrl = &watchingController{}
+		tracker = &registrar.StampedObjectTracker{Controller: ctrl}
+		image = &unstructured.Unstructured{}
+		image.SetAPIVersion("kpack.io/v1alpha2")
+		image.SetKind("Image")
+		image.SetName("app")
+	})
+
+	It("watches each kind once, filtering out unchanged updates", func() {
+		Expect(tracker.Watch(logr.Discard(), image, &handler.EnqueueRequestForObject{})).To(Succeed())
+		other := image.DeepCopy()
+		other.SetName("other")
+		Expect(tracker.Watch(logr.Discard(), other, &handler.EnqueueRequestForObject{})).To(Succeed())
+
+		Expect(ctrl.watches).To(HaveLen(1))
+		Expect(ctrl.watches[0]).To(HaveLen(2))
+	})
+
+	It("watches the kind again after a watch failed", func() {
+		ctrl.err = errors.New("no matches for kind")
+		Expect(tracker.Watch(logr.Discard(), image, &handler.EnqueueRequestForObject{})).To(MatchError(ContainSubstring("no matches for kind")))
+
+		ctrl.err = nil
+		Expect(tracker.Watch(logr.Discard(), image, &handler.EnqueueRequestForObject{})).To(Succeed())
+		Expect(ctrl.watches).To(HaveLen(2))
+	})
+})
+
+// watchingController records the predicates of the watches it is asked for.
+type watchingController struct {
+	watches [][]predicate.Predicate
+	err     error
+}
+
+func (c *watchingController) Reconcile(context.Context, reconcile.Request) (reconcile.Result, error) {
+	return reconcile.Result{}, nil
+}
+
+func (c *watchingController) Watch(_ source.Source, _ handler.EventHandler, predicates ...predicate.Predicate) error {
+	c.watches = append(c.watches, predicates)
+	return c.err
+}
+
+func (c *watchingController) Start(context.Context) error {
+	return nil
+}
+
+func (c *watchingController) GetLogger() logr.Logger {
+	return logr.Discard()
+}
@@ -0,0 +1,415 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render stamps blueprints offline, from manifests rather than a
+// cluster, with the same realizers the controller runs. It lets templates
+// be developed and checked in CI without a cluster.
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha2"
+	deliverablerealizer "github.com/vmware-tanzu/cartographer/pkg/realizer/deliverable"
+	workloadrealizer "github.com/vmware-tanzu/cartographer/pkg/realizer/workload"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// Manifests are the blueprints, templates, workloads and deliverables to
+// render. Pipelines and the ClusterRunTemplates they reference are loaded
+// for inspection, such as by rbac.Generate, but not rendered.
+type Manifests struct {
+	SupplyChains []v1alpha1.SupplyChainObject
+	Deliveries   []v1alpha1.DeliveryObject
+	Templates    []client.Object
+	Workloads    []*v1alpha1.Workload
+	Deliverables []*v1alpha1.Deliverable
+	Pipelines    []*v1alpha1.Pipeline
+	RunTemplates []*v1alpha1.ClusterRunTemplate
+}
+
+// Load adds the manifests in the YAML, or JSON, documents read from r.
+// Blueprints and templates may be written in any version Cartographer
+// serves. Lists, as exported by kubectl, are read item by item. Documents
+// of other kinds are rejected.
+func (m *Manifests) Load(r io.Reader) error {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("add v1alpha1 to scheme: %w", err)
+	}
+	if err := v1alpha2.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("add v1alpha2 to scheme: %w", err)
+	}
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read document: %w", err)
+		}
+		doc, err = yaml.ToJSON(doc)
+		if err != nil {
+			return fmt.Errorf("parse document: %w", err)
+		}
+		// Documents holding only comments.
+		if bytes.Equal(bytes.TrimSpace(doc), []byte("null")) {
+			continue
+		}
+
+		if err := m.add(scheme, decoder, doc); err != nil {
+			return err
+		}
+	}
+}
+
+// add decodes a document into the manifests. The items of a List, as
+// exported by kubectl get -o yaml, are added one by one.
+func (m *Manifests) add(scheme *runtime.Scheme, decoder runtime.Decoder, doc []byte) error {
+	var list struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Items      []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(doc, &list); err != nil {
+		return fmt.Errorf("parse document: %w", err)
+	}
+	if list.APIVersion == "v1" && list.Kind == "List" {
+		for _, item := range list.Items {
+			if err := m.add(scheme, decoder, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	obj, gvk, err := decoder.Decode(doc, nil, nil)
+	if err != nil {
+		return fmt.Errorf("decode document: %w", err)
+	}
+	obj, err = toHub(scheme, obj, gvk)
+	if err != nil {
+		return err
+	}
+
+	switch typed := obj.(type) {
+	case *v1alpha1.ClusterSupplyChain:
+		m.SupplyChains = append(m.SupplyChains, typed)
+	case *v1alpha1.SupplyChain:
+		m.SupplyChains = append(m.SupplyChains, typed)
+	case *v1alpha1.ClusterDelivery:
+		m.Deliveries = append(m.Deliveries, typed)
+	case *v1alpha1.Delivery:
+		m.Deliveries = append(m.Deliveries, typed)
+	case *v1alpha1.ClusterSourceTemplate, *v1alpha1.ClusterImageTemplate, *v1alpha1.ClusterConfigTemplate,
+		*v1alpha1.ClusterDeploymentTemplate, *v1alpha1.ClusterTemplate:
+		m.Templates = append(m.Templates, typed.(client.Object))
+	case *v1alpha1.Workload:
+		m.Workloads = append(m.Workloads, typed)
+	case *v1alpha1.Deliverable:
+		m.Deliverables = append(m.Deliverables, typed)
+	case *v1alpha1.Pipeline:
+		m.Pipelines = append(m.Pipelines, typed)
+	case *v1alpha1.ClusterRunTemplate:
+		m.RunTemplates = append(m.RunTemplates, typed)
+	default:
+		return fmt.Errorf("unsupported kind '%s'", gvk.Kind)
+	}
+	return nil
+}
+
+// Render stamps the objects of every workload and deliverable, in order,
+// with the blueprint selecting it. Outputs that are only known once objects
+// run on a cluster, such as those read from their status or the results of
+// a job, are passed on as placeholders naming the resource and output, like
+// <source-provider.url>.
+func Render(ctx context.Context, m *Manifests) ([]*unstructured.Unstructured, error) {
+	repo, err := NewOfflineRepository(m.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	var stamped []*unstructured.Unstructured
+	for _, workload := range m.Workloads {
+		objs, err := renderWorkload(ctx, repo, m.SupplyChains, workload)
+		if err != nil {
+			return nil, err
+		}
+		stamped = append(stamped, objs...)
+	}
+
+	for _, deliverable := range m.Deliverables {
+		objs, err := renderDeliverable(ctx, repo, m.Deliveries, deliverable)
+		if err != nil {
+			return nil, err
+		}
+		stamped = append(stamped, objs...)
+	}
+
+	return stamped, nil
+}
+
+func renderWorkload(ctx context.Context, repo *OfflineRepository, supplyChains []v1alpha1.SupplyChainObject, workload *v1alpha1.Workload) ([]*unstructured.Unstructured, error) {
+	if workload.Namespace == "" {
+		workload.Namespace = "default"
+	}
+	supplyChain, err := selectSupplyChain(supplyChains, workload)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.stamped = nil
+	serviceAccountRepo := func(string, string) (repository.Repository, error) { return repo, nil }
+	resourceRealizer := workloadResourceRealizer{workloadrealizer.NewResourceRealizer(workload, repo, serviceAccountRepo)}
+	if err := workloadrealizer.NewRealizer().Realize(ctx, resourceRealizer, supplyChain); err != nil {
+		return nil, fmt.Errorf("render workload '%s' with %s '%s': %w", workload.Name, supplyChain.GetObjectKind().GroupVersionKind().Kind, supplyChain.GetName(), err)
+	}
+	return repo.stamped, nil
+}
+
+func renderDeliverable(ctx context.Context, repo *OfflineRepository, deliveries []v1alpha1.DeliveryObject, deliverable *v1alpha1.Deliverable) ([]*unstructured.Unstructured, error) {
+	if deliverable.Namespace == "" {
+		deliverable.Namespace = "default"
+	}
+	delivery, err := selectDelivery(deliveries, deliverable)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.stamped = nil
+	serviceAccountRepo := func(string, string) (repository.Repository, error) { return repo, nil }
+	resourceRealizer := deliverableResourceRealizer{deliverablerealizer.NewResourceRealizer(deliverable, repo, serviceAccountRepo)}
+	if err := deliverablerealizer.NewRealizer().Realize(ctx, resourceRealizer, delivery); err != nil {
+		return nil, fmt.Errorf("render deliverable '%s' with %s '%s': %w", deliverable.Name, delivery.GetObjectKind().GroupVersionKind().Kind, delivery.GetName(), err)
+	}
+	return repo.stamped, nil
+}
+
+func selectSupplyChain(supplyChains []v1alpha1.SupplyChainObject, workload *v1alpha1.Workload) (v1alpha1.SupplyChainObject, error) {
+	var matches []v1alpha1.SupplyChainObject
+	for _, supplyChain := range supplyChains {
+		if labels.SelectorFromSet(supplyChain.GetSpec().Selector).Matches(labels.Set(workload.Labels)) {
+			matches = append(matches, supplyChain)
+		}
+	}
+	if len(matches) != 1 {
+		return nil, fmt.Errorf("workload '%s' is selected by %d supply chains, expected 1", workload.Name, len(matches))
+	}
+	return matches[0], nil
+}
+
+func selectDelivery(deliveries []v1alpha1.DeliveryObject, deliverable *v1alpha1.Deliverable) (v1alpha1.DeliveryObject, error) {
+	var matches []v1alpha1.DeliveryObject
+	for _, delivery := range deliveries {
+		if labels.SelectorFromSet(delivery.GetSpec().Selector).Matches(labels.Set(deliverable.Labels)) {
+			matches = append(matches, delivery)
+		}
+	}
+	if len(matches) != 1 {
+		return nil, fmt.Errorf("deliverable '%s' is selected by %d deliveries, expected 1", deliverable.Name, len(matches))
+	}
+	return matches[0], nil
+}
+
+// toHub converts objects of other served versions to v1alpha1.
+func toHub(scheme *runtime.Scheme, obj runtime.Object, gvk *schema.GroupVersionKind) (runtime.Object, error) {
+	convertible, ok := obj.(conversion.Convertible)
+	if !ok {
+		return obj, nil
+	}
+	hub, err := scheme.New(v1alpha1.SchemeGroupVersion.WithKind(gvk.Kind))
+	if err != nil {
+		return nil, fmt.Errorf("new %s: %w", gvk.Kind, err)
+	}
+	if err := convertible.ConvertTo(hub.(conversion.Hub)); err != nil {
+		return nil, fmt.Errorf("convert %s to %s: %w", gvk, v1alpha1.SchemeGroupVersion, err)
+	}
+	hub.GetObjectKind().SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(gvk.Kind))
+	return hub, nil
+}
+
+// OfflineRepository serves templates and holds the objects a realizer
+// stamps instead of applying them. It implements only the methods the
+// realizers call when stamping; the embedded Repository is nil.
+type OfflineRepository struct {
+	repository.Repository
+
+	// Libraries are the template libraries templates include partials
+	// from.
+	Libraries []*v1alpha1.ClusterTemplateLibrary
+	// Status, when set, is set on each object stamped in place, as the
+	// controller reconciling it would, before outputs are read from it.
+	Status map[string]interface{}
+
+	templates map[string]templates.Template
+	stamped   []*unstructured.Unstructured
+}
+
+func NewOfflineRepository(apiTemplates []client.Object) (*OfflineRepository, error) {
+	repo := &OfflineRepository{templates: map[string]templates.Template{}}
+	for _, apiTemplate := range apiTemplates {
+		template, err := templates.NewModelFromAPI(apiTemplate)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range TemplateKeys(template.GetKind(), apiTemplate) {
+			repo.templates[key] = template
+		}
+	}
+	return repo, nil
+}
+
+// TemplateKeys returns the keys, kind/name, that templateRefs find
+// apiTemplate of kind by: its name and, when it is labelled as a version of
+// a named template, that name and version, written as in the reference's
+// DisplayName.
+func TemplateKeys(kind string, apiTemplate client.Object) []string {
+	keys := []string{kind + "/" + apiTemplate.GetName()}
+	labels := apiTemplate.GetLabels()
+	name, version := labels[v1alpha1.TemplateNameLabel], labels[v1alpha1.TemplateVersionLabel]
+	if name != "" && version != "" {
+		keys = append(keys, kind+"/"+name+"@"+version)
+	}
+	return keys
+}
+
+func (r *OfflineRepository) getTemplate(kind, name string) (templates.Template, error) {
+	template, ok := r.templates[kind+"/"+name]
+	if !ok {
+		return nil, kerrors.NewNotFound(v1alpha1.SchemeGroupVersion.WithResource(kind).GroupResource(), name)
+	}
+	return template, nil
+}
+
+func (r *OfflineRepository) GetClusterTemplate(_ context.Context, reference v1alpha1.ClusterTemplateReference) (templates.Template, error) {
+	return r.getTemplate(reference.Kind, reference.DisplayName())
+}
+
+func (r *OfflineRepository) GetDeliveryClusterTemplate(_ context.Context, reference v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error) {
+	return r.getTemplate(reference.Kind, reference.DisplayName())
+}
+
+// Stamped returns the objects stamped so far, in order.
+func (r *OfflineRepository) Stamped() []*unstructured.Unstructured {
+	return r.stamped
+}
+
+func (r *OfflineRepository) GetTemplateLibrary(_ context.Context, name string) (*v1alpha1.ClusterTemplateLibrary, error) {
+	for _, library := range r.Libraries {
+		if library.Name == name {
+			return library, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *OfflineRepository) EnsureObjectExistsOnCluster(_ context.Context, obj *unstructured.Unstructured, _ bool) error {
+	r.stamped = append(r.stamped, obj.DeepCopy())
+	if r.Status == nil {
+		return nil
+	}
+
+	// Round trip the status so that it holds the types decoded objects do.
+	raw, err := json.Marshal(r.Status)
+	if err != nil {
+		return fmt.Errorf("marshal status: %w", err)
+	}
+	var status map[string]interface{}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return fmt.Errorf("unmarshal status: %w", err)
+	}
+	obj.Object["status"] = status
+	return nil
+}
+
+func (r *OfflineRepository) EnsureImmutableObjectExistsOnCluster(_ context.Context, obj *unstructured.Unstructured) error {
+	r.stamped = append(r.stamped, obj.DeepCopy())
+	return nil
+}
+
+func (r *OfflineRepository) GetUnstructured(_ context.Context, obj *unstructured.Unstructured) error {
+	return kerrors.NewNotFound(schema.GroupResource{Group: obj.GroupVersionKind().Group, Resource: obj.GetKind()}, obj.GetName())
+}
+
+func (r *OfflineRepository) ListUnstructured(context.Context, *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	return nil, nil
+}
+
+func (r *OfflineRepository) DeleteUnstructured(context.Context, *unstructured.Unstructured) error {
+	return nil
+}
+
+// workloadResourceRealizer passes placeholders on for the outputs of
+// objects that have not run.
+type workloadResourceRealizer struct {
+	workloadrealizer.ResourceRealizer
+}
+
+func (r workloadResourceRealizer) Do(ctx context.Context, resource *v1alpha1.SupplyChainResource, supplyChainName string, outputs workloadrealizer.Outputs) (*templates.Output, error) {
+	output, err := r.ResourceRealizer.Do(ctx, resource, supplyChainName, outputs)
+	if errors.As(err, &workloadrealizer.RetrieveOutputError{}) || errors.As(err, &workloadrealizer.JobRunningError{}) {
+		return placeholderOutput(resource.Name, resource.TemplateRef.Kind), nil
+	}
+	return output, err
+}
+
+type deliverableResourceRealizer struct {
+	deliverablerealizer.ResourceRealizer
+}
+
+func (r deliverableResourceRealizer) Do(ctx context.Context, resource *v1alpha1.ClusterDeliveryResource, deliveryName string, outputs deliverablerealizer.Outputs) (*templates.Output, error) {
+	output, err := r.ResourceRealizer.Do(ctx, resource, deliveryName, outputs)
+	if errors.As(err, &deliverablerealizer.RetrieveOutputError{}) || errors.As(err, &deliverablerealizer.JobRunningError{}) {
+		return placeholderOutput(resource.Name, resource.TemplateRef.Kind), nil
+	}
+	return output, err
+}
+
+func placeholderOutput(resourceName, templateKind string) *templates.Output {
+	placeholder := func(output string) string {
+		return fmt.Sprintf("<%s.%s>", resourceName, output)
+	}
+
+	switch templateKind {
+	case "ClusterSourceTemplate":
+		return &templates.Output{Source: &templates.Source{URL: placeholder("url"), Revision: placeholder("revision")}}
+	case "ClusterImageTemplate":
+		return &templates.Output{Image: placeholder("image")}
+	case "ClusterConfigTemplate":
+		return &templates.Output{Config: placeholder("config")}
+	default:
+		return &templates.Output{}
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/render"
+)
+
+const templatesYAML = `
+# templates used by the blueprints below
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterSourceTemplate
+metadata:
+  name: git-source
+spec:
+  urlPath: .status.artifact.url
+  revisionPath: .spec.ref.branch
+  template:
+    apiVersion: source.toolkit.fluxcd.io/v1beta1
+    kind: GitRepository
+    metadata:
+      name: $(workload.metadata.name)$
+    spec:
+      url: $(workload.spec.source.git.url)$
+      ref: $(workload.spec.source.git.ref)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: deploy
+spec:
+  template:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: $(workload.metadata.name)$-deploy
+    data:
+      url: $(sources.source.url)$
+      revision: $(sources.source.revision)$
+`
+
+const supplyChainYAML = `
+apiVersion: carto.run/v1alpha1
+kind: ClusterSupplyChain
+metadata:
+  name: source-to-deploy
+spec:
+  selector:
+    workload-type: web
+  resources:
+    - name: source-provider
+      templateRef:
+        kind: ClusterSourceTemplate
+        name: git-source
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: deploy
+      sources:
+        - name: source
+          resource: source-provider
+`
+
+const workloadYAML = `
+apiVersion: carto.run/v1alpha1
+kind: Workload
+metadata:
+  name: app
+  labels:
+    workload-type: web
+spec:
+  source:
+    git:
+      url: https://github.com/example/app
+      ref:
+        branch: main
+`
+
+var _ = Describe("Render", func() {
+	var manifests *render.Manifests
+
+	load := func(docs ...string) error {
+		for _, doc := range docs {
+			if err := manifests.Load(strings.NewReader(doc)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	BeforeEach(func() {
+		manifests = &render.Manifests{}
+	})
+
+	It("stamps the objects of a workload with the supply chain selecting it", func() {
+		Expect(load(templatesYAML, supplyChainYAML, workloadYAML)).To(Succeed())
+
+		stamped, err := render.Render(context.Background(), manifests)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stamped).To(HaveLen(2))
+
+		Expect(stamped[0].GetKind()).To(Equal("GitRepository"))
+		Expect(stamped[0].GetNamespace()).To(Equal("default"))
+		Expect(stamped[0].GetName()).To(Equal("app"))
+		Expect(stamped[0].GetLabels()).To(HaveKeyWithValue("carto.run/resource-name", "source-provider"))
+		Expect(stamped[0].Object["spec"]).To(HaveKeyWithValue("url", "https://github.com/example/app"))
+
+		Expect(stamped[1].GetName()).To(Equal("app-deploy"))
+		Expect(stamped[1].Object["data"]).To(Equal(map[string]interface{}{
+			"url":      "<source-provider.url>",
+			"revision": "<source-provider.revision>",
+		}))
+	})
+
+	It("accepts blueprints written as v1alpha2", func() {
+		Expect(load(templatesYAML, workloadYAML, `
+apiVersion: carto.run/v1alpha2
+kind: ClusterSupplyChain
+metadata:
+  name: source-to-deploy
+spec:
+  selector:
+    matchLabels:
+      workload-type: web
+  resources:
+    - name: source-provider
+      templateRef:
+        kind: ClusterSourceTemplate
+        name: git-source
+`)).To(Succeed())
+
+		stamped, err := render.Render(context.Background(), manifests)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stamped).To(HaveLen(1))
+		Expect(stamped[0].GetKind()).To(Equal("GitRepository"))
+	})
+
+	It("stamps the objects of a deliverable with the delivery selecting it", func() {
+		Expect(load(`
+apiVersion: carto.run/v1alpha1
+kind: ClusterSourceTemplate
+metadata:
+  name: config-source
+spec:
+  urlPath: .status.artifact.url
+  template:
+    apiVersion: source.toolkit.fluxcd.io/v1beta1
+    kind: GitRepository
+    metadata:
+      name: $(deliverable.metadata.name)$-config
+    spec:
+      url: $(deliverable.spec.source.git.url)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterTemplate
+metadata:
+  name: app-deploy
+spec:
+  template:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: $(deliverable.metadata.name)$-deploy
+    data:
+      url: $(sources.config.url)$
+---
+apiVersion: carto.run/v1alpha1
+kind: ClusterDelivery
+metadata:
+  name: delivery
+spec:
+  selector:
+    app: web
+  resources:
+    - name: config-provider
+      templateRef:
+        kind: ClusterSourceTemplate
+        name: config-source
+    - name: deployer
+      templateRef:
+        kind: ClusterTemplate
+        name: app-deploy
+      sources:
+        - name: config
+          resource: config-provider
+---
+apiVersion: carto.run/v1alpha1
+kind: Deliverable
+metadata:
+  name: app
+  namespace: prod
+  labels:
+    app: web
+spec:
+  source:
+    git:
+      url: https://github.com/example/app-config
+      ref:
+        branch: main
+`)).To(Succeed())
+
+		stamped, err := render.Render(context.Background(), manifests)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stamped).To(HaveLen(2))
+		Expect(stamped[1].GetNamespace()).To(Equal("prod"))
+		Expect(stamped[1].GetLabels()).To(HaveKeyWithValue("carto.run/deliverable-name", "app"))
+		Expect(stamped[1].Object["data"]).To(HaveKeyWithValue("url", "<config-provider.url>"))
+	})
+
+	It("returns an error when no supply chain selects a workload", func() {
+		Expect(load(templatesYAML, workloadYAML)).To(Succeed())
+
+		_, err := render.Render(context.Background(), manifests)
+		Expect(err).To(MatchError("workload 'app' is selected by 0 supply chains, expected 1"))
+	})
+
+	It("returns an error when a template is missing", func() {
+		Expect(load(supplyChainYAML, workloadYAML)).To(Succeed())
+
+		_, err := render.Render(context.Background(), manifests)
+		Expect(err).To(MatchError(ContainSubstring("render workload 'app' with ClusterSupplyChain 'source-to-deploy'")))
+		Expect(err).To(MatchError(ContainSubstring("git-source")))
+	})
+
+	It("rejects documents of other kinds", func() {
+		err := load(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+`)
+		Expect(err).To(MatchError(ContainSubstring("decode document")))
+	})
+})
@@ -0,0 +1,199 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Status summarizes how an owner renders with candidate manifests compared
+// to the baseline.
+type Status string
+
+const (
+	Unchanged Status = "unchanged"
+	Changed   Status = "changed"
+	Failed    Status = "failed"
+	Fixed     Status = "fixed"
+)
+
+// ChangeType is how a stamped object differs between renders.
+type ChangeType string
+
+const (
+	Added    ChangeType = "added"
+	Removed  ChangeType = "removed"
+	Modified ChangeType = "modified"
+)
+
+// Change is a stamped object that differs between the baseline and the
+// candidate render of an owner.
+type Change struct {
+	Type      ChangeType
+	Kind      string
+	Namespace string
+	Name      string
+	// Diff of the baseline and candidate object, for modified objects.
+	Diff string
+}
+
+// Simulation is the outcome of rendering one workload or deliverable with
+// the baseline and the candidate manifests.
+type Simulation struct {
+	Kind      string
+	Namespace string
+	Name      string
+
+	BaselineErr  error
+	CandidateErr error
+	Changes      []Change
+}
+
+// Status of the candidate render.
+func (s Simulation) Status() Status {
+	switch {
+	case s.CandidateErr != nil:
+		return Failed
+	case s.BaselineErr != nil:
+		return Fixed
+	case len(s.Changes) > 0:
+		return Changed
+	default:
+		return Unchanged
+	}
+}
+
+// Simulate renders every workload and deliverable in owners with the
+// blueprints and templates of the baseline, then of the candidate, and
+// compares the objects stamped. An owner failing to render does not stop the
+// others; its error is part of its simulation. Owners in baseline and
+// candidate are ignored.
+func Simulate(ctx context.Context, baseline, candidate, owners *Manifests) ([]Simulation, error) {
+	baselineRepo, err := NewOfflineRepository(baseline.Templates)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	candidateRepo, err := NewOfflineRepository(candidate.Templates)
+	if err != nil {
+		return nil, fmt.Errorf("candidate: %w", err)
+	}
+
+	var simulations []Simulation
+	for _, workload := range owners.Workloads {
+		before, baselineErr := renderWorkload(ctx, baselineRepo, baseline.SupplyChains, workload.DeepCopy())
+		after, candidateErr := renderWorkload(ctx, candidateRepo, candidate.SupplyChains, workload.DeepCopy())
+		simulations = append(simulations, simulation("Workload", workload.Namespace, workload.Name, before, after, baselineErr, candidateErr))
+	}
+	for _, deliverable := range owners.Deliverables {
+		before, baselineErr := renderDeliverable(ctx, baselineRepo, baseline.Deliveries, deliverable.DeepCopy())
+		after, candidateErr := renderDeliverable(ctx, candidateRepo, candidate.Deliveries, deliverable.DeepCopy())
+		simulations = append(simulations, simulation("Deliverable", deliverable.Namespace, deliverable.Name, before, after, baselineErr, candidateErr))
+	}
+	return simulations, nil
+}
+
+func simulation(kind, namespace, name string, before, after []*unstructured.Unstructured, baselineErr, candidateErr error) Simulation {
+	if namespace == "" {
+		namespace = "default"
+	}
+	s := Simulation{Kind: kind, Namespace: namespace, Name: name, BaselineErr: baselineErr, CandidateErr: candidateErr}
+	if baselineErr == nil && candidateErr == nil {
+		s.Changes = compare(before, after)
+	}
+	return s
+}
+
+// compare lists the objects added or modified, in the order the candidate
+// stamped them, then those removed.
+func compare(before, after []*unstructured.Unstructured) []Change {
+	key := func(obj *unstructured.Unstructured) string {
+		return strings.Join([]string{obj.GetAPIVersion(), obj.GetKind(), obj.GetNamespace(), obj.GetName()}, "/")
+	}
+	change := func(changeType ChangeType, obj *unstructured.Unstructured) Change {
+		return Change{Type: changeType, Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	}
+
+	baseline := map[string]*unstructured.Unstructured{}
+	for _, obj := range before {
+		baseline[key(obj)] = obj
+	}
+
+	var changes []Change
+	stamped := map[string]bool{}
+	for _, obj := range after {
+		stamped[key(obj)] = true
+		previous, ok := baseline[key(obj)]
+		if !ok {
+			changes = append(changes, change(Added, obj))
+			continue
+		}
+		if diff := cmp.Diff(previous.Object, obj.Object); diff != "" {
+			modified := change(Modified, obj)
+			modified.Diff = diff
+			changes = append(changes, modified)
+		}
+	}
+	for _, obj := range before {
+		if !stamped[key(obj)] {
+			changes = append(changes, change(Removed, obj))
+		}
+	}
+	return changes
+}
+
+// WriteReport writes the simulations to out as text: each owner, its
+// status and the objects that changed, then a count of owners by status.
+func WriteReport(out io.Writer, simulations []Simulation) error {
+	b := &strings.Builder{}
+
+	counts := map[Status]int{}
+	for _, s := range simulations {
+		status := s.Status()
+		counts[status]++
+
+		fmt.Fprintf(b, "%s %s/%s: %s\n", s.Kind, s.Namespace, s.Name, status)
+		switch status {
+		case Failed:
+			fmt.Fprintf(b, "  error: %s\n", s.CandidateErr)
+		case Fixed:
+			fmt.Fprintf(b, "  baseline error: %s\n", s.BaselineErr)
+		}
+		for _, c := range s.Changes {
+			fmt.Fprintf(b, "  %s %s %s/%s\n", changeSymbols[c.Type], c.Kind, c.Namespace, c.Name)
+			for _, line := range strings.Split(c.Diff, "\n") {
+				if line != "" {
+					fmt.Fprintf(b, "      %s\n", line)
+				}
+			}
+		}
+	}
+	fmt.Fprintf(b, "\n%d rendered: %d changed, %d unchanged, %d failed, %d fixed\n",
+		len(simulations), counts[Changed], counts[Unchanged], counts[Failed], counts[Fixed])
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+var changeSymbols = map[ChangeType]string{
+	Added:    "+",
+	Removed:  "-",
+	Modified: "~",
+}
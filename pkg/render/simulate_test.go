@@ -0,0 +1,118 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/render"
+)
+
+var _ = Describe("Simulate", func() {
+	var baseline, candidate, owners *render.Manifests
+
+	BeforeEach(func() {
+		baseline, candidate, owners = &render.Manifests{}, &render.Manifests{}, &render.Manifests{}
+		Expect(baseline.Load(strings.NewReader(templatesYAML + "---" + supplyChainYAML))).To(Succeed())
+		Expect(owners.Load(strings.NewReader(`
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: carto.run/v1alpha1
+    kind: Workload
+    metadata:
+      name: app
+      namespace: dev
+      labels:
+        workload-type: web
+    spec:
+      source:
+        git:
+          url: https://github.com/example/app
+  - apiVersion: carto.run/v1alpha1
+    kind: Workload
+    metadata:
+      name: other
+      namespace: dev
+      labels:
+        workload-type: batch
+    spec:
+      source:
+        git:
+          url: https://github.com/example/other
+`))).To(Succeed())
+	})
+
+	It("reports the stamped objects that differ and the owners that fail to render", func() {
+		Expect(candidate.Load(strings.NewReader(strings.Replace(templatesYAML, "-deploy", "-run", 1) + "---" + supplyChainYAML))).To(Succeed())
+
+		simulations, err := render.Simulate(context.Background(), baseline, candidate, owners)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(simulations).To(HaveLen(2))
+
+		Expect(simulations[0].Name).To(Equal("app"))
+		Expect(simulations[0].Status()).To(Equal(render.Changed))
+		Expect(simulations[0].Changes).To(HaveLen(2))
+		Expect(simulations[0].Changes[0].Type).To(Equal(render.Added))
+		Expect(simulations[0].Changes[0].Name).To(Equal("app-run"))
+		Expect(simulations[0].Changes[1].Type).To(Equal(render.Removed))
+		Expect(simulations[0].Changes[1].Name).To(Equal("app-deploy"))
+
+		Expect(simulations[1].Name).To(Equal("other"))
+		Expect(simulations[1].Status()).To(Equal(render.Failed))
+		Expect(simulations[1].CandidateErr).To(MatchError("workload 'other' is selected by 0 supply chains, expected 1"))
+	})
+
+	It("diffs the objects stamped by both", func() {
+		Expect(candidate.Load(strings.NewReader(strings.Replace(templatesYAML, "revision: $(sources.source.revision)$", "revision: fixed", 1) + "---" + supplyChainYAML))).To(Succeed())
+
+		simulations, err := render.Simulate(context.Background(), baseline, candidate, owners)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(simulations[0].Changes).To(HaveLen(1))
+		Expect(simulations[0].Changes[0].Type).To(Equal(render.Modified))
+		Expect(simulations[0].Changes[0].Diff).To(ContainSubstring(`"fixed"`))
+	})
+
+	It("reports owners the candidate fixes", func() {
+		Expect(candidate.Load(strings.NewReader(templatesYAML + "---" + strings.Replace(supplyChainYAML, "workload-type: web", "workload-type: batch", 1)))).To(Succeed())
+
+		simulations, err := render.Simulate(context.Background(), baseline, candidate, owners)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(simulations[0].Status()).To(Equal(render.Failed))
+		Expect(simulations[1].Status()).To(Equal(render.Fixed))
+		Expect(simulations[1].BaselineErr).To(HaveOccurred())
+	})
+
+	It("writes a report", func() {
+		Expect(candidate.Load(strings.NewReader(templatesYAML + "---" + supplyChainYAML))).To(Succeed())
+
+		simulations, err := render.Simulate(context.Background(), baseline, candidate, owners)
+		Expect(err).NotTo(HaveOccurred())
+
+		out := &bytes.Buffer{}
+		Expect(render.WriteReport(out, simulations)).To(Succeed())
+		Expect(out.String()).To(Equal(`Workload dev/app: unchanged
+Workload dev/other: failed
+  error: workload 'other' is selected by 0 supply chains, expected 1
+
+2 rendered: 0 changed, 1 unchanged, 1 failed, 0 fixed
+`))
+	})
+})
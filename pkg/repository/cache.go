@@ -15,9 +15,11 @@
 package repository
 
 import (
+	"container/list"
 	"fmt"
-	"reflect"
+	"sync"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -30,35 +32,129 @@ type Logger interface {
 type RepoCache interface {
 	Set(submitted, persisted *unstructured.Unstructured)
 	UnchangedSinceCached(local *unstructured.Unstructured, remote []*unstructured.Unstructured) *unstructured.Unstructured
+	SubmittedUnchanged(submitted *unstructured.Unstructured) bool
+	EvictOwned(ownerLabels map[string]string)
 }
 
+// DefaultCacheSize is the most objects a cache remembers. Once it is full,
+// the objects least recently looked up are forgotten first.
+const DefaultCacheSize = 10000
+
 func NewCache(l Logger) RepoCache {
+	return NewCacheOfSize(l, DefaultCacheSize)
+}
+
+// NewCacheOfSize returns a cache that remembers at most size objects.
+func NewCacheOfSize(l Logger, size int) RepoCache {
 	return &cache{
-		logger:         l,
-		submittedCache: make(map[string]unstructured.Unstructured),
-		persistedCache: make(map[string]unstructured.Unstructured),
+		logger:  l,
+		size:    size,
+		entries: map[string]*list.Element{},
+		recent:  list.New(),
 	}
 }
 
 type cache struct {
-	logger         Logger
-	submittedCache map[string]unstructured.Unstructured
-	persistedCache map[string]unstructured.Unstructured
+	logger Logger
+	size   int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// recent holds the entries, most recently used first.
+	recent *list.List
+	// changes counts the entries set and removed, so that a persistent cache
+	// saves only when it changed.
+	changes uint64
+}
+
+type cacheEntry struct {
+	key       string
+	submitted unstructured.Unstructured
+	persisted unstructured.Unstructured
 }
 
 func (c *cache) Set(submitted, persisted *unstructured.Unstructured) {
 	key := getKey(submitted)
-	c.submittedCache[key] = *submitted
-	c.persistedCache[key] = *persisted
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.submitted = *submitted
+		entry.persisted = *persisted
+		c.recent.MoveToFront(element)
+		c.changes++
+		return
+	}
+
+	c.changes++
+	c.entries[key] = c.recent.PushFront(&cacheEntry{key: key, submitted: *submitted, persisted: *persisted})
+	for c.recent.Len() > c.size {
+		oldest := c.recent.Back()
+		c.remove(oldest)
+		c.logger.Info("evicted least recently used object", "key", oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// EvictOwned forgets the objects submitted with all of ownerLabels: those
+// stamped for an owner, once it no longer exists.
+func (c *cache) EvictOwned(ownerLabels map[string]string) {
+	if len(ownerLabels) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	evicted := 0
+	for _, element := range c.entries {
+		if hasLabels(element.Value.(*cacheEntry).submitted.GetLabels(), ownerLabels) {
+			c.remove(element)
+			evicted++
+		}
+	}
+	if evicted > 0 {
+		c.logger.Info("evicted objects of deleted owner", "owner", ownerLabels, "count", evicted)
+	}
+}
+
+// lookup returns the entry for key, marking it as the most recently used.
+// The caller holds c.mu.
+func (c *cache) lookup(key string) (*cacheEntry, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.recent.MoveToFront(element)
+	return element.Value.(*cacheEntry), true
+}
+
+// remove forgets the entry of element. The caller holds c.mu.
+func (c *cache) remove(element *list.Element) {
+	c.recent.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+	c.changes++
+}
+
+func hasLabels(labels, wanted map[string]string) bool {
+	for key, value := range wanted {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
 }
 
 func (c *cache) UnchangedSinceCached(submitted *unstructured.Unstructured, existingList []*unstructured.Unstructured) *unstructured.Unstructured {
 	key := getKey(submitted)
 	c.logger.Info("checking for changes since cached", "key", key)
-	submittedCached, submittedFoundInCache := c.submittedCache[key]
-	submittedUnchanged := submittedFoundInCache && reflect.DeepEqual(submittedCached, *submitted)
 
-	persistedCached := c.getPersistedCached(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, submittedFoundInCache := c.lookup(key)
+	submittedUnchanged := submittedFoundInCache && semanticEqual(entry.submitted.Object, submitted.Object)
 
 	if submittedUnchanged {
 		c.logger.Info("no changes since last submission, checking existing objects on apiserver", "key", key)
@@ -79,13 +175,13 @@ func (c *cache) UnchangedSinceCached(submitted *unstructured.Unstructured, exist
 			continue
 		}
 
-		persistedCachedSpec, ok := persistedCached.Object["spec"]
+		persistedCachedSpec, ok := entry.persisted.Object["spec"]
 		if !ok {
 			c.logger.Info("persisted object in cache has no spec", "key", key)
 			continue
 		}
 
-		sameSame := reflect.DeepEqual(existingSpec, persistedCachedSpec)
+		sameSame := semanticEqual(existingSpec, persistedCachedSpec)
 		if sameSame {
 			c.logger.Info("hit: persisted object in cache matches spec on apiserver", "key", key)
 			return existing
@@ -99,6 +195,56 @@ func (c *cache) UnchangedSinceCached(submitted *unstructured.Unstructured, exist
 	return nil
 }
 
+// SubmittedUnchanged reports whether submitted is what was last submitted
+// for its object.
+func (c *cache) SubmittedUnchanged(submitted *unstructured.Unstructured) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(getKey(submitted))
+	return ok && semanticEqual(entry.submitted.Object, submitted.Object)
+}
+
+// semanticEqual compares objects as the apiserver sees them: numbers by
+// value, as objects read from the apiserver hold whole numbers as int64
+// where stamped ones may hold float64, and fields that are null, or an empty
+// map or list, as if they were not set, as serializing them drops them.
+func semanticEqual(a, b interface{}) bool {
+	return equality.Semantic.DeepEqual(normalize(a), normalize(b))
+}
+
+// normalize returns v with its numbers as float64 and without its null or
+// empty fields. Empty maps and lists are normalized to nil.
+func normalize(v interface{}) interface{} {
+	if n, ok := number(v); ok {
+		return n
+	}
+
+	switch typed := v.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(typed))
+		for key, value := range typed {
+			if value = normalize(value); value != nil {
+				normalized[key] = value
+			}
+		}
+		if len(normalized) == 0 {
+			return nil
+		}
+		return normalized
+	case []interface{}:
+		if len(typed) == 0 {
+			return nil
+		}
+		normalized := make([]interface{}, len(typed))
+		for i, item := range typed {
+			normalized[i] = normalize(item)
+		}
+		return normalized
+	}
+	return v
+}
+
 func getKey(obj *unstructured.Unstructured) string {
 	// todo: probably should hash object for key
 	kind := obj.GetObjectKind().GroupVersionKind().Kind
@@ -111,8 +257,3 @@ func getKey(obj *unstructured.Unstructured) string {
 	ns := obj.GetNamespace()
 	return fmt.Sprintf("%s:%s:%s", ns, kind, name)
 }
-
-func (c *cache) getPersistedCached(key string) *unstructured.Unstructured {
-	persisted := c.persistedCache[key]
-	return &persisted
-}
@@ -113,6 +113,27 @@ var _ = Describe("Cache", func() {
 							})
 						})
 
+						Context("when the existing object spec differs from the cached one only in how it is serialized", func() {
+							BeforeEach(func() {
+								existingObjsOnAPIServer[0].UnstructuredContent()["spec"] = map[string]interface{}{
+									"replicas": int64(2),
+									"ports":    []interface{}{int64(8080)},
+								}
+								persisted.UnstructuredContent()["spec"] = map[string]interface{}{
+									"replicas":  float64(2),
+									"ports":     []interface{}{float64(8080)},
+									"selector":  map[string]interface{}{},
+									"resources": nil,
+									"volumes":   []interface{}{},
+								}
+								cache.Set(submitted, persisted)
+							})
+
+							It("is true", func() {
+								Expect(cache.UnchangedSinceCached(submitted, existingObjsOnAPIServer)).ToNot(BeNil())
+							})
+						})
+
 						Context("when the existing object spec differs from the cached submitted object spec", func() {
 							BeforeEach(func() {
 								persisted.UnstructuredContent()["spec"] = map[string]interface{}{"oh-wait": "this-spec-is-different"}
@@ -151,4 +172,96 @@ var _ = Describe("Cache", func() {
 			})
 		})
 	})
+
+	Describe("SubmittedUnchanged", func() {
+		It("is false when the submitted object is not present in the cache", func() {
+			Expect(cache.SubmittedUnchanged(submitted)).To(BeFalse())
+		})
+
+		Context("when the submitted object is in the cache", func() {
+			BeforeEach(func() {
+				cache.Set(submitted, persisted)
+			})
+
+			It("is true when the submitted object is the same", func() {
+				Expect(cache.SubmittedUnchanged(submitted.DeepCopy())).To(BeTrue())
+			})
+
+			It("is true when the submitted object only differs in how it is serialized", func() {
+				submitted.UnstructuredContent()["spec"] = map[string]interface{}{"replicas": int64(2)}
+				cache.Set(submitted, persisted)
+
+				newSubmission := submitted.DeepCopy()
+				newSubmission.UnstructuredContent()["spec"] = map[string]interface{}{"replicas": float64(2), "template": map[string]interface{}{}}
+				Expect(cache.SubmittedUnchanged(newSubmission)).To(BeTrue())
+			})
+
+			It("is false when the submitted object differs", func() {
+				newSubmission := submitted.DeepCopy()
+				newSubmission.SetLabels(map[string]string{"now-with": "funky-labels"})
+				Expect(cache.SubmittedUnchanged(newSubmission)).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("size", func() {
+		named := func(name string) *unstructured.Unstructured {
+			obj := submitted.DeepCopy()
+			obj.SetName(name)
+			return obj
+		}
+
+		It("forgets the least recently looked up objects once full", func() {
+			cache = repository.NewCacheOfSize(fakeLogger, 2)
+			cache.Set(named("first"), persisted)
+			cache.Set(named("second"), persisted)
+			Expect(cache.SubmittedUnchanged(named("first"))).To(BeTrue())
+
+			cache.Set(named("third"), persisted)
+
+			Expect(cache.SubmittedUnchanged(named("first"))).To(BeTrue())
+			Expect(cache.SubmittedUnchanged(named("second"))).To(BeFalse())
+			Expect(cache.SubmittedUnchanged(named("third"))).To(BeTrue())
+		})
+	})
+
+	Describe("EvictOwned", func() {
+		var ownedByApp, ownedByOther *unstructured.Unstructured
+
+		BeforeEach(func() {
+			ownedByApp = submitted.DeepCopy()
+			ownedByApp.SetName("app-config")
+			ownedByApp.SetLabels(map[string]string{
+				"carto.run/workload-name":      "app",
+				"carto.run/workload-namespace": "dev",
+				"carto.run/resource-name":      "config",
+			})
+			ownedByOther = ownedByApp.DeepCopy()
+			ownedByOther.SetName("other-config")
+			ownedByOther.SetLabels(map[string]string{
+				"carto.run/workload-name":      "app",
+				"carto.run/workload-namespace": "prod",
+				"carto.run/resource-name":      "config",
+			})
+			cache.Set(ownedByApp, persisted)
+			cache.Set(ownedByOther, persisted)
+		})
+
+		It("forgets the objects submitted with all of the owner's labels", func() {
+			cache.EvictOwned(map[string]string{
+				"carto.run/workload-name":      "app",
+				"carto.run/workload-namespace": "dev",
+			})
+
+			Expect(cache.SubmittedUnchanged(ownedByApp)).To(BeFalse())
+			Expect(cache.SubmittedUnchanged(ownedByOther)).To(BeTrue())
+		})
+
+		It("forgets nothing for no labels", func() {
+			cache.EvictOwned(nil)
+
+			Expect(cache.SubmittedUnchanged(ownedByApp)).To(BeTrue())
+			Expect(cache.SubmittedUnchanged(ownedByOther)).To(BeTrue())
+		})
+	})
 })
@@ -0,0 +1,145 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxStoredBytes bounds the compressed entries saved to a ConfigMap, below
+// the 1MiB the apiserver accepts. The entries least recently used are left
+// out of those that do not fit.
+const MaxStoredBytes = 900 * 1024
+
+const configMapStoreKey = "entries.json.gz"
+
+// ConfigMapStore saves the entries of each cache, gzipped JSON, to the
+// ConfigMap named after the cache, as <prefix>-<name> in the namespace of
+// prefix.
+type ConfigMapStore struct {
+	client client.Client
+	reader client.Reader
+	prefix types.NamespacedName
+}
+
+func NewConfigMapStore(c client.Client, reader client.Reader, prefix types.NamespacedName) *ConfigMapStore {
+	return &ConfigMapStore{
+		client: c,
+		reader: reader,
+		prefix: prefix,
+	}
+}
+
+func (s *ConfigMapStore) Load(ctx context.Context, name string) ([]CacheEntry, error) {
+	configMap := &corev1.ConfigMap{}
+	err := s.reader.Get(ctx, s.key(name), configMap)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get configmap '%s': %w", s.key(name), err)
+	}
+
+	data, ok := configMap.BinaryData[configMapStoreKey]
+	if !ok {
+		return nil, nil
+	}
+	entries, err := decodeEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode configmap '%s': %w", s.key(name), err)
+	}
+	return entries, nil
+}
+
+func (s *ConfigMapStore) Save(ctx context.Context, name string, entries []CacheEntry) error {
+	data, err := encodeEntries(entries)
+	if err != nil {
+		return fmt.Errorf("encode entries: %w", err)
+	}
+	for len(data) > MaxStoredBytes && len(entries) > 0 {
+		entries = entries[:len(entries)/2]
+		if data, err = encodeEntries(entries); err != nil {
+			return fmt.Errorf("encode entries: %w", err)
+		}
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = s.reader.Get(ctx, s.key(name), configMap)
+	if kerrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.key(name).Name,
+				Namespace: s.key(name).Namespace,
+			},
+			BinaryData: map[string][]byte{configMapStoreKey: data},
+		}
+		if err := s.client.Create(ctx, configMap); err != nil {
+			return fmt.Errorf("create configmap '%s': %w", s.key(name), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get configmap '%s': %w", s.key(name), err)
+	}
+
+	if configMap.BinaryData == nil {
+		configMap.BinaryData = map[string][]byte{}
+	}
+	configMap.BinaryData[configMapStoreKey] = data
+	if err := s.client.Update(ctx, configMap); err != nil {
+		return fmt.Errorf("update configmap '%s': %w", s.key(name), err)
+	}
+	return nil
+}
+
+func (s *ConfigMapStore) key(name string) types.NamespacedName {
+	return types.NamespacedName{Namespace: s.prefix.Namespace, Name: s.prefix.Name + "-" + name}
+}
+
+func encodeEntries(entries []CacheEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(writer).Encode(entries); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeEntries(data []byte) ([]CacheEntry, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var entries []CacheEntry
+	if err := json.NewDecoder(reader).Decode(&entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+var _ = Describe("ConfigMapStore", func() {
+	var (
+		ctx     context.Context
+		c       client.Client
+		store   *repository.ConfigMapStore
+		entries []repository.CacheEntry
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		store = repository.NewConfigMapStore(c, c, types.NamespacedName{Namespace: "cartographer-system", Name: "repo-cache"})
+
+		entries = []repository.CacheEntry{{
+			Submitted: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"name": "app", "namespace": "dev"},
+				"spec":     map[string]interface{}{"replicas": float64(2)},
+			},
+			PersistedSpec: map[string]interface{}{"replicas": float64(2), "paused": false},
+		}}
+	})
+
+	It("loads nothing when the configmap does not exist", func() {
+		Expect(store.Load(ctx, "workload")).To(BeEmpty())
+	})
+
+	It("saves the entries to the configmap of the cache and loads them back", func() {
+		Expect(store.Save(ctx, "workload", entries)).To(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, types.NamespacedName{Namespace: "cartographer-system", Name: "repo-cache-workload"}, configMap)).To(Succeed())
+		Expect(configMap.BinaryData).To(HaveKey("entries.json.gz"))
+
+		Expect(store.Load(ctx, "workload")).To(Equal(entries))
+		Expect(store.Load(ctx, "deliverable")).To(BeEmpty())
+	})
+
+	It("updates the configmap, keeping its other data", func() {
+		Expect(c.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "cartographer-system", Name: "repo-cache-workload"},
+			Data:       map[string]string{"note": "kept"},
+		})).To(Succeed())
+
+		Expect(store.Save(ctx, "workload", nil)).To(Succeed())
+		Expect(store.Save(ctx, "workload", entries)).To(Succeed())
+
+		Expect(store.Load(ctx, "workload")).To(Equal(entries))
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, types.NamespacedName{Namespace: "cartographer-system", Name: "repo-cache-workload"}, configMap)).To(Succeed())
+		Expect(configMap.Data).To(Equal(map[string]string{"note": "kept"}))
+	})
+
+	It("leaves out the entries least recently used when they do not fit", func() {
+		random := rand.New(rand.NewSource(1))
+		var many []repository.CacheEntry
+		for i := 0; i < 2000; i++ {
+			noise := make([]byte, 512)
+			random.Read(noise)
+			many = append(many, repository.CacheEntry{
+				Submitted:     map[string]interface{}{"metadata": map[string]interface{}{"name": fmt.Sprintf("app-%d", i)}},
+				PersistedSpec: map[string]interface{}{"noise": fmt.Sprintf("%x", noise)},
+			})
+		}
+
+		Expect(store.Save(ctx, "workload", many)).To(Succeed())
+
+		loaded, err := store.Load(ctx, "workload")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(loaded)).To(BeNumerically("<", len(many)))
+		Expect(loaded).To(Equal(many[:len(loaded)]))
+	})
+
+	It("returns an error when the configmap does not hold entries", func() {
+		Expect(c.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "cartographer-system", Name: "repo-cache-workload"},
+			BinaryData: map[string][]byte{"entries.json.gz": []byte("not gzip")},
+		})).To(Succeed())
+
+		_, err := store.Load(ctx, "workload")
+		Expect(err).To(MatchError(ContainSubstring("decode configmap 'cartographer-system/repo-cache-workload'")))
+	})
+})
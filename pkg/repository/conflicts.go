@@ -0,0 +1,213 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// FieldManager is the field manager Cartographer creates and patches
+// stamped objects as.
+const FieldManager = "cartographer"
+
+// FieldConflict is a field manager other than Cartographer that last set
+// fields a patch of a stamped object changes back.
+type FieldConflict struct {
+	Manager string   `json:"manager"`
+	Fields  []string `json:"fields"`
+}
+
+// Conflicts returns the field managers of live, other than Cartographer,
+// that own the fields of changes, in manager order.
+func Conflicts(live *unstructured.Unstructured, changes []Change) []FieldConflict {
+	owned := map[string][]string{}
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == FieldManager || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		for _, change := range changes {
+			if owns(fields, live.Object, parsePath(change.Path)) && !contains(owned[entry.Manager], change.Path) {
+				owned[entry.Manager] = append(owned[entry.Manager], change.Path)
+			}
+		}
+	}
+
+	var conflicts []FieldConflict
+	for manager, fields := range owned {
+		conflicts = append(conflicts, FieldConflict{Manager: manager, Fields: fields})
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Manager < conflicts[j].Manager })
+	return conflicts
+}
+
+// owns reports whether the managed fields set fields holds the field at
+// path in value, or any field under it.
+func owns(fields map[string]interface{}, value interface{}, path []interface{}) bool {
+	if len(path) == 0 {
+		return true
+	}
+
+	switch segment := path[0].(type) {
+	case string:
+		child, ok := fields["f:"+segment].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		m, _ := value.(map[string]interface{})
+		return owns(child, m[segment], path[1:])
+	case int:
+		list, _ := value.([]interface{})
+		if segment >= len(list) {
+			return false
+		}
+		child, ok := listItemFields(fields, list[segment], segment)
+		if !ok {
+			return false
+		}
+		return owns(child, list[segment], path[1:])
+	}
+	return false
+}
+
+// listItemFields finds the fields of item, at index in its list, among
+// fields, whether the list is keyed by fields of its items, by its values
+// or by index.
+func listItemFields(fields map[string]interface{}, item interface{}, index int) (map[string]interface{}, bool) {
+	for key, child := range fields {
+		childFields, ok := child.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(key, "k:"):
+			var itemKey map[string]interface{}
+			if err := json.Unmarshal([]byte(key[2:]), &itemKey); err != nil {
+				continue
+			}
+			itemMap, _ := item.(map[string]interface{})
+			if matchesKey(itemMap, itemKey) {
+				return childFields, true
+			}
+		case strings.HasPrefix(key, "v:"):
+			var itemValue interface{}
+			if err := json.Unmarshal([]byte(key[2:]), &itemValue); err == nil && sameValue(itemValue, item) {
+				return childFields, true
+			}
+		case key == "i:"+strconv.Itoa(index):
+			return childFields, true
+		}
+	}
+	return nil, false
+}
+
+func matchesKey(item, key map[string]interface{}) bool {
+	if item == nil {
+		return false
+	}
+	for name, value := range key {
+		if !sameValue(item[name], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePath splits a path of a Change into its field names and list
+// indexes.
+func parsePath(path string) []interface{} {
+	var segments []interface{}
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			if strings.HasPrefix(path, `["`) {
+				// quoted keys may hold ']', so read up to the closing quote.
+				quoted, err := strconv.QuotedPrefix(path[1:])
+				if err != nil {
+					return segments
+				}
+				key, _ := strconv.Unquote(quoted)
+				segments = append(segments, key)
+				path = path[1+len(quoted)+1:]
+				continue
+			}
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return segments
+			}
+			index, err := strconv.Atoi(path[1:end])
+			if err != nil {
+				return segments
+			}
+			segments = append(segments, index)
+			path = path[end+1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segments = append(segments, path[:end])
+			path = path[end:]
+		}
+	}
+	return segments
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ConflictReporter is told of the field managers each patch of a stamped
+// object takes fields back from. obj is the object as submitted.
+type ConflictReporter func(obj *unstructured.Unstructured, conflicts []FieldConflict)
+
+type conflictReporterKey struct{}
+
+// WithConflictReporter returns a context under which the repository
+// reports the field managers it takes fields back from to reporter.
+func WithConflictReporter(ctx context.Context, reporter ConflictReporter) context.Context {
+	return context.WithValue(ctx, conflictReporterKey{}, reporter)
+}
+
+// ConflictReporterFrom returns the reporter in ctx, or nil if there is
+// none.
+func ConflictReporterFrom(ctx context.Context) ConflictReporter {
+	reporter, _ := ctx.Value(conflictReporterKey{}).(ConflictReporter)
+	return reporter
+}
+
+func reportConflicts(ctx context.Context, obj *unstructured.Unstructured, conflicts []FieldConflict) {
+	reporter := ConflictReporterFrom(ctx)
+	if reporter == nil || len(conflicts) == 0 {
+		return
+	}
+	reporter(obj, conflicts)
+}
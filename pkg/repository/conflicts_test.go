@@ -0,0 +1,97 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+var _ = Describe("Conflicts", func() {
+	var live *unstructured.Unstructured
+
+	managedBy := func(manager, fields string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager:    manager,
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+
+	BeforeEach(func() {
+		live = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":   "app",
+				"labels": map[string]interface{}{"app.kubernetes.io/version": "1.0"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(5),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "app", "image": "app:debug"},
+						},
+					},
+				},
+			},
+		}}
+		live.SetManagedFields([]metav1.ManagedFieldsEntry{
+			managedBy(repository.FieldManager, `{"f:metadata":{"f:labels":{".":{},"f:app.kubernetes.io/version":{}}},"f:spec":{"f:template":{"f:spec":{"f:containers":{}}}}}`),
+			managedBy("autoscaler", `{"f:spec":{"f:replicas":{}}}`),
+			managedBy("kubectl-edit", `{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{".":{},"f:image":{}}}}}}}`),
+		})
+	})
+
+	It("names the other managers owning the changed fields", func() {
+		changes := []repository.Change{
+			{Path: "spec.replicas", From: int64(5), To: int64(1)},
+			{Path: "spec.template.spec.containers[0].image", From: "app:debug", To: "app:1.0"},
+		}
+
+		Expect(repository.Conflicts(live, changes)).To(Equal([]repository.FieldConflict{
+			{Manager: "autoscaler", Fields: []string{"spec.replicas"}},
+			{Manager: "kubectl-edit", Fields: []string{"spec.template.spec.containers[0].image"}},
+		}))
+	})
+
+	It("ignores the fields Cartographer manages", func() {
+		changes := []repository.Change{
+			{Path: `metadata.labels["app.kubernetes.io/version"]`, From: "1.0", To: "1.1"},
+		}
+
+		Expect(repository.Conflicts(live, changes)).To(BeEmpty())
+	})
+
+	It("counts a manager owning fields under a changed field", func() {
+		changes := []repository.Change{
+			{Path: "spec.template.spec.containers", To: []interface{}{}},
+		}
+
+		Expect(repository.Conflicts(live, changes)).To(Equal([]repository.FieldConflict{
+			{Manager: "kubectl-edit", Fields: []string{"spec.template.spec.containers"}},
+		}))
+	})
+
+	It("finds no conflicts without changes", func() {
+		Expect(repository.Conflicts(live, nil)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,53 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ContentHashLabel holds the hash of what was stamped on objects that are
+// created rather than updated, under a generated name. It identifies the
+// object stamped for the same inputs across controller restarts, which the
+// cache does not survive.
+const ContentHashLabel = "carto.run/content-hash"
+
+const contentHashLength = 20
+
+// contentHash hashes obj as it was stamped, before ContentHashLabel is set
+// on it.
+func contentHash(obj *unstructured.Unstructured) (string, error) {
+	content, err := json.Marshal(obj.Object)
+	if err != nil {
+		return "", fmt.Errorf("marshal stamped object: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(content))[:contentHashLength], nil
+}
+
+// latestCreated returns the most recently created of objects, or nil when
+// there are none.
+func latestCreated(objects []*unstructured.Unstructured) *unstructured.Unstructured {
+	var latest *unstructured.Unstructured
+	for _, obj := range objects {
+		if latest == nil || latest.GetCreationTimestamp().Time.Before(obj.GetCreationTimestamp().Time) {
+			latest = obj
+		}
+	}
+	return latest
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Change is a field of a stamped object that patching it changes.
+type Change struct {
+	// Path locates the field, as in spec.template.spec.containers[0].image.
+	Path string `json:"path"`
+	// From is the field's live value, or nil when it is added.
+	From interface{} `json:"from,omitempty"`
+	// To is the field's submitted value, as the apiserver stores it.
+	To interface{} `json:"to"`
+}
+
+func (c Change) String() string {
+	if c.From == nil {
+		return fmt.Sprintf("%s: added %s", c.Path, display(c.To))
+	}
+	return fmt.Sprintf("%s: %s -> %s", c.Path, display(c.From), display(c.To))
+}
+
+func display(v interface{}) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
+
+// Summary describes the changes patching obj makes, listing the first
+// limit of them.
+func Summary(obj *unstructured.Unstructured, changes []Change, limit int) string {
+	var described []string
+	for i, change := range changes {
+		if i == limit {
+			described = append(described, fmt.Sprintf("and %d more", len(changes)-limit))
+			break
+		}
+		described = append(described, change.String())
+	}
+	return fmt.Sprintf("updated %s %s/%s: %s", obj.GetKind(), obj.GetNamespace(), obj.GetName(), strings.Join(described, "; "))
+}
+
+// Diff returns the changes from live to submitted in the fields submitted
+// sets. Its status and metadata are left out, except for its labels and
+// annotations. Fields only the live object sets, such as defaults, are not
+// changes.
+func Diff(live, submitted *unstructured.Unstructured) []Change {
+	var changes []Change
+	for _, key := range sortedKeys(submitted.Object) {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			for _, field := range []string{"labels", "annotations"} {
+				to, found, _ := unstructured.NestedFieldNoCopy(submitted.Object, "metadata", field)
+				if !found {
+					continue
+				}
+				from, _, _ := unstructured.NestedFieldNoCopy(live.Object, "metadata", field)
+				changes = diffValue(changes, "metadata."+field, from, to)
+			}
+		default:
+			changes = diffValue(changes, key, live.Object[key], submitted.Object[key])
+		}
+	}
+	return changes
+}
+
+func diffValue(changes []Change, path string, from, to interface{}) []Change {
+	switch typedTo := to.(type) {
+	case map[string]interface{}:
+		typedFrom, ok := from.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(typedTo) {
+			changes = diffValue(changes, fieldPath(path, key), typedFrom[key], typedTo[key])
+		}
+		return changes
+	case []interface{}:
+		typedFrom, ok := from.([]interface{})
+		if !ok || len(typedFrom) != len(typedTo) {
+			break
+		}
+		for i := range typedTo {
+			changes = diffValue(changes, fmt.Sprintf("%s[%d]", path, i), typedFrom[i], typedTo[i])
+		}
+		return changes
+	}
+
+	if sameValue(from, to) {
+		return changes
+	}
+	return append(changes, Change{Path: path, From: from, To: to})
+}
+
+// sameValue compares numbers by value, as live objects decode whole
+// numbers as int64 where stamped ones may hold float64.
+func sameValue(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return x == y
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func fieldPath(path, key string) string {
+	if strings.ContainsAny(key, "./[]") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ChangeReporter is told of the changes each patch of a stamped object
+// makes. obj is the object as submitted.
+type ChangeReporter func(obj *unstructured.Unstructured, changes []Change)
+
+type changeReporterKey struct{}
+
+// WithChangeReporter returns a context under which the repository reports
+// the changes it patches stamped objects with to reporter.
+func WithChangeReporter(ctx context.Context, reporter ChangeReporter) context.Context {
+	return context.WithValue(ctx, changeReporterKey{}, reporter)
+}
+
+// ChangeReporterFrom returns the reporter in ctx, or nil if there is none.
+func ChangeReporterFrom(ctx context.Context) ChangeReporter {
+	reporter, _ := ctx.Value(changeReporterKey{}).(ChangeReporter)
+	return reporter
+}
+
+func reportChanges(ctx context.Context, obj *unstructured.Unstructured, changes []Change) {
+	reporter := ChangeReporterFrom(ctx)
+	if reporter == nil || len(changes) == 0 {
+		return
+	}
+	reporter(obj, changes)
+}
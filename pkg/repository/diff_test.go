@@ -0,0 +1,110 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+var _ = Describe("Diff", func() {
+	var live, submitted *unstructured.Unstructured
+
+	BeforeEach(func() {
+		live = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":            "app",
+				"resourceVersion": "42",
+				"labels":          map[string]interface{}{"app.kubernetes.io/version": "1.0"},
+			},
+			"spec": map[string]interface{}{
+				"replicas":             int64(1),
+				"revisionHistoryLimit": int64(10),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "app", "image": "app:1.0"},
+						},
+					},
+				},
+			},
+			"status": map[string]interface{}{"replicas": int64(1)},
+		}}
+		submitted = live.DeepCopy()
+		unstructured.RemoveNestedField(submitted.Object, "metadata", "resourceVersion")
+		unstructured.RemoveNestedField(submitted.Object, "spec", "revisionHistoryLimit")
+		unstructured.RemoveNestedField(submitted.Object, "status")
+	})
+
+	It("finds no changes when submitted sets the live values", func() {
+		Expect(repository.Diff(live, submitted)).To(BeEmpty())
+	})
+
+	It("compares whole numbers by value", func() {
+		Expect(unstructured.SetNestedField(submitted.Object, float64(1), "spec", "replicas")).To(Succeed())
+		Expect(repository.Diff(live, submitted)).To(BeEmpty())
+	})
+
+	It("finds the fields submitted changes and adds", func() {
+		Expect(unstructured.SetNestedField(submitted.Object, int64(3), "spec", "replicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(submitted.Object, "2.0", "metadata", "labels", "app.kubernetes.io/version")).To(Succeed())
+		Expect(unstructured.SetNestedSlice(submitted.Object, []interface{}{
+			map[string]interface{}{"name": "app", "image": "app:2.0"},
+		}, "spec", "template", "spec", "containers")).To(Succeed())
+		Expect(unstructured.SetNestedField(submitted.Object, "Recreate", "spec", "strategy", "type")).To(Succeed())
+
+		Expect(repository.Diff(live, submitted)).To(Equal([]repository.Change{
+			{Path: `metadata.labels["app.kubernetes.io/version"]`, From: "1.0", To: "2.0"},
+			{Path: "spec.replicas", From: int64(1), To: int64(3)},
+			{Path: "spec.strategy", To: map[string]interface{}{"type": "Recreate"}},
+			{Path: "spec.template.spec.containers[0].image", From: "app:1.0", To: "app:2.0"},
+		}))
+	})
+
+	It("reports a list that changes length as a whole", func() {
+		containers := []interface{}{
+			map[string]interface{}{"name": "app", "image": "app:1.0"},
+			map[string]interface{}{"name": "sidecar", "image": "proxy"},
+		}
+		Expect(unstructured.SetNestedSlice(submitted.Object, containers, "spec", "template", "spec", "containers")).To(Succeed())
+
+		changes := repository.Diff(live, submitted)
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Path).To(Equal("spec.template.spec.containers"))
+	})
+})
+
+var _ = Describe("Summary", func() {
+	It("describes the object and the first changes", func() {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind("Deployment")
+		obj.SetNamespace("team-a")
+		obj.SetName("app")
+		changes := []repository.Change{
+			{Path: "spec.replicas", From: int64(1), To: int64(3)},
+			{Path: "spec.strategy", To: map[string]interface{}{"type": "Recreate"}},
+			{Path: "spec.paused", From: false, To: true},
+		}
+
+		Expect(repository.Summary(obj, changes, 2)).To(Equal(
+			`updated Deployment team-a/app: spec.replicas: 1 -> 3; spec.strategy: added {"type":"Recreate"}; and 1 more`,
+		))
+	})
+})
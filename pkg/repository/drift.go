@@ -0,0 +1,76 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type driftReporterKey struct{}
+
+// WithDriftDetection returns a context under which the repository leaves
+// stamped objects that were changed by something else, rather than
+// patching them back, and reports how they drifted to reporter. Objects
+// whose submitted form changed are still patched.
+func WithDriftDetection(ctx context.Context, reporter ChangeReporter) context.Context {
+	return context.WithValue(ctx, driftReporterKey{}, reporter)
+}
+
+// DriftReporterFrom returns the drift reporter in ctx, or nil if drift is
+// remediated.
+func DriftReporterFrom(ctx context.Context) ChangeReporter {
+	reporter, _ := ctx.Value(driftReporterKey{}).(ChangeReporter)
+	return reporter
+}
+
+// drift returns how live differs from submitted in the fields submitted
+// sets. Differences are confirmed against submitted as the apiserver would
+// store it, so that values it defaults or normalizes, such as a quantity of
+// 0.5 stored as 500m, are not taken for drift. When nothing differs, live is
+// cached as what submitted persisted as, so that it is not compared again.
+func (r *repository) drift(ctx context.Context, live, submitted *unstructured.Unstructured) []Change {
+	changes := Diff(live, submitted)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	normalized, err := r.normalize(ctx, live, submitted)
+	if err != nil {
+		r.logger.Info("unable to normalize object, comparing it as submitted", "name", submitted.GetName(), "namespace", submitted.GetNamespace(), "kind", submitted.GetKind(), "error", err.Error())
+		return changes
+	}
+
+	changes = Diff(live, normalized)
+	if len(changes) == 0 {
+		r.rc.Set(submitted.DeepCopy(), live.DeepCopy())
+	}
+	return changes
+}
+
+// normalize returns submitted as the apiserver would store it, patched over
+// live: with the fields it defaults filled in and the values it normalizes
+// in their canonical form. The patch is a dry run; nothing is written.
+func (r *repository) normalize(ctx context.Context, live, submitted *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	trial := submitted.DeepCopy()
+	trial.SetResourceVersion(live.GetResourceVersion())
+	if err := r.cl.Patch(ctx, trial, client.MergeFrom(live), client.FieldOwner(FieldManager), client.DryRunAll); err != nil {
+		return nil, fmt.Errorf("dry run: %w", err)
+	}
+	return trial, nil
+}
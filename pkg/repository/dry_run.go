@@ -0,0 +1,80 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context under which the repository submits each
+// object it is about to create or patch as a server-side dry run first. An
+// object the apiserver, or one of its admission webhooks, rejects is then
+// never written, and is reported as a RejectedError.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// DryRunFrom reports whether objects are dry run under ctx.
+func DryRunFrom(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// RejectedError is the apiserver's rejection of the dry run of an object.
+type RejectedError struct {
+	Err error
+}
+
+func (e RejectedError) Error() string {
+	return fmt.Sprintf("rejected on dry run: %s", e.Err)
+}
+
+func (e RejectedError) Unwrap() error {
+	return e.Err
+}
+
+// dryRun submits obj as a server-side dry run, as a patch of existingObj or,
+// when there is none, as a create, when ctx asks for it. obj is left as is.
+func (r *repository) dryRun(ctx context.Context, existingObj *unstructured.Unstructured, obj *unstructured.Unstructured) error {
+	if !DryRunFrom(ctx) {
+		return nil
+	}
+
+	trial := obj.DeepCopy()
+	var err error
+	if existingObj != nil {
+		trial.SetResourceVersion(existingObj.GetResourceVersion())
+		err = r.cl.Patch(ctx, trial, client.MergeFrom(existingObj), client.FieldOwner(FieldManager), client.DryRunAll)
+	} else {
+		err = r.cl.Create(ctx, trial, client.FieldOwner(FieldManager), client.DryRunAll)
+	}
+	if err == nil {
+		return nil
+	}
+
+	// the object itself is refused, as opposed to the apiserver being
+	// unable to answer
+	if kerrors.IsInvalid(err) || kerrors.IsForbidden(err) || kerrors.IsBadRequest(err) {
+		return RejectedError{Err: err}
+	}
+	return fmt.Errorf("dry run: %w", err)
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ServiceAccountRepository returns a repository whose requests to the
+// apiserver are made as the named service account.
+type ServiceAccountRepository func(namespace, serviceAccountName string) (Repository, error)
+
+// ImpersonatingClients hands out clients that impersonate service accounts,
+// creating each client once and reusing it afterwards.
+type ImpersonatingClients struct {
+	config  *rest.Config
+	options client.Options
+
+	mu      sync.Mutex
+	clients map[string]client.Client
+}
+
+func NewImpersonatingClients(config *rest.Config, options client.Options) *ImpersonatingClients {
+	return &ImpersonatingClients{
+		config:  config,
+		options: options,
+		clients: map[string]client.Client{},
+	}
+}
+
+// For returns a client acting as the service account serviceAccountName in
+// namespace.
+func (c *ImpersonatingClients) For(namespace, serviceAccountName string) (client.Client, error) {
+	username := serviceaccount.MakeUsername(namespace, serviceAccountName)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cl, ok := c.clients[username]; ok {
+		return cl, nil
+	}
+
+	config := rest.CopyConfig(c.config)
+	config.Impersonate = rest.ImpersonationConfig{UserName: username}
+
+	cl, err := client.New(config, c.options)
+	if err != nil {
+		return nil, fmt.Errorf("new client for '%s': %w", username, err)
+	}
+
+	c.clients[username] = cl
+	return cl, nil
+}
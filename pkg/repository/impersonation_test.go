@@ -0,0 +1,86 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+var _ = Describe("ImpersonatingClients", func() {
+	var (
+		server  *httptest.Server
+		mu      sync.Mutex
+		users   []string
+		clients *repository.ImpersonatingClients
+	)
+
+	BeforeEach(func() {
+		users = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			users = append(users, r.Header.Get("Impersonate-User"))
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"some-config","namespace":"some-namespace"}}`))
+		}))
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+		clients = repository.NewImpersonatingClients(&rest.Config{Host: server.URL}, client.Options{Scheme: scheme, Mapper: mapper})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("makes requests as the service account", func() {
+		cl, err := clients.For("some-namespace", "some-service-account")
+		Expect(err).NotTo(HaveOccurred())
+
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-config", Namespace: "some-namespace"}}
+		Expect(cl.Create(context.Background(), configMap)).To(Succeed())
+
+		Expect(users).To(Equal([]string{"system:serviceaccount:some-namespace:some-service-account"}))
+	})
+
+	It("reuses the client for the same service account", func() {
+		first, err := clients.For("some-namespace", "some-service-account")
+		Expect(err).NotTo(HaveOccurred())
+		second, err := clients.For("some-namespace", "some-service-account")
+		Expect(err).NotTo(HaveOccurred())
+		other, err := clients.For("other-namespace", "some-service-account")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second).To(BeIdenticalTo(first))
+		Expect(other).NotTo(BeIdenticalTo(first))
+	})
+})
@@ -0,0 +1,39 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+var _ = Describe("IsKindNotRegistered", func() {
+	It("is true of the kinds and resources the apiserver does not serve, however wrapped", func() {
+		Expect(repository.IsKindNotRegistered(fmt.Errorf("list: %w", &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "kpack.io", Kind: "Image"}}))).To(BeTrue())
+		Expect(repository.IsKindNotRegistered(&meta.NoResourceMatchError{PartialResource: schema.GroupVersionResource{Resource: "images"}})).To(BeTrue())
+	})
+
+	It("is false of other errors", func() {
+		Expect(repository.IsKindNotRegistered(errors.New("connection refused"))).To(BeFalse())
+		Expect(repository.IsKindNotRegistered(nil)).To(BeFalse())
+	})
+})
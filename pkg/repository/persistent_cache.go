@@ -0,0 +1,181 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultPersistInterval is how often a persistent cache is saved, when it
+// changed.
+const DefaultPersistInterval = time.Minute
+
+// persistTimeout bounds the save on shutdown.
+const persistTimeout = 10 * time.Second
+
+// CacheEntry is an object a cache remembers: the object last submitted for
+// it and the spec the apiserver persisted for that submission.
+type CacheEntry struct {
+	Submitted     map[string]interface{} `json:"submitted"`
+	PersistedSpec interface{}            `json:"persistedSpec,omitempty"`
+}
+
+//counterfeiter:generate . CacheStore
+type CacheStore interface {
+	// Load returns the entries last saved under name, most recently used
+	// first, or none when nothing was saved.
+	Load(ctx context.Context, name string) ([]CacheEntry, error)
+	Save(ctx context.Context, name string, entries []CacheEntry) error
+}
+
+// PersistentCache is a cache whose entries are saved to a store while it
+// runs, and restored from it on start, so that a restarted controller does
+// not submit every object again and start a new run for every runnable.
+// Restored entries are only hints: an object is reused when the apiserver
+// still holds the spec persisted for it.
+type PersistentCache struct {
+	*cache
+	store    CacheStore
+	name     string
+	interval time.Duration
+
+	savedChanges uint64
+}
+
+// NewPersistentCache returns a cache that remembers at most size objects,
+// saved to store under name every interval when it changed.
+func NewPersistentCache(l Logger, size int, store CacheStore, name string, interval time.Duration) *PersistentCache {
+	return &PersistentCache{
+		cache:    NewCacheOfSize(l, size).(*cache),
+		store:    store,
+		name:     name,
+		interval: interval,
+	}
+}
+
+// Restore adds the entries saved to the store to those of the cache, which
+// are kept when both hold the same object.
+func (p *PersistentCache) Restore(ctx context.Context) error {
+	entries, err := p.store.Load(ctx, p.name)
+	if err != nil {
+		return err
+	}
+
+	restored := p.restore(entries)
+	p.savedChanges = p.changesSoFar()
+	p.logger.Info("restored cache", "name", p.name, "count", restored)
+	return nil
+}
+
+// Start saves the cache every interval, when it changed, until ctx is done,
+// then saves it a last time.
+func (p *PersistentCache) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.save(ctx)
+		case <-ctx.Done():
+			saveCtx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+			defer cancel()
+			p.save(saveCtx)
+			return nil
+		}
+	}
+}
+
+func (p *PersistentCache) save(ctx context.Context) {
+	changes := p.changesSoFar()
+	if changes == p.savedChanges {
+		return
+	}
+
+	entries := p.snapshot()
+	if err := p.store.Save(ctx, p.name, entries); err != nil {
+		p.logger.Info("failed to save cache", "name", p.name, "error", err.Error())
+		return
+	}
+	p.savedChanges = changes
+	p.logger.Info("saved cache", "name", p.name, "count", len(entries))
+}
+
+func (c *cache) changesSoFar() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.changes
+}
+
+// snapshot returns the entries of the cache, most recently used first.
+// Secrets are left out, so that their data is never written to the store.
+func (c *cache) snapshot() []CacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]CacheEntry, 0, c.recent.Len())
+	for element := c.recent.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*cacheEntry)
+		if isSecret(&entry.submitted) {
+			continue
+		}
+		entries = append(entries, CacheEntry{
+			Submitted:     entry.submitted.Object,
+			PersistedSpec: entry.persisted.Object["spec"],
+		})
+	}
+	return entries
+}
+
+// restore adds entries, most recently used first, behind those of the
+// cache, for as long as it has room. It returns how many were added.
+func (c *cache) restore(entries []CacheEntry) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	restored := 0
+	for _, entry := range entries {
+		if c.recent.Len() >= c.size {
+			break
+		}
+		if len(entry.Submitted) == 0 || entry.PersistedSpec == nil {
+			continue
+		}
+
+		submitted := unstructured.Unstructured{Object: entry.Submitted}
+		if isSecret(&submitted) {
+			continue
+		}
+		key := getKey(&submitted)
+		if _, ok := c.entries[key]; ok {
+			continue
+		}
+		c.entries[key] = c.recent.PushBack(&cacheEntry{
+			key:       key,
+			submitted: submitted,
+			persisted: unstructured.Unstructured{Object: map[string]interface{}{"spec": entry.PersistedSpec}},
+		})
+		restored++
+	}
+	return restored
+}
+
+func isSecret(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Secret"
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+var _ = Describe("PersistentCache", func() {
+	var (
+		ctx       context.Context
+		store     *repositoryfakes.FakeCacheStore
+		cache     *repository.PersistentCache
+		submitted *unstructured.Unstructured
+		persisted *unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		store = &repositoryfakes.FakeCacheStore{}
+		cache = repository.NewPersistentCache(&repositoryfakes.FakeLogger{}, 2, store, "workload", 10*time.Millisecond)
+
+		submitted = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "app", "namespace": "dev"},
+			"spec":       map[string]interface{}{"replicas": int64(2)},
+		}}
+		persisted = submitted.DeepCopy()
+		persisted.SetResourceVersion("42")
+		persisted.Object["spec"] = map[string]interface{}{"replicas": int64(2), "paused": false}
+	})
+
+	Describe("Restore", func() {
+		It("remembers the entries loaded from the store", func() {
+			store.LoadReturns([]repository.CacheEntry{{
+				Submitted: map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata":   map[string]interface{}{"name": "app", "namespace": "dev"},
+					"spec":       map[string]interface{}{"replicas": float64(2)},
+				},
+				PersistedSpec: map[string]interface{}{"replicas": float64(2), "paused": false},
+			}}, nil)
+
+			Expect(cache.Restore(ctx)).To(Succeed())
+			_, name := store.LoadArgsForCall(0)
+			Expect(name).To(Equal("workload"))
+
+			Expect(cache.SubmittedUnchanged(submitted)).To(BeTrue())
+			Expect(cache.UnchangedSinceCached(submitted, []*unstructured.Unstructured{persisted})).To(Equal(persisted))
+		})
+
+		It("keeps the entries set before and restores only while there is room", func() {
+			cache.Set(submitted, persisted)
+
+			other := submitted.DeepCopy()
+			other.SetName("other")
+			another := submitted.DeepCopy()
+			another.SetName("another")
+			store.LoadReturns([]repository.CacheEntry{
+				{Submitted: map[string]interface{}{"kind": "Deployment", "metadata": map[string]interface{}{"name": "app", "namespace": "dev"}}, PersistedSpec: map[string]interface{}{}},
+				{Submitted: other.Object, PersistedSpec: persisted.Object["spec"]},
+				{Submitted: another.Object, PersistedSpec: persisted.Object["spec"]},
+			}, nil)
+
+			Expect(cache.Restore(ctx)).To(Succeed())
+			Expect(cache.SubmittedUnchanged(submitted)).To(BeTrue())
+			Expect(cache.SubmittedUnchanged(other)).To(BeTrue())
+			Expect(cache.SubmittedUnchanged(another)).To(BeFalse())
+		})
+
+		It("does not restore Secrets", func() {
+			secret := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]interface{}{"name": "app-credentials", "namespace": "dev"},
+				"data":       map[string]interface{}{"password": "c2VjcmV0"},
+			}}
+			store.LoadReturns([]repository.CacheEntry{{Submitted: secret.Object, PersistedSpec: map[string]interface{}{}}}, nil)
+
+			Expect(cache.Restore(ctx)).To(Succeed())
+			Expect(cache.SubmittedUnchanged(secret)).To(BeFalse())
+		})
+
+		It("returns the error of the store", func() {
+			store.LoadReturns(nil, errors.New("apiserver unavailable"))
+			Expect(cache.Restore(ctx)).To(MatchError("apiserver unavailable"))
+		})
+	})
+
+	Describe("Start", func() {
+		var cancel context.CancelFunc
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(ctx)
+			started := cache
+			go func() {
+				defer GinkgoRecover()
+				Expect(started.Start(ctx)).To(Succeed())
+			}()
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("saves the entries once they change, most recently used first", func() {
+			Consistently(store.SaveCallCount, "50ms").Should(Equal(0))
+
+			other := submitted.DeepCopy()
+			other.SetName("other")
+			cache.Set(submitted, persisted)
+			cache.Set(other, persisted)
+
+			Eventually(store.SaveCallCount).Should(Equal(1))
+			_, name, entries := store.SaveArgsForCall(0)
+			Expect(name).To(Equal("workload"))
+			Expect(entries).To(Equal([]repository.CacheEntry{
+				{Submitted: other.Object, PersistedSpec: persisted.Object["spec"]},
+				{Submitted: submitted.Object, PersistedSpec: persisted.Object["spec"]},
+			}))
+			Consistently(store.SaveCallCount, "50ms").Should(Equal(1))
+		})
+
+		It("never saves Secrets", func() {
+			secret := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]interface{}{"name": "app-credentials", "namespace": "dev"},
+				"data":       map[string]interface{}{"password": "c2VjcmV0"},
+			}}
+			cache.Set(submitted, persisted)
+			cache.Set(secret, secret.DeepCopy())
+
+			Eventually(store.SaveCallCount).Should(Equal(1))
+			_, _, entries := store.SaveArgsForCall(0)
+			Expect(entries).To(Equal([]repository.CacheEntry{
+				{Submitted: submitted.Object, PersistedSpec: persisted.Object["spec"]},
+			}))
+		})
+
+		It("saves again when the save failed", func() {
+			store.SaveReturnsOnCall(0, errors.New("conflict"))
+			cache.Set(submitted, persisted)
+
+			Eventually(store.SaveCallCount).Should(Equal(2))
+		})
+
+		It("saves the entries when stopped", func() {
+			cache = repository.NewPersistentCache(&repositoryfakes.FakeLogger{}, 2, store, "workload", time.Hour)
+			stopped := make(chan struct{})
+			stopCtx, stop := context.WithCancel(context.Background())
+			go func() {
+				defer GinkgoRecover()
+				defer close(stopped)
+				Expect(cache.Start(stopCtx)).To(Succeed())
+			}()
+
+			cache.Set(submitted, persisted)
+			stop()
+
+			Eventually(stopped).Should(BeClosed())
+			Expect(store.SaveCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,122 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// TargetRepository returns a repository whose requests are made to the
+// cluster described by the kubeconfig secret refers to.
+type TargetRepository func(ctx context.Context, secret v1alpha1.KubeconfigSecretReference) (Repository, error)
+
+// defaultKubeconfigKey is the key of the kubeconfig in the Secrets Cluster
+// API writes for the clusters it provisions.
+const defaultKubeconfigKey = "value"
+
+// NewTargetRepository returns a TargetRepository that reads kubeconfigs from
+// Secrets with secrets, and builds the repository for the cluster a
+// kubeconfig describes with newRepo.
+func NewTargetRepository(secrets Repository, clients *RemoteClients, newRepo func(client.Client, RepoCache) Repository) TargetRepository {
+	return func(ctx context.Context, ref v1alpha1.KubeconfigSecretReference) (Repository, error) {
+		key := ref.Key
+		if key == "" {
+			key = defaultKubeconfigKey
+		}
+
+		secret := &unstructured.Unstructured{}
+		secret.SetAPIVersion("v1")
+		secret.SetKind("Secret")
+		secret.SetNamespace(ref.Namespace)
+		secret.SetName(ref.Name)
+		if err := secrets.GetUnstructured(ctx, secret); err != nil {
+			return nil, fmt.Errorf("get secret '%s/%s': %w", ref.Namespace, ref.Name, err)
+		}
+
+		encoded, _, _ := unstructured.NestedString(secret.Object, "data", key)
+		kubeconfig, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(kubeconfig) == 0 {
+			return nil, fmt.Errorf("secret '%s/%s' has no %s", ref.Namespace, ref.Name, key)
+		}
+
+		cl, cache, err := clients.For(kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("secret '%s/%s': %w", ref.Namespace, ref.Name, err)
+		}
+		return newRepo(cl, cache), nil
+	}
+}
+
+// RemoteClients hands out clients for remote clusters, creating one client
+// per kubeconfig and reusing it until the kubeconfig changes.
+type RemoteClients struct {
+	options client.Options
+	logger  Logger
+
+	mu       sync.Mutex
+	clusters map[string]remoteCluster
+}
+
+type remoteCluster struct {
+	client client.Client
+	cache  RepoCache
+}
+
+func NewRemoteClients(options client.Options, logger Logger) *RemoteClients {
+	return &RemoteClients{
+		options:  options,
+		logger:   logger,
+		clusters: map[string]remoteCluster{},
+	}
+}
+
+// For returns a client for the cluster kubeconfig describes, along with
+// the cache of the objects submitted to that cluster.
+func (c *RemoteClients) For(kubeconfig []byte) (client.Client, RepoCache, error) {
+	sum := sha256.Sum256(kubeconfig)
+	key := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cluster, ok := c.clusters[key]; ok {
+		return cluster.client, cluster.cache, nil
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+
+	cl, err := client.New(config, c.options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new client for '%s': %w", config.Host, err)
+	}
+
+	cluster := remoteCluster{client: cl, cache: NewCache(c.logger)}
+	c.clusters[key] = cluster
+	return cluster.client, cluster.cache, nil
+}
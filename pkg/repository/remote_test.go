@@ -0,0 +1,179 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+)
+
+var _ = Describe("RemoteClients", func() {
+	var (
+		server  *httptest.Server
+		mu      sync.Mutex
+		tokens  []string
+		clients *repository.RemoteClients
+	)
+
+	kubeconfig := func(token string) []byte {
+		return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+users:
+- name: cartographer
+  user:
+    token: %s
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: cartographer
+current-context: remote
+`, server.URL, token))
+	}
+
+	BeforeEach(func() {
+		tokens = nil
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			tokens = append(tokens, r.Header.Get("Authorization"))
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"some-config","namespace":"some-namespace"}}`))
+		}))
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+		clients = repository.NewRemoteClients(client.Options{Scheme: scheme, Mapper: mapper}, &repositoryfakes.FakeLogger{})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("makes requests to the cluster with the kubeconfig's credentials", func() {
+		cl, cache, err := clients.For(kubeconfig("some-token"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cache).NotTo(BeNil())
+
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-config", Namespace: "some-namespace"}}
+		Expect(cl.Create(context.Background(), configMap)).To(Succeed())
+
+		Expect(tokens).To(Equal([]string{"Bearer some-token"}))
+	})
+
+	It("reuses the client while the kubeconfig is unchanged", func() {
+		first, firstCache, err := clients.For(kubeconfig("some-token"))
+		Expect(err).NotTo(HaveOccurred())
+		second, secondCache, err := clients.For(kubeconfig("some-token"))
+		Expect(err).NotTo(HaveOccurred())
+		rotated, rotatedCache, err := clients.For(kubeconfig("rotated-token"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second).To(BeIdenticalTo(first))
+		Expect(secondCache).To(BeIdenticalTo(firstCache))
+		Expect(rotated).NotTo(BeIdenticalTo(first))
+		Expect(rotatedCache).NotTo(BeIdenticalTo(firstCache))
+	})
+
+	It("returns an error for an invalid kubeconfig", func() {
+		_, _, err := clients.For([]byte("not: [a kubeconfig"))
+		Expect(err).To(MatchError(ContainSubstring("load kubeconfig")))
+	})
+
+	Describe("NewTargetRepository", func() {
+		var (
+			secrets *repositoryfakes.FakeRepository
+			built   []client.Client
+			targets repository.TargetRepository
+			ref     v1alpha1.KubeconfigSecretReference
+		)
+
+		BeforeEach(func() {
+			secrets = &repositoryfakes.FakeRepository{}
+			built = nil
+			targets = repository.NewTargetRepository(secrets, clients, func(cl client.Client, _ repository.RepoCache) repository.Repository {
+				built = append(built, cl)
+				return &repositoryfakes.FakeRepository{}
+			})
+			ref = v1alpha1.KubeconfigSecretReference{Name: "prod-kubeconfig", Namespace: "clusters"}
+		})
+
+		secretWith := func(key string, value []byte) func(context.Context, *unstructured.Unstructured) error {
+			return func(_ context.Context, obj *unstructured.Unstructured) error {
+				return unstructured.SetNestedField(obj.Object, base64.StdEncoding.EncodeToString(value), "data", key)
+			}
+		}
+
+		It("builds a repository for the cluster in the secret's kubeconfig", func() {
+			secrets.GetUnstructuredStub = secretWith("value", kubeconfig("some-token"))
+
+			repo, err := targets(context.Background(), ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo).NotTo(BeNil())
+			Expect(built).To(HaveLen(1))
+
+			_, secret := secrets.GetUnstructuredArgsForCall(0)
+			Expect(secret.GetKind()).To(Equal("Secret"))
+			Expect(secret.GetNamespace()).To(Equal("clusters"))
+			Expect(secret.GetName()).To(Equal("prod-kubeconfig"))
+		})
+
+		It("reads the kubeconfig under the key the reference names", func() {
+			ref.Key = "kubeconfig"
+			secrets.GetUnstructuredStub = secretWith("kubeconfig", kubeconfig("some-token"))
+
+			_, err := targets(context.Background(), ref)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when the secret holds no kubeconfig", func() {
+			_, err := targets(context.Background(), ref)
+			Expect(err).To(MatchError("secret 'clusters/prod-kubeconfig' has no value"))
+		})
+
+		It("returns an error when the secret cannot be read", func() {
+			secrets.GetUnstructuredReturns(errors.New("forbidden"))
+
+			_, err := targets(context.Background(), ref)
+			Expect(err).To(MatchError("get secret 'clusters/prod-kubeconfig': forbidden"))
+		})
+	})
+})
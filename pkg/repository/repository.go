@@ -17,9 +17,11 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,22 +36,43 @@ import (
 
 //counterfeiter:generate . Repository
 type Repository interface {
-	EnsureObjectExistsOnCluster(obj *unstructured.Unstructured, allowUpdate bool) error
-	GetClusterTemplate(reference v1alpha1.ClusterTemplateReference) (templates.Template, error)
-	GetDeliveryClusterTemplate(reference v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error)
-	GetRunTemplate(reference v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error)
-	GetSupplyChainsForWorkload(workload *v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error)
-	GetDeliveriesForDeliverable(deliverable *v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error)
-	GetWorkload(name string, namespace string) (*v1alpha1.Workload, error)
-	GetDeliverable(name string, namespace string) (*v1alpha1.Deliverable, error)
-	GetSupplyChain(name string) (*v1alpha1.ClusterSupplyChain, error)
-	StatusUpdate(object client.Object) error
+	EnsureObjectExistsOnCluster(ctx context.Context, obj *unstructured.Unstructured, allowUpdate bool) error
+	EnsureImmutableObjectExistsOnCluster(ctx context.Context, obj *unstructured.Unstructured) error
+	GetUnstructured(ctx context.Context, obj *unstructured.Unstructured) error
+	GetClusterTemplate(ctx context.Context, reference v1alpha1.ClusterTemplateReference) (templates.Template, error)
+	GetDeliveryClusterTemplate(ctx context.Context, reference v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error)
+	GetRunTemplate(ctx context.Context, reference v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error)
+	GetSupplyChainsForWorkload(ctx context.Context, workload *v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error)
+	GetDeliveriesForDeliverable(ctx context.Context, deliverable *v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error)
+	GetWorkload(ctx context.Context, name string, namespace string) (*v1alpha1.Workload, error)
+	GetDeliverable(ctx context.Context, name string, namespace string) (*v1alpha1.Deliverable, error)
+	ListWorkloads(ctx context.Context, namespace string) ([]v1alpha1.Workload, error)
+	ListDeliverables(ctx context.Context, namespace string) ([]v1alpha1.Deliverable, error)
+	GetSupplyChain(ctx context.Context, name string) (*v1alpha1.ClusterSupplyChain, error)
+	StatusUpdate(ctx context.Context, object client.Object) error
 	GetScheme() *runtime.Scheme
-	GetPipeline(name string, namespace string) (*v1alpha1.Pipeline, error)
-	ListUnstructured(obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error)
-	GetDelivery(name string) (*v1alpha1.ClusterDelivery, error)
+	GetPipeline(ctx context.Context, name string, namespace string) (*v1alpha1.Pipeline, error)
+	ListUnstructured(ctx context.Context, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error)
+	GetDelivery(ctx context.Context, name string) (*v1alpha1.ClusterDelivery, error)
+	GetNamespacedSupplyChainsForWorkload(ctx context.Context, workload *v1alpha1.Workload) ([]v1alpha1.SupplyChain, error)
+	GetNamespacedDeliveriesForDeliverable(ctx context.Context, deliverable *v1alpha1.Deliverable) ([]v1alpha1.Delivery, error)
+	GetNamespacedSupplyChain(ctx context.Context, name string, namespace string) (*v1alpha1.SupplyChain, error)
+	GetNamespacedDelivery(ctx context.Context, name string, namespace string) (*v1alpha1.Delivery, error)
+	Update(ctx context.Context, object client.Object) error
+	DeleteUnstructured(ctx context.Context, obj *unstructured.Unstructured) error
+	GetBlueprintSource(ctx context.Context, name string) (*v1alpha1.ClusterBlueprintSource, error)
+	GetBlueprint(ctx context.Context, name string) (*v1alpha1.ClusterBlueprint, error)
+	GetTemplateLibrary(ctx context.Context, name string) (*v1alpha1.ClusterTemplateLibrary, error)
 }
 
+// identityLabelPrefix prefixes the labels Cartographer puts on every object
+// it stamps.
+const identityLabelPrefix = "carto.run/"
+
+// ListPageSize is the most objects of a kind read from the apiserver in one
+// request. Longer lists are read a page at a time.
+const ListPageSize = 500
+
 type repository struct {
 	rc     RepoCache
 	cl     client.Client
@@ -64,14 +87,14 @@ func NewRepository(client client.Client, repoCache RepoCache, logger Logger) Rep
 	}
 }
 
-func (r *repository) GetDelivery(name string) (*v1alpha1.ClusterDelivery, error) {
+func (r *repository) GetDelivery(ctx context.Context, name string) (*v1alpha1.ClusterDelivery, error) {
 	delivery := &v1alpha1.ClusterDelivery{}
 
 	key := client.ObjectKey{
 		Name: name,
 	}
 
-	err := r.cl.Get(context.TODO(), key, delivery)
+	err := r.cl.Get(ctx, key, delivery)
 
 	if err != nil && !api_errors.IsNotFound(err) {
 		return nil, fmt.Errorf("get: %w", err)
@@ -84,8 +107,69 @@ func (r *repository) GetDelivery(name string) (*v1alpha1.ClusterDelivery, error)
 	return delivery, nil
 }
 
-func (r *repository) EnsureObjectExistsOnCluster(obj *unstructured.Unstructured, allowUpdate bool) error {
-	unstructuredList, err := r.ListUnstructured(obj)
+func (r *repository) GetBlueprintSource(ctx context.Context, name string) (*v1alpha1.ClusterBlueprintSource, error) {
+	source := &v1alpha1.ClusterBlueprintSource{}
+
+	err := r.cl.Get(ctx, client.ObjectKey{Name: name}, source)
+	if api_errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	return source, nil
+}
+
+func (r *repository) GetBlueprint(ctx context.Context, name string) (*v1alpha1.ClusterBlueprint, error) {
+	blueprint := &v1alpha1.ClusterBlueprint{}
+
+	err := r.cl.Get(ctx, client.ObjectKey{Name: name}, blueprint)
+	if api_errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	return blueprint, nil
+}
+
+func (r *repository) GetTemplateLibrary(ctx context.Context, name string) (*v1alpha1.ClusterTemplateLibrary, error) {
+	library := &v1alpha1.ClusterTemplateLibrary{}
+
+	err := r.cl.Get(ctx, client.ObjectKey{Name: name}, library)
+	if api_errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	return library, nil
+}
+
+func (r *repository) EnsureObjectExistsOnCluster(ctx context.Context, obj *unstructured.Unstructured, allowUpdate bool) error {
+	listOptions := candidateListOptions(obj)
+
+	// an object created under a generated name for every change to what is
+	// stamped is identified by the hash of what is stamped, so that the
+	// same content is not created twice once the cache is lost.
+	var hash string
+	if !allowUpdate && obj.GetName() == "" && obj.GetGenerateName() != "" {
+		var err error
+		if hash, err = contentHash(obj); err != nil {
+			return err
+		}
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[ContentHashLabel] = hash
+		obj.SetLabels(labels)
+	}
+
+	unstructuredList, err := r.listUnstructured(ctx, obj, listOptions)
 
 	var names []string
 	for _, considered := range unstructuredList {
@@ -103,18 +187,63 @@ func (r *repository) EnsureObjectExistsOnCluster(obj *unstructured.Unstructured,
 		return nil
 	}
 
+	if latest := latestCreated(unstructuredList); hash != "" && latest != nil && latest.GetLabels()[ContentHashLabel] == hash {
+		r.logger.Info("found object created for the same content", "name", latest.GetName(), "namespace", latest.GetNamespace(), "kind", latest.GetKind())
+		*obj = *latest
+		return nil
+	}
+
 	var outdatedObject *unstructured.Unstructured
 	if allowUpdate {
 		outdatedObject = getOutdatedUnstructuredByName(obj, unstructuredList)
 	}
 
 	if outdatedObject != nil {
+		if reporter := DriftReporterFrom(ctx); reporter != nil && r.rc.SubmittedUnchanged(obj) {
+			// what is stamped is as it was, so the object on the apiserver
+			// is what changed.
+			if changes := r.drift(ctx, outdatedObject, obj); len(changes) > 0 {
+				r.logger.Info("leaving drifted object", "name", obj.GetName(), "namespace", obj.GetNamespace(), "kind", obj.GetKind())
+				reporter(obj, changes)
+			}
+			*obj = *outdatedObject
+			return nil
+		}
+
+		if err := r.dryRun(ctx, outdatedObject, obj); err != nil {
+			return err
+		}
 		r.logger.Info("patching object", "name", obj.GetName(), "namespace", obj.GetNamespace(), "kind", obj.GetKind())
-		return r.patchUnstructured(outdatedObject, obj)
+		return r.patchUnstructured(ctx, outdatedObject, obj)
 	} else {
+		if err := r.dryRun(ctx, nil, obj); err != nil {
+			return err
+		}
 		r.logger.Info("creating object", "name", obj.GetName(), "namespace", obj.GetNamespace(), "kind", obj.GetKind())
-		return r.createUnstructured(obj)
+		return r.createUnstructured(ctx, obj)
+	}
+}
+
+// EnsureImmutableObjectExistsOnCluster creates obj unless an object of its
+// kind and name already exists, in which case obj is set to that object. The
+// existing object is never changed, as for objects such as Jobs whose spec
+// cannot be updated.
+func (r *repository) EnsureImmutableObjectExistsOnCluster(ctx context.Context, obj *unstructured.Unstructured) error {
+	unstructuredList, err := r.listUnstructured(ctx, obj, candidateListOptions(obj))
+	if err != nil {
+		return err
+	}
+
+	if existing := getOutdatedUnstructuredByName(obj, unstructuredList); existing != nil {
+		*obj = *existing
+		return nil
+	}
+
+	if err := r.dryRun(ctx, nil, obj); err != nil {
+		return err
 	}
+	r.logger.Info("creating object", "name", obj.GetName(), "namespace", obj.GetNamespace(), "kind", obj.GetKind())
+	return r.createUnstructured(ctx, obj)
 }
 
 func getOutdatedUnstructuredByName(target *unstructured.Unstructured, candidates []*unstructured.Unstructured) *unstructured.Unstructured {
@@ -126,42 +255,111 @@ func getOutdatedUnstructuredByName(target *unstructured.Unstructured, candidates
 	return nil
 }
 
-func (r *repository) ListUnstructured(obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
-	unstructuredList := &unstructured.UnstructuredList{}
-	unstructuredList.SetGroupVersionKind(obj.GroupVersionKind())
+// candidateListOptions narrows the list of objects that obj may already exist
+// as to those carrying its identity labels, the ones Cartographer stamps to
+// record which owner, blueprint and resource an object belongs to, and, when
+// obj is named, to that one name. Unstructured lists are not served from the
+// informer cache, so every reconcile of every resource lists from the
+// apiserver: selecting precisely keeps those calls cheap on clusters with many
+// stamped objects of a kind. Objects without identity labels fall back to
+// matching all of their labels. Objects with generated names, of which an
+// owner may have stamped many, are listed a page at a time.
+func candidateListOptions(obj *unstructured.Unstructured) []client.ListOption {
+	selector := map[string]string{}
+	for key, value := range obj.GetLabels() {
+		if strings.HasPrefix(key, identityLabelPrefix) {
+			selector[key] = value
+		}
+	}
+	if len(selector) == 0 {
+		selector = obj.GetLabels()
+	}
 
 	opts := []client.ListOption{
 		client.InNamespace(obj.GetNamespace()),
-		client.MatchingLabels(obj.GetLabels()),
+		client.MatchingLabels(selector),
 	}
-	err := r.cl.List(context.TODO(), unstructuredList, opts...)
-	if err != nil {
-		return nil, fmt.Errorf("list: %w", err)
+	if obj.GetName() != "" {
+		opts = append(opts,
+			client.MatchingFields{"metadata.name": obj.GetName()},
+			client.Limit(1),
+		)
 	}
+	return opts
+}
 
-	pointersToUnstructureds := make([]*unstructured.Unstructured, len(unstructuredList.Items))
+func (r *repository) ListUnstructured(ctx context.Context, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	return r.listUnstructured(ctx, obj, []client.ListOption{
+		client.InNamespace(obj.GetNamespace()),
+		client.MatchingLabels(obj.GetLabels()),
+	})
+}
+
+// listUnstructured lists the objects of obj's kind that opts select, a page
+// of ListPageSize at a time, so that the apiserver never has to read or
+// send every matching object in one response. Lists that set their own
+// limit are read in one request. When a page's continue token has expired,
+// the list is read again in full, as client-go's pager does.
+func (r *repository) listUnstructured(ctx context.Context, obj *unstructured.Unstructured, opts []client.ListOption) ([]*unstructured.Unstructured, error) {
+	listOptions := &client.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	paged := listOptions.Limit == 0
+
+	var items []*unstructured.Unstructured
+	continueToken := ""
+	for {
+		pageOpts := opts
+		if paged {
+			pageOpts = append(append([]client.ListOption{}, opts...), client.Limit(ListPageSize))
+			if continueToken != "" {
+				pageOpts = append(pageOpts, client.Continue(continueToken))
+			}
+		}
+
+		page := &unstructured.UnstructuredList{}
+		page.SetGroupVersionKind(obj.GroupVersionKind())
+		err := r.cl.List(ctx, page, pageOpts...)
+		if err != nil {
+			if paged && continueToken != "" && api_errors.IsResourceExpired(err) {
+				paged = false
+				items = nil
+				continue
+			}
+			return nil, fmt.Errorf("list: %w", err)
+		}
+
+		// each page is read into a list of its own, so its items can be
+		// returned without copying them.
+		for i := range page.Items {
+			items = append(items, &page.Items[i])
+		}
 
-	for i, item := range unstructuredList.Items {
-		pointersToUnstructureds[i] = item.DeepCopy()
+		continueToken = page.GetContinue()
+		if !paged || continueToken == "" {
+			return items, nil
+		}
 	}
-	return pointersToUnstructureds, nil
 }
 
-func (r *repository) GetClusterTemplate(ref v1alpha1.ClusterTemplateReference) (templates.Template, error) {
-	return r.getTemplate(ref.Name, ref.Kind)
+func (r *repository) GetClusterTemplate(ctx context.Context, ref v1alpha1.ClusterTemplateReference) (templates.Template, error) {
+	return r.getTemplate(ctx, ref.Name, ref.Version, ref.Kind)
 }
 
-func (r *repository) GetDeliveryClusterTemplate(ref v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error) {
-	return r.getTemplate(ref.Name, ref.Kind)
+func (r *repository) GetDeliveryClusterTemplate(ctx context.Context, ref v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error) {
+	return r.getTemplate(ctx, ref.Name, ref.Version, ref.Kind)
 }
 
-func (r *repository) getTemplate(name string, kind string) (templates.Template, error) {
+func (r *repository) getTemplate(ctx context.Context, name string, version string, kind string) (templates.Template, error) {
 	apiTemplate, err := v1alpha1.GetAPITemplate(kind)
 	if err != nil {
 		return nil, fmt.Errorf("get api template: %w", err)
 	}
 
-	err = r.getObject(name, "", apiTemplate)
+	if version == "" {
+		err = r.getObject(ctx, name, "", apiTemplate)
+	} else {
+		err = r.getTemplateVersion(ctx, name, version, kind, apiTemplate)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("get: %w", err)
 	}
@@ -174,10 +372,40 @@ func (r *repository) getTemplate(name string, kind string) (templates.Template,
 	return template, nil
 }
 
-func (r *repository) GetRunTemplate(ref v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error) {
+// getTemplateVersion reads into apiTemplate the one template of kind
+// labelled as the given version of the named template.
+func (r *repository) getTemplateVersion(ctx context.Context, name string, version string, kind string, apiTemplate client.Object) error {
+	template := &unstructured.Unstructured{}
+	template.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(kind))
+	versions, err := r.listUnstructured(ctx, template, []client.ListOption{
+		client.MatchingLabels{
+			v1alpha1.TemplateNameLabel:    name,
+			v1alpha1.TemplateVersionLabel: version,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	switch len(versions) {
+	case 0:
+		return api_errors.NewNotFound(v1alpha1.SchemeGroupVersion.WithResource(strings.ToLower(kind)+"s").GroupResource(), name+"@"+version)
+	case 1:
+		return runtime.DefaultUnstructuredConverter.FromUnstructured(versions[0].Object, apiTemplate)
+	}
+
+	var names []string
+	for _, v := range versions {
+		names = append(names, v.GetName())
+	}
+	sort.Strings(names)
+	return fmt.Errorf("%d %ss are labelled as version '%s' of '%s': %s", len(versions), kind, version, name, strings.Join(names, ", "))
+}
+
+func (r *repository) GetRunTemplate(ctx context.Context, ref v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error) {
 	runTemplate := &v1alpha1.ClusterRunTemplate{}
 
-	err := r.cl.Get(context.TODO(), client.ObjectKey{
+	err := r.cl.Get(ctx, client.ObjectKey{
 		Name: ref.Name,
 	}, runTemplate)
 	if err != nil {
@@ -192,9 +420,9 @@ func (r *repository) GetRunTemplate(ref v1alpha1.TemplateReference) (templates.C
 	return template, nil
 }
 
-func (r *repository) createUnstructured(obj *unstructured.Unstructured) error {
+func (r *repository) createUnstructured(ctx context.Context, obj *unstructured.Unstructured) error {
 	submitted := obj.DeepCopy()
-	if err := r.cl.Create(context.TODO(), obj); err != nil {
+	if err := r.cl.Create(ctx, obj, client.FieldOwner(FieldManager)); err != nil {
 		return fmt.Errorf("create: %w", err)
 	}
 
@@ -202,21 +430,26 @@ func (r *repository) createUnstructured(obj *unstructured.Unstructured) error {
 	return nil
 }
 
-func (r *repository) patchUnstructured(existingObj *unstructured.Unstructured, obj *unstructured.Unstructured) error {
+func (r *repository) patchUnstructured(ctx context.Context, existingObj *unstructured.Unstructured, obj *unstructured.Unstructured) error {
 	submitted := obj.DeepCopy()
 	// FIXME: I'm untested. What am I for? Patch doesn't block on RV's (is this a historical artifact of .Update?)
 	obj.SetResourceVersion(existingObj.GetResourceVersion())
-	if err := r.cl.Patch(context.TODO(), obj, client.MergeFrom(existingObj)); err != nil {
+	if err := r.cl.Patch(ctx, obj, client.MergeFrom(existingObj), client.FieldOwner(FieldManager)); err != nil {
 		return fmt.Errorf("patch: %w", err)
 	}
 
 	r.rc.Set(submitted, obj.DeepCopy())
+	// the changes are read from the object as the apiserver stored it, so
+	// that the values it defaults or normalizes are not taken for changes.
+	changes := Diff(existingObj, obj)
+	reportChanges(ctx, submitted, changes)
+	reportConflicts(ctx, submitted, Conflicts(existingObj, changes))
 	return nil
 }
 
-func (r *repository) GetSupplyChainsForWorkload(workload *v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error) {
+func (r *repository) GetSupplyChainsForWorkload(ctx context.Context, workload *v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error) {
 	list := &v1alpha1.ClusterSupplyChainList{}
-	if err := r.cl.List(context.TODO(), list); err != nil {
+	if err := r.cl.List(ctx, list); err != nil {
 		return nil, fmt.Errorf("list supply chains: %w", err)
 	}
 
@@ -230,9 +463,9 @@ func (r *repository) GetSupplyChainsForWorkload(workload *v1alpha1.Workload) ([]
 	return clusterSupplyChains, nil
 }
 
-func (r *repository) GetDeliveriesForDeliverable(deliverable *v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error) {
+func (r *repository) GetDeliveriesForDeliverable(ctx context.Context, deliverable *v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error) {
 	list := &v1alpha1.ClusterDeliveryList{}
-	if err := r.cl.List(context.TODO(), list); err != nil {
+	if err := r.cl.List(ctx, list); err != nil {
 		return nil, fmt.Errorf("list deliveries: %w", err)
 	}
 
@@ -246,8 +479,40 @@ func (r *repository) GetDeliveriesForDeliverable(deliverable *v1alpha1.Deliverab
 	return clusterDeliveries, nil
 }
 
-func (r *repository) getObject(name string, namespace string, obj client.Object) error {
-	err := r.cl.Get(context.TODO(),
+func (r *repository) GetNamespacedSupplyChainsForWorkload(ctx context.Context, workload *v1alpha1.Workload) ([]v1alpha1.SupplyChain, error) {
+	list := &v1alpha1.SupplyChainList{}
+	if err := r.cl.List(ctx, list, client.InNamespace(workload.Namespace)); err != nil {
+		return nil, fmt.Errorf("list namespaced supply chains: %w", err)
+	}
+
+	var supplyChains []v1alpha1.SupplyChain
+	for _, supplyChain := range list.Items {
+		if selectorMatchesLabels(supplyChain.Spec.Selector, workload.Labels) {
+			supplyChains = append(supplyChains, supplyChain)
+		}
+	}
+
+	return supplyChains, nil
+}
+
+func (r *repository) GetNamespacedDeliveriesForDeliverable(ctx context.Context, deliverable *v1alpha1.Deliverable) ([]v1alpha1.Delivery, error) {
+	list := &v1alpha1.DeliveryList{}
+	if err := r.cl.List(ctx, list, client.InNamespace(deliverable.Namespace)); err != nil {
+		return nil, fmt.Errorf("list namespaced deliveries: %w", err)
+	}
+
+	var deliveries []v1alpha1.Delivery
+	for _, delivery := range list.Items {
+		if selectorMatchesLabels(delivery.Spec.Selector, deliverable.Labels) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (r *repository) getObject(ctx context.Context, name string, namespace string, obj client.Object) error {
+	err := r.cl.Get(ctx,
 		client.ObjectKey{
 			Name:      name,
 			Namespace: namespace,
@@ -261,28 +526,54 @@ func (r *repository) getObject(name string, namespace string, obj client.Object)
 	return nil
 }
 
-func (r *repository) GetWorkload(name string, namespace string) (*v1alpha1.Workload, error) {
+// GetUnstructured reads the object of obj's kind, namespace and name into
+// obj.
+func (r *repository) GetUnstructured(ctx context.Context, obj *unstructured.Unstructured) error {
+	return r.getObject(ctx, obj.GetName(), obj.GetNamespace(), obj)
+}
+
+func (r *repository) GetWorkload(ctx context.Context, name string, namespace string) (*v1alpha1.Workload, error) {
 	workload := v1alpha1.Workload{}
-	err := r.getObject(name, namespace, &workload)
+	err := r.getObject(ctx, name, namespace, &workload)
 	if err != nil {
 		return nil, err
 	}
 	return &workload, nil
 }
 
-func (r *repository) GetDeliverable(name string, namespace string) (*v1alpha1.Deliverable, error) {
+func (r *repository) GetDeliverable(ctx context.Context, name string, namespace string) (*v1alpha1.Deliverable, error) {
 	deliverable := v1alpha1.Deliverable{}
-	err := r.getObject(name, namespace, &deliverable)
+	err := r.getObject(ctx, name, namespace, &deliverable)
 	if err != nil {
 		return nil, err
 	}
 	return &deliverable, nil
 }
 
-func (r *repository) GetPipeline(name string, namespace string) (*v1alpha1.Pipeline, error) {
+// ListWorkloads returns the workloads in namespace, or in every namespace
+// when namespace is empty.
+func (r *repository) ListWorkloads(ctx context.Context, namespace string) ([]v1alpha1.Workload, error) {
+	list := &v1alpha1.WorkloadList{}
+	if err := r.cl.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list workloads: %w", err)
+	}
+	return list.Items, nil
+}
+
+// ListDeliverables returns the deliverables in namespace, or in every
+// namespace when namespace is empty.
+func (r *repository) ListDeliverables(ctx context.Context, namespace string) ([]v1alpha1.Deliverable, error) {
+	list := &v1alpha1.DeliverableList{}
+	if err := r.cl.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list deliverables: %w", err)
+	}
+	return list.Items, nil
+}
+
+func (r *repository) GetPipeline(ctx context.Context, name string, namespace string) (*v1alpha1.Pipeline, error) {
 	pipeline := &v1alpha1.Pipeline{}
 
-	err := r.getObject(name, namespace, pipeline)
+	err := r.getObject(ctx, name, namespace, pipeline)
 
 	if err != nil {
 		return nil, fmt.Errorf("get-pipeline: %w", err)
@@ -301,10 +592,25 @@ func selectorMatchesLabels(selector map[string]string, labels map[string]string)
 	return true
 }
 
-func (r *repository) GetSupplyChain(name string) (*v1alpha1.ClusterSupplyChain, error) {
+func (r *repository) GetSupplyChain(ctx context.Context, name string) (*v1alpha1.ClusterSupplyChain, error) {
 	supplyChain := v1alpha1.ClusterSupplyChain{}
 
-	err := r.getObject(name, "", &supplyChain)
+	err := r.getObject(ctx, name, "", &supplyChain)
+	if err != nil && !api_errors.IsNotFound(err) {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	if api_errors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	return &supplyChain, nil
+}
+
+func (r *repository) GetNamespacedSupplyChain(ctx context.Context, name string, namespace string) (*v1alpha1.SupplyChain, error) {
+	supplyChain := v1alpha1.SupplyChain{}
+
+	err := r.getObject(ctx, name, namespace, &supplyChain)
 	if err != nil && !api_errors.IsNotFound(err) {
 		return nil, fmt.Errorf("get: %w", err)
 	}
@@ -316,8 +622,38 @@ func (r *repository) GetSupplyChain(name string) (*v1alpha1.ClusterSupplyChain,
 	return &supplyChain, nil
 }
 
-func (r *repository) StatusUpdate(object client.Object) error {
-	return r.cl.Status().Update(context.TODO(), object)
+func (r *repository) GetNamespacedDelivery(ctx context.Context, name string, namespace string) (*v1alpha1.Delivery, error) {
+	delivery := v1alpha1.Delivery{}
+
+	err := r.getObject(ctx, name, namespace, &delivery)
+	if err != nil && !api_errors.IsNotFound(err) {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	if api_errors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	return &delivery, nil
+}
+
+func (r *repository) StatusUpdate(ctx context.Context, object client.Object) error {
+	return r.cl.Status().Update(ctx, object)
+}
+
+func (r *repository) Update(ctx context.Context, object client.Object) error {
+	return r.cl.Update(ctx, object)
+}
+
+// DeleteUnstructured deletes obj, succeeding if it is already gone.
+func (r *repository) DeleteUnstructured(ctx context.Context, obj *unstructured.Unstructured) error {
+	r.logger.Info("deleting object", "name", obj.GetName(), "namespace", obj.GetNamespace(), "kind", obj.GetKind())
+	// Background propagation deletes the object's dependents too, such as a
+	// Job's pods, which the apiserver would otherwise orphan.
+	if err := r.cl.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !api_errors.IsNotFound(err) {
+		return fmt.Errorf("delete: %w", err)
+	}
+	return nil
 }
 
 func (r *repository) GetScheme() *runtime.Scheme {
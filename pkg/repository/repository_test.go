@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -39,12 +40,14 @@ import (
 
 var _ = Describe("repository", func() {
 	var (
+		ctx    context.Context
 		repo   repository.Repository
 		cache  *repositoryfakes.FakeRepoCache
 		logger *repositoryfakes.FakeLogger
 	)
 
 	BeforeEach(func() {
+		ctx = context.Background()
 		cache = &repositoryfakes.FakeRepoCache{}
 		logger = &repositoryfakes.FakeLogger{}
 	})
@@ -83,13 +86,15 @@ spec:
 			})
 
 			It("attempts to get the object from the apiServer", func() {
-				Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+				Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 
 				Expect(cl.ListCallCount()).To(Equal(1))
 
 				listOptions := []client.ListOption{
 					client.InNamespace(stampedObj.GetNamespace()),
 					client.MatchingLabels(stampedObj.GetLabels()),
+					client.MatchingFields{"metadata.name": "hello"},
+					client.Limit(1),
 				}
 
 				_, objectList, options := cl.ListArgsForCall(0)
@@ -100,24 +105,127 @@ spec:
 				Expect(unstructuredList.GetObjectKind().GroupVersionKind()).To(Equal(stampedObj.GroupVersionKind()))
 			})
 
+			It("selects candidates by Cartographer's identity labels only", func() {
+				stampedObj.SetLabels(map[string]string{
+					"carto.run/workload-name":   "my-workload",
+					"carto.run/resource-name":   "build",
+					"app.kubernetes.io/version": "1.2.3",
+				})
+
+				Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
+
+				_, _, options := cl.ListArgsForCall(0)
+				Expect(options).To(ContainElement(client.MatchingLabels{
+					"carto.run/workload-name": "my-workload",
+					"carto.run/resource-name": "build",
+				}))
+			})
+
+			It("does not narrow to a name for generated names, and lists a page at a time", func() {
+				stampedObj.SetName("")
+				stampedObj.SetGenerateName("hello-")
+
+				Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
+
+				_, _, options := cl.ListArgsForCall(0)
+				Expect(options).To(Equal([]client.ListOption{
+					client.InNamespace(stampedObj.GetNamespace()),
+					client.MatchingLabels(stampedObj.GetLabels()),
+					client.Limit(repository.ListPageSize),
+				}))
+			})
+
+			Context("when the candidates span several pages", func() {
+				BeforeEach(func() {
+					stampedObj.SetName("")
+					stampedObj.SetGenerateName("hello-")
+
+					cl.ListStub = func(ctx context.Context, list client.ObjectList, options ...client.ListOption) error {
+						listOptions := (&client.ListOptions{}).ApplyOptions(options)
+						unstructuredList := list.(*unstructured.UnstructuredList)
+						switch listOptions.Continue {
+						case "":
+							unstructuredList.Items = []unstructured.Unstructured{{}, {}}
+							unstructuredList.Items[0].SetName("hello-1")
+							unstructuredList.Items[1].SetName("hello-2")
+							unstructuredList.SetContinue("page-2")
+						case "page-2":
+							unstructuredList.Items = []unstructured.Unstructured{{}}
+							unstructuredList.Items[0].SetName("hello-3")
+						default:
+							return kerrors.NewResourceExpired("continue token expired")
+						}
+						return nil
+					}
+				})
+
+				It("reads every page, passing on the continue token", func() {
+					Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
+
+					Expect(cl.ListCallCount()).To(Equal(2))
+					_, _, options := cl.ListArgsForCall(1)
+					Expect(options).To(ContainElement(client.Continue("page-2")))
+
+					_, candidates := cache.UnchangedSinceCachedArgsForCall(0)
+					var names []string
+					for _, candidate := range candidates {
+						names = append(names, candidate.GetName())
+					}
+					Expect(names).To(Equal([]string{"hello-1", "hello-2", "hello-3"}))
+				})
+
+				It("reads the list again in full when the continue token has expired", func() {
+					stub := cl.ListStub
+					calls := 0
+					cl.ListStub = func(ctx context.Context, list client.ObjectList, options ...client.ListOption) error {
+						calls++
+						if calls == 2 {
+							return kerrors.NewResourceExpired("continue token expired")
+						}
+						return stub(ctx, list, options...)
+					}
+
+					Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
+
+					Expect(cl.ListCallCount()).To(Equal(3))
+					_, _, options := cl.ListArgsForCall(2)
+					Expect(options).To(Equal([]client.ListOption{
+						client.InNamespace(stampedObj.GetNamespace()),
+						client.MatchingLabels(stampedObj.GetLabels()),
+					}))
+				})
+			})
+
+			It("passes the caller's context to the apiServer", func() {
+				type ctxKey struct{}
+				ctx = context.WithValue(ctx, ctxKey{}, "reconcile")
+
+				Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
+
+				listCtx, _, _ := cl.ListArgsForCall(0)
+				Expect(listCtx.Value(ctxKey{})).To(Equal("reconcile"))
+				createCtx, _, _ := cl.CreateArgsForCall(0)
+				Expect(createCtx.Value(ctxKey{})).To(Equal("reconcile"))
+			})
+
 			Context("when the apiServer errors when trying to get the object", func() {
 				BeforeEach(func() {
 					cl.ListReturns(errors.New("some-error"))
 				})
 
 				It("returns a helpful error", func() {
-					err := repo.EnsureObjectExistsOnCluster(stampedObj, true)
+					err := repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)
 					Expect(err).To(MatchError(ContainSubstring("list: some-error")))
 				})
 
 				It("does not create or patch any objects", func() {
-					_ = repo.EnsureObjectExistsOnCluster(stampedObj, true)
+					_ = repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)
 					Expect(cl.CreateCallCount()).To(Equal(0))
 					Expect(cl.PatchCallCount()).To(Equal(0))
 				})
 
 				It("does not write to the submitted or persisted cache", func() {
-					_ = repo.EnsureObjectExistsOnCluster(stampedObj, true)
+					_ = repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)
 					Expect(cache.SetCallCount()).To(Equal(0))
 				})
 			})
@@ -127,7 +235,7 @@ spec:
 					// default behavior is empty list - no need to stub
 				})
 				It("attempts to create the object", func() {
-					Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+					Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 
 					Expect(cl.CreateCallCount()).To(Equal(1))
 					_, createCallObj, _ := cl.CreateArgsForCall(0)
@@ -140,14 +248,51 @@ spec:
 					})
 
 					It("returns a helpful error", func() {
-						err := repo.EnsureObjectExistsOnCluster(stampedObj, true)
+						err := repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)
 						Expect(err).To(MatchError(ContainSubstring("create: some-error")))
 					})
 
 					It("does not write to the submitted or persisted cache", func() {
-						_ = repo.EnsureObjectExistsOnCluster(stampedObj, true)
+						_ = repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)
+						Expect(cache.SetCallCount()).To(Equal(0))
+					})
+				})
+
+				Context("and the object is dry run", func() {
+					var dryRunCtx context.Context
+
+					BeforeEach(func() {
+						dryRunCtx = repository.WithDryRun(ctx)
+					})
+
+					It("creates the object as a dry run before creating it", func() {
+						Expect(repo.EnsureObjectExistsOnCluster(dryRunCtx, stampedObj, true)).To(Succeed())
+
+						Expect(cl.CreateCallCount()).To(Equal(2))
+						_, dryRunObj, dryRunOpts := cl.CreateArgsForCall(0)
+						Expect(dryRunObj).To(Equal(stampedObj))
+						Expect(dryRunOpts).To(ContainElement(client.DryRunAll))
+						_, _, opts := cl.CreateArgsForCall(1)
+						Expect(opts).NotTo(ContainElement(client.DryRunAll))
+					})
+
+					It("reports a rejected dry run, without creating the object", func() {
+						cl.CreateReturnsOnCall(0, kerrors.NewInvalid(schema.GroupKind{Kind: "Job"}, "hello", nil))
+
+						err := repo.EnsureObjectExistsOnCluster(dryRunCtx, stampedObj, true)
+						Expect(errors.As(err, &repository.RejectedError{})).To(BeTrue())
+						Expect(err).To(MatchError(ContainSubstring(`rejected on dry run: Job "hello" is invalid`)))
+						Expect(cl.CreateCallCount()).To(Equal(1))
 						Expect(cache.SetCallCount()).To(Equal(0))
 					})
+
+					It("returns other dry run errors as they are", func() {
+						cl.CreateReturnsOnCall(0, errors.New("some-error"))
+
+						err := repo.EnsureObjectExistsOnCluster(dryRunCtx, stampedObj, true)
+						Expect(err).To(MatchError("dry run: some-error"))
+						Expect(errors.As(err, &repository.RejectedError{})).To(BeFalse())
+					})
 				})
 
 				Context("and the apiServer succeeds", func() {
@@ -165,13 +310,13 @@ spec:
 					})
 
 					It("does not return an error", func() {
-						Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+						Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 					})
 
 					It("caches the submitted and persisted objects, as the persisted one may be modified by mutating webhooks", func() {
 						originalStampedObj := stampedObj.DeepCopy()
 
-						Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+						Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 						Expect(cache.SetCallCount()).To(Equal(1))
 						submitted, persisted := cache.SetArgsForCall(0)
 						Expect(*submitted).To(Equal(*originalStampedObj))
@@ -205,7 +350,7 @@ spec:
 				})
 
 				It("the cache is consulted to see if there was a change since the last time the cache was updated", func() {
-					Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+					Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 					Expect(cache.UnchangedSinceCachedCallCount()).To(Equal(1))
 
 					submitted, persisted := cache.UnchangedSinceCachedArgsForCall(0)
@@ -219,20 +364,20 @@ spec:
 					})
 
 					It("does not create or patch any objects", func() {
-						Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+						Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 						Expect(cl.CreateCallCount()).To(Equal(0))
 						Expect(cl.PatchCallCount()).To(Equal(0))
 					})
 
 					It("does not write to the submitted or persisted cache", func() {
-						Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+						Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 						Expect(cache.SetCallCount()).To(Equal(0))
 					})
 
 					It("populates the object passed into the function with the object in apiServer", func() {
 						originalStampedObj := stampedObj.DeepCopy()
 
-						_ = repo.EnsureObjectExistsOnCluster(stampedObj, true)
+						_ = repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)
 
 						Expect(stampedObj).To(Equal(existingObj))
 						Expect(stampedObj).NotTo(Equal(originalStampedObj))
@@ -247,7 +392,7 @@ spec:
 					Context("and allowUpdate is true", func() {
 						Context("list has exactly one object", func() {
 							It("patches the object", func() {
-								Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+								Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 								Expect(cl.PatchCallCount()).To(Equal(1))
 							})
 
@@ -267,18 +412,163 @@ spec:
 								})
 
 								It("does not return an error", func() {
-									Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+									Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 								})
 
 								It("caches the submitted and persisted objects, as the persisted one may be modified by mutating webhooks", func() {
 									originalStampedObj := stampedObj.DeepCopy()
 
-									Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+									Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 									Expect(cache.SetCallCount()).To(Equal(1))
 									submitted, persisted := cache.SetArgsForCall(0)
 									Expect(*submitted).To(Equal(*originalStampedObj))
 									Expect(*persisted).To(Equal(*returnedPatchedObj))
 								})
+
+								It("reports the changes it patched the object with", func() {
+									var (
+										reported *unstructured.Unstructured
+										changes  []repository.Change
+									)
+									reporterCtx := repository.WithChangeReporter(ctx, func(obj *unstructured.Unstructured, c []repository.Change) {
+										reported, changes = obj, c
+									})
+									originalStampedObj := stampedObj.DeepCopy()
+
+									Expect(repo.EnsureObjectExistsOnCluster(reporterCtx, stampedObj, true)).To(Succeed())
+									Expect(reported).To(Equal(originalStampedObj))
+									Expect(changes).To(HaveLen(1))
+									Expect(changes[0].Path).To(Equal("spec"))
+									Expect(changes[0].From).To(BeNil())
+								})
+
+								It("reports the changes as the apiserver stored them", func() {
+									existingObj.Object["spec"] = returnedPatchedObj.DeepCopy().Object["spec"]
+									existingObjList.Items = []unstructured.Unstructured{*existingObj}
+									reported := false
+									reporterCtx := repository.WithChangeReporter(ctx, func(obj *unstructured.Unstructured, c []repository.Change) {
+										reported = true
+									})
+
+									Expect(repo.EnsureObjectExistsOnCluster(reporterCtx, stampedObj, true)).To(Succeed())
+									Expect(reported).To(BeFalse())
+								})
+
+								It("reports the other field managers it took fields back from", func() {
+									existingObj.SetManagedFields([]metav1.ManagedFieldsEntry{{
+										Manager:  "kubectl-edit",
+										FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{}}}`)},
+									}})
+									existingObjList.Items = []unstructured.Unstructured{*existingObj}
+									var conflicts []repository.FieldConflict
+									reporterCtx := repository.WithConflictReporter(ctx, func(obj *unstructured.Unstructured, c []repository.FieldConflict) {
+										conflicts = c
+									})
+
+									Expect(repo.EnsureObjectExistsOnCluster(reporterCtx, stampedObj, true)).To(Succeed())
+									Expect(conflicts).To(Equal([]repository.FieldConflict{{Manager: "kubectl-edit", Fields: []string{"spec"}}}))
+								})
+
+								It("patches as Cartographer's field manager", func() {
+									Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
+									_, _, _, opts := cl.PatchArgsForCall(0)
+									Expect(opts).To(ContainElement(client.FieldOwner(repository.FieldManager)))
+								})
+
+								It("patches the object as a dry run first when asked to", func() {
+									Expect(repo.EnsureObjectExistsOnCluster(repository.WithDryRun(ctx), stampedObj, true)).To(Succeed())
+									Expect(cl.PatchCallCount()).To(Equal(2))
+									_, _, _, dryRunOpts := cl.PatchArgsForCall(0)
+									Expect(dryRunOpts).To(ContainElement(client.DryRunAll))
+									_, _, _, opts := cl.PatchArgsForCall(1)
+									Expect(opts).NotTo(ContainElement(client.DryRunAll))
+								})
+
+								It("does not patch the object when the dry run is rejected", func() {
+									cl.PatchReturnsOnCall(0, kerrors.NewForbidden(schema.GroupResource{Resource: "jobs"}, "hello", errors.New("denied by webhook")))
+
+									err := repo.EnsureObjectExistsOnCluster(repository.WithDryRun(ctx), stampedObj, true)
+									Expect(errors.As(err, &repository.RejectedError{})).To(BeTrue())
+									Expect(err).To(MatchError(ContainSubstring("denied by webhook")))
+									Expect(cl.PatchCallCount()).To(Equal(1))
+								})
+							})
+
+							Context("and drift is detected", func() {
+								var (
+									reported *unstructured.Unstructured
+									changes  []repository.Change
+									driftCtx context.Context
+								)
+
+								BeforeEach(func() {
+									reported, changes = nil, nil
+									driftCtx = repository.WithDriftDetection(ctx, func(obj *unstructured.Unstructured, c []repository.Change) {
+										reported, changes = obj.DeepCopy(), c
+									})
+								})
+
+								Context("and the submitted object is unchanged since it was cached", func() {
+									BeforeEach(func() {
+										cache.SubmittedUnchangedReturns(true)
+									})
+
+									It("leaves the object, reporting how it drifted", func() {
+										originalStampedObj := stampedObj.DeepCopy()
+
+										Expect(repo.EnsureObjectExistsOnCluster(driftCtx, stampedObj, true)).To(Succeed())
+										Expect(cl.PatchCallCount()).To(Equal(1))
+										_, _, _, opts := cl.PatchArgsForCall(0)
+										Expect(opts).To(ContainElement(client.DryRunAll))
+										Expect(cache.SetCallCount()).To(Equal(0))
+										Expect(reported).To(Equal(originalStampedObj))
+										Expect(changes).To(HaveLen(1))
+										Expect(changes[0].Path).To(Equal("spec"))
+										Expect(stampedObj).To(Equal(existingObj))
+									})
+
+									Context("and the apiserver stores the submitted object as the object on it", func() {
+										BeforeEach(func() {
+											cl.PatchStub = func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+												reflect.Indirect(reflect.ValueOf(obj)).Set(reflect.Indirect(reflect.ValueOf(existingObj.DeepCopy())))
+												return nil
+											}
+										})
+
+										It("reports no drift and caches the object as persisted for the submitted one", func() {
+											originalStampedObj := stampedObj.DeepCopy()
+
+											Expect(repo.EnsureObjectExistsOnCluster(driftCtx, stampedObj, true)).To(Succeed())
+											Expect(reported).To(BeNil())
+											Expect(cache.SetCallCount()).To(Equal(1))
+											submitted, persisted := cache.SetArgsForCall(0)
+											Expect(submitted).To(Equal(originalStampedObj))
+											Expect(persisted).To(Equal(existingObj))
+											Expect(stampedObj).To(Equal(existingObj))
+										})
+									})
+
+									It("reports the drift as submitted when the dry run fails", func() {
+										cl.PatchReturns(errors.New("apiserver unavailable"))
+
+										Expect(repo.EnsureObjectExistsOnCluster(driftCtx, stampedObj, true)).To(Succeed())
+										Expect(changes).To(HaveLen(1))
+										Expect(changes[0].Path).To(Equal("spec"))
+										Expect(stampedObj).To(Equal(existingObj))
+									})
+								})
+
+								Context("and the submitted object changed", func() {
+									BeforeEach(func() {
+										cache.SubmittedUnchangedReturns(false)
+									})
+
+									It("patches the object", func() {
+										Expect(repo.EnsureObjectExistsOnCluster(driftCtx, stampedObj, true)).To(Succeed())
+										Expect(cl.PatchCallCount()).To(Equal(1))
+										Expect(reported).To(BeNil())
+									})
+								})
 							})
 
 							Context("and the patch fails", func() {
@@ -286,12 +576,12 @@ spec:
 									cl.PatchReturns(errors.New("some-error"))
 								})
 								It("returns a helpful error", func() {
-									err := repo.EnsureObjectExistsOnCluster(stampedObj, true)
+									err := repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)
 									Expect(err).To(MatchError(ContainSubstring("patch: some-error")))
 								})
 
 								It("does not write to the submitted or persisted cache", func() {
-									_ = repo.EnsureObjectExistsOnCluster(stampedObj, true)
+									_ = repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)
 									Expect(cache.SetCallCount()).To(Equal(0))
 								})
 							})
@@ -308,7 +598,7 @@ spec:
 								})
 
 								It("it patches", func() {
-									Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+									Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 									Expect(cl.PatchCallCount()).To(Equal(1))
 								})
 							})
@@ -324,7 +614,7 @@ spec:
 									}
 								})
 								It("it creates", func() {
-									Expect(repo.EnsureObjectExistsOnCluster(stampedObj, true)).To(Succeed())
+									Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, true)).To(Succeed())
 									Expect(cl.CreateCallCount()).To(Equal(1))
 								})
 							})
@@ -333,11 +623,67 @@ spec:
 
 					Context("and allowUpate is false", func() {
 						It("creates a new object", func() {
-							Expect(repo.EnsureObjectExistsOnCluster(stampedObj, false)).To(Succeed())
+							Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, false)).To(Succeed())
 							Expect(cl.PatchCallCount()).To(Equal(0))
 							Expect(cl.CreateCallCount()).To(Equal(1))
 						})
 
+						Context("and the object has a generated name", func() {
+							BeforeEach(func() {
+								stampedObj.SetName("")
+								stampedObj.SetGenerateName("hello-")
+							})
+
+							It("labels the object with the hash of its content, without narrowing the list to it", func() {
+								Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, false)).To(Succeed())
+
+								Expect(cl.CreateCallCount()).To(Equal(1))
+								_, created, _ := cl.CreateArgsForCall(0)
+								Expect(created.GetLabels()).To(HaveKeyWithValue(repository.ContentHashLabel, MatchRegexp(`^[0-9a-f]{20}$`)))
+
+								_, _, options := cl.ListArgsForCall(0)
+								Expect(options).To(ContainElement(client.MatchingLabels(nil)))
+							})
+
+							It("does not create the object again when the latest one was created for the same content", func() {
+								Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj.DeepCopy(), false)).To(Succeed())
+								_, created, _ := cl.CreateArgsForCall(0)
+								hash := created.GetLabels()[repository.ContentHashLabel]
+
+								older := existingObj.DeepCopy()
+								older.SetName("hello-older")
+								older.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-time.Hour)))
+								latest := existingObj.DeepCopy()
+								latest.SetName("hello-latest")
+								latest.SetCreationTimestamp(metav1.NewTime(time.Now()))
+								latest.SetLabels(map[string]string{repository.ContentHashLabel: hash})
+								existingObjList.Items = []unstructured.Unstructured{*latest, *older}
+
+								Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, false)).To(Succeed())
+								Expect(cl.CreateCallCount()).To(Equal(1))
+								Expect(stampedObj.GetName()).To(Equal("hello-latest"))
+							})
+
+							It("creates the object again when the latest one was created for other content", func() {
+								Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj.DeepCopy(), false)).To(Succeed())
+								_, created, _ := cl.CreateArgsForCall(0)
+								hash := created.GetLabels()[repository.ContentHashLabel]
+
+								same := existingObj.DeepCopy()
+								same.SetName("hello-same")
+								same.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-time.Hour)))
+								same.SetLabels(map[string]string{repository.ContentHashLabel: hash})
+								other := existingObj.DeepCopy()
+								other.SetName("hello-other")
+								other.SetCreationTimestamp(metav1.NewTime(time.Now()))
+								other.SetLabels(map[string]string{repository.ContentHashLabel: "0123456789abcdef0123"})
+								existingObjList.Items = []unstructured.Unstructured{*same, *other}
+
+								Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, false)).To(Succeed())
+								Expect(cl.CreateCallCount()).To(Equal(2))
+							})
+						})
+
 						Context("and the create succeeds", func() {
 							var returnedCreatedObj *unstructured.Unstructured
 
@@ -354,13 +700,13 @@ spec:
 							})
 
 							It("does not return an error", func() {
-								Expect(repo.EnsureObjectExistsOnCluster(stampedObj, false)).To(Succeed())
+								Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, false)).To(Succeed())
 							})
 
 							It("caches the submitted and persisted objects, as the persisted one may be modified by mutating webhooks", func() {
 								originalStampedObj := stampedObj.DeepCopy()
 
-								Expect(repo.EnsureObjectExistsOnCluster(stampedObj, false)).To(Succeed())
+								Expect(repo.EnsureObjectExistsOnCluster(ctx, stampedObj, false)).To(Succeed())
 								Expect(cache.SetCallCount()).To(Equal(1))
 								submitted, persisted := cache.SetArgsForCall(0)
 								Expect(*submitted).To(Equal(*originalStampedObj))
@@ -373,12 +719,12 @@ spec:
 								cl.CreateReturns(errors.New("some-error"))
 							})
 							It("returns a helpful error", func() {
-								err := repo.EnsureObjectExistsOnCluster(stampedObj, false)
+								err := repo.EnsureObjectExistsOnCluster(ctx, stampedObj, false)
 								Expect(err).To(MatchError(ContainSubstring("create: some-error")))
 							})
 
 							It("does not write to the submitted or persisted cache", func() {
-								_ = repo.EnsureObjectExistsOnCluster(stampedObj, false)
+								_ = repo.EnsureObjectExistsOnCluster(ctx, stampedObj, false)
 								Expect(cache.SetCallCount()).To(Equal(0))
 							})
 						})
@@ -387,13 +733,30 @@ spec:
 			})
 		})
 
+		Context("DeleteUnstructured", func() {
+			It("deletes dependents of the object in the background", func() {
+				obj := &unstructured.Unstructured{}
+				obj.SetAPIVersion("batch/v1")
+				obj.SetKind("Job")
+				obj.SetNamespace("default")
+				obj.SetName("migrate")
+
+				Expect(repo.DeleteUnstructured(ctx, obj)).To(Succeed())
+
+				Expect(cl.DeleteCallCount()).To(Equal(1))
+				_, deleted, options := cl.DeleteArgsForCall(0)
+				Expect(deleted).To(Equal(obj))
+				Expect(options).To(ConsistOf(client.PropagationPolicy(metav1.DeletePropagationBackground)))
+			})
+		})
+
 		Context("GetSupplyChainsForWorkload", func() {
 			BeforeEach(func() {
 				cl.ListReturns(errors.New("some list error"))
 			})
 
 			It("attempts to list the object from the apiServer", func() {
-				_, err := repo.GetSupplyChainsForWorkload(&v1alpha1.Workload{})
+				_, err := repo.GetSupplyChainsForWorkload(ctx, &v1alpha1.Workload{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("list supply chains:"))
 			})
@@ -405,7 +768,7 @@ spec:
 			})
 
 			It("attempts to get the object from the apiServer", func() {
-				_, err := repo.GetSupplyChain("sc-name")
+				_, err := repo.GetSupplyChain(ctx, "sc-name")
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("get:"))
 			})
@@ -417,7 +780,7 @@ spec:
 					reference := v1alpha1.ClusterTemplateReference{
 						Kind: "some-unsupported-kind",
 					}
-					_, err := repo.GetClusterTemplate(reference)
+					_, err := repo.GetClusterTemplate(ctx, reference)
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("get api template:"))
 				})
@@ -432,7 +795,7 @@ spec:
 						Kind: "ClusterImageTemplate",
 						Name: "image-template",
 					}
-					_, err := repo.GetClusterTemplate(reference)
+					_, err := repo.GetClusterTemplate(ctx, reference)
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("get:"))
 				})
@@ -445,7 +808,7 @@ spec:
 					reference := v1alpha1.DeliveryClusterTemplateReference{
 						Kind: "some-unsupported-kind",
 					}
-					_, err := repo.GetDeliveryClusterTemplate(reference)
+					_, err := repo.GetDeliveryClusterTemplate(ctx, reference)
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("get api template:"))
 				})
@@ -460,7 +823,7 @@ spec:
 						Kind: "ClusterImageTemplate",
 						Name: "image-template",
 					}
-					_, err := repo.GetDeliveryClusterTemplate(reference)
+					_, err := repo.GetDeliveryClusterTemplate(ctx, reference)
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("get:"))
 				})
@@ -475,7 +838,7 @@ spec:
 					cl.GetReturns(apiError)
 				})
 				It("returns the error", func() {
-					_, err := repo.GetDelivery("my-delivery")
+					_, err := repo.GetDelivery(ctx, "my-delivery")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring(apiError.Error()))
 				})
@@ -494,7 +857,7 @@ spec:
 					cl.GetReturns(apiError)
 				})
 				It("returns a nil result without error", func() {
-					delivery, err := repo.GetDelivery("my-delivery")
+					delivery, err := repo.GetDelivery(ctx, "my-delivery")
 					Expect(err).NotTo(HaveOccurred())
 					Expect(delivery).To(BeNil())
 				})
@@ -513,7 +876,7 @@ spec:
 				})
 
 				It("asks for the delivery by name", func() {
-					_, err := repo.GetDelivery("my-delivery")
+					_, err := repo.GetDelivery(ctx, "my-delivery")
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(cl.GetCallCount()).To(Equal(1))
@@ -525,7 +888,7 @@ spec:
 				})
 
 				It("returns the delivery without error", func() {
-					delivery, err := repo.GetDelivery("my-delivery")
+					delivery, err := repo.GetDelivery(ctx, "my-delivery")
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(delivery).To(Equal(apiDelivery))
@@ -572,10 +935,67 @@ spec:
 					Kind: "ClusterSourceTemplate",
 					Name: "some-name",
 				}
-				template, err := repo.GetClusterTemplate(templateRef)
+				template, err := repo.GetClusterTemplate(ctx, templateRef)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(template.GetName()).To(Equal("some-name"))
 			})
+
+			Context("when the reference pins a version", func() {
+				version := func(name, version string) *v1alpha1.ClusterSourceTemplate {
+					return &v1alpha1.ClusterSourceTemplate{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: name,
+							Labels: map[string]string{
+								v1alpha1.TemplateNameLabel:    "some-name",
+								v1alpha1.TemplateVersionLabel: version,
+							},
+						},
+						Spec: v1alpha1.SourceTemplateSpec{URLPath: ".status." + version},
+					}
+				}
+
+				BeforeEach(func() {
+					clientObjects = append(clientObjects, version("some-name-v2", "v2"), version("some-name-v3", "v3"))
+				})
+
+				It("gets the template labelled as that version", func() {
+					templateRef := v1alpha1.ClusterTemplateReference{
+						Kind:    "ClusterSourceTemplate",
+						Name:    "some-name",
+						Version: "v2",
+					}
+					template, err := repo.GetClusterTemplate(ctx, templateRef)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(template.GetName()).To(Equal("some-name-v2"))
+				})
+
+				It("returns a not found error when no template is labelled as that version", func() {
+					templateRef := v1alpha1.ClusterTemplateReference{
+						Kind:    "ClusterSourceTemplate",
+						Name:    "some-name",
+						Version: "v4",
+					}
+					_, err := repo.GetClusterTemplate(ctx, templateRef)
+					Expect(kerrors.IsNotFound(err)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring(`"some-name@v4" not found`)))
+				})
+
+				Context("when several templates are labelled as that version", func() {
+					BeforeEach(func() {
+						clientObjects = append(clientObjects, version("some-name-v2-copy", "v2"))
+					})
+
+					It("returns an error naming them", func() {
+						templateRef := v1alpha1.ClusterTemplateReference{
+							Kind:    "ClusterSourceTemplate",
+							Name:    "some-name",
+							Version: "v2",
+						}
+						_, err := repo.GetClusterTemplate(ctx, templateRef)
+						Expect(err).To(MatchError("get: 2 ClusterSourceTemplates are labelled as version 'v2' of 'some-name': some-name-v2, some-name-v2-copy"))
+					})
+				})
+			})
 		})
 
 		Context("GetDeliveryClusterTemplate", func() {
@@ -593,7 +1013,7 @@ spec:
 					Kind: "ClusterSourceTemplate",
 					Name: "some-name",
 				}
-				template, err := repo.GetDeliveryClusterTemplate(templateRef)
+				template, err := repo.GetDeliveryClusterTemplate(ctx, templateRef)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(template.GetName()).To(Equal("some-name"))
 			})
@@ -619,7 +1039,7 @@ spec:
 					Kind: "ClusterRunTemplate",
 					Name: "second-template",
 				}
-				template, err := repo.GetRunTemplate(templateRef)
+				template, err := repo.GetRunTemplate(ctx, templateRef)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(template.GetName()).To(Equal("second-template"))
 			})
@@ -637,14 +1057,14 @@ spec:
 			})
 
 			It("gets the workload successfully", func() {
-				workload, err := repo.GetWorkload("workload-name", "workload-namespace")
+				workload, err := repo.GetWorkload(ctx, "workload-name", "workload-namespace")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(workload.GetName()).To(Equal("workload-name"))
 			})
 
 			Context("workload doesnt exist", func() {
 				It("returns an error", func() {
-					_, err := repo.GetWorkload("workload-that-does-not-exist-name", "workload-namespace")
+					_, err := repo.GetWorkload(ctx, "workload-that-does-not-exist-name", "workload-namespace")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("get:"))
 				})
@@ -663,14 +1083,14 @@ spec:
 			})
 
 			It("gets the deliverable successfully", func() {
-				workload, err := repo.GetDeliverable("deliverable-name", "deliverable-namespace")
+				workload, err := repo.GetDeliverable(ctx, "deliverable-name", "deliverable-namespace")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(workload.GetName()).To(Equal("deliverable-name"))
 			})
 
 			Context("deliverable doesnt exist", func() {
 				It("returns an error", func() {
-					_, err := repo.GetDeliverable("deliverable-that-does-not-exist-name", "deliverable-namespace")
+					_, err := repo.GetDeliverable(ctx, "deliverable-that-does-not-exist-name", "deliverable-namespace")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("get:"))
 				})
@@ -689,14 +1109,14 @@ spec:
 			})
 
 			It("gets the pipeline successfully", func() {
-				pipeline, err := repo.GetPipeline("pipeline-name", "pipeline-namespace")
+				pipeline, err := repo.GetPipeline(ctx, "pipeline-name", "pipeline-namespace")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(pipeline.GetName()).To(Equal("pipeline-name"))
 			})
 
 			Context("pipeline doesnt exist", func() {
 				It("returns an error", func() {
-					_, err := repo.GetPipeline("pipeline-that-does-not-exist-name", "pipeline-namespace")
+					_, err := repo.GetPipeline(ctx, "pipeline-that-does-not-exist-name", "pipeline-namespace")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("get-pipeline:"))
 				})
@@ -714,14 +1134,14 @@ spec:
 			})
 
 			It("gets the supply chain successfully", func() {
-				sc, err := repo.GetSupplyChain("sc-name")
+				sc, err := repo.GetSupplyChain(ctx, "sc-name")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(sc.GetName()).To(Equal("sc-name"))
 			})
 
 			Context("supply chain doesnt exist", func() {
 				It("returns no error", func() {
-					sc, err := repo.GetSupplyChain("sc-that-does-not-exist-name")
+					sc, err := repo.GetSupplyChain(ctx, "sc-that-does-not-exist-name")
 					Expect(err).ToNot(HaveOccurred())
 					Expect(sc).To(BeNil())
 				})
@@ -753,7 +1173,7 @@ spec:
 						Spec:   v1alpha1.WorkloadSpec{},
 						Status: v1alpha1.WorkloadStatus{},
 					}
-					supplyChains, err := repo.GetSupplyChainsForWorkload(workload)
+					supplyChains, err := repo.GetSupplyChainsForWorkload(ctx, workload)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(supplyChains)).To(Equal(1))
 					Expect(supplyChains[0].Name).To(Equal("supplychain-name"))
@@ -793,7 +1213,7 @@ spec:
 						Spec:   v1alpha1.WorkloadSpec{},
 						Status: v1alpha1.WorkloadStatus{},
 					}
-					supplyChains, err := repo.GetSupplyChainsForWorkload(workload)
+					supplyChains, err := repo.GetSupplyChainsForWorkload(ctx, workload)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(supplyChains)).To(Equal(1))
 					Expect(supplyChains[0].Name).To(Equal("supplychain-name"))
@@ -809,11 +1229,275 @@ spec:
 						Spec:   v1alpha1.WorkloadSpec{},
 						Status: v1alpha1.WorkloadStatus{},
 					}
-					supplyChains, err := repo.GetSupplyChainsForWorkload(workload)
+					supplyChains, err := repo.GetSupplyChainsForWorkload(ctx, workload)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(supplyChains)).To(Equal(0))
 				})
 			})
 		})
+
+		Context("GetNamespacedSupplyChainsForWorkload", func() {
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1alpha1.SupplyChain{
+						ObjectMeta: metav1.ObjectMeta{Name: "matching", Namespace: "team-a"},
+						Spec:       v1alpha1.SupplyChainSpec{Selector: map[string]string{"foo": "bar"}},
+					},
+					&v1alpha1.SupplyChain{
+						ObjectMeta: metav1.ObjectMeta{Name: "other-selector", Namespace: "team-a"},
+						Spec:       v1alpha1.SupplyChainSpec{Selector: map[string]string{"foo": "baz"}},
+					},
+					&v1alpha1.SupplyChain{
+						ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "team-b"},
+						Spec:       v1alpha1.SupplyChainSpec{Selector: map[string]string{"foo": "bar"}},
+					},
+				}
+			})
+
+			It("returns the matching supply chains in the workload's namespace", func() {
+				workload := &v1alpha1.Workload{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "workload-name",
+						Namespace: "team-a",
+						Labels:    map[string]string{"foo": "bar"},
+					},
+				}
+				supplyChains, err := repo.GetNamespacedSupplyChainsForWorkload(ctx, workload)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(supplyChains)).To(Equal(1))
+				Expect(supplyChains[0].Name).To(Equal("matching"))
+			})
+		})
+
+		Context("ListWorkloads", func() {
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"}},
+					&v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "team-b"}},
+				}
+			})
+
+			It("returns the workloads in the namespace", func() {
+				workloads, err := repo.ListWorkloads(ctx, "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(workloads).To(HaveLen(1))
+				Expect(workloads[0].Name).To(Equal("web"))
+			})
+
+			It("returns the workloads in every namespace when none is given", func() {
+				workloads, err := repo.ListWorkloads(ctx, "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(workloads).To(HaveLen(2))
+			})
+		})
+
+		Context("ListDeliverables", func() {
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1alpha1.Deliverable{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"}},
+					&v1alpha1.Deliverable{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "team-b"}},
+				}
+			})
+
+			It("returns the deliverables in the namespace", func() {
+				deliverables, err := repo.ListDeliverables(ctx, "team-b")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deliverables).To(HaveLen(1))
+				Expect(deliverables[0].Name).To(Equal("api"))
+			})
+		})
+
+		Context("GetNamespacedSupplyChain", func() {
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1alpha1.SupplyChain{ObjectMeta: metav1.ObjectMeta{Name: "sc-name", Namespace: "team-a"}},
+				}
+			})
+
+			It("gets the supply chain successfully", func() {
+				sc, err := repo.GetNamespacedSupplyChain(ctx, "sc-name", "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sc.GetName()).To(Equal("sc-name"))
+			})
+
+			Context("supply chain doesnt exist in the namespace", func() {
+				It("returns no error", func() {
+					sc, err := repo.GetNamespacedSupplyChain(ctx, "sc-name", "team-b")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(sc).To(BeNil())
+				})
+			})
+		})
+
+		Context("GetNamespacedDeliveriesForDeliverable", func() {
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1alpha1.Delivery{
+						ObjectMeta: metav1.ObjectMeta{Name: "matching", Namespace: "team-a"},
+						Spec:       v1alpha1.ClusterDeliverySpec{Selector: map[string]string{"foo": "bar"}},
+					},
+					&v1alpha1.Delivery{
+						ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "team-b"},
+						Spec:       v1alpha1.ClusterDeliverySpec{Selector: map[string]string{"foo": "bar"}},
+					},
+				}
+			})
+
+			It("returns the matching deliveries in the deliverable's namespace", func() {
+				deliverable := &v1alpha1.Deliverable{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "deliverable-name",
+						Namespace: "team-a",
+						Labels:    map[string]string{"foo": "bar"},
+					},
+				}
+				deliveries, err := repo.GetNamespacedDeliveriesForDeliverable(ctx, deliverable)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(deliveries)).To(Equal(1))
+				Expect(deliveries[0].Name).To(Equal("matching"))
+			})
+		})
+
+		Context("GetNamespacedDelivery", func() {
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1alpha1.Delivery{ObjectMeta: metav1.ObjectMeta{Name: "delivery-name", Namespace: "team-a"}},
+				}
+			})
+
+			It("gets the delivery successfully", func() {
+				delivery, err := repo.GetNamespacedDelivery(ctx, "delivery-name", "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(delivery.GetName()).To(Equal("delivery-name"))
+			})
+
+			Context("delivery doesnt exist in the namespace", func() {
+				It("returns no error", func() {
+					delivery, err := repo.GetNamespacedDelivery(ctx, "delivery-name", "team-b")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(delivery).To(BeNil())
+				})
+			})
+		})
+
+		Context("Update", func() {
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Name: "workload-name", Namespace: "team-a"}},
+				}
+			})
+
+			It("updates the object", func() {
+				workload, err := repo.GetWorkload(ctx, "workload-name", "team-a")
+				Expect(err).ToNot(HaveOccurred())
+
+				workload.Finalizers = []string{"some-finalizer"}
+				Expect(repo.Update(ctx, workload)).To(Succeed())
+
+				updated, err := repo.GetWorkload(ctx, "workload-name", "team-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(updated.Finalizers).To(ConsistOf("some-finalizer"))
+			})
+		})
+
+		Context("DeleteUnstructured", func() {
+			var obj *unstructured.Unstructured
+
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-config", Namespace: "shared"}},
+				}
+
+				obj = &unstructured.Unstructured{}
+				obj.SetAPIVersion("v1")
+				obj.SetKind("ConfigMap")
+				obj.SetNamespace("shared")
+				obj.SetName("some-config")
+			})
+
+			It("deletes the object", func() {
+				Expect(repo.DeleteUnstructured(ctx, obj)).To(Succeed())
+
+				list, err := repo.ListUnstructured(ctx, obj)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(list).To(BeEmpty())
+			})
+
+			It("succeeds when the object is already gone", func() {
+				obj.SetName("missing")
+				Expect(repo.DeleteUnstructured(ctx, obj)).To(Succeed())
+			})
+		})
+
+		Context("GetUnstructured", func() {
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: "some-config", Namespace: "shared"},
+						Data:       map[string]string{"url": "https://example.com"},
+					},
+				}
+			})
+
+			It("reads the object into obj", func() {
+				obj := &unstructured.Unstructured{}
+				obj.SetAPIVersion("v1")
+				obj.SetKind("ConfigMap")
+				obj.SetNamespace("shared")
+				obj.SetName("some-config")
+
+				Expect(repo.GetUnstructured(ctx, obj)).To(Succeed())
+				Expect(obj.Object["data"]).To(Equal(map[string]interface{}{"url": "https://example.com"}))
+			})
+
+			It("returns an error when the object does not exist", func() {
+				obj := &unstructured.Unstructured{}
+				obj.SetAPIVersion("v1")
+				obj.SetKind("ConfigMap")
+				obj.SetNamespace("shared")
+				obj.SetName("missing")
+
+				err := repo.GetUnstructured(ctx, obj)
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		Context("EnsureImmutableObjectExistsOnCluster", func() {
+			var obj *unstructured.Unstructured
+
+			BeforeEach(func() {
+				clientObjects = []client.Object{
+					&v1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "shared"},
+						Data:       map[string]string{"version": "1"},
+					},
+				}
+
+				obj = &unstructured.Unstructured{}
+				obj.SetAPIVersion("v1")
+				obj.SetKind("ConfigMap")
+				obj.SetNamespace("shared")
+				obj.Object["data"] = map[string]interface{}{"version": "2"}
+			})
+
+			It("creates the object when none of its name exists", func() {
+				obj.SetName("new")
+				Expect(repo.EnsureImmutableObjectExistsOnCluster(ctx, obj)).To(Succeed())
+
+				created := &v1.ConfigMap{}
+				Expect(cl.Get(ctx, client.ObjectKey{Namespace: "shared", Name: "new"}, created)).To(Succeed())
+				Expect(created.Data).To(Equal(map[string]string{"version": "2"}))
+			})
+
+			It("leaves an existing object unchanged and returns it", func() {
+				obj.SetName("existing")
+				Expect(repo.EnsureImmutableObjectExistsOnCluster(ctx, obj)).To(Succeed())
+				Expect(obj.Object["data"]).To(Equal(map[string]interface{}{"version": "1"}))
+
+				existing := &v1.ConfigMap{}
+				Expect(cl.Get(ctx, client.ObjectKey{Namespace: "shared", Name: "existing"}, existing)).To(Succeed())
+				Expect(existing.Data).To(Equal(map[string]string{"version": "1"}))
+			})
+		})
 	})
 })
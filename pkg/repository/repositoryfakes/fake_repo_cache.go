@@ -9,10 +9,10 @@ import (
 )
 
 type FakeRepoCache struct {
-	RefreshStub        func(*unstructured.Unstructured)
-	refreshMutex       sync.RWMutex
-	refreshArgsForCall []struct {
-		arg1 *unstructured.Unstructured
+	EvictOwnedStub        func(map[string]string)
+	evictOwnedMutex       sync.RWMutex
+	evictOwnedArgsForCall []struct {
+		arg1 map[string]string
 	}
 	SetStub        func(*unstructured.Unstructured, *unstructured.Unstructured)
 	setMutex       sync.RWMutex
@@ -20,6 +20,17 @@ type FakeRepoCache struct {
 		arg1 *unstructured.Unstructured
 		arg2 *unstructured.Unstructured
 	}
+	SubmittedUnchangedStub        func(*unstructured.Unstructured) bool
+	submittedUnchangedMutex       sync.RWMutex
+	submittedUnchangedArgsForCall []struct {
+		arg1 *unstructured.Unstructured
+	}
+	submittedUnchangedReturns struct {
+		result1 bool
+	}
+	submittedUnchangedReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	UnchangedSinceCachedStub        func(*unstructured.Unstructured, []*unstructured.Unstructured) *unstructured.Unstructured
 	unchangedSinceCachedMutex       sync.RWMutex
 	unchangedSinceCachedArgsForCall []struct {
@@ -36,35 +47,35 @@ type FakeRepoCache struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeRepoCache) Refresh(arg1 *unstructured.Unstructured) {
-	fake.refreshMutex.Lock()
-	fake.refreshArgsForCall = append(fake.refreshArgsForCall, struct {
-		arg1 *unstructured.Unstructured
+func (fake *FakeRepoCache) EvictOwned(arg1 map[string]string) {
+	fake.evictOwnedMutex.Lock()
+	fake.evictOwnedArgsForCall = append(fake.evictOwnedArgsForCall, struct {
+		arg1 map[string]string
 	}{arg1})
-	stub := fake.RefreshStub
-	fake.recordInvocation("Refresh", []interface{}{arg1})
-	fake.refreshMutex.Unlock()
+	stub := fake.EvictOwnedStub
+	fake.recordInvocation("EvictOwned", []interface{}{arg1})
+	fake.evictOwnedMutex.Unlock()
 	if stub != nil {
-		fake.RefreshStub(arg1)
+		fake.EvictOwnedStub(arg1)
 	}
 }
 
-func (fake *FakeRepoCache) RefreshCallCount() int {
-	fake.refreshMutex.RLock()
-	defer fake.refreshMutex.RUnlock()
-	return len(fake.refreshArgsForCall)
+func (fake *FakeRepoCache) EvictOwnedCallCount() int {
+	fake.evictOwnedMutex.RLock()
+	defer fake.evictOwnedMutex.RUnlock()
+	return len(fake.evictOwnedArgsForCall)
 }
 
-func (fake *FakeRepoCache) RefreshCalls(stub func(*unstructured.Unstructured)) {
-	fake.refreshMutex.Lock()
-	defer fake.refreshMutex.Unlock()
-	fake.RefreshStub = stub
+func (fake *FakeRepoCache) EvictOwnedCalls(stub func(map[string]string)) {
+	fake.evictOwnedMutex.Lock()
+	defer fake.evictOwnedMutex.Unlock()
+	fake.EvictOwnedStub = stub
 }
 
-func (fake *FakeRepoCache) RefreshArgsForCall(i int) *unstructured.Unstructured {
-	fake.refreshMutex.RLock()
-	defer fake.refreshMutex.RUnlock()
-	argsForCall := fake.refreshArgsForCall[i]
+func (fake *FakeRepoCache) EvictOwnedArgsForCall(i int) map[string]string {
+	fake.evictOwnedMutex.RLock()
+	defer fake.evictOwnedMutex.RUnlock()
+	argsForCall := fake.evictOwnedArgsForCall[i]
 	return argsForCall.arg1
 }
 
@@ -101,6 +112,67 @@ func (fake *FakeRepoCache) SetArgsForCall(i int) (*unstructured.Unstructured, *u
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *FakeRepoCache) SubmittedUnchanged(arg1 *unstructured.Unstructured) bool {
+	fake.submittedUnchangedMutex.Lock()
+	ret, specificReturn := fake.submittedUnchangedReturnsOnCall[len(fake.submittedUnchangedArgsForCall)]
+	fake.submittedUnchangedArgsForCall = append(fake.submittedUnchangedArgsForCall, struct {
+		arg1 *unstructured.Unstructured
+	}{arg1})
+	stub := fake.SubmittedUnchangedStub
+	fakeReturns := fake.submittedUnchangedReturns
+	fake.recordInvocation("SubmittedUnchanged", []interface{}{arg1})
+	fake.submittedUnchangedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRepoCache) SubmittedUnchangedCallCount() int {
+	fake.submittedUnchangedMutex.RLock()
+	defer fake.submittedUnchangedMutex.RUnlock()
+	return len(fake.submittedUnchangedArgsForCall)
+}
+
+func (fake *FakeRepoCache) SubmittedUnchangedCalls(stub func(*unstructured.Unstructured) bool) {
+	fake.submittedUnchangedMutex.Lock()
+	defer fake.submittedUnchangedMutex.Unlock()
+	fake.SubmittedUnchangedStub = stub
+}
+
+func (fake *FakeRepoCache) SubmittedUnchangedArgsForCall(i int) *unstructured.Unstructured {
+	fake.submittedUnchangedMutex.RLock()
+	defer fake.submittedUnchangedMutex.RUnlock()
+	argsForCall := fake.submittedUnchangedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeRepoCache) SubmittedUnchangedReturns(result1 bool) {
+	fake.submittedUnchangedMutex.Lock()
+	defer fake.submittedUnchangedMutex.Unlock()
+	fake.SubmittedUnchangedStub = nil
+	fake.submittedUnchangedReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeRepoCache) SubmittedUnchangedReturnsOnCall(i int, result1 bool) {
+	fake.submittedUnchangedMutex.Lock()
+	defer fake.submittedUnchangedMutex.Unlock()
+	fake.SubmittedUnchangedStub = nil
+	if fake.submittedUnchangedReturnsOnCall == nil {
+		fake.submittedUnchangedReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.submittedUnchangedReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeRepoCache) UnchangedSinceCached(arg1 *unstructured.Unstructured, arg2 []*unstructured.Unstructured) *unstructured.Unstructured {
 	var arg2Copy []*unstructured.Unstructured
 	if arg2 != nil {
@@ -171,10 +243,12 @@ func (fake *FakeRepoCache) UnchangedSinceCachedReturnsOnCall(i int, result1 *uns
 func (fake *FakeRepoCache) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.refreshMutex.RLock()
-	defer fake.refreshMutex.RUnlock()
+	fake.evictOwnedMutex.RLock()
+	defer fake.evictOwnedMutex.RUnlock()
 	fake.setMutex.RLock()
 	defer fake.setMutex.RUnlock()
+	fake.submittedUnchangedMutex.RLock()
+	defer fake.submittedUnchangedMutex.RUnlock()
 	fake.unchangedSinceCachedMutex.RLock()
 	defer fake.unchangedSinceCachedMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -2,6 +2,7 @@
 package repositoryfakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
@@ -13,11 +14,36 @@ import (
 )
 
 type FakeRepository struct {
-	EnsureObjectExistsOnClusterStub        func(*unstructured.Unstructured, bool) error
+	DeleteUnstructuredStub        func(context.Context, *unstructured.Unstructured) error
+	deleteUnstructuredMutex       sync.RWMutex
+	deleteUnstructuredArgsForCall []struct {
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
+	}
+	deleteUnstructuredReturns struct {
+		result1 error
+	}
+	deleteUnstructuredReturnsOnCall map[int]struct {
+		result1 error
+	}
+	EnsureImmutableObjectExistsOnClusterStub        func(context.Context, *unstructured.Unstructured) error
+	ensureImmutableObjectExistsOnClusterMutex       sync.RWMutex
+	ensureImmutableObjectExistsOnClusterArgsForCall []struct {
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
+	}
+	ensureImmutableObjectExistsOnClusterReturns struct {
+		result1 error
+	}
+	ensureImmutableObjectExistsOnClusterReturnsOnCall map[int]struct {
+		result1 error
+	}
+	EnsureObjectExistsOnClusterStub        func(context.Context, *unstructured.Unstructured, bool) error
 	ensureObjectExistsOnClusterMutex       sync.RWMutex
 	ensureObjectExistsOnClusterArgsForCall []struct {
-		arg1 *unstructured.Unstructured
-		arg2 bool
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
+		arg3 bool
 	}
 	ensureObjectExistsOnClusterReturns struct {
 		result1 error
@@ -25,10 +51,39 @@ type FakeRepository struct {
 	ensureObjectExistsOnClusterReturnsOnCall map[int]struct {
 		result1 error
 	}
-	GetClusterTemplateStub        func(v1alpha1.ClusterTemplateReference) (templates.Template, error)
+	GetBlueprintStub        func(context.Context, string) (*v1alpha1.ClusterBlueprint, error)
+	getBlueprintMutex       sync.RWMutex
+	getBlueprintArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getBlueprintReturns struct {
+		result1 *v1alpha1.ClusterBlueprint
+		result2 error
+	}
+	getBlueprintReturnsOnCall map[int]struct {
+		result1 *v1alpha1.ClusterBlueprint
+		result2 error
+	}
+	GetBlueprintSourceStub        func(context.Context, string) (*v1alpha1.ClusterBlueprintSource, error)
+	getBlueprintSourceMutex       sync.RWMutex
+	getBlueprintSourceArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getBlueprintSourceReturns struct {
+		result1 *v1alpha1.ClusterBlueprintSource
+		result2 error
+	}
+	getBlueprintSourceReturnsOnCall map[int]struct {
+		result1 *v1alpha1.ClusterBlueprintSource
+		result2 error
+	}
+	GetClusterTemplateStub        func(context.Context, v1alpha1.ClusterTemplateReference) (templates.Template, error)
 	getClusterTemplateMutex       sync.RWMutex
 	getClusterTemplateArgsForCall []struct {
-		arg1 v1alpha1.ClusterTemplateReference
+		arg1 context.Context
+		arg2 v1alpha1.ClusterTemplateReference
 	}
 	getClusterTemplateReturns struct {
 		result1 templates.Template
@@ -38,11 +93,12 @@ type FakeRepository struct {
 		result1 templates.Template
 		result2 error
 	}
-	GetDeliverableStub        func(string, string) (*v1alpha1.Deliverable, error)
+	GetDeliverableStub        func(context.Context, string, string) (*v1alpha1.Deliverable, error)
 	getDeliverableMutex       sync.RWMutex
 	getDeliverableArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
+		arg3 string
 	}
 	getDeliverableReturns struct {
 		result1 *v1alpha1.Deliverable
@@ -52,10 +108,11 @@ type FakeRepository struct {
 		result1 *v1alpha1.Deliverable
 		result2 error
 	}
-	GetDeliveriesForDeliverableStub        func(*v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error)
+	GetDeliveriesForDeliverableStub        func(context.Context, *v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error)
 	getDeliveriesForDeliverableMutex       sync.RWMutex
 	getDeliveriesForDeliverableArgsForCall []struct {
-		arg1 *v1alpha1.Deliverable
+		arg1 context.Context
+		arg2 *v1alpha1.Deliverable
 	}
 	getDeliveriesForDeliverableReturns struct {
 		result1 []v1alpha1.ClusterDelivery
@@ -65,10 +122,11 @@ type FakeRepository struct {
 		result1 []v1alpha1.ClusterDelivery
 		result2 error
 	}
-	GetDeliveryStub        func(string) (*v1alpha1.ClusterDelivery, error)
+	GetDeliveryStub        func(context.Context, string) (*v1alpha1.ClusterDelivery, error)
 	getDeliveryMutex       sync.RWMutex
 	getDeliveryArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	getDeliveryReturns struct {
 		result1 *v1alpha1.ClusterDelivery
@@ -78,10 +136,11 @@ type FakeRepository struct {
 		result1 *v1alpha1.ClusterDelivery
 		result2 error
 	}
-	GetDeliveryClusterTemplateStub        func(v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error)
+	GetDeliveryClusterTemplateStub        func(context.Context, v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error)
 	getDeliveryClusterTemplateMutex       sync.RWMutex
 	getDeliveryClusterTemplateArgsForCall []struct {
-		arg1 v1alpha1.DeliveryClusterTemplateReference
+		arg1 context.Context
+		arg2 v1alpha1.DeliveryClusterTemplateReference
 	}
 	getDeliveryClusterTemplateReturns struct {
 		result1 templates.Template
@@ -91,11 +150,70 @@ type FakeRepository struct {
 		result1 templates.Template
 		result2 error
 	}
-	GetPipelineStub        func(string, string) (*v1alpha1.Pipeline, error)
+	GetNamespacedDeliveriesForDeliverableStub        func(context.Context, *v1alpha1.Deliverable) ([]v1alpha1.Delivery, error)
+	getNamespacedDeliveriesForDeliverableMutex       sync.RWMutex
+	getNamespacedDeliveriesForDeliverableArgsForCall []struct {
+		arg1 context.Context
+		arg2 *v1alpha1.Deliverable
+	}
+	getNamespacedDeliveriesForDeliverableReturns struct {
+		result1 []v1alpha1.Delivery
+		result2 error
+	}
+	getNamespacedDeliveriesForDeliverableReturnsOnCall map[int]struct {
+		result1 []v1alpha1.Delivery
+		result2 error
+	}
+	GetNamespacedDeliveryStub        func(context.Context, string, string) (*v1alpha1.Delivery, error)
+	getNamespacedDeliveryMutex       sync.RWMutex
+	getNamespacedDeliveryArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	getNamespacedDeliveryReturns struct {
+		result1 *v1alpha1.Delivery
+		result2 error
+	}
+	getNamespacedDeliveryReturnsOnCall map[int]struct {
+		result1 *v1alpha1.Delivery
+		result2 error
+	}
+	GetNamespacedSupplyChainStub        func(context.Context, string, string) (*v1alpha1.SupplyChain, error)
+	getNamespacedSupplyChainMutex       sync.RWMutex
+	getNamespacedSupplyChainArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	getNamespacedSupplyChainReturns struct {
+		result1 *v1alpha1.SupplyChain
+		result2 error
+	}
+	getNamespacedSupplyChainReturnsOnCall map[int]struct {
+		result1 *v1alpha1.SupplyChain
+		result2 error
+	}
+	GetNamespacedSupplyChainsForWorkloadStub        func(context.Context, *v1alpha1.Workload) ([]v1alpha1.SupplyChain, error)
+	getNamespacedSupplyChainsForWorkloadMutex       sync.RWMutex
+	getNamespacedSupplyChainsForWorkloadArgsForCall []struct {
+		arg1 context.Context
+		arg2 *v1alpha1.Workload
+	}
+	getNamespacedSupplyChainsForWorkloadReturns struct {
+		result1 []v1alpha1.SupplyChain
+		result2 error
+	}
+	getNamespacedSupplyChainsForWorkloadReturnsOnCall map[int]struct {
+		result1 []v1alpha1.SupplyChain
+		result2 error
+	}
+	GetPipelineStub        func(context.Context, string, string) (*v1alpha1.Pipeline, error)
 	getPipelineMutex       sync.RWMutex
 	getPipelineArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
+		arg3 string
 	}
 	getPipelineReturns struct {
 		result1 *v1alpha1.Pipeline
@@ -105,10 +223,11 @@ type FakeRepository struct {
 		result1 *v1alpha1.Pipeline
 		result2 error
 	}
-	GetRunTemplateStub        func(v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error)
+	GetRunTemplateStub        func(context.Context, v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error)
 	getRunTemplateMutex       sync.RWMutex
 	getRunTemplateArgsForCall []struct {
-		arg1 v1alpha1.TemplateReference
+		arg1 context.Context
+		arg2 v1alpha1.TemplateReference
 	}
 	getRunTemplateReturns struct {
 		result1 templates.ClusterRunTemplate
@@ -128,10 +247,11 @@ type FakeRepository struct {
 	getSchemeReturnsOnCall map[int]struct {
 		result1 *runtime.Scheme
 	}
-	GetSupplyChainStub        func(string) (*v1alpha1.ClusterSupplyChain, error)
+	GetSupplyChainStub        func(context.Context, string) (*v1alpha1.ClusterSupplyChain, error)
 	getSupplyChainMutex       sync.RWMutex
 	getSupplyChainArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	getSupplyChainReturns struct {
 		result1 *v1alpha1.ClusterSupplyChain
@@ -141,10 +261,11 @@ type FakeRepository struct {
 		result1 *v1alpha1.ClusterSupplyChain
 		result2 error
 	}
-	GetSupplyChainsForWorkloadStub        func(*v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error)
+	GetSupplyChainsForWorkloadStub        func(context.Context, *v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error)
 	getSupplyChainsForWorkloadMutex       sync.RWMutex
 	getSupplyChainsForWorkloadArgsForCall []struct {
-		arg1 *v1alpha1.Workload
+		arg1 context.Context
+		arg2 *v1alpha1.Workload
 	}
 	getSupplyChainsForWorkloadReturns struct {
 		result1 []v1alpha1.ClusterSupplyChain
@@ -154,11 +275,38 @@ type FakeRepository struct {
 		result1 []v1alpha1.ClusterSupplyChain
 		result2 error
 	}
-	GetWorkloadStub        func(string, string) (*v1alpha1.Workload, error)
+	GetTemplateLibraryStub        func(context.Context, string) (*v1alpha1.ClusterTemplateLibrary, error)
+	getTemplateLibraryMutex       sync.RWMutex
+	getTemplateLibraryArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getTemplateLibraryReturns struct {
+		result1 *v1alpha1.ClusterTemplateLibrary
+		result2 error
+	}
+	getTemplateLibraryReturnsOnCall map[int]struct {
+		result1 *v1alpha1.ClusterTemplateLibrary
+		result2 error
+	}
+	GetUnstructuredStub        func(context.Context, *unstructured.Unstructured) error
+	getUnstructuredMutex       sync.RWMutex
+	getUnstructuredArgsForCall []struct {
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
+	}
+	getUnstructuredReturns struct {
+		result1 error
+	}
+	getUnstructuredReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetWorkloadStub        func(context.Context, string, string) (*v1alpha1.Workload, error)
 	getWorkloadMutex       sync.RWMutex
 	getWorkloadArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
+		arg3 string
 	}
 	getWorkloadReturns struct {
 		result1 *v1alpha1.Workload
@@ -168,10 +316,25 @@ type FakeRepository struct {
 		result1 *v1alpha1.Workload
 		result2 error
 	}
-	ListUnstructuredStub        func(*unstructured.Unstructured) ([]*unstructured.Unstructured, error)
+	ListDeliverablesStub        func(context.Context, string) ([]v1alpha1.Deliverable, error)
+	listDeliverablesMutex       sync.RWMutex
+	listDeliverablesArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listDeliverablesReturns struct {
+		result1 []v1alpha1.Deliverable
+		result2 error
+	}
+	listDeliverablesReturnsOnCall map[int]struct {
+		result1 []v1alpha1.Deliverable
+		result2 error
+	}
+	ListUnstructuredStub        func(context.Context, *unstructured.Unstructured) ([]*unstructured.Unstructured, error)
 	listUnstructuredMutex       sync.RWMutex
 	listUnstructuredArgsForCall []struct {
-		arg1 *unstructured.Unstructured
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
 	}
 	listUnstructuredReturns struct {
 		result1 []*unstructured.Unstructured
@@ -181,10 +344,25 @@ type FakeRepository struct {
 		result1 []*unstructured.Unstructured
 		result2 error
 	}
-	StatusUpdateStub        func(client.Object) error
+	ListWorkloadsStub        func(context.Context, string) ([]v1alpha1.Workload, error)
+	listWorkloadsMutex       sync.RWMutex
+	listWorkloadsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listWorkloadsReturns struct {
+		result1 []v1alpha1.Workload
+		result2 error
+	}
+	listWorkloadsReturnsOnCall map[int]struct {
+		result1 []v1alpha1.Workload
+		result2 error
+	}
+	StatusUpdateStub        func(context.Context, client.Object) error
 	statusUpdateMutex       sync.RWMutex
 	statusUpdateArgsForCall []struct {
-		arg1 client.Object
+		arg1 context.Context
+		arg2 client.Object
 	}
 	statusUpdateReturns struct {
 		result1 error
@@ -192,23 +370,160 @@ type FakeRepository struct {
 	statusUpdateReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UpdateStub        func(context.Context, client.Object) error
+	updateMutex       sync.RWMutex
+	updateArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Object
+	}
+	updateReturns struct {
+		result1 error
+	}
+	updateReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeRepository) EnsureObjectExistsOnCluster(arg1 *unstructured.Unstructured, arg2 bool) error {
+func (fake *FakeRepository) DeleteUnstructured(arg1 context.Context, arg2 *unstructured.Unstructured) error {
+	fake.deleteUnstructuredMutex.Lock()
+	ret, specificReturn := fake.deleteUnstructuredReturnsOnCall[len(fake.deleteUnstructuredArgsForCall)]
+	fake.deleteUnstructuredArgsForCall = append(fake.deleteUnstructuredArgsForCall, struct {
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
+	}{arg1, arg2})
+	stub := fake.DeleteUnstructuredStub
+	fakeReturns := fake.deleteUnstructuredReturns
+	fake.recordInvocation("DeleteUnstructured", []interface{}{arg1, arg2})
+	fake.deleteUnstructuredMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRepository) DeleteUnstructuredCallCount() int {
+	fake.deleteUnstructuredMutex.RLock()
+	defer fake.deleteUnstructuredMutex.RUnlock()
+	return len(fake.deleteUnstructuredArgsForCall)
+}
+
+func (fake *FakeRepository) DeleteUnstructuredCalls(stub func(context.Context, *unstructured.Unstructured) error) {
+	fake.deleteUnstructuredMutex.Lock()
+	defer fake.deleteUnstructuredMutex.Unlock()
+	fake.DeleteUnstructuredStub = stub
+}
+
+func (fake *FakeRepository) DeleteUnstructuredArgsForCall(i int) (context.Context, *unstructured.Unstructured) {
+	fake.deleteUnstructuredMutex.RLock()
+	defer fake.deleteUnstructuredMutex.RUnlock()
+	argsForCall := fake.deleteUnstructuredArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) DeleteUnstructuredReturns(result1 error) {
+	fake.deleteUnstructuredMutex.Lock()
+	defer fake.deleteUnstructuredMutex.Unlock()
+	fake.DeleteUnstructuredStub = nil
+	fake.deleteUnstructuredReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) DeleteUnstructuredReturnsOnCall(i int, result1 error) {
+	fake.deleteUnstructuredMutex.Lock()
+	defer fake.deleteUnstructuredMutex.Unlock()
+	fake.DeleteUnstructuredStub = nil
+	if fake.deleteUnstructuredReturnsOnCall == nil {
+		fake.deleteUnstructuredReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteUnstructuredReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) EnsureImmutableObjectExistsOnCluster(arg1 context.Context, arg2 *unstructured.Unstructured) error {
+	fake.ensureImmutableObjectExistsOnClusterMutex.Lock()
+	ret, specificReturn := fake.ensureImmutableObjectExistsOnClusterReturnsOnCall[len(fake.ensureImmutableObjectExistsOnClusterArgsForCall)]
+	fake.ensureImmutableObjectExistsOnClusterArgsForCall = append(fake.ensureImmutableObjectExistsOnClusterArgsForCall, struct {
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
+	}{arg1, arg2})
+	stub := fake.EnsureImmutableObjectExistsOnClusterStub
+	fakeReturns := fake.ensureImmutableObjectExistsOnClusterReturns
+	fake.recordInvocation("EnsureImmutableObjectExistsOnCluster", []interface{}{arg1, arg2})
+	fake.ensureImmutableObjectExistsOnClusterMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRepository) EnsureImmutableObjectExistsOnClusterCallCount() int {
+	fake.ensureImmutableObjectExistsOnClusterMutex.RLock()
+	defer fake.ensureImmutableObjectExistsOnClusterMutex.RUnlock()
+	return len(fake.ensureImmutableObjectExistsOnClusterArgsForCall)
+}
+
+func (fake *FakeRepository) EnsureImmutableObjectExistsOnClusterCalls(stub func(context.Context, *unstructured.Unstructured) error) {
+	fake.ensureImmutableObjectExistsOnClusterMutex.Lock()
+	defer fake.ensureImmutableObjectExistsOnClusterMutex.Unlock()
+	fake.EnsureImmutableObjectExistsOnClusterStub = stub
+}
+
+func (fake *FakeRepository) EnsureImmutableObjectExistsOnClusterArgsForCall(i int) (context.Context, *unstructured.Unstructured) {
+	fake.ensureImmutableObjectExistsOnClusterMutex.RLock()
+	defer fake.ensureImmutableObjectExistsOnClusterMutex.RUnlock()
+	argsForCall := fake.ensureImmutableObjectExistsOnClusterArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) EnsureImmutableObjectExistsOnClusterReturns(result1 error) {
+	fake.ensureImmutableObjectExistsOnClusterMutex.Lock()
+	defer fake.ensureImmutableObjectExistsOnClusterMutex.Unlock()
+	fake.EnsureImmutableObjectExistsOnClusterStub = nil
+	fake.ensureImmutableObjectExistsOnClusterReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) EnsureImmutableObjectExistsOnClusterReturnsOnCall(i int, result1 error) {
+	fake.ensureImmutableObjectExistsOnClusterMutex.Lock()
+	defer fake.ensureImmutableObjectExistsOnClusterMutex.Unlock()
+	fake.EnsureImmutableObjectExistsOnClusterStub = nil
+	if fake.ensureImmutableObjectExistsOnClusterReturnsOnCall == nil {
+		fake.ensureImmutableObjectExistsOnClusterReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.ensureImmutableObjectExistsOnClusterReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) EnsureObjectExistsOnCluster(arg1 context.Context, arg2 *unstructured.Unstructured, arg3 bool) error {
 	fake.ensureObjectExistsOnClusterMutex.Lock()
 	ret, specificReturn := fake.ensureObjectExistsOnClusterReturnsOnCall[len(fake.ensureObjectExistsOnClusterArgsForCall)]
 	fake.ensureObjectExistsOnClusterArgsForCall = append(fake.ensureObjectExistsOnClusterArgsForCall, struct {
-		arg1 *unstructured.Unstructured
-		arg2 bool
-	}{arg1, arg2})
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
+		arg3 bool
+	}{arg1, arg2, arg3})
 	stub := fake.EnsureObjectExistsOnClusterStub
 	fakeReturns := fake.ensureObjectExistsOnClusterReturns
-	fake.recordInvocation("EnsureObjectExistsOnCluster", []interface{}{arg1, arg2})
+	fake.recordInvocation("EnsureObjectExistsOnCluster", []interface{}{arg1, arg2, arg3})
 	fake.ensureObjectExistsOnClusterMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
@@ -222,17 +537,17 @@ func (fake *FakeRepository) EnsureObjectExistsOnClusterCallCount() int {
 	return len(fake.ensureObjectExistsOnClusterArgsForCall)
 }
 
-func (fake *FakeRepository) EnsureObjectExistsOnClusterCalls(stub func(*unstructured.Unstructured, bool) error) {
+func (fake *FakeRepository) EnsureObjectExistsOnClusterCalls(stub func(context.Context, *unstructured.Unstructured, bool) error) {
 	fake.ensureObjectExistsOnClusterMutex.Lock()
 	defer fake.ensureObjectExistsOnClusterMutex.Unlock()
 	fake.EnsureObjectExistsOnClusterStub = stub
 }
 
-func (fake *FakeRepository) EnsureObjectExistsOnClusterArgsForCall(i int) (*unstructured.Unstructured, bool) {
+func (fake *FakeRepository) EnsureObjectExistsOnClusterArgsForCall(i int) (context.Context, *unstructured.Unstructured, bool) {
 	fake.ensureObjectExistsOnClusterMutex.RLock()
 	defer fake.ensureObjectExistsOnClusterMutex.RUnlock()
 	argsForCall := fake.ensureObjectExistsOnClusterArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeRepository) EnsureObjectExistsOnClusterReturns(result1 error) {
@@ -258,18 +573,149 @@ func (fake *FakeRepository) EnsureObjectExistsOnClusterReturnsOnCall(i int, resu
 	}{result1}
 }
 
-func (fake *FakeRepository) GetClusterTemplate(arg1 v1alpha1.ClusterTemplateReference) (templates.Template, error) {
+func (fake *FakeRepository) GetBlueprint(arg1 context.Context, arg2 string) (*v1alpha1.ClusterBlueprint, error) {
+	fake.getBlueprintMutex.Lock()
+	ret, specificReturn := fake.getBlueprintReturnsOnCall[len(fake.getBlueprintArgsForCall)]
+	fake.getBlueprintArgsForCall = append(fake.getBlueprintArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetBlueprintStub
+	fakeReturns := fake.getBlueprintReturns
+	fake.recordInvocation("GetBlueprint", []interface{}{arg1, arg2})
+	fake.getBlueprintMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) GetBlueprintCallCount() int {
+	fake.getBlueprintMutex.RLock()
+	defer fake.getBlueprintMutex.RUnlock()
+	return len(fake.getBlueprintArgsForCall)
+}
+
+func (fake *FakeRepository) GetBlueprintCalls(stub func(context.Context, string) (*v1alpha1.ClusterBlueprint, error)) {
+	fake.getBlueprintMutex.Lock()
+	defer fake.getBlueprintMutex.Unlock()
+	fake.GetBlueprintStub = stub
+}
+
+func (fake *FakeRepository) GetBlueprintArgsForCall(i int) (context.Context, string) {
+	fake.getBlueprintMutex.RLock()
+	defer fake.getBlueprintMutex.RUnlock()
+	argsForCall := fake.getBlueprintArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) GetBlueprintReturns(result1 *v1alpha1.ClusterBlueprint, result2 error) {
+	fake.getBlueprintMutex.Lock()
+	defer fake.getBlueprintMutex.Unlock()
+	fake.GetBlueprintStub = nil
+	fake.getBlueprintReturns = struct {
+		result1 *v1alpha1.ClusterBlueprint
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetBlueprintReturnsOnCall(i int, result1 *v1alpha1.ClusterBlueprint, result2 error) {
+	fake.getBlueprintMutex.Lock()
+	defer fake.getBlueprintMutex.Unlock()
+	fake.GetBlueprintStub = nil
+	if fake.getBlueprintReturnsOnCall == nil {
+		fake.getBlueprintReturnsOnCall = make(map[int]struct {
+			result1 *v1alpha1.ClusterBlueprint
+			result2 error
+		})
+	}
+	fake.getBlueprintReturnsOnCall[i] = struct {
+		result1 *v1alpha1.ClusterBlueprint
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetBlueprintSource(arg1 context.Context, arg2 string) (*v1alpha1.ClusterBlueprintSource, error) {
+	fake.getBlueprintSourceMutex.Lock()
+	ret, specificReturn := fake.getBlueprintSourceReturnsOnCall[len(fake.getBlueprintSourceArgsForCall)]
+	fake.getBlueprintSourceArgsForCall = append(fake.getBlueprintSourceArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetBlueprintSourceStub
+	fakeReturns := fake.getBlueprintSourceReturns
+	fake.recordInvocation("GetBlueprintSource", []interface{}{arg1, arg2})
+	fake.getBlueprintSourceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) GetBlueprintSourceCallCount() int {
+	fake.getBlueprintSourceMutex.RLock()
+	defer fake.getBlueprintSourceMutex.RUnlock()
+	return len(fake.getBlueprintSourceArgsForCall)
+}
+
+func (fake *FakeRepository) GetBlueprintSourceCalls(stub func(context.Context, string) (*v1alpha1.ClusterBlueprintSource, error)) {
+	fake.getBlueprintSourceMutex.Lock()
+	defer fake.getBlueprintSourceMutex.Unlock()
+	fake.GetBlueprintSourceStub = stub
+}
+
+func (fake *FakeRepository) GetBlueprintSourceArgsForCall(i int) (context.Context, string) {
+	fake.getBlueprintSourceMutex.RLock()
+	defer fake.getBlueprintSourceMutex.RUnlock()
+	argsForCall := fake.getBlueprintSourceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) GetBlueprintSourceReturns(result1 *v1alpha1.ClusterBlueprintSource, result2 error) {
+	fake.getBlueprintSourceMutex.Lock()
+	defer fake.getBlueprintSourceMutex.Unlock()
+	fake.GetBlueprintSourceStub = nil
+	fake.getBlueprintSourceReturns = struct {
+		result1 *v1alpha1.ClusterBlueprintSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetBlueprintSourceReturnsOnCall(i int, result1 *v1alpha1.ClusterBlueprintSource, result2 error) {
+	fake.getBlueprintSourceMutex.Lock()
+	defer fake.getBlueprintSourceMutex.Unlock()
+	fake.GetBlueprintSourceStub = nil
+	if fake.getBlueprintSourceReturnsOnCall == nil {
+		fake.getBlueprintSourceReturnsOnCall = make(map[int]struct {
+			result1 *v1alpha1.ClusterBlueprintSource
+			result2 error
+		})
+	}
+	fake.getBlueprintSourceReturnsOnCall[i] = struct {
+		result1 *v1alpha1.ClusterBlueprintSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetClusterTemplate(arg1 context.Context, arg2 v1alpha1.ClusterTemplateReference) (templates.Template, error) {
 	fake.getClusterTemplateMutex.Lock()
 	ret, specificReturn := fake.getClusterTemplateReturnsOnCall[len(fake.getClusterTemplateArgsForCall)]
 	fake.getClusterTemplateArgsForCall = append(fake.getClusterTemplateArgsForCall, struct {
-		arg1 v1alpha1.ClusterTemplateReference
-	}{arg1})
+		arg1 context.Context
+		arg2 v1alpha1.ClusterTemplateReference
+	}{arg1, arg2})
 	stub := fake.GetClusterTemplateStub
 	fakeReturns := fake.getClusterTemplateReturns
-	fake.recordInvocation("GetClusterTemplate", []interface{}{arg1})
+	fake.recordInvocation("GetClusterTemplate", []interface{}{arg1, arg2})
 	fake.getClusterTemplateMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -283,17 +729,17 @@ func (fake *FakeRepository) GetClusterTemplateCallCount() int {
 	return len(fake.getClusterTemplateArgsForCall)
 }
 
-func (fake *FakeRepository) GetClusterTemplateCalls(stub func(v1alpha1.ClusterTemplateReference) (templates.Template, error)) {
+func (fake *FakeRepository) GetClusterTemplateCalls(stub func(context.Context, v1alpha1.ClusterTemplateReference) (templates.Template, error)) {
 	fake.getClusterTemplateMutex.Lock()
 	defer fake.getClusterTemplateMutex.Unlock()
 	fake.GetClusterTemplateStub = stub
 }
 
-func (fake *FakeRepository) GetClusterTemplateArgsForCall(i int) v1alpha1.ClusterTemplateReference {
+func (fake *FakeRepository) GetClusterTemplateArgsForCall(i int) (context.Context, v1alpha1.ClusterTemplateReference) {
 	fake.getClusterTemplateMutex.RLock()
 	defer fake.getClusterTemplateMutex.RUnlock()
 	argsForCall := fake.getClusterTemplateArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeRepository) GetClusterTemplateReturns(result1 templates.Template, result2 error) {
@@ -322,19 +768,20 @@ func (fake *FakeRepository) GetClusterTemplateReturnsOnCall(i int, result1 templ
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) GetDeliverable(arg1 string, arg2 string) (*v1alpha1.Deliverable, error) {
+func (fake *FakeRepository) GetDeliverable(arg1 context.Context, arg2 string, arg3 string) (*v1alpha1.Deliverable, error) {
 	fake.getDeliverableMutex.Lock()
 	ret, specificReturn := fake.getDeliverableReturnsOnCall[len(fake.getDeliverableArgsForCall)]
 	fake.getDeliverableArgsForCall = append(fake.getDeliverableArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-	}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
 	stub := fake.GetDeliverableStub
 	fakeReturns := fake.getDeliverableReturns
-	fake.recordInvocation("GetDeliverable", []interface{}{arg1, arg2})
+	fake.recordInvocation("GetDeliverable", []interface{}{arg1, arg2, arg3})
 	fake.getDeliverableMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -348,17 +795,17 @@ func (fake *FakeRepository) GetDeliverableCallCount() int {
 	return len(fake.getDeliverableArgsForCall)
 }
 
-func (fake *FakeRepository) GetDeliverableCalls(stub func(string, string) (*v1alpha1.Deliverable, error)) {
+func (fake *FakeRepository) GetDeliverableCalls(stub func(context.Context, string, string) (*v1alpha1.Deliverable, error)) {
 	fake.getDeliverableMutex.Lock()
 	defer fake.getDeliverableMutex.Unlock()
 	fake.GetDeliverableStub = stub
 }
 
-func (fake *FakeRepository) GetDeliverableArgsForCall(i int) (string, string) {
+func (fake *FakeRepository) GetDeliverableArgsForCall(i int) (context.Context, string, string) {
 	fake.getDeliverableMutex.RLock()
 	defer fake.getDeliverableMutex.RUnlock()
 	argsForCall := fake.getDeliverableArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeRepository) GetDeliverableReturns(result1 *v1alpha1.Deliverable, result2 error) {
@@ -387,18 +834,19 @@ func (fake *FakeRepository) GetDeliverableReturnsOnCall(i int, result1 *v1alpha1
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) GetDeliveriesForDeliverable(arg1 *v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error) {
+func (fake *FakeRepository) GetDeliveriesForDeliverable(arg1 context.Context, arg2 *v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error) {
 	fake.getDeliveriesForDeliverableMutex.Lock()
 	ret, specificReturn := fake.getDeliveriesForDeliverableReturnsOnCall[len(fake.getDeliveriesForDeliverableArgsForCall)]
 	fake.getDeliveriesForDeliverableArgsForCall = append(fake.getDeliveriesForDeliverableArgsForCall, struct {
-		arg1 *v1alpha1.Deliverable
-	}{arg1})
+		arg1 context.Context
+		arg2 *v1alpha1.Deliverable
+	}{arg1, arg2})
 	stub := fake.GetDeliveriesForDeliverableStub
 	fakeReturns := fake.getDeliveriesForDeliverableReturns
-	fake.recordInvocation("GetDeliveriesForDeliverable", []interface{}{arg1})
+	fake.recordInvocation("GetDeliveriesForDeliverable", []interface{}{arg1, arg2})
 	fake.getDeliveriesForDeliverableMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -412,17 +860,17 @@ func (fake *FakeRepository) GetDeliveriesForDeliverableCallCount() int {
 	return len(fake.getDeliveriesForDeliverableArgsForCall)
 }
 
-func (fake *FakeRepository) GetDeliveriesForDeliverableCalls(stub func(*v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error)) {
+func (fake *FakeRepository) GetDeliveriesForDeliverableCalls(stub func(context.Context, *v1alpha1.Deliverable) ([]v1alpha1.ClusterDelivery, error)) {
 	fake.getDeliveriesForDeliverableMutex.Lock()
 	defer fake.getDeliveriesForDeliverableMutex.Unlock()
 	fake.GetDeliveriesForDeliverableStub = stub
 }
 
-func (fake *FakeRepository) GetDeliveriesForDeliverableArgsForCall(i int) *v1alpha1.Deliverable {
+func (fake *FakeRepository) GetDeliveriesForDeliverableArgsForCall(i int) (context.Context, *v1alpha1.Deliverable) {
 	fake.getDeliveriesForDeliverableMutex.RLock()
 	defer fake.getDeliveriesForDeliverableMutex.RUnlock()
 	argsForCall := fake.getDeliveriesForDeliverableArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeRepository) GetDeliveriesForDeliverableReturns(result1 []v1alpha1.ClusterDelivery, result2 error) {
@@ -451,18 +899,19 @@ func (fake *FakeRepository) GetDeliveriesForDeliverableReturnsOnCall(i int, resu
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) GetDelivery(arg1 string) (*v1alpha1.ClusterDelivery, error) {
+func (fake *FakeRepository) GetDelivery(arg1 context.Context, arg2 string) (*v1alpha1.ClusterDelivery, error) {
 	fake.getDeliveryMutex.Lock()
 	ret, specificReturn := fake.getDeliveryReturnsOnCall[len(fake.getDeliveryArgsForCall)]
 	fake.getDeliveryArgsForCall = append(fake.getDeliveryArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.GetDeliveryStub
 	fakeReturns := fake.getDeliveryReturns
-	fake.recordInvocation("GetDelivery", []interface{}{arg1})
+	fake.recordInvocation("GetDelivery", []interface{}{arg1, arg2})
 	fake.getDeliveryMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -476,17 +925,17 @@ func (fake *FakeRepository) GetDeliveryCallCount() int {
 	return len(fake.getDeliveryArgsForCall)
 }
 
-func (fake *FakeRepository) GetDeliveryCalls(stub func(string) (*v1alpha1.ClusterDelivery, error)) {
+func (fake *FakeRepository) GetDeliveryCalls(stub func(context.Context, string) (*v1alpha1.ClusterDelivery, error)) {
 	fake.getDeliveryMutex.Lock()
 	defer fake.getDeliveryMutex.Unlock()
 	fake.GetDeliveryStub = stub
 }
 
-func (fake *FakeRepository) GetDeliveryArgsForCall(i int) string {
+func (fake *FakeRepository) GetDeliveryArgsForCall(i int) (context.Context, string) {
 	fake.getDeliveryMutex.RLock()
 	defer fake.getDeliveryMutex.RUnlock()
 	argsForCall := fake.getDeliveryArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeRepository) GetDeliveryReturns(result1 *v1alpha1.ClusterDelivery, result2 error) {
@@ -515,18 +964,19 @@ func (fake *FakeRepository) GetDeliveryReturnsOnCall(i int, result1 *v1alpha1.Cl
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) GetDeliveryClusterTemplate(arg1 v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error) {
+func (fake *FakeRepository) GetDeliveryClusterTemplate(arg1 context.Context, arg2 v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error) {
 	fake.getDeliveryClusterTemplateMutex.Lock()
 	ret, specificReturn := fake.getDeliveryClusterTemplateReturnsOnCall[len(fake.getDeliveryClusterTemplateArgsForCall)]
 	fake.getDeliveryClusterTemplateArgsForCall = append(fake.getDeliveryClusterTemplateArgsForCall, struct {
-		arg1 v1alpha1.DeliveryClusterTemplateReference
-	}{arg1})
+		arg1 context.Context
+		arg2 v1alpha1.DeliveryClusterTemplateReference
+	}{arg1, arg2})
 	stub := fake.GetDeliveryClusterTemplateStub
 	fakeReturns := fake.getDeliveryClusterTemplateReturns
-	fake.recordInvocation("GetDeliveryClusterTemplate", []interface{}{arg1})
+	fake.recordInvocation("GetDeliveryClusterTemplate", []interface{}{arg1, arg2})
 	fake.getDeliveryClusterTemplateMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -540,17 +990,17 @@ func (fake *FakeRepository) GetDeliveryClusterTemplateCallCount() int {
 	return len(fake.getDeliveryClusterTemplateArgsForCall)
 }
 
-func (fake *FakeRepository) GetDeliveryClusterTemplateCalls(stub func(v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error)) {
+func (fake *FakeRepository) GetDeliveryClusterTemplateCalls(stub func(context.Context, v1alpha1.DeliveryClusterTemplateReference) (templates.Template, error)) {
 	fake.getDeliveryClusterTemplateMutex.Lock()
 	defer fake.getDeliveryClusterTemplateMutex.Unlock()
 	fake.GetDeliveryClusterTemplateStub = stub
 }
 
-func (fake *FakeRepository) GetDeliveryClusterTemplateArgsForCall(i int) v1alpha1.DeliveryClusterTemplateReference {
+func (fake *FakeRepository) GetDeliveryClusterTemplateArgsForCall(i int) (context.Context, v1alpha1.DeliveryClusterTemplateReference) {
 	fake.getDeliveryClusterTemplateMutex.RLock()
 	defer fake.getDeliveryClusterTemplateMutex.RUnlock()
 	argsForCall := fake.getDeliveryClusterTemplateArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeRepository) GetDeliveryClusterTemplateReturns(result1 templates.Template, result2 error) {
@@ -579,19 +1029,282 @@ func (fake *FakeRepository) GetDeliveryClusterTemplateReturnsOnCall(i int, resul
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) GetPipeline(arg1 string, arg2 string) (*v1alpha1.Pipeline, error) {
+func (fake *FakeRepository) GetNamespacedDeliveriesForDeliverable(arg1 context.Context, arg2 *v1alpha1.Deliverable) ([]v1alpha1.Delivery, error) {
+	fake.getNamespacedDeliveriesForDeliverableMutex.Lock()
+	ret, specificReturn := fake.getNamespacedDeliveriesForDeliverableReturnsOnCall[len(fake.getNamespacedDeliveriesForDeliverableArgsForCall)]
+	fake.getNamespacedDeliveriesForDeliverableArgsForCall = append(fake.getNamespacedDeliveriesForDeliverableArgsForCall, struct {
+		arg1 context.Context
+		arg2 *v1alpha1.Deliverable
+	}{arg1, arg2})
+	stub := fake.GetNamespacedDeliveriesForDeliverableStub
+	fakeReturns := fake.getNamespacedDeliveriesForDeliverableReturns
+	fake.recordInvocation("GetNamespacedDeliveriesForDeliverable", []interface{}{arg1, arg2})
+	fake.getNamespacedDeliveriesForDeliverableMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveriesForDeliverableCallCount() int {
+	fake.getNamespacedDeliveriesForDeliverableMutex.RLock()
+	defer fake.getNamespacedDeliveriesForDeliverableMutex.RUnlock()
+	return len(fake.getNamespacedDeliveriesForDeliverableArgsForCall)
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveriesForDeliverableCalls(stub func(context.Context, *v1alpha1.Deliverable) ([]v1alpha1.Delivery, error)) {
+	fake.getNamespacedDeliveriesForDeliverableMutex.Lock()
+	defer fake.getNamespacedDeliveriesForDeliverableMutex.Unlock()
+	fake.GetNamespacedDeliveriesForDeliverableStub = stub
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveriesForDeliverableArgsForCall(i int) (context.Context, *v1alpha1.Deliverable) {
+	fake.getNamespacedDeliveriesForDeliverableMutex.RLock()
+	defer fake.getNamespacedDeliveriesForDeliverableMutex.RUnlock()
+	argsForCall := fake.getNamespacedDeliveriesForDeliverableArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveriesForDeliverableReturns(result1 []v1alpha1.Delivery, result2 error) {
+	fake.getNamespacedDeliveriesForDeliverableMutex.Lock()
+	defer fake.getNamespacedDeliveriesForDeliverableMutex.Unlock()
+	fake.GetNamespacedDeliveriesForDeliverableStub = nil
+	fake.getNamespacedDeliveriesForDeliverableReturns = struct {
+		result1 []v1alpha1.Delivery
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveriesForDeliverableReturnsOnCall(i int, result1 []v1alpha1.Delivery, result2 error) {
+	fake.getNamespacedDeliveriesForDeliverableMutex.Lock()
+	defer fake.getNamespacedDeliveriesForDeliverableMutex.Unlock()
+	fake.GetNamespacedDeliveriesForDeliverableStub = nil
+	if fake.getNamespacedDeliveriesForDeliverableReturnsOnCall == nil {
+		fake.getNamespacedDeliveriesForDeliverableReturnsOnCall = make(map[int]struct {
+			result1 []v1alpha1.Delivery
+			result2 error
+		})
+	}
+	fake.getNamespacedDeliveriesForDeliverableReturnsOnCall[i] = struct {
+		result1 []v1alpha1.Delivery
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetNamespacedDelivery(arg1 context.Context, arg2 string, arg3 string) (*v1alpha1.Delivery, error) {
+	fake.getNamespacedDeliveryMutex.Lock()
+	ret, specificReturn := fake.getNamespacedDeliveryReturnsOnCall[len(fake.getNamespacedDeliveryArgsForCall)]
+	fake.getNamespacedDeliveryArgsForCall = append(fake.getNamespacedDeliveryArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetNamespacedDeliveryStub
+	fakeReturns := fake.getNamespacedDeliveryReturns
+	fake.recordInvocation("GetNamespacedDelivery", []interface{}{arg1, arg2, arg3})
+	fake.getNamespacedDeliveryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveryCallCount() int {
+	fake.getNamespacedDeliveryMutex.RLock()
+	defer fake.getNamespacedDeliveryMutex.RUnlock()
+	return len(fake.getNamespacedDeliveryArgsForCall)
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveryCalls(stub func(context.Context, string, string) (*v1alpha1.Delivery, error)) {
+	fake.getNamespacedDeliveryMutex.Lock()
+	defer fake.getNamespacedDeliveryMutex.Unlock()
+	fake.GetNamespacedDeliveryStub = stub
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveryArgsForCall(i int) (context.Context, string, string) {
+	fake.getNamespacedDeliveryMutex.RLock()
+	defer fake.getNamespacedDeliveryMutex.RUnlock()
+	argsForCall := fake.getNamespacedDeliveryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveryReturns(result1 *v1alpha1.Delivery, result2 error) {
+	fake.getNamespacedDeliveryMutex.Lock()
+	defer fake.getNamespacedDeliveryMutex.Unlock()
+	fake.GetNamespacedDeliveryStub = nil
+	fake.getNamespacedDeliveryReturns = struct {
+		result1 *v1alpha1.Delivery
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetNamespacedDeliveryReturnsOnCall(i int, result1 *v1alpha1.Delivery, result2 error) {
+	fake.getNamespacedDeliveryMutex.Lock()
+	defer fake.getNamespacedDeliveryMutex.Unlock()
+	fake.GetNamespacedDeliveryStub = nil
+	if fake.getNamespacedDeliveryReturnsOnCall == nil {
+		fake.getNamespacedDeliveryReturnsOnCall = make(map[int]struct {
+			result1 *v1alpha1.Delivery
+			result2 error
+		})
+	}
+	fake.getNamespacedDeliveryReturnsOnCall[i] = struct {
+		result1 *v1alpha1.Delivery
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChain(arg1 context.Context, arg2 string, arg3 string) (*v1alpha1.SupplyChain, error) {
+	fake.getNamespacedSupplyChainMutex.Lock()
+	ret, specificReturn := fake.getNamespacedSupplyChainReturnsOnCall[len(fake.getNamespacedSupplyChainArgsForCall)]
+	fake.getNamespacedSupplyChainArgsForCall = append(fake.getNamespacedSupplyChainArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetNamespacedSupplyChainStub
+	fakeReturns := fake.getNamespacedSupplyChainReturns
+	fake.recordInvocation("GetNamespacedSupplyChain", []interface{}{arg1, arg2, arg3})
+	fake.getNamespacedSupplyChainMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainCallCount() int {
+	fake.getNamespacedSupplyChainMutex.RLock()
+	defer fake.getNamespacedSupplyChainMutex.RUnlock()
+	return len(fake.getNamespacedSupplyChainArgsForCall)
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainCalls(stub func(context.Context, string, string) (*v1alpha1.SupplyChain, error)) {
+	fake.getNamespacedSupplyChainMutex.Lock()
+	defer fake.getNamespacedSupplyChainMutex.Unlock()
+	fake.GetNamespacedSupplyChainStub = stub
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainArgsForCall(i int) (context.Context, string, string) {
+	fake.getNamespacedSupplyChainMutex.RLock()
+	defer fake.getNamespacedSupplyChainMutex.RUnlock()
+	argsForCall := fake.getNamespacedSupplyChainArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainReturns(result1 *v1alpha1.SupplyChain, result2 error) {
+	fake.getNamespacedSupplyChainMutex.Lock()
+	defer fake.getNamespacedSupplyChainMutex.Unlock()
+	fake.GetNamespacedSupplyChainStub = nil
+	fake.getNamespacedSupplyChainReturns = struct {
+		result1 *v1alpha1.SupplyChain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainReturnsOnCall(i int, result1 *v1alpha1.SupplyChain, result2 error) {
+	fake.getNamespacedSupplyChainMutex.Lock()
+	defer fake.getNamespacedSupplyChainMutex.Unlock()
+	fake.GetNamespacedSupplyChainStub = nil
+	if fake.getNamespacedSupplyChainReturnsOnCall == nil {
+		fake.getNamespacedSupplyChainReturnsOnCall = make(map[int]struct {
+			result1 *v1alpha1.SupplyChain
+			result2 error
+		})
+	}
+	fake.getNamespacedSupplyChainReturnsOnCall[i] = struct {
+		result1 *v1alpha1.SupplyChain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainsForWorkload(arg1 context.Context, arg2 *v1alpha1.Workload) ([]v1alpha1.SupplyChain, error) {
+	fake.getNamespacedSupplyChainsForWorkloadMutex.Lock()
+	ret, specificReturn := fake.getNamespacedSupplyChainsForWorkloadReturnsOnCall[len(fake.getNamespacedSupplyChainsForWorkloadArgsForCall)]
+	fake.getNamespacedSupplyChainsForWorkloadArgsForCall = append(fake.getNamespacedSupplyChainsForWorkloadArgsForCall, struct {
+		arg1 context.Context
+		arg2 *v1alpha1.Workload
+	}{arg1, arg2})
+	stub := fake.GetNamespacedSupplyChainsForWorkloadStub
+	fakeReturns := fake.getNamespacedSupplyChainsForWorkloadReturns
+	fake.recordInvocation("GetNamespacedSupplyChainsForWorkload", []interface{}{arg1, arg2})
+	fake.getNamespacedSupplyChainsForWorkloadMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainsForWorkloadCallCount() int {
+	fake.getNamespacedSupplyChainsForWorkloadMutex.RLock()
+	defer fake.getNamespacedSupplyChainsForWorkloadMutex.RUnlock()
+	return len(fake.getNamespacedSupplyChainsForWorkloadArgsForCall)
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainsForWorkloadCalls(stub func(context.Context, *v1alpha1.Workload) ([]v1alpha1.SupplyChain, error)) {
+	fake.getNamespacedSupplyChainsForWorkloadMutex.Lock()
+	defer fake.getNamespacedSupplyChainsForWorkloadMutex.Unlock()
+	fake.GetNamespacedSupplyChainsForWorkloadStub = stub
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainsForWorkloadArgsForCall(i int) (context.Context, *v1alpha1.Workload) {
+	fake.getNamespacedSupplyChainsForWorkloadMutex.RLock()
+	defer fake.getNamespacedSupplyChainsForWorkloadMutex.RUnlock()
+	argsForCall := fake.getNamespacedSupplyChainsForWorkloadArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainsForWorkloadReturns(result1 []v1alpha1.SupplyChain, result2 error) {
+	fake.getNamespacedSupplyChainsForWorkloadMutex.Lock()
+	defer fake.getNamespacedSupplyChainsForWorkloadMutex.Unlock()
+	fake.GetNamespacedSupplyChainsForWorkloadStub = nil
+	fake.getNamespacedSupplyChainsForWorkloadReturns = struct {
+		result1 []v1alpha1.SupplyChain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetNamespacedSupplyChainsForWorkloadReturnsOnCall(i int, result1 []v1alpha1.SupplyChain, result2 error) {
+	fake.getNamespacedSupplyChainsForWorkloadMutex.Lock()
+	defer fake.getNamespacedSupplyChainsForWorkloadMutex.Unlock()
+	fake.GetNamespacedSupplyChainsForWorkloadStub = nil
+	if fake.getNamespacedSupplyChainsForWorkloadReturnsOnCall == nil {
+		fake.getNamespacedSupplyChainsForWorkloadReturnsOnCall = make(map[int]struct {
+			result1 []v1alpha1.SupplyChain
+			result2 error
+		})
+	}
+	fake.getNamespacedSupplyChainsForWorkloadReturnsOnCall[i] = struct {
+		result1 []v1alpha1.SupplyChain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetPipeline(arg1 context.Context, arg2 string, arg3 string) (*v1alpha1.Pipeline, error) {
 	fake.getPipelineMutex.Lock()
 	ret, specificReturn := fake.getPipelineReturnsOnCall[len(fake.getPipelineArgsForCall)]
 	fake.getPipelineArgsForCall = append(fake.getPipelineArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-	}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
 	stub := fake.GetPipelineStub
 	fakeReturns := fake.getPipelineReturns
-	fake.recordInvocation("GetPipeline", []interface{}{arg1, arg2})
+	fake.recordInvocation("GetPipeline", []interface{}{arg1, arg2, arg3})
 	fake.getPipelineMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -605,17 +1318,17 @@ func (fake *FakeRepository) GetPipelineCallCount() int {
 	return len(fake.getPipelineArgsForCall)
 }
 
-func (fake *FakeRepository) GetPipelineCalls(stub func(string, string) (*v1alpha1.Pipeline, error)) {
+func (fake *FakeRepository) GetPipelineCalls(stub func(context.Context, string, string) (*v1alpha1.Pipeline, error)) {
 	fake.getPipelineMutex.Lock()
 	defer fake.getPipelineMutex.Unlock()
 	fake.GetPipelineStub = stub
 }
 
-func (fake *FakeRepository) GetPipelineArgsForCall(i int) (string, string) {
+func (fake *FakeRepository) GetPipelineArgsForCall(i int) (context.Context, string, string) {
 	fake.getPipelineMutex.RLock()
 	defer fake.getPipelineMutex.RUnlock()
 	argsForCall := fake.getPipelineArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeRepository) GetPipelineReturns(result1 *v1alpha1.Pipeline, result2 error) {
@@ -644,18 +1357,19 @@ func (fake *FakeRepository) GetPipelineReturnsOnCall(i int, result1 *v1alpha1.Pi
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) GetRunTemplate(arg1 v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error) {
+func (fake *FakeRepository) GetRunTemplate(arg1 context.Context, arg2 v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error) {
 	fake.getRunTemplateMutex.Lock()
 	ret, specificReturn := fake.getRunTemplateReturnsOnCall[len(fake.getRunTemplateArgsForCall)]
 	fake.getRunTemplateArgsForCall = append(fake.getRunTemplateArgsForCall, struct {
-		arg1 v1alpha1.TemplateReference
-	}{arg1})
+		arg1 context.Context
+		arg2 v1alpha1.TemplateReference
+	}{arg1, arg2})
 	stub := fake.GetRunTemplateStub
 	fakeReturns := fake.getRunTemplateReturns
-	fake.recordInvocation("GetRunTemplate", []interface{}{arg1})
+	fake.recordInvocation("GetRunTemplate", []interface{}{arg1, arg2})
 	fake.getRunTemplateMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -669,17 +1383,17 @@ func (fake *FakeRepository) GetRunTemplateCallCount() int {
 	return len(fake.getRunTemplateArgsForCall)
 }
 
-func (fake *FakeRepository) GetRunTemplateCalls(stub func(v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error)) {
+func (fake *FakeRepository) GetRunTemplateCalls(stub func(context.Context, v1alpha1.TemplateReference) (templates.ClusterRunTemplate, error)) {
 	fake.getRunTemplateMutex.Lock()
 	defer fake.getRunTemplateMutex.Unlock()
 	fake.GetRunTemplateStub = stub
 }
 
-func (fake *FakeRepository) GetRunTemplateArgsForCall(i int) v1alpha1.TemplateReference {
+func (fake *FakeRepository) GetRunTemplateArgsForCall(i int) (context.Context, v1alpha1.TemplateReference) {
 	fake.getRunTemplateMutex.RLock()
 	defer fake.getRunTemplateMutex.RUnlock()
 	argsForCall := fake.getRunTemplateArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeRepository) GetRunTemplateReturns(result1 templates.ClusterRunTemplate, result2 error) {
@@ -761,18 +1475,19 @@ func (fake *FakeRepository) GetSchemeReturnsOnCall(i int, result1 *runtime.Schem
 	}{result1}
 }
 
-func (fake *FakeRepository) GetSupplyChain(arg1 string) (*v1alpha1.ClusterSupplyChain, error) {
+func (fake *FakeRepository) GetSupplyChain(arg1 context.Context, arg2 string) (*v1alpha1.ClusterSupplyChain, error) {
 	fake.getSupplyChainMutex.Lock()
 	ret, specificReturn := fake.getSupplyChainReturnsOnCall[len(fake.getSupplyChainArgsForCall)]
 	fake.getSupplyChainArgsForCall = append(fake.getSupplyChainArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.GetSupplyChainStub
 	fakeReturns := fake.getSupplyChainReturns
-	fake.recordInvocation("GetSupplyChain", []interface{}{arg1})
+	fake.recordInvocation("GetSupplyChain", []interface{}{arg1, arg2})
 	fake.getSupplyChainMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -786,17 +1501,17 @@ func (fake *FakeRepository) GetSupplyChainCallCount() int {
 	return len(fake.getSupplyChainArgsForCall)
 }
 
-func (fake *FakeRepository) GetSupplyChainCalls(stub func(string) (*v1alpha1.ClusterSupplyChain, error)) {
+func (fake *FakeRepository) GetSupplyChainCalls(stub func(context.Context, string) (*v1alpha1.ClusterSupplyChain, error)) {
 	fake.getSupplyChainMutex.Lock()
 	defer fake.getSupplyChainMutex.Unlock()
 	fake.GetSupplyChainStub = stub
 }
 
-func (fake *FakeRepository) GetSupplyChainArgsForCall(i int) string {
+func (fake *FakeRepository) GetSupplyChainArgsForCall(i int) (context.Context, string) {
 	fake.getSupplyChainMutex.RLock()
 	defer fake.getSupplyChainMutex.RUnlock()
 	argsForCall := fake.getSupplyChainArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeRepository) GetSupplyChainReturns(result1 *v1alpha1.ClusterSupplyChain, result2 error) {
@@ -825,18 +1540,19 @@ func (fake *FakeRepository) GetSupplyChainReturnsOnCall(i int, result1 *v1alpha1
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) GetSupplyChainsForWorkload(arg1 *v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error) {
+func (fake *FakeRepository) GetSupplyChainsForWorkload(arg1 context.Context, arg2 *v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error) {
 	fake.getSupplyChainsForWorkloadMutex.Lock()
 	ret, specificReturn := fake.getSupplyChainsForWorkloadReturnsOnCall[len(fake.getSupplyChainsForWorkloadArgsForCall)]
 	fake.getSupplyChainsForWorkloadArgsForCall = append(fake.getSupplyChainsForWorkloadArgsForCall, struct {
-		arg1 *v1alpha1.Workload
-	}{arg1})
+		arg1 context.Context
+		arg2 *v1alpha1.Workload
+	}{arg1, arg2})
 	stub := fake.GetSupplyChainsForWorkloadStub
 	fakeReturns := fake.getSupplyChainsForWorkloadReturns
-	fake.recordInvocation("GetSupplyChainsForWorkload", []interface{}{arg1})
+	fake.recordInvocation("GetSupplyChainsForWorkload", []interface{}{arg1, arg2})
 	fake.getSupplyChainsForWorkloadMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -850,17 +1566,17 @@ func (fake *FakeRepository) GetSupplyChainsForWorkloadCallCount() int {
 	return len(fake.getSupplyChainsForWorkloadArgsForCall)
 }
 
-func (fake *FakeRepository) GetSupplyChainsForWorkloadCalls(stub func(*v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error)) {
+func (fake *FakeRepository) GetSupplyChainsForWorkloadCalls(stub func(context.Context, *v1alpha1.Workload) ([]v1alpha1.ClusterSupplyChain, error)) {
 	fake.getSupplyChainsForWorkloadMutex.Lock()
 	defer fake.getSupplyChainsForWorkloadMutex.Unlock()
 	fake.GetSupplyChainsForWorkloadStub = stub
 }
 
-func (fake *FakeRepository) GetSupplyChainsForWorkloadArgsForCall(i int) *v1alpha1.Workload {
+func (fake *FakeRepository) GetSupplyChainsForWorkloadArgsForCall(i int) (context.Context, *v1alpha1.Workload) {
 	fake.getSupplyChainsForWorkloadMutex.RLock()
 	defer fake.getSupplyChainsForWorkloadMutex.RUnlock()
 	argsForCall := fake.getSupplyChainsForWorkloadArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeRepository) GetSupplyChainsForWorkloadReturns(result1 []v1alpha1.ClusterSupplyChain, result2 error) {
@@ -889,19 +1605,147 @@ func (fake *FakeRepository) GetSupplyChainsForWorkloadReturnsOnCall(i int, resul
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) GetWorkload(arg1 string, arg2 string) (*v1alpha1.Workload, error) {
+func (fake *FakeRepository) GetTemplateLibrary(arg1 context.Context, arg2 string) (*v1alpha1.ClusterTemplateLibrary, error) {
+	fake.getTemplateLibraryMutex.Lock()
+	ret, specificReturn := fake.getTemplateLibraryReturnsOnCall[len(fake.getTemplateLibraryArgsForCall)]
+	fake.getTemplateLibraryArgsForCall = append(fake.getTemplateLibraryArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetTemplateLibraryStub
+	fakeReturns := fake.getTemplateLibraryReturns
+	fake.recordInvocation("GetTemplateLibrary", []interface{}{arg1, arg2})
+	fake.getTemplateLibraryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) GetTemplateLibraryCallCount() int {
+	fake.getTemplateLibraryMutex.RLock()
+	defer fake.getTemplateLibraryMutex.RUnlock()
+	return len(fake.getTemplateLibraryArgsForCall)
+}
+
+func (fake *FakeRepository) GetTemplateLibraryCalls(stub func(context.Context, string) (*v1alpha1.ClusterTemplateLibrary, error)) {
+	fake.getTemplateLibraryMutex.Lock()
+	defer fake.getTemplateLibraryMutex.Unlock()
+	fake.GetTemplateLibraryStub = stub
+}
+
+func (fake *FakeRepository) GetTemplateLibraryArgsForCall(i int) (context.Context, string) {
+	fake.getTemplateLibraryMutex.RLock()
+	defer fake.getTemplateLibraryMutex.RUnlock()
+	argsForCall := fake.getTemplateLibraryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) GetTemplateLibraryReturns(result1 *v1alpha1.ClusterTemplateLibrary, result2 error) {
+	fake.getTemplateLibraryMutex.Lock()
+	defer fake.getTemplateLibraryMutex.Unlock()
+	fake.GetTemplateLibraryStub = nil
+	fake.getTemplateLibraryReturns = struct {
+		result1 *v1alpha1.ClusterTemplateLibrary
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetTemplateLibraryReturnsOnCall(i int, result1 *v1alpha1.ClusterTemplateLibrary, result2 error) {
+	fake.getTemplateLibraryMutex.Lock()
+	defer fake.getTemplateLibraryMutex.Unlock()
+	fake.GetTemplateLibraryStub = nil
+	if fake.getTemplateLibraryReturnsOnCall == nil {
+		fake.getTemplateLibraryReturnsOnCall = make(map[int]struct {
+			result1 *v1alpha1.ClusterTemplateLibrary
+			result2 error
+		})
+	}
+	fake.getTemplateLibraryReturnsOnCall[i] = struct {
+		result1 *v1alpha1.ClusterTemplateLibrary
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) GetUnstructured(arg1 context.Context, arg2 *unstructured.Unstructured) error {
+	fake.getUnstructuredMutex.Lock()
+	ret, specificReturn := fake.getUnstructuredReturnsOnCall[len(fake.getUnstructuredArgsForCall)]
+	fake.getUnstructuredArgsForCall = append(fake.getUnstructuredArgsForCall, struct {
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
+	}{arg1, arg2})
+	stub := fake.GetUnstructuredStub
+	fakeReturns := fake.getUnstructuredReturns
+	fake.recordInvocation("GetUnstructured", []interface{}{arg1, arg2})
+	fake.getUnstructuredMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRepository) GetUnstructuredCallCount() int {
+	fake.getUnstructuredMutex.RLock()
+	defer fake.getUnstructuredMutex.RUnlock()
+	return len(fake.getUnstructuredArgsForCall)
+}
+
+func (fake *FakeRepository) GetUnstructuredCalls(stub func(context.Context, *unstructured.Unstructured) error) {
+	fake.getUnstructuredMutex.Lock()
+	defer fake.getUnstructuredMutex.Unlock()
+	fake.GetUnstructuredStub = stub
+}
+
+func (fake *FakeRepository) GetUnstructuredArgsForCall(i int) (context.Context, *unstructured.Unstructured) {
+	fake.getUnstructuredMutex.RLock()
+	defer fake.getUnstructuredMutex.RUnlock()
+	argsForCall := fake.getUnstructuredArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) GetUnstructuredReturns(result1 error) {
+	fake.getUnstructuredMutex.Lock()
+	defer fake.getUnstructuredMutex.Unlock()
+	fake.GetUnstructuredStub = nil
+	fake.getUnstructuredReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) GetUnstructuredReturnsOnCall(i int, result1 error) {
+	fake.getUnstructuredMutex.Lock()
+	defer fake.getUnstructuredMutex.Unlock()
+	fake.GetUnstructuredStub = nil
+	if fake.getUnstructuredReturnsOnCall == nil {
+		fake.getUnstructuredReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.getUnstructuredReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) GetWorkload(arg1 context.Context, arg2 string, arg3 string) (*v1alpha1.Workload, error) {
 	fake.getWorkloadMutex.Lock()
 	ret, specificReturn := fake.getWorkloadReturnsOnCall[len(fake.getWorkloadArgsForCall)]
 	fake.getWorkloadArgsForCall = append(fake.getWorkloadArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-	}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
 	stub := fake.GetWorkloadStub
 	fakeReturns := fake.getWorkloadReturns
-	fake.recordInvocation("GetWorkload", []interface{}{arg1, arg2})
+	fake.recordInvocation("GetWorkload", []interface{}{arg1, arg2, arg3})
 	fake.getWorkloadMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -915,17 +1759,17 @@ func (fake *FakeRepository) GetWorkloadCallCount() int {
 	return len(fake.getWorkloadArgsForCall)
 }
 
-func (fake *FakeRepository) GetWorkloadCalls(stub func(string, string) (*v1alpha1.Workload, error)) {
+func (fake *FakeRepository) GetWorkloadCalls(stub func(context.Context, string, string) (*v1alpha1.Workload, error)) {
 	fake.getWorkloadMutex.Lock()
 	defer fake.getWorkloadMutex.Unlock()
 	fake.GetWorkloadStub = stub
 }
 
-func (fake *FakeRepository) GetWorkloadArgsForCall(i int) (string, string) {
+func (fake *FakeRepository) GetWorkloadArgsForCall(i int) (context.Context, string, string) {
 	fake.getWorkloadMutex.RLock()
 	defer fake.getWorkloadMutex.RUnlock()
 	argsForCall := fake.getWorkloadArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeRepository) GetWorkloadReturns(result1 *v1alpha1.Workload, result2 error) {
@@ -954,18 +1798,84 @@ func (fake *FakeRepository) GetWorkloadReturnsOnCall(i int, result1 *v1alpha1.Wo
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) ListUnstructured(arg1 *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+func (fake *FakeRepository) ListDeliverables(arg1 context.Context, arg2 string) ([]v1alpha1.Deliverable, error) {
+	fake.listDeliverablesMutex.Lock()
+	ret, specificReturn := fake.listDeliverablesReturnsOnCall[len(fake.listDeliverablesArgsForCall)]
+	fake.listDeliverablesArgsForCall = append(fake.listDeliverablesArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListDeliverablesStub
+	fakeReturns := fake.listDeliverablesReturns
+	fake.recordInvocation("ListDeliverables", []interface{}{arg1, arg2})
+	fake.listDeliverablesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) ListDeliverablesCallCount() int {
+	fake.listDeliverablesMutex.RLock()
+	defer fake.listDeliverablesMutex.RUnlock()
+	return len(fake.listDeliverablesArgsForCall)
+}
+
+func (fake *FakeRepository) ListDeliverablesCalls(stub func(context.Context, string) ([]v1alpha1.Deliverable, error)) {
+	fake.listDeliverablesMutex.Lock()
+	defer fake.listDeliverablesMutex.Unlock()
+	fake.ListDeliverablesStub = stub
+}
+
+func (fake *FakeRepository) ListDeliverablesArgsForCall(i int) (context.Context, string) {
+	fake.listDeliverablesMutex.RLock()
+	defer fake.listDeliverablesMutex.RUnlock()
+	argsForCall := fake.listDeliverablesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) ListDeliverablesReturns(result1 []v1alpha1.Deliverable, result2 error) {
+	fake.listDeliverablesMutex.Lock()
+	defer fake.listDeliverablesMutex.Unlock()
+	fake.ListDeliverablesStub = nil
+	fake.listDeliverablesReturns = struct {
+		result1 []v1alpha1.Deliverable
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) ListDeliverablesReturnsOnCall(i int, result1 []v1alpha1.Deliverable, result2 error) {
+	fake.listDeliverablesMutex.Lock()
+	defer fake.listDeliverablesMutex.Unlock()
+	fake.ListDeliverablesStub = nil
+	if fake.listDeliverablesReturnsOnCall == nil {
+		fake.listDeliverablesReturnsOnCall = make(map[int]struct {
+			result1 []v1alpha1.Deliverable
+			result2 error
+		})
+	}
+	fake.listDeliverablesReturnsOnCall[i] = struct {
+		result1 []v1alpha1.Deliverable
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) ListUnstructured(arg1 context.Context, arg2 *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
 	fake.listUnstructuredMutex.Lock()
 	ret, specificReturn := fake.listUnstructuredReturnsOnCall[len(fake.listUnstructuredArgsForCall)]
 	fake.listUnstructuredArgsForCall = append(fake.listUnstructuredArgsForCall, struct {
-		arg1 *unstructured.Unstructured
-	}{arg1})
+		arg1 context.Context
+		arg2 *unstructured.Unstructured
+	}{arg1, arg2})
 	stub := fake.ListUnstructuredStub
 	fakeReturns := fake.listUnstructuredReturns
-	fake.recordInvocation("ListUnstructured", []interface{}{arg1})
+	fake.recordInvocation("ListUnstructured", []interface{}{arg1, arg2})
 	fake.listUnstructuredMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -979,17 +1889,17 @@ func (fake *FakeRepository) ListUnstructuredCallCount() int {
 	return len(fake.listUnstructuredArgsForCall)
 }
 
-func (fake *FakeRepository) ListUnstructuredCalls(stub func(*unstructured.Unstructured) ([]*unstructured.Unstructured, error)) {
+func (fake *FakeRepository) ListUnstructuredCalls(stub func(context.Context, *unstructured.Unstructured) ([]*unstructured.Unstructured, error)) {
 	fake.listUnstructuredMutex.Lock()
 	defer fake.listUnstructuredMutex.Unlock()
 	fake.ListUnstructuredStub = stub
 }
 
-func (fake *FakeRepository) ListUnstructuredArgsForCall(i int) *unstructured.Unstructured {
+func (fake *FakeRepository) ListUnstructuredArgsForCall(i int) (context.Context, *unstructured.Unstructured) {
 	fake.listUnstructuredMutex.RLock()
 	defer fake.listUnstructuredMutex.RUnlock()
 	argsForCall := fake.listUnstructuredArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeRepository) ListUnstructuredReturns(result1 []*unstructured.Unstructured, result2 error) {
@@ -1018,18 +1928,84 @@ func (fake *FakeRepository) ListUnstructuredReturnsOnCall(i int, result1 []*unst
 	}{result1, result2}
 }
 
-func (fake *FakeRepository) StatusUpdate(arg1 client.Object) error {
+func (fake *FakeRepository) ListWorkloads(arg1 context.Context, arg2 string) ([]v1alpha1.Workload, error) {
+	fake.listWorkloadsMutex.Lock()
+	ret, specificReturn := fake.listWorkloadsReturnsOnCall[len(fake.listWorkloadsArgsForCall)]
+	fake.listWorkloadsArgsForCall = append(fake.listWorkloadsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListWorkloadsStub
+	fakeReturns := fake.listWorkloadsReturns
+	fake.recordInvocation("ListWorkloads", []interface{}{arg1, arg2})
+	fake.listWorkloadsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) ListWorkloadsCallCount() int {
+	fake.listWorkloadsMutex.RLock()
+	defer fake.listWorkloadsMutex.RUnlock()
+	return len(fake.listWorkloadsArgsForCall)
+}
+
+func (fake *FakeRepository) ListWorkloadsCalls(stub func(context.Context, string) ([]v1alpha1.Workload, error)) {
+	fake.listWorkloadsMutex.Lock()
+	defer fake.listWorkloadsMutex.Unlock()
+	fake.ListWorkloadsStub = stub
+}
+
+func (fake *FakeRepository) ListWorkloadsArgsForCall(i int) (context.Context, string) {
+	fake.listWorkloadsMutex.RLock()
+	defer fake.listWorkloadsMutex.RUnlock()
+	argsForCall := fake.listWorkloadsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) ListWorkloadsReturns(result1 []v1alpha1.Workload, result2 error) {
+	fake.listWorkloadsMutex.Lock()
+	defer fake.listWorkloadsMutex.Unlock()
+	fake.ListWorkloadsStub = nil
+	fake.listWorkloadsReturns = struct {
+		result1 []v1alpha1.Workload
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) ListWorkloadsReturnsOnCall(i int, result1 []v1alpha1.Workload, result2 error) {
+	fake.listWorkloadsMutex.Lock()
+	defer fake.listWorkloadsMutex.Unlock()
+	fake.ListWorkloadsStub = nil
+	if fake.listWorkloadsReturnsOnCall == nil {
+		fake.listWorkloadsReturnsOnCall = make(map[int]struct {
+			result1 []v1alpha1.Workload
+			result2 error
+		})
+	}
+	fake.listWorkloadsReturnsOnCall[i] = struct {
+		result1 []v1alpha1.Workload
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) StatusUpdate(arg1 context.Context, arg2 client.Object) error {
 	fake.statusUpdateMutex.Lock()
 	ret, specificReturn := fake.statusUpdateReturnsOnCall[len(fake.statusUpdateArgsForCall)]
 	fake.statusUpdateArgsForCall = append(fake.statusUpdateArgsForCall, struct {
-		arg1 client.Object
-	}{arg1})
+		arg1 context.Context
+		arg2 client.Object
+	}{arg1, arg2})
 	stub := fake.StatusUpdateStub
 	fakeReturns := fake.statusUpdateReturns
-	fake.recordInvocation("StatusUpdate", []interface{}{arg1})
+	fake.recordInvocation("StatusUpdate", []interface{}{arg1, arg2})
 	fake.statusUpdateMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -1043,17 +2019,17 @@ func (fake *FakeRepository) StatusUpdateCallCount() int {
 	return len(fake.statusUpdateArgsForCall)
 }
 
-func (fake *FakeRepository) StatusUpdateCalls(stub func(client.Object) error) {
+func (fake *FakeRepository) StatusUpdateCalls(stub func(context.Context, client.Object) error) {
 	fake.statusUpdateMutex.Lock()
 	defer fake.statusUpdateMutex.Unlock()
 	fake.StatusUpdateStub = stub
 }
 
-func (fake *FakeRepository) StatusUpdateArgsForCall(i int) client.Object {
+func (fake *FakeRepository) StatusUpdateArgsForCall(i int) (context.Context, client.Object) {
 	fake.statusUpdateMutex.RLock()
 	defer fake.statusUpdateMutex.RUnlock()
 	argsForCall := fake.statusUpdateArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeRepository) StatusUpdateReturns(result1 error) {
@@ -1079,11 +2055,81 @@ func (fake *FakeRepository) StatusUpdateReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeRepository) Update(arg1 context.Context, arg2 client.Object) error {
+	fake.updateMutex.Lock()
+	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
+	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Object
+	}{arg1, arg2})
+	stub := fake.UpdateStub
+	fakeReturns := fake.updateReturns
+	fake.recordInvocation("Update", []interface{}{arg1, arg2})
+	fake.updateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRepository) UpdateCallCount() int {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return len(fake.updateArgsForCall)
+}
+
+func (fake *FakeRepository) UpdateCalls(stub func(context.Context, client.Object) error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = stub
+}
+
+func (fake *FakeRepository) UpdateArgsForCall(i int) (context.Context, client.Object) {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	argsForCall := fake.updateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRepository) UpdateReturns(result1 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	fake.updateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRepository) UpdateReturnsOnCall(i int, result1 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	if fake.updateReturnsOnCall == nil {
+		fake.updateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.deleteUnstructuredMutex.RLock()
+	defer fake.deleteUnstructuredMutex.RUnlock()
+	fake.ensureImmutableObjectExistsOnClusterMutex.RLock()
+	defer fake.ensureImmutableObjectExistsOnClusterMutex.RUnlock()
 	fake.ensureObjectExistsOnClusterMutex.RLock()
 	defer fake.ensureObjectExistsOnClusterMutex.RUnlock()
+	fake.getBlueprintMutex.RLock()
+	defer fake.getBlueprintMutex.RUnlock()
+	fake.getBlueprintSourceMutex.RLock()
+	defer fake.getBlueprintSourceMutex.RUnlock()
 	fake.getClusterTemplateMutex.RLock()
 	defer fake.getClusterTemplateMutex.RUnlock()
 	fake.getDeliverableMutex.RLock()
@@ -1094,6 +2140,14 @@ func (fake *FakeRepository) Invocations() map[string][][]interface{} {
 	defer fake.getDeliveryMutex.RUnlock()
 	fake.getDeliveryClusterTemplateMutex.RLock()
 	defer fake.getDeliveryClusterTemplateMutex.RUnlock()
+	fake.getNamespacedDeliveriesForDeliverableMutex.RLock()
+	defer fake.getNamespacedDeliveriesForDeliverableMutex.RUnlock()
+	fake.getNamespacedDeliveryMutex.RLock()
+	defer fake.getNamespacedDeliveryMutex.RUnlock()
+	fake.getNamespacedSupplyChainMutex.RLock()
+	defer fake.getNamespacedSupplyChainMutex.RUnlock()
+	fake.getNamespacedSupplyChainsForWorkloadMutex.RLock()
+	defer fake.getNamespacedSupplyChainsForWorkloadMutex.RUnlock()
 	fake.getPipelineMutex.RLock()
 	defer fake.getPipelineMutex.RUnlock()
 	fake.getRunTemplateMutex.RLock()
@@ -1104,12 +2158,22 @@ func (fake *FakeRepository) Invocations() map[string][][]interface{} {
 	defer fake.getSupplyChainMutex.RUnlock()
 	fake.getSupplyChainsForWorkloadMutex.RLock()
 	defer fake.getSupplyChainsForWorkloadMutex.RUnlock()
+	fake.getTemplateLibraryMutex.RLock()
+	defer fake.getTemplateLibraryMutex.RUnlock()
+	fake.getUnstructuredMutex.RLock()
+	defer fake.getUnstructuredMutex.RUnlock()
 	fake.getWorkloadMutex.RLock()
 	defer fake.getWorkloadMutex.RUnlock()
+	fake.listDeliverablesMutex.RLock()
+	defer fake.listDeliverablesMutex.RUnlock()
 	fake.listUnstructuredMutex.RLock()
 	defer fake.listUnstructuredMutex.RUnlock()
+	fake.listWorkloadsMutex.RLock()
+	defer fake.listWorkloadsMutex.RUnlock()
 	fake.statusUpdateMutex.RLock()
 	defer fake.statusUpdateMutex.RUnlock()
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
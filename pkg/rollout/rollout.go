@@ -0,0 +1,61 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rollout counts the workloads or deliverables realized with a
+// blueprint by how far they have converged on its current generation.
+package rollout
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// Owner is a workload or deliverable, as far as its rollout is concerned.
+type Owner struct {
+	Generation         int64
+	ObservedGeneration int64
+	Conditions         []metav1.Condition
+	History            []v1alpha1.RealizationRecord
+}
+
+// Count counts owners realized with the blueprint named blueprint, whose
+// current generation is generation.
+func Count(owners []Owner, blueprint string, generation int64) v1alpha1.RolloutStatus {
+	status := v1alpha1.RolloutStatus{Total: len(owners)}
+	for _, owner := range owners {
+		switch {
+		case meta.IsStatusConditionFalse(owner.Conditions, "Ready"):
+			status.Failing++
+		case meta.IsStatusConditionTrue(owner.Conditions, "Ready") && current(owner, blueprint, generation):
+			status.Ready++
+		default:
+			status.Stale++
+		}
+	}
+	return status
+}
+
+// current reports whether owner was last realized with its own current
+// generation and the given generation of blueprint.
+func current(owner Owner, blueprint string, generation int64) bool {
+	if owner.ObservedGeneration != owner.Generation || len(owner.History) == 0 {
+		return false
+	}
+	latest := owner.History[0]
+	return latest.Generation == owner.Generation &&
+		latest.Blueprint == blueprint &&
+		latest.BlueprintGeneration == generation
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/rollout"
+)
+
+var _ = Describe("Count", func() {
+	owner := func(ready metav1.ConditionStatus, blueprintGeneration int64) rollout.Owner {
+		return rollout.Owner{
+			Generation:         2,
+			ObservedGeneration: 2,
+			Conditions:         []metav1.Condition{{Type: "Ready", Status: ready}},
+			History: []v1alpha1.RealizationRecord{
+				{Generation: 2, Blueprint: "web", BlueprintGeneration: blueprintGeneration},
+			},
+		}
+	}
+
+	It("counts ready the owners realized with the current generation of the blueprint", func() {
+		Expect(rollout.Count([]rollout.Owner{owner(metav1.ConditionTrue, 5)}, "web", 5)).To(Equal(v1alpha1.RolloutStatus{Total: 1, Ready: 1}))
+	})
+
+	It("counts failing the owners that are not ready, whatever they were realized with", func() {
+		Expect(rollout.Count([]rollout.Owner{owner(metav1.ConditionFalse, 5), owner(metav1.ConditionFalse, 4)}, "web", 5)).
+			To(Equal(v1alpha1.RolloutStatus{Total: 2, Failing: 2}))
+	})
+
+	It("counts stale the owners realized with an earlier generation of the blueprint", func() {
+		Expect(rollout.Count([]rollout.Owner{owner(metav1.ConditionTrue, 4)}, "web", 5)).To(Equal(v1alpha1.RolloutStatus{Total: 1, Stale: 1}))
+	})
+
+	It("counts stale the owners realized with another blueprint", func() {
+		Expect(rollout.Count([]rollout.Owner{owner(metav1.ConditionTrue, 5)}, "api", 5)).To(Equal(v1alpha1.RolloutStatus{Total: 1, Stale: 1}))
+	})
+
+	It("counts stale the owners whose latest spec was not realized yet", func() {
+		changed := owner(metav1.ConditionTrue, 5)
+		changed.Generation = 3
+		Expect(rollout.Count([]rollout.Owner{changed}, "web", 5)).To(Equal(v1alpha1.RolloutStatus{Total: 1, Stale: 1}))
+	})
+
+	It("counts stale the owners that were never fully realized", func() {
+		pending := owner(metav1.ConditionUnknown, 5)
+		pending.History = nil
+		Expect(rollout.Count([]rollout.Owner{pending}, "web", 5)).To(Equal(v1alpha1.RolloutStatus{Total: 1, Stale: 1}))
+	})
+})
@@ -17,16 +17,34 @@ package root
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	"github.com/vmware-tanzu/cartographer/pkg/admission"
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
+	"github.com/vmware-tanzu/cartographer/pkg/lease"
+	"github.com/vmware-tanzu/cartographer/pkg/plugin"
+	"github.com/vmware-tanzu/cartographer/pkg/policy"
+	"github.com/vmware-tanzu/cartographer/pkg/provenance"
+	"github.com/vmware-tanzu/cartographer/pkg/rbac"
 	"github.com/vmware-tanzu/cartographer/pkg/registrar"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/shard"
+	"github.com/vmware-tanzu/cartographer/pkg/shutdown"
+	"github.com/vmware-tanzu/cartographer/pkg/tracing"
+	"github.com/vmware-tanzu/cartographer/pkg/trigger"
 )
 
 type Command struct {
@@ -34,12 +52,113 @@ type Command struct {
 	CertDir string
 	Context context.Context
 	Logger  logr.Logger
+
+	// OTLPEndpoint is the host:port of an OpenTelemetry collector to which
+	// trace spans are exported. Tracing is disabled when empty.
+	OTLPEndpoint string
+	OTLPInsecure bool
+
+	// MetricsBindAddress is the address Prometheus metrics are served on.
+	// Metrics are not served when empty or "0".
+	MetricsBindAddress string
+
+	// GracefulShutdownTimeout is how long reconciles in flight at shutdown
+	// are given to finish before they are cancelled.
+	GracefulShutdownTimeout time.Duration
+
+	// WorkloadDefaults is the ConfigMap, as namespace/name, whose values
+	// the workload webhook fills in on workloads that do not set them.
+	// Workloads are not defaulted when empty.
+	WorkloadDefaults string
+
+	// PolicyFile is a YAML list of CEL rules that every stamped object must
+	// satisfy before it is submitted. No rules are enforced when empty.
+	PolicyFile string
+
+	// TriggerToken must be presented by callers of the trigger endpoint
+	// served alongside the webhooks. The endpoint is not served without it.
+	TriggerToken string
+
+	// RealizationLeaseDuration is how long a replica holds the lease on a
+	// workload, deliverable or pipeline it realizes, so that replicas never
+	// realize the same object concurrently. Objects are not leased when zero.
+	RealizationLeaseDuration time.Duration
+
+	// ArtifactStoreURL is the HTTP endpoint the artifacts realized for
+	// workloads are posted to, with ArtifactStoreToken as a bearer token
+	// when set. Artifacts are not recorded when empty.
+	ArtifactStoreURL   string
+	ArtifactStoreToken string
+
+	// ClusterName identifies this cluster in the records of the artifact
+	// store, and to templates as $(cluster.name)$.
+	ClusterName string
+
+	// ClusterDomain is the DNS domain of the cluster's services, which
+	// templates read as $(cluster.domain)$.
+	ClusterDomain string
+
+	// ClusterData is the ConfigMap, as namespace/name, whose data templates
+	// read as $(cluster.data)$. The data is empty when it is empty or the
+	// ConfigMap does not exist.
+	ClusterData string
+
+	// RateLimits tune the rate limiters of the controllers' work queues.
+	RateLimits registrar.RateLimits
+
+	// ShardCount is the number of shards workloads and deliverables are
+	// partitioned into, each realized by the replicas of ShardIndex. When
+	// ShardIndex is negative, it is the ordinal ending the hostname, as in
+	// a StatefulSet. Objects are not sharded when ShardCount is 1 or less.
+	ShardCount int
+	ShardIndex int
+
+	// CacheSelectors restrict the cache of some kinds, such as the kinds
+	// that are stamped, to the objects matching a label selector. Every
+	// object of a kind without a selector is cached.
+	CacheSelectors registrar.CacheSelectors
+
+	// ResyncInterval is how often ready workloads and deliverables are
+	// reconciled again, unless annotated with carto.run/resync-interval.
+	// It is five seconds when zero.
+	ResyncInterval time.Duration
+
+	// RealizerPlugins are the addresses of the gRPC plugins that realize
+	// the resources whose templates name them, connected to over TLS unless
+	// RealizerPluginsInsecure is set. Such resources wait when empty.
+	RealizerPlugins         plugin.Endpoints
+	RealizerPluginsInsecure bool
+
+	// PreflightPermissions has the permissions needed to submit and track
+	// the objects a blueprint stamps reviewed before it is realized, and
+	// those missing reported on the owner.
+	PreflightPermissions bool
+
+	// RepoCacheConfigMaps, as namespace/prefix, has the caches of the
+	// objects the workload, deliverable and pipeline controllers submitted
+	// saved to the ConfigMaps <prefix>-<controller>, and restored from them
+	// on start. A sharded replica adds -shard-<index> to prefix. The caches
+	// are not persisted when it is empty.
+	RepoCacheConfigMaps string
 }
 
 func (cmd *Command) Execute() error {
 	log.SetLogger(cmd.Logger)
 	l := log.Log.WithName("cartographer")
 
+	if cmd.OTLPEndpoint != "" {
+		shutdown, err := tracing.Setup(cmd.Context, cmd.OTLPEndpoint, cmd.OTLPInsecure)
+		if err != nil {
+			return fmt.Errorf("setup tracing: %w", err)
+		}
+		defer func() {
+			if err := shutdown(context.Background()); err != nil {
+				l.Error(err, "shutdown tracing")
+			}
+		}()
+		l.Info("exporting traces", "endpoint", cmd.OTLPEndpoint)
+	}
+
 	cfg, err := config.GetConfig()
 	if err != nil {
 		return fmt.Errorf("get config: %w", err)
@@ -50,18 +169,132 @@ func (cmd *Command) Execute() error {
 		return fmt.Errorf("add to scheme: %w", err)
 	}
 
-	mgr, err := manager.New(cfg, manager.Options{
+	options := manager.Options{
 		Port:               cmd.Port,
 		CertDir:            cmd.CertDir,
 		Scheme:             scheme,
-		MetricsBindAddress: "0",
-	})
+		MetricsBindAddress: cmd.MetricsBindAddress,
+	}
+	if len(cmd.CacheSelectors) > 0 {
+		options.NewCache = cmd.CacheSelectors.NewCache()
+		l.Info("restricting caches", "selectors", cmd.CacheSelectors.String())
+	}
+	if options.MetricsBindAddress == "" {
+		options.MetricsBindAddress = "0"
+	}
+	if cmd.GracefulShutdownTimeout > 0 {
+		// leave room after the drain deadline for the final status updates
+		gracePeriod := cmd.GracefulShutdownTimeout + shutdown.StatusFlushTimeout
+		options.GracefulShutdownTimeout = &gracePeriod
+	}
+
+	mgr, err := manager.New(cfg, options)
 
 	if err != nil {
 		return fmt.Errorf("manager new: %w", err)
 	}
 
-	if err := registrar.RegisterControllers(mgr); err != nil {
+	drainer := shutdown.NewDrainer(cmd.GracefulShutdownTimeout)
+	if err := mgr.Add(drainer); err != nil {
+		return fmt.Errorf("add drainer: %w", err)
+	}
+
+	var stampPolicy *policy.Policy
+	if cmd.PolicyFile != "" {
+		stampPolicy, err = policy.LoadFile(cmd.PolicyFile)
+		if err != nil {
+			return fmt.Errorf("load policy: %w", err)
+		}
+	}
+
+	var receiver *trigger.Receiver
+	if cmd.CertDir != "" && cmd.TriggerToken != "" {
+		receiver = trigger.NewReceiver(mgr.GetClient(), cmd.TriggerToken, l.WithName("trigger"))
+	}
+
+	var locker *lease.Locker
+	if cmd.RealizationLeaseDuration > 0 {
+		identity, err := replicaIdentity()
+		if err != nil {
+			return fmt.Errorf("replica identity: %w", err)
+		}
+		locker = lease.NewLocker(mgr.GetClient(), mgr.GetAPIReader(), identity, cmd.RealizationLeaseDuration)
+		l.Info("leasing realized objects", "identity", identity, "duration", cmd.RealizationLeaseDuration)
+	}
+
+	var recorder *provenance.Recorder
+	if cmd.ArtifactStoreURL != "" {
+		recorder = provenance.NewRecorder(provenance.NewHTTPStore(cmd.ArtifactStoreURL, cmd.ArtifactStoreToken), cmd.ClusterName)
+		l.Info("recording artifacts", "url", cmd.ArtifactStoreURL, "cluster", cmd.ClusterName)
+	}
+
+	var sharder *shard.Sharder
+	var shardIndex int
+	if cmd.ShardCount > 1 {
+		index := cmd.ShardIndex
+		if index < 0 {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return fmt.Errorf("hostname: %w", err)
+			}
+			if index, err = shard.IndexFromHostname(hostname); err != nil {
+				return fmt.Errorf("shard index: %w", err)
+			}
+		}
+		sharder, err = shard.NewSharder(mgr.GetClient(), index, cmd.ShardCount)
+		if err != nil {
+			return fmt.Errorf("sharder: %w", err)
+		}
+		shardIndex = index
+		l.Info("sharding workloads and deliverables", "index", index, "count", cmd.ShardCount)
+	}
+
+	var clusterDataConfigMap types.NamespacedName
+	if cmd.ClusterData != "" {
+		clusterDataConfigMap, err = parseNamespacedName(cmd.ClusterData)
+		if err != nil {
+			return fmt.Errorf("cluster data: %w", err)
+		}
+	}
+	clusterData := clusterdata.NewReader(mgr.GetAPIReader(), cmd.ClusterName, cmd.ClusterDomain, clusterDataConfigMap, clusterdata.DefaultTTL)
+
+	var plugins *plugin.Plugins
+	if len(cmd.RealizerPlugins) > 0 {
+		plugins, err = plugin.Dial(cmd.RealizerPlugins, cmd.RealizerPluginsInsecure)
+		if err != nil {
+			return fmt.Errorf("realizer plugins: %w", err)
+		}
+		defer func() {
+			if err := plugins.Close(); err != nil {
+				l.Error(err, "close realizer plugins")
+			}
+		}()
+		l.Info("realizing through plugins", "plugins", cmd.RealizerPlugins.String())
+	}
+
+	var permissions *rbac.Reviewer
+	if cmd.PreflightPermissions {
+		clients := repository.NewImpersonatingClients(mgr.GetConfig(), client.Options{
+			Scheme: mgr.GetScheme(),
+			Mapper: mgr.GetRESTMapper(),
+		})
+		permissions = rbac.NewReviewer(mgr.GetClient(), clients.For, mgr.GetRESTMapper(), rbac.DefaultReviewTTL)
+	}
+
+	var cacheStore repository.CacheStore
+	if cmd.RepoCacheConfigMaps != "" {
+		prefix, err := parseNamespacedName(cmd.RepoCacheConfigMaps)
+		if err != nil {
+			return fmt.Errorf("repo cache configmaps: %w", err)
+		}
+		if sharder != nil {
+			prefix.Name = fmt.Sprintf("%s-shard-%d", prefix.Name, shardIndex)
+		}
+		cacheStore = repository.NewConfigMapStore(mgr.GetClient(), mgr.GetAPIReader(), prefix)
+		l.Info("persisting repo caches", "configmaps", prefix.String())
+	}
+
+	if err := registrar.RegisterControllers(mgr, drainer, stampPolicy, receiver, locker, recorder, cmd.RateLimits, sharder, cmd.ResyncInterval, plugins, clusterData, permissions, cacheStore); err != nil {
 		return fmt.Errorf("register controllers: %w", err)
 	}
 
@@ -72,36 +305,91 @@ func (cmd *Command) Execute() error {
 	if cmd.CertDir == "" {
 		l.Info("Not registering the webhook server. Must pass a directory containing tls.crt and tls.key to --cert-dir")
 	} else {
+		if receiver != nil {
+			mgr.GetWebhookServer().Register(trigger.Path, receiver)
+		}
+
+		templateValidator := admission.NewTemplateValidator(mgr.GetAPIReader())
+		templateValidator.AddClusterData(clusterData)
+
 		if err := controllerruntime.NewWebhookManagedBy(mgr).
 			For(&v1alpha1.ClusterSupplyChain{}).
 			Complete(); err != nil {
 			return fmt.Errorf("clustersupplychain webhook: %w", err)
 		}
+		if err := controllerruntime.NewWebhookManagedBy(mgr).
+			For(&v1alpha1.SupplyChain{}).
+			Complete(); err != nil {
+			return fmt.Errorf("supplychain webhook: %w", err)
+		}
+		if err := controllerruntime.NewWebhookManagedBy(mgr).
+			For(&v1alpha1.ClusterBlueprint{}).
+			Complete(); err != nil {
+			return fmt.Errorf("clusterblueprint webhook: %w", err)
+		}
 		if err := controllerruntime.NewWebhookManagedBy(mgr).
 			For(&v1alpha1.ClusterConfigTemplate{}).
+			WithValidator(templateValidator).
 			Complete(); err != nil {
 			return fmt.Errorf("clusterconfigtemplate webhook: %w", err)
 		}
 		if err := controllerruntime.NewWebhookManagedBy(mgr).
 			For(&v1alpha1.ClusterImageTemplate{}).
+			WithValidator(templateValidator).
 			Complete(); err != nil {
 			return fmt.Errorf("clusterimagetemplate webhook: %w", err)
 		}
 		if err := controllerruntime.NewWebhookManagedBy(mgr).
 			For(&v1alpha1.ClusterSourceTemplate{}).
+			WithValidator(templateValidator).
 			Complete(); err != nil {
 			return fmt.Errorf("clustersourcetemplate webhook: %w", err)
 		}
 		if err := controllerruntime.NewWebhookManagedBy(mgr).
 			For(&v1alpha1.ClusterTemplate{}).
+			WithValidator(templateValidator).
 			Complete(); err != nil {
 			return fmt.Errorf("clustertemplate webhook: %w", err)
 		}
+		if err := controllerruntime.NewWebhookManagedBy(mgr).
+			For(&v1alpha1.ClusterDeploymentTemplate{}).
+			WithValidator(templateValidator).
+			Complete(); err != nil {
+			return fmt.Errorf("clusterdeploymenttemplate webhook: %w", err)
+		}
+		if err := controllerruntime.NewWebhookManagedBy(mgr).
+			For(&v1alpha1.ClusterTemplateLibrary{}).
+			Complete(); err != nil {
+			return fmt.Errorf("clustertemplatelibrary webhook: %w", err)
+		}
 		if err := controllerruntime.NewWebhookManagedBy(mgr).
 			For(&v1alpha1.ClusterDelivery{}).
 			Complete(); err != nil {
 			return fmt.Errorf("clusterdelivery webhook: %w", err)
 		}
+		if err := controllerruntime.NewWebhookManagedBy(mgr).
+			For(&v1alpha1.Delivery{}).
+			Complete(); err != nil {
+			return fmt.Errorf("delivery webhook: %w", err)
+		}
+		if err := controllerruntime.NewWebhookManagedBy(mgr).
+			For(&v1alpha1.ClusterNotificationSink{}).
+			Complete(); err != nil {
+			return fmt.Errorf("clusternotificationsink webhook: %w", err)
+		}
+		workloadWebhook := controllerruntime.NewWebhookManagedBy(mgr).
+			For(&v1alpha1.Workload{}).
+			WithValidator(admission.NewWorkloadValidator(mgr.GetAPIReader()))
+		if cmd.WorkloadDefaults != "" {
+			configMap, err := parseNamespacedName(cmd.WorkloadDefaults)
+			if err != nil {
+				return fmt.Errorf("workload defaults: %w", err)
+			}
+			workloadWebhook = workloadWebhook.WithDefaulter(admission.NewWorkloadDefaulter(mgr.GetAPIReader(), configMap))
+		}
+		if err := workloadWebhook.Complete(); err != nil {
+			return fmt.Errorf("workload webhook: %w", err)
+		}
 
 	}
 
@@ -111,3 +399,21 @@ func (cmd *Command) Execute() error {
 
 	return nil
 }
+
+// replicaIdentity names this replica uniquely, even across restarts of a
+// pod with the same hostname.
+func replicaIdentity() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("hostname: %w", err)
+	}
+	return hostname + "_" + string(uuid.NewUUID()), nil
+}
+
+func parseNamespacedName(s string) (types.NamespacedName, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, fmt.Errorf("expected namespace/name, got '%s'", s)
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// podSpecPaths are where pod specs sit in the kinds templates commonly
+// stamp: pods, workload controllers (deployments, jobs, ...) and cron jobs.
+var podSpecPaths = [][]string{
+	{"spec"},
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// Inject applies hints to obj. Annotations are set on the object itself;
+// priority class, tolerations and annotations are set on its pod spec, when
+// it has one. Values the template already set are left untouched.
+func Inject(obj *unstructured.Unstructured, hints *v1alpha1.SchedulingHints) error {
+	if obj == nil || hints == nil {
+		return nil
+	}
+
+	if len(hints.Annotations) > 0 {
+		obj.SetAnnotations(withDefaults(obj.GetAnnotations(), hints.Annotations))
+	}
+
+	pod, ok := findPodSpec(obj)
+	if !ok {
+		return nil
+	}
+
+	if len(hints.Annotations) > 0 && len(pod.fields) > 1 {
+		if err := injectPodTemplateAnnotations(obj, pod.fields[:len(pod.fields)-1], hints.Annotations); err != nil {
+			return err
+		}
+	}
+
+	if hints.PriorityClassName != "" {
+		if _, set := pod.spec["priorityClassName"]; !set {
+			pod.spec["priorityClassName"] = hints.PriorityClassName
+		}
+	}
+
+	if len(hints.Tolerations) > 0 {
+		tolerations, err := mergeTolerations(pod.spec["tolerations"], hints.Tolerations)
+		if err != nil {
+			return fmt.Errorf("inject tolerations at '%s': %w", strings.Join(pod.fields, "."), err)
+		}
+		pod.spec["tolerations"] = tolerations
+	}
+
+	return nil
+}
+
+type podSpec struct {
+	fields []string
+	spec   map[string]interface{}
+}
+
+// findPodSpec returns the first of podSpecPaths holding a list of
+// containers, along with the (uncopied) pod spec found there.
+func findPodSpec(obj *unstructured.Unstructured) (podSpec, bool) {
+	for _, fields := range podSpecPaths {
+		spec, found, err := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+		if err != nil || !found {
+			continue
+		}
+		specMap, ok := spec.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := specMap["containers"].([]interface{}); ok {
+			return podSpec{fields: fields, spec: specMap}, true
+		}
+	}
+	return podSpec{}, false
+}
+
+func injectPodTemplateAnnotations(obj *unstructured.Unstructured, templatePath []string, annotations map[string]string) error {
+	metadataPath := append(append([]string{}, templatePath...), "metadata", "annotations")
+	existing, _, err := unstructured.NestedStringMap(obj.Object, metadataPath...)
+	if err != nil {
+		return fmt.Errorf("read pod template annotations: %w", err)
+	}
+	if err := unstructured.SetNestedStringMap(obj.Object, withDefaults(existing, annotations), metadataPath...); err != nil {
+		return fmt.Errorf("set pod template annotations: %w", err)
+	}
+	return nil
+}
+
+func withDefaults(existing, defaults map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(defaults))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range existing {
+		merged[key] = value
+	}
+	return merged
+}
+
+func mergeTolerations(existing interface{}, hinted []corev1.Toleration) ([]interface{}, error) {
+	var tolerations []interface{}
+	if existing != nil {
+		list, ok := existing.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("tolerations is not a list")
+		}
+		tolerations = list
+	}
+
+	present := make([]corev1.Toleration, 0, len(tolerations))
+	for _, t := range tolerations {
+		var toleration corev1.Toleration
+		item, ok := t.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("toleration is not an object")
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item, &toleration); err != nil {
+			return nil, fmt.Errorf("read toleration: %w", err)
+		}
+		present = append(present, toleration)
+	}
+
+	for i := range hinted {
+		if containsToleration(present, hinted[i]) {
+			continue
+		}
+		item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&hinted[i])
+		if err != nil {
+			return nil, fmt.Errorf("convert toleration: %w", err)
+		}
+		tolerations = append(tolerations, item)
+		present = append(present, hinted[i])
+	}
+	return tolerations, nil
+}
+
+func containsToleration(tolerations []corev1.Toleration, toleration corev1.Toleration) bool {
+	for _, t := range tolerations {
+		if reflect.DeepEqual(t, toleration) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduling_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/scheduling"
+)
+
+var _ = Describe("Inject", func() {
+	var hints *v1alpha1.SchedulingHints
+
+	BeforeEach(func() {
+		hints = &v1alpha1.SchedulingHints{
+			PriorityClassName: "platform-default",
+			Tolerations: []corev1.Toleration{
+				{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "builds", Effect: corev1.TaintEffectNoSchedule},
+			},
+			Annotations: map[string]string{"cost-center": "platform"},
+		}
+	})
+
+	Context("on a deployment", func() {
+		var obj *unstructured.Unstructured
+
+		BeforeEach(func() {
+			obj = &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "app"},
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{"name": "app", "image": "app:latest"},
+							},
+						},
+					},
+				},
+			}}
+		})
+
+		It("sets the priority class, tolerations and annotations", func() {
+			Expect(scheduling.Inject(obj, hints)).To(Succeed())
+
+			Expect(obj.GetAnnotations()).To(Equal(map[string]string{"cost-center": "platform"}))
+
+			priorityClassName, _, _ := unstructured.NestedString(obj.Object, "spec", "template", "spec", "priorityClassName")
+			Expect(priorityClassName).To(Equal("platform-default"))
+
+			tolerations, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "tolerations")
+			Expect(tolerations).To(ConsistOf(map[string]interface{}{
+				"key":      "dedicated",
+				"operator": "Equal",
+				"value":    "builds",
+				"effect":   "NoSchedule",
+			}))
+
+			podAnnotations, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
+			Expect(podAnnotations).To(Equal(map[string]string{"cost-center": "platform"}))
+		})
+
+		It("leaves values the template set untouched", func() {
+			obj.SetAnnotations(map[string]string{"cost-center": "team-a"})
+			Expect(unstructured.SetNestedField(obj.Object, "critical", "spec", "template", "spec", "priorityClassName")).To(Succeed())
+			Expect(unstructured.SetNestedSlice(obj.Object, []interface{}{
+				map[string]interface{}{"key": "dedicated", "operator": "Equal", "value": "builds", "effect": "NoSchedule"},
+			}, "spec", "template", "spec", "tolerations")).To(Succeed())
+
+			Expect(scheduling.Inject(obj, hints)).To(Succeed())
+
+			Expect(obj.GetAnnotations()).To(Equal(map[string]string{"cost-center": "team-a"}))
+
+			priorityClassName, _, _ := unstructured.NestedString(obj.Object, "spec", "template", "spec", "priorityClassName")
+			Expect(priorityClassName).To(Equal("critical"))
+
+			tolerations, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "tolerations")
+			Expect(tolerations).To(HaveLen(1))
+		})
+
+		It("returns an error when the template's tolerations are not a list", func() {
+			Expect(unstructured.SetNestedField(obj.Object, "oops", "spec", "template", "spec", "tolerations")).To(Succeed())
+
+			Expect(scheduling.Inject(obj, hints)).To(MatchError(ContainSubstring("inject tolerations at 'spec.template.spec'")))
+		})
+	})
+
+	It("sets the pod spec of a pod", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": "app"},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "app"}},
+			},
+		}}
+
+		Expect(scheduling.Inject(obj, hints)).To(Succeed())
+
+		priorityClassName, _, _ := unstructured.NestedString(obj.Object, "spec", "priorityClassName")
+		Expect(priorityClassName).To(Equal("platform-default"))
+		Expect(obj.GetAnnotations()).To(Equal(map[string]string{"cost-center": "platform"}))
+	})
+
+	It("sets the pod spec of a cron job", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "CronJob",
+			"metadata":   map[string]interface{}{"name": "app"},
+			"spec": map[string]interface{}{
+				"jobTemplate": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"template": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"containers": []interface{}{map[string]interface{}{"name": "app"}},
+							},
+						},
+					},
+				},
+			},
+		}}
+
+		Expect(scheduling.Inject(obj, hints)).To(Succeed())
+
+		priorityClassName, _, _ := unstructured.NestedString(obj.Object, "spec", "jobTemplate", "spec", "template", "spec", "priorityClassName")
+		Expect(priorityClassName).To(Equal("platform-default"))
+	})
+
+	It("only annotates objects without a pod spec", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "config"},
+			"data":       map[string]interface{}{"key": "value"},
+		}}
+
+		Expect(scheduling.Inject(obj, hints)).To(Succeed())
+
+		Expect(obj.GetAnnotations()).To(Equal(map[string]string{"cost-center": "platform"}))
+		Expect(obj.Object).To(HaveLen(4))
+	})
+
+	It("does nothing without hints", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Pod"}}
+		Expect(scheduling.Inject(obj, nil)).To(Succeed())
+		Expect(obj.Object).To(Equal(map[string]interface{}{"kind": "Pod"}))
+	})
+})
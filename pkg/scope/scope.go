@@ -0,0 +1,60 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scope tells the stamped objects of cluster-scoped kinds, such as
+// Namespaces and ClusterRoles, from those of namespaced kinds, by the
+// mappings the apiserver serves.
+package scope
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ClusterScoped reports whether obj is of a cluster-scoped kind, as mapper
+// knows it. Without a mapper, or for a kind the mapper does not know, obj is
+// taken as namespaced: applying an object of a kind that is not registered
+// fails on its own.
+func ClusterScoped(mapper meta.RESTMapper, obj *unstructured.Unstructured) (bool, error) {
+	if mapper == nil {
+		return false, nil
+	}
+
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("scope of kind '%s': %w", gvk.Kind, err)
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameRoot, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the mapper the scope of stamped
+// objects is read from.
+func NewContext(ctx context.Context, mapper meta.RESTMapper) context.Context {
+	return context.WithValue(ctx, contextKey{}, mapper)
+}
+
+// FromContext returns the mapper carried by ctx, or nil.
+func FromContext(ctx context.Context) meta.RESTMapper {
+	mapper, _ := ctx.Value(contextKey{}).(meta.RESTMapper)
+	return mapper
+}
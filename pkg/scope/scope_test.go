@@ -0,0 +1,82 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scope_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/vmware-tanzu/cartographer/pkg/scope"
+)
+
+type failingMapper struct {
+	meta.RESTMapper
+}
+
+func (failingMapper) RESTMapping(schema.GroupKind, ...string) (*meta.RESTMapping, error) {
+	return nil, errors.New("discovery unavailable")
+}
+
+var _ = Describe("ClusterScoped", func() {
+	var mapper *meta.DefaultRESTMapper
+
+	object := func(apiVersion, kind string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName("app")
+		return obj
+	}
+
+	BeforeEach(func() {
+		mapper = meta.NewDefaultRESTMapper(nil)
+		mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+		mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+		mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	})
+
+	It("reports kinds the mapper maps to the root scope as cluster-scoped", func() {
+		Expect(scope.ClusterScoped(mapper, object("v1", "Namespace"))).To(BeTrue())
+		Expect(scope.ClusterScoped(mapper, object("rbac.authorization.k8s.io/v1", "ClusterRole"))).To(BeTrue())
+		Expect(scope.ClusterScoped(mapper, object("v1", "ConfigMap"))).To(BeFalse())
+	})
+
+	It("takes kinds the mapper does not know as namespaced", func() {
+		Expect(scope.ClusterScoped(mapper, object("example.com/v1", "Widget"))).To(BeFalse())
+	})
+
+	It("takes every kind as namespaced without a mapper", func() {
+		Expect(scope.ClusterScoped(nil, object("v1", "Namespace"))).To(BeFalse())
+	})
+
+	It("returns other errors reading the mapping", func() {
+		_, err := scope.ClusterScoped(failingMapper{}, object("v1", "Namespace"))
+		Expect(err).To(MatchError("scope of kind 'Namespace': discovery unavailable"))
+	})
+})
+
+var _ = Describe("Context", func() {
+	It("carries the mapper", func() {
+		mapper := meta.NewDefaultRESTMapper(nil)
+		Expect(scope.FromContext(scope.NewContext(context.Background(), mapper))).To(Equal(mapper))
+		Expect(scope.FromContext(context.Background())).To(BeNil())
+	})
+})
@@ -0,0 +1,127 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shard
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Label pins an object to a shard, overriding the one its name hashes to.
+// Its value is the shard's index.
+const Label = "carto.run/shard"
+
+// Sharder partitions the objects a controller reconciles between replicas,
+// each realizing those of its own shard. An object belongs to the shard
+// named by its carto.run/shard label, or else to the one its namespace and
+// name hash to, so that every replica agrees without coordinating.
+type Sharder struct {
+	client client.Client
+	index  int
+	count  int
+}
+
+// NewSharder returns a Sharder for the shard index of count shards, reading
+// the objects it is asked about through c.
+func NewSharder(c client.Client, index, count int) (*Sharder, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("shard count must be at least 1, not %d", count)
+	}
+	if index < 0 || index >= count {
+		return nil, fmt.Errorf("shard index %d is not between 0 and %d", index, count-1)
+	}
+	return &Sharder{
+		client: c,
+		index:  index,
+		count:  count,
+	}, nil
+}
+
+// IndexFromHostname returns the ordinal that ends the hostname of a
+// StatefulSet's pod, as 2 in cartographer-controller-2.
+func IndexFromHostname(hostname string) (int, error) {
+	ordinal := hostname[strings.LastIndex(hostname, "-")+1:]
+	index, err := strconv.Atoi(ordinal)
+	if err != nil {
+		return 0, fmt.Errorf("hostname '%s' does not end with an ordinal", hostname)
+	}
+	return index, nil
+}
+
+// Of returns the shard, of count shards, the object named key with labels
+// belongs to.
+func Of(key types.NamespacedName, labels map[string]string, count int) int {
+	if value, ok := labels[Label]; ok {
+		if index, err := strconv.Atoi(value); err == nil && index >= 0 && index < count {
+			return index
+		}
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key.String()))
+	return int(hash.Sum32() % uint32(count))
+}
+
+// Owns reports whether obj belongs to this replica's shard. A nil Sharder
+// owns every object.
+func (s *Sharder) Owns(obj client.Object) bool {
+	if s == nil {
+		return true
+	}
+	return Of(client.ObjectKeyFromObject(obj), obj.GetLabels(), s.count) == s.index
+}
+
+// Predicate filters the events of a watch down to the objects this
+// replica's shard owns.
+func (s *Sharder) Predicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(s.Owns)
+}
+
+// Wrap decorates a reconciler so that it only runs for objects this
+// replica's shard owns, whichever watch requested them. newObject returns
+// an empty object of the kind r reconciles. Requests for objects that no
+// longer exist are reconciled by the shard their name hashes to. A nil
+// Sharder returns r unchanged.
+func (s *Sharder) Wrap(newObject func() client.Object, r reconcile.Reconciler) reconcile.Reconciler {
+	if s == nil {
+		return r
+	}
+
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		var labels map[string]string
+		obj := newObject()
+		if err := s.client.Get(ctx, req.NamespacedName, obj); err == nil {
+			labels = obj.GetLabels()
+		} else if !kerrors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("get object to shard: %w", err)
+		}
+
+		if shard := Of(req.NamespacedName, labels, s.count); shard != s.index {
+			logr.FromContextOrDiscard(ctx).V(1).Info("owned by another shard, not reconciling", "request", req.NamespacedName, "shard", shard)
+			return reconcile.Result{}, nil
+		}
+
+		return r.Reconcile(ctx, req)
+	})
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shard_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/shard"
+)
+
+var _ = Describe("Sharder", func() {
+	var (
+		ctx      context.Context
+		c        client.Client
+		workload *v1alpha1.Workload
+		key      types.NamespacedName
+		owner    int
+	)
+
+	newWorkload := func() client.Object { return &v1alpha1.Workload{} }
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		workload = &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-workload",
+				Namespace: "my-namespace",
+			},
+		}
+		key = types.NamespacedName{Namespace: "my-namespace", Name: "my-workload"}
+		owner = shard.Of(key, nil, 3)
+
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(workload).Build()
+	})
+
+	Describe("NewSharder", func() {
+		It("rejects fewer than one shard", func() {
+			_, err := shard.NewSharder(c, 0, 0)
+			Expect(err).To(MatchError("shard count must be at least 1, not 0"))
+		})
+
+		It("rejects an index outside the shards", func() {
+			_, err := shard.NewSharder(c, 3, 3)
+			Expect(err).To(MatchError("shard index 3 is not between 0 and 2"))
+		})
+	})
+
+	Describe("IndexFromHostname", func() {
+		It("reads the ordinal of a StatefulSet's pod", func() {
+			Expect(shard.IndexFromHostname("cartographer-controller-2")).To(Equal(2))
+		})
+
+		It("fails for hostnames without an ordinal", func() {
+			_, err := shard.IndexFromHostname("cartographer-controller-7d9f8")
+			Expect(err).To(MatchError("hostname 'cartographer-controller-7d9f8' does not end with an ordinal"))
+		})
+	})
+
+	Describe("Of", func() {
+		It("hashes the same object to the same shard", func() {
+			Expect(shard.Of(key, nil, 3)).To(Equal(owner))
+			Expect(owner).To(BeNumerically(">=", 0))
+			Expect(owner).To(BeNumerically("<", 3))
+		})
+
+		It("spreads objects across every shard", func() {
+			counts := make([]int, 4)
+			for i := 0; i < 1000; i++ {
+				counts[shard.Of(types.NamespacedName{Namespace: "team", Name: fmt.Sprintf("app-%d", i)}, nil, 4)]++
+			}
+			for _, count := range counts {
+				Expect(count).To(BeNumerically(">", 150))
+			}
+		})
+
+		It("follows the shard label", func() {
+			Expect(shard.Of(key, map[string]string{shard.Label: "1"}, 3)).To(Equal(1))
+		})
+
+		It("ignores shard labels that name no shard", func() {
+			Expect(shard.Of(key, map[string]string{shard.Label: "7"}, 3)).To(Equal(owner))
+			Expect(shard.Of(key, map[string]string{shard.Label: "one"}, 3)).To(Equal(owner))
+		})
+	})
+
+	Describe("Predicate", func() {
+		It("lets through the events of owned objects only", func() {
+			sharder, err := shard.NewSharder(c, owner, 3)
+			Expect(err).NotTo(HaveOccurred())
+			other, err := shard.NewSharder(c, (owner+1)%3, 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sharder.Predicate().Create(event.CreateEvent{Object: workload})).To(BeTrue())
+			Expect(other.Predicate().Create(event.CreateEvent{Object: workload})).To(BeFalse())
+		})
+
+		It("lets an object relabelled into the shard through", func() {
+			other, err := shard.NewSharder(c, (owner+1)%3, 3)
+			Expect(err).NotTo(HaveOccurred())
+			relabelled := workload.DeepCopy()
+			relabelled.Labels = map[string]string{shard.Label: fmt.Sprint((owner + 1) % 3)}
+
+			Expect(other.Predicate().Update(event.UpdateEvent{ObjectOld: workload, ObjectNew: relabelled})).To(BeTrue())
+		})
+	})
+
+	Describe("Wrap", func() {
+		var (
+			reconciled int
+			inner      reconcile.Reconciler
+		)
+
+		BeforeEach(func() {
+			reconciled = 0
+			inner = reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				reconciled++
+				return reconcile.Result{}, nil
+			})
+		})
+
+		It("reconciles the objects of its shard", func() {
+			sharder, err := shard.NewSharder(c, owner, 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = sharder.Wrap(newWorkload, inner).Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciled).To(Equal(1))
+		})
+
+		It("skips the objects of other shards", func() {
+			sharder, err := shard.NewSharder(c, (owner+1)%3, 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := sharder.Wrap(newWorkload, inner).Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+			Expect(reconciled).To(Equal(0))
+		})
+
+		It("reads the shard label of the object", func() {
+			workload.Labels = map[string]string{shard.Label: fmt.Sprint((owner + 1) % 3)}
+			Expect(c.Update(ctx, workload)).To(Succeed())
+			sharder, err := shard.NewSharder(c, (owner+1)%3, 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = sharder.Wrap(newWorkload, inner).Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciled).To(Equal(1))
+		})
+
+		It("leaves deleted objects to the shard their name hashes to", func() {
+			Expect(c.Delete(ctx, workload)).To(Succeed())
+			sharder, err := shard.NewSharder(c, owner, 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = sharder.Wrap(newWorkload, inner).Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciled).To(Equal(1))
+		})
+
+		It("returns the reconciler unchanged when nil", func() {
+			var sharder *shard.Sharder
+
+			_, err := sharder.Wrap(newWorkload, inner).Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciled).To(Equal(1))
+			Expect(sharder.Owns(workload)).To(BeTrue())
+		})
+	})
+})
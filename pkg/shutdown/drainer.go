@@ -0,0 +1,144 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// StatusFlushTimeout bounds the final status update of a reconcile that was
+// cut off at the drain deadline.
+const StatusFlushTimeout = 5 * time.Second
+
+// Drainer lets reconciles that are in flight when the manager stops run to
+// completion for up to a timeout, while refusing to start new ones. It must
+// be added to the manager as a runnable so that it observes the stop.
+type Drainer struct {
+	timeout time.Duration
+	expired chan struct{}
+
+	mu       sync.Mutex
+	stopping bool
+	inFlight sync.WaitGroup
+}
+
+func NewDrainer(timeout time.Duration) *Drainer {
+	return &Drainer{
+		timeout: timeout,
+		expired: make(chan struct{}),
+	}
+}
+
+// Start blocks until ctx is done, then stops new reconciles from starting and
+// waits for the in-flight ones to finish, cancelling those still running once
+// the timeout has elapsed.
+func (d *Drainer) Start(ctx context.Context) error {
+	<-ctx.Done()
+	d.mu.Lock()
+	d.stopping = true
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+	case <-timer.C:
+	}
+
+	close(d.expired)
+	return nil
+}
+
+// NeedLeaderElection is false so that non-leader replicas drain too.
+func (d *Drainer) NeedLeaderElection() bool {
+	return false
+}
+
+// Wrap decorates a reconciler so that it runs with a context that survives the
+// manager stopping until the drain deadline.
+func (d *Drainer) Wrap(r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		d.mu.Lock()
+		if d.stopping {
+			d.mu.Unlock()
+			logr.FromContextOrDiscard(ctx).Info("shutting down, not starting reconcile", "request", req.NamespacedName)
+			return reconcile.Result{}, nil
+		}
+		// counted under the lock, so that Start never waits on a reconcile
+		// it has let start after it began waiting.
+		d.inFlight.Add(1)
+		d.mu.Unlock()
+		defer d.inFlight.Done()
+
+		drainCtx, cancel := d.context(ctx)
+		defer cancel()
+
+		return r.Reconcile(drainCtx, req)
+	})
+}
+
+func (d *Drainer) context(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(detached{parent: parent})
+	go func() {
+		select {
+		case <-d.expired:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// FlushContext returns a context for writing the final status of a reconcile.
+// If ctx has already been cancelled the returned context keeps its values but
+// allows StatusFlushTimeout for the write, so conditions are not left stale.
+func FlushContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx.Err() == nil {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(detached{parent: ctx}, StatusFlushTimeout)
+}
+
+// detached carries the values of its parent but none of its cancellation.
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
+
+func (d detached) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/cartographer/pkg/shutdown"
+)
+
+type ctxKey struct{}
+
+var _ = Describe("Drainer", func() {
+	var (
+		drainer    *shutdown.Drainer
+		managerCtx context.Context
+		stop       context.CancelFunc
+		started    chan context.Context
+		release    chan struct{}
+		wrapped    reconcile.Reconciler
+		req        reconcile.Request
+		drained    chan struct{}
+	)
+
+	BeforeEach(func() {
+		managerCtx, stop = context.WithCancel(context.Background())
+		started = make(chan context.Context, 1)
+		release = make(chan struct{})
+		req = reconcile.Request{NamespacedName: types.NamespacedName{Name: "my-workload", Namespace: "default"}}
+	})
+
+	JustBeforeEach(func() {
+		drained = make(chan struct{})
+		go func(d *shutdown.Drainer, ctx context.Context, drained chan struct{}) {
+			defer GinkgoRecover()
+			defer close(drained)
+			Expect(d.Start(ctx)).To(Succeed())
+		}(drainer, managerCtx, drained)
+
+		started, release := started, release
+		wrapped = drainer.Wrap(reconcile.Func(func(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+			started <- ctx
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return reconcile.Result{}, ctx.Err()
+		}))
+	})
+
+	AfterEach(func() {
+		stop()
+	})
+
+	Context("with a drain timeout", func() {
+		BeforeEach(func() {
+			drainer = shutdown.NewDrainer(time.Hour)
+		})
+
+		It("keeps the values of the reconcile context", func() {
+			ctx := context.WithValue(managerCtx, ctxKey{}, "value")
+			go func() { _, _ = wrapped.Reconcile(ctx, req) }()
+
+			var reconcileCtx context.Context
+			Eventually(started).Should(Receive(&reconcileCtx))
+			Expect(reconcileCtx.Value(ctxKey{})).To(Equal("value"))
+			close(release)
+		})
+
+		It("lets an in-flight reconcile finish after the manager stops", func() {
+			result := make(chan error, 1)
+			go func() {
+				_, err := wrapped.Reconcile(managerCtx, req)
+				result <- err
+			}()
+
+			var reconcileCtx context.Context
+			Eventually(started).Should(Receive(&reconcileCtx))
+
+			stop()
+			Consistently(reconcileCtx.Done(), "100ms").ShouldNot(BeClosed())
+
+			close(release)
+			Eventually(result).Should(Receive(BeNil()))
+			Eventually(drained).Should(BeClosed())
+		})
+
+		It("returns right away when no reconcile is in flight", func() {
+			stop()
+			Eventually(drained, "1s").Should(BeClosed())
+		})
+
+		It("waits for in-flight reconciles before returning", func() {
+			go func() { _, _ = wrapped.Reconcile(managerCtx, req) }()
+			Eventually(started).Should(Receive())
+
+			stop()
+			Consistently(drained, "100ms").ShouldNot(BeClosed())
+
+			close(release)
+			Eventually(drained).Should(BeClosed())
+		})
+
+		It("does not start new reconciles once the manager stops", func() {
+			var calls int
+			counting := drainer.Wrap(reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				calls++
+				return reconcile.Result{}, nil
+			}))
+
+			stop()
+
+			Eventually(func() int {
+				calls = 0
+				_, _ = counting.Reconcile(context.Background(), req)
+				return calls
+			}).Should(Equal(0))
+		})
+	})
+
+	Context("when the drain timeout elapses", func() {
+		BeforeEach(func() {
+			drainer = shutdown.NewDrainer(50 * time.Millisecond)
+		})
+
+		It("cancels the in-flight reconcile", func() {
+			result := make(chan error, 1)
+			go func() {
+				_, err := wrapped.Reconcile(managerCtx, req)
+				result <- err
+			}()
+			Eventually(started).Should(Receive())
+
+			stop()
+			Eventually(result).Should(Receive(MatchError(context.Canceled)))
+		})
+	})
+})
+
+var _ = Describe("FlushContext", func() {
+	It("returns a live context when the reconcile context is live", func() {
+		ctx, cancel := shutdown.FlushContext(context.WithValue(context.Background(), ctxKey{}, "value"))
+		defer cancel()
+
+		Expect(ctx.Err()).NotTo(HaveOccurred())
+		Expect(ctx.Value(ctxKey{})).To(Equal("value"))
+	})
+
+	It("returns a live, bounded context when the reconcile context has been cancelled", func() {
+		parent, cancelParent := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+		cancelParent()
+
+		ctx, cancel := shutdown.FlushContext(parent)
+		defer cancel()
+
+		Expect(ctx.Err()).NotTo(HaveOccurred())
+		Expect(ctx.Value(ctxKey{})).To(Equal("value"))
+		deadline, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(shutdown.StatusFlushTimeout), time.Second))
+	})
+})
@@ -0,0 +1,85 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teardown
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+)
+
+// Collect deletes the tracked objects stamped for the blueprint whose owners
+// are gone, and returns how many it deleted. Its templates are those of the
+// resources with Tracked ownership. An object stamped for an owner that was
+// deleted and created again under the same name is deleted too: the new
+// owner stamps its own.
+func Collect(ctx context.Context, repo repository.Repository, blueprint Blueprint) (int, error) {
+	kinds := stampedKinds(blueprint.Templates)
+	if len(kinds) == 0 {
+		return 0, nil
+	}
+
+	owners, err := ownerUIDs(ctx, repo, blueprint)
+	if err != nil {
+		return 0, err
+	}
+
+	collected := 0
+	for _, gvk := range kinds {
+		list := &unstructured.Unstructured{}
+		list.SetGroupVersionKind(gvk)
+		list.SetLabels(map[string]string{blueprint.Label: blueprint.Name})
+		objects, err := repo.ListUnstructured(ctx, list)
+		if err != nil {
+			return collected, fmt.Errorf("list %s: %w", gvk.Kind, err)
+		}
+
+		for _, obj := range objects {
+			uid, tracked := obj.GetAnnotations()[v1alpha1.TrackedOwnerAnnotation]
+			if !tracked || obj.GetDeletionTimestamp() != nil || owners[ownerOf(obj, blueprint.OwnerKind)] == types.UID(uid) {
+				continue
+			}
+			if err := repo.DeleteUnstructured(ctx, obj); err != nil {
+				return collected, fmt.Errorf("delete %s '%s/%s': %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+			}
+			collected++
+		}
+	}
+	return collected, nil
+}
+
+// ownerUIDs returns the UIDs of the owners of the blueprint's kind of owner,
+// whichever blueprint they are realized with.
+func ownerUIDs(ctx context.Context, repo repository.Repository, blueprint Blueprint) (map[types.NamespacedName]types.UID, error) {
+	list := &unstructured.Unstructured{}
+	list.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(blueprint.OwnerKind))
+	list.SetNamespace(blueprint.Namespace)
+	candidates, err := repo.ListUnstructured(ctx, list)
+	if err != nil {
+		return nil, fmt.Errorf("list %ss: %w", strings.ToLower(blueprint.OwnerKind), err)
+	}
+
+	owners := map[types.NamespacedName]types.UID{}
+	for _, candidate := range candidates {
+		owners[types.NamespacedName{Namespace: candidate.GetNamespace(), Name: candidate.GetName()}] = candidate.GetUID()
+	}
+	return owners, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teardown_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/teardown"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+var _ = Describe("Collect", func() {
+	var (
+		ctx       context.Context
+		repo      *repositoryfakes.FakeRepository
+		blueprint teardown.Blueprint
+		workloads []*unstructured.Unstructured
+		stamped   []*unstructured.Unstructured
+	)
+
+	workload := func(name string, uid types.UID) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("carto.run/v1alpha1")
+		obj.SetKind("Workload")
+		obj.SetNamespace("my-ns")
+		obj.SetName(name)
+		obj.SetUID(uid)
+		return obj
+	}
+
+	trackedFor := func(name, workloadName, uid string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("rbac.authorization.k8s.io/v1")
+		obj.SetKind("ClusterRole")
+		obj.SetName(name)
+		obj.SetLabels(map[string]string{
+			"carto.run/cluster-supply-chain-name": "my-supply-chain",
+			"carto.run/workload-name":             workloadName,
+			"carto.run/workload-namespace":        "my-ns",
+		})
+		if uid != "" {
+			obj.SetAnnotations(map[string]string{v1alpha1.TrackedOwnerAnnotation: uid})
+		}
+		return obj
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &repositoryfakes.FakeRepository{}
+
+		apiTemplate := &v1alpha1.ClusterTemplate{}
+		apiTemplate.Spec.Template = &runtime.RawExtension{Raw: []byte(`{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRole"}`)}
+		model, err := templates.NewModelFromAPI(apiTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		blueprint = teardown.Blueprint{
+			Kind:      "ClusterSupplyChain",
+			Name:      "my-supply-chain",
+			Label:     "carto.run/cluster-supply-chain-name",
+			OwnerKind: "Workload",
+			Templates: []templates.Template{model},
+		}
+
+		workloads = []*unstructured.Unstructured{
+			workload("live", "live-uid"),
+			workload("recreated", "new-uid"),
+		}
+		stamped = []*unstructured.Unstructured{
+			trackedFor("of-live", "live", "live-uid"),
+			trackedFor("of-deleted", "deleted", "deleted-uid"),
+			trackedFor("of-recreated", "recreated", "old-uid"),
+			trackedFor("owned", "deleted", ""),
+		}
+
+		repo.ListUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+			if obj.GetKind() == "Workload" {
+				return workloads, nil
+			}
+			return stamped, nil
+		}
+	})
+
+	It("lists the stamped kinds by the blueprint's label", func() {
+		_, err := teardown.Collect(ctx, repo, blueprint)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(repo.ListUnstructuredCallCount()).To(Equal(2))
+		_, list := repo.ListUnstructuredArgsForCall(1)
+		Expect(list.GetKind()).To(Equal("ClusterRole"))
+		Expect(list.GetLabels()).To(Equal(map[string]string{"carto.run/cluster-supply-chain-name": "my-supply-chain"}))
+	})
+
+	It("deletes the tracked objects whose owners are gone or were created again", func() {
+		collected, err := teardown.Collect(ctx, repo, blueprint)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(collected).To(Equal(2))
+
+		Expect(repo.DeleteUnstructuredCallCount()).To(Equal(2))
+		_, deleted := repo.DeleteUnstructuredArgsForCall(0)
+		Expect(deleted.GetName()).To(Equal("of-deleted"))
+		_, deleted = repo.DeleteUnstructuredArgsForCall(1)
+		Expect(deleted.GetName()).To(Equal("of-recreated"))
+	})
+
+	It("leaves objects already being deleted", func() {
+		now := metav1.Now()
+		stamped[1].SetDeletionTimestamp(&now)
+
+		collected, err := teardown.Collect(ctx, repo, blueprint)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(collected).To(Equal(1))
+	})
+
+	It("does nothing without templates", func() {
+		blueprint.Templates = nil
+
+		collected, err := teardown.Collect(ctx, repo, blueprint)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(collected).To(BeZero())
+		Expect(repo.ListUnstructuredCallCount()).To(BeZero())
+	})
+
+	It("returns errors deleting", func() {
+		repo.DeleteUnstructuredReturns(errors.New("forbidden"))
+
+		_, err := teardown.Collect(ctx, repo, blueprint)
+		Expect(err).To(MatchError("delete ClusterRole '/of-deleted': forbidden"))
+	})
+})
@@ -0,0 +1,51 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teardown
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+func StartingCondition(ownerKind string) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintTornDown,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.TearingDownTornDownReason,
+		Message: fmt.Sprintf("waiting for %ss to stop stamping", strings.ToLower(ownerKind)),
+	}
+}
+
+func TearingDownCondition(progress Progress, ownerKind string) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintTornDown,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.TearingDownTornDownReason,
+		Message: fmt.Sprintf("%d objects stamped for %d %ss remaining", progress.Remaining, progress.Owners, strings.ToLower(ownerKind)),
+	}
+}
+
+func FailedCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    v1alpha1.BlueprintTornDown,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.TeardownFailedTornDownReason,
+		Message: err.Error(),
+	}
+}
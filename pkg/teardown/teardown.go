@@ -0,0 +1,182 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package teardown deletes or orphans the objects stamped on behalf of a
+// blueprint that is being deleted, across all of its owners, and collects
+// the tracked objects of owners that are gone.
+package teardown
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// Blueprint is a supply chain or delivery being torn down.
+type Blueprint struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Policy    string
+
+	// Label is the label holding the blueprint's name on the objects
+	// stamped for it, such as carto.run/cluster-supply-chain-name.
+	Label string
+
+	// OwnerKind is the kind of the objects the blueprint stamps for,
+	// Workload or Deliverable, and OwnerRefField the field of their status
+	// referring to the blueprint that selected them.
+	OwnerKind     string
+	OwnerRefField string
+
+	// Templates of the blueprint's resources, whose objects are stamped.
+	Templates []templates.Template
+}
+
+// Progress of a teardown. It is complete once no object is left.
+type Progress struct {
+	Owners    int
+	Remaining int
+}
+
+func (p Progress) Complete() bool {
+	return p.Remaining == 0
+}
+
+// Run deletes or orphans, according to the blueprint's policy, the objects
+// stamped for the blueprint's owners, and reports how many it found. Objects
+// found may take some time to go, so a teardown is complete once a run finds
+// none.
+func Run(ctx context.Context, repo repository.Repository, blueprint Blueprint) (Progress, error) {
+	owners, err := findOwners(ctx, repo, blueprint)
+	if err != nil {
+		return Progress{}, err
+	}
+	progress := Progress{Owners: len(owners)}
+	if len(owners) == 0 {
+		return progress, nil
+	}
+
+	for _, gvk := range stampedKinds(blueprint.Templates) {
+		list := &unstructured.Unstructured{}
+		list.SetGroupVersionKind(gvk)
+		list.SetLabels(map[string]string{blueprint.Label: blueprint.Name})
+		objects, err := repo.ListUnstructured(ctx, list)
+		if err != nil {
+			return progress, fmt.Errorf("list %s: %w", gvk.Kind, err)
+		}
+
+		for _, obj := range objects {
+			if !owners[ownerOf(obj, blueprint.OwnerKind)] {
+				continue
+			}
+			progress.Remaining++
+			if err := tearDown(ctx, repo, blueprint, obj); err != nil {
+				return progress, fmt.Errorf("tear down %s '%s/%s': %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+			}
+		}
+	}
+	return progress, nil
+}
+
+// findOwners returns the owners whose status refers to the blueprint.
+func findOwners(ctx context.Context, repo repository.Repository, blueprint Blueprint) (map[types.NamespacedName]bool, error) {
+	list := &unstructured.Unstructured{}
+	list.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(blueprint.OwnerKind))
+	list.SetNamespace(blueprint.Namespace)
+	candidates, err := repo.ListUnstructured(ctx, list)
+	if err != nil {
+		return nil, fmt.Errorf("list %ss: %w", strings.ToLower(blueprint.OwnerKind), err)
+	}
+
+	owners := map[types.NamespacedName]bool{}
+	for _, candidate := range candidates {
+		kind, _, _ := unstructured.NestedString(candidate.Object, "status", blueprint.OwnerRefField, "kind")
+		name, _, _ := unstructured.NestedString(candidate.Object, "status", blueprint.OwnerRefField, "name")
+		if kind == blueprint.Kind && name == blueprint.Name {
+			owners[types.NamespacedName{Namespace: candidate.GetNamespace(), Name: candidate.GetName()}] = true
+		}
+	}
+	return owners, nil
+}
+
+// stampedKinds returns the kinds of the objects the templates stamp. The
+// kinds of ytt templates cannot be known without stamping them, so their
+// objects are not torn down.
+func stampedKinds(blueprintTemplates []templates.Template) []schema.GroupVersionKind {
+	var kinds []schema.GroupVersionKind
+	seen := map[schema.GroupVersionKind]bool{}
+	for _, template := range blueprintTemplates {
+		raw := template.GetResourceTemplate().Template
+		if raw == nil {
+			continue
+		}
+		types, err := templates.TemplatedTypes(raw.Raw)
+		if err != nil {
+			continue
+		}
+		for _, typeMeta := range types {
+			if typeMeta.Kind == "" {
+				continue
+			}
+			gvk := typeMeta.GroupVersionKind()
+			if !seen[gvk] {
+				seen[gvk] = true
+				kinds = append(kinds, gvk)
+			}
+		}
+	}
+	return kinds
+}
+
+// ownerOf returns the owner obj was stamped for, by the labels every
+// stamped object carries.
+func ownerOf(obj *unstructured.Unstructured, ownerKind string) types.NamespacedName {
+	prefix := "carto.run/" + strings.ToLower(ownerKind)
+	labels := obj.GetLabels()
+	return types.NamespacedName{Namespace: labels[prefix+"-namespace"], Name: labels[prefix+"-name"]}
+}
+
+// tearDown deletes obj or, to orphan it, removes its owner's reference and
+// the label tying it to the blueprint, so that it neither goes with the
+// owner nor is found again.
+func tearDown(ctx context.Context, repo repository.Repository, blueprint Blueprint, obj *unstructured.Unstructured) error {
+	if blueprint.Policy == v1alpha1.DeleteTeardownPolicy {
+		return repo.DeleteUnstructured(ctx, obj)
+	}
+
+	var refs []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Kind == blueprint.OwnerKind && strings.HasPrefix(ref.APIVersion, v1alpha1.SchemeGroupVersion.Group+"/") {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	obj.SetOwnerReferences(refs)
+
+	labels := obj.GetLabels()
+	delete(labels, blueprint.Label)
+	obj.SetLabels(labels)
+
+	return repo.Update(ctx, obj)
+}
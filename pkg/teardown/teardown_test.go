@@ -0,0 +1,233 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package teardown_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/repository/repositoryfakes"
+	"github.com/vmware-tanzu/cartographer/pkg/teardown"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+var _ = Describe("Run", func() {
+	var (
+		ctx       context.Context
+		repo      *repositoryfakes.FakeRepository
+		blueprint teardown.Blueprint
+		workloads []*unstructured.Unstructured
+		stamped   []*unstructured.Unstructured
+	)
+
+	template := func(raw string) templates.Template {
+		apiTemplate := &v1alpha1.ClusterTemplate{}
+		if raw == "" {
+			apiTemplate.Spec.Ytt = "kind: #@ data.values.kind"
+		} else {
+			apiTemplate.Spec.Template = &runtime.RawExtension{Raw: []byte(raw)}
+		}
+		model, err := templates.NewModelFromAPI(apiTemplate)
+		Expect(err).NotTo(HaveOccurred())
+		return model
+	}
+
+	workload := func(name, supplyChainKind, supplyChainName string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("carto.run/v1alpha1")
+		obj.SetKind("Workload")
+		obj.SetNamespace("my-ns")
+		obj.SetName(name)
+		Expect(unstructured.SetNestedField(obj.Object, supplyChainKind, "status", "supplyChainRef", "kind")).To(Succeed())
+		Expect(unstructured.SetNestedField(obj.Object, supplyChainName, "status", "supplyChainRef", "name")).To(Succeed())
+		return obj
+	}
+
+	stampedFor := func(name, workloadName string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetNamespace("my-ns")
+		obj.SetName(name)
+		obj.SetLabels(map[string]string{
+			"carto.run/cluster-supply-chain-name": "my-supply-chain",
+			"carto.run/workload-name":             workloadName,
+			"carto.run/workload-namespace":        "my-ns",
+			"app":                                 "mine",
+		})
+		obj.SetOwnerReferences([]metav1.OwnerReference{
+			{APIVersion: "carto.run/v1alpha1", Kind: "Workload", Name: workloadName},
+			{APIVersion: "v1", Kind: "ConfigMap", Name: "other-owner"},
+		})
+		return obj
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = &repositoryfakes.FakeRepository{}
+
+		blueprint = teardown.Blueprint{
+			Kind:          "ClusterSupplyChain",
+			Name:          "my-supply-chain",
+			Policy:        v1alpha1.DeleteTeardownPolicy,
+			Label:         "carto.run/cluster-supply-chain-name",
+			OwnerKind:     "Workload",
+			OwnerRefField: "supplyChainRef",
+			Templates: []templates.Template{
+				template(`{"apiVersion": "v1", "kind": "ConfigMap"}`),
+				template(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "again"}}`),
+				template(""),
+			},
+		}
+
+		workloads = []*unstructured.Unstructured{
+			workload("mine", "ClusterSupplyChain", "my-supply-chain"),
+			workload("other-chain", "ClusterSupplyChain", "other-supply-chain"),
+			workload("other-kind", "SupplyChain", "my-supply-chain"),
+		}
+		stamped = []*unstructured.Unstructured{
+			stampedFor("first", "mine"),
+			stampedFor("second", "mine"),
+			stampedFor("not-mine", "other-chain"),
+		}
+
+		repo.ListUnstructuredStub = func(_ context.Context, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+			if obj.GetKind() == "Workload" {
+				return workloads, nil
+			}
+			return stamped, nil
+		}
+	})
+
+	It("lists the owners in the blueprint's namespace", func() {
+		blueprint.Namespace = "my-ns"
+		_, err := teardown.Run(ctx, repo, blueprint)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, list := repo.ListUnstructuredArgsForCall(0)
+		Expect(list.GetAPIVersion()).To(Equal("carto.run/v1alpha1"))
+		Expect(list.GetKind()).To(Equal("Workload"))
+		Expect(list.GetNamespace()).To(Equal("my-ns"))
+	})
+
+	It("lists each kind stamped by the templates once, by the blueprint's label", func() {
+		_, err := teardown.Run(ctx, repo, blueprint)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(repo.ListUnstructuredCallCount()).To(Equal(2))
+		_, list := repo.ListUnstructuredArgsForCall(1)
+		Expect(list.GetAPIVersion()).To(Equal("v1"))
+		Expect(list.GetKind()).To(Equal("ConfigMap"))
+		Expect(list.GetNamespace()).To(BeEmpty())
+		Expect(list.GetLabels()).To(Equal(map[string]string{"carto.run/cluster-supply-chain-name": "my-supply-chain"}))
+	})
+
+	It("deletes the objects stamped for the blueprint's owners", func() {
+		progress, err := teardown.Run(ctx, repo, blueprint)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(progress).To(Equal(teardown.Progress{Owners: 1, Remaining: 2}))
+		Expect(progress.Complete()).To(BeFalse())
+
+		Expect(repo.DeleteUnstructuredCallCount()).To(Equal(2))
+		_, deleted := repo.DeleteUnstructuredArgsForCall(0)
+		Expect(deleted.GetName()).To(Equal("first"))
+		_, deleted = repo.DeleteUnstructuredArgsForCall(1)
+		Expect(deleted.GetName()).To(Equal("second"))
+		Expect(repo.UpdateCallCount()).To(Equal(0))
+	})
+
+	Context("when the policy is Orphan", func() {
+		BeforeEach(func() {
+			blueprint.Policy = v1alpha1.OrphanTeardownPolicy
+		})
+
+		It("removes the owner's reference and the blueprint's label rather than deleting", func() {
+			progress, err := teardown.Run(ctx, repo, blueprint)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(progress).To(Equal(teardown.Progress{Owners: 1, Remaining: 2}))
+
+			Expect(repo.DeleteUnstructuredCallCount()).To(Equal(0))
+			Expect(repo.UpdateCallCount()).To(Equal(2))
+
+			_, updated := repo.UpdateArgsForCall(0)
+			Expect(updated.GetName()).To(Equal("first"))
+			Expect(updated.GetOwnerReferences()).To(Equal([]metav1.OwnerReference{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "other-owner"},
+			}))
+			Expect(updated.GetLabels()).To(Equal(map[string]string{
+				"carto.run/workload-name":      "mine",
+				"carto.run/workload-namespace": "my-ns",
+				"app":                          "mine",
+			}))
+		})
+	})
+
+	Context("when nothing is left", func() {
+		BeforeEach(func() {
+			stamped = stamped[2:]
+		})
+
+		It("is complete", func() {
+			progress, err := teardown.Run(ctx, repo, blueprint)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(progress.Complete()).To(BeTrue())
+		})
+	})
+
+	Context("when no owner refers to the blueprint", func() {
+		BeforeEach(func() {
+			workloads = workloads[1:]
+		})
+
+		It("is complete without listing stamped objects", func() {
+			progress, err := teardown.Run(ctx, repo, blueprint)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(progress).To(Equal(teardown.Progress{}))
+			Expect(progress.Complete()).To(BeTrue())
+			Expect(repo.ListUnstructuredCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when deleting an object fails", func() {
+		BeforeEach(func() {
+			repo.DeleteUnstructuredReturns(errors.New("deleting is hard"))
+		})
+
+		It("returns a helpful error", func() {
+			_, err := teardown.Run(ctx, repo, blueprint)
+			Expect(err).To(MatchError("tear down ConfigMap 'my-ns/first': deleting is hard"))
+		})
+	})
+
+	Context("when listing owners fails", func() {
+		BeforeEach(func() {
+			repo.ListUnstructuredReturns(nil, errors.New("listing is hard"))
+			repo.ListUnstructuredStub = nil
+		})
+
+		It("returns a helpful error", func() {
+			_, err := teardown.Run(ctx, repo, blueprint)
+			Expect(err).To(MatchError("list workloads: listing is hard"))
+		})
+	})
+})
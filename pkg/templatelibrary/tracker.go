@@ -0,0 +1,67 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatelibrary
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Tracker remembers the ClusterTemplateLibraries included by the templates
+// stamped for each owner, so that changes to a library reconcile the owners
+// whose objects were stamped with it.
+type Tracker struct {
+	mu        sync.Mutex
+	libraries map[types.NamespacedName][]string
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		libraries: map[types.NamespacedName][]string{},
+	}
+}
+
+// Track replaces the names of the libraries the owner's objects were
+// stamped with.
+func (t *Tracker) Track(owner types.NamespacedName, libraries []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(libraries) == 0 {
+		delete(t.libraries, owner)
+		return
+	}
+	t.libraries[owner] = libraries
+}
+
+// Requests returns a request for each owner stamped with the library.
+func (t *Tracker) Requests(obj client.Object) []reconcile.Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var requests []reconcile.Request
+	for owner, libraries := range t.libraries {
+		for _, name := range libraries {
+			if name == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: owner})
+				break
+			}
+		}
+	}
+	return requests
+}
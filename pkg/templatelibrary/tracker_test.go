@@ -0,0 +1,64 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatelibrary_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templatelibrary"
+)
+
+var _ = Describe("Tracker", func() {
+	var (
+		tracker *templatelibrary.Tracker
+		web     types.NamespacedName
+		worker  types.NamespacedName
+	)
+
+	library := func(name string) *v1alpha1.ClusterTemplateLibrary {
+		return &v1alpha1.ClusterTemplateLibrary{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	BeforeEach(func() {
+		tracker = templatelibrary.NewTracker()
+		web = types.NamespacedName{Namespace: "some-ns", Name: "web"}
+		worker = types.NamespacedName{Namespace: "other-ns", Name: "worker"}
+
+		tracker.Track(web, []string{"probes", "standard"})
+		tracker.Track(worker, []string{"standard"})
+	})
+
+	It("requests the owners stamped with a library", func() {
+		Expect(tracker.Requests(library("standard"))).To(ConsistOf(
+			reconcile.Request{NamespacedName: web},
+			reconcile.Request{NamespacedName: worker},
+		))
+		Expect(tracker.Requests(library("probes"))).To(ConsistOf(reconcile.Request{NamespacedName: web}))
+		Expect(tracker.Requests(library("unused"))).To(BeEmpty())
+	})
+
+	It("forgets the libraries an owner is no longer stamped with", func() {
+		tracker.Track(web, []string{"standard"})
+		Expect(tracker.Requests(library("probes"))).To(BeEmpty())
+
+		tracker.Track(worker, nil)
+		Expect(tracker.Requests(library("standard"))).To(ConsistOf(reconcile.Request{NamespacedName: web}))
+	})
+})
@@ -0,0 +1,59 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Paths of what an ArgoCD Application reports in its status.
+const (
+	argoCDHealthStatusPath = ".status.health.status"
+	argoCDSyncStatusPath   = ".status.sync.status"
+)
+
+// argoCDReady returns an error unless the Application is synced with its
+// source and healthy.
+func argoCDReady(obj *unstructured.Unstructured) error {
+	sync, _, _ := unstructured.NestedString(obj.Object, "status", "sync", "status")
+	if sync != "Synced" {
+		return &JsonPathError{
+			Err:        fmt.Errorf("%s '%s' is not synced: %s", obj.GetKind(), obj.GetName(), orUnknown(sync)),
+			expression: argoCDSyncStatusPath,
+		}
+	}
+
+	health, _, _ := unstructured.NestedString(obj.Object, "status", "health", "status")
+	if health != "Healthy" {
+		err := fmt.Errorf("%s '%s' is not healthy: %s", obj.GetKind(), obj.GetName(), orUnknown(health))
+		if message, _, _ := unstructured.NestedString(obj.Object, "status", "health", "message"); message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+		return &JsonPathError{
+			Err:        err,
+			expression: argoCDHealthStatusPath,
+		}
+	}
+	return nil
+}
+
+func orUnknown(status string) string {
+	if status == "" {
+		return "Unknown"
+	}
+	return status
+}
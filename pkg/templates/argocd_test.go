@@ -0,0 +1,96 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+	"github.com/vmware-tanzu/cartographer/pkg/templates/templatesfakes"
+)
+
+var _ = Describe("ArgoCD preset", func() {
+	var (
+		application *unstructured.Unstructured
+		output      *templates.Output
+		err         error
+	)
+
+	BeforeEach(func() {
+		application = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Application",
+			"metadata":   map[string]interface{}{"name": "app"},
+			"status": map[string]interface{}{
+				"sync":   map[string]interface{}{"status": "Synced"},
+				"health": map[string]interface{}{"status": "Healthy"},
+			},
+		}}
+	})
+
+	JustBeforeEach(func() {
+		template := &v1alpha1.ClusterDeploymentTemplate{
+			Spec: v1alpha1.TemplateSpec{Preset: v1alpha1.ArgoCDTemplatePreset},
+		}
+		output, err = templates.NewClusterDeploymentTemplateModel(template, &templatesfakes.FakeEvaluator{}).GetOutput(application)
+	})
+
+	It("returns the outputs of a synced and healthy Application", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).NotTo(BeNil())
+	})
+
+	When("the Application is out of sync", func() {
+		BeforeEach(func() {
+			application.Object["status"].(map[string]interface{})["sync"] = map[string]interface{}{"status": "OutOfSync"}
+		})
+
+		It("returns an error naming the sync status", func() {
+			jsonPathErr, ok := err.(*templates.JsonPathError)
+			Expect(ok).To(BeTrue())
+			Expect(jsonPathErr.JsonPathExpression()).To(Equal(".status.sync.status"))
+			Expect(err).To(MatchError(ContainSubstring("Application 'app' is not synced: OutOfSync")))
+		})
+	})
+
+	When("the Application is degraded", func() {
+		BeforeEach(func() {
+			application.Object["status"].(map[string]interface{})["health"] = map[string]interface{}{
+				"status":  "Degraded",
+				"message": "Deployment has minimum availability",
+			}
+		})
+
+		It("returns an error naming the health status", func() {
+			jsonPathErr, ok := err.(*templates.JsonPathError)
+			Expect(ok).To(BeTrue())
+			Expect(jsonPathErr.JsonPathExpression()).To(Equal(".status.health.status"))
+			Expect(err).To(MatchError(ContainSubstring("Application 'app' is not healthy: Degraded: Deployment has minimum availability")))
+		})
+	})
+
+	When("the Application has no status yet", func() {
+		BeforeEach(func() {
+			delete(application.Object, "status")
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(ContainSubstring("Application 'app' is not synced: Unknown")))
+		})
+	})
+})
@@ -15,8 +15,6 @@
 package templates
 
 import (
-	"fmt"
-
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
@@ -39,17 +37,25 @@ func (t clusterConfigTemplate) GetName() string {
 	return t.template.Name
 }
 
+func (t clusterConfigTemplate) GetGeneration() int64 {
+	return t.template.Generation
+}
+
 func (t clusterConfigTemplate) GetOutput(stampedObject *unstructured.Unstructured) (*Output, error) {
-	config, err := t.evaluator.EvaluateJsonPath(t.template.Spec.ConfigPath, stampedObject.UnstructuredContent())
+	if err := checkPreset(t.template.Spec.TemplateSpec, stampedObject); err != nil {
+		return nil, err
+	}
+
+	reader := newOutputReader(t.evaluator, stampedObject, t.template.Spec.OptionalOutputs)
+	config, err := reader.read(configOutput, t.template.Spec.ConfigPath, t.template.Spec.ConfigExpression, t.template.Spec.ConfigDefault)
 	if err != nil {
-		return nil, &JsonPathError{
-			Err:        fmt.Errorf("evaluate config url json path: %w", err),
-			expression: t.template.Spec.ConfigPath,
-		}
+		return nil, err
 	}
 
 	return &Output{
-		Config: config,
+		Config:    config,
+		Missing:   reader.missing,
+		Sensitive: t.template.Spec.SensitiveOutputs,
 	}, nil
 }
 
@@ -60,3 +66,11 @@ func (t clusterConfigTemplate) GetResourceTemplate() v1alpha1.TemplateSpec {
 func (t clusterConfigTemplate) GetDefaultParams() v1alpha1.DefaultParams {
 	return t.template.Spec.Params
 }
+
+func (t clusterConfigTemplate) GetPatches() *v1alpha1.ConfigPatches {
+	return t.template.Spec.Patches
+}
+
+func (t clusterConfigTemplate) GetSensitiveOutputs() []string {
+	return t.template.Spec.SensitiveOutputs
+}
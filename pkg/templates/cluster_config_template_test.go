@@ -19,6 +19,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
@@ -76,6 +77,17 @@ var _ = Describe("ClusterConfigTemplate", func() {
 
 				Expect(output.Config).To(Equal("some value"))
 			})
+
+			When("the config is sensitive", func() {
+				BeforeEach(func() {
+					configTemplate.Spec.SensitiveOutputs = []string{"config"}
+				})
+
+				It("marks it sensitive", func() {
+					Expect(output.Sensitive).To(Equal([]string{"config"}))
+					Expect(output.SensitiveValues()).To(Equal([]interface{}{"some value"}))
+				})
+			})
 		})
 		When("passed a stamped object for which the evaluator cannot return a value at the configPath", func() {
 			BeforeEach(func() {
@@ -90,6 +102,44 @@ var _ = Describe("ClusterConfigTemplate", func() {
 				Expect(jsonPathErr.JsonPathExpression()).To(Equal("some.path"))
 			})
 			ItReturnsAHelpfulError("some error")
+
+			When("the config is optional", func() {
+				BeforeEach(func() {
+					configTemplate.Spec.OptionalOutputs = []string{"config"}
+				})
+
+				It("outputs null and reports the config as missing", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(output.Config).To(BeNil())
+					Expect(output.Missing).To(Equal([]templates.MissingOutput{{Name: "config", Expression: "some.path"}}))
+				})
+			})
+		})
+		When("the template reads the config with an expression", func() {
+			BeforeEach(func() {
+				configTemplate.Spec.ConfigPath = ""
+				configTemplate.Spec.ConfigExpression = `[value.spec.host, string(value.spec.port)].join(":")`
+				stampedObject = &unstructured.Unstructured{Object: map[string]interface{}{
+					"spec": map[string]interface{}{"host": "db.example.com", "port": int64(5432)},
+				}}
+			})
+			It("evaluates the expression over the stamped object", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(output.Config).To(Equal("db.example.com:5432"))
+				Expect(evaluator.EvaluateJsonPathCallCount()).To(Equal(0))
+			})
+
+			When("the stamped object has no value for it yet, and the template has a default", func() {
+				BeforeEach(func() {
+					delete(stampedObject.Object, "spec")
+					configTemplate.Spec.ConfigDefault = &apiextensionsv1.JSON{Raw: []byte(`{"host": "localhost", "port": 5432}`)}
+				})
+
+				It("returns the default", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(output.Config).To(Equal(map[string]interface{}{"host": "localhost", "port": float64(5432)}))
+				})
+			})
 		})
 	})
 })
@@ -37,7 +37,14 @@ func (t clusterDeploymentTemplate) GetName() string {
 	return t.template.Name
 }
 
+func (t clusterDeploymentTemplate) GetGeneration() int64 {
+	return t.template.Generation
+}
+
 func (t clusterDeploymentTemplate) GetOutput(stampedObject *unstructured.Unstructured) (*Output, error) {
+	if err := checkPreset(t.template.Spec, stampedObject); err != nil {
+		return nil, err
+	}
 	return &Output{}, nil
 }
 
@@ -17,8 +17,6 @@ package templates
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
 
 import (
-	"fmt"
-
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
@@ -41,17 +39,26 @@ func (t clusterImageTemplate) GetName() string {
 	return t.template.Name
 }
 
+func (t clusterImageTemplate) GetGeneration() int64 {
+	return t.template.Generation
+}
+
 func (t clusterImageTemplate) GetOutput(stampedObject *unstructured.Unstructured) (*Output, error) {
-	image, err := t.evaluator.EvaluateJsonPath(t.template.Spec.ImagePath, stampedObject.UnstructuredContent())
+	if err := checkPreset(t.template.Spec.TemplateSpec, stampedObject); err != nil {
+		return nil, err
+	}
+
+	imagePath := presetPath(t.template.Spec.TemplateSpec, t.template.Spec.ImagePath, imageOutput)
+	reader := newOutputReader(t.evaluator, stampedObject, t.template.Spec.OptionalOutputs)
+	image, err := reader.read(imageOutput, imagePath, t.template.Spec.ImageExpression, t.template.Spec.ImageDefault)
 	if err != nil {
-		return nil, &JsonPathError{
-			Err:        fmt.Errorf("evaluate image json path: %w", err),
-			expression: t.template.Spec.ImagePath,
-		}
+		return nil, err
 	}
 
 	return &Output{
-		Image: image,
+		Image:     image,
+		Missing:   reader.missing,
+		Sensitive: t.template.Spec.SensitiveOutputs,
 	}, nil
 }
 
@@ -92,5 +92,47 @@ var _ = Describe("ClusterImageTemplate", func() {
 			})
 			ItReturnsAHelpfulError("some error")
 		})
+
+		When("the template has the Kpack preset", func() {
+			BeforeEach(func() {
+				imageTemplate.Spec.Preset = v1alpha1.KpackTemplatePreset
+				imageTemplate.Spec.ImagePath = ""
+
+				stampedObject = &unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "kpack.io/v1alpha2",
+					"kind":       "Image",
+					"metadata":   map[string]interface{}{"name": "app", "generation": int64(1)},
+					"status": map[string]interface{}{
+						"observedGeneration": int64(1),
+						"latestImage":        "registry.example.com/app@sha256:abc",
+						"conditions": []interface{}{
+							map[string]interface{}{"type": "Ready", "status": "True"},
+						},
+					},
+				}}
+				evaluator.EvaluateJsonPathReturns("registry.example.com/app@sha256:abc", nil)
+			})
+
+			It("reads the latest image", func() {
+				Expect(err).NotTo(HaveOccurred())
+				path, _ := evaluator.EvaluateJsonPathArgsForCall(0)
+				Expect(path).To(Equal(".status.latestImage"))
+				Expect(output.Image).To(Equal("registry.example.com/app@sha256:abc"))
+			})
+
+			When("the build has failed", func() {
+				BeforeEach(func() {
+					stampedObject.Object["status"].(map[string]interface{})["conditions"] = []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False", "message": "Build failed"},
+					}
+				})
+
+				It("does not return an output", func() {
+					Expect(output).To(BeNil())
+					Expect(evaluator.EvaluateJsonPathCallCount()).To(Equal(0))
+				})
+				ItReturnsAHelpfulError("Image 'app' is not ready: Build failed")
+			})
+		})
 	})
 })
@@ -127,9 +127,56 @@ func (t runTemplate) getOutputsOfSingleObject(evaluator eval.Evaluator, stampedO
 		ext := apiextensionsv1.JSON{Raw: result}
 		provisionalOutputs[key] = ext
 	}
+
+	// a run that has not succeeded has no results to read yet.
+	if len(t.template.Spec.Results) > 0 && succeeded(stampedObject) {
+		results := tektonResults(stampedObject)
+		for key, name := range t.template.Spec.Results {
+			result, ok := results[name]
+			if !ok {
+				objectErr = fmt.Errorf("get output: %s '%s' has no result '%s'", stampedObject.GetKind(), stampedObject.GetName(), name)
+				continue
+			}
+			provisionalOutputs[key] = result
+		}
+	}
 	return objectErr, provisionalOutputs
 }
 
+// succeeded reports whether the object's Succeeded condition is True.
+func succeeded(obj unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Succeeded" {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
+
+// tektonResults returns the results of a Tekton PipelineRun or TaskRun by
+// name. Array and object results are kept as they are.
+func tektonResults(obj unstructured.Unstructured) map[string]apiextensionsv1.JSON {
+	results := map[string]apiextensionsv1.JSON{}
+	for _, field := range []string{"results", "pipelineResults", "taskResults"} {
+		entries, _, _ := unstructured.NestedSlice(obj.Object, "status", field)
+		for _, e := range entries {
+			entry, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := entry["name"].(string)
+			raw, err := json.Marshal(entry["value"])
+			if name == "" || err != nil {
+				continue
+			}
+			results[name] = apiextensionsv1.JSON{Raw: raw}
+		}
+	}
+	return results
+}
+
 func NewRunTemplateModel(template *v1alpha1.ClusterRunTemplate) ClusterRunTemplate {
 	return &runTemplate{template: template}
 }
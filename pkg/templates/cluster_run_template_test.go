@@ -157,6 +157,89 @@ var _ = Describe("ClusterRunTemplate", func() {
 			})
 		})
 
+		Context("when the template reads Tekton results", func() {
+			var pipelineRun *unstructured.Unstructured
+
+			BeforeEach(func() {
+				apiTemplate.Spec.Results = map[string]string{
+					"revision": "commit",
+					"digests":  "image-digests",
+				}
+
+				pipelineRun = &unstructured.Unstructured{}
+				manifest := utils.HereYamlF(`
+					apiVersion: tekton.dev/v1
+					kind: PipelineRun
+					metadata:
+					  name: build-abc
+					  namespace: somens
+					  creationTimestamp: "2021-09-17T16:02:30Z"
+					status:
+					  conditions:
+					    - type: Succeeded
+					      status: "True"
+					  results:
+					    - name: commit
+					      value: abc123
+					    - name: image-digests
+					      value: [sha256:aaa, sha256:bbb]
+				`)
+				dec := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+				_, _, err := dec.Decode([]byte(manifest), nil, pipelineRun)
+				Expect(err).NotTo(HaveOccurred())
+				stampedObjects = []*unstructured.Unstructured{pipelineRun}
+			})
+
+			It("returns the results by name", func() {
+				template := templates.NewRunTemplateModel(apiTemplate)
+				outputs, err := template.GetOutput(stampedObjects)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(outputs["revision"]).To(Equal(apiextensionsv1.JSON{Raw: []byte(`"abc123"`)}))
+				Expect(outputs["digests"]).To(Equal(apiextensionsv1.JSON{Raw: []byte(`["sha256:aaa","sha256:bbb"]`)}))
+			})
+
+			Context("when the run reports v1beta1 results", func() {
+				BeforeEach(func() {
+					status := pipelineRun.Object["status"].(map[string]interface{})
+					status["pipelineResults"] = status["results"]
+					delete(status, "results")
+				})
+
+				It("returns the results by name", func() {
+					template := templates.NewRunTemplateModel(apiTemplate)
+					outputs, err := template.GetOutput(stampedObjects)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(outputs["revision"]).To(Equal(apiextensionsv1.JSON{Raw: []byte(`"abc123"`)}))
+				})
+			})
+
+			Context("when the run has not succeeded", func() {
+				BeforeEach(func() {
+					Expect(utils.AlterFieldOfNestedStringMaps(pipelineRun.Object, "status.conditions.[0]status", "Unknown")).To(Succeed())
+					delete(pipelineRun.Object["status"].(map[string]interface{}), "results")
+				})
+
+				It("returns empty outputs", func() {
+					template := templates.NewRunTemplateModel(apiTemplate)
+					outputs, err := template.GetOutput(stampedObjects)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(outputs).To(BeEmpty())
+				})
+			})
+
+			Context("when a result is missing", func() {
+				BeforeEach(func() {
+					apiTemplate.Spec.Results["tag"] = "image-tag"
+				})
+
+				It("returns a helpful error", func() {
+					template := templates.NewRunTemplateModel(apiTemplate)
+					_, err := template.GetOutput(stampedObjects)
+					Expect(err).To(MatchError("get output: PipelineRun 'build-abc' has no result 'image-tag'"))
+				})
+			})
+		})
+
 		Context("when there are multiple objects", func() {
 			BeforeEach(func() {
 				stampedObjects = []*unstructured.Unstructured{secondStampedObject, firstStampedObject}
@@ -15,8 +15,6 @@
 package templates
 
 import (
-	"fmt"
-
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
@@ -39,27 +37,34 @@ func (t clusterSourceTemplate) GetName() string {
 	return t.template.Name
 }
 
+func (t clusterSourceTemplate) GetGeneration() int64 {
+	return t.template.Generation
+}
+
 func (t clusterSourceTemplate) GetOutput(stampedObject *unstructured.Unstructured) (*Output, error) {
-	url, err := t.evaluator.EvaluateJsonPath(t.template.Spec.URLPath, stampedObject.UnstructuredContent())
+	if err := checkPreset(t.template.Spec.TemplateSpec, stampedObject); err != nil {
+		return nil, err
+	}
+
+	urlPath := presetPath(t.template.Spec.TemplateSpec, t.template.Spec.URLPath, urlOutput)
+	reader := newOutputReader(t.evaluator, stampedObject, t.template.Spec.OptionalOutputs)
+	url, err := reader.read(urlOutput, urlPath, t.template.Spec.URLExpression, t.template.Spec.URLDefault)
 	if err != nil {
-		return nil, &JsonPathError{
-			Err:        fmt.Errorf("evaluate source url json path: %w", err),
-			expression: t.template.Spec.URLPath,
-		}
+		return nil, err
 	}
 
-	revision, err := t.evaluator.EvaluateJsonPath(t.template.Spec.RevisionPath, stampedObject.UnstructuredContent())
+	revisionPath := presetPath(t.template.Spec.TemplateSpec, t.template.Spec.RevisionPath, revisionOutput)
+	revision, err := reader.read(revisionOutput, revisionPath, t.template.Spec.RevisionExpression, t.template.Spec.RevisionDefault)
 	if err != nil {
-		return nil, &JsonPathError{
-			Err:        fmt.Errorf("evaluate source revision json path: %w", err),
-			expression: t.template.Spec.RevisionPath,
-		}
+		return nil, err
 	}
 	return &Output{
 		Source: &Source{
 			URL:      url,
 			Revision: revision,
 		},
+		Missing:   reader.missing,
+		Sensitive: t.template.Spec.SensitiveOutputs,
 	}, nil
 }
 
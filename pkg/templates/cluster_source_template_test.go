@@ -19,6 +19,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
@@ -112,6 +113,166 @@ var _ = Describe("ClusterSourceTemplate", func() {
 				Expect(jsonPathErr.JsonPathExpression()).To(Equal("some.url.path"))
 			})
 			ItReturnsAHelpfulError("some error")
+
+			When("the template has defaults for the outputs", func() {
+				BeforeEach(func() {
+					sourceTemplate.Spec.URLDefault = &apiextensionsv1.JSON{Raw: []byte(`"https://example.com/placeholder.tar.gz"`)}
+					sourceTemplate.Spec.RevisionDefault = &apiextensionsv1.JSON{Raw: []byte(`"none"`)}
+				})
+
+				It("returns the defaults", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(*output.Source).To(Equal(templates.Source{
+						URL:      "https://example.com/placeholder.tar.gz",
+						Revision: "none",
+					}))
+				})
+			})
+
+			When("the template has a default for only one of the outputs", func() {
+				BeforeEach(func() {
+					sourceTemplate.Spec.URLDefault = &apiextensionsv1.JSON{Raw: []byte(`"https://example.com/placeholder.tar.gz"`)}
+				})
+
+				It("returns an error which identifies the path without a default", func() {
+					Expect(output).To(BeNil())
+					jsonPathErr, ok := err.(*templates.JsonPathError)
+					Expect(ok).To(BeTrue())
+					Expect(jsonPathErr.JsonPathExpression()).To(Equal("some.revision.path"))
+				})
+
+				When("the other output is optional", func() {
+					BeforeEach(func() {
+						sourceTemplate.Spec.OptionalOutputs = []string{"revision"}
+					})
+
+					It("outputs the default, and null for the optional output", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(*output.Source).To(Equal(templates.Source{URL: "https://example.com/placeholder.tar.gz"}))
+						Expect(output.Missing).To(Equal([]templates.MissingOutput{{Name: "revision", Expression: "some.revision.path"}}))
+					})
+				})
+			})
+		})
+
+		When("the template reads the url with an expression", func() {
+			BeforeEach(func() {
+				sourceTemplate.Spec.URLPath = ""
+				sourceTemplate.Spec.URLExpression = `value.status.url.startsWith("https://") ? value.status.url.replace("https://", "") : value.status.url`
+				stampedObject = &unstructured.Unstructured{Object: map[string]interface{}{
+					"status": map[string]interface{}{"url": "https://example.com/source.tar.gz"},
+				}}
+				evaluator.EvaluateJsonPathReturns("some other value", nil)
+			})
+
+			It("evaluates the expression over the stamped object and the path for the revision", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*output.Source).To(Equal(templates.Source{
+					URL:      "example.com/source.tar.gz",
+					Revision: "some other value",
+				}))
+
+				Expect(evaluator.EvaluateJsonPathCallCount()).To(Equal(1))
+				path, _ := evaluator.EvaluateJsonPathArgsForCall(0)
+				Expect(path).To(Equal(revisionPath))
+			})
+
+			When("the expression fails over the stamped object", func() {
+				BeforeEach(func() {
+					stampedObject.Object = map[string]interface{}{}
+				})
+
+				It("returns an error which identifies the failing expression", func() {
+					Expect(output).To(BeNil())
+					expressionErr, ok := err.(*templates.OutputExpressionError)
+					Expect(ok).To(BeTrue())
+					Expect(expressionErr.JsonPathExpression()).To(Equal(sourceTemplate.Spec.URLExpression))
+				})
+				ItReturnsAHelpfulError("evaluate source url expression")
+			})
+		})
+
+		When("the template has the Flux preset", func() {
+			BeforeEach(func() {
+				sourceTemplate.Spec.Preset = v1alpha1.FluxTemplatePreset
+				sourceTemplate.Spec.URLPath = ""
+				sourceTemplate.Spec.RevisionPath = ""
+
+				stampedObject = &unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "source.toolkit.fluxcd.io/v1beta2",
+					"kind":       "GitRepository",
+					"metadata":   map[string]interface{}{"name": "app", "generation": int64(2)},
+					"status": map[string]interface{}{
+						"observedGeneration": int64(2),
+						"conditions": []interface{}{
+							map[string]interface{}{"type": "Ready", "status": "True"},
+						},
+					},
+				}}
+				evaluator.EvaluateJsonPathReturns("some value", nil)
+			})
+
+			It("reads the artifact Flux publishes", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(evaluator.EvaluateJsonPathCallCount()).To(Equal(2))
+
+				path, _ := evaluator.EvaluateJsonPathArgsForCall(0)
+				Expect(path).To(Equal(".status.artifact.url"))
+				path, _ = evaluator.EvaluateJsonPathArgsForCall(1)
+				Expect(path).To(Equal(".status.artifact.revision"))
+			})
+
+			When("the template sets its own paths", func() {
+				BeforeEach(func() {
+					sourceTemplate.Spec.URLPath = urlPath
+					sourceTemplate.Spec.RevisionPath = revisionPath
+				})
+
+				It("reads them instead", func() {
+					path, _ := evaluator.EvaluateJsonPathArgsForCall(0)
+					Expect(path).To(Equal(urlPath))
+					path, _ = evaluator.EvaluateJsonPathArgsForCall(1)
+					Expect(path).To(Equal(revisionPath))
+				})
+			})
+
+			When("the object is not ready", func() {
+				BeforeEach(func() {
+					stampedObject.Object["status"] = map[string]interface{}{
+						"observedGeneration": int64(2),
+						"conditions": []interface{}{
+							map[string]interface{}{"type": "Ready", "status": "False", "message": "failed to checkout"},
+						},
+					}
+				})
+
+				It("returns an error naming the Ready condition", func() {
+					Expect(output).To(BeNil())
+					Expect(err).To(MatchError("evaluate json path '.status.conditions[?(@.type==\"Ready\")].status': GitRepository 'app' is not ready: failed to checkout"))
+					Expect(evaluator.EvaluateJsonPathCallCount()).To(Equal(0))
+				})
+			})
+
+			When("the object has not observed its generation", func() {
+				BeforeEach(func() {
+					stampedObject.Object["status"].(map[string]interface{})["observedGeneration"] = int64(1)
+				})
+
+				It("returns an error naming the observed generation", func() {
+					jsonPathErr, ok := err.(*templates.JsonPathError)
+					Expect(ok).To(BeTrue())
+					Expect(jsonPathErr.JsonPathExpression()).To(Equal(".status.observedGeneration"))
+					Expect(err).To(MatchError(ContainSubstring("GitRepository 'app' has not observed generation 2")))
+				})
+			})
+
+			When("the object has no Ready condition", func() {
+				BeforeEach(func() {
+					delete(stampedObject.Object, "status")
+				})
+
+				ItReturnsAHelpfulError("GitRepository 'app' has no Ready condition")
+			})
 		})
 	})
 })
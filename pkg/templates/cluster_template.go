@@ -36,14 +36,24 @@ func (t clusterTemplate) GetName() string {
 	return t.template.Name
 }
 
-func (t clusterTemplate) GetOutput(_ *unstructured.Unstructured) (*Output, error) {
+func (t clusterTemplate) GetGeneration() int64 {
+	return t.template.Generation
+}
+
+func (t clusterTemplate) GetOutput(stampedObject *unstructured.Unstructured) (*Output, error) {
+	if err := checkPreset(t.template.Spec, stampedObject); err != nil {
+		return nil, err
+	}
 	return &Output{}, nil
 }
 
 func (t clusterTemplate) GetResourceTemplate() v1alpha1.TemplateSpec {
 	return v1alpha1.TemplateSpec{
-		Template: t.template.Spec.Template,
-		Ytt:      t.template.Spec.Ytt,
+		Template:  t.template.Spec.Template,
+		Ytt:       t.template.Spec.Ytt,
+		Lifecycle: t.template.Spec.Lifecycle,
+		Results:   t.template.Spec.Results,
+		Libraries: t.template.Spec.Libraries,
 	}
 }
 
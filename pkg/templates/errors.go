@@ -34,6 +34,30 @@ func (e JsonPathError) Error() string {
 	return fmt.Errorf("evaluate json path '%s': %w", e.expression, e.Err).Error()
 }
 
+func (e JsonPathError) Unwrap() error {
+	return e.Err
+}
+
 func (e JsonPathError) JsonPathExpression() string {
 	return e.expression
 }
+
+// OutputExpressionError is returned when the CEL expression of an output
+// fails over the stamped object. It reports the expression the way a
+// JsonPathError reports its path.
+type OutputExpressionError struct {
+	Err        error
+	expression string
+}
+
+func (e OutputExpressionError) Error() string {
+	return fmt.Errorf("evaluate output expression '%s': %w", e.expression, e.Err).Error()
+}
+
+func (e OutputExpressionError) Unwrap() error {
+	return e.Err
+}
+
+func (e OutputExpressionError) JsonPathExpression() string {
+	return e.expression
+}
@@ -18,16 +18,20 @@ type SourceInput struct {
 	URL      interface{} `json:"url"`
 	Revision interface{} `json:"revision"`
 	Name     string      `json:"name"`
+	// Stale inputs are the last outputs of a resource waiting for new ones.
+	Stale bool `json:"stale"`
 }
 
 type ImageInput struct {
 	Image interface{} `json:"image"`
 	Name  string      `json:"name"`
+	Stale bool        `json:"stale"`
 }
 
 type ConfigInput struct {
 	Config interface{} `json:"config"`
 	Name   string      `json:"name"`
+	Stale  bool        `json:"stale"`
 }
 
 type Inputs struct {
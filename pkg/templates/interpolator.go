@@ -16,12 +16,16 @@ package templates
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
 	"strings"
 
 	"github.com/valyala/fasttemplate"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/eval"
 )
 
 type TemplateExecutor func(template, startTag, endTag string, f fasttemplate.TagFunc) (string, error)
@@ -69,11 +73,30 @@ func InterpolateLeafNode(executor TemplateExecutor, template []byte, tagInterpol
 type StandardTagInterpolator struct {
 	Context   JsonPathContext
 	Evaluator evaluator
+	// MissingKey is what a tag reading a value that is not there does:
+	// "error" fails on a null value as well as a missing one, "zero"
+	// evaluates either to nil.
+	MissingKey string
 }
 
 //counterfeiter:generate io.Writer
 func (t StandardTagInterpolator) Evaluate(tag string) (interface{}, error) {
-	return t.Evaluator.EvaluateJsonPath(tag, t.Context)
+	val, err := t.evaluate(tag)
+	if err != nil {
+		return nil, err
+	}
+	if val == nil && t.MissingKey == v1alpha1.ErrorMissingKey {
+		return nil, fmt.Errorf("tag must not point to nil value: %s", tag)
+	}
+	return val, nil
+}
+
+func (t StandardTagInterpolator) evaluate(tag string) (interface{}, error) {
+	val, err := t.Evaluator.EvaluateJsonPath(tag, t.Context)
+	if err != nil && t.MissingKey == v1alpha1.ZeroMissingKey && errors.Is(err, eval.ErrNoResults) {
+		return nil, nil
+	}
+	return val, err
 }
 
 func (t StandardTagInterpolator) InterpolateTag(w io.Writer, tag string) (int, error) {
@@ -84,12 +107,15 @@ func (t StandardTagInterpolator) InterpolateTag(w io.Writer, tag string) (int, e
 		jsonValue []byte
 	)
 
-	val, err = t.Evaluator.EvaluateJsonPath(tag, t.Context)
+	val, err = t.evaluate(tag)
 	if err != nil {
 		return 0, fmt.Errorf("evaluate jsonpath: %w", err)
 	}
 
 	if val == nil {
+		if t.MissingKey == v1alpha1.ZeroMissingKey {
+			return 0, nil
+		}
 		return 0, fmt.Errorf("tag must not point to nil value: %s", tag)
 	}
 
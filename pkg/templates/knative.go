@@ -0,0 +1,39 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// knativeReady returns an error unless the Service is ready for its
+// current generation and serves the latest revision it created.
+func knativeReady(obj *unstructured.Unstructured) error {
+	if err := readyForGeneration(obj); err != nil {
+		return err
+	}
+
+	created, _, _ := unstructured.NestedString(obj.Object, "status", "latestCreatedRevisionName")
+	ready, _, _ := unstructured.NestedString(obj.Object, "status", "latestReadyRevisionName")
+	if ready == "" || ready != created {
+		return &JsonPathError{
+			Err:        fmt.Errorf("%s '%s' has not made revision '%s' ready", obj.GetKind(), obj.GetName(), created),
+			expression: knativeRevisionPath,
+		}
+	}
+	return nil
+}
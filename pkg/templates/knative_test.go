@@ -0,0 +1,98 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+	"github.com/vmware-tanzu/cartographer/pkg/templates/templatesfakes"
+)
+
+var _ = Describe("Knative preset", func() {
+	var (
+		service   *unstructured.Unstructured
+		evaluator *templatesfakes.FakeEvaluator
+		output    *templates.Output
+		err       error
+	)
+
+	BeforeEach(func() {
+		service = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "serving.knative.dev/v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": "app", "generation": int64(3)},
+			"status": map[string]interface{}{
+				"observedGeneration":        int64(3),
+				"url":                       "http://app.default.example.com",
+				"latestCreatedRevisionName": "app-00003",
+				"latestReadyRevisionName":   "app-00003",
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True"},
+				},
+			},
+		}}
+		evaluator = &templatesfakes.FakeEvaluator{}
+		evaluator.EvaluateJsonPathReturns("some value", nil)
+	})
+
+	JustBeforeEach(func() {
+		template := &v1alpha1.ClusterSourceTemplate{
+			Spec: v1alpha1.SourceTemplateSpec{
+				TemplateSpec: v1alpha1.TemplateSpec{Preset: v1alpha1.KnativeTemplatePreset},
+			},
+		}
+		output, err = templates.NewClusterSourceTemplateModel(template, evaluator).GetOutput(service)
+	})
+
+	It("reads the url and the latest ready revision of a ready Service", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Source).NotTo(BeNil())
+
+		path, _ := evaluator.EvaluateJsonPathArgsForCall(0)
+		Expect(path).To(Equal(".status.url"))
+		path, _ = evaluator.EvaluateJsonPathArgsForCall(1)
+		Expect(path).To(Equal(".status.latestReadyRevisionName"))
+	})
+
+	When("the latest revision is not ready", func() {
+		BeforeEach(func() {
+			service.Object["status"].(map[string]interface{})["latestCreatedRevisionName"] = "app-00004"
+		})
+
+		It("returns an error naming the latest ready revision", func() {
+			jsonPathErr, ok := err.(*templates.JsonPathError)
+			Expect(ok).To(BeTrue())
+			Expect(jsonPathErr.JsonPathExpression()).To(Equal(".status.latestReadyRevisionName"))
+			Expect(err).To(MatchError(ContainSubstring("Service 'app' has not made revision 'app-00004' ready")))
+		})
+	})
+
+	When("the Service is not ready", func() {
+		BeforeEach(func() {
+			service.Object["status"].(map[string]interface{})["conditions"] = []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "message": "Revision failed"},
+			}
+		})
+
+		It("returns an error naming the Ready condition", func() {
+			Expect(err).To(MatchError(ContainSubstring("Service 'app' is not ready: Revision failed")))
+			Expect(evaluator.EvaluateJsonPathCallCount()).To(Equal(0))
+		})
+	})
+})
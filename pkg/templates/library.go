@@ -0,0 +1,139 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/transform"
+)
+
+// LibraryGetter gets the named ClusterTemplateLibrary, or nil when there is
+// none.
+type LibraryGetter func(ctx context.Context, name string) (*v1alpha1.ClusterTemplateLibrary, error)
+
+// GetLibraries gets the named libraries, in order. It returns an error when
+// one of them does not exist.
+func GetLibraries(ctx context.Context, getLibrary LibraryGetter, names []string) ([]*v1alpha1.ClusterTemplateLibrary, error) {
+	var libraries []*v1alpha1.ClusterTemplateLibrary
+	for _, name := range names {
+		library, err := getLibrary(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("get template library '%s': %w", name, err)
+		}
+		if library == nil {
+			return nil, fmt.Errorf("template library '%s' not found", name)
+		}
+		libraries = append(libraries, library)
+	}
+	return libraries, nil
+}
+
+// IncludeLibraries adds the partials and helpers of libraries to the
+// templating context, by name, for templates to include as
+// $(partials.<name>)$ and $(helpers.<name>)$. Helpers are evaluated over
+// the context as it was before either was added. The context is left as is
+// when there are no libraries.
+func IncludeLibraries(libraries []*v1alpha1.ClusterTemplateLibrary, templatingContext map[string]interface{}) error {
+	if len(libraries) == 0 {
+		return nil
+	}
+
+	partials := map[string]interface{}{}
+	partialsBy := map[string]string{}
+	for _, library := range libraries {
+		for _, partial := range library.Spec.Partials {
+			if other, ok := partialsBy[partial.Name]; ok {
+				return fmt.Errorf("partial '%s' is defined by template libraries '%s' and '%s'", partial.Name, other, library.Name)
+			}
+			var value interface{}
+			if err := json.Unmarshal(partial.Template.Raw, &value); err != nil {
+				return fmt.Errorf("unmarshal partial '%s' of template library '%s': %w", partial.Name, library.Name, err)
+			}
+			partials[partial.Name] = value
+			partialsBy[partial.Name] = library.Name
+		}
+	}
+
+	helpers, err := evaluateHelpers(libraries, templatingContext)
+	if err != nil {
+		return err
+	}
+
+	templatingContext["partials"] = partials
+	templatingContext["helpers"] = helpers
+	return nil
+}
+
+func evaluateHelpers(libraries []*v1alpha1.ClusterTemplateLibrary, templatingContext map[string]interface{}) (map[string]interface{}, error) {
+	var value map[string]interface{}
+	helpers := map[string]interface{}{}
+	helpersBy := map[string]string{}
+	for _, library := range libraries {
+		for _, helper := range library.Spec.Helpers {
+			if other, ok := helpersBy[helper.Name]; ok {
+				return nil, fmt.Errorf("helper '%s' is defined by template libraries '%s' and '%s'", helper.Name, other, library.Name)
+			}
+			helpersBy[helper.Name] = library.Name
+
+			if value == nil {
+				// CEL reads plain values, not the types the context holds
+				raw, err := json.Marshal(templatingContext)
+				if err != nil {
+					return nil, fmt.Errorf("marshal templating context: %w", err)
+				}
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return nil, fmt.Errorf("unmarshal templating context: %w", err)
+				}
+			}
+
+			result, err := transform.Apply(helper.Expression, value)
+			if err != nil {
+				return nil, fmt.Errorf("helper '%s' of template library '%s': %w", helper.Name, library.Name, err)
+			}
+			helpers[helper.Name] = result
+		}
+	}
+	return helpers, nil
+}
+
+var libraryReference = regexp.MustCompile(`\$\(\s*(partials|helpers)\.([A-Za-z0-9_-]+)`)
+
+// CheckLibraryReferences returns an error when the raw template includes a
+// partial or helper that none of libraries defines. ytt templates, which read
+// them as data values, are not checked.
+func CheckLibraryReferences(raw []byte, libraries []*v1alpha1.ClusterTemplateLibrary) error {
+	defined := map[string]bool{}
+	for _, library := range libraries {
+		for _, partial := range library.Spec.Partials {
+			defined["partials."+partial.Name] = true
+		}
+		for _, helper := range library.Spec.Helpers {
+			defined["helpers."+helper.Name] = true
+		}
+	}
+
+	for _, match := range libraryReference.FindAllSubmatch(raw, -1) {
+		kind, name := string(match[1]), string(match[2])
+		if !defined[kind+"."+name] {
+			return fmt.Errorf("%s '%s' is not defined by the template's libraries", kind[:len(kind)-1], name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+var _ = Describe("Libraries", func() {
+	var (
+		standard *v1alpha1.ClusterTemplateLibrary
+		probes   *v1alpha1.ClusterTemplateLibrary
+	)
+
+	partial := func(name, template string) v1alpha1.TemplatePartial {
+		return v1alpha1.TemplatePartial{Name: name, Template: runtime.RawExtension{Raw: []byte(template)}}
+	}
+
+	BeforeEach(func() {
+		standard = &v1alpha1.ClusterTemplateLibrary{
+			ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+			Spec: v1alpha1.TemplateLibrarySpec{
+				Partials: []v1alpha1.TemplatePartial{
+					partial("labels", `{"app.kubernetes.io/part-of": "$(workload.metadata.name)$"}`),
+					partial("securityContext", `{"runAsNonRoot": true}`),
+				},
+				Helpers: []v1alpha1.TemplateHelper{
+					{Name: "fullName", Expression: `value.workload.metadata.name + "-" + value.params.suffix`},
+				},
+			},
+		}
+		probes = &v1alpha1.ClusterTemplateLibrary{
+			ObjectMeta: metav1.ObjectMeta{Name: "probes"},
+			Spec: v1alpha1.TemplateLibrarySpec{
+				Partials: []v1alpha1.TemplatePartial{
+					partial("readiness", `{"httpGet": {"path": "/ready", "port": 8080}}`),
+				},
+			},
+		}
+	})
+
+	Describe("GetLibraries", func() {
+		getLibrary := func(_ context.Context, name string) (*v1alpha1.ClusterTemplateLibrary, error) {
+			if name == "standard" {
+				return standard, nil
+			}
+			return nil, nil
+		}
+
+		It("gets the named libraries", func() {
+			Expect(templates.GetLibraries(context.TODO(), getLibrary, []string{"standard"})).To(Equal([]*v1alpha1.ClusterTemplateLibrary{standard}))
+		})
+
+		It("returns an error when a library does not exist", func() {
+			_, err := templates.GetLibraries(context.TODO(), getLibrary, []string{"standard", "missing"})
+			Expect(err).To(MatchError("template library 'missing' not found"))
+		})
+
+		It("returns an error when a library cannot be got", func() {
+			failing := func(context.Context, string) (*v1alpha1.ClusterTemplateLibrary, error) {
+				return nil, errors.New("no connection")
+			}
+
+			_, err := templates.GetLibraries(context.TODO(), failing, []string{"standard"})
+			Expect(err).To(MatchError("get template library 'standard': no connection"))
+		})
+	})
+
+	Describe("IncludeLibraries", func() {
+		var templatingContext map[string]interface{}
+
+		BeforeEach(func() {
+			templatingContext = map[string]interface{}{
+				"workload": &v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+				"params":   map[string]interface{}{"suffix": "prod"},
+			}
+		})
+
+		It("adds the partials and helpers of every library by name", func() {
+			Expect(templates.IncludeLibraries([]*v1alpha1.ClusterTemplateLibrary{standard, probes}, templatingContext)).To(Succeed())
+
+			Expect(templatingContext["partials"]).To(Equal(map[string]interface{}{
+				"labels":          map[string]interface{}{"app.kubernetes.io/part-of": "$(workload.metadata.name)$"},
+				"securityContext": map[string]interface{}{"runAsNonRoot": true},
+				"readiness": map[string]interface{}{
+					"httpGet": map[string]interface{}{"path": "/ready", "port": float64(8080)},
+				},
+			}))
+			Expect(templatingContext["helpers"]).To(Equal(map[string]interface{}{
+				"fullName": "app-prod",
+			}))
+		})
+
+		It("leaves the context as is without libraries", func() {
+			Expect(templates.IncludeLibraries(nil, templatingContext)).To(Succeed())
+			Expect(templatingContext).NotTo(HaveKey("partials"))
+			Expect(templatingContext).NotTo(HaveKey("helpers"))
+		})
+
+		It("returns an error when two libraries define the same partial", func() {
+			probes.Spec.Partials = append(probes.Spec.Partials, partial("labels", `{}`))
+
+			err := templates.IncludeLibraries([]*v1alpha1.ClusterTemplateLibrary{standard, probes}, templatingContext)
+			Expect(err).To(MatchError("partial 'labels' is defined by template libraries 'standard' and 'probes'"))
+		})
+
+		It("returns an error when two libraries define the same helper", func() {
+			probes.Spec.Helpers = []v1alpha1.TemplateHelper{{Name: "fullName", Expression: `"other"`}}
+
+			err := templates.IncludeLibraries([]*v1alpha1.ClusterTemplateLibrary{standard, probes}, templatingContext)
+			Expect(err).To(MatchError("helper 'fullName' is defined by template libraries 'standard' and 'probes'"))
+		})
+
+		It("returns an error when a helper cannot be evaluated", func() {
+			delete(templatingContext, "params")
+
+			err := templates.IncludeLibraries([]*v1alpha1.ClusterTemplateLibrary{standard}, templatingContext)
+			Expect(err).To(MatchError(ContainSubstring("helper 'fullName' of template library 'standard'")))
+		})
+	})
+
+	Describe("CheckLibraryReferences", func() {
+		It("accepts templates including what their libraries define", func() {
+			raw := []byte(`{"labels": "$(partials.labels)$", "name": "$( helpers.fullName )$", "app": "$(workload.metadata.name)$"}`)
+			Expect(templates.CheckLibraryReferences(raw, []*v1alpha1.ClusterTemplateLibrary{standard})).To(Succeed())
+		})
+
+		It("rejects templates including partials their libraries do not define", func() {
+			raw := []byte(`{"readinessProbe": "$(partials.readiness)$"}`)
+			Expect(templates.CheckLibraryReferences(raw, []*v1alpha1.ClusterTemplateLibrary{standard})).
+				To(MatchError("partial 'readiness' is not defined by the template's libraries"))
+		})
+
+		It("rejects templates including helpers their libraries do not define", func() {
+			raw := []byte(`{"name": "$(helpers.shortName)$"}`)
+			Expect(templates.CheckLibraryReferences(raw, nil)).
+				To(MatchError("helper 'shortName' is not defined by the template's libraries"))
+		})
+	})
+})
@@ -14,6 +14,17 @@
 
 package templates
 
+import (
+	"encoding/json"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/transform"
+)
+
 type Source struct {
 	URL      interface{} `json:"url"`
 	Revision interface{} `json:"revision"`
@@ -24,7 +35,137 @@ type Image interface{}
 type Config interface{}
 
 type Output struct {
-	Source *Source
-	Image  Image
-	Config Config
+	Source *Source `json:"source,omitempty"`
+	Image  Image   `json:"image,omitempty"`
+	Config Config  `json:"config,omitempty"`
+
+	// Stale outputs were read from an earlier object, and are passed on
+	// while the resource's object has not produced new ones.
+	Stale bool `json:"-"`
+
+	// Missing are the optional outputs the object had not populated.
+	Missing []MissingOutput `json:"-"`
+
+	// Sensitive are the names of the outputs the template marks sensitive.
+	Sensitive []string `json:"-"`
+}
+
+// SensitiveValues returns the values of the outputs marked sensitive.
+func (o *Output) SensitiveValues() []interface{} {
+	var values []interface{}
+	for _, name := range o.Sensitive {
+		switch {
+		case name == urlOutput && o.Source != nil:
+			values = append(values, o.Source.URL)
+		case name == revisionOutput && o.Source != nil:
+			values = append(values, o.Source.Revision)
+		case name == imageOutput:
+			values = append(values, o.Image)
+		case name == configOutput:
+			values = append(values, o.Config)
+		}
+	}
+	return values
+}
+
+// NewLastOutput records output as the last output of resource.
+func NewLastOutput(resource string, output *Output) (v1alpha1.LastOutput, error) {
+	raw, err := json.Marshal(output)
+	if err != nil {
+		return v1alpha1.LastOutput{}, fmt.Errorf("marshal output of resource '%s': %w", resource, err)
+	}
+	return v1alpha1.LastOutput{Resource: resource, Output: apiextensionsv1.JSON{Raw: raw}}, nil
+}
+
+// StaleOutput returns the output recorded in last, flagged as stale.
+func StaleOutput(last v1alpha1.LastOutput) (*Output, error) {
+	output := &Output{}
+	if err := json.Unmarshal(last.Output.Raw, output); err != nil {
+		return nil, fmt.Errorf("unmarshal last output of resource '%s': %w", last.Resource, err)
+	}
+	output.Stale = true
+	return output, nil
+}
+
+// MissingOutput is an optional output the stamped object had not
+// populated, and which was output as null.
+type MissingOutput struct {
+	Name string
+	// Expression is the path or CEL expression that found nothing to read.
+	Expression string
+}
+
+// outputLabels name outputs in errors.
+var outputLabels = map[string]string{
+	urlOutput:      "source url",
+	revisionOutput: "source revision",
+	imageOutput:    "image",
+	configOutput:   "config",
+}
+
+// outputReader reads the outputs of a stamped object, recording the
+// optional ones it finds missing.
+type outputReader struct {
+	evaluator     evaluator
+	stampedObject *unstructured.Unstructured
+	optional      []string
+	missing       []MissingOutput
+}
+
+func newOutputReader(evaluator evaluator, stampedObject *unstructured.Unstructured, optional []string) *outputReader {
+	return &outputReader{evaluator: evaluator, stampedObject: stampedObject, optional: optional}
+}
+
+// read reads the named output with expression, a CEL expression over the
+// object, when the template sets one, and with path otherwise. When the
+// object has no value to read, the template's default for the output, if it
+// has one, is returned instead, and otherwise null if the output is
+// optional.
+func (o *outputReader) read(name, path, expression string, fallback *apiextensionsv1.JSON) (interface{}, error) {
+	value, err := o.evaluate(name, path, expression)
+	if err == nil {
+		return value, nil
+	}
+
+	if fallback != nil {
+		var defaultValue interface{}
+		if unmarshalErr := json.Unmarshal(fallback.Raw, &defaultValue); unmarshalErr != nil {
+			return nil, fmt.Errorf("unmarshal %s default: %w", outputLabels[name], unmarshalErr)
+		}
+		return defaultValue, nil
+	}
+
+	for _, optional := range o.optional {
+		if optional == name {
+			read := expression
+			if read == "" {
+				read = path
+			}
+			o.missing = append(o.missing, MissingOutput{Name: name, Expression: read})
+			return nil, nil
+		}
+	}
+	return nil, err
+}
+
+func (o *outputReader) evaluate(name, path, expression string) (interface{}, error) {
+	if expression != "" {
+		value, err := transform.Apply(expression, o.stampedObject.UnstructuredContent())
+		if err != nil {
+			return nil, &OutputExpressionError{
+				Err:        fmt.Errorf("evaluate %s expression: %w", outputLabels[name], err),
+				expression: expression,
+			}
+		}
+		return value, nil
+	}
+
+	value, err := o.evaluator.EvaluateJsonPath(path, o.stampedObject.UnstructuredContent())
+	if err != nil {
+		return nil, &JsonPathError{
+			Err:        fmt.Errorf("evaluate %s json path: %w", outputLabels[name], err),
+			expression: path,
+		}
+	}
+	return value, nil
 }
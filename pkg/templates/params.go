@@ -37,3 +37,19 @@ func ParamsBuilder(defaultParams v1alpha1.DefaultParams, resourceParams []v1alph
 	}
 	return newParams
 }
+
+// SensitiveParams returns the values, in params, of the params the template
+// or the resource marks sensitive.
+func SensitiveParams(defaultParams v1alpha1.DefaultParams, resourceParams []v1alpha1.Param, params Params) []apiextensionsv1.JSON {
+	var values []apiextensionsv1.JSON
+	for _, param := range defaultParams {
+		sensitive := param.Sensitive
+		for _, override := range resourceParams {
+			sensitive = sensitive || (override.Name == param.Name && override.Sensitive)
+		}
+		if sensitive {
+			values = append(values, params[param.Name])
+		}
+	}
+	return values
+}
@@ -55,4 +55,24 @@ var _ = Describe("OverrideDefaultParams", func() {
 			Expect(params["fizz"].Raw).To(Equal([]byte("buzz")))
 		})
 	})
+
+	Describe("SensitiveParams", func() {
+		It("returns the values of the params the template or the resource marks sensitive", func() {
+			defaultParams := v1alpha1.DefaultParams{
+				{Name: "token", DefaultValue: apiextensionsv1.JSON{Raw: []byte(`"none"`)}, Sensitive: true},
+				{Name: "password", DefaultValue: apiextensionsv1.JSON{Raw: []byte(`"none"`)}},
+				{Name: "replicas", DefaultValue: apiextensionsv1.JSON{Raw: []byte(`1`)}},
+			}
+			resourceParams := []v1alpha1.Param{
+				{Name: "token", Value: apiextensionsv1.JSON{Raw: []byte(`"s3cr3t"`)}},
+				{Name: "password", Value: apiextensionsv1.JSON{Raw: []byte(`"p4ssw0rd"`)}, Sensitive: true},
+			}
+			params := templates.ParamsBuilder(defaultParams, resourceParams)
+
+			Expect(templates.SensitiveParams(defaultParams, resourceParams, params)).To(Equal([]apiextensionsv1.JSON{
+				{Raw: []byte(`"s3cr3t"`)},
+				{Raw: []byte(`"p4ssw0rd"`)},
+			}))
+		})
+	})
 })
@@ -0,0 +1,222 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// ConfigPatcher is a template that can output a config input of its
+// resource, patched, in place of stamping an object.
+type ConfigPatcher interface {
+	GetPatches() *v1alpha1.ConfigPatches
+	GetSensitiveOutputs() []string
+}
+
+// PatchConfig returns the config input patches name, patched. A config
+// that is a YAML document is patched as the object it describes, and output
+// as YAML.
+func PatchConfig(stamper Stamper, patches v1alpha1.ConfigPatches, inputs Inputs) (*Output, error) {
+	input, err := patchedInput(patches.Config, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	config, asYAML, err := configObject(input)
+	if err != nil {
+		return nil, err
+	}
+
+	patches, err = interpolatePatches(stamper, patches)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, patch := range patches.StrategicMerge {
+		config, err = strategicMerge(config, patch.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("strategic merge patch %d: %w", i, err)
+		}
+	}
+	if len(patches.JSON6902) > 0 {
+		config, err = applyJSON6902(config, patches.JSON6902)
+		if err != nil {
+			return nil, fmt.Errorf("json6902 patch: %w", err)
+		}
+	}
+
+	output := &Output{Stale: input.Stale}
+	if !asYAML {
+		output.Config = config
+		return output, nil
+	}
+	document, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal patched config: %w", err)
+	}
+	output.Config = string(document)
+	return output, nil
+}
+
+// patchedInput returns the config input named name, or the only one when no
+// name is given.
+func patchedInput(name string, inputs Inputs) (ConfigInput, error) {
+	if name == "" {
+		if len(inputs.Configs) != 1 {
+			return ConfigInput{}, fmt.Errorf("patches must name the config they patch, found %d config inputs", len(inputs.Configs))
+		}
+		for _, input := range inputs.Configs {
+			return input, nil
+		}
+	}
+
+	input, ok := inputs.Configs[name]
+	if !ok {
+		return ConfigInput{}, fmt.Errorf("config input '%s' not found", name)
+	}
+	return input, nil
+}
+
+// configObject returns the object input's config is, or describes as a
+// single YAML document, and whether it was YAML.
+func configObject(input ConfigInput) (map[string]interface{}, bool, error) {
+	switch config := input.Config.(type) {
+	case map[string]interface{}:
+		return config, false, nil
+	case string:
+		var documents [][]byte
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(config)))
+		for {
+			document, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, false, fmt.Errorf("config input '%s' is not YAML: %w", input.Name, err)
+			}
+			if strings.TrimSpace(string(document)) != "" {
+				documents = append(documents, document)
+			}
+		}
+		if len(documents) != 1 {
+			return nil, false, fmt.Errorf("config input '%s' has %d YAML documents, expected one object", input.Name, len(documents))
+		}
+
+		var object map[string]interface{}
+		if err := yaml.Unmarshal(documents[0], &object); err != nil || object == nil {
+			return nil, false, fmt.Errorf("config input '%s' is not a YAML object", input.Name)
+		}
+		return object, true, nil
+	case nil:
+		return nil, false, fmt.Errorf("config input '%s' has no config to patch", input.Name)
+	default:
+		return nil, false, fmt.Errorf("config input '%s' is not an object or YAML document", input.Name)
+	}
+}
+
+// interpolatePatches interpolates the tags in patches, as they are
+// interpolated in templates.
+func interpolatePatches(stamper Stamper, patches v1alpha1.ConfigPatches) (v1alpha1.ConfigPatches, error) {
+	raw, err := json.Marshal(patches)
+	if err != nil {
+		return patches, fmt.Errorf("marshal patches: %w", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return patches, fmt.Errorf("unmarshal patches: %w", err)
+	}
+
+	interpolated, err := stamper.Interpolate(generic)
+	if err != nil {
+		return patches, fmt.Errorf("interpolate patches: %w", err)
+	}
+
+	raw, err = json.Marshal(interpolated)
+	if err != nil {
+		return patches, fmt.Errorf("marshal interpolated patches: %w", err)
+	}
+	result := v1alpha1.ConfigPatches{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return patches, fmt.Errorf("unmarshal interpolated patches: %w", err)
+	}
+	return result, nil
+}
+
+// strategicMerge merges patch into config: as a strategic merge patch when
+// config is of a kind known to client-go, as a JSON merge patch otherwise.
+func strategicMerge(config map[string]interface{}, patch []byte) (map[string]interface{}, error) {
+	apiVersion, _ := config["apiVersion"].(string)
+	kind, _ := config["kind"].(string)
+	if typed, err := scheme.Scheme.New(schema.FromAPIVersionAndKind(apiVersion, kind)); err == nil {
+		var patchMap map[string]interface{}
+		if err := json.Unmarshal(patch, &patchMap); err != nil {
+			return nil, fmt.Errorf("unmarshal patch: %w", err)
+		}
+		return strategicpatch.StrategicMergeMapPatch(config, patchMap, typed)
+	}
+
+	original, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	merged, err := jsonpatch.MergePatch(original, patch)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal patched config: %w", err)
+	}
+	return result, nil
+}
+
+// applyJSON6902 applies operations to config.
+func applyJSON6902(config map[string]interface{}, operations []v1alpha1.JSON6902Operation) (map[string]interface{}, error) {
+	rawOperations, err := json.Marshal(operations)
+	if err != nil {
+		return nil, fmt.Errorf("marshal operations: %w", err)
+	}
+	patch, err := jsonpatch.DecodePatch(rawOperations)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	patched, err := patch.Apply(original)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(patched, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal patched config: %w", err)
+	}
+	return result, nil
+}
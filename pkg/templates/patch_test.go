@@ -0,0 +1,197 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+var _ = Describe("PatchConfig", func() {
+	var (
+		stamper templates.Stamper
+		inputs  templates.Inputs
+		patches v1alpha1.ConfigPatches
+	)
+
+	deployment := func() map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "app"},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "app", "image": "app:v1"},
+							map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		owner := &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "owner-ns"},
+		}
+		stamper = templates.StamperBuilder(owner, map[string]interface{}{
+			"params": map[string]interface{}{"replicas": 3, "env": "prod"},
+		}, templates.Labels{})
+
+		inputs = templates.Inputs{Configs: map[string]templates.ConfigInput{
+			"app": {Name: "app", Config: deployment()},
+		}}
+		patches = v1alpha1.ConfigPatches{}
+	})
+
+	It("merges strategic merge patches into configs of built-in kinds by their patch keys", func() {
+		patches.StrategicMerge = []runtime.RawExtension{{Raw: []byte(`{
+			"spec": {
+				"replicas": "$(params.replicas)$",
+				"template": {"spec": {"containers": [{"name": "app", "image": "app:v2"}]}}
+			}
+		}`)}}
+
+		output, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).NotTo(HaveOccurred())
+
+		config := output.Config.(map[string]interface{})
+		spec := config["spec"].(map[string]interface{})
+		Expect(spec["replicas"]).To(BeNumerically("==", 3))
+		Expect(spec["template"]).To(Equal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:v2"},
+					map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+				},
+			},
+		}))
+	})
+
+	It("merges strategic merge patches into configs of other kinds as JSON merge patches", func() {
+		inputs.Configs["app"] = templates.ConfigInput{Name: "app", Config: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "App",
+			"spec":       map[string]interface{}{"env": "dev", "tier": "web"},
+		}}
+		patches.StrategicMerge = []runtime.RawExtension{{Raw: []byte(`{"spec": {"env": "$(params.env)$", "tier": null}}`)}}
+
+		output, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Config).To(HaveKeyWithValue("spec", map[string]interface{}{"env": "prod"}))
+	})
+
+	It("applies JSON 6902 operations after the strategic merge patches", func() {
+		patches.StrategicMerge = []runtime.RawExtension{{Raw: []byte(`{"metadata": {"labels": {"env": "prod"}}}`)}}
+		patches.JSON6902 = []v1alpha1.JSON6902Operation{
+			{Op: "replace", Path: "/metadata/labels/env", Value: &apiextensionsv1.JSON{Raw: []byte(`"$(params.env)$-eu"`)}},
+			{Op: "remove", Path: "/spec/template/spec/containers/1"},
+		}
+
+		output, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).NotTo(HaveOccurred())
+
+		config := output.Config.(map[string]interface{})
+		Expect(config["metadata"]).To(Equal(map[string]interface{}{
+			"name":   "app",
+			"labels": map[string]interface{}{"env": "prod-eu"},
+		}))
+		Expect(config["spec"].(map[string]interface{})["template"]).To(Equal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:v1"},
+				},
+			},
+		}))
+	})
+
+	It("patches a YAML config as the object it describes, and outputs YAML", func() {
+		inputs.Configs["app"] = templates.ConfigInput{Name: "app", Config: "apiVersion: v1\nkind: ConfigMap\ndata:\n  env: dev\n"}
+		patches.JSON6902 = []v1alpha1.JSON6902Operation{
+			{Op: "replace", Path: "/data/env", Value: &apiextensionsv1.JSON{Raw: []byte(`"prod"`)}},
+		}
+
+		output, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Config).To(Equal("apiVersion: v1\ndata:\n  env: prod\nkind: ConfigMap\n"))
+	})
+
+	It("patches the named config input", func() {
+		inputs.Configs["other"] = templates.ConfigInput{Name: "other", Config: map[string]interface{}{"kind": "Other"}}
+		patches.Config = "other"
+		patches.JSON6902 = []v1alpha1.JSON6902Operation{
+			{Op: "add", Path: "/patched", Value: &apiextensionsv1.JSON{Raw: []byte(`true`)}},
+		}
+
+		output, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Config).To(Equal(map[string]interface{}{"kind": "Other", "patched": true}))
+	})
+
+	It("passes on whether the config input is stale", func() {
+		inputs.Configs["app"] = templates.ConfigInput{Name: "app", Config: deployment(), Stale: true}
+		patches.JSON6902 = []v1alpha1.JSON6902Operation{{Op: "remove", Path: "/spec/replicas"}}
+
+		output, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Stale).To(BeTrue())
+	})
+
+	It("returns an error when the config is not named and there are several", func() {
+		inputs.Configs["other"] = templates.ConfigInput{Name: "other", Config: map[string]interface{}{}}
+
+		_, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).To(MatchError("patches must name the config they patch, found 2 config inputs"))
+	})
+
+	It("returns an error when the named config is not an input", func() {
+		patches.Config = "missing"
+
+		_, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).To(MatchError("config input 'missing' not found"))
+	})
+
+	It("returns an error when a YAML config has several documents", func() {
+		inputs.Configs["app"] = templates.ConfigInput{Name: "app", Config: "kind: One\n---\nkind: Two\n"}
+
+		_, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).To(MatchError("config input 'app' has 2 YAML documents, expected one object"))
+	})
+
+	It("returns an error when the config is not an object", func() {
+		inputs.Configs["app"] = templates.ConfigInput{Name: "app", Config: []interface{}{"a"}}
+
+		_, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).To(MatchError("config input 'app' is not an object or YAML document"))
+	})
+
+	It("returns an error when an operation fails", func() {
+		patches.JSON6902 = []v1alpha1.JSON6902Operation{{Op: "remove", Path: "/spec/missing"}}
+
+		_, err := templates.PatchConfig(stamper, patches, inputs)
+		Expect(err).To(MatchError(ContainSubstring("json6902 patch:")))
+	})
+})
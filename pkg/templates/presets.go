@@ -0,0 +1,118 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// checkPreset returns an error while the stamped object is not ready, as
+// the preset of the template defines it.
+func checkPreset(spec v1alpha1.TemplateSpec, stampedObject *unstructured.Unstructured) error {
+	switch spec.Preset {
+	case v1alpha1.FluxTemplatePreset, v1alpha1.KpackTemplatePreset:
+		return readyForGeneration(stampedObject)
+	case v1alpha1.ArgoCDTemplatePreset:
+		return argoCDReady(stampedObject)
+	case v1alpha1.KnativeTemplatePreset:
+		return knativeReady(stampedObject)
+	default:
+		return nil
+	}
+}
+
+// Paths of what presets read in the status of stamped objects.
+const (
+	observedGenerationPath   = ".status.observedGeneration"
+	readyConditionPath       = `.status.conditions[?(@.type=="Ready")].status`
+	fluxArtifactURLPath      = ".status.artifact.url"
+	fluxArtifactRevisionPath = ".status.artifact.revision"
+	knativeURLPath           = ".status.url"
+	knativeRevisionPath      = ".status.latestReadyRevisionName"
+	kpackLatestImagePath     = ".status.latestImage"
+)
+
+// Outputs of source, image and config templates.
+const (
+	urlOutput      = "url"
+	revisionOutput = "revision"
+	imageOutput    = "image"
+	configOutput   = "config"
+)
+
+// presetPaths are the paths presets read outputs from when a template
+// leaves them out.
+var presetPaths = map[string]map[string]string{
+	v1alpha1.FluxTemplatePreset: {
+		urlOutput:      fluxArtifactURLPath,
+		revisionOutput: fluxArtifactRevisionPath,
+	},
+	v1alpha1.KnativeTemplatePreset: {
+		urlOutput:      knativeURLPath,
+		revisionOutput: knativeRevisionPath,
+	},
+	v1alpha1.KpackTemplatePreset: {
+		imageOutput: kpackLatestImagePath,
+	},
+}
+
+// presetPath returns path, or, when it is left out, the path the preset of
+// the template reads the output from.
+func presetPath(spec v1alpha1.TemplateSpec, path, output string) string {
+	if path != "" {
+		return path
+	}
+	if presetPath, ok := presetPaths[spec.Preset][output]; ok {
+		return presetPath
+	}
+	return path
+}
+
+// readyForGeneration returns an error unless the object's Ready condition
+// is True for its current generation, as Flux, Knative and kpack objects
+// report.
+func readyForGeneration(obj *unstructured.Unstructured) error {
+	observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if found && observed < obj.GetGeneration() {
+		return &JsonPathError{
+			Err:        fmt.Errorf("%s '%s' has not observed generation %d", obj.GetKind(), obj.GetName(), obj.GetGeneration()),
+			expression: observedGenerationPath,
+		}
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		if condition["status"] == "True" {
+			return nil
+		}
+		return &JsonPathError{
+			Err:        fmt.Errorf("%s '%s' is not ready: %v", obj.GetKind(), obj.GetName(), condition["message"]),
+			expression: readyConditionPath,
+		}
+	}
+
+	return &JsonPathError{
+		Err:        fmt.Errorf("%s '%s' has no Ready condition", obj.GetKind(), obj.GetName()),
+		expression: readyConditionPath,
+	}
+}
@@ -15,30 +15,48 @@
 package templates
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 	"os/exec"
-	"path"
-	"runtime"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/valyala/fasttemplate"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
 	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
 	"github.com/vmware-tanzu/cartographer/pkg/eval"
+	"github.com/vmware-tanzu/cartographer/pkg/redact"
+	"github.com/vmware-tanzu/cartographer/pkg/utils"
 )
 
 type Labels map[string]string
 
+// nameHashLength is the length of the hash NameHash returns.
+const nameHashLength = 8
+
+// ownerScheme knows the kinds of the owners objects are stamped for, which
+// owners read by some clients do not carry.
+var ownerScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(v1alpha1.AddToScheme(ownerScheme))
+}
+
 // JsonPathContext is any structure that you intend for jsonpath to treat as it's context.
 // typically any struct with template-specific json structure tags
 type JsonPathContext interface{}
@@ -47,6 +65,11 @@ type Stamper struct {
 	TemplatingContext JsonPathContext
 	Owner             client.Object
 	Labels            Labels
+	// MissingKey is what a tag reading a value that is not there does.
+	// StampAll sets it from the options of the template it stamps.
+	MissingKey string
+
+	plainContext interface{}
 }
 
 func StamperBuilder(owner client.Object, templatingContext JsonPathContext, labels Labels) Stamper {
@@ -90,9 +113,9 @@ func formatExpressionLoop(expressionLoop []string) string {
 func (s *Stamper) recursivelyEvaluateTemplates(jsonValue interface{}, loopDetector loopDetector) (interface{}, error) {
 	switch typedJSONValue := jsonValue.(type) {
 	case string:
-		stamperTagInterpolator := StandardTagInterpolator{
-			Context:   s.TemplatingContext,
-			Evaluator: eval.EvaluatorBuilder(),
+		stamperTagInterpolator, err := s.tagInterpolator()
+		if err != nil {
+			return nil, err
 		}
 		loopDetector, err := loopDetector.checkItem(typedJSONValue)
 		if err != nil {
@@ -133,43 +156,174 @@ func (s *Stamper) recursivelyEvaluateTemplates(jsonValue interface{}, loopDetect
 	}
 }
 
+// tagInterpolator interpolates tags as MissingKey says. With "error" they
+// are evaluated over the templating context as plain JSON values, so that
+// the fields an object leaves unset are missing rather than empty. With
+// "zero" missing keys are evaluated to nothing.
+func (s *Stamper) tagInterpolator() (StandardTagInterpolator, error) {
+	interpolator := StandardTagInterpolator{
+		Context:    s.TemplatingContext,
+		Evaluator:  eval.EvaluatorBuilder(),
+		MissingKey: s.MissingKey,
+	}
+
+	switch s.MissingKey {
+	case v1alpha1.ErrorMissingKey:
+		if s.plainContext == nil {
+			raw, err := json.Marshal(s.TemplatingContext)
+			if err != nil {
+				return interpolator, fmt.Errorf("marshal templating context: %w", err)
+			}
+			if err := json.Unmarshal(raw, &s.plainContext); err != nil {
+				return interpolator, fmt.Errorf("unmarshal templating context: %w", err)
+			}
+		}
+		interpolator.Context = s.plainContext
+	case v1alpha1.ZeroMissingKey:
+		interpolator.Evaluator = eval.LenientEvaluatorBuilder()
+	}
+	return interpolator, nil
+}
+
+// Stamp stamps the object the template describes. A template stamping
+// several objects is stamped as its first.
 func (s *Stamper) Stamp(ctx context.Context, resourceTemplate v1alpha1.TemplateSpec) (*unstructured.Unstructured, error) {
-	var stampedObject *unstructured.Unstructured
+	stampedObjects, err := s.StampAll(ctx, resourceTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return stampedObjects[0], nil
+}
+
+// StampAll stamps every object the template describes: the items of a List
+// template, or each document ytt renders, in order. The first is the
+// object the template's outputs are read from.
+func (s *Stamper) StampAll(ctx context.Context, resourceTemplate v1alpha1.TemplateSpec) ([]*unstructured.Unstructured, error) {
+	s.MissingKey = resourceTemplate.MissingKey()
+	s.plainContext = nil
+
+	var stampedObjects []*unstructured.Unstructured
 	var err error
 	switch {
 	case resourceTemplate.Template != nil:
-		stampedObject, err = s.applyTemplate(resourceTemplate.Template.Raw)
+		stampedObjects, err = s.applyTemplate(resourceTemplate.Template.Raw)
 	case resourceTemplate.Ytt != "":
-		stampedObject, err = s.applyYtt(ctx, resourceTemplate.Ytt)
+		stampedObjects, err = s.applyYtt(ctx, resourceTemplate.Ytt)
 	default:
 		err = fmt.Errorf("unknown resource template type, expected either template or ytt")
 	}
 	if err != nil {
 		return nil, err
 	}
+	if len(stampedObjects) == 0 {
+		return nil, fmt.Errorf("template stamps no objects")
+	}
+	if resourceTemplate.IsJob() && len(stampedObjects) > 1 {
+		return nil, fmt.Errorf("job lifecycle template stamps %d objects, expected a single Job", len(stampedObjects))
+	}
+
+	apiVersion, kind := s.ownerGVK().ToAPIVersionAndKind()
+
+	for _, stampedObject := range stampedObjects {
+		if resourceTemplate.Naming != nil {
+			if err := s.name(stampedObject, *resourceTemplate.Naming); err != nil {
+				return nil, fmt.Errorf("naming: %w", err)
+			}
+		}
+
+		if stampedObject.GetNamespace() == "" {
+			stampedObject.SetNamespace(s.Owner.GetNamespace())
+		}
+
+		stampedObject.SetOwnerReferences([]metav1.OwnerReference{
+			{
+				APIVersion:         apiVersion,
+				Kind:               kind,
+				UID:                s.Owner.GetUID(),
+				Name:               s.Owner.GetName(),
+				BlockOwnerDeletion: pointer.BoolPtr(true),
+				Controller:         pointer.BoolPtr(true),
+			},
+		})
+
+		s.mergeLabels(stampedObject)
+	}
+
+	return stampedObjects, nil
+}
+
+// Interpolate interpolates the tags in value, as they are interpolated in
+// templates.
+func (s *Stamper) Interpolate(value interface{}) (interface{}, error) {
+	s.plainContext = nil
+	return s.recursivelyEvaluateTemplates(value, loopDetector{})
+}
+
+// name names stampedObject as naming says: a generateName, when the object
+// has no name, is prefixed and suffixed in place of the name.
+func (s *Stamper) name(stampedObject *unstructured.Unstructured, naming v1alpha1.NamingStrategy) error {
+	name := stampedObject.GetName()
+	if naming.Name != "" {
+		evaluated, err := s.recursivelyEvaluateTemplates(naming.Name, loopDetector{})
+		if err != nil {
+			return fmt.Errorf("interpolate name: %w", err)
+		}
+		name, _ = evaluated.(string)
+		if name == "" {
+			return fmt.Errorf("name '%s' did not evaluate to a string", naming.Name)
+		}
+		stampedObject.SetGenerateName("")
+	}
+
+	generated := name == "" && stampedObject.GetGenerateName() != ""
+	if generated {
+		name = strings.TrimSuffix(stampedObject.GetGenerateName(), "-")
+	}
 
-	if stampedObject.GetNamespace() == "" {
-		stampedObject.SetNamespace(s.Owner.GetNamespace())
+	var parts []string
+	if naming.Prefix == v1alpha1.OwnerNamePrefix {
+		parts = append(parts, s.Owner.GetName())
+	}
+	if name != "" {
+		parts = append(parts, name)
+	}
+	if naming.Suffix == v1alpha1.HashNameSuffix {
+		parts = append(parts, NameHash(s.Owner))
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("template stamps an object without a name")
 	}
 
-	apiVersion, kind := s.Owner.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
-	stampedObject.SetOwnerReferences([]metav1.OwnerReference{
-		{
-			APIVersion:         apiVersion,
-			Kind:               kind,
-			UID:                s.Owner.GetUID(),
-			Name:               s.Owner.GetName(),
-			BlockOwnerDeletion: pointer.BoolPtr(true),
-			Controller:         pointer.BoolPtr(true),
-		},
-	})
+	if generated {
+		stampedObject.SetGenerateName(strings.Join(parts, "-") + "-")
+	} else {
+		stampedObject.SetName(strings.Join(parts, "-"))
+	}
+	return nil
+}
 
-	s.mergeLabels(stampedObject)
+// ownerGVK returns the group, version and kind of the owner, looked up in
+// ownerScheme when the owner does not carry them.
+func (s *Stamper) ownerGVK() schema.GroupVersionKind {
+	gvk := s.Owner.GetObjectKind().GroupVersionKind()
+	if !gvk.Empty() {
+		return gvk
+	}
+	if gvks, _, err := ownerScheme.ObjectKinds(s.Owner); err == nil && len(gvks) > 0 {
+		return gvks[0]
+	}
+	return gvk
+}
 
-	return stampedObject, nil
+// NameHash is a short hash of the namespace and name of owner, the same for
+// every object stamped for it. It suffixes the names of the objects stamped
+// for resources with hashName set, and by templates naming them with the
+// hash suffix.
+func NameHash(owner metav1.Object) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(owner.GetNamespace()+"/"+owner.GetName())))[:nameHashLength]
 }
 
-func (s *Stamper) applyTemplate(resourceTemplate []byte) (*unstructured.Unstructured, error) {
+func (s *Stamper) applyTemplate(resourceTemplate []byte) ([]*unstructured.Unstructured, error) {
 	var resourceTemplateJSON interface{}
 	err := json.Unmarshal(resourceTemplate, &resourceTemplateJSON)
 	if err != nil {
@@ -185,24 +339,61 @@ func (s *Stamper) applyTemplate(resourceTemplate []byte) (*unstructured.Unstruct
 	if !ok {
 		return nil, fmt.Errorf("stamped resource is not a map[string]interface{}: %+v", stampedObjectJSON)
 	}
-	stampedObject := &unstructured.Unstructured{}
-	stampedObject.SetUnstructuredContent(unstructuredContent)
 
-	return stampedObject, nil
+	return expandList(unstructuredContent)
+}
+
+// expandList returns the items of a stamped List, or the stamped object
+// itself when it is not one.
+func expandList(content map[string]interface{}) ([]*unstructured.Unstructured, error) {
+	if content["apiVersion"] != "v1" || content["kind"] != "List" {
+		return []*unstructured.Unstructured{{Object: content}}, nil
+	}
+
+	items, ok := content["items"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("stamped List has no items")
+	}
+	var stampedObjects []*unstructured.Unstructured
+	for i, item := range items {
+		itemContent, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("stamped List item %d is not an object: %+v", i, item)
+		}
+		stampedObjects = append(stampedObjects, &unstructured.Unstructured{Object: itemContent})
+	}
+	return stampedObjects, nil
+}
+
+// TemplatedTypes returns the apiVersion and kind written in a template for
+// each object it stamps: those of its items, for a List template.
+func TemplatedTypes(raw []byte) ([]metav1.TypeMeta, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.APIVersion != "v1" || typeMeta.Kind != "List" {
+		return []metav1.TypeMeta{typeMeta}, nil
+	}
+
+	list := struct {
+		Items []metav1.TypeMeta `json:"items"`
+	}{}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
 }
 
-func (s *Stamper) applyYtt(ctx context.Context, template string) (*unstructured.Unstructured, error) {
+func (s *Stamper) applyYtt(ctx context.Context, template string) ([]*unstructured.Unstructured, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
 	// limit execution duration to protect against infinite loops or cpu wasting templates
+	parentCtx := ctx
 	ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
 	defer cancel()
 
-	ytt := "ytt"
-	// ko copies the content of the kodata directory into the container at a path referenced by $KO_DATA_PATH
-	if kodata, ok := os.LookupEnv("KO_DATA_PATH"); ok {
-		ytt = path.Join(kodata, fmt.Sprintf("ytt-%s-%s", runtime.GOOS, runtime.GOARCH))
-	}
+	ytt := utils.KoDataBinary("ytt")
 
 	args := []string{"-f", "-"}
 	stdin := bytes.NewReader([]byte(template))
@@ -227,8 +418,13 @@ func (s *Stamper) applyYtt(ctx context.Context, template string) (*unstructured.
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	logger.V(1).Info("ytt call", "args", args, "input", template)
+	redactor := redact.FromContext(ctx)
+	logger.V(1).Info("ytt call", "args", redactor.Strings(args), "input", template)
 	if err := cmd.Run(); err != nil {
+		// the reconcile was cancelled (e.g. controller shutdown), the ytt failure is incidental
+		if parentCtx.Err() != nil {
+			return nil, fmt.Errorf("ytt template render cancelled: %w", parentCtx.Err())
+		}
 		msg := stderr.String()
 		if msg == "" {
 			return nil, fmt.Errorf("unable to apply ytt template: %w", err)
@@ -236,15 +432,37 @@ func (s *Stamper) applyYtt(ctx context.Context, template string) (*unstructured.
 		return nil, fmt.Errorf("unable to apply ytt template: %s", msg)
 	}
 	output := stdout.String()
-	logger.V(1).Info("ytt result", "output", output)
+	logger.V(1).Info("ytt result", "output", redactor.String(output))
+
+	// ytt renders each object of a multi-document template as its own
+	// document
+	var stampedObjects []*unstructured.Unstructured
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(output)))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
 
-	stampedObject := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal([]byte(output), stampedObject); err != nil {
-		// ytt should never return invalid yaml
-		return nil, err
+		var content map[string]interface{}
+		if err := yaml.Unmarshal(document, &content); err != nil {
+			// ytt should never return invalid yaml
+			return nil, err
+		}
+		if len(content) == 0 {
+			continue
+		}
+		documentObjects, err := expandList(content)
+		if err != nil {
+			return nil, err
+		}
+		stampedObjects = append(stampedObjects, documentObjects...)
 	}
 
-	return stampedObject, nil
+	return stampedObjects, nil
 }
 
 func (s *Stamper) mergeLabels(obj *unstructured.Unstructured) {
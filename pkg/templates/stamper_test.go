@@ -16,6 +16,7 @@ package templates_test
 
 import (
 	"context"
+	"errors"
 	"os"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -122,6 +123,179 @@ var _ = Describe("Stamper", func() {
 			})
 		})
 
+		Describe("naming strategy", func() {
+			var (
+				stamper  templates.Stamper
+				template v1alpha1.TemplateSpec
+			)
+
+			BeforeEach(func() {
+				owner := &v1alpha1.Workload{
+					TypeMeta:   metav1.TypeMeta{Kind: "Workload", APIVersion: "carto.run/v1alpha1"},
+					ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "team-a"},
+				}
+				stamper = templates.StamperBuilder(owner, map[string]interface{}{"workload": owner}, templates.Labels{})
+
+				template = v1alpha1.TemplateSpec{
+					Template: &runtime.RawExtension{
+						Raw: []byte(`{"kind": "Silly", "apiVersion": "silly.io/v1", "metadata": {"name": "build"}}`),
+					},
+				}
+			})
+
+			It("keeps the name the template gives the object by default", func() {
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.GetName()).To(Equal("build"))
+			})
+
+			It("replaces the name with the interpolated name", func() {
+				template.Naming = &v1alpha1.NamingStrategy{Name: "$(workload.metadata.name)$-image"}
+
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.GetName()).To(Equal("my-app-image"))
+			})
+
+			It("prefixes the name with the owner's name", func() {
+				template.Naming = &v1alpha1.NamingStrategy{Prefix: v1alpha1.OwnerNamePrefix}
+
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.GetName()).To(Equal("my-app-build"))
+			})
+
+			It("suffixes the name with a hash of the owner", func() {
+				template.Naming = &v1alpha1.NamingStrategy{Suffix: v1alpha1.HashNameSuffix}
+
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.GetName()).To(MatchRegexp(`^build-[0-9a-f]{8}$`))
+
+				again, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(again.GetName()).To(Equal(stamped.GetName()))
+
+				other := templates.StamperBuilder(&v1alpha1.Workload{
+					TypeMeta:   metav1.TypeMeta{Kind: "Workload", APIVersion: "carto.run/v1alpha1"},
+					ObjectMeta: metav1.ObjectMeta{Name: "other-app", Namespace: "team-a"},
+				}, struct{}{}, templates.Labels{})
+				otherStamped, err := other.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(otherStamped.GetName()).NotTo(Equal(stamped.GetName()))
+			})
+
+			It("suffixes the name with the hash resources with hashName set use", func() {
+				template.Naming = &v1alpha1.NamingStrategy{Suffix: v1alpha1.HashNameSuffix}
+				owner := &v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "team-a"}}
+
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.GetName()).To(Equal("build-" + templates.NameHash(owner)))
+			})
+
+			It("names objects and references owners that do not carry their kind", func() {
+				template.Naming = &v1alpha1.NamingStrategy{Suffix: v1alpha1.HashNameSuffix}
+				owner := &v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "team-a", UID: "some-uid"}}
+				withoutKind := templates.StamperBuilder(owner, map[string]interface{}{"workload": owner}, templates.Labels{})
+
+				stamped, err := withoutKind.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				withKind, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.GetName()).To(Equal(withKind.GetName()))
+
+				Expect(stamped.GetOwnerReferences()).To(HaveLen(1))
+				Expect(stamped.GetOwnerReferences()[0].APIVersion).To(Equal("carto.run/v1alpha1"))
+				Expect(stamped.GetOwnerReferences()[0].Kind).To(Equal("Workload"))
+			})
+
+			It("prefixes and suffixes the generateName of a template without a name", func() {
+				template.Template.Raw = []byte(`{"kind": "Silly", "apiVersion": "silly.io/v1", "metadata": {"generateName": "build-"}}`)
+				template.Naming = &v1alpha1.NamingStrategy{Prefix: v1alpha1.OwnerNamePrefix, Suffix: v1alpha1.HashNameSuffix}
+
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.GetName()).To(BeEmpty())
+				Expect(stamped.GetGenerateName()).To(MatchRegexp(`^my-app-build-[0-9a-f]{8}-$`))
+			})
+
+			It("names an object without a name after its owner", func() {
+				template.Template.Raw = []byte(`{"kind": "Silly", "apiVersion": "silly.io/v1"}`)
+				template.Naming = &v1alpha1.NamingStrategy{Prefix: v1alpha1.OwnerNamePrefix}
+
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.GetName()).To(Equal("my-app"))
+			})
+
+			It("returns an error when the name does not interpolate to a string", func() {
+				template.Naming = &v1alpha1.NamingStrategy{Name: "$(workload.metadata.generation)$"}
+
+				_, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).To(MatchError("naming: name '$(workload.metadata.generation)$' did not evaluate to a string"))
+			})
+		})
+
+		Describe("missing keys", func() {
+			var (
+				stamper  templates.Stamper
+				template v1alpha1.TemplateSpec
+			)
+
+			BeforeEach(func() {
+				owner := &v1alpha1.Workload{
+					TypeMeta:   metav1.TypeMeta{Kind: "Workload", APIVersion: "carto.run/v1alpha1"},
+					ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "team-a"},
+				}
+				stamper = templates.StamperBuilder(owner, map[string]interface{}{
+					"workload": owner,
+					"params":   map[string]interface{}{"port": nil},
+				}, templates.Labels{})
+
+				template = v1alpha1.TemplateSpec{
+					Template: &runtime.RawExtension{
+						Raw: []byte(`{"kind": "Silly", "apiVersion": "silly.io/v1", "metadata": {"name": "build"}, "spec": {"account": "sa-$(workload.spec.serviceAccountName)$"}}`),
+					},
+				}
+			})
+
+			It("interpolates unset fields as empty and fails on missing keys by default", func() {
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.Object["spec"]).To(Equal(map[string]interface{}{"account": "sa-"}))
+
+				template.Template.Raw = []byte(`{"kind": "Silly", "spec": {"label": "$(workload.metadata.labels.team)$"}}`)
+				_, err = stamper.Stamp(context.TODO(), template)
+				Expect(err).To(MatchError(ContainSubstring("team is not found")))
+			})
+
+			It("fails on unset fields and null values when missingKey is error", func() {
+				template.TemplateOptions = &v1alpha1.TemplateOptions{MissingKey: v1alpha1.ErrorMissingKey}
+
+				_, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).To(MatchError(ContainSubstring("serviceAccountName is not found")))
+
+				template.Template.Raw = []byte(`{"kind": "Silly", "spec": {"port": "$(params.port)$"}}`)
+				_, err = stamper.Stamp(context.TODO(), template)
+				Expect(err).To(MatchError(ContainSubstring("tag must not point to nil value: params.port")))
+
+				template.Template.Raw = []byte(`{"kind": "Silly", "spec": {"name": "$(workload.metadata.name)$"}}`)
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.Object["spec"]).To(Equal(map[string]interface{}{"name": "my-app"}))
+			})
+
+			It("interpolates missing keys as their zero value when missingKey is zero", func() {
+				template.TemplateOptions = &v1alpha1.TemplateOptions{MissingKey: v1alpha1.ZeroMissingKey}
+				template.Template.Raw = []byte(`{"kind": "Silly", "spec": {"label": "team-$(workload.metadata.labels.team)$", "team": "$(workload.metadata.labels.team)$", "port": "$(params.port)$"}}`)
+
+				stamped, err := stamper.Stamp(context.TODO(), template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stamped.Object["spec"]).To(Equal(map[string]interface{}{"label": "team-", "team": nil, "port": nil}))
+			})
+		})
+
 		DescribeTable("tag evaluation of template",
 			func(tmpl string, subJSON string, expected interface{}, expectedErr string) {
 				template := v1alpha1.TemplateSpec{
@@ -280,5 +454,113 @@ key: ` + tmpl + `
 			Entry(`Invalid ytt`,
 				"#@ data.values.params['sub']", `""`, nil, "/not/a/path/to/ytt", "unable to apply ytt template: fork/exec"),
 		)
+
+		Context("when the context is cancelled before the ytt render completes", func() {
+			It("returns a cancellation error", func() {
+				template := v1alpha1.TemplateSpec{
+					Ytt: `
+apiVersion: v1
+kind: TestResource
+`,
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				stamper := templates.StamperBuilder(&v1.ConfigMap{}, struct{}{}, templates.Labels{})
+				_, err := stamper.Stamp(ctx, template)
+				Expect(err).To(MatchError(ContainSubstring("ytt template render cancelled")))
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("StampAll", func() {
+		var (
+			stamper  templates.Stamper
+			template v1alpha1.TemplateSpec
+		)
+
+		BeforeEach(func() {
+			owner := &v1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					UID:       "1234567890abcdef",
+					Name:      "my-config-map",
+					Namespace: "owner-ns",
+				},
+			}
+			templatingContext := map[string]interface{}{"name": "my-app"}
+			stamper = templates.StamperBuilder(owner, templatingContext, templates.Labels{"carto.run/resource-name": "app"})
+
+			template = v1alpha1.TemplateSpec{
+				Template: &runtime.RawExtension{Raw: []byte(`{
+					"apiVersion": "v1",
+					"kind": "List",
+					"items": [
+						{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "$(name)$"}},
+						{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "$(name)$", "namespace": "other-ns"}}
+					]
+				}`)},
+			}
+		})
+
+		It("stamps each item of a List template in order", func() {
+			stamped, err := stamper.StampAll(context.TODO(), template)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stamped).To(HaveLen(2))
+
+			Expect(stamped[0].GetKind()).To(Equal("Deployment"))
+			Expect(stamped[0].GetName()).To(Equal("my-app"))
+			Expect(stamped[0].GetNamespace()).To(Equal("owner-ns"))
+			Expect(stamped[1].GetKind()).To(Equal("Service"))
+			Expect(stamped[1].GetName()).To(Equal("my-app"))
+			Expect(stamped[1].GetNamespace()).To(Equal("other-ns"))
+
+			for _, obj := range stamped {
+				Expect(obj.GetOwnerReferences()).To(HaveLen(1))
+				Expect(obj.GetOwnerReferences()[0].UID).To(Equal(types.UID("1234567890abcdef")))
+				Expect(obj.GetLabels()).To(HaveKeyWithValue("carto.run/resource-name", "app"))
+			}
+		})
+
+		It("stamps a template that is not a List as a single object", func() {
+			template.Template.Raw = []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "$(name)$"}}`)
+
+			stamped, err := stamper.StampAll(context.TODO(), template)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stamped).To(HaveLen(1))
+			Expect(stamped[0].GetKind()).To(Equal("ConfigMap"))
+		})
+
+		It("stamps the first item of a List template with Stamp", func() {
+			stamped, err := stamper.Stamp(context.TODO(), template)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stamped.GetKind()).To(Equal("Deployment"))
+		})
+
+		It("returns an error when the List has no items", func() {
+			template.Template.Raw = []byte(`{"apiVersion": "v1", "kind": "List", "items": []}`)
+
+			_, err := stamper.StampAll(context.TODO(), template)
+			Expect(err).To(MatchError("template stamps no objects"))
+		})
+
+		It("returns an error when a List item is not an object", func() {
+			template.Template.Raw = []byte(`{"apiVersion": "v1", "kind": "List", "items": ["$(name)$"]}`)
+
+			_, err := stamper.StampAll(context.TODO(), template)
+			Expect(err).To(MatchError("stamped List item 0 is not an object: my-app"))
+		})
+
+		It("returns an error when a job lifecycle template stamps several objects", func() {
+			template.Lifecycle = v1alpha1.JobTemplateLifecycle
+
+			_, err := stamper.StampAll(context.TODO(), template)
+			Expect(err).To(MatchError("job lifecycle template stamps 2 objects, expected a single Job"))
+		})
 	})
 })
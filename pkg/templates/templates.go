@@ -30,6 +30,7 @@ type Template interface {
 	GetOutput(stampedObject *unstructured.Unstructured) (*Output, error)
 	GetName() string
 	GetKind() string
+	GetGeneration() int64
 }
 
 func NewModelFromAPI(template client.Object) (Template, error) {
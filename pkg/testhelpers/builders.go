@@ -0,0 +1,213 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/utils"
+)
+
+// ObjectOption mutates the metadata of an object produced by one of the builders.
+type ObjectOption func(obj client.Object)
+
+func WithLabels(labels map[string]string) ObjectOption {
+	return func(obj client.Object) {
+		merged := obj.GetLabels()
+		if merged == nil {
+			merged = map[string]string{}
+		}
+		for key, value := range labels {
+			merged[key] = value
+		}
+		obj.SetLabels(merged)
+	}
+}
+
+func WithAnnotations(annotations map[string]string) ObjectOption {
+	return func(obj client.Object) {
+		merged := obj.GetAnnotations()
+		if merged == nil {
+			merged = map[string]string{}
+		}
+		for key, value := range annotations {
+			merged[key] = value
+		}
+		obj.SetAnnotations(merged)
+	}
+}
+
+func WithGeneration(generation int64) ObjectOption {
+	return func(obj client.Object) {
+		obj.SetGeneration(generation)
+	}
+}
+
+func objectMeta(name, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+	}
+}
+
+func typeMeta(kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       kind,
+	}
+}
+
+func apply(obj client.Object, opts []ObjectOption) {
+	for _, opt := range opts {
+		opt(obj)
+	}
+}
+
+// TemplateFromObject marshals obj into the raw form used by the template
+// field of the carto template kinds.
+func TemplateFromObject(obj interface{}) (*runtime.RawExtension, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("marshal template: %w", err)
+	}
+	return &runtime.RawExtension{Raw: raw}, nil
+}
+
+// TemplateFromYaml converts a heredoc into the raw form used by the template
+// field of the carto template kinds.
+func TemplateFromYaml(y string) (*runtime.RawExtension, error) {
+	raw, err := yaml.YAMLToJSON([]byte(utils.HereYaml(y)))
+	if err != nil {
+		return nil, fmt.Errorf("yaml to json: %w", err)
+	}
+	return &runtime.RawExtension{Raw: raw}, nil
+}
+
+func NewWorkload(name, namespace string, spec v1alpha1.WorkloadSpec, opts ...ObjectOption) *v1alpha1.Workload {
+	obj := &v1alpha1.Workload{
+		TypeMeta:   typeMeta("Workload"),
+		ObjectMeta: objectMeta(name, namespace),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewDeliverable(name, namespace string, spec v1alpha1.DeliverableSpec, opts ...ObjectOption) *v1alpha1.Deliverable {
+	obj := &v1alpha1.Deliverable{
+		TypeMeta:   typeMeta("Deliverable"),
+		ObjectMeta: objectMeta(name, namespace),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewPipeline(name, namespace string, spec v1alpha1.PipelineSpec, opts ...ObjectOption) *v1alpha1.Pipeline {
+	obj := &v1alpha1.Pipeline{
+		TypeMeta:   typeMeta("Pipeline"),
+		ObjectMeta: objectMeta(name, namespace),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewClusterSupplyChain(name string, spec v1alpha1.SupplyChainSpec, opts ...ObjectOption) *v1alpha1.ClusterSupplyChain {
+	obj := &v1alpha1.ClusterSupplyChain{
+		TypeMeta:   typeMeta("ClusterSupplyChain"),
+		ObjectMeta: objectMeta(name, ""),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewClusterDelivery(name string, spec v1alpha1.ClusterDeliverySpec, opts ...ObjectOption) *v1alpha1.ClusterDelivery {
+	obj := &v1alpha1.ClusterDelivery{
+		TypeMeta:   typeMeta("ClusterDelivery"),
+		ObjectMeta: objectMeta(name, ""),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewClusterSourceTemplate(name string, spec v1alpha1.SourceTemplateSpec, opts ...ObjectOption) *v1alpha1.ClusterSourceTemplate {
+	obj := &v1alpha1.ClusterSourceTemplate{
+		TypeMeta:   typeMeta("ClusterSourceTemplate"),
+		ObjectMeta: objectMeta(name, ""),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewClusterImageTemplate(name string, spec v1alpha1.ImageTemplateSpec, opts ...ObjectOption) *v1alpha1.ClusterImageTemplate {
+	obj := &v1alpha1.ClusterImageTemplate{
+		TypeMeta:   typeMeta("ClusterImageTemplate"),
+		ObjectMeta: objectMeta(name, ""),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewClusterConfigTemplate(name string, spec v1alpha1.ConfigTemplateSpec, opts ...ObjectOption) *v1alpha1.ClusterConfigTemplate {
+	obj := &v1alpha1.ClusterConfigTemplate{
+		TypeMeta:   typeMeta("ClusterConfigTemplate"),
+		ObjectMeta: objectMeta(name, ""),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewClusterDeploymentTemplate(name string, spec v1alpha1.TemplateSpec, opts ...ObjectOption) *v1alpha1.ClusterDeploymentTemplate {
+	obj := &v1alpha1.ClusterDeploymentTemplate{
+		TypeMeta:   typeMeta("ClusterDeploymentTemplate"),
+		ObjectMeta: objectMeta(name, ""),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewClusterTemplate(name string, spec v1alpha1.TemplateSpec, opts ...ObjectOption) *v1alpha1.ClusterTemplate {
+	obj := &v1alpha1.ClusterTemplate{
+		TypeMeta:   typeMeta("ClusterTemplate"),
+		ObjectMeta: objectMeta(name, ""),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
+
+func NewClusterRunTemplate(name string, spec v1alpha1.ClusterRunTemplateSpec, opts ...ObjectOption) *v1alpha1.ClusterRunTemplate {
+	obj := &v1alpha1.ClusterRunTemplate{
+		TypeMeta:   typeMeta("ClusterRunTemplate"),
+		ObjectMeta: objectMeta(name, ""),
+		Spec:       spec,
+	}
+	apply(obj, opts)
+	return obj
+}
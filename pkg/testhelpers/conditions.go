@@ -0,0 +1,93 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testhelpers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/gstruct"
+	"github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Conditions reads status.conditions from any typed or unstructured object.
+func Conditions(obj client.Object) ([]metav1.Condition, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("to unstructured: %w", err)
+	}
+
+	rawConditions, found, err := unstructured.NestedSlice(content, "status", "conditions")
+	if err != nil {
+		return nil, fmt.Errorf("status.conditions: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	conditions := make([]metav1.Condition, len(rawConditions))
+	for i, rawCondition := range rawConditions {
+		rawMap, ok := rawCondition.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("status.conditions[%d] is not an object", i)
+		}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(rawMap, &conditions[i])
+		if err != nil {
+			return nil, fmt.Errorf("status.conditions[%d]: %w", i, err)
+		}
+	}
+
+	return conditions, nil
+}
+
+// ConditionsFunc returns a function for use with gomega's Eventually or
+// Consistently. Each call re-reads obj from the apiserver and returns its conditions.
+func ConditionsFunc(ctx context.Context, c client.Reader, key client.ObjectKey, obj client.Object) func() ([]metav1.Condition, error) {
+	return func() ([]metav1.Condition, error) {
+		if err := c.Get(ctx, key, obj); err != nil {
+			return nil, fmt.Errorf("get: %w", err)
+		}
+		return Conditions(obj)
+	}
+}
+
+// EventuallyConditions is shorthand for Eventually(ConditionsFunc(...), intervals...).
+func EventuallyConditions(ctx context.Context, c client.Reader, key client.ObjectKey, obj client.Object, intervals ...interface{}) gomega.AsyncAssertion {
+	return gomega.Eventually(ConditionsFunc(ctx, c, key, obj), intervals...)
+}
+
+// HaveCondition succeeds when a slice of conditions contains one with the given
+// type and status. When reason is not empty it must match as well.
+func HaveCondition(conditionType string, status metav1.ConditionStatus, reason string) types.GomegaMatcher {
+	fields := gstruct.Fields{
+		"Type":   gomega.Equal(conditionType),
+		"Status": gomega.Equal(status),
+	}
+	if reason != "" {
+		fields["Reason"] = gomega.Equal(reason)
+	}
+
+	return gomega.ContainElement(gstruct.MatchFields(gstruct.IgnoreExtras, fields))
+}
+
+// BeReady succeeds when a slice of conditions contains a True Ready condition.
+func BeReady() types.GomegaMatcher {
+	return HaveCondition("Ready", metav1.ConditionTrue, "")
+}
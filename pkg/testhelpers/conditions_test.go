@@ -0,0 +1,62 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testhelpers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/testhelpers"
+)
+
+var _ = Describe("Conditions", func() {
+	It("reads conditions from typed objects", func() {
+		workload := testhelpers.NewWorkload("my-workload", "my-ns", v1alpha1.WorkloadSpec{})
+		workload.Status.Conditions = []metav1.Condition{
+			{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"},
+			{Type: "SupplyChainReady", Status: metav1.ConditionFalse, Reason: "SupplyChainNotFound"},
+		}
+
+		conditions, err := testhelpers.Conditions(workload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conditions).To(testhelpers.BeReady())
+		Expect(conditions).To(testhelpers.HaveCondition("SupplyChainReady", metav1.ConditionFalse, "SupplyChainNotFound"))
+		Expect(conditions).NotTo(testhelpers.HaveCondition("SupplyChainReady", metav1.ConditionFalse, "SupplyChainNotReady"))
+	})
+
+	It("reads conditions from unstructured objects", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "Unknown", "reason": "Unknown"},
+				},
+			},
+		}}
+
+		conditions, err := testhelpers.Conditions(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conditions).To(testhelpers.HaveCondition("Ready", metav1.ConditionUnknown, ""))
+		Expect(conditions).NotTo(testhelpers.BeReady())
+	})
+
+	It("returns no conditions when the object has no status", func() {
+		conditions, err := testhelpers.Conditions(&unstructured.Unstructured{Object: map[string]interface{}{}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conditions).To(BeEmpty())
+	})
+})
@@ -0,0 +1,101 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testhelpers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/vmware-tanzu/cartographer/pkg/utils"
+)
+
+// HereYamlDocuments behaves like utils.HereYaml but splits the result on
+// document separators, dropping any empty documents.
+func HereYamlDocuments(y string) ([]string, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(utils.HereYaml(y))))
+
+	var documents []string
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read yaml document: %w", err)
+		}
+
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		documents = append(documents, string(document))
+	}
+}
+
+// UnstructuredFromYaml parses every document of a (possibly multi-document)
+// heredoc into an unstructured object. Documents containing only comments
+// or whitespace are skipped.
+func UnstructuredFromYaml(y string) ([]*unstructured.Unstructured, error) {
+	documents, err := HereYamlDocuments(y)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*unstructured.Unstructured
+	for i, document := range documents {
+		content := map[string]interface{}{}
+		if err := utilyaml.Unmarshal([]byte(document), &content); err != nil {
+			return nil, fmt.Errorf("unmarshal document %d: %w", i, err)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: content})
+	}
+
+	return objects, nil
+}
+
+// ObjectFromYaml parses a single-document heredoc into obj, which may be any
+// typed object registered with a scheme or an *unstructured.Unstructured.
+func ObjectFromYaml(y string, obj runtime.Object) error {
+	objects, err := UnstructuredFromYaml(y)
+	if err != nil {
+		return err
+	}
+
+	if len(objects) != 1 {
+		return fmt.Errorf("expected exactly one yaml document, found %d", len(objects))
+	}
+
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.Object = objects[0].Object
+		return nil
+	}
+
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(objects[0].Object, obj)
+	if err != nil {
+		return fmt.Errorf("from unstructured: %w", err)
+	}
+
+	return nil
+}
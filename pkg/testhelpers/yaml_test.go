@@ -0,0 +1,88 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testhelpers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/testhelpers"
+)
+
+var _ = Describe("Yaml", func() {
+	Describe("UnstructuredFromYaml", func() {
+		It("returns one object per non-empty document", func() {
+			objects, err := testhelpers.UnstructuredFromYaml(`
+				---
+				apiVersion: v1
+				kind: ConfigMap
+				metadata:
+				  name: first
+				---
+				# only a comment
+				---
+				apiVersion: v1
+				kind: Secret
+				metadata:
+				  name: second
+			`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objects).To(HaveLen(2))
+			Expect(objects[0].GetKind()).To(Equal("ConfigMap"))
+			Expect(objects[0].GetName()).To(Equal("first"))
+			Expect(objects[1].GetKind()).To(Equal("Secret"))
+			Expect(objects[1].GetName()).To(Equal("second"))
+		})
+
+		It("returns a helpful error for malformed yaml", func() {
+			_, err := testhelpers.UnstructuredFromYaml(`
+				---
+				apiVersion: [v1
+			`)
+			Expect(err).To(MatchError(ContainSubstring("unmarshal document 0")))
+		})
+	})
+
+	Describe("ObjectFromYaml", func() {
+		It("populates a typed object", func() {
+			workload := &v1alpha1.Workload{}
+			err := testhelpers.ObjectFromYaml(`
+				apiVersion: carto.run/v1alpha1
+				kind: Workload
+				metadata:
+				  name: my-workload
+				  labels:
+				    app: web
+				spec:
+				  image: some/image
+			`, workload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workload.Name).To(Equal("my-workload"))
+			Expect(workload.Labels).To(Equal(map[string]string{"app": "web"}))
+			Expect(*workload.Spec.Image).To(Equal("some/image"))
+		})
+
+		It("rejects multiple documents", func() {
+			err := testhelpers.ObjectFromYaml(`
+				---
+				kind: A
+				---
+				kind: B
+			`, &v1alpha1.Workload{})
+			Expect(err).To(MatchError("expected exactly one yaml document, found 2"))
+		})
+	})
+})
@@ -0,0 +1,292 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package environment runs Cartographer against a local API server for
+// integration tests of supply chains, deliveries and templates. It starts
+// etcd and kube-apiserver with envtest, installs Cartographer's CRDs and
+// webhooks and runs the controller in process:
+//
+//	env, err := environment.Start(environment.Options{
+//		ConfigDir: "path/to/cartographer/config",
+//		Output:    GinkgoWriter,
+//	})
+//	Expect(err).NotTo(HaveOccurred())
+//	defer env.Stop()
+//
+//	namespace, err := env.CreateNamespace(ctx, "test-")
+//
+// envtest needs the etcd and kube-apiserver binaries, found through the
+// KUBEBUILDER_ASSETS environment variable. The fakes of the realizers'
+// interfaces, generated by counterfeiter, are in the workloadfakes,
+// deliverablefakes and pipelinefakes packages next to them.
+package environment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega/gbytes"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage/names"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/root"
+)
+
+const defaultStartTimeout = 10 * time.Second
+
+// Options configure an Environment.
+type Options struct {
+	// ConfigDir is Cartographer's config directory, holding the crd/bases
+	// and webhook directories.
+	ConfigDir string
+
+	// CRDDirectoryPaths are installed along with Cartographer's CRDs, for
+	// instance those of the kinds templates stamp.
+	CRDDirectoryPaths []string
+
+	// AddToScheme registers kinds with the Client beyond Cartographer's and
+	// the Kubernetes built-in ones.
+	AddToScheme []func(*runtime.Scheme) error
+
+	// Output receives the controller's log, which Environment.Log also
+	// holds.
+	Output io.Writer
+
+	// AttachControlPlaneOutput forwards the output of etcd and
+	// kube-apiserver to os.Stdout and os.Stderr.
+	AttachControlPlaneOutput bool
+
+	// ConfigureEnvironment, when set, is called before the API server is
+	// started, to configure it further.
+	ConfigureEnvironment func(*envtest.Environment)
+
+	// ConfigureCommand, when set, is called before the controller is run,
+	// to set options such as a policy file.
+	ConfigureCommand func(*root.Command)
+
+	// StartTimeout is how long the controller is given to start serving.
+	// Defaults to 10 seconds.
+	StartTimeout time.Duration
+}
+
+// Environment is an API server with Cartographer running against it.
+type Environment struct {
+	Env    *envtest.Environment
+	Config *rest.Config
+	Client client.Client
+
+	// Log holds the controller's log.
+	Log *gbytes.Buffer
+
+	// KubeconfigFile is a kubeconfig of a cluster administrator, which
+	// KUBECONFIG is set to.
+	KubeconfigFile string
+
+	cancel context.CancelFunc
+}
+
+// Start starts the API server, installs Cartographer and runs its
+// controller, returning once the controller serves its webhooks and runs
+// its reconcilers.
+func Start(opts Options) (*Environment, error) {
+	env := &Environment{
+		Env: &envtest.Environment{
+			WebhookInstallOptions: envtest.WebhookInstallOptions{
+				Paths: []string{filepath.Join(opts.ConfigDir, "webhook")},
+			},
+			CRDDirectoryPaths:        append([]string{filepath.Join(opts.ConfigDir, "crd", "bases")}, opts.CRDDirectoryPaths...),
+			AttachControlPlaneOutput: opts.AttachControlPlaneOutput,
+		},
+		Log: gbytes.NewBuffer(),
+	}
+	if opts.ConfigureEnvironment != nil {
+		opts.ConfigureEnvironment(env.Env)
+	}
+
+	var err error
+	env.Config, err = env.Env.Start()
+	if err != nil {
+		return nil, fmt.Errorf("start envtest: %w", err)
+	}
+
+	if err := env.start(opts); err != nil {
+		_ = env.Stop()
+		return nil, err
+	}
+	return env, nil
+}
+
+func (e *Environment) start(opts Options) error {
+	var err error
+	e.KubeconfigFile, err = generateKubeconfig(e.Env)
+	if err != nil {
+		return err
+	}
+	// The controller reads its configuration as it would in a cluster.
+	if err := os.Setenv("KUBECONFIG", e.KubeconfigFile); err != nil {
+		return fmt.Errorf("set KUBECONFIG: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	addToScheme := append([]func(*runtime.Scheme) error{clientgoscheme.AddToScheme, v1alpha1.AddToScheme}, opts.AddToScheme...)
+	for _, add := range addToScheme {
+		if err := add(scheme); err != nil {
+			return fmt.Errorf("add to scheme: %w", err)
+		}
+	}
+	e.Client, err = client.New(e.Config, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("new client: %w", err)
+	}
+
+	output := io.Writer(e.Log)
+	if opts.Output != nil {
+		output = io.MultiWriter(e.Log, opts.Output)
+	}
+
+	var ctx context.Context
+	ctx, e.cancel = context.WithCancel(context.Background())
+	command := &root.Command{
+		Port:    e.Env.WebhookInstallOptions.LocalServingPort,
+		CertDir: e.Env.WebhookInstallOptions.LocalServingCertDir,
+		Context: ctx,
+		Logger:  zap.New(zap.WriteTo(output)),
+	}
+	if opts.ConfigureCommand != nil {
+		opts.ConfigureCommand(command)
+	}
+
+	controllerErr := make(chan error, 1)
+	go func() {
+		controllerErr <- command.Execute()
+	}()
+
+	timeout := opts.StartTimeout
+	if timeout == 0 {
+		timeout = defaultStartTimeout
+	}
+	return waitForController(e.Log, controllerErr, timeout)
+}
+
+// waitForController waits for the controller to log that it serves its
+// webhooks and has started its reconcilers.
+func waitForController(log *gbytes.Buffer, controllerErr <-chan error, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		contents := string(log.Contents())
+		if strings.Contains(contents, "serving webhook server") && strings.Contains(contents, "Starting Controller") {
+			// The webhook server starts listening just after it logs.
+			time.Sleep(200 * time.Millisecond)
+			return nil
+		}
+
+		select {
+		case err := <-controllerErr:
+			return fmt.Errorf("controller exited: %w", err)
+		case <-deadline:
+			return fmt.Errorf("controller did not start within %s", timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops the controller and the API server.
+func (e *Environment) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	if e.KubeconfigFile != "" {
+		_ = os.Remove(e.KubeconfigFile)
+	}
+	if err := e.Env.Stop(); err != nil {
+		return fmt.Errorf("stop envtest: %w", err)
+	}
+	return nil
+}
+
+// CreateNamespace creates a namespace named prefix followed by a random
+// suffix, and returns its name.
+func (e *Environment) CreateNamespace(ctx context.Context, prefix string) (string, error) {
+	name := names.SimpleNameGenerator.GenerateName(prefix)
+	if err := EnsureNamespace(ctx, e.Client, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// DeleteNamespace deletes the namespace name. envtest runs no namespace
+// controller, so the objects in it are not deleted.
+func (e *Environment) DeleteNamespace(ctx context.Context, name string) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if err := e.Client.Delete(ctx, ns); err != nil {
+		return fmt.Errorf("delete namespace '%s': %w", name, err)
+	}
+	return nil
+}
+
+// EnsureNamespace creates the namespace name unless it exists.
+func EnsureNamespace(ctx context.Context, c client.Client, name string) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if err := c.Create(ctx, ns); err != nil && !kerrors.IsAlreadyExists(err) {
+		return fmt.Errorf("create namespace '%s': %w", name, err)
+	}
+	return nil
+}
+
+// generateKubeconfig writes a kubeconfig of a cluster administrator to a
+// temporary file, and returns its path.
+func generateKubeconfig(env *envtest.Environment) (string, error) {
+	user, err := env.ControlPlane.AddUser(envtest.User{
+		Name:   "envtest-admin",
+		Groups: []string{"system:masters"},
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("add user: %w", err)
+	}
+
+	kubeconfigFile, err := ioutil.TempFile("", "cartographer-integration-test-kubeconfig-")
+	if err != nil {
+		return "", fmt.Errorf("tempfile: %w", err)
+	}
+	defer kubeconfigFile.Close()
+
+	kubeConfig, err := user.KubeConfig()
+	if err != nil {
+		return "", fmt.Errorf("kubeconfig: %w", err)
+	}
+
+	if _, err := kubeconfigFile.Write(kubeConfig); err != nil {
+		return "", fmt.Errorf("write kubeconfig: %w", err)
+	}
+
+	return kubeconfigFile.Name(), nil
+}
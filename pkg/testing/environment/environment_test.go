@@ -0,0 +1,47 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/cartographer/pkg/testing/environment"
+)
+
+var _ = Describe("EnsureNamespace", func() {
+	var c client.Client
+
+	BeforeEach(func() {
+		c = fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	})
+
+	It("creates the namespace", func() {
+		Expect(environment.EnsureNamespace(context.Background(), c, "some-namespace")).To(Succeed())
+		Expect(c.Get(context.Background(), types.NamespacedName{Name: "some-namespace"}, &corev1.Namespace{})).To(Succeed())
+	})
+
+	It("succeeds when the namespace exists", func() {
+		Expect(environment.EnsureNamespace(context.Background(), c, "some-namespace")).To(Succeed())
+		Expect(environment.EnsureNamespace(context.Background(), c, "some-namespace")).To(Succeed())
+	})
+})
@@ -0,0 +1,156 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware-tanzu/cartographer/pkg/render"
+)
+
+// LoadTemplate reads the one template in the YAML file at path.
+func LoadTemplate(path string) (client.Object, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	manifests := &render.Manifests{}
+	if err := manifests.Load(f); err != nil {
+		return nil, fmt.Errorf("load %s: %w", path, err)
+	}
+	if len(manifests.Templates) != 1 {
+		return nil, fmt.Errorf("load %s: found %d templates, expected 1", path, len(manifests.Templates))
+	}
+	return manifests.Templates[0], nil
+}
+
+// ContainFields succeeds when the actual object, an *unstructured.Unstructured
+// or map, has every field of the expected YAML with the same value. Maps may
+// have fields the expected YAML does not; lists must match in full.
+func ContainFields(expected string) types.GomegaMatcher {
+	return &containFieldsMatcher{expected: expected}
+}
+
+type containFieldsMatcher struct {
+	expected string
+	mismatch string
+}
+
+func (m *containFieldsMatcher) Match(actual interface{}) (bool, error) {
+	var expected map[string]interface{}
+	if err := yaml.Unmarshal([]byte(m.expected), &expected); err != nil {
+		return false, fmt.Errorf("parse expected fields: %w", err)
+	}
+
+	var object map[string]interface{}
+	switch typed := actual.(type) {
+	case *unstructured.Unstructured:
+		if typed == nil {
+			return false, fmt.Errorf("expected an object, got nil")
+		}
+		object = typed.Object
+	case map[string]interface{}:
+		object = typed
+	default:
+		return false, fmt.Errorf("ContainFields expects an *unstructured.Unstructured or map[string]interface{}, got %T", actual)
+	}
+
+	m.mismatch = containsFields(".", object, expected)
+	return m.mismatch == "", nil
+}
+
+func (m *containFieldsMatcher) FailureMessage(actual interface{}) string {
+	return format.Message(actual, fmt.Sprintf("to contain fields\n%s\n%s", format.IndentString(m.expected, 1), m.mismatch))
+}
+
+func (m *containFieldsMatcher) NegatedFailureMessage(actual interface{}) string {
+	return format.Message(actual, fmt.Sprintf("not to contain fields\n%s", format.IndentString(m.expected, 1)))
+}
+
+// containsFields describes the first field of expected that actual does not
+// have, or returns "" when it has them all.
+func containsFields(path string, actual, expected interface{}) string {
+	expectedMap, ok := expected.(map[string]interface{})
+	if !ok {
+		if !reflect.DeepEqual(normalize(actual), normalize(expected)) {
+			return fmt.Sprintf("%s is %v, expected %v", path, actual, expected)
+		}
+		return ""
+	}
+
+	actualMap, ok := actual.(map[string]interface{})
+	if !ok {
+		return fmt.Sprintf("%s is %v, expected a map", path, actual)
+	}
+
+	keys := make([]string, 0, len(expectedMap))
+	for key := range expectedMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := path + key
+		if path != "." {
+			fieldPath = path + "." + key
+		}
+		value, ok := actualMap[key]
+		if !ok {
+			return fmt.Sprintf("%s is missing", fieldPath)
+		}
+		if mismatch := containsFields(fieldPath, value, expectedMap[key]); mismatch != "" {
+			return mismatch
+		}
+	}
+	return ""
+}
+
+// normalize converts numbers to float64, as YAML decodes them, so that the
+// int64 values of unstructured objects compare equal.
+func normalize(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed)
+	case int32:
+		return float64(typed)
+	case int64:
+		return float64(typed)
+	case []interface{}:
+		normalized := make([]interface{}, len(typed))
+		for i, item := range typed {
+			normalized[i] = normalize(item)
+		}
+		return normalized
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			normalized[key] = normalize(item)
+		}
+		return normalized
+	default:
+		return value
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package templates helps blueprint authors test their templates. It stamps
+// a template for a workload, or deliverable, and the inputs of earlier
+// resources the way a supply chain, or delivery, resource would, then reads
+// the template's outputs from the stamped object:
+//
+//	result, err := templates.Stamp(ctx, templates.Test{
+//		Template: template,
+//		Workload: workload,
+//		Sources:  map[string]cartotemplates.Source{"source": {URL: "https://example.com/app.tar.gz"}},
+//		Status:   map[string]interface{}{"latestImage": "example.com/app@sha256:abc"},
+//	})
+//	Expect(err).NotTo(HaveOccurred())
+//	Expect(result.Object).To(templates.ContainFields(`spec: {tag: example.com/app}`))
+//	Expect(result.Output.Image).To(Equal("example.com/app@sha256:abc"))
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/clusterdata"
+	deliverablerealizer "github.com/vmware-tanzu/cartographer/pkg/realizer/deliverable"
+	workloadrealizer "github.com/vmware-tanzu/cartographer/pkg/realizer/workload"
+	"github.com/vmware-tanzu/cartographer/pkg/render"
+	"github.com/vmware-tanzu/cartographer/pkg/repository"
+	cartotemplates "github.com/vmware-tanzu/cartographer/pkg/templates"
+)
+
+// Test is a template and what it is stamped with.
+type Test struct {
+	// Template is a ClusterSourceTemplate, ClusterImageTemplate,
+	// ClusterConfigTemplate, ClusterDeploymentTemplate or ClusterTemplate.
+	Template client.Object
+
+	// Workload, or Deliverable, the template is stamped for. Exactly one
+	// must be set.
+	Workload    *v1alpha1.Workload
+	Deliverable *v1alpha1.Deliverable
+
+	// Resource is the name of the blueprint resource referencing the
+	// template. Defaults to the template's name.
+	Resource string
+	// Params set on the blueprint resource.
+	Params []v1alpha1.Param
+
+	// Sources, Images and Configs are the outputs of earlier resources, by
+	// the name the template refers to them with. Deliveries have no images.
+	Sources map[string]cartotemplates.Source
+	Images  map[string]cartotemplates.Image
+	Configs map[string]cartotemplates.Config
+
+	// Libraries the template includes partials from.
+	Libraries []*v1alpha1.ClusterTemplateLibrary
+
+	// Cluster is the data about the cluster the template reads as
+	// $(cluster)$, such as {"domain": "cluster.local"}.
+	Cluster map[string]interface{}
+
+	// Status is set on the stamped object, as the controller reconciling it
+	// would, before the template's outputs are read from it.
+	Status map[string]interface{}
+}
+
+// Result is the object a template stamped and the outputs read from it.
+type Result struct {
+	Object *unstructured.Unstructured
+
+	// Output of the template, or OutputErr when the stamped object does not
+	// have the values the template reads its outputs from. Outputs of job
+	// lifecycle templates are not read.
+	Output    *cartotemplates.Output
+	OutputErr error
+}
+
+// Stamp stamps t.Template. It returns an error when the template cannot be
+// stamped, and in the Result when its outputs cannot be read.
+func Stamp(ctx context.Context, t Test) (*Result, error) {
+	if (t.Workload == nil) == (t.Deliverable == nil) {
+		return nil, fmt.Errorf("exactly one of workload or deliverable must be set")
+	}
+
+	template, err := cartotemplates.NewModelFromAPI(t.Template)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := render.NewOfflineRepository([]client.Object{t.Template})
+	if err != nil {
+		return nil, err
+	}
+	repo.Libraries = t.Libraries
+	repo.Status = t.Status
+	serviceAccountRepo := func(string, string) (repository.Repository, error) { return repo, nil }
+
+	if t.Cluster != nil {
+		ctx = clusterdata.NewContext(ctx, t.Cluster)
+	}
+
+	resourceName := t.Resource
+	if resourceName == "" {
+		resourceName = template.GetName()
+	}
+
+	if t.Workload != nil {
+		workload := t.Workload.DeepCopy()
+		if workload.Namespace == "" {
+			workload.Namespace = "default"
+		}
+		resource := &v1alpha1.SupplyChainResource{
+			Name:        resourceName,
+			TemplateRef: v1alpha1.ClusterTemplateReference{Kind: template.GetKind(), Name: template.GetName()},
+			Params:      t.Params,
+		}
+		outputs := workloadrealizer.NewOutputs()
+		for name, source := range t.Sources {
+			source := source
+			resource.Sources = append(resource.Sources, v1alpha1.ResourceReference{Name: name, Resource: name})
+			outputs.AddOutput(name, &cartotemplates.Output{Source: &source})
+		}
+		for name, image := range t.Images {
+			resource.Images = append(resource.Images, v1alpha1.ResourceReference{Name: name, Resource: name})
+			outputs.AddOutput(name, &cartotemplates.Output{Image: image})
+		}
+		for name, config := range t.Configs {
+			resource.Configs = append(resource.Configs, v1alpha1.ResourceReference{Name: name, Resource: name})
+			outputs.AddOutput(name, &cartotemplates.Output{Config: config})
+		}
+
+		output, err := workloadrealizer.NewResourceRealizer(workload, repo, serviceAccountRepo).Do(ctx, resource, "test", outputs)
+		if errors.As(err, &workloadrealizer.RetrieveOutputError{}) || errors.As(err, &workloadrealizer.JobRunningError{}) {
+			return &Result{Object: lastStamped(repo), OutputErr: err}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Object: lastStamped(repo), Output: output}, nil
+	}
+
+	if len(t.Images) > 0 {
+		return nil, fmt.Errorf("deliveries have no images")
+	}
+	deliverable := t.Deliverable.DeepCopy()
+	if deliverable.Namespace == "" {
+		deliverable.Namespace = "default"
+	}
+	resource := &v1alpha1.ClusterDeliveryResource{
+		Name:        resourceName,
+		TemplateRef: v1alpha1.DeliveryClusterTemplateReference{Kind: template.GetKind(), Name: template.GetName()},
+		Params:      t.Params,
+	}
+	outputs := deliverablerealizer.NewOutputs()
+	for name, source := range t.Sources {
+		source := source
+		resource.Sources = append(resource.Sources, v1alpha1.ResourceReference{Name: name, Resource: name})
+		outputs.AddOutput(name, &cartotemplates.Output{Source: &source})
+	}
+	for name, config := range t.Configs {
+		resource.Configs = append(resource.Configs, v1alpha1.ResourceReference{Name: name, Resource: name})
+		outputs.AddOutput(name, &cartotemplates.Output{Config: config})
+	}
+
+	output, err := deliverablerealizer.NewResourceRealizer(deliverable, repo, serviceAccountRepo).Do(ctx, resource, "test", outputs)
+	if errors.As(err, &deliverablerealizer.RetrieveOutputError{}) || errors.As(err, &deliverablerealizer.JobRunningError{}) {
+		return &Result{Object: lastStamped(repo), OutputErr: err}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Object: lastStamped(repo), Output: output}, nil
+}
+
+// lastStamped returns the object the template under test stamped, if any.
+func lastStamped(repo *render.OfflineRepository) *unstructured.Unstructured {
+	stamped := repo.Stamped()
+	if len(stamped) == 0 {
+		return nil
+	}
+	return stamped[len(stamped)-1]
+}
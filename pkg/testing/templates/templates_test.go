@@ -0,0 +1,204 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	cartotemplates "github.com/vmware-tanzu/cartographer/pkg/templates"
+	"github.com/vmware-tanzu/cartographer/pkg/testing/templates"
+)
+
+var _ = Describe("Stamp", func() {
+	var (
+		ctx           context.Context
+		imageTemplate *v1alpha1.ClusterImageTemplate
+		workload      *v1alpha1.Workload
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		imageTemplate = &v1alpha1.ClusterImageTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "kpack"},
+			Spec: v1alpha1.ImageTemplateSpec{
+				TemplateSpec: v1alpha1.TemplateSpec{
+					Template: &runtime.RawExtension{Raw: []byte(`{
+						"apiVersion": "kpack.io/v1alpha2",
+						"kind": "Image",
+						"metadata": {"name": "$(workload.metadata.name)$"},
+						"spec": {
+							"tag": "$(params.registry)$/$(workload.metadata.name)$",
+							"source": {"blob": {"url": "$(source.url)$"}}
+						}
+					}`)},
+					Params: v1alpha1.DefaultParams{
+						{Name: "registry", DefaultValue: apiextensionsv1.JSON{Raw: []byte(`"registry.example.com"`)}},
+					},
+				},
+				ImagePath: ".status.latestImage",
+			},
+		}
+		workload = &v1alpha1.Workload{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dev"},
+		}
+	})
+
+	It("stamps the template for the workload with its inputs and params", func() {
+		result, err := templates.Stamp(ctx, templates.Test{
+			Template: imageTemplate,
+			Workload: workload,
+			Sources:  map[string]cartotemplates.Source{"source": {URL: "https://example.com/app.tar.gz"}},
+			Params:   []v1alpha1.Param{{Name: "registry", Value: apiextensionsv1.JSON{Raw: []byte(`"ghcr.io/example"`)}}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Object).To(templates.ContainFields(`
+kind: Image
+metadata:
+  name: app
+  namespace: dev
+  labels:
+    carto.run/resource-name: kpack
+spec:
+  tag: ghcr.io/example/app
+  source:
+    blob:
+      url: https://example.com/app.tar.gz
+`))
+	})
+
+	It("reads the template's outputs from the status given", func() {
+		result, err := templates.Stamp(ctx, templates.Test{
+			Template: imageTemplate,
+			Workload: workload,
+			Sources:  map[string]cartotemplates.Source{"source": {URL: "https://example.com/app.tar.gz"}},
+			Status:   map[string]interface{}{"latestImage": "ghcr.io/example/app@sha256:abc"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.OutputErr).NotTo(HaveOccurred())
+		Expect(result.Output.Image).To(Equal("ghcr.io/example/app@sha256:abc"))
+		Expect(result.Object.Object).NotTo(HaveKey("status"))
+	})
+
+	It("returns the output error when the status does not hold the outputs", func() {
+		result, err := templates.Stamp(ctx, templates.Test{
+			Template: imageTemplate,
+			Workload: workload,
+			Sources:  map[string]cartotemplates.Source{"source": {URL: "https://example.com/app.tar.gz"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Object).NotTo(BeNil())
+		Expect(result.Output).To(BeNil())
+		Expect(result.OutputErr).To(MatchError(ContainSubstring(".status.latestImage")))
+	})
+
+	It("returns an error when the template cannot be stamped", func() {
+		_, err := templates.Stamp(ctx, templates.Test{
+			Template: imageTemplate,
+			Workload: workload,
+		})
+		Expect(err).To(MatchError(ContainSubstring("source")))
+	})
+
+	It("stamps templates for deliverables", func() {
+		result, err := templates.Stamp(ctx, templates.Test{
+			Template: &v1alpha1.ClusterTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "deploy"},
+				Spec: v1alpha1.TemplateSpec{
+					Template: &runtime.RawExtension{Raw: []byte(`{
+						"apiVersion": "v1",
+						"kind": "ConfigMap",
+						"metadata": {"name": "$(deliverable.metadata.name)$"},
+						"data": {"config": "$(configs.app.config)$"}
+					}`)},
+				},
+			},
+			Deliverable: &v1alpha1.Deliverable{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+			Resource:    "deployer",
+			Configs:     map[string]cartotemplates.Config{"app": "replicas: 2"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Object).To(templates.ContainFields(`
+metadata:
+  name: app
+  namespace: default
+  labels:
+    carto.run/resource-name: deployer
+data:
+  config: "replicas: 2"
+`))
+	})
+
+	It("passes the cluster data given to the template", func() {
+		result, err := templates.Stamp(ctx, templates.Test{
+			Template: &v1alpha1.ClusterTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "route"},
+				Spec: v1alpha1.TemplateSpec{
+					Template: &runtime.RawExtension{Raw: []byte(`{
+						"apiVersion": "v1",
+						"kind": "ConfigMap",
+						"metadata": {"name": "$(workload.metadata.name)$"},
+						"data": {"host": "$(workload.metadata.name)$.$(cluster.domain)$"}
+					}`)},
+				},
+			},
+			Workload: workload,
+			Resource: "route",
+			Cluster:  map[string]interface{}{"domain": "cluster.local"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Object).To(templates.ContainFields(`{data: {host: app.cluster.local}}`))
+	})
+
+	It("requires exactly one of workload or deliverable", func() {
+		_, err := templates.Stamp(ctx, templates.Test{Template: imageTemplate})
+		Expect(err).To(MatchError("exactly one of workload or deliverable must be set"))
+	})
+})
+
+var _ = Describe("ContainFields", func() {
+	object := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"ports":    []interface{}{int64(8080)},
+			"image":    "app",
+		},
+	}
+
+	It("matches a subset of the object's fields", func() {
+		Expect(object).To(templates.ContainFields(`{spec: {replicas: 2, ports: [8080]}}`))
+	})
+
+	It("describes the first field that differs", func() {
+		matcher := templates.ContainFields(`{spec: {image: other}}`)
+		Expect(matcher.Match(object)).To(BeFalse())
+		Expect(matcher.FailureMessage(object)).To(ContainSubstring(".spec.image is app, expected other"))
+
+		matcher = templates.ContainFields(`{spec: {name: app}}`)
+		Expect(matcher.Match(object)).To(BeFalse())
+		Expect(matcher.FailureMessage(object)).To(ContainSubstring(".spec.name is missing"))
+	})
+
+	It("compares lists in full", func() {
+		Expect(object).NotTo(templates.ContainFields(`{spec: {ports: [8080, 9090]}}`))
+	})
+})
@@ -0,0 +1,80 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vmware-tanzu/cartographer/pkg/tracing"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		recorder         *tracetest.SpanRecorder
+		previousProvider trace.TracerProvider
+	)
+
+	BeforeEach(func() {
+		previousProvider = otel.GetTracerProvider()
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+
+	AfterEach(func() {
+		otel.SetTracerProvider(previousProvider)
+	})
+
+	It("nests spans started from a span's context", func() {
+		ctx, parent := tracing.Start(context.Background(), "parent", attribute.String("workload.name", "my-workload"))
+		_, child := tracing.Start(ctx, "child")
+		tracing.End(child, nil)
+		tracing.End(parent, nil)
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name()).To(Equal("child"))
+		Expect(spans[1].Name()).To(Equal("parent"))
+		Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+		Expect(spans[1].Attributes()).To(ContainElement(attribute.String("workload.name", "my-workload")))
+	})
+
+	It("records errors on the span", func() {
+		_, span := tracing.Start(context.Background(), "failing")
+		tracing.End(span, errors.New("boom"))
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Status().Description).To(Equal("boom"))
+		Expect(spans[0].Events()).To(HaveLen(1))
+	})
+
+	It("leaves the status unset when there is no error", func() {
+		_, span := tracing.Start(context.Background(), "passing")
+		tracing.End(span, nil)
+
+		Expect(recorder.Ended()[0].Status().Code).To(Equal(codes.Unset))
+	})
+})
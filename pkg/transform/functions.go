@@ -0,0 +1,155 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// functions are the functions expressions may call beyond the standard
+// ones and the string extensions.
+type functions struct{}
+
+func (functions) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("semver.compare",
+			cel.Overload("semver_compare_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.IntType,
+				cel.BinaryBinding(func(a, b ref.Val) ref.Val {
+					result, err := semverCompare(string(a.(types.String)), string(b.(types.String)))
+					if err != nil {
+						return types.NewErr("semver.compare: %s", err)
+					}
+					return types.Int(result)
+				}))),
+		cel.Function("semver.bump",
+			cel.Overload("semver_bump_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.StringType,
+				cel.BinaryBinding(func(version, part ref.Val) ref.Val {
+					result, err := semverBump(string(version.(types.String)), string(part.(types.String)))
+					if err != nil {
+						return types.NewErr("semver.bump: %s", err)
+					}
+					return types.String(result)
+				}))),
+		cel.Function("sha256",
+			cel.Overload("sha256_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					return types.String(fmt.Sprintf("%x", sha256.Sum256([]byte(value.(types.String)))))
+				}))),
+		cel.Function("regexReplace",
+			cel.Overload("regex_replace_string_string_string", []*cel.Type{cel.StringType, cel.StringType, cel.StringType}, cel.StringType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					pattern, err := regexp.Compile(string(args[1].(types.String)))
+					if err != nil {
+						return types.NewErr("regexReplace: %s", err)
+					}
+					return types.String(pattern.ReplaceAllString(string(args[0].(types.String)), string(args[2].(types.String))))
+				}))),
+		cel.Function("urlParse",
+			cel.Overload("url_parse_string", []*cel.Type{cel.StringType}, cel.MapType(cel.StringType, cel.DynType),
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					result, err := urlParse(string(value.(types.String)))
+					if err != nil {
+						return types.NewErr("urlParse: %s", err)
+					}
+					return types.DefaultTypeAdapter.NativeToValue(result)
+				}))),
+	}
+}
+
+func (functions) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+// parseSemver parses a version, tolerating a leading v as git tags often
+// carry.
+func parseSemver(version string) (semver.Version, error) {
+	parsed, err := semver.Parse(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("invalid version '%s': %w", version, err)
+	}
+	return parsed, nil
+}
+
+func semverCompare(a, b string) (int, error) {
+	versionA, err := parseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+	versionB, err := parseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+	return versionA.Compare(versionB), nil
+}
+
+// semverBump increments the major, minor or patch part of version, zeroing
+// the parts after it and dropping any pre-release and build metadata. A
+// leading v is kept.
+func semverBump(version, part string) (string, error) {
+	parsed, err := parseSemver(version)
+	if err != nil {
+		return "", err
+	}
+
+	switch part {
+	case "major":
+		parsed.Major, parsed.Minor, parsed.Patch = parsed.Major+1, 0, 0
+	case "minor":
+		parsed.Minor, parsed.Patch = parsed.Minor+1, 0
+	case "patch":
+		parsed.Patch++
+	default:
+		return "", fmt.Errorf("unknown part '%s', expected major, minor or patch", part)
+	}
+	parsed.Pre, parsed.Build = nil, nil
+
+	if strings.HasPrefix(version, "v") {
+		return "v" + parsed.String(), nil
+	}
+	return parsed.String(), nil
+}
+
+// urlParse splits a URL into its parts. Query parameters given more than
+// once keep their first value.
+func urlParse(value string) (map[string]interface{}, error) {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	query := map[string]interface{}{}
+	for key, values := range parsed.Query() {
+		query[key] = values[0]
+	}
+	return map[string]interface{}{
+		"scheme":   parsed.Scheme,
+		"user":     parsed.User.Username(),
+		"host":     parsed.Host,
+		"hostname": parsed.Hostname(),
+		"port":     parsed.Port(),
+		"path":     parsed.Path,
+		"query":    query,
+		"fragment": parsed.Fragment,
+	}, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+)
+
+// programs caches compiled expressions, which are evaluated on every
+// reconcile.
+var programs sync.Map
+
+// Compile checks that expression is a valid CEL expression over `value`.
+func Compile(expression string) error {
+	_, err := program(expression)
+	return err
+}
+
+// Apply evaluates the CEL expression with value available to it as `value`,
+// and returns the result as plain Go values: maps, slices, strings, numbers
+// and booleans. Expressions may use the CEL string extensions, such as
+// replace, split and join, along with the standard functions.
+func Apply(expression string, value interface{}) (interface{}, error) {
+	prg, err := program(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	result, _, err := prg.Eval(map[string]interface{}{"value": value})
+	if err != nil {
+		return nil, fmt.Errorf("evaluate: %w", err)
+	}
+	return native(result)
+}
+
+// ApplySource evaluates expression over a source output, presented as a map
+// with `url` and `revision`, and returns the url and revision of the map the
+// expression produces.
+func ApplySource(expression string, url, revision interface{}) (interface{}, interface{}, error) {
+	result, err := Apply(expression, map[string]interface{}{
+		"url":      url,
+		"revision": revision,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	source, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("source transform must return a map with url and revision, got %T", result)
+	}
+	return source["url"], source["revision"], nil
+}
+
+func program(expression string) (cel.Program, error) {
+	if cached, ok := programs.Load(expression); ok {
+		return cached.(cel.Program), nil
+	}
+
+	env, err := cel.NewEnv(cel.Variable("value", cel.DynType), ext.Strings(), cel.Lib(functions{}))
+	if err != nil {
+		return nil, fmt.Errorf("new cel env: %w", err)
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("compile: %w", issues.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("compile: %w", err)
+	}
+
+	programs.Store(expression, prg)
+	return prg, nil
+}
+
+func native(val ref.Val) (interface{}, error) {
+	switch v := val.(type) {
+	case types.Null:
+		return nil, nil
+	case traits.Mapper:
+		result := map[string]interface{}{}
+		it := v.Iterator()
+		for it.HasNext() == types.True {
+			key := it.Next()
+			name, ok := key.Value().(string)
+			if !ok {
+				return nil, fmt.Errorf("map key %v is not a string", key.Value())
+			}
+			item, err := native(v.Get(key))
+			if err != nil {
+				return nil, err
+			}
+			result[name] = item
+		}
+		return result, nil
+	case traits.Lister:
+		size := v.Size().(types.Int)
+		result := make([]interface{}, 0, size)
+		for i := types.Int(0); i < size; i++ {
+			item, err := native(v.Get(i))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+		return result, nil
+	default:
+		return val.Value(), nil
+	}
+}
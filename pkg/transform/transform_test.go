@@ -0,0 +1,135 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/transform"
+)
+
+var _ = Describe("Transform", func() {
+	Describe("Compile", func() {
+		It("accepts expressions over value", func() {
+			Expect(transform.Compile(`value.url + "/sub"`)).To(Succeed())
+		})
+
+		It("rejects expressions that do not parse", func() {
+			Expect(transform.Compile(`value.url +`)).To(MatchError(ContainSubstring("compile")))
+		})
+
+		It("rejects expressions over unknown variables", func() {
+			Expect(transform.Compile(`workload.name`)).To(MatchError(ContainSubstring("undeclared reference")))
+		})
+	})
+
+	Describe("Apply", func() {
+		It("returns maps, lists and scalars as plain Go values", func() {
+			result, err := transform.Apply(`{"image": value.image, "tags": [value.tag, "latest"], "replicas": 2, "null": null}`, map[string]interface{}{
+				"image": "registry/app",
+				"tag":   "v1",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(map[string]interface{}{
+				"image":    "registry/app",
+				"tags":     []interface{}{"v1", "latest"},
+				"replicas": int64(2),
+				"null":     nil,
+			}))
+		})
+
+		It("offers the string extensions", func() {
+			result, err := transform.Apply(`value.url.replace("https://", "") + "@" + [value.owner, value.repo].join("/")`, map[string]interface{}{
+				"url":   "https://github.com",
+				"owner": "vmware-tanzu",
+				"repo":  "cartographer",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("github.com@vmware-tanzu/cartographer"))
+		})
+
+		It("compares and bumps semantic versions", func() {
+			result, err := transform.Apply(`[semver.compare(value.tag, "v1.10.0"), semver.compare("1.2.3", "1.2.3-rc.1"), semver.compare("1.0.0", "1.0.0")]`, map[string]interface{}{
+				"tag": "v1.9.2",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal([]interface{}{int64(-1), int64(1), int64(0)}))
+
+			result, err = transform.Apply(`[semver.bump(value.tag, "major"), semver.bump(value.tag, "minor"), semver.bump("1.2.3-rc.1", "patch")]`, map[string]interface{}{
+				"tag": "v1.9.2",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal([]interface{}{"v2.0.0", "v1.10.0", "1.2.4"}))
+		})
+
+		It("returns an error for invalid versions and parts", func() {
+			_, err := transform.Apply(`semver.compare(value, "1.0.0")`, "latest")
+			Expect(err).To(MatchError(ContainSubstring("invalid version 'latest'")))
+
+			_, err = transform.Apply(`semver.bump("1.0.0", value)`, "build")
+			Expect(err).To(MatchError(ContainSubstring("unknown part 'build'")))
+		})
+
+		It("hashes strings with sha256", func() {
+			result, err := transform.Apply(`sha256(value).substring(0, 8)`, "cartographer")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("03a62bc6"))
+		})
+
+		It("replaces regular expression matches", func() {
+			result, err := transform.Apply(`regexReplace(value, "^feature/([a-z]+)-.*$", "preview-$1")`, "feature/login-form")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("preview-login"))
+
+			_, err = transform.Apply(`regexReplace(value, "(", "")`, "app")
+			Expect(err).To(MatchError(ContainSubstring("regexReplace")))
+		})
+
+		It("parses URLs", func() {
+			result, err := transform.Apply(`urlParse(value)`, "https://git@github.com:443/vmware-tanzu/cartographer?ref=main#readme")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(map[string]interface{}{
+				"scheme":   "https",
+				"user":     "git",
+				"host":     "github.com:443",
+				"hostname": "github.com",
+				"port":     "443",
+				"path":     "/vmware-tanzu/cartographer",
+				"query":    map[string]interface{}{"ref": "main"},
+				"fragment": "readme",
+			}))
+		})
+
+		It("returns an error when evaluation fails", func() {
+			_, err := transform.Apply(`value.missing`, map[string]interface{}{})
+			Expect(err).To(MatchError(ContainSubstring("evaluate")))
+		})
+	})
+
+	Describe("ApplySource", func() {
+		It("returns the url and revision of the resulting map", func() {
+			url, revision, err := transform.ApplySource(`{"url": value.url + "/app", "revision": value.revision}`, "https://example.com/src.tar.gz", "abc123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url).To(Equal("https://example.com/src.tar.gz/app"))
+			Expect(revision).To(Equal("abc123"))
+		})
+
+		It("returns an error when the result is not a map", func() {
+			_, _, err := transform.ApplySource(`value.url`, "https://example.com", "abc123")
+			Expect(err).To(MatchError(ContainSubstring("must return a map")))
+		})
+	})
+})
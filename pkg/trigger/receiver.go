@@ -0,0 +1,141 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+)
+
+// Path is where the Receiver is served. Requests name their target as
+// Path + "{workloads|deliverables}/{namespace}/{name}".
+const Path = "/trigger/"
+
+// QueueSize is how many triggers may wait for their controller before
+// further requests are turned away.
+const QueueSize = 256
+
+// Receiver is an HTTP handler through which external systems, such as
+// registry and git webhooks, ask for a workload or deliverable to be
+// reconciled now instead of at its next resync.
+type Receiver struct {
+	reader       client.Reader
+	token        string
+	logger       logr.Logger
+	workloads    chan event.GenericEvent
+	deliverables chan event.GenericEvent
+}
+
+// NewReceiver returns a Receiver that checks targets exist through reader.
+// Requests must present token either as a bearer token or as the `token`
+// query parameter. Without a token, every request is refused.
+func NewReceiver(reader client.Reader, token string, logger logr.Logger) *Receiver {
+	return &Receiver{
+		reader:       reader,
+		token:        token,
+		logger:       logger,
+		workloads:    make(chan event.GenericEvent, QueueSize),
+		deliverables: make(chan event.GenericEvent, QueueSize),
+	}
+}
+
+// WorkloadEvents is the source of workload reconcile requests.
+func (r *Receiver) WorkloadEvents() <-chan event.GenericEvent {
+	return r.workloads
+}
+
+// DeliverableEvents is the source of deliverable reconcile requests.
+func (r *Receiver) DeliverableEvents() <-chan event.GenericEvent {
+	return r.deliverables
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !r.authorized(req) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, Path), "/")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		http.Error(w, fmt.Sprintf("expected %s{workloads|deliverables}/{namespace}/{name}", Path), http.StatusNotFound)
+		return
+	}
+
+	var (
+		obj   client.Object
+		queue chan event.GenericEvent
+	)
+	switch parts[0] {
+	case "workloads":
+		obj, queue = &v1alpha1.Workload{}, r.workloads
+	case "deliverables":
+		obj, queue = &v1alpha1.Deliverable{}, r.deliverables
+	default:
+		http.Error(w, fmt.Sprintf("unknown kind '%s'", parts[0]), http.StatusNotFound)
+		return
+	}
+
+	key := types.NamespacedName{Namespace: parts[1], Name: parts[2]}
+	if err := r.reader.Get(req.Context(), key, obj); err != nil {
+		if kerrors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("%s '%s' not found", strings.TrimSuffix(parts[0], "s"), key), http.StatusNotFound)
+			return
+		}
+		r.logger.Error(err, "get trigger target", "kind", parts[0], "target", key)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	trigger := event.GenericEvent{Object: &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+	}}
+	select {
+	case queue <- trigger:
+		r.logger.Info("triggered", "kind", parts[0], "target", key)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "too many pending triggers", http.StatusServiceUnavailable)
+	}
+}
+
+func (r *Receiver) authorized(req *http.Request) bool {
+	if r.token == "" {
+		return false
+	}
+
+	presented := req.URL.Query().Get("token")
+	if bearer := req.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+		presented = strings.TrimPrefix(bearer, "Bearer ")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(r.token)) == 1
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/vmware-tanzu/cartographer/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/cartographer/pkg/trigger"
+)
+
+var _ = Describe("Receiver", func() {
+	var (
+		token    string
+		receiver *trigger.Receiver
+	)
+
+	BeforeEach(func() {
+		token = "s3cret"
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&v1alpha1.Workload{ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-workload"}},
+			&v1alpha1.Deliverable{ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-deliverable"}},
+		).Build()
+		receiver = trigger.NewReceiver(reader, token, logr.Discard())
+	})
+
+	post := func(target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, target, nil)
+		request.Header.Set("Authorization", "Bearer s3cret")
+		receiver.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	postWithoutToken := func(target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		receiver.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, target, nil))
+		return recorder
+	}
+
+	It("queues a reconcile for the named workload", func() {
+		Expect(post("/trigger/workloads/some-namespace/some-workload").Code).To(Equal(http.StatusAccepted))
+
+		var trigger event.GenericEvent
+		Expect(receiver.WorkloadEvents()).To(Receive(&trigger))
+		Expect(trigger.Object.GetNamespace()).To(Equal("some-namespace"))
+		Expect(trigger.Object.GetName()).To(Equal("some-workload"))
+		Expect(receiver.DeliverableEvents()).NotTo(Receive())
+	})
+
+	It("queues a reconcile for the named deliverable", func() {
+		Expect(post("/trigger/deliverables/some-namespace/some-deliverable").Code).To(Equal(http.StatusAccepted))
+
+		var trigger event.GenericEvent
+		Expect(receiver.DeliverableEvents()).To(Receive(&trigger))
+		Expect(trigger.Object.GetName()).To(Equal("some-deliverable"))
+		Expect(receiver.WorkloadEvents()).NotTo(Receive())
+	})
+
+	It("returns not found for objects that do not exist", func() {
+		response := post("/trigger/workloads/some-namespace/other-workload")
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+		Expect(response.Body.String()).To(ContainSubstring("workload 'some-namespace/other-workload' not found"))
+		Expect(receiver.WorkloadEvents()).NotTo(Receive())
+	})
+
+	It("returns not found for unknown kinds and malformed paths", func() {
+		Expect(post("/trigger/pipelines/some-namespace/some-pipeline").Code).To(Equal(http.StatusNotFound))
+		Expect(post("/trigger/workloads/some-workload").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("only accepts POST", func() {
+		recorder := httptest.NewRecorder()
+		receiver.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/trigger/workloads/some-namespace/some-workload", nil))
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("turns requests away once the queue is full", func() {
+		for i := 0; i < trigger.QueueSize; i++ {
+			Expect(post("/trigger/workloads/some-namespace/some-workload").Code).To(Equal(http.StatusAccepted))
+		}
+		Expect(post("/trigger/workloads/some-namespace/some-workload").Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	Context("a token is required", func() {
+		It("rejects requests without the token", func() {
+			Expect(postWithoutToken("/trigger/workloads/some-namespace/some-workload").Code).To(Equal(http.StatusUnauthorized))
+			Expect(postWithoutToken("/trigger/workloads/some-namespace/some-workload?token=wrong").Code).To(Equal(http.StatusUnauthorized))
+			Expect(receiver.WorkloadEvents()).NotTo(Receive())
+		})
+
+		It("accepts the token as a query parameter", func() {
+			Expect(postWithoutToken("/trigger/workloads/some-namespace/some-workload?token=s3cret").Code).To(Equal(http.StatusAccepted))
+		})
+
+		It("accepts the token as a bearer token", func() {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/trigger/workloads/some-namespace/some-workload", nil)
+			request.Header.Set("Authorization", "Bearer s3cret")
+			receiver.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusAccepted))
+		})
+	})
+
+	Context("no token is set", func() {
+		BeforeEach(func() {
+			token = ""
+		})
+
+		It("refuses every request", func() {
+			Expect(postWithoutToken("/trigger/workloads/some-namespace/some-workload").Code).To(Equal(http.StatusUnauthorized))
+			Expect(postWithoutToken("/trigger/workloads/some-namespace/some-workload?token=").Code).To(Equal(http.StatusUnauthorized))
+			Expect(receiver.WorkloadEvents()).NotTo(Receive())
+		})
+	})
+})
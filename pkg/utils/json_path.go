@@ -22,17 +22,43 @@ import (
 	"k8s.io/client-go/util/jsonpath"
 )
 
+// JsonPathParseError reports an expression that is not valid jsonpath,
+// as opposed to a valid expression that found no value.
+type JsonPathParseError struct {
+	Err        error
+	Expression string
+}
+
+func (e JsonPathParseError) Error() string {
+	return fmt.Errorf("jsonpath parse path '%s': %w", e.Expression, e.Err).Error()
+}
+
+func (e JsonPathParseError) Unwrap() error {
+	return e.Err
+}
+
 func SinglePathEvaluate(jsonpathExpression string, obj interface{}) ([]interface{}, error) {
+	return singlePathEvaluate(jsonpathExpression, obj, false)
+}
+
+// SinglePathEvaluateAllowingMissingKeys evaluates the expression like
+// SinglePathEvaluate, finding no results, rather than failing, where a key
+// it reads does not exist.
+func SinglePathEvaluateAllowingMissingKeys(jsonpathExpression string, obj interface{}) ([]interface{}, error) {
+	return singlePathEvaluate(jsonpathExpression, obj, true)
+}
+
+func singlePathEvaluate(jsonpathExpression string, obj interface{}, allowMissingKeys bool) ([]interface{}, error) {
 	var (
 		jsonBuffer    bytes.Buffer
 		interfaceList []interface{}
 	)
 
-	parser := jsonpath.New("")
+	parser := jsonpath.New("").AllowMissingKeys(allowMissingKeys)
 
 	err := parser.Parse(jsonpathExpression)
 	if err != nil {
-		return nil, fmt.Errorf("jsonpath parse path '%s': %w", jsonpathExpression, err)
+		return nil, JsonPathParseError{Err: err, Expression: jsonpathExpression}
 	}
 
 	values, err := parser.FindResults(obj)
@@ -15,7 +15,7 @@
 package utils_test
 
 import (
-	//"fmt"
+	"errors"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -59,6 +59,10 @@ var _ = Describe("JsonPath", func() {
 		})
 
 		ItReturnsAHelpfulError("jsonpath parse path '{{': ")
+
+		It("returns a parse error", func() {
+			Expect(errors.As(err, &utils.JsonPathParseError{})).To(BeTrue())
+		})
 	})
 
 	Context("when there are two queries are in the path", func() {
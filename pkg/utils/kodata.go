@@ -0,0 +1,33 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+)
+
+// KoDataBinary returns the path of the named binary shipped with the
+// controller, or the name alone, to be looked up on the PATH, when the
+// controller does not run from an image built by ko.
+func KoDataBinary(name string) string {
+	// ko copies the content of the kodata directory into the container at a path referenced by $KO_DATA_PATH
+	if kodata, ok := os.LookupEnv("KO_DATA_PATH"); ok {
+		return path.Join(kodata, fmt.Sprintf("%s-%s-%s", name, runtime.GOOS, runtime.GOARCH))
+	}
+	return name
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 VMware
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils_test
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/cartographer/pkg/utils"
+)
+
+var _ = Describe("KoDataBinary", func() {
+	var (
+		previous string
+		set      bool
+	)
+
+	BeforeEach(func() {
+		previous, set = os.LookupEnv("KO_DATA_PATH")
+	})
+
+	AfterEach(func() {
+		if set {
+			Expect(os.Setenv("KO_DATA_PATH", previous)).To(Succeed())
+		} else {
+			Expect(os.Unsetenv("KO_DATA_PATH")).To(Succeed())
+		}
+	})
+
+	It("returns the binary for the platform in the kodata directory", func() {
+		Expect(os.Setenv("KO_DATA_PATH", "/var/run/ko")).To(Succeed())
+
+		Expect(utils.KoDataBinary("ytt")).To(Equal(fmt.Sprintf("/var/run/ko/ytt-%s-%s", runtime.GOOS, runtime.GOARCH)))
+	})
+
+	It("returns the name alone outside of a ko image", func() {
+		Expect(os.Unsetenv("KO_DATA_PATH")).To(Succeed())
+
+		Expect(utils.KoDataBinary("ytt")).To(Equal("ytt"))
+	})
+})